@@ -281,6 +281,108 @@ func (a *Client) GetRule(params *GetRuleParams) (*GetRuleOK, error) {
 	panic(msg)
 }
 
+/*
+ImportPack installs a detection pack
+*/
+func (a *Client) ImportPack(params *ImportPackParams) (*ImportPackOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewImportPackParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "ImportPack",
+		Method:             "POST",
+		PathPattern:        "/pack/import",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ImportPackReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ImportPackOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for ImportPack: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+ListPackUpdates lists the installed detection packs with the versions available from their sources
+*/
+func (a *Client) ListPackUpdates(params *ListPackUpdatesParams) (*ListPackUpdatesOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewListPackUpdatesParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "ListPackUpdates",
+		Method:             "GET",
+		PathPattern:        "/pack/updates",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ListPackUpdatesReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ListPackUpdatesOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for ListPackUpdates: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+ListPacks lists the installed detection packs
+*/
+func (a *Client) ListPacks(params *ListPacksParams) (*ListPacksOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewListPacksParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "ListPacks",
+		Method:             "GET",
+		PathPattern:        "/pack/list",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ListPacksReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ListPacksOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for ListPacks: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 /*
 ListPolicies pages through policies in a customer s account
 */
@@ -485,6 +587,74 @@ func (a *Client) TestPolicy(params *TestPolicyParams) (*TestPolicyOK, error) {
 	panic(msg)
 }
 
+/*
+TestRule tests a rule against a set of sample events
+*/
+func (a *Client) TestRule(params *TestRuleParams) (*TestRuleOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewTestRuleParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "TestRule",
+		Method:             "POST",
+		PathPattern:        "/rule/test",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &TestRuleReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*TestRuleOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for TestRule: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+UpgradePack upgrades a detection pack
+*/
+func (a *Client) UpgradePack(params *UpgradePackParams) (*UpgradePackOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewUpgradePackParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "UpgradePack",
+		Method:             "POST",
+		PathPattern:        "/pack/upgrade",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &UpgradePackReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*UpgradePackOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for UpgradePack: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 // SetTransport changes the transport on the client
 func (a *Client) SetTransport(transport runtime.ClientTransport) {
 	a.transport = transport
@@ -24,8 +24,11 @@ package analysis
 // JSON tags not present because the JSON unmarshaller is easy
 type Config struct {
 	AnalysisType              string            `yaml:"AnalysisType"`
+	AutoRemediationApproval   bool              `yaml:"AutoRemediationApproval"`
 	AutoRemediationID         string            `yaml:"AutoRemediationID"`
 	AutoRemediationParameters map[string]string `yaml:"AutoRemediationParameters"`
+	DedupKey                  string            `yaml:"DedupKey"`
+	DedupPeriodMinutes        int64             `yaml:"DedupPeriodMinutes"`
 	Description               string            `yaml:"Description"`
 	DisplayName               string            `yaml:"DisplayName"`
 	Enabled                   bool              `yaml:"Enabled"`
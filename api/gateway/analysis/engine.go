@@ -70,6 +70,7 @@ type Rule struct {
 	Body     string   `json:"body"`
 	ID       string   `json:"id"`
 	LogTypes []string `json:"logTypes"`
+	DedupKey string   `json:"dedupKey,omitempty"`
 }
 
 // Event is a security log to be analyzed, e.g. a  CloudTrail event.
@@ -90,4 +91,7 @@ type EventAnalysis struct {
 	Errored    []PolicyError `json:"errored"`
 	Matched    []string      `json:"matched"`    // set of rule IDs which returned True
 	NotMatched []string      `json:"notMatched"` // set of rule IDs which returned False
+
+	// The dedup string of the match, only in direct analysis
+	Dedup string `json:"dedup,omitempty"`
 }
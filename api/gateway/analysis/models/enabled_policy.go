@@ -36,6 +36,12 @@ type EnabledPolicy struct {
 	// body
 	Body Body `json:"body,omitempty"`
 
+	// dedup key
+	DedupKey DedupKey `json:"dedupKey,omitempty"`
+
+	// dedup period minutes
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes,omitempty"`
+
 	// id
 	ID ID `json:"id,omitempty"`
 
@@ -48,6 +54,9 @@ type EnabledPolicy struct {
 	// suppressions
 	Suppressions Suppressions `json:"suppressions,omitempty"`
 
+	// tags
+	Tags Tags `json:"tags,omitempty"`
+
 	// version Id
 	VersionID VersionID `json:"versionId,omitempty"`
 }
@@ -60,6 +69,14 @@ func (m *EnabledPolicy) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateDedupKey(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -76,6 +93,10 @@ func (m *EnabledPolicy) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateTags(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateVersionID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -102,6 +123,38 @@ func (m *EnabledPolicy) validateBody(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *EnabledPolicy) validateDedupKey(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupKey) { // not required
+		return nil
+	}
+
+	if err := m.DedupKey.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupKey")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *EnabledPolicy) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupPeriodMinutes) { // not required
+		return nil
+	}
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *EnabledPolicy) validateID(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.ID) { // not required
@@ -166,6 +219,22 @@ func (m *EnabledPolicy) validateSuppressions(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *EnabledPolicy) validateTags(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Tags) { // not required
+		return nil
+	}
+
+	if err := m.Tags.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("tags")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *EnabledPolicy) validateVersionID(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.VersionID) { // not required
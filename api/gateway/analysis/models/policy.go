@@ -34,6 +34,9 @@ import (
 // swagger:model Policy
 type Policy struct {
 
+	// auto remediation approval
+	AutoRemediationApproval AutoRemediationApproval `json:"autoRemediationApproval,omitempty"`
+
 	// auto remediation Id
 	// Required: true
 	AutoRemediationID AutoRemediationID `json:"autoRemediationId"`
@@ -121,6 +124,10 @@ type Policy struct {
 func (m *Policy) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAutoRemediationApproval(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateAutoRemediationID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -207,6 +214,22 @@ func (m *Policy) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Policy) validateAutoRemediationApproval(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.AutoRemediationApproval) { // not required
+		return nil
+	}
+
+	if err := m.AutoRemediationApproval.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("autoRemediationApproval")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *Policy) validateAutoRemediationID(formats strfmt.Registry) error {
 
 	if err := m.AutoRemediationID.Validate(formats); err != nil {
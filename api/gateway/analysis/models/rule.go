@@ -47,6 +47,14 @@ type Rule struct {
 	// Required: true
 	CreatedBy UserID `json:"createdBy"`
 
+	// dedup key
+	// Required: true
+	DedupKey DedupKey `json:"dedupKey"`
+
+	// dedup period minutes
+	// Required: true
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes"`
+
 	// description
 	// Required: true
 	Description Description `json:"description"`
@@ -117,6 +125,14 @@ func (m *Rule) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateDedupKey(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -211,6 +227,30 @@ func (m *Rule) validateCreatedBy(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Rule) validateDedupKey(formats strfmt.Registry) error {
+
+	if err := m.DedupKey.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupKey")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *Rule) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *Rule) validateDescription(formats strfmt.Registry) error {
 
 	if err := m.Description.Validate(formats); err != nil {
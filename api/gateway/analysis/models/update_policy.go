@@ -33,6 +33,9 @@ import (
 // swagger:model UpdatePolicy
 type UpdatePolicy struct {
 
+	// auto remediation approval
+	AutoRemediationApproval AutoRemediationApproval `json:"autoRemediationApproval,omitempty"`
+
 	// auto remediation Id
 	AutoRemediationID AutoRemediationID `json:"autoRemediationId,omitempty"`
 
@@ -88,6 +91,10 @@ type UpdatePolicy struct {
 func (m *UpdatePolicy) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAutoRemediationApproval(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateAutoRemediationID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -154,6 +161,22 @@ func (m *UpdatePolicy) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdatePolicy) validateAutoRemediationApproval(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.AutoRemediationApproval) { // not required
+		return nil
+	}
+
+	if err := m.AutoRemediationApproval.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("autoRemediationApproval")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *UpdatePolicy) validateAutoRemediationID(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.AutoRemediationID) { // not required
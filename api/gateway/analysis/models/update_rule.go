@@ -37,6 +37,12 @@ type UpdateRule struct {
 	// Required: true
 	Body Body `json:"body"`
 
+	// dedup key
+	DedupKey DedupKey `json:"dedupKey,omitempty"`
+
+	// dedup period minutes
+	DedupPeriodMinutes DedupPeriodMinutes `json:"dedupPeriodMinutes,omitempty"`
+
 	// description
 	Description Description `json:"description,omitempty"`
 
@@ -83,6 +89,14 @@ func (m *UpdateRule) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateDedupKey(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateDedupPeriodMinutes(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateDescription(formats); err != nil {
 		res = append(res, err)
 	}
@@ -145,6 +159,38 @@ func (m *UpdateRule) validateBody(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdateRule) validateDedupKey(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupKey) { // not required
+		return nil
+	}
+
+	if err := m.DedupKey.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupKey")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m *UpdateRule) validateDedupPeriodMinutes(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.DedupPeriodMinutes) { // not required
+		return nil
+	}
+
+	if err := m.DedupPeriodMinutes.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("dedupPeriodMinutes")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *UpdateRule) validateDescription(formats strfmt.Registry) error {
 
 	if swag.IsZero(m.Description) { // not required
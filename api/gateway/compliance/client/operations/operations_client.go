@@ -43,6 +43,74 @@ type Client struct {
 	formats   strfmt.Registry
 }
 
+/*
+CreateException creates a compliance exception
+*/
+func (a *Client) CreateException(params *CreateExceptionParams) (*CreateExceptionCreated, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewCreateExceptionParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "CreateException",
+		Method:             "POST",
+		PathPattern:        "/exceptions",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &CreateExceptionReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*CreateExceptionCreated)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for CreateException: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+DeleteExceptions deletes one or more compliance exceptions
+*/
+func (a *Client) DeleteExceptions(params *DeleteExceptionsParams) (*DeleteExceptionsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewDeleteExceptionsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "DeleteExceptions",
+		Method:             "POST",
+		PathPattern:        "/delete-exceptions",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &DeleteExceptionsReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*DeleteExceptionsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for DeleteExceptions: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 /*
 DeleteStatus deletes the status associated with one or more policies or resources
 */
@@ -179,6 +247,74 @@ func (a *Client) DescribeResource(params *DescribeResourceParams) (*DescribeReso
 	panic(msg)
 }
 
+/*
+GetFrameworkReport gets a report of the controls of a compliance framework
+*/
+func (a *Client) GetFrameworkReport(params *GetFrameworkReportParams) (*GetFrameworkReportOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetFrameworkReportParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "GetFrameworkReport",
+		Method:             "GET",
+		PathPattern:        "/framework-report",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &GetFrameworkReportReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetFrameworkReportOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for GetFrameworkReport: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+GetFrameworkSummary gets the pass fail status of the controls of the compliance frameworks
+*/
+func (a *Client) GetFrameworkSummary(params *GetFrameworkSummaryParams) (*GetFrameworkSummaryOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetFrameworkSummaryParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "GetFrameworkSummary",
+		Method:             "GET",
+		PathPattern:        "/framework-summary",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &GetFrameworkSummaryReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetFrameworkSummaryOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for GetFrameworkSummary: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 /*
 GetOrgOverview gets account totals and top failing policies resources
 */
@@ -247,6 +383,40 @@ func (a *Client) GetStatus(params *GetStatusParams) (*GetStatusOK, error) {
 	panic(msg)
 }
 
+/*
+ListExceptions lists the compliance exceptions
+*/
+func (a *Client) ListExceptions(params *ListExceptionsParams) (*ListExceptionsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewListExceptionsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "ListExceptions",
+		Method:             "GET",
+		PathPattern:        "/exceptions",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ListExceptionsReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ListExceptionsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for ListExceptions: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 /*
 SetStatus sets the compliance status for a batch of resource policy pairs
 */
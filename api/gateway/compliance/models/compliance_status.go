@@ -36,6 +36,9 @@ type ComplianceStatus struct {
 	// error message
 	ErrorMessage ErrorMessage `json:"errorMessage,omitempty"`
 
+	// exception Id
+	ExceptionID ExceptionID `json:"exceptionId,omitempty"`
+
 	// expires at
 	// Required: true
 	ExpiresAt ExpiresAt `json:"expiresAt"`
@@ -57,6 +60,9 @@ type ComplianceStatus struct {
 	// Required: true
 	PolicySeverity PolicySeverity `json:"policySeverity"`
 
+	// policy tags
+	PolicyTags PolicyTags `json:"policyTags,omitempty"`
+
 	// resource Id
 	// Required: true
 	ResourceID ResourceID `json:"resourceId"`
@@ -82,6 +88,10 @@ func (m *ComplianceStatus) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateExceptionID(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateExpiresAt(formats); err != nil {
 		res = append(res, err)
 	}
@@ -102,6 +112,10 @@ func (m *ComplianceStatus) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validatePolicyTags(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateResourceID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -140,6 +154,22 @@ func (m *ComplianceStatus) validateErrorMessage(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *ComplianceStatus) validateExceptionID(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ExceptionID) { // not required
+		return nil
+	}
+
+	if err := m.ExceptionID.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("exceptionId")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *ComplianceStatus) validateExpiresAt(formats strfmt.Registry) error {
 
 	if err := m.ExpiresAt.Validate(formats); err != nil {
@@ -200,6 +230,22 @@ func (m *ComplianceStatus) validatePolicySeverity(formats strfmt.Registry) error
 	return nil
 }
 
+func (m *ComplianceStatus) validatePolicyTags(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.PolicyTags) { // not required
+		return nil
+	}
+
+	if err := m.PolicyTags.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("policyTags")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *ComplianceStatus) validateResourceID(formats strfmt.Registry) error {
 
 	if err := m.ResourceID.Validate(formats); err != nil {
@@ -36,6 +36,9 @@ type SetStatus struct {
 	// error message
 	ErrorMessage ErrorMessage `json:"errorMessage,omitempty"`
 
+	// exception Id
+	ExceptionID ExceptionID `json:"exceptionId,omitempty"`
+
 	// integration Id
 	// Required: true
 	IntegrationID IntegrationID `json:"integrationId"`
@@ -48,6 +51,9 @@ type SetStatus struct {
 	// Required: true
 	PolicySeverity PolicySeverity `json:"policySeverity"`
 
+	// policy tags
+	PolicyTags PolicyTags `json:"policyTags,omitempty"`
+
 	// resource Id
 	// Required: true
 	ResourceID ResourceID `json:"resourceId"`
@@ -73,6 +79,10 @@ func (m *SetStatus) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateExceptionID(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateIntegrationID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -85,6 +95,10 @@ func (m *SetStatus) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validatePolicyTags(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateResourceID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -123,6 +137,22 @@ func (m *SetStatus) validateErrorMessage(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *SetStatus) validateExceptionID(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.ExceptionID) { // not required
+		return nil
+	}
+
+	if err := m.ExceptionID.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("exceptionId")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *SetStatus) validateIntegrationID(formats strfmt.Registry) error {
 
 	if err := m.IntegrationID.Validate(formats); err != nil {
@@ -159,6 +189,22 @@ func (m *SetStatus) validatePolicySeverity(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *SetStatus) validatePolicyTags(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.PolicyTags) { // not required
+		return nil
+	}
+
+	if err := m.PolicyTags.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("policyTags")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *SetStatus) validateResourceID(formats strfmt.Registry) error {
 
 	if err := m.ResourceID.Validate(formats); err != nil {
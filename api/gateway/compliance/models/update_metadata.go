@@ -43,6 +43,9 @@ type UpdateMetadata struct {
 
 	// suppressions
 	Suppressions IgnoreSet `json:"suppressions,omitempty"`
+
+	// tags
+	Tags PolicyTags `json:"tags,omitempty"`
 }
 
 // Validate validates this update metadata
@@ -61,6 +64,10 @@ func (m *UpdateMetadata) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateTags(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -107,6 +114,22 @@ func (m *UpdateMetadata) validateSuppressions(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UpdateMetadata) validateTags(formats strfmt.Registry) error {
+
+	if swag.IsZero(m.Tags) { // not required
+		return nil
+	}
+
+	if err := m.Tags.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("tags")
+		}
+		return err
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *UpdateMetadata) MarshalBinary() ([]byte, error) {
 	if m == nil {
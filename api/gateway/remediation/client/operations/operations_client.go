@@ -43,6 +43,108 @@ type Client struct {
 	formats   strfmt.Registry
 }
 
+/*
+DeleteCustomRemediation removes a custom remediation the policies using it can no longer be remediated
+*/
+func (a *Client) DeleteCustomRemediation(params *DeleteCustomRemediationParams) (*DeleteCustomRemediationOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewDeleteCustomRemediationParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "DeleteCustomRemediation",
+		Method:             "POST",
+		PathPattern:        "/delete-custom",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &DeleteCustomRemediationReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*DeleteCustomRemediationOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for DeleteCustomRemediation: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+ListApprovals lists the remediations proposed for approval most recent first
+*/
+func (a *Client) ListApprovals(params *ListApprovalsParams) (*ListApprovalsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewListApprovalsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "ListApprovals",
+		Method:             "GET",
+		PathPattern:        "/approvals",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ListApprovalsReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ListApprovalsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for ListApprovals: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+ListCustomRemediations lists the remediations which invoke a customer lambda function
+*/
+func (a *Client) ListCustomRemediations(params *ListCustomRemediationsParams) (*ListCustomRemediationsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewListCustomRemediationsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "ListCustomRemediations",
+		Method:             "GET",
+		PathPattern:        "/custom",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ListCustomRemediationsReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ListCustomRemediationsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for ListCustomRemediations: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 /*
 ListRemediations retrieves available remediations
 */
@@ -77,6 +179,40 @@ func (a *Client) ListRemediations(params *ListRemediationsParams) (*ListRemediat
 	panic(msg)
 }
 
+/*
+PutCustomRemediation registers or update a remediation which invokes a customer lambda function
+*/
+func (a *Client) PutCustomRemediation(params *PutCustomRemediationParams) (*PutCustomRemediationOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewPutCustomRemediationParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "PutCustomRemediation",
+		Method:             "POST",
+		PathPattern:        "/custom",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &PutCustomRemediationReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*PutCustomRemediationOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for PutCustomRemediation: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 /*
 RemediateResource synchronouslies remediate resource for an account
 */
@@ -145,6 +281,74 @@ func (a *Client) RemediateResourceAsync(params *RemediateResourceAsyncParams) (*
 	panic(msg)
 }
 
+/*
+ReviewApproval approves or reject a proposed remediation an approved remediation is invoked right away
+*/
+func (a *Client) ReviewApproval(params *ReviewApprovalParams) (*ReviewApprovalOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewReviewApprovalParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "ReviewApproval",
+		Method:             "POST",
+		PathPattern:        "/review",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &ReviewApprovalReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ReviewApprovalOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for ReviewApproval: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+TestCustomRemediation invokes a custom remediation with a test resource the function is told it is a test
+*/
+func (a *Client) TestCustomRemediation(params *TestCustomRemediationParams) (*TestCustomRemediationOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewTestCustomRemediationParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "TestCustomRemediation",
+		Method:             "POST",
+		PathPattern:        "/test-custom",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader:             &TestCustomRemediationReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*TestCustomRemediationOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for TestCustomRemediation: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 // SetTransport changes the transport on the client
 func (a *Client) SetTransport(transport runtime.ClientTransport) {
 	a.transport = transport
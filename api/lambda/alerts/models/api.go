@@ -18,14 +18,31 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "time"
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
 
 // LambdaInput is the request structure for the alerts-api Lambda function.
 type LambdaInput struct {
-	GetAlert   *GetAlertInput   `json:"getAlert"`
-	ListAlerts *ListAlertsInput `json:"listAlerts"`
+	Caller *genericapi.Caller `json:"caller"`
+
+	GetAlert            *GetAlertInput            `json:"getAlert"`
+	ListAlerts          *ListAlertsInput          `json:"listAlerts"`
+	UpdateAlertStatus   *UpdateAlertStatusInput   `json:"updateAlertStatus"`
+	UpdateAlertAssignee *UpdateAlertAssigneeInput `json:"updateAlertAssignee"`
+	AddAlertComment     *AddAlertCommentInput     `json:"addAlertComment"`
 }
 
+// The triage status of an alert
+const (
+	StatusOpen          = "OPEN"
+	StatusTriaged       = "TRIAGED"
+	StatusResolved      = "RESOLVED"
+	StatusFalsePositive = "FALSE_POSITIVE"
+)
+
 // GetAlertInput retrieves details for a single alert.
 //
 // The response will contain by definition all of the events associated with the alert.
@@ -54,6 +71,8 @@ type GetAlertOutput = Alert
 
 // ListAlertsInput lists the alerts in reverse-chronological order (newest to oldest)
 // If "ruleId" is not set, we return all the alerts for the organization
+// If "status" or "assignee" are set, only the alerts with that status or assignee are returned. The filters
+// are applied to each page of alerts, so a page can have fewer than "pageSize" alerts even if there are more.
 // If the "exclusiveStartKey" is not set, we return alerts starting from the most recent one. If it is set,
 // the output will return alerts starting from the "exclusiveStartKey" exclusive.
 //
@@ -61,11 +80,14 @@ type GetAlertOutput = Alert
 // {
 //     "listAlerts": {
 //         "ruleId": "My.Rule",
+//         "status": "OPEN",
 //         "pageSize": 25
 //     }
 // }
 type ListAlertsInput struct {
 	RuleID            *string `json:"ruleId,omitempty"`
+	Status            *string `json:"status,omitempty" validate:"omitempty,oneof=OPEN TRIAGED RESOLVED FALSE_POSITIVE"`
+	Assignee          *string `json:"assignee,omitempty" validate:"omitempty,min=1"`
 	PageSize          *int    `json:"pageSize,omitempty"  validate:"omitempty,min=1,max=50"`
 	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
 }
@@ -89,6 +111,8 @@ type AlertSummary struct {
 	UpdateTime    *time.Time `json:"updateTime"`
 	EventsMatched *int       `json:"eventsMatched"`
 	Severity      *string    `json:"severity"`
+	Status        *string    `json:"status"`
+	Assignee      *string    `json:"assignee,omitempty"`
 }
 
 // Alert contains the details of an alert
@@ -100,4 +124,77 @@ type Alert struct {
 	EventsMatched          *int       `json:"eventsMatched"`
 	Events                 []*string  `json:"events"`
 	EventsLastEvaluatedKey *string    `json:"eventsLastEvaluatedKey,omitempty"`
+	Status                 *string    `json:"status"`
+	Assignee               *string    `json:"assignee,omitempty"`
+	LastUpdatedBy          *string    `json:"lastUpdatedBy,omitempty"`
+	LastUpdatedByTime      *time.Time `json:"lastUpdatedByTime,omitempty"`
+	Comments               []*Comment `json:"comments"`
+}
+
+// Comment is a comment of an analyst on an alert
+//
+// A reply to another comment of the alert has its ID as "parentId".
+type Comment struct {
+	CommentID *string    `json:"commentId"`
+	ParentID  *string    `json:"parentId,omitempty"`
+	UserID    *string    `json:"userId"`
+	CreatedAt *time.Time `json:"createdAt"`
+	Body      *string    `json:"body"`
+}
+
+// UpdateAlertStatusInput changes the triage status of an alert.
+//
+// An open alert can be triaged, resolved or marked as a false positive, a triaged alert can be resolved,
+// marked as a false positive or opened again, and a resolved or false positive alert can only be opened again.
+// {
+//     "updateAlertStatus": {
+//         "alertId": "ruleId-2",
+//         "status": "TRIAGED",
+//         "userId": "97e2d3d1-6ae2-4b4c-8b31-d5a4c6c4c3b9"
+//     }
+// }
+type UpdateAlertStatusInput struct {
+	AlertID *string `json:"alertId" validate:"required"`
+	Status  *string `json:"status" validate:"required,oneof=OPEN TRIAGED RESOLVED FALSE_POSITIVE"`
+	UserID  *string `json:"userId" validate:"required"`
+}
+
+// UpdateAlertStatusOutput is the alert with its new status.
+type UpdateAlertStatusOutput = AlertSummary
+
+// UpdateAlertAssigneeInput assigns an alert to an analyst.
+//
+// The alert is unassigned if "assignee" is not set.
+// {
+//     "updateAlertAssignee": {
+//         "alertId": "ruleId-2",
+//         "assignee": "97e2d3d1-6ae2-4b4c-8b31-d5a4c6c4c3b9",
+//         "userId": "97e2d3d1-6ae2-4b4c-8b31-d5a4c6c4c3b9"
+//     }
+// }
+type UpdateAlertAssigneeInput struct {
+	AlertID  *string `json:"alertId" validate:"required"`
+	Assignee *string `json:"assignee,omitempty" validate:"omitempty,min=1"`
+	UserID   *string `json:"userId" validate:"required"`
 }
+
+// UpdateAlertAssigneeOutput is the alert with its new assignee.
+type UpdateAlertAssigneeOutput = AlertSummary
+
+// AddAlertCommentInput adds a comment to an alert, or a reply to the comment with the "parentId".
+// {
+//     "addAlertComment": {
+//         "alertId": "ruleId-2",
+//         "body": "The source IP belongs to our VPN",
+//         "userId": "97e2d3d1-6ae2-4b4c-8b31-d5a4c6c4c3b9"
+//     }
+// }
+type AddAlertCommentInput struct {
+	AlertID  *string `json:"alertId" validate:"required"`
+	ParentID *string `json:"parentId,omitempty"`
+	Body     *string `json:"body" validate:"required,min=1,max=10000"`
+	UserID   *string `json:"userId" validate:"required"`
+}
+
+// AddAlertCommentOutput is the new comment.
+type AddAlertCommentOutput = Comment
@@ -24,6 +24,9 @@ type S3Notification struct {
 	S3Bucket *string `json:"s3Bucket" validate:"required"`
 	// S3ObjectKey is the key of the S3 object that contains the new data
 	S3ObjectKey *string `json:"s3ObjectKey" validate:"required"`
+	// TableS3ObjectKey is the key of the Parquet S3 object of the Glue table with the same data.
+	// The gzip JSON object of S3ObjectKey is then a staging copy, read by the rules engine.
+	TableS3ObjectKey *string `json:"tableS3ObjectKey,omitempty"`
 	// Events is the number of events in the S3 object
 	Events *int `json:"events"`
 	// Bytes is the uncompressed size in bytes of the S3 object
@@ -35,6 +38,11 @@ type S3Notification struct {
 	ID *string `json:"id" validate:"required"`
 }
 
+// SourceIDTagKey is the tag of the processed S3 objects with the ID of the source integration of their events.
+//
+// The objects of the events of unknown sources are not tagged.
+const SourceIDTagKey = "panther-source-id"
+
 // The type of data that are stored in the Panther
 type DataType string
 
@@ -0,0 +1,175 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the custom-schema-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	PutCustomSchema    *PutCustomSchemaInput    `json:"putCustomSchema"`
+	GetCustomSchema    *GetCustomSchemaInput    `json:"getCustomSchema"`
+	ListCustomSchemas  *ListCustomSchemasInput  `json:"listCustomSchemas"`
+	DeleteCustomSchema *DeleteCustomSchemaInput `json:"deleteCustomSchema"`
+}
+
+// The layouts of the log lines of custom schemas
+const (
+	LayoutJSON  = "json"
+	LayoutCSV   = "csv"
+	LayoutRegex = "regex"
+)
+
+// The types of the fields of custom schemas, named after their Glue column types
+const (
+	FieldTypeString    = "string"
+	FieldTypeInt       = "int"
+	FieldTypeBigInt    = "bigint"
+	FieldTypeDouble    = "double"
+	FieldTypeBoolean   = "boolean"
+	FieldTypeTimestamp = "timestamp"
+)
+
+// The indicators of the values of custom schema fields, added to the p_any fields of the events
+const (
+	IndicatorIP     = "ip"
+	IndicatorDomain = "domain"
+	IndicatorSHA1   = "sha1"
+	IndicatorMD5    = "md5"
+)
+
+// PutCustomSchemaInput creates a custom schema, or updates the one with the same name.
+//
+// The log type of the schema is "Custom.{name}", its events are stored in the custom_{name} Glue tables.
+// Updates can add fields: the existing fields can't be removed or change type.
+//
+// Example:
+//
+//	{
+//	    "putCustomSchema": {
+//	        "name": "FirewallAudit",
+//	        "layout": "csv",
+//	        "fields": [
+//	            {"name": "time", "type": "timestamp"},
+//	            {"name": "src_ip", "type": "string", "indicator": "ip"},
+//	            {"name": "bytes", "type": "bigint"}
+//	        ],
+//	        "timestampField": "time",
+//	        "timestampFormat": "unix",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type PutCustomSchemaInput struct {
+	Name        *string `json:"name" validate:"required,min=1,max=64,alphanum"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Layout      *string `json:"layout" validate:"required,oneof=json csv regex"`
+
+	Fields []*CustomSchemaField `json:"fields" validate:"required,min=1,max=500,dive,required"`
+
+	// The field holding the time of the events, which is required in the log lines
+	TimestampField *string `json:"timestampField,omitempty" validate:"omitempty,min=1"`
+	// The format of the timestamps: rfc3339 (default), unix, unix_ms, or a Go time layout
+	TimestampFormat *string `json:"timestampFormat,omitempty" validate:"omitempty,min=1,max=100"`
+
+	// The separator of the values of csv lines, defaults to ","
+	Delimiter *string `json:"delimiter,omitempty" validate:"omitempty,len=1"`
+	// The regular expression of regex lines, with a named group for each field
+	Pattern *string `json:"pattern,omitempty" validate:"omitempty,min=1,max=10000"`
+
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// CustomSchemaField is a field of the events of a custom schema, and a column of its Glue tables.
+type CustomSchemaField struct {
+	Name        *string `json:"name" validate:"required,min=1,max=128"`
+	Type        *string `json:"type" validate:"required,oneof=string int bigint double boolean timestamp"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	// Lines without the required fields are not of the log type
+	Required *bool `json:"required,omitempty"`
+	// The values of indicator fields are added to the p_any fields of the events: ip, domain, sha1 or md5
+	Indicator *string `json:"indicator,omitempty" validate:"omitempty,oneof=ip domain sha1 md5"`
+}
+
+// PutCustomSchemaOutput is the stored custom schema.
+type PutCustomSchemaOutput = CustomSchema
+
+// GetCustomSchemaInput retrieves a custom schema.
+//
+// Example:
+//
+//	{
+//	    "getCustomSchema": {
+//	        "name": "FirewallAudit"
+//	    }
+//	}
+type GetCustomSchemaInput struct {
+	Name *string `json:"name" validate:"required,min=1,max=64,alphanum"`
+}
+
+// GetCustomSchemaOutput is the custom schema.
+type GetCustomSchemaOutput = CustomSchema
+
+// ListCustomSchemasInput lists all the custom schemas.
+//
+// Example:
+//
+//	{
+//	    "listCustomSchemas": {}
+//	}
+type ListCustomSchemasInput struct{}
+
+// ListCustomSchemasOutput is all the custom schemas.
+type ListCustomSchemasOutput = []*CustomSchema
+
+// DeleteCustomSchemaInput deletes a custom schema.
+//
+// Its log type is no longer classified, the Glue tables are kept so its processed events stay searchable.
+//
+// Example:
+//
+//	{
+//	    "deleteCustomSchema": {
+//	        "name": "FirewallAudit"
+//	    }
+//	}
+type DeleteCustomSchemaInput struct {
+	Name *string `json:"name" validate:"required,min=1,max=64,alphanum"`
+}
+
+// CustomSchema is a user-defined log type and its parser.
+type CustomSchema struct {
+	Name            *string              `json:"name"`
+	LogType         *string              `json:"logType"`
+	Description     *string              `json:"description,omitempty"`
+	Layout          *string              `json:"layout"`
+	Fields          []*CustomSchemaField `json:"fields"`
+	TimestampField  *string              `json:"timestampField,omitempty"`
+	TimestampFormat *string              `json:"timestampFormat,omitempty"`
+	Delimiter       *string              `json:"delimiter,omitempty"`
+	Pattern         *string              `json:"pattern,omitempty"`
+	CreatedAtTime   *time.Time           `json:"createdAtTime"`
+	CreatedBy       *string              `json:"createdBy"`
+	LastModified    *time.Time           `json:"lastModified"`
+	LastModifiedBy  *string              `json:"lastModifiedBy"`
+}
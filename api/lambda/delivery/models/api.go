@@ -0,0 +1,52 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// LambdaInput is the direct invocation event of the alert-delivery Lambda function.
+//
+// The function also handles the alerts of the alert and retry queues.
+type LambdaInput struct {
+	Redeliver *RedeliverInput `json:"redeliver"`
+}
+
+// RedeliverInput queues the alerts which failed delivery again, from the dead letter queue.
+//
+// Only the alerts with a failed delivery to OutputID are redelivered if it is set.
+//
+// Example:
+// {
+//     "redeliver": {
+//         "outputId": "7d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "maxAlerts": 100
+//     }
+// }
+type RedeliverInput struct {
+	OutputID  *string `json:"outputId" validate:"omitempty,uuid4"`
+	MaxAlerts *int    `json:"maxAlerts" validate:"omitempty,min=1,max=1000"` // 100 if not set
+}
+
+// RedeliverOutput returns the number of alerts queued again.
+//
+// Example:
+// {
+//     "redelivered": 12
+// }
+type RedeliverOutput struct {
+	Redelivered int `json:"redelivered"`
+}
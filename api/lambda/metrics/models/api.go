@@ -0,0 +1,88 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The metrics of the ingestion, published by the log processor for each source and log type.
+const (
+	MetricBytesIngested        = "BytesIngested"
+	MetricClassificationErrors = "ClassificationErrors" // only per source, the log type of the data is unknown
+	MetricEventsProcessed      = "EventsProcessed"
+	MetricProcessingLatency    = "ProcessingLatency" // from the time of the events to their processing
+
+	// Published by the alert forwarder for each log type
+	MetricAlertsCreated = "AlertsCreated"
+)
+
+// The dimensions of the metrics
+const (
+	DimensionLogType  = "LogType"
+	DimensionSourceID = "SourceId"
+)
+
+// LambdaInput is the request structure for the metrics-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	GetMetrics *GetMetricsInput `json:"getMetrics"`
+}
+
+// GetMetricsInput returns the time series of the metrics, one for each log type or source.
+//
+// Example:
+//
+//	{
+//	    "getMetrics": {
+//	        "metricNames": ["EventsProcessed", "ClassificationErrors"],
+//	        "groupBy": "SourceId",
+//	        "fromDate": "2020-05-01T00:00:00Z",
+//	        "toDate": "2020-05-02T00:00:00Z",
+//	        "intervalMinutes": 60
+//	    }
+//	}
+type GetMetricsInput struct {
+	MetricNames []*string `json:"metricNames" validate:"min=1,max=5,dive,required,oneof=AlertsCreated BytesIngested ClassificationErrors EventsProcessed ProcessingLatency"` //nolint:lll
+	GroupBy     *string   `json:"groupBy" validate:"required,oneof=LogType SourceId"`
+
+	FromDate        *time.Time `json:"fromDate" validate:"required"`
+	ToDate          *time.Time `json:"toDate" validate:"required"`
+	IntervalMinutes *int64     `json:"intervalMinutes" validate:"required,min=1,max=1440"`
+}
+
+// GetMetricsOutput has a series for each metric and value of the dimension grouping them.
+type GetMetricsOutput struct {
+	Series []*MetricSeries `json:"series"`
+}
+
+// MetricSeries is the values of a metric over the time range, at each interval with data.
+//
+// The counts are summed over each interval and the latency is averaged.
+type MetricSeries struct {
+	MetricName *string `json:"metricName"`
+	// The log type or the source ID
+	Dimension *string `json:"dimension"`
+
+	Timestamps []*time.Time `json:"timestamps"`
+	Values     []*float64   `json:"values"`
+}
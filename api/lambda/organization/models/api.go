@@ -18,8 +18,12 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import "github.com/panther-labs/panther/pkg/genericapi"
+
 // LambdaInput is the request structure for the organization-api Lambda function.
 type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
 	GetSettings    *GetSettingsInput    `json:"getSettings"`
 	UpdateSettings *UpdateSettingsInput `json:"updateSettings"`
 }
@@ -18,15 +18,23 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import "github.com/panther-labs/panther/pkg/genericapi"
+
 // LambdaInput is the invocation event expected by the Lambda function.
 //
 // Exactly one action must be specified.
 type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
 	AddOutput    *AddOutputInput    `json:"addOutput"`
 	UpdateOutput *UpdateOutputInput `json:"updateOutput"`
 	GetOutput    *GetOutputInput    `json:"getOutput"`
 	DeleteOutput *DeleteOutputInput `json:"deleteOutput"`
 	GetOutputs   *GetOutputsInput   `json:"getOutputs"`
+
+	GetAlertRouting    *GetAlertRoutingInput    `json:"getAlertRouting"`
+	UpdateAlertRouting *UpdateAlertRoutingInput `json:"updateAlertRouting"`
+	RouteAlert         *RouteAlertInput         `json:"routeAlert"`
 }
 
 // AddOutputInput adds a new encrypted alert output to DynamoDB.
@@ -125,6 +133,77 @@ type GetOutputsInput struct {
 // }
 type GetOutputsOutput = []*AlertOutput
 
+// GetAlertRoutingInput fetches the routing rules of the alerts
+//
+// Example:
+// {
+//     "getAlertRouting": {
+//     }
+// }
+type GetAlertRoutingInput struct {
+}
+
+// GetAlertRoutingOutput returns the routing rules of the alerts, they are empty if never configured
+type GetAlertRoutingOutput = AlertRouting
+
+// UpdateAlertRoutingInput replaces the routing rules of the alerts.
+//
+// Example:
+// {
+//     "updateAlertRouting": {
+//         "userId": "9d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//         "rules": [
+//             {
+//                 "name": "critical-to-pager",
+//                 "severities": ["CRITICAL"],
+//                 "outputIds": ["7d1c5854-f3ea-491c-8a52-0aa0d58cb456"]
+//             }
+//         ],
+//         "defaultOutputIds": ["3c9d5c31-d8d4-4d2c-a93d-28b4a5a1c0a4"]
+//     }
+// }
+type UpdateAlertRoutingInput struct {
+	UserID           *string        `json:"userId" validate:"required,uuid4"`
+	Rules            []*RoutingRule `json:"rules" validate:"max=100,dive,required"`
+	DefaultOutputIDs []*string      `json:"defaultOutputIds" validate:"dive,required,uuid4"`
+}
+
+// UpdateAlertRoutingOutput returns the new routing rules
+type UpdateAlertRoutingOutput = AlertRouting
+
+// RouteAlertInput is a dry run of the routing of an alert with the given attributes.
+//
+// Example:
+// {
+//     "routeAlert": {
+//         "severity": "HIGH",
+//         "tags": ["pci"],
+//         "logTypes": ["AWS.CloudTrail"]
+//     }
+// }
+type RouteAlertInput = RoutedAlert
+
+// RouteAlertOutput returns the destinations the alert would be delivered to.
+//
+// RuleName is the routing rule matching the alert, it is not set if the alert falls back to the default route.
+// Without a default route, the alert goes to the destinations which are default for its severity.
+//
+// Example:
+// {
+//     "ruleName": "critical-to-pager",
+//     "outputs": [
+//         {
+//             "displayName": "alert-channel",
+//             "outputId": "7d1c5854-f3ea-491c-8a52-0aa0d58cb456",
+//             "outputType": "slack"
+//         }
+//     ]
+// }
+type RouteAlertOutput struct {
+	RuleName *string        `json:"ruleName,omitempty"`
+	Outputs  []*AlertOutput `json:"outputs"`
+}
+
 // AlertOutput contains the information for alert output configuration
 type AlertOutput struct {
 
@@ -185,6 +264,12 @@ type OutputConfig struct {
 
 	// AsanaConfig contains the configuration for Asana alert output
 	Asana *AsanaConfig `json:"asana,omitempty"`
+
+	// ServiceNowConfig contains the configuration for ServiceNow alert output
+	ServiceNow *ServiceNowConfig `json:"serviceNow,omitempty"`
+
+	// CustomWebhookConfig contains the configuration for custom webhook alert output
+	CustomWebhook *CustomWebhookConfig `json:"customWebhook,omitempty"`
 }
 
 // SlackConfig defines options for each Slack output.
@@ -225,7 +310,7 @@ type OpsgenieConfig struct {
 
 // MsTeamsConfig defines options for each MsTeamsConfig output
 type MsTeamsConfig struct {
-	WebhookURL *string `json:"webhookURL" validate:"required,url"`
+	WebhookURL *string `json:"webhookURL" validate:"required,url,msTeamsWebhook"` // https://outlook.office.com/webhook/...
 }
 
 // SqsConfig defines options for each Sqs topic output
@@ -239,6 +324,30 @@ type AsanaConfig struct {
 	ProjectGids         []*string `json:"projectGids" validate:"required,min=1,dive,required"`
 }
 
+// ServiceNowConfig defines options for each ServiceNow output
+//
+// Each alert opens an incident in the table, its urgency is the urgency of the alert severity.
+// ServiceNow calls back Panther with the callback token when the incidents are resolved.
+type ServiceNowConfig struct {
+	InstanceURL    *string         `json:"instanceURL" validate:"required,url,startswith=https://"` // https://<instance>.service-now.com
+	UserName       *string         `json:"userName" validate:"required"`
+	Password       *string         `json:"password" validate:"required"`
+	Table          *string         `json:"table,omitempty" validate:"omitempty,min=1,max=80"` // "incident" if not set
+	UrgencyMapping map[string]*int `json:"urgencyMapping,omitempty" validate:"omitempty,dive,keys,oneof=INFO LOW MEDIUM HIGH CRITICAL,endkeys,required,min=1,max=3"`
+	CallbackToken  *string         `json:"callbackToken" validate:"required,min=16"`
+}
+
+// CustomWebhookConfig defines options for each custom webhook output
+//
+// The body and the header values are Go templates rendered with the alert, which can
+// reference secrets stored in Secrets Manager, e.g. {{secret "panther-webhooks/token"}}.
+type CustomWebhookConfig struct {
+	WebhookURL   *string            `json:"webhookURL" validate:"required,url"`
+	Method       *string            `json:"method,omitempty" validate:"omitempty,oneof=POST PUT PATCH"` // POST if not set
+	Headers      map[string]*string `json:"headers,omitempty" validate:"omitempty,dive,keys,required,endkeys,required,webhookTemplate"`
+	BodyTemplate *string            `json:"bodyTemplate" validate:"required,webhookTemplate"`
+}
+
 // DefaultOutputs is the structure holding the information about default outputs for severity
 type DefaultOutputs struct {
 	Severity  *string   `json:"severity"`
@@ -0,0 +1,110 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// AlertRouting holds the ordered routing rules which select the destinations of the alerts.
+//
+// An alert goes to the destinations of the first rule it matches. An alert matching no rule
+// goes to the default route, or to the destinations which are default for its severity
+// if there is no default route.
+type AlertRouting struct {
+	Rules            []*RoutingRule `json:"rules"`
+	DefaultOutputIDs []*string      `json:"defaultOutputIds"`
+
+	// The user ID of the user that last modified the routing rules
+	LastModifiedBy *string `json:"lastModifiedBy,omitempty"`
+
+	// The time in RFC3339 format when the routing rules were last modified
+	LastModifiedTime *string `json:"lastModifiedTime,omitempty"`
+}
+
+// RoutingRule selects the destinations of the alerts it matches.
+//
+// An alert matches when it matches every criteria which is set, and it matches a criteria
+// when it has at least one of its values. A rule without criteria matches every alert.
+type RoutingRule struct {
+	Name                 *string   `json:"name" validate:"required,min=1"`
+	Severities           []*string `json:"severities,omitempty" validate:"dive,required,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Tags                 []*string `json:"tags,omitempty" validate:"dive,required"`
+	LogTypes             []*string `json:"logTypes,omitempty" validate:"dive,required"`
+	SourceIntegrationIDs []*string `json:"sourceIntegrationIds,omitempty" validate:"dive,required,uuid4"`
+	OutputIDs            []*string `json:"outputIds" validate:"min=1,dive,required,uuid4"`
+}
+
+// RoutedAlert has the attributes of an alert the routing rules match on.
+type RoutedAlert struct {
+	Severity            *string   `json:"severity" validate:"required,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Tags                []*string `json:"tags,omitempty"`
+	LogTypes            []*string `json:"logTypes,omitempty"`
+	SourceIntegrationID *string   `json:"sourceIntegrationId,omitempty"`
+}
+
+// Route returns the rule matching the alert and the IDs of the destinations it selects.
+//
+// The rule is nil if the alert falls back to the default route, the IDs are nil as well
+// if there is no default route.
+func (routing *AlertRouting) Route(alert *RoutedAlert) (*RoutingRule, []*string) {
+	if routing == nil {
+		return nil, nil
+	}
+	for _, rule := range routing.Rules {
+		if rule.Matches(alert) {
+			return rule, rule.OutputIDs
+		}
+	}
+	if len(routing.DefaultOutputIDs) == 0 {
+		return nil, nil
+	}
+	return nil, routing.DefaultOutputIDs
+}
+
+// Matches returns true if the alert matches every criteria of the rule.
+func (rule *RoutingRule) Matches(alert *RoutedAlert) bool {
+	if len(rule.Severities) > 0 && !containsAny(rule.Severities, []*string{alert.Severity}, false) {
+		return false
+	}
+	// Tags are free form, they match regardless of their case
+	if len(rule.Tags) > 0 && !containsAny(rule.Tags, alert.Tags, true) {
+		return false
+	}
+	if len(rule.LogTypes) > 0 && !containsAny(rule.LogTypes, alert.LogTypes, false) {
+		return false
+	}
+	if len(rule.SourceIntegrationIDs) > 0 && !containsAny(rule.SourceIntegrationIDs, []*string{alert.SourceIntegrationID}, false) {
+		return false
+	}
+	return true
+}
+
+func containsAny(values []*string, candidates []*string, ignoreCase bool) bool {
+	for _, value := range aws.StringValueSlice(values) {
+		for _, candidate := range aws.StringValueSlice(candidates) {
+			if value == candidate || ignoreCase && strings.EqualFold(value, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the partitions-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	SyncPartitions *SyncPartitionsInput `json:"syncPartitions"`
+}
+
+// MaxSyncHours is the longest time range of a SyncPartitions request, 31 days of hourly partitions.
+const MaxSyncHours = 31 * 24
+
+// SyncPartitionsInput repairs the Glue partitions of a log type over a time range from its data in S3.
+//
+// The partitions of the time range are created in the tables of the events (panther_logs) and of their
+// rule matches (panther_rule_matches) for the hours which have data, and the partitions with the wrong location
+// are updated. The partitions without data are left as they are.
+//
+// Example:
+//
+//	{
+//	    "syncPartitions": {
+//	        "logType": "AWS.CloudTrail",
+//	        "start": "2020-05-01T00:00:00Z",
+//	        "end": "2020-05-08T00:00:00Z"
+//	    }
+//	}
+type SyncPartitionsInput struct {
+	LogType *string `json:"logType" validate:"required,min=1,max=128"`
+
+	// The partitions which overlap the time range are synced, up to MaxSyncHours
+	Start *time.Time `json:"start" validate:"required"`
+	End   *time.Time `json:"end" validate:"required"`
+}
+
+// SyncPartitionsOutput is the number of partitions synced in each table.
+type SyncPartitionsOutput struct {
+	Tables []*TableSync `json:"tables"`
+}
+
+// TableSync is the number of partitions synced in a table of the log type.
+type TableSync struct {
+	DatabaseName *string `json:"databaseName"`
+	TableName    *string `json:"tableName"`
+
+	// The partitions which were missing, those whose location was updated, and those which were already correct
+	Created   *int `json:"created"`
+	Updated   *int `json:"updated"`
+	Unchanged *int `json:"unchanged"`
+	// The time ranges without data in S3
+	Empty *int `json:"empty"`
+
+	// The partitions which can't be repaired, such as the JSON partitions with Parquet objects to migrate
+	Errors []string `json:"errors,omitempty"`
+}
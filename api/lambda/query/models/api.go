@@ -0,0 +1,271 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the query-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	ExecuteQuery    *ExecuteQueryInput    `json:"executeQuery"`
+	GetQueryStatus  *GetQueryStatusInput  `json:"getQueryStatus"`
+	GetQueryResults *GetQueryResultsInput `json:"getQueryResults"`
+	StopQuery       *StopQueryInput       `json:"stopQuery"`
+
+	PutSavedSearch    *PutSavedSearchInput    `json:"putSavedSearch"`
+	GetSavedSearch    *GetSavedSearchInput    `json:"getSavedSearch"`
+	ListSavedSearches *ListSavedSearchesInput `json:"listSavedSearches"`
+	DeleteSavedSearch *DeleteSavedSearchInput `json:"deleteSavedSearch"`
+}
+
+// The statuses of a query execution, those of Athena
+const (
+	StatusQueued    = "QUEUED"
+	StatusRunning   = "RUNNING"
+	StatusSucceeded = "SUCCEEDED"
+	StatusFailed    = "FAILED"
+	StatusCancelled = "CANCELLED"
+)
+
+// The types of the parameters of a saved search
+const (
+	ParameterString = "string"
+	ParameterNumber = "number"
+)
+
+// ExecuteQueryInput starts an Athena query of the data lake, the query runs asynchronously.
+//
+// The query is either the SQL, or a saved search of the user with the values of its parameters.
+// Each user can run a few queries at the same time, and scan a limited number of bytes in 24 hours:
+// a query beyond these quotas is rejected with an UnavailableError.
+//
+// Example:
+//
+//	{
+//	    "executeQuery": {
+//	        "sql": "SELECT p_log_type, count(1) FROM all_logs WHERE year=2020 AND month=3 GROUP BY 1",
+//	        "database": "panther_views",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type ExecuteQueryInput struct {
+	SQL *string `json:"sql,omitempty" validate:"omitempty,min=1,max=100000"`
+	// The database of the unqualified table names, panther_logs by default
+	Database *string `json:"database,omitempty" validate:"omitempty,oneof=panther_logs panther_rule_matches panther_views"`
+
+	SearchID   *string           `json:"searchId,omitempty" validate:"omitempty,uuid4"`
+	Parameters map[string]string `json:"parameters,omitempty" validate:"max=100,dive,max=1000"`
+
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// ExecuteQueryOutput is the started query execution.
+type ExecuteQueryOutput = QueryExecution
+
+// GetQueryStatusInput retrieves the status of a query execution of the user.
+//
+// Example:
+//
+//	{
+//	    "getQueryStatus": {
+//	        "queryExecutionId": "c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type GetQueryStatusInput struct {
+	QueryExecutionID *string `json:"queryExecutionId" validate:"required,uuid"`
+	UserID           *string `json:"userId" validate:"required,uuid4"`
+}
+
+// GetQueryStatusOutput is the query execution.
+type GetQueryStatusOutput = QueryExecution
+
+// GetQueryResultsInput retrieves a page of the results of a succeeded query execution of the user.
+//
+// The results of a query which is still running are an UnavailableError.
+//
+// Example:
+//
+//	{
+//	    "getQueryResults": {
+//	        "queryExecutionId": "c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e",
+//	        "pageSize": 100,
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type GetQueryResultsInput struct {
+	QueryExecutionID *string `json:"queryExecutionId" validate:"required,uuid"`
+	// 100 rows by default
+	PageSize        *int64  `json:"pageSize,omitempty" validate:"omitempty,min=1,max=1000"`
+	PaginationToken *string `json:"paginationToken,omitempty" validate:"omitempty,min=1"`
+	UserID          *string `json:"userId" validate:"required,uuid4"`
+}
+
+// GetQueryResultsOutput is a page of the result rows, the values are strings and nil for null.
+type GetQueryResultsOutput struct {
+	Columns []*Column   `json:"columns"`
+	Rows    [][]*string `json:"rows"`
+	// Set if there are more rows
+	PaginationToken *string `json:"paginationToken,omitempty"`
+}
+
+// Column is a column of the results of a query.
+type Column struct {
+	Name *string `json:"name"`
+	Type *string `json:"type"`
+}
+
+// StopQueryInput cancels a query execution of the user.
+//
+// Example:
+//
+//	{
+//	    "stopQuery": {
+//	        "queryExecutionId": "c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type StopQueryInput struct {
+	QueryExecutionID *string `json:"queryExecutionId" validate:"required,uuid"`
+	UserID           *string `json:"userId" validate:"required,uuid4"`
+}
+
+// StopQueryOutput is the query execution.
+type StopQueryOutput = QueryExecution
+
+// QueryExecution is a query of the data lake run by a user.
+type QueryExecution struct {
+	QueryExecutionID *string `json:"queryExecutionId"`
+	UserID           *string `json:"userId"`
+	SQL              *string `json:"sql"`
+	Database         *string `json:"database"`
+	SearchID         *string `json:"searchId,omitempty"`
+
+	Status              *string    `json:"status"`
+	Error               *string    `json:"error,omitempty"`
+	ScannedBytes        *int64     `json:"scannedBytes,omitempty"`
+	ExecutionTimeMillis *int64     `json:"executionTimeMillis,omitempty"`
+	SubmittedAt         *time.Time `json:"submittedAt"`
+	CompletedAt         *time.Time `json:"completedAt,omitempty"`
+}
+
+// PutSavedSearchInput creates a saved search of the user, or updates the one with the given searchId.
+//
+// The parameters are referenced as {{name}} in the SQL, and replaced by their values when the search
+// is executed: strings are quoted, numbers are inserted as they are.
+//
+// Example:
+//
+//	{
+//	    "putSavedSearch": {
+//	        "name": "Activity of an ip address",
+//	        "sql": "SELECT p_log_type, count(1) FROM all_logs WHERE contains(p_any_ip_addresses, {{ip}}) GROUP BY 1",
+//	        "database": "panther_views",
+//	        "parameters": [{"name": "ip", "type": "string"}],
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type PutSavedSearchInput struct {
+	SearchID    *string            `json:"searchId,omitempty" validate:"omitempty,uuid4"`
+	Name        *string            `json:"name" validate:"required,min=1,max=128"`
+	Description *string            `json:"description,omitempty" validate:"omitempty,max=1000"`
+	SQL         *string            `json:"sql" validate:"required,min=1,max=100000"`
+	Database    *string            `json:"database,omitempty" validate:"omitempty,oneof=panther_logs panther_rule_matches panther_views"`
+	Parameters  []*SearchParameter `json:"parameters,omitempty" validate:"max=100,dive,required"`
+	UserID      *string            `json:"userId" validate:"required,uuid4"`
+}
+
+// PutSavedSearchOutput is the stored saved search.
+type PutSavedSearchOutput = SavedSearch
+
+// SearchParameter is a parameter of a saved search.
+type SearchParameter struct {
+	Name *string `json:"name" validate:"required,min=1,max=64"`
+	Type *string `json:"type" validate:"required,oneof=string number"`
+	// Used when the search is executed without a value, a parameter without a default is required
+	DefaultValue *string `json:"defaultValue,omitempty" validate:"omitempty,max=1000"`
+}
+
+// GetSavedSearchInput retrieves a saved search of the user.
+//
+// Example:
+//
+//	{
+//	    "getSavedSearch": {
+//	        "searchId": "1e6f4f62-8d8b-4f4b-95c7-1c0f5f3b2a7d",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type GetSavedSearchInput struct {
+	SearchID *string `json:"searchId" validate:"required,uuid4"`
+	UserID   *string `json:"userId" validate:"required,uuid4"`
+}
+
+// GetSavedSearchOutput is the saved search.
+type GetSavedSearchOutput = SavedSearch
+
+// ListSavedSearchesInput lists the saved searches of the user.
+//
+// Example:
+//
+//	{
+//	    "listSavedSearches": {
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type ListSavedSearchesInput struct {
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// ListSavedSearchesOutput is the saved searches of the user.
+type ListSavedSearchesOutput = []*SavedSearch
+
+// DeleteSavedSearchInput deletes a saved search of the user.
+//
+// Example:
+//
+//	{
+//	    "deleteSavedSearch": {
+//	        "searchId": "1e6f4f62-8d8b-4f4b-95c7-1c0f5f3b2a7d",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type DeleteSavedSearchInput struct {
+	SearchID *string `json:"searchId" validate:"required,uuid4"`
+	UserID   *string `json:"userId" validate:"required,uuid4"`
+}
+
+// SavedSearch is a query of a user, with parameters.
+type SavedSearch struct {
+	SearchID    *string            `json:"searchId"`
+	UserID      *string            `json:"userId"`
+	Name        *string            `json:"name"`
+	Description *string            `json:"description,omitempty"`
+	SQL         *string            `json:"sql"`
+	Database    *string            `json:"database"`
+	Parameters  []*SearchParameter `json:"parameters"`
+
+	CreatedAtTime *time.Time `json:"createdAtTime"`
+	LastModified  *time.Time `json:"lastModified"`
+}
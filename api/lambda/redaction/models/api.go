@@ -0,0 +1,189 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the redaction-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	PutRedactionPolicy         *PutRedactionPolicyInput         `json:"putRedactionPolicy"`
+	GetRedactionPolicy         *GetRedactionPolicyInput         `json:"getRedactionPolicy"`
+	ListRedactionPolicies      *ListRedactionPoliciesInput      `json:"listRedactionPolicies"`
+	DeleteRedactionPolicy      *DeleteRedactionPolicyInput      `json:"deleteRedactionPolicy"`
+	ListRedactionPolicyChanges *ListRedactionPolicyChangesInput `json:"listRedactionPolicyChanges"`
+}
+
+// The actions of the redacted fields
+const (
+	// The value is replaced with its SHA256 hash, so the events can still be correlated on it
+	ActionHash = "hash"
+	// Only the first characters of the value are kept
+	ActionTruncate = "truncate"
+	// The field is removed from the event
+	ActionDrop = "drop"
+)
+
+// The operations of the changes to the redaction policies
+const (
+	OperationCreated = "CREATED"
+	OperationUpdated = "UPDATED"
+	OperationDeleted = "DELETED"
+)
+
+// PutRedactionPolicyInput creates or replaces the redaction policy of a log type.
+//
+// The log processor redacts the fields of the events of the log type before they are written to the data lake,
+// so neither the rules nor the queries see the original values. The events processed before are not redacted.
+//
+// Example:
+//
+//	{
+//	    "putRedactionPolicy": {
+//	        "logType": "AWS.ALB",
+//	        "description": "Client addresses are personal data",
+//	        "fields": [
+//	            {"path": "clientIp", "action": "hash"},
+//	            {"path": "requestUrl", "action": "truncate", "length": 64},
+//	            {"path": "userAgent", "action": "drop"}
+//	        ],
+//	        "enabled": true,
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type PutRedactionPolicyInput struct {
+	LogType     *string          `json:"logType" validate:"required,min=1,max=500"`
+	Description *string          `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Fields      []*RedactedField `json:"fields" validate:"required,min=1,max=100,dive,required"`
+	Enabled     *bool            `json:"enabled"`
+
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// RedactedField is a field of the events of a log type and how it is redacted.
+type RedactedField struct {
+	// The dot-separated path of the field, e.g. "userIdentity.arn", the elements of the arrays on the path
+	// are all redacted. The p_ fields added by Panther can't be redacted.
+	Path   *string `json:"path" validate:"required,min=1,max=500"`
+	Action *string `json:"action" validate:"required,oneof=hash truncate drop"`
+	// The characters kept by truncate
+	Length *int `json:"length,omitempty" validate:"omitempty,min=1,max=10000"`
+}
+
+// PutRedactionPolicyOutput is the stored redaction policy.
+type PutRedactionPolicyOutput = RedactionPolicy
+
+// GetRedactionPolicyInput retrieves the redaction policy of a log type.
+//
+// Example:
+//
+//	{
+//	    "getRedactionPolicy": {
+//	        "logType": "AWS.ALB"
+//	    }
+//	}
+type GetRedactionPolicyInput struct {
+	LogType *string `json:"logType" validate:"required,min=1,max=500"`
+}
+
+// GetRedactionPolicyOutput is the redaction policy.
+type GetRedactionPolicyOutput = RedactionPolicy
+
+// ListRedactionPoliciesInput lists all the redaction policies.
+//
+// Example:
+//
+//	{
+//	    "listRedactionPolicies": {}
+//	}
+type ListRedactionPoliciesInput struct{}
+
+// ListRedactionPoliciesOutput is all the redaction policies.
+type ListRedactionPoliciesOutput = []*RedactionPolicy
+
+// DeleteRedactionPolicyInput deletes the redaction policy of a log type.
+//
+// Example:
+//
+//	{
+//	    "deleteRedactionPolicy": {
+//	        "logType": "AWS.ALB",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type DeleteRedactionPolicyInput struct {
+	LogType *string `json:"logType" validate:"required,min=1,max=500"`
+	UserID  *string `json:"userId" validate:"required,uuid4"`
+}
+
+// ListRedactionPolicyChangesInput returns a page of the changes made to the redaction policy of a log type,
+// most recent first. The changes of a deleted policy are kept.
+//
+// Example:
+//
+//	{
+//	    "listRedactionPolicyChanges": {
+//	        "logType": "AWS.ALB",
+//	        "pageSize": 25
+//	    }
+//	}
+type ListRedactionPolicyChangesInput struct {
+	LogType  *string `json:"logType" validate:"required,min=1,max=500"`
+	PageSize *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	// The lastEvaluatedKey of the previous page
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+// ListRedactionPolicyChangesOutput is a page of the changes to a redaction policy.
+type ListRedactionPolicyChangesOutput struct {
+	Changes []*RedactionPolicyChange `json:"changes"`
+	// Set when there may be more changes
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// RedactionPolicy is how the fields of the events of a log type are redacted.
+type RedactionPolicy struct {
+	LogType     *string          `json:"logType"`
+	Description *string          `json:"description,omitempty"`
+	Fields      []*RedactedField `json:"fields"`
+	Enabled     *bool            `json:"enabled"`
+
+	CreatedAtTime  *time.Time `json:"createdAtTime"`
+	CreatedBy      *string    `json:"createdBy"`
+	LastModified   *time.Time `json:"lastModified"`
+	LastModifiedBy *string    `json:"lastModifiedBy"`
+}
+
+// RedactionPolicyChange is a change to a redaction policy, recorded along with it.
+type RedactionPolicyChange struct {
+	LogType *string `json:"logType"`
+	// Starts with the time of the change, so the changes sort chronologically
+	ChangeID  *string    `json:"changeId"`
+	ChangedAt *time.Time `json:"changedAt"`
+	ChangedBy *string    `json:"changedBy"`
+	Operation *string    `json:"operation"`
+	// The policy before and after the change, Before is not set for a creation nor After for a deletion
+	Before *RedactionPolicy `json:"before,omitempty"`
+	After  *RedactionPolicy `json:"after,omitempty"`
+}
@@ -0,0 +1,105 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the replay-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	ReplayRule *ReplayRuleInput `json:"replayRule"`
+}
+
+// ReplayRuleInput runs a rule against the historical events of the data lake, and returns the alerts
+// it would have created. Nothing is delivered: the matches are neither stored nor alerted on.
+//
+// The rule is either an existing one, with its ruleId, or a new one with its Python body.
+// The events are read from the processed data of the log types in the hourly partitions of the time range,
+// and those whose p_event_time is within it are analyzed. The replay stops after maxEvents events.
+//
+// Example:
+//
+//	{
+//	    "replayRule": {
+//	        "body": "def rule(event):\n    return event.get('errorCode') == 'AccessDenied'",
+//	        "logTypes": ["AWS.CloudTrail"],
+//	        "startTime": "2020-05-01T00:00:00Z",
+//	        "endTime": "2020-05-02T00:00:00Z",
+//	        "dedupPeriodMinutes": 60
+//	    }
+//	}
+type ReplayRuleInput struct {
+	// An existing rule, whose body, log types and dedup settings are replayed unless set here
+	RuleID *string `json:"ruleId,omitempty" validate:"omitempty,min=1,max=1000"`
+	// A new rule, which needs its log types
+	Body *string `json:"body,omitempty" validate:"omitempty,min=1,max=100000"`
+
+	LogTypes  []string   `json:"logTypes,omitempty" validate:"omitempty,max=100,dive,min=1"`
+	StartTime *time.Time `json:"startTime" validate:"required"`
+	EndTime   *time.Time `json:"endTime" validate:"required"`
+
+	// The template of the dedup string of the rules without a dedup function, e.g. "{userIdentity.arn}"
+	DedupKey *string `json:"dedupKey,omitempty" validate:"omitempty,max=1000"`
+	// The matches with the same dedup string are merged into an alert for this period, 60 minutes by default
+	DedupPeriodMinutes *int `json:"dedupPeriodMinutes,omitempty" validate:"omitempty,min=5,max=1440"`
+	// 100000 by default
+	MaxEvents *int `json:"maxEvents,omitempty" validate:"omitempty,min=1,max=1000000"`
+}
+
+// ReplayRuleOutput is the outcome of the replay of a rule.
+type ReplayRuleOutput struct {
+	RuleID    string    `json:"ruleId"`
+	LogTypes  []string  `json:"logTypes"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	EventsScanned int `json:"eventsScanned"`
+	EventsMatched int `json:"eventsMatched"`
+	EventsErrored int `json:"eventsErrored"`
+	// Set when maxEvents was reached before the end of the time range
+	Truncated bool `json:"truncated"`
+
+	// The first errors of the rule, at most 10
+	Errors []*ReplayError `json:"errors"`
+	// The alerts the matches would have created, by the time of their first event
+	Alerts []*ReplayAlert `json:"alerts"`
+}
+
+// ReplayError is an error of the rule for an event.
+type ReplayError struct {
+	EventTime time.Time       `json:"eventTime"`
+	Message   string          `json:"message"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// ReplayAlert is an alert the rule would have created.
+type ReplayAlert struct {
+	Dedup          string    `json:"dedup"`
+	FirstEventTime time.Time `json:"firstEventTime"`
+	LastEventTime  time.Time `json:"lastEventTime"`
+	EventCount     int       `json:"eventCount"`
+	// The first events of the alert, at most 10
+	SampleEvents []json.RawMessage `json:"sampleEvents"`
+}
@@ -0,0 +1,172 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the retention-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	PutRetentionPolicy    *PutRetentionPolicyInput    `json:"putRetentionPolicy"`
+	GetRetentionPolicy    *GetRetentionPolicyInput    `json:"getRetentionPolicy"`
+	ListRetentionPolicies *ListRetentionPoliciesInput `json:"listRetentionPolicies"`
+	DeleteRetentionPolicy *DeleteRetentionPolicyInput `json:"deleteRetentionPolicy"`
+	GetStorageUsage       *GetStorageUsageInput       `json:"getStorageUsage"`
+	ApplyRetention        *ApplyRetentionInput        `json:"applyRetention"`
+}
+
+// PutRetentionPolicyInput sets the retention of the processed data of a log type or of a source.
+//
+// The data of a log type, both its events and their rule matches, is deleted once it's older than the retention
+// of the log type. The events of a source are deleted once they're older than the retention of the source,
+// or than the retention of their log type when it's shorter: the shortest retention always applies.
+//
+// Example:
+//
+//	{
+//	    "putRetentionPolicy": {
+//	        "logType": "AWS.CloudTrail",
+//	        "retentionDays": 365,
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type PutRetentionPolicyInput struct {
+	// Either a log type or the ID of a source integration
+	LogType  *string `json:"logType,omitempty" validate:"omitempty,min=1,max=128"`
+	SourceID *string `json:"sourceId,omitempty" validate:"omitempty,uuid4"`
+
+	// Up to 10 years
+	RetentionDays *int `json:"retentionDays" validate:"required,min=1,max=3650"`
+
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// PutRetentionPolicyOutput is the stored retention policy.
+type PutRetentionPolicyOutput = RetentionPolicy
+
+// GetRetentionPolicyInput retrieves the retention policy of a log type or of a source.
+//
+// Example:
+//
+//	{
+//	    "getRetentionPolicy": {
+//	        "sourceId": "45c378a7-2e36-4b12-8e16-2d3c49ff1371"
+//	    }
+//	}
+type GetRetentionPolicyInput struct {
+	LogType  *string `json:"logType,omitempty" validate:"omitempty,min=1,max=128"`
+	SourceID *string `json:"sourceId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// GetRetentionPolicyOutput is the retention policy.
+type GetRetentionPolicyOutput = RetentionPolicy
+
+// ListRetentionPoliciesInput lists all the retention policies.
+//
+// Example:
+//
+//	{
+//	    "listRetentionPolicies": {}
+//	}
+type ListRetentionPoliciesInput struct{}
+
+// ListRetentionPoliciesOutput is all the retention policies.
+type ListRetentionPoliciesOutput = []*RetentionPolicy
+
+// DeleteRetentionPolicyInput deletes the retention policy of a log type or of a source: its data is then kept forever.
+//
+// Example:
+//
+//	{
+//	    "deleteRetentionPolicy": {
+//	        "logType": "AWS.CloudTrail"
+//	    }
+//	}
+type DeleteRetentionPolicyInput struct {
+	LogType  *string `json:"logType,omitempty" validate:"omitempty,min=1,max=128"`
+	SourceID *string `json:"sourceId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// RetentionPolicy is the retention of the processed data of a log type or of a source.
+type RetentionPolicy struct {
+	LogType  *string `json:"logType,omitempty"`
+	SourceID *string `json:"sourceId,omitempty"`
+
+	RetentionDays *int `json:"retentionDays"`
+
+	LastModified   *time.Time `json:"lastModified"`
+	LastModifiedBy *string    `json:"lastModifiedBy"`
+}
+
+// GetStorageUsageInput reports the storage consumed by the processed data of each log type.
+//
+// The objects of the processed data bucket are listed, which takes longer as the data lake grows.
+//
+// Example:
+//
+//	{
+//	    "getStorageUsage": {}
+//	}
+type GetStorageUsageInput struct{}
+
+// GetStorageUsageOutput is the storage consumed by each log type, with the largest first.
+type GetStorageUsageOutput struct {
+	LogTypes []*LogTypeUsage `json:"logTypes"`
+
+	TotalBytes *int64 `json:"totalBytes"`
+}
+
+// LogTypeUsage is the storage consumed by the processed data of a log type.
+type LogTypeUsage struct {
+	// The log type is missing for the tables of the log types which aren't known, like removed custom log types
+	LogType   *string `json:"logType,omitempty"`
+	TableName *string `json:"tableName"`
+	// The retention of the log type, if it has a policy
+	RetentionDays *int `json:"retentionDays,omitempty"`
+
+	// The current versions of the objects of the events (panther_logs) and of their rule matches (panther_rule_matches)
+	LogsBytes          *int64 `json:"logsBytes"`
+	LogsObjects        *int64 `json:"logsObjects"`
+	RuleMatchesBytes   *int64 `json:"ruleMatchesBytes"`
+	RuleMatchesObjects *int64 `json:"ruleMatchesObjects"`
+}
+
+// ApplyRetentionInput applies the retention policies: it updates the lifecycle rules of the processed data bucket,
+// which delete the expired objects, and drops the Glue partitions of the log types which have expired.
+// It runs every hour.
+//
+// Example:
+//
+//	{
+//	    "applyRetention": {}
+//	}
+type ApplyRetentionInput struct{}
+
+// ApplyRetentionOutput is the result of applying the retention policies.
+type ApplyRetentionOutput struct {
+	// The lifecycle rules of the retention policies in the bucket
+	LifecycleRules *int `json:"lifecycleRules"`
+	// The Glue partitions dropped from the panther_logs and panther_rule_matches tables
+	DroppedPartitions *int `json:"droppedPartitions"`
+}
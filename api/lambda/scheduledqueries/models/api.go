@@ -0,0 +1,175 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the scheduled-queries-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	PutScheduledQuery    *PutScheduledQueryInput    `json:"putScheduledQuery"`
+	GetScheduledQuery    *GetScheduledQueryInput    `json:"getScheduledQuery"`
+	ListScheduledQueries *ListScheduledQueriesInput `json:"listScheduledQueries"`
+	DeleteScheduledQuery *DeleteScheduledQueryInput `json:"deleteScheduledQuery"`
+	RunScheduledQueries  *RunScheduledQueriesInput  `json:"runScheduledQueries"`
+}
+
+// LogTypePrefix is the prefix of the log type of the results of a scheduled query.
+//
+// The rules with the log type of a query analyze its result rows, like the events of the other log types.
+const LogTypePrefix = "ScheduledQuery."
+
+// LogType is the log type of the results of a scheduled query, e.g. "ScheduledQuery.FailedLoginsPerUser".
+func LogType(queryID string) string {
+	return LogTypePrefix + queryID
+}
+
+// RuleMatchesKeyPrefix is the prefix of the S3 objects the rules engine writes the matches of the result rows to.
+//
+// The log types of the scheduled queries have no Glue tables, their rule matches are not partitioned.
+const RuleMatchesKeyPrefix = "rules/scheduledquery_"
+
+// ResultsKeyPrefix is the prefix of the S3 objects of the result rows, in the processed data bucket
+const ResultsKeyPrefix = "scheduled_queries/"
+
+// The statuses of the last run of a scheduled query
+const (
+	StatusRunning   = "RUNNING"
+	StatusSucceeded = "SUCCEEDED"
+	StatusFailed    = "FAILED"
+)
+
+// PutScheduledQueryInput creates a scheduled query, or updates the one with the given queryId.
+//
+// The query runs in Athena on the cron schedule, in UTC. Each result row is analyzed by the rules
+// with the log type of the query, see LogType, and the matches are alerted on like those of any other rule.
+//
+// Example:
+//
+//	{
+//	    "putScheduledQuery": {
+//	        "queryId": "FailedLoginsPerUser",
+//	        "description": "Users with more than 100 failed console logins in the last hour",
+//	        "sql": "SELECT useridentity.arn, count(1) AS failures FROM aws_cloudtrail WHERE ... GROUP BY 1 HAVING count(1) > 100",
+//	        "schedule": "0 * * * *",
+//	        "enabled": true,
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type PutScheduledQueryInput struct {
+	// Letters, digits and underscores
+	QueryID     *string `json:"queryId" validate:"required,min=1,max=64"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	// Unqualified table names are those of the panther_logs database
+	SQL *string `json:"sql" validate:"required,min=1,max=100000"`
+	// A cron expression, e.g. "*/15 * * * *" to run every 15 minutes
+	Schedule *string `json:"schedule" validate:"required"`
+	Enabled  *bool   `json:"enabled"`
+
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// PutScheduledQueryOutput is the stored scheduled query.
+type PutScheduledQueryOutput = ScheduledQuery
+
+// GetScheduledQueryInput retrieves a scheduled query.
+//
+// Example:
+//
+//	{
+//	    "getScheduledQuery": {
+//	        "queryId": "FailedLoginsPerUser"
+//	    }
+//	}
+type GetScheduledQueryInput struct {
+	QueryID *string `json:"queryId" validate:"required,min=1,max=64"`
+}
+
+// GetScheduledQueryOutput is the scheduled query.
+type GetScheduledQueryOutput = ScheduledQuery
+
+// ListScheduledQueriesInput lists all the scheduled queries.
+//
+// Example:
+//
+//	{
+//	    "listScheduledQueries": {}
+//	}
+type ListScheduledQueriesInput struct{}
+
+// ListScheduledQueriesOutput is all the scheduled queries.
+type ListScheduledQueriesOutput = []*ScheduledQuery
+
+// DeleteScheduledQueryInput deletes a scheduled query, a running execution is left to finish without its results.
+//
+// Example:
+//
+//	{
+//	    "deleteScheduledQuery": {
+//	        "queryId": "FailedLoginsPerUser"
+//	    }
+//	}
+type DeleteScheduledQueryInput struct {
+	QueryID *string `json:"queryId" validate:"required,min=1,max=64"`
+}
+
+// RunScheduledQueriesInput starts the enabled queries which are due and delivers the results of the finished ones
+// to the rules engine. It runs every minute.
+//
+// With a queryId, that query is started right away, even when it's disabled or not due.
+//
+// Example:
+//
+//	{
+//	    "runScheduledQueries": {}
+//	}
+type RunScheduledQueriesInput struct {
+	QueryID *string `json:"queryId,omitempty" validate:"omitempty,min=1,max=64"`
+}
+
+// RunScheduledQueriesOutput is the queries which were started or finished.
+type RunScheduledQueriesOutput = []*ScheduledQuery
+
+// ScheduledQuery is an Athena query run on a schedule, the rows of its results are analyzed by the rules.
+type ScheduledQuery struct {
+	QueryID     *string `json:"queryId"`
+	Description *string `json:"description,omitempty"`
+	SQL         *string `json:"sql"`
+	Schedule    *string `json:"schedule"`
+	Enabled     *bool   `json:"enabled"`
+	LogType     *string `json:"logType"`
+
+	NextRunTime *time.Time `json:"nextRunTime,omitempty"`
+	// Set while the query runs in Athena
+	QueryExecutionID *string    `json:"queryExecutionId,omitempty"`
+	LastRunTime      *time.Time `json:"lastRunTime,omitempty"`
+	LastRunStatus    *string    `json:"lastRunStatus,omitempty"`
+	LastRunError     *string    `json:"lastRunError,omitempty"`
+	LastRunRows      *int       `json:"lastRunRows,omitempty"`
+
+	CreatedAtTime  *time.Time `json:"createdAtTime"`
+	CreatedBy      *string    `json:"createdBy"`
+	LastModified   *time.Time `json:"lastModified"`
+	LastModifiedBy *string    `json:"lastModifiedBy"`
+}
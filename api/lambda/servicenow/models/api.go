@@ -0,0 +1,40 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// LambdaInput is the direct invocation event of the servicenow-sync Lambda function.
+//
+// The function also handles the requests of the ServiceNow callback API.
+type LambdaInput struct {
+	SyncAlertStatus *SyncAlertStatusInput `json:"syncAlertStatus"`
+}
+
+// SyncAlertStatusInput closes the ServiceNow incidents of an alert which was resolved in Panther.
+//
+// Example:
+// {
+//     "syncAlertStatus": {
+//         "alertId": "7d1c5854f3ea491c8a520aa0d58cb456",
+//         "status": "RESOLVED"
+//     }
+// }
+type SyncAlertStatusInput struct {
+	AlertID *string `json:"alertId" validate:"required"`
+	Status  *string `json:"status" validate:"required,oneof=RESOLVED FALSE_POSITIVE"`
+}
@@ -18,23 +18,95 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "time"
+import (
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
 
 // LambdaInput is the collection of all possible args to the Lambda function.
 type LambdaInput struct {
-	CheckIntegration *CheckIntegrationInput `json:"integrationHealthCheck"`
+	Caller *genericapi.Caller `json:"caller"`
+
+	CheckIntegration   *CheckIntegrationInput   `json:"integrationHealthCheck"`
+	CheckKmsKeyAliases *CheckKmsKeyAliasesInput `json:"checkKmsKeyAliases"`
+
+	CheckOnboardingReadiness *CheckOnboardingReadinessInput `json:"checkOnboardingReadiness"`
+	EstimateScanCost         *EstimateScanCostInput         `json:"estimateScanCost"`
+	PreviewMatchedObjects    *PreviewMatchedObjectsInput    `json:"previewMatchedObjects"`
+	RunPipelineSelfTest      *RunPipelineSelfTestInput      `json:"runPipelineSelfTest"`
+	GetPipelineSelfTest      *GetPipelineSelfTestInput      `json:"getPipelineSelfTest"`
+
+	PutIntegration            *PutIntegrationInput            `json:"putIntegration"`
+	CloneIntegration          *CloneIntegrationInput          `json:"cloneIntegration"`
+	CreateOrUpdateIntegration *CreateOrUpdateIntegrationInput `json:"createOrUpdateIntegration"`
+	ApplyAccountManifest      *ApplyAccountManifestInput      `json:"applyAccountManifest"`
 
-	PutIntegration *PutIntegrationInput `json:"putIntegration"`
+	GetIntegration           *GetIntegrationInput           `json:"getIntegration"`
+	GetScanErrorSamples      *GetScanErrorSamplesInput      `json:"getScanErrorSamples"`
+	GetEffectiveConfig       *GetEffectiveConfigInput       `json:"getEffectiveConfig"`
+	GetTimeToHealthyEstimate *GetTimeToHealthyEstimateInput `json:"getTimeToHealthyEstimate"`
+	RecommendScanInterval    *RecommendScanIntervalInput    `json:"recommendScanInterval"`
+	ListIntegrations         *ListIntegrationsInput         `json:"getEnabledIntegrations"`
+	ListIntegrationsPages    *ListIntegrationsPagesInput    `json:"listIntegrationsPages"`
+	ListIntegrationsPage     *ListIntegrationsPageInput     `json:"listIntegrationsPage"`
 
-	ListIntegrations *ListIntegrationsInput `json:"getEnabledIntegrations"`
+	GetIntegrationsDueForScan *GetIntegrationsDueForScanInput `json:"getIntegrationsDueForScan"`
+	GetScanSchedulePreview    *GetScanSchedulePreviewInput    `json:"getScanSchedulePreview"`
+	ListChangedIntegrations   *ListChangedIntegrationsInput   `json:"listChangedIntegrations"`
+	ExportAllIntegrations     *ExportAllIntegrationsInput     `json:"exportAllIntegrations"`
 
-	GetIntegrationTemplate *GetIntegrationTemplateInput `json:"getIntegrationTemplate"`
+	ListPendingRetries     *ListPendingRetriesInput     `json:"listPendingRetries"`
+	RetryFailedSideEffects *RetryFailedSideEffectsInput `json:"retryFailedSideEffects"`
+	RequeueFailedObjects   *RequeueFailedObjectsInput   `json:"requeueFailedObjects"`
+
+	GetIntegrationChangeHistory *GetIntegrationChangeHistoryInput `json:"getIntegrationChangeHistory"`
+	GetIntegrationAuditLog      *GetIntegrationAuditLogInput      `json:"getIntegrationAuditLog"`
+	GetIntegrationHistory       *GetIntegrationHistoryInput       `json:"getIntegrationHistory"`
+	ListScanHistory             *ListScanHistoryInput             `json:"listScanHistory"`
+	CompactIntegrationHistory   *CompactIntegrationHistoryInput   `json:"compactIntegrationHistory"`
+
+	GetIntegrationTemplate       *GetIntegrationTemplateInput       `json:"getIntegrationTemplate"`
+	GetIntegrationPolicyDocument *GetIntegrationPolicyDocumentInput `json:"getIntegrationPolicyDocument"`
+	ListIntegrationTypes         *ListIntegrationTypesInput         `json:"listIntegrationTypes"`
 
 	UpdateIntegrationLastScanEnd   *UpdateIntegrationLastScanEndInput   `json:"updateIntegrationLastScanEnd"`
 	UpdateIntegrationLastScanStart *UpdateIntegrationLastScanStartInput `json:"updateIntegrationLastScanStart"`
+	TriggerScan                    *TriggerScanInput                    `json:"triggerScan"`
+	RotateHTTPIngestKey            *RotateHTTPIngestKeyInput            `json:"rotateHttpIngestKey"`
+	UpdateKinesisCheckpoints       *UpdateKinesisCheckpointsInput       `json:"updateKinesisCheckpoints"`
+	UpdateOktaCursor               *UpdateOktaCursorInput               `json:"updateOktaCursor"`
 	UpdateIntegrationSettings      *UpdateIntegrationSettingsInput      `json:"updateIntegrationSettings"`
+	TransactUpdateIntegrations     *TransactUpdateIntegrationsInput     `json:"transactUpdateIntegrations"`
+	UpdateIntegrationsBatch        *UpdateIntegrationsBatchInput        `json:"updateIntegrationsBatch"`
+	PreviewIntegrationChangeSet    *PreviewIntegrationChangeSetInput    `json:"previewIntegrationChangeSet"`
+	CompareIntegrations            *CompareIntegrationsInput            `json:"compareIntegrations"`
+	BulkSetScanInterval            *BulkSetScanIntervalInput            `json:"bulkSetScanInterval"`
+	ApplyTagPolicy                 *ApplyTagPolicyInput                 `json:"applyTagPolicy"`
+	ResetIntegrationBookmark       *ResetIntegrationBookmarkInput       `json:"resetIntegrationBookmark"`
+	RemoveBuckets                  *RemoveBucketsInput                  `json:"removeBuckets"`
+	ReplaceBuckets                 *ReplaceBucketsInput                 `json:"replaceBuckets"`
+	MigrateToPrefixConfig          *MigrateToPrefixConfigInput          `json:"migrateToPrefixConfig"`
+	RemoveKmsKeys                  *RemoveKmsKeysInput                  `json:"removeKmsKeys"`
+
+	UpdateIntegrationsLastScanStart *UpdateIntegrationsLastScanStartInput `json:"updateIntegrationsLastScanStart"`
 
-	DeleteIntegration *DeleteIntegrationInput `json:"deleteIntegration"`
+	DeleteIntegration        *DeleteIntegrationInput        `json:"deleteIntegration"`
+	RestoreIntegration       *RestoreIntegrationInput       `json:"restoreIntegration"`
+	PurgeDeletedIntegrations *PurgeDeletedIntegrationsInput `json:"purgeDeletedIntegrations"`
+
+	GetAccountHealthSummary     *GetAccountHealthSummaryInput     `json:"getAccountHealthSummary"`
+	ListStaleHealthIntegrations *ListStaleHealthIntegrationsInput `json:"listStaleHealthIntegrations"`
+	RecheckIntegrationHealth    *RecheckIntegrationHealthInput    `json:"recheckIntegrationHealth"`
+	ListExpiringCredentials     *ListExpiringCredentialsInput     `json:"listExpiringCredentials"`
+	VerifyTrustForAccount       *VerifyTrustForAccountInput       `json:"verifyTrustForAccount"`
+	ListSilentIntegrations      *ListSilentIntegrationsInput      `json:"listSilentIntegrations"`
+	CheckIngestionAlarms        *CheckIngestionAlarmsInput        `json:"checkIngestionAlarms"`
+	RecheckKmsKeyAliases        *RecheckKmsKeyAliasesInput        `json:"recheckKmsKeyAliases"`
+	SyncOrganizations           *SyncOrganizationsInput           `json:"syncOrganizations"`
+
+	ExportAuditLog       *ExportAuditLogInput       `json:"exportAuditLog"`
+	GenerateHealthReport *GenerateHealthReportInput `json:"generateHealthReport"`
 }
 
 //
@@ -43,8 +115,9 @@ type LambdaInput struct {
 
 // CheckIntegrationInput is used to check the health of a potential configuration.
 type CheckIntegrationInput struct {
-	AWSAccountID    *string `genericapi:"redact" json:"awsAccountId" validate:"required,len=12,numeric"`
-	IntegrationType *string `json:"integrationType" validate:"required,oneof=aws-scan aws-s3"`
+	// Required by the AWS integration types
+	AWSAccountID    *string `genericapi:"redact" json:"awsAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+	IntegrationType *string `json:"integrationType" validate:"required,integrationType"`
 
 	// Checks for cloudsec integrations
 	EnableCWESetup    *bool `json:"enableCWESetup"`
@@ -53,6 +126,138 @@ type CheckIntegrationInput struct {
 	// Checks for log analysis integrations
 	S3Buckets []*string `json:"s3Buckets"`
 	KmsKeys   []*string `json:"kmsKeys"`
+
+	// Region of each bucket, buckets without one are checked in the region of Panther
+	S3BucketRegions map[string]*string `json:"s3BucketRegions"`
+
+	// Sub-checks whose result is informational, they don't affect the health of the integration
+	DisabledChecks []*string `json:"disabledChecks,omitempty" validate:"omitempty,dive,required,disableableHealthCheck"`
+
+	// KMS grants created for the log processing role by key ARN, each must still exist
+	KmsGrants map[string]*string `json:"kmsGrants,omitempty"`
+
+	// The buckets receive a CloudTrail organization trail, the account must be a member of the organization
+	IsOrgTrail          *bool   `json:"isOrgTrail,omitempty"`
+	ManagementAccountID *string `genericapi:"redact" json:"managementAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+
+	// Checks for Kinesis integrations, the consumer is only checked once the integration registered it
+	StreamARN          *string `json:"streamArn,omitempty" validate:"omitempty,kinesisStreamArn"`
+	KinesisConsumerARN *string `json:"kinesisConsumerArn,omitempty" validate:"omitempty,min=1"`
+
+	// Checks for Azure integrations: the service principal must sign in and read the subscription
+	AzureTenantID        *string `genericapi:"redact" json:"azureTenantId,omitempty" validate:"omitempty,uuid"`
+	AzureSubscriptionID  *string `genericapi:"redact" json:"azureSubscriptionId,omitempty" validate:"omitempty,uuid"`
+	AzureClientID        *string `json:"azureClientId,omitempty" validate:"omitempty,uuid"`
+	AzureClientSecretArn *string `json:"azureClientSecretArn,omitempty" validate:"omitempty,secretArn"`
+
+	// Checks for GCP integrations: the service account must sign in and read the bucket or the subscription
+	GCPProjectID               *string `genericapi:"redact" json:"gcpProjectId,omitempty" validate:"omitempty,gcpProjectId"`
+	GCPServiceAccountSecretArn *string `json:"gcpServiceAccountSecretArn,omitempty" validate:"omitempty,secretArn"`
+	GCSBucket                  *string `json:"gcsBucket,omitempty" validate:"omitempty,min=3,max=222"`
+	PubSubSubscription         *string `json:"pubSubSubscription,omitempty" validate:"omitempty,pubSubSubscription"`
+
+	// Checks for SQS integrations: the queue producers send the log events to must be reachable
+	SQSQueueArn *string `json:"sqsQueueArn,omitempty" validate:"omitempty,sqsQueueArn"`
+
+	// Checks for HTTP ingest integrations: the API key the log events are posted with must be enabled
+	HTTPIngestAPIKeyID *string `json:"httpIngestApiKeyId,omitempty" validate:"omitempty,min=1"`
+
+	// Checks for Okta integrations: the API token must read the System Log of the organization. The token is read
+	// from the secret of the integration, unless the credentials are given
+	OktaDomain           *string            `json:"oktaDomain,omitempty" validate:"omitempty,fqdn"`
+	Credentials          map[string]*string `genericapi:"redact" json:"credentials,omitempty" validate:"omitempty,credentials"`
+	CredentialsSecretArn *string            `json:"credentialsSecretArn,omitempty" validate:"omitempty,min=1"`
+
+	// The dead letter queue the log processing role moves the failed notifications to
+	DeadLetterQueueArn *string `json:"deadLetterQueueArn,omitempty" validate:"omitempty,sqsQueueArn"`
+
+	// The format of the archives, a sampled object of each bucket must be one which can be expanded
+	ArchiveFormat *string `json:"archiveFormat,omitempty" validate:"omitempty,archiveFormat"`
+
+	// Roles assumed in order instead of the log processing role, the last one must be able to read the logs
+	RoleChain []*string `json:"roleChain,omitempty" validate:"omitempty,min=1,max=5,dive,required,roleArn"`
+
+	// The duration of the sessions of the role the logs are read with, it must be within the maximum of the role
+	SessionDurationSeconds *int `json:"sessionDurationSeconds,omitempty" validate:"omitempty,min=900,max=43200"`
+}
+
+//
+// CheckKmsKeyAliases: Used to detect KMS aliases which no longer point to the pinned key
+//
+
+// CheckKmsKeyAliasesInput is used to re-resolve the KMS aliases of an integration.
+type CheckKmsKeyAliasesInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+}
+
+//
+// CheckOnboardingReadiness: Used by the setup wizard before an integration is saved
+//
+
+// CheckOnboardingReadinessInput describes the integration a customer intends to create.
+type CheckOnboardingReadinessInput struct {
+	AWSAccountID    *string `genericapi:"redact" json:"awsAccountId" validate:"required,len=12,numeric"`
+	IntegrationType *string `json:"integrationType" validate:"required,integrationType"`
+
+	EnableCWESetup    *bool              `json:"enableCWESetup"`
+	EnableRemediation *bool              `json:"enableRemediation"`
+	S3Buckets         []*string          `json:"s3Buckets"`
+	KmsKeys           []*string          `json:"kmsKeys"`
+	S3BucketRegions   map[string]*string `json:"s3BucketRegions" validate:"omitempty,dive,keys,required,endkeys,required,awsRegion"`
+}
+
+//
+// EstimateScanCost: Used by the UI before enabling frequent scans
+//
+
+// EstimateScanCostInput is a proposed scan configuration for a log analysis integration.
+type EstimateScanCostInput struct {
+	AWSAccountID     *string   `genericapi:"redact" json:"awsAccountId" validate:"required,len=12,numeric"`
+	S3Buckets        []*string `json:"s3Buckets" validate:"required,min=1,dive,required"`
+	ScanIntervalMins *int      `json:"scanIntervalMins" validate:"required,scanInterval"`
+}
+
+//
+// PreviewMatchedObjects: Used by the UI to confirm the bucket prefixes before saving them
+//
+
+// PreviewMatchedObjectsInput lists the objects matching the buckets of an integration or a proposed configuration.
+//
+// Bucket entries are a bucket name optionally followed by a key pattern, where * matches any characters,
+// e.g. "my-bucket/logs/*.gz".
+type PreviewMatchedObjectsInput struct {
+	IntegrationID *string   `json:"integrationId,omitempty" validate:"omitempty,uuid4"`
+	AWSAccountID  *string   `genericapi:"redact" json:"awsAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+	S3Buckets     []*string `json:"s3Buckets,omitempty" validate:"omitempty,dive,required"`
+
+	// The key patterns of a proposed configuration, those of the integration are used with an integrationId
+	IncludePatterns []*string `json:"includePatterns,omitempty" validate:"omitempty,max=20,dive,required,max=256,keyPattern"`
+	ExcludePatterns []*string `json:"excludePatterns,omitempty" validate:"omitempty,max=20,dive,required,max=256,keyPattern"`
+	// The backfill start of a proposed configuration, the objects last modified before it are not listed
+	BackfillStartTime *time.Time `json:"backfillStartTime,omitempty"`
+
+	PageSize          *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+//
+// RunPipelineSelfTest, GetPipelineSelfTest: Used by the UI to confirm data flows from a log integration into Panther
+//
+
+// RunPipelineSelfTestInput starts the end-to-end self test of a log analysis integration.
+//
+// The self test runs in the background, its TestID is polled with GetPipelineSelfTest until it is completed.
+type RunPipelineSelfTestInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+
+	// How long to wait for the synthetic log to be processed, 5 minutes by default
+	TimeoutSeconds *int `json:"timeoutSeconds,omitempty" validate:"omitempty,min=10,max=3600"`
+}
+
+// GetPipelineSelfTestInput returns the progress of a self test started with RunPipelineSelfTest.
+type GetPipelineSelfTestInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	TestID        *string `json:"testId" validate:"required,uuid4"`
 }
 
 //
@@ -63,32 +268,589 @@ type CheckIntegrationInput struct {
 type PutIntegrationInput struct {
 	Integrations  []*PutIntegrationSettings `json:"integrations" validate:"required,dive"`
 	SkipScanQueue *bool                     `json:"skipScanQueue"`
+
+	// Run the validation and the health checks without writing anything, see DryRunReport
+	DryRun *bool `json:"dryRun,omitempty"`
 }
 
 // PutIntegrationSettings are all the settings for the new integration.
 type PutIntegrationSettings struct {
-	AWSAccountID       *string   `genericapi:"redact" json:"awsAccountId" validate:"required,len=12,numeric"`
+	// Required by the AWS integration types
+	AWSAccountID       *string   `genericapi:"redact" json:"awsAccountId,omitempty" validate:"omitempty,len=12,numeric"`
 	IntegrationLabel   *string   `json:"integrationLabel,omitempty" validate:"omitempty,min=1"`
-	IntegrationType    *string   `json:"integrationType" validate:"required,oneof=aws-scan aws-s3"`
+	Description        *string   `json:"description,omitempty" validate:"omitempty,max=1000"`
+	IntegrationType    *string   `json:"integrationType" validate:"required,integrationType"`
 	ScanEnabled        *bool     `json:"scanEnabled,omitempty"`
 	CWEEnabled         *bool     `json:"cweEnabled,omitempty"`
 	RemediationEnabled *bool     `json:"remediationEnabled,omitempty"`
-	ScanIntervalMins   *int      `json:"scanIntervalMins,omitempty" validate:"omitempty,oneof=60 180 360 720 1440"`
+	ScanIntervalMins   *int      `json:"scanIntervalMins,omitempty" validate:"omitempty,scanInterval"`
 	UserID             *string   `json:"userId" validate:"required,uuid4"`
 	S3Buckets          []*string `json:"s3Buckets"`
 	KmsKeys            []*string `json:"kmsKeys"`
+
+	// Scan more often at first to backfill the integration, then settle into ScanIntervalMins
+	ScanRampUp *ScanRampUp `json:"scanRampUp,omitempty"`
+
+	// Region of each bucket by bucket name, for buckets outside the region of Panther
+	S3BucketRegions map[string]*string `json:"s3BucketRegions" validate:"omitempty,dive,keys,required,endkeys,required,awsRegion"`
+
+	// The objects ingested from each bucket by bucket name, a bucket without a filter is ingested whole
+	S3ObjectFilters map[string]*S3ObjectFilter `json:"s3ObjectFilters,omitempty" validate:"omitempty,dive,keys,required,endkeys,required"`
+
+	// By default, the label must be unique among the integrations of the same account
+	AllowDuplicateLabel *bool `json:"allowDuplicateLabel,omitempty"`
+
+	// Save the integration even if some buckets or keys fail the health check, roles must always pass
+	AllowPartialHealth *bool `json:"allowPartialHealth,omitempty"`
+
+	// Create a KMS grant for decrypt on each key for the log processing role, instead of editing the key policies
+	CreateKmsGrants *bool `json:"createKmsGrants,omitempty"`
+
+	// Add the KMS keys the buckets are encrypted with by default to the kmsKeys, before the health check
+	DiscoverKmsKeys *bool `json:"discoverKmsKeys,omitempty"`
+
+	// The buckets receive a CloudTrail organization trail of the organization managed by ManagementAccountID
+	IsOrgTrail          *bool   `json:"isOrgTrail,omitempty"`
+	ManagementAccountID *string `genericapi:"redact" json:"managementAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+
+	// The Kinesis stream the logs are read from, and where reading starts: TRIM_HORIZON (default) or LATEST
+	StreamARN         *string `json:"streamArn,omitempty" validate:"omitempty,kinesisStreamArn"`
+	ShardIteratorType *string `json:"shardIteratorType,omitempty" validate:"omitempty,oneof=TRIM_HORIZON LATEST"`
+
+	// The Azure subscription scanned with the service principal of the client ID. Its client secret is read from
+	// the Secrets Manager secret, which must be named panther-azure-*
+	AzureTenantID        *string `genericapi:"redact" json:"azureTenantId,omitempty" validate:"omitempty,uuid"`
+	AzureSubscriptionID  *string `genericapi:"redact" json:"azureSubscriptionId,omitempty" validate:"omitempty,uuid"`
+	AzureClientID        *string `json:"azureClientId,omitempty" validate:"omitempty,uuid"`
+	AzureClientSecretArn *string `json:"azureClientSecretArn,omitempty" validate:"omitempty,secretArn"`
+
+	// The GCP project whose audit logs are read from either a GCS bucket or a Pub/Sub subscription, with the service
+	// account whose JSON key is in the Secrets Manager secret. The secret must be named panther-gcp-*
+	GCPProjectID               *string `genericapi:"redact" json:"gcpProjectId,omitempty" validate:"omitempty,gcpProjectId"`
+	GCPServiceAccountSecretArn *string `json:"gcpServiceAccountSecretArn,omitempty" validate:"omitempty,secretArn"`
+	GCSBucket                  *string `json:"gcsBucket,omitempty" validate:"omitempty,min=3,max=222"`
+	PubSubSubscription         *string `json:"pubSubSubscription,omitempty" validate:"omitempty,pubSubSubscription"`
+
+	// The SQS queue the producers of the AWS account send JSON log events to. Panther creates one when it's not
+	// set, an existing queue must be in the region of Panther
+	SQSQueueArn *string `json:"sqsQueueArn,omitempty" validate:"omitempty,sqsQueueArn"`
+
+	// The domain of the Okta organization whose System Log is pulled, such as example.okta.com.
+	// The API token it is pulled with is the apiToken credential
+	OktaDomain *string `json:"oktaDomain,omitempty" validate:"omitempty,fqdn"`
+
+	// The networks the devices of a syslog integration send their messages from, such as 10.0.0.0/16. The syslog
+	// collector attributes a message to the integration with the most specific network of its sender
+	SyslogAllowedCIDRs []*string `json:"syslogAllowedCidrs,omitempty" validate:"omitempty,max=100,dive,required,cidr"`
+
+	// The member accounts of an aws-organization integration which are not onboarded. The management account
+	// never is, the StackSet of service-managed permissions doesn't deploy to it
+	OrganizationExcludedAccounts []*string `json:"organizationExcludedAccounts,omitempty" validate:"omitempty,max=1000,dive,len=12,numeric"`
+
+	// The aws-organization integration an aws-scan integration is onboarded by, it's only set by SyncOrganizations
+	OrganizationIntegrationID *string `json:"-"`
+
+	// The credentials the logs of a pull source are read with, e.g. an API token, by name. They are stored in a
+	// Secrets Manager secret of the integration and never returned. See IntegrationTypeCapabilities.CredentialFields
+	Credentials map[string]*string `genericapi:"redact" json:"credentials,omitempty" validate:"omitempty,credentials"`
+
+	// Objects last modified before it are skipped by the first scan, instead of backfilling the whole buckets.
+	// It can't be in the future
+	BackfillStartTime *time.Time `json:"backfillStartTime,omitempty"`
+
+	// Roles assumed in order to reach the logs instead of the log processing role, e.g. a jump role of the
+	// customer followed by the role which can read the logs
+	RoleChain []*string `json:"roleChain,omitempty" validate:"omitempty,min=1,max=5,dive,required,roleArn"`
+
+	// The duration of the STS sessions of the role the logs are read with, from 15 minutes to 12 hours and within
+	// the maximum session duration of the role. Unset uses sessions of an hour
+	SessionDurationSeconds *int `json:"sessionDurationSeconds,omitempty" validate:"omitempty,min=900,max=43200"`
+
+	// Limits protecting the pipeline from a single noisy integration
+	MaxConcurrentObjects *int `json:"maxConcurrentObjects,omitempty" validate:"omitempty,min=1,max=100"`
+	MaxObjectsPerScan    *int `json:"maxObjectsPerScan,omitempty" validate:"omitempty,min=1,max=1000000"`
+
+	// The order in which the objects are ingested: none (default), per-prefix or strict, and how many objects
+	// are ingested at once. Strict ordering ingests a single object at a time
+	OrderingMode   *string `json:"orderingMode,omitempty" validate:"omitempty,orderingMode"`
+	MaxParallelism *int    `json:"maxParallelism,omitempty" validate:"omitempty,min=1,max=100"`
+
+	// Duplicate alerts from this source are suppressed within this window, up to a week
+	DedupWindowMinutes *int `json:"dedupWindowMinutes,omitempty" validate:"omitempty,min=1,max=10080"`
+
+	// Largest log record accepted, up to 10 MiB, and what happens to the larger ones: drop (default), truncate or error
+	MaxRecordBytes        *int    `json:"maxRecordBytes,omitempty" validate:"omitempty,min=1,max=10485760"`
+	OversizedRecordPolicy *string `json:"oversizedRecordPolicy,omitempty" validate:"omitempty,oversizedRecordPolicy"`
+
+	// Fraction of the log records ingested, the others are dropped at random. Unset ingests every record
+	SampleRate *float64 `json:"sampleRate,omitempty" validate:"omitempty,min=0,max=1"`
+
+	// Fraction of the objects a scan may fail on before an alert is sent to the notification targets
+	ErrorRateThreshold *float64 `json:"errorRateThreshold,omitempty" validate:"omitempty,min=0,max=1"`
+
+	// Alarms of the ingestion of a log analysis integration, from the metrics of the log processor: an alert is sent
+	// when no events are delivered for the threshold, from 5 minutes to a week, or when a larger fraction of the
+	// log lines fails to classify than the threshold
+	SilenceThresholdMinutes *int     `json:"silenceThresholdMinutes,omitempty" validate:"omitempty,min=5,max=10080"`
+	EventErrorRateThreshold *float64 `json:"eventErrorRateThreshold,omitempty" validate:"omitempty,min=0,max=1"`
+
+	// How the timestamp of the events is derived for a log layout Panther doesn't know, see TimeExtraction.Validate
+	TimeExtraction *TimeExtraction `json:"timeExtraction,omitempty" validate:"omitempty"`
+
+	// How far from the time they are processed the events can be timestamped, for sources with clock drift
+	TimestampSkew *TimestampSkew `json:"timestampSkew,omitempty" validate:"omitempty"`
+
+	// Where the notifications which failed to be processed are moved, for log analysis integrations
+	DeadLetterQueue *DeadLetterQueue `json:"deadLetterQueue,omitempty" validate:"omitempty"`
+
+	// Format of the archives the log analysis integration receives, expanded before ingestion: zip, tar or tar.gz
+	ArchiveFormat *string `json:"archiveFormat,omitempty" validate:"omitempty,archiveFormat"`
+
+	// Sensitivity of the data of the source, honored by the downstream access controls
+	DataClassification *string `json:"dataClassification,omitempty" validate:"omitempty,dataClassification"`
+
+	// The region of the deployment which processes the integration, one of the regions Panther is deployed in
+	ProcessingRegion *string `json:"processingRegion,omitempty"`
+
+	// Regular expressions of the object keys to ingest: keys matching an exclude pattern are skipped, and
+	// when there are include patterns only the keys matching one of them are ingested
+	IncludePatterns []*string `json:"includePatterns,omitempty" validate:"omitempty,max=20,dive,required,max=256,keyPattern"`
+	ExcludePatterns []*string `json:"excludePatterns,omitempty" validate:"omitempty,max=20,dive,required,max=256,keyPattern"`
+
+	// Periods during which no scans run
+	BlackoutWindows []*BlackoutWindow `json:"blackoutWindows,omitempty" validate:"omitempty,max=20,dive,required"`
+
+	// A cron expression of when the integration is scanned in UTC, e.g. "0 2 * * mon-fri" on weekdays at 02:00.
+	// It replaces scanIntervalMins, and scans at most once an hour
+	ScanSchedule *string `json:"scanSchedule,omitempty" validate:"omitempty,cronSchedule"`
+
+	// The regions aws-scan integrations are scanned in, in the partition of Panther: only the enabled regions when
+	// there are some, minus the excluded regions
+	EnabledRegions  []*string `json:"enabledRegions,omitempty" validate:"omitempty,max=50,dive,required,awsRegion"`
+	ExcludedRegions []*string `json:"excludedRegions,omitempty" validate:"omitempty,max=50,dive,required,awsRegion"`
+
+	// The resource types aws-scan integrations are scanned for, e.g. "AWS.IAM.Role": only the allowed types when
+	// there are some, minus the denied types
+	ResourceTypeAllowList []*string `json:"resourceTypeAllowList,omitempty" validate:"omitempty,max=100,dive,required"`
+	ResourceTypeDenyList  []*string `json:"resourceTypeDenyList,omitempty" validate:"omitempty,max=100,dive,required"`
+
+	// Fields of the logs masked before they are stored
+	RedactionRules []*RedactionRule `json:"redactionRules,omitempty" validate:"omitempty,max=50,dive,required"`
+
+	// Health sub-checks which are still run but only reported as informational, e.g. "s3Buckets"
+	DisabledChecks []*string `json:"disabledChecks,omitempty" validate:"omitempty,dive,required,disableableHealthCheck"`
+
+	// Experimental ingestion modes the integration is opted into, e.g. "parserV2"
+	FeatureFlags map[string]bool `json:"featureFlags" validate:"omitempty,dive,keys,featureFlag,endkeys"`
+
+	// IDs of existing integrations this one depends on, they can't be deleted while it exists
+	DependsOn []*string `json:"dependsOn,omitempty" validate:"omitempty,dive,required,uuid4"`
+
+	// IDs of the alert outputs notified when the health of the integration changes, instead of the default outputs
+	NotificationTargets []*string `json:"notificationTargets,omitempty" validate:"omitempty,dive,required,uuid4"`
+
+	// Names of the configured enrichment sources the events of the integration are enriched with
+	EnrichmentSources []*string `json:"enrichmentSources,omitempty" validate:"omitempty,dive,required,max=128"`
+
+	// Tags of the integration by key, e.g. "env": "dev", which select it for tag policies
+	Tags map[string]*string `json:"tags" validate:"omitempty,max=50,dive,keys,required,max=128,endkeys,required,max=256"`
+
+	// Severity of the alerts on the events of the integration by matcher, e.g. "AWS.CloudTrail.*": "HIGH"
+	SeverityOverrides map[string]*string `json:"severityOverrides" validate:"omitempty,max=50,dive,keys,required,max=128,endkeys,severity"`
+
+	// The https endpoint a signed summary of each completed scan is posted to
+	ScanCompleteCallbackURL *string `json:"scanCompleteCallbackUrl,omitempty" validate:"omitempty,max=2048,url,startswith=https://"`
+}
+
+//
+// CreateOrUpdateIntegration: Used by infrastructure as code tooling
+//
+
+// CreateOrUpdateIntegrationInput creates the integration with the given identity, or updates it if it exists.
+//
+// The identity is the account and the type of the settings along with an ExternalKey chosen by the client,
+// e.g. the path of the integration in a GitOps repository.
+type CreateOrUpdateIntegrationInput struct {
+	ExternalKey *string                 `json:"externalKey" validate:"required,min=1,max=256"`
+	Settings    *PutIntegrationSettings `json:"settings" validate:"required"`
+}
+
+//
+// CloneIntegration: Used by the UI
+//
+
+// CloneIntegrationInput is used to create a new integration from the settings of an existing one.
+//
+// Any override which is set replaces the corresponding setting copied from the source integration.
+type CloneIntegrationInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	UserID        *string `json:"userId" validate:"required,uuid4"`
+
+	AWSAccountID       *string   `genericapi:"redact" json:"awsAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+	IntegrationLabel   *string   `json:"integrationLabel,omitempty" validate:"omitempty,min=1"`
+	ScanEnabled        *bool     `json:"scanEnabled,omitempty"`
+	CWEEnabled         *bool     `json:"cweEnabled,omitempty"`
+	RemediationEnabled *bool     `json:"remediationEnabled,omitempty"`
+	ScanIntervalMins   *int      `json:"scanIntervalMins,omitempty" validate:"omitempty,scanInterval"`
+	S3Buckets          []*string `json:"s3Buckets,omitempty"`
+	KmsKeys            []*string `json:"kmsKeys,omitempty"`
+
+	MaxConcurrentObjects *int `json:"maxConcurrentObjects,omitempty" validate:"omitempty,min=1,max=100"`
+	MaxObjectsPerScan    *int `json:"maxObjectsPerScan,omitempty" validate:"omitempty,min=1,max=1000000"`
+
+	// Duplicate alerts from this source are suppressed within this window, up to a week
+	DedupWindowMinutes *int `json:"dedupWindowMinutes,omitempty" validate:"omitempty,min=1,max=10080"`
+
+	// Largest log record accepted, up to 10 MiB, and what happens to the larger ones: drop (default), truncate or error
+	MaxRecordBytes        *int    `json:"maxRecordBytes,omitempty" validate:"omitempty,min=1,max=10485760"`
+	OversizedRecordPolicy *string `json:"oversizedRecordPolicy,omitempty" validate:"omitempty,oversizedRecordPolicy"`
+
+	// Periods during which no scans run
+	BlackoutWindows []*BlackoutWindow `json:"blackoutWindows,omitempty" validate:"omitempty,max=20,dive,required"`
+
+	// A cron expression of when the integration is scanned in UTC, see PutIntegrationSettings
+	ScanSchedule *string `json:"scanSchedule,omitempty" validate:"omitempty,cronSchedule"`
+
+	// Fields of the logs masked before they are stored
+	RedactionRules []*RedactionRule `json:"redactionRules,omitempty" validate:"omitempty,max=50,dive,required"`
+
+	// Health sub-checks which are still run but only reported as informational, e.g. "s3Buckets"
+	DisabledChecks []*string `json:"disabledChecks,omitempty" validate:"omitempty,dive,required,disableableHealthCheck"`
+
+	AllowDuplicateLabel *bool `json:"allowDuplicateLabel,omitempty"`
+}
+
+//
+// ApplyAccountManifest: Used to onboard all the integrations of an account at once, e.g. by an MSSP
+//
+
+// ApplyAccountManifestInput converges the integrations of an AWS account to a manifest.
+//
+// Integrations are matched to the existing ones of the account by label: missing ones are created, existing
+// ones are updated, and the integrations of the account which are not part of the manifest are deleted.
+// Settings which are not set in the manifest are left unchanged on existing integrations.
+type ApplyAccountManifestInput struct {
+	AWSAccountID *string `genericapi:"redact" json:"awsAccountId" validate:"required,len=12,numeric"`
+	UserID       *string `json:"userId" validate:"required,uuid4"`
+
+	// Every integration must be for the account of the manifest and have a label
+	Integrations []*PutIntegrationSettings `json:"integrations" validate:"required,min=1,dive,required"`
+
+	// Only plan the changes and health check the integrations, nothing is written
+	DryRun *bool `json:"dryRun,omitempty"`
+}
+
+//
+// GetIntegration: Used by the UI
+//
+
+// GetIntegrationInput is used to get a single integration.
+//
+// If IfNoneMatch is the current ETag of the integration, it's not returned again.
+type GetIntegrationInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	IfNoneMatch   *string `json:"ifNoneMatch"`
+}
+
+// GetScanErrorSamplesInput is used to get the records which recent scans of an integration failed on.
+type GetScanErrorSamplesInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+}
+
+// GetEffectiveConfigInput resolves the settings of an integration at the given time, now by default.
+type GetEffectiveConfigInput struct {
+	IntegrationID *string    `json:"integrationId" validate:"required,uuid4"`
+	Now           *time.Time `json:"now,omitempty"`
+}
+
+// GetTimeToHealthyEstimateInput estimates when data flows again from a scheduled integration, from the given time,
+// now by default.
+type GetTimeToHealthyEstimateInput struct {
+	IntegrationID *string    `json:"integrationId" validate:"required,uuid4"`
+	Now           *time.Time `json:"now,omitempty"`
+}
+
+// RecommendScanIntervalInput asks for the scan interval suited to the observed volume of a scheduled integration.
+type RecommendScanIntervalInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+}
+
+// GetIntegrationChangeHistoryInput pages through the changes made to an integration, most recent first.
+//
+// The history is kept after the integration is deleted.
+type GetIntegrationChangeHistoryInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+
+	PageSize          *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+// GetIntegrationAuditLogInput pages through the audit log of an integration, most recent first: who created,
+// changed, deleted or restored it, when, and the settings before and after each change.
+type GetIntegrationAuditLogInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	// Only the changes made from the start time and before the end time, when they are set
+	StartTime *time.Time `json:"startTime,omitempty"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+
+	PageSize          *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+// ExportAuditLogInput exports the changes made to every integration within a time range to a destination of
+// the deployment, s3 or sqs.
+//
+// An export sends a limited number of changes, the next export of the range resumes from the returned cursor.
+type ExportAuditLogInput struct {
+	StartTime   *time.Time `json:"startTime" validate:"required"`
+	EndTime     *time.Time `json:"endTime" validate:"required,gtfield=StartTime"`
+	Destination *string    `json:"destination" validate:"required,oneof=s3 sqs"`
+	Cursor      *string    `json:"cursor,omitempty" validate:"omitempty,min=1"`
+}
+
+// GetIntegrationHistoryInput pages through the health and scan history of an integration, most recent first.
+//
+// Records older than the retention period of the deployment are excluded, even before they are deleted.
+type GetIntegrationHistoryInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	// Only the records of this kind, all of them by default
+	Kind *string `json:"kind,omitempty" validate:"omitempty,oneof=health scan selfTest"`
+
+	PageSize          *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+// ListScanHistoryInput returns the most recent scans of an integration, the history keeps the last 100.
+type ListScanHistoryInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	// How many scans, all the ones kept by default
+	Limit *int `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// CompactIntegrationHistoryInput removes the duplicated records of the history of an integration, and the oldest
+// records above the most an integration keeps. It's a maintenance operation, e.g. after a migration.
+type CompactIntegrationHistoryInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+}
+
+// GenerateHealthReportInput renders a health report of an integration to S3, shared by a link which expires.
+type GenerateHealthReportInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	// How long the link to the report is valid, an hour by default and at most 7 days
+	ExpiresInMinutes *int `json:"expiresInMinutes,omitempty" validate:"omitempty,min=1,max=10080"`
 }
 
 //
 // ListIntegrations: Used by the Scheduler
 //
 
-// ListIntegrationsInput allows filtering by the IntegrationType or Enabled fields
+// ListIntegrationsInput allows filtering by the IntegrationType, ScanStatus, HealthStatus, label prefix or tags
 type ListIntegrationsInput struct {
 	ScanEnabled     *bool   `json:"scanEnabled"`
-	IntegrationType *string `json:"integrationType" validate:"oneof=aws-scan aws-s3"`
+	IntegrationType *string `json:"integrationType" validate:"integrationType"`
+	// Only list the integrations processed in the region, including the unpinned ones if it's the region of the API
+	ProcessingRegion *string `json:"processingRegion,omitempty"`
+
+	ScanStatus   *string `json:"scanStatus,omitempty" validate:"omitempty,oneof=ok error scanning"`
+	HealthStatus *string `json:"healthStatus,omitempty" validate:"omitempty,oneof=healthy degraded unhealthy"`
+	// Only list the integrations whose label starts with the prefix
+	LabelPrefix *string `json:"labelPrefix,omitempty" validate:"omitempty,min=1"`
+	// Only list the integrations which have every tag with the given value, keys can't have '.', '[' or ']'
+	Tags map[string]*string `json:"tags,omitempty" validate:"omitempty,max=10,dive,keys,required,excludesall=.[],endkeys,required"`
+}
+
+// ListIntegrationsPageInput returns a page of the enabled integrations, filtered like ListIntegrations.
+type ListIntegrationsPageInput struct {
+	IntegrationType *string `json:"integrationType,omitempty" validate:"omitempty,integrationType"`
+	ScanStatus      *string `json:"scanStatus,omitempty" validate:"omitempty,oneof=ok error scanning"`
+	HealthStatus    *string `json:"healthStatus,omitempty" validate:"omitempty,oneof=healthy degraded unhealthy"`
+	LabelPrefix     *string `json:"labelPrefix,omitempty" validate:"omitempty,min=1"`
+	// Only list the integrations which have every tag with the given value, keys can't have '.', '[' or ']'
+	Tags map[string]*string `json:"tags,omitempty" validate:"omitempty,max=10,dive,keys,required,excludesall=.[],endkeys,required"`
+
+	PageSize *int `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	// The LastEvaluatedKey of the previous page
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty" validate:"omitempty,uuid4"`
+}
+
+// ListIntegrationsPagesInput lists the enabled integrations like ListIntegrations, but a listing which fails
+// partway returns the integrations gathered so far and where to resume from.
+type ListIntegrationsPagesInput struct {
+	IntegrationType *string `json:"integrationType,omitempty" validate:"omitempty,integrationType"`
+	// The ExclusiveStartKey of an incomplete listing, to resume it
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty" validate:"omitempty,uuid4"`
+}
+
+// GetIntegrationsDueForScanInput pages through the enabled integrations whose next scan is due at the given time.
+type GetIntegrationsDueForScanInput struct {
+	Now             *time.Time `json:"now" validate:"required"`
+	IntegrationType *string    `json:"integrationType,omitempty" validate:"omitempty,integrationType"`
+
+	PageSize          *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+//
+// GetScanSchedulePreview: Used by operators to spot the scans which bunch up or leave gaps
+//
+
+// GetScanSchedulePreviewInput projects the scans of the enabled integrations over the next WindowHours.
+type GetScanSchedulePreviewInput struct {
+	WindowHours *int `json:"windowHours" validate:"required,min=1,max=168"`
+
+	// The start of the window, now by default
+	Now *time.Time `json:"now,omitempty"`
 }
 
+//
+// ListChangedIntegrations: Used by mirrors of the integrations to sync incrementally
+//
+
+// ListChangedIntegrationsInput pages through the integrations written to or deleted after the given time.
+//
+// Deletions are listed for as long as the marker of the deleted integration is kept, 30 days.
+type ListChangedIntegrationsInput struct {
+	SinceTime *time.Time `json:"sinceTime" validate:"required"`
+
+	PageSize          *int    `json:"pageSize,omitempty" validate:"omitempty,min=1,max=100"`
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+//
+// ExportAllIntegrations: Used by a daily schedule to back up the integrations
+//
+
+// ExportAllIntegrationsInput has no parameters, the export is written to the configured bucket.
+type ExportAllIntegrationsInput struct{}
+
+//
+// ListPendingRetries: Used by operators to follow the side effects which are retried in the background
+//
+
+// ListPendingRetriesInput lists the pending retries of an integration, or of every integration if none is given.
+type ListPendingRetriesInput struct {
+	IntegrationID *string `json:"integrationId,omitempty" validate:"omitempty,uuid4"`
+}
+
+//
+// RetryFailedSideEffects: Used by a schedule to retry the failed side effects in the background
+//
+
+// RetryFailedSideEffectsInput has no parameters, every retry which is due is attempted.
+type RetryFailedSideEffectsInput struct{}
+
+//
+// RequeueFailedObjects: Used by operators to reprocess the objects which failed in a window of time
+//
+
+// RequeueFailedObjectsInput re-submits the objects scans of an integration failed on between StartTime and EndTime.
+type RequeueFailedObjectsInput struct {
+	IntegrationID *string    `json:"integrationId" validate:"required,uuid4"`
+	StartTime     *time.Time `json:"startTime" validate:"required"`
+	EndTime       *time.Time `json:"endTime" validate:"required"`
+
+	// Requeue a time range longer than a day
+	Confirm *bool `json:"confirm,omitempty"`
+}
+
+//
+// GetAccountHealthSummary: Used by the UI to show the health of each AWS account
+//
+
+// GetAccountHealthSummaryInput pages through the integration health rolled up by AWS account.
+type GetAccountHealthSummaryInput struct {
+	Page     *int `json:"page" validate:"omitempty,min=1"`
+	PageSize *int `json:"pageSize" validate:"omitempty,min=1,max=1000"`
+}
+
+//
+// ListStaleHealthIntegrations: Used by monitoring to alert on integrations whose health is no longer confirmed
+//
+
+// ListStaleHealthIntegrationsInput lists the integrations which haven't passed a health check within the threshold.
+type ListStaleHealthIntegrationsInput struct {
+	ThresholdMinutes *int `json:"thresholdMinutes" validate:"required,min=1"`
+}
+
+//
+// RecheckIntegrationHealth: Triggered on a schedule to notice the integrations which break after they were saved
+//
+
+// RecheckIntegrationHealthInput re-runs the health check of every integration, or of every integration of a type.
+type RecheckIntegrationHealthInput struct {
+	IntegrationType *string `json:"integrationType,omitempty" validate:"omitempty,integrationType"`
+}
+
+//
+// ListExpiringCredentials: Used by monitoring to warn operators before the credentials of integrations expire
+//
+
+// ListExpiringCredentialsInput lists the integrations whose credentials expire within the given number of days.
+type ListExpiringCredentialsInput struct {
+	WithinDays *int `json:"withinDays" validate:"required,min=1,max=365"`
+}
+
+//
+// VerifyTrustForAccount: Used by operators after the trust policies of an AWS account changed
+//
+
+// VerifyTrustForAccountInput verifies that the roles of every integration of the account can still be assumed.
+type VerifyTrustForAccountInput struct {
+	AWSAccountID *string `genericapi:"redact" json:"awsAccountId" validate:"required,len=12,numeric"`
+}
+
+//
+// ListSilentIntegrations: Used by monitoring to find the sources which are configured but deliver nothing
+//
+
+// ListSilentIntegrationsInput lists the enabled integrations whose scans processed no objects over the period.
+type ListSilentIntegrationsInput struct {
+	PeriodMinutes *int `json:"periodMinutes" validate:"required,min=1"`
+}
+
+//
+// CheckIngestionAlarms: Triggered on a schedule to alert on the log sources which stopped delivering events
+//
+
+// CheckIngestionAlarmsInput checks the ingestion alarms of every log analysis integration which configures them.
+type CheckIngestionAlarmsInput struct {
+}
+
+//
+// RecheckKmsKeyAliases: Triggered on a schedule to notice the KMS aliases repointed after they were pinned
+//
+
+// RecheckKmsKeyAliasesInput re-resolves the KMS aliases of every integration which pinned some.
+type RecheckKmsKeyAliasesInput struct {
+}
+
+//
+// SyncOrganizations: Triggered on a schedule to onboard the new member accounts of the AWS Organizations
+//
+
+// SyncOrganizationsInput reconciles the aws-scan integrations of the member accounts of an aws-organization
+// integration, or of all of them when no integration is given.
+type SyncOrganizationsInput struct {
+	IntegrationID *string `json:"integrationId,omitempty" validate:"omitempty,uuid4"`
+	DryRun        *bool   `json:"dryRun,omitempty"`
+}
+
+//
+// GetIntegrationPolicyDocument: Used by the frontend for customers managing the IAM role themselves
+//
+
+// GetIntegrationPolicyDocumentInput is used to get the IAM policy needed by an existing integration.
+type GetIntegrationPolicyDocumentInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+}
+
+//
+// ListIntegrationTypes: Used by the frontend to render the integration forms
+//
+
+// ListIntegrationTypesInput has no parameters, every supported integration type is returned.
+type ListIntegrationTypesInput struct{}
+
 //
 // GetIntegrationTemplate: Used by the frontend to provide templates for users
 //
@@ -96,11 +858,20 @@ type ListIntegrationsInput struct {
 // GetIntegrationTemplateInput allows specification of what resources should be enabled/disabled in the template
 type GetIntegrationTemplateInput struct {
 	AWSAccountID       *string   `genericapi:"redact" json:"awsAccountId" validate:"required,len=12,numeric"`
-	IntegrationType    *string   `json:"integrationType" validate:"oneof=aws-scan aws-s3"`
+	IntegrationType    *string   `json:"integrationType" validate:"integrationType"`
 	RemediationEnabled *bool     `json:"remediationEnabled"`
 	CWEEnabled         *bool     `json:"cweEnabled"`
 	S3Buckets          []*string `json:"s3Buckets"`
 	KmsKeys            []*string `json:"kmsKeys"`
+
+	// Allow the log processing role to grant itself decrypt on the keys, instead of editing their key policies
+	CreateKmsGrants *bool `json:"createKmsGrants,omitempty"`
+
+	// Allow the log processing role to describe the organization of the account, to verify the org trail setup
+	IsOrgTrail *bool `json:"isOrgTrail,omitempty"`
+
+	// Allow the log processing role to read the records of this Kinesis stream
+	StreamARN *string `json:"streamArn,omitempty" validate:"omitempty,kinesisStreamArn"`
 }
 
 //
@@ -110,6 +881,93 @@ type GetIntegrationTemplateInput struct {
 // DeleteIntegrationInput is used to delete a specific item from the database.
 type DeleteIntegrationInput struct {
 	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+
+	// Delete the integration even if other integrations depend on it
+	Force *bool `json:"force,omitempty"`
+
+	// Keep the settings of the integration so that it can be restored with RestoreIntegration during the
+	// retention, 7 days by default
+	SoftDelete    *bool `json:"softDelete,omitempty"`
+	RetentionDays *int  `json:"retentionDays,omitempty" validate:"omitempty,min=1,max=90"`
+
+	// The user making the change, recorded in the change history of the integration
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+//
+// RestoreIntegration: Used by the UI to undo the soft deletion of an integration
+//
+
+// RestoreIntegrationInput restores an integration which was soft-deleted, before its retention expires.
+type RestoreIntegrationInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+
+	// The user making the change, recorded in the change history of the integration
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+//
+// PurgeDeletedIntegrations: Triggered on a schedule to drop the soft-deleted integrations whose retention expired
+//
+
+// PurgeDeletedIntegrationsInput has no parameters, every integration which is no longer restorable is purged.
+type PurgeDeletedIntegrationsInput struct{}
+
+//
+// TriggerScan: Used by the UI to rescan an aws-scan integration right away, e.g. to verify a remediation
+//
+
+// TriggerScanInput starts a scan of an integration now, limited to a region or a resource type when they are set.
+//
+// The scan is still limited to the regions and resource types of the integration.
+type TriggerScanInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	Region        *string `json:"region,omitempty" validate:"omitempty,awsRegion"`
+	ResourceType  *string `json:"resourceType,omitempty" validate:"omitempty,min=1"`
+}
+
+//
+// RotateHTTPIngestKey: Used by the UI to replace the API key of an http-ingest integration
+//
+
+// RotateHTTPIngestKeyInput replaces the API key of an http-ingest integration.
+type RotateHTTPIngestKeyInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	UserID        *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// HTTPIngestKey is the endpoint of an http-ingest integration and the API key its log events are posted with.
+//
+// The value of the key is only returned when the key is created, Panther doesn't keep it.
+type HTTPIngestKey struct {
+	IntegrationID *string `json:"integrationId"`
+	Endpoint      *string `json:"endpoint"`
+	APIKeyID      *string `json:"apiKeyId"`
+	APIKey        *string `genericapi:"redact" json:"apiKey"`
+}
+
+//
+// UpdateKinesisCheckpoints: Used by the Kinesis reader
+//
+
+// UpdateKinesisCheckpointsInput saves how far the reader processed the stream of a Kinesis integration.
+type UpdateKinesisCheckpointsInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	// The stream the checkpoints were read from, those of a previous stream are ignored
+	StreamARN *string `json:"streamArn" validate:"required,kinesisStreamArn"`
+	// The sequence number reading resumes after, by shard ID
+	Checkpoints map[string]*string `json:"checkpoints" validate:"required,min=1,dive,keys,min=1,endkeys,required,min=1"`
+}
+
+//
+// UpdateOktaCursor: Used by the Okta puller
+//
+
+// UpdateOktaCursorInput saves how far the puller read the System Log of an Okta integration.
+type UpdateOktaCursorInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
+	// The after parameter of the next page of the System Log
+	Cursor *string `json:"cursor" validate:"required,min=1,max=1024"`
 }
 
 //
@@ -123,6 +981,13 @@ type UpdateIntegrationLastScanStartInput struct {
 	ScanStatus        *string    `json:"scanStatus" validate:"required,oneof=ok error scanning"`
 }
 
+// UpdateIntegrationsLastScanStartInput records the start of the scans of many integrations at once.
+//
+// Each start is recorded like with UpdateIntegrationLastScanStart, one which fails doesn't prevent the others.
+type UpdateIntegrationsLastScanStartInput struct {
+	Updates []*UpdateIntegrationLastScanStartInput `json:"updates" validate:"required,min=1,max=100,dive,required"`
+}
+
 // UpdateIntegrationLastScanEndInput is used to update scan information at the end of a scan.
 type UpdateIntegrationLastScanEndInput struct {
 	EventStatus          *string    `json:"eventStatus"`
@@ -130,16 +995,309 @@ type UpdateIntegrationLastScanEndInput struct {
 	LastScanEndTime      *time.Time `json:"lastScanEndTime" validate:"required"`
 	LastScanErrorMessage *string    `json:"lastScanErrorMessage"`
 	ScanStatus           *string    `json:"scanStatus" validate:"required,oneof=ok error scanning"`
+
+	// The position reached by the scan (e.g. the last object key), it can never move backwards
+	LastScanBookmark *string `json:"lastScanBookmark" validate:"omitempty,min=1"`
+
+	// Set when the scan stopped at the object cap of the integration, with the number of objects it processed
+	ScanTruncated    *bool  `json:"scanTruncated,omitempty"`
+	ObjectsProcessed *int64 `json:"objectsProcessed,omitempty" validate:"omitempty,min=0"`
+	// How many of the objects processed the scan failed on, compared to the ErrorRateThreshold of the integration
+	ObjectsFailed *int64 `json:"objectsFailed,omitempty" validate:"omitempty,min=0"`
+
+	// A few of the records the scan failed on, they are added to the samples of previous scans
+	ErrorSamples []*ScanErrorSample `json:"errorSamples,omitempty" validate:"omitempty,max=10,dive,required"`
+
+	// Identifies the scan in the summary posted to the scan complete callback of the integration
+	ScanID *string `json:"scanId,omitempty" validate:"omitempty,max=128"`
+}
+
+// TransactUpdateIntegrationsInput updates several integrations all-or-nothing, e.g. to move buckets between them.
+//
+// Each update is checked like with UpdateIntegrationSettings, then they are written in a single transaction of
+// at most 25 integrations. An integration can only be updated once per transaction.
+type TransactUpdateIntegrationsInput struct {
+	Updates []*UpdateIntegrationSettingsInput `json:"updates" validate:"required,min=1,max=25,dive,required"`
+}
+
+// UpdateIntegrationsBatchInput updates several integrations independently, e.g. to onboard many accounts at once.
+//
+// Each update is applied like with UpdateIntegrationSettings, one which fails doesn't prevent the others.
+type UpdateIntegrationsBatchInput struct {
+	Updates []*UpdateIntegrationSettingsInput `json:"updates" validate:"required,min=1,max=250,dive,required"`
+}
+
+// PreviewIntegrationChangeSetInput is used to diff a desired configuration against the stored integration.
+//
+// The desired configuration is applied with UpdateIntegrationSettings, so settings which are not set are
+// left unchanged and are not part of the change set.
+type PreviewIntegrationChangeSetInput struct {
+	Desired *UpdateIntegrationSettingsInput `json:"desired" validate:"required"`
+}
+
+// CompareIntegrationsInput is used to check that an integration is configured like a reference one.
+type CompareIntegrationsInput struct {
+	ReferenceIntegrationID *string `json:"referenceIntegrationId" validate:"required,uuid4"`
+	IntegrationID          *string `json:"integrationId" validate:"required,uuid4"`
+}
+
+// BulkSetScanIntervalInput sets the scan interval of every integration matching the filters.
+//
+// Filters which are not set match every integration.
+type BulkSetScanIntervalInput struct {
+	IntegrationType  *string `json:"integrationType,omitempty" validate:"omitempty,integrationType"`
+	AWSAccountID     *string `genericapi:"redact" json:"awsAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+	ScanIntervalMins *int    `json:"scanIntervalMins" validate:"required,scanInterval"`
+
+	// The user making the change, recorded in the change history of the integrations
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// ApplyTagPolicyInput applies the settings of a policy to every integration with all the tags of the selector.
+type ApplyTagPolicyInput struct {
+	TagSelector map[string]*string `json:"tagSelector" validate:"required,min=1,dive,keys,required,max=128,endkeys,required,max=256"`
+	Settings    *TagPolicySettings `json:"settings" validate:"required"`
+
+	// The user making the change, recorded in the change history of the integrations
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// TagPolicySettings are the settings a tag policy can govern, the ones which are not set are left unchanged.
+//
+// The schedule settings (scanEnabled, scanIntervalMins and blackoutWindows) are applied without a health check.
+type TagPolicySettings struct {
+	ScanEnabled        *bool             `json:"scanEnabled,omitempty"`
+	ScanIntervalMins   *int              `json:"scanIntervalMins,omitempty" validate:"omitempty,scanInterval"`
+	BlackoutWindows    []*BlackoutWindow `json:"blackoutWindows,omitempty" validate:"omitempty,max=20,dive,required"`
+	CWEEnabled         *bool             `json:"cweEnabled,omitempty"`
+	RemediationEnabled *bool             `json:"remediationEnabled,omitempty"`
+	DedupWindowMinutes *int              `json:"dedupWindowMinutes,omitempty" validate:"omitempty,min=1,max=10080"`
+	DataClassification *string           `json:"dataClassification,omitempty" validate:"omitempty,dataClassification"`
+}
+
+// RemoveBucketsInput is used to remove some S3 buckets from an integration.
+type RemoveBucketsInput struct {
+	IntegrationID *string   `json:"integrationId" validate:"required,uuid4"`
+	S3Buckets     []*string `json:"s3Buckets" validate:"required,min=1,dive,required"`
+
+	// The user making the change, recorded in the change history of the integration
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// ReplaceBucketsInput is used to replace the whole set of S3 buckets of an integration at once.
+type ReplaceBucketsInput struct {
+	IntegrationID *string   `json:"integrationId" validate:"required,uuid4"`
+	S3Buckets     []*string `json:"s3Buckets" validate:"required,min=1,dive,required"`
+
+	// Buckets owned by another account are rejected, unless that account delegated them to the account of the integration
+	AllowCrossAccountBuckets *bool   `json:"allowCrossAccountBuckets,omitempty"`
+	DelegatingAccountID      *string `genericapi:"redact" json:"delegatingAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+
+	// Save the integration even if some of the new buckets fail the health check, roles must always pass
+	AllowPartialHealth *bool `json:"allowPartialHealth,omitempty"`
+
+	// The user making the change, recorded in the change history of the integration
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// MigrateToPrefixConfigInput moves the buckets of the log analysis integrations matching the filters to bucket
+// entries with a prefix, the prefixes are inferred from the top level of the keys of each bucket.
+//
+// Filters which are not set match every log analysis integration.
+type MigrateToPrefixConfigInput struct {
+	AWSAccountID   *string   `genericapi:"redact" json:"awsAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+	IntegrationIDs []*string `json:"integrationIds,omitempty" validate:"omitempty,max=100,dive,required,uuid4"`
+
+	// Only propose the prefixes, nothing is written
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// The user making the change, recorded in the change history of the integrations
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// RemoveKmsKeysInput is used to remove some KMS keys from an integration, given by key ARN or by alias.
+type RemoveKmsKeysInput struct {
+	IntegrationID *string   `json:"integrationId" validate:"required,uuid4"`
+	KmsKeys       []*string `json:"kmsKeys" validate:"required,min=1,dive,required"`
+
+	// The user making the change, recorded in the change history of the integration
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// ResetIntegrationBookmarkInput is used to clear the scan bookmark, causing the next scan to be a full one.
+type ResetIntegrationBookmarkInput struct {
+	IntegrationID *string `json:"integrationId" validate:"required,uuid4"`
 }
 
 // UpdateIntegrationSettingsInput is used to update integration settings.
+//
+// The integration type can be changed when the settings of the old type are cleared in the same update,
+// and the ones the new type requires are set.
 type UpdateIntegrationSettingsInput struct {
 	IntegrationID      *string   `json:"integrationId" validate:"required,uuid4"`
+	IntegrationType    *string   `json:"integrationType,omitempty" validate:"omitempty,integrationType"`
 	IntegrationLabel   *string   `json:"integrationLabel,omitempty" validate:"omitempty,min=1"`
+	Description        *string   `json:"description,omitempty" validate:"omitempty,max=1000"`
 	ScanEnabled        *bool     `json:"scanEnabled"`
 	CWEEnabled         *bool     `json:"cweEnabled,omitempty"`
 	RemediationEnabled *bool     `json:"remediationEnabled,omitempty"`
-	ScanIntervalMins   *int      `json:"scanIntervalMins" validate:"omitempty,oneof=60 180 360 720 1440"`
+	ScanIntervalMins   *int      `json:"scanIntervalMins" validate:"omitempty,scanInterval"`
 	S3Buckets          []*string `json:"s3Buckets"`
 	KmsKeys            []*string `json:"kmsKeys"`
+
+	// Region of each bucket by bucket name, replaces the stored regions when set
+	S3BucketRegions map[string]*string `json:"s3BucketRegions" validate:"omitempty,dive,keys,required,endkeys,required,awsRegion"`
+
+	// The objects ingested from each bucket by bucket name, replaces the stored filters when set
+	S3ObjectFilters map[string]*S3ObjectFilter `json:"s3ObjectFilters" validate:"omitempty,dive,keys,required,endkeys,required"`
+
+	// The networks the devices of a syslog integration send their messages from, replaces the stored ones when set
+	SyslogAllowedCIDRs []*string `json:"syslogAllowedCidrs" validate:"omitempty,max=100,dive,required,cidr"`
+
+	// The member accounts of an aws-organization integration which are not onboarded, replaces the stored ones when set
+	OrganizationExcludedAccounts []*string `json:"organizationExcludedAccounts" validate:"omitempty,max=1000,dive,len=12,numeric"`
+
+	// By default, the label must be unique among the integrations of the same account
+	AllowDuplicateLabel *bool `json:"allowDuplicateLabel,omitempty"`
+
+	// Save the integration even if some buckets or keys fail the health check, roles must always pass
+	AllowPartialHealth *bool `json:"allowPartialHealth,omitempty"`
+
+	// Buckets owned by another account are rejected, unless that account delegated them to the account of the integration
+	AllowCrossAccountBuckets *bool   `json:"allowCrossAccountBuckets,omitempty"`
+	DelegatingAccountID      *string `genericapi:"redact" json:"delegatingAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+
+	// Only update the integration if its scan status is the expected one, e.g. to avoid disturbing a scan
+	ExpectedScanStatus *string `json:"expectedScanStatus,omitempty" validate:"omitempty,oneof=ok error scanning"`
+
+	// Only update the integration if it wasn't written since it was read with this ETag, see GetIntegration.
+	// ForceUpdate skips the check, e.g. once the user chose to overwrite the changes made in the meantime
+	IfMatch     *string `json:"ifMatch,omitempty" validate:"omitempty,max=32"`
+	ForceUpdate *bool   `json:"forceUpdate,omitempty"`
+
+	// Run the validation and the health checks without writing anything, see DryRunReport
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// Create KMS grants for decrypt on the keys, or retire the grants which were created when false
+	CreateKmsGrants *bool `json:"createKmsGrants,omitempty"`
+
+	// Add the KMS keys the buckets are encrypted with by default to the kmsKeys, before the health check
+	DiscoverKmsKeys *bool `json:"discoverKmsKeys,omitempty"`
+
+	// The org trail settings, the management account is required once the integration is an org trail
+	IsOrgTrail          *bool   `json:"isOrgTrail,omitempty"`
+	ManagementAccountID *string `genericapi:"redact" json:"managementAccountId,omitempty" validate:"omitempty,len=12,numeric"`
+
+	// The stream settings of Kinesis integrations, empty values clear them when the integration changes to another type
+	StreamARN         *string `json:"streamArn,omitempty" validate:"omitempty,eq=|kinesisStreamArn"`
+	ShardIteratorType *string `json:"shardIteratorType,omitempty" validate:"omitempty,eq=|oneof=TRIM_HORIZON LATEST"`
+
+	// The roles assumed in order to reach the logs, an empty chain goes back to the log processing role
+	RoleChain []*string `json:"roleChain,omitempty" validate:"omitempty,max=5,dive,required,roleArn"`
+
+	// Rotates the credentials of a pull source, every credential of the integration type must be given
+	Credentials map[string]*string `genericapi:"redact" json:"credentials,omitempty" validate:"omitempty,credentials"`
+
+	// The duration of the STS sessions of the role the logs are read with, from 15 minutes to 12 hours and within
+	// the maximum session duration of the role. Unset uses sessions of an hour
+	SessionDurationSeconds *int `json:"sessionDurationSeconds,omitempty" validate:"omitempty,min=900,max=43200"`
+
+	// Limits protecting the pipeline from a single noisy integration
+	MaxConcurrentObjects *int `json:"maxConcurrentObjects,omitempty" validate:"omitempty,min=1,max=100"`
+	MaxObjectsPerScan    *int `json:"maxObjectsPerScan,omitempty" validate:"omitempty,min=1,max=1000000"`
+
+	// The order in which the objects are ingested: none (default), per-prefix or strict, and how many objects
+	// are ingested at once. Strict ordering ingests a single object at a time
+	OrderingMode   *string `json:"orderingMode,omitempty" validate:"omitempty,orderingMode"`
+	MaxParallelism *int    `json:"maxParallelism,omitempty" validate:"omitempty,min=1,max=100"`
+
+	// Duplicate alerts from this source are suppressed within this window, up to a week
+	DedupWindowMinutes *int `json:"dedupWindowMinutes,omitempty" validate:"omitempty,min=1,max=10080"`
+
+	// Largest log record accepted, up to 10 MiB, and what happens to the larger ones: drop (default), truncate or error
+	MaxRecordBytes        *int    `json:"maxRecordBytes,omitempty" validate:"omitempty,min=1,max=10485760"`
+	OversizedRecordPolicy *string `json:"oversizedRecordPolicy,omitempty" validate:"omitempty,oversizedRecordPolicy"`
+
+	// Fraction of the log records ingested, the others are dropped at random. Unset ingests every record
+	SampleRate *float64 `json:"sampleRate,omitempty" validate:"omitempty,min=0,max=1"`
+
+	// Fraction of the objects a scan may fail on before an alert is sent to the notification targets
+	ErrorRateThreshold *float64 `json:"errorRateThreshold,omitempty" validate:"omitempty,min=0,max=1"`
+
+	// Alarms of the ingestion of a log analysis integration, from the metrics of the log processor: an alert is sent
+	// when no events are delivered for the threshold, from 5 minutes to a week, or when a larger fraction of the
+	// log lines fails to classify than the threshold
+	SilenceThresholdMinutes *int     `json:"silenceThresholdMinutes,omitempty" validate:"omitempty,min=5,max=10080"`
+	EventErrorRateThreshold *float64 `json:"eventErrorRateThreshold,omitempty" validate:"omitempty,min=0,max=1"`
+
+	// How the timestamp of the events is derived for a log layout Panther doesn't know, see TimeExtraction.Validate.
+	// An empty one removes it
+	TimeExtraction *TimeExtraction `json:"timeExtraction,omitempty" validate:"omitempty"`
+
+	// How far from the time they are processed the events can be timestamped, an empty one removes the tolerance
+	TimestampSkew *TimestampSkew `json:"timestampSkew,omitempty" validate:"omitempty"`
+
+	// Where the notifications which failed to be processed are moved, for log analysis integrations. An empty one removes it
+	DeadLetterQueue *DeadLetterQueue `json:"deadLetterQueue,omitempty" validate:"omitempty"`
+
+	// Format of the archives the log analysis integration receives: zip, tar or tar.gz. An empty one removes it
+	ArchiveFormat *string `json:"archiveFormat,omitempty" validate:"omitempty,archiveFormat"`
+
+	// Sensitivity of the data of the source, honored by the downstream access controls
+	DataClassification *string `json:"dataClassification,omitempty" validate:"omitempty,dataClassification"`
+
+	// The region of the deployment which processes the integration, one of the regions Panther is deployed in
+	ProcessingRegion *string `json:"processingRegion,omitempty"`
+
+	// Regular expressions of the object keys to ingest: keys matching an exclude pattern are skipped, and
+	// when there are include patterns only the keys matching one of them are ingested
+	IncludePatterns []*string `json:"includePatterns,omitempty" validate:"omitempty,max=20,dive,required,max=256,keyPattern"`
+	ExcludePatterns []*string `json:"excludePatterns,omitempty" validate:"omitempty,max=20,dive,required,max=256,keyPattern"`
+
+	// Periods during which no scans run
+	BlackoutWindows []*BlackoutWindow `json:"blackoutWindows,omitempty" validate:"omitempty,max=20,dive,required"`
+
+	// A cron expression of when the integration is scanned in UTC, see PutIntegrationSettings. Empty goes back
+	// to scanIntervalMins
+	ScanSchedule *string `json:"scanSchedule,omitempty" validate:"omitempty,eq=|cronSchedule"`
+
+	// The regions aws-scan integrations are scanned in, see PutIntegrationSettings. Each replaces the stored regions
+	// when set, empty lists scan every region
+	EnabledRegions  []*string `json:"enabledRegions,omitempty" validate:"omitempty,max=50,dive,required,awsRegion"`
+	ExcludedRegions []*string `json:"excludedRegions,omitempty" validate:"omitempty,max=50,dive,required,awsRegion"`
+
+	// The resource types aws-scan integrations are scanned for, see PutIntegrationSettings. Each replaces the stored
+	// list when set, empty lists scan every type
+	ResourceTypeAllowList []*string `json:"resourceTypeAllowList,omitempty" validate:"omitempty,max=100,dive,required"`
+	ResourceTypeDenyList  []*string `json:"resourceTypeDenyList,omitempty" validate:"omitempty,max=100,dive,required"`
+
+	// Fields of the logs masked before they are stored
+	RedactionRules []*RedactionRule `json:"redactionRules,omitempty" validate:"omitempty,max=50,dive,required"`
+
+	// Health sub-checks which are still run but only reported as informational, e.g. "s3Buckets"
+	DisabledChecks []*string `json:"disabledChecks,omitempty" validate:"omitempty,dive,required,disableableHealthCheck"`
+
+	// Experimental ingestion modes the integration is opted into, e.g. "parserV2", replaces the stored flags when set
+	FeatureFlags map[string]bool `json:"featureFlags" validate:"omitempty,dive,keys,featureFlag,endkeys"`
+
+	// IDs of existing integrations this one depends on, they can't be deleted while it exists
+	DependsOn []*string `json:"dependsOn,omitempty" validate:"omitempty,dive,required,uuid4"`
+
+	// IDs of the alert outputs notified when the health of the integration changes, instead of the default outputs
+	NotificationTargets []*string `json:"notificationTargets,omitempty" validate:"omitempty,dive,required,uuid4"`
+
+	// Names of the configured enrichment sources the events of the integration are enriched with, an empty list clears them
+	EnrichmentSources []*string `json:"enrichmentSources,omitempty" validate:"omitempty,dive,required,max=128"`
+
+	// Tags of the integration by key, replaces the stored tags when set
+	Tags map[string]*string `json:"tags" validate:"omitempty,max=50,dive,keys,required,max=128,endkeys,required,max=256"`
+
+	// Severity of the alerts by matcher, replaces the stored overrides when set, an empty map clears them
+	SeverityOverrides map[string]*string `json:"severityOverrides" validate:"omitempty,max=50,dive,keys,required,max=128,endkeys,severity"`
+
+	// The https endpoint a signed summary of each completed scan is posted to, an empty URL removes the callback
+	ScanCompleteCallbackURL *string `json:"scanCompleteCallbackUrl,omitempty" validate:"omitempty,max=2048,url,startswith=https://"`
+
+	// The user making the change, recorded in the change history of the integration
+	UserID *string `json:"userId,omitempty" validate:"omitempty,uuid4"`
 }
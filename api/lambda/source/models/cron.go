@@ -0,0 +1,195 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bounds the search of the next time a schedule matches, a schedule which only matches on February 29 still
+// matches within this many days
+const maxCronSearchDays = 5 * 366
+
+// CronSchedule is a parsed cron expression: "minute hour day-of-month month day-of-week", matched in UTC.
+//
+// Each field is a "*", a value, a range "a-b" or a list of them, and any of them can have a step "/n".
+// Months and days of the week can be given by their 3 letter English names, and Sunday is either 0 or 7.
+// As with the classic cron, a day matches if either of the day fields matches when both are restricted.
+type CronSchedule struct {
+	minutes, hours, daysOfMonth, months, daysOfWeek uint64
+
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// ParseCronSchedule parses a cron expression, e.g. "0 2 * * mon-fri" to scan on weekdays at 02:00 UTC.
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, not %d", len(cronFields), len(parts))
+	}
+	var values [5]uint64
+	for i, field := range cronFields {
+		var err error
+		if values[i], err = field.parse(strings.ToLower(parts[i])); err != nil {
+			return nil, fmt.Errorf("%s: %s", field.name, err)
+		}
+	}
+	schedule := &CronSchedule{
+		minutes:       values[0],
+		hours:         values[1],
+		daysOfMonth:   values[2],
+		months:        values[3],
+		daysOfWeek:    values[4],
+		anyDayOfMonth: strings.HasPrefix(parts[2], "*"),
+		anyDayOfWeek:  strings.HasPrefix(parts[4], "*"),
+	}
+	// Sunday is both 0 and 7
+	if schedule.daysOfWeek&(1<<7) != 0 {
+		schedule.daysOfWeek |= 1
+	}
+	if schedule.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, errors.New("the schedule never matches")
+	}
+	return schedule, nil
+}
+
+// parse returns the values of the field which match, as a bit set.
+func (field cronField) parse(expression string) (uint64, error) {
+	var result uint64
+	for _, item := range strings.Split(expression, ",") {
+		rangeExpression, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+			rangeExpression = item[:i]
+		}
+
+		low, high := field.min, field.max
+		switch bounds := strings.SplitN(rangeExpression, "-", 2); {
+		case rangeExpression == "*":
+		case len(bounds) == 2:
+			var err error
+			if low, err = field.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if high, err = field.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q", rangeExpression)
+			}
+		default:
+			var err error
+			if low, err = field.value(rangeExpression); err != nil {
+				return 0, err
+			}
+			// "5/15" is from 5 to the end of the range
+			if step == 1 {
+				high = low
+			}
+		}
+		for value := low; value <= high; value += step {
+			result |= 1 << uint(value)
+		}
+	}
+	return result, nil
+}
+
+func (field cronField) value(expression string) (int, error) {
+	for i, name := range field.names {
+		if expression == name {
+			return i + field.min, nil
+		}
+	}
+	value, err := strconv.Atoi(expression)
+	if err != nil || value < field.min || value > field.max {
+		return 0, fmt.Errorf("invalid value %q, expected %d to %d", expression, field.min, field.max)
+	}
+	return value, nil
+}
+
+// RunsPerHour is the most times the schedule matches within an hour.
+func (schedule *CronSchedule) RunsPerHour() int {
+	return bits.OnesCount64(schedule.minutes)
+}
+
+// Next returns the first time the schedule matches strictly after t, in UTC.
+//
+// The zero time is returned if the schedule doesn't match in the next few years, e.g. "0 0 30 2 *".
+func (schedule *CronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxCronSearchDays; i, day = i+1, day.AddDate(0, 0, 1) {
+		if !schedule.matchesDay(day) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if schedule.hours&(1<<uint(hour)) == 0 {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if schedule.minutes&(1<<uint(minute)) == 0 {
+					continue
+				}
+				if next := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute); !next.Before(t) {
+					return next
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (schedule *CronSchedule) matchesDay(day time.Time) bool {
+	if schedule.months&(1<<uint(day.Month())) == 0 {
+		return false
+	}
+	dayOfMonth := schedule.daysOfMonth&(1<<uint(day.Day())) != 0
+	dayOfWeek := schedule.daysOfWeek&(1<<uint(day.Weekday())) != 0
+	switch {
+	case schedule.anyDayOfMonth:
+		return dayOfWeek
+	case schedule.anyDayOfWeek:
+		return dayOfMonth
+	default:
+		return dayOfMonth || dayOfWeek
+	}
+}
@@ -18,7 +18,16 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
 
 // SourceIntegration is the dynamodb item corresponding to the PutIntegration route.
 type SourceIntegration struct {
@@ -34,6 +43,7 @@ type SourceIntegrationMetadata struct {
 	CreatedBy          *string    `json:"createdBy"`
 	IntegrationID      *string    `json:"integrationId"`
 	IntegrationLabel   *string    `json:"integrationLabel"`
+	Description        *string    `json:"description,omitempty"`
 	IntegrationType    *string    `json:"integrationType"`
 	ScanEnabled        *bool      `json:"scanEnabled"`
 	RemediationEnabled *bool      `json:"remediationEnabled"`
@@ -41,6 +51,535 @@ type SourceIntegrationMetadata struct {
 	ScanIntervalMins   *int       `json:"scanIntervalMins"`
 	S3Buckets          []*string  `json:"s3Buckets"`
 	KmsKeys            []*string  `json:"kmsKeys"`
+
+	// When the integration was last written to, and whether it was deleted since, see ListChangedIntegrations
+	LastModifiedAt *time.Time `json:"lastModifiedAt,omitempty"`
+	Deleted        *bool      `json:"deleted,omitempty"`
+
+	// KMS aliases given by the user, mapped to the key ARN they were pinned to in KmsKeys
+	KmsKeyAliases map[string]*string `json:"kmsKeyAliases,omitempty"`
+
+	// Whether decrypt on the keys is given to the log processing role by KMS grants, and the grant ID by key ARN
+	CreateKmsGrants *bool              `json:"createKmsGrants,omitempty"`
+	KmsGrants       map[string]*string `json:"kmsGrants"`
+
+	// Whether the buckets receive a CloudTrail organization trail, and the management account of the organization
+	IsOrgTrail          *bool   `json:"isOrgTrail,omitempty"`
+	ManagementAccountID *string `json:"managementAccountId,omitempty"`
+
+	// The Kinesis stream of Kinesis integrations, and the position reading starts from
+	StreamARN         *string `json:"streamArn,omitempty"`
+	ShardIteratorType *string `json:"shardIteratorType,omitempty"`
+
+	// The enhanced fan-out consumer the stream is read with, and by shard ID the sequence number reading resumes
+	// after. The checkpoints are those of the stream they were read from, they are ignored once the stream changes.
+	KinesisConsumerARN     *string            `json:"kinesisConsumerArn,omitempty"`
+	KinesisCheckpoints     map[string]*string `json:"kinesisCheckpoints,omitempty"`
+	KinesisCheckpointsFrom *string            `json:"kinesisCheckpointsFrom,omitempty"`
+
+	// For Azure integrations: the subscription, and the service principal it is scanned with
+	AzureTenantID        *string `json:"azureTenantId,omitempty"`
+	AzureSubscriptionID  *string `json:"azureSubscriptionId,omitempty"`
+	AzureClientID        *string `json:"azureClientId,omitempty"`
+	AzureClientSecretArn *string `json:"azureClientSecretArn,omitempty"`
+
+	// For GCP integrations: the project, the service account its logs are read with, and where they are read from
+	GCPProjectID               *string `json:"gcpProjectId,omitempty"`
+	GCPServiceAccountSecretArn *string `json:"gcpServiceAccountSecretArn,omitempty"`
+	GCSBucket                  *string `json:"gcsBucket,omitempty"`
+	PubSubSubscription         *string `json:"pubSubSubscription,omitempty"`
+
+	// For SQS integrations: the queue the log events are sent to, whether Panther created it, and the mapping
+	// of the queue to the log processor
+	SQSQueueArn             *string `json:"sqsQueueArn,omitempty"`
+	SQSQueueURL             *string `json:"sqsQueueUrl,omitempty"`
+	SQSQueueProvisioned     *bool   `json:"sqsQueueProvisioned,omitempty"`
+	SQSEventSourceMappingID *string `json:"sqsEventSourceMappingId,omitempty"`
+
+	// For HTTP ingest integrations: the endpoint the log events are posted to, and the API key they are posted with.
+	// The value of the key is only returned when the key is created, it's never stored
+	HTTPIngestEndpoint *string `json:"httpIngestEndpoint,omitempty"`
+	HTTPIngestAPIKeyID *string `json:"httpIngestApiKeyId,omitempty"`
+	HTTPIngestAPIKey   *string `genericapi:"redact" json:"httpIngestApiKey,omitempty" dynamodbav:"-"`
+
+	// For Okta integrations: the organization whose System Log is pulled, and where the next pull resumes from
+	OktaDomain    *string `json:"oktaDomain,omitempty"`
+	OktaLogCursor *string `json:"oktaLogCursor,omitempty"`
+
+	// For syslog integrations: the networks their devices send the messages from
+	SyslogAllowedCIDRs []*string `json:"syslogAllowedCidrs,omitempty" dynamodbav:"syslogAllowedCidrs,stringset"`
+
+	// For aws-organization integrations: the member accounts which are not onboarded. For the aws-scan integrations
+	// they onboard: the aws-organization integration, see SyncOrganizations
+	OrganizationExcludedAccounts []*string `json:"organizationExcludedAccounts,omitempty"`
+	OrganizationIntegrationID    *string   `json:"organizationIntegrationId,omitempty"`
+
+	// For pull sources: the secret their credentials are stored in, and when they were last stored.
+	// The credentials themselves are never returned
+	CredentialsSecretArn   *string    `json:"credentialsSecretArn,omitempty"`
+	CredentialsUpdatedTime *time.Time `json:"credentialsUpdatedTime,omitempty"`
+
+	// How far back the first scan of a log analysis integration lists the objects of its buckets, nil lists them all
+	BackfillStartTime *time.Time `json:"backfillStartTime,omitempty"`
+
+	// Roles assumed in order to reach the logs, instead of the log processing role
+	RoleChain []*string `json:"roleChain,omitempty"`
+	// The duration of the STS sessions of the role the logs are read with, nil means an hour. See SessionDuration
+	SessionDurationSeconds *int `json:"sessionDurationSeconds,omitempty"`
+
+	// Region of the buckets outside the region of Panther, by bucket name
+	S3BucketRegions map[string]*string `json:"s3BucketRegions"`
+	// The objects ingested from each bucket by bucket name, see S3ObjectFilter
+	S3ObjectFilters map[string]*S3ObjectFilter `json:"s3ObjectFilters,omitempty"`
+
+	// Limits the scanner must respect for this integration, nil means no limit
+	MaxConcurrentObjects *int `json:"maxConcurrentObjects,omitempty"`
+	MaxObjectsPerScan    *int `json:"maxObjectsPerScan,omitempty"`
+
+	// The order in which the log processor ingests the objects, nil means none, and how many it ingests at once,
+	// nil means no limit. See OrderingGroup
+	OrderingMode   *string `json:"orderingMode,omitempty"`
+	MaxParallelism *int    `json:"maxParallelism,omitempty"`
+
+	// Periods during which no scans run
+	BlackoutWindows []*BlackoutWindow `json:"blackoutWindows,omitempty"`
+
+	// A cron expression of when the integration is scanned in UTC, see CronSchedule. It replaces ScanIntervalMins
+	ScanSchedule *string `json:"scanSchedule,omitempty"`
+
+	// The regions of the scans of aws-scan integrations: only the enabled regions when there are some, minus the
+	// excluded regions. See ScanRegions
+	EnabledRegions  []*string `json:"enabledRegions,omitempty"`
+	ExcludedRegions []*string `json:"excludedRegions,omitempty"`
+
+	// The resource types of the scans of aws-scan integrations: only the allowed types when there are some, minus
+	// the denied types
+	ResourceTypeAllowList []*string `json:"resourceTypeAllowList,omitempty"`
+	ResourceTypeDenyList  []*string `json:"resourceTypeDenyList,omitempty"`
+
+	// Fields of the logs masked by the log processor before they are stored
+	RedactionRules []*RedactionRule `json:"redactionRules,omitempty"`
+
+	// Regular expressions selecting the object keys the scanner ingests, see KeyFilter
+	IncludePatterns []*string `json:"includePatterns,omitempty"`
+	ExcludePatterns []*string `json:"excludePatterns,omitempty"`
+
+	// Health sub-checks which are only reported as informational
+	DisabledChecks []*string `json:"disabledChecks,omitempty"`
+
+	// Experimental ingestion modes the integration is opted into, by flag
+	FeatureFlags map[string]bool `json:"featureFlags"`
+
+	// Shorter intervals between the first scans, after which ScanIntervalMins applies
+	ScanRampUp *ScanRampUp `json:"scanRampUp,omitempty"`
+
+	// Alerting suppresses duplicate findings from this source within the window, nil means no deduplication
+	DedupWindowMinutes *int `json:"dedupWindowMinutes,omitempty"`
+
+	// Largest log record the log processor accepts, nil means no limit, and how it handles the larger ones
+	MaxRecordBytes        *int    `json:"maxRecordBytes,omitempty"`
+	OversizedRecordPolicy *string `json:"oversizedRecordPolicy,omitempty"`
+
+	// Fraction of the log records the log processor ingests, nil means every record
+	SampleRate *float64 `json:"sampleRate,omitempty"`
+
+	// Fraction of the objects a scan may fail on before an alert is sent, nil means no alert on the error rate
+	ErrorRateThreshold *float64 `json:"errorRateThreshold,omitempty"`
+
+	// How long the integration may deliver no events, and the fraction of its log lines which may fail to classify,
+	// before an ingestion alarm is raised. Nil means no alarm
+	SilenceThresholdMinutes *int     `json:"silenceThresholdMinutes,omitempty"`
+	EventErrorRateThreshold *float64 `json:"eventErrorRateThreshold,omitempty"`
+
+	// The ingestion alarms raised and not resolved yet, an alert is only sent when they change
+	IngestionAlarms []*string `json:"ingestionAlarms,omitempty"`
+
+	// How the timestamp of the events is derived for a log layout Panther doesn't know, nil means the parser's
+	TimeExtraction *TimeExtraction `json:"timeExtraction,omitempty"`
+	// The tolerance for the events timestamped in the future or in the past, see ApplyTimestampSkew
+	TimestampSkew *TimestampSkew `json:"timestampSkew,omitempty"`
+
+	// Where the notifications the log processor failed to process are moved, nil means they are retried until they expire
+	DeadLetterQueue *DeadLetterQueue `json:"deadLetterQueue,omitempty"`
+
+	// Format of the archives the objects of the buckets are, which the ingestion expands, nil means plain objects
+	ArchiveFormat *string `json:"archiveFormat,omitempty"`
+
+	// Sensitivity of the data of the source: public, internal, confidential or restricted
+	DataClassification *string `json:"dataClassification,omitempty"`
+
+	// The region of the deployment which processes the integration, for data residency. When it isn't set the
+	// integration is processed where the source API is deployed
+	ProcessingRegion *string `json:"processingRegion,omitempty"`
+
+	// Result of the last health check, when the integration was saved or re-checked since
+	HealthStatus       *string   `json:"healthStatus,omitempty"`
+	FailedHealthChecks []*string `json:"failedHealthChecks,omitempty"`
+
+	// Result of the health check of the save which returned the integration, partialSuccess when it was saved
+	// degraded. Only returned by the save, it isn't stored
+	HealthCheckResult *string `json:"healthCheckResult,omitempty" dynamodbav:"-"`
+
+	// When the integration last passed a health check, not updated when it was only saved as degraded
+	LastHealthyTime *time.Time `json:"lastHealthyTime,omitempty"`
+
+	// When the health of the integration was last checked, whatever the result
+	LastHealthCheckTime *time.Time `json:"lastHealthCheckTime,omitempty"`
+
+	// When the earliest credential the integration depends on expires, e.g. key material imported into a
+	// KMS key, as of its last health check. Roles don't expire, it's unset when nothing does.
+	CredentialExpiry *time.Time `json:"credentialExpiry,omitempty"`
+
+	// IDs of the integrations this one depends on
+	DependsOn []*string `json:"dependsOn,omitempty"`
+
+	// IDs of the alert outputs notified of health changes, the default outputs are notified if there are none
+	NotificationTargets []*string `json:"notificationTargets,omitempty"`
+
+	// Names of the enrichment sources applied to the events of the integration by log processing
+	EnrichmentSources []*string `json:"enrichmentSources,omitempty"`
+
+	// Tags of the integration by key, e.g. "env": "dev", which select it for tag policies
+	Tags map[string]*string `json:"tags"`
+
+	// Severity the alerts on the events of the integration are raised with instead of the one of their rule.
+	//
+	// The matchers are rule IDs or log types, which may end with "*" to match a prefix, e.g. "AWS.CloudTrail.*".
+	// The alerting applies the override of the longest matcher.
+	SeverityOverrides map[string]*string `json:"severityOverrides,omitempty"`
+
+	// The endpoint a signed summary of each completed scan is posted to
+	ScanCompleteCallbackURL *string `json:"scanCompleteCallbackUrl,omitempty"`
+
+	// The key of the integration given to CreateOrUpdateIntegration, its ID is derived from it
+	ExternalKey *string `json:"externalKey,omitempty"`
+
+	// Incremented on every write, the ETag of the integration is derived from it
+	Version *int64 `json:"version,omitempty"`
+
+	// Only set on the integrations returned by a dry run, it's never stored
+	DryRunReport *DryRunReport `json:"dryRunReport,omitempty"`
+}
+
+// BlackoutWindow is a period during which the scheduler doesn't start scans of an integration.
+//
+// A one-off window sets Start and End. A recurring window sets DailyStart ("15:04" in UTC) and DurationMins,
+// it can be limited to some Weekdays (0 is Sunday) otherwise it recurs every day.
+type BlackoutWindow struct {
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+
+	DailyStart   *string `json:"dailyStart,omitempty"`
+	DurationMins *int    `json:"durationMins,omitempty" validate:"omitempty,min=1,max=1440"`
+	Weekdays     []int   `json:"weekdays,omitempty" validate:"omitempty,max=7,dive,min=0,max=6"`
+}
+
+// ScanRampUp shortens the intervals between the first scans of a new integration, so that it is backfilled quickly.
+//
+// The interval after the first scan is InitialIntervalMins, each following one is multiplied by the same factor
+// so that the interval after the given number of scans reaches the ScanIntervalMins of the integration.
+type ScanRampUp struct {
+	InitialIntervalMins *int `json:"initialIntervalMins" validate:"required,min=1,max=1440"`
+	Scans               *int `json:"scans" validate:"required,min=1,max=50"`
+}
+
+// RedactionRule masks a field of the logs of an integration before they are stored.
+//
+// FieldPath is a dot separated path in the log, where * matches any key or array element, e.g. "user.*.email".
+type RedactionRule struct {
+	FieldPath *string `json:"fieldPath" validate:"required,max=256,redactionFieldPath"`
+	Strategy  *string `json:"strategy" validate:"required,maskStrategy"`
+}
+
+// TimeExtraction derives the timestamp of the events of an integration, and the partition they are stored in.
+//
+// Either FieldPath locates the timestamp in each event, as a dot separated path of keys e.g. "meta.ts", or
+// KeyPattern is matched against the key of each object, the capture group named "date" holding its timestamp,
+// e.g. `^logs/(?P<date>\d{4}/\d{2}/\d{2})/`. TimeFormat parses the timestamp, it is either a Go time layout
+// e.g. "2006/01/02", or one of rfc3339, unix and unix_ms.
+type TimeExtraction struct {
+	FieldPath  *string `json:"fieldPath,omitempty" validate:"omitempty,max=256"`
+	KeyPattern *string `json:"keyPattern,omitempty" validate:"omitempty,max=256"`
+	TimeFormat *string `json:"timeFormat,omitempty" validate:"omitempty,max=64"`
+}
+
+// TimestampSkew bounds how far from the time they are processed the events of an integration can be timestamped.
+//
+// Sources with clock drift timestamp events in the future, which puts them in partitions that don't exist yet.
+// A bound which isn't set isn't enforced, the Policy applies to the events beyond the bounds.
+type TimestampSkew struct {
+	MaxFutureSkewMins *int    `json:"maxFutureSkewMins,omitempty" validate:"omitempty,min=0,max=10080"`
+	MaxPastSkewMins   *int    `json:"maxPastSkewMins,omitempty" validate:"omitempty,min=0,max=525600"`
+	Policy            *string `json:"policy,omitempty" validate:"omitempty,skewPolicy"`
+}
+
+// DeadLetterQueue is a queue of the customer the notifications of an integration are moved to, once the log
+// processor failed to process them MaxReceiveCount times.
+//
+// The log processing role sends the notifications, the queue must be a standard queue.
+type DeadLetterQueue struct {
+	QueueArn        *string `json:"queueArn,omitempty" validate:"omitempty,sqsQueueArn"`
+	MaxReceiveCount *int    `json:"maxReceiveCount,omitempty" validate:"omitempty,min=1,max=1000"`
+}
+
+// A dot separated path of keys, the wildcards of the redaction rules can't locate a single timestamp
+var timeFieldPathRegexp = regexp.MustCompile(`^[\w@-]+(\.[\w@-]+)*$`)
+
+// timeExtractionDateGroup is the name of the capture group of a KeyPattern which holds the timestamp.
+const timeExtractionDateGroup = "date"
+
+// Validate checks that a time extraction is complete and that its pattern and format can be applied.
+//
+// The error names the setting at fault and why, e.g. "timeExtraction.keyPattern: no capture group named date".
+func (extraction *TimeExtraction) Validate() error {
+	fieldPath, keyPattern := aws.StringValue(extraction.FieldPath), aws.StringValue(extraction.KeyPattern)
+	switch {
+	case fieldPath == "" && keyPattern == "":
+		return errors.New("timeExtraction: either fieldPath or keyPattern is required")
+	case fieldPath != "" && keyPattern != "":
+		return errors.New("timeExtraction: fieldPath and keyPattern are exclusive")
+	case fieldPath != "" && !timeFieldPathRegexp.MatchString(fieldPath):
+		return fmt.Errorf("timeExtraction.fieldPath: %q is not a dot separated path of keys", fieldPath)
+	case keyPattern != "":
+		if err := validateTimeKeyPattern(keyPattern); err != nil {
+			return fmt.Errorf("timeExtraction.keyPattern: %s", err)
+		}
+	}
+
+	switch format := aws.StringValue(extraction.TimeFormat); format {
+	case "":
+		return errors.New("timeExtraction.timeFormat: required")
+	case TimeFormatRFC3339, TimeFormatUnix, TimeFormatUnixMillis:
+		return nil
+	default:
+		// A layout without a date, e.g. "15:04" or a typo like "YYYY-MM-DD", parses every timestamp to year 0
+		reference := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+		parsed, err := time.Parse(format, reference.Format(format))
+		if err != nil || parsed.Year() != reference.Year() || parsed.YearDay() != reference.YearDay() {
+			return fmt.Errorf("timeExtraction.timeFormat: %q is not a time layout with a year, a month and a day, "+
+				"e.g. 2006-01-02", format)
+		}
+		return nil
+	}
+}
+
+func validateTimeKeyPattern(pattern string) error {
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return err
+	}
+	if nestedRepetition(parsed, false) {
+		return errors.New("nested repetitions are not supported")
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range compiled.SubexpNames() {
+		if name == timeExtractionDateGroup {
+			return nil
+		}
+	}
+	return fmt.Errorf("no capture group named %s, e.g. (?P<date>\\d{4}-\\d{2}-\\d{2})", timeExtractionDateGroup)
+}
+
+// FeatureEnabled returns true if the integration is opted into the experimental ingestion mode of the flag.
+func (metadata *SourceIntegrationMetadata) FeatureEnabled(flag string) bool {
+	return metadata.FeatureFlags[flag]
+}
+
+// SampleRecord returns true if a log record is ingested under the SampleRate of the integration.
+//
+// The draw is a random number in [0, 1), e.g. rand.Float64(), drawn for each record. Records are always
+// ingested without a SampleRate or with a SampleRate of 1.
+func (metadata *SourceIntegrationMetadata) SampleRecord(draw float64) bool {
+	return metadata.SampleRate == nil || draw < *metadata.SampleRate
+}
+
+// LimitRecord applies the MaxRecordBytes of the integration to a log record before it is parsed.
+//
+// A record within the limit is returned unchanged. A larger one is dropped (nil is returned), truncated,
+// or rejected with an error, according to the OversizedRecordPolicy.
+func (metadata *SourceIntegrationMetadata) LimitRecord(record []byte) ([]byte, error) {
+	if metadata.MaxRecordBytes == nil || len(record) <= *metadata.MaxRecordBytes {
+		return record, nil
+	}
+	switch aws.StringValue(metadata.OversizedRecordPolicy) {
+	case OversizedRecordTruncate:
+		return record[:*metadata.MaxRecordBytes], nil
+	case OversizedRecordError:
+		return nil, fmt.Errorf("record of %d bytes exceeds the maximum of %d bytes", len(record), *metadata.MaxRecordBytes)
+	default:
+		return nil, nil
+	}
+}
+
+// SessionDuration returns the duration of the STS sessions of the role the logs of the integration are read with.
+func (metadata *SourceIntegrationMetadata) SessionDuration() time.Duration {
+	if metadata.SessionDurationSeconds == nil {
+		return DefaultSessionDurationSeconds * time.Second
+	}
+	return time.Duration(*metadata.SessionDurationSeconds) * time.Second
+}
+
+// OrderingGroup returns the group of an object under the OrderingMode of the integration.
+//
+// The objects of a group are ingested one at a time in the order they were written, and different groups are
+// ingested in parallel, up to the MaxParallelism of the integration.
+func (metadata *SourceIntegrationMetadata) OrderingGroup(objectKey string) string {
+	switch aws.StringValue(metadata.OrderingMode) {
+	case OrderingModeStrict:
+		return ""
+	case OrderingModePerPrefix:
+		return objectKey[:strings.LastIndex(objectKey, "/")+1]
+	default:
+		return objectKey
+	}
+}
+
+// ApplyTimestampSkew applies the TimestampSkew of the integration to the timestamp of an event processed at now.
+//
+// A timestamp within the tolerance is returned unchanged. One beyond a bound is moved to it, or the event is
+// dropped (false is returned), or the timestamp is kept, according to the Policy.
+func (metadata *SourceIntegrationMetadata) ApplyTimestampSkew(timestamp, now time.Time) (time.Time, bool) {
+	skew := metadata.TimestampSkew
+	if skew == nil {
+		return timestamp, true
+	}
+	bound := timestamp
+	if skew.MaxFutureSkewMins != nil {
+		if latest := now.Add(time.Duration(*skew.MaxFutureSkewMins) * time.Minute); timestamp.After(latest) {
+			bound = latest
+		}
+	}
+	if skew.MaxPastSkewMins != nil {
+		if earliest := now.Add(-time.Duration(*skew.MaxPastSkewMins) * time.Minute); timestamp.Before(earliest) {
+			bound = earliest
+		}
+	}
+	switch {
+	case bound.Equal(timestamp) || aws.StringValue(skew.Policy) == SkewPolicyAccept:
+		return timestamp, true
+	case aws.StringValue(skew.Policy) == SkewPolicyDrop:
+		return timestamp, false
+	default:
+		return bound, true
+	}
+}
+
+// KeyFilter selects the object keys of an integration by its IncludePatterns and ExcludePatterns.
+type KeyFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// KeyFilter compiles the key patterns of the integration, the scanner filters the keys of a scan with it.
+//
+// The patterns are validated when they are saved, an error means the stored settings are corrupt.
+func (metadata *SourceIntegrationMetadata) KeyFilter() (*KeyFilter, error) {
+	var filter KeyFilter
+	for _, pattern := range metadata.IncludePatterns {
+		compiled, err := regexp.Compile(aws.StringValue(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %s", aws.StringValue(pattern), err)
+		}
+		filter.include = append(filter.include, compiled)
+	}
+	for _, pattern := range metadata.ExcludePatterns {
+		compiled, err := regexp.Compile(aws.StringValue(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %s", aws.StringValue(pattern), err)
+		}
+		filter.exclude = append(filter.exclude, compiled)
+	}
+	return &filter, nil
+}
+
+// Includes returns true if the object key is ingested.
+//
+// Excludes take precedence: a key matching any exclude pattern is skipped even if it matches an include pattern.
+// Otherwise the key must match one of the include patterns, unless there are none.
+func (filter *KeyFilter) Includes(key string) bool {
+	for _, pattern := range filter.exclude {
+		if pattern.MatchString(key) {
+			return false
+		}
+	}
+	if len(filter.include) == 0 {
+		return true
+	}
+	for _, pattern := range filter.include {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// S3ObjectFilter selects the objects of a bucket which are ingested by their key.
+//
+// A key is ingested when it starts with one of the prefixes and ends with one of the suffixes, an empty list
+// matches any key.
+type S3ObjectFilter struct {
+	Prefixes []*string `json:"prefixes,omitempty" validate:"omitempty,max=100,dive,required,max=1024"`
+	Suffixes []*string `json:"suffixes,omitempty" validate:"omitempty,max=100,dive,required,max=1024"`
+}
+
+// Includes returns true if the object key is ingested, a nil filter ingests every key.
+func (filter *S3ObjectFilter) Includes(key string) bool {
+	if filter == nil {
+		return true
+	}
+	return matchesAny(filter.Prefixes, func(prefix string) bool { return strings.HasPrefix(key, prefix) }) &&
+		matchesAny(filter.Suffixes, func(suffix string) bool { return strings.HasSuffix(key, suffix) })
+}
+
+// Overlaps returns true if some key is ingested by both filters.
+//
+// Two prefixes share keys when one starts with the other, and two suffixes when one ends with the other.
+func (filter *S3ObjectFilter) Overlaps(other *S3ObjectFilter) bool {
+	if filter == nil || other == nil {
+		return true
+	}
+	prefixes := func(a, b string) bool { return strings.HasPrefix(a, b) || strings.HasPrefix(b, a) }
+	suffixes := func(a, b string) bool { return strings.HasSuffix(a, b) || strings.HasSuffix(b, a) }
+	return sharesAny(filter.Prefixes, other.Prefixes, prefixes) && sharesAny(filter.Suffixes, other.Suffixes, suffixes)
+}
+
+// matchesAny returns true if any value matches, or if there are no values.
+func matchesAny(values []*string, match func(string) bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if match(aws.StringValue(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharesAny returns true if a value of the first list shares keys with one of the second, an empty list shares
+// every key.
+func sharesAny(first, second []*string, shares func(string, string) bool) bool {
+	return matchesAny(first, func(a string) bool {
+		return matchesAny(second, func(b string) bool { return shares(a, b) })
+	})
+}
+
+// BackfillIncludes returns true if the scan of the integration lists an object last modified at the given time,
+// PreviewMatchedObjects lists the objects of the first scan with it.
+//
+// Only the first scan is bounded by the BackfillStartTime of the integration: once a scan completed, every object
+// is listed whatever its age.
+func (integration *SourceIntegration) BackfillIncludes(lastModified time.Time) bool {
+	if integration.SourceIntegrationMetadata == nil || integration.BackfillStartTime == nil {
+		return true
+	}
+	if integration.SourceIntegrationScanInformation != nil && aws.IntValue(integration.CompletedScans) > 0 {
+		return true
+	}
+	return !lastModified.Before(*integration.BackfillStartTime)
 }
 
 // SourceIntegrationStatus provides context that the full scan works and that events are being received.
@@ -54,8 +593,44 @@ type SourceIntegrationScanInformation struct {
 	LastScanEndTime      *time.Time `json:"lastScanEndTime"`
 	LastScanErrorMessage *string    `json:"lastScanErrorMessage"`
 	LastScanStartTime    *time.Time `json:"lastScanStartTime"`
+	LastScanBookmark     *string    `json:"lastScanBookmark"`
+
+	// Set when the last scan stopped at the object cap of the integration before listing every object
+	LastScanTruncated        *bool  `json:"lastScanTruncated,omitempty"`
+	LastScanObjectsProcessed *int64 `json:"lastScanObjectsProcessed,omitempty"`
+	// Number of scans in a row truncated by the object cap
+	ConsecutiveTruncatedScans *int `json:"consecutiveTruncatedScans,omitempty"`
+	// Derived from ConsecutiveTruncatedScans: the scans don't keep up with the new objects
+	BacklogSuspected *bool `json:"backlogSuspected,omitempty"`
+	// Number of scans which ended since the integration was added, it drives the ScanRampUp
+	CompletedScans *int `json:"completedScans,omitempty"`
+	// Rolling average of how long the scans took, weighted towards the most recent ones
+	AverageScanDurationSecs *int64 `json:"averageScanDurationSecs,omitempty"`
+	// When the next scan is due, only maintained while the integration is enabled
+	NextScanTime *time.Time `json:"nextScanTime,omitempty"`
 }
 
+// ScanErrorSample is a record a scan failed on, e.g. a log line which couldn't be parsed.
+//
+// Records are truncated and redacted before they are stored.
+type ScanErrorSample struct {
+	ObjectKey    *string    `json:"objectKey,omitempty"`
+	Record       *string    `genericapi:"redact" json:"record" validate:"required"`
+	ErrorMessage *string    `json:"errorMessage,omitempty"`
+	ObservedAt   *time.Time `json:"observedAt,omitempty"`
+}
+
+// BacklogSuspectedAfter is the number of scans in a row truncated by the object cap which suggests a backlog.
+const BacklogSuspectedAfter = 3
+
+const (
+	// DefaultSessionDurationSeconds is the duration of the STS sessions of the roles the logs are read with,
+	// unless the integration sets its own.
+	DefaultSessionDurationSeconds = 3600
+	// MaxChainedSessionDurationSeconds is the longest session AWS issues for a role assumed by another role.
+	MaxChainedSessionDurationSeconds = 3600
+)
+
 type SourceIntegrationHealth struct {
 	AWSAccountID    *string `json:"awsAccountId"`
 	IntegrationType *string `json:"integrationType"`
@@ -69,13 +644,681 @@ type SourceIntegrationHealth struct {
 	ProcessingRoleStatus SourceIntegrationItemStatus            `json:"processingRoleStatus"`
 	S3BucketsStatus      map[string]SourceIntegrationItemStatus `json:"s3BucketsStatus"`
 	KMSKeysStatus        map[string]SourceIntegrationItemStatus `json:"kmsKeysStatus"`
+	KMSGrantsStatus      map[string]SourceIntegrationItemStatus `json:"kmsGrantsStatus"`
+	// Whether S3 was available in each region of the buckets, the buckets of an unavailable region are inconclusive
+	S3RegionsStatus map[string]SourceIntegrationItemStatus `json:"s3RegionsStatus"`
+
+	// For org trails: whether the account is a member of the organization of the management account,
+	// and whether the role can list the logs the trail delivered to each bucket
+	OrgTrailStatus        SourceIntegrationItemStatus            `json:"orgTrailStatus"`
+	OrgTrailBucketsStatus map[string]SourceIntegrationItemStatus `json:"orgTrailBucketsStatus"`
+
+	// Checks for Kinesis integrations: whether the stream is active and the role can read its records
+	KinesisStreamStatus SourceIntegrationItemStatus `json:"kinesisStreamStatus"`
+	// and whether the consumer the stream is read with is active
+	KinesisConsumerStatus SourceIntegrationItemStatus `json:"kinesisConsumerStatus"`
+
+	// Checks for Azure integrations: whether the service principal signs in, and whether it can read the subscription
+	AzureServicePrincipalStatus SourceIntegrationItemStatus `json:"azureServicePrincipalStatus"`
+	AzureSubscriptionStatus     SourceIntegrationItemStatus `json:"azureSubscriptionStatus"`
+
+	// Checks for GCP integrations: whether the service account signs in, and whether it can read the bucket or
+	// consume the subscription its logs are read from
+	GCPServiceAccountStatus SourceIntegrationItemStatus `json:"gcpServiceAccountStatus"`
+	GCPLogSourceStatus      SourceIntegrationItemStatus `json:"gcpLogSourceStatus"`
+
+	// Checks for SQS integrations: whether the queue the log events are sent to can be reached
+	SQSQueueStatus SourceIntegrationItemStatus `json:"sqsQueueStatus"`
+
+	// Checks for HTTP ingest integrations: whether the API key the log events are posted with is enabled
+	HTTPIngestKeyStatus SourceIntegrationItemStatus `json:"httpIngestKeyStatus"`
+
+	// Checks for Okta integrations: whether the API token can read the System Log of the organization
+	OktaSystemLogStatus SourceIntegrationItemStatus `json:"oktaSystemLogStatus"`
+
+	// Checks for aws-organization integrations: whether the organization role can be assumed, and whether the
+	// account manages the organization
+	OrganizationRoleStatus SourceIntegrationItemStatus `json:"organizationRoleStatus"`
+	OrganizationStatus     SourceIntegrationItemStatus `json:"organizationStatus"`
+
+	// Whether the role can reach the dead letter queue of the integration, and whether it's a standard queue
+	DeadLetterQueueStatus SourceIntegrationItemStatus `json:"deadLetterQueueStatus"`
+
+	// Status of each role of the role chain by role ARN, the ProcessingRoleStatus is the one of the whole chain
+	RoleChainStatus map[string]SourceIntegrationItemStatus `json:"roleChainStatus"`
+	// Whether the session duration of the integration is within the maximum session duration of the role the
+	// logs are read with
+	SessionDurationStatus SourceIntegrationItemStatus `json:"sessionDurationStatus"`
+
+	// Advisory on the policies of the role the logs are read with, always informational: it is unhealthy
+	// when they grant more than Panther needs, and the permissions in excess are listed
+	RolePolicyStatus  SourceIntegrationItemStatus `json:"rolePolicyStatus"`
+	ExcessPermissions []*ExcessPermission         `json:"excessPermissions,omitempty"`
+
+	// Sample read of an object of each reachable bucket: whether it could be read, and then decrypted
+	S3ObjectReadStatus    map[string]SourceIntegrationItemStatus `json:"s3ObjectReadStatus"`
+	S3ObjectDecryptStatus map[string]SourceIntegrationItemStatus `json:"s3ObjectDecryptStatus"`
+	// With an archive format, whether the sampled object of each bucket is an archive which can be expanded
+	S3ArchiveStatus map[string]SourceIntegrationItemStatus `json:"s3ArchiveStatus"`
+	// Whether the role can decrypt the objects of each reachable bucket with the KMS key of its default
+	// encryption. The keys the buckets are encrypted with which aren't among the kmsKeys are discovered
+	S3BucketEncryptionStatus map[string]SourceIntegrationItemStatus `json:"s3BucketEncryptionStatus"`
+	DiscoveredKmsKeys        []*string                              `json:"discoveredKmsKeys,omitempty"`
+
+	// When the earliest credential which was checked expires, e.g. the key material imported into a KMS key
+	CredentialExpiry *time.Time `json:"credentialExpiry,omitempty"`
+
+	// Sum of the latencies of all the sub-checks
+	TotalLatencyMillis *int64 `json:"totalLatencyMillis"`
 }
 
 type SourceIntegrationItemStatus struct {
-	Healthy      *bool   `json:"healthy"`
-	ErrorMessage *string `json:"errorMessage"`
+	Healthy       *bool   `json:"healthy"`
+	ErrorMessage  *string `json:"errorMessage"`
+	LatencyMillis *int64  `json:"latencyMillis,omitempty"`
+	// Set when the check could not be completed, e.g. it was throttled, and should be retried later
+	Inconclusive *bool `json:"inconclusive,omitempty"`
+	// Set when the check is disabled for the integration, its result doesn't affect the health
+	Informational *bool `json:"informational,omitempty"`
+}
+
+// ExcessPermission is an action on a resource granted by a policy of a role, which Panther doesn't need.
+type ExcessPermission struct {
+	Policy   *string `json:"policy"`
+	Action   *string `json:"action"`
+	Resource *string `json:"resource"`
+}
+
+// KmsKeyAliasChange reports a KMS alias which points to a different key than the one pinned.
+type KmsKeyAliasChange struct {
+	Alias      *string `json:"alias"`
+	PinnedArn  *string `json:"pinnedArn"`
+	CurrentArn *string `json:"currentArn"`
+}
+
+// AccountHealthSummary is the health of all the integrations of an AWS account.
+type AccountHealthSummary struct {
+	AWSAccountID     *string `json:"awsAccountId"`
+	IntegrationCount *int    `json:"integrationCount"`
+
+	// Number of integrations for each scan status
+	StatusCounts map[string]int `json:"statusCounts"`
+
+	// The worst scan status among the integrations of the account, nil if none have been scanned
+	WorstStatus *string `json:"worstStatus"`
+}
+
+// AccountTrustVerification is whether the roles of each integration of an AWS account can be assumed.
+//
+// Only the roles are verified, not what they can access: a passing integration can still fail its health check.
+type AccountTrustVerification struct {
+	AWSAccountID *string                   `json:"awsAccountId"`
+	Integrations []*IntegrationTrustStatus `json:"integrations"`
+	PassingCount *int                      `json:"passingCount"`
+	FailingCount *int                      `json:"failingCount"`
+}
+
+// IntegrationTrustStatus is whether the roles of an integration can be assumed.
+type IntegrationTrustStatus struct {
+	IntegrationID    *string `json:"integrationId"`
+	IntegrationLabel *string `json:"integrationLabel"`
+	IntegrationType  *string `json:"integrationType"`
+	Healthy          *bool   `json:"healthy"`
+
+	// The status of each role by ARN, the last role of a role chain stands for the whole chain
+	RoleStatuses map[string]SourceIntegrationItemStatus `json:"roleStatuses"`
+}
+
+// SilentIntegration is an enabled integration whose scans processed no objects over a period.
+type SilentIntegration struct {
+	IntegrationID    *string `json:"integrationId"`
+	IntegrationLabel *string `json:"integrationLabel"`
+	IntegrationType  *string `json:"integrationType"`
+
+	// Scans which ended within the period, none of which processed an object
+	ScansInPeriod *int `json:"scansInPeriod"`
+	// When the last scan of the integration ended, nil if it was never scanned
+	LastScanEndTime *time.Time `json:"lastScanEndTime,omitempty"`
+}
+
+// AccountHealthSummaryPage is a single page of account health summaries.
+type AccountHealthSummaryPage struct {
+	Accounts []*AccountHealthSummary `json:"accounts"`
+	Paging   *Paging                 `json:"paging"`
+}
+
+// Paging describes the position of a page in the full list of results.
+type Paging struct {
+	ThisPage   *int `json:"thisPage"`
+	TotalItems *int `json:"totalItems"`
+	TotalPages *int `json:"totalPages"`
+}
+
+// OnboardingCheck is a single step of the onboarding checklist.
+type OnboardingCheck struct {
+	Name         *string `json:"name"`
+	Status       *string `json:"status"`
+	ErrorMessage *string `json:"errorMessage,omitempty"`
+	// What the customer should do to make the check pass
+	NextStep *string `json:"nextStep,omitempty"`
+}
+
+// PipelineSelfTestResult is the progress of the end-to-end self test of an integration, its outcome once completed.
+type PipelineSelfTestResult struct {
+	// Unique to the self test, it is part of the synthetic log and of the key of the object holding it
+	TestID *string `json:"testId"`
+	// Unset while the synthetic log is still expected in the processed data
+	Completed *bool `json:"completed"`
+	Passed    *bool `json:"passed"`
+	// When the synthetic log was uploaded, and when the self test times out if it isn't processed by then
+	StartedAt *time.Time `json:"startedAt"`
+	Deadline  *time.Time `json:"deadline"`
+	// The first stage which didn't pass, if any
+	StalledStage *string                `json:"stalledStage,omitempty"`
+	Stages       []*PipelineStageStatus `json:"stages"`
+}
+
+// PipelineStageStatus is the outcome of a single stage of the self test, in the order the log goes through them.
+type PipelineStageStatus struct {
+	Stage         *string `json:"stage"`
+	Status        *string `json:"status"`
+	ErrorMessage  *string `json:"errorMessage,omitempty"`
+	LatencyMillis *int64  `json:"latencyMillis,omitempty"`
+}
+
+// ScanCostEstimate is a rough, advisory estimate of the cost of a scan configuration.
+//
+// It is based on a sample of the bucket listings and published S3 request prices: it is always approximate.
+type ScanCostEstimate struct {
+	Approximate    *bool  `json:"approximate"`
+	ObjectsPerScan *int64 `json:"objectsPerScan"`
+	BytesPerScan   *int64 `json:"bytesPerScan"`
+	ObjectsPerDay  *int64 `json:"objectsPerDay"`
+	// Set if a bucket holds more objects than were sampled, the real figures are then higher
+	SampleTruncated *bool `json:"sampleTruncated"`
+	// One of low, medium or high
+	CostBand *string `json:"costBand"`
+	// Approximate monthly cost of the S3 requests in USD
+	MonthlyRequestCostUSD *float64 `json:"monthlyRequestCostUsd"`
+}
+
+// PreviewMatchedObjectsOutput is a page of the objects an integration would ingest.
+type PreviewMatchedObjectsOutput struct {
+	Objects []*MatchedObject `json:"objects"`
+	// If it is populated there may be more matching objects, pass it as the ExclusiveStartKey of the next request
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// MatchedObject is an S3 object matching one of the bucket entries of an integration.
+type MatchedObject struct {
+	Bucket *string `json:"bucket"`
+	Key    *string `json:"key"`
+	Size   *int64  `json:"size"`
+}
+
+// GetIntegrationOutput is an integration along with its ETag.
+//
+// If the ETag matched the IfNoneMatch of the request, NotModified is set and the integration is omitted.
+type GetIntegrationOutput struct {
+	ETag        *string `json:"etag"`
+	NotModified *bool   `json:"notModified"`
+	// Set when the primary table was unavailable and the integration was read from its replica
+	PossiblyStale *bool              `json:"possiblyStale"`
+	Integration   *SourceIntegration `json:"integration,omitempty"`
+}
+
+// CreateOrUpdateIntegrationOutput is the integration which was upserted, and whether it was created.
+type CreateOrUpdateIntegrationOutput struct {
+	Integration *SourceIntegrationMetadata `json:"integration"`
+	Created     *bool                      `json:"created"`
+}
+
+// AccountManifestResult is the plan for one integration of an account manifest, and its outcome.
+type AccountManifestResult struct {
+	IntegrationID    *string `json:"integrationId,omitempty"`
+	IntegrationLabel *string `json:"integrationLabel"`
+	IntegrationType  *string `json:"integrationType"`
+	Action           *string `json:"action"`
+
+	// The settings which change, the ones of a deleted integration are not listed
+	Changes []*IntegrationFieldChange `json:"changes"`
+
+	// In a dry run, whether the integration passed its health check, otherwise whether the action succeeded
+	Success      *bool   `json:"success"`
+	ErrorMessage *string `json:"errorMessage,omitempty"`
+}
+
+// OrganizationSyncResult is the reconciliation of the member accounts of an aws-organization integration.
+//
+// The results list the aws-scan integrations created for the new member accounts and deleted for the ones
+// which left the organization or were excluded, the member accounts already onboarded are not listed.
+type OrganizationSyncResult struct {
+	IntegrationID  *string                  `json:"integrationId"`
+	OrganizationID *string                  `json:"organizationId,omitempty"`
+	Results        []*AccountManifestResult `json:"results"`
+	ErrorMessage   *string                  `json:"errorMessage,omitempty"`
+}
+
+// BulkSetScanIntervalResult is the outcome of a bulk scan interval update for one integration.
+type BulkSetScanIntervalResult struct {
+	IntegrationID *string `json:"integrationId"`
+	Success       *bool   `json:"success"`
+	ErrorMessage  *string `json:"errorMessage,omitempty"`
+}
+
+// PrefixMigration is the prefix configuration proposed for the buckets of an integration, and whether it was applied.
+//
+// Buckets whose prefixes can't be inferred, e.g. with objects at the top level, are proposed as they are.
+type PrefixMigration struct {
+	IntegrationID   *string   `json:"integrationId"`
+	CurrentBuckets  []*string `json:"currentBuckets"`
+	ProposedBuckets []*string `json:"proposedBuckets"`
+	Applied         *bool     `json:"applied"`
+	ErrorMessage    *string   `json:"errorMessage,omitempty"`
+}
+
+// IntegrationUpdateResult is the outcome of the update of one integration in a batch.
+type IntegrationUpdateResult struct {
+	IntegrationID *string            `json:"integrationId"`
+	Success       *bool              `json:"success"`
+	Integration   *SourceIntegration `json:"integration,omitempty"`
+	ErrorMessage  *string            `json:"errorMessage,omitempty"`
+}
+
+// ApplyTagPolicyOutput counts the integrations a tag policy was applied to.
+//
+// Matched integrations which already had the settings of the policy are neither updated nor failed.
+type ApplyTagPolicyOutput struct {
+	MatchedCount *int                         `json:"matchedCount"`
+	UpdatedCount *int                         `json:"updatedCount"`
+	FailedCount  *int                         `json:"failedCount"`
+	Failures     []*BulkSetScanIntervalResult `json:"failures"`
+}
+
+// HealthRecheckSummary counts the integrations by the result of their periodic health check.
+//
+// Integrations whose health check couldn't run are failures, their health is left as it was.
+type HealthRecheckSummary struct {
+	CheckedCount   *int                         `json:"checkedCount"`
+	HealthyCount   *int                         `json:"healthyCount"`
+	DegradedCount  *int                         `json:"degradedCount"`
+	UnhealthyCount *int                         `json:"unhealthyCount"`
+	Failures       []*BulkSetScanIntervalResult `json:"failures"`
+}
+
+// KmsKeyAliasRecheckSummary counts the integrations whose KMS aliases point to a different key than the pinned one.
+//
+// Integrations whose aliases couldn't be resolved are failures.
+type KmsKeyAliasRecheckSummary struct {
+	CheckedCount *int                         `json:"checkedCount"`
+	ChangedCount *int                         `json:"changedCount"`
+	Failures     []*BulkSetScanIntervalResult `json:"failures"`
+}
+
+// IngestionAlarmsSummary counts the ingestion alarms raised and resolved by a periodic check.
+//
+// Integrations whose metrics couldn't be read are failures, their alarms are left as they were.
+type IngestionAlarmsSummary struct {
+	CheckedCount  *int                         `json:"checkedCount"`
+	RaisedCount   *int                         `json:"raisedCount"`
+	ResolvedCount *int                         `json:"resolvedCount"`
+	Failures      []*BulkSetScanIntervalResult `json:"failures"`
+}
+
+// RequeueFailedObjectsOutput counts the failed objects which were re-submitted to the log processor.
+//
+// Failed objects which don't match any bucket of the integration can't be requeued, they are skipped.
+type RequeueFailedObjectsOutput struct {
+	RequeuedCount *int `json:"requeuedCount"`
+	SkippedCount  *int `json:"skippedCount"`
+}
+
+// Actions of a field change in a change set
+const (
+	FieldAdded   = "added"
+	FieldRemoved = "removed"
+	FieldChanged = "changed"
+)
+
+// IntegrationChangeSet is the difference between a desired configuration and the stored integration.
+type IntegrationChangeSet struct {
+	IntegrationID *string                   `json:"integrationId"`
+	Changes       []*IntegrationFieldChange `json:"changes"`
+	// Set when a setting verified by the health check changes, applying the change set may then fail
+	HealthCheckRequired *bool `json:"healthCheckRequired"`
+}
+
+// IntegrationComparison is the difference between the settings of an integration and those of a reference one.
+//
+// Each difference is the change which would make the reference integration configured like the other one:
+// Current is the setting of the reference integration and Desired the setting of the other integration.
+type IntegrationComparison struct {
+	ReferenceIntegrationID *string                   `json:"referenceIntegrationId"`
+	IntegrationID          *string                   `json:"integrationId"`
+	Differences            []*IntegrationFieldChange `json:"differences"`
+	Identical              *bool                     `json:"identical"`
+}
+
+// IntegrationFieldChange is the change of a single setting.
+//
+// Lists are diffed by element: every element added to or removed from the list is a separate change.
+type IntegrationFieldChange struct {
+	Field   *string     `json:"field"`
+	Action  *string     `json:"action"`
+	Current interface{} `json:"current"`
+	Desired interface{} `json:"desired"`
+}
+
+// IntegrationChangeHistoryPage is a page of the recorded changes of an integration, most recent first.
+type IntegrationChangeHistoryPage struct {
+	Changes []*IntegrationChangeRecord `json:"changes"`
+	// If it is populated there may be more changes, pass it as the ExclusiveStartKey of the next request
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// AuditLogSchemaVersion is the version of the format of the exported AuditLogEvents.
+const AuditLogSchemaVersion = 1
+
+// AuditLogEvent is a change made to an integration as it is exported to the audit log of the customer.
+//
+// The format is stable: a change of the format comes with a new AuditLogSchemaVersion.
+type AuditLogEvent struct {
+	SchemaVersion  *int                      `json:"schemaVersion"`
+	EventID        *string                   `json:"eventId"`
+	EventTime      *time.Time                `json:"eventTime"`
+	IntegrationID  *string                   `json:"integrationId"`
+	Actor          *string                   `json:"actor,omitempty"`
+	CallerIdentity *string                   `json:"callerIdentity,omitempty"`
+	Operation      *string                   `json:"operation"`
+	Changes        []*IntegrationFieldChange `json:"changes"`
+}
+
+// AuditLogExport is what an export of the audit log sent to its destination.
+type AuditLogExport struct {
+	Destination *string `json:"destination"`
+	EventCount  *int    `json:"eventCount"`
+	// The object the events were written to, for the s3 destination
+	Key *string `json:"key,omitempty"`
+	// If it is populated there are more events in the range, pass it as the Cursor of the next export
+	Cursor *string `json:"cursor,omitempty"`
+	// Set when the destination rejected events, the cursor is then the last event it accepted
+	ErrorMessage *string `json:"errorMessage,omitempty"`
+}
+
+// HealthReport is a snapshot of the health of an integration, meant to be shared outside of Panther.
+//
+// It holds no settings of the integration besides its identity, so that no secret is shared with it.
+type HealthReport struct {
+	GeneratedAt      *time.Time `json:"generatedAt"`
+	IntegrationID    *string    `json:"integrationId"`
+	IntegrationLabel *string    `json:"integrationLabel"`
+	IntegrationType  *string    `json:"integrationType"`
+	AWSAccountID     *string    `json:"awsAccountId"`
+
+	// Result of every sub-check of the health check run for the report
+	Health *SourceIntegrationHealth `json:"health"`
+
+	// Result of the health check when the integration was last saved
+	HealthStatus       *string    `json:"healthStatus,omitempty"`
+	FailedHealthChecks []*string  `json:"failedHealthChecks,omitempty"`
+	LastHealthyTime    *time.Time `json:"lastHealthyTime,omitempty"`
+
+	// The most recent health checks and scans, most recent first
+	History []*IntegrationHistoryRecord `json:"history"`
+}
+
+// DryRunReport is what a dry run of PutIntegration or UpdateIntegrationSettings found, nothing was written.
+//
+// Unlike the write, a dry run doesn't fail when the integration doesn't pass its health check: it is reported
+// unhealthy along with the checks which failed.
+type DryRunReport struct {
+	HealthStatus       *string   `json:"healthStatus,omitempty"`
+	FailedHealthChecks []*string `json:"failedHealthChecks,omitempty"`
+
+	// The result of each check, unset when the update only changes settings which need no health check
+	Health *SourceIntegrationHealth `json:"health,omitempty"`
+
+	// The changes the update would make, unset for a new integration
+	Changes []*IntegrationFieldChange `json:"changes,omitempty"`
+}
+
+// HealthReportLink is where a health report can be downloaded from until it expires.
+type HealthReportLink struct {
+	URL       *string    `json:"url"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// IntegrationHistoryRecord is a health check or a scan of an integration, kept for the retention period of the deployment.
+type IntegrationHistoryRecord struct {
+	IntegrationID *string    `json:"integrationId"`
+	RecordID      *string    `json:"recordId"`
+	Kind          *string    `json:"kind"`
+	RecordedAt    *time.Time `json:"recordedAt"`
+
+	// Set on the records of health checks
+	HealthStatus       *string   `json:"healthStatus,omitempty"`
+	FailedHealthChecks []*string `json:"failedHealthChecks,omitempty"`
+
+	// Set on the records of scans. The objects of aws-scan integrations are the resources they scanned
+	ScanStatus       *string    `json:"scanStatus,omitempty"`
+	ScanErrorMessage *string    `json:"scanErrorMessage,omitempty"`
+	ObjectsProcessed *int64     `json:"objectsProcessed,omitempty"`
+	ObjectsFailed    *int64     `json:"objectsFailed,omitempty"`
+	ScanTruncated    *bool      `json:"scanTruncated,omitempty"`
+	ScanStartTime    *time.Time `json:"scanStartTime,omitempty"`
+	ScanDurationSecs *int64     `json:"scanDurationSecs,omitempty"`
+
+	// Set on the records of pipeline self tests
+	SelfTest *PipelineSelfTestResult `json:"selfTest,omitempty"`
+
+	// When the record expires, in epoch seconds. DynamoDB deletes it some time after that
+	ExpiresAt *int64 `json:"expiresAt,omitempty"`
+}
+
+// IntegrationHistoryPage is a page of the health and scan history of an integration, most recent first.
+type IntegrationHistoryPage struct {
+	Records []*IntegrationHistoryRecord `json:"records"`
+	// If it is populated there may be more records, pass it as the ExclusiveStartKey of the next request
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// ScanHistory is the most recent scans of an integration, most recent first, with a summary of their outcome.
+type ScanHistory struct {
+	IntegrationID *string                     `json:"integrationId"`
+	Scans         []*IntegrationHistoryRecord `json:"scans"`
+
+	// How many of the scans failed, and their average duration among the scans whose duration is known
+	FailedScans             *int   `json:"failedScans"`
+	AverageScanDurationSecs *int64 `json:"averageScanDurationSecs,omitempty"`
+}
+
+// HistoryCompaction is the outcome of the compaction of the history of an integration.
+type HistoryCompaction struct {
+	IntegrationID  *string `json:"integrationId"`
+	RecordsRemoved *int    `json:"recordsRemoved"`
+	RecordsKept    *int    `json:"recordsKept"`
+}
+
+// ChangedIntegrationsPage is a page of the integrations changed since a time, the least recently changed first.
+//
+// Deleted integrations only have their ID, their type and the time of the deletion, with Deleted set.
+type ChangedIntegrationsPage struct {
+	Integrations []*SourceIntegration `json:"integrations"`
+	// If it is populated there may be more integrations, pass it as the ExclusiveStartKey of the next request
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// IntegrationsListing is the result of ListIntegrationsPages.
+//
+// When reading the table fails partway, the integrations read so far are returned and the listing is
+// marked incomplete: pass the ExclusiveStartKey to the next request to resume where it stopped.
+type IntegrationsListing struct {
+	Integrations []*SourceIntegration `json:"integrations"`
+	Incomplete   *bool                `json:"incomplete"`
+	ErrorMessage *string              `json:"errorMessage,omitempty"`
+	// Only populated for an incomplete listing
+	ExclusiveStartKey *string `json:"exclusiveStartKey,omitempty"`
+}
+
+// IntegrationsPage is a page of the integrations returned by ListIntegrationsPage.
+type IntegrationsPage struct {
+	Integrations []*SourceIntegration `json:"integrations"`
+	// If it is populated there may be more integrations, pass it as the ExclusiveStartKey of the next request
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// IntegrationsDueForScanPage is a page of the integrations whose next scan is due.
+type IntegrationsDueForScanPage struct {
+	Integrations []*SourceIntegration `json:"integrations"`
+	// If it is populated there may be more integrations, pass it as the ExclusiveStartKey of the next request
+	LastEvaluatedKey *string `json:"lastEvaluatedKey,omitempty"`
+}
+
+// ScanSchedulePreview is the timeline of the scans the scheduler would start in a window of time.
+//
+// The projected scans are sorted by time. Each scan is assumed to end as soon as it starts.
+type ScanSchedulePreview struct {
+	WindowStart *time.Time       `json:"windowStart"`
+	WindowEnd   *time.Time       `json:"windowEnd"`
+	Scans       []*ProjectedScan `json:"scans"`
+}
+
+// ProjectedScan is a scan of an integration the scheduler would start.
+type ProjectedScan struct {
+	IntegrationID    *string    `json:"integrationId"`
+	IntegrationLabel *string    `json:"integrationLabel"`
+	IntegrationType  *string    `json:"integrationType"`
+	ScanTime         *time.Time `json:"scanTime"`
+
+	// Whether a blackout window postponed the scan
+	PostponedByBlackout *bool `json:"postponedByBlackout"`
+}
+
+// TimeToHealthyEstimate is when data is expected to flow again from an integration after its configuration changed.
+//
+// It's an estimate: the next scan plus how long the scans of the integration usually take, or a conservative
+// default duration while the integration has no scan history.
+type TimeToHealthyEstimate struct {
+	IntegrationID *string `json:"integrationId"`
+
+	// Always true, the time is derived from the schedule and past scans rather than observed
+	Estimate *bool `json:"estimate"`
+
+	NextScanTime           *time.Time `json:"nextScanTime"`
+	ScanDurationSecs       *int64     `json:"scanDurationSecs"`
+	DefaultScanDuration    *bool      `json:"defaultScanDuration"`
+	EstimatedDataFlowingBy *time.Time `json:"estimatedDataFlowingBy"`
+}
+
+// ScanIntervalRecommendation is the scan interval suggested for an integration from the volume of its scans.
+//
+// It's advisory, the configuration of the integration is left as it is. The reasons explain the recommendation
+// in the order they were considered.
+type ScanIntervalRecommendation struct {
+	IntegrationID *string `json:"integrationId"`
+
+	// Always true, nothing is changed
+	Advisory *bool `json:"advisory"`
+
+	CurrentIntervalMins     *int      `json:"currentIntervalMins"`
+	RecommendedIntervalMins *int      `json:"recommendedIntervalMins"`
+	ObjectsPerHour          *int64    `json:"objectsPerHour,omitempty"`
+	Reasons                 []*string `json:"reasons"`
+}
+
+// EffectiveIntegrationConfig is how an integration behaves once all of its settings are resolved.
+//
+// The scan interval is the one of the ramp-up while it lasts, the next scan is postponed past the blackout
+// windows, and the settings which aren't set are reported with the default they fall back to.
+type EffectiveIntegrationConfig struct {
+	IntegrationID   *string `json:"integrationId"`
+	IntegrationType *string `json:"integrationType"`
+
+	// Whether the scheduler starts the scans of the integration
+	Scheduled *bool `json:"scheduled"`
+
+	// The configured interval between scans, and the one before the next scan which is shorter during the ramp-up
+	ScanIntervalMins          *int  `json:"scanIntervalMins,omitempty"`
+	EffectiveScanIntervalMins *int  `json:"effectiveScanIntervalMins,omitempty"`
+	RampingUp                 *bool `json:"rampingUp"`
+
+	// When the next scan is due, nil if the integration isn't scheduled, and whether a blackout window postponed it
+	NextScanTime        *time.Time `json:"nextScanTime,omitempty"`
+	PostponedByBlackout *bool      `json:"postponedByBlackout"`
+
+	// The feature flags and the optional behaviors which are on, sorted
+	Features []*string `json:"features"`
+
+	// Only set when they apply: the handling of oversized records once there is a limit, and the reading
+	// position of Kinesis streams
+	OversizedRecordPolicy *string `json:"oversizedRecordPolicy,omitempty"`
+	ShardIteratorType     *string `json:"shardIteratorType,omitempty"`
+}
+
+// IntegrationChangeRecord is a change made to an integration, and who made it.
+type IntegrationChangeRecord struct {
+	IntegrationID *string    `json:"integrationId"`
+	ChangeID      *string    `json:"changeId"`
+	ChangedAt     *time.Time `json:"changedAt"`
+	ChangedBy     *string    `json:"changedBy,omitempty"`
+	// Identity of the caller of the API which made the change, e.g. the service on behalf of the user
+	CallerIdentity *string                   `json:"callerIdentity,omitempty"`
+	Operation      *string                   `json:"operation"`
+	Changes        []*IntegrationFieldChange `json:"changes"`
+}
+
+// PendingRetry is a side effect of a change to an integration which failed, it is retried with a backoff until
+// it succeeds. Retrying a side effect reconciles the integration with its current settings.
+type PendingRetry struct {
+	IntegrationID   *string    `json:"integrationId"`
+	SideEffect      *string    `json:"sideEffect"`
+	EnqueuedAt      *time.Time `json:"enqueuedAt"`
+	Attempts        *int       `json:"attempts"`
+	NextAttemptTime *time.Time `json:"nextAttemptTime"`
+	LastError       *string    `json:"lastError"`
+}
+
+// PurgedIntegrations lists the soft-deleted integrations a run of PurgeDeletedIntegrations purged.
+type PurgedIntegrations struct {
+	IntegrationIDs []*string `json:"integrationIds"`
+}
+
+// SideEffectRetries is the outcome of a run of the retries which were due.
+type SideEffectRetries struct {
+	Attempted  *int `json:"attempted"`
+	Reconciled *int `json:"reconciled"`
+	Pending    *int `json:"pending"`
 }
 
 type SourceIntegrationTemplate struct {
 	Body *string `json:"body"`
 }
+
+// IntegrationsExport is the object written by an export of all the integrations.
+type IntegrationsExport struct {
+	Bucket           *string `json:"bucket"`
+	Key              *string `json:"key"`
+	IntegrationCount *int    `json:"integrationCount"`
+}
+
+// ExportedIntegrations is the content of an export, the settings of every integration as of ExportedAt.
+type ExportedIntegrations struct {
+	ExportedAt   *time.Time                   `json:"exportedAt"`
+	Integrations []*SourceIntegrationMetadata `json:"integrations"`
+}
+
+// ScanCompleteSummary is posted to the scan complete callback of an integration when one of its scans ends.
+type ScanCompleteSummary struct {
+	IntegrationID    *string    `json:"integrationId"`
+	ScanID           *string    `json:"scanId,omitempty"`
+	ScanStatus       *string    `json:"scanStatus"`
+	ScanEndTime      *time.Time `json:"scanEndTime"`
+	ObjectsProcessed *int64     `json:"objectsProcessed,omitempty"`
+	ErrorCount       *int       `json:"errorCount"`
+	Truncated        *bool      `json:"truncated"`
+}
+
+// SourceIntegrationPolicyDocument is an IAM policy document in JSON.
+type SourceIntegrationPolicyDocument struct {
+	Body *string `json:"body"`
+}
@@ -0,0 +1,178 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// IntegrationTypeCapabilities describes which settings an integration type supports.
+type IntegrationTypeCapabilities struct {
+	IntegrationType     *string   `json:"integrationType"`
+	SupportsCWE         *bool     `json:"supportsCWE"`
+	SupportsRemediation *bool     `json:"supportsRemediation"`
+	SupportsPrefixes    *bool     `json:"supportsPrefixes"`
+	SupportsOrgTrail    *bool     `json:"supportsOrgTrail"`
+	SupportsStreams     *bool     `json:"supportsStreams"`
+	SupportsRoleChain   *bool     `json:"supportsRoleChain"`
+	SupportsQueues      *bool     `json:"supportsQueues"`
+	RequiredFields      []*string `json:"requiredFields"`
+	// The names of the credentials of the pull sources, see PutIntegrationSettings.Credentials
+	CredentialFields []*string `json:"credentialFields,omitempty"`
+	ExactlyOneOf     []*string `json:"exactlyOneOf,omitempty"`
+}
+
+type integrationTypeCapabilities struct {
+	integrationType     string
+	supportsCWE         bool
+	supportsRemediation bool
+	supportsPrefixes    bool
+	supportsOrgTrail    bool
+	supportsStreams     bool
+	supportsRoleChain   bool
+	supportsQueues      bool
+	// JSON names of the PutIntegrationSettings fields which must be set
+	requiredFields []string
+	// JSON names of the PutIntegrationSettings fields of which exactly one must be set
+	exactlyOneOf []string
+	// Names of the credentials the logs are pulled with, every one of them must be set
+	credentialFields []string
+}
+
+// integrationTypes is the feature matrix of the integration types, the validators are derived from it.
+var integrationTypes = []integrationTypeCapabilities{
+	{
+		integrationType:     IntegrationTypeAWSScan,
+		supportsCWE:         true,
+		supportsRemediation: true,
+		requiredFields:      []string{"awsAccountId"},
+	},
+	{
+		integrationType:   IntegrationTypeAWS3,
+		supportsPrefixes:  true,
+		supportsOrgTrail:  true,
+		supportsRoleChain: true,
+		requiredFields:    []string{"awsAccountId", "s3Buckets"},
+	},
+	{
+		integrationType:   IntegrationTypeAWSKinesis,
+		supportsStreams:   true,
+		supportsRoleChain: true,
+		requiredFields:    []string{"awsAccountId", "streamArn"},
+	},
+	{
+		integrationType: IntegrationTypeAzureScan,
+		requiredFields:  []string{"azureTenantId", "azureSubscriptionId", "azureClientId", "azureClientSecretArn"},
+	},
+	{
+		integrationType: IntegrationTypeGCPLogSource,
+		requiredFields:  []string{"gcpProjectId", "gcpServiceAccountSecretArn"},
+		exactlyOneOf:    []string{"gcsBucket", "pubSubSubscription"},
+	},
+	{
+		integrationType: IntegrationTypeSQSQueue,
+		supportsQueues:  true,
+		requiredFields:  []string{"awsAccountId"},
+	},
+	{
+		integrationType: IntegrationTypeHTTPIngest,
+		requiredFields:  []string{},
+	},
+	{
+		integrationType:  IntegrationTypeOkta,
+		requiredFields:   []string{"oktaDomain"},
+		credentialFields: []string{"apiToken"},
+	},
+	{
+		integrationType: IntegrationTypeSyslog,
+		requiredFields:  []string{"syslogAllowedCidrs"},
+	},
+	{
+		// The member accounts are onboarded with the CWE and remediation settings of the organization
+		integrationType:     IntegrationTypeAWSOrganization,
+		supportsCWE:         true,
+		supportsRemediation: true,
+		requiredFields:      []string{"awsAccountId"},
+	},
+}
+
+// IntegrationTypes returns the capabilities of every supported integration type.
+func IntegrationTypes() []*IntegrationTypeCapabilities {
+	result := make([]*IntegrationTypeCapabilities, len(integrationTypes))
+	for i, capabilities := range integrationTypes {
+		result[i] = &IntegrationTypeCapabilities{
+			IntegrationType:     aws.String(capabilities.integrationType),
+			SupportsCWE:         aws.Bool(capabilities.supportsCWE),
+			SupportsRemediation: aws.Bool(capabilities.supportsRemediation),
+			SupportsPrefixes:    aws.Bool(capabilities.supportsPrefixes),
+			SupportsOrgTrail:    aws.Bool(capabilities.supportsOrgTrail),
+			SupportsStreams:     aws.Bool(capabilities.supportsStreams),
+			SupportsRoleChain:   aws.Bool(capabilities.supportsRoleChain),
+			SupportsQueues:      aws.Bool(capabilities.supportsQueues),
+			RequiredFields:      aws.StringSlice(capabilities.requiredFields),
+		}
+		if len(capabilities.exactlyOneOf) > 0 {
+			result[i].ExactlyOneOf = aws.StringSlice(capabilities.exactlyOneOf)
+		}
+		if len(capabilities.credentialFields) > 0 {
+			result[i].CredentialFields = aws.StringSlice(capabilities.credentialFields)
+		}
+	}
+	return result
+}
+
+func lookupIntegrationType(integrationType string) *integrationTypeCapabilities {
+	for i := range integrationTypes {
+		if integrationTypes[i].integrationType == integrationType {
+			return &integrationTypes[i]
+		}
+	}
+	return nil
+}
+
+// CheckCredentialFields returns whether the credentials are exactly those of the integration type, and otherwise
+// the first of them which is missing or unknown, as "credentials.name".
+//
+// Integration types without credential fields don't have credentials.
+func CheckCredentialFields(integrationType string, credentials map[string]*string) (string, bool) {
+	capabilities := lookupIntegrationType(integrationType)
+	if capabilities == nil || len(capabilities.credentialFields) == 0 {
+		return "credentials", credentials == nil
+	}
+	known := make(map[string]struct{}, len(capabilities.credentialFields))
+	for _, field := range capabilities.credentialFields {
+		if credentials[field] == nil {
+			return "credentials." + field, false
+		}
+		known[field] = struct{}{}
+	}
+	names := make([]string, 0, len(credentials))
+	for name := range credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			return "credentials." + name, false
+		}
+	}
+	return "", true
+}
@@ -19,7 +19,14 @@ package models
  */
 
 import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -29,6 +36,71 @@ func Validator() (*validator.Validate, error) {
 	if err := result.RegisterValidation("roleArn", validateRoleArn); err != nil {
 		return nil, err
 	}
+	if err := result.RegisterValidation("integrationType", validateIntegrationType); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("scanInterval", validateScanInterval); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("cronSchedule", validateCronSchedule); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("awsRegion", validateAWSRegion); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("redactionFieldPath", validateRedactionFieldPath); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("maskStrategy", validateMaskStrategy); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("disableableHealthCheck", validateDisableableHealthCheck); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("featureFlag", validateFeatureFlag); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("oversizedRecordPolicy", validateOversizedRecordPolicy); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("skewPolicy", validateSkewPolicy); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("orderingMode", validateOrderingMode); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("archiveFormat", validateArchiveFormat); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("dataClassification", validateDataClassification); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("severity", validateSeverity); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("kinesisStreamArn", validateKinesisStreamArn); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("sqsQueueArn", validateSQSQueueArn); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("keyPattern", validateKeyPattern); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("secretArn", validateSecretArn); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("gcpProjectId", validateGCPProjectID); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("pubSubSubscription", validatePubSubSubscription); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("credentials", validateCredentials); err != nil {
+		return nil, err
+	}
+	result.RegisterStructValidation(validatePutIntegrationSettings, PutIntegrationSettings{})
+	result.RegisterStructValidation(validateBlackoutWindow, BlackoutWindow{})
 	return result, nil
 }
 
@@ -36,3 +108,357 @@ func validateRoleArn(fl validator.FieldLevel) bool {
 	fieldArn, err := arn.Parse(fl.Field().String())
 	return err == nil && fieldArn.Service == "iam"
 }
+
+// validateKinesisStreamArn accepts the ARN of a Kinesis data stream, e.g. arn:aws:kinesis:us-west-2:123456789012:stream/logs
+func validateKinesisStreamArn(fl validator.FieldLevel) bool {
+	fieldArn, err := arn.Parse(fl.Field().String())
+	return err == nil && fieldArn.Service == "kinesis" && strings.HasPrefix(fieldArn.Resource, "stream/") &&
+		len(fieldArn.Resource) > len("stream/")
+}
+
+func validateSQSQueueArn(fl validator.FieldLevel) bool {
+	fieldArn, err := arn.Parse(fl.Field().String())
+	return err == nil && fieldArn.Service == "sqs" && fieldArn.Region != "" && fieldArn.AccountID != "" &&
+		fieldArn.Resource != "" && !strings.Contains(fieldArn.Resource, ":")
+}
+
+func validateSecretArn(fl validator.FieldLevel) bool {
+	fieldArn, err := arn.Parse(fl.Field().String())
+	return err == nil && fieldArn.Service == "secretsmanager" && fieldArn.Region != "" && fieldArn.AccountID != "" &&
+		strings.HasPrefix(fieldArn.Resource, "secret:")
+}
+
+// GCP project IDs are 6 to 30 lowercase letters, digits or hyphens, starting with a letter
+const gcpProjectIDPattern = `[a-z][a-z0-9-]{4,28}[a-z0-9]`
+
+var (
+	gcpProjectIDRegexp       = regexp.MustCompile(`^` + gcpProjectIDPattern + `$`)
+	pubSubSubscriptionRegexp = regexp.MustCompile(`^projects/` + gcpProjectIDPattern + `/subscriptions/[A-Za-z][\w.~+%-]{2,254}$`)
+)
+
+func validateGCPProjectID(fl validator.FieldLevel) bool {
+	return gcpProjectIDRegexp.MatchString(fl.Field().String())
+}
+
+// validatePubSubSubscription accepts the name of a Pub/Sub subscription, e.g. projects/my-project/subscriptions/audit-logs
+func validatePubSubSubscription(fl validator.FieldLevel) bool {
+	return pubSubSubscriptionRegexp.MatchString(fl.Field().String())
+}
+
+// The limits of the credentials of an integration, they are stored together in a single secret
+const (
+	maxCredentials      = 10
+	maxCredentialName   = 64
+	maxCredentialLength = 10240
+)
+
+// validateCredentials checks the credentials are named, and that none of them is empty.
+func validateCredentials(fl validator.FieldLevel) bool {
+	credentials, ok := fl.Field().Interface().(map[string]*string)
+	if !ok || len(credentials) == 0 || len(credentials) > maxCredentials {
+		return false
+	}
+	for name, value := range credentials {
+		if name == "" || len(name) > maxCredentialName || aws.StringValue(value) == "" || len(*value) > maxCredentialLength {
+			return false
+		}
+	}
+	return true
+}
+
+func validateIntegrationType(fl validator.FieldLevel) bool {
+	return lookupIntegrationType(fl.Field().String()) != nil
+}
+
+// ScanIntervalsMins are the supported intervals between the scans of an integration, from the shortest.
+var ScanIntervalsMins = []int64{60, 180, 360, 720, 1440}
+
+func validateScanInterval(fl validator.FieldLevel) bool {
+	for _, interval := range ScanIntervalsMins {
+		if fl.Field().Int() == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCronSchedule accepts the cron expressions which scan at most once an hour, like the shortest scan interval.
+func validateCronSchedule(fl validator.FieldLevel) bool {
+	schedule, err := ParseCronSchedule(fl.Field().String())
+	return err == nil && schedule.RunsPerHour() == 1
+}
+
+// validateAWSRegion accepts the regions of every partition known to the SDK, e.g. "eu-west-1".
+func validateAWSRegion(fl validator.FieldLevel) bool {
+	for _, partition := range endpoints.DefaultPartitions() {
+		if _, ok := partition.Regions()[fl.Field().String()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// A dot separated path of keys, where * matches any key or array element
+var redactionFieldPathRegexp = regexp.MustCompile(`^([\w@-]+|\*)(\.([\w@-]+|\*))*$`)
+
+func validateRedactionFieldPath(fl validator.FieldLevel) bool {
+	return redactionFieldPathRegexp.MatchString(fl.Field().String())
+}
+
+// maskStrategies are the ways the log processor can mask a field.
+var maskStrategies = []string{MaskStrategyRedact, MaskStrategyHash, MaskStrategyPartial, MaskStrategyRemove}
+
+func validateMaskStrategy(fl validator.FieldLevel) bool {
+	for _, strategy := range maskStrategies {
+		if fl.Field().String() == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// disableableHealthChecks are the health sub-checks an integration can opt out of.
+//
+// The checks of the roles are security critical, they always apply.
+var disableableHealthChecks = []string{HealthCheckS3Buckets, HealthCheckKMSKeys, HealthCheckS3Objects}
+
+func validateDisableableHealthCheck(fl validator.FieldLevel) bool {
+	for _, check := range disableableHealthChecks {
+		if fl.Field().String() == check {
+			return true
+		}
+	}
+	return false
+}
+
+// featureFlags are the experimental ingestion modes an integration can be opted into.
+var featureFlags = []string{FeatureFlagParserV2, FeatureFlagBetaEnrichment}
+
+func validateFeatureFlag(fl validator.FieldLevel) bool {
+	for _, flag := range featureFlags {
+		if fl.Field().String() == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// oversizedRecordPolicies are the ways the log processor can handle a record larger than the MaxRecordBytes.
+var oversizedRecordPolicies = []string{OversizedRecordDrop, OversizedRecordTruncate, OversizedRecordError}
+
+func validateOversizedRecordPolicy(fl validator.FieldLevel) bool {
+	for _, policy := range oversizedRecordPolicies {
+		if fl.Field().String() == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// orderingModes are the ordering guarantees the log processor can give for the objects of an integration.
+var orderingModes = []string{OrderingModeNone, OrderingModePerPrefix, OrderingModeStrict}
+
+func validateOrderingMode(fl validator.FieldLevel) bool {
+	for _, mode := range orderingModes {
+		if fl.Field().String() == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// skewPolicies are the ways the log processor can handle an event timestamped outside the TimestampSkew.
+var skewPolicies = []string{SkewPolicyClamp, SkewPolicyDrop, SkewPolicyAccept}
+
+func validateSkewPolicy(fl validator.FieldLevel) bool {
+	for _, policy := range skewPolicies {
+		if fl.Field().String() == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveFormats are the formats of the archives of logs the ingestion can expand.
+var archiveFormats = []string{ArchiveFormatZip, ArchiveFormatTar, ArchiveFormatTarGzip}
+
+func validateArchiveFormat(fl validator.FieldLevel) bool {
+	for _, format := range archiveFormats {
+		if fl.Field().String() == format {
+			return true
+		}
+	}
+	return false
+}
+
+// dataClassifications are the levels of sensitivity of the data of a source, from the least sensitive.
+var dataClassifications = []string{
+	DataClassificationPublic, DataClassificationInternal, DataClassificationConfidential, DataClassificationRestricted,
+}
+
+func validateDataClassification(fl validator.FieldLevel) bool {
+	for _, level := range dataClassifications {
+		if fl.Field().String() == level {
+			return true
+		}
+	}
+	return false
+}
+
+// severities are the severities of the alerts, from the lowest.
+var severities = []string{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+func validateSeverity(fl validator.FieldLevel) bool {
+	for _, severity := range severities {
+		if fl.Field().String() == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds the size of a compiled key pattern, e.g. against large counted repetitions like (a{1,100}){1,100}
+const maxKeyPatternInstructions = 1000
+
+// validateKeyPattern accepts the regular expressions which compile and stay simple enough to match every key
+// of a scan: nested repetitions such as (a+)+, which backtrack catastrophically in most regex engines, are
+// rejected as well so that the patterns behave the same in every consumer of the settings.
+func validateKeyPattern(fl validator.FieldLevel) bool {
+	parsed, err := syntax.Parse(fl.Field().String(), syntax.Perl)
+	if err != nil || nestedRepetition(parsed, false) {
+		return false
+	}
+	program, err := syntax.Compile(parsed.Simplify())
+	return err == nil && len(program.Inst) <= maxKeyPatternInstructions
+}
+
+// nestedRepetition returns true if a repeated sub-expression of the regular expression contains a repetition.
+func nestedRepetition(expr *syntax.Regexp, repeated bool) bool {
+	repeats := expr.Op == syntax.OpStar || expr.Op == syntax.OpPlus ||
+		(expr.Op == syntax.OpRepeat && expr.Max != 1)
+	if repeats && repeated {
+		return true
+	}
+	for _, sub := range expr.Sub {
+		if nestedRepetition(sub, repeated || repeats) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePutIntegrationSettings checks the settings against the capabilities of the integration type.
+func validatePutIntegrationSettings(sl validator.StructLevel) {
+	settings := sl.Current().Interface().(PutIntegrationSettings)
+	capabilities := lookupIntegrationType(aws.StringValue(settings.IntegrationType))
+	if capabilities == nil {
+		// Reported by the field validation
+		return
+	}
+
+	if aws.BoolValue(settings.CWEEnabled) && !capabilities.supportsCWE {
+		sl.ReportError(settings.CWEEnabled, "cweEnabled", "CWEEnabled", "supportsCWE", "")
+	}
+	if aws.BoolValue(settings.RemediationEnabled) && !capabilities.supportsRemediation {
+		sl.ReportError(settings.RemediationEnabled, "remediationEnabled", "RemediationEnabled", "supportsRemediation", "")
+	}
+	if !capabilities.supportsPrefixes {
+		for _, bucket := range settings.S3Buckets {
+			if strings.Contains(aws.StringValue(bucket), "/") {
+				sl.ReportError(settings.S3Buckets, "s3Buckets", "S3Buckets", "supportsPrefixes", "")
+				break
+			}
+		}
+	}
+	if len(settings.S3ObjectFilters) > 0 && !capabilities.supportsPrefixes {
+		sl.ReportError(settings.S3ObjectFilters, "s3ObjectFilters", "S3ObjectFilters", "supportsPrefixes", "")
+	}
+
+	if aws.BoolValue(settings.IsOrgTrail) && !capabilities.supportsOrgTrail {
+		sl.ReportError(settings.IsOrgTrail, "isOrgTrail", "IsOrgTrail", "supportsOrgTrail", "")
+	}
+	if aws.BoolValue(settings.IsOrgTrail) && settings.ManagementAccountID == nil {
+		sl.ReportError(settings.ManagementAccountID, "managementAccountId", "ManagementAccountID", "required", "")
+	}
+
+	if (settings.StreamARN != nil || settings.ShardIteratorType != nil) && !capabilities.supportsStreams {
+		sl.ReportError(settings.StreamARN, "streamArn", "StreamARN", "supportsStreams", "")
+	}
+	if settings.RoleChain != nil && !capabilities.supportsRoleChain {
+		sl.ReportError(settings.RoleChain, "roleChain", "RoleChain", "supportsRoleChain", "")
+	}
+	if settings.SQSQueueArn != nil && !capabilities.supportsQueues {
+		sl.ReportError(settings.SQSQueueArn, "sqsQueueArn", "SQSQueueArn", "supportsQueues", "")
+	}
+	if settings.Credentials != nil || len(capabilities.credentialFields) > 0 {
+		if field, ok := CheckCredentialFields(aws.StringValue(settings.IntegrationType), settings.Credentials); !ok {
+			sl.ReportError(settings.Credentials, field, "Credentials", "credentialFields", "")
+		}
+	}
+
+	fields := map[string]bool{
+		"awsAccountId":     settings.AWSAccountID != nil,
+		"integrationLabel": settings.IntegrationLabel != nil,
+		"s3Buckets":        len(settings.S3Buckets) > 0,
+		"streamArn":        settings.StreamARN != nil,
+
+		"azureTenantId":        settings.AzureTenantID != nil,
+		"azureSubscriptionId":  settings.AzureSubscriptionID != nil,
+		"azureClientId":        settings.AzureClientID != nil,
+		"azureClientSecretArn": settings.AzureClientSecretArn != nil,
+
+		"gcpProjectId":               settings.GCPProjectID != nil,
+		"gcpServiceAccountSecretArn": settings.GCPServiceAccountSecretArn != nil,
+		"gcsBucket":                  settings.GCSBucket != nil,
+		"pubSubSubscription":         settings.PubSubSubscription != nil,
+
+		"oktaDomain": settings.OktaDomain != nil,
+
+		"syslogAllowedCidrs": len(settings.SyslogAllowedCIDRs) > 0,
+	}
+	for _, field := range capabilities.requiredFields {
+		if !fields[field] {
+			sl.ReportError(nil, field, field, "required", "")
+		}
+	}
+	if len(capabilities.exactlyOneOf) > 0 {
+		set := 0
+		for _, field := range capabilities.exactlyOneOf {
+			if fields[field] {
+				set++
+			}
+		}
+		if set != 1 {
+			field := strings.Join(capabilities.exactlyOneOf, "|")
+			sl.ReportError(nil, field, field, "exactlyOneOf", "")
+		}
+	}
+}
+
+// BlackoutDailyStartLayout is the time layout of BlackoutWindow.DailyStart.
+const BlackoutDailyStartLayout = "15:04"
+
+// validateBlackoutWindow checks that a window is either a well formed one-off or recurring window.
+func validateBlackoutWindow(sl validator.StructLevel) {
+	window := sl.Current().Interface().(BlackoutWindow)
+	oneOff := window.Start != nil || window.End != nil
+	recurring := window.DailyStart != nil || window.DurationMins != nil || len(window.Weekdays) > 0
+
+	switch {
+	case oneOff && recurring:
+		sl.ReportError(window.Start, "start", "Start", "oneOffOrRecurring", "")
+	case oneOff:
+		if window.Start == nil || window.End == nil || !window.End.After(*window.Start) {
+			sl.ReportError(window.End, "end", "End", "afterStart", "")
+		}
+	case recurring:
+		if _, err := time.Parse(BlackoutDailyStartLayout, aws.StringValue(window.DailyStart)); err != nil {
+			sl.ReportError(window.DailyStart, "dailyStart", "DailyStart", "dailyStart", "")
+		}
+		if window.DurationMins == nil {
+			sl.ReportError(window.DurationMins, "durationMins", "DurationMins", "required", "")
+		}
+	default:
+		sl.ReportError(window.Start, "start", "Start", "required", "")
+	}
+}
@@ -23,6 +23,23 @@ const (
 	IntegrationTypeAWSScan = "aws-scan"
 	// IntegrationTypeAWS3 is the integration type for importing data from customer S3 buckets.
 	IntegrationTypeAWS3 = "aws-s3"
+	// IntegrationTypeAWSKinesis is the integration type for reading logs from a customer Kinesis stream.
+	IntegrationTypeAWSKinesis = "aws-kinesis"
+	// IntegrationTypeAzureScan is the integration type for snapshots in customer Azure subscriptions.
+	IntegrationTypeAzureScan = "azure-scan"
+	// IntegrationTypeGCPLogSource is the integration type for reading audit logs from a customer GCP project.
+	IntegrationTypeGCPLogSource = "gcp-logsource"
+	// IntegrationTypeSQSQueue is the integration type for the JSON log events producers send to an SQS queue.
+	IntegrationTypeSQSQueue = "sqs-queue"
+	// IntegrationTypeHTTPIngest is the integration type for the log events SaaS products POST to an HTTPS endpoint.
+	IntegrationTypeHTTPIngest = "http-ingest"
+	// IntegrationTypeOkta is the integration type for pulling the System Log of a customer Okta organization.
+	IntegrationTypeOkta = "okta"
+	// IntegrationTypeSyslog is the integration type for the syslog messages devices send to the syslog collector.
+	IntegrationTypeSyslog = "syslog"
+	// IntegrationTypeAWSOrganization is the integration type for onboarding the member accounts of a customer
+	// AWS Organization as aws-scan integrations.
+	IntegrationTypeAWSOrganization = "aws-organization"
 
 	// StatusError is the string set in the database when an error occurs in a scan.
 	StatusError = "error"
@@ -30,4 +47,159 @@ const (
 	StatusOK = "ok"
 	// StatusScanning is the status set while a scan is underway.
 	StatusScanning = "scanning"
+
+	// HealthStatusHealthy is the health of an integration which passed every check.
+	HealthStatusHealthy = "healthy"
+	// HealthStatusDegraded is the health of an integration saved even though some buckets or keys failed their check.
+	HealthStatusDegraded = "degraded"
+	// HealthStatusUnhealthy is the health of an integration whose roles failed a periodic re-check after it was saved.
+	HealthStatusUnhealthy = "unhealthy"
+
+	// HealthCheckSuccess is the result of a save whose health check passed.
+	HealthCheckSuccess = "success"
+	// HealthCheckPartialSuccess is the result of a save which tolerated the failed buckets or keys of its health check.
+	HealthCheckPartialSuccess = "partialSuccess"
+
+	// OnboardingCheckPassed is the status of an onboarding check which succeeded.
+	OnboardingCheckPassed = "passed"
+	// OnboardingCheckFailed is the status of an onboarding check which did not succeed.
+	OnboardingCheckFailed = "failed"
+	// OnboardingCheckSkipped is the status of an onboarding check which depends on a failed one.
+	OnboardingCheckSkipped = "skipped"
+
+	// MaskStrategyRedact replaces the value of a field with a fixed mask.
+	MaskStrategyRedact = "redact"
+	// MaskStrategyHash replaces the value of a field with its SHA-256, equal values can still be correlated.
+	MaskStrategyHash = "hash"
+	// MaskStrategyPartial masks all but the last 4 characters of the value of a field.
+	MaskStrategyPartial = "partial"
+	// MaskStrategyRemove removes the field from the log.
+	MaskStrategyRemove = "remove"
+
+	// PipelineStagePassed is the status of a self test stage the synthetic log went through.
+	PipelineStagePassed = "passed"
+	// PipelineStageFailed is the status of a self test stage which returned an error.
+	PipelineStageFailed = "failed"
+	// PipelineStageTimedOut is the status of a self test stage the synthetic log didn't get through in time.
+	PipelineStageTimedOut = "timedOut"
+	// PipelineStageSkipped is the status of a self test stage after the one which stalled.
+	PipelineStageSkipped = "skipped"
+	// PipelineStagePending is the status of a self test stage the synthetic log may still go through.
+	PipelineStagePending = "pending"
+
+	// AuditDestinationS3 exports the audit log to objects of the audit export bucket of the deployment, in JSON lines.
+	AuditDestinationS3 = "s3"
+	// AuditDestinationSQS exports the audit log to the audit export queue of the deployment, a message per change.
+	AuditDestinationSQS = "sqs"
+
+	// ChangeOperationCreated is the operation of the change which added an integration.
+	ChangeOperationCreated = "created"
+	// ChangeOperationUpdated is the operation of a change to the settings of an integration.
+	ChangeOperationUpdated = "updated"
+	// ChangeOperationDeleted is the operation of the change which deleted an integration.
+	ChangeOperationDeleted = "deleted"
+	// ChangeOperationRestored is the operation of the change which restored a soft-deleted integration.
+	ChangeOperationRestored = "restored"
+
+	// HistoryKindHealth is the kind of the history records of the health checks of an integration.
+	HistoryKindHealth = "health"
+	// HistoryKindScan is the kind of the history records of the scans of an integration.
+	HistoryKindScan = "scan"
+	// HistoryKindSelfTest is the kind of the history records of the pipeline self tests of an integration.
+	HistoryKindSelfTest = "selfTest"
+
+	// IngestionAlarmSilence is the ingestion alarm of an integration which delivered no events for its
+	// SilenceThresholdMinutes.
+	IngestionAlarmSilence = "silence"
+	// IngestionAlarmErrorRate is the ingestion alarm of an integration whose log lines failed to classify at a larger
+	// rate than its EventErrorRateThreshold.
+	IngestionAlarmErrorRate = "errorRate"
+
+	// HealthCheckS3Buckets is the health sub-check verifying the log processing role can reach each bucket.
+	HealthCheckS3Buckets = "s3Buckets"
+	// HealthCheckKMSKeys is the health sub-check verifying each KMS key is enabled and can be described.
+	HealthCheckKMSKeys = "kmsKeys"
+	// HealthCheckS3Objects is the health sub-check reading and decrypting a sampled object of each bucket, and
+	// expanding it when the objects are archives.
+	HealthCheckS3Objects = "s3Objects"
+
+	// OversizedRecordDrop skips the records larger than the MaxRecordBytes of the integration, the default.
+	OversizedRecordDrop = "drop"
+	// OversizedRecordTruncate keeps the first MaxRecordBytes of the records which are larger.
+	OversizedRecordTruncate = "truncate"
+	// OversizedRecordError fails the processing of the object with a record larger than MaxRecordBytes.
+	OversizedRecordError = "error"
+
+	// SkewPolicyClamp moves the timestamp of the events outside the TimestampSkew of an integration to its bound,
+	// the default.
+	SkewPolicyClamp = "clamp"
+	// SkewPolicyDrop skips the events timestamped outside the TimestampSkew of an integration.
+	SkewPolicyDrop = "drop"
+	// SkewPolicyAccept keeps the events timestamped outside the TimestampSkew of an integration as they are.
+	SkewPolicyAccept = "accept"
+
+	// OrderingModeNone lets the log processor ingest the objects of an integration in any order, the default.
+	OrderingModeNone = "none"
+	// OrderingModePerPrefix ingests the objects sharing a key prefix in the order they were written, the
+	// prefixes are ingested in parallel.
+	OrderingModePerPrefix = "per-prefix"
+	// OrderingModeStrict ingests the objects of an integration one at a time, in the order they were written.
+	OrderingModeStrict = "strict"
+
+	// ArchiveFormatZip is the format of the zip archives of logs, each file of the archive is an object to ingest.
+	ArchiveFormatZip = "zip"
+	// ArchiveFormatTar is the format of the uncompressed tar archives of logs.
+	ArchiveFormatTar = "tar"
+	// ArchiveFormatTarGzip is the format of the gzip compressed tar archives of logs.
+	ArchiveFormatTarGzip = "tar.gz"
+
+	// DataClassificationPublic is for the sources whose data can be shared outside of the organization.
+	DataClassificationPublic = "public"
+	// DataClassificationInternal is for the sources whose data can be shared within the organization.
+	DataClassificationInternal = "internal"
+	// DataClassificationConfidential is for the sources whose data is restricted to the teams which need it.
+	DataClassificationConfidential = "confidential"
+	// DataClassificationRestricted is for the sources whose data is only accessible to named individuals.
+	DataClassificationRestricted = "restricted"
+
+	// SeverityInfo is the lowest severity of the alerts, the severity overrides of an integration map to one of these.
+	SeverityInfo = "INFO"
+	// SeverityLow is the severity of the alerts unlikely to need action.
+	SeverityLow = "LOW"
+	// SeverityMedium is the severity of the alerts which need action.
+	SeverityMedium = "MEDIUM"
+	// SeverityHigh is the severity of the alerts which need action soon.
+	SeverityHigh = "HIGH"
+	// SeverityCritical is the highest severity of the alerts, which need action right away.
+	SeverityCritical = "CRITICAL"
+
+	// TimeFormatRFC3339 parses the event timestamps of a TimeExtraction as RFC 3339, e.g. 2006-01-02T15:04:05Z.
+	TimeFormatRFC3339 = "rfc3339"
+	// TimeFormatUnix parses the event timestamps of a TimeExtraction as seconds since the epoch.
+	TimeFormatUnix = "unix"
+	// TimeFormatUnixMillis parses the event timestamps of a TimeExtraction as milliseconds since the epoch.
+	TimeFormatUnixMillis = "unix_ms"
+
+	// ShardIteratorTrimHorizon reads a Kinesis stream from its oldest record, the default.
+	ShardIteratorTrimHorizon = "TRIM_HORIZON"
+	// ShardIteratorLatest reads a Kinesis stream from the records added after the integration is created.
+	ShardIteratorLatest = "LATEST"
+
+	// ManifestActionCreate is the action for an integration of an account manifest which doesn't exist yet.
+	ManifestActionCreate = "create"
+	// ManifestActionUpdate is the action for an existing integration whose settings differ from the manifest.
+	ManifestActionUpdate = "update"
+	// ManifestActionDelete is the action for an existing integration which is not part of the manifest.
+	ManifestActionDelete = "delete"
+	// ManifestActionUnchanged is the action for an existing integration which already matches the manifest.
+	ManifestActionUnchanged = "unchanged"
+)
+
+// The experimental ingestion modes an integration can be opted into, see SourceIntegrationMetadata.FeatureEnabled
+const (
+	// FeatureFlagParserV2 opts the logs of an integration into the new generation of parsers.
+	FeatureFlagParserV2 = "parserV2"
+	// FeatureFlagBetaEnrichment opts the events of an integration into the beta enrichments, the GeoIP enrichment
+	// of their ip addresses.
+	FeatureFlagBetaEnrichment = "betaEnrichment"
 )
@@ -0,0 +1,176 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"time"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// LambdaInput is the request structure for the threat-intel-api Lambda function.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	PutThreatIntelSet      *PutThreatIntelSetInput      `json:"putThreatIntelSet"`
+	GetThreatIntelSet      *GetThreatIntelSetInput      `json:"getThreatIntelSet"`
+	ListThreatIntelSets    *ListThreatIntelSetsInput    `json:"listThreatIntelSets"`
+	DeleteThreatIntelSet   *DeleteThreatIntelSetInput   `json:"deleteThreatIntelSet"`
+	ImportThreatIntelFeeds *ImportThreatIntelFeedsInput `json:"importThreatIntelFeeds"`
+}
+
+// The sources of the indicators of threat intel sets
+const (
+	SourceUser = "user"
+	SourceFeed = "feed"
+)
+
+// IndicatorsKeyPrefix is the prefix of the S3 objects of the indicators, in the processed data bucket
+const IndicatorsKeyPrefix = "threat_intel/"
+
+// IndicatorsKey is the S3 key of the indicators of a set, one indicator per line.
+func IndicatorsKey(setID string) string {
+	return IndicatorsKeyPrefix + setID + ".txt"
+}
+
+// NormalizeIndicator returns the form of an indicator the p_any values are matched with.
+//
+// IP addresses, domain names and hashes are case-insensitive, the empty string is not an indicator.
+func NormalizeIndicator(indicator string) string {
+	return strings.ToLower(strings.TrimSpace(indicator))
+}
+
+// PutThreatIntelSetInput creates a threat intel set, or updates the one with the given setId.
+//
+// The indicators of a set are either managed by the user, or imported from the feed URL:
+// a text file with an IP address, domain name, SHA1 or MD5 hash per line, where # starts a comment.
+// Feeds are imported when the set is put, then every hour.
+//
+// Example:
+//
+//	{
+//	    "putThreatIntelSet": {
+//	        "name": "Known C2 servers",
+//	        "indicators": ["198.51.100.7", "evil.example.com"],
+//	        "enabled": true,
+//	        "alertOnMatch": true,
+//	        "severity": "HIGH",
+//	        "userId": "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+//	    }
+//	}
+type PutThreatIntelSetInput struct {
+	SetID       *string `json:"setId,omitempty" validate:"omitempty,uuid4"`
+	Name        *string `json:"name" validate:"required,min=1,max=128"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+
+	// One of the user-managed indicators or a feed URL is required
+	Indicators []string `json:"indicators,omitempty" validate:"max=100000,dive,min=1,max=256"`
+	FeedURL    *string  `json:"feedUrl,omitempty" validate:"omitempty,url,startswith=https://"`
+
+	Enabled *bool `json:"enabled"`
+	// An alert is sent for the events matching the set in each processed batch of logs
+	AlertOnMatch *bool   `json:"alertOnMatch,omitempty"`
+	Severity     *string `json:"severity,omitempty" validate:"omitempty,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// PutThreatIntelSetOutput is the stored threat intel set.
+type PutThreatIntelSetOutput = ThreatIntelSet
+
+// GetThreatIntelSetInput retrieves a threat intel set, without its indicators.
+//
+// Example:
+//
+//	{
+//	    "getThreatIntelSet": {
+//	        "setId": "8c2e7e84-6b4c-4f86-9b36-1c3d0f8e8a61"
+//	    }
+//	}
+type GetThreatIntelSetInput struct {
+	SetID *string `json:"setId" validate:"required,uuid4"`
+}
+
+// GetThreatIntelSetOutput is the threat intel set.
+type GetThreatIntelSetOutput = ThreatIntelSet
+
+// ListThreatIntelSetsInput lists all the threat intel sets, without their indicators.
+//
+// Example:
+//
+//	{
+//	    "listThreatIntelSets": {}
+//	}
+type ListThreatIntelSetsInput struct{}
+
+// ListThreatIntelSetsOutput is all the threat intel sets.
+type ListThreatIntelSetsOutput = []*ThreatIntelSet
+
+// DeleteThreatIntelSetInput deletes a threat intel set and its indicators.
+//
+// Example:
+//
+//	{
+//	    "deleteThreatIntelSet": {
+//	        "setId": "8c2e7e84-6b4c-4f86-9b36-1c3d0f8e8a61"
+//	    }
+//	}
+type DeleteThreatIntelSetInput struct {
+	SetID *string `json:"setId" validate:"required,uuid4"`
+}
+
+// ImportThreatIntelFeedsInput imports the indicators of the enabled feed sets, or of a single one.
+//
+// A failed import keeps the indicators of the last successful one and is stored in lastImportError.
+//
+// Example:
+//
+//	{
+//	    "importThreatIntelFeeds": {}
+//	}
+type ImportThreatIntelFeedsInput struct {
+	SetID *string `json:"setId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// ImportThreatIntelFeedsOutput is the sets of the imported feeds, with the result of their import.
+type ImportThreatIntelFeedsOutput = []*ThreatIntelSet
+
+// ThreatIntelSet is a set of indicators the events of all log types are matched with.
+type ThreatIntelSet struct {
+	SetID        *string `json:"setId"`
+	Name         *string `json:"name"`
+	Description  *string `json:"description,omitempty"`
+	Source       *string `json:"source"`
+	FeedURL      *string `json:"feedUrl,omitempty"`
+	Enabled      *bool   `json:"enabled"`
+	AlertOnMatch *bool   `json:"alertOnMatch"`
+	Severity     *string `json:"severity,omitempty"`
+
+	IndicatorCount *int `json:"indicatorCount"`
+	// The log processor reloads the indicators of a set when they are updated
+	IndicatorsUpdatedAt *time.Time `json:"indicatorsUpdatedAt,omitempty"`
+	LastImportTime      *time.Time `json:"lastImportTime,omitempty"`
+	LastImportError     *string    `json:"lastImportError,omitempty"`
+
+	CreatedAtTime  *time.Time `json:"createdAtTime"`
+	CreatedBy      *string    `json:"createdBy"`
+	LastModified   *time.Time `json:"lastModified"`
+	LastModifiedBy *string    `json:"lastModifiedBy"`
+}
@@ -0,0 +1,74 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "github.com/panther-labs/panther/pkg/genericapi"
+
+// LambdaInput is the invocation event expected by the Lambda function.
+//
+// Exactly one action must be specified.
+type LambdaInput struct {
+	Caller *genericapi.Caller `json:"caller"`
+
+	CreateToken *CreateTokenInput `json:"createToken"`
+	ListTokens  *ListTokensInput  `json:"listTokens"`
+	RevokeToken *RevokeTokenInput `json:"revokeToken"`
+}
+
+// The permission scopes of the API tokens, as <api>:<access>.
+//
+// The read access allows the GET methods of the API, the write access allows all of its methods.
+const (
+	ScopeAnalysisRead   = "analysis:read"
+	ScopeAnalysisWrite  = "analysis:write"
+	ScopeComplianceRead = "compliance:read"
+)
+
+// CreateTokenInput creates a long-lived API token with a set of scopes.
+type CreateTokenInput struct {
+	Name   *string   `json:"name" validate:"required,min=1,max=128"`
+	Scopes []*string `json:"scopes" validate:"min=1,dive,required,oneof=analysis:read analysis:write compliance:read"`
+	UserID *string   `json:"userId" validate:"required,uuid4"`
+
+	// Optional, the token never expires by default
+	ExpiresInDays *int64 `json:"expiresInDays" validate:"omitempty,min=1,max=3650"`
+}
+
+// CreateTokenOutput returns the token, its secret is only returned once.
+type CreateTokenOutput struct {
+	Token  *string    `genericapi:"redact" json:"token"`
+	Detail *TokenInfo `json:"detail"`
+}
+
+// ListTokensInput lists the API tokens, the revoked ones included.
+type ListTokensInput struct{}
+
+// ListTokensOutput returns the API tokens sorted by creation time.
+type ListTokensOutput struct {
+	Tokens []*TokenInfo `json:"tokens"`
+}
+
+// RevokeTokenInput revokes an API token, it can't authorize requests anymore.
+type RevokeTokenInput struct {
+	ID     *string `json:"id" validate:"required,uuid4"`
+	UserID *string `json:"userId" validate:"required,uuid4"`
+}
+
+// RevokeTokenOutput returns the revoked token.
+type RevokeTokenOutput = TokenInfo
@@ -18,18 +18,40 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import "github.com/panther-labs/panther/pkg/genericapi"
+
 // LambdaInput is the invocation event expected by the Lambda function.
 //
 // Exactly one action must be specified.
 type LambdaInput struct {
-	GetUser           *GetUserInput           `json:"getUser"`
-	InviteUser        *InviteUserInput        `json:"inviteUser"`
-	ListUsers         *ListUsersInput         `json:"listUsers"`
-	RemoveUser        *RemoveUserInput        `json:"removeUser"`
-	ResetUserPassword *ResetUserPasswordInput `json:"resetUserPassword"`
-	UpdateUser        *UpdateUserInput        `json:"updateUser"`
+	Caller *genericapi.Caller `json:"caller"`
+
+	DeleteRole             *DeleteRoleInput             `json:"deleteRole"`
+	GetIdentityProvider    *GetIdentityProviderInput    `json:"getIdentityProvider"`
+	GetUser                *GetUserInput                `json:"getUser"`
+	InviteUser             *InviteUserInput             `json:"inviteUser"`
+	ListRoles              *ListRolesInput              `json:"listRoles"`
+	ListUsers              *ListUsersInput              `json:"listUsers"`
+	MigrateUserRoles       *MigrateUserRolesInput       `json:"migrateUserRoles"`
+	PutIdentityProvider    *PutIdentityProviderInput    `json:"putIdentityProvider"`
+	PutRole                *PutRoleInput                `json:"putRole"`
+	RemoveIdentityProvider *RemoveIdentityProviderInput `json:"removeIdentityProvider"`
+	RemoveUser             *RemoveUserInput             `json:"removeUser"`
+	ResetUserPassword      *ResetUserPasswordInput      `json:"resetUserPassword"`
+	UpdateUser             *UpdateUserInput             `json:"updateUser"`
+}
+
+// DeleteRoleInput deletes a custom role, no user can have it anymore.
+type DeleteRoleInput struct {
+	Name *string `json:"name" validate:"required,min=1,max=128"`
 }
 
+// GetIdentityProviderInput retrieves the single sign-on identity provider of the user pool.
+type GetIdentityProviderInput struct{}
+
+// GetIdentityProviderOutput returns the identity provider, it is empty when there is none.
+type GetIdentityProviderOutput = IdentityProvider
+
 // GetUserInput retrieves a user's information based on id.
 type GetUserInput struct {
 	ID *string `json:"id" validate:"required,uuid4"`
@@ -43,6 +65,9 @@ type InviteUserInput struct {
 	GivenName  *string `json:"givenName" validate:"required,min=1"`
 	FamilyName *string `json:"familyName" validate:"required,min=1"`
 	Email      *string `json:"email" validate:"required,email"`
+
+	// Optional, the user has the ReadOnly role by default
+	Role *string `json:"role" validate:"omitempty,min=1,max=128"`
 }
 
 // InviteUserOutput returns the randomly generated user id.
@@ -50,6 +75,14 @@ type InviteUserOutput struct {
 	ID *string `json:"id"`
 }
 
+// ListRolesInput lists the built-in and the custom roles.
+type ListRolesInput struct{}
+
+// ListRolesOutput returns the roles sorted by name, the built-in ones first.
+type ListRolesOutput struct {
+	Roles []*Role `json:"roles"`
+}
+
 // ListUsersInput lists all users in Panther.
 type ListUsersInput struct{}
 
@@ -58,6 +91,60 @@ type ListUsersOutput struct {
 	Users []*User `json:"users"`
 }
 
+// MigrateUserRolesInput gives the Admin role to the users who have no role, the first time it is invoked only.
+//
+// The users created before the roles were introduced were all administrators, they keep their access.
+type MigrateUserRolesInput struct{}
+
+// MigrateUserRolesOutput returns the IDs of the users who were given the Admin role.
+type MigrateUserRolesOutput struct {
+	MigratedUsers []*string `json:"migratedUsers"`
+}
+
+// PutIdentityProviderInput configures the single sign-on identity provider of the user pool.
+//
+// The users signing in with the identity provider for the first time are created,
+// and join the group of the default role.
+type PutIdentityProviderInput struct {
+	Name *string `json:"name" validate:"required,min=1,max=32,excludesall=_"`
+	Type *string `json:"type" validate:"required,oneof=SAML OIDC"`
+
+	// SAML: exactly one of the following must be specified
+	MetadataURL  *string `json:"metadataURL" validate:"omitempty,url"`
+	MetadataFile *string `json:"metadataFile" validate:"omitempty,min=1,max=131072"`
+
+	// OIDC: all of the following must be specified
+	ClientID     *string `json:"clientId" validate:"omitempty,min=1"`
+	ClientSecret *string `genericapi:"redact" json:"clientSecret" validate:"omitempty,min=1"`
+	Issuer       *string `json:"issuer" validate:"omitempty,url"`
+	Scopes       *string `json:"scopes" validate:"omitempty,min=1"`
+
+	// Maps the user pool attributes (email, given_name, family_name) to the attributes of the identity provider,
+	// the email is mapped to the standard attribute of the identity provider by default
+	AttributeMapping map[string]string `json:"attributeMapping" validate:"omitempty,dive,keys,oneof=email given_name family_name,endkeys,min=1"`
+
+	// The role of the users provisioned by the identity provider, ReadOnly by default
+	DefaultRole *string `json:"defaultRole" validate:"omitempty,min=1,max=128"`
+}
+
+// PutIdentityProviderOutput returns the identity provider with the settings to configure on its side.
+type PutIdentityProviderOutput = IdentityProvider
+
+// PutRoleInput creates or replaces a custom role.
+//
+// The users having the role get its new permissions the next time their session is refreshed.
+type PutRoleInput struct {
+	Name        *string   `json:"name" validate:"required,min=1,max=128,excludes=:"`
+	Description *string   `json:"description" validate:"omitempty,max=1024"`
+	Permissions []*string `json:"permissions" validate:"min=1,dive,required,permission"`
+}
+
+// PutRoleOutput returns the custom role.
+type PutRoleOutput = Role
+
+// RemoveIdentityProviderInput removes the single sign-on identity provider, the users go back to signing in with passwords.
+type RemoveIdentityProviderInput struct{}
+
 // RemoveUserInput deletes a user.
 type RemoveUserInput struct {
 	ID *string `json:"id" validate:"required,uuid4"`
@@ -76,6 +163,7 @@ type UpdateUserInput struct {
 	GivenName  *string `json:"givenName" validate:"omitempty,min=1"`
 	FamilyName *string `json:"familyName" validate:"omitempty,min=1"`
 	Email      *string `json:"email" validate:"omitempty,min=1"`
+	Role       *string `json:"role" validate:"omitempty,min=1,max=128"`
 }
 
 // UpdateUserOutput returns the new Panther user details.
@@ -0,0 +1,139 @@
+package models
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "github.com/aws/aws-sdk-go/aws"
+
+// The permissions of the roles, each one allows a set of API actions.
+//
+// The read permissions allow listing and getting the items, the modify permissions allow changing them.
+const (
+	PermissionAlertModify         = "AlertModify"
+	PermissionAlertRead           = "AlertRead"
+	PermissionAPITokenModify      = "APITokenModify"
+	PermissionAPITokenRead        = "APITokenRead"
+	PermissionDataAnalyticsModify = "DataAnalyticsModify" // queries, custom schemas, threat intel, retention
+	PermissionDataAnalyticsRead   = "DataAnalyticsRead"
+	PermissionDestinationModify   = "DestinationModify"
+	PermissionDestinationRead     = "DestinationRead"
+	PermissionPolicyModify        = "PolicyModify" // policies, their suppressions and tests
+	PermissionPolicyRead          = "PolicyRead"
+	PermissionResourceModify      = "ResourceModify" // remediations
+	PermissionResourceRead        = "ResourceRead"   // resources and their compliance
+	PermissionRuleModify          = "RuleModify"
+	PermissionRuleRead            = "RuleRead"
+	PermissionSettingsModify      = "SettingsModify" // general settings and single sign-on
+	PermissionSettingsRead        = "SettingsRead"
+	PermissionSourceModify        = "SourceModify"
+	PermissionSourceRead          = "SourceRead"
+	PermissionUserModify          = "UserModify" // users and roles
+	PermissionUserRead            = "UserRead"
+)
+
+// Permissions lists every permission, in the order they are listed in the roles.
+var Permissions = []string{
+	PermissionAlertModify,
+	PermissionAlertRead,
+	PermissionAPITokenModify,
+	PermissionAPITokenRead,
+	PermissionDataAnalyticsModify,
+	PermissionDataAnalyticsRead,
+	PermissionDestinationModify,
+	PermissionDestinationRead,
+	PermissionPolicyModify,
+	PermissionPolicyRead,
+	PermissionResourceModify,
+	PermissionResourceRead,
+	PermissionRuleModify,
+	PermissionRuleRead,
+	PermissionSettingsModify,
+	PermissionSettingsRead,
+	PermissionSourceModify,
+	PermissionSourceRead,
+	PermissionUserModify,
+	PermissionUserRead,
+}
+
+// The built-in roles, they can't be modified nor deleted.
+const (
+	RoleAdmin    = "Admin"
+	RoleAnalyst  = "Analyst"
+	RoleReadOnly = "ReadOnly"
+)
+
+// BuiltInRoles maps the built-in roles to their permissions.
+var BuiltInRoles = map[string][]string{
+	RoleAdmin: Permissions,
+	RoleAnalyst: {
+		PermissionAlertModify,
+		PermissionAlertRead,
+		PermissionAPITokenRead,
+		PermissionDataAnalyticsModify,
+		PermissionDataAnalyticsRead,
+		PermissionDestinationRead,
+		PermissionPolicyModify,
+		PermissionPolicyRead,
+		PermissionResourceRead,
+		PermissionRuleModify,
+		PermissionRuleRead,
+		PermissionSettingsRead,
+		PermissionSourceRead,
+		PermissionUserRead,
+	},
+	RoleReadOnly: {
+		PermissionAlertRead,
+		PermissionAPITokenRead,
+		PermissionDataAnalyticsRead,
+		PermissionDestinationRead,
+		PermissionPolicyRead,
+		PermissionResourceRead,
+		PermissionRuleRead,
+		PermissionSettingsRead,
+		PermissionSourceRead,
+		PermissionUserRead,
+	},
+}
+
+var builtInRoleDescriptions = map[string]string{
+	RoleAdmin:    "Full access, including the users, the settings and the API tokens",
+	RoleAnalyst:  "Triages alerts, analyzes the data and writes detections, without changing the configuration",
+	RoleReadOnly: "Views everything, without changing anything",
+}
+
+// Role is a named set of permissions, the users have exactly one role.
+type Role struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	Permissions []*string `json:"permissions"`
+	BuiltIn     *bool     `json:"builtIn"`
+}
+
+// BuiltInRole returns the built-in role with the given name, nil if there is none.
+func BuiltInRole(name string) *Role {
+	permissions, ok := BuiltInRoles[name]
+	if !ok {
+		return nil
+	}
+	return &Role{
+		Name:        &name,
+		Description: aws.String(builtInRoleDescriptions[name]),
+		Permissions: aws.StringSlice(permissions),
+		BuiltIn:     aws.Bool(true),
+	}
+}
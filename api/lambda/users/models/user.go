@@ -25,5 +25,23 @@ type User struct {
 	FamilyName *string `json:"familyName"`
 	GivenName  *string `json:"givenName"`
 	ID         *string `json:"id"`
+	Role       *string `json:"role"`
 	Status     *string `json:"status"`
 }
+
+// IdentityProvider is a struct describing the single sign-on identity provider of the Panther users.
+type IdentityProvider struct {
+	Name             *string           `json:"name,omitempty"`
+	Type             *string           `json:"type,omitempty"`
+	MetadataURL      *string           `json:"metadataURL,omitempty"`
+	ClientID         *string           `json:"clientId,omitempty"`
+	Issuer           *string           `json:"issuer,omitempty"`
+	Scopes           *string           `json:"scopes,omitempty"`
+	AttributeMapping map[string]string `json:"attributeMapping,omitempty"`
+	DefaultRole      *string           `json:"defaultRole,omitempty"`
+
+	// The settings of Panther as a service provider, to configure in the identity provider
+	EntityID    *string `json:"entityId,omitempty"`
+	RedirectURL *string `json:"redirectURL,omitempty"`
+	SignInURL   *string `json:"signInURL,omitempty"`
+}
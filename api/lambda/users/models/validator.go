@@ -24,12 +24,43 @@ import "gopkg.in/go-playground/validator.v9"
 func Validator() *validator.Validate {
 	result := validator.New()
 	result.RegisterStructValidation(atLeastOneUpdate, &UpdateUserInput{})
+	result.RegisterStructValidation(identityProviderDetails, &PutIdentityProviderInput{})
+	if err := result.RegisterValidation("permission", isPermission); err != nil {
+		panic(err)
+	}
 	return result
 }
 
+func isPermission(fl validator.FieldLevel) bool {
+	for _, permission := range Permissions {
+		if fl.Field().String() == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func atLeastOneUpdate(sl validator.StructLevel) {
 	in := sl.Current().Interface().(UpdateUserInput)
-	if in.GivenName == nil && in.FamilyName == nil && in.Email == nil {
-		sl.ReportError(in, "FamilyName|GivenName|Email", "", "at_least_one_update", "")
+	if in.GivenName == nil && in.FamilyName == nil && in.Email == nil && in.Role == nil {
+		sl.ReportError(in, "FamilyName|GivenName|Email|Role", "", "at_least_one_update", "")
+	}
+}
+
+// The details of an identity provider depend on its type
+func identityProviderDetails(sl validator.StructLevel) {
+	in := sl.Current().Interface().(PutIdentityProviderInput)
+	if in.Type == nil {
+		return
+	}
+	switch *in.Type {
+	case "SAML":
+		if (in.MetadataURL == nil) == (in.MetadataFile == nil) {
+			sl.ReportError(in, "MetadataURL|MetadataFile", "", "exactly_one_metadata", "")
+		}
+	case "OIDC":
+		if in.ClientID == nil || in.ClientSecret == nil || in.Issuer == nil {
+			sl.ReportError(in, "ClientID|ClientSecret|Issuer", "", "required_oidc_details", "")
+		}
 	}
 }
@@ -52,3 +52,90 @@ func TestUpdateUserAllFields(t *testing.T) {
 		FamilyName: aws.String("family-name"),
 	}))
 }
+
+func TestPutIdentityProviderSAML(t *testing.T) {
+	assert.NoError(t, Validator().Struct(&PutIdentityProviderInput{
+		Name:        aws.String("Okta"),
+		Type:        aws.String("SAML"),
+		MetadataURL: aws.String("https://example.okta.com/app/exk1/sso/saml/metadata"),
+		DefaultRole: aws.String("Analyst"),
+	}))
+}
+
+func TestPutIdentityProviderSAMLMetadata(t *testing.T) {
+	// Neither the metadata URL nor the metadata file
+	assert.Error(t, Validator().Struct(&PutIdentityProviderInput{
+		Name: aws.String("Okta"),
+		Type: aws.String("SAML"),
+	}))
+	// Both of them
+	assert.Error(t, Validator().Struct(&PutIdentityProviderInput{
+		Name:         aws.String("Okta"),
+		Type:         aws.String("SAML"),
+		MetadataURL:  aws.String("https://example.okta.com/app/exk1/sso/saml/metadata"),
+		MetadataFile: aws.String("<EntityDescriptor/>"),
+	}))
+}
+
+func TestPutIdentityProviderOIDC(t *testing.T) {
+	assert.NoError(t, Validator().Struct(&PutIdentityProviderInput{
+		Name:             aws.String("Google"),
+		Type:             aws.String("OIDC"),
+		ClientID:         aws.String("client"),
+		ClientSecret:     aws.String("secret"),
+		Issuer:           aws.String("https://accounts.google.com"),
+		AttributeMapping: map[string]string{"given_name": "given_name"},
+	}))
+	assert.Error(t, Validator().Struct(&PutIdentityProviderInput{
+		Name:     aws.String("Google"),
+		Type:     aws.String("OIDC"),
+		ClientID: aws.String("client"),
+	}))
+}
+
+func TestPutIdentityProviderInvalidAttribute(t *testing.T) {
+	assert.Error(t, Validator().Struct(&PutIdentityProviderInput{
+		Name:             aws.String("Okta"),
+		Type:             aws.String("SAML"),
+		MetadataURL:      aws.String("https://example.okta.com/app/exk1/sso/saml/metadata"),
+		AttributeMapping: map[string]string{"phone_number": "phone"},
+	}))
+}
+
+func TestPutIdentityProviderInvalidName(t *testing.T) {
+	assert.Error(t, Validator().Struct(&PutIdentityProviderInput{
+		Name:        aws.String("my_okta"),
+		Type:        aws.String("SAML"),
+		MetadataURL: aws.String("https://example.okta.com/app/exk1/sso/saml/metadata"),
+	}))
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	assert.NoError(t, Validator().Struct(&UpdateUserInput{
+		ID:   mockID,
+		Role: aws.String(RoleAnalyst),
+	}))
+}
+
+func TestPutRole(t *testing.T) {
+	assert.NoError(t, Validator().Struct(&PutRoleInput{
+		Name:        aws.String("DetectionEngineer"),
+		Permissions: aws.StringSlice([]string{PermissionAlertRead, PermissionDataAnalyticsModify}),
+	}))
+}
+
+func TestPutRoleInvalidPermission(t *testing.T) {
+	assert.Error(t, Validator().Struct(&PutRoleInput{
+		Name:        aws.String("DetectionEngineer"),
+		Permissions: aws.StringSlice([]string{PermissionAlertRead, "PolicyDelete"}),
+	}))
+	assert.Error(t, Validator().Struct(&PutRoleInput{Name: aws.String("Nothing")}))
+}
+
+func TestPutRoleInvalidName(t *testing.T) {
+	// The names with a colon are reserved
+	assert.Error(t, Validator().Struct(&PutRoleInput{
+		Name:        aws.String("migration:userRoles"),
+		Permissions: aws.StringSlice([]string{PermissionAlertRead}),
+	}))
+}
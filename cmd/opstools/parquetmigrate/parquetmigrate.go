@@ -0,0 +1,173 @@
+package parquetmigrate
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/pkg/awsbatch/s3batch"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/parquet"
+)
+
+const deleteMaxElapsedTime = time.Minute
+
+// Migrate converts the gzip JSON objects of the log partitions under a prefix to Parquet.
+//
+// The prefix is the one of a table, e.g. logs/aws_cloudtrail/, or of some of its partitions.
+// Once all the objects of a partition are converted, the partition is set to Parquet and its JSON objects are deleted.
+func Migrate(s3Client s3iface.S3API, glueClient glueiface.GlueAPI, bucket, prefix string) error {
+	if !strings.HasPrefix(prefix, "logs/") {
+		return errors.Errorf("%s is not the prefix of a log table", prefix)
+	}
+
+	// The JSON objects of each partition, by the location of the partition
+	partitions := make(map[string][]string)
+	err := s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			if !strings.HasSuffix(*object.Key, awsglue.JSONObjectSuffix) {
+				continue
+			}
+			partition, err := awsglue.GetPartitionFromS3(bucket, *object.Key)
+			if err != nil {
+				log.Printf("Skipping %s: %v", *object.Key, err)
+				continue
+			}
+			location := partition.GetPartitionLocation()
+			partitions[location] = append(partitions[location], *object.Key)
+		}
+		return true
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list s3://%s/%s", bucket, prefix)
+	}
+
+	locations := make([]string, 0, len(partitions))
+	for location := range partitions {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	schemas := make(map[string]*parquet.Schema)
+	for _, location := range locations {
+		if err := migratePartition(s3Client, glueClient, bucket, partitions[location], schemas); err != nil {
+			return errors.WithMessagef(err, "failed to migrate partition %s", location)
+		}
+		log.Printf("Migrated %d object(s) of %s", len(partitions[location]), location)
+	}
+	log.Printf("Successfully migrated %d partition(s).", len(locations))
+	return nil
+}
+
+func migratePartition(s3Client s3iface.S3API, glueClient glueiface.GlueAPI, bucket string, keys []string,
+	schemas map[string]*parquet.Schema) error {
+
+	var partition *awsglue.GluePartition
+	for _, key := range keys {
+		parquetKey := strings.TrimSuffix(key, awsglue.JSONObjectSuffix) + awsglue.ParquetObjectSuffix
+		var err error
+		if partition, err = awsglue.GetPartitionFromS3(bucket, parquetKey); err != nil {
+			return err
+		}
+		schema, ok := schemas[partition.GetTable()]
+		if !ok {
+			if schema, err = awsglue.GetParquetSchema(glueClient, partition.GetDatabase(), partition.GetTable()); err != nil {
+				return err
+			}
+			schemas[partition.GetTable()] = schema
+		}
+		if err = convertObject(s3Client, bucket, key, parquetKey, schema); err != nil {
+			return err
+		}
+	}
+
+	if err := partition.UpdatePartition(glueClient); err != nil {
+		awsErr, ok := errors.Cause(err).(awserr.Error)
+		if !ok || awsErr.Code() != glue.ErrCodeEntityNotFoundException {
+			return err
+		}
+		// The partition was never created in Glue
+		if err = partition.CreatePartition(glueClient); err != nil {
+			return err
+		}
+	}
+
+	objects := make([]*s3.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		objects[i] = &s3.ObjectIdentifier{Key: aws.String(key)}
+	}
+	return s3batch.DeleteObjects(s3Client, deleteMaxElapsedTime, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &s3.Delete{Objects: objects},
+	})
+}
+
+// convertObject writes the events of a gzip JSON lines object as a Parquet object
+func convertObject(s3Client s3iface.S3API, bucket, key, parquetKey string, schema *parquet.Schema) error {
+	output, err := s3Client.GetObject(&s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %s", key)
+	}
+	defer output.Body.Close()
+	gzipReader, err := gzip.NewReader(output.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", key)
+	}
+
+	writer := parquet.NewWriter(schema)
+	reader := bufio.NewReader(gzipReader)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if writeErr := writer.WriteJSON(line); writeErr != nil {
+				return errors.WithMessagef(writeErr, "failed to convert event %d of %s", writer.Rows()+1, key)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", key)
+		}
+	}
+
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(parquetKey),
+		Body:   bytes.NewReader(writer.Bytes()),
+	})
+	return errors.Wrapf(err, "failed to put %s", parquetKey)
+}
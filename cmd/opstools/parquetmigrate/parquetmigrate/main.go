@@ -0,0 +1,96 @@
+package main
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/cmd/opstools/parquetmigrate"
+)
+
+const (
+	banner = "converts the gzip JSON partitions of log tables to Parquet"
+)
+
+var (
+	REGION = flag.String("region", "", "The AWS region where the bucket exists (optional, defaults to session env vars)")
+	BUCKET = flag.String("bucket", "", "The name of the bucket of the processed data")
+	PREFIX = flag.String("prefix", "", "The prefix of the table or partitions to convert, e.g. logs/aws_cloudtrail/year=2020/")
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(),
+		"%s %s\nUsage:\n",
+		filepath.Base(os.Args[0]), banner)
+	flag.PrintDefaults()
+}
+
+func init() {
+	flag.Usage = usage
+}
+
+func main() {
+	flag.Parse()
+
+	sess, err := session.NewSession()
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	if *REGION != "" { //override
+		sess.Config.Region = REGION
+	}
+
+	validateFlags()
+
+	err = parquetmigrate.Migrate(s3.New(sess), glue.New(sess), *BUCKET, *PREFIX)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func validateFlags() {
+	var err error
+	defer func() {
+		if err != nil {
+			fmt.Printf("%s\n", err)
+			flag.Usage()
+			os.Exit(-2)
+		}
+	}()
+
+	if *BUCKET == "" {
+		err = errors.New("-bucket not set")
+		return
+	}
+	if *PREFIX == "" {
+		err = errors.New("-prefix not set")
+		return
+	}
+}
@@ -0,0 +1,164 @@
+package parquetmigrate
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockS3 struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (m *mockS3) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	args := m.Called(input, fn)
+	fn(args.Get(0).(*s3.ListObjectsV2Output), true)
+	return args.Error(1)
+}
+
+func (m *mockS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
+}
+
+func (m *mockS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+func (m *mockS3) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.DeleteObjectsOutput), args.Error(1)
+}
+
+type mockGlue struct {
+	glueiface.GlueAPI
+	mock.Mock
+}
+
+func (m *mockGlue) GetTable(input *glue.GetTableInput) (*glue.GetTableOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.GetTableOutput), args.Error(1)
+}
+
+func (m *mockGlue) UpdatePartition(input *glue.UpdatePartitionInput) (*glue.UpdatePartitionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.UpdatePartitionOutput), args.Error(1)
+}
+
+func (m *mockGlue) CreatePartition(input *glue.CreatePartitionInput) (*glue.CreatePartitionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.CreatePartitionOutput), args.Error(1)
+}
+
+func gzipObject(t *testing.T, lines string) *s3.GetObjectOutput {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(lines))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(&buffer)}
+}
+
+func TestMigrate(t *testing.T) {
+	const (
+		hour1 = "logs/aws_cloudtrail/year=2020/month=02/day=26/hour=15/"
+		hour2 = "logs/aws_cloudtrail/year=2020/month=02/day=26/hour=16/"
+	)
+	s3Client, glueClient := &mockS3{}, &mockGlue{}
+	s3Client.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{
+			{Key: aws.String(hour1 + "a.json.gz")},
+			{Key: aws.String(hour1 + "b.json.gz")},
+			{Key: aws.String(hour1 + "c.parquet")}, // already converted
+			{Key: aws.String(hour2 + "a.json.gz")},
+		},
+	}, nil)
+	s3Client.On("GetObject", mock.Anything).Return(gzipObject(t, "{\"eventName\":\"GetObject\"}\n\n"), nil).Once()
+	s3Client.On("GetObject", mock.Anything).Return(gzipObject(t, `{"eventName":"PutObject"}`), nil).Once()
+	s3Client.On("GetObject", mock.Anything).Return(gzipObject(t, `{"eventName":"ListBuckets"}`), nil).Once()
+	s3Client.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Times(3)
+	s3Client.On("DeleteObjects", &s3.DeleteObjectsInput{
+		Bucket: aws.String("bucket"),
+		Delete: &s3.Delete{Objects: []*s3.ObjectIdentifier{{Key: aws.String(hour1 + "a.json.gz")}, {Key: aws.String(hour1 + "b.json.gz")}}},
+	}).Return(&s3.DeleteObjectsOutput{}, nil).Once()
+	s3Client.On("DeleteObjects", mock.Anything).Return(&s3.DeleteObjectsOutput{}, nil).Once()
+
+	glueClient.On("GetTable", mock.Anything).Return(&glue.GetTableOutput{Table: &glue.TableData{
+		StorageDescriptor: &glue.StorageDescriptor{
+			Columns: []*glue.Column{{Name: aws.String("eventName"), Type: aws.String("string")}},
+		},
+	}}, nil).Once()
+	glueClient.On("UpdatePartition", mock.Anything).Return(&glue.UpdatePartitionOutput{}, nil).Once()
+	// The second partition doesn't exist in Glue yet
+	glueClient.On("UpdatePartition", mock.Anything).
+		Return(&glue.UpdatePartitionOutput{}, awserr.New(glue.ErrCodeEntityNotFoundException, "not found", nil)).Once()
+	glueClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, nil).Once()
+
+	require.NoError(t, Migrate(s3Client, glueClient, "bucket", "logs/aws_cloudtrail/"))
+	s3Client.AssertExpectations(t)
+	glueClient.AssertExpectations(t)
+
+	putInput := s3Client.Calls[2].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Equal(t, hour1+"a.parquet", *putInput.Key)
+	body, err := ioutil.ReadAll(putInput.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "PAR1", string(body[:4]))
+	updateInput := glueClient.Calls[1].Arguments.Get(0).(*glue.UpdatePartitionInput)
+	assert.Equal(t, "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe",
+		*updateInput.PartitionInput.StorageDescriptor.SerdeInfo.SerializationLibrary)
+	assert.Equal(t, aws.StringSlice([]string{"2020", "02", "26", "15"}), updateInput.PartitionValueList)
+}
+
+func TestMigrateEventMismatch(t *testing.T) {
+	s3Client, glueClient := &mockS3{}, &mockGlue{}
+	s3Client.On("ListObjectsV2Pages", mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{{Key: aws.String("logs/aws_cloudtrail/year=2020/month=02/day=26/hour=15/a.json.gz")}},
+	}, nil)
+	s3Client.On("GetObject", mock.Anything).Return(gzipObject(t, `{"eventName":"GetObject"}`), nil)
+	glueClient.On("GetTable", mock.Anything).Return(&glue.GetTableOutput{Table: &glue.TableData{
+		StorageDescriptor: &glue.StorageDescriptor{
+			Columns: []*glue.Column{{Name: aws.String("eventName"), Type: aws.String("bigint")}},
+		},
+	}}, nil)
+
+	// Nothing is put, updated or deleted
+	err := Migrate(s3Client, glueClient, "bucket", "logs/aws_cloudtrail/")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "event 1")
+	s3Client.AssertExpectations(t)
+}
+
+func TestMigrateRuleMatches(t *testing.T) {
+	assert.Error(t, Migrate(&mockS3{}, &mockGlue{}, "bucket", "rules/aws_cloudtrail/"))
+}
@@ -24,6 +24,10 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/gateway/compliance/models"
@@ -35,6 +39,8 @@ type resourceMap map[models.ResourceID]*models.ResourceSummary
 var (
 	awsSession                             = session.Must(session.NewSession())
 	dynamoClient dynamodbiface.DynamoDBAPI = dynamodb.New(awsSession)
+	s3Client     s3iface.S3API             = s3.New(awsSession)
+	sqsClient    sqsiface.SQSAPI           = sqs.New(awsSession)
 )
 
 // Build the table key in the format Dynamo expects
@@ -19,8 +19,11 @@ package handlers
  */
 
 type envConfig struct {
+	AlertQueueURL   string `required:"true" split_words:"true"`
 	ComplianceTable string `required:"true" split_words:"true"`
+	ExceptionsTable string `required:"true" split_words:"true"`
 	IndexName       string `required:"true" split_words:"true"`
+	ReportBucket    string `required:"true" split_words:"true"`
 }
 
 // Env is the parsed environment variables
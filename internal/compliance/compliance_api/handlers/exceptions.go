@@ -0,0 +1,463 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/compliance/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+const (
+	// Exceptions can't accept a risk for more than a year, they have to be created again
+	maxExceptionLifetime = 365 * 24 * time.Hour
+
+	// An alert is sent when an exception expires within a week
+	exceptionExpiryWarning = 7 * 24 * time.Hour
+)
+
+// The exceptions table also records whether the expiry alert of an exception was sent
+type exceptionItem struct {
+	models.ComplianceException
+	ExpiryNotified bool `json:"expiryNotified,omitempty"`
+}
+
+func (e *exceptionItem) expired(now time.Time) bool {
+	return !time.Time(*e.ExpirationTime).After(now)
+}
+
+// Returns true if the exception applies to the policy and resource of the status entry
+func (e *exceptionItem) matches(entry *models.ComplianceStatus) bool {
+	if e.PolicyID != entry.PolicyID {
+		return false
+	}
+	if e.IntegrationID != "" && e.IntegrationID != entry.IntegrationID {
+		return false
+	}
+	return globMatch(aws.StringValue(e.ResourcePattern), string(entry.ResourceID))
+}
+
+// Returns true if the value matches the glob pattern, where "*" matches any characters.
+//
+// This is how the resource-processor matches the suppressions of the policies.
+func globMatch(pattern, value string) bool {
+	regex := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `.*`) + "$"
+	// We are building the regex, so it is always valid
+	matched, _ := regexp.MatchString(regex, value)
+	return matched
+}
+
+// Returns the first exception which applies to the status entry, if any
+func matchException(exceptions []*exceptionItem, entry *models.ComplianceStatus) *exceptionItem {
+	for _, exception := range exceptions {
+		if exception.matches(entry) {
+			return exception
+		}
+	}
+	return nil
+}
+
+// CreateException adds a compliance exception and suppresses the status entries it matches.
+func CreateException(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	input, err := parseCreateException(request)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	now := time.Now().UTC()
+	expiration := time.Time(*input.ExpirationTime)
+	if !expiration.After(now) {
+		return badRequest(errors.New("expirationTime must be in the future"))
+	}
+	if expiration.Sub(now) > maxExceptionLifetime {
+		return badRequest(errors.New("expirationTime must be at most a year from now"))
+	}
+
+	createdAt := strfmt.DateTime(now)
+	exception := &exceptionItem{ComplianceException: models.ComplianceException{
+		CreatedAt:       &createdAt,
+		CreatedBy:       input.UserID,
+		ExceptionID:     models.ExceptionID(uuid.New().String()),
+		ExpirationTime:  input.ExpirationTime,
+		IntegrationID:   input.IntegrationID,
+		Justification:   input.Justification,
+		PolicyID:        input.PolicyID,
+		ResourcePattern: input.ResourcePattern,
+	}}
+
+	marshalled, err := dynamodbattribute.MarshalMap(exception)
+	if err != nil {
+		zap.L().Error("dynamodbattribute.MarshalMap failed", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if _, err = dynamoClient.PutItem(&dynamodb.PutItemInput{Item: marshalled, TableName: &Env.ExceptionsTable}); err != nil {
+		zap.L().Error("dynamoClient.PutItem failed", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	// The failures are suppressed right away instead of when the resources are scanned again
+	if err = suppressMatching(exception); err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	return gatewayapi.MarshalResponse(&exception.ComplianceException, http.StatusCreated)
+}
+
+func parseCreateException(request *events.APIGatewayProxyRequest) (*models.CreateException, error) {
+	var result models.CreateException
+	if err := jsoniter.UnmarshalFromString(request.Body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, result.Validate(nil)
+}
+
+// Suppress the status entries the exception matches.
+//
+// Entries which are already suppressed, by the policy itself or by another exception, are left as they are.
+func suppressMatching(exception *exceptionItem) error {
+	query, err := buildDescribePolicyQuery(exception.PolicyID)
+	if err != nil {
+		return err
+	}
+
+	var writes []*dynamodb.WriteRequest
+	err = queryPages(query, func(item *models.ComplianceStatus) error {
+		if bool(item.Suppressed) || !exception.matches(item) {
+			return nil
+		}
+		item.Suppressed = true
+		item.ExceptionID = exception.ExceptionID
+		return appendPutRequest(&writes, item)
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeStatusEntries(writes)
+}
+
+// ListExceptions lists the compliance exceptions, sorted by expiration time.
+func ListExceptions(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	policyID := models.PolicyID(request.QueryStringParameters["policyId"])
+	if err := policyID.Validate(nil); err != nil {
+		return badRequest(errors.New("invalid policyId: " + err.Error()))
+	}
+
+	integrationID := models.IntegrationID(request.QueryStringParameters["integrationId"])
+	if integrationID != "" {
+		if err := integrationID.Validate(nil); err != nil {
+			return badRequest(errors.New("invalid integrationId: " + err.Error()))
+		}
+	}
+
+	includeExpired := false
+	if raw := request.QueryStringParameters["includeExpired"]; raw != "" {
+		var err error
+		if includeExpired, err = strconv.ParseBool(raw); err != nil {
+			return badRequest(errors.New("invalid includeExpired: " + err.Error()))
+		}
+	}
+
+	exceptions, err := scanExceptions()
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	now := time.Now()
+	result := &models.ExceptionList{Exceptions: make([]*models.ComplianceException, 0, len(exceptions))}
+	for _, exception := range exceptions {
+		if policyID != "" && exception.PolicyID != policyID {
+			continue
+		}
+		// Exceptions without an integration apply to every integration
+		if integrationID != "" && exception.IntegrationID != "" && exception.IntegrationID != integrationID {
+			continue
+		}
+		if !includeExpired && exception.expired(now) {
+			continue
+		}
+		result.Exceptions = append(result.Exceptions, &exception.ComplianceException)
+	}
+
+	sort.Slice(result.Exceptions, func(i, j int) bool {
+		return time.Time(*result.Exceptions[i].ExpirationTime).Before(time.Time(*result.Exceptions[j].ExpirationTime))
+	})
+	return gatewayapi.MarshalResponse(result, http.StatusOK)
+}
+
+// DeleteExceptions removes compliance exceptions and restores the status entries they suppressed.
+func DeleteExceptions(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	input, err := parseDeleteExceptions(request)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	exceptions, err := scanExceptions()
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	deleted := make(map[models.ExceptionID]bool, len(input.ExceptionIds))
+	for _, id := range input.ExceptionIds {
+		deleted[id] = true
+	}
+
+	now := time.Now()
+	var removed, remaining []*exceptionItem
+	for _, exception := range exceptions {
+		if deleted[exception.ExceptionID] {
+			removed = append(removed, exception)
+		} else if !exception.expired(now) {
+			remaining = append(remaining, exception)
+		}
+	}
+
+	// Exceptions which don't exist are ignored, like status entries which don't exist
+	if err := removeExceptions(removed, remaining); err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
+}
+
+func parseDeleteExceptions(request *events.APIGatewayProxyRequest) (*models.DeleteExceptions, error) {
+	var result models.DeleteExceptions
+	if err := jsoniter.UnmarshalFromString(request.Body, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, result.Validate(nil)
+}
+
+// ExpireExceptions removes the expired compliance exceptions and alerts on the ones about to expire.
+//
+// This is invoked every hour by a scheduled event, it is not exposed by the API gateway.
+func ExpireExceptions(*events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	exceptions, err := scanExceptions()
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	now := time.Now()
+	var expired, active []*exceptionItem
+	for _, exception := range exceptions {
+		if exception.expired(now) {
+			expired = append(expired, exception)
+		} else {
+			active = append(active, exception)
+		}
+	}
+
+	if len(expired) > 0 {
+		zap.L().Info("removing expired exceptions", zap.Int("exceptionCount", len(expired)))
+		if err := removeExceptions(expired, active); err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+	}
+
+	for _, exception := range active {
+		if exception.ExpiryNotified || time.Time(*exception.ExpirationTime).Sub(now) > exceptionExpiryWarning {
+			continue
+		}
+		if err := sendExpiryAlert(exception, now); err != nil {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+	}
+
+	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
+}
+
+// Scan every compliance exception, there are only a few of them
+func scanExceptions() ([]*exceptionItem, error) {
+	var result []*exceptionItem
+	var innerErr error
+	err := dynamoClient.ScanPages(&dynamodb.ScanInput{TableName: &Env.ExceptionsTable},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var items []*exceptionItem
+			if innerErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); innerErr != nil {
+				return false // stop paging
+			}
+			result = append(result, items...)
+			return true
+		})
+
+	if innerErr != nil {
+		zap.L().Error("dynamodbattribute.UnmarshalListOfMaps failed", zap.Error(innerErr))
+		return nil, innerErr
+	}
+	if err != nil {
+		zap.L().Error("dynamoClient.ScanPages failed", zap.Error(err))
+		return nil, err
+	}
+	return result, nil
+}
+
+// Delete the exceptions and unsuppress the status entries they suppressed.
+//
+// An entry matched by one of the remaining exceptions is suppressed by that exception instead.
+func removeExceptions(removed, remaining []*exceptionItem) error {
+	if len(removed) == 0 {
+		return nil
+	}
+
+	removedIDs := make(map[models.ExceptionID]bool, len(removed))
+	policies := make(map[models.PolicyID]bool)
+	deletes := make([]*dynamodb.WriteRequest, 0, len(removed))
+	for _, exception := range removed {
+		removedIDs[exception.ExceptionID] = true
+		policies[exception.PolicyID] = true
+		deletes = append(deletes, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{
+			Key: map[string]*dynamodb.AttributeValue{
+				"exceptionId": {S: aws.String(string(exception.ExceptionID))},
+			},
+		}})
+	}
+
+	var writes []*dynamodb.WriteRequest
+	for policyID := range policies {
+		query, err := buildDescribePolicyQuery(policyID)
+		if err != nil {
+			return err
+		}
+
+		err = queryPages(query, func(item *models.ComplianceStatus) error {
+			if !removedIDs[item.ExceptionID] {
+				return nil
+			}
+			item.ExceptionID, item.Suppressed = "", false
+			if exception := matchException(remaining, item); exception != nil {
+				item.ExceptionID, item.Suppressed = exception.ExceptionID, true
+			}
+			return appendPutRequest(&writes, item)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeStatusEntries(writes); err != nil {
+		return err
+	}
+
+	// The exceptions are deleted last, a failure above is retried with the next expiration or deletion
+	batchInput := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{Env.ExceptionsTable: deletes},
+	}
+	if err := dynamodbbatch.BatchWriteItem(dynamoClient, maxWriteBackoff, batchInput); err != nil {
+		zap.L().Error("dynamodbbatch.BatchWriteItem failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Send an alert that the exception is about to expire and record that it was sent
+func sendExpiryAlert(exception *exceptionItem, now time.Time) error {
+	body, err := jsoniter.MarshalToString(expiryAlert(exception, now))
+	if err != nil {
+		zap.L().Error("failed to marshal expiry alert", zap.Error(err))
+		return err
+	}
+
+	if _, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		MessageBody: &body,
+		QueueUrl:    &Env.AlertQueueURL,
+	}); err != nil {
+		zap.L().Error("failed to send expiry alert",
+			zap.String("exceptionId", string(exception.ExceptionID)), zap.Error(err))
+		return err
+	}
+
+	_, err = dynamoClient.UpdateItem(&dynamodb.UpdateItemInput{
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":notified": {BOOL: aws.Bool(true)}},
+		Key: map[string]*dynamodb.AttributeValue{
+			"exceptionId": {S: aws.String(string(exception.ExceptionID))},
+		},
+		TableName:        &Env.ExceptionsTable,
+		UpdateExpression: aws.String("SET expiryNotified = :notified"),
+	})
+	if err != nil {
+		zap.L().Error("dynamoClient.UpdateItem failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func expiryAlert(exception *exceptionItem, now time.Time) *alertmodels.Alert {
+	scope := "all integrations"
+	if exception.IntegrationID != "" {
+		scope = "integration " + string(exception.IntegrationID)
+	}
+	description := fmt.Sprintf(
+		"The exception for the resources matching %s in %s expires at %s, their failures will be alerted "+
+			"on again. Justification: %s",
+		aws.StringValue(exception.ResourcePattern), scope,
+		time.Time(*exception.ExpirationTime).UTC().Format(time.RFC3339), aws.StringValue(exception.Justification))
+
+	return &alertmodels.Alert{
+		CreatedAt:         aws.Time(now.UTC()),
+		PolicyID:          aws.String(string(exception.PolicyID)),
+		PolicyName:        aws.String("Compliance exception expiring: " + string(exception.PolicyID)),
+		PolicyDescription: aws.String(description),
+		Severity:          aws.String(string(models.PolicySeverityINFO)),
+		Type:              aws.String(alertmodels.PolicyType),
+	}
+}
+
+func appendPutRequest(writes *[]*dynamodb.WriteRequest, item *models.ComplianceStatus) error {
+	marshalled, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+	*writes = append(*writes, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: marshalled}})
+	return nil
+}
+
+func writeStatusEntries(writes []*dynamodb.WriteRequest) error {
+	if len(writes) == 0 {
+		return nil
+	}
+	batchInput := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{Env.ComplianceTable: writes},
+	}
+	if err := dynamodbbatch.BatchWriteItem(dynamoClient, maxWriteBackoff, batchInput); err != nil {
+		zap.L().Error("dynamodbbatch.BatchWriteItem failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
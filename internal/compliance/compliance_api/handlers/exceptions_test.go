@@ -0,0 +1,117 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/gateway/compliance/models"
+)
+
+var testIntegrationID = models.IntegrationID("f0e95b8b-6d93-4de5-a963-a2974fd2ba72")
+
+func testException(pattern string, integrationID models.IntegrationID, expiration time.Time) *exceptionItem {
+	createdAt, expirationTime := strfmt.DateTime(expiration.Add(-time.Hour)), strfmt.DateTime(expiration)
+	return &exceptionItem{ComplianceException: models.ComplianceException{
+		CreatedAt:       &createdAt,
+		CreatedBy:       aws.String("test-user"),
+		ExceptionID:     "e0e95b8b-6d93-4de5-a963-a2974fd2ba72",
+		ExpirationTime:  &expirationTime,
+		IntegrationID:   integrationID,
+		Justification:   aws.String("accepted"),
+		PolicyID:        "AWS.S3.Versioning",
+		ResourcePattern: aws.String(pattern),
+	}}
+}
+
+func TestExceptionMatches(t *testing.T) {
+	entry := &models.ComplianceStatus{
+		IntegrationID: testIntegrationID,
+		PolicyID:      "AWS.S3.Versioning",
+		ResourceID:    "arn:aws:s3:::prod-logs/bucket",
+	}
+	expiration := time.Now().Add(time.Hour)
+
+	assert.True(t, testException("*", "", expiration).matches(entry))
+	assert.True(t, testException("arn:aws:s3:::prod-*", testIntegrationID, expiration).matches(entry))
+	assert.True(t, testException("arn:aws:s3:::*/bucket", "", expiration).matches(entry))
+	assert.False(t, testException("arn:aws:s3:::prod-", "", expiration).matches(entry))
+	assert.False(t, testException("arn:aws:s3:::prod.logs/bucket", "", expiration).matches(entry))
+	assert.False(t, testException("*", "a0e95b8b-6d93-4de5-a963-a2974fd2ba72", expiration).matches(entry))
+
+	other := *entry
+	other.PolicyID = "AWS.S3.Encryption"
+	assert.False(t, testException("*", "", expiration).matches(&other))
+}
+
+func TestExceptionItemMarshal(t *testing.T) {
+	exception := testException("arn:aws:s3:::prod-*", testIntegrationID, time.Now().UTC().Truncate(time.Second))
+	exception.ExpiryNotified = true
+
+	item, err := dynamodbattribute.MarshalMap(exception)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:s3:::prod-*", aws.StringValue(item["resourcePattern"].S))
+	assert.True(t, aws.BoolValue(item["expiryNotified"].BOOL))
+
+	var result exceptionItem
+	require.NoError(t, dynamodbattribute.UnmarshalMap(item, &result))
+	assert.Equal(t, exception.ExceptionID, result.ExceptionID)
+	assert.True(t, time.Time(*exception.ExpirationTime).Equal(time.Time(*result.ExpirationTime)))
+	assert.True(t, result.ExpiryNotified)
+}
+
+func TestCreateExceptionExpiration(t *testing.T) {
+	request := func(expiration time.Time) *events.APIGatewayProxyRequest {
+		return &events.APIGatewayProxyRequest{Body: `{
+			"expirationTime": "` + expiration.UTC().Format(time.RFC3339) + `",
+			"justification": "accepted",
+			"policyId": "AWS.S3.Versioning",
+			"resourcePattern": "*",
+			"userId": "test-user"
+		}`}
+	}
+
+	response := CreateException(request(time.Now().Add(-time.Hour)))
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Contains(t, response.Body, "must be in the future")
+
+	response = CreateException(request(time.Now().Add(400 * 24 * time.Hour)))
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Contains(t, response.Body, "at most a year from now")
+}
+
+func TestExpiryAlert(t *testing.T) {
+	now := time.Date(2020, 5, 14, 0, 0, 0, 0, time.UTC)
+	alert := expiryAlert(testException("arn:aws:s3:::prod-*", "", now.Add(72*time.Hour)), now)
+	assert.Equal(t, "AWS.S3.Versioning", aws.StringValue(alert.PolicyID))
+	assert.Equal(t, "INFO", aws.StringValue(alert.Severity))
+	assert.Equal(t, "POLICY", aws.StringValue(alert.Type))
+	assert.Equal(t, "The exception for the resources matching arn:aws:s3:::prod-* in all integrations expires "+
+		"at 2020-05-17T00:00:00Z, their failures will be alerted on again. Justification: accepted",
+		aws.StringValue(alert.PolicyDescription))
+}
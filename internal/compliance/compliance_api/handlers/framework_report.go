@@ -0,0 +1,240 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/compliance/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+const (
+	reportFormatCSV = "CSV"
+	reportFormatPDF = "PDF"
+
+	// Reports are written under this prefix of the report bucket, the bucket expires them after a day
+	reportKeyPrefix = "framework-reports/"
+	reportURLExpiry = time.Hour
+)
+
+var reportColumns = []string{
+	"integrationId", "control", "status", "policies", "failingResources", "failingPolicies",
+}
+
+type getFrameworkReportParams struct {
+	Framework     models.Framework
+	Format        string
+	IntegrationID models.IntegrationID
+}
+
+// A row of the report: the status of a control in an account
+type reportRow struct {
+	controlKey
+	*controlStatus
+}
+
+// GetFrameworkReport returns a CSV or PDF report of the status of the controls of a framework in each account.
+func GetFrameworkReport(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	params, err := parseGetFrameworkReport(request)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	controls, err := scanFrameworkControls([]models.Framework{params.Framework}, params.IntegrationID)
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	rows := sortedReportRows(controls[params.Framework])
+
+	now := time.Now().UTC()
+	fileName := fmt.Sprintf("panther-%s-report-%s", strings.ToLower(string(params.Framework)), now.Format("2006-01-02"))
+	var content []byte
+	var contentType string
+	if params.Format == reportFormatCSV {
+		fileName += ".csv"
+		contentType = "text/csv"
+		content, err = writeCSVReport(rows)
+	} else {
+		fileName += ".pdf"
+		contentType = "application/pdf"
+		content = writePDF(pdfReportLines(params.Framework, now, rows))
+	}
+	if err != nil {
+		zap.L().Error("failed to write report", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	url, expiresAt, err := uploadReport(content, contentType, fileName)
+	if err != nil {
+		zap.L().Error("failed to upload report", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	return gatewayapi.MarshalResponse(&models.ComplianceReport{
+		ContentType: aws.String(contentType),
+		ExpiresAt:   &expiresAt,
+		FileName:    aws.String(fileName),
+		URL:         aws.String(url),
+	}, http.StatusOK)
+}
+
+// Write the report to the report bucket and presign a download of it.
+//
+// The report is not returned inline: for a large org it could exceed the 6MB response limit of the function.
+func uploadReport(content []byte, contentType, fileName string) (string, strfmt.DateTime, error) {
+	key := reportKeyPrefix + uuid.New().String() + "/" + fileName
+	_, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:        bytes.NewReader(content),
+		Bucket:      &Env.ReportBucket,
+		ContentType: &contentType,
+		Key:         &key,
+	})
+	if err != nil {
+		return "", strfmt.DateTime{}, err
+	}
+
+	request, _ := s3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket:                     &Env.ReportBucket,
+		Key:                        &key,
+		ResponseContentDisposition: aws.String(fmt.Sprintf("attachment; filename=%q", fileName)),
+	})
+	expiresAt := time.Now().UTC().Add(reportURLExpiry)
+	url, err := request.Presign(reportURLExpiry)
+	if err != nil {
+		return "", strfmt.DateTime{}, err
+	}
+	return url, strfmt.DateTime(expiresAt), nil
+}
+
+func parseGetFrameworkReport(request *events.APIGatewayProxyRequest) (*getFrameworkReportParams, error) {
+	framework, err := parseFramework(request.QueryStringParameters["framework"])
+	if err != nil {
+		return nil, err
+	}
+
+	result := getFrameworkReportParams{
+		Framework:     framework,
+		Format:        request.QueryStringParameters["format"],
+		IntegrationID: models.IntegrationID(request.QueryStringParameters["integrationId"]),
+	}
+	if result.Format != reportFormatCSV && result.Format != reportFormatPDF {
+		return nil, errors.New("invalid format: must be CSV or PDF")
+	}
+	if result.IntegrationID != "" {
+		if err := result.IntegrationID.Validate(nil); err != nil {
+			return nil, errors.New("invalid integrationId: " + err.Error())
+		}
+	}
+	return &result, nil
+}
+
+// Sort the controls by account, then by control
+func sortedReportRows(controls map[controlKey]*controlStatus) []reportRow {
+	rows := make([]reportRow, 0, len(controls))
+	for key, entry := range controls {
+		rows = append(rows, reportRow{controlKey: key, controlStatus: entry})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].IntegrationID != rows[j].IntegrationID {
+			return rows[i].IntegrationID < rows[j].IntegrationID
+		}
+		return controlLess(rows[i].Control, rows[j].Control)
+	})
+	return rows
+}
+
+func sortedPolicies(policies map[models.PolicyID]bool) []string {
+	result := make([]string, 0, len(policies))
+	for policyID := range policies {
+		result = append(result, string(policyID))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func writeCSVReport(rows []reportRow) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(reportColumns); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		record := []string{
+			string(row.IntegrationID),
+			row.Control,
+			string(row.status()),
+			strconv.Itoa(len(row.Policies)),
+			strconv.Itoa(len(row.FailingResources)),
+			strings.Join(sortedPolicies(row.FailingPolicies), " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// The lines of the PDF report: a summary of each account followed by the table of its controls
+func pdfReportLines(framework models.Framework, now time.Time, rows []reportRow) []string {
+	lines := []string{
+		fmt.Sprintf("Panther %s compliance report", framework),
+		"Generated " + now.Format(time.RFC3339),
+	}
+	if len(rows) == 0 {
+		return append(lines, "", "No policies are mapped to the controls of this framework.")
+	}
+
+	for start := 0; start < len(rows); {
+		end := start
+		count := NewStatusCount()
+		for ; end < len(rows) && rows[end].IntegrationID == rows[start].IntegrationID; end++ {
+			updateStatusCount(count, rows[end].status())
+		}
+
+		lines = append(lines, "",
+			fmt.Sprintf("Account %s: %d passing, %d failing, %d errors (%.2f%% coverage)",
+				rows[start].IntegrationID, *count.Pass, *count.Fail, *count.Error, coverage(count)),
+			"",
+			fmt.Sprintf("%-12s %-6s %8s %9s  %s", "Control", "Status", "Policies", "Resources", "Failing policies"))
+		for _, row := range rows[start:end] {
+			lines = append(lines, fmt.Sprintf("%-12s %-6s %8d %9d  %s",
+				row.Control, row.status(), len(row.Policies), len(row.FailingResources),
+				strings.Join(sortedPolicies(row.FailingPolicies), " ")))
+		}
+		start = end
+	}
+	return lines
+}
@@ -0,0 +1,225 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/compliance/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+// The compliance frameworks in the order they are summarized
+var frameworks = []models.Framework{models.FrameworkCIS, models.FrameworkPCI, models.FrameworkSOC2}
+
+// A control of a framework in an account
+type controlKey struct {
+	IntegrationID models.IntegrationID
+	Control       string
+}
+
+// The status entries of the policies mapped to a control in an account
+type controlStatus struct {
+	Count            *models.StatusCount
+	Policies         map[models.PolicyID]bool
+	FailingPolicies  map[models.PolicyID]bool
+	FailingResources map[models.ResourceID]bool
+}
+
+func (c *controlStatus) status() models.Status {
+	return countToStatus(c.Count)
+}
+
+type frameworkControls map[models.Framework]map[controlKey]*controlStatus
+
+// GetFrameworkSummary returns the pass/fail status of the controls of the compliance frameworks in each account.
+func GetFrameworkSummary(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	selected := frameworks
+	if raw := request.QueryStringParameters["framework"]; raw != "" {
+		framework, err := parseFramework(raw)
+		if err != nil {
+			return badRequest(err)
+		}
+		selected = []models.Framework{framework}
+	}
+
+	controls, err := scanFrameworkControls(selected, "")
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	result := &models.FrameworkSummaries{Frameworks: make([]*models.FrameworkSummary, 0, len(selected))}
+	for _, framework := range selected {
+		result.Frameworks = append(result.Frameworks, buildFrameworkSummary(framework, controls[framework]))
+	}
+	return gatewayapi.MarshalResponse(result, http.StatusOK)
+}
+
+func parseFramework(raw string) (models.Framework, error) {
+	framework := models.Framework(raw)
+	if err := framework.Validate(nil); err != nil {
+		return "", errors.New("invalid framework: " + err.Error())
+	}
+	return framework, nil
+}
+
+// Scan the status entries and group them by the controls their policies are mapped to.
+//
+// Suppressed resources are not included. If integrationID is not empty, only the entries of that
+// integration are included.
+func scanFrameworkControls(selected []models.Framework, integrationID models.IntegrationID) (frameworkControls, error) {
+	filter := expression.Equal(expression.Name("suppressed"), expression.Value(false)).
+		And(expression.AttributeExists(expression.Name("policyTags")))
+	if integrationID != "" {
+		filter = filter.And(expression.Equal(expression.Name("integrationId"), expression.Value(integrationID)))
+	}
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		zap.L().Error("expression.Build failed", zap.Error(err))
+		return nil, err
+	}
+
+	result := make(frameworkControls, len(selected))
+	for _, framework := range selected {
+		result[framework] = make(map[controlKey]*controlStatus)
+	}
+
+	err = scanPages(&dynamodb.ScanInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		TableName:                 &Env.ComplianceTable,
+	}, func(item *models.ComplianceStatus) error {
+		for _, framework := range selected {
+			for _, control := range policyControls(item.PolicyTags, framework) {
+				key := controlKey{IntegrationID: item.IntegrationID, Control: control}
+				entry, ok := result[framework][key]
+				if !ok {
+					entry = &controlStatus{
+						Count:            NewStatusCount(),
+						Policies:         make(map[models.PolicyID]bool),
+						FailingPolicies:  make(map[models.PolicyID]bool),
+						FailingResources: make(map[models.ResourceID]bool),
+					}
+					result[framework][key] = entry
+				}
+
+				updateStatusCount(entry.Count, item.Status)
+				entry.Policies[item.PolicyID] = true
+				if item.Status != models.StatusPASS {
+					entry.FailingPolicies[item.PolicyID] = true
+					entry.FailingResources[item.ResourceID] = true
+				}
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Returns the controls of a framework a policy is mapped to by its tags, e.g. "CIS:1.3" maps it to CIS control 1.3
+func policyControls(tags models.PolicyTags, framework models.Framework) []string {
+	prefix := string(framework) + ":"
+	var controls []string
+	for _, tag := range tags {
+		if len(tag) <= len(prefix) || !strings.EqualFold(tag[:len(prefix)], prefix) {
+			continue
+		}
+		if control := strings.TrimSpace(tag[len(prefix):]); control != "" {
+			controls = append(controls, control)
+		}
+	}
+	return controls
+}
+
+func buildFrameworkSummary(framework models.Framework, controls map[controlKey]*controlStatus) *models.FrameworkSummary {
+	accounts := make(map[models.IntegrationID]*models.StatusCount)
+	// A control has the worst status it has in any account
+	overall := make(map[string]*models.StatusCount)
+	for key, entry := range controls {
+		count, ok := accounts[key.IntegrationID]
+		if !ok {
+			count = NewStatusCount()
+			accounts[key.IntegrationID] = count
+		}
+		updateStatusCount(count, entry.status())
+
+		if overall[key.Control] == nil {
+			overall[key.Control] = NewStatusCount()
+		}
+		updateStatusCount(overall[key.Control], entry.status())
+	}
+
+	summary := &models.FrameworkSummary{
+		Accounts:  make([]*models.AccountCompliance, 0, len(accounts)),
+		Controls:  NewStatusCount(),
+		Framework: framework,
+	}
+	for _, count := range overall {
+		updateStatusCount(summary.Controls, countToStatus(count))
+	}
+	for integrationID, count := range accounts {
+		summary.Accounts = append(summary.Accounts, &models.AccountCompliance{
+			Controls:      count,
+			Coverage:      aws.Float64(coverage(count)),
+			IntegrationID: integrationID,
+		})
+	}
+	sort.Slice(summary.Accounts, func(i, j int) bool {
+		return summary.Accounts[i].IntegrationID < summary.Accounts[j].IntegrationID
+	})
+	return summary
+}
+
+// The percentage of passing controls, rounded to 2 decimals
+func coverage(count *models.StatusCount) float64 {
+	total := *count.Pass + *count.Fail + *count.Error
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(*count.Pass)*10000/float64(total)) / 100
+}
+
+// Sort controls by their numbered sections, e.g. CIS 1.2 before 1.10
+func controlLess(left, right string) bool {
+	leftParts, rightParts := strings.Split(left, "."), strings.Split(right, ".")
+	for i := 0; i < len(leftParts) && i < len(rightParts); i++ {
+		if leftParts[i] == rightParts[i] {
+			continue
+		}
+		leftNumber, leftErr := strconv.Atoi(leftParts[i])
+		rightNumber, rightErr := strconv.Atoi(rightParts[i])
+		if leftErr == nil && rightErr == nil {
+			return leftNumber < rightNumber
+		}
+		return leftParts[i] < rightParts[i]
+	}
+	return len(leftParts) < len(rightParts)
+}
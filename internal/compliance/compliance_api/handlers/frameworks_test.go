@@ -0,0 +1,93 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/panther-labs/panther/api/gateway/compliance/models"
+)
+
+func TestPolicyControls(t *testing.T) {
+	tags := models.PolicyTags{"CIS:1.3", "cis:1.4", "PCI:8.2.1", "CIS:", "CISO", "SOC2: CC6.1 "}
+	assert.Equal(t, []string{"1.3", "1.4"}, policyControls(tags, models.FrameworkCIS))
+	assert.Equal(t, []string{"8.2.1"}, policyControls(tags, models.FrameworkPCI))
+	assert.Equal(t, []string{"CC6.1"}, policyControls(tags, models.FrameworkSOC2))
+	assert.Empty(t, policyControls(nil, models.FrameworkCIS))
+}
+
+func TestControlLess(t *testing.T) {
+	assert.True(t, controlLess("1.2", "1.10"))
+	assert.False(t, controlLess("1.10", "1.2"))
+	assert.True(t, controlLess("1", "1.1"))
+	assert.True(t, controlLess("CC6.1", "CC6.2"))
+	assert.True(t, controlLess("A1.2", "CC1.1"))
+}
+
+func TestBuildFrameworkSummary(t *testing.T) {
+	first, second := models.IntegrationID("aaaaaaaa-0000-0000-0000-000000000000"),
+		models.IntegrationID("bbbbbbbb-0000-0000-0000-000000000000")
+	status := func(pass, fail, errors int64) *controlStatus {
+		return &controlStatus{Count: &models.StatusCount{Pass: &pass, Fail: &fail, Error: &errors}}
+	}
+	summary := buildFrameworkSummary(models.FrameworkCIS, map[controlKey]*controlStatus{
+		{IntegrationID: first, Control: "1.1"}:  status(3, 0, 0),
+		{IntegrationID: first, Control: "1.2"}:  status(1, 1, 0),
+		{IntegrationID: first, Control: "1.3"}:  status(2, 0, 0),
+		{IntegrationID: second, Control: "1.1"}: status(1, 0, 0),
+		{IntegrationID: second, Control: "1.3"}: status(0, 1, 1),
+	})
+
+	// A control has its worst status in any account
+	assert.Equal(t, &models.StatusCount{Pass: aws.Int64(1), Fail: aws.Int64(1), Error: aws.Int64(1)}, summary.Controls)
+	assert.Equal(t, []*models.AccountCompliance{
+		{
+			Controls:      &models.StatusCount{Pass: aws.Int64(2), Fail: aws.Int64(1), Error: aws.Int64(0)},
+			Coverage:      aws.Float64(66.67),
+			IntegrationID: first,
+		},
+		{
+			Controls:      &models.StatusCount{Pass: aws.Int64(1), Fail: aws.Int64(0), Error: aws.Int64(1)},
+			Coverage:      aws.Float64(50),
+			IntegrationID: second,
+		},
+	}, summary.Accounts)
+}
+
+func TestWritePDF(t *testing.T) {
+	lines := make([]string, pdfLinesPerPage+1)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line (%d) \\ é", i)
+	}
+	pdf := writePDF(lines)
+
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "/Count 2")
+	assert.Contains(t, string(pdf), `(line \(0\) \\ ?) '`)
+
+	// The xref table points to each object
+	assert.Contains(t, string(pdf), fmt.Sprintf("%010d 00000 n \n", bytes.Index(pdf, []byte("1 0 obj"))))
+	assert.Contains(t, string(pdf), fmt.Sprintf("%010d 00000 n \n", bytes.Index(pdf, []byte("7 0 obj"))))
+}
@@ -55,11 +55,13 @@ func SetStatus(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyRe
 	for i, entry := range input.Entries {
 		status := &models.ComplianceStatus{
 			ErrorMessage:   entry.ErrorMessage,
+			ExceptionID:    entry.ExceptionID,
 			ExpiresAt:      models.ExpiresAt(expiresAt),
 			IntegrationID:  entry.IntegrationID,
 			LastUpdated:    models.LastUpdated(now),
 			PolicyID:       entry.PolicyID,
 			PolicySeverity: entry.PolicySeverity,
+			PolicyTags:     entry.PolicyTags,
 			ResourceID:     entry.ResourceID,
 			ResourceType:   entry.ResourceType,
 			Status:         entry.Status,
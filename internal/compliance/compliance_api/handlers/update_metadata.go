@@ -22,6 +22,7 @@ import (
 	"errors"
 	"net/http"
 	"path"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -33,7 +34,7 @@ import (
 	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
 )
 
-// UpdateMetadata updates status entries for a given policy with a new severity / suppression set.
+// UpdateMetadata updates status entries for a given policy with a new severity / suppression set / tags.
 func UpdateMetadata(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
 	input, err := parseUpdateMetadata(request)
 	if err != nil {
@@ -79,6 +80,12 @@ func itemsToUpdate(input *models.UpdateMetadata) ([]*dynamodb.WriteRequest, *eve
 		return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
 	}
 
+	// Resources which aren't suppressed by the policy can still be suppressed by an exception
+	exceptions, err := policyExceptions(input.PolicyID)
+	if err != nil {
+		return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
 	zap.L().Info("querying items to update",
 		zap.String("policyId", string(input.PolicyID)))
 	var writes []*dynamodb.WriteRequest
@@ -88,10 +95,22 @@ func itemsToUpdate(input *models.UpdateMetadata) ([]*dynamodb.WriteRequest, *eve
 			return patternErr
 		}
 
+		var exceptionID models.ExceptionID
+		if !ignored {
+			if exception := matchException(exceptions, item); exception != nil {
+				exceptionID = exception.ExceptionID
+			}
+		}
+		suppressed := ignored || exceptionID != ""
+
 		// This status entry has changed - we need to rewrite it
-		if bool(item.Suppressed) != ignored || item.PolicySeverity != input.Severity {
+		if bool(item.Suppressed) != suppressed || item.ExceptionID != exceptionID ||
+			item.PolicySeverity != input.Severity || !tagsEqual(item.PolicyTags, input.Tags) {
+
+			item.ExceptionID = exceptionID
 			item.PolicySeverity = input.Severity
-			item.Suppressed = models.Suppressed(ignored)
+			item.PolicyTags = input.Tags
+			item.Suppressed = models.Suppressed(suppressed)
 
 			marshalled, err := dynamodbattribute.MarshalMap(item)
 			if err != nil {
@@ -115,3 +134,37 @@ func itemsToUpdate(input *models.UpdateMetadata) ([]*dynamodb.WriteRequest, *eve
 
 	return writes, nil
 }
+
+// Returns true if both policies have the same tags, in any order
+func tagsEqual(first, second models.PolicyTags) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	tags := make(map[string]bool, len(first))
+	for _, tag := range first {
+		tags[tag] = true
+	}
+	for _, tag := range second {
+		if !tags[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the active exceptions of the policy
+func policyExceptions(policyID models.PolicyID) ([]*exceptionItem, error) {
+	exceptions, err := scanExceptions()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var result []*exceptionItem
+	for _, exception := range exceptions {
+		if exception.PolicyID == policyID && !exception.expired(now) {
+			result = append(result, exception)
+		}
+	}
+	return result, nil
+}
@@ -20,14 +20,18 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/go-openapi/strfmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -62,6 +66,7 @@ var (
 		{
 			PolicyID:       models.PolicyID("AWS-S3-Versioning"),
 			PolicySeverity: models.PolicySeverityMEDIUM,
+			PolicyTags:     models.PolicyTags{"CIS:2.1.3", "SOC2:CC6.1"},
 			ResourceID:     models.ResourceID("arn:aws:s3:::my-bucket"),
 			ResourceType:   models.ResourceType("AWS.S3.Bucket"),
 			Status:         models.StatusFAIL,
@@ -71,6 +76,7 @@ var (
 		{
 			PolicyID:       models.PolicyID("AWS-S3-Versioning"),
 			PolicySeverity: models.PolicySeverityMEDIUM,
+			PolicyTags:     models.PolicyTags{"CIS:2.1.3", "SOC2:CC6.1"},
 			ResourceID:     models.ResourceID("arn:aws:s3:::my-other-bucket"),
 			ResourceType:   models.ResourceType("AWS.S3.Bucket"),
 			Status:         models.StatusFAIL,
@@ -80,6 +86,7 @@ var (
 		{
 			PolicyID:       models.PolicyID("AWS-S3-BlockPublicAccess"),
 			PolicySeverity: models.PolicySeverityCRITICAL,
+			PolicyTags:     models.PolicyTags{"CIS:2.1.5", "PCI:1.3"},
 			ResourceID:     models.ResourceID("arn:aws:s3:::my-bucket"),
 			ResourceType:   models.ResourceType("AWS.S3.Bucket"),
 			Status:         models.StatusPASS,
@@ -89,6 +96,7 @@ var (
 		{
 			PolicyID:       models.PolicyID("AWS-Cloudtrail-Encryption"),
 			PolicySeverity: models.PolicySeverityCRITICAL,
+			PolicyTags:     models.PolicyTags{"CIS:3.7"},
 			ResourceID:     models.ResourceID("arn:aws:cloudtrail:123412341234::my-trail"),
 			ResourceType:   models.ResourceType("AWS.CloudTrail"),
 			Status:         models.StatusPASS,
@@ -124,6 +132,7 @@ func TestIntegrationAPI(t *testing.T) {
 
 	// Reset Dynamo table and build API client
 	require.NoError(t, testutils.ClearDynamoTable(awsSession, "panther-compliance"))
+	require.NoError(t, testutils.ClearDynamoTable(awsSession, "panther-compliance-exceptions"))
 	require.NotEmpty(t, endpoint)
 	apiClient = client.NewHTTPClientWithConfig(nil, client.DefaultTransportConfig().
 		WithBasePath("/v1").WithHost(endpoint))
@@ -165,9 +174,14 @@ func TestIntegrationAPI(t *testing.T) {
 		t.Run("GetOrgOverview", getOrgOverview)
 		t.Run("GetOrgOverviewCustomLimit", getOrgOverviewCustomLimit)
 	})
+	t.Run("Frameworks", func(t *testing.T) {
+		t.Run("GetFrameworkSummary", getFrameworkSummary)
+		t.Run("GetFrameworkReport", getFrameworkReport)
+	})
 	t.Run("DescribePolicyPageAndFilter", describePolicyPageAndFilter)
 
 	t.Run("Update", update)
+	t.Run("Exceptions", exceptions)
 	t.Run("Delete", deleteBatch)
 }
 
@@ -193,6 +207,7 @@ func setSuccess(t *testing.T) {
 			ErrorMessage:   status.ErrorMessage,
 			PolicyID:       status.PolicyID,
 			PolicySeverity: status.PolicySeverity,
+			PolicyTags:     status.PolicyTags,
 			ResourceID:     status.ResourceID,
 			ResourceType:   status.ResourceType,
 			Status:         status.Status,
@@ -743,12 +758,96 @@ func getOrgOverviewCustomLimit(t *testing.T) {
 	assert.Equal(t, models.ResourceID("arn:aws:s3:::my-bucket"), resources[0].ID)
 }
 
+func getFrameworkSummary(t *testing.T) {
+	t.Parallel()
+	result, err := apiClient.Operations.GetFrameworkSummary(&operations.GetFrameworkSummaryParams{
+		Framework:  aws.String("CIS"),
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+
+	// AWS-S3-Versioning fails control 2.1.3 on my-other-bucket (its failure on my-bucket is suppressed)
+	expected := &models.FrameworkSummaries{
+		Frameworks: []*models.FrameworkSummary{
+			{
+				Accounts: []*models.AccountCompliance{
+					{
+						Controls: &models.StatusCount{
+							Error: aws.Int64(0),
+							Fail:  aws.Int64(1),
+							Pass:  aws.Int64(2),
+						},
+						Coverage:      aws.Float64(66.67),
+						IntegrationID: integrationID,
+					},
+				},
+				Controls: &models.StatusCount{
+					Error: aws.Int64(0),
+					Fail:  aws.Int64(1),
+					Pass:  aws.Int64(2),
+				},
+				Framework: models.FrameworkCIS,
+			},
+		},
+	}
+	assert.Equal(t, expected, result.Payload)
+
+	// All of the frameworks are summarized by default
+	result, err = apiClient.Operations.GetFrameworkSummary(&operations.GetFrameworkSummaryParams{
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Payload.Frameworks, 3)
+	assert.Equal(t, models.FrameworkPCI, result.Payload.Frameworks[1].Framework)
+	assert.Equal(t, int64(1), *result.Payload.Frameworks[1].Controls.Pass)
+	assert.Equal(t, models.FrameworkSOC2, result.Payload.Frameworks[2].Framework)
+	assert.Equal(t, int64(1), *result.Payload.Frameworks[2].Controls.Fail)
+}
+
+func getFrameworkReport(t *testing.T) {
+	t.Parallel()
+	result, err := apiClient.Operations.GetFrameworkReport(&operations.GetFrameworkReportParams{
+		Format:     "CSV",
+		Framework:  "CIS",
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", *result.Payload.ContentType)
+	expected := "integrationId,control,status,policies,failingResources,failingPolicies\n" +
+		string(integrationID) + ",2.1.3,FAIL,1,1,AWS-S3-Versioning\n" +
+		string(integrationID) + ",2.1.5,PASS,1,0,\n" +
+		string(integrationID) + ",3.7,PASS,1,0,\n"
+	assert.Equal(t, expected, string(downloadReport(t, result.Payload)))
+
+	result, err = apiClient.Operations.GetFrameworkReport(&operations.GetFrameworkReportParams{
+		Format:     "PDF",
+		Framework:  "SOC2",
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", *result.Payload.ContentType)
+	assert.True(t, strings.HasPrefix(string(downloadReport(t, result.Payload)), "%PDF-1.4"))
+}
+
+// Download the report file from its presigned URL
+func downloadReport(t *testing.T, report *models.ComplianceReport) []byte {
+	assert.True(t, time.Time(*report.ExpiresAt).After(time.Now()))
+	response, err := http.Get(*report.URL)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+	return body
+}
+
 func update(t *testing.T) {
 	result, err := apiClient.Operations.UpdateMetadata(&operations.UpdateMetadataParams{
 		Body: &models.UpdateMetadata{
 			PolicyID:     "AWS-S3-Versioning",
 			Severity:     "INFO",
 			Suppressions: nil,
+			Tags:         statuses[1].PolicyTags,
 		},
 		HTTPClient: httpClient,
 	})
@@ -777,6 +876,71 @@ func update(t *testing.T) {
 	assert.Equal(t, statuses[2], entry.Payload)
 }
 
+func exceptions(t *testing.T) {
+	expiration := strfmt.DateTime(time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second))
+	created, err := apiClient.Operations.CreateException(&operations.CreateExceptionParams{
+		Body: &models.CreateException{
+			ExpirationTime:  &expiration,
+			IntegrationID:   integrationID,
+			Justification:   aws.String("Versioning is not needed for this bucket"),
+			PolicyID:        statuses[2].PolicyID,
+			ResourcePattern: aws.String("arn:aws:s3:::my-other-*"),
+			UserID:          aws.String("test-user"),
+		},
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	exception := created.Payload
+	require.NotEmpty(t, exception.ExceptionID)
+
+	// The failure of my-other-bucket is suppressed by the exception right away
+	entry, err := apiClient.Operations.GetStatus(&operations.GetStatusParams{
+		PolicyID:   string(statuses[2].PolicyID),
+		ResourceID: string(statuses[2].ResourceID),
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, exception.ExceptionID, entry.Payload.ExceptionID)
+	assert.True(t, bool(entry.Payload.Suppressed))
+
+	listed, err := apiClient.Operations.ListExceptions(&operations.ListExceptionsParams{
+		PolicyID:   aws.String(string(statuses[2].PolicyID)),
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.ExceptionList{Exceptions: []*models.ComplianceException{exception}}, listed.Payload)
+
+	// An expiration more than a year from now is rejected
+	tooLate := strfmt.DateTime(time.Now().Add(400 * 24 * time.Hour))
+	_, err = apiClient.Operations.CreateException(&operations.CreateExceptionParams{
+		Body: &models.CreateException{
+			ExpirationTime:  &tooLate,
+			Justification:   aws.String("Forever"),
+			PolicyID:        statuses[2].PolicyID,
+			ResourcePattern: aws.String("*"),
+			UserID:          aws.String("test-user"),
+		},
+		HTTPClient: httpClient,
+	})
+	assert.IsType(t, &operations.CreateExceptionBadRequest{}, err)
+
+	// Deleting the exception restores the failure
+	_, err = apiClient.Operations.DeleteExceptions(&operations.DeleteExceptionsParams{
+		Body:       &models.DeleteExceptions{ExceptionIds: []models.ExceptionID{exception.ExceptionID}},
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+
+	entry, err = apiClient.Operations.GetStatus(&operations.GetStatusParams{
+		PolicyID:   string(statuses[2].PolicyID),
+		ResourceID: string(statuses[2].ResourceID),
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, entry.Payload.ExceptionID)
+	assert.False(t, bool(entry.Payload.Suppressed))
+}
+
 func deleteBatch(t *testing.T) {
 	result, err := apiClient.Operations.DeleteStatus(&operations.DeleteStatusParams{
 		Body: &models.DeleteStatusBatch{
@@ -30,12 +30,20 @@ var methodHandlers = map[string]gatewayapi.RequestHandler{
 	"GET /describe-org":      handlers.DescribeOrg,
 	"GET /describe-policy":   handlers.DescribePolicy,
 	"GET /describe-resource": handlers.DescribeResource,
+	"GET /exceptions":        handlers.ListExceptions,
+	"GET /framework-report":  handlers.GetFrameworkReport,
+	"GET /framework-summary": handlers.GetFrameworkSummary,
 	"GET /org-overview":      handlers.GetOrgOverview,
 	"GET /status":            handlers.GetStatus,
 
-	"POST /delete": handlers.DeleteStatus,
-	"POST /status": handlers.SetStatus,
-	"POST /update": handlers.UpdateMetadata,
+	"POST /delete":            handlers.DeleteStatus,
+	"POST /delete-exceptions": handlers.DeleteExceptions,
+	"POST /exceptions":        handlers.CreateException,
+	"POST /status":            handlers.SetStatus,
+	"POST /update":            handlers.UpdateMetadata,
+
+	// Invoked by a scheduled event, not exposed by the API gateway
+	"POST /expire-exceptions": handlers.ExpireExceptions,
 }
 
 func main() {
@@ -42,3 +42,8 @@ func (m *mockInvoker) GetRemediations() (*models.Remediations, error) {
 	}
 	return args.Get(0).(*models.Remediations), args.Error(1)
 }
+
+func (m *mockInvoker) DeleteCustomRemediation(remediationID models.CustomRemediationID) error {
+	args := m.Called(remediationID)
+	return args.Error(0)
+}
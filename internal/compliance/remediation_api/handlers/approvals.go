@@ -0,0 +1,81 @@
+package apihandlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/remediation/models"
+	"github.com/panther-labs/panther/internal/compliance/remediation_api/remediation"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+// ListApprovals returns the remediations proposed for an approval
+func ListApprovals(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	status := models.ApprovalStatus(request.QueryStringParameters["status"])
+	if status != "" {
+		if err := status.Validate(nil); err != nil {
+			return badRequest(aws.String("invalid status: " + err.Error()))
+		}
+	}
+
+	approvals, err := invoker.ListApprovals(status)
+	if err != nil {
+		zap.L().Warn("failed to list approvals", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if approvals == nil {
+		approvals = []*models.RemediationApproval{}
+	}
+	return gatewayapi.MarshalResponse(&models.ApprovalList{Approvals: approvals}, http.StatusOK)
+}
+
+// ReviewApproval approves or rejects a proposed remediation
+func ReviewApproval(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	var review models.ReviewApproval
+	if err := jsoniter.UnmarshalFromString(request.Body, &review); err != nil {
+		return badRequest(aws.String("invalid request"))
+	}
+	if err := review.Validate(nil); err != nil {
+		return badRequest(aws.String(err.Error()))
+	}
+
+	approval, err := invoker.ReviewApproval(&review)
+	if err != nil {
+		switch err {
+		case remediation.ApprovalNotFound:
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound}
+		case remediation.ApprovalReviewed:
+			return gatewayapi.MarshalResponse(remediation.ApprovalReviewed, http.StatusConflict)
+		default:
+			zap.L().Warn("failed to review approval", zap.Error(err))
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		}
+	}
+
+	zap.L().Debug("reviewed remediation",
+		zap.String("approvalId", string(approval.ApprovalID)),
+		zap.String("status", string(approval.Status)))
+	return gatewayapi.MarshalResponse(approval, http.StatusOK)
+}
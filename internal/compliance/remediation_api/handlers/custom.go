@@ -0,0 +1,110 @@
+package apihandlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/remediation/models"
+	"github.com/panther-labs/panther/internal/compliance/remediation_api/remediation"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+// ListCustomRemediations returns the registered custom remediations
+func ListCustomRemediations(_ *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	remediations, err := invoker.ListCustomRemediations()
+	if err != nil {
+		zap.L().Warn("failed to list custom remediations", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if remediations == nil {
+		remediations = []*models.CustomRemediation{}
+	}
+	return gatewayapi.MarshalResponse(&models.CustomRemediationList{Remediations: remediations}, http.StatusOK)
+}
+
+// PutCustomRemediation registers or updates a custom remediation
+func PutCustomRemediation(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	var input models.PutCustomRemediation
+	if err := jsoniter.UnmarshalFromString(request.Body, &input); err != nil {
+		return badRequest(aws.String("invalid request"))
+	}
+	if err := input.Validate(nil); err != nil {
+		return badRequest(aws.String(err.Error()))
+	}
+
+	custom, err := invoker.PutCustomRemediation(&input)
+	if err != nil {
+		if modelErr, ok := err.(*models.Error); ok {
+			return badRequest(modelErr.Message)
+		}
+		zap.L().Warn("failed to put custom remediation", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(custom, http.StatusOK)
+}
+
+// DeleteCustomRemediation removes a custom remediation
+func DeleteCustomRemediation(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	var input models.DeleteCustomRemediation
+	if err := jsoniter.UnmarshalFromString(request.Body, &input); err != nil {
+		return badRequest(aws.String("invalid request"))
+	}
+	if err := input.Validate(nil); err != nil {
+		return badRequest(aws.String(err.Error()))
+	}
+
+	if err := invoker.DeleteCustomRemediation(input.RemediationID); err != nil {
+		if err == remediation.CustomRemediationNotFound {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound}
+		}
+		zap.L().Warn("failed to delete custom remediation", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}
+}
+
+// TestCustomRemediation invokes a custom remediation with a test resource
+func TestCustomRemediation(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	var input models.TestCustomRemediation
+	if err := jsoniter.UnmarshalFromString(request.Body, &input); err != nil {
+		return badRequest(aws.String("invalid request"))
+	}
+	if err := input.Validate(nil); err != nil {
+		return badRequest(aws.String(err.Error()))
+	}
+
+	result, err := invoker.TestCustomRemediation(&input)
+	if err != nil {
+		if err == remediation.CustomRemediationNotFound {
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound}
+		}
+		if modelErr, ok := err.(*models.Error); ok {
+			return badRequest(modelErr.Message)
+		}
+		zap.L().Warn("failed to test custom remediation", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(result, http.StatusOK)
+}
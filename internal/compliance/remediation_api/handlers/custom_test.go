@@ -0,0 +1,66 @@
+package apihandlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/panther-labs/panther/api/gateway/remediation/models"
+	"github.com/panther-labs/panther/internal/compliance/remediation_api/remediation"
+)
+
+func TestDeleteCustomRemediation(t *testing.T) {
+	mockInvoker := &mockInvoker{}
+	invoker = mockInvoker
+
+	request := &events.APIGatewayProxyRequest{Body: `{"remediationId": "Custom.Tag"}`}
+	mockInvoker.On("DeleteCustomRemediation", models.CustomRemediationID("Custom.Tag")).Return(nil)
+
+	response := DeleteCustomRemediation(request)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	mockInvoker.AssertExpectations(t)
+}
+
+func TestDeleteCustomRemediationNotFound(t *testing.T) {
+	mockInvoker := &mockInvoker{}
+	invoker = mockInvoker
+
+	request := &events.APIGatewayProxyRequest{Body: `{"remediationId": "Custom.Tag"}`}
+	mockInvoker.On("DeleteCustomRemediation", models.CustomRemediationID("Custom.Tag")).Return(
+		remediation.CustomRemediationNotFound)
+
+	response := DeleteCustomRemediation(request)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	mockInvoker.AssertExpectations(t)
+}
+
+func TestDeleteCustomRemediationInvalidID(t *testing.T) {
+	mockInvoker := &mockInvoker{}
+	invoker = mockInvoker
+
+	request := &events.APIGatewayProxyRequest{Body: `{"remediationId": "AWS.S3.EnableBucketEncryption"}`}
+
+	response := DeleteCustomRemediation(request)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	mockInvoker.AssertNotCalled(t, "DeleteCustomRemediation", models.CustomRemediationID("Custom.Tag"))
+}
@@ -27,8 +27,14 @@ import (
 
 var methodHandlers = map[string]gatewayapi.RequestHandler{
 	"GET /":                apihandlers.GetRemediations,
+	"GET /approvals":       apihandlers.ListApprovals,
+	"GET /custom":          apihandlers.ListCustomRemediations,
+	"POST /custom":         apihandlers.PutCustomRemediation,
+	"POST /delete-custom":  apihandlers.DeleteCustomRemediation,
 	"POST /remediate":      apihandlers.RemediateResource,
 	"POST /remediateasync": apihandlers.RemediateResourceAsync,
+	"POST /review":         apihandlers.ReviewApproval,
+	"POST /test-custom":    apihandlers.TestCustomRemediation,
 }
 
 func main() {
@@ -0,0 +1,323 @@
+package remediation
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	remediationmodels "github.com/panther-labs/panther/api/gateway/remediation/models"
+	resourcesmodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var (
+	approvalsTable = os.Getenv("APPROVALS_TABLE")
+	alertQueueURL  = os.Getenv("ALERT_QUEUE_URL")
+
+	ApprovalNotFound = &remediationmodels.Error{Message: aws.String("Remediation approval not found")}
+	ApprovalReviewed = &remediationmodels.Error{Message: aws.String("Remediation approval was already reviewed")}
+)
+
+// Queue the remediation of a policy failure for an approval and notify the destinations of the policy.
+//
+// A resource which keeps failing the policy has a single pending approval.
+func (remediator *Invoker) proposeRemediation(remediation *remediationmodels.RemediateResource,
+	policy *analysismodels.Policy, resource *resourcesmodels.Resource) error {
+
+	filter := expression.Name("status").Equal(expression.Value(remediationmodels.ApprovalStatusPENDING)).
+		And(expression.Name("policyId").Equal(expression.Value(remediation.PolicyID))).
+		And(expression.Name("resourceId").Equal(expression.Value(remediation.ResourceID)))
+	pending, err := remediator.scanApprovals(&filter)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		zap.L().Debug("remediation is already pending approval",
+			zap.String("approvalId", string(pending[0].ApprovalID)))
+		return nil
+	}
+
+	approval := newApproval(remediation, policy, resource, time.Now())
+	item, err := dynamodbattribute.MarshalMap(approval)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal approval")
+	}
+	if _, err = remediator.ddbClient.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(approvalsTable),
+	}); err != nil {
+		return errors.Wrap(err, "failed to store approval")
+	}
+
+	body, err := jsoniter.MarshalToString(approvalAlert(approval, policy))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal approval alert")
+	}
+	if _, err = remediator.sqsClient.SendMessage(&sqs.SendMessageInput{
+		MessageBody: &body,
+		QueueUrl:    aws.String(alertQueueURL),
+	}); err != nil {
+		return errors.Wrap(err, "failed to send approval alert")
+	}
+
+	zap.L().Info("proposed remediation for approval",
+		zap.String("approvalId", string(approval.ApprovalID)),
+		zap.Any("policyId", approval.PolicyID),
+		zap.Any("resourceId", approval.ResourceID))
+	return nil
+}
+
+func newApproval(remediation *remediationmodels.RemediateResource, policy *analysismodels.Policy,
+	resource *resourcesmodels.Resource, now time.Time) *remediationmodels.RemediationApproval {
+
+	createdAt := strfmt.DateTime(now.UTC())
+	return &remediationmodels.RemediationApproval{
+		ApprovalID:    remediationmodels.ApprovalID(uuid.New().String()),
+		Changes:       remediationChanges(policy.AutoRemediationParameters, resource.Attributes),
+		CreatedAt:     &createdAt,
+		Parameters:    policy.AutoRemediationParameters,
+		PolicyID:      remediation.PolicyID,
+		RemediationID: aws.String(string(policy.AutoRemediationID)),
+		ResourceID:    remediation.ResourceID,
+		Status:        remediationmodels.ApprovalStatusPENDING,
+	}
+}
+
+// The diff of a remediation are the values of its parameters next to the current values of the
+// resource attributes with the same name.
+func remediationChanges(parameters map[string]string, attributes interface{}) []*remediationmodels.RemediationChange {
+	current, _ := attributes.(map[string]interface{})
+	result := make([]*remediationmodels.RemediationChange, 0, len(parameters))
+	for name, value := range parameters {
+		result = append(result, &remediationmodels.RemediationChange{
+			Attribute: aws.String(name),
+			Current:   current[name],
+			Proposed:  aws.String(value),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return *result[i].Attribute < *result[j].Attribute })
+	return result
+}
+
+func approvalAlert(approval *remediationmodels.RemediationApproval, policy *analysismodels.Policy) *alertmodels.Alert {
+	changes := make([]string, len(approval.Changes))
+	for i, change := range approval.Changes {
+		current, _ := jsoniter.MarshalToString(change.Current)
+		changes[i] = fmt.Sprintf("%s: %s -> %s", *change.Attribute, current, *change.Proposed)
+	}
+	description := fmt.Sprintf(
+		"The remediation %s of the resource %s is waiting for an approval. Proposed changes: %s",
+		*approval.RemediationID, approval.ResourceID, strings.Join(changes, ", "))
+	if len(changes) == 0 {
+		description = fmt.Sprintf("The remediation %s of the resource %s is waiting for an approval.",
+			*approval.RemediationID, approval.ResourceID)
+	}
+
+	name := string(policy.DisplayName)
+	if name == "" {
+		name = string(policy.ID)
+	}
+	tags := make([]*string, len(policy.Tags))
+	for i, tag := range policy.Tags {
+		tags[i] = aws.String(tag)
+	}
+
+	return &alertmodels.Alert{
+		CreatedAt:         aws.Time(time.Time(*approval.CreatedAt)),
+		PolicyDescription: aws.String(description),
+		PolicyID:          aws.String(string(approval.PolicyID)),
+		PolicyName:        aws.String("Remediation approval required: " + name),
+		PolicyVersionID:   aws.String(string(policy.VersionID)),
+		Runbook: aws.String(fmt.Sprintf(
+			"Approve or reject the remediation with the remediation API, approval ID %s", approval.ApprovalID)),
+		Severity: aws.String(string(policy.Severity)),
+		Tags:     tags,
+		Type:     aws.String(alertmodels.PolicyType),
+	}
+}
+
+// ListApprovals returns the proposed remediations, most recent first, optionally only those with the given status.
+func (remediator *Invoker) ListApprovals(status remediationmodels.ApprovalStatus) ([]*remediationmodels.RemediationApproval, error) {
+	var filter *expression.ConditionBuilder
+	if status != "" {
+		condition := expression.Name("status").Equal(expression.Value(status))
+		filter = &condition
+	}
+	approvals, err := remediator.scanApprovals(filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return time.Time(*approvals[i].CreatedAt).After(time.Time(*approvals[j].CreatedAt))
+	})
+	return approvals, nil
+}
+
+func (remediator *Invoker) scanApprovals(filter *expression.ConditionBuilder) ([]*remediationmodels.RemediationApproval, error) {
+	input := &dynamodb.ScanInput{TableName: aws.String(approvalsTable)}
+	if filter != nil {
+		expr, err := expression.NewBuilder().WithFilter(*filter).Build()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build filter expression")
+		}
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+		input.FilterExpression = expr.Filter()
+	}
+
+	var result []*remediationmodels.RemediationApproval
+	var unmarshalErr error
+	err := remediator.ddbClient.ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var approvals []*remediationmodels.RemediationApproval
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &approvals); unmarshalErr != nil {
+			return false // stop paginating
+		}
+		result = append(result, approvals...)
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan approvals")
+	}
+	if unmarshalErr != nil {
+		return nil, errors.Wrap(unmarshalErr, "failed to unmarshal approvals")
+	}
+	return result, nil
+}
+
+// ReviewApproval approves or rejects a pending remediation, an approved remediation is invoked right away.
+//
+// The remediation which was proposed is invoked on the current state of the resource
+// and the outcome is recorded in the approval.
+func (remediator *Invoker) ReviewApproval(review *remediationmodels.ReviewApproval) (*remediationmodels.RemediationApproval, error) {
+	status := remediationmodels.ApprovalStatusREJECTED
+	if *review.Decision == remediationmodels.ReviewApprovalDecisionAPPROVE {
+		status = remediationmodels.ApprovalStatusAPPROVED
+	}
+
+	// Only a pending approval can be reviewed, a concurrent review of the same approval fails
+	condition := expression.Name("approvalId").AttributeExists().
+		And(expression.Name("status").Equal(expression.Value(remediationmodels.ApprovalStatusPENDING)))
+	update := expression.Set(expression.Name("status"), expression.Value(status)).
+		Set(expression.Name("reviewedAt"), expression.Value(time.Now().UTC())).
+		Set(expression.Name("reviewedBy"), expression.Value(*review.UserID))
+	if review.Reason != "" {
+		update = update.Set(expression.Name("reason"), expression.Value(review.Reason))
+	}
+	approval, err := remediator.updateApproval(review.ApprovalID, update, &condition)
+	if err != nil {
+		return nil, err
+	}
+	if status == remediationmodels.ApprovalStatusREJECTED {
+		zap.L().Info("rejected remediation", zap.String("approvalId", string(approval.ApprovalID)))
+		return approval, nil
+	}
+
+	outcome := expression.Set(expression.Name("status"), expression.Value(remediationmodels.ApprovalStatusREMEDIATED))
+	if err := remediator.remediateApproved(approval); err != nil {
+		zap.L().Warn("approved remediation failed",
+			zap.String("approvalId", string(approval.ApprovalID)), zap.Error(err))
+		outcome = expression.Set(expression.Name("status"), expression.Value(remediationmodels.ApprovalStatusFAILED)).
+			Set(expression.Name("error"), expression.Value(err.Error()))
+	}
+	return remediator.updateApproval(approval.ApprovalID, outcome, nil)
+}
+
+func (remediator *Invoker) remediateApproved(approval *remediationmodels.RemediationApproval) error {
+	policy, err := getPolicy(string(approval.PolicyID))
+	if err != nil {
+		return errors.Wrap(err, "Encountered issue when getting policy")
+	}
+	resource, err := getResource(string(approval.ResourceID))
+	if err != nil {
+		return errors.Wrap(err, "Encountered issue when getting resource")
+	}
+	return remediator.invokeRemediation(*approval.RemediationID, approval.Parameters, policy, resource)
+}
+
+func (remediator *Invoker) updateApproval(approvalID remediationmodels.ApprovalID, update expression.UpdateBuilder,
+	condition *expression.ConditionBuilder) (*remediationmodels.RemediationApproval, error) {
+
+	builder := expression.NewBuilder().WithUpdate(update)
+	if condition != nil {
+		builder = builder.WithCondition(*condition)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build update expression")
+	}
+
+	response, err := remediator.ddbClient.UpdateItem(&dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			"approvalId": {S: aws.String(string(approvalID))},
+		},
+		ReturnValues:     aws.String(dynamodb.ReturnValueAllNew),
+		TableName:        aws.String(approvalsTable),
+		UpdateExpression: expr.Update(),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && condition != nil &&
+			aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, remediator.reviewConflict(approvalID)
+		}
+		return nil, errors.Wrap(err, "failed to update approval")
+	}
+
+	var approval remediationmodels.RemediationApproval
+	if err := dynamodbattribute.UnmarshalMap(response.Attributes, &approval); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal approval")
+	}
+	return &approval, nil
+}
+
+// A review which fails its condition is for an approval which doesn't exist or was already reviewed
+func (remediator *Invoker) reviewConflict(approvalID remediationmodels.ApprovalID) error {
+	response, err := remediator.ddbClient.GetItem(&dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"approvalId": {S: aws.String(string(approvalID))},
+		},
+		TableName: aws.String(approvalsTable),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get approval")
+	}
+	if len(response.Item) == 0 {
+		return ApprovalNotFound
+	}
+	return ApprovalReviewed
+}
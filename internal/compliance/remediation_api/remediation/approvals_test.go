@@ -0,0 +1,213 @@
+package remediation
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/go-openapi/strfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	policymodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	processormodels "github.com/panther-labs/panther/api/gateway/remediation/models"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) ScanPages(input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool) error {
+	args := m.Called(input)
+	fn(args.Get(0).(*dynamodb.ScanOutput), true)
+	return args.Error(1)
+}
+
+func (m *mockDynamoClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
+type mockSqsClient struct {
+	sqsiface.SQSAPI
+	mock.Mock
+}
+
+func (m *mockSqsClient) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
+
+var approvalPolicy = &policymodels.Policy{
+	AutoRemediationApproval: true,
+	AutoRemediationID:       "AWS.S3.EnableBucketEncryption",
+	AutoRemediationParameters: map[string]string{
+		"SSEAlgorithm": "AES256",
+	},
+	ID:       "policyId",
+	Severity: policymodels.SeverityHIGH,
+}
+
+func testApproval(t *testing.T, status processormodels.ApprovalStatus) map[string]*dynamodb.AttributeValue {
+	createdAt := strfmt.DateTime(time.Now().UTC())
+	item, err := dynamodbattribute.MarshalMap(&processormodels.RemediationApproval{
+		ApprovalID:    "3c1f3b8e-4c2d-4b7a-9a5e-2f6d8e1a0b9c",
+		Changes:       []*processormodels.RemediationChange{},
+		CreatedAt:     &createdAt,
+		PolicyID:      "policyId",
+		RemediationID: aws.String("AWS.S3.EnableBucketEncryption"),
+		ResourceID:    "resourceId",
+		Status:        status,
+	})
+	require.NoError(t, err)
+	return item
+}
+
+func TestAutoRemediateProposesApproval(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	mockDynamo := &mockDynamoClient{}
+	mockSqs := &mockSqsClient{}
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: mockRoundTripper}
+	remediator := &Invoker{lambdaClient: mockClient, ddbClient: mockDynamo, sqsClient: mockSqs}
+
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(approvalPolicy, http.StatusOK), nil).Once()
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(resource, http.StatusOK), nil).Once()
+	mockDynamo.On("ScanPages", mock.Anything).Return(&dynamodb.ScanOutput{}, nil)
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+	mockSqs.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil)
+
+	require.NoError(t, remediator.AutoRemediate(input))
+
+	// The remediation is stored for an approval instead of invoking the remediation lambda
+	mockClient.AssertNotCalled(t, "Invoke", mock.Anything)
+	mockDynamo.AssertExpectations(t)
+	mockSqs.AssertExpectations(t)
+
+	var approval processormodels.RemediationApproval
+	putInput := mockDynamo.Calls[1].Arguments.Get(0).(*dynamodb.PutItemInput)
+	require.NoError(t, dynamodbattribute.UnmarshalMap(putInput.Item, &approval))
+	assert.NoError(t, approval.Validate(nil))
+	assert.Equal(t, processormodels.ApprovalStatusPENDING, approval.Status)
+	assert.Equal(t, "AWS.S3.EnableBucketEncryption", *approval.RemediationID)
+	assert.Contains(t, *mockSqs.Calls[0].Arguments.Get(0).(*sqs.SendMessageInput).MessageBody,
+		"Remediation approval required: policyId")
+}
+
+func TestAutoRemediateAlreadyPending(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	mockDynamo := &mockDynamoClient{}
+	mockSqs := &mockSqsClient{}
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: mockRoundTripper}
+	remediator := &Invoker{lambdaClient: mockClient, ddbClient: mockDynamo, sqsClient: mockSqs}
+
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(approvalPolicy, http.StatusOK), nil).Once()
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(resource, http.StatusOK), nil).Once()
+	mockDynamo.On("ScanPages", mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{testApproval(t, processormodels.ApprovalStatusPENDING)},
+	}, nil)
+
+	require.NoError(t, remediator.AutoRemediate(input))
+	mockClient.AssertNotCalled(t, "Invoke", mock.Anything)
+	mockDynamo.AssertNotCalled(t, "PutItem", mock.Anything)
+	mockSqs.AssertNotCalled(t, "SendMessage", mock.Anything)
+}
+
+func TestRemediationChanges(t *testing.T) {
+	changes := remediationChanges(
+		map[string]string{"SSEAlgorithm": "AES256", "KMSMasterKeyID": "key"},
+		map[string]interface{}{"SSEAlgorithm": nil, "Region": "us-west-2"})
+
+	require.Len(t, changes, 2)
+	assert.Equal(t, "KMSMasterKeyID", *changes[0].Attribute)
+	assert.Nil(t, changes[0].Current)
+	assert.Equal(t, "key", *changes[0].Proposed)
+	assert.Equal(t, "SSEAlgorithm", *changes[1].Attribute)
+	assert.Equal(t, "AES256", *changes[1].Proposed)
+}
+
+func TestReviewApprovalReject(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	mockDynamo := &mockDynamoClient{}
+	remediator := &Invoker{lambdaClient: mockClient, ddbClient: mockDynamo}
+
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: testApproval(t, processormodels.ApprovalStatusREJECTED),
+	}, nil)
+
+	approval, err := remediator.ReviewApproval(&processormodels.ReviewApproval{
+		ApprovalID: "3c1f3b8e-4c2d-4b7a-9a5e-2f6d8e1a0b9c",
+		Decision:   aws.String(processormodels.ReviewApprovalDecisionREJECT),
+		Reason:     "not now",
+		UserID:     aws.String("user"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, processormodels.ApprovalStatusREJECTED, approval.Status)
+	mockClient.AssertNotCalled(t, "Invoke", mock.Anything)
+	mockDynamo.AssertNumberOfCalls(t, "UpdateItem", 1)
+}
+
+func TestReviewApprovalAlreadyReviewed(t *testing.T) {
+	mockDynamo := &mockDynamoClient{}
+	remediator := &Invoker{ddbClient: mockDynamo}
+
+	mockDynamo.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+	mockDynamo.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: testApproval(t, processormodels.ApprovalStatusREMEDIATED),
+	}, nil).Once()
+	mockDynamo.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	review := &processormodels.ReviewApproval{
+		ApprovalID: "3c1f3b8e-4c2d-4b7a-9a5e-2f6d8e1a0b9c",
+		Decision:   aws.String(processormodels.ReviewApprovalDecisionAPPROVE),
+		UserID:     aws.String("user"),
+	}
+	_, err := remediator.ReviewApproval(review)
+	assert.Equal(t, ApprovalReviewed, err)
+	_, err = remediator.ReviewApproval(review)
+	assert.Equal(t, ApprovalNotFound, err)
+}
@@ -0,0 +1,295 @@
+package remediation
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/go-openapi/strfmt"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	remediationmodels "github.com/panther-labs/panther/api/gateway/remediation/models"
+	resourcesmodels "github.com/panther-labs/panther/api/gateway/resources/models"
+)
+
+const (
+	// The IDs of the custom remediations have this prefix, the other IDs are remediations of the Remediation Lambda
+	customRemediationPrefix = "Custom."
+
+	// The action of a test invocation of a custom remediation, the function shouldn't change the resource
+	testRemediationAction = "testRemediation"
+)
+
+var (
+	customRemediationsTable = os.Getenv("CUSTOM_REMEDIATIONS_TABLE")
+
+	CustomRemediationNotFound = &remediationmodels.Error{Message: aws.String("Custom remediation not found")}
+)
+
+// CustomPayload is the input to the customer Lambda function of a custom remediation,
+// it has the context of the policy and the resource on top of the remediation payload.
+type CustomPayload struct {
+	Payload
+	Policy       *PolicyContext `json:"policy,omitempty"`
+	ResourceID   string         `json:"resourceId,omitempty"`
+	ResourceType string         `json:"resourceType,omitempty"`
+}
+
+// PolicyContext describes the policy which the resource fails
+type PolicyContext struct {
+	ID          string   `json:"id"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Severity    string   `json:"severity"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+func isCustomRemediation(remediationID string) bool {
+	return strings.HasPrefix(remediationID, customRemediationPrefix)
+}
+
+func (remediator *Invoker) invokeCustomRemediation(remediationID string, parameters map[string]string,
+	policy *analysismodels.Policy, resource *resourcesmodels.Resource) error {
+
+	custom, err := remediator.getCustomRemediation(remediationID)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveParameters(custom.Parameters, parameters)
+	if err != nil {
+		return err
+	}
+
+	lambdaInput := &LambdaInput{
+		Action: aws.String(remediationAction),
+		Payload: &CustomPayload{
+			Payload: Payload{
+				RemediationID: remediationID,
+				Resource:      resource.Attributes,
+				Parameters:    resolved,
+			},
+			Policy: &PolicyContext{
+				ID:          string(policy.ID),
+				DisplayName: string(policy.DisplayName),
+				Severity:    string(policy.Severity),
+				Tags:        policy.Tags,
+			},
+			ResourceID:   string(resource.ID),
+			ResourceType: string(resource.Type),
+		},
+	}
+	if _, err = remediator.invokeFunction(string(custom.LambdaArn), lambdaInput); err != nil {
+		return errors.Wrap(err, "failed to invoke custom remediation "+remediationID)
+	}
+
+	zap.L().Debug("finished custom remediation", zap.String("remediationId", remediationID))
+	return nil
+}
+
+// The parameters configured by the policy, with the defaults of the missing ones
+func resolveParameters(schema remediationmodels.RemediationParameters, parameters map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(schema))
+	for _, parameter := range schema {
+		value, ok := parameters[*parameter.Name]
+		if !ok {
+			if parameter.Required {
+				return nil, &remediationmodels.Error{
+					Message: aws.String("missing required remediation parameter " + *parameter.Name)}
+			}
+			value = parameter.Default
+		}
+		result[*parameter.Name] = value
+	}
+	for name, value := range parameters {
+		if _, ok := result[name]; !ok {
+			result[name] = value // pass the extra parameters through, as for the Remediation Lambda
+		}
+	}
+	return result, nil
+}
+
+func defaultParameters(schema remediationmodels.RemediationParameters) map[string]string {
+	result := make(map[string]string, len(schema))
+	for _, parameter := range schema {
+		result[*parameter.Name] = parameter.Default
+	}
+	return result
+}
+
+func (remediator *Invoker) getCustomRemediation(remediationID string) (*remediationmodels.CustomRemediation, error) {
+	response, err := remediator.ddbClient.GetItem(&dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"remediationId": {S: aws.String(remediationID)},
+		},
+		TableName: aws.String(customRemediationsTable),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get custom remediation")
+	}
+	if len(response.Item) == 0 {
+		return nil, CustomRemediationNotFound
+	}
+
+	var custom remediationmodels.CustomRemediation
+	if err := dynamodbattribute.UnmarshalMap(response.Item, &custom); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal custom remediation")
+	}
+	return &custom, nil
+}
+
+// ListCustomRemediations returns the registered custom remediations, sorted by ID.
+func (remediator *Invoker) ListCustomRemediations() ([]*remediationmodels.CustomRemediation, error) {
+	var result []*remediationmodels.CustomRemediation
+	var unmarshalErr error
+	err := remediator.ddbClient.ScanPages(&dynamodb.ScanInput{TableName: aws.String(customRemediationsTable)},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var remediations []*remediationmodels.CustomRemediation
+			if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &remediations); unmarshalErr != nil {
+				return false // stop paginating
+			}
+			result = append(result, remediations...)
+			return true
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan custom remediations")
+	}
+	if unmarshalErr != nil {
+		return nil, errors.Wrap(unmarshalErr, "failed to unmarshal custom remediations")
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].RemediationID < result[j].RemediationID })
+	return result, nil
+}
+
+// PutCustomRemediation registers a custom remediation, or replaces the one with the same ID.
+func (remediator *Invoker) PutCustomRemediation(
+	input *remediationmodels.PutCustomRemediation) (*remediationmodels.CustomRemediation, error) {
+
+	names := make(map[string]bool, len(input.Parameters))
+	for _, parameter := range input.Parameters {
+		if names[*parameter.Name] {
+			return nil, &remediationmodels.Error{
+				Message: aws.String("duplicate remediation parameter " + *parameter.Name)}
+		}
+		names[*parameter.Name] = true
+	}
+
+	lastModified := strfmt.DateTime(time.Now().UTC())
+	custom := &remediationmodels.CustomRemediation{
+		Description:    input.Description,
+		LambdaArn:      input.LambdaArn,
+		LastModified:   &lastModified,
+		LastModifiedBy: input.UserID,
+		Parameters:     input.Parameters,
+		RemediationID:  input.RemediationID,
+	}
+	if custom.Parameters == nil {
+		custom.Parameters = remediationmodels.RemediationParameters{}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(custom)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal custom remediation")
+	}
+	if _, err = remediator.ddbClient.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(customRemediationsTable),
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to store custom remediation")
+	}
+	return custom, nil
+}
+
+// DeleteCustomRemediation removes a custom remediation, the policies which set it can no longer be remediated.
+func (remediator *Invoker) DeleteCustomRemediation(remediationID remediationmodels.CustomRemediationID) error {
+	response, err := remediator.ddbClient.DeleteItem(&dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"remediationId": {S: aws.String(string(remediationID))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+		TableName:    aws.String(customRemediationsTable),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to delete custom remediation")
+	}
+	if len(response.Attributes) == 0 {
+		return CustomRemediationNotFound
+	}
+	return nil
+}
+
+// TestCustomRemediation invokes the function of a custom remediation with a test resource.
+//
+// The function gets the test action, it is expected to check the resource and parameters without changing anything.
+func (remediator *Invoker) TestCustomRemediation(
+	input *remediationmodels.TestCustomRemediation) (*remediationmodels.TestCustomRemediationResult, error) {
+
+	custom, err := remediator.getCustomRemediation(string(input.RemediationID))
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := resolveParameters(custom.Parameters, input.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	serializedPayload, err := jsoniter.Marshal(&LambdaInput{
+		Action: aws.String(testRemediationAction),
+		Payload: &CustomPayload{
+			Payload: Payload{
+				RemediationID: string(input.RemediationID),
+				Resource:      input.Resource,
+				Parameters:    parameters,
+			},
+			ResourceType: input.ResourceType,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal lambda input")
+	}
+
+	response, err := remediator.lambdaClient.Invoke(&lambda.InvokeInput{
+		FunctionName: aws.String(string(custom.LambdaArn)),
+		Payload:      serializedPayload,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to invoke custom remediation "+string(input.RemediationID))
+	}
+
+	result := &remediationmodels.TestCustomRemediationResult{}
+	if len(response.Payload) > 0 {
+		if err := jsoniter.Unmarshal(response.Payload, &result.Output); err != nil {
+			result.Output = string(response.Payload) // not JSON, return it as it is
+		}
+	}
+	if response.FunctionError != nil {
+		result.Error = fmt.Sprintf("%s: %s", *response.FunctionError, string(response.Payload))
+	}
+	return result, nil
+}
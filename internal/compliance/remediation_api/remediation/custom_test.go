@@ -0,0 +1,113 @@
+package remediation
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	processormodels "github.com/panther-labs/panther/api/gateway/remediation/models"
+)
+
+var customParameters = processormodels.RemediationParameters{
+	{Name: aws.String("Retention"), Default: "30"},
+	{Name: aws.String("KmsKeyId"), Required: true},
+}
+
+func TestIsCustomRemediation(t *testing.T) {
+	assert.True(t, isCustomRemediation("Custom.AWS.S3.Tag"))
+	assert.False(t, isCustomRemediation("AWS.S3.EnableBucketEncryption"))
+}
+
+func TestResolveParameters(t *testing.T) {
+	result, err := resolveParameters(customParameters, map[string]string{"KmsKeyId": "key", "Extra": "value"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Retention": "30", "KmsKeyId": "key", "Extra": "value"}, result)
+
+	result, err = resolveParameters(customParameters, map[string]string{"KmsKeyId": "key", "Retention": "90"})
+	require.NoError(t, err)
+	assert.Equal(t, "90", result["Retention"])
+}
+
+func TestResolveParametersMissingRequired(t *testing.T) {
+	_, err := resolveParameters(customParameters, map[string]string{"Retention": "90"})
+	require.Error(t, err)
+	assert.Equal(t, "missing required remediation parameter KmsKeyId", *err.(*processormodels.Error).Message)
+}
+
+func TestInvokeCustomRemediationNotFound(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	mockDynamo := &mockDynamoClient{}
+	remediator := &Invoker{lambdaClient: mockClient, ddbClient: mockDynamo}
+
+	mockDynamo.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	err := remediator.invokeCustomRemediation("Custom.Missing", nil, approvalPolicy, resource)
+	assert.Equal(t, CustomRemediationNotFound, err)
+	mockClient.AssertNotCalled(t, "Invoke", mock.Anything)
+}
+
+func TestPutCustomRemediationDuplicateParameter(t *testing.T) {
+	mockDynamo := &mockDynamoClient{}
+	remediator := &Invoker{ddbClient: mockDynamo}
+
+	_, err := remediator.PutCustomRemediation(&processormodels.PutCustomRemediation{
+		LambdaArn: "arn:aws:lambda:us-west-2:123456789012:function:PantherCustomRemediationTag",
+		Parameters: processormodels.RemediationParameters{
+			{Name: aws.String("Tag")},
+			{Name: aws.String("Tag")},
+		},
+		RemediationID: "Custom.Tag",
+		UserID:        aws.String("userId"),
+	})
+	require.Error(t, err)
+	mockDynamo.AssertNotCalled(t, "PutItem", mock.Anything)
+}
+
+func TestPutCustomRemediation(t *testing.T) {
+	mockDynamo := &mockDynamoClient{}
+	remediator := &Invoker{ddbClient: mockDynamo}
+
+	mockDynamo.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+
+	result, err := remediator.PutCustomRemediation(&processormodels.PutCustomRemediation{
+		LambdaArn:     "arn:aws:lambda:us-west-2:123456789012:function:PantherCustomRemediationTag",
+		Parameters:    customParameters,
+		RemediationID: "Custom.Tag",
+		UserID:        aws.String("userId"),
+	})
+	require.NoError(t, err)
+	assert.NoError(t, result.Validate(nil))
+	assert.Equal(t, "userId", *result.LastModifiedBy)
+	mockDynamo.AssertExpectations(t)
+}
+
+func TestDeleteCustomRemediationNotFound(t *testing.T) {
+	mockDynamo := &mockDynamoClient{}
+	remediator := &Invoker{ddbClient: mockDynamo}
+
+	mockDynamo.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	assert.Equal(t, CustomRemediationNotFound, remediator.DeleteCustomRemediation("Custom.Missing"))
+}
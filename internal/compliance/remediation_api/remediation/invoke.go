@@ -69,30 +69,73 @@ func (remediator *Invoker) Remediate(remediation *remediationmodels.RemediateRes
 		zap.Any("policyId", remediation.PolicyID),
 		zap.Any("resourceId", remediation.ResourceID))
 
+	policy, resource, err := getRemediationTarget(remediation)
+	if err != nil {
+		return err
+	}
+	return remediator.invokeRemediation(
+		string(policy.AutoRemediationID), policy.AutoRemediationParameters, policy, resource)
+}
+
+// AutoRemediate is the automatic remediation of a policy failure.
+//
+// If the policy requires an approval, the remediation is proposed for an approval instead of running.
+func (remediator *Invoker) AutoRemediate(remediation *remediationmodels.RemediateResource) error {
+	zap.L().Debug("handling automatic remediation",
+		zap.Any("policyId", remediation.PolicyID),
+		zap.Any("resourceId", remediation.ResourceID))
+
+	policy, resource, err := getRemediationTarget(remediation)
+	if err != nil {
+		return err
+	}
+	if !policy.AutoRemediationApproval {
+		return remediator.invokeRemediation(
+			string(policy.AutoRemediationID), policy.AutoRemediationParameters, policy, resource)
+	}
+	return remediator.proposeRemediation(remediation, policy, resource)
+}
+
+// Get the policy with its remediation settings and the resource it remediates
+func getRemediationTarget(
+	remediation *remediationmodels.RemediateResource) (*analysismodels.Policy, *resourcesmodels.Resource, error) {
+
 	policy, err := getPolicy(string(remediation.PolicyID))
 	if err != nil {
-		return errors.Wrap(err, "Encountered issue when getting policy")
+		return nil, nil, errors.Wrap(err, "Encountered issue when getting policy")
 	}
 
 	if policy.AutoRemediationID == "" {
-		return RemediationNotFound
+		return nil, nil, RemediationNotFound
 	}
 
 	resource, err := getResource(string(remediation.ResourceID))
 	if err != nil {
-		return errors.Wrap(err, "Encountered issue when getting resource")
+		return nil, nil, errors.Wrap(err, "Encountered issue when getting resource")
 	}
+	return policy, resource, nil
+}
+
+// Invoke the remediation of the resource, either with the Remediation Lambda or with the customer function
+// of a custom remediation
+func (remediator *Invoker) invokeRemediation(remediationID string, parameters map[string]string,
+	policy *analysismodels.Policy, resource *resourcesmodels.Resource) error {
+
+	if isCustomRemediation(remediationID) {
+		return remediator.invokeCustomRemediation(remediationID, parameters, policy, resource)
+	}
+
 	remediationPayload := &Payload{
-		RemediationID: string(policy.AutoRemediationID),
+		RemediationID: remediationID,
 		Resource:      resource.Attributes,
-		Parameters:    policy.AutoRemediationParameters,
+		Parameters:    parameters,
 	}
 	lambdaInput := &LambdaInput{
 		Action:  aws.String(remediationAction),
 		Payload: remediationPayload,
 	}
 
-	_, err = remediator.invokeLambda(lambdaInput)
+	_, err := remediator.invokeLambda(lambdaInput)
 	if err != nil {
 		return errors.Wrap(err, "failed to invoke remediator")
 	}
@@ -120,6 +163,18 @@ func (remediator *Invoker) GetRemediations() (*remediationmodels.Remediations, e
 		return nil, err
 	}
 
+	// The custom remediations are listed with the default values of their parameters
+	custom, err := remediator.ListCustomRemediations()
+	if err != nil {
+		return nil, err
+	}
+	if remediations == nil && len(custom) > 0 {
+		remediations = make(remediationmodels.Remediations, len(custom))
+	}
+	for _, remediation := range custom {
+		remediations[string(remediation.RemediationID)] = defaultParameters(remediation.Parameters)
+	}
+
 	zap.L().Debug("finished action to get remediations")
 	return &remediations, nil
 }
@@ -149,6 +204,10 @@ func getResource(resourceID string) (*resourcesmodels.Resource, error) {
 }
 
 func (remediator *Invoker) invokeLambda(lambdaInput *LambdaInput) ([]byte, error) {
+	return remediator.invokeFunction(remediationLambdaArn, lambdaInput)
+}
+
+func (remediator *Invoker) invokeFunction(functionArn string, lambdaInput *LambdaInput) ([]byte, error) {
 	serializedPayload, err := jsoniter.Marshal(lambdaInput)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to marshal lambda input")
@@ -156,7 +215,7 @@ func (remediator *Invoker) invokeLambda(lambdaInput *LambdaInput) ([]byte, error
 
 	invokeInput := &lambda.InvokeInput{
 		Payload:      serializedPayload,
-		FunctionName: aws.String(remediationLambdaArn),
+		FunctionName: aws.String(functionArn),
 	}
 
 	response, err := remediator.lambdaClient.Invoke(invokeInput)
@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	jsoniter "github.com/json-iterator/go"
@@ -168,8 +169,10 @@ func TestRemediateLambdaFunctionError(t *testing.T) {
 
 func TestGetRemediations(t *testing.T) {
 	mockClient := &mockLambdaClient{}
+	mockDdbClient := &mockDynamoClient{}
 	remediator := &Invoker{
 		lambdaClient: mockClient,
+		ddbClient:    mockDdbClient,
 	}
 
 	expectedInput := LambdaInput{Action: aws.String(listRemediationsAction)}
@@ -182,6 +185,7 @@ func TestGetRemediations(t *testing.T) {
 
 	serializedRemediations := []byte("{\"AWS.S3.EnableBucketEncryption\": {\"SSEAlgorithm\": \"AES256\"}}")
 	mockClient.On("Invoke", expectedLambdaInput).Return(&lambda.InvokeOutput{Payload: serializedRemediations}, nil)
+	mockDdbClient.On("ScanPages", mock.Anything).Return(&dynamodb.ScanOutput{}, nil)
 
 	result, err := remediator.GetRemediations()
 	assert.NoError(t, err)
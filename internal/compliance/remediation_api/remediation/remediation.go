@@ -20,8 +20,12 @@ package remediation
 
 import (
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 
 	"github.com/panther-labs/panther/api/gateway/remediation/models"
 )
@@ -30,17 +34,31 @@ import (
 // the component that is responsible for invoking Remediation Lambda
 type InvokerAPI interface {
 	Remediate(*models.RemediateResource) error
+	AutoRemediate(*models.RemediateResource) error
 	GetRemediations() (*models.Remediations, error)
+	ListApprovals(models.ApprovalStatus) ([]*models.RemediationApproval, error)
+	ReviewApproval(*models.ReviewApproval) (*models.RemediationApproval, error)
+	ListCustomRemediations() ([]*models.CustomRemediation, error)
+	PutCustomRemediation(*models.PutCustomRemediation) (*models.CustomRemediation, error)
+	DeleteCustomRemediation(models.CustomRemediationID) error
+	TestCustomRemediation(*models.TestCustomRemediation) (*models.TestCustomRemediationResult, error)
 }
 
 //Invoker is responsible for invoking Remediation Lambda
 type Invoker struct {
 	lambdaClient lambdaiface.LambdaAPI
+
+	// The proposed remediations waiting for an approval and the notifications about them,
+	// and the registered custom remediations
+	ddbClient dynamodbiface.DynamoDBAPI
+	sqsClient sqsiface.SQSAPI
 }
 
 //NewInvoker method returns a new instance of Invoker
 func NewInvoker(sess *session.Session) *Invoker {
 	return &Invoker{
 		lambdaClient: lambda.New(sess),
+		ddbClient:    dynamodb.New(sess),
+		sqsClient:    sqs.New(sess),
 	}
 }
@@ -54,7 +54,7 @@ func lambdaHandler(ctx context.Context, event events.SQSEvent) (err error) {
 			err = errors.Wrap(err, "Failed to unmarshal item")
 			return err
 		}
-		if err = invoker.Remediate(&input); err != nil {
+		if err = invoker.AutoRemediate(&input); err != nil {
 			err = errors.Wrap(err, "encountered issue while processing event")
 			return err
 		}
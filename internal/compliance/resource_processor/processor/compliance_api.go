@@ -0,0 +1,83 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/compliance/client/operations"
+	compliancemodels "github.com/panther-labs/panther/api/gateway/compliance/models"
+)
+
+// Map policy ID to its active compliance exceptions
+type exceptionMap map[compliancemodels.PolicyID][]*compliancemodels.ComplianceException
+
+type exceptionCacheEntry struct {
+	LastUpdated time.Time
+	Exceptions  exceptionMap
+}
+
+var exceptionCache exceptionCacheEntry
+
+// Get active compliance exceptions from either the memory cache or the compliance-api
+func getExceptions() (exceptionMap, error) {
+	if exceptionCache.Exceptions != nil && exceptionCache.LastUpdated.Add(cacheDuration).After(time.Now()) {
+		// Cache entry exists and hasn't expired yet
+		return exceptionCache.Exceptions, nil
+	}
+
+	// Load from compliance-api
+	result, err := complianceClient.Operations.ListExceptions(
+		&operations.ListExceptionsParams{HTTPClient: httpClient})
+	if err != nil {
+		zap.L().Error("failed to load exceptions from compliance-api", zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("successfully loaded compliance exceptions from compliance-api",
+		zap.Int("exceptionCount", len(result.Payload.Exceptions)))
+
+	// Group the exceptions by policy
+	exceptions := make(exceptionMap)
+	for _, exception := range result.Payload.Exceptions {
+		exceptions[exception.PolicyID] = append(exceptions[exception.PolicyID], exception)
+	}
+
+	exceptionCache = exceptionCacheEntry{LastUpdated: time.Now(), Exceptions: exceptions}
+	return exceptions, nil
+}
+
+// Returns the exception which applies to the policy and resource of the status entry, if any
+func (m exceptionMap) match(entry *compliancemodels.SetStatus) *compliancemodels.ComplianceException {
+	now := time.Now()
+	for _, exception := range m[entry.PolicyID] {
+		if !time.Time(*exception.ExpirationTime).After(now) {
+			continue // expired since it was listed
+		}
+		if exception.IntegrationID != "" && exception.IntegrationID != entry.IntegrationID {
+			continue
+		}
+		if globMatch(aws.StringValue(exception.ResourcePattern), string(entry.ResourceID)) {
+			return exception
+		}
+	}
+	return nil
+}
@@ -122,6 +122,7 @@ func (r *batchResults) analyzeUpdatedPolicy(policy *analysismodels.Policy) error
 			ResourceTypes: policy.ResourceTypes,
 			Severity:      policy.Severity,
 			Suppressions:  policy.Suppressions,
+			Tags:          policy.Tags,
 			VersionID:     policy.VersionID,
 		},
 	}
@@ -166,17 +167,22 @@ func (r *batchResults) analyze(resources resourceMap, policies policyMap) error
 		return err
 	}
 
+	var exceptions exceptionMap
+	if exceptions, err = getExceptions(); err != nil {
+		return err
+	}
+
 	// Add a status entry for every policy/resource pair
 	for _, result := range analysis.Resources {
 		for _, policyError := range result.Errored {
-			entry := buildStatus(policies[policyError.ID], resources[result.ID], compliancemodels.StatusERROR)
+			entry := buildStatus(policies[policyError.ID], resources[result.ID], compliancemodels.StatusERROR, exceptions)
 			entry.ErrorMessage = compliancemodels.ErrorMessage(policyError.Message)
 			r.StatusEntries = append(r.StatusEntries, entry)
 		}
 
 		for _, policyID := range result.Failed {
 			policy, resource := policies[policyID], resources[result.ID]
-			entry := buildStatus(policy, resource, compliancemodels.StatusFAIL)
+			entry := buildStatus(policy, resource, compliancemodels.StatusFAIL, exceptions)
 			r.StatusEntries = append(r.StatusEntries, entry)
 
 			if entry.Suppressed {
@@ -234,7 +240,7 @@ func (r *batchResults) analyze(resources resourceMap, policies policyMap) error
 		}
 
 		for _, policyID := range result.Passed {
-			entry := buildStatus(policies[policyID], resources[result.ID], compliancemodels.StatusPASS)
+			entry := buildStatus(policies[policyID], resources[result.ID], compliancemodels.StatusPASS, exceptions)
 			r.StatusEntries = append(r.StatusEntries, entry)
 		}
 	}
@@ -303,11 +309,13 @@ func buildStatus(
 	policy *analysismodels.EnabledPolicy,
 	resource *resourcemodels.Resource,
 	status compliancemodels.Status,
+	exceptions exceptionMap,
 ) *compliancemodels.SetStatus {
 
-	return &compliancemodels.SetStatus{
+	entry := &compliancemodels.SetStatus{
 		PolicyID:       compliancemodels.PolicyID(policy.ID),
 		PolicySeverity: compliancemodels.PolicySeverity(policy.Severity),
+		PolicyTags:     compliancemodels.PolicyTags(policy.Tags),
 		ResourceID:     compliancemodels.ResourceID(resource.ID),
 		ResourceType:   compliancemodels.ResourceType(resource.Type),
 		Suppressed:     compliancemodels.Suppressed(isSuppressed(string(resource.ID), policy)),
@@ -315,31 +323,21 @@ func buildStatus(
 
 		Status: status,
 	}
+
+	// Resources which aren't suppressed by the policy can be suppressed by a compliance exception
+	if !entry.Suppressed {
+		if exception := exceptions.match(entry); exception != nil {
+			entry.ExceptionID = exception.ExceptionID
+			entry.Suppressed = true
+		}
+	}
+	return entry
 }
 
 // Returns true if the resource is suppressed by the given policy
 func isSuppressed(resourceID string, policy *analysismodels.EnabledPolicy) bool {
 	for _, pattern := range policy.Suppressions {
-		// Convert the glob pattern (e.g "prod.*.bucket") to regex ("prod\..*\.bucket")
-
-		// First, escape any regex special characters
-		escaped := regexp.QuoteMeta(pattern)
-
-		// Wildcards in the original pattern are now escaped literals - convert back
-		// NOTE: currently no way for user to specify a glob that would match a literal '*'
-		regex := "^" + strings.ReplaceAll(escaped, `\*`, `.*`) + "$"
-		matcher, err := regexp.Compile(regex)
-		if err != nil {
-			// We are building the regex, so it should always be valid
-			zap.L().Error("invalid regex",
-				zap.String("originalPattern", pattern),
-				zap.String("transformedRegex", regex),
-				zap.Error(err),
-			)
-			continue
-		}
-
-		if matcher.MatchString(resourceID) {
+		if globMatch(pattern, resourceID) {
 			return true
 		}
 	}
@@ -347,6 +345,30 @@ func isSuppressed(resourceID string, policy *analysismodels.EnabledPolicy) bool
 	return false
 }
 
+// Returns true if the resource matches the glob pattern
+func globMatch(pattern string, resourceID string) bool {
+	// Convert the glob pattern (e.g "prod.*.bucket") to regex ("prod\..*\.bucket")
+
+	// First, escape any regex special characters
+	escaped := regexp.QuoteMeta(pattern)
+
+	// Wildcards in the original pattern are now escaped literals - convert back
+	// NOTE: currently no way for user to specify a glob that would match a literal '*'
+	regex := "^" + strings.ReplaceAll(escaped, `\*`, `.*`) + "$"
+	matcher, err := regexp.Compile(regex)
+	if err != nil {
+		// We are building the regex, so it should always be valid
+		zap.L().Error("invalid regex",
+			zap.String("originalPattern", pattern),
+			zap.String("transformedRegex", regex),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	return matcher.MatchString(resourceID)
+}
+
 // Deliver all analysis results to compliance-api and alert-processor
 func (r *batchResults) deliver() error {
 	if len(r.StatusEntries) == 0 {
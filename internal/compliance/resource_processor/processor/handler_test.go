@@ -20,10 +20,15 @@ package processor
 
 import (
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-openapi/strfmt"
 	"github.com/stretchr/testify/assert"
 
 	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	compliancemodels "github.com/panther-labs/panther/api/gateway/compliance/models"
+	resourcemodels "github.com/panther-labs/panther/api/gateway/resources/models"
 )
 
 func TestIsSuppressed(t *testing.T) {
@@ -49,3 +54,43 @@ func TestIsSuppressed(t *testing.T) {
 		Suppressions: []string{"not", "this", "one", "but", "here:", "*.us-west-2/*"},
 	}))
 }
+
+func TestBuildStatusException(t *testing.T) {
+	integrationID := "f0e95b8b-6d93-4de5-a963-a2974fd2ba72"
+	active, expired := strfmt.DateTime(time.Now().Add(time.Hour)), strfmt.DateTime(time.Now().Add(-time.Hour))
+	exceptions := exceptionMap{
+		"AWS.S3.Versioning": {
+			{
+				ExceptionID:     "a0e95b8b-6d93-4de5-a963-a2974fd2ba72",
+				ExpirationTime:  &expired,
+				PolicyID:        "AWS.S3.Versioning",
+				ResourcePattern: aws.String("*"),
+			},
+			{
+				ExceptionID:     "b0e95b8b-6d93-4de5-a963-a2974fd2ba72",
+				ExpirationTime:  &active,
+				IntegrationID:   compliancemodels.IntegrationID(integrationID),
+				PolicyID:        "AWS.S3.Versioning",
+				ResourcePattern: aws.String("arn:aws:s3:::prod-*"),
+			},
+		},
+	}
+	policy := &analysismodels.EnabledPolicy{ID: "AWS.S3.Versioning", Suppressions: []string{"*-suppressed"}}
+	resource := func(id string) *resourcemodels.Resource {
+		return &resourcemodels.Resource{ID: resourcemodels.ResourceID(id), IntegrationID: resourcemodels.IntegrationID(integrationID)}
+	}
+
+	entry := buildStatus(policy, resource("arn:aws:s3:::prod-logs"), compliancemodels.StatusFAIL, exceptions)
+	assert.True(t, bool(entry.Suppressed))
+	assert.Equal(t, compliancemodels.ExceptionID("b0e95b8b-6d93-4de5-a963-a2974fd2ba72"), entry.ExceptionID)
+
+	// The expired exception doesn't apply anymore
+	entry = buildStatus(policy, resource("arn:aws:s3:::dev-logs"), compliancemodels.StatusFAIL, exceptions)
+	assert.False(t, bool(entry.Suppressed))
+	assert.Empty(t, entry.ExceptionID)
+
+	// The suppressions of the policy take precedence
+	entry = buildStatus(policy, resource("arn:aws:s3:::prod-suppressed"), compliancemodels.StatusFAIL, exceptions)
+	assert.True(t, bool(entry.Suppressed))
+	assert.Empty(t, entry.ExceptionID)
+}
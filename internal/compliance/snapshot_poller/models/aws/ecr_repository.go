@@ -0,0 +1,44 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "github.com/aws/aws-sdk-go/service/ecr"
+
+const (
+	EcrRepositorySchema = "AWS.ECR.Repository"
+)
+
+// EcrRepository contains all the information about an ECR Repository
+type EcrRepository struct {
+	// Generic resource fields
+	GenericAWSResource
+	GenericResource
+
+	// Fields embedded from ecr.Repository
+	ImageScanningConfiguration *ecr.ImageScanningConfiguration
+	ImageTagMutability         *string
+	RegistryId                 *string
+	RepositoryUri              *string
+
+	// Additional fields
+	Images            []*ecr.ImageDetail
+	ImageScanFindings *ecr.ImageScanFindings
+	LifecyclePolicy   *string
+	Policy            *string
+}
@@ -34,4 +34,12 @@ type ScanEntry struct {
 	ResourceID       *string `json:"resourceId"`
 	ResourceType     *string `json:"resourceType"`
 	ScanAllResources *bool   `json:"scanAllResources"`
+
+	// The regions of the integration the scans of every region are limited to: only the enabled regions when
+	// there are some, minus the excluded regions
+	EnabledRegions  []*string `json:"enabledRegions,omitempty"`
+	ExcludedRegions []*string `json:"excludedRegions,omitempty"`
+
+	// The most resources the scan reports, its share of the MaxObjectsPerScan of the integration. Nil means no limit
+	MaxResources *int `json:"maxResources,omitempty"`
 }
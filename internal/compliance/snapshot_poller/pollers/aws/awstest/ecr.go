@@ -0,0 +1,297 @@
+package awstest
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/stretchr/testify/mock"
+)
+
+// Example ECR API return values
+var (
+	ExampleEcrRepositoryName = aws.String("example-repository")
+	ExampleEcrRepositoryArn  = aws.String("arn:aws:ecr:us-west-2:123456789012:repository/example-repository")
+
+	ExampleEcrRepository = &ecr.Repository{
+		CreatedAt: ExampleDate,
+		ImageScanningConfiguration: &ecr.ImageScanningConfiguration{
+			ScanOnPush: aws.Bool(true),
+		},
+		ImageTagMutability: aws.String("MUTABLE"),
+		RegistryId:         aws.String("123456789012"),
+		RepositoryArn:      ExampleEcrRepositoryArn,
+		RepositoryName:     ExampleEcrRepositoryName,
+		RepositoryUri:      aws.String("123456789012.dkr.ecr.us-west-2.amazonaws.com/example-repository"),
+	}
+
+	ExampleEcrDescribeRepositoriesOutput = &ecr.DescribeRepositoriesOutput{
+		Repositories: []*ecr.Repository{
+			ExampleEcrRepository,
+		},
+	}
+
+	ExampleEcrDescribeImagesOutput = &ecr.DescribeImagesOutput{
+		ImageDetails: []*ecr.ImageDetail{
+			{
+				ImageDigest:      aws.String("sha256:1111"),
+				ImagePushedAt:    ExampleDate,
+				ImageSizeInBytes: aws.Int64(1024),
+				ImageTags:        aws.StringSlice([]string{"v1"}),
+				RegistryId:       aws.String("123456789012"),
+				RepositoryName:   ExampleEcrRepositoryName,
+			},
+			{
+				ImageDigest:      aws.String("sha256:2222"),
+				ImagePushedAt:    aws.Time(ExampleTimeParsed.Add(time.Hour)),
+				ImageSizeInBytes: aws.Int64(2048),
+				ImageTags:        aws.StringSlice([]string{"latest", "v2"}),
+				RegistryId:       aws.String("123456789012"),
+				RepositoryName:   ExampleEcrRepositoryName,
+			},
+		},
+	}
+
+	ExampleEcrDescribeImageScanFindingsOutput = &ecr.DescribeImageScanFindingsOutput{
+		ImageId: &ecr.ImageIdentifier{
+			ImageDigest: aws.String("sha256:2222"),
+		},
+		ImageScanFindings: &ecr.ImageScanFindings{
+			FindingSeverityCounts: map[string]*int64{
+				"HIGH": aws.Int64(1),
+			},
+			Findings: []*ecr.ImageScanFinding{
+				{
+					Name:     aws.String("CVE-2019-0000"),
+					Severity: aws.String("HIGH"),
+					Uri:      aws.String("https://security-tracker.debian.org/tracker/CVE-2019-0000"),
+				},
+			},
+			ImageScanCompletedAt:         ExampleDate,
+			VulnerabilitySourceUpdatedAt: ExampleDate,
+		},
+		RegistryId:     aws.String("123456789012"),
+		RepositoryName: ExampleEcrRepositoryName,
+	}
+
+	ExampleEcrGetRepositoryPolicyOutput = &ecr.GetRepositoryPolicyOutput{
+		PolicyText:     aws.String("{\"Version\":\"2008-10-17\",\"Statement\":[]}"),
+		RegistryId:     aws.String("123456789012"),
+		RepositoryName: ExampleEcrRepositoryName,
+	}
+
+	ExampleEcrListTagsForResourceOutput = &ecr.ListTagsForResourceOutput{
+		Tags: []*ecr.Tag{
+			{
+				Key:   aws.String("Key1"),
+				Value: aws.String("Value1"),
+			},
+		},
+	}
+
+	svcEcrSetupCalls = map[string]func(*MockEcr){
+		"DescribeRepositoriesPages": func(svc *MockEcr) {
+			svc.On("DescribeRepositoriesPages", mock.Anything).
+				Return(nil)
+		},
+		"DescribeRepositories": func(svc *MockEcr) {
+			svc.On("DescribeRepositories", mock.Anything).
+				Return(ExampleEcrDescribeRepositoriesOutput, nil)
+		},
+		"DescribeImagesPages": func(svc *MockEcr) {
+			svc.On("DescribeImagesPages", mock.Anything).
+				Return(nil)
+		},
+		"DescribeImageScanFindings": func(svc *MockEcr) {
+			svc.On("DescribeImageScanFindings", mock.Anything).
+				Return(ExampleEcrDescribeImageScanFindingsOutput, nil)
+		},
+		"GetLifecyclePolicy": func(svc *MockEcr) {
+			svc.On("GetLifecyclePolicy", mock.Anything).
+				Return(&ecr.GetLifecyclePolicyOutput{},
+					awserr.New(ecr.ErrCodeLifecyclePolicyNotFoundException, "Lifecycle policy does not exist", nil),
+				)
+		},
+		"GetRepositoryPolicy": func(svc *MockEcr) {
+			svc.On("GetRepositoryPolicy", mock.Anything).
+				Return(ExampleEcrGetRepositoryPolicyOutput, nil)
+		},
+		"ListTagsForResource": func(svc *MockEcr) {
+			svc.On("ListTagsForResource", mock.Anything).
+				Return(ExampleEcrListTagsForResourceOutput, nil)
+		},
+	}
+
+	svcEcrSetupCallsError = map[string]func(*MockEcr){
+		"DescribeRepositoriesPages": func(svc *MockEcr) {
+			svc.On("DescribeRepositoriesPages", mock.Anything).
+				Return(errors.New("ECR.DescribeRepositoriesPages error"))
+		},
+		"DescribeRepositories": func(svc *MockEcr) {
+			svc.On("DescribeRepositories", mock.Anything).
+				Return(&ecr.DescribeRepositoriesOutput{},
+					errors.New("ECR.DescribeRepositories error"),
+				)
+		},
+		"DescribeImagesPages": func(svc *MockEcr) {
+			svc.On("DescribeImagesPages", mock.Anything).
+				Return(errors.New("ECR.DescribeImagesPages error"))
+		},
+		"DescribeImageScanFindings": func(svc *MockEcr) {
+			svc.On("DescribeImageScanFindings", mock.Anything).
+				Return(&ecr.DescribeImageScanFindingsOutput{},
+					errors.New("ECR.DescribeImageScanFindings error"),
+				)
+		},
+		"GetLifecyclePolicy": func(svc *MockEcr) {
+			svc.On("GetLifecyclePolicy", mock.Anything).
+				Return(&ecr.GetLifecyclePolicyOutput{},
+					errors.New("ECR.GetLifecyclePolicy error"),
+				)
+		},
+		"GetRepositoryPolicy": func(svc *MockEcr) {
+			svc.On("GetRepositoryPolicy", mock.Anything).
+				Return(&ecr.GetRepositoryPolicyOutput{},
+					errors.New("ECR.GetRepositoryPolicy error"),
+				)
+		},
+		"ListTagsForResource": func(svc *MockEcr) {
+			svc.On("ListTagsForResource", mock.Anything).
+				Return(&ecr.ListTagsForResourceOutput{},
+					errors.New("ECR.ListTagsForResource error"),
+				)
+		},
+	}
+
+	MockEcrForSetup = &MockEcr{}
+)
+
+// ECR mock
+
+// SetupMockEcr is used to override the ECR Client initializer
+func SetupMockEcr(_ *session.Session, _ *aws.Config) interface{} {
+	return MockEcrForSetup
+}
+
+// MockEcr is a mock ECR client
+type MockEcr struct {
+	ecriface.ECRAPI
+	mock.Mock
+}
+
+// BuildMockEcrSvc builds and returns a MockEcr struct
+//
+// Additionally, the appropriate calls to On and Return are made based on the strings passed in
+func BuildMockEcrSvc(funcs []string) (mockSvc *MockEcr) {
+	mockSvc = &MockEcr{}
+	for _, f := range funcs {
+		svcEcrSetupCalls[f](mockSvc)
+	}
+	return
+}
+
+// BuildMockEcrSvcError builds and returns a MockEcr struct with errors set
+//
+// Additionally, the appropriate calls to On and Return are made based on the strings passed in
+func BuildMockEcrSvcError(funcs []string) (mockSvc *MockEcr) {
+	mockSvc = &MockEcr{}
+	for _, f := range funcs {
+		svcEcrSetupCallsError[f](mockSvc)
+	}
+	return
+}
+
+// BuildMockEcrSvcAll builds and returns a MockEcr struct
+//
+// Additionally, the appropriate calls to On and Return are made for all possible function calls
+func BuildMockEcrSvcAll() (mockSvc *MockEcr) {
+	mockSvc = &MockEcr{}
+	for _, f := range svcEcrSetupCalls {
+		f(mockSvc)
+	}
+	return
+}
+
+// BuildMockEcrSvcAllError builds and returns a MockEcr struct with errors set
+//
+// Additionally, the appropriate calls to On and Return are made for all possible function calls
+func BuildMockEcrSvcAllError() (mockSvc *MockEcr) {
+	mockSvc = &MockEcr{}
+	for _, f := range svcEcrSetupCallsError {
+		f(mockSvc)
+	}
+	return
+}
+
+func (m *MockEcr) DescribeRepositoriesPages(
+	in *ecr.DescribeRepositoriesInput,
+	paginationFunction func(*ecr.DescribeRepositoriesOutput, bool) bool,
+) error {
+
+	args := m.Called(in)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	paginationFunction(ExampleEcrDescribeRepositoriesOutput, true)
+	return args.Error(0)
+}
+
+func (m *MockEcr) DescribeImagesPages(
+	in *ecr.DescribeImagesInput,
+	paginationFunction func(*ecr.DescribeImagesOutput, bool) bool,
+) error {
+
+	args := m.Called(in)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	paginationFunction(ExampleEcrDescribeImagesOutput, true)
+	return args.Error(0)
+}
+
+func (m *MockEcr) DescribeRepositories(in *ecr.DescribeRepositoriesInput) (*ecr.DescribeRepositoriesOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*ecr.DescribeRepositoriesOutput), args.Error(1)
+}
+
+func (m *MockEcr) DescribeImageScanFindings(in *ecr.DescribeImageScanFindingsInput) (*ecr.DescribeImageScanFindingsOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*ecr.DescribeImageScanFindingsOutput), args.Error(1)
+}
+
+func (m *MockEcr) GetLifecyclePolicy(in *ecr.GetLifecyclePolicyInput) (*ecr.GetLifecyclePolicyOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*ecr.GetLifecyclePolicyOutput), args.Error(1)
+}
+
+func (m *MockEcr) GetRepositoryPolicy(in *ecr.GetRepositoryPolicyInput) (*ecr.GetRepositoryPolicyOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*ecr.GetRepositoryPolicyOutput), args.Error(1)
+}
+
+func (m *MockEcr) ListTagsForResource(in *ecr.ListTagsForResourceInput) (*ecr.ListTagsForResourceOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*ecr.ListTagsForResourceOutput), args.Error(1)
+}
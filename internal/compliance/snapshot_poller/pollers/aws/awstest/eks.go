@@ -0,0 +1,238 @@
+package awstest
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/stretchr/testify/mock"
+)
+
+// Example EKS API return values
+var (
+	ExampleEksClusterName = aws.String("example-cluster")
+	ExampleEksClusterArn  = aws.String("arn:aws:eks:us-west-2:123456789012:cluster/example-cluster")
+
+	ExampleEksListClusters = &eks.ListClustersOutput{
+		Clusters: []*string{
+			ExampleEksClusterName,
+		},
+	}
+
+	ExampleEksListNodegroups = &eks.ListNodegroupsOutput{
+		Nodegroups: []*string{
+			aws.String("example-nodegroup"),
+		},
+	}
+
+	ExampleEksDescribeClusterOutput = &eks.DescribeClusterOutput{
+		Cluster: &eks.Cluster{
+			Arn:       ExampleEksClusterArn,
+			CreatedAt: ExampleDate,
+			Endpoint:  aws.String("https://1111.gr7.us-west-2.eks.amazonaws.com"),
+			Logging: &eks.Logging{
+				ClusterLogging: []*eks.LogSetup{
+					{
+						Enabled: aws.Bool(false),
+						Types:   aws.StringSlice([]string{"api", "audit", "authenticator", "controllerManager", "scheduler"}),
+					},
+				},
+			},
+			Name:            ExampleEksClusterName,
+			PlatformVersion: aws.String("eks.2"),
+			ResourcesVpcConfig: &eks.VpcConfigResponse{
+				EndpointPrivateAccess: aws.Bool(false),
+				EndpointPublicAccess:  aws.Bool(true),
+				PublicAccessCidrs:     aws.StringSlice([]string{"0.0.0.0/0"}),
+				SubnetIds:             aws.StringSlice([]string{"subnet-111", "subnet-222"}),
+				VpcId:                 aws.String("vpc-111"),
+			},
+			RoleArn: aws.String("arn:aws:iam::123456789012:role/eks-cluster-role"),
+			Status:  aws.String("ACTIVE"),
+			Tags: map[string]*string{
+				"Key1": aws.String("Value1"),
+			},
+			Version: aws.String("1.15"),
+		},
+	}
+
+	ExampleEksDescribeNodegroupOutput = &eks.DescribeNodegroupOutput{
+		Nodegroup: &eks.Nodegroup{
+			AmiType:        aws.String("AL2_x86_64"),
+			ClusterName:    ExampleEksClusterName,
+			CreatedAt:      ExampleDate,
+			DiskSize:       aws.Int64(20),
+			InstanceTypes:  aws.StringSlice([]string{"t3.medium"}),
+			NodeRole:       aws.String("arn:aws:iam::123456789012:role/eks-node-role"),
+			NodegroupArn:   aws.String("arn:aws:eks:us-west-2:123456789012:nodegroup/example-cluster/example-nodegroup/1111"),
+			NodegroupName:  aws.String("example-nodegroup"),
+			ReleaseVersion: aws.String("1.15.10-20200228"),
+			ScalingConfig: &eks.NodegroupScalingConfig{
+				DesiredSize: aws.Int64(2),
+				MaxSize:     aws.Int64(2),
+				MinSize:     aws.Int64(2),
+			},
+			Status:  aws.String("ACTIVE"),
+			Subnets: aws.StringSlice([]string{"subnet-111", "subnet-222"}),
+			Tags:    map[string]*string{},
+			Version: aws.String("1.15"),
+		},
+	}
+
+	svcEksSetupCalls = map[string]func(*MockEks){
+		"ListClustersPages": func(svc *MockEks) {
+			svc.On("ListClustersPages", mock.Anything).
+				Return(nil)
+		},
+		"ListNodegroupsPages": func(svc *MockEks) {
+			svc.On("ListNodegroupsPages", mock.Anything).
+				Return(nil)
+		},
+		"DescribeCluster": func(svc *MockEks) {
+			svc.On("DescribeCluster", mock.Anything).
+				Return(ExampleEksDescribeClusterOutput, nil)
+		},
+		"DescribeNodegroup": func(svc *MockEks) {
+			svc.On("DescribeNodegroup", mock.Anything).
+				Return(ExampleEksDescribeNodegroupOutput, nil)
+		},
+	}
+
+	svcEksSetupCallsError = map[string]func(*MockEks){
+		"ListClustersPages": func(svc *MockEks) {
+			svc.On("ListClustersPages", mock.Anything).
+				Return(errors.New("EKS.ListClustersPages error"))
+		},
+		"ListNodegroupsPages": func(svc *MockEks) {
+			svc.On("ListNodegroupsPages", mock.Anything).
+				Return(errors.New("EKS.ListNodegroupsPages error"))
+		},
+		"DescribeCluster": func(svc *MockEks) {
+			svc.On("DescribeCluster", mock.Anything).
+				Return(&eks.DescribeClusterOutput{},
+					errors.New("EKS.DescribeCluster error"),
+				)
+		},
+		"DescribeNodegroup": func(svc *MockEks) {
+			svc.On("DescribeNodegroup", mock.Anything).
+				Return(&eks.DescribeNodegroupOutput{},
+					errors.New("EKS.DescribeNodegroup error"),
+				)
+		},
+	}
+
+	MockEksForSetup = &MockEks{}
+)
+
+// EKS mock
+
+// SetupMockEks is used to override the EKS Client initializer
+func SetupMockEks(_ *session.Session, _ *aws.Config) interface{} {
+	return MockEksForSetup
+}
+
+// MockEks is a mock EKS client
+type MockEks struct {
+	eksiface.EKSAPI
+	mock.Mock
+}
+
+// BuildMockEksSvc builds and returns a MockEks struct
+//
+// Additionally, the appropriate calls to On and Return are made based on the strings passed in
+func BuildMockEksSvc(funcs []string) (mockSvc *MockEks) {
+	mockSvc = &MockEks{}
+	for _, f := range funcs {
+		svcEksSetupCalls[f](mockSvc)
+	}
+	return
+}
+
+// BuildMockEksSvcError builds and returns a MockEks struct with errors set
+//
+// Additionally, the appropriate calls to On and Return are made based on the strings passed in
+func BuildMockEksSvcError(funcs []string) (mockSvc *MockEks) {
+	mockSvc = &MockEks{}
+	for _, f := range funcs {
+		svcEksSetupCallsError[f](mockSvc)
+	}
+	return
+}
+
+// BuildMockEksSvcAll builds and returns a MockEks struct
+//
+// Additionally, the appropriate calls to On and Return are made for all possible function calls
+func BuildMockEksSvcAll() (mockSvc *MockEks) {
+	mockSvc = &MockEks{}
+	for _, f := range svcEksSetupCalls {
+		f(mockSvc)
+	}
+	return
+}
+
+// BuildMockEksSvcAllError builds and returns a MockEks struct with errors set
+//
+// Additionally, the appropriate calls to On and Return are made for all possible function calls
+func BuildMockEksSvcAllError() (mockSvc *MockEks) {
+	mockSvc = &MockEks{}
+	for _, f := range svcEksSetupCallsError {
+		f(mockSvc)
+	}
+	return
+}
+
+func (m *MockEks) ListClustersPages(
+	in *eks.ListClustersInput,
+	paginationFunction func(*eks.ListClustersOutput, bool) bool,
+) error {
+
+	args := m.Called(in)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	paginationFunction(ExampleEksListClusters, true)
+	return args.Error(0)
+}
+
+func (m *MockEks) ListNodegroupsPages(
+	in *eks.ListNodegroupsInput,
+	paginationFunction func(*eks.ListNodegroupsOutput, bool) bool,
+) error {
+
+	args := m.Called(in)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	paginationFunction(ExampleEksListNodegroups, true)
+	return args.Error(0)
+}
+
+func (m *MockEks) DescribeCluster(in *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*eks.DescribeClusterOutput), args.Error(1)
+}
+
+func (m *MockEks) DescribeNodegroup(in *eks.DescribeNodegroupInput) (*eks.DescribeNodegroupOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*eks.DescribeNodegroupOutput), args.Error(1)
+}
@@ -0,0 +1,214 @@
+package awstest
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/stretchr/testify/mock"
+)
+
+// Example SNS API return values
+var (
+	ExampleSnsTopicArn = aws.String("arn:aws:sns:us-west-2:123456789012:example-topic")
+
+	ExampleListTopicsOutput = &sns.ListTopicsOutput{
+		Topics: []*sns.Topic{
+			{
+				TopicArn: ExampleSnsTopicArn,
+			},
+		},
+	}
+
+	ExampleGetTopicAttributesOutput = &sns.GetTopicAttributesOutput{
+		Attributes: map[string]*string{
+			"DisplayName":             aws.String("Example"),
+			"EffectiveDeliveryPolicy": aws.String("{\"http\":{\"defaultHealthyRetryPolicy\":{\"numRetries\":3}}}"),
+			"KmsMasterKeyId":          aws.String("alias/aws/sns"),
+			"Owner":                   aws.String("123456789012"),
+			"Policy":                  aws.String("{\"Version\":\"2008-10-17\",\"Statement\":[]}"),
+			"SubscriptionsConfirmed":  aws.String("1"),
+			"SubscriptionsDeleted":    aws.String("0"),
+			"SubscriptionsPending":    aws.String("0"),
+			"TopicArn":                ExampleSnsTopicArn,
+		},
+	}
+
+	ExampleListSubscriptionsByTopicOutput = &sns.ListSubscriptionsByTopicOutput{
+		Subscriptions: []*sns.Subscription{
+			{
+				Endpoint:        aws.String("example@example.com"),
+				Owner:           aws.String("123456789012"),
+				Protocol:        aws.String("email"),
+				SubscriptionArn: aws.String("arn:aws:sns:us-west-2:123456789012:example-topic:1111"),
+				TopicArn:        ExampleSnsTopicArn,
+			},
+		},
+	}
+
+	ExampleSnsListTagsForResourceOutput = &sns.ListTagsForResourceOutput{
+		Tags: []*sns.Tag{
+			{
+				Key:   aws.String("Key1"),
+				Value: aws.String("Value1"),
+			},
+		},
+	}
+
+	svcSnsSetupCalls = map[string]func(*MockSns){
+		"ListTopicsPages": func(svc *MockSns) {
+			svc.On("ListTopicsPages", mock.Anything).
+				Return(nil)
+		},
+		"GetTopicAttributes": func(svc *MockSns) {
+			svc.On("GetTopicAttributes", mock.Anything).
+				Return(ExampleGetTopicAttributesOutput, nil)
+		},
+		"ListSubscriptionsByTopicPages": func(svc *MockSns) {
+			svc.On("ListSubscriptionsByTopicPages", mock.Anything).
+				Return(nil)
+		},
+		"ListTagsForResource": func(svc *MockSns) {
+			svc.On("ListTagsForResource", mock.Anything).
+				Return(ExampleSnsListTagsForResourceOutput, nil)
+		},
+	}
+
+	svcSnsSetupCallsError = map[string]func(*MockSns){
+		"ListTopicsPages": func(svc *MockSns) {
+			svc.On("ListTopicsPages", mock.Anything).
+				Return(errors.New("SNS.ListTopicsPages error"))
+		},
+		"GetTopicAttributes": func(svc *MockSns) {
+			svc.On("GetTopicAttributes", mock.Anything).
+				Return(&sns.GetTopicAttributesOutput{},
+					errors.New("SNS.GetTopicAttributes error"),
+				)
+		},
+		"ListSubscriptionsByTopicPages": func(svc *MockSns) {
+			svc.On("ListSubscriptionsByTopicPages", mock.Anything).
+				Return(errors.New("SNS.ListSubscriptionsByTopicPages error"))
+		},
+		"ListTagsForResource": func(svc *MockSns) {
+			svc.On("ListTagsForResource", mock.Anything).
+				Return(&sns.ListTagsForResourceOutput{},
+					errors.New("SNS.ListTagsForResource error"),
+				)
+		},
+	}
+
+	MockSnsForSetup = &MockSns{}
+)
+
+// SNS mock
+
+// SetupMockSns is used to override the SNS Client initializer
+func SetupMockSns(_ *session.Session, _ *aws.Config) interface{} {
+	return MockSnsForSetup
+}
+
+// MockSns is a mock SNS client
+type MockSns struct {
+	snsiface.SNSAPI
+	mock.Mock
+}
+
+// BuildMockSnsSvc builds and returns a MockSns struct
+//
+// Additionally, the appropriate calls to On and Return are made based on the strings passed in
+func BuildMockSnsSvc(funcs []string) (mockSvc *MockSns) {
+	mockSvc = &MockSns{}
+	for _, f := range funcs {
+		svcSnsSetupCalls[f](mockSvc)
+	}
+	return
+}
+
+// BuildMockSnsSvcError builds and returns a MockSns struct with errors set
+//
+// Additionally, the appropriate calls to On and Return are made based on the strings passed in
+func BuildMockSnsSvcError(funcs []string) (mockSvc *MockSns) {
+	mockSvc = &MockSns{}
+	for _, f := range funcs {
+		svcSnsSetupCallsError[f](mockSvc)
+	}
+	return
+}
+
+// BuildMockSnsSvcAll builds and returns a MockSns struct
+//
+// Additionally, the appropriate calls to On and Return are made for all possible function calls
+func BuildMockSnsSvcAll() (mockSvc *MockSns) {
+	mockSvc = &MockSns{}
+	for _, f := range svcSnsSetupCalls {
+		f(mockSvc)
+	}
+	return
+}
+
+// BuildMockSnsSvcAllError builds and returns a MockSns struct with errors set
+//
+// Additionally, the appropriate calls to On and Return are made for all possible function calls
+func BuildMockSnsSvcAllError() (mockSvc *MockSns) {
+	mockSvc = &MockSns{}
+	for _, f := range svcSnsSetupCallsError {
+		f(mockSvc)
+	}
+	return
+}
+
+func (m *MockSns) ListTopicsPages(
+	in *sns.ListTopicsInput,
+	paginationFunction func(*sns.ListTopicsOutput, bool) bool,
+) error {
+
+	args := m.Called(in)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	paginationFunction(ExampleListTopicsOutput, true)
+	return args.Error(0)
+}
+
+func (m *MockSns) ListSubscriptionsByTopicPages(
+	in *sns.ListSubscriptionsByTopicInput,
+	paginationFunction func(*sns.ListSubscriptionsByTopicOutput, bool) bool,
+) error {
+
+	args := m.Called(in)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	paginationFunction(ExampleListSubscriptionsByTopicOutput, true)
+	return args.Error(0)
+}
+
+func (m *MockSns) GetTopicAttributes(in *sns.GetTopicAttributesInput) (*sns.GetTopicAttributesOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*sns.GetTopicAttributesOutput), args.Error(1)
+}
+
+func (m *MockSns) ListTagsForResource(in *sns.ListTagsForResourceInput) (*sns.ListTagsForResourceOutput, error) {
+	args := m.Called(in)
+	return args.Get(0).(*sns.ListTagsForResourceOutput), args.Error(1)
+}
@@ -53,7 +53,9 @@ var clientFuncs = map[string]func(session2 *session.Session, config *aws.Config)
 	"configservice":          ConfigServiceClientFunc,
 	"dynamodb":               DynamoDBClientFunc,
 	"ec2":                    EC2ClientFunc,
+	"ecr":                    EcrClientFunc,
 	"ecs":                    EcsClientFunc,
+	"eks":                    EksClientFunc,
 	"elbv2":                  Elbv2ClientFunc,
 	"guardduty":              GuardDutyClientFunc,
 	"iam":                    IAMClientFunc,
@@ -61,7 +63,9 @@ var clientFuncs = map[string]func(session2 *session.Session, config *aws.Config)
 	"lambda":                 LambdaClientFunc,
 	"rds":                    RDSClientFunc,
 	"redshift":               RedshiftClientFunc,
+	"route53":                Route53ClientFunc,
 	"s3":                     S3ClientFunc,
+	"sns":                    SnsClientFunc,
 	"waf":                    WafClientFunc,
 	"waf-regional":           WafRegionalClientFunc,
 }
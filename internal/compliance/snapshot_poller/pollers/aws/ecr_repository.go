@@ -0,0 +1,305 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
+)
+
+// Set as variables to be overridden in testing
+var EcrClientFunc = setupEcrClient
+
+func setupEcrClient(sess *session.Session, cfg *aws.Config) interface{} {
+	cfg.MaxRetries = aws.Int(MaxRetries)
+	return ecr.New(sess, cfg)
+}
+
+// PollECRRepository polls a single ECR repository resource
+func PollECRRepository(
+	pollerInput *awsmodels.ResourcePollerInput,
+	resourceARN arn.ARN,
+	scanRequest *pollermodels.ScanEntry,
+) interface{} {
+
+	client := getClient(pollerInput, "ecr", resourceARN.Region).(ecriface.ECRAPI)
+	// The resource of a repository ARN is repository/<name>, the name may contain slashes
+	repositoryName := strings.TrimPrefix(resourceARN.Resource, "repository/")
+
+	repository := getRepository(client, aws.String(repositoryName))
+	snapshot := buildEcrRepositorySnapshot(client, repository)
+	if snapshot == nil {
+		return nil
+	}
+	snapshot.Region = aws.String(resourceARN.Region)
+	snapshot.AccountID = aws.String(resourceARN.AccountID)
+
+	return snapshot
+}
+
+// listRepositories returns all ECR repositories in the account
+func listRepositories(ecrSvc ecriface.ECRAPI) (repositories []*ecr.Repository) {
+	err := ecrSvc.DescribeRepositoriesPages(&ecr.DescribeRepositoriesInput{},
+		func(page *ecr.DescribeRepositoriesOutput, lastPage bool) bool {
+			repositories = append(repositories, page.Repositories...)
+			return true
+		})
+	if err != nil {
+		utils.LogAWSError("ECR.DescribeRepositoriesPages", err)
+	}
+	return
+}
+
+// getRepository returns a specific ECR repository
+func getRepository(ecrSvc ecriface.ECRAPI, name *string) *ecr.Repository {
+	out, err := ecrSvc.DescribeRepositories(&ecr.DescribeRepositoriesInput{
+		RepositoryNames: []*string{name},
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecr.ErrCodeRepositoryNotFoundException {
+			zap.L().Warn(
+				"tried to scan non-existent resource",
+				zap.String("resourceType", awsmodels.EcrRepositorySchema),
+				zap.String("resourceId", *name),
+			)
+			return nil
+		}
+		utils.LogAWSError("ECR.DescribeRepositories", err)
+		return nil
+	}
+
+	if len(out.Repositories) == 0 {
+		return nil
+	}
+	return out.Repositories[0]
+}
+
+// getRepositoryPolicy returns the resource policy of an ECR repository, if it has one
+func getRepositoryPolicy(ecrSvc ecriface.ECRAPI, name *string) (*string, error) {
+	out, err := ecrSvc.GetRepositoryPolicy(&ecr.GetRepositoryPolicyInput{RepositoryName: name})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecr.ErrCodeRepositoryPolicyNotFoundException {
+			return nil, nil
+		}
+		utils.LogAWSError("ECR.GetRepositoryPolicy", err)
+		return nil, err
+	}
+	return out.PolicyText, nil
+}
+
+// getLifecyclePolicy returns the lifecycle policy of an ECR repository, if it has one
+func getLifecyclePolicy(ecrSvc ecriface.ECRAPI, name *string) (*string, error) {
+	out, err := ecrSvc.GetLifecyclePolicy(&ecr.GetLifecyclePolicyInput{RepositoryName: name})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecr.ErrCodeLifecyclePolicyNotFoundException {
+			return nil, nil
+		}
+		utils.LogAWSError("ECR.GetLifecyclePolicy", err)
+		return nil, err
+	}
+	return out.LifecyclePolicyText, nil
+}
+
+// listEcrTags returns the tags of an ECR repository
+func listEcrTags(ecrSvc ecriface.ECRAPI, arn *string) ([]*ecr.Tag, error) {
+	out, err := ecrSvc.ListTagsForResource(&ecr.ListTagsForResourceInput{ResourceArn: arn})
+	if err != nil {
+		utils.LogAWSError("ECR.ListTagsForResource", err)
+		return nil, err
+	}
+	return out.Tags, nil
+}
+
+// listImages returns the details of all images of an ECR repository
+func listImages(ecrSvc ecriface.ECRAPI, name *string) (images []*ecr.ImageDetail, err error) {
+	err = ecrSvc.DescribeImagesPages(&ecr.DescribeImagesInput{RepositoryName: name},
+		func(page *ecr.DescribeImagesOutput, lastPage bool) bool {
+			images = append(images, page.ImageDetails...)
+			return true
+		})
+	if err != nil {
+		utils.LogAWSError("ECR.DescribeImagesPages", err)
+		return nil, err
+	}
+	return images, nil
+}
+
+// getImageScanFindings returns the findings of the last scan of an image, if it was scanned
+func getImageScanFindings(ecrSvc ecriface.ECRAPI, name *string, image *ecr.ImageDetail) (*ecr.ImageScanFindings, error) {
+	out, err := ecrSvc.DescribeImageScanFindings(&ecr.DescribeImageScanFindingsInput{
+		ImageId:        &ecr.ImageIdentifier{ImageDigest: image.ImageDigest},
+		RepositoryName: name,
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecr.ErrCodeScanNotFoundException {
+			return nil, nil
+		}
+		utils.LogAWSError("ECR.DescribeImageScanFindings", err)
+		return nil, err
+	}
+	return out.ImageScanFindings, nil
+}
+
+// latestImage returns the most recently pushed image, nil if there are none
+func latestImage(images []*ecr.ImageDetail) (latest *ecr.ImageDetail) {
+	for _, image := range images {
+		if latest == nil || aws.TimeValue(image.ImagePushedAt).After(aws.TimeValue(latest.ImagePushedAt)) {
+			latest = image
+		}
+	}
+	return
+}
+
+// buildEcrRepositorySnapshot returns a complete snapshot of an ECR repository
+func buildEcrRepositorySnapshot(ecrSvc ecriface.ECRAPI, repository *ecr.Repository) *awsmodels.EcrRepository {
+	if repository == nil {
+		return nil
+	}
+
+	ecrRepository := &awsmodels.EcrRepository{
+		GenericAWSResource: awsmodels.GenericAWSResource{
+			ARN:  repository.RepositoryArn,
+			Name: repository.RepositoryName,
+		},
+		GenericResource: awsmodels.GenericResource{
+			ResourceID:   repository.RepositoryArn,
+			ResourceType: aws.String(awsmodels.EcrRepositorySchema),
+			TimeCreated:  utils.DateTimeFormat(aws.TimeValue(repository.CreatedAt)),
+		},
+		ImageScanningConfiguration: repository.ImageScanningConfiguration,
+		ImageTagMutability:         repository.ImageTagMutability,
+		RegistryId:                 repository.RegistryId,
+		RepositoryUri:              repository.RepositoryUri,
+	}
+
+	tags, err := listEcrTags(ecrSvc, repository.RepositoryArn)
+	if err != nil {
+		return nil
+	}
+	ecrRepository.Tags = utils.ParseTagSlice(tags)
+
+	ecrRepository.Policy, err = getRepositoryPolicy(ecrSvc, repository.RepositoryName)
+	if err != nil {
+		return nil
+	}
+
+	ecrRepository.LifecyclePolicy, err = getLifecyclePolicy(ecrSvc, repository.RepositoryName)
+	if err != nil {
+		return nil
+	}
+
+	ecrRepository.Images, err = listImages(ecrSvc, repository.RepositoryName)
+	if err != nil {
+		return nil
+	}
+
+	// Only the findings of the image most recently pushed are kept, the older images are usually not deployed
+	if image := latestImage(ecrRepository.Images); image != nil {
+		ecrRepository.ImageScanFindings, err = getImageScanFindings(ecrSvc, repository.RepositoryName, image)
+		if err != nil {
+			return nil
+		}
+	}
+
+	return ecrRepository
+}
+
+// PollEcrRepositories gathers information on each ECR Repository for an AWS account.
+func PollEcrRepositories(pollerInput *awsmodels.ResourcePollerInput) ([]*apimodels.AddResourceEntry, error) {
+	zap.L().Debug("starting ECR Repository resource poller")
+	ecrRepositorySnapshots := make(map[string]*awsmodels.EcrRepository)
+
+	for _, regionID := range utils.GetServiceRegions(pollerInput.Regions, "api.ecr") {
+		sess, err := session.NewSession(&aws.Config{Region: regionID})
+		if err != nil {
+			// The session failed to create, log an error and continue to the next region
+			zap.L().Error(
+				"unable to create aws session",
+				zap.String("region", *regionID),
+				zap.String("service", "ecr"),
+				zap.Error(errors.WithStack(err)),
+			)
+			continue
+		}
+
+		creds, err := AssumeRoleFunc(pollerInput, sess)
+		if err != nil {
+			// The client failed to create, log an error and continue to the next region
+			zap.L().Error(
+				"unable to create aws client",
+				zap.String("region", *regionID),
+				zap.String("service", "ecr"),
+				zap.Error(errors.WithStack(err)),
+			)
+			continue
+		}
+
+		ecrSvc := EcrClientFunc(sess, &aws.Config{Credentials: creds}).(ecriface.ECRAPI)
+
+		// Start with generating a list of all repositories
+		repositories := listRepositories(ecrSvc)
+		if len(repositories) == 0 {
+			zap.L().Debug("no ECR repositories found", zap.String("region", *regionID))
+			continue
+		}
+
+		for _, repository := range repositories {
+			ecrRepositorySnapshot := buildEcrRepositorySnapshot(ecrSvc, repository)
+			if ecrRepositorySnapshot == nil {
+				continue
+			}
+			ecrRepositorySnapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+			ecrRepositorySnapshot.Region = regionID
+
+			if _, ok := ecrRepositorySnapshots[*ecrRepositorySnapshot.ARN]; ok {
+				zap.L().Info(
+					"overwriting existing ECR Repository snapshot",
+					zap.String("resourceId", *ecrRepositorySnapshot.ARN),
+				)
+			}
+			ecrRepositorySnapshots[*ecrRepositorySnapshot.ARN] = ecrRepositorySnapshot
+		}
+	}
+
+	resources := make([]*apimodels.AddResourceEntry, 0, len(ecrRepositorySnapshots))
+	for resourceID, ecrSnapshot := range ecrRepositorySnapshots {
+		resources = append(resources, &apimodels.AddResourceEntry{
+			Attributes:      ecrSnapshot,
+			ID:              apimodels.ResourceID(resourceID),
+			IntegrationID:   apimodels.IntegrationID(*pollerInput.IntegrationID),
+			IntegrationType: apimodels.IntegrationTypeAws,
+			Type:            awsmodels.EcrRepositorySchema,
+		})
+	}
+
+	return resources, nil
+}
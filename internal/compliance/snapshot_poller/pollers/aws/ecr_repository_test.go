@@ -0,0 +1,164 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws/awstest"
+)
+
+func TestEcrRepositoryList(t *testing.T) {
+	mockSvc := awstest.BuildMockEcrSvc([]string{"DescribeRepositoriesPages"})
+
+	out := listRepositories(mockSvc)
+	assert.NotEmpty(t, out)
+}
+
+func TestEcrRepositoryListError(t *testing.T) {
+	mockSvc := awstest.BuildMockEcrSvcError([]string{"DescribeRepositoriesPages"})
+
+	out := listRepositories(mockSvc)
+	assert.Nil(t, out)
+}
+
+func TestEcrRepositoryGet(t *testing.T) {
+	mockSvc := awstest.BuildMockEcrSvc([]string{"DescribeRepositories"})
+
+	out := getRepository(mockSvc, awstest.ExampleEcrRepositoryName)
+	assert.Equal(t, awstest.ExampleEcrRepository, out)
+}
+
+func TestEcrRepositoryGetDoesNotExist(t *testing.T) {
+	mockSvc := &awstest.MockEcr{}
+	mockSvc.On("DescribeRepositories", mock.Anything).
+		Return(
+			&ecr.DescribeRepositoriesOutput{},
+			awserr.New(ecr.ErrCodeRepositoryNotFoundException, "The repository does not exist", nil),
+		)
+
+	out := getRepository(mockSvc, awstest.ExampleEcrRepositoryName)
+	assert.Nil(t, out)
+}
+
+func TestEcrRepositoryPolicyDoesNotExist(t *testing.T) {
+	mockSvc := &awstest.MockEcr{}
+	mockSvc.On("GetRepositoryPolicy", mock.Anything).
+		Return(
+			&ecr.GetRepositoryPolicyOutput{},
+			awserr.New(ecr.ErrCodeRepositoryPolicyNotFoundException, "Repository policy does not exist", nil),
+		)
+
+	out, err := getRepositoryPolicy(mockSvc, awstest.ExampleEcrRepositoryName)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+}
+
+func TestEcrRepositoryLatestImage(t *testing.T) {
+	image := latestImage(awstest.ExampleEcrDescribeImagesOutput.ImageDetails)
+	require.NotNil(t, image)
+	assert.Equal(t, "sha256:2222", *image.ImageDigest)
+	assert.Nil(t, latestImage(nil))
+}
+
+func TestEcrRepositoryBuildSnapshot(t *testing.T) {
+	mockSvc := awstest.BuildMockEcrSvcAll()
+
+	repositorySnapshot := buildEcrRepositorySnapshot(mockSvc, awstest.ExampleEcrRepository)
+
+	require.NotNil(t, repositorySnapshot)
+	assert.Equal(t, *awstest.ExampleEcrRepositoryArn, *repositorySnapshot.ResourceID)
+	assert.Equal(t, "MUTABLE", *repositorySnapshot.ImageTagMutability)
+	assert.Equal(t, "Value1", *repositorySnapshot.Tags["Key1"])
+	assert.NotNil(t, repositorySnapshot.Policy)
+	assert.Nil(t, repositorySnapshot.LifecyclePolicy)
+	assert.Len(t, repositorySnapshot.Images, 2)
+	assert.Equal(t, int64(1), *repositorySnapshot.ImageScanFindings.FindingSeverityCounts["HIGH"])
+	mockSvc.AssertCalled(t, "DescribeImageScanFindings", &ecr.DescribeImageScanFindingsInput{
+		ImageId:        &ecr.ImageIdentifier{ImageDigest: awstest.ExampleEcrDescribeImagesOutput.ImageDetails[1].ImageDigest},
+		RepositoryName: awstest.ExampleEcrRepositoryName,
+	})
+}
+
+func TestEcrRepositoryBuildSnapshotNotScanned(t *testing.T) {
+	mockSvc := awstest.BuildMockEcrSvc([]string{
+		"DescribeImagesPages", "GetLifecyclePolicy", "GetRepositoryPolicy", "ListTagsForResource"})
+	mockSvc.On("DescribeImageScanFindings", mock.Anything).
+		Return(
+			&ecr.DescribeImageScanFindingsOutput{},
+			awserr.New(ecr.ErrCodeScanNotFoundException, "Image scan does not exist", nil),
+		)
+
+	repositorySnapshot := buildEcrRepositorySnapshot(mockSvc, awstest.ExampleEcrRepository)
+
+	require.NotNil(t, repositorySnapshot)
+	assert.Nil(t, repositorySnapshot.ImageScanFindings)
+}
+
+func TestEcrRepositoryBuildSnapshotErrors(t *testing.T) {
+	mockSvc := awstest.BuildMockEcrSvcAllError()
+
+	repositorySnapshot := buildEcrRepositorySnapshot(mockSvc, awstest.ExampleEcrRepository)
+
+	assert.Nil(t, repositorySnapshot)
+}
+
+func TestEcrRepositoryPoller(t *testing.T) {
+	awstest.MockEcrForSetup = awstest.BuildMockEcrSvcAll()
+
+	AssumeRoleFunc = awstest.AssumeRoleMock
+	EcrClientFunc = awstest.SetupMockEcr
+
+	resources, err := PollEcrRepositories(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, *awstest.ExampleEcrRepositoryArn, string(resources[0].ID))
+}
+
+func TestEcrRepositoryPollerError(t *testing.T) {
+	awstest.MockEcrForSetup = awstest.BuildMockEcrSvcAllError()
+
+	AssumeRoleFunc = awstest.AssumeRoleMock
+	EcrClientFunc = awstest.SetupMockEcr
+
+	resources, err := PollEcrRepositories(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
@@ -0,0 +1,265 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
+)
+
+// Set as variables to be overridden in testing
+var EksClientFunc = setupEksClient
+
+func setupEksClient(sess *session.Session, cfg *aws.Config) interface{} {
+	cfg.MaxRetries = aws.Int(MaxRetries)
+	return eks.New(sess, cfg)
+}
+
+// PollEKSCluster polls a single EKS cluster resource
+func PollEKSCluster(
+	pollerInput *awsmodels.ResourcePollerInput,
+	resourceARN arn.ARN,
+	scanRequest *pollermodels.ScanEntry,
+) interface{} {
+
+	client := getClient(pollerInput, "eks", resourceARN.Region).(eksiface.EKSAPI)
+	// The resource of a cluster ARN is cluster/<name>
+	clusterName := strings.TrimPrefix(resourceARN.Resource, "cluster/")
+
+	snapshot := buildEksClusterSnapshot(client, aws.String(clusterName))
+	if snapshot == nil {
+		return nil
+	}
+	snapshot.Region = aws.String(resourceARN.Region)
+	snapshot.AccountID = aws.String(resourceARN.AccountID)
+
+	return snapshot
+}
+
+// listEksClusters returns the names of all EKS clusters in the account
+func listEksClusters(eksSvc eksiface.EKSAPI) (clusters []*string) {
+	err := eksSvc.ListClustersPages(&eks.ListClustersInput{},
+		func(page *eks.ListClustersOutput, lastPage bool) bool {
+			clusters = append(clusters, page.Clusters...)
+			return true
+		})
+	if err != nil {
+		utils.LogAWSError("EKS.ListClustersPages", err)
+	}
+	return
+}
+
+// describeEksCluster provides detailed information for a given EKS cluster
+func describeEksCluster(eksSvc eksiface.EKSAPI, name *string) (*eks.Cluster, error) {
+	out, err := eksSvc.DescribeCluster(&eks.DescribeClusterInput{Name: name})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == eks.ErrCodeResourceNotFoundException {
+			zap.L().Warn(
+				"tried to scan non-existent resource",
+				zap.String("resourceType", awsmodels.EksClusterSchema),
+				zap.String("resourceId", *name),
+			)
+			return nil, nil
+		}
+		utils.LogAWSError("EKS.DescribeCluster", err)
+		return nil, err
+	}
+
+	return out.Cluster, nil
+}
+
+// getClusterNodegroups enumerates and then describes all managed node groups of a cluster
+func getClusterNodegroups(eksSvc eksiface.EKSAPI, clusterName *string) ([]*awsmodels.EksNodegroup, error) {
+	// Enumerate node groups
+	var nodegroupNames []*string
+	err := eksSvc.ListNodegroupsPages(&eks.ListNodegroupsInput{ClusterName: clusterName},
+		func(page *eks.ListNodegroupsOutput, lastPage bool) bool {
+			nodegroupNames = append(nodegroupNames, page.Nodegroups...)
+			return true
+		})
+	if err != nil {
+		utils.LogAWSError("EKS.ListNodegroupsPages", err)
+		return nil, err
+	}
+
+	// Describe node groups, the API only describes one at a time
+	nodegroups := make([]*awsmodels.EksNodegroup, 0, len(nodegroupNames))
+	for _, nodegroupName := range nodegroupNames {
+		out, err := eksSvc.DescribeNodegroup(&eks.DescribeNodegroupInput{
+			ClusterName:   clusterName,
+			NodegroupName: nodegroupName,
+		})
+		if err != nil {
+			utils.LogAWSError("EKS.DescribeNodegroup", err)
+			return nil, err
+		}
+
+		nodegroup := out.Nodegroup
+		nodegroups = append(nodegroups, &awsmodels.EksNodegroup{
+			GenericAWSResource: awsmodels.GenericAWSResource{
+				ARN:  nodegroup.NodegroupArn,
+				Name: nodegroup.NodegroupName,
+				Tags: nodegroup.Tags,
+			},
+			AmiType:        nodegroup.AmiType,
+			TimeCreated:    utils.DateTimeFormat(aws.TimeValue(nodegroup.CreatedAt)),
+			DiskSize:       nodegroup.DiskSize,
+			Health:         nodegroup.Health,
+			InstanceTypes:  nodegroup.InstanceTypes,
+			Labels:         nodegroup.Labels,
+			NodeRole:       nodegroup.NodeRole,
+			ReleaseVersion: nodegroup.ReleaseVersion,
+			RemoteAccess:   nodegroup.RemoteAccess,
+			Resources:      nodegroup.Resources,
+			ScalingConfig:  nodegroup.ScalingConfig,
+			Status:         nodegroup.Status,
+			Subnets:        nodegroup.Subnets,
+			Version:        nodegroup.Version,
+		})
+	}
+
+	return nodegroups, nil
+}
+
+// buildEksClusterSnapshot returns a complete snapshot of an EKS cluster
+func buildEksClusterSnapshot(eksSvc eksiface.EKSAPI, clusterName *string) *awsmodels.EksCluster {
+	if clusterName == nil {
+		return nil
+	}
+
+	details, err := describeEksCluster(eksSvc, clusterName)
+	if err != nil || details == nil {
+		return nil
+	}
+
+	eksCluster := &awsmodels.EksCluster{
+		GenericAWSResource: awsmodels.GenericAWSResource{
+			ARN:  details.Arn,
+			Name: details.Name,
+			Tags: details.Tags,
+		},
+		GenericResource: awsmodels.GenericResource{
+			ResourceID:   details.Arn,
+			ResourceType: aws.String(awsmodels.EksClusterSchema),
+			TimeCreated:  utils.DateTimeFormat(aws.TimeValue(details.CreatedAt)),
+		},
+		CertificateAuthority: details.CertificateAuthority,
+		EncryptionConfig:     details.EncryptionConfig,
+		Endpoint:             details.Endpoint,
+		Identity:             details.Identity,
+		Logging:              details.Logging,
+		PlatformVersion:      details.PlatformVersion,
+		ResourcesVpcConfig:   details.ResourcesVpcConfig,
+		RoleArn:              details.RoleArn,
+		Status:               details.Status,
+		Version:              details.Version,
+	}
+
+	eksCluster.Nodegroups, err = getClusterNodegroups(eksSvc, details.Name)
+	if err != nil {
+		return nil
+	}
+
+	return eksCluster
+}
+
+// PollEksClusters gathers information on each EKS Cluster for an AWS account.
+func PollEksClusters(pollerInput *awsmodels.ResourcePollerInput) ([]*apimodels.AddResourceEntry, error) {
+	zap.L().Debug("starting EKS Cluster resource poller")
+	eksClusterSnapshots := make(map[string]*awsmodels.EksCluster)
+
+	// The endpoints of the SDK have no regions for EKS, all the active regions are polled
+	for _, regionID := range pollerInput.Regions {
+		sess, err := session.NewSession(&aws.Config{Region: regionID})
+		if err != nil {
+			// The session failed to create, log an error and continue to the next region
+			zap.L().Error(
+				"unable to create aws session",
+				zap.String("region", *regionID),
+				zap.String("service", "eks"),
+				zap.Error(errors.WithStack(err)),
+			)
+			continue
+		}
+
+		creds, err := AssumeRoleFunc(pollerInput, sess)
+		if err != nil {
+			// The client failed to create, log an error and continue to the next region
+			zap.L().Error(
+				"unable to create aws client",
+				zap.String("region", *regionID),
+				zap.String("service", "eks"),
+				zap.Error(errors.WithStack(err)),
+			)
+			continue
+		}
+
+		eksSvc := EksClientFunc(sess, &aws.Config{Credentials: creds}).(eksiface.EKSAPI)
+
+		// Start with generating a list of all clusters
+		clusters := listEksClusters(eksSvc)
+		if len(clusters) == 0 {
+			zap.L().Debug("no EKS clusters found", zap.String("region", *regionID))
+			continue
+		}
+
+		for _, clusterName := range clusters {
+			eksClusterSnapshot := buildEksClusterSnapshot(eksSvc, clusterName)
+			if eksClusterSnapshot == nil {
+				continue
+			}
+			eksClusterSnapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+			eksClusterSnapshot.Region = regionID
+
+			if _, ok := eksClusterSnapshots[*eksClusterSnapshot.ARN]; ok {
+				zap.L().Info(
+					"overwriting existing EKS Cluster snapshot",
+					zap.String("resourceId", *eksClusterSnapshot.ARN),
+				)
+			}
+			eksClusterSnapshots[*eksClusterSnapshot.ARN] = eksClusterSnapshot
+		}
+	}
+
+	resources := make([]*apimodels.AddResourceEntry, 0, len(eksClusterSnapshots))
+	for resourceID, eksSnapshot := range eksClusterSnapshots {
+		resources = append(resources, &apimodels.AddResourceEntry{
+			Attributes:      eksSnapshot,
+			ID:              apimodels.ResourceID(resourceID),
+			IntegrationID:   apimodels.IntegrationID(*pollerInput.IntegrationID),
+			IntegrationType: apimodels.IntegrationTypeAws,
+			Type:            awsmodels.EksClusterSchema,
+		})
+	}
+
+	return resources, nil
+}
@@ -88,7 +88,9 @@ var (
 		awsmodels.Ec2SecurityGroupSchema:    PollEC2SecurityGroup,
 		awsmodels.Ec2VolumeSchema:           PollEC2Volume,
 		awsmodels.Ec2VpcSchema:              PollEC2VPC,
+		awsmodels.EcrRepositorySchema:       PollECRRepository,
 		awsmodels.EcsClusterSchema:          PollECSCluster,
+		awsmodels.EksClusterSchema:          PollEKSCluster,
 		awsmodels.Elbv2LoadBalancerSchema:   PollELBV2LoadBalancer,
 		awsmodels.IAMGroupSchema:            PollIAMGroup,
 		awsmodels.IAMPolicySchema:           PollIAMPolicy,
@@ -99,7 +101,9 @@ var (
 		awsmodels.LambdaFunctionSchema:      PollLambdaFunction,
 		awsmodels.RDSInstanceSchema:         PollRDSInstance,
 		awsmodels.RedshiftClusterSchema:     PollRedshiftCluster,
+		awsmodels.Route53HostedZoneSchema:   PollRoute53HostedZone,
 		awsmodels.S3BucketSchema:            PollS3Bucket,
+		awsmodels.SnsTopicSchema:            PollSNSTopic,
 		awsmodels.WafWebAclSchema:           PollWAFWebACL,
 		awsmodels.WafRegionalWebAclSchema:   PollWAFRegionalWebACL,
 	}
@@ -123,10 +127,14 @@ var (
 		awsmodels.Ec2SecurityGroupSchema:    {"EC2SecurityGroup", PollEc2SecurityGroups},
 		awsmodels.Ec2VolumeSchema:           {"EC2Volume", PollEc2Volumes},
 		awsmodels.Ec2VpcSchema:              {"EC2VPC", PollEc2Vpcs},
+		awsmodels.EcrRepositorySchema:       {"ECRRepository", PollEcrRepositories},
 		awsmodels.EcsClusterSchema:          {"ECSCluster", PollEcsClusters},
+		awsmodels.EksClusterSchema:          {"EKSCluster", PollEksClusters},
 		awsmodels.Elbv2LoadBalancerSchema:   {"ELBV2LoadBalancer", PollElbv2ApplicationLoadBalancers},
 		awsmodels.KmsKeySchema:              {"KMSKey", PollKmsKeys},
+		awsmodels.Route53HostedZoneSchema:   {"Route53HostedZone", PollRoute53HostedZones},
 		awsmodels.S3BucketSchema:            {"S3Bucket", PollS3Buckets},
+		awsmodels.SnsTopicSchema:            {"SNSTopic", PollSnsTopics},
 		awsmodels.WafWebAclSchema:           {"WAFWebAcl", PollWafWebAcls},
 		awsmodels.WafRegionalWebAclSchema:   {"WAFRegionalWebAcl", PollWafRegionalWebAcls},
 		awsmodels.CloudFormationStackSchema: {"CloudFormationStack", PollCloudFormationStacks},
@@ -261,6 +269,11 @@ func Poll(scanRequest *pollermodels.ScanEntry) (
 
 		// Single region service scan
 	} else if scanRequest.Region != nil && scanRequest.ResourceType != nil {
+		if !utils.InScope(*scanRequest.Region, scanRequest.EnabledRegions, scanRequest.ExcludedRegions) {
+			zap.L().Info("skipping single region service scan outside the regions of the integration",
+				zap.String("region", *scanRequest.Region))
+			return nil, nil
+		}
 		zap.L().Info("processing single region service scan")
 		if poller, ok := ServicePollers[*scanRequest.ResourceType]; ok {
 			return serviceScan(
@@ -285,6 +298,7 @@ func Poll(scanRequest *pollermodels.ScanEntry) (
 	regions := utils.GetRegions(
 		EC2ClientFunc(sess, &aws.Config{Credentials: creds}).(ec2iface.EC2API),
 	)
+	regions = utils.ScopeRegions(regions, scanRequest.EnabledRegions, scanRequest.ExcludedRegions)
 	if regions == nil {
 		zap.L().Info("no valid regions to scan")
 		return
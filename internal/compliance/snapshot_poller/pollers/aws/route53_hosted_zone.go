@@ -0,0 +1,221 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"go.uber.org/zap"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
+)
+
+const (
+	// The ID of a hosted zone is returned as /hostedzone/<ID>, its ARN is arn:aws:route53:::hostedzone/<ID>
+	hostedZoneIDPrefix  = "/hostedzone/"
+	hostedZoneARNPrefix = "arn:aws:route53:::hostedzone/"
+)
+
+// Set as variables to be overridden in testing
+var Route53ClientFunc = setupRoute53Client
+
+func setupRoute53Client(sess *session.Session, cfg *aws.Config) interface{} {
+	cfg.MaxRetries = aws.Int(MaxRetries)
+	return route53.New(sess, cfg)
+}
+
+// PollRoute53HostedZone polls a single Route53 hosted zone resource
+func PollRoute53HostedZone(
+	pollerInput *awsmodels.ResourcePollerInput,
+	resourceARN arn.ARN,
+	scanRequest *pollermodels.ScanEntry,
+) interface{} {
+
+	client := getClient(pollerInput, "route53", defaultRegion).(route53iface.Route53API)
+	hostedZoneID := strings.TrimPrefix(resourceARN.Resource, "hostedzone/")
+
+	snapshot := buildRoute53HostedZoneSnapshot(client, aws.String(hostedZoneID))
+	if snapshot == nil {
+		return nil
+	}
+	// Hosted zone ARNs have no account ID
+	snapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+
+	return snapshot
+}
+
+// listHostedZones returns all Route53 hosted zones in the account
+func listHostedZones(route53Svc route53iface.Route53API) (hostedZones []*route53.HostedZone) {
+	err := route53Svc.ListHostedZonesPages(&route53.ListHostedZonesInput{},
+		func(page *route53.ListHostedZonesOutput, lastPage bool) bool {
+			hostedZones = append(hostedZones, page.HostedZones...)
+			return true
+		})
+	if err != nil {
+		utils.LogAWSError("Route53.ListHostedZonesPages", err)
+	}
+	return
+}
+
+// getHostedZone provides detailed information for a given Route53 hosted zone
+func getHostedZone(route53Svc route53iface.Route53API, id *string) (*route53.GetHostedZoneOutput, error) {
+	out, err := route53Svc.GetHostedZone(&route53.GetHostedZoneInput{Id: id})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == route53.ErrCodeNoSuchHostedZone {
+			zap.L().Warn(
+				"tried to scan non-existent resource",
+				zap.String("resourceType", awsmodels.Route53HostedZoneSchema),
+				zap.String("resourceId", *id),
+			)
+			return nil, nil
+		}
+		utils.LogAWSError("Route53.GetHostedZone", err)
+		return nil, err
+	}
+	return out, nil
+}
+
+// listQueryLoggingConfigs returns the query logging configurations of a Route53 hosted zone
+func listQueryLoggingConfigs(route53Svc route53iface.Route53API, id *string) ([]*route53.QueryLoggingConfig, error) {
+	var configs []*route53.QueryLoggingConfig
+	input := &route53.ListQueryLoggingConfigsInput{HostedZoneId: id}
+	// The SDK has no paginated version of this API call
+	for {
+		out, err := route53Svc.ListQueryLoggingConfigs(input)
+		if err != nil {
+			utils.LogAWSError("Route53.ListQueryLoggingConfigs", err)
+			return nil, err
+		}
+		configs = append(configs, out.QueryLoggingConfigs...)
+		if out.NextToken == nil {
+			return configs, nil
+		}
+		input.NextToken = out.NextToken
+	}
+}
+
+// listRoute53Tags returns the tags of a Route53 hosted zone
+func listRoute53Tags(route53Svc route53iface.Route53API, id *string) ([]*route53.Tag, error) {
+	out, err := route53Svc.ListTagsForResource(&route53.ListTagsForResourceInput{
+		ResourceId:   id,
+		ResourceType: aws.String(route53.TagResourceTypeHostedzone),
+	})
+	if err != nil {
+		utils.LogAWSError("Route53.ListTagsForResource", err)
+		return nil, err
+	}
+	if out.ResourceTagSet == nil {
+		return nil, nil
+	}
+	return out.ResourceTagSet.Tags, nil
+}
+
+// buildRoute53HostedZoneSnapshot returns a complete snapshot of a Route53 hosted zone
+func buildRoute53HostedZoneSnapshot(route53Svc route53iface.Route53API, hostedZoneID *string) *awsmodels.Route53HostedZone {
+	if hostedZoneID == nil {
+		return nil
+	}
+
+	// The API calls accept the ID with or without its /hostedzone/ prefix
+	id := aws.String(strings.TrimPrefix(*hostedZoneID, hostedZoneIDPrefix))
+	details, err := getHostedZone(route53Svc, id)
+	if err != nil || details == nil || details.HostedZone == nil {
+		return nil
+	}
+
+	resourceARN := aws.String(hostedZoneARNPrefix + *id)
+	hostedZone := &awsmodels.Route53HostedZone{
+		GenericAWSResource: awsmodels.GenericAWSResource{
+			ARN:    resourceARN,
+			ID:     id,
+			Name:   details.HostedZone.Name,
+			Region: aws.String(awsmodels.GlobalRegion),
+		},
+		GenericResource: awsmodels.GenericResource{
+			ResourceID:   resourceARN,
+			ResourceType: aws.String(awsmodels.Route53HostedZoneSchema),
+		},
+		CallerReference:        details.HostedZone.CallerReference,
+		Config:                 details.HostedZone.Config,
+		LinkedService:          details.HostedZone.LinkedService,
+		ResourceRecordSetCount: details.HostedZone.ResourceRecordSetCount,
+		DelegationSet:          details.DelegationSet,
+		VPCs:                   details.VPCs,
+	}
+
+	tags, err := listRoute53Tags(route53Svc, id)
+	if err != nil {
+		return nil
+	}
+	hostedZone.Tags = utils.ParseTagSlice(tags)
+
+	hostedZone.QueryLoggingConfigs, err = listQueryLoggingConfigs(route53Svc, id)
+	if err != nil {
+		return nil
+	}
+
+	return hostedZone
+}
+
+// PollRoute53HostedZones gathers information on each Route53 Hosted Zone for an AWS account.
+func PollRoute53HostedZones(pollerInput *awsmodels.ResourcePollerInput) ([]*apimodels.AddResourceEntry, error) {
+	zap.L().Debug("starting Route53 Hosted Zone resource poller")
+	sess := session.Must(session.NewSession(&aws.Config{}))
+	creds, err := AssumeRoleFunc(pollerInput, sess)
+	if err != nil {
+		return nil, err
+	}
+
+	route53Svc := Route53ClientFunc(sess, &aws.Config{Credentials: creds}).(route53iface.Route53API)
+
+	// Start with generating a list of all hosted zones
+	hostedZones := listHostedZones(route53Svc)
+	if len(hostedZones) == 0 {
+		zap.L().Debug("no Route53 hosted zones found")
+		return nil, nil
+	}
+
+	resources := make([]*apimodels.AddResourceEntry, 0, len(hostedZones))
+	for _, hostedZone := range hostedZones {
+		hostedZoneSnapshot := buildRoute53HostedZoneSnapshot(route53Svc, hostedZone.Id)
+		if hostedZoneSnapshot == nil {
+			continue
+		}
+		hostedZoneSnapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+
+		resources = append(resources, &apimodels.AddResourceEntry{
+			Attributes:      hostedZoneSnapshot,
+			ID:              apimodels.ResourceID(*hostedZoneSnapshot.ResourceID),
+			IntegrationID:   apimodels.IntegrationID(*pollerInput.IntegrationID),
+			IntegrationType: apimodels.IntegrationTypeAws,
+			Type:            awsmodels.Route53HostedZoneSchema,
+		})
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,140 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws/awstest"
+)
+
+func TestRoute53HostedZoneList(t *testing.T) {
+	mockSvc := awstest.BuildMockRoute53Svc([]string{"ListHostedZonesPages"})
+
+	out := listHostedZones(mockSvc)
+	assert.NotEmpty(t, out)
+}
+
+func TestRoute53HostedZoneListError(t *testing.T) {
+	mockSvc := awstest.BuildMockRoute53SvcError([]string{"ListHostedZonesPages"})
+
+	out := listHostedZones(mockSvc)
+	assert.Nil(t, out)
+}
+
+func TestRoute53HostedZoneGetDoesNotExist(t *testing.T) {
+	mockSvc := &awstest.MockRoute53{}
+	mockSvc.On("GetHostedZone", mock.Anything).
+		Return(
+			&route53.GetHostedZoneOutput{},
+			awserr.New(route53.ErrCodeNoSuchHostedZone, "No hosted zone found", nil),
+		)
+
+	out, err := getHostedZone(mockSvc, aws.String("Z1111"))
+	require.NoError(t, err)
+	assert.Nil(t, out)
+}
+
+func TestRoute53HostedZoneQueryLoggingConfigsPaging(t *testing.T) {
+	mockSvc := &awstest.MockRoute53{}
+	mockSvc.On("ListQueryLoggingConfigs", &route53.ListQueryLoggingConfigsInput{HostedZoneId: aws.String("Z1111")}).
+		Return(&route53.ListQueryLoggingConfigsOutput{
+			QueryLoggingConfigs: awstest.ExampleListQueryLoggingConfigsOutput.QueryLoggingConfigs,
+			NextToken:           aws.String("next"),
+		}, nil).Once()
+	mockSvc.On("ListQueryLoggingConfigs", mock.Anything).
+		Return(awstest.ExampleListQueryLoggingConfigsOutput, nil).Once()
+
+	out, err := listQueryLoggingConfigs(mockSvc, aws.String("Z1111"))
+	require.NoError(t, err)
+	assert.Len(t, out, 2)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestRoute53HostedZoneBuildSnapshot(t *testing.T) {
+	mockSvc := awstest.BuildMockRoute53SvcAll()
+
+	hostedZoneSnapshot := buildRoute53HostedZoneSnapshot(mockSvc, awstest.ExampleHostedZoneId)
+
+	require.NotNil(t, hostedZoneSnapshot)
+	assert.Equal(t, *awstest.ExampleHostedZoneArn, *hostedZoneSnapshot.ResourceID)
+	assert.Equal(t, "Z1111", *hostedZoneSnapshot.ID)
+	assert.Equal(t, awsmodels.GlobalRegion, *hostedZoneSnapshot.Region)
+	assert.Equal(t, "Value1", *hostedZoneSnapshot.Tags["Key1"])
+	assert.Len(t, hostedZoneSnapshot.QueryLoggingConfigs, 1)
+	assert.NotNil(t, hostedZoneSnapshot.DelegationSet)
+	mockSvc.AssertCalled(t, "ListTagsForResource", &route53.ListTagsForResourceInput{
+		ResourceId:   aws.String("Z1111"),
+		ResourceType: aws.String("hostedzone"),
+	})
+}
+
+func TestRoute53HostedZoneBuildSnapshotErrors(t *testing.T) {
+	mockSvc := awstest.BuildMockRoute53SvcAllError()
+
+	hostedZoneSnapshot := buildRoute53HostedZoneSnapshot(mockSvc, awstest.ExampleHostedZoneId)
+
+	assert.Nil(t, hostedZoneSnapshot)
+}
+
+func TestRoute53HostedZonePoller(t *testing.T) {
+	awstest.MockRoute53ForSetup = awstest.BuildMockRoute53SvcAll()
+
+	AssumeRoleFunc = awstest.AssumeRoleMock
+	Route53ClientFunc = awstest.SetupMockRoute53
+
+	resources, err := PollRoute53HostedZones(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, *awstest.ExampleHostedZoneArn, string(resources[0].ID))
+	assert.Equal(t, "123456789012", *resources[0].Attributes.(*awsmodels.Route53HostedZone).AccountID)
+}
+
+func TestRoute53HostedZonePollerError(t *testing.T) {
+	awstest.MockRoute53ForSetup = awstest.BuildMockRoute53SvcAllError()
+
+	AssumeRoleFunc = awstest.AssumeRoleMock
+	Route53ClientFunc = awstest.SetupMockRoute53
+
+	resources, err := PollRoute53HostedZones(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
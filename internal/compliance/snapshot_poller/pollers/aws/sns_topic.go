@@ -0,0 +1,257 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	apimodels "github.com/panther-labs/panther/api/gateway/resources/models"
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
+)
+
+// Set as variables to be overridden in testing
+var SnsClientFunc = setupSnsClient
+
+func setupSnsClient(sess *session.Session, cfg *aws.Config) interface{} {
+	cfg.MaxRetries = aws.Int(MaxRetries)
+	return sns.New(sess, cfg)
+}
+
+// PollSNSTopic polls a single SNS topic resource
+func PollSNSTopic(
+	pollerInput *awsmodels.ResourcePollerInput,
+	resourceARN arn.ARN,
+	scanRequest *pollermodels.ScanEntry,
+) interface{} {
+
+	client := getClient(pollerInput, "sns", resourceARN.Region).(snsiface.SNSAPI)
+
+	snapshot := buildSnsTopicSnapshot(client, scanRequest.ResourceID)
+	if snapshot == nil {
+		return nil
+	}
+	snapshot.Region = aws.String(resourceARN.Region)
+	snapshot.AccountID = aws.String(resourceARN.AccountID)
+
+	return snapshot
+}
+
+// listTopics returns the ARNs of all SNS topics in the account
+func listTopics(snsSvc snsiface.SNSAPI) (topics []*string) {
+	err := snsSvc.ListTopicsPages(&sns.ListTopicsInput{},
+		func(page *sns.ListTopicsOutput, lastPage bool) bool {
+			for _, topic := range page.Topics {
+				topics = append(topics, topic.TopicArn)
+			}
+			return true
+		})
+	if err != nil {
+		utils.LogAWSError("SNS.ListTopicsPages", err)
+	}
+	return
+}
+
+// getTopicAttributes returns the attributes of a given SNS topic
+func getTopicAttributes(snsSvc snsiface.SNSAPI, topicArn *string) (map[string]*string, error) {
+	out, err := snsSvc.GetTopicAttributes(&sns.GetTopicAttributesInput{TopicArn: topicArn})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == sns.ErrCodeNotFoundException {
+			zap.L().Warn(
+				"tried to scan non-existent resource",
+				zap.String("resourceType", awsmodels.SnsTopicSchema),
+				zap.String("resourceId", *topicArn),
+			)
+			return nil, nil
+		}
+		utils.LogAWSError("SNS.GetTopicAttributes", err)
+		return nil, err
+	}
+	return out.Attributes, nil
+}
+
+// listSnsTags returns the tags of an SNS topic
+func listSnsTags(snsSvc snsiface.SNSAPI, topicArn *string) ([]*sns.Tag, error) {
+	out, err := snsSvc.ListTagsForResource(&sns.ListTagsForResourceInput{ResourceArn: topicArn})
+	if err != nil {
+		utils.LogAWSError("SNS.ListTagsForResource", err)
+		return nil, err
+	}
+	return out.Tags, nil
+}
+
+// listTopicSubscriptions returns the subscriptions of an SNS topic
+func listTopicSubscriptions(snsSvc snsiface.SNSAPI, topicArn *string) (subscriptions []*sns.Subscription, err error) {
+	err = snsSvc.ListSubscriptionsByTopicPages(&sns.ListSubscriptionsByTopicInput{TopicArn: topicArn},
+		func(page *sns.ListSubscriptionsByTopicOutput, lastPage bool) bool {
+			subscriptions = append(subscriptions, page.Subscriptions...)
+			return true
+		})
+	if err != nil {
+		utils.LogAWSError("SNS.ListSubscriptionsByTopicPages", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// parseTopicCount converts one of the subscription counts of the topic attributes, which are strings
+func parseTopicCount(attributes map[string]*string, name string) *int64 {
+	value, ok := attributes[name]
+	if !ok || value == nil {
+		return nil
+	}
+	count, err := strconv.ParseInt(*value, 10, 64)
+	if err != nil {
+		zap.L().Warn("unable to parse SNS topic attribute",
+			zap.String("attribute", name),
+			zap.String("value", *value))
+		return nil
+	}
+	return aws.Int64(count)
+}
+
+// buildSnsTopicSnapshot returns a complete snapshot of an SNS topic
+func buildSnsTopicSnapshot(snsSvc snsiface.SNSAPI, topicArn *string) *awsmodels.SnsTopic {
+	if topicArn == nil {
+		return nil
+	}
+
+	attributes, err := getTopicAttributes(snsSvc, topicArn)
+	if err != nil || attributes == nil {
+		return nil
+	}
+
+	snsTopic := &awsmodels.SnsTopic{
+		GenericAWSResource: awsmodels.GenericAWSResource{
+			ARN: topicArn,
+		},
+		GenericResource: awsmodels.GenericResource{
+			ResourceID:   topicArn,
+			ResourceType: aws.String(awsmodels.SnsTopicSchema),
+		},
+		DeliveryPolicy:          attributes["DeliveryPolicy"],
+		DisplayName:             attributes["DisplayName"],
+		EffectiveDeliveryPolicy: attributes["EffectiveDeliveryPolicy"],
+		KmsMasterKeyId:          attributes["KmsMasterKeyId"],
+		Owner:                   attributes["Owner"],
+		Policy:                  attributes["Policy"],
+		SubscriptionsConfirmed:  parseTopicCount(attributes, "SubscriptionsConfirmed"),
+		SubscriptionsDeleted:    parseTopicCount(attributes, "SubscriptionsDeleted"),
+		SubscriptionsPending:    parseTopicCount(attributes, "SubscriptionsPending"),
+	}
+
+	// The name of a topic is the resource of its ARN
+	if parsedARN, err := arn.Parse(*topicArn); err == nil {
+		snsTopic.Name = aws.String(parsedARN.Resource)
+	}
+
+	tags, err := listSnsTags(snsSvc, topicArn)
+	if err != nil {
+		return nil
+	}
+	snsTopic.Tags = utils.ParseTagSlice(tags)
+
+	snsTopic.Subscriptions, err = listTopicSubscriptions(snsSvc, topicArn)
+	if err != nil {
+		return nil
+	}
+
+	return snsTopic
+}
+
+// PollSnsTopics gathers information on each SNS Topic for an AWS account.
+func PollSnsTopics(pollerInput *awsmodels.ResourcePollerInput) ([]*apimodels.AddResourceEntry, error) {
+	zap.L().Debug("starting SNS Topic resource poller")
+	snsTopicSnapshots := make(map[string]*awsmodels.SnsTopic)
+
+	for _, regionID := range utils.GetServiceRegions(pollerInput.Regions, "sns") {
+		sess, err := session.NewSession(&aws.Config{Region: regionID})
+		if err != nil {
+			// The session failed to create, log an error and continue to the next region
+			zap.L().Error(
+				"unable to create aws session",
+				zap.String("region", *regionID),
+				zap.String("service", "sns"),
+				zap.Error(errors.WithStack(err)),
+			)
+			continue
+		}
+
+		creds, err := AssumeRoleFunc(pollerInput, sess)
+		if err != nil {
+			// The client failed to create, log an error and continue to the next region
+			zap.L().Error(
+				"unable to create aws client",
+				zap.String("region", *regionID),
+				zap.String("service", "sns"),
+				zap.Error(errors.WithStack(err)),
+			)
+			continue
+		}
+
+		snsSvc := SnsClientFunc(sess, &aws.Config{Credentials: creds}).(snsiface.SNSAPI)
+
+		// Start with generating a list of all topics
+		topics := listTopics(snsSvc)
+		if len(topics) == 0 {
+			zap.L().Debug("no SNS topics found", zap.String("region", *regionID))
+			continue
+		}
+
+		for _, topicArn := range topics {
+			snsTopicSnapshot := buildSnsTopicSnapshot(snsSvc, topicArn)
+			if snsTopicSnapshot == nil {
+				continue
+			}
+			snsTopicSnapshot.AccountID = aws.String(pollerInput.AuthSourceParsedARN.AccountID)
+			snsTopicSnapshot.Region = regionID
+
+			if _, ok := snsTopicSnapshots[*snsTopicSnapshot.ARN]; ok {
+				zap.L().Info(
+					"overwriting existing SNS Topic snapshot",
+					zap.String("resourceId", *snsTopicSnapshot.ARN),
+				)
+			}
+			snsTopicSnapshots[*snsTopicSnapshot.ARN] = snsTopicSnapshot
+		}
+	}
+
+	resources := make([]*apimodels.AddResourceEntry, 0, len(snsTopicSnapshots))
+	for resourceID, snsSnapshot := range snsTopicSnapshots {
+		resources = append(resources, &apimodels.AddResourceEntry{
+			Attributes:      snsSnapshot,
+			ID:              apimodels.ResourceID(resourceID),
+			IntegrationID:   apimodels.IntegrationID(*pollerInput.IntegrationID),
+			IntegrationType: apimodels.IntegrationTypeAws,
+			Type:            awsmodels.SnsTopicSchema,
+		})
+	}
+
+	return resources, nil
+}
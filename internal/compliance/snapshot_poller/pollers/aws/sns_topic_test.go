@@ -0,0 +1,118 @@
+package aws
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	awsmodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/aws"
+	"github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws/awstest"
+)
+
+func TestSnsTopicList(t *testing.T) {
+	mockSvc := awstest.BuildMockSnsSvc([]string{"ListTopicsPages"})
+
+	out := listTopics(mockSvc)
+	assert.Equal(t, []*string{awstest.ExampleSnsTopicArn}, out)
+}
+
+func TestSnsTopicListError(t *testing.T) {
+	mockSvc := awstest.BuildMockSnsSvcError([]string{"ListTopicsPages"})
+
+	out := listTopics(mockSvc)
+	assert.Nil(t, out)
+}
+
+func TestSnsTopicAttributesDoesNotExist(t *testing.T) {
+	mockSvc := &awstest.MockSns{}
+	mockSvc.On("GetTopicAttributes", mock.Anything).
+		Return(
+			&sns.GetTopicAttributesOutput{},
+			awserr.New(sns.ErrCodeNotFoundException, "Topic does not exist", nil),
+		)
+
+	out, err := getTopicAttributes(mockSvc, awstest.ExampleSnsTopicArn)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+}
+
+func TestSnsTopicBuildSnapshot(t *testing.T) {
+	mockSvc := awstest.BuildMockSnsSvcAll()
+
+	topicSnapshot := buildSnsTopicSnapshot(mockSvc, awstest.ExampleSnsTopicArn)
+
+	require.NotNil(t, topicSnapshot)
+	assert.Equal(t, "example-topic", *topicSnapshot.Name)
+	assert.Equal(t, "alias/aws/sns", *topicSnapshot.KmsMasterKeyId)
+	assert.Equal(t, int64(1), *topicSnapshot.SubscriptionsConfirmed)
+	assert.Nil(t, topicSnapshot.DeliveryPolicy)
+	assert.Equal(t, "Value1", *topicSnapshot.Tags["Key1"])
+	assert.Len(t, topicSnapshot.Subscriptions, 1)
+}
+
+func TestSnsTopicBuildSnapshotErrors(t *testing.T) {
+	mockSvc := awstest.BuildMockSnsSvcAllError()
+
+	topicSnapshot := buildSnsTopicSnapshot(mockSvc, awstest.ExampleSnsTopicArn)
+
+	assert.Nil(t, topicSnapshot)
+}
+
+func TestSnsTopicPoller(t *testing.T) {
+	awstest.MockSnsForSetup = awstest.BuildMockSnsSvcAll()
+
+	AssumeRoleFunc = awstest.AssumeRoleMock
+	SnsClientFunc = awstest.SetupMockSns
+
+	resources, err := PollSnsTopics(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, *awstest.ExampleSnsTopicArn, string(resources[0].ID))
+}
+
+func TestSnsTopicPollerError(t *testing.T) {
+	awstest.MockSnsForSetup = awstest.BuildMockSnsSvcAllError()
+
+	AssumeRoleFunc = awstest.AssumeRoleMock
+	SnsClientFunc = awstest.SetupMockSns
+
+	resources, err := PollSnsTopics(&awsmodels.ResourcePollerInput{
+		AuthSource:          &awstest.ExampleAuthSource,
+		AuthSourceParsedARN: awstest.ExampleAuthSourceParsedARN,
+		IntegrationID:       awstest.ExampleIntegrationID,
+		Regions:             awstest.ExampleRegions,
+		Timestamp:           &awstest.ExampleTime,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
@@ -60,6 +60,18 @@ func batchResources(resources []*api.AddResourceEntry) (batches [][]*api.AddReso
 	return
 }
 
+// limitResources drops the resources past the limit of the scan, which protects the pipeline from a noisy integration.
+func limitResources(entry *pollermodels.ScanEntry, resources []*api.AddResourceEntry) []*api.AddResourceEntry {
+	if entry.MaxResources == nil || len(resources) <= *entry.MaxResources {
+		return resources
+	}
+	zap.L().Warn("scan reached the resource limit of the integration",
+		zap.Any("sqsEntry", entry),
+		zap.Int("numResources", len(resources)),
+		zap.Int("maxResources", *entry.MaxResources))
+	return resources[:*entry.MaxResources]
+}
+
 // Handle is the main Lambda Handler.
 func Handle(ctx context.Context, event events.SQSEvent) (err error) {
 	lc, _ := lambdalogger.ConfigureGlobal(ctx, nil)
@@ -90,6 +102,7 @@ func Handle(ctx context.Context, event events.SQSEvent) (err error) {
 				operation.LogError(errors.Wrap(pollErr, "poll failed"), zap.Any("sqsEntry", entry))
 				continue
 			}
+			resources = limitResources(entry, resources)
 
 			// Send data to the Resources API
 			if resources != nil {
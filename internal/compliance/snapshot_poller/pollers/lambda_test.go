@@ -20,6 +20,7 @@ package pollers
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -78,6 +79,17 @@ func TestBatchResources(t *testing.T) {
 	assert.Len(t, testBatches[2], 100)
 }
 
+func TestLimitResources(t *testing.T) {
+	resources := make([]*resourcesapi.AddResourceEntry, 5)
+	for i := range resources {
+		resources[i] = &resourcesapi.AddResourceEntry{ID: resourcesapi.ResourceID(fmt.Sprintf("resource-%d", i))}
+	}
+
+	assert.Len(t, limitResources(&pollermodels.ScanEntry{}, resources), 5)
+	assert.Len(t, limitResources(&pollermodels.ScanEntry{MaxResources: aws.Int(10)}, resources), 5)
+	assert.Equal(t, resources[:2], limitResources(&pollermodels.ScanEntry{MaxResources: aws.Int(2)}, resources))
+}
+
 func TestHandlerNonExistentIntegration(t *testing.T) {
 	t.Skip("skipping until resources-api mock is in place")
 	testIntegrations := &pollermodels.ScanMsg{
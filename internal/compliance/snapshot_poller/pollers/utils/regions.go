@@ -71,3 +71,32 @@ func GetServiceRegions(activeRegions []*string, serviceID string) (regions []*st
 	}
 	return
 }
+
+// ScopeRegions returns the regions within the scan scope of an integration: the enabled regions when there are
+// some, minus the excluded regions. The order of the regions is kept.
+func ScopeRegions(regions, enabledRegions, excludedRegions []*string) (scoped []*string) {
+	for _, region := range regions {
+		if InScope(*region, enabledRegions, excludedRegions) {
+			scoped = append(scoped, region)
+		}
+	}
+	return
+}
+
+// InScope returns whether the region is within the scan scope given by the enabled and excluded regions.
+func InScope(region string, enabledRegions, excludedRegions []*string) bool {
+	for _, excluded := range excludedRegions {
+		if *excluded == region {
+			return false
+		}
+	}
+	if len(enabledRegions) == 0 {
+		return true
+	}
+	for _, enabled := range enabledRegions {
+		if *enabled == region {
+			return true
+		}
+	}
+	return false
+}
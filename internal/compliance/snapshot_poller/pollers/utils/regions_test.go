@@ -0,0 +1,46 @@
+package utils
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeRegions(t *testing.T) {
+	regions := aws.StringSlice([]string{"us-east-1", "us-west-2", "eu-west-1", "ap-south-1"})
+
+	assert.Equal(t, regions, ScopeRegions(regions, nil, nil))
+	assert.Equal(t, aws.StringSlice([]string{"us-west-2", "ap-south-1"}),
+		ScopeRegions(regions, aws.StringSlice([]string{"ap-south-1", "us-west-2", "cn-north-1"}), nil))
+	assert.Equal(t, aws.StringSlice([]string{"us-east-1", "ap-south-1"}),
+		ScopeRegions(regions, nil, aws.StringSlice([]string{"us-west-2", "eu-west-1"})))
+	assert.Equal(t, aws.StringSlice([]string{"us-east-1"}),
+		ScopeRegions(regions, aws.StringSlice([]string{"us-east-1", "eu-west-1"}), aws.StringSlice([]string{"eu-west-1"})))
+	assert.Nil(t, ScopeRegions(regions, aws.StringSlice([]string{"eu-central-1"}), nil))
+}
+
+func TestInScope(t *testing.T) {
+	assert.True(t, InScope("us-east-1", nil, nil))
+	assert.True(t, InScope("us-east-1", aws.StringSlice([]string{"us-east-1"}), aws.StringSlice([]string{"eu-west-1"})))
+	assert.False(t, InScope("eu-west-1", nil, aws.StringSlice([]string{"eu-west-1"})))
+	assert.False(t, InScope("eu-west-1", aws.StringSlice([]string{"us-east-1"}), nil))
+}
@@ -32,7 +32,12 @@ import (
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
-const sourceAPIFunctionName = "panther-source-api"
+const (
+	sourceAPIFunctionName = "panther-source-api"
+
+	// The most scan starts the source API records in a single request
+	maxScanStartsPerRequest = 100
+)
 
 var (
 	sess                               = session.Must(session.NewSession())
@@ -62,7 +67,57 @@ func PollAndIssueNewScans() error {
 		}
 	}
 
-	return snapshotapi.ScanAllResources(integrationsToScan)
+	if err := snapshotapi.ScanAllResources(integrationsToScan); err != nil {
+		return err
+	}
+	recordScanStarts(integrationsToScan, time.Now())
+	return nil
+}
+
+// recordScanStarts marks the integrations whose scans were queued as scanning, a few of them in each request.
+//
+// The scans are already queued, an integration whose start can't be recorded is only logged. It is scheduled
+// again like any other integration which doesn't appear to be scanning.
+func recordScanStarts(integrations []*models.SourceIntegrationMetadata, startTime time.Time) {
+	var updates []*models.UpdateIntegrationLastScanStartInput
+	for _, integration := range integrations {
+		// The scans of these are not queued
+		if !aws.BoolValue(integration.ScanEnabled) {
+			continue
+		}
+		updates = append(updates, &models.UpdateIntegrationLastScanStartInput{
+			IntegrationID:     integration.IntegrationID,
+			LastScanStartTime: aws.Time(startTime),
+			ScanStatus:        aws.String(models.StatusScanning),
+		})
+	}
+
+	for start := 0; start < len(updates); start += maxScanStartsPerRequest {
+		end := start + maxScanStartsPerRequest
+		if end > len(updates) {
+			end = len(updates)
+		}
+		var results []*models.IntegrationUpdateResult
+		err := genericapi.Invoke(
+			lambdaClient,
+			sourceAPIFunctionName,
+			&models.LambdaInput{UpdateIntegrationsLastScanStart: &models.UpdateIntegrationsLastScanStartInput{
+				Updates: updates[start:end],
+			}},
+			&results,
+		)
+		if err != nil {
+			zap.L().Warn("failed to record the start of the scans", zap.Int("count", end-start), zap.Error(err))
+			continue
+		}
+		for _, result := range results {
+			if !aws.BoolValue(result.Success) {
+				zap.L().Warn("failed to record the start of the scan",
+					zap.String("integrationId", aws.StringValue(result.IntegrationID)),
+					zap.String("error", aws.StringValue(result.ErrorMessage)))
+			}
+		}
+	}
 }
 
 // getEnabledIntegrations lists enabled integrations from the snapshot-api.
@@ -83,13 +138,26 @@ func getEnabledIntegrations() (integrations []*models.SourceIntegration, err err
 }
 
 // scanIsStuck checks if an integration's is stuck in the "scanning" state.
+//
+// A new integration which never completed a scan is stuck once the interval elapsed since its first scan started:
+// the scheduler marks it scanning when it queues the scan, whether or not the scan ever runs.
 func scanIsStuck(integration *models.SourceIntegration) bool {
-	// Accounts for a new integration that has not completed a scan
-	if integration.SourceIntegrationStatus == nil || integration.LastScanEndTime == nil {
+	if integration.SourceIntegrationStatus == nil || aws.StringValue(integration.ScanStatus) != models.StatusScanning {
 		return false
 	}
+	if integration.SourceIntegrationScanInformation == nil || integration.LastScanStartTime == nil {
+		// Its start was never recorded, nothing else would schedule it
+		return true
+	}
+	if integration.LastScanEndTime != nil {
+		return scanIntervalElapsed(integration)
+	}
 
-	return *integration.ScanStatus == models.StatusScanning && scanIntervalElapsed(integration)
+	started := *integration
+	scan := *integration.SourceIntegrationScanInformation
+	scan.LastScanEndTime = scan.LastScanStartTime
+	started.SourceIntegrationScanInformation = &scan
+	return scanIntervalElapsed(&started)
 }
 
 // scanIsNotOngoing checks if an integration's snapshot is currently running.
@@ -102,16 +170,9 @@ func scanIsNotOngoing(integration *models.SourceIntegration) bool {
 }
 
 // scanIntervalElapsed determines if a new scan needs to be started based on the configured interval.
+//
+// Scans are not started during the blackout windows of the integration.
 func scanIntervalElapsed(integration *models.SourceIntegration) bool {
-	// Account for cases when a scan has never ran.
-	if integration.SourceIntegrationScanInformation == nil {
-		return true
-	}
-
-	if integration.SourceIntegrationScanInformation.LastScanEndTime == nil {
-		return true
-	}
-
-	intervalMins := time.Duration(*integration.SourceIntegrationMetadata.ScanIntervalMins) * time.Minute
-	return time.Since(*integration.LastScanEndTime) >= intervalMins
+	now := time.Now()
+	return !snapshotapi.NextScanTime(integration, now).After(now)
 }
@@ -20,6 +20,7 @@ package scheduler
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -227,6 +228,32 @@ func TestNewScanNotNeeded(t *testing.T) {
 	}))
 }
 
+func TestScanIsStuck(t *testing.T) {
+	assert.False(t, scanIsStuck(exampleIntegrations[0]))
+	assert.True(t, scanIsStuck(exampleIntegrations[4]))
+}
+
+func TestScanIsStuckNeverCompleted(t *testing.T) {
+	// Its first scan started 20 minutes ago, within the interval
+	assert.False(t, scanIsStuck(exampleIntegrations[3]))
+
+	// Its first scan was queued 65 minutes ago and never completed: the message was lost, or the poller failed
+	assert.True(t, scanIsStuck(&models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String("0b5ae8a0-9c5c-44a4-a2d2-03e6d2d8f4a1"),
+			IntegrationType:  aws.String("aws-scan"),
+			ScanIntervalMins: aws.Int(60),
+			ScanEnabled:      aws.Bool(true),
+		},
+		SourceIntegrationStatus: &models.SourceIntegrationStatus{
+			ScanStatus: aws.String(models.StatusScanning),
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			LastScanStartTime: aws.Time(time.Now().Add(-65 * time.Minute)),
+		},
+	}))
+}
+
 func TestScanIsNotOngoingScanning(t *testing.T) {
 	assert.False(t, scanIsNotOngoing(&models.SourceIntegration{
 		SourceIntegrationStatus: &models.SourceIntegrationStatus{
@@ -271,3 +298,39 @@ func TestGetEnabledIntegrationsError(t *testing.T) {
 	mockLambda.AssertExpectations(t)
 	require.Error(t, err)
 }
+
+func TestRecordScanStarts(t *testing.T) {
+	mockLambda := &mockLambdaClient{}
+	lambdaClient = mockLambda
+	var requests []*models.UpdateIntegrationsLastScanStartInput
+	mockLambda.On("Invoke", mock.Anything).Return(getTestInvokeOutput([]*models.IntegrationUpdateResult{
+		{IntegrationID: aws.String("integration-0"), Success: aws.Bool(false), ErrorMessage: aws.String("in a blackout window")},
+	}, 200), nil).Run(func(args mock.Arguments) {
+		var input models.LambdaInput
+		require.NoError(t, jsoniter.Unmarshal(args.Get(0).(*lambda.InvokeInput).Payload, &input))
+		requests = append(requests, input.UpdateIntegrationsLastScanStart)
+	})
+
+	var integrations []*models.SourceIntegrationMetadata
+	for i := 0; i < 150; i++ {
+		integrations = append(integrations, &models.SourceIntegrationMetadata{
+			IntegrationID: aws.String(fmt.Sprintf("integration-%d", i)),
+			// The scans of the disabled integrations are not queued
+			ScanEnabled: aws.Bool(i != 7),
+		})
+	}
+	startTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	recordScanStarts(integrations, startTime)
+
+	require.Len(t, requests, 2)
+	assert.Len(t, requests[0].Updates, 100)
+	assert.Len(t, requests[1].Updates, 49)
+	first := requests[0].Updates[0]
+	assert.Equal(t, "integration-0", *first.IntegrationID)
+	assert.Equal(t, models.StatusScanning, *first.ScanStatus)
+	assert.True(t, startTime.Equal(*first.LastScanStartTime))
+	for _, update := range requests[0].Updates {
+		assert.NotEqual(t, "integration-7", *update.IntegrationID)
+	}
+	mockLambda.AssertExpectations(t)
+}
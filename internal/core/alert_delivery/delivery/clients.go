@@ -20,6 +20,8 @@ package delivery
 
 import (
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -38,6 +40,9 @@ var (
 
 	// Lazy-load the SQS client - we only need it to retry failed alerts
 	sqsClient sqsiface.SQSAPI
+
+	// Lazy-load the CloudWatch client - we only need it for the metrics of failed alerts
+	cloudwatchClient cloudwatchiface.CloudWatchAPI
 )
 
 func getSQSClient() sqsiface.SQSAPI {
@@ -46,3 +51,10 @@ func getSQSClient() sqsiface.SQSAPI {
 	}
 	return sqsClient
 }
+
+func getCloudWatchClient() cloudwatchiface.CloudWatchAPI {
+	if cloudwatchClient == nil {
+		cloudwatchClient = cloudwatch.New(awsSession)
+	}
+	return cloudwatchClient
+}
@@ -19,6 +19,8 @@ package delivery
  */
 
 import (
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/stretchr/testify/mock"
@@ -47,3 +49,13 @@ func (m *mockLambdaClient) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutp
 	args := m.Called(input)
 	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
 }
+
+type mockCloudWatchClient struct {
+	cloudwatchiface.CloudWatchAPI
+	mock.Mock
+}
+
+func (m *mockCloudWatchClient) PutMetricData(input *cloudwatch.PutMetricDataInput) (*cloudwatch.PutMetricDataOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*cloudwatch.PutMetricDataOutput), args.Error(1)
+}
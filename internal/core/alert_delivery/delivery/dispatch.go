@@ -76,6 +76,10 @@ func send(alert *alertmodels.Alert, output *outputmodels.AlertOutput, statusChan
 		alertDeliveryError = outputClient.Sns(alert, output.OutputConfig.Sns)
 	case "asana":
 		alertDeliveryError = outputClient.Asana(alert, output.OutputConfig.Asana)
+	case "servicenow":
+		alertDeliveryError = outputClient.ServiceNow(alert, output.OutputConfig.ServiceNow)
+	case "customwebhook":
+		alertDeliveryError = outputClient.CustomWebhook(alert, output.OutputConfig.CustomWebhook)
 	default:
 		zap.L().Warn("unsupported output type", commonFields...)
 		statusChannel <- outputStatus{outputID: *output.OutputID, success: false, needsRetry: false}
@@ -94,8 +98,10 @@ func send(alert *alertmodels.Alert, output *outputmodels.AlertOutput, statusChan
 
 // Dispatch sends the alert to each of its designated outputs.
 //
-// Returns true if the alert was sent successfully, false if it needs to be retried.
-func dispatch(alert *alertmodels.Alert) bool {
+// Returns true if the alert was sent successfully, false if it needs to be retried, in which case
+// the outputs of the alert are replaced with the ones to retry.
+// The outputs which permanently failed are returned as well, they are not retried.
+func dispatch(alert *alertmodels.Alert, metrics *deliveryMetrics) (bool, []*string) {
 	outputs, err := getAlertOutputs(alert)
 
 	if err != nil {
@@ -104,7 +110,7 @@ func dispatch(alert *alertmodels.Alert) bool {
 			zap.String("severity", *alert.Severity),
 			zap.Error(err),
 		)
-		return false
+		return false, nil
 	}
 
 	if len(outputs) == 0 {
@@ -112,7 +118,7 @@ func dispatch(alert *alertmodels.Alert) bool {
 			zap.String("policyId", *alert.PolicyID),
 			zap.String("severity", *alert.Severity),
 		)
-		return true
+		return true, nil
 	}
 
 	// Dispatch all outputs in parallel.
@@ -123,9 +129,12 @@ func dispatch(alert *alertmodels.Alert) bool {
 	}
 
 	// Wait until all outputs have finished, gathering any that need to be retried.
-	var retryOutputs []*string
+	var retryOutputs, failedOutputs []*string
 	for range outputs {
 		status := <-statusChannel
+		if !status.success {
+			metrics.addFailure(status.outputID)
+		}
 		if status.needsRetry {
 			retryOutputs = append(retryOutputs, aws.String(status.outputID))
 		} else if !status.success {
@@ -133,13 +142,14 @@ func dispatch(alert *alertmodels.Alert) bool {
 				"permanently failed to send alert to output",
 				zap.String("outputID", status.outputID),
 			)
+			failedOutputs = append(failedOutputs, aws.String(status.outputID))
 		}
 	}
 
 	if len(retryOutputs) > 0 {
 		alert.OutputIDs = retryOutputs // Replace the outputs with the set that failed
-		return false
+		return false, failedOutputs
 	}
 
-	return true
+	return true, failedOutputs
 }
@@ -114,7 +114,25 @@ func TestDispatchFailure(t *testing.T) {
 	setCaches()
 	mockClient.On("Slack", mock.Anything, mock.Anything).Return(&outputs.AlertDeliveryError{})
 
-	assert.False(t, dispatch(sampleAlert()))
+	metrics := newDeliveryMetrics()
+	success, failedOutputs := dispatch(sampleAlert(), metrics)
+	assert.False(t, success)
+	assert.Empty(t, failedOutputs)
+	assert.Equal(t, map[string]int{"output-id": 1}, metrics.failures)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDispatchPermanentFailure(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	setCaches()
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return(&outputs.AlertDeliveryError{Permanent: true})
+
+	metrics := newDeliveryMetrics()
+	success, failedOutputs := dispatch(sampleAlert(), metrics)
+	assert.True(t, success) // nothing to retry
+	assert.Equal(t, aws.StringSlice([]string{"output-id"}), failedOutputs)
+	assert.Equal(t, map[string]int{"output-id": 1}, metrics.failures)
 	mockClient.AssertExpectations(t)
 }
 
@@ -123,7 +141,8 @@ func TestDispatchSuccess(t *testing.T) {
 	outputClient = mockClient
 	setCaches()
 	mockClient.On("Slack", mock.Anything, mock.Anything).Return((*outputs.AlertDeliveryError)(nil))
-	assert.True(t, dispatch(sampleAlert()))
+	success, _ := dispatch(sampleAlert(), newDeliveryMetrics())
+	assert.True(t, success)
 }
 
 func TestDispatchUseCachedDefault(t *testing.T) {
@@ -134,7 +153,8 @@ func TestDispatchUseCachedDefault(t *testing.T) {
 	alert := sampleAlert()
 	alert.OutputIDs = nil //Setting OutputIds in the alert to nil, in order to fetch default outputs
 
-	assert.True(t, dispatch(alert))
+	success, _ := dispatch(alert, newDeliveryMetrics())
+	assert.True(t, success)
 	mockLambdaClient.AssertExpectations(t)
 }
 
@@ -155,11 +175,13 @@ func TestDispatchUseNonCachedDefault(t *testing.T) {
 		Payload: payload,
 	}
 
+	mockGetAlertRouting(t, mockLambdaClient, &outputmodels.AlertRouting{})
 	mockLambdaClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil)
 	alert := sampleAlert()
 	alert.OutputIDs = nil //Setting OutputIds in the alert to nil, in order to fetch default outputs
 	cache = nil           // Setting cache to nil, so we fetch latest outputs IDs from Lambda
-	assert.True(t, dispatch(alert))
+	success, _ := dispatch(alert, newDeliveryMetrics())
+	assert.True(t, success)
 	mockLambdaClient.AssertExpectations(t)
 }
 
@@ -183,12 +205,14 @@ func TestAllGoRoutinesShouldComplete(t *testing.T) {
 		Payload: payload,
 	}
 
-	// Invoke once to get all outpts
+	// Invoke once to get all outpts and once to get the routing rules
+	mockGetAlertRouting(t, mockLambdaClient, &outputmodels.AlertRouting{})
 	mockLambdaClient.On("Invoke", mock.Anything).Return(mockGetOutputsResponse, nil).Once()
 	alert := sampleAlert()
 	alert.OutputIDs = nil //Setting OutputIds in the alert to nil, in order to fetch default outputs
 	cache = nil           // Clearing the default output ids cache
 
-	assert.True(t, dispatch(alert))
+	success, _ := dispatch(alert, newDeliveryMetrics())
+	assert.True(t, success)
 	mockLambdaClient.AssertExpectations(t)
 }
@@ -41,33 +41,61 @@ func getMaxRetryDuration() time.Duration {
 	return time.Duration(mustParseInt(os.Getenv("ALERT_RETRY_DURATION_MINS"))) * time.Minute
 }
 
-// HandleAlerts sends each alert to its outputs and puts failed alerts back on the queue to retry.
+// deliveryStart is when the delivery of the alert started, it is retried for the max retry duration from then.
+func deliveryStart(alert *models.Alert) time.Time {
+	if alert.DeliveryStartedAt != nil {
+		return *alert.DeliveryStartedAt
+	}
+	return *alert.CreatedAt
+}
+
+// HandleAlerts sends each alert to its outputs and puts failed alerts on the retry queue.
+//
+// The alerts which permanently failed or exceeded the max retry duration go to the dead letter queue,
+// with the outputs they failed to be sent to.
 func HandleAlerts(alerts []*models.Alert) {
-	var failedAlerts []*models.Alert
+	var failedAlerts, deadLetters []*models.Alert
+	metrics := newDeliveryMetrics()
 
 	zap.L().Info("starting processing alerts", zap.Int("alerts", len(alerts)))
 
 	for _, alert := range alerts {
-		if !dispatch(alert) {
-			if time.Since(*alert.CreatedAt) > getMaxRetryDuration() {
-				zap.L().Error(
-					"alert delivery permanently failed, exceeded max retry duration",
-					zap.Strings("failedOutputs", aws.StringValueSlice(alert.OutputIDs)),
-					zap.Time("alertCreatedAt", *alert.CreatedAt),
-					zap.String("policyId", *alert.PolicyID),
-					zap.String("severity", *alert.Severity),
-				)
-			} else {
-				zap.L().Warn("will retry delivery of alert",
-					zap.String("policyId", *alert.PolicyID),
-					zap.String("severity", *alert.Severity),
-				)
-				failedAlerts = append(failedAlerts, alert)
-			}
+		success, failedOutputs := dispatch(alert, metrics)
+		if len(failedOutputs) > 0 {
+			failedAlert := *alert
+			failedAlert.OutputIDs = failedOutputs
+			deadLetters = append(deadLetters, &failedAlert)
+		}
+		if success {
+			continue
+		}
+
+		if time.Since(deliveryStart(alert)) > getMaxRetryDuration() {
+			zap.L().Error(
+				"alert delivery permanently failed, exceeded max retry duration",
+				zap.Strings("failedOutputs", aws.StringValueSlice(alert.OutputIDs)),
+				zap.Time("alertCreatedAt", *alert.CreatedAt),
+				zap.String("policyId", *alert.PolicyID),
+				zap.String("severity", *alert.Severity),
+			)
+			deadLetters = append(deadLetters, alert)
+		} else {
+			zap.L().Warn("will retry delivery of alert",
+				zap.String("policyId", *alert.PolicyID),
+				zap.String("severity", *alert.Severity),
+			)
+			failedAlerts = append(failedAlerts, alert)
 		}
 	}
 
 	if len(failedAlerts) > 0 {
 		retry(failedAlerts)
 	}
+	if len(deadLetters) > 0 {
+		for _, alert := range deadLetters {
+			metrics.addDeadLetters(alert.OutputIDs)
+		}
+		deadLetter(deadLetters)
+	}
+	metrics.publish()
 }
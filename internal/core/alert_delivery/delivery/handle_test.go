@@ -23,8 +23,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/panther-labs/panther/internal/core/alert_delivery/models"
 	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
@@ -45,10 +48,42 @@ func TestHandleAlertsPermanentlyFailed(t *testing.T) {
 	alert := sampleAlert()
 	alert.CreatedAt = &createdAtTime
 	alerts := []*models.Alert{alert, alert, alert}
-	sqsMessages = 0
+	os.Setenv("ALERT_DEAD_LETTER_QUEUE_URL", "dlq.url")
+	sqsMessages = make(map[string]int)
+	mockCloudWatch := &mockCloudWatchClient{}
+	cloudwatchClient = mockCloudWatch
+	mockCloudWatch.On("PutMetricData", mock.Anything).Return(&cloudwatch.PutMetricDataOutput{}, nil).Once()
 
 	HandleAlerts(alerts)
-	assert.Equal(t, 0, sqsMessages)
+	assert.Equal(t, map[string]int{"dlq.url": 3}, sqsMessages)
+	mockCloudWatch.AssertExpectations(t)
+}
+
+func TestHandleAlertsPermanentOutputFailure(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockClient.On("Slack", mock.Anything, mock.Anything).Return(&outputs.AlertDeliveryError{Permanent: true})
+	sqsClient = &mockSQSClient{}
+	setCaches()
+	os.Setenv("ALERT_RETRY_DURATION_MINS", "5")
+	os.Setenv("ALERT_DEAD_LETTER_QUEUE_URL", "dlq.url")
+	sqsMessages = make(map[string]int)
+	mockCloudWatch := &mockCloudWatchClient{}
+	cloudwatchClient = mockCloudWatch
+	mockCloudWatch.On("PutMetricData", mock.Anything).Return(&cloudwatch.PutMetricDataOutput{}, nil).Once()
+
+	// A permanent failure isn't retried, but the alert is not lost
+	HandleAlerts([]*models.Alert{sampleAlert()})
+	assert.Equal(t, map[string]int{"dlq.url": 1}, sqsMessages)
+
+	metrics := mockCloudWatch.Calls[0].Arguments.Get(0).(*cloudwatch.PutMetricDataInput)
+	require.Len(t, metrics.MetricData, 2)
+	assert.Equal(t, deliveryFailuresMetric, *metrics.MetricData[0].MetricName)
+	assert.Equal(t, deliveryDeadLettersMetric, *metrics.MetricData[1].MetricName)
+	for _, datum := range metrics.MetricData {
+		assert.Equal(t, "output-id", *datum.Dimensions[0].Value)
+		assert.Equal(t, float64(1), *datum.Value)
+	}
 }
 
 func TestHandleAlertsTemporarilyFailed(t *testing.T) {
@@ -59,14 +94,23 @@ func TestHandleAlertsTemporarilyFailed(t *testing.T) {
 	sqsClient = &mockSQSClient{}
 	setCaches()
 	os.Setenv("ALERT_RETRY_DURATION_MINS", "5")
-	os.Setenv("ALERT_QUEUE_URL", "sqs.url")
+	os.Setenv("ALERT_RETRY_QUEUE_URL", "retry.url")
 	os.Setenv("MIN_RETRY_DELAY_SECS", "10")
 	os.Setenv("MAX_RETRY_DELAY_SECS", "30")
-	alert := sampleAlert()
-	alert.CreatedAt = &createdAtTime
-	alerts := []*models.Alert{alert, alert, alert}
-	sqsMessages = 0
+	alerts := []*models.Alert{sampleAlert(), sampleAlert(), sampleAlert()}
+	alerts[0].CreatedAt = &createdAtTime
+	// A redelivered alert is retried again
+	alerts[1].CreatedAt = aws.Time(createdAtTime.Add(-time.Hour))
+	alerts[1].DeliveryStartedAt = &createdAtTime
+	sqsMessages = make(map[string]int)
+	mockCloudWatch := &mockCloudWatchClient{}
+	cloudwatchClient = mockCloudWatch
+	mockCloudWatch.On("PutMetricData", mock.Anything).Return(&cloudwatch.PutMetricDataOutput{}, nil).Once()
 
 	HandleAlerts(alerts)
-	assert.Equal(t, 3, sqsMessages)
+	assert.Equal(t, map[string]int{"retry.url": 3}, sqsMessages)
+	for _, alert := range alerts {
+		assert.Equal(t, 1, alert.RetryCount)
+	}
+	mockCloudWatch.AssertExpectations(t)
 }
@@ -0,0 +1,106 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"go.uber.org/zap"
+)
+
+const (
+	metricsNamespace = "Panther"
+
+	// Metrics of each output, by output ID
+	deliveryFailuresMetric    = "AlertDeliveryFailures"
+	deliveryDeadLettersMetric = "AlertDeliveryDeadLetters"
+
+	maxMetricDataPerRequest = 20
+)
+
+// deliveryMetrics counts the failed deliveries of an invocation, per output.
+type deliveryMetrics struct {
+	failures    map[string]int
+	deadLetters map[string]int
+}
+
+func newDeliveryMetrics() *deliveryMetrics {
+	return &deliveryMetrics{
+		failures:    make(map[string]int),
+		deadLetters: make(map[string]int),
+	}
+}
+
+// addFailure counts a failed attempt to send an alert to an output.
+func (m *deliveryMetrics) addFailure(outputID string) {
+	m.failures[outputID]++
+}
+
+// addDeadLetters counts the alerts sent to the dead letter queue, which failed delivery to each output.
+func (m *deliveryMetrics) addDeadLetters(outputIDs []*string) {
+	for _, outputID := range outputIDs {
+		m.deadLetters[*outputID]++
+	}
+}
+
+// publish sends the metrics to CloudWatch, a failure is logged but does not fail the delivery.
+func (m *deliveryMetrics) publish() {
+	now := time.Now()
+	var data []*cloudwatch.MetricDatum
+	data = append(data, metricData(deliveryFailuresMetric, m.failures, now)...)
+	data = append(data, metricData(deliveryDeadLettersMetric, m.deadLetters, now)...)
+
+	for start := 0; start < len(data); start += maxMetricDataPerRequest {
+		end := start + maxMetricDataPerRequest
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := getCloudWatchClient().PutMetricData(&cloudwatch.PutMetricDataInput{
+			MetricData: data[start:end],
+			Namespace:  aws.String(metricsNamespace),
+		})
+		if err != nil {
+			zap.L().Warn("failed to publish alert delivery metrics", zap.Error(err))
+			return
+		}
+	}
+}
+
+func metricData(name string, counts map[string]int, now time.Time) []*cloudwatch.MetricDatum {
+	outputIDs := make([]string, 0, len(counts))
+	for outputID := range counts {
+		outputIDs = append(outputIDs, outputID)
+	}
+	sort.Strings(outputIDs) // deterministic requests
+
+	result := make([]*cloudwatch.MetricDatum, len(outputIDs))
+	for i, outputID := range outputIDs {
+		result[i] = &cloudwatch.MetricDatum{
+			Dimensions: []*cloudwatch.Dimension{{Name: aws.String("OutputId"), Value: aws.String(outputID)}},
+			MetricName: aws.String(name),
+			Timestamp:  aws.Time(now),
+			Unit:       aws.String(cloudwatch.StandardUnitCount),
+			Value:      aws.Float64(float64(counts[outputID])),
+		}
+	}
+	return result
+}
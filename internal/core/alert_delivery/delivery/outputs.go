@@ -22,6 +22,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"go.uber.org/zap"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -31,7 +32,9 @@ import (
 
 type outputsCache struct {
 	// All cached outputs
-	Outputs   []*outputmodels.AlertOutput
+	Outputs []*outputmodels.AlertOutput
+	// The routing rules selecting the outputs of the alerts
+	Routing   *outputmodels.AlertRouting
 	Timestamp time.Time
 }
 
@@ -58,26 +61,50 @@ func getAlertOutputs(alert *alertmodels.Alert) ([]*outputmodels.AlertOutput, err
 		if err := genericapi.Invoke(lambdaClient, outputsAPI, &input, &outputs); err != nil {
 			return nil, err
 		}
+		input = outputmodels.LambdaInput{GetAlertRouting: &outputmodels.GetAlertRoutingInput{}}
+		var routing outputmodels.GetAlertRoutingOutput
+		if err := genericapi.Invoke(lambdaClient, outputsAPI, &input, &routing); err != nil {
+			return nil, err
+		}
 		cache = &outputsCache{
 			Outputs:   outputs,
+			Routing:   &routing,
 			Timestamp: time.Now().UTC(),
 		}
 	}
 
-	// If alert doesn't have outputs IDs specified, return the defaults for the severity
-	if len(alert.OutputIDs) == 0 {
+	if len(alert.OutputIDs) > 0 {
+		return getOutputsByID(alert.OutputIDs), nil
+	}
+
+	// If alert doesn't have outputs IDs specified, route it with the routing rules
+	rule, outputIDs := cache.Routing.Route(&outputmodels.RoutedAlert{
+		Severity:            alert.Severity,
+		Tags:                alert.Tags,
+		LogTypes:            alert.LogTypes,
+		SourceIntegrationID: alert.SourceIntegrationID,
+	})
+	if rule != nil {
+		zap.L().Debug("alert matched routing rule",
+			zap.String("alertId", aws.StringValue(alert.AlertID)), zap.String("rule", *rule.Name))
+	}
+	// Without a default route, return the defaults for the severity
+	if outputIDs == nil {
 		return getOutputsBySeverity(alert.Severity), nil
 	}
+	return getOutputsByID(outputIDs), nil
+}
 
+func getOutputsByID(outputIDs []*string) []*outputmodels.AlertOutput {
 	result := []*outputmodels.AlertOutput{}
 	for _, output := range cache.Outputs {
-		for _, alertOutputID := range alert.OutputIDs {
-			if *output.OutputID == *alertOutputID {
+		for _, outputID := range outputIDs {
+			if *output.OutputID == *outputID {
 				result = append(result, output)
 			}
 		}
 	}
-	return result, nil
+	return result
 }
 
 func getOutputsBySeverity(severity *string) []*outputmodels.AlertOutput {
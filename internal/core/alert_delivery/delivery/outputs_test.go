@@ -20,6 +20,7 @@ package delivery
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -32,6 +33,15 @@ import (
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
+// mockGetAlertRouting mocks the routing rules returned by the outputs-api, before any other invocation
+func mockGetAlertRouting(t *testing.T, client *mockLambdaClient, routing *outputmodels.AlertRouting) {
+	payload, err := jsoniter.Marshal(routing)
+	require.NoError(t, err)
+	client.On("Invoke", mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+		return strings.Contains(string(input.Payload), `"getAlertRouting":{`)
+	})).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+}
+
 func TestGetAlertOutputs(t *testing.T) {
 	mockClient := &mockLambdaClient{}
 	lambdaClient = mockClient
@@ -55,6 +65,7 @@ func TestGetAlertOutputs(t *testing.T) {
 	mockLambdaResponse := &lambda.InvokeOutput{Payload: payload}
 
 	cache = nil // Clear the cache
+	mockGetAlertRouting(t, mockClient, &outputmodels.AlertRouting{})
 	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
 	alert := sampleAlert()
 	alert.OutputIDs = nil
@@ -91,3 +102,63 @@ func TestGetAlertOutputsIdsError(t *testing.T) {
 	assert.Nil(t, result)
 	mockClient.AssertExpectations(t)
 }
+
+func TestGetAlertOutputsRouting(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+
+	output := &outputmodels.GetOutputsOutput{
+		{
+			OutputID:           aws.String("pager"),
+			DefaultForSeverity: aws.StringSlice([]string{"CRITICAL"}),
+		},
+		{
+			OutputID:           aws.String("slack"),
+			DefaultForSeverity: aws.StringSlice([]string{"INFO"}),
+		},
+		{
+			OutputID: aws.String("ticket"),
+		},
+	}
+	payload, err := jsoniter.Marshal(output)
+	require.NoError(t, err)
+
+	cache = nil // Clear the cache
+	mockGetAlertRouting(t, mockClient, &outputmodels.AlertRouting{
+		Rules: []*outputmodels.RoutingRule{
+			{
+				Name:      aws.String("cloudtrail-pci"),
+				Tags:      aws.StringSlice([]string{"PCI"}),
+				LogTypes:  aws.StringSlice([]string{"AWS.CloudTrail"}),
+				OutputIDs: aws.StringSlice([]string{"pager", "ticket"}),
+			},
+		},
+		DefaultOutputIDs: aws.StringSlice([]string{"ticket"}),
+	})
+	mockClient.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+
+	alert := sampleAlert()
+	alert.OutputIDs = nil
+	alert.Tags = aws.StringSlice([]string{"pci"})
+	alert.LogTypes = aws.StringSlice([]string{"AWS.CloudTrail"})
+	result, err := getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "pager", *result[0].OutputID)
+	assert.Equal(t, "ticket", *result[1].OutputID)
+
+	// An alert matching no rule takes the default route instead of the defaults for its severity
+	alert.LogTypes = aws.StringSlice([]string{"AWS.VPCFlow"})
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "ticket", *result[0].OutputID)
+
+	// The outputs set on the alert are not routed
+	alert.OutputIDs = aws.StringSlice([]string{"slack"})
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "slack", *result[0].OutputID)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	deliverymodels "github.com/panther-labs/panther/api/lambda/delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	defaultRedeliverMaxAlerts = 100
+
+	// The alerts which are received but not redelivered are visible again in the dead letter queue
+	// after this timeout, long enough for them not to be received twice by one redelivery.
+	redeliverVisibilitySecs = 120
+)
+
+// Redeliver moves the alerts of the dead letter queue back to the alert queue, for a new delivery.
+//
+// Each redelivered alert is retried for the max retry duration again.
+func Redeliver(input *deliverymodels.RedeliverInput) (*deliverymodels.RedeliverOutput, error) {
+	maxAlerts := defaultRedeliverMaxAlerts
+	if input.MaxAlerts != nil {
+		maxAlerts = *input.MaxAlerts
+	}
+	deadLetterQueueURL := aws.String(os.Getenv("ALERT_DEAD_LETTER_QUEUE_URL"))
+
+	result := &deliverymodels.RedeliverOutput{}
+	for result.Redelivered < maxAlerts {
+		batchSize := maxAlerts - result.Redelivered
+		if batchSize > 10 {
+			batchSize = 10
+		}
+		response, err := getSQSClient().ReceiveMessage(&sqs.ReceiveMessageInput{
+			MaxNumberOfMessages: aws.Int64(int64(batchSize)),
+			QueueUrl:            deadLetterQueueURL,
+			VisibilityTimeout:   aws.Int64(redeliverVisibilitySecs),
+			WaitTimeSeconds:     aws.Int64(1), // query all the servers of the queue
+		})
+		if err != nil {
+			return result, &genericapi.AWSError{Method: "sqs.ReceiveMessage", Err: err}
+		}
+		if len(response.Messages) == 0 {
+			break
+		}
+
+		var alerts []*models.Alert
+		var deleteEntries []*sqs.DeleteMessageBatchRequestEntry
+		for _, message := range response.Messages {
+			alert := &models.Alert{}
+			if err := jsoniter.UnmarshalFromString(aws.StringValue(message.Body), alert); err != nil {
+				zap.L().Warn("skipping invalid dead letter", zap.String("messageId", aws.StringValue(message.MessageId)))
+				continue
+			}
+			if input.OutputID != nil && !containsOutput(alert.OutputIDs, *input.OutputID) {
+				continue
+			}
+			alert.RetryCount = 0
+			alert.DeliveryStartedAt = aws.Time(time.Now().UTC())
+			alerts = append(alerts, alert)
+			deleteEntries = append(deleteEntries, &sqs.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(len(deleteEntries))),
+				ReceiptHandle: message.ReceiptHandle,
+			})
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+
+		if err := sendAlerts(os.Getenv("ALERT_QUEUE_URL"), alerts, nil); err != nil {
+			return result, err
+		}
+		// The alerts are queued, a failure to delete them from the dead letter queue only duplicates them
+		deleteResponse, err := getSQSClient().DeleteMessageBatch(&sqs.DeleteMessageBatchInput{
+			Entries:  deleteEntries,
+			QueueUrl: deadLetterQueueURL,
+		})
+		if err != nil || len(deleteResponse.Failed) > 0 {
+			zap.L().Error("failed to delete redelivered alerts from the dead letter queue", zap.Error(err))
+		}
+		result.Redelivered += len(alerts)
+	}
+
+	zap.L().Info("redelivered alerts", zap.Int("alerts", result.Redelivered))
+	return result, nil
+}
+
+func containsOutput(outputIDs []*string, outputID string) bool {
+	for _, id := range outputIDs {
+		if *id == outputID {
+			return true
+		}
+	}
+	return false
+}
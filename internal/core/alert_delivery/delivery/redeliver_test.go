@@ -0,0 +1,134 @@
+package delivery
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deliverymodels "github.com/panther-labs/panther/api/lambda/delivery/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// mockDeadLetterQueue returns the batches of its messages, then nothing
+type mockDeadLetterQueue struct {
+	mockSQSClient
+	batches      [][]*sqs.Message
+	deleted      []string
+	requeued     []*models.Alert
+	receiveCalls int
+}
+
+func (m *mockDeadLetterQueue) ReceiveMessage(input *sqs.ReceiveMessageInput) (*sqs.ReceiveMessageOutput, error) {
+	m.receiveCalls++
+	if len(m.batches) == 0 {
+		return &sqs.ReceiveMessageOutput{}, nil
+	}
+	batch := m.batches[0]
+	m.batches = m.batches[1:]
+	if int64(len(batch)) > *input.MaxNumberOfMessages {
+		batch = batch[:*input.MaxNumberOfMessages]
+	}
+	return &sqs.ReceiveMessageOutput{Messages: batch}, nil
+}
+
+func (m *mockDeadLetterQueue) SendMessageBatch(input *sqs.SendMessageBatchInput) (*sqs.SendMessageBatchOutput, error) {
+	for _, entry := range input.Entries {
+		var alert models.Alert
+		if err := jsoniter.UnmarshalFromString(*entry.MessageBody, &alert); err != nil {
+			return nil, err
+		}
+		m.requeued = append(m.requeued, &alert)
+	}
+	return m.mockSQSClient.SendMessageBatch(input)
+}
+
+func (m *mockDeadLetterQueue) DeleteMessageBatch(input *sqs.DeleteMessageBatchInput) (*sqs.DeleteMessageBatchOutput, error) {
+	for _, entry := range input.Entries {
+		m.deleted = append(m.deleted, *entry.ReceiptHandle)
+	}
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+
+func deadLetterMessage(t *testing.T, receiptHandle string, outputIDs ...string) *sqs.Message {
+	alert := sampleAlert()
+	alert.OutputIDs = aws.StringSlice(outputIDs)
+	alert.RetryCount = 5
+	body, err := jsoniter.MarshalToString(alert)
+	require.NoError(t, err)
+	return &sqs.Message{Body: aws.String(body), ReceiptHandle: aws.String(receiptHandle)}
+}
+
+func TestRedeliver(t *testing.T) {
+	os.Setenv("ALERT_QUEUE_URL", "alert.url")
+	os.Setenv("ALERT_DEAD_LETTER_QUEUE_URL", "dlq.url")
+	sqsMessages = make(map[string]int)
+	queue := &mockDeadLetterQueue{batches: [][]*sqs.Message{
+		{
+			deadLetterMessage(t, "slack", "slack-output-id"),
+			{Body: aws.String("invalid"), ReceiptHandle: aws.String("invalid")},
+			deadLetterMessage(t, "slack-and-pager", "slack-output-id", "pager-output-id"),
+		},
+		{
+			deadLetterMessage(t, "pager", "pager-output-id"),
+		},
+	}}
+	sqsClient = queue
+
+	result, err := Redeliver(&deliverymodels.RedeliverInput{OutputID: aws.String("slack-output-id")})
+	require.NoError(t, err)
+	assert.Equal(t, &deliverymodels.RedeliverOutput{Redelivered: 2}, result)
+	assert.Equal(t, map[string]int{"alert.url": 2}, sqsMessages)
+	assert.Equal(t, []string{"slack", "slack-and-pager"}, queue.deleted)
+	assert.Equal(t, 3, queue.receiveCalls)
+
+	// The alerts are retried again from now
+	for _, alert := range queue.requeued {
+		assert.Equal(t, 0, alert.RetryCount)
+		assert.WithinDuration(t, time.Now(), *alert.DeliveryStartedAt, time.Minute)
+	}
+}
+
+func TestRedeliverMaxAlerts(t *testing.T) {
+	os.Setenv("ALERT_QUEUE_URL", "alert.url")
+	sqsMessages = make(map[string]int)
+	queue := &mockDeadLetterQueue{batches: [][]*sqs.Message{
+		{
+			deadLetterMessage(t, "1", "output-id"),
+			deadLetterMessage(t, "2", "output-id"),
+		},
+		{
+			deadLetterMessage(t, "3", "output-id"),
+		},
+	}}
+	sqsClient = queue
+
+	result, err := Redeliver(&deliverymodels.RedeliverInput{MaxAlerts: aws.Int(1)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Redelivered)
+	assert.Equal(t, []string{"1"}, queue.deleted)
+	assert.Equal(t, 1, queue.receiveCalls)
+}
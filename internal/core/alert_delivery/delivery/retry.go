@@ -33,25 +33,70 @@ import (
 	"github.com/panther-labs/panther/pkg/awsbatch/sqsbatch"
 )
 
-const maxSQSBackoff = 30 * time.Second
+const (
+	maxSQSBackoff = 30 * time.Second
+
+	// SQS doesn't delay messages longer than 15 minutes
+	maxSQSDelaySeconds = 900
+)
 
 // Generate a random int between lower (inclusive) and upper (exclusive).
 func randomInt(lower, upper int) int {
 	return rand.Intn(upper-lower) + lower
 }
 
-// retry a batch of failed outputs by putting them all back on the queue with random delays.
+// retryDelaySeconds is the exponential backoff before the next delivery attempt of an alert.
+//
+// The delay doubles with each retry from minDelay up to maxDelay and up to half of it is taken off at random,
+// so the retries of the alerts of a broken output are spread out.
+func retryDelaySeconds(retryCount, minDelay, maxDelay int) int {
+	delay := minDelay
+	for i := 0; i < retryCount && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if delay > maxSQSDelaySeconds {
+		delay = maxSQSDelaySeconds
+	}
+	return randomInt(delay-delay/2, delay+1)
+}
+
+// retry a batch of failed outputs by putting them all on the retry queue with exponential backoff.
 func retry(alerts []*models.Alert) {
 	zap.L().Warn("queueing failed alerts for future retry", zap.Int("failedAlerts", len(alerts)))
-	input := &sqs.SendMessageBatchInput{
-		Entries:  make([]*sqs.SendMessageBatchRequestEntry, len(alerts)),
-		QueueUrl: aws.String(os.Getenv("ALERT_QUEUE_URL")),
-	}
 
 	rand.Seed(time.Now().UnixNano())
 	minDelaySeconds := mustParseInt(os.Getenv("MIN_RETRY_DELAY_SECS"))
 	maxDelaySeconds := mustParseInt(os.Getenv("MAX_RETRY_DELAY_SECS"))
 
+	delays := make([]int, len(alerts))
+	for i, alert := range alerts {
+		delays[i] = retryDelaySeconds(alert.RetryCount, minDelaySeconds, maxDelaySeconds)
+		alert.RetryCount++
+	}
+
+	if err := sendAlerts(os.Getenv("ALERT_RETRY_QUEUE_URL"), alerts, delays); err != nil {
+		zap.L().Error("unable to retry failed alerts", zap.Error(err))
+	}
+}
+
+// deadLetter sends the alerts which can't be delivered to the dead letter queue, to be redelivered later.
+func deadLetter(alerts []*models.Alert) {
+	zap.L().Warn("sending undeliverable alerts to the dead letter queue", zap.Int("failedAlerts", len(alerts)))
+	if err := sendAlerts(os.Getenv("ALERT_DEAD_LETTER_QUEUE_URL"), alerts, nil); err != nil {
+		zap.L().Error("unable to send undeliverable alerts to the dead letter queue", zap.Error(err))
+	}
+}
+
+// sendAlerts puts the alerts on a queue, each with its delay if there are delays.
+func sendAlerts(queueURL string, alerts []*models.Alert, delaySeconds []int) error {
+	input := &sqs.SendMessageBatchInput{
+		Entries:  make([]*sqs.SendMessageBatchRequestEntry, len(alerts)),
+		QueueUrl: aws.String(queueURL),
+	}
+
 	for i, alert := range alerts {
 		body, err := jsoniter.MarshalToString(alert)
 		if err != nil {
@@ -59,13 +104,13 @@ func retry(alerts []*models.Alert) {
 		}
 
 		input.Entries[i] = &sqs.SendMessageBatchRequestEntry{
-			DelaySeconds: aws.Int64(int64(randomInt(minDelaySeconds, maxDelaySeconds))),
-			Id:           aws.String(strconv.Itoa(i)),
-			MessageBody:  aws.String(body),
+			Id:          aws.String(strconv.Itoa(i)),
+			MessageBody: aws.String(body),
+		}
+		if delaySeconds != nil {
+			input.Entries[i].DelaySeconds = aws.Int64(int64(delaySeconds[i]))
 		}
 	}
 
-	if err := sqsbatch.SendMessageBatch(getSQSClient(), maxSQSBackoff, input); err != nil {
-		zap.L().Error("unable to retry failed alerts", zap.Error(err))
-	}
+	return sqsbatch.SendMessageBatch(getSQSClient(), maxSQSBackoff, input)
 }
@@ -20,9 +20,11 @@ package delivery
 
 import (
 	"errors"
+	"testing"
 
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
 )
 
 type mockSQSClient struct {
@@ -30,14 +32,27 @@ type mockSQSClient struct {
 	err bool
 }
 
-var sqsMessages int // store number of messages here for tests to verify
+var sqsMessages map[string]int // store number of messages sent to each queue here for tests to verify
 
 func (m mockSQSClient) SendMessageBatch(input *sqs.SendMessageBatchInput) (*sqs.SendMessageBatchOutput, error) {
 	if m.err {
 		return nil, errors.New("internal service error")
 	}
-	sqsMessages = len(input.Entries)
+	sqsMessages[*input.QueueUrl] += len(input.Entries)
 	return &sqs.SendMessageBatchOutput{
 		Successful: make([]*sqs.SendMessageBatchResultEntry, len(input.Entries)),
 	}, nil
 }
+
+func TestRetryDelaySeconds(t *testing.T) {
+	for retryCount, expected := range []int{10, 20, 40, 80, 100, 100} {
+		for i := 0; i < 10; i++ {
+			delay := retryDelaySeconds(retryCount, 10, 100)
+			assert.LessOrEqual(t, delay, expected)
+			assert.GreaterOrEqual(t, delay, expected-expected/2)
+		}
+	}
+
+	// SQS can't delay messages longer than 15 minutes
+	assert.LessOrEqual(t, retryDelaySeconds(20, 60, 86400), maxSQSDelaySeconds)
+}
@@ -29,15 +29,35 @@ import (
 	"go.uber.org/zap"
 	"gopkg.in/go-playground/validator.v9"
 
+	deliverymodels "github.com/panther-labs/panther/api/lambda/delivery/models"
 	"github.com/panther-labs/panther/internal/core/alert_delivery/delivery"
 	"github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
 	"github.com/panther-labs/panther/pkg/lambdalogger"
 	"github.com/panther-labs/panther/pkg/oplog"
 )
 
 var validate = validator.New()
 
-func lambdaHandler(ctx context.Context, event events.SQSEvent) (err error) {
+// lambdaInput is either a batch of the alert queues or a direct invocation to redeliver alerts
+type lambdaInput struct {
+	events.SQSEvent
+	deliverymodels.LambdaInput
+}
+
+func lambdaHandler(ctx context.Context, input *lambdaInput) (interface{}, error) {
+	if input.Redeliver == nil {
+		return nil, handleSQSEvent(ctx, input.SQSEvent)
+	}
+
+	lambdalogger.ConfigureGlobal(ctx, nil)
+	if err := validate.Struct(input.Redeliver); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+	return delivery.Redeliver(input.Redeliver)
+}
+
+func handleSQSEvent(ctx context.Context, event events.SQSEvent) (err error) {
 	var alerts []*models.Alert
 
 	lc, _ := lambdalogger.ConfigureGlobal(ctx, nil)
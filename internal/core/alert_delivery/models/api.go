@@ -56,9 +56,21 @@ type Alert struct {
 	// Tags is the set of policy tags.
 	Tags []*string `json:"tags,omitempty"`
 
+	// LogTypes is the set of log types of the events which triggered a rule alert.
+	LogTypes []*string `json:"logTypes,omitempty"`
+
+	// SourceIntegrationID is the source integration the alert is about, if any.
+	SourceIntegrationID *string `json:"sourceIntegrationId,omitempty"`
+
 	// AlertID specifies the alertId that this Alert is associated with.
 	AlertID *string `json:"alertId,omitempty"`
 
 	// Type specifies if an alert is for a policy or a rule
 	Type *string `json:"type,omitempty" validate:"omitempty,oneof=RULE POLICY"`
+
+	// RetryCount is the number of failed delivery attempts, it sets the backoff of the next one.
+	RetryCount int `json:"retryCount,omitempty"`
+
+	// DeliveryStartedAt is when the alert was redelivered, retries are bounded from this time instead of CreatedAt.
+	DeliveryStartedAt *time.Time `json:"deliveryStartedAt,omitempty"`
 }
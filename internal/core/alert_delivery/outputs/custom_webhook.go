@@ -0,0 +1,165 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	jsoniter "github.com/json-iterator/go"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+// CustomWebhookSecretPrefix is the prefix of the secrets the custom webhooks can reference,
+// the alert delivery can't read any other secret.
+const CustomWebhookSecretPrefix = "panther-webhooks/"
+
+// customWebhookData is what the templates of custom webhooks are rendered with.
+//
+// Every field of the alert can be referenced, e.g. {{.PolicyID}}, along with the fields below.
+type customWebhookData struct {
+	*alertmodels.Alert
+	// Title is the title of the alert, e.g. "New Alert: rule name"
+	Title string
+	// Message is the detailed message the other outputs send
+	Message string
+	// URL is the link to the alert in Panther
+	URL string
+}
+
+// ParseWebhookTemplate parses the template of the body or a header value of a custom webhook.
+//
+// The templates can use the "json" function to encode a value to JSON and the "secret" function
+// to read a secret from Secrets Manager, optionally a key of a JSON secret: {{secret "name" "key"}}.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Option("missingkey=error").Funcs(webhookFuncs(nil)).Parse(text)
+}
+
+// CustomWebhook sends an alert to a custom webhook, rendering its body and headers from the alert.
+func (client *OutputClient) CustomWebhook(
+	alert *alertmodels.Alert, config *outputmodels.CustomWebhookConfig) *AlertDeliveryError {
+
+	renderer := &webhookRenderer{
+		client: client,
+		data: &customWebhookData{
+			Alert:   alert,
+			Title:   generateAlertTitle(alert),
+			Message: generateDetailedAlertMessage(alert),
+			URL:     generateURL(alert),
+		},
+		secrets: make(map[string]string),
+	}
+
+	body, deliveryErr := renderer.render(*config.BodyTemplate)
+	if deliveryErr != nil {
+		return deliveryErr
+	}
+	headers := make(map[string]string, len(config.Headers))
+	for name, value := range config.Headers {
+		if headers[name], deliveryErr = renderer.render(aws.StringValue(value)); deliveryErr != nil {
+			return deliveryErr
+		}
+	}
+
+	postInput := &PostInput{
+		url:     *config.WebhookURL,
+		method:  aws.StringValue(config.Method),
+		headers: headers,
+		rawBody: []byte(body),
+	}
+	return client.httpWrapper.post(postInput)
+}
+
+// webhookRenderer renders the templates of one alert, reading each secret only once
+type webhookRenderer struct {
+	client  *OutputClient
+	data    *customWebhookData
+	secrets map[string]string
+	// secretErr is the error reading a secret, the delivery can be retried
+	secretErr error
+}
+
+func (r *webhookRenderer) render(text string) (string, *AlertDeliveryError) {
+	tmpl, err := ParseWebhookTemplate(text)
+	if err != nil {
+		return "", &AlertDeliveryError{Message: "invalid webhook template: " + err.Error(), Permanent: true}
+	}
+
+	var result bytes.Buffer
+	r.secretErr = nil
+	if err = tmpl.Funcs(webhookFuncs(r.getSecret)).Execute(&result, r.data); err != nil {
+		if r.secretErr != nil {
+			return "", &AlertDeliveryError{Message: "failed to read webhook secret: " + r.secretErr.Error()}
+		}
+		return "", &AlertDeliveryError{Message: "failed to render webhook template: " + err.Error(), Permanent: true}
+	}
+	return result.String(), nil
+}
+
+func (r *webhookRenderer) getSecret(secretID string) (string, error) {
+	if value, ok := r.secrets[secretID]; ok {
+		return value, nil
+	}
+	output, err := r.client.secretsClient.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)})
+	if err != nil {
+		r.secretErr = err
+		return "", err
+	}
+	r.secrets[secretID] = aws.StringValue(output.SecretString)
+	return r.secrets[secretID], nil
+}
+
+// webhookFuncs are the functions of the webhook templates, getSecret reads a secret by its name
+func webhookFuncs(getSecret func(string) (string, error)) template.FuncMap {
+	return template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			return jsoniter.MarshalToString(value)
+		},
+		"secret": func(name string, keys ...string) (string, error) {
+			if !strings.HasPrefix(name, CustomWebhookSecretPrefix) {
+				return "", errors.New("secret " + name + " isn't under " + CustomWebhookSecretPrefix)
+			}
+			if len(keys) > 1 {
+				return "", errors.New("a secret has at most one key")
+			}
+			if getSecret == nil {
+				return "", nil
+			}
+			value, err := getSecret(name)
+			if err != nil || len(keys) == 0 {
+				return value, err
+			}
+			var fields map[string]string
+			if err = jsoniter.UnmarshalFromString(value, &fields); err != nil {
+				return "", errors.New("secret " + name + " isn't a JSON object of strings")
+			}
+			field, ok := fields[keys[0]]
+			if !ok {
+				return "", errors.New("secret " + name + " has no key " + keys[0])
+			}
+			return field, nil
+		},
+	}
+}
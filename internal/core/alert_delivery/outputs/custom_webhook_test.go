@@ -0,0 +1,124 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+type mockSecretsClient struct {
+	secretsmanageriface.SecretsManagerAPI
+	mock.Mock
+}
+
+func (m *mockSecretsClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*secretsmanager.GetSecretValueOutput), args.Error(1)
+}
+
+var customWebhookAlert = &alertmodels.Alert{
+	AlertID:    aws.String("alertId"),
+	PolicyID:   aws.String("ruleId"),
+	PolicyName: aws.String("rule \"name\""),
+	Severity:   aws.String("HIGH"),
+	Type:       aws.String(alertmodels.RuleType),
+	CreatedAt:  aws.Time(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+}
+
+func TestCustomWebhookAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	secretsClient := &mockSecretsClient{}
+	client := &OutputClient{httpWrapper: httpWrapper, secretsClient: secretsClient}
+
+	config := &outputmodels.CustomWebhookConfig{
+		WebhookURL: aws.String("https://alerts.example.com/panther"),
+		Method:     aws.String("PUT"),
+		Headers: map[string]*string{
+			"Authorization": aws.String(`Bearer {{secret "panther-webhooks/example" "token"}}`),
+			"X-Alert-Id":    aws.String("{{.AlertID}}"),
+		},
+		BodyTemplate: aws.String(`{"title": {{json .Title}}, "severity": "{{.Severity}}"}`),
+	}
+
+	secretsClient.On("GetSecretValue", &secretsmanager.GetSecretValueInput{SecretId: aws.String("panther-webhooks/example")}).
+		Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String(`{"token": "secret-token"}`)}, nil).Once()
+	expectedPostInput := &PostInput{
+		url:    "https://alerts.example.com/panther",
+		method: "PUT",
+		headers: map[string]string{
+			"Authorization": "Bearer secret-token",
+			"X-Alert-Id":    "alertId",
+		},
+		rawBody: []byte(`{"title": "New Alert: rule \"name\"", "severity": "HIGH"}`),
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.CustomWebhook(customWebhookAlert, config))
+	httpWrapper.AssertExpectations(t)
+	secretsClient.AssertExpectations(t)
+}
+
+func TestCustomWebhookSecretOutsidePrefix(t *testing.T) {
+	client := &OutputClient{httpWrapper: &mockHTTPWrapper{}, secretsClient: &mockSecretsClient{}}
+	config := &outputmodels.CustomWebhookConfig{
+		WebhookURL:   aws.String("https://alerts.example.com/panther"),
+		BodyTemplate: aws.String(`{{secret "database-password"}}`),
+	}
+
+	result := client.CustomWebhook(customWebhookAlert, config)
+	require.NotNil(t, result)
+	assert.True(t, result.Permanent)
+}
+
+func TestCustomWebhookSecretError(t *testing.T) {
+	secretsClient := &mockSecretsClient{}
+	client := &OutputClient{httpWrapper: &mockHTTPWrapper{}, secretsClient: secretsClient}
+	config := &outputmodels.CustomWebhookConfig{
+		WebhookURL:   aws.String("https://alerts.example.com/panther"),
+		BodyTemplate: aws.String(`{{secret "panther-webhooks/example"}}`),
+	}
+	secretsClient.On("GetSecretValue", mock.Anything).
+		Return((*secretsmanager.GetSecretValueOutput)(nil), errors.New("throttled")).Once()
+
+	result := client.CustomWebhook(customWebhookAlert, config)
+	require.NotNil(t, result)
+	assert.False(t, result.Permanent)
+	secretsClient.AssertExpectations(t)
+}
+
+func TestParseWebhookTemplate(t *testing.T) {
+	_, err := ParseWebhookTemplate(`{"id": {{json .PolicyID}}, "token": "{{secret "panther-webhooks/token"}}"}`)
+	assert.NoError(t, err)
+	_, err = ParseWebhookTemplate(`{{.PolicyID`)
+	assert.Error(t, err)
+	_, err = ParseWebhookTemplate(`{{unknown .PolicyID}}`)
+	assert.Error(t, err)
+}
@@ -27,41 +27,75 @@ import (
 	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 )
 
+// The Adaptive Card colors of the alert severities, the closest ones to the colors in the Panther UI
+var msTeamsSeverityColors = map[string]string{
+	"CRITICAL": "dark",
+	"HIGH":     "attention",
+	"MEDIUM":   "warning",
+	"LOW":      "accent",
+	"INFO":     "good",
+}
+
 // MsTeams alert send an alert.
+//
+// The alert is an Adaptive Card message, see https://docs.microsoft.com/en-us/adaptive-cards/
 func (client *OutputClient) MsTeams(
 	alert *alertmodels.Alert, config *outputmodels.MsTeamsConfig) *AlertDeliveryError {
 
-	var tagsItem = aws.StringValueSlice(alert.Tags)
-
-	link := "[Click here to view in the Panther UI](" + policyURLPrefix + aws.StringValue(alert.PolicyID) + ").\n"
-	runBook := aws.StringValue(alert.Runbook)
-	ruleDescription := aws.StringValue(alert.PolicyDescription)
+	analysisType := "Policy"
+	if aws.StringValue(alert.Type) == alertmodels.RuleType {
+		analysisType = "Rule"
+	}
 	severity := aws.StringValue(alert.Severity)
-	tags := strings.Join(tagsItem, ", ")
 
-	msTeamsRequestBody := map[string]interface{}{
-		"@context": "http://schema.org/extensions",
-		"@type":    "MessageCard",
-		"text":     generateAlertTitle(alert),
-		"sections": []interface{}{
-			map[string]interface{}{
-				"facts": []interface{}{
-					map[string]string{"name": "Description", "value": ruleDescription},
-					map[string]string{"name": "Runbook", "value": runBook},
-					map[string]string{"name": "Severity", "value": severity},
-					map[string]string{"name": "Tags", "value": tags},
-				},
-				"text": link,
+	body := []interface{}{
+		map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   generateAlertTitle(alert),
+			"size":   "Large",
+			"weight": "Bolder",
+			"wrap":   true,
+		},
+		map[string]interface{}{
+			"type":    "TextBlock",
+			"text":    severity,
+			"color":   msTeamsSeverityColors[severity],
+			"weight":  "Bolder",
+			"spacing": "None",
+		},
+		map[string]interface{}{
+			"type": "FactSet",
+			"facts": []interface{}{
+				map[string]string{"title": analysisType, "value": aws.StringValue(alert.PolicyID)},
+				map[string]string{"title": "Runbook", "value": aws.StringValue(alert.Runbook)},
+				map[string]string{"title": "Tags", "value": strings.Join(aws.StringValueSlice(alert.Tags), ", ")},
 			},
 		},
-		"potentialAction": []interface{}{
+	}
+	if description := aws.StringValue(alert.PolicyDescription); description != "" {
+		body = append(body, map[string]interface{}{
+			"type": "TextBlock",
+			"text": description,
+			"wrap": true,
+		})
+	}
+
+	msTeamsRequestBody := map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
 			map[string]interface{}{
-				"@type": "OpenUri",
-				"name":  "Click here to view in the Panther UI",
-				"targets": []interface{}{
-					map[string]string{
-						"os":  "default",
-						"uri": generateURL(alert),
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.2",
+					"body":    body,
+					"actions": []interface{}{
+						map[string]interface{}{
+							"type":  "Action.OpenUrl",
+							"title": "Click here to view in the Panther UI",
+							"url":   generateURL(alert),
+						},
 					},
 				},
 			},
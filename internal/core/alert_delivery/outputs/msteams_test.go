@@ -23,6 +23,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -47,28 +49,44 @@ func TestMsTeamsAlert(t *testing.T) {
 	}
 
 	msTeamsPayload := map[string]interface{}{
-		"@context": "http://schema.org/extensions",
-		"@type":    "MessageCard",
-		"text":     "Policy Failure: policyName",
-		"sections": []interface{}{
+		"type": "message",
+		"attachments": []interface{}{
 			map[string]interface{}{
-				"facts": []interface{}{
-					map[string]string{"name": "Description", "value": ""},
-					map[string]string{"name": "Runbook", "value": ""},
-					map[string]string{"name": "Severity", "value": "INFO"},
-					map[string]string{"name": "Tags", "value": ""},
-				},
-				"text": "[Click here to view in the Panther UI](https://panther.io/policies/policyId).\n",
-			},
-		},
-		"potentialAction": []interface{}{
-			map[string]interface{}{
-				"@type": "OpenUri",
-				"name":  "Click here to view in the Panther UI",
-				"targets": []interface{}{
-					map[string]string{
-						"os":  "default",
-						"uri": "https://panther.io/policies/policyId",
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.2",
+					"body": []interface{}{
+						map[string]interface{}{
+							"type":   "TextBlock",
+							"text":   "Policy Failure: policyName",
+							"size":   "Large",
+							"weight": "Bolder",
+							"wrap":   true,
+						},
+						map[string]interface{}{
+							"type":    "TextBlock",
+							"text":    "INFO",
+							"color":   "good",
+							"weight":  "Bolder",
+							"spacing": "None",
+						},
+						map[string]interface{}{
+							"type": "FactSet",
+							"facts": []interface{}{
+								map[string]string{"title": "Policy", "value": "policyId"},
+								map[string]string{"title": "Runbook", "value": ""},
+								map[string]string{"title": "Tags", "value": ""},
+							},
+						},
+					},
+					"actions": []interface{}{
+						map[string]interface{}{
+							"type":  "Action.OpenUrl",
+							"title": "Click here to view in the Panther UI",
+							"url":   "https://panther.io/policies/policyId",
+						},
 					},
 				},
 			},
@@ -87,3 +105,36 @@ func TestMsTeamsAlert(t *testing.T) {
 	require.Nil(t, client.MsTeams(alert, msTeamConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestMsTeamsRuleAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		AlertID:           aws.String("alertId"),
+		PolicyID:          aws.String("ruleId"),
+		PolicyDescription: aws.String("description"),
+		PolicyName:        aws.String("ruleName"),
+		Runbook:           aws.String("runbook"),
+		Severity:          aws.String("HIGH"),
+		Tags:              aws.StringSlice([]string{"tag1", "tag2"}),
+		Type:              aws.String(alertmodels.RuleType),
+	}
+
+	httpWrapper.On("post", mock.Anything).Return((*AlertDeliveryError)(nil))
+	require.Nil(t, client.MsTeams(alert, msTeamConfig))
+
+	postInput := httpWrapper.Calls[0].Arguments.Get(0).(*PostInput)
+	card := postInput.body["attachments"].([]interface{})[0].(map[string]interface{})["content"].(map[string]interface{})
+	body := card["body"].([]interface{})
+	require.Len(t, body, 4)
+	assert.Equal(t, "New Alert: ruleName", body[0].(map[string]interface{})["text"])
+	assert.Equal(t, "attention", body[1].(map[string]interface{})["color"])
+	assert.Equal(t, []interface{}{
+		map[string]string{"title": "Rule", "value": "ruleId"},
+		map[string]string{"title": "Runbook", "value": "runbook"},
+		map[string]string{"title": "Tags", "value": "tag1, tag2"},
+	}, body[2].(map[string]interface{})["facts"])
+	assert.Equal(t, "description", body[3].(map[string]interface{})["text"])
+	assert.Equal(t, alertURLPrefix+"alertId", card["actions"].([]interface{})[0].(map[string]interface{})["url"])
+}
@@ -25,6 +25,8 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 
@@ -47,6 +49,10 @@ type PostInput struct {
 	url     string
 	body    map[string]interface{}
 	headers map[string]string
+	// method is POST unless it is set
+	method string
+	// rawBody is sent as it is instead of the JSON body, if it is set
+	rawBody []byte
 }
 
 // HTTPWrapperiface is the interface for our wrapper around Golang's http client
@@ -70,6 +76,8 @@ type API interface {
 	Sqs(*alertmodels.Alert, *outputmodels.SqsConfig) *AlertDeliveryError
 	Sns(*alertmodels.Alert, *outputmodels.SnsConfig) *AlertDeliveryError
 	Asana(*alertmodels.Alert, *outputmodels.AsanaConfig) *AlertDeliveryError
+	ServiceNow(*alertmodels.Alert, *outputmodels.ServiceNowConfig) *AlertDeliveryError
+	CustomWebhook(*alertmodels.Alert, *outputmodels.CustomWebhookConfig) *AlertDeliveryError
 }
 
 // OutputClient encapsulates the clients that allow sending alerts to multiple outputs
@@ -79,6 +87,8 @@ type OutputClient struct {
 	// Map from region -> client
 	sqsClients map[string]sqsiface.SQSAPI
 	snsClients map[string]snsiface.SNSAPI
+	// The secrets referenced by the custom webhooks
+	secretsClient secretsmanageriface.SecretsManagerAPI
 }
 
 // OutputClient must satisfy the API interface.
@@ -90,8 +100,9 @@ func New(sess *session.Session) *OutputClient {
 		session:     sess,
 		httpWrapper: &HTTPWrapper{httpClient: &http.Client{}},
 		// TODO Lazy initialization of clients
-		sqsClients: make(map[string]sqsiface.SQSAPI),
-		snsClients: make(map[string]snsiface.SNSAPI),
+		sqsClients:    make(map[string]sqsiface.SQSAPI),
+		snsClients:    make(map[string]snsiface.SNSAPI),
+		secretsClient: secretsmanager.New(sess),
 	}
 }
 
@@ -30,14 +30,21 @@ const (
 	AuthorizationHTTPHeader = "Authorization"
 )
 
-// post sends a JSON body to an endpoint.
+// post sends a JSON body to an endpoint, or the raw body of the input with its method.
 func (client *HTTPWrapper) post(input *PostInput) *AlertDeliveryError {
-	payload, err := jsoniter.Marshal(input.body)
-	if err != nil {
-		return &AlertDeliveryError{Message: "json marshal error: " + err.Error(), Permanent: true}
+	payload := input.rawBody
+	if payload == nil {
+		var err error
+		if payload, err = jsoniter.Marshal(input.body); err != nil {
+			return &AlertDeliveryError{Message: "json marshal error: " + err.Error(), Permanent: true}
+		}
 	}
 
-	request, err := http.NewRequest("POST", input.url, bytes.NewBuffer(payload))
+	method := input.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	request, err := http.NewRequest(method, input.url, bytes.NewBuffer(payload))
 	if err != nil {
 		return &AlertDeliveryError{Message: "http request error: " + err.Error(), Permanent: true}
 	}
@@ -30,9 +30,10 @@ import (
 
 type mockHTTPClient struct {
 	HTTPiface
-	statusCode   int
-	requestError bool
-	requestBody  string // Request body is saved here for tests to verify
+	statusCode    int
+	requestError  bool
+	requestBody   string // Request body is saved here for tests to verify
+	requestMethod string
 }
 
 var requestEndpoint = "https://runpanther.io"
@@ -46,6 +47,7 @@ func (m *mockHTTPClient) Do(request *http.Request) (*http.Response, error) {
 		panic(err)
 	}
 	m.requestBody = string(requestBytes)
+	m.requestMethod = request.Method
 
 	responseBody := ioutil.NopCloser(bytes.NewReader([]byte("response")))
 	return &http.Response{Body: responseBody, StatusCode: m.statusCode}, nil
@@ -96,3 +98,16 @@ func TestPostCreated(t *testing.T) {
 	}
 	assert.Nil(t, c.post(postInput))
 }
+
+func TestPostRawBody(t *testing.T) {
+	httpClient := &mockHTTPClient{statusCode: http.StatusOK}
+	c := &HTTPWrapper{httpClient: httpClient}
+	postInput := &PostInput{
+		url:     requestEndpoint,
+		method:  http.MethodPut,
+		rawBody: []byte("key=value"),
+	}
+	assert.Nil(t, c.post(postInput))
+	assert.Equal(t, "key=value", httpClient.requestBody)
+	assert.Equal(t, http.MethodPut, httpClient.requestMethod)
+}
@@ -0,0 +1,97 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+const (
+	serviceNowDefaultTable = "incident"
+	serviceNowTableAPIPath = "/api/now/table/"
+
+	// ServiceNowCorrelationDisplay is the source of the incidents correlated to Panther alerts
+	ServiceNowCorrelationDisplay = "Panther"
+)
+
+// The ServiceNow urgency of each alert severity, unless the output has its own mapping: 1 (High), 2 (Medium) or 3 (Low)
+var serviceNowDefaultUrgencies = map[string]int{
+	"CRITICAL": 1,
+	"HIGH":     1,
+	"MEDIUM":   2,
+	"LOW":      3,
+	"INFO":     3,
+}
+
+// ServiceNow opens an incident for an alert.
+//
+// The incidents of rule alerts are correlated to the alerts by their alert ID.
+func (client *OutputClient) ServiceNow(
+	alert *alertmodels.Alert, config *outputmodels.ServiceNowConfig) *AlertDeliveryError {
+
+	incident := map[string]interface{}{
+		"short_description": generateAlertTitle(alert),
+		"description":       generateDetailedAlertMessage(alert),
+		"urgency":           strconv.Itoa(serviceNowUrgency(aws.StringValue(alert.Severity), config)),
+	}
+	if alert.AlertID != nil {
+		incident["correlation_id"] = *alert.AlertID
+		incident["correlation_display"] = ServiceNowCorrelationDisplay
+	}
+
+	postInput := &PostInput{
+		url:     ServiceNowTableURL(config),
+		body:    incident,
+		headers: map[string]string{AuthorizationHTTPHeader: ServiceNowAuthorization(config)},
+	}
+	return client.httpWrapper.post(postInput)
+}
+
+// ServiceNowTableURL returns the URL of the Table API of the incident table of the output
+func ServiceNowTableURL(config *outputmodels.ServiceNowConfig) string {
+	table := serviceNowDefaultTable
+	if aws.StringValue(config.Table) != "" {
+		table = *config.Table
+	}
+	return strings.TrimSuffix(*config.InstanceURL, "/") + serviceNowTableAPIPath + url.PathEscape(table)
+}
+
+// ServiceNowAuthorization returns the Authorization header of the ServiceNow user of the output
+func ServiceNowAuthorization(config *outputmodels.ServiceNowConfig) string {
+	auth := *config.UserName + ":" + *config.Password
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+}
+
+func serviceNowUrgency(severity string, config *outputmodels.ServiceNowConfig) int {
+	if urgency := config.UrgencyMapping[severity]; urgency != nil {
+		return *urgency
+	}
+	if urgency, ok := serviceNowDefaultUrgencies[severity]; ok {
+		return urgency
+	}
+	return serviceNowDefaultUrgencies["INFO"]
+}
@@ -0,0 +1,112 @@
+package outputs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+)
+
+var serviceNowConfig = &outputmodels.ServiceNowConfig{
+	InstanceURL:   aws.String("https://example.service-now.com/"),
+	UserName:      aws.String("panther"),
+	Password:      aws.String("password"),
+	CallbackToken: aws.String("callback-token-callback-token"),
+}
+
+func TestServiceNowRuleAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	createdAtTime, err := time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	require.NoError(t, err)
+	alert := &alertmodels.Alert{
+		AlertID:    aws.String("alertId"),
+		PolicyID:   aws.String("ruleId"),
+		CreatedAt:  &createdAtTime,
+		OutputIDs:  aws.StringSlice([]string{"output-id"}),
+		PolicyName: aws.String("rule_name"),
+		Severity:   aws.String("HIGH"),
+		Type:       aws.String(alertmodels.RuleType),
+	}
+
+	expectedPostInput := &PostInput{
+		url: "https://example.service-now.com/api/now/table/incident",
+		body: map[string]interface{}{
+			"short_description":   "New Alert: rule_name",
+			"description":         generateDetailedAlertMessage(alert),
+			"urgency":             "1",
+			"correlation_id":      "alertId",
+			"correlation_display": "Panther",
+		},
+		headers: map[string]string{
+			// base64 of "panther:password"
+			AuthorizationHTTPHeader: "Basic cGFudGhlcjpwYXNzd29yZA==",
+		},
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.ServiceNow(alert, serviceNowConfig))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestServiceNowPolicyAlertCustomTable(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	alert := &alertmodels.Alert{
+		PolicyID:   aws.String("policyId"),
+		OutputIDs:  aws.StringSlice([]string{"output-id"}),
+		PolicyName: aws.String("policy_name"),
+		Severity:   aws.String("LOW"),
+	}
+	config := *serviceNowConfig
+	config.Table = aws.String("sn_si_incident")
+	config.UrgencyMapping = map[string]*int{"LOW": aws.Int(2)}
+
+	expectedPostInput := &PostInput{
+		url: "https://example.service-now.com/api/now/table/sn_si_incident",
+		body: map[string]interface{}{
+			"short_description": "Policy Failure: policy_name",
+			"description":       generateDetailedAlertMessage(alert),
+			"urgency":           "2",
+		},
+		headers: map[string]string{AuthorizationHTTPHeader: "Basic cGFudGhlcjpwYXNzd29yZA=="},
+	}
+	httpWrapper.On("post", expectedPostInput).Return((*AlertDeliveryError)(nil))
+
+	require.Nil(t, client.ServiceNow(alert, &config))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestServiceNowUrgency(t *testing.T) {
+	config := &outputmodels.ServiceNowConfig{UrgencyMapping: map[string]*int{"CRITICAL": aws.Int(2)}}
+	assert.Equal(t, 2, serviceNowUrgency("CRITICAL", config))
+	assert.Equal(t, 1, serviceNowUrgency("HIGH", config))
+	assert.Equal(t, 2, serviceNowUrgency("MEDIUM", config))
+	assert.Equal(t, 3, serviceNowUrgency("INFO", config))
+	assert.Equal(t, 3, serviceNowUrgency("UNKNOWN", config))
+}
@@ -154,10 +154,8 @@ func extractZipFile(input *models.BulkUpload) (map[models.ID]*tableItem, error)
 		return nil, fmt.Errorf("zipReader failed: %s", err)
 	}
 
-	policyBodies := make(map[string]models.Body) // map base file name to contents
-	result := make(map[models.ID]*tableItem)
-
-	// Process each file
+	// Read each file
+	files := make(map[string][]byte, len(zipReader.File))
 	for _, zipFile := range zipReader.File {
 		if strings.HasSuffix(zipFile.Name, "/") {
 			continue // skip directories (we will see their nested files next)
@@ -167,24 +165,37 @@ func extractZipFile(input *models.BulkUpload) (map[models.ID]*tableItem, error)
 		if err != nil {
 			return nil, fmt.Errorf("file extraction failed: %s: %s", zipFile.Name, err)
 		}
+		files[zipFile.Name] = unzippedBytes
+	}
+
+	return parseAnalysisFiles(files, input.UserID)
+}
+
+// Parse the policy and rule specs and their Python bodies, keyed by file name.
+func parseAnalysisFiles(files map[string][]byte, userID models.UserID) (map[models.ID]*tableItem, error) {
+	policyBodies := make(map[string]models.Body) // map base file name to contents
+	result := make(map[models.ID]*tableItem)
 
-		if strings.Contains(zipFile.Name, "__pycache__") {
+	// Process each file
+	for fileName, contents := range files {
+		if strings.Contains(fileName, "__pycache__") {
 			continue
 		}
 
+		var err error
 		var config analysis.Config
 
-		switch strings.ToLower(filepath.Ext(zipFile.Name)) {
+		switch strings.ToLower(filepath.Ext(fileName)) {
 		case ".py":
 			// Store the Python body to be referenced later
-			policyBodies[filepath.Base(zipFile.Name)] = models.Body(unzippedBytes)
+			policyBodies[filepath.Base(fileName)] = models.Body(contents)
 			continue
 		case ".json":
-			err = jsoniter.Unmarshal(unzippedBytes, &config)
+			err = jsoniter.Unmarshal(contents, &config)
 		case ".yml", ".yaml":
-			err = yaml.Unmarshal(unzippedBytes, &config)
+			err = yaml.Unmarshal(contents, &config)
 		default:
-			zap.L().Debug("skipped unsupported file", zap.String("fileName", zipFile.Name))
+			zap.L().Debug("skipped unsupported file", zap.String("fileName", fileName))
 		}
 
 		if err != nil {
@@ -193,24 +204,27 @@ func extractZipFile(input *models.BulkUpload) (map[models.ID]*tableItem, error)
 
 		// Map the Config struct fields over to the fields we need to store in Dynamo
 		policy := tableItem{
+			AutoRemediationApproval:   models.AutoRemediationApproval(config.AutoRemediationApproval),
 			AutoRemediationID:         models.AutoRemediationID(config.AutoRemediationID),
 			AutoRemediationParameters: models.AutoRemediationParameters(config.AutoRemediationParameters),
 
 			// Use filename as placeholder for the body which we lookup later
 			Body: models.Body(config.Filename),
 
-			Description:   models.Description(config.Description),
-			DisplayName:   models.DisplayName(config.DisplayName),
-			Enabled:       models.Enabled(config.Enabled),
-			ID:            models.ID(config.PolicyID),
-			Reference:     models.Reference(config.Reference),
-			ResourceTypes: models.TypeSet(config.ResourceTypes),
-			Runbook:       models.Runbook(config.Runbook),
-			Severity:      models.Severity(strings.ToUpper(config.Severity)),
-			Suppressions:  models.Suppressions(config.Suppressions),
-			Tags:          config.Tags,
-			Tests:         make([]*models.UnitTest, len(config.Tests)),
-			Type:          strings.ToUpper(config.AnalysisType),
+			DedupKey:           models.DedupKey(config.DedupKey),
+			DedupPeriodMinutes: models.DedupPeriodMinutes(config.DedupPeriodMinutes),
+			Description:        models.Description(config.Description),
+			DisplayName:        models.DisplayName(config.DisplayName),
+			Enabled:            models.Enabled(config.Enabled),
+			ID:                 models.ID(config.PolicyID),
+			Reference:          models.Reference(config.Reference),
+			ResourceTypes:      models.TypeSet(config.ResourceTypes),
+			Runbook:            models.Runbook(config.Runbook),
+			Severity:           models.Severity(strings.ToUpper(config.Severity)),
+			Suppressions:       models.Suppressions(config.Suppressions),
+			Tags:               config.Tags,
+			Tests:              make([]*models.UnitTest, len(config.Tests)),
+			Type:               strings.ToUpper(config.AnalysisType),
 		}
 
 		for i, test := range config.Tests {
@@ -237,7 +251,7 @@ func extractZipFile(input *models.BulkUpload) (map[models.ID]*tableItem, error)
 	for _, policy := range result {
 		if body, ok := policyBodies[string(policy.Body)]; ok {
 			policy.Body = body
-			if err := validateUploadedPolicy(policy, input.UserID); err != nil {
+			if err := validateUploadedPolicy(policy, userID); err != nil {
 				return nil, err
 			}
 		} else {
@@ -277,5 +291,15 @@ func validateUploadedPolicy(item *tableItem, userID models.UserID) error {
 	if err := policy.Validate(nil); err != nil {
 		return fmt.Errorf("policy ID %s is invalid: %s", policy.ID, err)
 	}
+
+	// The Policy model has no dedup settings, a missing dedup period is the default one
+	if err := item.DedupKey.Validate(nil); err != nil {
+		return fmt.Errorf("policy ID %s is invalid: %s", policy.ID, err)
+	}
+	if item.DedupPeriodMinutes != 0 {
+		if err := item.DedupPeriodMinutes.Validate(nil); err != nil {
+			return fmt.Errorf("policy ID %s is invalid: %s", policy.ID, err)
+		}
+	}
 	return nil
 }
@@ -50,6 +50,8 @@ type envConfig struct {
 	Bucket            string `required:"true" split_words:"true"`
 	ComplianceAPIHost string `required:"true" split_words:"true"`
 	ComplianceAPIPath string `required:"true" split_words:"true"`
+	PackSigningKeys   string `split_words:"true"`
+	PackTable         string `required:"true" split_words:"true"`
 	RulesEngine       string `required:"true" split_words:"true"`
 	PolicyEngine      string `required:"true" split_words:"true"`
 	ResourceQueueURL  string `required:"true" split_words:"true"`
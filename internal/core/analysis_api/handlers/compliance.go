@@ -166,9 +166,10 @@ func updateComplianceStatus(oldItem, newItem *tableItem) error {
 	// At this point, we know the compliance value (PASS/FAIL) won't change for any (policy, resource) pairs.
 	// In other words, we don't need to re-evaluate the policy with the Python engine.
 	//
-	// But the compliance table has columns for severity and suppression -
-	// if either of those changed, we can update the compliance API directly.
-	if oldItem.Severity != newItem.Severity || !setEquality(oldItem.Suppressions, newItem.Suppressions) {
+	// But the compliance table has columns for severity, suppression and tags (which map the policy to
+	// compliance framework controls) - if any of those changed, we can update the compliance API directly.
+	if oldItem.Severity != newItem.Severity || !setEquality(oldItem.Suppressions, newItem.Suppressions) ||
+		!setEquality(oldItem.Tags, newItem.Tags) {
 		return updateComplianceMetadata(newItem)
 	}
 
@@ -178,7 +179,7 @@ func updateComplianceStatus(oldItem, newItem *tableItem) error {
 
 // Update compliance status entries directly.
 //
-// This is used when only the policy severity / suppressions change - we don't need to rescan
+// This is used when only the policy severity / suppressions / tags change - we don't need to rescan
 // all affected resources in this case.
 func updateComplianceMetadata(policy *tableItem) error {
 	zap.L().Info("updating compliance status entry",
@@ -189,6 +190,7 @@ func updateComplianceMetadata(policy *tableItem) error {
 			PolicyID:     compliancemodels.PolicyID(policy.ID),
 			Severity:     compliancemodels.PolicySeverity(policy.Severity),
 			Suppressions: compliancemodels.IgnoreSet(policy.Suppressions),
+			Tags:         compliancemodels.PolicyTags(policy.Tags),
 		},
 		HTTPClient: httpClient,
 	})
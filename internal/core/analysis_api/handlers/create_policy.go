@@ -37,6 +37,7 @@ func CreatePolicy(request *events.APIGatewayProxyRequest) *events.APIGatewayProx
 	}
 
 	item := &tableItem{
+		AutoRemediationApproval:   input.AutoRemediationApproval,
 		AutoRemediationID:         input.AutoRemediationID,
 		AutoRemediationParameters: input.AutoRemediationParameters,
 		Body:                      input.Body,
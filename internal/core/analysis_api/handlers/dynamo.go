@@ -38,6 +38,9 @@ const (
 	typePolicy       = "POLICY"
 	typeRule         = "RULE"
 	maxDynamoBackoff = 30 * time.Second
+
+	// Rules created before the dedup period was configurable merged their alerts per hour
+	defaultDedupPeriodMinutes = 60
 )
 
 // The policy struct stored in Dynamo isn't quite the same as the policy struct returned in the API.
@@ -46,11 +49,14 @@ const (
 // optional values can be omitted from the table if they are empty,
 // and extra fields are added for more efficient filtering.
 type tableItem struct {
+	AutoRemediationApproval   models.AutoRemediationApproval   `json:"autoRemediationApproval,omitempty"`
 	AutoRemediationID         models.AutoRemediationID         `json:"autoRemediationId,omitempty"`
 	AutoRemediationParameters models.AutoRemediationParameters `json:"autoRemediationParameters,omitempty"`
 	Body                      models.Body                      `json:"body"`
 	CreatedAt                 models.ModifyTime                `json:"createdAt"`
 	CreatedBy                 models.UserID                    `json:"createdBy"`
+	DedupKey                  models.DedupKey                  `json:"dedupKey,omitempty"`
+	DedupPeriodMinutes        models.DedupPeriodMinutes        `json:"dedupPeriodMinutes,omitempty"`
 	Description               models.Description               `json:"description,omitempty"`
 	DisplayName               models.DisplayName               `json:"displayName,omitempty"`
 	Enabled                   models.Enabled                   `json:"enabled"`
@@ -89,10 +95,19 @@ func (r *tableItem) normalize() {
 	sortCaseInsensitive(r.Tags)
 }
 
+// The dedup period of a rule, rules stored before it was configurable have none.
+func (r *tableItem) dedupPeriod() models.DedupPeriodMinutes {
+	if r.DedupPeriodMinutes == 0 {
+		return defaultDedupPeriodMinutes
+	}
+	return r.DedupPeriodMinutes
+}
+
 // Policy converts a Dynamo row into a Policy external model.
 func (r *tableItem) Policy(status models.ComplianceStatus) *models.Policy {
 	r.normalize()
 	result := &models.Policy{
+		AutoRemediationApproval:   r.AutoRemediationApproval,
 		AutoRemediationID:         r.AutoRemediationID,
 		AutoRemediationParameters: r.AutoRemediationParameters,
 		Body:                      r.Body,
@@ -142,22 +157,24 @@ func (r *tableItem) PolicySummary(status models.ComplianceStatus) *models.Policy
 func (r *tableItem) Rule() *models.Rule {
 	r.normalize()
 	result := &models.Rule{
-		Body:           r.Body,
-		CreatedAt:      r.CreatedAt,
-		CreatedBy:      r.CreatedBy,
-		Description:    r.Description,
-		DisplayName:    r.DisplayName,
-		Enabled:        r.Enabled,
-		ID:             r.ID,
-		LastModified:   r.LastModified,
-		LastModifiedBy: r.LastModifiedBy,
-		LogTypes:       r.ResourceTypes,
-		Reference:      r.Reference,
-		Runbook:        r.Runbook,
-		Severity:       r.Severity,
-		Tags:           r.Tags,
-		Tests:          r.Tests,
-		VersionID:      r.VersionID,
+		Body:               r.Body,
+		CreatedAt:          r.CreatedAt,
+		CreatedBy:          r.CreatedBy,
+		DedupKey:           r.DedupKey,
+		DedupPeriodMinutes: r.dedupPeriod(),
+		Description:        r.Description,
+		DisplayName:        r.DisplayName,
+		Enabled:            r.Enabled,
+		ID:                 r.ID,
+		LastModified:       r.LastModified,
+		LastModifiedBy:     r.LastModifiedBy,
+		LogTypes:           r.ResourceTypes,
+		Reference:          r.Reference,
+		Runbook:            r.Runbook,
+		Severity:           r.Severity,
+		Tags:               r.Tags,
+		Tests:              r.Tests,
+		VersionID:          r.VersionID,
 	}
 	gatewayapi.ReplaceMapSliceNils(result)
 	return result
@@ -327,3 +344,76 @@ func scanPages(input *dynamodb.ScanInput, handler func(*tableItem) error) error
 
 	return nil
 }
+
+// Load a pack from the packs table.
+//
+// Returns (nil, nil) if the pack isn't installed.
+func dynamoGetPack(packID models.PackID) (*packItem, error) {
+	response, err := dynamoClient.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(string(packID))},
+		},
+		TableName: &env.PackTable,
+	})
+	if err != nil {
+		zap.L().Error("dynamoClient.GetItem failed", zap.Error(err))
+		return nil, err
+	}
+
+	if len(response.Item) == 0 {
+		return nil, nil
+	}
+
+	var pack packItem
+	if err = dynamodbattribute.UnmarshalMap(response.Item, &pack); err != nil {
+		zap.L().Error("dynamodbattribute.UnmarshalMap failed", zap.Error(err))
+		return nil, err
+	}
+
+	return &pack, nil
+}
+
+// Write a single pack to the packs table.
+func dynamoPutPack(pack *packItem) error {
+	body, err := dynamodbattribute.MarshalMap(pack)
+	if err != nil {
+		zap.L().Error("dynamodbattribute.MarshalMap failed", zap.Error(err))
+		return err
+	}
+
+	if _, err = dynamoClient.PutItem(&dynamodb.PutItemInput{Item: body, TableName: &env.PackTable}); err != nil {
+		zap.L().Error("dynamoClient.PutItem failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Load all the installed packs (there are only a handful).
+func scanPacks() ([]*packItem, error) {
+	var result []*packItem
+	var unmarshalErr error
+
+	err := dynamoClient.ScanPages(&dynamodb.ScanInput{TableName: &env.PackTable},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var packs []*packItem
+			if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &packs); unmarshalErr != nil {
+				return false // stop paginating
+			}
+			result = append(result, packs...)
+			return true // keep paging
+		})
+
+	if unmarshalErr != nil {
+		zap.L().Error("dynamodbattribute.UnmarshalListOfMaps failed", zap.Error(unmarshalErr))
+		return nil, unmarshalErr
+	}
+
+	if err != nil {
+		zap.L().Error("dynamoClient.ScanPages failed", zap.Error(err))
+		return nil, err
+	}
+
+	return result, nil
+}
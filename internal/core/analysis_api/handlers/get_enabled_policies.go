@@ -42,14 +42,20 @@ func GetEnabledPolicies(request *events.APIGatewayProxyRequest) *events.APIGatew
 
 	policies := make([]*models.EnabledPolicy, 0, 100)
 	err = scanPages(scanInput, func(policy *tableItem) error {
-		policies = append(policies, &models.EnabledPolicy{
+		enabled := &models.EnabledPolicy{
 			Body:          policy.Body,
 			ID:            policy.ID,
 			ResourceTypes: policy.ResourceTypes,
 			Severity:      policy.Severity,
 			Suppressions:  policy.Suppressions,
+			Tags:          policy.Tags,
 			VersionID:     policy.VersionID,
-		})
+		}
+		if policy.Type == typeRule {
+			enabled.DedupKey = policy.DedupKey
+			enabled.DedupPeriodMinutes = policy.dedupPeriod()
+		}
+		policies = append(policies, enabled)
 		return nil
 	})
 	if err != nil {
@@ -72,12 +78,16 @@ func buildEnabledScan(ruleType string) (*dynamodb.ScanInput, error) {
 	filter := expression.Equal(expression.Name("enabled"), expression.Value(true))
 	filter = filter.And(expression.Equal(expression.Name("type"), expression.Value(ruleType)))
 	projection := expression.NamesList(
-		// does not include unit tests, last modified, org id, reference, tags, etc
+		// does not include unit tests, last modified, org id, reference, etc
 		expression.Name("body"),
+		expression.Name("dedupKey"),
+		expression.Name("dedupPeriodMinutes"),
 		expression.Name("id"),
 		expression.Name("resourceTypes"),
 		expression.Name("severity"),
 		expression.Name("suppressions"),
+		expression.Name("tags"),
+		expression.Name("type"),
 		expression.Name("versionId"),
 	)
 
@@ -35,6 +35,7 @@ func ModifyPolicy(request *events.APIGatewayProxyRequest) *events.APIGatewayProx
 	}
 
 	item := &tableItem{
+		AutoRemediationApproval:   input.AutoRemediationApproval,
 		AutoRemediationID:         input.AutoRemediationID,
 		AutoRemediationParameters: input.AutoRemediationParameters,
 		Body:                      input.Body,
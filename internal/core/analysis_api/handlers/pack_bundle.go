@@ -0,0 +1,284 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/api/gateway/analysis/models"
+)
+
+const (
+	packManifestFile  = "pack.yml"
+	packSignatureFile = "pack.yml.sig"
+
+	// The max size of a downloaded bundle, it's held in memory while it's unzipped
+	maxPackBundleSize = 50 * 1024 * 1024
+)
+
+var packDownloadClient = &http.Client{Timeout: 30 * time.Second}
+
+// The manifest at the root of the bundle of a detection pack.
+//
+// Only the files listed in the manifest are installed, the signature of the manifest
+// covers each of them through its SHA256 hash.
+type packManifest struct {
+	Name        string            `yaml:"Name"`
+	Version     string            `yaml:"Version"`
+	Description string            `yaml:"Description"`
+	Files       map[string]string `yaml:"Files"` // relative path => hex SHA256 of its contents
+}
+
+// A bundle whose signature and file hashes have been verified.
+type packBundle struct {
+	manifest *packManifest
+	files    map[string][]byte // relative path => contents
+}
+
+// Download and verify the bundle of a detection pack.
+func loadPackBundle(source models.PackSource) (*packBundle, error) {
+	content, err := downloadPackBundle(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %s", source, err)
+	}
+
+	keys, err := packSigningKeys()
+	if err != nil {
+		return nil, err
+	}
+	return readPackBundle(content, keys)
+}
+
+func downloadPackBundle(source string) ([]byte, error) {
+	sourceURL, err := url.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	switch sourceURL.Scheme {
+	case "s3":
+		response, err := s3Client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(sourceURL.Host),
+			Key:    aws.String(strings.TrimPrefix(sourceURL.Path, "/")),
+		})
+		if err != nil {
+			zap.L().Error("s3Client.GetObject failed", zap.Error(err))
+			return nil, err
+		}
+		body = response.Body
+	case "https":
+		response, err := packDownloadClient.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			_ = response.Body.Close()
+			return nil, fmt.Errorf("unexpected status %s", response.Status)
+		}
+		body = response.Body
+	default:
+		return nil, fmt.Errorf("unsupported scheme %s", sourceURL.Scheme)
+	}
+
+	defer func() {
+		if err := body.Close(); err != nil {
+			zap.L().Error("error closing pack bundle", zap.Error(err))
+		}
+	}()
+	content, err := ioutil.ReadAll(io.LimitReader(body, maxPackBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxPackBundleSize {
+		return nil, fmt.Errorf("bundle is larger than %d bytes", maxPackBundleSize)
+	}
+	return content, nil
+}
+
+// The public keys trusted to sign packs (comma-separated base64 Ed25519 keys).
+func packSigningKeys() ([]ed25519.PublicKey, error) {
+	var result []ed25519.PublicKey
+	for _, encoded := range strings.Split(env.PackSigningKeys, ",") {
+		encoded = strings.TrimSpace(encoded)
+		if encoded == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			zap.L().Error("invalid pack signing key", zap.String("key", encoded))
+			return nil, errors.New("invalid pack signing key")
+		}
+		result = append(result, key)
+	}
+
+	if len(result) == 0 {
+		return nil, errors.New("no pack signing keys are configured")
+	}
+	return result, nil
+}
+
+// Unzip a bundle and verify its manifest.
+//
+// The manifest is at the root of the zip file or in a single top-level directory
+// (e.g. the archive of a release of a Git repository), the paths of the files are relative to it.
+func readPackBundle(content []byte, keys []ed25519.PublicKey) (*packBundle, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("zipReader failed: %s", err)
+	}
+
+	root := ""
+	found := false
+	for _, zipFile := range zipReader.File {
+		if path.Base(zipFile.Name) != packManifestFile || strings.Count(zipFile.Name, "/") > 1 {
+			continue
+		}
+		if found {
+			return nil, fmt.Errorf("multiple %s manifests", packManifestFile)
+		}
+		root, found = strings.TrimSuffix(zipFile.Name, packManifestFile), true
+	}
+	if !found {
+		return nil, fmt.Errorf("%s manifest not found", packManifestFile)
+	}
+
+	files := make(map[string][]byte, len(zipReader.File))
+	for _, zipFile := range zipReader.File {
+		if strings.HasSuffix(zipFile.Name, "/") || !strings.HasPrefix(zipFile.Name, root) {
+			continue
+		}
+
+		unzippedBytes, err := readZipFile(zipFile)
+		if err != nil {
+			return nil, fmt.Errorf("file extraction failed: %s: %s", zipFile.Name, err)
+		}
+		files[strings.TrimPrefix(zipFile.Name, root)] = unzippedBytes
+	}
+
+	rawManifest := files[packManifestFile]
+	if err := verifyPackSignature(rawManifest, files[packSignatureFile], keys); err != nil {
+		return nil, err
+	}
+
+	var manifest packManifest
+	if err := yaml.Unmarshal(rawManifest, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s manifest: %s", packManifestFile, err)
+	}
+	if err := models.PackID(manifest.Name).Validate(nil); err != nil {
+		return nil, fmt.Errorf("invalid pack name %q: %s", manifest.Name, err)
+	}
+	if manifest.Version == "" {
+		return nil, fmt.Errorf("pack %s has no version", manifest.Name)
+	}
+
+	result := &packBundle{manifest: &manifest, files: make(map[string][]byte, len(manifest.Files))}
+	for name, digest := range manifest.Files {
+		contents, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("file %s of the manifest is missing", name)
+		}
+		hash := sha256.Sum256(contents)
+		if !strings.EqualFold(hex.EncodeToString(hash[:]), digest) {
+			return nil, fmt.Errorf("file %s doesn't match its hash in the manifest", name)
+		}
+		result.files[name] = contents
+	}
+	return result, nil
+}
+
+// Verify the base64 signature of the manifest with any of the trusted keys.
+func verifyPackSignature(manifest, signature []byte, keys []ed25519.PublicKey) error {
+	if len(signature) == 0 {
+		return fmt.Errorf("%s signature not found", packSignatureFile)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid %s signature: %s", packSignatureFile, err)
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, manifest, decoded) {
+			return nil
+		}
+	}
+	return errors.New("the manifest is not signed by a trusted key")
+}
+
+// Compare two dotted version numbers (e.g. "v1.10.2"), returning -1, 0 or 1.
+//
+// Parts which are not numbers are compared as strings.
+func compareVersions(first, second string) int {
+	firstParts := strings.Split(strings.TrimPrefix(first, "v"), ".")
+	secondParts := strings.Split(strings.TrimPrefix(second, "v"), ".")
+
+	for i := 0; i < len(firstParts) || i < len(secondParts); i++ {
+		var x, y string
+		if i < len(firstParts) {
+			x = firstParts[i]
+		}
+		if i < len(secondParts) {
+			y = secondParts[i]
+		}
+
+		xNum, xErr := strconv.Atoi(defaultVersionPart(x))
+		yNum, yErr := strconv.Atoi(defaultVersionPart(y))
+		switch {
+		case xErr == nil && yErr == nil && xNum != yNum:
+			if xNum < yNum {
+				return -1
+			}
+			return 1
+		case (xErr != nil || yErr != nil) && x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Missing parts of a version are zeroes, so "1.2" is the same as "1.2.0"
+func defaultVersionPart(part string) string {
+	if part == "" {
+		return "0"
+	}
+	return part
+}
@@ -0,0 +1,125 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	testPackRule     = "AnalysisType: rule\nFilename: rule.py\nPolicyID: Pack.Rule\n"
+	testPackRuleBody = "def rule(event): return True\n"
+)
+
+// Zip the files of a pack with its signed manifest under the given directory
+func testPackZip(t *testing.T, dir string, key ed25519.PrivateKey, manifest *packManifest, files map[string]string) []byte {
+	rawManifest, err := yaml.Marshal(manifest)
+	require.NoError(t, err)
+	files[packManifestFile] = string(rawManifest)
+	files[packSignatureFile] = base64.StdEncoding.EncodeToString(ed25519.Sign(key, rawManifest))
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for name, contents := range files {
+		fileWriter, err := writer.Create(dir + name)
+		require.NoError(t, err)
+		_, err = fileWriter.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func testPackManifest(files map[string]string) *packManifest {
+	result := &packManifest{Name: "aws-pack", Version: "1.2.0", Files: make(map[string]string)}
+	for name, contents := range files {
+		hash := sha256.Sum256([]byte(contents))
+		result.Files[name] = hex.EncodeToString(hash[:])
+	}
+	return result
+}
+
+func TestReadPackBundle(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	files := map[string]string{"rules/rule.yml": testPackRule, "rules/rule.py": testPackRuleBody}
+	manifest := testPackManifest(files)
+	files["README.md"] = "not listed in the manifest"
+
+	// The archive of a release has a top-level directory
+	for _, dir := range []string{"", "panther-analysis-1.2.0/"} {
+		content := testPackZip(t, dir, private, manifest, files)
+		bundle, err := readPackBundle(content, []ed25519.PublicKey{public})
+		require.NoError(t, err)
+		assert.Equal(t, manifest, bundle.manifest)
+		assert.Equal(t, map[string][]byte{
+			"rules/rule.yml": []byte(testPackRule),
+			"rules/rule.py":  []byte(testPackRuleBody),
+		}, bundle.files)
+	}
+}
+
+func TestReadPackBundleUntrustedKey(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	files := map[string]string{"rule.yml": testPackRule}
+
+	content := testPackZip(t, "", private, testPackManifest(files), files)
+	_, err = readPackBundle(content, []ed25519.PublicKey{public})
+	assert.EqualError(t, err, "the manifest is not signed by a trusted key")
+}
+
+func TestReadPackBundleModifiedFile(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	files := map[string]string{"rule.py": testPackRuleBody}
+	manifest := testPackManifest(files)
+	files["rule.py"] = "def rule(event): return False\n"
+
+	content := testPackZip(t, "", private, manifest, files)
+	_, err = readPackBundle(content, []ed25519.PublicKey{public})
+	assert.EqualError(t, err, "file rule.py doesn't match its hash in the manifest")
+}
+
+func TestReadPackBundleNoManifest(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, zip.NewWriter(&buffer).Close())
+	_, err := readPackBundle(buffer.Bytes(), nil)
+	assert.EqualError(t, err, "pack.yml manifest not found")
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("1.2.0", "v1.2"))
+	assert.Equal(t, -1, compareVersions("1.2.0", "1.10.0"))
+	assert.Equal(t, 1, compareVersions("v2", "1.99.99"))
+	assert.Equal(t, -1, compareVersions("1.0.0-beta", "1.0.0-rc"))
+}
@@ -0,0 +1,333 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/gateway/analysis/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+// The pack struct stored in the packs table.
+type packItem struct {
+	Description string            `json:"description,omitempty"`
+	ID          models.PackID     `json:"id"`
+	InstalledAt models.ModifyTime `json:"installedAt"`
+	InstalledBy models.UserID     `json:"installedBy"`
+	Source      models.PackSource `json:"source"`
+	Version     string            `json:"version"`
+
+	// The version of each detection written by the pack, a detection is modified locally
+	// once its current version is different.
+	Detections map[models.ID]models.VersionID `json:"detections"`
+}
+
+// Pack converts a Dynamo row into a Pack external model.
+func (p *packItem) Pack() *models.Pack {
+	result := &models.Pack{
+		Description: p.Description,
+		Detections:  make([]models.ID, 0, len(p.Detections)),
+		ID:          p.ID,
+		InstalledAt: p.InstalledAt,
+		InstalledBy: p.InstalledBy,
+		Source:      p.Source,
+		Version:     aws.String(p.Version),
+	}
+	for id := range p.Detections {
+		result.Detections = append(result.Detections, id)
+	}
+	sort.Slice(result.Detections, func(i, j int) bool { return result.Detections[i] < result.Detections[j] })
+	return result
+}
+
+// ListPacks lists the installed detection packs.
+func ListPacks(_ *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	packs, err := scanPacks()
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(packList(packs), http.StatusOK)
+}
+
+// ListPackUpdates lists the installed detection packs with the versions available from their sources.
+//
+// A pack whose source can't be reached or verified is listed without an available version.
+func ListPackUpdates(_ *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	packs, err := scanPacks()
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	result := packList(packs)
+	for _, pack := range result.Packs {
+		bundle, err := loadPackBundle(pack.Source)
+		if err != nil {
+			zap.L().Warn("failed to load pack bundle",
+				zap.String("packId", string(pack.ID)), zap.Error(err))
+			continue
+		}
+		pack.AvailableVersion = bundle.manifest.Version
+		pack.UpdateAvailable = compareVersions(bundle.manifest.Version, *pack.Version) > 0
+	}
+	return gatewayapi.MarshalResponse(result, http.StatusOK)
+}
+
+// ImportPack installs the rules, policies and helpers of a detection pack.
+func ImportPack(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	input, err := parseImportPack(request)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	bundle, err := loadPackBundle(input.Source)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	installed, err := dynamoGetPack(models.PackID(bundle.manifest.Name))
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if installed != nil {
+		return failedRequest(fmt.Sprintf("pack %s is already installed", installed.ID), http.StatusConflict)
+	}
+
+	// Every detection of the pack which already exists is a conflict
+	return applyPack(bundle, input.Source, nil, bool(input.Force), input.UserID)
+}
+
+// UpgradePack installs the version available from the source of an installed detection pack.
+//
+// Detections which were removed from the pack are left in place, they're no longer tracked by the pack.
+func UpgradePack(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	input, err := parseUpgradePack(request)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	installed, err := dynamoGetPack(input.ID)
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if installed == nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound}
+	}
+
+	bundle, err := loadPackBundle(installed.Source)
+	if err != nil {
+		return badRequest(err)
+	}
+	if bundle.manifest.Name != string(installed.ID) {
+		return badRequest(fmt.Errorf("the source of pack %s now contains pack %s", installed.ID, bundle.manifest.Name))
+	}
+	if compareVersions(bundle.manifest.Version, installed.Version) < 0 {
+		return badRequest(fmt.Errorf("version %s at the source of pack %s is older than the installed version %s",
+			bundle.manifest.Version, installed.ID, installed.Version))
+	}
+
+	return applyPack(bundle, installed.Source, installed.Detections, bool(input.Force), input.UserID)
+}
+
+// Write the detections of a pack unless they have conflicts, and record the pack.
+//
+// tracked is the version of each detection written by the previous version of the pack.
+func applyPack(
+	bundle *packBundle,
+	source models.PackSource,
+	tracked map[models.ID]models.VersionID,
+	force bool,
+	userID models.UserID,
+) *events.APIGatewayProxyResponse {
+
+	items, err := parseAnalysisFiles(bundle.files, userID)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	current, err := currentVersions(items)
+	if err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	pack := &packItem{
+		Description: bundle.manifest.Description,
+		Detections:  make(map[models.ID]models.VersionID, len(items)),
+		ID:          models.PackID(bundle.manifest.Name),
+		InstalledAt: models.ModifyTime(time.Now()),
+		InstalledBy: userID,
+		Source:      source,
+		Version:     bundle.manifest.Version,
+	}
+	for id := range items {
+		pack.Detections[id] = ""
+	}
+
+	change := &models.PackChange{
+		Applied:   aws.Bool(false),
+		Conflicts: packConflicts(items, tracked, current),
+		Pack:      pack.Pack(),
+	}
+	if len(change.Conflicts) > 0 && !force {
+		zap.L().Info("pack not applied because of conflicts",
+			zap.String("packId", string(pack.ID)), zap.Int("conflicts", len(change.Conflicts)))
+		return gatewayapi.MarshalResponse(change, http.StatusOK)
+	}
+
+	// A failed write leaves the pack as it was, so applying it again lists the detections
+	// which were already written as conflicts.
+	if err := writePackItems(items, pack, userID); err != nil {
+		if err == errWrongType {
+			return failedRequest(err.Error(), http.StatusConflict)
+		}
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	if err := dynamoPutPack(pack); err != nil {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	change.Applied = aws.Bool(true)
+	return gatewayapi.MarshalResponse(change, http.StatusOK)
+}
+
+// The detections of a pack which would overwrite local changes, sorted by ID.
+//
+// A detection is a conflict if it exists but it wasn't written by the pack,
+// or if it was modified since the pack wrote it.
+func packConflicts(
+	items map[models.ID]*tableItem,
+	tracked map[models.ID]models.VersionID,
+	current map[models.ID]models.VersionID,
+) []models.ID {
+
+	result := make([]models.ID, 0)
+	for id := range items {
+		currentVersion, exists := current[id]
+		if !exists {
+			continue
+		}
+		if trackedVersion, ok := tracked[id]; !ok || trackedVersion != currentVersion {
+			result = append(result, id)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// The current version of each detection which already exists.
+func currentVersions(items map[models.ID]*tableItem) (map[models.ID]models.VersionID, error) {
+	result := make(map[models.ID]models.VersionID, len(items))
+	for id := range items {
+		item, err := dynamoGet(id, true)
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			result[id] = item.VersionID
+		}
+	}
+	return result, nil
+}
+
+// Create/modify each detection of a pack in parallel, recording the versions in the pack.
+func writePackItems(items map[models.ID]*tableItem, pack *packItem, userID models.UserID) error {
+	results := make(chan writeResult)
+	for _, item := range items {
+		go func(item *tableItem) {
+			defer func() {
+				// Recover from panic so we don't block forever when waiting for routines to finish.
+				if r := recover(); r != nil {
+					zap.L().Error("panicked while processing item",
+						zap.String("id", string(item.ID)), zap.Any("panic", r))
+					results <- writeResult{item: item, err: errors.New("panicked goroutine")}
+				}
+			}()
+			changeType, err := writeItem(item, userID, nil)
+			if err == nil && changeType == noChange {
+				// The existing item was left as it is, its version is the version of the pack
+				var existing *tableItem
+				if existing, err = dynamoGet(item.ID, true); err == nil && existing != nil {
+					item.VersionID = existing.VersionID
+				}
+			}
+			results <- writeResult{item: item, changeType: changeType, err: err}
+		}(item)
+	}
+
+	// Wait for all the goroutines to finish, returning the first error
+	var result error
+	for range items {
+		written := <-results
+		if written.err != nil {
+			zap.L().Error("failed to write pack item",
+				zap.String("packId", string(pack.ID)), zap.String("id", string(written.item.ID)), zap.Error(written.err))
+			if result == nil || written.err == errWrongType {
+				result = written.err
+			}
+			continue
+		}
+		pack.Detections[written.item.ID] = written.item.VersionID
+	}
+	return result
+}
+
+func packList(packs []*packItem) *models.PackList {
+	result := &models.PackList{Packs: make([]*models.Pack, len(packs))}
+	for i, pack := range packs {
+		result.Packs[i] = pack.Pack()
+	}
+	sort.Slice(result.Packs, func(i, j int) bool { return result.Packs[i].ID < result.Packs[j].ID })
+	return result
+}
+
+func parseImportPack(request *events.APIGatewayProxyRequest) (*models.ImportPack, error) {
+	var result models.ImportPack
+	if err := jsoniter.UnmarshalFromString(request.Body, &result); err != nil {
+		return nil, err
+	}
+
+	if err := result.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func parseUpgradePack(request *events.APIGatewayProxyRequest) (*models.UpgradePack, error) {
+	var result models.UpgradePack
+	if err := jsoniter.UnmarshalFromString(request.Body, &result); err != nil {
+		return nil, err
+	}
+
+	if err := result.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
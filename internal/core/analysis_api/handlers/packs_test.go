@@ -0,0 +1,51 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/panther-labs/panther/api/gateway/analysis/models"
+)
+
+func TestPackConflicts(t *testing.T) {
+	items := map[models.ID]*tableItem{
+		"New":       {ID: "New"},
+		"Unchanged": {ID: "Unchanged"},
+		"Modified":  {ID: "Modified"},
+		"Untracked": {ID: "Untracked"},
+	}
+	tracked := map[models.ID]models.VersionID{
+		"Unchanged": "v1",
+		"Modified":  "v1",
+		"Removed":   "v1",
+	}
+	current := map[models.ID]models.VersionID{
+		"Unchanged": "v1",
+		"Modified":  "v2",
+		"Untracked": "v1",
+	}
+	assert.Equal(t, []models.ID{"Modified", "Untracked"}, packConflicts(items, tracked, current))
+
+	// Every existing detection is a conflict when a pack is imported
+	assert.Equal(t, []models.ID{"Modified", "Unchanged", "Untracked"}, packConflicts(items, nil, current))
+	assert.Equal(t, []models.ID{}, packConflicts(items, nil, nil))
+}
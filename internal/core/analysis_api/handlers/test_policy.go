@@ -158,11 +158,16 @@ func getRuleResults(input *models.TestPolicy) (*enginemodels.RulesEngineOutput,
 		},
 		Events: inputEvents,
 	}
+	return invokeRulesEngine(&testRequest)
+}
 
+// invokeRulesEngine runs a rule against test events in the rules engine, which analyzes the logs with the
+// same helpers and limits.
+func invokeRulesEngine(testRequest *enginemodels.RulesEngineInput) (*enginemodels.RulesEngineOutput, *events.APIGatewayProxyResponse) {
 	// Send the request to the rule-engine
 	var rulesEngineResults *enginemodels.RulesEngineOutput
 	client := lambda.New(awsSession)
-	payload, err := jsoniter.Marshal(&testRequest)
+	payload, err := jsoniter.Marshal(testRequest)
 	if err != nil {
 		zap.L().Error("failed to marshal RuleEngineInput", zap.Error(err))
 		return nil, &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
@@ -0,0 +1,126 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	enginemodels "github.com/panther-labs/panther/api/gateway/analysis"
+	"github.com/panther-labs/panther/api/gateway/analysis/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+// TestRule runs a rule against a set of sample events in the rules engine.
+//
+// The result of each event is returned, so rules can be checked before they're enabled.
+func TestRule(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	input, err := parseTestRule(request)
+	if err != nil {
+		return badRequest(err)
+	}
+
+	// Build the list of events to run the rule against
+	inputEvents := make([]enginemodels.Event, len(input.Tests))
+	for i, test := range input.Tests {
+		var data map[string]interface{}
+		if err := jsoniter.UnmarshalFromString(string(test.Event), &data); err != nil {
+			return badRequest(errors.Wrapf(err, "tests[%d].event is not valid json", i))
+		}
+		inputEvents[i] = enginemodels.Event{Data: data, ID: testResourceID + strconv.Itoa(i)}
+	}
+
+	engineOutput, errResponse := invokeRulesEngine(&enginemodels.RulesEngineInput{
+		Rules: []enginemodels.Rule{
+			{
+				Body: string(input.Body),
+				// Doesn't matter as we're only running one rule
+				ID: testPolicyID,
+			},
+		},
+		Events: inputEvents,
+	})
+	if errResponse != nil {
+		return errResponse
+	}
+
+	result, err := ruleTestResults(input.Tests, engineOutput)
+	if err != nil {
+		zap.L().Error("unexpected rules engine output", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(result, http.StatusOK)
+}
+
+// ruleTestResults compares the outcome of each test event with its expected result.
+func ruleTestResults(tests models.RuleTestSuite, output *enginemodels.RulesEngineOutput) (*models.TestRuleResult, error) {
+	if len(output.Events) != len(tests) {
+		return nil, errors.Errorf("%d events were analyzed, expected %d", len(output.Events), len(tests))
+	}
+
+	result := &models.TestRuleResult{
+		TestSummary: true,
+		Results:     make(models.RuleTestResults, len(tests)),
+	}
+	for _, event := range output.Events {
+		// The test events have the IDs Panther:Test:Resource:TestNumber
+		testIndex, err := strconv.Atoi(strings.TrimPrefix(event.ID, testResourceID))
+		if err != nil || testIndex < 0 || testIndex >= len(tests) || result.Results[testIndex] != nil {
+			return nil, errors.Errorf("unexpected test event ID %q", event.ID)
+		}
+
+		test := tests[testIndex]
+		testResult := &models.RuleTestCaseResult{Name: test.Name}
+		matched := len(event.Matched) > 0
+		switch {
+		case len(event.Errored) > 0:
+			testResult.Status = models.RuleTestStatusERROR
+			testResult.ErrorMessage = event.Errored[0].Message
+		case matched == bool(test.ExpectedResult):
+			testResult.Status = models.RuleTestStatusPASS
+			testResult.Matched = matched
+		default:
+			testResult.Status = models.RuleTestStatusFAIL
+			testResult.Matched = matched
+		}
+		if testResult.Status != models.RuleTestStatusPASS {
+			result.TestSummary = false
+		}
+		result.Results[testIndex] = testResult
+	}
+	return result, nil
+}
+
+func parseTestRule(request *events.APIGatewayProxyRequest) (*models.TestRule, error) {
+	var result models.TestRule
+	if err := jsoniter.UnmarshalFromString(request.Body, &result); err != nil {
+		return nil, err
+	}
+
+	if err := result.Validate(nil); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
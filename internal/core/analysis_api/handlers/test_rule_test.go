@@ -0,0 +1,83 @@
+package handlers
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	enginemodels "github.com/panther-labs/panther/api/gateway/analysis"
+	"github.com/panther-labs/panther/api/gateway/analysis/models"
+)
+
+var ruleTests = models.RuleTestSuite{
+	{Name: "matches", Event: `{"user": "root"}`, ExpectedResult: true},
+	{Name: "doesn't match", Event: `{"user": "alice"}`, ExpectedResult: false},
+	{Name: "should match", Event: `{"user": "bob"}`, ExpectedResult: true},
+	{Name: "raises", Event: `{}`, ExpectedResult: true},
+}
+
+func TestRuleTestResults(t *testing.T) {
+	// The rules engine doesn't have to keep the order of the events
+	result, err := ruleTestResults(ruleTests, &enginemodels.RulesEngineOutput{
+		Events: []enginemodels.EventAnalysis{
+			{ID: testResourceID + "3", Errored: []enginemodels.PolicyError{{ID: testPolicyID, Message: "KeyError('user')"}}},
+			{ID: testResourceID + "0", Matched: []string{testPolicyID}},
+			{ID: testResourceID + "1", NotMatched: []string{testPolicyID}},
+			{ID: testResourceID + "2", NotMatched: []string{testPolicyID}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.TestRuleResult{
+		TestSummary: false,
+		Results: models.RuleTestResults{
+			{Name: "matches", Status: models.RuleTestStatusPASS, Matched: true},
+			{Name: "doesn't match", Status: models.RuleTestStatusPASS},
+			{Name: "should match", Status: models.RuleTestStatusFAIL},
+			{Name: "raises", Status: models.RuleTestStatusERROR, ErrorMessage: "KeyError('user')"},
+		},
+	}, result)
+}
+
+func TestRuleTestResultsAllPass(t *testing.T) {
+	result, err := ruleTestResults(ruleTests[:2], &enginemodels.RulesEngineOutput{
+		Events: []enginemodels.EventAnalysis{
+			{ID: testResourceID + "0", Matched: []string{testPolicyID}},
+			{ID: testResourceID + "1", NotMatched: []string{testPolicyID}},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, bool(result.TestSummary))
+}
+
+func TestRuleTestResultsUnexpectedEvents(t *testing.T) {
+	_, err := ruleTestResults(ruleTests[:1], &enginemodels.RulesEngineOutput{})
+	assert.Error(t, err)
+
+	_, err = ruleTestResults(ruleTests[:1], &enginemodels.RulesEngineOutput{
+		Events: []enginemodels.EventAnalysis{{ID: testResourceID + "5", Matched: []string{testPolicyID}}},
+	})
+	assert.Error(t, err)
+
+	_, err = ruleTestResults(ruleTests[:2], &enginemodels.RulesEngineOutput{
+		Events: []enginemodels.EventAnalysis{{ID: testResourceID + "0"}, {ID: testResourceID + "0"}},
+	})
+	assert.Error(t, err)
+}
@@ -109,3 +109,8 @@ func TestSortCaseInsensitive(t *testing.T) {
 	expected := []string{"Panna Cotta", "panna cotta", "Panther", "panther"}
 	assert.Equal(t, expected, input)
 }
+
+func TestDedupPeriod(t *testing.T) {
+	assert.Equal(t, models.DedupPeriodMinutes(defaultDedupPeriodMinutes), (&tableItem{}).dedupPeriod())
+	assert.Equal(t, models.DedupPeriodMinutes(15), (&tableItem{DedupPeriodMinutes: 15}).dedupPeriod())
+}
@@ -47,6 +47,7 @@ import (
 const (
 	stackName           = "panther-app"
 	tableName           = "panther-analysis"
+	packTableName       = "panther-analysis-packs"
 	policiesRoot        = "./test_policies"
 	policiesZipLocation = "./bulk_upload.zip"
 )
@@ -232,9 +233,10 @@ func TestIntegrationAPI(t *testing.T) {
 		}
 	}
 
-	// Reset data stores: S3 bucket and Dynamo table
+	// Reset data stores: S3 bucket and Dynamo tables
 	require.NoError(t, testutils.ClearS3Bucket(awsSession, bucketName))
 	require.NoError(t, testutils.ClearDynamoTable(awsSession, tableName))
+	require.NoError(t, testutils.ClearDynamoTable(awsSession, packTableName))
 
 	require.NotEmpty(t, endpoint)
 	apiClient = client.NewHTTPClientWithConfig(nil, client.DefaultTransportConfig().
@@ -246,12 +248,17 @@ func TestIntegrationAPI(t *testing.T) {
 		t.Run("TestPolicyError", testPolicyError)
 		t.Run("TestPolicyNotApplicable", testPolicyNotApplicable)
 		t.Run("TestPolicyMixed", testPolicyMixed)
+		t.Run("TestRuleMixed", testRuleMixed)
+		t.Run("TestRuleImportError", testRuleImportError)
 	})
 
 	// These tests must be run before any data is input
 	t.Run("TestEmpty", func(t *testing.T) {
 		t.Run("GetEnabledEmpty", getEnabledEmpty)
 		t.Run("ListNotFound", listNotFound)
+		t.Run("ListPacksEmpty", listPacksEmpty)
+		t.Run("UpgradePackNotFound", upgradePackNotFound)
+		t.Run("ImportPackInvalidSource", importPackInvalidSource)
 	})
 
 	t.Run("Create", func(t *testing.T) {
@@ -471,6 +478,53 @@ func testPolicyMixed(t *testing.T) {
 	assert.Equal(t, expected, result.Payload)
 }
 
+func testRuleMixed(t *testing.T) {
+	t.Parallel()
+	result, err := apiClient.Operations.TestRule(&operations.TestRuleParams{
+		Body: &models.TestRule{
+			Body: "def rule(event): return event['Hello']",
+			Tests: models.RuleTestSuite{
+				{ExpectedResult: true, Name: "test-1", Event: `{"Hello": true}`},
+				{ExpectedResult: false, Name: "test-2", Event: `{"Hello": false}`},
+				{ExpectedResult: true, Name: "test-3", Event: `{"Hello": false}`},
+				{ExpectedResult: true, Name: "test-4", Event: `{"Goodbye": false}`},
+			},
+		},
+		HTTPClient: httpClient,
+	})
+
+	require.NoError(t, err)
+	expected := &models.TestRuleResult{
+		TestSummary: false,
+		Results: models.RuleTestResults{
+			{Name: "test-1", Status: models.RuleTestStatusPASS, Matched: true},
+			{Name: "test-2", Status: models.RuleTestStatusPASS},
+			{Name: "test-3", Status: models.RuleTestStatusFAIL},
+			{Name: "test-4", Status: models.RuleTestStatusERROR, ErrorMessage: "'Hello'"},
+		},
+	}
+	assert.Equal(t, expected, result.Payload)
+}
+
+func testRuleImportError(t *testing.T) {
+	t.Parallel()
+	result, err := apiClient.Operations.TestRule(&operations.TestRuleParams{
+		Body: &models.TestRule{
+			Body: "def rule(event) return True",
+			Tests: models.RuleTestSuite{
+				{ExpectedResult: true, Name: "test-1", Event: `{"Hello": true}`},
+			},
+		},
+		HTTPClient: httpClient,
+	})
+
+	require.NoError(t, err)
+	assert.False(t, bool(result.Payload.TestSummary))
+	require.Len(t, result.Payload.Results, 1)
+	assert.Equal(t, models.RuleTestStatusERROR, result.Payload.Results[0].Status)
+	assert.NotEmpty(t, result.Payload.Results[0].ErrorMessage)
+}
+
 func createInvalid(t *testing.T) {
 	t.Parallel()
 	result, err := apiClient.Operations.CreatePolicy(&operations.CreatePolicyParams{HTTPClient: httpClient})
@@ -1106,6 +1160,43 @@ func getEnabledEmpty(t *testing.T) {
 	assert.Equal(t, &models.EnabledPolicies{Policies: []*models.EnabledPolicy{}}, result.Payload)
 }
 
+func listPacksEmpty(t *testing.T) {
+	t.Parallel()
+	result, err := apiClient.Operations.ListPacks(&operations.ListPacksParams{
+		HTTPClient: httpClient,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.PackList{Packs: []*models.Pack{}}, result.Payload)
+}
+
+func upgradePackNotFound(t *testing.T) {
+	t.Parallel()
+	result, err := apiClient.Operations.UpgradePack(&operations.UpgradePackParams{
+		Body: &models.UpgradePack{
+			ID:     "DOES.NOT.EXIST",
+			UserID: userID,
+		},
+		HTTPClient: httpClient,
+	})
+	assert.Nil(t, result)
+	require.Error(t, err)
+	require.IsType(t, &operations.UpgradePackNotFound{}, err)
+}
+
+func importPackInvalidSource(t *testing.T) {
+	t.Parallel()
+	result, err := apiClient.Operations.ImportPack(&operations.ImportPackParams{
+		Body: &models.ImportPack{
+			Source: "ftp://example.com/pack.zip",
+			UserID: userID,
+		},
+		HTTPClient: httpClient,
+	})
+	assert.Nil(t, result)
+	require.Error(t, err)
+	require.IsType(t, &operations.ImportPackBadRequest{}, err)
+}
+
 func getEnabledSuccess(t *testing.T) {
 	t.Parallel()
 	result, err := apiClient.Operations.GetEnabledPolicies(&operations.GetEnabledPoliciesParams{
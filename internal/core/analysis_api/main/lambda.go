@@ -39,11 +39,18 @@ var methodHandlers = map[string]gatewayapi.RequestHandler{
 	"POST /rule":        handlers.CreateRule,
 	"GET /rule/list":    handlers.ListRules,
 	"POST /rule/update": handlers.ModifyRule,
+	"POST /rule/test":   handlers.TestRule,
 
 	// Rules and Policies
 	"POST /delete": handlers.DeletePolicies,
 	"GET /enabled": handlers.GetEnabledPolicies,
 	"POST /test":   handlers.TestPolicy,
+
+	// Detection packs
+	"GET /pack/list":     handlers.ListPacks,
+	"GET /pack/updates":  handlers.ListPackUpdates,
+	"POST /pack/import":  handlers.ImportPack,
+	"POST /pack/upgrade": handlers.UpgradePack,
 }
 
 func main() {
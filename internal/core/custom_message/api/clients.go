@@ -24,6 +24,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 
 	"github.com/panther-labs/panther/internal/core/users_api/gateway"
+	"github.com/panther-labs/panther/internal/core/users_api/table"
 )
 
 var (
@@ -31,4 +32,5 @@ var (
 	awsSession   = session.Must(session.NewSession())
 
 	userGateway gateway.API = gateway.New(awsSession)
+	rolesTable  table.API   = table.New(os.Getenv("ROLES_TABLE_NAME"), awsSession)
 )
@@ -20,6 +20,8 @@ package custommessage
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
@@ -29,20 +31,42 @@ import (
 )
 
 // HandleEvent routes Custom Message event based on the triggerSource
-func HandleEvent(ctx context.Context, event *events.CognitoEventUserPoolsCustomMessage) (
-	returnedEvent *events.CognitoEventUserPoolsCustomMessage, err error) {
-
+//
+// The function is the Post Confirmation and the Pre Token Generation trigger of the user pool as well,
+// the events have the same header.
+func HandleEvent(ctx context.Context, rawEvent json.RawMessage) (returnedEvent interface{}, err error) {
 	lc, _ := lambdalogger.ConfigureGlobal(ctx, nil)
 	operation := oplog.NewManager("api", "custom_message").Start(lc.InvokedFunctionArn).WithMemUsed(lambdacontext.MemoryLimitInMB)
 	defer func() {
 		operation.Stop().Log(err)
 	}()
 
-	switch ts := event.TriggerSource; ts {
-	case "CustomMessage_ForgotPassword":
-		event, err = handleForgotPassword(event)
-		return event, err
+	var header events.CognitoEventUserPoolsHeader
+	if err = json.Unmarshal(rawEvent, &header); err != nil {
+		return nil, err
+	}
+
+	switch ts := header.TriggerSource; {
+	case ts == "CustomMessage_ForgotPassword":
+		var event events.CognitoEventUserPoolsCustomMessage
+		if err = json.Unmarshal(rawEvent, &event); err != nil {
+			return nil, err
+		}
+		return handleForgotPassword(&event)
+	case ts == "PostConfirmation_ConfirmSignUp":
+		var event events.CognitoEventUserPoolsCustomMessage
+		if err = json.Unmarshal(rawEvent, &event); err != nil {
+			return nil, err
+		}
+		return handleProvisionUser(&event)
+	case strings.HasPrefix(ts, "TokenGeneration_"):
+		// The tokens are generated when signing in and when refreshing them, with the same event
+		var event events.CognitoEventUserPoolsPreTokenGen
+		if err = json.Unmarshal(rawEvent, &event); err != nil {
+			return nil, err
+		}
+		return handleTokenGeneration(&event)
 	default:
-		return event, nil
+		return rawEvent, nil
 	}
 }
@@ -55,3 +55,34 @@ func TestHandleForgotPasswordGeneratePlainTextEmail(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, e)
 }
+
+func TestHandleProvisionUser(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	username := "Okta_user@test.pizza"
+	event := events.CognitoEventUserPoolsCustomMessage{
+		CognitoEventUserPoolsHeader: events.CognitoEventUserPoolsHeader{UserName: username},
+		Request: events.CognitoEventUserPoolsCustomMessageRequest{
+			UserAttributes: map[string]interface{}{"identities": `[{"providerName":"Okta"}]`},
+		},
+	}
+	mockGateway.On("ProvisionUser", &username).Return(nil)
+
+	e, err := handleProvisionUser(&event)
+	assert.NoError(t, err)
+	assert.NotNil(t, e)
+	mockGateway.AssertExpectations(t)
+}
+
+func TestHandleProvisionUserNotFederated(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	event := events.CognitoEventUserPoolsCustomMessage{
+		CognitoEventUserPoolsHeader: events.CognitoEventUserPoolsHeader{UserName: "user-123"},
+	}
+
+	e, err := handleProvisionUser(&event)
+	assert.NoError(t, err)
+	assert.NotNil(t, e)
+	mockGateway.AssertNotCalled(t, "ProvisionUser", &event.UserName)
+}
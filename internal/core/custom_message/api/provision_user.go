@@ -0,0 +1,40 @@
+package custommessage
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+)
+
+// The users signing in with the identity provider for the first time are confirmed right away,
+// they join the group of the default role of the identity provider.
+func handleProvisionUser(event *events.CognitoEventUserPoolsCustomMessage) (*events.CognitoEventUserPoolsCustomMessage, error) {
+	// Only the users created by an identity provider are linked to its identities
+	if _, federated := event.Request.UserAttributes["identities"]; !federated {
+		return event, nil
+	}
+
+	zap.L().Info("provisioning single sign-on user " + event.UserName)
+	if err := userGateway.ProvisionUser(&event.UserName); err != nil {
+		zap.L().Error("failed to provision user "+event.UserName, zap.Error(err))
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,71 @@
+package custommessage
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/core/users_api/gateway"
+	"github.com/panther-labs/panther/internal/core/users_api/table"
+)
+
+// The claims of the ID token with the role of the user and its permissions, space-separated.
+//
+// AppSync passes them to the APIs, whose routers check them.
+const (
+	roleClaim        = "role"
+	permissionsClaim = "permissions"
+)
+
+// The ID tokens of the users carry their role, the Cognito group they are in, and its permissions.
+//
+// The permissions of a custom role deleted in the meantime are empty, the user can't invoke any API.
+// Neither can a user without a role.
+func handleTokenGeneration(event *events.CognitoEventUserPoolsPreTokenGen) (*events.CognitoEventUserPoolsPreTokenGen, error) {
+	groups := aws.StringSlice(event.Request.GroupConfiguration.GroupsToOverride)
+	roleName := gateway.RoleOfGroups(groups)
+
+	var permissions []string
+	if roleName != nil {
+		role, err := table.FindRole(rolesTable, roleName)
+		if err != nil {
+			zap.L().Error("failed to find role "+*roleName, zap.Error(err))
+			return nil, err
+		}
+		if role != nil {
+			permissions = aws.StringValueSlice(role.Permissions)
+		}
+	} else {
+		zap.L().Warn("user has no role", zap.String("userName", event.UserName))
+	}
+
+	event.Response.ClaimsOverrideDetails = events.ClaimsOverrideDetails{
+		// The groups of the user are kept as they are in the token
+		GroupOverrideDetails: event.Request.GroupConfiguration,
+		ClaimsToAddOrOverride: map[string]string{
+			roleClaim:        aws.StringValue(roleName),
+			permissionsClaim: strings.Join(permissions, " "),
+		},
+	}
+	return event, nil
+}
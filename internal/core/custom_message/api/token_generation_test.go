@@ -0,0 +1,101 @@
+package custommessage
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/users_api/table"
+)
+
+type mockRolesTable struct {
+	table.API
+	mock.Mock
+}
+
+func (m *mockRolesTable) Get(name *string) (*models.Role, error) {
+	args := m.Called(name)
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func tokenGenerationEvent(groups ...string) *events.CognitoEventUserPoolsPreTokenGen {
+	return &events.CognitoEventUserPoolsPreTokenGen{
+		Request: events.CognitoEventUserPoolsPreTokenGenRequest{
+			GroupConfiguration: events.GroupConfiguration{GroupsToOverride: groups},
+		},
+	}
+}
+
+func TestHandleTokenGenerationBuiltInRole(t *testing.T) {
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+
+	event, err := handleTokenGeneration(tokenGenerationEvent(models.RoleReadOnly))
+	require.NoError(t, err)
+	claims := event.Response.ClaimsOverrideDetails.ClaimsToAddOrOverride
+	assert.Equal(t, models.RoleReadOnly, claims["role"])
+	assert.Contains(t, claims["permissions"], models.PermissionAlertRead)
+	assert.NotContains(t, claims["permissions"], models.PermissionAlertModify)
+	assert.Equal(t, []string{models.RoleReadOnly}, event.Response.ClaimsOverrideDetails.GroupOverrideDetails.GroupsToOverride)
+	mockTable.AssertExpectations(t)
+}
+
+func TestHandleTokenGenerationUnassigned(t *testing.T) {
+	rolesTable = &mockRolesTable{}
+
+	event, err := handleTokenGeneration(tokenGenerationEvent())
+	require.NoError(t, err)
+	claims := event.Response.ClaimsOverrideDetails.ClaimsToAddOrOverride
+	assert.Equal(t, "", claims["role"])
+	assert.Equal(t, "", claims["permissions"])
+}
+
+func TestHandleTokenGenerationCustomRole(t *testing.T) {
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("Get", aws.String("Triage")).Return(&models.Role{
+		Name:        aws.String("Triage"),
+		Permissions: aws.StringSlice([]string{models.PermissionAlertModify, models.PermissionAlertRead}),
+	}, nil)
+
+	event, err := handleTokenGeneration(tokenGenerationEvent("Triage"))
+	require.NoError(t, err)
+	claims := event.Response.ClaimsOverrideDetails.ClaimsToAddOrOverride
+	assert.Equal(t, "Triage", claims["role"])
+	assert.Equal(t, "AlertModify AlertRead", claims["permissions"])
+	mockTable.AssertExpectations(t)
+}
+
+func TestHandleTokenGenerationDeletedRole(t *testing.T) {
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("Get", aws.String("Triage")).Return((*models.Role)(nil), nil)
+
+	event, err := handleTokenGeneration(tokenGenerationEvent("Triage"))
+	require.NoError(t, err)
+	assert.Equal(t, "", event.Response.ClaimsOverrideDetails.ClaimsToAddOrOverride["permissions"])
+	mockTable.AssertExpectations(t)
+}
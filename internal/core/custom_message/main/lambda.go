@@ -20,17 +20,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 
 	custommessage "github.com/panther-labs/panther/internal/core/custom_message/api"
 )
 
 // TODO - merge this with users-api
-func lambdaHandler(ctx context.Context, event *events.CognitoEventUserPoolsCustomMessage) (
-	*events.CognitoEventUserPoolsCustomMessage, error) {
-
+func lambdaHandler(ctx context.Context, event json.RawMessage) (interface{}, error) {
 	return custommessage.HandleEvent(ctx, event)
 }
 
@@ -24,12 +24,15 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 
 	"github.com/panther-labs/panther/api/lambda/organization/models"
+	usermodels "github.com/panther-labs/panther/api/lambda/users/models"
 	"github.com/panther-labs/panther/internal/core/organization_api/api"
 	"github.com/panther-labs/panther/pkg/genericapi"
 	"github.com/panther-labs/panther/pkg/lambdalogger"
 )
 
-var router = genericapi.NewRouter("api", "organization", nil, api.API{})
+var router = genericapi.NewRouter("api", "organization", nil, api.API{}).
+	RequirePermission(usermodels.PermissionSettingsModify, "UpdateSettings").
+	RequirePermission(usermodels.PermissionSettingsRead, "GetSettings")
 
 func lambdaHandler(ctx context.Context, input *models.LambdaInput) (interface{}, error) {
 	lambdalogger.ConfigureGlobal(ctx, nil)
@@ -40,4 +40,6 @@ var (
 		os.Getenv("OUTPUTS_TABLE_NAME"),
 		os.Getenv("OUTPUTS_DISPLAY_NAME_INDEX_NAME"),
 		awsSession)
+
+	routingTable table.RoutingAPI = table.NewRouting(os.Getenv("ROUTING_TABLE_NAME"), awsSession)
 )
@@ -78,3 +78,22 @@ func (m *mockEncryptionKey) EncryptConfig(config interface{}) ([]byte, error) {
 	args := m.Called(config)
 	return args.Get(0).([]byte), args.Error(1)
 }
+
+type mockRoutingTable struct {
+	table.RoutingTable
+	mock.Mock
+}
+
+func (m *mockRoutingTable) GetAlertRouting() (*table.AlertRoutingItem, error) {
+	args := m.Called()
+	routing := args.Get(0)
+	if routing == nil {
+		return nil, args.Error(1)
+	}
+	return routing.(*table.AlertRoutingItem), args.Error(1)
+}
+
+func (m *mockRoutingTable) PutAlertRouting(routing *table.AlertRoutingItem) error {
+	args := m.Called(routing)
+	return args.Error(0)
+}
@@ -0,0 +1,66 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// RouteAlert returns the destinations an alert with the given attributes would be delivered to.
+func (API) RouteAlert(input *models.RouteAlertInput) (*models.RouteAlertOutput, error) {
+	routing, err := routingTable.GetAlertRouting()
+	if err != nil {
+		return nil, err
+	}
+	var rule *models.RoutingRule
+	var routedIDs []*string
+	if routing != nil {
+		rule, routedIDs = routing.Route(input)
+	}
+
+	outputs, err := API{}.GetOutputs(&models.GetOutputsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RouteAlertOutput{Outputs: []*models.AlertOutput{}}
+	if rule != nil {
+		result.RuleName = rule.Name
+	}
+	for _, output := range outputs {
+		// Without a route, the alert goes to the destinations which are default for its severity
+		selected := containsString(routedIDs, *output.OutputID)
+		if routedIDs == nil {
+			selected = containsString(output.DefaultForSeverity, *input.Severity)
+		}
+		if selected {
+			result.Outputs = append(result.Outputs, output)
+		}
+	}
+	return result, nil
+}
+
+func containsString(values []*string, value string) bool {
+	for _, v := range values {
+		if *v == value {
+			return true
+		}
+	}
+	return false
+}
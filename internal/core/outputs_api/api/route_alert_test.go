@@ -0,0 +1,128 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/outputs_api/table"
+)
+
+func routeAlertItems() []*table.AlertOutputItem {
+	return []*table.AlertOutputItem{
+		{
+			OutputID:           aws.String("pagerOutputId"),
+			OutputType:         aws.String("pagerduty"),
+			DefaultForSeverity: aws.StringSlice([]string{"CRITICAL"}),
+		},
+		{
+			OutputID:           aws.String("slackOutputId"),
+			OutputType:         aws.String("slack"),
+			DefaultForSeverity: aws.StringSlice([]string{"HIGH"}),
+		},
+	}
+}
+
+func setupRouteAlert(routing *table.AlertRoutingItem) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockRoutingTable := &mockRoutingTable{}
+	routingTable = mockRoutingTable
+	mockEncryptionKey := &mockEncryptionKey{}
+	encryptionKey = mockEncryptionKey
+
+	if routing == nil {
+		mockRoutingTable.On("GetAlertRouting").Return(nil, nil)
+	} else {
+		mockRoutingTable.On("GetAlertRouting").Return(routing, nil)
+	}
+	mockOutputsTable.On("GetOutputs").Return(routeAlertItems(), nil)
+	mockEncryptionKey.On("DecryptConfig", mock.Anything, mock.Anything).Return(nil)
+}
+
+func routedOutputIDs(output *models.RouteAlertOutput) []string {
+	result := make([]string, len(output.Outputs))
+	for i, alertOutput := range output.Outputs {
+		result[i] = *alertOutput.OutputID
+	}
+	return result
+}
+
+func TestRouteAlert(t *testing.T) {
+	setupRouteAlert(&table.AlertRoutingItem{AlertRouting: models.AlertRouting{
+		Rules: []*models.RoutingRule{
+			{
+				Name:      aws.String("pci"),
+				Tags:      aws.StringSlice([]string{"PCI"}),
+				LogTypes:  aws.StringSlice([]string{"AWS.CloudTrail"}),
+				OutputIDs: aws.StringSlice([]string{"pagerOutputId", "slackOutputId"}),
+			},
+			{
+				Name:       aws.String("critical"),
+				Severities: aws.StringSlice([]string{"CRITICAL"}),
+				OutputIDs:  aws.StringSlice([]string{"pagerOutputId"}),
+			},
+		},
+		DefaultOutputIDs: aws.StringSlice([]string{"slackOutputId"}),
+	}})
+
+	// The first matching rule wins
+	result, err := (API{}).RouteAlert(&models.RouteAlertInput{
+		Severity: aws.String("CRITICAL"),
+		Tags:     aws.StringSlice([]string{"pci"}),
+		LogTypes: aws.StringSlice([]string{"AWS.VPCFlow", "AWS.CloudTrail"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, aws.String("pci"), result.RuleName)
+	assert.Equal(t, []string{"pagerOutputId", "slackOutputId"}, routedOutputIDs(result))
+
+	// Every criteria of a rule must match
+	result, err = (API{}).RouteAlert(&models.RouteAlertInput{
+		Severity: aws.String("CRITICAL"),
+		Tags:     aws.StringSlice([]string{"pci"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, aws.String("critical"), result.RuleName)
+	assert.Equal(t, []string{"pagerOutputId"}, routedOutputIDs(result))
+
+	// No rule matches, the alert takes the default route
+	result, err = (API{}).RouteAlert(&models.RouteAlertInput{Severity: aws.String("LOW")})
+	require.NoError(t, err)
+	assert.Nil(t, result.RuleName)
+	assert.Equal(t, []string{"slackOutputId"}, routedOutputIDs(result))
+}
+
+func TestRouteAlertSeverityDefaults(t *testing.T) {
+	setupRouteAlert(nil)
+
+	result, err := (API{}).RouteAlert(&models.RouteAlertInput{Severity: aws.String("HIGH")})
+	require.NoError(t, err)
+	assert.Nil(t, result.RuleName)
+	assert.Equal(t, []string{"slackOutputId"}, routedOutputIDs(result))
+
+	result, err = (API{}).RouteAlert(&models.RouteAlertInput{Severity: aws.String("INFO")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, routedOutputIDs(result))
+}
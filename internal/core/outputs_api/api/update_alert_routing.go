@@ -0,0 +1,86 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/outputs_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// UpdateAlertRouting replaces the routing rules of the alerts.
+//
+// The rules must have unique names and can only select existing destinations.
+func (API) UpdateAlertRouting(input *models.UpdateAlertRoutingInput) (*models.UpdateAlertRoutingOutput, error) {
+	outputItems, err := outputsTable.GetOutputs()
+	if err != nil {
+		return nil, err
+	}
+	outputIDs := make(map[string]struct{}, len(outputItems))
+	for _, item := range outputItems {
+		outputIDs[*item.OutputID] = struct{}{}
+	}
+
+	names := make(map[string]struct{}, len(input.Rules))
+	for _, rule := range input.Rules {
+		if _, exists := names[*rule.Name]; exists {
+			return nil, &genericapi.InvalidInputError{Message: "routing rule name " + *rule.Name + " is not unique"}
+		}
+		names[*rule.Name] = struct{}{}
+		if err := checkOutputsExist(rule.OutputIDs, outputIDs); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkOutputsExist(input.DefaultOutputIDs, outputIDs); err != nil {
+		return nil, err
+	}
+
+	routing := models.AlertRouting{
+		Rules:            input.Rules,
+		DefaultOutputIDs: input.DefaultOutputIDs,
+		LastModifiedBy:   input.UserID,
+		LastModifiedTime: aws.String(time.Now().Format(time.RFC3339)),
+	}
+	if routing.Rules == nil {
+		routing.Rules = []*models.RoutingRule{}
+	}
+	if routing.DefaultOutputIDs == nil {
+		routing.DefaultOutputIDs = []*string{}
+	}
+	if err = routingTable.PutAlertRouting(&table.AlertRoutingItem{AlertRouting: routing}); err != nil {
+		return nil, err
+	}
+
+	zap.L().Debug("updated alert routing", zap.Int("rules", len(routing.Rules)))
+	return &routing, nil
+}
+
+func checkOutputsExist(ids []*string, outputIDs map[string]struct{}) error {
+	for _, id := range ids {
+		if _, exists := outputIDs[*id]; !exists {
+			return &genericapi.InvalidInputError{Message: "destination " + *id + " does not exist"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/outputs_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var mockUpdateAlertRoutingInput = &models.UpdateAlertRoutingInput{
+	UserID: aws.String("userId"),
+	Rules: []*models.RoutingRule{
+		{
+			Name:       aws.String("critical"),
+			Severities: aws.StringSlice([]string{"CRITICAL"}),
+			OutputIDs:  aws.StringSlice([]string{"outputId"}),
+		},
+	},
+}
+
+func TestUpdateAlertRouting(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockRoutingTable := &mockRoutingTable{}
+	routingTable = mockRoutingTable
+
+	mockOutputsTable.On("GetOutputs").Return([]*table.AlertOutputItem{alertOutputItem}, nil)
+	mockRoutingTable.On("PutAlertRouting", mock.Anything).Return(nil)
+
+	result, err := (API{}).UpdateAlertRouting(mockUpdateAlertRoutingInput)
+	require.NoError(t, err)
+	assert.Equal(t, mockUpdateAlertRoutingInput.Rules, result.Rules)
+	assert.Equal(t, []*string{}, result.DefaultOutputIDs)
+	assert.Equal(t, aws.String("userId"), result.LastModifiedBy)
+	assert.NotNil(t, result.LastModifiedTime)
+
+	stored := mockRoutingTable.Calls[0].Arguments.Get(0).(*table.AlertRoutingItem)
+	assert.Equal(t, *result, stored.AlertRouting)
+	mockOutputsTable.AssertExpectations(t)
+	mockRoutingTable.AssertExpectations(t)
+}
+
+func TestUpdateAlertRoutingUnknownOutput(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockRoutingTable := &mockRoutingTable{}
+	routingTable = mockRoutingTable
+
+	mockOutputsTable.On("GetOutputs").Return([]*table.AlertOutputItem{alertOutputItem}, nil)
+
+	input := *mockUpdateAlertRoutingInput
+	input.DefaultOutputIDs = aws.StringSlice([]string{"deletedOutputId"})
+	result, err := (API{}).UpdateAlertRouting(&input)
+	assert.Nil(t, result)
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "destination deletedOutputId does not exist"}, err)
+	mockRoutingTable.AssertNotCalled(t, "PutAlertRouting", mock.Anything)
+}
+
+func TestUpdateAlertRoutingDuplicateName(t *testing.T) {
+	mockOutputsTable := &mockOutputTable{}
+	outputsTable = mockOutputsTable
+	mockRoutingTable := &mockRoutingTable{}
+	routingTable = mockRoutingTable
+
+	mockOutputsTable.On("GetOutputs").Return([]*table.AlertOutputItem{alertOutputItem}, nil)
+
+	input := *mockUpdateAlertRoutingInput
+	input.Rules = append(input.Rules, input.Rules[0])
+	result, err := (API{}).UpdateAlertRouting(&input)
+	assert.Nil(t, result)
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "routing rule name critical is not unique"}, err)
+	mockRoutingTable.AssertNotCalled(t, "PutAlertRouting", mock.Anything)
+}
+
+func TestGetAlertRoutingNotConfigured(t *testing.T) {
+	mockRoutingTable := &mockRoutingTable{}
+	routingTable = mockRoutingTable
+	mockRoutingTable.On("GetAlertRouting").Return(nil, nil)
+
+	result, err := (API{}).GetAlertRouting(&models.GetAlertRoutingInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.AlertRouting{Rules: []*models.RoutingRule{}, DefaultOutputIDs: []*string{}}, result)
+}
@@ -102,6 +102,12 @@ func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
 	if outputConfig.Asana != nil {
 		return aws.String("asana"), nil
 	}
+	if outputConfig.ServiceNow != nil {
+		return aws.String("servicenow"), nil
+	}
+	if outputConfig.CustomWebhook != nil {
+		return aws.String("customwebhook"), nil
+	}
 
 	return nil, errors.New("no valid output configuration specified for alert output")
 }
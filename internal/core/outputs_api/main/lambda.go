@@ -24,6 +24,7 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 
 	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	usermodels "github.com/panther-labs/panther/api/lambda/users/models"
 	"github.com/panther-labs/panther/internal/core/outputs_api/api"
 	"github.com/panther-labs/panther/internal/core/outputs_api/validator"
 	"github.com/panther-labs/panther/pkg/genericapi"
@@ -37,7 +38,9 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	router = genericapi.NewRouter("api", "outputs", validator, api.API{})
+	router = genericapi.NewRouter("api", "outputs", validator, api.API{}).
+		RequirePermission(usermodels.PermissionDestinationModify, "AddOutput", "DeleteOutput", "UpdateAlertRouting", "UpdateOutput").
+		RequirePermission(usermodels.PermissionDestinationRead, "GetAlertRouting", "GetOutput", "GetOutputs")
 }
 
 func lambdaHandler(ctx context.Context, input *models.LambdaInput) (interface{}, error) {
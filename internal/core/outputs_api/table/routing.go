@@ -0,0 +1,94 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The routing rules are a single item, they are always read and replaced together
+const alertRoutingID = "alertRouting"
+
+// RoutingAPI defines the interface for the alert routing table which can be used for mocking.
+type RoutingAPI interface {
+	GetAlertRouting() (*AlertRoutingItem, error)
+	PutAlertRouting(*AlertRoutingItem) error
+}
+
+// RoutingTable encapsulates a connection to the Dynamo alert routing table.
+type RoutingTable struct {
+	Name   *string
+	client dynamodbiface.DynamoDBAPI
+}
+
+// NewRouting creates an AWS client to interface with the alert routing table.
+func NewRouting(name string, sess *session.Session) *RoutingTable {
+	return &RoutingTable{
+		Name:   aws.String(name),
+		client: dynamodb.New(sess),
+	}
+}
+
+// AlertRoutingItem is the alert routing configuration stored in DynamoDB.
+type AlertRoutingItem struct {
+	RoutingID *string `json:"routingId"`
+	models.AlertRouting
+}
+
+// GetAlertRouting returns the routing rules, or nil if they were never configured.
+func (table *RoutingTable) GetAlertRouting() (*AlertRoutingItem, error) {
+	response, err := table.client.GetItem(&dynamodb.GetItemInput{
+		Key:       DynamoItem{"routingId": {S: aws.String(alertRoutingID)}},
+		TableName: table.Name,
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.GetItem", Err: err}
+	}
+	if len(response.Item) == 0 {
+		return nil, nil
+	}
+
+	var item AlertRoutingItem
+	if err = dynamodbattribute.UnmarshalMap(response.Item, &item); err != nil {
+		return nil, &genericapi.InternalError{
+			Message: "failed to unmarshal dynamo item to an AlertRoutingItem: " + err.Error()}
+	}
+	return &item, nil
+}
+
+// PutAlertRouting replaces the routing rules.
+func (table *RoutingTable) PutAlertRouting(routing *AlertRoutingItem) error {
+	routing.RoutingID = aws.String(alertRoutingID)
+	item, err := dynamodbattribute.MarshalMap(routing)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal AlertRouting to a dynamo item: " + err.Error()}
+	}
+
+	if _, err = table.client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: table.Name}); err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var mockAlertRouting = models.AlertRouting{
+	Rules: []*models.RoutingRule{
+		{
+			Name:       aws.String("critical"),
+			Severities: aws.StringSlice([]string{"CRITICAL"}),
+			OutputIDs:  aws.StringSlice([]string{"outputId"}),
+		},
+	},
+	DefaultOutputIDs: aws.StringSlice([]string{"defaultOutputId"}),
+	LastModifiedBy:   aws.String("userId"),
+}
+
+func TestPutAndGetAlertRouting(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &RoutingTable{Name: aws.String("routing"), client: client}
+
+	var stored DynamoItem
+	client.On("PutItem", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*dynamodb.PutItemInput).Item
+	}).Return(&dynamodb.PutItemOutput{}, nil)
+	require.NoError(t, table.PutAlertRouting(&AlertRoutingItem{AlertRouting: mockAlertRouting}))
+	assert.Equal(t, alertRoutingID, *stored["routingId"].S)
+
+	client.On("GetItem", &dynamodb.GetItemInput{
+		Key:       DynamoItem{"routingId": {S: aws.String(alertRoutingID)}},
+		TableName: aws.String("routing"),
+	}).Return(&dynamodb.GetItemOutput{Item: stored}, nil)
+	result, err := table.GetAlertRouting()
+	require.NoError(t, err)
+	assert.Equal(t, &AlertRoutingItem{RoutingID: aws.String(alertRoutingID), AlertRouting: mockAlertRouting}, result)
+	client.AssertExpectations(t)
+}
+
+func TestGetAlertRoutingNotConfigured(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &RoutingTable{client: client}
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := table.GetAlertRouting()
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestGetAlertRoutingServiceError(t *testing.T) {
+	client := &mockDynamoDB{}
+	table := &RoutingTable{client: client}
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, errors.New("service error"))
+
+	_, err := table.GetAlertRouting()
+	assert.NotNil(t, err.(*genericapi.AWSError))
+}
@@ -19,12 +19,14 @@ package validator
  */
 
 import (
+	"net/url"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"gopkg.in/go-playground/validator.v9"
 
 	"github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
 )
 
 // Validator builds a custom struct validator.
@@ -34,10 +36,16 @@ func Validator() (*validator.Validate, error) {
 	if err := result.RegisterValidation("snsArn", validateAwsArn); err != nil {
 		return nil, err
 	}
+	if err := result.RegisterValidation("msTeamsWebhook", validateMsTeamsWebhook); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("webhookTemplate", validateWebhookTemplate); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
-var outputTypes = []string{"Slack", "Sns", "PagerDuty", "Github", "Jira", "Opsgenie", "MsTeams", "Sqs", "Asana"}
+var outputTypes = []string{"Slack", "Sns", "PagerDuty", "Github", "Jira", "Opsgenie", "MsTeams", "Sqs", "Asana", "ServiceNow", "CustomWebhook"}
 
 func ensureOneOutput(sl validator.StructLevel) {
 	input := sl.Current()
@@ -58,3 +66,19 @@ func validateAwsArn(fl validator.FieldLevel) bool {
 	fieldArn, err := arn.Parse(fl.Field().String())
 	return err == nil && fieldArn.Service == "sns"
 }
+
+// Incoming webhooks of Teams are https://outlook.office.com/webhook/... or https://<tenant>.webhook.office.com/webhookb2/...
+func validateMsTeamsWebhook(fl validator.FieldLevel) bool {
+	webhookURL, err := url.Parse(fl.Field().String())
+	if err != nil || webhookURL.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(webhookURL.Hostname())
+	return host == "outlook.office.com" || host == "outlook.office365.com" || strings.HasSuffix(host, ".webhook.office.com")
+}
+
+// The templates of custom webhooks are rendered when the alerts are delivered, only their syntax can be checked
+func validateWebhookTemplate(fl validator.FieldLevel) bool {
+	_, err := outputs.ParseWebhookTemplate(fl.Field().String())
+	return err == nil
+}
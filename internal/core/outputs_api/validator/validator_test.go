@@ -29,7 +29,7 @@ import (
 	"github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
-const outputSet = "Slack|Sns|PagerDuty|Github|Jira|Opsgenie|MsTeams|Sqs|Asana"
+const outputSet = "Slack|Sns|PagerDuty|Github|Jira|Opsgenie|MsTeams|Sqs|Asana|ServiceNow|CustomWebhook"
 
 func expectedMsg(structName string, fieldName string, tagName string) string {
 	return fmt.Sprintf(
@@ -116,3 +116,97 @@ func TestAddNonSnsArn(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Sns", "TopicArn", "snsArn"), err.Error())
 }
+
+func TestAddMsTeamsWebhook(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	for _, webhookURL := range []string{
+		"https://outlook.office.com/webhook/a1b2c3/IncomingWebhook/d4e5f6/g7h8",
+		"https://panther.webhook.office.com/webhookb2/a1b2c3/IncomingWebhook/d4e5f6/g7h8",
+	} {
+		assert.NoError(t, validator.Struct(&models.AddOutputInput{
+			UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName:  aws.String("myteam"),
+			OutputConfig: &models.OutputConfig{MsTeams: &models.MsTeamsConfig{WebhookURL: aws.String(webhookURL)}},
+		}), webhookURL)
+	}
+}
+
+func TestAddInvalidMsTeamsWebhook(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	for _, webhookURL := range []string{
+		"http://outlook.office.com/webhook/a1b2c3",
+		"https://hooks.slack.com/services/a1b2c3",
+		"https://webhook.office.com.example.com/webhookb2/a1b2c3",
+	} {
+		err = validator.Struct(&models.AddOutputInput{
+			UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName:  aws.String("myteam"),
+			OutputConfig: &models.OutputConfig{MsTeams: &models.MsTeamsConfig{WebhookURL: aws.String(webhookURL)}},
+		})
+		require.Error(t, err, webhookURL)
+		assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.MsTeams", "WebhookURL", "msTeamsWebhook"), err.Error())
+	}
+}
+
+func TestAddServiceNow(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	config := &models.ServiceNowConfig{
+		InstanceURL:    aws.String("https://panther.service-now.com"),
+		UserName:       aws.String("panther"),
+		Password:       aws.String("password"),
+		UrgencyMapping: map[string]*int{"CRITICAL": aws.Int(1), "INFO": aws.Int(3)},
+		CallbackToken:  aws.String("0123456789abcdef"),
+	}
+	assert.NoError(t, validator.Struct(&models.AddOutputInput{
+		UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName:  aws.String("incidents"),
+		OutputConfig: &models.OutputConfig{ServiceNow: config},
+	}))
+
+	config.UrgencyMapping["INFO"] = aws.Int(4)
+	err = validator.Struct(&models.AddOutputInput{
+		UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName:  aws.String("incidents"),
+		OutputConfig: &models.OutputConfig{ServiceNow: config},
+	})
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.ServiceNow", "UrgencyMapping[INFO]", "max"), err.Error())
+}
+
+func TestAddCustomWebhook(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	config := &models.CustomWebhookConfig{
+		WebhookURL:   aws.String("https://alerts.example.com/panther"),
+		Method:       aws.String("PUT"),
+		Headers:      map[string]*string{"X-Token": aws.String(`{{secret "panther-webhooks/example"}}`)},
+		BodyTemplate: aws.String(`{"title": {{json .Title}}, "severity": {{json .Severity}}}`),
+	}
+	assert.NoError(t, validator.Struct(&models.AddOutputInput{
+		UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName:  aws.String("webhook"),
+		OutputConfig: &models.OutputConfig{CustomWebhook: config},
+	}))
+
+	config.BodyTemplate = aws.String(`{"title": {{json .Title}`)
+	err = validator.Struct(&models.AddOutputInput{
+		UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName:  aws.String("webhook"),
+		OutputConfig: &models.OutputConfig{CustomWebhook: config},
+	})
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.CustomWebhook", "BodyTemplate", "webhookTemplate"), err.Error())
+
+	config.BodyTemplate = aws.String("{}")
+	config.Method = aws.String("GET")
+	err = validator.Struct(&models.AddOutputInput{
+		UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName:  aws.String("webhook"),
+		OutputConfig: &models.OutputConfig{CustomWebhook: config},
+	})
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.CustomWebhook", "Method", "oneof"), err.Error())
+}
@@ -0,0 +1,69 @@
+package main
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/api/lambda/servicenow/models"
+	"github.com/panther-labs/panther/internal/core/servicenow_sync/sync"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/genericapi"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+var methodHandlers = map[string]gatewayapi.RequestHandler{
+	"POST /callback/{outputId}": sync.HandleCallback,
+}
+
+var (
+	callbackProxy = gatewayapi.LambdaProxy(methodHandlers)
+	validate      = validator.New()
+)
+
+// lambdaInput is either a request of the callback API or a direct invocation from the alerts-api
+type lambdaInput struct {
+	events.APIGatewayProxyRequest
+	models.LambdaInput
+}
+
+func lambdaHandler(ctx context.Context, input *lambdaInput) (interface{}, error) {
+	if input.SyncAlertStatus == nil {
+		return callbackProxy(ctx, &input.APIGatewayProxyRequest)
+	}
+
+	lambdalogger.ConfigureGlobal(ctx, nil)
+	if err := validate.Struct(input.SyncAlertStatus); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+	return nil, sync.SyncAlertStatus(input.SyncAlertStatus)
+}
+
+func init() {
+	// Required only once per Lambda container
+	sync.Setup()
+}
+
+func main() {
+	lambda.Start(lambdaHandler)
+}
@@ -0,0 +1,158 @@
+package sync
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	alertmodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The user the alert status changes coming from ServiceNow are made by
+const callbackUserID = "ServiceNow"
+
+// The Panther status of the incident states which close an alert: 6 (Resolved), 7 (Closed) and 8 (Canceled)
+var incidentStateStatuses = map[string]string{
+	"6": alertmodels.StatusResolved,
+	"7": alertmodels.StatusResolved,
+	"8": alertmodels.StatusFalsePositive,
+}
+
+// CallbackRequest is the notification ServiceNow sends when the state of an incident changes.
+type CallbackRequest struct {
+	// The correlation ID of the incident, the ID of its Panther alert
+	CorrelationID string `json:"correlationId"`
+	// The state of the incident, e.g. "6" for Resolved
+	State string `json:"state"`
+}
+
+// CallbackResponse is the alert status the incident state was synced to.
+type CallbackResponse struct {
+	// The new status of the alert, empty if the state doesn't change the alert
+	Status string `json:"status"`
+}
+
+// ErrorResponse explains why a callback was rejected.
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// HandleCallback resolves the Panther alert of an incident which was closed in ServiceNow.
+//
+// The callback is authorized with the callback token of the ServiceNow output of the URL as a bearer token.
+// States which don't close an incident, and alerts which can't be resolved (e.g. they were deleted),
+// are acknowledged without changes.
+func HandleCallback(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	output, err := getOutput(request.PathParameters["outputId"])
+	if err != nil {
+		zap.L().Error("failed to get the ServiceNow output", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if output == nil || !validCallbackToken(request, output.OutputConfig.ServiceNow) {
+		return gatewayapi.MarshalResponse(&ErrorResponse{Message: "the token isn't the callback token of the output"},
+			http.StatusForbidden)
+	}
+
+	var callback CallbackRequest
+	if err = jsoniter.UnmarshalFromString(request.Body, &callback); err != nil {
+		return gatewayapi.MarshalResponse(&ErrorResponse{Message: "invalid callback: " + err.Error()}, http.StatusBadRequest)
+	}
+	if callback.CorrelationID == "" {
+		return gatewayapi.MarshalResponse(&ErrorResponse{Message: "correlationId is required"}, http.StatusBadRequest)
+	}
+
+	status, ok := incidentStateStatuses[callback.State]
+	if !ok {
+		return gatewayapi.MarshalResponse(&CallbackResponse{}, http.StatusOK)
+	}
+
+	input := alertmodels.LambdaInput{UpdateAlertStatus: &alertmodels.UpdateAlertStatusInput{
+		AlertID: aws.String(callback.CorrelationID),
+		Status:  aws.String(status),
+		UserID:  aws.String(callbackUserID),
+	}}
+	if err = genericapi.Invoke(lambdaClient, env.AlertsAPI, &input, nil); err != nil {
+		if isClientError(err) {
+			zap.L().Warn("ignored the state of the ServiceNow incident",
+				zap.String("alertId", callback.CorrelationID),
+				zap.String("state", callback.State),
+				zap.Error(err))
+			return gatewayapi.MarshalResponse(&CallbackResponse{}, http.StatusOK)
+		}
+		zap.L().Error("failed to update the alert status",
+			zap.String("alertId", callback.CorrelationID),
+			zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	return gatewayapi.MarshalResponse(&CallbackResponse{Status: status}, http.StatusOK)
+}
+
+// getOutput returns the ServiceNow output with the ID, or nil if there isn't one
+func getOutput(outputID string) (*outputmodels.AlertOutput, error) {
+	if outputID == "" {
+		return nil, nil
+	}
+	input := outputmodels.LambdaInput{GetOutput: &outputmodels.GetOutputInput{OutputID: aws.String(outputID)}}
+	var output outputmodels.GetOutputOutput
+	if err := genericapi.Invoke(lambdaClient, env.OutputsAPI, &input, &output); err != nil {
+		if isClientError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if output.OutputConfig == nil || output.OutputConfig.ServiceNow == nil {
+		return nil, nil
+	}
+	return &output, nil
+}
+
+func validCallbackToken(request *events.APIGatewayProxyRequest, config *outputmodels.ServiceNowConfig) bool {
+	var authorization string
+	for name, value := range request.Headers {
+		if strings.EqualFold(name, "Authorization") {
+			authorization = value
+		}
+	}
+	expected := "Bearer " + aws.StringValue(config.CallbackToken)
+	return subtle.ConstantTimeCompare([]byte(authorization), []byte(expected)) == 1
+}
+
+// isClientError is true for the errors of requests which can't succeed, e.g. an ID which doesn't exist
+func isClientError(err error) bool {
+	lambdaErr, ok := err.(*genericapi.LambdaError)
+	if !ok {
+		return false
+	}
+	switch aws.StringValue(lambdaErr.ErrorType) {
+	case "InvalidInputError", "DoesNotExistError":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,192 @@
+package sync
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertmodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const testOutputID = "7d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+
+type mockLambdaClient struct {
+	lambdaiface.LambdaAPI
+	mock.Mock
+}
+
+func (m *mockLambdaClient) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
+}
+
+var testOutput = &outputmodels.AlertOutput{
+	OutputID:   aws.String(testOutputID),
+	OutputType: aws.String("servicenow"),
+	OutputConfig: &outputmodels.OutputConfig{
+		ServiceNow: &outputmodels.ServiceNowConfig{
+			InstanceURL:   aws.String("https://example.service-now.com"),
+			UserName:      aws.String("panther"),
+			Password:      aws.String("password"),
+			CallbackToken: aws.String("callback-token-callback-token"),
+		},
+	},
+}
+
+func init() {
+	env.OutputsAPI = "panther-outputs-api"
+	env.AlertsAPI = "panther-alerts-api"
+}
+
+// invokedWith matches the lambda invocations of a function with the payload
+func invokedWith(function string, payload interface{}) interface{} {
+	expected, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	return mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+		return *input.FunctionName == function && string(input.Payload) == string(expected)
+	})
+}
+
+func invokeOutput(payload interface{}) *lambda.InvokeOutput {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	return &lambda.InvokeOutput{Payload: body}
+}
+
+func lambdaErrorOutput(errorType string) *lambda.InvokeOutput {
+	return &lambda.InvokeOutput{
+		FunctionError: aws.String("Unhandled"),
+		Payload:       []byte(`{"errorMessage": "error", "errorType": "` + errorType + `"}`),
+	}
+}
+
+func callbackRequest(token, body string) *events.APIGatewayProxyRequest {
+	return &events.APIGatewayProxyRequest{
+		Body:           body,
+		Headers:        map[string]string{"authorization": "Bearer " + token},
+		PathParameters: map[string]string{"outputId": testOutputID},
+	}
+}
+
+var getOutputInput = outputmodels.LambdaInput{
+	GetOutput: &outputmodels.GetOutputInput{OutputID: aws.String(testOutputID)},
+}
+
+func TestHandleCallbackResolved(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", invokedWith("panther-outputs-api", getOutputInput)).
+		Return(invokeOutput(testOutput), nil).Once()
+	updateInput := alertmodels.LambdaInput{UpdateAlertStatus: &alertmodels.UpdateAlertStatusInput{
+		AlertID: aws.String("alertId"),
+		Status:  aws.String(alertmodels.StatusResolved),
+		UserID:  aws.String("ServiceNow"),
+	}}
+	mockClient.On("Invoke", invokedWith("panther-alerts-api", updateInput)).
+		Return(invokeOutput(&alertmodels.AlertSummary{}), nil).Once()
+
+	response := HandleCallback(callbackRequest("callback-token-callback-token", `{"correlationId": "alertId", "state": "7"}`))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"status":"RESOLVED"}`, response.Body)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleCallbackIgnoredState(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", mock.Anything).Return(invokeOutput(testOutput), nil).Once()
+
+	response := HandleCallback(callbackRequest("callback-token-callback-token", `{"correlationId": "alertId", "state": "2"}`))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"status":""}`, response.Body)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleCallbackInvalidTransition(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", invokedWith("panther-outputs-api", getOutputInput)).
+		Return(invokeOutput(testOutput), nil).Once()
+	mockClient.On("Invoke", mock.Anything).Return(lambdaErrorOutput("InvalidInputError"), nil).Once()
+
+	response := HandleCallback(callbackRequest("callback-token-callback-token", `{"correlationId": "alertId", "state": "8"}`))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"status":""}`, response.Body)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleCallbackWrongToken(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", mock.Anything).Return(invokeOutput(testOutput), nil).Once()
+
+	response := HandleCallback(callbackRequest("wrong-token", `{"correlationId": "alertId", "state": "6"}`))
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleCallbackOutputDoesNotExist(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", mock.Anything).Return(lambdaErrorOutput("DoesNotExistError"), nil).Once()
+
+	response := HandleCallback(callbackRequest("callback-token-callback-token", `{"correlationId": "alertId", "state": "6"}`))
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleCallbackInvalidBody(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", mock.Anything).Return(invokeOutput(testOutput), nil).Twice()
+
+	response := HandleCallback(callbackRequest("callback-token-callback-token", `{"state": "6"}`))
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response = HandleCallback(callbackRequest("callback-token-callback-token", `not json`))
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHandleCallbackUpdateFails(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", invokedWith("panther-outputs-api", getOutputInput)).
+		Return(invokeOutput(testOutput), nil).Once()
+	mockClient.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{}, errors.New("throttled")).Once()
+
+	response := HandleCallback(callbackRequest("callback-token-callback-token", `{"correlationId": "alertId", "state": "6"}`))
+	require.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,51 @@
+package sync
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/kelseyhightower/envconfig"
+)
+
+var (
+	env          envConfig
+	awsSession   *session.Session
+	lambdaClient lambdaiface.LambdaAPI
+	httpClient   = &http.Client{Timeout: 10 * time.Second}
+)
+
+type envConfig struct {
+	// The ServiceNow outputs, with their credentials and callback tokens
+	OutputsAPI string `required:"true" split_words:"true"`
+	// The alerts resolved from ServiceNow are updated through the alerts API
+	AlertsAPI string `required:"true" split_words:"true"`
+}
+
+// Setup parses the environment and builds the AWS clients.
+func Setup() {
+	envconfig.MustProcess("", &env)
+
+	awsSession = session.Must(session.NewSession())
+	lambdaClient = lambda.New(awsSession)
+}
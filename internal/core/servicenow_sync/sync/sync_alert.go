@@ -0,0 +1,157 @@
+package sync
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	alertmodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/api/lambda/servicenow/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const serviceNowOutputType = "servicenow"
+
+// The update of an incident closed by the alert status sync
+type incidentUpdate struct {
+	State      string `json:"state"`
+	CloseCode  string `json:"close_code,omitempty"`
+	CloseNotes string `json:"close_notes"`
+}
+
+// The incident updates closing the incidents of the alerts with each status: 6 (Resolved) or 8 (Canceled)
+var statusIncidentUpdates = map[string]*incidentUpdate{
+	alertmodels.StatusResolved: {
+		State:      "6",
+		CloseCode:  "Solved (Permanently)",
+		CloseNotes: "The Panther alert was resolved",
+	},
+	alertmodels.StatusFalsePositive: {
+		State:      "8",
+		CloseNotes: "The Panther alert was a false positive",
+	},
+}
+
+// The incidents of an alert in a ServiceNow table
+type incidentsResponse struct {
+	Result []struct {
+		SysID string `json:"sys_id"`
+	} `json:"result"`
+}
+
+// SyncAlertStatus closes the open ServiceNow incidents of an alert which was resolved in Panther.
+//
+// Every ServiceNow output is searched, an alert doesn't record the outputs it was delivered to.
+func SyncAlertStatus(input *models.SyncAlertStatusInput) error {
+	update, ok := statusIncidentUpdates[*input.Status]
+	if !ok {
+		return &genericapi.InvalidInputError{Message: "alerts with status " + *input.Status + " aren't synced"}
+	}
+
+	outputsInput := outputmodels.LambdaInput{GetOutputs: &outputmodels.GetOutputsInput{}}
+	var alertOutputs outputmodels.GetOutputsOutput
+	if err := genericapi.Invoke(lambdaClient, env.OutputsAPI, &outputsInput, &alertOutputs); err != nil {
+		return err
+	}
+
+	var result error
+	for _, output := range alertOutputs {
+		if aws.StringValue(output.OutputType) != serviceNowOutputType || output.OutputConfig.ServiceNow == nil {
+			continue
+		}
+		if err := closeIncidents(output.OutputConfig.ServiceNow, *input.AlertID, update); err != nil {
+			zap.L().Error("failed to close the ServiceNow incidents of the alert",
+				zap.String("outputId", aws.StringValue(output.OutputID)),
+				zap.String("alertId", *input.AlertID),
+				zap.Error(err))
+			result = err
+		}
+	}
+	return result
+}
+
+func closeIncidents(config *outputmodels.ServiceNowConfig, alertID string, update *incidentUpdate) error {
+	tableURL := outputs.ServiceNowTableURL(config)
+	query := url.Values{
+		"sysparm_query": []string{fmt.Sprintf("correlation_id=%s^correlation_display=%s^stateNOT IN6,7,8",
+			alertID, outputs.ServiceNowCorrelationDisplay)},
+		"sysparm_fields": []string{"sys_id"},
+	}
+
+	var incidents incidentsResponse
+	if err := serviceNowRequest(config, http.MethodGet, tableURL+"?"+query.Encode(), nil, &incidents); err != nil {
+		return err
+	}
+	for _, incident := range incidents.Result {
+		if err := serviceNowRequest(config, http.MethodPatch, tableURL+"/"+url.PathEscape(incident.SysID), update, nil); err != nil {
+			return err
+		}
+		zap.L().Info("closed ServiceNow incident", zap.String("alertId", alertID), zap.String("sysId", incident.SysID))
+	}
+	return nil
+}
+
+// serviceNowRequest sends a request to the Table API of a ServiceNow instance, the response is unmarshaled to result
+func serviceNowRequest(config *outputmodels.ServiceNowConfig, method, requestURL string,
+	body interface{}, result interface{}) error {
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = jsoniter.Marshal(body); err != nil {
+			return errors.Wrap(err, "failed to marshal the request")
+		}
+	}
+	request, err := http.NewRequest(method, requestURL, bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to build the request")
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(outputs.AuthorizationHTTPHeader, outputs.ServiceNowAuthorization(config))
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, method+" request failed")
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the response")
+	}
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("%s request failed with status %d: %s", method, response.StatusCode, responseBody)
+	}
+	if result == nil {
+		return nil
+	}
+	return errors.Wrap(jsoniter.Unmarshal(responseBody, result), "failed to unmarshal the response")
+}
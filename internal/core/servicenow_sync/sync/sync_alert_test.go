@@ -0,0 +1,114 @@
+package sync
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertmodels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/api/lambda/servicenow/models"
+)
+
+type mockRoundTripper struct {
+	http.RoundTripper
+	mock.Mock
+}
+
+func (m *mockRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	args := m.Called(request)
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
+func httpResponse(statusCode int, body string) *http.Response {
+	return &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+}
+
+func requestTo(method, url string) interface{} {
+	return mock.MatchedBy(func(request *http.Request) bool {
+		return request.Method == method && request.URL.String() == url &&
+			request.Header.Get("Authorization") == "Basic cGFudGhlcjpwYXNzd29yZA=="
+	})
+}
+
+var resolvedInput = &models.SyncAlertStatusInput{
+	AlertID: aws.String("alertId"),
+	Status:  aws.String(alertmodels.StatusResolved),
+}
+
+const incidentsQueryURL = "https://example.service-now.com/api/now/table/incident?" +
+	"sysparm_fields=sys_id&sysparm_query=correlation_id%3DalertId%5Ecorrelation_display%3DPanther%5EstateNOT+IN6%2C7%2C8"
+
+func TestSyncAlertStatus(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	slackOutput := &outputmodels.AlertOutput{
+		OutputID:     aws.String("slack"),
+		OutputType:   aws.String("slack"),
+		OutputConfig: &outputmodels.OutputConfig{Slack: &outputmodels.SlackConfig{WebhookURL: aws.String("https://slack")}},
+	}
+	mockClient.On("Invoke", invokedWith("panther-outputs-api", outputmodels.LambdaInput{GetOutputs: &outputmodels.GetOutputsInput{}})).
+		Return(invokeOutput([]*outputmodels.AlertOutput{slackOutput, testOutput}), nil).Once()
+
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: mockRoundTripper}
+	mockRoundTripper.On("RoundTrip", requestTo(http.MethodGet, incidentsQueryURL)).
+		Return(httpResponse(http.StatusOK, `{"result": [{"sys_id": "first"}, {"sys_id": "second"}]}`), nil).Once()
+	for _, sysID := range []string{"first", "second"} {
+		mockRoundTripper.On("RoundTrip", requestTo(http.MethodPatch, "https://example.service-now.com/api/now/table/incident/"+sysID)).
+			Return(httpResponse(http.StatusOK, `{}`), nil).Once()
+	}
+
+	require.NoError(t, SyncAlertStatus(resolvedInput))
+	mockClient.AssertExpectations(t)
+	mockRoundTripper.AssertExpectations(t)
+
+	patch := mockRoundTripper.Calls[1].Arguments.Get(0).(*http.Request)
+	body, err := ioutil.ReadAll(patch.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"state": "6", "close_code": "Solved (Permanently)", "close_notes": "The Panther alert was resolved"}`,
+		string(body))
+}
+
+func TestSyncAlertStatusRequestFails(t *testing.T) {
+	mockClient := &mockLambdaClient{}
+	lambdaClient = mockClient
+	mockClient.On("Invoke", mock.Anything).Return(invokeOutput([]*outputmodels.AlertOutput{testOutput}), nil).Once()
+
+	mockRoundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: mockRoundTripper}
+	mockRoundTripper.On("RoundTrip", mock.Anything).Return(httpResponse(http.StatusUnauthorized, `{}`), nil).Once()
+
+	assert.Error(t, SyncAlertStatus(resolvedInput))
+	mockClient.AssertExpectations(t)
+	mockRoundTripper.AssertExpectations(t)
+}
+
+func TestSyncAlertStatusNotClosed(t *testing.T) {
+	err := SyncAlertStatus(&models.SyncAlertStatusInput{AlertID: aws.String("alertId"), Status: aws.String("OPEN")})
+	assert.Error(t, err)
+}
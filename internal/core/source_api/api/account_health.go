@@ -0,0 +1,120 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const defaultAccountHealthPageSize = 25
+
+// Scan statuses ordered from best to worst
+var scanStatusSeverity = map[string]int{
+	models.StatusOK:       0,
+	models.StatusScanning: 1,
+	models.StatusError:    2,
+}
+
+// GetAccountHealthSummary returns the health of integrations grouped by AWS account.
+//
+// Accounts are sorted by ID, each one reports the number of integrations in each scan status
+// along with the worst of those statuses.
+func (API) GetAccountHealthSummary(input *models.GetAccountHealthSummaryInput) (*models.AccountHealthSummaryPage, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := summarizeAccountHealth(integrations)
+	page, pageSize := 1, defaultAccountHealthPageSize
+	if input.Page != nil {
+		page = *input.Page
+	}
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+	return pageAccountHealth(summaries, page, pageSize), nil
+}
+
+// summarizeAccountHealth rolls up the scan status of each integration into its account, sorted by account ID.
+func summarizeAccountHealth(integrations []*models.SourceIntegration) []*models.AccountHealthSummary {
+	byAccount := make(map[string]*models.AccountHealthSummary)
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil {
+			continue
+		}
+
+		accountID := aws.StringValue(integration.AWSAccountID)
+		summary, ok := byAccount[accountID]
+		if !ok {
+			summary = &models.AccountHealthSummary{
+				AWSAccountID:     aws.String(accountID),
+				IntegrationCount: aws.Int(0),
+				StatusCounts:     make(map[string]int),
+			}
+			byAccount[accountID] = summary
+		}
+		*summary.IntegrationCount++
+
+		if integration.SourceIntegrationStatus == nil || integration.ScanStatus == nil {
+			continue
+		}
+		status := *integration.ScanStatus
+		summary.StatusCounts[status]++
+		if summary.WorstStatus == nil || scanStatusSeverity[status] > scanStatusSeverity[*summary.WorstStatus] {
+			summary.WorstStatus = aws.String(status)
+		}
+	}
+
+	result := make([]*models.AccountHealthSummary, 0, len(byAccount))
+	for _, summary := range byAccount {
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return *result[i].AWSAccountID < *result[j].AWSAccountID })
+	return result
+}
+
+func pageAccountHealth(summaries []*models.AccountHealthSummary, page, pageSize int) *models.AccountHealthSummaryPage {
+	totalPages := len(summaries) / pageSize
+	if len(summaries)%pageSize > 0 {
+		totalPages++ // Add one more to page count if there is an incomplete page at the end
+	}
+
+	paging := &models.Paging{
+		ThisPage:   aws.Int(page),
+		TotalItems: aws.Int(len(summaries)),
+		TotalPages: aws.Int(totalPages),
+	}
+
+	// Truncate summaries to just the requested page
+	lowerBound := intMin((page-1)*pageSize, len(summaries))
+	upperBound := intMin(page*pageSize, len(summaries))
+	return &models.AccountHealthSummaryPage{Accounts: summaries[lowerBound:upperBound], Paging: paging}
+}
+
+func intMin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func integrationScanItem(accountID string, scanStatus string) map[string]*dynamodb.AttributeValue {
+	item := map[string]*dynamodb.AttributeValue{"awsAccountId": {S: aws.String(accountID)}}
+	if scanStatus != "" {
+		item["scanStatus"] = &dynamodb.AttributeValue{S: aws.String(scanStatus)}
+	}
+	return item
+}
+
+func TestGetAccountHealthSummary(t *testing.T) {
+	db = &ddb.DDB{
+		Client: &modelstest.MockDDBClient{
+			MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+				integrationScanItem("222222222222", models.StatusOK),
+				integrationScanItem("111111111111", models.StatusOK),
+				integrationScanItem("111111111111", models.StatusError),
+				integrationScanItem("111111111111", models.StatusScanning),
+				integrationScanItem("222222222222", models.StatusScanning),
+				integrationScanItem("333333333333", ""),
+			},
+		},
+		TableName: "test",
+	}
+
+	result, err := apiTest.GetAccountHealthSummary(&models.GetAccountHealthSummaryInput{})
+	require.NoError(t, err)
+
+	expected := &models.AccountHealthSummaryPage{
+		Accounts: []*models.AccountHealthSummary{
+			{
+				AWSAccountID:     aws.String("111111111111"),
+				IntegrationCount: aws.Int(3),
+				StatusCounts:     map[string]int{models.StatusOK: 1, models.StatusError: 1, models.StatusScanning: 1},
+				WorstStatus:      aws.String(models.StatusError),
+			},
+			{
+				AWSAccountID:     aws.String("222222222222"),
+				IntegrationCount: aws.Int(2),
+				StatusCounts:     map[string]int{models.StatusOK: 1, models.StatusScanning: 1},
+				WorstStatus:      aws.String(models.StatusScanning),
+			},
+			{
+				AWSAccountID:     aws.String("333333333333"),
+				IntegrationCount: aws.Int(1),
+				StatusCounts:     map[string]int{},
+			},
+		},
+		Paging: &models.Paging{ThisPage: aws.Int(1), TotalItems: aws.Int(3), TotalPages: aws.Int(1)},
+	}
+	assert.Equal(t, expected, result)
+}
+
+func TestGetAccountHealthSummaryPaging(t *testing.T) {
+	db = &ddb.DDB{
+		Client: &modelstest.MockDDBClient{
+			MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+				integrationScanItem("111111111111", models.StatusOK),
+				integrationScanItem("222222222222", models.StatusOK),
+				integrationScanItem("333333333333", models.StatusError),
+			},
+		},
+		TableName: "test",
+	}
+
+	result, err := apiTest.GetAccountHealthSummary(&models.GetAccountHealthSummaryInput{
+		Page:     aws.Int(2),
+		PageSize: aws.Int(2),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Accounts, 1)
+	assert.Equal(t, "333333333333", *result.Accounts[0].AWSAccountID)
+	assert.Equal(t, &models.Paging{ThisPage: aws.Int(2), TotalItems: aws.Int(3), TotalPages: aws.Int(2)}, result.Paging)
+}
@@ -0,0 +1,322 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The order in which the planned actions are applied
+var manifestActionOrder = map[string]int{
+	models.ManifestActionDelete:    0,
+	models.ManifestActionUpdate:    1,
+	models.ManifestActionCreate:    2,
+	models.ManifestActionUnchanged: 3,
+}
+
+// manifestStep is the planned action for one integration of an account manifest.
+type manifestStep struct {
+	result *models.AccountManifestResult
+	// Set when the integration is created
+	settings *models.PutIntegrationSettings
+	// Set when the integration is updated
+	update   *models.UpdateIntegrationSettingsInput
+	existing *models.SourceIntegrationMetadata
+}
+
+// ApplyAccountManifest converges the integrations of an AWS account to a manifest.
+//
+// The manifest is validated as a whole before anything is planned: labels must be unique and no bucket can be
+// used by two of its integrations. A dry run health checks every created or updated integration without writing
+// anything. Otherwise each planned action is applied, a failed action doesn't stop the others.
+// The results follow the order of the manifest, followed by the deleted integrations.
+func (api API) ApplyAccountManifest(input *models.ApplyAccountManifestInput) ([]*models.AccountManifestResult, error) {
+	api = api.withAlertOutputs()
+	if err := validateManifest(input); err != nil {
+		return nil, err
+	}
+	plan, err := planManifest(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deletions are applied first, an account can only have one enabled cloud security integration
+	steps := append([]*manifestStep(nil), plan...)
+	sort.SliceStable(steps, func(i, j int) bool {
+		return manifestActionOrder[*steps[i].result.Action] < manifestActionOrder[*steps[j].result.Action]
+	})
+	for _, step := range steps {
+		if aws.BoolValue(input.DryRun) {
+			err = step.check(api)
+		} else {
+			err = step.apply(api, input.UserID)
+		}
+		step.result.Success = aws.Bool(err == nil)
+		if err != nil {
+			step.result.ErrorMessage = aws.String(err.Error())
+		}
+	}
+
+	results := make([]*models.AccountManifestResult, len(plan))
+	for i, step := range plan {
+		results[i] = step.result
+	}
+	return results, nil
+}
+
+// validateManifest checks the integrations of a manifest against each other.
+func validateManifest(input *models.ApplyAccountManifestInput) error {
+	labels := make(map[string]string, len(input.Integrations))
+	bucketLabels := make(map[string]string)
+	for _, integration := range input.Integrations {
+		label := strings.TrimSpace(aws.StringValue(integration.IntegrationLabel))
+		if label == "" {
+			return &genericapi.InvalidInputError{Message: "every integration of the manifest needs an integrationLabel"}
+		}
+		if *integration.AWSAccountID != *input.AWSAccountID {
+			return &genericapi.InvalidInputError{
+				Message: fmt.Sprintf("integration %q is not for account %s", label, *input.AWSAccountID)}
+		}
+		key := labelKey(input.AWSAccountID, integration.IntegrationLabel)
+		if _, ok := labels[key]; ok {
+			return &genericapi.InvalidInputError{Message: fmt.Sprintf("integration label %q is used more than once", label)}
+		}
+		labels[key] = label
+
+		if err := checkBucketRegions(integration.S3Buckets, integration.S3BucketRegions); err != nil {
+			return err
+		}
+		// Entries of the same integration can share a bucket with different prefixes
+		for _, entry := range integration.S3Buckets {
+			bucket, _ := parseBucketEntry(aws.StringValue(entry))
+			if other, ok := bucketLabels[bucket]; ok && other != label {
+				return &genericapi.InvalidInputError{
+					Message: fmt.Sprintf("bucket %s is used by both %q and %q", bucket, other, label)}
+			}
+			bucketLabels[bucket] = label
+		}
+	}
+	return nil
+}
+
+// planManifest matches the integrations of the manifest to the existing ones of the account.
+//
+// The steps follow the order of the manifest, the deletions come last.
+func planManifest(input *models.ApplyAccountManifestInput) ([]*manifestStep, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string][]*models.SourceIntegrationMetadata)
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil || aws.StringValue(integration.AWSAccountID) != *input.AWSAccountID {
+			continue
+		}
+		key := labelKey(integration.AWSAccountID, integration.IntegrationLabel)
+		existing[key] = append(existing[key], integration.SourceIntegrationMetadata)
+	}
+
+	plan := make([]*manifestStep, 0, len(input.Integrations))
+	for _, settings := range input.Integrations {
+		key := labelKey(settings.AWSAccountID, settings.IntegrationLabel)
+		matches := existing[key]
+		delete(existing, key)
+
+		if len(matches) == 0 {
+			plan = append(plan, &manifestStep{
+				settings: settings,
+				result: &models.AccountManifestResult{
+					IntegrationLabel: settings.IntegrationLabel,
+					IntegrationType:  settings.IntegrationType,
+					Action:           aws.String(models.ManifestActionCreate),
+					Changes:          creationChanges(generateNewIntegration(settings)),
+				},
+			})
+			continue
+		}
+		if len(matches) > 1 {
+			return nil, &genericapi.InvalidInputError{Message: fmt.Sprintf(
+				"integration label %q matches %d integrations of the account", *settings.IntegrationLabel, len(matches))}
+		}
+
+		integration := matches[0]
+		if *integration.IntegrationType != *settings.IntegrationType {
+			return nil, &genericapi.InvalidInputError{Message: fmt.Sprintf("integration %q is of type %s, not %s",
+				*settings.IntegrationLabel, *integration.IntegrationType, *settings.IntegrationType)}
+		}
+		step := &manifestStep{
+			update:   manifestUpdate(integration.IntegrationID, settings, input.UserID),
+			existing: integration,
+			result: &models.AccountManifestResult{
+				IntegrationID:    integration.IntegrationID,
+				IntegrationLabel: integration.IntegrationLabel,
+				IntegrationType:  integration.IntegrationType,
+				Action:           aws.String(models.ManifestActionUpdate),
+			},
+		}
+		step.result.Changes = diffIntegration(integration, step.update)
+		if len(step.result.Changes) == 0 {
+			step.result.Action = aws.String(models.ManifestActionUnchanged)
+		}
+		plan = append(plan, step)
+	}
+
+	// The remaining integrations of the account are not part of the manifest
+	var deletions []*manifestStep
+	for _, integrations := range existing {
+		for _, integration := range integrations {
+			deletions = append(deletions, &manifestStep{
+				existing: integration,
+				result: &models.AccountManifestResult{
+					IntegrationID:    integration.IntegrationID,
+					IntegrationLabel: integration.IntegrationLabel,
+					IntegrationType:  integration.IntegrationType,
+					Action:           aws.String(models.ManifestActionDelete),
+					Changes:          make([]*models.IntegrationFieldChange, 0),
+				},
+			})
+		}
+	}
+	sort.Slice(deletions, func(i, j int) bool {
+		return aws.StringValue(deletions[i].existing.IntegrationLabel) < aws.StringValue(deletions[j].existing.IntegrationLabel)
+	})
+	return append(plan, deletions...), nil
+}
+
+// manifestUpdate is the update converging an existing integration to its settings in the manifest.
+func manifestUpdate(
+	integrationID *string, settings *models.PutIntegrationSettings, userID *string) *models.UpdateIntegrationSettingsInput {
+
+	update := &models.UpdateIntegrationSettingsInput{
+		IntegrationID:         integrationID,
+		IntegrationLabel:      settings.IntegrationLabel,
+		Description:           settings.Description,
+		ScanEnabled:           settings.ScanEnabled,
+		CWEEnabled:            settings.CWEEnabled,
+		RemediationEnabled:    settings.RemediationEnabled,
+		ScanIntervalMins:      settings.ScanIntervalMins,
+		S3BucketRegions:       settings.S3BucketRegions,
+		AllowDuplicateLabel:   settings.AllowDuplicateLabel,
+		AllowPartialHealth:    settings.AllowPartialHealth,
+		CreateKmsGrants:       settings.CreateKmsGrants,
+		IsOrgTrail:            settings.IsOrgTrail,
+		ManagementAccountID:   settings.ManagementAccountID,
+		StreamARN:             settings.StreamARN,
+		RoleChain:             settings.RoleChain,
+		ShardIteratorType:     settings.ShardIteratorType,
+		MaxConcurrentObjects:  settings.MaxConcurrentObjects,
+		MaxObjectsPerScan:     settings.MaxObjectsPerScan,
+		OrderingMode:          settings.OrderingMode,
+		MaxParallelism:        settings.MaxParallelism,
+		DedupWindowMinutes:    settings.DedupWindowMinutes,
+		MaxRecordBytes:        settings.MaxRecordBytes,
+		OversizedRecordPolicy: settings.OversizedRecordPolicy,
+		SampleRate:            settings.SampleRate,
+		ErrorRateThreshold:    settings.ErrorRateThreshold,
+
+		SilenceThresholdMinutes: settings.SilenceThresholdMinutes,
+		EventErrorRateThreshold: settings.EventErrorRateThreshold,
+
+		TimeExtraction:        settings.TimeExtraction,
+		TimestampSkew:         settings.TimestampSkew,
+		DeadLetterQueue:       settings.DeadLetterQueue,
+		ArchiveFormat:         settings.ArchiveFormat,
+		DataClassification:    settings.DataClassification,
+		ProcessingRegion:      settings.ProcessingRegion,
+		BlackoutWindows:       settings.BlackoutWindows,
+		ScanSchedule:          settings.ScanSchedule,
+		EnabledRegions:        settings.EnabledRegions,
+		ExcludedRegions:       settings.ExcludedRegions,
+		ResourceTypeAllowList: settings.ResourceTypeAllowList,
+		ResourceTypeDenyList:  settings.ResourceTypeDenyList,
+		RedactionRules:        settings.RedactionRules,
+		IncludePatterns:       settings.IncludePatterns,
+		ExcludePatterns:       settings.ExcludePatterns,
+		DisabledChecks:        settings.DisabledChecks,
+		FeatureFlags:          settings.FeatureFlags,
+		DependsOn:             settings.DependsOn,
+		NotificationTargets:   settings.NotificationTargets,
+		EnrichmentSources:     settings.EnrichmentSources,
+		Tags:                  settings.Tags,
+		SeverityOverrides:     settings.SeverityOverrides,
+		UserID:                userID,
+
+		SessionDurationSeconds:  settings.SessionDurationSeconds,
+		ScanCompleteCallbackURL: settings.ScanCompleteCallbackURL,
+	}
+	// An empty list is the same as a list which is not set in the manifest
+	if len(settings.S3Buckets) > 0 {
+		update.S3Buckets = settings.S3Buckets
+	}
+	if len(settings.KmsKeys) > 0 {
+		update.KmsKeys = settings.KmsKeys
+	}
+	if len(settings.OrganizationExcludedAccounts) > 0 {
+		update.OrganizationExcludedAccounts = settings.OrganizationExcludedAccounts
+	}
+	return update
+}
+
+// check runs the health check of a created or updated integration, and the deletion checks of a deleted one.
+func (step *manifestStep) check(api API) error {
+	var err error
+	switch *step.result.Action {
+	case models.ManifestActionCreate:
+		_, _, _, err = checkIntegrationHealth(
+			api, healthCheckInputForNew(step.settings), aws.BoolValue(step.settings.AllowPartialHealth))
+	case models.ManifestActionUpdate:
+		_, _, _, err = checkIntegrationHealth(
+			api, healthCheckInputForUpdate(step.existing, step.update), aws.BoolValue(step.update.AllowPartialHealth))
+	case models.ManifestActionDelete:
+		err = checkDependents(&models.DeleteIntegrationInput{IntegrationID: step.existing.IntegrationID})
+	}
+	return err
+}
+
+// apply writes the planned action, with the same checks as the individual endpoints.
+func (step *manifestStep) apply(api API, userID *string) error {
+	switch *step.result.Action {
+	case models.ManifestActionCreate:
+		integrations, err := api.PutIntegration(&models.PutIntegrationInput{
+			Integrations: []*models.PutIntegrationSettings{step.settings},
+		})
+		if err != nil {
+			return err
+		}
+		if len(integrations) == 0 {
+			return &genericapi.AlreadyExistsError{Message: fmt.Sprintf("integration of type %s already exists for %s",
+				*step.settings.IntegrationType, *step.settings.AWSAccountID)}
+		}
+		step.result.IntegrationID = integrations[0].IntegrationID
+		return nil
+	case models.ManifestActionUpdate:
+		_, err := api.UpdateIntegrationSettings(step.update)
+		return err
+	case models.ManifestActionDelete:
+		return api.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: step.existing.IntegrationID, UserID: userID})
+	}
+	return nil
+}
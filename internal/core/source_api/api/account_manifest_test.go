@@ -0,0 +1,250 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testObsoleteIntegrationID = "0e8b4a2d-53c4-4a63-9d55-8d6a6d2a1d9b"
+
+// mockAccountDDBClient removes the deleted integrations from the scanned ones.
+type mockAccountDDBClient struct {
+	*mockBatchWriteDDBClient
+}
+
+func (client *mockAccountDDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	kept := client.MockScanAttributes[:0]
+	for _, item := range client.MockScanAttributes {
+		if *item["integrationId"].S != *input.Key["integrationId"].S {
+			kept = append(kept, item)
+		}
+	}
+	client.MockScanAttributes = kept
+	return client.MockDDBClient.DeleteItem(input)
+}
+
+// mockAccountIntegrations stores a log integration which is part of the manifest, a cloud security integration
+// which is not, and an integration of another account.
+func mockAccountIntegrations(t *testing.T) *mockAccountDDBClient {
+	stored := []*models.SourceIntegrationMetadata{
+		{
+			AWSAccountID:     aws.String(testAccountID),
+			IntegrationID:    aws.String(testIntegrationID),
+			IntegrationLabel: aws.String("logs"),
+			IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+			S3Buckets:        aws.StringSlice([]string{"logs-v1"}),
+		},
+		{
+			AWSAccountID:     aws.String(testAccountID),
+			IntegrationID:    aws.String(testObsoleteIntegrationID),
+			IntegrationLabel: aws.String("cloudsec"),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+			ScanIntervalMins: aws.Int(60),
+		},
+		{
+			AWSAccountID:     aws.String("210987654321"),
+			IntegrationID:    aws.String(testDependencyID),
+			IntegrationLabel: aws.String("other-account"),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+		},
+	}
+
+	mockClient := &mockAccountDDBClient{&mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}}
+	for _, integration := range stored {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		mockClient.MockScanAttributes = append(mockClient.MockScanAttributes, item)
+		mockClient.On("GetItem", getItemFor(*integration.IntegrationID)).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+	}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	return mockClient
+}
+
+// testManifest adds a bucket to the log integration and replaces the cloud security integration.
+func testManifest() *models.ApplyAccountManifestInput {
+	return &models.ApplyAccountManifestInput{
+		AWSAccountID: aws.String(testAccountID),
+		UserID:       aws.String(testUserID),
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:     aws.String(testAccountID),
+				IntegrationLabel: aws.String("logs"),
+				IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+				S3Buckets:        aws.StringSlice([]string{"logs-v1", "logs-v2"}),
+				UserID:           aws.String(testUserID),
+			},
+			{
+				AWSAccountID:     aws.String(testAccountID),
+				IntegrationLabel: aws.String("cloudsec-prod"),
+				IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+				ScanEnabled:      aws.Bool(true),
+				ScanIntervalMins: aws.Int(1440),
+				UserID:           aws.String(testUserID),
+			},
+		},
+	}
+}
+
+func TestApplyAccountManifestDryRun(t *testing.T) {
+	mockClient := mockAccountIntegrations(t)
+	var checked []string
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = append(checked, *input.IntegrationType)
+		return *input.IntegrationType == models.IntegrationTypeAWSScan, nil, nil
+	}
+	input := testManifest()
+	input.DryRun = aws.Bool(true)
+
+	results, err := apiTest.ApplyAccountManifest(input)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	update := results[0]
+	assert.Equal(t, testIntegrationID, *update.IntegrationID)
+	assert.Equal(t, models.ManifestActionUpdate, *update.Action)
+	assert.Equal(t, []*models.IntegrationFieldChange{
+		{Field: aws.String("s3Buckets"), Action: aws.String(models.FieldAdded), Desired: "logs-v2"},
+	}, update.Changes)
+	// The new bucket is not reachable
+	assert.False(t, *update.Success)
+	assert.Contains(t, *update.ErrorMessage, "did not pass health check")
+
+	create := results[1]
+	assert.Nil(t, create.IntegrationID)
+	assert.Equal(t, "cloudsec-prod", *create.IntegrationLabel)
+	assert.Equal(t, models.ManifestActionCreate, *create.Action)
+	assert.Contains(t, create.Changes, &models.IntegrationFieldChange{
+		Field: aws.String("scanIntervalMins"), Action: aws.String(models.FieldAdded), Desired: 1440})
+	assert.True(t, *create.Success)
+
+	deletion := results[2]
+	assert.Equal(t, testObsoleteIntegrationID, *deletion.IntegrationID)
+	assert.Equal(t, models.ManifestActionDelete, *deletion.Action)
+	assert.True(t, *deletion.Success)
+
+	assert.Equal(t, []string{models.IntegrationTypeAWS3, models.IntegrationTypeAWSScan}, checked)
+	// Nothing was written
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.Anything)
+	assert.Empty(t, mockClient.written)
+}
+
+func TestApplyAccountManifestConverges(t *testing.T) {
+	mockClient := mockAccountIntegrations(t)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	results, err := apiTest.ApplyAccountManifest(testManifest())
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.True(t, *result.Success, *result.IntegrationLabel)
+		assert.Nil(t, result.ErrorMessage, *result.IntegrationLabel)
+	}
+
+	// The bucket was added to the existing log integration
+	mockClient.AssertCalled(t, "UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["integrationId"].S == testIntegrationID
+	}))
+	// The cloud security integration missing from the manifest was deleted before its replacement was created
+	mockClient.AssertCalled(t, "DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return *input.Key["integrationId"].S == testObsoleteIntegrationID
+	}))
+	require.Len(t, mockClient.written, 1)
+	var created models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &created))
+	assert.Equal(t, "cloudsec-prod", *created.IntegrationLabel)
+	assert.Equal(t, 1440, *created.ScanIntervalMins)
+	assert.Equal(t, *created.IntegrationID, *results[1].IntegrationID)
+	// The integration of the other account is untouched
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return *input.Key["integrationId"].S == testDependencyID
+	}))
+}
+
+func TestApplyAccountManifestUnchanged(t *testing.T) {
+	mockClient := mockAccountIntegrations(t)
+	input := testManifest()
+	input.Integrations = input.Integrations[:1]
+	input.Integrations[0].S3Buckets = aws.StringSlice([]string{"logs-v1"})
+	input.DryRun = aws.Bool(true)
+
+	results, err := apiTest.ApplyAccountManifest(input)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, models.ManifestActionUnchanged, *results[0].Action)
+	assert.Empty(t, results[0].Changes)
+	assert.True(t, *results[0].Success)
+	assert.Equal(t, models.ManifestActionDelete, *results[1].Action)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestApplyAccountManifestInvalid(t *testing.T) {
+	mockAccountIntegrations(t)
+
+	duplicateLabel := testManifest()
+	duplicateLabel.Integrations[1].IntegrationLabel = aws.String(" LOGS ")
+
+	duplicateBucket := testManifest()
+	duplicateBucket.Integrations = append(duplicateBucket.Integrations, &models.PutIntegrationSettings{
+		AWSAccountID:     aws.String(testAccountID),
+		IntegrationLabel: aws.String("more-logs"),
+		IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:        aws.StringSlice([]string{"logs-v2/other/*"}),
+		UserID:           aws.String(testUserID),
+	})
+
+	otherAccount := testManifest()
+	otherAccount.Integrations[1].AWSAccountID = aws.String("210987654321")
+
+	changedType := testManifest()
+	changedType.Integrations[0].IntegrationType = aws.String(models.IntegrationTypeAWSScan)
+
+	for name, input := range map[string]*models.ApplyAccountManifestInput{
+		"duplicate label":  duplicateLabel,
+		"duplicate bucket": duplicateBucket,
+		"other account":    otherAccount,
+		"changed type":     changedType,
+	} {
+		results, err := apiTest.ApplyAccountManifest(input)
+		assert.Nil(t, results, name)
+		assert.IsType(t, &genericapi.InvalidInputError{}, err, name)
+	}
+}
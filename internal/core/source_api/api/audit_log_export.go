@@ -0,0 +1,194 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	auditLogKeyPrefix = "audit-log/"
+
+	// Events sent by a single export, the next export of the range resumes after them
+	maxAuditEventsPerExport = 1000
+
+	// The most messages SQS accepts in a batch
+	maxAuditMessagesPerBatch = 10
+)
+
+// ExportAuditLog sends the changes made to the integrations within a time range to a destination of the deployment.
+//
+// The changes are sent in the order they were made, as AuditLogEvents. Each export sends at most
+// maxAuditEventsPerExport of them and returns a cursor while the range has more: exporting the range again with
+// the cursor resumes after the last event sent. The s3 destination receives an object per export in JSON lines,
+// named after its first event. The sqs destination receives a message per event; a FIFO queue keeps them in order
+// and drops the events sent again.
+func (API) ExportAuditLog(input *models.ExportAuditLogInput) (*models.AuditLogExport, error) {
+	switch {
+	case *input.Destination == models.AuditDestinationS3 && auditExportBucket == "":
+		return nil, &genericapi.InvalidInputError{Message: "no audit export bucket is configured"}
+	case *input.Destination == models.AuditDestinationSQS && auditExportQueueURL == "":
+		return nil, &genericapi.InvalidInputError{Message: "no audit export queue is configured"}
+	}
+
+	// Change IDs start with the time of the change, the cursor is the ID of the last change sent
+	after := input.StartTime.UTC().Format(changeIDTimeFormat)
+	if input.Cursor != nil && *input.Cursor > after {
+		after = *input.Cursor
+	}
+	changes, err := db.ScanChanges(after, input.EndTime.UTC().Format(changeIDTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(changes, func(i, j int) bool { return *changes[i].ChangeID < *changes[j].ChangeID })
+
+	result := &models.AuditLogExport{Destination: input.Destination, EventCount: aws.Int(0)}
+	if len(changes) > maxAuditEventsPerExport {
+		changes = changes[:maxAuditEventsPerExport]
+		result.Cursor = changes[len(changes)-1].ChangeID
+	}
+	if len(changes) == 0 {
+		return result, nil
+	}
+	events := make([]*models.AuditLogEvent, len(changes))
+	for i, change := range changes {
+		events[i] = auditLogEvent(change)
+	}
+
+	if *input.Destination == models.AuditDestinationS3 {
+		if result.Key, err = putAuditLogObject(events); err != nil {
+			return nil, err
+		}
+		result.EventCount = aws.Int(len(events))
+		return result, nil
+	}
+
+	sent, err := sendAuditLogMessages(events)
+	if err != nil && sent == 0 {
+		return nil, err
+	}
+	result.EventCount = aws.Int(sent)
+	if err != nil {
+		zap.L().Warn("audit log export interrupted", zap.Int("sent", sent), zap.Error(err))
+		result.Cursor = events[sent-1].EventID
+		result.ErrorMessage = aws.String(err.Error())
+	}
+	return result, nil
+}
+
+func auditLogEvent(change *models.IntegrationChangeRecord) *models.AuditLogEvent {
+	changes := change.Changes
+	if changes == nil {
+		changes = make([]*models.IntegrationFieldChange, 0)
+	}
+	return &models.AuditLogEvent{
+		SchemaVersion:  aws.Int(models.AuditLogSchemaVersion),
+		EventID:        change.ChangeID,
+		EventTime:      change.ChangedAt,
+		IntegrationID:  change.IntegrationID,
+		Actor:          change.ChangedBy,
+		CallerIdentity: change.CallerIdentity,
+		Operation:      change.Operation,
+		Changes:        changes,
+	}
+}
+
+// putAuditLogObject writes the events to an object of the audit export bucket, a line per event, and returns its key.
+func putAuditLogObject(events []*models.AuditLogEvent) (*string, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, &genericapi.InternalError{Message: "failed to marshal audit log event: " + err.Error()}
+		}
+	}
+
+	key := aws.String(auditLogKeyPrefix + *events[0].EventID + ".jsonl")
+	_, err := exportClient.PutObject(&s3.PutObjectInput{
+		Body:        bytes.NewReader(body.Bytes()),
+		Bucket:      aws.String(auditExportBucket),
+		ContentType: aws.String("application/x-ndjson"),
+		Key:         key,
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "S3.PutObject"}
+	}
+	return key, nil
+}
+
+// sendAuditLogMessages sends the events to the audit export queue in order, and returns how many were sent
+// before the first one which failed.
+func sendAuditLogMessages(events []*models.AuditLogEvent) (int, error) {
+	fifo := strings.HasSuffix(auditExportQueueURL, ".fifo")
+	for start := 0; start < len(events); start += maxAuditMessagesPerBatch {
+		end := start + maxAuditMessagesPerBatch
+		if end > len(events) {
+			end = len(events)
+		}
+		entries := make([]*sqs.SendMessageBatchRequestEntry, 0, end-start)
+		for i, event := range events[start:end] {
+			body, err := json.Marshal(event)
+			if err != nil {
+				return start, &genericapi.InternalError{Message: "failed to marshal audit log event: " + err.Error()}
+			}
+			entry := &sqs.SendMessageBatchRequestEntry{
+				Id:          aws.String(strconv.Itoa(i)),
+				MessageBody: aws.String(string(body)),
+			}
+			if fifo {
+				entry.MessageGroupId = aws.String("audit-log")
+				entry.MessageDeduplicationId = event.EventID
+			}
+			entries = append(entries, entry)
+		}
+
+		output, err := SQSClient.SendMessageBatch(&sqs.SendMessageBatchInput{
+			Entries:  entries,
+			QueueUrl: aws.String(auditExportQueueURL),
+		})
+		if err != nil {
+			return start, &genericapi.AWSError{Err: err, Method: "SQS.SendMessageBatch"}
+		}
+		// The events are resumed from the first one which failed
+		failedAt, failure := len(entries), (*sqs.BatchResultErrorEntry)(nil)
+		for _, failed := range output.Failed {
+			if i, _ := strconv.Atoi(aws.StringValue(failed.Id)); i < failedAt {
+				failedAt, failure = i, failed
+			}
+		}
+		if failure != nil {
+			return start + failedAt, &genericapi.AWSError{Method: "SQS.SendMessageBatch",
+				Err: errors.New(aws.StringValue(failure.Code) + ": " + aws.StringValue(failure.Message))}
+		}
+	}
+	return len(events), nil
+}
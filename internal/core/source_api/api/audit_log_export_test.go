@@ -0,0 +1,250 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var auditExportStart = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// mockChangesScanClient returns the changes between the bounds of the filter, most recent first, a few per page.
+type mockChangesScanClient struct {
+	*modelstest.MockDDBClient
+	changes []*models.IntegrationChangeRecord
+}
+
+func (client *mockChangesScanClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	after, before := *input.ExpressionAttributeValues[":0"].S, *input.ExpressionAttributeValues[":1"].S
+	var items []map[string]*dynamodb.AttributeValue
+	for i := len(client.changes) - 1; i >= 0; i-- {
+		if id := *client.changes[i].ChangeID; id > after && id < before {
+			item, err := dynamodbattribute.MarshalMap(client.changes[i])
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+
+	const pageSize = 7
+	start := 0
+	if input.ExclusiveStartKey != nil {
+		start, _ = strconv.Atoi(*input.ExclusiveStartKey["changeId"].S)
+	}
+	if start+pageSize >= len(items) {
+		return &dynamodb.ScanOutput{Items: items[start:]}, nil
+	}
+	return &dynamodb.ScanOutput{
+		Items:            items[start : start+pageSize],
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"changeId": {S: aws.String(strconv.Itoa(start + pageSize))}},
+	}, nil
+}
+
+// mockAuditLog seeds a change a second from the start of the export, and one before and after the exported hour.
+func mockAuditLog(count int) []string {
+	client := &mockChangesScanClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	var inRange []string
+	for i := -1; i <= count; i++ {
+		changedAt := auditExportStart.Add(time.Duration(i) * time.Second)
+		if i == count {
+			changedAt = auditExportStart.Add(time.Hour)
+		}
+		change := &models.IntegrationChangeRecord{
+			IntegrationID: aws.String(testIntegrationID),
+			ChangeID:      aws.String(changedAt.Format(changeIDTimeFormat) + "-" + strconv.Itoa(i)),
+			ChangedAt:     aws.Time(changedAt),
+			ChangedBy:     aws.String("user-" + strconv.Itoa(i)),
+			Operation:     aws.String(models.ChangeOperationUpdated),
+			Changes: []*models.IntegrationFieldChange{
+				{Field: aws.String("scanIntervalMins"), Action: aws.String(models.FieldChanged), Current: 60, Desired: 60 + i},
+			},
+		}
+		client.changes = append(client.changes, change)
+		if i >= 0 && i < count {
+			inRange = append(inRange, *change.ChangeID)
+		}
+	}
+	db = &ddb.DDB{Client: client, TableName: "test", ChangesTableName: "changes"}
+	return inRange
+}
+
+func auditExportInput(destination string, cursor *string) *models.ExportAuditLogInput {
+	return &models.ExportAuditLogInput{
+		StartTime:   aws.Time(auditExportStart),
+		EndTime:     aws.Time(auditExportStart.Add(time.Hour)),
+		Destination: aws.String(destination),
+		Cursor:      cursor,
+	}
+}
+
+func TestExportAuditLogToS3(t *testing.T) {
+	expected := mockAuditLog(20)
+	auditExportBucket = "audit"
+	t.Cleanup(func() { auditExportBucket = "" })
+	mockS3 := &mockS3Client{}
+	exportClient = mockS3
+	var put *s3.PutObjectInput
+	mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).
+		Run(func(args mock.Arguments) { put = args.Get(0).(*s3.PutObjectInput) })
+
+	result, err := apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationS3, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 20, *result.EventCount)
+	assert.Nil(t, result.Cursor)
+	require.NotNil(t, put)
+	assert.Equal(t, "audit", *put.Bucket)
+	assert.Equal(t, "audit-log/"+expected[0]+".jsonl", *put.Key)
+	assert.Equal(t, put.Key, result.Key)
+
+	body, err := ioutil.ReadAll(put.Body)
+	require.NoError(t, err)
+	var exported []string
+	lines := bufio.NewScanner(bytes.NewReader(body))
+	for lines.Scan() {
+		var event models.AuditLogEvent
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &event))
+		assert.Equal(t, models.AuditLogSchemaVersion, *event.SchemaVersion)
+		assert.Equal(t, testIntegrationID, *event.IntegrationID)
+		exported = append(exported, *event.EventID)
+	}
+	assert.Equal(t, expected, exported)
+
+	// The first event is in the format the customers parse
+	var first map[string]interface{}
+	require.NoError(t, json.Unmarshal(body[:bytes.IndexByte(body, '\n')], &first))
+	assert.Equal(t, map[string]interface{}{
+		"schemaVersion": float64(1),
+		"eventId":       expected[0],
+		"eventTime":     "2020-06-01T00:00:00Z",
+		"integrationId": testIntegrationID,
+		"actor":         "user-0",
+		"operation":     models.ChangeOperationUpdated,
+		"changes": []interface{}{map[string]interface{}{
+			"field": "scanIntervalMins", "action": models.FieldChanged, "current": float64(60), "desired": float64(60),
+		}},
+	}, first)
+}
+
+// mockAuditQueue collects the events sent to the audit export queue, a FIFO queue.
+func mockAuditQueue(t *testing.T) *[]*sqs.SendMessageBatchRequestEntry {
+	auditExportQueueURL = "https://sqs.us-west-2.amazonaws.com/123456789012/audit.fifo"
+	t.Cleanup(func() { auditExportQueueURL = "" })
+	var sent []*sqs.SendMessageBatchRequestEntry
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(*sqs.SendMessageBatchInput).Entries...)
+	})
+	SQSClient = mockSQS
+	return &sent
+}
+
+func sentEventIDs(t *testing.T, entries []*sqs.SendMessageBatchRequestEntry) []string {
+	var ids []string
+	for _, entry := range entries {
+		var event models.AuditLogEvent
+		require.NoError(t, json.Unmarshal([]byte(*entry.MessageBody), &event))
+		assert.Equal(t, "audit-log", *entry.MessageGroupId)
+		assert.Equal(t, event.EventID, entry.MessageDeduplicationId)
+		ids = append(ids, *event.EventID)
+	}
+	return ids
+}
+
+func TestExportAuditLogToSQS(t *testing.T) {
+	expected := mockAuditLog(25)
+	sent := mockAuditQueue(t)
+
+	result, err := apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationSQS, nil))
+	require.NoError(t, err)
+	assert.Equal(t, &models.AuditLogExport{Destination: aws.String(models.AuditDestinationSQS), EventCount: aws.Int(25)}, result)
+	assert.Equal(t, expected, sentEventIDs(t, *sent))
+}
+
+func TestExportAuditLogResumesFromCursor(t *testing.T) {
+	expected := mockAuditLog(25)
+	mockAuditQueue(t)
+	// The second batch fails from its fourth event
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil).Once()
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{
+		Successful: []*sqs.SendMessageBatchResultEntry{{Id: aws.String("0")}, {Id: aws.String("1")}, {Id: aws.String("2")}},
+		Failed: []*sqs.BatchResultErrorEntry{
+			{Id: aws.String("5"), Code: aws.String("InternalError"), Message: aws.String("try again")},
+			{Id: aws.String("3"), Code: aws.String("InternalError"), Message: aws.String("try again")},
+		},
+	}, nil).Once()
+	SQSClient = mockSQS
+
+	result, err := apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationSQS, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 13, *result.EventCount)
+	assert.Equal(t, expected[12], *result.Cursor)
+	assert.Contains(t, *result.ErrorMessage, "InternalError: try again")
+
+	sent := mockAuditQueue(t)
+	result, err = apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationSQS, result.Cursor))
+	require.NoError(t, err)
+	assert.Equal(t, 12, *result.EventCount)
+	assert.Nil(t, result.Cursor)
+	assert.Equal(t, expected[13:], sentEventIDs(t, *sent))
+}
+
+func TestExportAuditLogLimit(t *testing.T) {
+	expected := mockAuditLog(maxAuditEventsPerExport + 5)
+	sent := mockAuditQueue(t)
+
+	result, err := apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationSQS, nil))
+	require.NoError(t, err)
+	assert.Equal(t, maxAuditEventsPerExport, *result.EventCount)
+	assert.Equal(t, expected[maxAuditEventsPerExport-1], *result.Cursor)
+
+	result, err = apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationSQS, result.Cursor))
+	require.NoError(t, err)
+	assert.Equal(t, 5, *result.EventCount)
+	assert.Nil(t, result.Cursor)
+	assert.Equal(t, expected, sentEventIDs(t, *sent))
+}
+
+func TestExportAuditLogNotConfigured(t *testing.T) {
+	_, err := apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationS3, nil))
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "no audit export bucket is configured"}, err)
+	_, err = apiTest.ExportAuditLog(auditExportInput(models.AuditDestinationSQS, nil))
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "no audit export queue is configured"}, err)
+}
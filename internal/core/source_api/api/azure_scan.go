@@ -0,0 +1,142 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const azureSubscriptionAPIVersion = "2020-01-01"
+
+var (
+	// The endpoints of Azure, tests point them at a local server
+	azureLoginURL      = "https://login.microsoftonline.com"
+	azureManagementURL = "https://management.azure.com"
+
+	azureClient                                         = &http.Client{Timeout: 10 * time.Second}
+	secretsClient secretsmanageriface.SecretsManagerAPI = secretsmanager.New(sess)
+)
+
+// azureHealthChecker checks the service principal of the Azure integrations.
+type azureHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration: the service principal must sign in and read the subscription.
+func (azureHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	status, err := api.CheckIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+	return &integrationEvaluation{
+		rolesHealthy: aws.BoolValue(status.AzureServicePrincipalStatus.Healthy) &&
+			aws.BoolValue(status.AzureSubscriptionStatus.Healthy),
+		failedItems: make([]*string, 0),
+		health:      status,
+	}, nil
+}
+
+// checkAzureSubscription signs in as the service principal of the integration, then reads its subscription.
+func checkAzureSubscription(input *models.CheckIntegrationInput, out *models.SourceIntegrationHealth) {
+	start := time.Now()
+	failed := func(err error) models.SourceIntegrationItemStatus {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	token, err := azureAccessToken(input)
+	if err != nil {
+		out.AzureServicePrincipalStatus = failed(err)
+		return
+	}
+	out.AzureServicePrincipalStatus = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+
+	start = time.Now()
+	subscriptionURL := fmt.Sprintf("%s/subscriptions/%s?api-version=%s",
+		azureManagementURL, url.PathEscape(aws.StringValue(input.AzureSubscriptionID)), azureSubscriptionAPIVersion)
+	request, err := http.NewRequest(http.MethodGet, subscriptionURL, nil)
+	if err != nil {
+		out.AzureSubscriptionStatus = failed(err)
+		return
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := azureClient.Do(request)
+	if err != nil {
+		out.AzureSubscriptionStatus = failed(err)
+		return
+	}
+	defer response.Body.Close()
+	var subscription struct {
+		State string `json:"state"`
+	}
+	switch {
+	case response.StatusCode != http.StatusOK:
+		out.AzureSubscriptionStatus = failed(fmt.Errorf("reading the subscription responded with status %d", response.StatusCode))
+	case jsoniter.NewDecoder(response.Body).Decode(&subscription) != nil:
+		out.AzureSubscriptionStatus = failed(fmt.Errorf("the subscription can't be decoded"))
+	case subscription.State != "Enabled":
+		out.AzureSubscriptionStatus = failed(fmt.Errorf("the subscription is %s", subscription.State))
+	default:
+		out.AzureSubscriptionStatus = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+	}
+}
+
+// azureAccessToken gets a token of the Azure Resource Manager for the service principal, with its client secret.
+func azureAccessToken(input *models.CheckIntegrationInput) (string, error) {
+	secret, err := secretsClient.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: input.AzureClientSecretArn})
+	if err != nil {
+		return "", fmt.Errorf("the client secret can't be read: %s", err)
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {aws.StringValue(input.AzureClientID)},
+		"client_secret": {aws.StringValue(secret.SecretString)},
+		"scope":         {azureManagementURL + "/.default"},
+	}
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", azureLoginURL, url.PathEscape(aws.StringValue(input.AzureTenantID)))
+	response, err := azureClient.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err = jsoniter.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("signing in responded with status %d", response.StatusCode)
+	}
+	if response.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("signing in failed with status %d: %s", response.StatusCode, token.Error)
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,146 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const (
+	testAzureTenantID        = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+	testAzureSubscriptionID  = "9b6d1a3c-4f7e-4c52-8d0a-1f2e3d4c5b6a"
+	testAzureClientID        = "0e1d2c3b-4a59-4687-9a8b-7c6d5e4f3a2b"
+	testAzureClientSecretArn = "arn:aws:secretsmanager:us-west-2:123456789012:secret:panther-azure-test"
+)
+
+// mockSecretsClient returns the secret, "client-secret" unless it is set
+type mockSecretsClient struct {
+	secretsmanageriface.SecretsManagerAPI
+	secret string
+	err    error
+}
+
+func (m *mockSecretsClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	secret := m.secret
+	if secret == "" {
+		secret = "client-secret"
+	}
+	return &secretsmanager.GetSecretValueOutput{ARN: input.SecretId, SecretString: aws.String(secret)}, nil
+}
+
+// mockAzure points the Azure endpoints at a local server answering the token and subscription requests
+func mockAzure(t *testing.T, tokenStatus int, subscriptionState string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+testAzureTenantID+"/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, testAzureClientID, r.PostForm.Get("client_id"))
+		assert.Equal(t, "client-secret", r.PostForm.Get("client_secret"))
+		w.WriteHeader(tokenStatus)
+		if tokenStatus != http.StatusOK {
+			_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token": "token"}`))
+	})
+	mux.HandleFunc("/subscriptions/"+testAzureSubscriptionID, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"state": "` + subscriptionState + `"}`))
+	})
+	server := httptest.NewServer(mux)
+
+	loginURL, managementURL, client := azureLoginURL, azureManagementURL, secretsClient
+	azureLoginURL, azureManagementURL, secretsClient = server.URL, server.URL, &mockSecretsClient{}
+	t.Cleanup(func() {
+		server.Close()
+		azureLoginURL, azureManagementURL, secretsClient = loginURL, managementURL, client
+	})
+}
+
+func azureCheckInput() *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		IntegrationType:      aws.String(models.IntegrationTypeAzureScan),
+		AzureTenantID:        aws.String(testAzureTenantID),
+		AzureSubscriptionID:  aws.String(testAzureSubscriptionID),
+		AzureClientID:        aws.String(testAzureClientID),
+		AzureClientSecretArn: aws.String(testAzureClientSecretArn),
+	}
+}
+
+func TestCheckIntegrationAzure(t *testing.T) {
+	mockAzure(t, http.StatusOK, "Enabled")
+
+	result, err := apiTest.CheckIntegration(azureCheckInput())
+	require.NoError(t, err)
+	assert.True(t, *result.AzureServicePrincipalStatus.Healthy)
+	assert.True(t, *result.AzureSubscriptionStatus.Healthy)
+	assert.Nil(t, result.AuditRoleStatus.Healthy)
+
+	evaluation, err := azureHealthChecker{}.Evaluate(apiTest, azureCheckInput())
+	require.NoError(t, err)
+	assert.True(t, evaluation.rolesHealthy)
+}
+
+func TestCheckIntegrationAzureSignInFails(t *testing.T) {
+	mockAzure(t, http.StatusUnauthorized, "Enabled")
+
+	result, err := apiTest.CheckIntegration(azureCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.AzureServicePrincipalStatus.Healthy)
+	assert.Equal(t, "signing in failed with status 401: invalid_client", *result.AzureServicePrincipalStatus.ErrorMessage)
+	assert.Nil(t, result.AzureSubscriptionStatus.Healthy)
+
+	evaluation, err := azureHealthChecker{}.Evaluate(apiTest, azureCheckInput())
+	require.NoError(t, err)
+	assert.False(t, evaluation.rolesHealthy)
+}
+
+func TestCheckIntegrationAzureSecretUnreadable(t *testing.T) {
+	mockAzure(t, http.StatusOK, "Enabled")
+	secretsClient = &mockSecretsClient{err: errors.New("AccessDeniedException")}
+
+	result, err := apiTest.CheckIntegration(azureCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.AzureServicePrincipalStatus.Healthy)
+	assert.Equal(t, "the client secret can't be read: AccessDeniedException", *result.AzureServicePrincipalStatus.ErrorMessage)
+}
+
+func TestCheckIntegrationAzureSubscriptionDisabled(t *testing.T) {
+	mockAzure(t, http.StatusOK, "Disabled")
+
+	result, err := apiTest.CheckIntegration(azureCheckInput())
+	require.NoError(t, err)
+	assert.True(t, *result.AzureServicePrincipalStatus.Healthy)
+	assert.False(t, *result.AzureSubscriptionStatus.Healthy)
+	assert.Equal(t, "the subscription is Disabled", *result.AzureSubscriptionStatus.ErrorMessage)
+}
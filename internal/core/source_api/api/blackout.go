@@ -0,0 +1,114 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// Overlapping windows are skipped one after the other, up to this many
+const maxBlackoutSkips = 100
+
+// NextScanTime returns when the next scan of an integration is due, never before now.
+//
+// With a scan schedule, the next scan is due at the first time the schedule matches after the last scan.
+// A scan which would be due during a blackout window is postponed to the end of the window.
+func NextScanTime(integration *models.SourceIntegration, now time.Time) time.Time {
+	next := now
+	if integration.SourceIntegrationScanInformation != nil && integration.LastScanEndTime != nil &&
+		integration.SourceIntegrationMetadata != nil {
+
+		var due time.Time
+		if schedule := scanSchedule(integration.SourceIntegrationMetadata); schedule != nil {
+			due = schedule.Next(*integration.LastScanEndTime)
+		} else if integration.ScanIntervalMins != nil {
+			due = integration.LastScanEndTime.Add(time.Duration(currentScanIntervalMins(integration)) * time.Minute)
+		}
+		if due.After(now) {
+			next = due
+		}
+	}
+
+	if integration.SourceIntegrationMetadata == nil {
+		return next
+	}
+	for i := 0; i < maxBlackoutSkips; i++ {
+		end, inBlackout := blackoutEnd(integration.BlackoutWindows, next)
+		if !inBlackout {
+			break
+		}
+		next = end
+	}
+	return next
+}
+
+// blackoutEnd returns the end of the blackout window t falls in, the latest one if windows overlap.
+func blackoutEnd(windows []*models.BlackoutWindow, t time.Time) (end time.Time, inBlackout bool) {
+	for _, window := range windows {
+		if windowEnd, ok := windowEndAt(window, t); ok && windowEnd.After(end) {
+			end, inBlackout = windowEnd, true
+		}
+	}
+	return end, inBlackout
+}
+
+// windowEndAt returns the end of the occurrence of the window which contains t, if any.
+func windowEndAt(window *models.BlackoutWindow, t time.Time) (time.Time, bool) {
+	if window.Start != nil && window.End != nil {
+		return *window.End, !t.Before(*window.Start) && t.Before(*window.End)
+	}
+
+	dailyStart, err := time.Parse(models.BlackoutDailyStartLayout, aws.StringValue(window.DailyStart))
+	if err != nil || window.DurationMins == nil {
+		// Rejected by the validator
+		return time.Time{}, false
+	}
+	duration := time.Duration(*window.DurationMins) * time.Minute
+	offset := time.Duration(dailyStart.Hour())*time.Hour + time.Duration(dailyStart.Minute())*time.Minute
+
+	// A window is at most a day long, so only the occurrences starting today or yesterday can contain t
+	t = t.UTC()
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for _, day := range []time.Time{today, today.AddDate(0, 0, -1)} {
+		start := day.Add(offset)
+		if !recursOn(window, start.Weekday()) {
+			continue
+		}
+		if end := start.Add(duration); !t.Before(start) && t.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func recursOn(window *models.BlackoutWindow, weekday time.Weekday) bool {
+	if len(window.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range window.Weekdays {
+		if time.Weekday(day) == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// Wednesday
+var testNow = time.Date(2020, 3, 4, 12, 0, 0, 0, time.UTC)
+
+func integrationWithBlackout(lastScanEnd time.Time, windows ...*models.BlackoutWindow) *models.SourceIntegration {
+	return &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			ScanIntervalMins: aws.Int(60),
+			BlackoutWindows:  windows,
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{LastScanEndTime: &lastScanEnd},
+	}
+}
+
+func TestNextScanTime(t *testing.T) {
+	integration := integrationWithBlackout(testNow.Add(-30 * time.Minute))
+	assert.Equal(t, testNow.Add(30*time.Minute), NextScanTime(integration, testNow))
+
+	// Overdue scans are due now
+	integration = integrationWithBlackout(testNow.Add(-2 * time.Hour))
+	assert.Equal(t, testNow, NextScanTime(integration, testNow))
+
+	// Never scanned
+	assert.Equal(t, testNow, NextScanTime(&models.SourceIntegration{}, testNow))
+}
+
+func TestNextScanTimeOneOffBlackout(t *testing.T) {
+	window := &models.BlackoutWindow{
+		Start: aws.Time(testNow.Add(10 * time.Minute)),
+		End:   aws.Time(testNow.Add(3 * time.Hour)),
+	}
+	integration := integrationWithBlackout(testNow.Add(-30*time.Minute), window)
+	assert.Equal(t, testNow.Add(3*time.Hour), NextScanTime(integration, testNow))
+
+	// A scan due before the window is not affected
+	integration = integrationWithBlackout(testNow.Add(-55*time.Minute), window)
+	assert.Equal(t, testNow.Add(5*time.Minute), NextScanTime(integration, testNow))
+}
+
+func TestNextScanTimeRecurringBlackout(t *testing.T) {
+	// Every day from 23:00 to 01:00, so the window spans midnight
+	nightly := &models.BlackoutWindow{DailyStart: aws.String("23:00"), DurationMins: aws.Int(120)}
+	lastScanEnd := time.Date(2020, 3, 4, 22, 30, 0, 0, time.UTC)
+	integration := integrationWithBlackout(lastScanEnd, nightly)
+	assert.Equal(t, time.Date(2020, 3, 5, 1, 0, 0, 0, time.UTC), NextScanTime(integration, lastScanEnd))
+
+	// Inside the part of the window after midnight
+	integration = integrationWithBlackout(time.Date(2020, 3, 4, 23, 30, 0, 0, time.UTC), nightly)
+	now := time.Date(2020, 3, 5, 0, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2020, 3, 5, 1, 0, 0, 0, time.UTC), NextScanTime(integration, now))
+
+	// Only on Saturdays and Sundays, so a Wednesday scan is not affected
+	weekends := &models.BlackoutWindow{DailyStart: aws.String("23:00"), DurationMins: aws.Int(120), Weekdays: []int{0, 6}}
+	integration = integrationWithBlackout(lastScanEnd, weekends)
+	assert.Equal(t, lastScanEnd.Add(time.Hour), NextScanTime(integration, lastScanEnd))
+}
+
+func TestNextScanTimeOverlappingBlackouts(t *testing.T) {
+	first := &models.BlackoutWindow{Start: aws.Time(testNow), End: aws.Time(testNow.Add(2 * time.Hour))}
+	second := &models.BlackoutWindow{Start: aws.Time(testNow.Add(time.Hour)), End: aws.Time(testNow.Add(4 * time.Hour))}
+	integration := integrationWithBlackout(testNow.Add(-2*time.Hour), first, second)
+	assert.Equal(t, testNow.Add(4*time.Hour), NextScanTime(integration, testNow))
+}
+
+func TestUpdateIntegrationLastScanStartInBlackout(t *testing.T) {
+	now := time.Now()
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		IntegrationID: aws.String(testIntegrationID),
+		BlackoutWindows: []*models.BlackoutWindow{
+			{Start: aws.Time(now.Add(-time.Hour)), End: aws.Time(now.Add(time.Hour))},
+		},
+	})
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+
+	result, err := apiTest.UpdateIntegrationLastScanStart(&models.UpdateIntegrationLastScanStartInput{
+		IntegrationID:     aws.String(testIntegrationID),
+		LastScanStartTime: aws.Time(now),
+		ScanStatus:        aws.String(models.StatusScanning),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.UnavailableError{}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+	// A cached copy could miss the window which was just added
+	assert.True(t, *mockClient.Calls[0].Arguments.Get(0).(*dynamodb.GetItemInput).ConsistentRead)
+}
+
+func TestBlackoutWindowValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	valid := []*models.BlackoutWindow{
+		{Start: aws.Time(testNow), End: aws.Time(testNow.Add(time.Hour))},
+		{DailyStart: aws.String("23:00"), DurationMins: aws.Int(120)},
+		{DailyStart: aws.String("08:30"), DurationMins: aws.Int(60), Weekdays: []int{1, 5}},
+	}
+	for _, window := range valid {
+		assert.NoError(t, validator.Struct(window))
+	}
+
+	invalid := []*models.BlackoutWindow{
+		{},
+		{Start: aws.Time(testNow)},
+		{Start: aws.Time(testNow), End: aws.Time(testNow.Add(-time.Hour))},
+		{Start: aws.Time(testNow), End: aws.Time(testNow.Add(time.Hour)), DurationMins: aws.Int(60)},
+		{DailyStart: aws.String("25:00"), DurationMins: aws.Int(60)},
+		{DailyStart: aws.String("23:00")},
+		{DailyStart: aws.String("23:00"), DurationMins: aws.Int(1441)},
+		{DailyStart: aws.String("23:00"), DurationMins: aws.Int(60), Weekdays: []int{7}},
+	}
+	for i, window := range invalid {
+		assert.Error(t, validator.Struct(window), i)
+	}
+}
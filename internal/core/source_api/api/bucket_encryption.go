@@ -0,0 +1,184 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The code S3 answers GetBucketEncryption with for a bucket without default encryption
+const noBucketEncryptionCode = "ServerSideEncryptionConfigurationNotFoundError"
+
+var discoverKmsKeysFunc = discoverKmsKeys
+
+// checkBucketEncryption reads the default encryption of each bucket which passed its check.
+//
+// The objects of a bucket encrypted by default with a KMS key can only be read if the role is allowed kms:Decrypt on
+// it: the onboarding template only allows it on the kmsKeys. A bucket whose objects failed to decrypt fails, with the
+// fix depending on whether its key is one of the kmsKeys. The keys which aren't are returned as discovered, whether or
+// not their objects could be decrypted. Roles onboarded before the template granted s3:GetEncryptionConfiguration
+// can't read the encryption, their buckets are inconclusive.
+func checkBucketEncryption(
+	logger *zap.Logger, roleCredentials *credentials.Credentials, buckets []*string, regions map[string]*string, keys []*string,
+	bucketStatuses, decryptions map[string]models.SourceIntegrationItemStatus,
+) (statuses map[string]models.SourceIntegrationItemStatus, discovered []*string) {
+
+	clientForBucket := bucketClients(roleCredentials, regions)
+	kmsClient := kmsClientFunc(roleCredentials)
+	statuses = make(map[string]models.SourceIntegrationItemStatus, len(buckets))
+	seen := make(map[string]bool)
+	for _, bucket := range buckets {
+		if !aws.BoolValue(bucketStatuses[*bucket].Healthy) {
+			continue
+		}
+		name, _ := parseBucketEntry(*bucket)
+		s3Client, _ := clientForBucket(name)
+
+		start := time.Now()
+		output, err := s3Client.GetBucketEncryption(&s3.GetBucketEncryptionInput{Bucket: aws.String(name)})
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == noBucketEncryptionCode {
+			statuses[*bucket] = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+			continue
+		}
+		if err != nil {
+			status := models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+			switch {
+			case isThrottlingError(err):
+				logger.Warn("bucket encryption check throttled", zap.String("bucket", *bucket), zap.Error(err))
+				status.Inconclusive = aws.Bool(true)
+			case isAccessDenied(err):
+				status.Inconclusive = aws.Bool(true)
+				status.ErrorMessage = aws.String("not allowed to read the default encryption, " +
+					"allow s3:GetEncryptionConfiguration to the role: " + err.Error())
+			}
+			statuses[*bucket] = status
+			continue
+		}
+
+		key := bucketKmsKey(output.ServerSideEncryptionConfiguration)
+		if key == "" {
+			// Objects encrypted with the keys managed by S3 are decrypted without any permission on KMS
+			statuses[*bucket] = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+			continue
+		}
+		key = kmsKeyARN(logger, kmsClient, key)
+		listed := isListedKmsKey(keys, key)
+		if !listed && !seen[key] {
+			seen[key] = true
+			discovered = append(discovered, aws.String(key))
+		}
+
+		decryption, sampled := decryptions[*bucket]
+		switch {
+		case sampled && !aws.BoolValue(decryption.Healthy) && !listed:
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy: aws.Bool(false),
+				ErrorMessage: aws.String(fmt.Sprintf("objects are encrypted with the KMS key %s which isn't one of the kmsKeys: "+
+					"add it to the kmsKeys, and to the EncryptionKeys of the stack of the role", key)),
+				LatencyMillis: millisSince(start),
+			}
+		case sampled && !aws.BoolValue(decryption.Healthy):
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy: aws.Bool(false),
+				ErrorMessage: aws.String(fmt.Sprintf("the role is not allowed kms:Decrypt on the KMS key %s: "+
+					"allow it in the policy of the key, or set createKmsGrants", key)),
+				LatencyMillis: millisSince(start),
+			}
+		default:
+			statuses[*bucket] = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+		}
+	}
+
+	return statuses, discovered
+}
+
+// bucketKmsKey returns the KMS key of the default encryption rule of a bucket, empty if it has none.
+//
+// Buckets encrypted with aws:kms without a key use the key of S3 managed by AWS, which has no key to list either.
+func bucketKmsKey(config *s3.ServerSideEncryptionConfiguration) string {
+	if config == nil {
+		return ""
+	}
+	for _, rule := range config.Rules {
+		encryption := rule.ApplyServerSideEncryptionByDefault
+		if encryption != nil && aws.StringValue(encryption.SSEAlgorithm) == s3.ServerSideEncryptionAwsKms {
+			return aws.StringValue(encryption.KMSMasterKeyID)
+		}
+	}
+	return ""
+}
+
+// kmsKeyARN returns the ARN of a key referenced by its ID or an alias, the key is returned as it is if it can't be described.
+func kmsKeyARN(logger *zap.Logger, kmsClient kmsiface.KMSAPI, key string) string {
+	if strings.HasPrefix(key, "arn:") && !isKmsAlias(key) {
+		return key
+	}
+	info, err := kmsClient.DescribeKey(&kms.DescribeKeyInput{KeyId: aws.String(key)})
+	if err != nil || info.KeyMetadata == nil || info.KeyMetadata.Arn == nil {
+		logger.Debug("failed to describe the KMS key of a bucket", zap.String("key", key), zap.Error(err))
+		return key
+	}
+	return *info.KeyMetadata.Arn
+}
+
+// isListedKmsKey returns true if the key ARN is one of the keys, which may be referenced by their ID.
+func isListedKmsKey(keys []*string, keyARN string) bool {
+	keyID := keyARN[strings.LastIndex(keyARN, "/")+1:]
+	for _, key := range keys {
+		if *key == keyARN || *key == keyID {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverKmsKeys returns the KMS keys the buckets are encrypted with by default which aren't among the keys to check.
+//
+// Nothing is discovered when the role fails, buckets which can't be reached or whose encryption can't be read are
+// left to the health check.
+func discoverKmsKeys(input *models.CheckIntegrationInput) []*string {
+	if *input.IntegrationType != models.IntegrationTypeAWS3 || len(input.S3Buckets) == 0 {
+		return nil
+	}
+	health := &models.SourceIntegrationHealth{}
+	roleCreds := processingRoleCredentials(zap.L(), input, health)
+	if !aws.BoolValue(health.ProcessingRoleStatus.Healthy) {
+		return nil
+	}
+	bucketStatuses, _ := checkBuckets(zap.L(), roleCreds, input.S3Buckets, input.S3BucketRegions)
+	_, discovered := checkBucketEncryption(
+		zap.L(), roleCreds, input.S3Buckets, input.S3BucketRegions, input.KmsKeys, bucketStatuses, nil)
+	return discovered
+}
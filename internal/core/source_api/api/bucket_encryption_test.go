@@ -0,0 +1,175 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const (
+	testListedKey   = "arn:aws:kms:us-west-2:123456789012:key/listed"
+	testUnlistedKey = "arn:aws:kms:us-west-2:123456789012:key/unlisted"
+)
+
+func kmsEncryption(key string) *s3.GetBucketEncryptionOutput {
+	return &s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+		Rules: []*s3.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+			SSEAlgorithm:   aws.String(s3.ServerSideEncryptionAwsKms),
+			KMSMasterKeyID: aws.String(key),
+		}}},
+	}}
+}
+
+// restoreHealthCheckClients puts back the clients of the health check once the test is done, otherwise the update
+// tests which follow would check the credential expiry of their keys with the role mocked here.
+func restoreHealthCheckClients(t *testing.T) {
+	stsFunc, s3Func, regionalS3Func, kmsFunc, iamFunc := stsClientFunc, s3ClientFunc, regionalS3ClientFunc, kmsClientFunc, iamClientFunc
+	t.Cleanup(func() {
+		stsClientFunc, s3ClientFunc, regionalS3ClientFunc, kmsClientFunc, iamClientFunc = stsFunc, s3Func, regionalS3Func, kmsFunc, iamFunc
+	})
+}
+
+func TestCheckBucketEncryption(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketEncryption", &s3.GetBucketEncryptionInput{Bucket: aws.String("plain")}).
+		Return(&s3.GetBucketEncryptionOutput{}, awserr.New(noBucketEncryptionCode, "not found", nil))
+	mockS3.On("GetBucketEncryption", &s3.GetBucketEncryptionInput{Bucket: aws.String("sse-s3")}).
+		Return(&s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+			Rules: []*s3.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+				SSEAlgorithm: aws.String(s3.ServerSideEncryptionAes256),
+			}}},
+		}}, nil)
+	mockS3.On("GetBucketEncryption", &s3.GetBucketEncryptionInput{Bucket: aws.String("listed")}).
+		Return(kmsEncryption(testListedKey), nil)
+	mockS3.On("GetBucketEncryption", &s3.GetBucketEncryptionInput{Bucket: aws.String("listed-denied")}).
+		Return(kmsEncryption(testListedKey), nil)
+	// The key is referenced by its alias, it is discovered by its ARN
+	mockS3.On("GetBucketEncryption", &s3.GetBucketEncryptionInput{Bucket: aws.String("unlisted")}).
+		Return(kmsEncryption("alias/logs"), nil)
+	mockS3.On("GetBucketEncryption", &s3.GetBucketEncryptionInput{Bucket: aws.String("unlisted-denied")}).
+		Return(kmsEncryption(testUnlistedKey), nil)
+	mockS3.On("GetBucketEncryption", &s3.GetBucketEncryptionInput{Bucket: aws.String("old-role")}).
+		Return(&s3.GetBucketEncryptionOutput{}, awserr.New("AccessDenied", "Access Denied", nil))
+	mockKMS := &mockKMSClient{}
+	mockKMS.On("DescribeKey", &kms.DescribeKeyInput{KeyId: aws.String("alias/logs")}).
+		Return(&kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Arn: aws.String(testUnlistedKey)}}, nil)
+	restoreHealthCheckClients(t)
+	mockHealthCheckClients(&mockSTSClient{}, mockS3, mockKMS)
+
+	buckets := aws.StringSlice([]string{
+		"plain", "sse-s3", "listed", "listed-denied", "unlisted", "unlisted-denied", "old-role", "unreachable"})
+	bucketStatuses := map[string]models.SourceIntegrationItemStatus{
+		"unreachable": {Healthy: aws.Bool(false)},
+	}
+	for _, bucket := range buckets[:7] {
+		bucketStatuses[*bucket] = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true)}
+	}
+	decryptions := map[string]models.SourceIntegrationItemStatus{
+		"listed":          {Healthy: aws.Bool(true)},
+		"listed-denied":   {Healthy: aws.Bool(false)},
+		"unlisted":        {Healthy: aws.Bool(true)},
+		"unlisted-denied": {Healthy: aws.Bool(false)},
+	}
+
+	statuses, discovered := checkBucketEncryption(zap.NewNop(),
+		nil, buckets, nil, aws.StringSlice([]string{testListedKey}), bucketStatuses, decryptions)
+	for _, bucket := range []string{"plain", "sse-s3", "listed", "unlisted"} {
+		assert.True(t, *statuses[bucket].Healthy, bucket)
+	}
+	assert.False(t, *statuses["listed-denied"].Healthy)
+	assert.Equal(t, "the role is not allowed kms:Decrypt on the KMS key "+testListedKey+
+		": allow it in the policy of the key, or set createKmsGrants", *statuses["listed-denied"].ErrorMessage)
+	assert.False(t, *statuses["unlisted-denied"].Healthy)
+	assert.Equal(t, "objects are encrypted with the KMS key "+testUnlistedKey+" which isn't one of the kmsKeys: "+
+		"add it to the kmsKeys, and to the EncryptionKeys of the stack of the role", *statuses["unlisted-denied"].ErrorMessage)
+	assert.True(t, *statuses["old-role"].Inconclusive)
+	assert.Contains(t, *statuses["old-role"].ErrorMessage, "s3:GetEncryptionConfiguration")
+	assert.NotContains(t, statuses, "unreachable")
+	// Each key is discovered once
+	assert.Equal(t, aws.StringSlice([]string{testUnlistedKey}), discovered)
+	mockS3.AssertExpectations(t)
+	mockKMS.AssertExpectations(t)
+}
+
+func TestCheckIntegrationBucketEncryptionFailure(t *testing.T) {
+	restoreHealthCheckClients(t)
+	mockS3 := mockSampledObject("logs", "events.json.gz")
+	mockS3.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{}, awserr.New("AccessDenied",
+		"not authorized to perform: kms:Decrypt on resource: "+testUnlistedKey, nil))
+	mockS3.On("GetBucketEncryption", mock.Anything).Return(kmsEncryption(testUnlistedKey), nil)
+	input := sampleReadInput("logs")
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.False(t, *result.S3BucketEncryptionStatus["logs"].Healthy)
+	assert.Equal(t, aws.StringSlice([]string{testUnlistedKey}), result.DiscoveredKmsKeys)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"s3BucketEncryption:logs", "s3ObjectDecrypt:logs"}), eval.failedItems)
+
+	// Disabling the key checks doesn't fail the integration because of the key of the bucket
+	input.DisabledChecks = aws.StringSlice([]string{models.HealthCheckKMSKeys})
+	result, err = apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.S3BucketEncryptionStatus["logs"].Informational)
+}
+
+func TestDiscoverKmsKeysForUpdate(t *testing.T) {
+	discoverKmsKeysFunc = func(input *models.CheckIntegrationInput) []*string {
+		// The stored keys are not discovered again
+		assert.Equal(t, aws.StringSlice([]string{testListedKey}), input.KmsKeys)
+		return aws.StringSlice([]string{testUnlistedKey})
+	}
+	t.Cleanup(func() { discoverKmsKeysFunc = discoverKmsKeys })
+
+	prepared := &preparedUpdate{
+		integration: &models.SourceIntegrationMetadata{
+			IntegrationID:   aws.String(testIntegrationID),
+			IntegrationType: aws.String(models.IntegrationTypeAWS3),
+			S3Buckets:       aws.StringSlice([]string{"logs"}),
+			KmsKeys:         aws.StringSlice([]string{testListedKey}),
+		},
+		input: &models.UpdateIntegrationSettingsInput{
+			IntegrationID:   aws.String(testIntegrationID),
+			S3Buckets:       aws.StringSlice([]string{"logs", "encrypted-logs"}),
+			DiscoverKmsKeys: aws.Bool(true),
+		},
+	}
+	healthCheckInput := healthCheckInputForUpdate(prepared.integration, prepared.input)
+
+	discoverKmsKeysForUpdate(prepared, healthCheckInput)
+	expected := aws.StringSlice([]string{testListedKey, testUnlistedKey})
+	assert.Equal(t, expected, prepared.input.KmsKeys)
+	assert.Equal(t, expected, healthCheckInput.KmsKeys)
+	assert.Contains(t, prepared.changes, &models.IntegrationFieldChange{
+		Field: aws.String("kmsKeys"), Action: aws.String(models.FieldAdded), Desired: testUnlistedKey,
+	})
+}
@@ -0,0 +1,123 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// foreignBucketsFunc returns the buckets owned by another account, it's replaced in tests.
+var foreignBucketsFunc = foreignBuckets
+
+// checkBucketOwnership rejects the buckets an update adds to a log integration which belong to another account.
+//
+// In org setups a bucket of e.g. the management account must not be claimed by the integration of a member account.
+// Such buckets are only accepted when the update allows cross account buckets and names the delegating account.
+// When the owners can't be read the ownership is unknown, the buckets are then left to the health check.
+func checkBucketOwnership(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput,
+	regions map[string]*string) error {
+
+	delegated := aws.BoolValue(input.AllowCrossAccountBuckets)
+	switch {
+	case delegated && input.DelegatingAccountID == nil:
+		return &genericapi.InvalidInputError{Message: "cross account buckets need the delegatingAccountId which owns them"}
+	case !delegated && input.DelegatingAccountID != nil:
+		return &genericapi.InvalidInputError{Message: "delegatingAccountId is only used with allowCrossAccountBuckets"}
+	case delegated && *input.DelegatingAccountID == aws.StringValue(integration.AWSAccountID):
+		return &genericapi.InvalidInputError{Message: "the delegating account is the account of the integration"}
+	}
+	if input.S3Buckets == nil || aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWS3 {
+		return nil
+	}
+
+	// The buckets already configured were accepted when they were added
+	current := make(map[string]struct{}, len(integration.S3Buckets))
+	for _, entry := range integration.S3Buckets {
+		name, _ := parseBucketEntry(*entry)
+		current[name] = struct{}{}
+	}
+	added := make([]string, 0, len(input.S3Buckets))
+	for _, entry := range input.S3Buckets {
+		name, _ := parseBucketEntry(*entry)
+		if _, ok := current[name]; !ok {
+			current[name] = struct{}{}
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	foreign, err := foreignBucketsFunc(integration.AWSAccountID, added, regions)
+	if err != nil {
+		zap.L().Warn("failed to verify bucket ownership", zap.Strings("buckets", added), zap.Error(err))
+		return nil
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+	if delegated {
+		zap.L().Info("cross account buckets delegated",
+			zap.Strings("buckets", foreign), zap.String("delegatingAccountId", *input.DelegatingAccountID))
+		return nil
+	}
+	return &genericapi.InvalidInputError{Message: fmt.Sprintf(
+		"buckets %s are not owned by account %s, allow cross account buckets with their delegating account",
+		strings.Join(foreign, ", "), *integration.AWSAccountID)}
+}
+
+// foreignBuckets returns the buckets, sorted, which are owned by another account than the given one.
+//
+// The owners are compared by canonical ID with the log processing role of the account: the ID of the account
+// comes from listing its buckets, the one of each bucket from its ACL.
+func foreignBuckets(accountID *string, buckets []string, regions map[string]*string) ([]string, error) {
+	roleCredentials := stscreds.NewCredentials(sess, fmt.Sprintf(logProcessingRoleFormat, aws.StringValue(accountID)))
+	listing, err := s3ClientFunc(roleCredentials).ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
+	if listing.Owner == nil || listing.Owner.ID == nil {
+		return nil, fmt.Errorf("no canonical ID for account %s", aws.StringValue(accountID))
+	}
+
+	clientForBucket := bucketClients(roleCredentials, regions)
+	var foreign []string
+	for _, bucket := range buckets {
+		s3Client, _ := clientForBucket(bucket)
+		acl, err := s3Client.GetBucketAcl(&s3.GetBucketAclInput{Bucket: aws.String(bucket)})
+		if err != nil {
+			return nil, err
+		}
+		if acl.Owner == nil || aws.StringValue(acl.Owner.ID) != *listing.Owner.ID {
+			foreign = append(foreign, bucket)
+		}
+	}
+	sort.Strings(foreign)
+	return foreign, nil
+}
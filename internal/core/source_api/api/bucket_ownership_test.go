@@ -0,0 +1,159 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testDelegatingAccountID = "210987654321"
+
+func (client *mockS3Client) ListBuckets(input *s3.ListBucketsInput) (*s3.ListBucketsOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.ListBucketsOutput), args.Error(1)
+}
+
+func (client *mockS3Client) GetBucketAcl(input *s3.GetBucketAclInput) (*s3.GetBucketAclOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.GetBucketAclOutput), args.Error(1)
+}
+
+// mockBucketOwnership makes the given buckets owned by another account than the one of the integration.
+func mockBucketOwnership(t *testing.T, foreign ...string) {
+	original := foreignBucketsFunc
+	t.Cleanup(func() { foreignBucketsFunc = original })
+	foreignBucketsFunc = func(_ *string, buckets []string, _ map[string]*string) ([]string, error) {
+		var result []string
+		for _, bucket := range buckets {
+			for _, name := range foreign {
+				if bucket == name {
+					result = append(result, bucket)
+				}
+			}
+		}
+		return result, nil
+	}
+}
+
+// updateBuckets updates a stored log integration reading from "bucket", which passes its health check.
+func updateBuckets(t *testing.T, input *models.UpdateIntegrationSettingsInput) (*modelstest.MockDDBClient, error) {
+	mockClient := mockStoredIntegration(t, storedLogIntegration())
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	input.IntegrationID = aws.String(testIntegrationID)
+	_, err := apiTest.UpdateIntegrationSettings(input)
+	return mockClient, err
+}
+
+func TestUpdateIntegrationSettingsSameAccountBuckets(t *testing.T) {
+	mockBucketOwnership(t, "org-logs")
+
+	mockClient, err := updateBuckets(t, &models.UpdateIntegrationSettingsInput{
+		S3Buckets: aws.StringSlice([]string{"bucket", "logs/cloudtrail"}),
+	})
+	require.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 1)
+}
+
+func TestUpdateIntegrationSettingsCrossAccountBucketRejected(t *testing.T) {
+	mockBucketOwnership(t, "org-logs")
+
+	mockClient, err := updateBuckets(t, &models.UpdateIntegrationSettingsInput{
+		S3Buckets: aws.StringSlice([]string{"bucket", "org-logs/AWSLogs"}),
+	})
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Contains(t, err.Error(), "buckets org-logs are not owned by account "+testAccountID)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsDelegatedCrossAccountBucket(t *testing.T) {
+	mockBucketOwnership(t, "org-logs")
+
+	mockClient, err := updateBuckets(t, &models.UpdateIntegrationSettingsInput{
+		S3Buckets:                aws.StringSlice([]string{"bucket", "org-logs/AWSLogs"}),
+		AllowCrossAccountBuckets: aws.Bool(true),
+		DelegatingAccountID:      aws.String(testDelegatingAccountID),
+	})
+	require.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 1)
+}
+
+func TestUpdateIntegrationSettingsDelegationSettings(t *testing.T) {
+	mockBucketOwnership(t)
+
+	// The delegating account is required, and it can't be the account of the integration
+	_, err := updateBuckets(t, &models.UpdateIntegrationSettingsInput{AllowCrossAccountBuckets: aws.Bool(true)})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	_, err = updateBuckets(t, &models.UpdateIntegrationSettingsInput{
+		AllowCrossAccountBuckets: aws.Bool(true),
+		DelegatingAccountID:      aws.String(testAccountID),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	_, err = updateBuckets(t, &models.UpdateIntegrationSettingsInput{DelegatingAccountID: aws.String(testDelegatingAccountID)})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestUpdateIntegrationSettingsBucketOwnershipUnknown(t *testing.T) {
+	original := foreignBucketsFunc
+	t.Cleanup(func() { foreignBucketsFunc = original })
+	foreignBucketsFunc = func(*string, []string, map[string]*string) ([]string, error) {
+		return nil, errors.New("AccessDenied")
+	}
+
+	// The buckets are left to the health check
+	_, err := updateBuckets(t, &models.UpdateIntegrationSettingsInput{
+		S3Buckets: aws.StringSlice([]string{"bucket", "org-logs"}),
+	})
+	require.NoError(t, err)
+}
+
+func TestForeignBuckets(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListBuckets", &s3.ListBucketsInput{}).
+		Return(&s3.ListBucketsOutput{Owner: &s3.Owner{ID: aws.String("member-canonical-id")}}, nil)
+	mockS3.On("GetBucketAcl", &s3.GetBucketAclInput{Bucket: aws.String("logs")}).
+		Return(&s3.GetBucketAclOutput{Owner: &s3.Owner{ID: aws.String("member-canonical-id")}}, nil)
+	mockS3.On("GetBucketAcl", &s3.GetBucketAclInput{Bucket: aws.String("org-logs")}).
+		Return(&s3.GetBucketAclOutput{Owner: &s3.Owner{ID: aws.String("management-canonical-id")}}, nil)
+	originalS3, originalRegionalS3 := s3ClientFunc, regionalS3ClientFunc
+	t.Cleanup(func() { s3ClientFunc, regionalS3ClientFunc = originalS3, originalRegionalS3 })
+	s3ClientFunc = func(*credentials.Credentials) s3iface.S3API { return mockS3 }
+	regionalS3ClientFunc = func(*credentials.Credentials, string) s3iface.S3API { return mockS3 }
+
+	foreign, err := foreignBuckets(aws.String(testAccountID), []string{"org-logs", "logs"},
+		map[string]*string{"org-logs": aws.String("us-east-1")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org-logs"}, foreign)
+	mockS3.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+)
+
+// BulkSetScanInterval sets the scan interval of every integration matching the filters.
+//
+// Only the schedule changes so no health check is needed. A failed update doesn't stop the others,
+// the outcome is reported for each matching integration.
+func (API) BulkSetScanInterval(input *models.BulkSetScanIntervalInput) ([]*models.BulkSetScanIntervalResult, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.BulkSetScanIntervalResult, 0)
+	for _, integration := range integrations {
+		if !matchesIntegrationFilter(integration.SourceIntegrationMetadata, input) {
+			continue
+		}
+
+		result := &models.BulkSetScanIntervalResult{IntegrationID: integration.IntegrationID, Success: aws.Bool(true)}
+		_, err := db.UpdateItem(&ddb.UpdateIntegrationItem{
+			IntegrationID:    integration.IntegrationID,
+			ScanIntervalMins: input.ScanIntervalMins,
+		})
+		if err == nil {
+			err = recordUpdate(integration.IntegrationID, input.UserID, diffIntegration(integration.SourceIntegrationMetadata,
+				&models.UpdateIntegrationSettingsInput{ScanIntervalMins: input.ScanIntervalMins}))
+		}
+		if err != nil {
+			zap.L().Warn("failed to set scan interval",
+				zap.String("integrationId", *integration.IntegrationID), zap.Error(err))
+			result.Success = aws.Bool(false)
+			result.ErrorMessage = aws.String(err.Error())
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func matchesIntegrationFilter(integration *models.SourceIntegrationMetadata, input *models.BulkSetScanIntervalInput) bool {
+	if input.IntegrationType != nil && aws.StringValue(integration.IntegrationType) != *input.IntegrationType {
+		return false
+	}
+	if input.AWSAccountID != nil && aws.StringValue(integration.AWSAccountID) != *input.AWSAccountID {
+		return false
+	}
+	return true
+}
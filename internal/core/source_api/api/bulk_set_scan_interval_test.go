@@ -0,0 +1,102 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func scanItem(integrationID, integrationType string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"integrationId":   {S: aws.String(integrationID)},
+		"integrationType": {S: aws.String(integrationType)},
+		"awsAccountId":    {S: aws.String(testAccountID)},
+	}
+}
+
+// updatedIntegrationID matches the UpdateItem of one integration.
+func updatedIntegrationID(integrationID string) interface{} {
+	return mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["integrationId"].S == integrationID
+	})
+}
+
+func TestBulkSetScanInterval(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+		scanItem("scan-1", models.IntegrationTypeAWSScan),
+		scanItem("logs-1", models.IntegrationTypeAWS3),
+		scanItem("scan-2", models.IntegrationTypeAWSScan),
+	}}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", Clock: func() time.Time { return now }}
+	mockClient.On("UpdateItem", updatedIntegrationID("scan-1")).Return(&dynamodb.UpdateItemOutput{}, nil)
+	mockClient.On("UpdateItem", updatedIntegrationID("scan-2")).
+		Return(&dynamodb.UpdateItemOutput{}, errors.New("throttled"))
+
+	results, err := apiTest.BulkSetScanInterval(&models.BulkSetScanIntervalInput{
+		IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+		ScanIntervalMins: aws.Int(360),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, &models.BulkSetScanIntervalResult{IntegrationID: aws.String("scan-1"), Success: aws.Bool(true)}, results[0])
+	assert.Equal(t, "scan-2", *results[1].IntegrationID)
+	assert.False(t, *results[1].Success)
+	assert.Contains(t, *results[1].ErrorMessage, "throttled")
+
+	// The log integration is untouched, and only the interval is written besides the stamp of the write
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 2)
+	update := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var values []string
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, aws.StringValue(value.N)+aws.StringValue(value.S))
+	}
+	assert.ElementsMatch(t, []string{"360", "1", ddb.ModifiedTime(now), "integrations"}, values)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.ElementsMatch(t, []string{"scanIntervalMins", "version", "lastModifiedAt", "modifiedFeed"}, names)
+}
+
+func TestBulkSetScanIntervalValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	assert.NoError(t, validator.Struct(&models.BulkSetScanIntervalInput{ScanIntervalMins: aws.Int(1440)}))
+	assert.Error(t, validator.Struct(&models.BulkSetScanIntervalInput{ScanIntervalMins: aws.Int(90)}))
+	assert.Error(t, validator.Struct(&models.BulkSetScanIntervalInput{}))
+	assert.Error(t, validator.Struct(&models.BulkSetScanIntervalInput{
+		IntegrationType:  aws.String("aws-unknown"),
+		ScanIntervalMins: aws.Int(60),
+	}))
+}
@@ -0,0 +1,150 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	defaultChangeHistoryPageSize = 25
+
+	// Fixed width, so that change IDs sort chronologically
+	changeIDTimeFormat = "2006-01-02T15:04:05.000000000Z"
+)
+
+// GetIntegrationChangeHistory returns a page of the changes made to an integration, most recent first.
+func (API) GetIntegrationChangeHistory(input *models.GetIntegrationChangeHistoryInput) (*models.IntegrationChangeHistoryPage, error) {
+	pageSize := defaultChangeHistoryPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+
+	return listChanges(input.IntegrationID, "", "", pageSize, input.ExclusiveStartKey)
+}
+
+// GetIntegrationAuditLog returns a page of the audit log of an integration, most recent first.
+//
+// The audit log is the change history of the integration, which records the user and the caller of every change
+// along with the settings before and after it. It can be limited to a time range.
+func (API) GetIntegrationAuditLog(input *models.GetIntegrationAuditLogInput) (*models.IntegrationChangeHistoryPage, error) {
+	var from, before string
+	if input.StartTime != nil {
+		from = input.StartTime.UTC().Format(changeIDTimeFormat)
+	}
+	if input.EndTime != nil {
+		before = input.EndTime.UTC().Format(changeIDTimeFormat)
+	}
+	if from != "" && before != "" && from >= before {
+		return nil, &genericapi.InvalidInputError{Message: "endTime must be after startTime"}
+	}
+	pageSize := defaultChangeHistoryPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+	return listChanges(input.IntegrationID, from, before, pageSize, input.ExclusiveStartKey)
+}
+
+func listChanges(integrationID *string, from, before string, pageSize int,
+	exclusiveStartKey *string) (*models.IntegrationChangeHistoryPage, error) {
+
+	changes, lastChangeID, err := db.ListChanges(integrationID, from, before, pageSize, exclusiveStartKey)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = make([]*models.IntegrationChangeRecord, 0)
+	}
+	return &models.IntegrationChangeHistoryPage{Changes: changes, LastEvaluatedKey: lastChangeID}, nil
+}
+
+// callerIdentity identifies the caller of the request being handled, it is recorded along with the changes.
+//
+// The Lambda function handles one request at a time, the handler sets it for each of them.
+var callerIdentity string
+
+// SetCallerIdentity sets the identity of the caller of the request about to be handled, empty if it's unknown.
+func SetCallerIdentity(identity string) {
+	callerIdentity = identity
+}
+
+// recordChange adds a change to the history of an integration.
+//
+// The change has already been applied, so failing to record it only fails the operation with strict side effects.
+func recordChange(integrationID *string, operation string, changedBy *string, changes []*models.IntegrationFieldChange) error {
+	_, err := recordChangeWithID(integrationID, operation, changedBy, changes)
+	return err
+}
+
+// recordChangeWithID records a change like recordChange and returns the ID of its record, so that it can be deleted
+// if the change is undone.
+func recordChangeWithID(integrationID *string, operation string, changedBy *string,
+	changes []*models.IntegrationFieldChange) (*string, error) {
+
+	now := time.Now().UTC()
+	changeID := aws.String(now.Format(changeIDTimeFormat) + "-" + uuid.New().String())
+	return changeID, runSideEffect(sideEffectAudit, integrationID, func() error {
+		return db.PutChange(&models.IntegrationChangeRecord{
+			IntegrationID:  integrationID,
+			ChangeID:       changeID,
+			ChangedAt:      aws.Time(now),
+			ChangedBy:      changedBy,
+			CallerIdentity: callerIdentityOrNil(),
+			Operation:      aws.String(operation),
+			Changes:        changes,
+		})
+	})
+}
+
+func callerIdentityOrNil() *string {
+	if callerIdentity == "" {
+		return nil
+	}
+	return aws.String(callerIdentity)
+}
+
+// recordUpdate records an update of the settings of an integration, unless nothing changed.
+func recordUpdate(integrationID *string, changedBy *string, changes []*models.IntegrationFieldChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return recordChange(integrationID, models.ChangeOperationUpdated, changedBy, changes)
+}
+
+// creationChanges lists the settings of a new integration as added.
+func creationChanges(integration *models.SourceIntegrationMetadata) []*models.IntegrationFieldChange {
+	return diffIntegration(&models.SourceIntegrationMetadata{}, integrationSettings(integration))
+}
+
+// removalChanges lists the elements removed from a list setting.
+func removalChanges(field string, removed map[int]string) []*models.IntegrationFieldChange {
+	changes := make(changeSet, 0, len(removed))
+	for i := 0; len(changes) < len(removed); i++ {
+		if value, ok := removed[i]; ok {
+			changes.add(field, models.FieldRemoved, value, nil)
+		}
+	}
+	return changes
+}
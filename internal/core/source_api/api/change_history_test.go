@@ -0,0 +1,223 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// mockChangesDDBClient keeps the change records written with PutItem and queries them like the changes table.
+type mockChangesDDBClient struct {
+	modelstest.MockDDBClient
+	changes []map[string]*dynamodb.AttributeValue
+	queries []*dynamodb.QueryInput
+}
+
+func (client *mockChangesDDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	client.changes = append(client.changes, input.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (client *mockChangesDDBClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	client.queries = append(client.queries, input)
+	integrationID := *input.ExpressionAttributeValues[":0"].S
+	var items []map[string]*dynamodb.AttributeValue
+	for _, item := range client.changes {
+		if *item["integrationId"].S == integrationID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return *items[i]["changeId"].S > *items[j]["changeId"].S })
+
+	if input.ExclusiveStartKey != nil {
+		start := *input.ExclusiveStartKey["changeId"].S
+		for len(items) > 0 && *items[0]["changeId"].S >= start {
+			items = items[1:]
+		}
+	}
+	output := &dynamodb.QueryOutput{Items: items}
+	if limit := int(*input.Limit); len(items) > limit {
+		output.Items = items[:limit]
+		output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{
+			"integrationId": {S: aws.String(integrationID)},
+			"changeId":      items[limit-1]["changeId"],
+		}
+	}
+	return output, nil
+}
+
+func mockChangeRecord(t *testing.T, client *mockChangesDDBClient, changeID string, changedAt time.Time) {
+	item, err := dynamodbattribute.MarshalMap(&models.IntegrationChangeRecord{
+		IntegrationID: aws.String(testIntegrationID),
+		ChangeID:      aws.String(changeID),
+		ChangedAt:     aws.Time(changedAt),
+		Operation:     aws.String(models.ChangeOperationUpdated),
+		Changes:       []*models.IntegrationFieldChange{},
+	})
+	require.NoError(t, err)
+	client.changes = append(client.changes, item)
+}
+
+func TestGetIntegrationChangeHistory(t *testing.T) {
+	mockClient := &mockChangesDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", ChangesTableName: "test-changes"}
+	start := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Written out of order, the history is sorted by change ID
+	for _, i := range []int{1, 0, 2} {
+		changedAt := start.Add(time.Duration(i) * time.Hour)
+		mockChangeRecord(t, mockClient, changedAt.Format(changeIDTimeFormat)+"-change", changedAt)
+	}
+
+	page, err := apiTest.GetIntegrationChangeHistory(&models.GetIntegrationChangeHistoryInput{
+		IntegrationID: aws.String(testIntegrationID),
+		PageSize:      aws.Int(2),
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 2)
+	assert.Equal(t, start.Add(2*time.Hour), *page.Changes[0].ChangedAt)
+	assert.Equal(t, start.Add(time.Hour), *page.Changes[1].ChangedAt)
+	require.NotNil(t, page.LastEvaluatedKey)
+
+	page, err = apiTest.GetIntegrationChangeHistory(&models.GetIntegrationChangeHistoryInput{
+		IntegrationID:     aws.String(testIntegrationID),
+		PageSize:          aws.Int(2),
+		ExclusiveStartKey: page.LastEvaluatedKey,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 1)
+	assert.Equal(t, start, *page.Changes[0].ChangedAt)
+	assert.Nil(t, page.LastEvaluatedKey)
+}
+
+func TestGetIntegrationChangeHistoryEmpty(t *testing.T) {
+	db = &ddb.DDB{Client: &mockChangesDDBClient{}, TableName: "test", ChangesTableName: "test-changes"}
+
+	page, err := apiTest.GetIntegrationChangeHistory(&models.GetIntegrationChangeHistoryInput{
+		IntegrationID: aws.String(testIntegrationID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.IntegrationChangeHistoryPage{Changes: []*models.IntegrationChangeRecord{}}, page)
+}
+
+func TestUpdateIntegrationSettingsRecordsChange(t *testing.T) {
+	mockClient := &mockChangesDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", ChangesTableName: "test-changes"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("Owned by team-logs"),
+		UserID:        aws.String(testUserID),
+	})
+	require.NoError(t, err)
+
+	page, err := apiTest.GetIntegrationChangeHistory(&models.GetIntegrationChangeHistoryInput{
+		IntegrationID: aws.String(testIntegrationID),
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 1)
+	change := page.Changes[0]
+	assert.Equal(t, testIntegrationID, *change.IntegrationID)
+	assert.Equal(t, models.ChangeOperationUpdated, *change.Operation)
+	assert.Equal(t, testUserID, *change.ChangedBy)
+	assert.Equal(t, []*models.IntegrationFieldChange{
+		{Field: aws.String("description"), Action: aws.String(models.FieldAdded), Desired: "Owned by team-logs"},
+	}, change.Changes)
+}
+
+func TestRemovalChanges(t *testing.T) {
+	assert.Equal(t, []*models.IntegrationFieldChange{
+		{Field: aws.String("s3Buckets"), Action: aws.String(models.FieldRemoved), Current: "bucket-a"},
+		{Field: aws.String("s3Buckets"), Action: aws.String(models.FieldRemoved), Current: "bucket-c"},
+	}, removalChanges("s3Buckets", map[int]string{2: "bucket-c", 0: "bucket-a"}))
+}
+
+func TestGetIntegrationAuditLogTimeRange(t *testing.T) {
+	mockClient := &mockChangesDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", ChangesTableName: "test-changes"}
+	start := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	page, err := apiTest.GetIntegrationAuditLog(&models.GetIntegrationAuditLogInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StartTime:     aws.Time(start),
+		EndTime:       aws.Time(start.Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, page.Changes)
+
+	require.Len(t, mockClient.queries, 1)
+	query := mockClient.queries[0]
+	assert.Contains(t, *query.KeyConditionExpression, "BETWEEN")
+	values := make([]string, 0, len(query.ExpressionAttributeValues))
+	for _, value := range query.ExpressionAttributeValues {
+		values = append(values, *value.S)
+	}
+	assert.ElementsMatch(t, []string{
+		testIntegrationID, start.Format(changeIDTimeFormat), start.Add(time.Hour).Format(changeIDTimeFormat),
+	}, values)
+}
+
+func TestGetIntegrationAuditLogInvalidTimeRange(t *testing.T) {
+	db = &ddb.DDB{Client: &mockChangesDDBClient{}, TableName: "test", ChangesTableName: "test-changes"}
+	start := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	page, err := apiTest.GetIntegrationAuditLog(&models.GetIntegrationAuditLogInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StartTime:     aws.Time(start),
+		EndTime:       aws.Time(start),
+	})
+	assert.Nil(t, page)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestRecordChangeCallerIdentity(t *testing.T) {
+	mockClient := &mockChangesDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", ChangesTableName: "test-changes"}
+	SetCallerIdentity("panther-snapshot-scheduler")
+	defer SetCallerIdentity("")
+
+	require.NoError(t, recordChange(aws.String(testIntegrationID), models.ChangeOperationDeleted, nil, nil))
+	SetCallerIdentity("")
+	require.NoError(t, recordChange(aws.String(testIntegrationID), models.ChangeOperationRestored, nil, nil))
+
+	page, err := apiTest.GetIntegrationAuditLog(&models.GetIntegrationAuditLogInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 2)
+	byOperation := make(map[string]*models.IntegrationChangeRecord, 2)
+	for _, change := range page.Changes {
+		byOperation[*change.Operation] = change
+	}
+	assert.Equal(t, aws.String("panther-snapshot-scheduler"), byOperation[models.ChangeOperationDeleted].CallerIdentity)
+	assert.Nil(t, byOperation[models.ChangeOperationRestored].CallerIdentity)
+}
@@ -0,0 +1,67 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const defaultChangedIntegrationsPageSize = 25
+
+// ListChangedIntegrations returns a page of the integrations changed after the given time, the least recently
+// changed first, so that a mirror of the integrations can sync from the last change it has seen.
+//
+// Every write to an integration changes it, including the updates of its status by scans. Deleted integrations
+// are listed with Deleted set, until their marker expires. Integrations which weren't written to since they
+// were first indexed by the time of their last write aren't listed.
+func (API) ListChangedIntegrations(input *models.ListChangedIntegrationsInput) (*models.ChangedIntegrationsPage, error) {
+	var position *ddb.ModifiedKey
+	if input.ExclusiveStartKey != nil {
+		decoded, err := base64.URLEncoding.DecodeString(*input.ExclusiveStartKey)
+		if err == nil {
+			err = json.Unmarshal(decoded, &position)
+		}
+		if err != nil || position == nil {
+			return nil, &genericapi.InvalidInputError{Message: "invalid exclusiveStartKey"}
+		}
+	}
+	pageSize := defaultChangedIntegrationsPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+
+	integrations, last, err := db.ListModifiedSince(*input.SinceTime, pageSize, position)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.ChangedIntegrationsPage{Integrations: integrations}
+	if last != nil {
+		// This can't fail, the struct only has basic types
+		encoded, _ := json.Marshal(last)
+		page.LastEvaluatedKey = aws.String(base64.URLEncoding.EncodeToString(encoded))
+	}
+	return page, nil
+}
@@ -0,0 +1,153 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// mockModifiedDDBClient keeps the integrations of the "test" table, and queries them by the time of their last write.
+type mockModifiedDDBClient struct {
+	modelstest.MockDDBClient
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func (client *mockModifiedDDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: client.items[*input.Key["integrationId"].S]}, nil
+}
+
+func (client *mockModifiedDDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	integrationID := *input.Key["integrationId"].S
+	deleted := client.items[integrationID]
+	delete(client.items, integrationID)
+	return &dynamodb.DeleteItemOutput{Attributes: deleted}, nil
+}
+
+// PutItem accepts the change records too, only the integrations are kept.
+func (client *mockModifiedDDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if *input.TableName == "test" {
+		client.items[*input.Item["integrationId"].S] = input.Item
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (client *mockModifiedDDBClient) Scan(*dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return &dynamodb.ScanOutput{}, nil
+}
+
+func (client *mockModifiedDDBClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	var since string
+	for _, value := range input.ExpressionAttributeValues {
+		if *value.S != "integrations" {
+			since = *value.S
+		}
+	}
+	var items []map[string]*dynamodb.AttributeValue
+	for _, item := range client.items {
+		if item["modifiedFeed"] != nil && *item["lastModifiedAt"].S > since {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return *items[i]["lastModifiedAt"].S < *items[j]["lastModifiedAt"].S })
+
+	if input.ExclusiveStartKey != nil {
+		start := *input.ExclusiveStartKey["lastModifiedAt"].S
+		for len(items) > 0 && *items[0]["lastModifiedAt"].S <= start {
+			items = items[1:]
+		}
+	}
+	output := &dynamodb.QueryOutput{Items: items}
+	if limit := int(*input.Limit); len(items) > limit {
+		output.Items = items[:limit]
+		output.LastEvaluatedKey = output.Items[limit-1]
+	}
+	return output, nil
+}
+
+func modifiedItem(integrationID string, lastModifiedAt time.Time) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"integrationId":   {S: aws.String(integrationID)},
+		"integrationType": {S: aws.String(models.IntegrationTypeAWSScan)},
+		"lastModifiedAt":  {S: aws.String(ddb.ModifiedTime(lastModifiedAt))},
+		"modifiedFeed":    {S: aws.String("integrations")},
+	}
+}
+
+func TestListChangedIntegrations(t *testing.T) {
+	since := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	unchangedID, changedID := "7d4c6ba4-6bc9-4ea6-b6a4-2e8d52d8f7a1", "0d6c6c4e-3c5b-4bb5-9a3e-86b2a1a3c9f2"
+	mockClient := &mockModifiedDDBClient{items: map[string]map[string]*dynamodb.AttributeValue{
+		unchangedID:       modifiedItem(unchangedID, since.Add(-time.Hour)),
+		changedID:         modifiedItem(changedID, since.Add(time.Minute)),
+		testIntegrationID: modifiedItem(testIntegrationID, since.Add(-time.Hour)),
+	}}
+	deletedAt := since.Add(time.Hour)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", Clock: func() time.Time { return deletedAt }}
+
+	require.NoError(t, apiTest.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: aws.String(testIntegrationID)}))
+	// The marker of the deleted integration isn't an integration
+	integration, err := db.GetIntegration(aws.String(testIntegrationID), true)
+	require.NoError(t, err)
+	assert.Nil(t, integration)
+
+	page, err := apiTest.ListChangedIntegrations(&models.ListChangedIntegrationsInput{
+		SinceTime: aws.Time(since),
+		PageSize:  aws.Int(1),
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Integrations, 1)
+	assert.Equal(t, changedID, *page.Integrations[0].IntegrationID)
+	assert.Nil(t, page.Integrations[0].Deleted)
+	require.NotNil(t, page.LastEvaluatedKey)
+
+	page, err = apiTest.ListChangedIntegrations(&models.ListChangedIntegrationsInput{
+		SinceTime:         aws.Time(since),
+		PageSize:          aws.Int(1),
+		ExclusiveStartKey: page.LastEvaluatedKey,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Integrations, 1)
+	deleted := page.Integrations[0]
+	assert.Equal(t, testIntegrationID, *deleted.IntegrationID)
+	assert.True(t, *deleted.Deleted)
+	assert.Equal(t, models.IntegrationTypeAWSScan, *deleted.IntegrationType)
+	assert.Equal(t, deletedAt, *deleted.LastModifiedAt)
+	assert.Nil(t, page.LastEvaluatedKey)
+}
+
+func TestListChangedIntegrationsInvalidStartKey(t *testing.T) {
+	page, err := apiTest.ListChangedIntegrations(&models.ListChangedIntegrationsInput{
+		SinceTime:         aws.Time(time.Now()),
+		ExclusiveStartKey: aws.String("not a key"),
+	})
+	assert.Nil(t, page)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
@@ -0,0 +1,177 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The start of a tar archive which is read to expand its first file, compressed or not
+const archiveHeaderBytes = 64 * 1024
+
+// checkArchives expands the sampled object of each bucket whose object could be read, as an archive of the format.
+//
+// Only the start of a tar archive is read, up to the header of its first file. A zip archive is opened from its
+// central directory at the end of the object, with ranged reads rather than downloading the whole archive.
+func checkArchives(roleCredentials *credentials.Credentials, buckets []*string, regions map[string]*string,
+	format string, readStatuses map[string]models.SourceIntegrationItemStatus) map[string]models.SourceIntegrationItemStatus {
+
+	clientForBucket := bucketClients(roleCredentials, regions)
+	statuses := make(map[string]models.SourceIntegrationItemStatus, len(buckets))
+	for _, bucket := range buckets {
+		if status, ok := readStatuses[*bucket]; !ok || !aws.BoolValue(status.Healthy) {
+			// Already reported by the object check
+			continue
+		}
+		name, pattern := parseBucketEntry(*bucket)
+		s3Client, _ := clientForBucket(name)
+
+		start := time.Now()
+		key, err := sampleObjectKey(s3Client, name, pattern)
+		if err == nil && key == nil {
+			continue
+		}
+		if err == nil {
+			err = expandArchive(s3Client, name, *key, format)
+		}
+		switch {
+		case isThrottlingError(err):
+			zap.L().Warn("archive check throttled", zap.String("bucket", *bucket), zap.Error(err))
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		case err != nil:
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String(fmt.Sprintf("failed to expand %s as a %s archive: %s", *key, format, err)),
+				LatencyMillis: millisSince(start),
+			}
+		default:
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(true),
+				LatencyMillis: millisSince(start),
+			}
+		}
+	}
+	return statuses
+}
+
+// expandArchive reads the first file of an archive object, an error means it can't be expanded.
+func expandArchive(s3Client s3iface.S3API, bucket, key, format string) error {
+	if format == models.ArchiveFormatZip {
+		object := &s3ObjectReader{s3Client: s3Client, bucket: bucket, key: key}
+		size, err := object.size()
+		if err != nil {
+			return err
+		}
+		archive, err := zip.NewReader(object, size)
+		if err != nil {
+			return err
+		}
+		if len(archive.File) == 0 {
+			return fmt.Errorf("no file in the archive")
+		}
+		return nil
+	}
+
+	header, err := readRange(s3Client, bucket, key, 0, archiveHeaderBytes-1)
+	if err != nil {
+		return err
+	}
+	var reader io.Reader = bytes.NewReader(header)
+	if format == models.ArchiveFormatTarGzip {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return err
+		}
+	}
+	_, err = tar.NewReader(reader).Next()
+	return err
+}
+
+// s3ObjectReader reads an object with a ranged request for each read.
+type s3ObjectReader struct {
+	s3Client    s3iface.S3API
+	bucket, key string
+}
+
+// size returns the size of the object, from the range of a read of its first byte.
+func (object *s3ObjectReader) size() (int64, error) {
+	output, err := object.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(object.bucket),
+		Key:    aws.String(object.key),
+		Range:  aws.String("bytes=0-0"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer output.Body.Close()
+	// The content range is e.g. "bytes 0-0/1234"
+	contentRange := aws.StringValue(output.ContentRange)
+	size, err := strconv.ParseInt(contentRange[strings.LastIndex(contentRange, "/")+1:], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("no size in the content range %q", contentRange)
+	}
+	return size, nil
+}
+
+func (object *s3ObjectReader) ReadAt(p []byte, offset int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	data, err := readRange(object.s3Client, object.bucket, object.key, offset, offset+int64(len(p))-1)
+	n := copy(p, data)
+	if err == nil && n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// readRange reads the bytes of an object from first to last included, fewer if the object ends before.
+func readRange(s3Client s3iface.S3API, bucket, key string, first, last int64) ([]byte, error) {
+	output, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", first, last)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return ioutil.ReadAll(output.Body)
+}
@@ -0,0 +1,160 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// archiveS3Client serves objects from memory, honoring the range of the reads like S3.
+type archiveS3Client struct {
+	s3iface.S3API
+	objects map[string][]byte
+	reads   int
+}
+
+func (client *archiveS3Client) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	output := &s3.ListObjectsV2Output{}
+	for key, data := range client.objects {
+		output.Contents = append(output.Contents, &s3.Object{Key: aws.String(key), Size: aws.Int64(int64(len(data)))})
+	}
+	sort.Slice(output.Contents, func(i, j int) bool { return *output.Contents[i].Key < *output.Contents[j].Key })
+	return output, nil
+}
+
+func (client *archiveS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	client.reads++
+	data := client.objects[*input.Key]
+	var first, last int64
+	_, err := fmt.Sscanf(*input.Range, "bytes=%d-%d", &first, &last)
+	if err != nil {
+		return nil, err
+	}
+	if last >= int64(len(data)) {
+		last = int64(len(data)) - 1
+	}
+	return &s3.GetObjectOutput{
+		Body:         ioutil.NopCloser(bytes.NewReader(data[first : last+1])),
+		ContentRange: aws.String(fmt.Sprintf("bytes %d-%d/%d", first, last, len(data))),
+	}, nil
+}
+
+func zipArchive(t *testing.T) []byte {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	file, err := writer.Create("logs/1.json")
+	require.NoError(t, err)
+	_, err = file.Write(bytes.Repeat([]byte(`{"event":"login"}`+"\n"), 1000))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func tarArchive(t *testing.T, compressed bool) []byte {
+	var buffer bytes.Buffer
+	var gzipWriter *gzip.Writer
+	writer := tar.NewWriter(&buffer)
+	if compressed {
+		gzipWriter = gzip.NewWriter(&buffer)
+		writer = tar.NewWriter(gzipWriter)
+	}
+	content := bytes.Repeat([]byte(`{"event":"login"}`+"\n"), 1000)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "logs/1.json", Mode: 0600, Size: int64(len(content))}))
+	_, err := writer.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	if compressed {
+		require.NoError(t, gzipWriter.Close())
+	}
+	return buffer.Bytes()
+}
+
+func mockArchiveClient(objects map[string][]byte) *archiveS3Client {
+	client := &archiveS3Client{objects: objects}
+	s3ClientFunc = func(*credentials.Credentials) s3iface.S3API { return client }
+	return client
+}
+
+func TestCheckArchives(t *testing.T) {
+	readable := map[string]models.SourceIntegrationItemStatus{"bucket": {Healthy: aws.Bool(true)}}
+	for format, archive := range map[string][]byte{
+		models.ArchiveFormatZip:     zipArchive(t),
+		models.ArchiveFormatTar:     tarArchive(t, false),
+		models.ArchiveFormatTarGzip: tarArchive(t, true),
+	} {
+		mockArchiveClient(map[string][]byte{"logs.archive": archive})
+		statuses := checkArchives(nil, aws.StringSlice([]string{"bucket"}), nil, format, readable)
+		assert.True(t, *statuses["bucket"].Healthy, format)
+	}
+}
+
+func TestCheckArchivesNotAnArchive(t *testing.T) {
+	readable := map[string]models.SourceIntegrationItemStatus{
+		"bucket": {Healthy: aws.Bool(true)},
+		"unread": {Healthy: aws.Bool(false)},
+	}
+	client := mockArchiveClient(map[string][]byte{"logs.json": []byte(`{"event":"login"}`)})
+
+	statuses := checkArchives(nil, aws.StringSlice([]string{"bucket", "unread"}), nil, models.ArchiveFormatZip, readable)
+	require.Len(t, statuses, 1)
+	assert.False(t, *statuses["bucket"].Healthy)
+	assert.Contains(t, *statuses["bucket"].ErrorMessage, "failed to expand logs.json as a zip archive")
+
+	// A tar archive of the wrong format isn't a zip archive either
+	client.objects = map[string][]byte{"logs.tar": tarArchive(t, false)}
+	statuses = checkArchives(nil, aws.StringSlice([]string{"bucket"}), nil, models.ArchiveFormatZip, readable)
+	assert.False(t, *statuses["bucket"].Healthy)
+}
+
+func TestArchiveFormatValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	input := &models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		ArchiveFormat: aws.String(models.ArchiveFormatTarGzip),
+	}
+	assert.NoError(t, validator.Struct(input))
+	input.ArchiveFormat = aws.String("rar")
+	assert.Error(t, validator.Struct(input))
+}
+
+func TestCheckArchiveFormat(t *testing.T) {
+	assert.NoError(t, checkArchiveFormat(aws.String(models.IntegrationTypeAWS3), aws.String(models.ArchiveFormatZip)))
+	assert.NoError(t, checkArchiveFormat(aws.String(models.IntegrationTypeAWSKinesis), nil))
+	assert.IsType(t, &genericapi.InvalidInputError{},
+		checkArchiveFormat(aws.String(models.IntegrationTypeAWSKinesis), aws.String(models.ArchiveFormatTar)))
+}
@@ -20,16 +20,29 @@ package api
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 const (
@@ -37,51 +50,229 @@ const (
 	logProcessingRoleFormat = "arn:aws:iam::%s:role/PantherLogProcessingRole"
 	cweRoleFormat           = "arn:aws:iam::%s:role/PantherCloudFormationStackSetExecutionRole"
 	remediationRoleFormat   = "arn:aws:iam::%s:role/PantherRemediationRole"
+	organizationRoleFormat  = "arn:aws:iam::%s:role/PantherOrganizationRole"
+
+	// The region the buckets without a configured region are reported in, they are checked in the region of the session
+	defaultBucketRegion = "default"
 )
 
 var evaluateIntegrationFunc = evaluateIntegration
 
+// The clients used by the health check assume the role being checked, they can be replaced in unit tests.
+var (
+	stsClientFunc = func(roleCredentials *credentials.Credentials) stsiface.STSAPI {
+		return sts.New(sess, &aws.Config{Credentials: roleCredentials})
+	}
+	s3ClientFunc = func(roleCredentials *credentials.Credentials) s3iface.S3API {
+		return s3.New(sess, &aws.Config{Credentials: roleCredentials})
+	}
+	regionalS3ClientFunc = func(roleCredentials *credentials.Credentials, region string) s3iface.S3API {
+		return s3.New(sess, &aws.Config{Credentials: roleCredentials, Region: aws.String(region)})
+	}
+	kmsClientFunc = func(roleCredentials *credentials.Credentials) kmsiface.KMSAPI {
+		return kms.New(sess, &aws.Config{Credentials: roleCredentials})
+	}
+	iamClientFunc = func(roleCredentials *credentials.Credentials) iamiface.IAMAPI {
+		return iam.New(sess, &aws.Config{Credentials: roleCredentials})
+	}
+)
+
+// healthCheckOverride replaces the real health check with a scripted one.
+//
+// It is only ever set in builds with the healthcheckmock tag, see check_integration_mock.go
+var healthCheckOverride func(*models.CheckIntegrationInput) *models.SourceIntegrationHealth
+
 // CheckIntegration adds a set of new integrations in a batch.
-func (API) CheckIntegration(input *models.CheckIntegrationInput) (*models.SourceIntegrationHealth, error) {
-	zap.L().Debug("beginning source health check")
+func (api API) CheckIntegration(input *models.CheckIntegrationInput) (*models.SourceIntegrationHealth, error) {
+	if healthCheckOverride != nil {
+		api.log().Warn("using mock source health check")
+		return healthCheckOverride(input), nil
+	}
+
+	api.log().Debug("beginning source health check")
 	out := &models.SourceIntegrationHealth{
 		AWSAccountID:    input.AWSAccountID,
 		IntegrationType: input.IntegrationType,
 	}
 
 	if *input.IntegrationType == models.IntegrationTypeAWSScan {
-		_, out.AuditRoleStatus = getCredentialsWithStatus(aws.String(fmt.Sprintf(auditRoleFormat, *input.AWSAccountID)))
+		_, out.AuditRoleStatus = getCredentialsWithStatus(api.log(), aws.String(fmt.Sprintf(auditRoleFormat, *input.AWSAccountID)))
 		if aws.BoolValue(input.EnableCWESetup) {
-			_, out.CWERoleStatus = getCredentialsWithStatus(aws.String(fmt.Sprintf(cweRoleFormat, *input.AWSAccountID)))
+			_, out.CWERoleStatus = getCredentialsWithStatus(api.log(), aws.String(fmt.Sprintf(cweRoleFormat, *input.AWSAccountID)))
 		}
 		if aws.BoolValue(input.EnableRemediation) {
-			_, out.RemediationRoleStatus = getCredentialsWithStatus(aws.String(fmt.Sprintf(remediationRoleFormat, *input.AWSAccountID)))
+			_, out.RemediationRoleStatus = getCredentialsWithStatus(api.log(), aws.String(fmt.Sprintf(remediationRoleFormat, *input.AWSAccountID)))
 		}
 	}
 
 	if *input.IntegrationType == models.IntegrationTypeAWS3 {
-		var roleCreds *credentials.Credentials
-		roleCreds, out.ProcessingRoleStatus = getCredentialsWithStatus(aws.String(fmt.Sprintf(logProcessingRoleFormat, *input.AWSAccountID)))
+		roleCreds := processingRoleCredentials(api.log(), input, out)
 		if len(input.S3Buckets) > 0 && *out.ProcessingRoleStatus.Healthy {
-			out.S3BucketsStatus = checkBuckets(roleCreds, input.S3Buckets)
+			out.S3BucketsStatus, out.S3RegionsStatus = checkBuckets(api.log(), roleCreds, input.S3Buckets, input.S3BucketRegions)
+			out.S3ObjectReadStatus, out.S3ObjectDecryptStatus = checkObjects(
+				roleCreds, input.S3Buckets, input.S3BucketRegions, out.S3BucketsStatus)
+			out.S3BucketEncryptionStatus, out.DiscoveredKmsKeys = checkBucketEncryption(api.log(), roleCreds, input.S3Buckets,
+				input.S3BucketRegions, input.KmsKeys, out.S3BucketsStatus, out.S3ObjectDecryptStatus)
+			if input.ArchiveFormat != nil {
+				out.S3ArchiveStatus = checkArchives(
+					roleCreds, input.S3Buckets, input.S3BucketRegions, *input.ArchiveFormat, out.S3ObjectReadStatus)
+			}
 		}
 		if len(input.KmsKeys) > 0 && *out.ProcessingRoleStatus.Healthy {
-			out.KMSKeysStatus = checkKeys(roleCreds, input.KmsKeys)
+			out.KMSKeysStatus, out.CredentialExpiry = checkKeys(roleCreds, input.KmsKeys)
+		}
+		if len(input.KmsGrants) > 0 && *out.ProcessingRoleStatus.Healthy {
+			out.KMSGrantsStatus = checkGrants(roleCreds, input.KmsGrants)
+		}
+		if aws.BoolValue(input.IsOrgTrail) && *out.ProcessingRoleStatus.Healthy {
+			var organizationID string
+			organizationID, out.OrgTrailStatus = checkOrgTrail(api.log(), roleCreds, input.ManagementAccountID)
+			if *out.OrgTrailStatus.Healthy && len(out.S3BucketsStatus) > 0 {
+				out.OrgTrailBucketsStatus = checkOrgTrailBuckets(
+					api.log(), roleCreds, organizationID, input.S3Buckets, input.S3BucketRegions, out.S3BucketsStatus)
+			}
+		}
+		if input.DeadLetterQueueArn != nil && *out.ProcessingRoleStatus.Healthy {
+			out.DeadLetterQueueStatus = checkDeadLetterQueue(api.log(), roleCreds, input.DeadLetterQueueArn)
+		}
+		if *out.ProcessingRoleStatus.Healthy {
+			out.RolePolicyStatus, out.ExcessPermissions = checkRolePolicy(roleCreds, processingRoleARN(input), input)
+		}
+		if input.SessionDurationSeconds != nil && *out.ProcessingRoleStatus.Healthy {
+			out.SessionDurationStatus = checkSessionDuration(roleCreds, processingRoleARN(input), *input.SessionDurationSeconds)
+		}
+		for _, check := range input.DisabledChecks {
+			switch *check {
+			case models.HealthCheckS3Buckets:
+				markInformational(out.S3BucketsStatus)
+				markInformational(out.OrgTrailBucketsStatus)
+			case models.HealthCheckKMSKeys:
+				markInformational(out.KMSKeysStatus)
+				markInformational(out.KMSGrantsStatus)
+				markInformational(out.S3BucketEncryptionStatus)
+			case models.HealthCheckS3Objects:
+				markInformational(out.S3ObjectReadStatus)
+				markInformational(out.S3ObjectDecryptStatus)
+				markInformational(out.S3ArchiveStatus)
+			}
+		}
+	}
+
+	if *input.IntegrationType == models.IntegrationTypeAWSKinesis {
+		roleCreds := processingRoleCredentials(api.log(), input, out)
+		if input.StreamARN != nil && *out.ProcessingRoleStatus.Healthy {
+			out.KinesisStreamStatus = checkKinesisStream(roleCreds, input.StreamARN)
+		}
+		if input.KinesisConsumerARN != nil && *out.ProcessingRoleStatus.Healthy {
+			out.KinesisConsumerStatus = checkKinesisConsumer(roleCreds, input.KinesisConsumerARN)
+		}
+		if input.SessionDurationSeconds != nil && *out.ProcessingRoleStatus.Healthy {
+			out.SessionDurationStatus = checkSessionDuration(roleCreds, processingRoleARN(input), *input.SessionDurationSeconds)
 		}
 	}
 
+	if *input.IntegrationType == models.IntegrationTypeAzureScan {
+		checkAzureSubscription(input, out)
+	}
+	if *input.IntegrationType == models.IntegrationTypeGCPLogSource {
+		checkGCPLogSource(input, out)
+	}
+	if *input.IntegrationType == models.IntegrationTypeSQSQueue && input.SQSQueueArn != nil {
+		out.SQSQueueStatus = checkSQSQueue(input.SQSQueueArn)
+	}
+	if *input.IntegrationType == models.IntegrationTypeHTTPIngest && input.HTTPIngestAPIKeyID != nil {
+		out.HTTPIngestKeyStatus = checkHTTPIngestKey(input.HTTPIngestAPIKeyID)
+	}
+	if *input.IntegrationType == models.IntegrationTypeOkta {
+		out.OktaSystemLogStatus = checkOktaSystemLog(input)
+	}
+	if *input.IntegrationType == models.IntegrationTypeAWSOrganization {
+		var roleCreds *credentials.Credentials
+		roleCreds, out.OrganizationRoleStatus = getCredentialsWithStatus(
+			api.log(), aws.String(fmt.Sprintf(organizationRoleFormat, *input.AWSAccountID)))
+		if *out.OrganizationRoleStatus.Healthy {
+			// Only the management account can list the member accounts
+			_, out.OrganizationStatus = checkOrgTrail(api.log(), roleCreds, input.AWSAccountID)
+		}
+	}
+
+	out.TotalLatencyMillis = aws.Int64(totalLatencyMillis(out))
 	return out, nil
 }
-func checkKeys(roleCredentials *credentials.Credentials, keys []*string) map[string]models.SourceIntegrationItemStatus {
-	kmsClient := kms.New(sess, &aws.Config{Credentials: roleCredentials})
+
+// markInformational flags the statuses of a disabled sub-check, their result is still reported.
+func markInformational(statuses map[string]models.SourceIntegrationItemStatus) {
+	for item, status := range statuses {
+		status.Informational = aws.Bool(true)
+		statuses[item] = status
+	}
+}
+
+// totalLatencyMillis adds up the latency of every sub-check which was run.
+func totalLatencyMillis(health *models.SourceIntegrationHealth) int64 {
+	var total int64
+	for _, status := range []models.SourceIntegrationItemStatus{
+		health.AuditRoleStatus,
+		health.CWERoleStatus,
+		health.RemediationRoleStatus,
+		health.ProcessingRoleStatus,
+		health.RolePolicyStatus,
+		health.OrgTrailStatus,
+		health.KinesisStreamStatus,
+		health.KinesisConsumerStatus,
+		health.DeadLetterQueueStatus,
+		health.AzureServicePrincipalStatus,
+		health.AzureSubscriptionStatus,
+		health.GCPServiceAccountStatus,
+		health.GCPLogSourceStatus,
+		health.SQSQueueStatus,
+		health.HTTPIngestKeyStatus,
+		health.OktaSystemLogStatus,
+		health.OrganizationRoleStatus,
+		health.OrganizationStatus,
+	} {
+		total += aws.Int64Value(status.LatencyMillis)
+	}
+	for _, statuses := range []map[string]models.SourceIntegrationItemStatus{
+		health.S3BucketsStatus,
+		health.KMSKeysStatus,
+		health.KMSGrantsStatus,
+		health.OrgTrailBucketsStatus,
+		health.S3ObjectReadStatus,
+		health.S3ObjectDecryptStatus,
+		health.S3ArchiveStatus,
+		health.S3BucketEncryptionStatus,
+	} {
+		for _, status := range statuses {
+			total += aws.Int64Value(status.LatencyMillis)
+		}
+	}
+	return total
+}
+
+// millisSince returns the time elapsed since start in milliseconds.
+func millisSince(start time.Time) *int64 {
+	return aws.Int64(time.Since(start).Milliseconds())
+}
+
+// checkKeys verifies each key is enabled and can be described by the processing role.
+//
+// It also returns when the earliest key material imported into the keys expires, nil if none does.
+func checkKeys(
+	roleCredentials *credentials.Credentials, keys []*string) (map[string]models.SourceIntegrationItemStatus, *time.Time) {
+
+	kmsClient := kmsClientFunc(roleCredentials)
 
 	keyStatuses := make(map[string]models.SourceIntegrationItemStatus, len(keys))
+	var expiry *time.Time
 	for _, key := range keys {
+		start := time.Now()
 		info, err := kmsClient.DescribeKey(&kms.DescribeKeyInput{KeyId: key})
 		if err != nil {
 			keyStatuses[*key] = models.SourceIntegrationItemStatus{
-				Healthy:      aws.Bool(false),
-				ErrorMessage: aws.String(err.Error()),
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
 			}
 			continue
 		}
@@ -89,71 +280,316 @@ func checkKeys(roleCredentials *credentials.Credentials, keys []*string) map[str
 		if !*info.KeyMetadata.Enabled {
 			// If the key is disabled, we should fail as well
 			keyStatuses[*key] = models.SourceIntegrationItemStatus{
-				Healthy:      aws.Bool(false),
-				ErrorMessage: aws.String("key disabled"),
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String("key disabled"),
+				LatencyMillis: millisSince(start),
 			}
 			continue
 		}
 
+		if validTo := info.KeyMetadata.ValidTo; validTo != nil && (expiry == nil || validTo.Before(*expiry)) {
+			expiry = validTo
+		}
 		keyStatuses[*key] = models.SourceIntegrationItemStatus{
-			Healthy: aws.Bool(true),
+			Healthy:       aws.Bool(true),
+			LatencyMillis: millisSince(start),
 		}
 	}
 
-	return keyStatuses
+	return keyStatuses, expiry
 }
 
-func checkBuckets(roleCredentials *credentials.Credentials, buckets []*string) map[string]models.SourceIntegrationItemStatus {
-	s3Client := s3.New(sess, &aws.Config{Credentials: roleCredentials})
+// checkBuckets verifies the processing role can reach each bucket.
+//
+// GetBucketLocation is a single request per bucket and is granted to the log processing role by the
+// onboarding template, so no listing of the bucket contents is needed. Throttled requests (after the
+// retries of the SDK) are reported as inconclusive instead of unhealthy.
+//
+// Buckets with a region are checked with a client of that region, and must actually be located there.
+// When the S3 endpoint of a region is unavailable, the region is reported as inconclusive along with
+// all its buckets, the remaining buckets of the region are not checked.
+func checkBuckets(
+	logger *zap.Logger, roleCredentials *credentials.Credentials, buckets []*string, regions map[string]*string,
+) (bucketStatuses, regionStatuses map[string]models.SourceIntegrationItemStatus) {
 
-	bucketStatuses := make(map[string]models.SourceIntegrationItemStatus, len(buckets))
+	clientForBucket := bucketClients(roleCredentials, regions)
+	bucketStatuses = make(map[string]models.SourceIntegrationItemStatus, len(buckets))
+	regionStatuses = make(map[string]models.SourceIntegrationItemStatus)
 	for _, bucket := range buckets {
-		_, err := s3Client.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: bucket})
-		if err != nil {
+		name, _ := parseBucketEntry(*bucket)
+		s3Client, region := clientForBucket(name)
+		regionName := region
+		if regionName == "" {
+			regionName = defaultBucketRegion
+		}
+		if regionStatus, ok := regionStatuses[regionName]; ok && aws.BoolValue(regionStatus.Inconclusive) {
 			bucketStatuses[*bucket] = models.SourceIntegrationItemStatus{
 				Healthy:      aws.Bool(false),
+				Inconclusive: aws.Bool(true),
+				ErrorMessage: aws.String(fmt.Sprintf("S3 is unavailable in region %s", regionName)),
+			}
+			continue
+		}
+
+		start := time.Now()
+		location, err := s3Client.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(name)})
+		switch {
+		case isThrottlingError(err):
+			logger.Warn("bucket check throttled", zap.String("bucket", *bucket), zap.Error(err))
+			bucketStatuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		case isUnavailableError(err):
+			logger.Warn("S3 unavailable in region", zap.String("region", regionName), zap.Error(err))
+			regionStatuses[regionName] = models.SourceIntegrationItemStatus{
+				Healthy:      aws.Bool(false),
+				Inconclusive: aws.Bool(true),
 				ErrorMessage: aws.String(err.Error()),
 			}
-		} else {
 			bucketStatuses[*bucket] = models.SourceIntegrationItemStatus{
-				Healthy: aws.Bool(true),
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+			continue
+		case err != nil:
+			bucketStatuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		case region != "" && s3.NormalizeBucketLocation(aws.StringValue(location.LocationConstraint)) != region:
+			bucketStatuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy: aws.Bool(false),
+				ErrorMessage: aws.String(fmt.Sprintf("bucket is located in %s, not %s",
+					s3.NormalizeBucketLocation(aws.StringValue(location.LocationConstraint)), region)),
+				LatencyMillis: millisSince(start),
+			}
+		default:
+			bucketStatuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(true),
+				LatencyMillis: millisSince(start),
 			}
 		}
+		// Any answer of S3, even a denial, means it is available in the region
+		regionStatuses[regionName] = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true)}
 	}
 
-	return bucketStatuses
+	return bucketStatuses, regionStatuses
+}
+
+// bucketClients returns the S3 client for a bucket along with its configured region, if any.
+//
+// Buckets with a region get a client of that region, which is created once and shared by all its buckets.
+func bucketClients(
+	roleCredentials *credentials.Credentials, regions map[string]*string) func(bucket string) (s3iface.S3API, string) {
+
+	defaultClient := s3ClientFunc(roleCredentials)
+	regionalClients := make(map[string]s3iface.S3API)
+	return func(bucket string) (s3iface.S3API, string) {
+		region := aws.StringValue(regions[bucket])
+		if region == "" {
+			return defaultClient, region
+		}
+		if _, ok := regionalClients[region]; !ok {
+			regionalClients[region] = regionalS3ClientFunc(roleCredentials, region)
+		}
+		return regionalClients[region], region
+	}
+}
+
+// isThrottlingError returns true if the request was rejected because of its rate rather than its content.
+//
+// S3 reports throttling with the SlowDown code or a 503, neither of which the SDK classifies as throttling.
+func isThrottlingError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if request.IsErrorThrottle(err) {
+		return true
+	}
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "SlowDown" {
+		return true
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() == http.StatusServiceUnavailable || reqErr.StatusCode() == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// isUnavailableError returns true if S3 could not answer at all, e.g. the endpoint of the region is down.
+func isUnavailableError(err error) bool {
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() >= http.StatusInternalServerError {
+		return true
+	}
+	if awsErr, ok := err.(awserr.Error); ok {
+		switch awsErr.Code() {
+		case request.ErrCodeRequestError, request.ErrCodeResponseTimeout, "InternalError", "ServiceUnavailable":
+			return true
+		}
+	}
+	return false
+}
+
+// processingRoleCredentials returns the credentials the logs are read with and sets the ProcessingRoleStatus.
+//
+// They are the ones of the log processing role, or of the last role of the role chain of the integration.
+func processingRoleCredentials(
+	logger *zap.Logger, input *models.CheckIntegrationInput, out *models.SourceIntegrationHealth) *credentials.Credentials {
+
+	if len(input.RoleChain) == 0 {
+		roleCreds, status := getCredentialsWithStatus(logger, aws.String(processingRoleARN(input)))
+		out.ProcessingRoleStatus = status
+		return roleCreds
+	}
+	roleCreds, status, chainStatuses := getChainedCredentialsWithStatus(logger, input.RoleChain)
+	out.ProcessingRoleStatus, out.RoleChainStatus = status, chainStatuses
+	return roleCreds
+}
+
+// processingRoleARN is the role the logs are read with, the log processing role or the last role of the role chain.
+func processingRoleARN(input *models.CheckIntegrationInput) string {
+	if len(input.RoleChain) == 0 {
+		return fmt.Sprintf(logProcessingRoleFormat, *input.AWSAccountID)
+	}
+	return *input.RoleChain[len(input.RoleChain)-1]
+}
+
+// getChainedCredentialsWithStatus assumes each role of a chain with the credentials of the previous role.
+//
+// Each role is verified before the next one is assumed. The chain stops at the first role which fails, the status
+// of the whole chain reports which one it was, and the roles after it are not checked.
+func getChainedCredentialsWithStatus(logger *zap.Logger,
+	roleARNs []*string) (*credentials.Credentials, models.SourceIntegrationItemStatus, map[string]models.SourceIntegrationItemStatus) {
+
+	statuses := make(map[string]models.SourceIntegrationItemStatus, len(roleARNs))
+	var roleCredentials *credentials.Credentials
+	var latency int64
+	for i, roleARN := range roleARNs {
+		var provider client.ConfigProvider = sess
+		if roleCredentials != nil {
+			provider = sess.Copy(&aws.Config{Credentials: roleCredentials})
+		}
+		var status models.SourceIntegrationItemStatus
+		roleCredentials, status = assumeRoleWithStatus(logger, provider, roleARN)
+		statuses[*roleARN] = status
+		latency += aws.Int64Value(status.LatencyMillis)
+		if !aws.BoolValue(status.Healthy) {
+			return roleCredentials, models.SourceIntegrationItemStatus{
+				Healthy: aws.Bool(false),
+				ErrorMessage: aws.String(fmt.Sprintf("role %d of %d of the chain (%s) failed: %s",
+					i+1, len(roleARNs), *roleARN, aws.StringValue(status.ErrorMessage))),
+				LatencyMillis: aws.Int64(latency),
+			}, statuses
+		}
+	}
+	return roleCredentials, models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(true),
+		LatencyMillis: aws.Int64(latency),
+	}, statuses
 }
 
 func getCredentialsWithStatus(
+	logger *zap.Logger,
 	roleARN *string,
 ) (*credentials.Credentials, models.SourceIntegrationItemStatus) {
 
-	zap.L().Debug("checking role", zap.String("roleArn", *roleARN))
+	return assumeRoleWithStatus(logger, sess, roleARN)
+}
+
+// assumeRoleWithStatus assumes a role with the credentials of the provider, and verifies them.
+func assumeRoleWithStatus(
+	logger *zap.Logger, provider client.ConfigProvider, roleARN *string) (*credentials.Credentials, models.SourceIntegrationItemStatus) {
+
+	logger.Debug("checking role", zap.String("roleArn", *roleARN))
 	// Setup new credentials with the role
 	roleCredentials := stscreds.NewCredentials(
-		sess,
+		provider,
 		*roleARN,
 	)
 
 	// Use the role to make sure it's good
-	stsClient := sts.New(sess, &aws.Config{Credentials: roleCredentials})
+	start := time.Now()
+	stsClient := stsClientFunc(roleCredentials)
 	_, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 	if err != nil {
 		return roleCredentials, models.SourceIntegrationItemStatus{
-			Healthy:      aws.Bool(false),
-			ErrorMessage: aws.String(err.Error()),
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
 		}
 	}
 
 	return roleCredentials, models.SourceIntegrationItemStatus{
-		Healthy: aws.Bool(true),
+		Healthy:       aws.Bool(true),
+		LatencyMillis: millisSince(start),
+	}
+}
+
+// integrationEvaluation is the outcome of the health check of an integration.
+type integrationEvaluation struct {
+	// Roles are security critical: a failure can never be tolerated
+	rolesHealthy bool
+	// Buckets, objects and keys which failed their check, e.g. "s3Bucket:my-bucket"
+	failedItems []*string
+	// Buckets, objects and keys whose check was inconclusive, they are neither passing nor failing
+	inconclusiveItems []*string
+	// Regions whose S3 was unavailable, e.g. "s3Region:eu-west-1". Their buckets are inconclusive
+	unavailableRegions []*string
+	// When the earliest credential checked expires, nil if none does
+	credentialExpiry *time.Time
+	// The result of CheckIntegration the evaluation was made from, nil if the checker doesn't set it
+	health *models.SourceIntegrationHealth
+}
+
+// addItems adds the failed and inconclusive items of a sub-check, the informational ones are ignored.
+func (eval *integrationEvaluation) addItems(prefix string, statuses map[string]models.SourceIntegrationItemStatus) {
+	for item, status := range statuses {
+		switch {
+		case aws.BoolValue(status.Informational):
+			continue
+		case aws.BoolValue(status.Inconclusive):
+			eval.inconclusiveItems = append(eval.inconclusiveItems, aws.String(prefix+item))
+		case !aws.BoolValue(status.Healthy):
+			eval.failedItems = append(eval.failedItems, aws.String(prefix+item))
+		}
+	}
+}
+
+func (eval *integrationEvaluation) passing() bool {
+	return eval.rolesHealthy && len(eval.failedItems) == 0
+}
+
+var evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+
+// evaluateIntegration returns true if the integration passes its health check, without tolerating any failure.
+//
+// The buckets of the regions whose S3 is unavailable couldn't be checked: the check fails with an UnavailableError
+// naming the regions, so that it is retried rather than the unchecked buckets being reported healthy.
+func evaluateIntegration(api API, integration *models.CheckIntegrationInput) (bool, *time.Time, error) {
+	eval, err := evaluateIntegrationHealth(api, integration)
+	if err != nil {
+		return false, nil, err
 	}
+	if eval.passing() && len(eval.unavailableRegions) > 0 {
+		return false, nil, &genericapi.UnavailableError{Message: fmt.Sprintf(
+			"the health check is inconclusive, S3 is unavailable in %s", strings.Join(aws.StringValueSlice(eval.unavailableRegions), ", "))}
+	}
+	return eval.passing(), eval.credentialExpiry, nil
 }
 
-func evaluateIntegration(api API, integration *models.CheckIntegrationInput) (bool, error) {
+// awsHealthChecker checks the roles and resources of the AWS integrations.
+type awsHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration and collects which of its items failed.
+func (awsHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
 	status, err := api.CheckIntegration(integration)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// One of these will be nil, one of these will not. We only care about the value of the not nil one.
@@ -163,13 +599,139 @@ func evaluateIntegration(api API, integration *models.CheckIntegrationInput) (bo
 	passing = passing && (!aws.BoolValue(integration.EnableRemediation) || aws.BoolValue(status.RemediationRoleStatus.Healthy))
 	passing = passing && (!aws.BoolValue(integration.EnableCWESetup) || aws.BoolValue(status.CWERoleStatus.Healthy))
 
-	// For these two, we are ok if none are set or all are passing
-	for _, bucket := range status.S3BucketsStatus {
-		passing = passing && aws.BoolValue(bucket.Healthy)
+	// For the buckets, objects and keys, we are ok if none are set or all are passing
+	eval := &integrationEvaluation{
+		rolesHealthy: passing, failedItems: make([]*string, 0), credentialExpiry: status.CredentialExpiry, health: status}
+	eval.addItems("s3Bucket:", status.S3BucketsStatus)
+	eval.addItems("s3ObjectRead:", status.S3ObjectReadStatus)
+	eval.addItems("s3ObjectDecrypt:", status.S3ObjectDecryptStatus)
+	eval.addItems("s3Archive:", status.S3ArchiveStatus)
+	for region, regionStatus := range status.S3RegionsStatus {
+		if aws.BoolValue(regionStatus.Inconclusive) {
+			eval.unavailableRegions = append(eval.unavailableRegions, aws.String("s3Region:"+region))
+		}
+	}
+	eval.addItems("kmsKey:", status.KMSKeysStatus)
+	eval.addItems("kmsGrant:", status.KMSGrantsStatus)
+	eval.addItems("s3BucketEncryption:", status.S3BucketEncryptionStatus)
+	if status.OrgTrailStatus.Healthy != nil {
+		eval.addItems("orgTrail:", map[string]models.SourceIntegrationItemStatus{
+			aws.StringValue(integration.ManagementAccountID): status.OrgTrailStatus,
+		})
+	}
+	eval.addItems("orgTrailBucket:", status.OrgTrailBucketsStatus)
+	if status.KinesisStreamStatus.Healthy != nil {
+		eval.addItems("kinesisStream:", map[string]models.SourceIntegrationItemStatus{
+			aws.StringValue(integration.StreamARN): status.KinesisStreamStatus,
+		})
 	}
-	for _, key := range status.KMSKeysStatus {
-		passing = passing && aws.BoolValue(key.Healthy)
+	if status.KinesisConsumerStatus.Healthy != nil {
+		eval.addItems("kinesisConsumer:", map[string]models.SourceIntegrationItemStatus{
+			aws.StringValue(integration.KinesisConsumerARN): status.KinesisConsumerStatus,
+		})
 	}
+	if status.DeadLetterQueueStatus.Healthy != nil {
+		eval.addItems("deadLetterQueue:", map[string]models.SourceIntegrationItemStatus{
+			aws.StringValue(integration.DeadLetterQueueArn): status.DeadLetterQueueStatus,
+		})
+	}
+	// Sessions longer than the role allows can't be issued, the duration is rejected rather than tolerated
+	if sessionStatus := status.SessionDurationStatus; sessionStatus.Healthy != nil {
+		if !*sessionStatus.Healthy && !aws.BoolValue(sessionStatus.Inconclusive) {
+			return nil, &genericapi.InvalidInputError{Message: aws.StringValue(sessionStatus.ErrorMessage)}
+		}
+		eval.addItems("sessionDuration:", map[string]models.SourceIntegrationItemStatus{
+			processingRoleARN(integration): sessionStatus,
+		})
+	}
+	sort.Slice(eval.failedItems, func(i, j int) bool { return *eval.failedItems[i] < *eval.failedItems[j] })
+	sort.Slice(eval.inconclusiveItems, func(i, j int) bool { return *eval.inconclusiveItems[i] < *eval.inconclusiveItems[j] })
+	sort.Slice(eval.unavailableRegions, func(i, j int) bool { return *eval.unavailableRegions[i] < *eval.unavailableRegions[j] })
+
+	return eval, nil
+}
 
-	return passing, nil
+// checkIntegrationHealth returns an error unless the integration passes its health check.
+//
+// When allowPartial is set, failed buckets and keys are tolerated and the integration is reported as degraded.
+// So is an integration with a region whose S3 is unavailable, the region is returned with the items which failed.
+// Otherwise such a region fails the check, see evaluateIntegration.
+// The health status to store is returned along with the items which failed and when the checked credentials expire.
+func checkIntegrationHealth(
+	api API, integration *models.CheckIntegrationInput, allowPartial bool) (*string, []*string, *time.Time, error) {
+
+	failedErr := &genericapi.InvalidInputError{
+		Message: fmt.Sprintf("integration %s did not pass health check",
+			aws.StringValue(accountOf(integration.AWSAccountID, integration.AzureSubscriptionID, integration.GCPProjectID))),
+	}
+
+	if !allowPartial {
+		passing, expiry, err := evaluateIntegrationFunc(api, integration)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !passing {
+			return nil, nil, nil, failedErr
+		}
+		return aws.String(models.HealthStatusHealthy), make([]*string, 0), expiry, nil
+	}
+
+	eval, err := evaluateIntegrationHealthFunc(api, integration)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !eval.rolesHealthy {
+		return nil, nil, nil, failedErr
+	}
+	if len(eval.failedItems) > 0 || len(eval.unavailableRegions) > 0 {
+		return aws.String(models.HealthStatusDegraded), append(eval.failedItems, eval.unavailableRegions...), eval.credentialExpiry, nil
+	}
+	return aws.String(models.HealthStatusHealthy), eval.failedItems, eval.credentialExpiry, nil
+}
+
+// evaluatedHealth is the health status of an evaluation which doesn't fail when the integration doesn't pass.
+//
+// Integrations whose roles fail, or whose buckets, keys or regions fail unless allowPartial is set, are unhealthy.
+func evaluatedHealth(eval *integrationEvaluation, allowPartial bool) (*string, []*string) {
+	switch {
+	case !eval.rolesHealthy:
+		return aws.String(models.HealthStatusUnhealthy), append([]*string{aws.String(failedRolesCheck)}, eval.failedItems...)
+	case !allowPartial && (len(eval.failedItems) > 0 || len(eval.unavailableRegions) > 0):
+		return aws.String(models.HealthStatusUnhealthy), append(eval.failedItems, eval.unavailableRegions...)
+	case !allowPartial:
+		return aws.String(models.HealthStatusHealthy), make([]*string, 0)
+	case len(eval.failedItems) > 0 || len(eval.unavailableRegions) > 0:
+		return aws.String(models.HealthStatusDegraded), append(eval.failedItems, eval.unavailableRegions...)
+	}
+	return aws.String(models.HealthStatusHealthy), eval.failedItems
+}
+
+// healthCheckResult is the result of a save whose health check gave the health status, nil if there was none.
+func healthCheckResult(healthStatus *string) *string {
+	switch aws.StringValue(healthStatus) {
+	case models.HealthStatusHealthy:
+		return aws.String(models.HealthCheckSuccess)
+	case models.HealthStatusDegraded:
+		return aws.String(models.HealthCheckPartialSuccess)
+	}
+	return nil
+}
+
+// dryRunHealth runs the health check of a dry run, the result of each check is the one the evaluation was made from.
+//
+// CheckIntegration is only run again for a registered checker which doesn't set it.
+func dryRunHealth(api API, integration *models.CheckIntegrationInput, allowPartial bool) (*models.DryRunReport, error) {
+	eval, err := evaluateIntegrationHealthFunc(api, integration)
+	if err != nil {
+		return nil, err
+	}
+	health := eval.health
+	if health == nil {
+		if health, err = api.CheckIntegration(integration); err != nil {
+			return nil, err
+		}
+	}
+	report := &models.DryRunReport{Health: health}
+	report.HealthStatus, report.FailedHealthChecks = evaluatedHealth(eval, allowPartial)
+	return report, nil
 }
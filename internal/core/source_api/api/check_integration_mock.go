@@ -0,0 +1,137 @@
+// +build healthcheckmock
+
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// The mock health check lets integrations be created without real AWS accounts, e.g. when testing deployments in CI.
+//
+// It is compiled only with "go build -tags healthcheckmock" and enabled by the HEALTH_CHECK_MOCK environment variable:
+//   HEALTH_CHECK_MOCK=pass                        every check passes
+//   HEALTH_CHECK_MOCK=processingRole,s3Bucket:foo the listed checks fail, all others pass
+//
+// Check names are auditRole, cweRole, remediationRole, processingRole, s3Bucket:<bucket>, s3ObjectRead:<bucket>,
+// s3ObjectDecrypt:<bucket>, kmsKey:<key>, organizationRole, organization, kinesisStream:<arn>,
+// kinesisConsumer:<arn>, azureServicePrincipal, azureSubscription, gcpServiceAccount, gcpLogSource, sqsQueue:<arn>,
+// httpIngestKey:<id> and oktaSystemLog.
+
+import (
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const mockHealthCheckEnv = "HEALTH_CHECK_MOCK"
+
+func init() {
+	if mode := os.Getenv(mockHealthCheckEnv); mode != "" {
+		healthCheckOverride = newMockHealthCheck(mode)
+	}
+}
+
+// newMockHealthCheck returns a health check failing only the checks listed in the mode.
+func newMockHealthCheck(mode string) func(*models.CheckIntegrationInput) *models.SourceIntegrationHealth {
+	failures := make(map[string]struct{})
+	if mode != "pass" {
+		for _, name := range strings.Split(mode, ",") {
+			failures[strings.TrimSpace(name)] = struct{}{}
+		}
+	}
+
+	status := func(name string) models.SourceIntegrationItemStatus {
+		if _, failed := failures[name]; failed {
+			return models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String("mock health check failure"),
+				LatencyMillis: aws.Int64(0),
+			}
+		}
+		return models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: aws.Int64(0)}
+	}
+
+	return func(input *models.CheckIntegrationInput) *models.SourceIntegrationHealth {
+		out := &models.SourceIntegrationHealth{
+			AWSAccountID:       input.AWSAccountID,
+			IntegrationType:    input.IntegrationType,
+			TotalLatencyMillis: aws.Int64(0),
+		}
+
+		switch *input.IntegrationType {
+		case models.IntegrationTypeAWSScan:
+			out.AuditRoleStatus = status("auditRole")
+			if aws.BoolValue(input.EnableCWESetup) {
+				out.CWERoleStatus = status("cweRole")
+			}
+			if aws.BoolValue(input.EnableRemediation) {
+				out.RemediationRoleStatus = status("remediationRole")
+			}
+		case models.IntegrationTypeAWS3:
+			out.ProcessingRoleStatus = status("processingRole")
+			if len(input.S3Buckets) > 0 {
+				out.S3BucketsStatus = make(map[string]models.SourceIntegrationItemStatus, len(input.S3Buckets))
+				out.S3ObjectReadStatus = make(map[string]models.SourceIntegrationItemStatus, len(input.S3Buckets))
+				out.S3ObjectDecryptStatus = make(map[string]models.SourceIntegrationItemStatus, len(input.S3Buckets))
+				for _, bucket := range input.S3Buckets {
+					out.S3BucketsStatus[*bucket] = status("s3Bucket:" + *bucket)
+					out.S3ObjectReadStatus[*bucket] = status("s3ObjectRead:" + *bucket)
+					out.S3ObjectDecryptStatus[*bucket] = status("s3ObjectDecrypt:" + *bucket)
+				}
+			}
+			if len(input.KmsKeys) > 0 {
+				out.KMSKeysStatus = make(map[string]models.SourceIntegrationItemStatus, len(input.KmsKeys))
+				for _, key := range input.KmsKeys {
+					out.KMSKeysStatus[*key] = status("kmsKey:" + *key)
+				}
+			}
+		case models.IntegrationTypeAWSOrganization:
+			out.OrganizationRoleStatus = status("organizationRole")
+			out.OrganizationStatus = status("organization")
+		case models.IntegrationTypeAWSKinesis:
+			out.ProcessingRoleStatus = status("processingRole")
+			if input.StreamARN != nil {
+				out.KinesisStreamStatus = status("kinesisStream:" + *input.StreamARN)
+			}
+			if input.KinesisConsumerARN != nil {
+				out.KinesisConsumerStatus = status("kinesisConsumer:" + *input.KinesisConsumerARN)
+			}
+		case models.IntegrationTypeAzureScan:
+			out.AzureServicePrincipalStatus = status("azureServicePrincipal")
+			out.AzureSubscriptionStatus = status("azureSubscription")
+		case models.IntegrationTypeGCPLogSource:
+			out.GCPServiceAccountStatus = status("gcpServiceAccount")
+			out.GCPLogSourceStatus = status("gcpLogSource")
+		case models.IntegrationTypeSQSQueue:
+			if input.SQSQueueArn != nil {
+				out.SQSQueueStatus = status("sqsQueue:" + *input.SQSQueueArn)
+			}
+		case models.IntegrationTypeHTTPIngest:
+			if input.HTTPIngestAPIKeyID != nil {
+				out.HTTPIngestKeyStatus = status("httpIngestKey:" + *input.HTTPIngestAPIKeyID)
+			}
+		case models.IntegrationTypeOkta:
+			out.OktaSystemLogStatus = status("oktaSystemLog")
+		case models.IntegrationTypeSyslog:
+			// Syslog integrations have nothing to check
+		}
+		return out
+	}
+}
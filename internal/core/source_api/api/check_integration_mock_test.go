@@ -0,0 +1,93 @@
+// +build healthcheckmock
+
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+func TestMockHealthCheck(t *testing.T) {
+	healthCheckOverride = newMockHealthCheck("s3Bucket:bad")
+	defer func() { healthCheckOverride = nil }()
+
+	input := &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"good", "bad"}),
+	}
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.ProcessingRoleStatus.Healthy)
+	assert.True(t, *result.S3BucketsStatus["good"].Healthy)
+	assert.False(t, *result.S3BucketsStatus["bad"].Healthy)
+
+	passing, _, err := evaluateIntegration(apiTest, input)
+	require.NoError(t, err)
+	assert.False(t, passing)
+
+	healthCheckOverride = newMockHealthCheck("pass")
+	passing, _, err = evaluateIntegration(apiTest, input)
+	require.NoError(t, err)
+	assert.True(t, passing)
+}
+
+func TestMockHealthCheckIntegrationTypes(t *testing.T) {
+	defer func() { healthCheckOverride = nil }()
+	queueARN := "arn:aws:sqs:us-west-2:123456789012:logs"
+	inputs := map[string]*models.CheckIntegrationInput{
+		"auditRole":             {IntegrationType: aws.String(models.IntegrationTypeAWSScan)},
+		"organization":          {IntegrationType: aws.String(models.IntegrationTypeAWSOrganization)},
+		"processingRole":        {IntegrationType: aws.String(models.IntegrationTypeAWSKinesis)},
+		"azureSubscription":     {IntegrationType: aws.String(models.IntegrationTypeAzureScan)},
+		"gcpLogSource":          {IntegrationType: aws.String(models.IntegrationTypeGCPLogSource)},
+		"sqsQueue:" + queueARN:  {IntegrationType: aws.String(models.IntegrationTypeSQSQueue), SQSQueueArn: aws.String(queueARN)},
+		"httpIngestKey:key-1":   {IntegrationType: aws.String(models.IntegrationTypeHTTPIngest), HTTPIngestAPIKeyID: aws.String("key-1")},
+		"oktaSystemLog":         {IntegrationType: aws.String(models.IntegrationTypeOkta)},
+		"gcpServiceAccount":     {IntegrationType: aws.String(models.IntegrationTypeGCPLogSource)},
+		"azureServicePrincipal": {IntegrationType: aws.String(models.IntegrationTypeAzureScan)},
+	}
+	for check, input := range inputs {
+		input.AWSAccountID = aws.String(testAccountID)
+
+		// Each integration type passes, unless one of its checks is listed
+		healthCheckOverride = newMockHealthCheck("pass")
+		passing, _, err := evaluateIntegration(apiTest, input)
+		require.NoError(t, err)
+		assert.True(t, passing, *input.IntegrationType)
+
+		healthCheckOverride = newMockHealthCheck(check)
+		passing, _, err = evaluateIntegration(apiTest, input)
+		require.NoError(t, err)
+		assert.False(t, passing, check)
+	}
+
+	// Syslog integrations have nothing to fail
+	healthCheckOverride = newMockHealthCheck("processingRole")
+	passing, _, err := evaluateIntegration(apiTest, &models.CheckIntegrationInput{IntegrationType: aws.String(models.IntegrationTypeSyslog)})
+	require.NoError(t, err)
+	assert.True(t, passing)
+}
@@ -0,0 +1,496 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockSTSClient struct {
+	stsiface.STSAPI
+	mock.Mock
+}
+
+func (client *mockSTSClient) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*sts.GetCallerIdentityOutput), args.Error(1)
+}
+
+type mockS3Client struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (client *mockS3Client) GetBucketLocation(input *s3.GetBucketLocationInput) (*s3.GetBucketLocationOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.GetBucketLocationOutput), args.Error(1)
+}
+
+// GetBucketEncryption answers that the bucket has no default encryption, unless the test mocks it.
+func (client *mockS3Client) GetBucketEncryption(input *s3.GetBucketEncryptionInput) (*s3.GetBucketEncryptionOutput, error) {
+	for _, call := range client.ExpectedCalls {
+		if call.Method == "GetBucketEncryption" {
+			args := client.Called(input)
+			return args.Get(0).(*s3.GetBucketEncryptionOutput), args.Error(1)
+		}
+	}
+	return nil, awserr.New(noBucketEncryptionCode, "The server side encryption configuration was not found", nil)
+}
+
+type mockKMSClient struct {
+	kmsiface.KMSAPI
+	mock.Mock
+}
+
+func (client *mockKMSClient) DescribeKey(input *kms.DescribeKeyInput) (*kms.DescribeKeyOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*kms.DescribeKeyOutput), args.Error(1)
+}
+
+// mockHealthCheckClients replaces the clients used by the health check with the given mocks.
+func mockHealthCheckClients(stsClient stsiface.STSAPI, s3Client s3iface.S3API, kmsClient kmsiface.KMSAPI) {
+	stsClientFunc = func(*credentials.Credentials) stsiface.STSAPI { return stsClient }
+	s3ClientFunc = func(*credentials.Credentials) s3iface.S3API { return s3Client }
+	regionalS3ClientFunc = func(*credentials.Credentials, string) s3iface.S3API { return s3Client }
+	kmsClientFunc = func(*credentials.Credentials) kmsiface.KMSAPI { return kmsClient }
+	// The roles can't read their own policies unless a test mocks them, the advisory is then inconclusive
+	deniedIAM := &mockIAMClient{}
+	deniedIAM.On("ListRolePolicies", mock.Anything).
+		Return(&iam.ListRolePoliciesOutput{}, awserr.New("AccessDenied", "not authorized to perform: iam:ListRolePolicies", nil))
+	iamClientFunc = func(*credentials.Credentials) iamiface.IAMAPI { return deniedIAM }
+}
+
+func TestCheckIntegrationLatency(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("bucket-1")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("bucket-2")}).
+		Return(&s3.GetBucketLocationOutput{}, errors.New("access denied"))
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	mockKMS := &mockKMSClient{}
+	mockKMS.On("DescribeKey", mock.Anything).
+		Return(&kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Enabled: aws.Bool(true)}}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, mockKMS)
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bucket-1", "bucket-2"}),
+		KmsKeys:         aws.StringSlice([]string{"key"}),
+	})
+	require.NoError(t, err)
+
+	var total int64
+	statuses := []models.SourceIntegrationItemStatus{
+		result.ProcessingRoleStatus,
+		result.S3BucketsStatus["bucket-1"],
+		result.S3BucketsStatus["bucket-2"],
+		result.S3ObjectReadStatus["bucket-1"],
+		result.KMSKeysStatus["key"],
+		result.RolePolicyStatus,
+	}
+	for _, status := range statuses {
+		require.NotNil(t, status.LatencyMillis)
+		assert.GreaterOrEqual(t, *status.LatencyMillis, int64(0))
+		total += *status.LatencyMillis
+	}
+	assert.False(t, *result.S3BucketsStatus["bucket-2"].Healthy)
+	require.NotNil(t, result.TotalLatencyMillis)
+	assert.Equal(t, total, *result.TotalLatencyMillis)
+
+	// Checks which were not run have no latency
+	assert.Nil(t, result.AuditRoleStatus.LatencyMillis)
+	mockSTS.AssertExpectations(t)
+	mockS3.AssertExpectations(t)
+	mockKMS.AssertExpectations(t)
+}
+
+func TestCheckIntegrationBucketsInTwoRegions(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+
+	// Buckets in us-east-1 have no location constraint, the legacy "EU" constraint is eu-west-1
+	regionalClients := map[string]*mockS3Client{"us-east-1": {}, "eu-west-1": {}}
+	regionalClients["us-east-1"].On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("logs-us")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	regionalClients["eu-west-1"].On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("logs-eu")}).
+		Return(&s3.GetBucketLocationOutput{LocationConstraint: aws.String("EU")}, nil)
+	for _, client := range regionalClients {
+		client.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	}
+	regionalS3ClientFunc = func(_ *credentials.Credentials, region string) s3iface.S3API {
+		return regionalClients[region]
+	}
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs-us", "logs-eu/cloudtrail/*"}),
+		S3BucketRegions: map[string]*string{"logs-us": aws.String("us-east-1"), "logs-eu": aws.String("eu-west-1")},
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.S3BucketsStatus["logs-us"].Healthy)
+	assert.True(t, *result.S3BucketsStatus["logs-eu/cloudtrail/*"].Healthy)
+	regionalClients["us-east-1"].AssertExpectations(t)
+	regionalClients["eu-west-1"].AssertExpectations(t)
+}
+
+func TestCheckIntegrationBucketInWrongRegion(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("logs")}).
+		Return(&s3.GetBucketLocationOutput{LocationConstraint: aws.String("ap-south-1")}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs"}),
+		S3BucketRegions: map[string]*string{"logs": aws.String("eu-west-1")},
+	})
+	require.NoError(t, err)
+	status := result.S3BucketsStatus["logs"]
+	assert.False(t, *status.Healthy)
+	assert.Equal(t, "bucket is located in ap-south-1, not eu-west-1", *status.ErrorMessage)
+}
+
+func TestCheckIntegrationRegionUnavailable(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+
+	// The second bucket of eu-west-1 is not checked once S3 failed in the region
+	regionalClients := map[string]*mockS3Client{"us-east-1": {}, "eu-west-1": {}}
+	regionalClients["us-east-1"].On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("logs-us")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	regionalClients["us-east-1"].On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	regionalClients["eu-west-1"].On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("logs-eu-1")}).
+		Return(&s3.GetBucketLocationOutput{}, awserr.NewRequestFailure(
+			awserr.New("InternalError", "we encountered an internal error", nil), 500, "request-id"))
+	regionalS3ClientFunc = func(_ *credentials.Credentials, region string) s3iface.S3API {
+		return regionalClients[region]
+	}
+	evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+	input := &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs-eu-1", "logs-us", "logs-eu-2"}),
+		S3BucketRegions: map[string]*string{
+			"logs-us":   aws.String("us-east-1"),
+			"logs-eu-1": aws.String("eu-west-1"),
+			"logs-eu-2": aws.String("eu-west-1"),
+		},
+	}
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.S3BucketsStatus["logs-us"].Healthy)
+	for _, bucket := range []string{"logs-eu-1", "logs-eu-2"} {
+		assert.False(t, *result.S3BucketsStatus[bucket].Healthy)
+		assert.True(t, *result.S3BucketsStatus[bucket].Inconclusive)
+	}
+	assert.True(t, *result.S3RegionsStatus["us-east-1"].Healthy)
+	assert.True(t, *result.S3RegionsStatus["eu-west-1"].Inconclusive)
+	assert.Contains(t, *result.S3RegionsStatus["eu-west-1"].ErrorMessage, "InternalError")
+
+	// The integration is degraded rather than unhealthy
+	status, failed, _, err := checkIntegrationHealth(apiTest, input, true)
+	require.NoError(t, err)
+	assert.Equal(t, models.HealthStatusDegraded, *status)
+	assert.Equal(t, aws.StringSlice([]string{"s3Region:eu-west-1"}), failed)
+
+	// Without partial health, the buckets of the region can't be reported healthy: the check fails until it's retried
+	previousEvaluate := evaluateIntegrationFunc
+	defer func() { evaluateIntegrationFunc = previousEvaluate }()
+	evaluateIntegrationFunc = evaluateIntegration
+	status, _, _, err = checkIntegrationHealth(apiTest, input, false)
+	assert.Nil(t, status)
+	assert.Equal(t, &genericapi.UnavailableError{
+		Message: "the health check is inconclusive, S3 is unavailable in s3Region:eu-west-1"}, err)
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	status, failed = evaluatedHealth(eval, false)
+	assert.Equal(t, models.HealthStatusUnhealthy, *status)
+	assert.Equal(t, aws.StringSlice([]string{"s3Region:eu-west-1"}), failed)
+	regionalClients["us-east-1"].AssertExpectations(t)
+	regionalClients["eu-west-1"].AssertExpectations(t)
+}
+
+func TestCheckIntegrationThrottledBucketInconclusive(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("busy")}).
+		Return(&s3.GetBucketLocationOutput{}, awserr.New("SlowDown", "please reduce your request rate", nil))
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("bad")}).
+		Return(&s3.GetBucketLocationOutput{}, awserr.New("AccessDenied", "access denied", nil))
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	input := &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"busy", "bad"}),
+	}
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	busy := result.S3BucketsStatus["busy"]
+	assert.False(t, aws.BoolValue(busy.Healthy))
+	assert.True(t, aws.BoolValue(busy.Inconclusive))
+	assert.Contains(t, aws.StringValue(busy.ErrorMessage), "SlowDown")
+	assert.Nil(t, result.S3BucketsStatus["bad"].Inconclusive)
+
+	// Only the hard failure counts against the integration
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"s3Bucket:bad"}), eval.failedItems)
+	assert.Equal(t, aws.StringSlice([]string{"s3Bucket:busy"}), eval.inconclusiveItems)
+	mockS3.AssertExpectations(t)
+}
+
+func TestCheckIntegrationDisabledCheckInformational(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("bad")}).
+		Return(&s3.GetBucketLocationOutput{}, awserr.New("AccessDenied", "access denied", nil))
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	input := &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bad"}),
+		DisabledChecks:  aws.StringSlice([]string{models.HealthCheckS3Buckets}),
+	}
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	// The check still runs and reports its result
+	bad := result.S3BucketsStatus["bad"]
+	assert.False(t, aws.BoolValue(bad.Healthy))
+	assert.True(t, aws.BoolValue(bad.Informational))
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.True(t, eval.passing())
+	assert.Empty(t, eval.failedItems)
+	mockS3.AssertExpectations(t)
+}
+
+func TestDisabledChecksValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(checks ...string) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{
+			IntegrationID: aws.String(testIntegrationID), DisabledChecks: aws.StringSlice(checks)}
+	}
+
+	assert.NoError(t, validator.Struct(settings(models.HealthCheckS3Buckets, models.HealthCheckKMSKeys, models.HealthCheckS3Objects)))
+	// The checks of the roles are security critical
+	for _, invalid := range []string{"processingRole", "auditRole", "cweRole", "remediationRole", "bucketNotifications", ""} {
+		assert.Error(t, validator.Struct(settings(models.HealthCheckS3Buckets, invalid)), invalid)
+	}
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	assert.False(t, isThrottlingError(nil))
+	assert.False(t, isThrottlingError(errors.New("SlowDown")))
+	assert.False(t, isThrottlingError(awserr.New("AccessDenied", "access denied", nil)))
+	assert.True(t, isThrottlingError(awserr.New("SlowDown", "slow down", nil)))
+	assert.True(t, isThrottlingError(awserr.New("Throttling", "rate exceeded", nil)))
+	assert.True(t, isThrottlingError(awserr.NewRequestFailure(awserr.New("ServiceUnavailable", "", nil), 503, "id")))
+	assert.False(t, isThrottlingError(awserr.NewRequestFailure(awserr.New("NotFound", "", nil), 404, "id")))
+}
+
+func TestUpdateIntegrationSettingsPartialHealth(t *testing.T) {
+	mockBucketOwnership(t)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"awsAccountId":    {S: aws.String(testAccountID)},
+		"integrationId":   {S: aws.String(testIntegrationID)},
+		"integrationType": {S: aws.String(models.IntegrationTypeAWS3)},
+	}}, nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"integrationId": {S: aws.String(testIntegrationID)}},
+	}, nil).Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("good")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("bad")}).
+		Return(&s3.GetBucketLocationOutput{}, errors.New("access denied"))
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		S3Buckets:          aws.StringSlice([]string{"good", "bad"}),
+		AllowPartialHealth: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.HealthCheckPartialSuccess, aws.StringValue(result.HealthCheckResult))
+
+	// The integration is saved, but flagged as degraded with the failed bucket recorded
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{S: aws.String(models.HealthStatusDegraded)})
+	assert.Contains(t, values, &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{S: aws.String("s3Bucket:bad")}}})
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsPartialHealthRoleFailure(t *testing.T) {
+	mockBucketOwnership(t)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"awsAccountId":    {S: aws.String(testAccountID)},
+		"integrationId":   {S: aws.String(testIntegrationID)},
+		"integrationType": {S: aws.String(models.IntegrationTypeAWS3)},
+	}}, nil)
+
+	// Even with partial health allowed, the role must always be assumable
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, errors.New("AccessDenied"))
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+	evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		S3Buckets:          aws.StringSlice([]string{"good"}),
+		AllowPartialHealth: aws.Bool(true),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestCheckIntegrationRoleChain(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil).Twice()
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("bucket")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	// Each role is verified with its own credentials
+	var verified []*credentials.Credentials
+	stsClientFunc = func(roleCredentials *credentials.Credentials) stsiface.STSAPI {
+		verified = append(verified, roleCredentials)
+		return mockSTS
+	}
+
+	chain := aws.StringSlice([]string{
+		"arn:aws:iam::123456789012:role/JumpRole",
+		"arn:aws:iam::123456789012:role/LogAccessRole",
+	})
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bucket"}),
+		RoleChain:       chain,
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.ProcessingRoleStatus.Healthy)
+	assert.Len(t, result.RoleChainStatus, 2)
+	assert.True(t, *result.RoleChainStatus[*chain[0]].Healthy)
+	assert.True(t, *result.RoleChainStatus[*chain[1]].Healthy)
+	require.Len(t, verified, 2)
+	assert.NotSame(t, verified[0], verified[1])
+	// The buckets are checked with the credentials of the last role
+	assert.True(t, *result.S3BucketsStatus["bucket"].Healthy)
+	mockSTS.AssertExpectations(t)
+	mockS3.AssertExpectations(t)
+}
+
+func TestCheckIntegrationRoleChainBrokenHop(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil).Once()
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, errors.New("access denied")).Once()
+	// No bucket is checked once the chain is broken
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+
+	chain := aws.StringSlice([]string{
+		"arn:aws:iam::123456789012:role/JumpRole",
+		"arn:aws:iam::123456789012:role/MiddleRole",
+		"arn:aws:iam::123456789012:role/LogAccessRole",
+	})
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bucket"}),
+		RoleChain:       chain,
+	})
+	require.NoError(t, err)
+	assert.False(t, *result.ProcessingRoleStatus.Healthy)
+	assert.Contains(t, *result.ProcessingRoleStatus.ErrorMessage, "role 2 of 3")
+	assert.Contains(t, *result.ProcessingRoleStatus.ErrorMessage, "MiddleRole")
+	assert.True(t, *result.RoleChainStatus[*chain[0]].Healthy)
+	assert.False(t, *result.RoleChainStatus[*chain[1]].Healthy)
+	// The last role is never assumed
+	assert.NotContains(t, result.RoleChainStatus, *chain[2])
+	assert.Empty(t, result.S3BucketsStatus)
+	mockSTS.AssertExpectations(t)
+}
+
+func TestRoleChainValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := validSettings(models.IntegrationTypeAWS3)
+	settings.RoleChain = aws.StringSlice([]string{"arn:aws:iam::123456789012:role/JumpRole", "arn:aws:iam::123456789012:role/Logs"})
+	assert.NoError(t, validator.Struct(settings))
+
+	settings.RoleChain = []*string{}
+	assert.Error(t, validator.Struct(settings))
+	settings.RoleChain = aws.StringSlice([]string{"arn:aws:iam::123456789012:role/JumpRole", "not-an-arn"})
+	assert.Error(t, validator.Struct(settings))
+	settings.RoleChain = aws.StringSlice([]string{"arn:aws:s3:::bucket"})
+	assert.Error(t, validator.Struct(settings))
+}
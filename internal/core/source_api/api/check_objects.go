@@ -0,0 +1,171 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The objects listed to find one to sample, only the first page of each bucket is considered
+const objectSampleListSize = 100
+
+// checkObjects reads a sampled object of each bucket which passed its check.
+//
+// Reaching a bucket doesn't mean its logs can be processed: the role may not be allowed to read the objects,
+// or KMS may refuse to decrypt them. A read which S3 denies fails the read check, while a read which S3
+// accepts but KMS can't decrypt passes it and fails the decrypt check, the fix is not the same.
+// Buckets without any object matching their pattern, or which the role can't list, are inconclusive.
+func checkObjects(
+	roleCredentials *credentials.Credentials, buckets []*string, regions map[string]*string,
+	bucketStatuses map[string]models.SourceIntegrationItemStatus) (reads, decryptions map[string]models.SourceIntegrationItemStatus) {
+
+	clientForBucket := bucketClients(roleCredentials, regions)
+	reads = make(map[string]models.SourceIntegrationItemStatus, len(buckets))
+	decryptions = make(map[string]models.SourceIntegrationItemStatus, len(buckets))
+	for _, bucket := range buckets {
+		if !aws.BoolValue(bucketStatuses[*bucket].Healthy) {
+			continue
+		}
+		name, pattern := parseBucketEntry(*bucket)
+		s3Client, _ := clientForBucket(name)
+
+		start := time.Now()
+		key, err := sampleObjectKey(s3Client, name, pattern)
+		if isAccessDenied(err) || (err == nil && key == nil) {
+			// Roles onboarded before the template granted s3:ListBucket can't list, that's not a read failure
+			message := "no object to sample"
+			if err != nil {
+				message = "not allowed to list objects to sample: " + err.Error()
+			}
+			reads[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(message),
+				LatencyMillis: millisSince(start),
+			}
+			continue
+		}
+		if err == nil {
+			err = readObject(s3Client, name, *key)
+		}
+
+		// The decryption is part of the read, its latency is only reported once
+		switch {
+		case isThrottlingError(err):
+			zap.L().Warn("object check throttled", zap.String("bucket", *bucket), zap.Error(err))
+			reads[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		case isDecryptionError(err):
+			reads[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(true),
+				LatencyMillis: millisSince(start),
+			}
+			decryptions[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:      aws.Bool(false),
+				ErrorMessage: aws.String(fmt.Sprintf("failed to decrypt %s: %s", *key, err)),
+			}
+		case err != nil:
+			reads[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		default:
+			reads[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(true),
+				LatencyMillis: millisSince(start),
+			}
+			decryptions[*bucket] = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true)}
+		}
+	}
+
+	return reads, decryptions
+}
+
+// sampleObjectKey returns the key of an object matching the pattern, or nil if there is none.
+func sampleObjectKey(s3Client s3iface.S3API, bucket, pattern string) (*string, error) {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bucket), MaxKeys: aws.Int64(objectSampleListSize)}
+	if prefix := patternPrefix(pattern); prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	page, err := s3Client.ListObjectsV2(input)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := patternRegexp(pattern)
+	for _, object := range page.Contents {
+		// Empty objects, e.g. folder markers, have no content to decrypt
+		if aws.Int64Value(object.Size) > 0 && matcher.MatchString(aws.StringValue(object.Key)) {
+			return object.Key, nil
+		}
+	}
+	return nil, nil
+}
+
+// readObject reads the first byte of an object, which S3 has to decrypt to return it.
+func readObject(s3Client s3iface.S3API, bucket, key string) error {
+	output, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  aws.String("bytes=0-0"),
+	})
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+	_, err = io.Copy(ioutil.Discard, output.Body)
+	return err
+}
+
+// isAccessDenied returns true if the request was denied by the permissions of the role.
+func isAccessDenied(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == "AccessDenied"
+}
+
+// isDecryptionError returns true if S3 accepted the read of an object but KMS failed to decrypt it.
+//
+// The KMS failures have their own codes, e.g. KMS.DisabledException, except for a missing kms:Decrypt
+// permission which is an AccessDenied naming the KMS action.
+func isDecryptionError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	return strings.HasPrefix(awsErr.Code(), "KMS.") ||
+		(awsErr.Code() == "AccessDenied" && strings.Contains(awsErr.Message(), "kms:"))
+}
@@ -0,0 +1,180 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// mockSampledObject lets the log processing role reach the bucket, and lists a single object in it.
+func mockSampledObject(bucket, key string) *mockS3Client {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String(bucket)}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{{Key: aws.String(key), Size: aws.Int64(1024)}},
+	}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	return mockS3
+}
+
+func sampleReadInput(bucket string) *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{bucket}),
+	}
+}
+
+func TestCheckIntegrationSampleReadSuccess(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("logs")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	// The listing starts at the literal prefix of the pattern, and skips the folder marker and the other objects
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{
+		Bucket: aws.String("logs"), Prefix: aws.String("cloudtrail/"), MaxKeys: aws.Int64(objectSampleListSize),
+	}).Return(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+		{Key: aws.String("cloudtrail/"), Size: aws.Int64(0)},
+		{Key: aws.String("cloudtrail/digest.json"), Size: aws.Int64(100)},
+		{Key: aws.String("cloudtrail/2020/01/events.json.gz"), Size: aws.Int64(2048)},
+	}}, nil)
+	mockS3.On("GetObject", &s3.GetObjectInput{
+		Bucket: aws.String("logs"), Key: aws.String("cloudtrail/2020/01/events.json.gz"), Range: aws.String("bytes=0-0"),
+	}).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(strings.NewReader("x"))}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	input := sampleReadInput("logs/cloudtrail/*.gz")
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.S3ObjectReadStatus["logs/cloudtrail/*.gz"].Healthy)
+	assert.True(t, *result.S3ObjectDecryptStatus["logs/cloudtrail/*.gz"].Healthy)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.True(t, eval.passing())
+	mockS3.AssertExpectations(t)
+}
+
+func TestCheckIntegrationSampleReadPermissionFailure(t *testing.T) {
+	mockS3 := mockSampledObject("logs", "events.json.gz")
+	mockS3.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{}, awserr.New("AccessDenied", "Access Denied", nil))
+	input := sampleReadInput("logs")
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	read := result.S3ObjectReadStatus["logs"]
+	assert.False(t, *read.Healthy)
+	assert.Contains(t, *read.ErrorMessage, "AccessDenied")
+	// The object was never read, so its decryption is not reported
+	assert.NotContains(t, result.S3ObjectDecryptStatus, "logs")
+	// The bucket itself is reachable
+	assert.True(t, *result.S3BucketsStatus["logs"].Healthy)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"s3ObjectRead:logs"}), eval.failedItems)
+}
+
+func TestCheckIntegrationSampleReadDecryptFailure(t *testing.T) {
+	for _, decryptErr := range []error{
+		awserr.New("AccessDenied", "User: arn:aws:sts::123456789012:assumed-role/PantherLogProcessingRole "+
+			"is not authorized to perform: kms:Decrypt on resource: arn:aws:kms:us-west-2:123456789012:key/logs", nil),
+		awserr.New("KMS.DisabledException", "arn:aws:kms:us-west-2:123456789012:key/logs is disabled.", nil),
+	} {
+		mockS3 := mockSampledObject("logs", "events.json.gz")
+		mockS3.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{}, decryptErr)
+		input := sampleReadInput("logs")
+
+		result, err := apiTest.CheckIntegration(input)
+		require.NoError(t, err)
+		// S3 let the role read the object, KMS did not let it decrypt it
+		assert.True(t, *result.S3ObjectReadStatus["logs"].Healthy)
+		decrypt := result.S3ObjectDecryptStatus["logs"]
+		assert.False(t, *decrypt.Healthy)
+		assert.Contains(t, *decrypt.ErrorMessage, "failed to decrypt events.json.gz")
+
+		eval, err := evaluateIntegrationHealth(apiTest, input)
+		require.NoError(t, err)
+		assert.Equal(t, aws.StringSlice([]string{"s3ObjectDecrypt:logs"}), eval.failedItems)
+	}
+}
+
+func TestCheckIntegrationSampleReadNoObject(t *testing.T) {
+	mockS3 := mockSampledObject("logs", "other/events.json.gz")
+	input := sampleReadInput("logs/cloudtrail/*")
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	read := result.S3ObjectReadStatus["logs/cloudtrail/*"]
+	assert.True(t, *read.Inconclusive)
+	assert.Equal(t, "no object to sample", *read.ErrorMessage)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.True(t, eval.passing())
+	assert.Equal(t, aws.StringSlice([]string{"s3ObjectRead:logs/cloudtrail/*"}), eval.inconclusiveItems)
+	mockS3.AssertNotCalled(t, "GetObject", mock.Anything)
+}
+
+func TestCheckIntegrationSampleReadListDenied(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, awserr.New("AccessDenied", "Access Denied", nil))
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+
+	result, err := apiTest.CheckIntegration(sampleReadInput("logs"))
+	require.NoError(t, err)
+	read := result.S3ObjectReadStatus["logs"]
+	assert.False(t, *read.Healthy)
+	assert.True(t, *read.Inconclusive)
+	assert.Contains(t, *read.ErrorMessage, "not allowed to list")
+}
+
+func TestCheckIntegrationSampleReadDisabled(t *testing.T) {
+	mockS3 := mockSampledObject("logs", "events.json.gz")
+	mockS3.On("GetObject", mock.Anything).Return(&s3.GetObjectOutput{}, awserr.New("KMS.NotFoundException", "key not found", nil))
+	input := sampleReadInput("logs")
+	input.DisabledChecks = aws.StringSlice([]string{models.HealthCheckS3Objects})
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.S3ObjectDecryptStatus["logs"].Informational)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.True(t, eval.passing())
+}
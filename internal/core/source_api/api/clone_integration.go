@@ -0,0 +1,200 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// CloneIntegration creates a new integration with the settings of an existing one.
+//
+// Only the configuration of the source integration is copied: the new integration gets its own ID
+// and starts without any scan status or history. Overrides in the input are applied on top of the
+// copied settings, and the result must pass the same health check as a PutIntegration.
+func (api API) CloneIntegration(input *models.CloneIntegrationInput) (*models.SourceIntegrationMetadata, error) {
+	api = api.withIntegration(input.IntegrationID)
+
+	source, err := db.GetIntegration(input.IntegrationID, false)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+
+	settings := cloneIntegrationSettings(source, input)
+	newIntegrations, err := api.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{settings},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(newIntegrations) == 0 {
+		return nil, &genericapi.AlreadyExistsError{
+			Message: fmt.Sprintf("integration of type %s already exists for %s",
+				*settings.IntegrationType, aws.StringValue(accountOf(settings.AWSAccountID, settings.AzureSubscriptionID, settings.GCPProjectID))),
+		}
+	}
+	return newIntegrations[0], nil
+}
+
+// cloneIntegrationSettings copies the configuration of an integration and applies the overrides.
+func cloneIntegrationSettings(
+	source *models.SourceIntegrationMetadata, input *models.CloneIntegrationInput) *models.PutIntegrationSettings {
+
+	settings := &models.PutIntegrationSettings{
+		AWSAccountID:        source.AWSAccountID,
+		IntegrationLabel:    source.IntegrationLabel,
+		Description:         source.Description,
+		IntegrationType:     source.IntegrationType,
+		ScanEnabled:         source.ScanEnabled,
+		CWEEnabled:          source.CWEEnabled,
+		RemediationEnabled:  source.RemediationEnabled,
+		ScanIntervalMins:    source.ScanIntervalMins,
+		UserID:              input.UserID,
+		S3Buckets:           append([]*string(nil), source.S3Buckets...),
+		KmsKeys:             append([]*string(nil), source.KmsKeys...),
+		CreateKmsGrants:     source.CreateKmsGrants,
+		IsOrgTrail:          source.IsOrgTrail,
+		ManagementAccountID: source.ManagementAccountID,
+		StreamARN:           source.StreamARN,
+		ShardIteratorType:   source.ShardIteratorType,
+		RoleChain:           append([]*string(nil), source.RoleChain...),
+
+		AzureTenantID:        source.AzureTenantID,
+		AzureSubscriptionID:  source.AzureSubscriptionID,
+		AzureClientID:        source.AzureClientID,
+		AzureClientSecretArn: source.AzureClientSecretArn,
+
+		GCPProjectID:               source.GCPProjectID,
+		GCPServiceAccountSecretArn: source.GCPServiceAccountSecretArn,
+		GCSBucket:                  source.GCSBucket,
+		PubSubSubscription:         source.PubSubSubscription,
+
+		// The credentials are not copied, they are only ever read by the puller
+		OktaDomain: source.OktaDomain,
+
+		SyslogAllowedCIDRs: source.SyslogAllowedCIDRs,
+		// A clone is never managed by the organization of its source, it would be deleted by the next sync
+		OrganizationExcludedAccounts: source.OrganizationExcludedAccounts,
+
+		MaxConcurrentObjects:  source.MaxConcurrentObjects,
+		MaxObjectsPerScan:     source.MaxObjectsPerScan,
+		OrderingMode:          source.OrderingMode,
+		MaxParallelism:        source.MaxParallelism,
+		DedupWindowMinutes:    source.DedupWindowMinutes,
+		MaxRecordBytes:        source.MaxRecordBytes,
+		OversizedRecordPolicy: source.OversizedRecordPolicy,
+		SampleRate:            source.SampleRate,
+		ErrorRateThreshold:    source.ErrorRateThreshold,
+
+		SilenceThresholdMinutes: source.SilenceThresholdMinutes,
+		EventErrorRateThreshold: source.EventErrorRateThreshold,
+
+		TimeExtraction:        source.TimeExtraction,
+		TimestampSkew:         source.TimestampSkew,
+		DeadLetterQueue:       source.DeadLetterQueue,
+		ArchiveFormat:         source.ArchiveFormat,
+		DataClassification:    source.DataClassification,
+		ProcessingRegion:      source.ProcessingRegion,
+		BlackoutWindows:       source.BlackoutWindows,
+		ScanSchedule:          source.ScanSchedule,
+		EnabledRegions:        append([]*string(nil), source.EnabledRegions...),
+		ExcludedRegions:       append([]*string(nil), source.ExcludedRegions...),
+		ResourceTypeAllowList: append([]*string(nil), source.ResourceTypeAllowList...),
+		ResourceTypeDenyList:  append([]*string(nil), source.ResourceTypeDenyList...),
+		RedactionRules:        source.RedactionRules,
+		IncludePatterns:       append([]*string(nil), source.IncludePatterns...),
+		ExcludePatterns:       append([]*string(nil), source.ExcludePatterns...),
+		DisabledChecks:        append([]*string(nil), source.DisabledChecks...),
+		FeatureFlags:          source.FeatureFlags,
+		ScanRampUp:            source.ScanRampUp,
+		DependsOn:             append([]*string(nil), source.DependsOn...),
+		NotificationTargets:   append([]*string(nil), source.NotificationTargets...),
+		EnrichmentSources:     append([]*string(nil), source.EnrichmentSources...),
+		Tags:                  source.Tags,
+		SeverityOverrides:     source.SeverityOverrides,
+
+		SessionDurationSeconds:  source.SessionDurationSeconds,
+		ScanCompleteCallbackURL: source.ScanCompleteCallbackURL,
+
+		AllowDuplicateLabel: input.AllowDuplicateLabel,
+	}
+
+	if input.AWSAccountID != nil {
+		settings.AWSAccountID = input.AWSAccountID
+	}
+	if input.IntegrationLabel != nil {
+		settings.IntegrationLabel = input.IntegrationLabel
+	}
+	if input.ScanEnabled != nil {
+		settings.ScanEnabled = input.ScanEnabled
+	}
+	if input.CWEEnabled != nil {
+		settings.CWEEnabled = input.CWEEnabled
+	}
+	if input.RemediationEnabled != nil {
+		settings.RemediationEnabled = input.RemediationEnabled
+	}
+	if input.ScanIntervalMins != nil {
+		settings.ScanIntervalMins = input.ScanIntervalMins
+	}
+	if input.S3Buckets != nil {
+		settings.S3Buckets = input.S3Buckets
+	}
+	if input.KmsKeys != nil {
+		settings.KmsKeys = input.KmsKeys
+	}
+	// Only the regions of the buckets which are still part of the clone are kept
+	settings.S3BucketRegions = bucketRegionsFor(settings.S3Buckets, source.S3BucketRegions)
+	settings.S3ObjectFilters = objectFiltersFor(settings.S3Buckets, source.S3ObjectFilters)
+	if input.MaxConcurrentObjects != nil {
+		settings.MaxConcurrentObjects = input.MaxConcurrentObjects
+	}
+	if input.MaxObjectsPerScan != nil {
+		settings.MaxObjectsPerScan = input.MaxObjectsPerScan
+	}
+	if input.DedupWindowMinutes != nil {
+		settings.DedupWindowMinutes = input.DedupWindowMinutes
+	}
+	if input.MaxRecordBytes != nil {
+		settings.MaxRecordBytes = input.MaxRecordBytes
+	}
+	if input.OversizedRecordPolicy != nil {
+		settings.OversizedRecordPolicy = input.OversizedRecordPolicy
+	}
+	if input.BlackoutWindows != nil {
+		settings.BlackoutWindows = input.BlackoutWindows
+	}
+	if input.ScanSchedule != nil {
+		settings.ScanSchedule = input.ScanSchedule
+	}
+	if input.RedactionRules != nil {
+		settings.RedactionRules = input.RedactionRules
+	}
+	if input.DisabledChecks != nil {
+		settings.DisabledChecks = input.DisabledChecks
+	}
+	return settings
+}
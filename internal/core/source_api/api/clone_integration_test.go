@@ -0,0 +1,113 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// mockBatchWriteDDBClient records the items written by BatchWriteItem.
+type mockBatchWriteDDBClient struct {
+	*modelstest.MockDDBClient
+	written []map[string]*dynamodb.AttributeValue
+}
+
+func (client *mockBatchWriteDDBClient) BatchWriteItem(
+	input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+
+	for _, requests := range input.RequestItems {
+		for _, request := range requests {
+			client.written = append(client.written, request.PutRequest.Item)
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func TestCloneIntegration(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"integrationId":        {S: aws.String(testIntegrationID)},
+			"integrationType":      {S: aws.String(models.IntegrationTypeAWSScan)},
+			"integrationLabel":     {S: aws.String(testIntegrationLabel)},
+			"awsAccountId":         {S: aws.String(testAccountID)},
+			"scanEnabled":          {BOOL: aws.Bool(true)},
+			"scanIntervalMins":     {N: aws.String("60")},
+			"scanStatus":           {S: aws.String(models.StatusError)},
+			"lastScanStartTime":    {S: aws.String("2009-11-10T23:00:00Z")},
+			"lastScanEndTime":      {S: aws.String("2009-11-10T23:10:00Z")},
+			"lastScanErrorMessage": {S: aws.String("something went wrong")},
+		},
+	}, nil)
+
+	result, err := apiTest.CloneIntegration(&models.CloneIntegrationInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		UserID:           aws.String(testUserID),
+		AWSAccountID:     aws.String("210987654321"),
+		IntegrationLabel: aws.String("StagingAWS"),
+	})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, testIntegrationID, *result.IntegrationID)
+	assert.Equal(t, "210987654321", *result.AWSAccountID)
+	assert.Equal(t, "StagingAWS", *result.IntegrationLabel)
+	assert.Equal(t, models.IntegrationTypeAWSScan, *result.IntegrationType)
+	assert.Equal(t, 60, *result.ScanIntervalMins)
+	assert.Equal(t, testUserID, *result.CreatedBy)
+
+	// Scan status and history must not be carried over to the clone
+	require.Len(t, mockClient.written, 1)
+	for _, attribute := range []string{"scanStatus", "lastScanStartTime", "lastScanEndTime", "lastScanErrorMessage"} {
+		assert.NotContains(t, mockClient.written[0], attribute)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestCloneIntegrationDoesNotExist(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := apiTest.CloneIntegration(&models.CloneIntegrationInput{
+		IntegrationID: aws.String(testIntegrationID),
+		UserID:        aws.String(testUserID),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockClient.AssertExpectations(t)
+}
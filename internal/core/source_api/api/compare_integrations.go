@@ -0,0 +1,147 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// CompareIntegrations returns the differences between the comparable settings of two integrations.
+//
+// IDs, accounts, credentials, timestamps and the scan and health history are not settings, they are ignored.
+// This verifies that a clone, or an integration created from a manifest, is configured like the reference one.
+func (API) CompareIntegrations(input *models.CompareIntegrationsInput) (*models.IntegrationComparison, error) {
+	reference, err := getIntegrationForEdit(input.ReferenceIntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	integration, err := getIntegrationForEdit(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	differences := compareIntegrations(reference, integration)
+	return &models.IntegrationComparison{
+		ReferenceIntegrationID: reference.IntegrationID,
+		IntegrationID:          integration.IntegrationID,
+		Differences:            differences,
+		Identical:              aws.Bool(len(differences) == 0),
+	}, nil
+}
+
+// compareIntegrations diffs the settings of an integration against the reference one.
+//
+// A change set leaves the settings it doesn't set unchanged, so the integration is also diffed against the
+// reference: the settings only the reference has are reported as removed.
+func compareIntegrations(reference, integration *models.SourceIntegrationMetadata) []*models.IntegrationFieldChange {
+	differences := make(changeSet, 0)
+	differences.setting("integrationType", reference.IntegrationType, integration.IntegrationType)
+	differences = append(differences, diffIntegration(reference, integrationSettings(integration))...)
+
+	for _, change := range diffIntegration(integration, integrationSettings(reference)) {
+		if *change.Action != models.FieldAdded {
+			// The other changes mirror the ones already found
+			continue
+		}
+		removed := &models.IntegrationFieldChange{
+			Field:   change.Field,
+			Action:  aws.String(models.FieldRemoved),
+			Current: change.Desired,
+		}
+		if !differences.contains(removed) {
+			differences = append(differences, removed)
+		}
+	}
+	return differences
+}
+
+func (set changeSet) contains(change *models.IntegrationFieldChange) bool {
+	for _, existing := range set {
+		if reflect.DeepEqual(existing, change) {
+			return true
+		}
+	}
+	return false
+}
+
+// integrationSettings are the settings of an integration, as the change set which configures another one like it.
+func integrationSettings(integration *models.SourceIntegrationMetadata) *models.UpdateIntegrationSettingsInput {
+	return &models.UpdateIntegrationSettingsInput{
+		IntegrationID:         integration.IntegrationID,
+		IntegrationLabel:      integration.IntegrationLabel,
+		Description:           integration.Description,
+		ScanEnabled:           integration.ScanEnabled,
+		CWEEnabled:            integration.CWEEnabled,
+		RemediationEnabled:    integration.RemediationEnabled,
+		ScanIntervalMins:      integration.ScanIntervalMins,
+		S3Buckets:             integration.S3Buckets,
+		KmsKeys:               integration.KmsKeys,
+		CreateKmsGrants:       integration.CreateKmsGrants,
+		IsOrgTrail:            integration.IsOrgTrail,
+		ManagementAccountID:   integration.ManagementAccountID,
+		StreamARN:             integration.StreamARN,
+		RoleChain:             integration.RoleChain,
+		ShardIteratorType:     integration.ShardIteratorType,
+		S3BucketRegions:       integration.S3BucketRegions,
+		MaxConcurrentObjects:  integration.MaxConcurrentObjects,
+		MaxObjectsPerScan:     integration.MaxObjectsPerScan,
+		OrderingMode:          integration.OrderingMode,
+		MaxParallelism:        integration.MaxParallelism,
+		DedupWindowMinutes:    integration.DedupWindowMinutes,
+		MaxRecordBytes:        integration.MaxRecordBytes,
+		OversizedRecordPolicy: integration.OversizedRecordPolicy,
+		SampleRate:            integration.SampleRate,
+		ErrorRateThreshold:    integration.ErrorRateThreshold,
+
+		SilenceThresholdMinutes: integration.SilenceThresholdMinutes,
+		EventErrorRateThreshold: integration.EventErrorRateThreshold,
+
+		TimeExtraction:        integration.TimeExtraction,
+		TimestampSkew:         integration.TimestampSkew,
+		DeadLetterQueue:       integration.DeadLetterQueue,
+		ArchiveFormat:         integration.ArchiveFormat,
+		DataClassification:    integration.DataClassification,
+		ProcessingRegion:      integration.ProcessingRegion,
+		BlackoutWindows:       integration.BlackoutWindows,
+		ScanSchedule:          integration.ScanSchedule,
+		EnabledRegions:        integration.EnabledRegions,
+		ExcludedRegions:       integration.ExcludedRegions,
+		ResourceTypeAllowList: integration.ResourceTypeAllowList,
+		ResourceTypeDenyList:  integration.ResourceTypeDenyList,
+		RedactionRules:        integration.RedactionRules,
+		IncludePatterns:       integration.IncludePatterns,
+		ExcludePatterns:       integration.ExcludePatterns,
+		DisabledChecks:        integration.DisabledChecks,
+		FeatureFlags:          integration.FeatureFlags,
+		DependsOn:             integration.DependsOn,
+		NotificationTargets:   integration.NotificationTargets,
+		EnrichmentSources:     integration.EnrichmentSources,
+		Tags:                  integration.Tags,
+		SeverityOverrides:     integration.SeverityOverrides,
+
+		SessionDurationSeconds:  integration.SessionDurationSeconds,
+		ScanCompleteCallbackURL: integration.ScanCompleteCallbackURL,
+
+		OrganizationExcludedAccounts: integration.OrganizationExcludedAccounts,
+	}
+}
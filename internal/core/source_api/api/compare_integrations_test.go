@@ -0,0 +1,144 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testReferenceIntegrationID = "0d6b7c2e-3d0b-4f6e-9d1a-2e8a5b3c4f71"
+
+// comparedIntegration is a log integration of another account, created at another time, with the given changes.
+func comparedIntegration(integrationID, accountID string, edit func(*models.SourceIntegrationMetadata)) *models.SourceIntegrationMetadata {
+	integration := &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(integrationID),
+		AWSAccountID:       aws.String(accountID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWS3),
+		IntegrationLabel:   aws.String("cloudtrail"),
+		CreatedAtTime:      aws.Time(time.Now()),
+		ScanEnabled:        aws.Bool(true),
+		ScanIntervalMins:   aws.Int(60),
+		S3Buckets:          aws.StringSlice([]string{"trail", "trail-archive/2020/*"}),
+		DedupWindowMinutes: aws.Int(30),
+		FeatureFlags:       map[string]bool{models.FeatureFlagParserV2: true},
+		Version:            aws.Int64(3),
+	}
+	if edit != nil {
+		edit(integration)
+	}
+	return integration
+}
+
+func mockComparedIntegrations(t *testing.T, integrations ...*models.SourceIntegrationMetadata) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	for _, integration := range integrations {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		mockClient.On("GetItem", getItemFor(*integration.IntegrationID)).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+	}
+}
+
+func TestCompareIntegrationsIdentical(t *testing.T) {
+	mockComparedIntegrations(t,
+		comparedIntegration(testReferenceIntegrationID, testAccountID, nil),
+		comparedIntegration(testIntegrationID, "210987654321", func(integration *models.SourceIntegrationMetadata) {
+			// Bucket order doesn't matter
+			integration.S3Buckets = aws.StringSlice([]string{"trail-archive/2020/*", "trail"})
+			integration.CreatedAtTime = aws.Time(time.Now().Add(-time.Hour))
+			integration.Version = aws.Int64(1)
+		}),
+	)
+
+	result, err := apiTest.CompareIntegrations(&models.CompareIntegrationsInput{
+		ReferenceIntegrationID: aws.String(testReferenceIntegrationID),
+		IntegrationID:          aws.String(testIntegrationID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.IntegrationComparison{
+		ReferenceIntegrationID: aws.String(testReferenceIntegrationID),
+		IntegrationID:          aws.String(testIntegrationID),
+		Differences:            []*models.IntegrationFieldChange{},
+		Identical:              aws.Bool(true),
+	}, result)
+}
+
+func TestCompareIntegrationsDifferent(t *testing.T) {
+	mockComparedIntegrations(t,
+		comparedIntegration(testReferenceIntegrationID, testAccountID, nil),
+		comparedIntegration(testIntegrationID, testAccountID, func(integration *models.SourceIntegrationMetadata) {
+			integration.ScanIntervalMins = aws.Int(180)
+			integration.S3Buckets = aws.StringSlice([]string{"trail"})
+			// Only the reference has these settings
+			integration.DedupWindowMinutes = nil
+			integration.FeatureFlags = nil
+		}),
+	)
+
+	result, err := apiTest.CompareIntegrations(&models.CompareIntegrationsInput{
+		ReferenceIntegrationID: aws.String(testReferenceIntegrationID),
+		IntegrationID:          aws.String(testIntegrationID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.IntegrationFieldChange{
+		{Field: aws.String("scanIntervalMins"), Action: aws.String(models.FieldChanged), Current: 60, Desired: 180},
+		{Field: aws.String("s3Buckets"), Action: aws.String(models.FieldRemoved), Current: "trail-archive/2020/*"},
+		{Field: aws.String("dedupWindowMinutes"), Action: aws.String(models.FieldRemoved), Current: 30},
+		{Field: aws.String("featureFlags." + models.FeatureFlagParserV2), Action: aws.String(models.FieldRemoved), Current: true},
+	}, result.Differences)
+	assert.False(t, *result.Identical)
+}
+
+func TestCompareIntegrationsType(t *testing.T) {
+	reference := comparedIntegration(testReferenceIntegrationID, testAccountID, nil)
+	integration := comparedIntegration(testIntegrationID, testAccountID, func(integration *models.SourceIntegrationMetadata) {
+		integration.IntegrationType = aws.String(models.IntegrationTypeAWSScan)
+	})
+
+	assert.Equal(t, []*models.IntegrationFieldChange{{
+		Field:   aws.String("integrationType"),
+		Action:  aws.String(models.FieldChanged),
+		Current: models.IntegrationTypeAWS3,
+		Desired: models.IntegrationTypeAWSScan,
+	}}, compareIntegrations(reference, integration))
+}
+
+func TestCompareIntegrationsDoesNotExist(t *testing.T) {
+	mockComparedIntegrations(t, comparedIntegration(testReferenceIntegrationID, testAccountID, nil))
+	db.Client.(*modelstest.MockDDBClient).On("GetItem", getItemFor(testIntegrationID)).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := apiTest.CompareIntegrations(&models.CompareIntegrationsInput{
+		ReferenceIntegrationID: aws.String(testReferenceIntegrationID),
+		IntegrationID:          aws.String(testIntegrationID),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
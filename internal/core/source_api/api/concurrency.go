@@ -0,0 +1,53 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// forEachConcurrently calls fn for each index below count, with at most limit calls running at the same time.
+//
+// The error of each call is returned at its index. A call which panics is recovered, so that waiting for the
+// others doesn't block forever, and fails with an error.
+func forEachConcurrently(count, limit int, fn func(i int) error) []error {
+	errs := make([]error, count)
+	done := make(chan struct{}, count)
+	running := make(chan struct{}, limit)
+	for i := 0; i < count; i++ {
+		running <- struct{}{}
+		go func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					zap.L().Error("panicked while running concurrently", zap.Int("index", i), zap.Any("panic", r))
+					errs[i] = errors.New("failed unexpectedly")
+				}
+				<-running
+				done <- struct{}{}
+			}()
+			errs[i] = fn(i)
+		}(i)
+	}
+	for i := 0; i < count; i++ {
+		<-done
+	}
+	return errs
+}
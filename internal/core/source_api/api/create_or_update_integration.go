@@ -0,0 +1,178 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The namespace of the IDs derived from the identity of the integrations created by CreateOrUpdateIntegration
+var externalIntegrationNamespace = uuid.MustParse("5b0c4c5e-6c1e-4c35-9d0a-0d1f5a3e7b21")
+
+// CreateOrUpdateIntegration creates the integration with the given external identity, or updates it if it exists.
+//
+// The ID of the integration is derived from its identity, so the integration is created with a conditional write:
+// when two requests create the same integration concurrently, the one which loses the race updates it instead.
+// The health check runs once, either before the integration is created or as part of its update.
+func (api API) CreateOrUpdateIntegration(
+	input *models.CreateOrUpdateIntegrationInput) (*models.CreateOrUpdateIntegrationOutput, error) {
+
+	settings := input.Settings
+	integrationID := externalIntegrationID(settings.AWSAccountID, settings.IntegrationType, input.ExternalKey)
+	existing, err := db.GetIntegration(integrationID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		created, err := api.createExternalIntegration(integrationID, input)
+		if _, lostRace := err.(*ddb.ConditionalCheckFailedError); !lostRace {
+			if err != nil {
+				return nil, err
+			}
+			return &models.CreateOrUpdateIntegrationOutput{Integration: created, Created: aws.Bool(true)}, nil
+		}
+		zap.L().Info("integration was created concurrently, updating it",
+			zap.String("integrationId", *integrationID))
+	}
+
+	result, err := api.UpdateIntegrationSettings(manifestUpdate(integrationID, settings, settings.UserID))
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreateOrUpdateIntegrationOutput{Integration: result.SourceIntegrationMetadata, Created: aws.Bool(false)}, nil
+}
+
+// externalIntegrationID derives the ID of an integration from its identity.
+//
+// It's formatted as a random UUID so that it's accepted wherever the ID of an integration is.
+func externalIntegrationID(awsAccountID, integrationType, externalKey *string) *string {
+	identity := *awsAccountID + "/" + *integrationType + "/" + *externalKey
+	return aws.String(uuid.NewHash(sha256.New(), externalIntegrationNamespace, []byte(identity), 4).String())
+}
+
+// createExternalIntegration creates an integration with the checks of PutIntegration, unless it already exists.
+//
+// The integration is written before the queue permission is added, so that a request which loses the race to
+// create it has no side effect to undo besides its own KMS grants.
+func (api API) createExternalIntegration(
+	integrationID *string, input *models.CreateOrUpdateIntegrationInput) (*models.SourceIntegrationMetadata, error) {
+
+	settings := input.Settings
+	if err := checkBucketRegions(settings.S3Buckets, settings.S3BucketRegions); err != nil {
+		return nil, err
+	}
+	if !aws.BoolValue(settings.AllowDuplicateLabel) {
+		labels, err := getIntegrationLabels(nil)
+		if err != nil {
+			return nil, err
+		}
+		account := accountOf(settings.AWSAccountID, settings.AzureSubscriptionID, settings.GCPProjectID)
+		if err = labels.check(account, settings.IntegrationLabel); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkDependencies(nil, settings.DependsOn); err != nil {
+		return nil, err
+	}
+	if err := api.checkNotificationTargets(settings.NotificationTargets); err != nil {
+		return nil, err
+	}
+	if err := checkEnrichmentSources(settings.EnrichmentSources); err != nil {
+		return nil, err
+	}
+	if err := checkProcessingRegion(settings.ProcessingRegion); err != nil {
+		return nil, err
+	}
+	healthCheckInput := healthCheckInputForNew(settings)
+	status, failedChecks, expiry, err := checkIntegrationHealth(api, healthCheckInput, aws.BoolValue(settings.AllowPartialHealth))
+	if err != nil {
+		return nil, err
+	}
+
+	integration := generateNewIntegration(settings)
+	integration.IntegrationID, integration.ExternalKey = integrationID, input.ExternalKey
+	integration.HealthStatus, integration.FailedHealthChecks = status, failedChecks
+	integration.HealthCheckResult = healthCheckResult(status)
+	integration.LastHealthyTime, integration.LastHealthCheckTime = lastHealthyTime(status), aws.Time(time.Now())
+	integration.CredentialExpiry = expiry
+	if len(settings.KmsKeys) > 0 {
+		integration.KmsKeys, integration.KmsKeyAliases, err = resolveKmsKeysFunc(settings.AWSAccountID, settings.KmsKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pendingGrants *transientError
+	if aws.BoolValue(settings.CreateKmsGrants) && *settings.IntegrationType == models.IntegrationTypeAWS3 &&
+		len(integration.KmsKeys) > 0 {
+
+		// The integration is new, none of its grants are replaced
+		grants, err := syncKmsGrants(settings.AWSAccountID, integrationID, integration.KmsKeys, nil)
+		if transientErr, ok := err.(*transientError); ok {
+			pendingGrants, err = transientErr, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		integration.KmsGrants = grants.result()
+	}
+
+	if err = db.PutNewSourceIntegration(integration); err != nil {
+		retireIntegrationKmsGrants(integration)
+		return nil, err
+	}
+	if *integration.IntegrationType == models.IntegrationTypeAWS3 {
+		// The queue already allows the accounts which have other log integrations
+		err = AddPermissionToLogProcessorQueue(*integration.AWSAccountID)
+		if _, ok := errors.Cause(err).(*genericapi.AlreadyExistsError); err != nil && !ok {
+			if deleteErr := db.DeleteIntegrationItem(&models.DeleteIntegrationInput{IntegrationID: integrationID}); deleteErr != nil {
+				zap.L().Error("failed to delete the integration after its queue permission failed",
+					zap.String("integrationId", *integrationID), zap.Error(deleteErr))
+			}
+			retireIntegrationKmsGrants(integration)
+			return nil, err
+		}
+	}
+
+	if pendingGrants != nil {
+		if err = enqueueRetry(integrationID, pendingGrants); err != nil {
+			return nil, err
+		}
+	}
+	if err = recordChange(integrationID, models.ChangeOperationCreated, integration.CreatedBy, creationChanges(integration)); err != nil {
+		return nil, err
+	}
+	if *integration.IntegrationType == models.IntegrationTypeAWSScan {
+		if err = ScanAllResources([]*models.SourceIntegrationMetadata{integration}); err != nil {
+			return nil, err
+		}
+	}
+	return integration, nil
+}
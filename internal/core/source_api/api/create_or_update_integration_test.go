@@ -0,0 +1,154 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+// mockConditionalPutClient records the integrations which are put, and fails their conditional write if it's set to.
+type mockConditionalPutClient struct {
+	*modelstest.MockDDBClient
+	conditionFails bool
+	puts           []*dynamodb.PutItemInput
+}
+
+func (client *mockConditionalPutClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if aws.StringValue(input.TableName) != "test" {
+		return client.MockDDBClient.PutItem(input)
+	}
+	client.puts = append(client.puts, input)
+	if client.conditionFails && input.ConditionExpression != nil {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "the integration exists", nil)
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func externalSettings() *models.PutIntegrationSettings {
+	settings := validSettings(models.IntegrationTypeAWSScan)
+	settings.S3Buckets = nil
+	settings.ScanEnabled, settings.ScanIntervalMins = aws.Bool(false), aws.Int(60)
+	return &settings
+}
+
+func TestExternalIntegrationID(t *testing.T) {
+	id := externalIntegrationID(aws.String(testAccountID), aws.String(models.IntegrationTypeAWSScan), aws.String("prod"))
+	assert.Equal(t, id, externalIntegrationID(aws.String(testAccountID), aws.String(models.IntegrationTypeAWSScan), aws.String("prod")))
+	assert.NotEqual(t, id, externalIntegrationID(aws.String(testAccountID), aws.String(models.IntegrationTypeAWSScan), aws.String("dev")))
+	assert.NotEqual(t, id, externalIntegrationID(aws.String(testAccountID), aws.String(models.IntegrationTypeAWS3), aws.String("prod")))
+
+	validate, err := models.Validator()
+	require.NoError(t, err)
+	assert.NoError(t, validate.Struct(&models.GetIntegrationInput{IntegrationID: id}))
+}
+
+func TestCreateOrUpdateIntegrationCreates(t *testing.T) {
+	mockClient := &mockConditionalPutClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	result, err := apiTest.CreateOrUpdateIntegration(&models.CreateOrUpdateIntegrationInput{
+		ExternalKey: aws.String("prod"),
+		Settings:    externalSettings(),
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.Created)
+	expectedID := externalIntegrationID(aws.String(testAccountID), aws.String(models.IntegrationTypeAWSScan), aws.String("prod"))
+	assert.Equal(t, expectedID, result.Integration.IntegrationID)
+	assert.Equal(t, "prod", *result.Integration.ExternalKey)
+	assert.Equal(t, models.HealthStatusHealthy, *result.Integration.HealthStatus)
+
+	require.Len(t, mockClient.puts, 1)
+	// Only the marker of a deleted integration can be replaced
+	assert.Equal(t, "(attribute_not_exists (#0)) OR (attribute_exists (#1))", *mockClient.puts[0].ConditionExpression)
+	var written models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.puts[0].Item, &written))
+	assert.Equal(t, expectedID, written.IntegrationID)
+	assert.Equal(t, "prod", *written.ExternalKey)
+	mockClient.AssertExpectations(t)
+}
+
+func TestCreateOrUpdateIntegrationUpdates(t *testing.T) {
+	integrationID := externalIntegrationID(aws.String(testAccountID), aws.String(models.IntegrationTypeAWSScan), aws.String("prod"))
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:     aws.String(testAccountID),
+		IntegrationID:    integrationID,
+		IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+		IntegrationLabel: aws.String(testIntegrationLabel),
+		ExternalKey:      aws.String("prod"),
+		ScanEnabled:      aws.Bool(true),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"integrationId": {S: integrationID},
+		"scanEnabled":   {BOOL: aws.Bool(false)},
+	}}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	result, err := apiTest.CreateOrUpdateIntegration(&models.CreateOrUpdateIntegrationInput{
+		ExternalKey: aws.String("prod"),
+		Settings:    externalSettings(),
+	})
+	require.NoError(t, err)
+	assert.False(t, *result.Created)
+	assert.Equal(t, integrationID, result.Integration.IntegrationID)
+	assert.False(t, *result.Integration.ScanEnabled)
+	mockClient.AssertExpectations(t)
+}
+
+// A request which loses the race to create the integration updates it instead.
+func TestCreateOrUpdateIntegrationLostRace(t *testing.T) {
+	integrationID := externalIntegrationID(aws.String(testAccountID), aws.String(models.IntegrationTypeAWSScan), aws.String("prod"))
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   integrationID,
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+		ExternalKey:     aws.String("prod"),
+	})
+	require.NoError(t, err)
+	mockClient := &mockConditionalPutClient{MockDDBClient: &modelstest.MockDDBClient{}, conditionFails: true}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	result, err := apiTest.CreateOrUpdateIntegration(&models.CreateOrUpdateIntegrationInput{
+		ExternalKey: aws.String("prod"),
+		Settings:    externalSettings(),
+	})
+	require.NoError(t, err)
+	assert.False(t, *result.Created)
+	assert.Equal(t, integrationID, result.Integration.IntegrationID)
+	require.Len(t, mockClient.puts, 1)
+	mockClient.AssertExpectations(t)
+}
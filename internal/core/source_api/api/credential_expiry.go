@@ -0,0 +1,59 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// storedCredentialExpiry is the CredentialExpiry to write with an update, the zero time removes a previous one.
+func storedCredentialExpiry(expiry *time.Time) *time.Time {
+	if expiry == nil {
+		return aws.Time(time.Time{})
+	}
+	return expiry
+}
+
+// ListExpiringCredentials returns the integrations whose credentials expire within the given number of days.
+//
+// Credentials which already expired are included, the earliest to expire come first.
+func (API) ListExpiringCredentials(input *models.ListExpiringCredentialsInput) ([]*models.SourceIntegration, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, *input.WithinDays)
+	expiring := make([]*models.SourceIntegration, 0)
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil || integration.CredentialExpiry == nil {
+			continue
+		}
+		if integration.CredentialExpiry.Before(cutoff) {
+			expiring = append(expiring, integration)
+		}
+	}
+	sort.SliceStable(expiring, func(i, j int) bool { return expiring[i].CredentialExpiry.Before(*expiring[j].CredentialExpiry) })
+	return expiring, nil
+}
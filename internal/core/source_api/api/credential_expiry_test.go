@@ -0,0 +1,138 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func TestCheckKeysCredentialExpiry(t *testing.T) {
+	soon, later := time.Now().Add(24*time.Hour).UTC(), time.Now().Add(90*24*time.Hour).UTC()
+	mockKMS := &mockKMSClient{}
+	mockKMS.On("DescribeKey", &kms.DescribeKeyInput{KeyId: aws.String("imported-later")}).
+		Return(&kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Enabled: aws.Bool(true), ValidTo: aws.Time(later)}}, nil)
+	mockKMS.On("DescribeKey", &kms.DescribeKeyInput{KeyId: aws.String("imported-soon")}).
+		Return(&kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Enabled: aws.Bool(true), ValidTo: aws.Time(soon)}}, nil)
+	mockKMS.On("DescribeKey", &kms.DescribeKeyInput{KeyId: aws.String("generated")}).
+		Return(&kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Enabled: aws.Bool(true)}}, nil)
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+
+	_, expiry := checkKeys(nil, aws.StringSlice([]string{"imported-later", "generated", "imported-soon"}))
+	assert.Equal(t, aws.Time(soon), expiry)
+
+	// Key material generated by KMS doesn't expire
+	_, expiry = checkKeys(nil, aws.StringSlice([]string{"generated"}))
+	assert.Nil(t, expiry)
+}
+
+// updatedAttributes are the names and string values of the attributes written by an update.
+func updatedAttributes(update *dynamodb.UpdateItemInput) (names []string, values []string) {
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	for _, value := range update.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	return names, values
+}
+
+func TestUpdateIntegrationSettingsRecordsCredentialExpiry(t *testing.T) {
+	mockBucketOwnership(t)
+	expiry := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	mockLogIntegration([]string{"bucket-1"}, []string{testKeyArn})
+	mockClient := db.Client.(*modelstest.MockDDBClient)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	var checkedKeys []*string
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checkedKeys = input.KmsKeys
+		return true, aws.Time(expiry), nil
+	}
+	defer func() { evaluateIntegrationFunc = evaluateIntegration }()
+	resolveKmsKeysFunc = func(_ *string, keys []*string) ([]*string, map[string]*string, error) { return keys, nil, nil }
+	defer func() { resolveKmsKeysFunc = resolveKmsKeys }()
+
+	// The expiry comes from the health check of the keys in the update
+	keys := aws.StringSlice([]string{testKeyArn, "arn:aws:kms:us-west-2:123456789012:key/imported"})
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		KmsKeys:       keys,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, keys, checkedKeys)
+	update := mockClient.Calls[len(mockClient.Calls)-1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	names, values := updatedAttributes(update)
+	assert.Contains(t, names, "credentialExpiry")
+	assert.Contains(t, values, "2020-07-01T00:00:00Z")
+}
+
+func TestUpdateIntegrationSettingsKeepsCredentialExpiry(t *testing.T) {
+	mockBucketOwnership(t)
+	mockLogIntegration([]string{"bucket-1"}, []string{testKeyArn})
+	mockClient := db.Client.(*modelstest.MockDDBClient)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	defer func() { evaluateIntegrationFunc = evaluateIntegration }()
+
+	// The keys are not checked when the update doesn't change them, their stored expiry holds
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-2"}),
+	})
+	require.NoError(t, err)
+	update := mockClient.Calls[len(mockClient.Calls)-1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	names, _ := updatedAttributes(update)
+	assert.NotContains(t, names, "credentialExpiry")
+}
+
+func TestListExpiringCredentials(t *testing.T) {
+	now := time.Now()
+	var items []map[string]*dynamodb.AttributeValue
+	for _, integration := range []*models.SourceIntegrationMetadata{
+		{IntegrationID: aws.String("outside-window"), CredentialExpiry: aws.Time(now.Add(60 * 24 * time.Hour))},
+		{IntegrationID: aws.String("inside-window"), CredentialExpiry: aws.Time(now.Add(10 * 24 * time.Hour))},
+		{IntegrationID: aws.String("never-expires")},
+		{IntegrationID: aws.String("already-expired"), CredentialExpiry: aws.Time(now.Add(-24 * time.Hour))},
+	} {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{MockScanAttributes: items}, TableName: "test"}
+
+	result, err := apiTest.ListExpiringCredentials(&models.ListExpiringCredentialsInput{WithinDays: aws.Int(30)})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "already-expired", *result[0].IntegrationID)
+	assert.Equal(t, "inside-window", *result[1].IntegrationID)
+}
@@ -0,0 +1,149 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The secret of the credentials of an integration is named after it, the source API can only manage the secrets
+// with this prefix
+const credentialsSecretPrefix = "panther-source-credentials-"
+
+// How long the secret of a deleted integration can still be recovered
+const credentialsRecoveryWindowDays = 7
+
+// storeCredentials creates the secret the credentials of a new integration are stored in.
+//
+// Only the reference to the secret is stored with the integration, the credentials are never returned.
+func storeCredentials(integration *models.SourceIntegrationMetadata, credentials map[string]*string) error {
+	secret, err := json.Marshal(credentials)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal the credentials: " + err.Error()}
+	}
+	name := aws.String(credentialsSecretPrefix + *integration.IntegrationID)
+	output, err := secretsClient.CreateSecret(&secretsmanager.CreateSecretInput{
+		Name:         name,
+		Description:  aws.String("The credentials of the Panther integration " + *integration.IntegrationID),
+		SecretString: aws.String(string(secret)),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == secretsmanager.ErrCodeResourceExistsException {
+		// Left behind by an integration which failed to be written, the secret is reused
+		putOutput, putErr := secretsClient.PutSecretValue(&secretsmanager.PutSecretValueInput{
+			SecretId:     name,
+			SecretString: aws.String(string(secret)),
+		})
+		if putErr != nil {
+			return &genericapi.AWSError{Err: putErr, Method: "secretsmanager.PutSecretValue"}
+		}
+		output, err = &secretsmanager.CreateSecretOutput{ARN: putOutput.ARN}, nil
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "secretsmanager.CreateSecret"}
+	}
+	integration.CredentialsSecretArn = output.ARN
+	integration.CredentialsUpdatedTime = aws.Time(time.Now())
+	return nil
+}
+
+// rotateCredentials replaces the credentials of an integration, the secret is created if it has none yet.
+func rotateCredentials(integration *models.SourceIntegrationMetadata, credentials map[string]*string) error {
+	if integration.CredentialsSecretArn == nil {
+		return storeCredentials(integration, credentials)
+	}
+	secret, err := json.Marshal(credentials)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal the credentials: " + err.Error()}
+	}
+	_, err = secretsClient.PutSecretValue(&secretsmanager.PutSecretValueInput{
+		SecretId:     integration.CredentialsSecretArn,
+		SecretString: aws.String(string(secret)),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "secretsmanager.PutSecretValue"}
+	}
+	integration.CredentialsUpdatedTime = aws.Time(time.Now())
+	return nil
+}
+
+// loadCredentials reads the credentials of an integration from its secret.
+func loadCredentials(secretArn *string) (map[string]*string, error) {
+	secret, err := secretsClient.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: secretArn})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "secretsmanager.GetSecretValue"}
+	}
+	var credentials map[string]*string
+	if err = json.Unmarshal([]byte(aws.StringValue(secret.SecretString)), &credentials); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal the credentials: " + err.Error()}
+	}
+	return credentials, nil
+}
+
+// deleteCredentials deletes the secret of the credentials of an integration.
+//
+// The secret of a deleted integration can be recovered for a week, unless force is set, which is used to undo
+// a failed creation.
+func deleteCredentials(integration *models.SourceIntegrationMetadata, force bool) {
+	if integration.CredentialsSecretArn == nil {
+		return
+	}
+	input := &secretsmanager.DeleteSecretInput{SecretId: integration.CredentialsSecretArn}
+	if force {
+		input.ForceDeleteWithoutRecovery = aws.Bool(true)
+	} else {
+		input.RecoveryWindowInDays = aws.Int64(credentialsRecoveryWindowDays)
+	}
+	_, err := secretsClient.DeleteSecret(input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == secretsmanager.ErrCodeResourceNotFoundException {
+		return
+	}
+	if err != nil {
+		zap.L().Error("failed to delete the credentials of the integration, the secret has to be deleted manually",
+			zap.String("integrationId", *integration.IntegrationID),
+			zap.String("secretArn", *integration.CredentialsSecretArn),
+			zap.Error(err))
+	}
+}
+
+// checkCredentialsSettings rejects credentials which are not exactly those of the integration type.
+//
+// An integration which needs credentials can only be without them in the update when it already has a secret.
+func checkCredentialsSettings(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	if input.Credentials == nil && integration.CredentialsSecretArn != nil {
+		return nil
+	}
+	field, ok := models.CheckCredentialFields(aws.StringValue(integration.IntegrationType), input.Credentials)
+	switch {
+	case ok:
+		return nil
+	case field == "credentials":
+		return &genericapi.InvalidInputError{Message: "credentials: only integrations which pull their logs have credentials"}
+	default:
+		return &genericapi.InvalidInputError{Message: field + ": the credentials must be exactly those of the integration type"}
+	}
+}
@@ -0,0 +1,226 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testCredentialsSecretArn = "arn:aws:secretsmanager:us-west-2:123456789012:secret:panther-source-credentials-" +
+	testIntegrationID + "-AbCdEf"
+
+type mockCredentialsClient struct {
+	secretsmanageriface.SecretsManagerAPI
+	mock.Mock
+}
+
+func (client *mockCredentialsClient) CreateSecret(input *secretsmanager.CreateSecretInput) (*secretsmanager.CreateSecretOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*secretsmanager.CreateSecretOutput), args.Error(1)
+}
+
+func (client *mockCredentialsClient) PutSecretValue(
+	input *secretsmanager.PutSecretValueInput) (*secretsmanager.PutSecretValueOutput, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*secretsmanager.PutSecretValueOutput), args.Error(1)
+}
+
+func (client *mockCredentialsClient) DeleteSecret(input *secretsmanager.DeleteSecretInput) (*secretsmanager.DeleteSecretOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*secretsmanager.DeleteSecretOutput), args.Error(1)
+}
+
+func mockCredentials(t *testing.T) *mockCredentialsClient {
+	previousClient := secretsClient
+	t.Cleanup(func() { secretsClient = previousClient })
+	mockSecrets := &mockCredentialsClient{}
+	secretsClient = mockSecrets
+	return mockSecrets
+}
+
+func testCredentials() map[string]*string {
+	return map[string]*string{"apiToken": aws.String("token-value")}
+}
+
+func TestStoreCredentials(t *testing.T) {
+	mockSecrets := mockCredentials(t)
+	mockSecrets.On("CreateSecret", mock.Anything).
+		Return(&secretsmanager.CreateSecretOutput{ARN: aws.String(testCredentialsSecretArn)}, nil)
+
+	integration := &models.SourceIntegrationMetadata{IntegrationID: aws.String(testIntegrationID)}
+	require.NoError(t, storeCredentials(integration, testCredentials()))
+	assert.Equal(t, testCredentialsSecretArn, *integration.CredentialsSecretArn)
+	assert.NotNil(t, integration.CredentialsUpdatedTime)
+
+	// The secret is named after the integration and holds the credentials as JSON
+	createInput := mockSecrets.Calls[0].Arguments.Get(0).(*secretsmanager.CreateSecretInput)
+	assert.Equal(t, "panther-source-credentials-"+testIntegrationID, *createInput.Name)
+	var stored map[string]string
+	require.NoError(t, json.Unmarshal([]byte(*createInput.SecretString), &stored))
+	assert.Equal(t, map[string]string{"apiToken": "token-value"}, stored)
+	mockSecrets.AssertExpectations(t)
+}
+
+func TestStoreCredentialsSecretExists(t *testing.T) {
+	mockSecrets := mockCredentials(t)
+	mockSecrets.On("CreateSecret", mock.Anything).Return(
+		(*secretsmanager.CreateSecretOutput)(nil), awserr.New(secretsmanager.ErrCodeResourceExistsException, "exists", nil))
+	mockSecrets.On("PutSecretValue", mock.Anything).
+		Return(&secretsmanager.PutSecretValueOutput{ARN: aws.String(testCredentialsSecretArn)}, nil)
+
+	integration := &models.SourceIntegrationMetadata{IntegrationID: aws.String(testIntegrationID)}
+	require.NoError(t, storeCredentials(integration, testCredentials()))
+	assert.Equal(t, testCredentialsSecretArn, *integration.CredentialsSecretArn)
+	putInput := mockSecrets.Calls[1].Arguments.Get(0).(*secretsmanager.PutSecretValueInput)
+	assert.Equal(t, "panther-source-credentials-"+testIntegrationID, *putInput.SecretId)
+	mockSecrets.AssertExpectations(t)
+}
+
+func TestStoreCredentialsFails(t *testing.T) {
+	mockSecrets := mockCredentials(t)
+	mockSecrets.On("CreateSecret", mock.Anything).Return(
+		(*secretsmanager.CreateSecretOutput)(nil), awserr.New("AccessDeniedException", "denied", nil))
+
+	integration := &models.SourceIntegrationMetadata{IntegrationID: aws.String(testIntegrationID)}
+	err := storeCredentials(integration, testCredentials())
+	require.IsType(t, &genericapi.AWSError{}, err)
+	assert.Equal(t, "secretsmanager.CreateSecret", err.(*genericapi.AWSError).Method)
+	assert.Nil(t, integration.CredentialsSecretArn)
+}
+
+func TestRotateCredentials(t *testing.T) {
+	mockSecrets := mockCredentials(t)
+	mockSecrets.On("PutSecretValue", mock.Anything).
+		Return(&secretsmanager.PutSecretValueOutput{ARN: aws.String(testCredentialsSecretArn)}, nil)
+
+	integration := &models.SourceIntegrationMetadata{
+		IntegrationID:        aws.String(testIntegrationID),
+		CredentialsSecretArn: aws.String(testCredentialsSecretArn),
+	}
+	require.NoError(t, rotateCredentials(integration, map[string]*string{"apiToken": aws.String("rotated")}))
+	assert.NotNil(t, integration.CredentialsUpdatedTime)
+	putInput := mockSecrets.Calls[0].Arguments.Get(0).(*secretsmanager.PutSecretValueInput)
+	assert.Equal(t, testCredentialsSecretArn, *putInput.SecretId)
+	assert.JSONEq(t, `{"apiToken": "rotated"}`, *putInput.SecretString)
+	mockSecrets.AssertNotCalled(t, "CreateSecret", mock.Anything)
+}
+
+func TestDeleteIntegrationDeletesCredentials(t *testing.T) {
+	mockSecrets := mockCredentials(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:        aws.String(testIntegrationID),
+		IntegrationType:      aws.String(models.IntegrationTypeHTTPIngest),
+		CredentialsSecretArn: aws.String(testCredentialsSecretArn),
+	})
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	mockSecrets.On("DeleteSecret", &secretsmanager.DeleteSecretInput{
+		SecretId:             aws.String(testCredentialsSecretArn),
+		RecoveryWindowInDays: aws.Int64(7),
+	}).Return(&secretsmanager.DeleteSecretOutput{}, nil)
+
+	require.NoError(t, apiTest.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: aws.String(testIntegrationID)}))
+	mockSecrets.AssertExpectations(t)
+}
+
+func TestSoftDeleteIntegrationKeepsCredentials(t *testing.T) {
+	mockSecrets := mockCredentials(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:        aws.String(testIntegrationID),
+		IntegrationType:      aws.String(models.IntegrationTypeHTTPIngest),
+		CredentialsSecretArn: aws.String(testCredentialsSecretArn),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	// The credentials can't be provided again when the integration is restored
+	require.NoError(t, apiTest.DeleteIntegration(&models.DeleteIntegrationInput{
+		IntegrationID: aws.String(testIntegrationID),
+		SoftDelete:    aws.Bool(true),
+	}))
+	mockSecrets.AssertNotCalled(t, "DeleteSecret", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsCredentialsNotSupported(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+		AWSAccountID:    aws.String(testAccountID),
+	})
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Credentials:   testCredentials(),
+	})
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Equal(t, "credentials: only integrations which pull their logs have credentials", err.(*genericapi.InvalidInputError).Message)
+}
+
+func TestPreviewIntegrationChangeSetCredentials(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:        aws.String(testIntegrationID),
+		IntegrationType:      aws.String(models.IntegrationTypeHTTPIngest),
+		CredentialsSecretArn: aws.String(testCredentialsSecretArn),
+	})
+
+	result, err := apiTest.PreviewIntegrationChangeSet(&models.PreviewIntegrationChangeSetInput{
+		Desired: &models.UpdateIntegrationSettingsInput{
+			IntegrationID: aws.String(testIntegrationID),
+			Credentials:   testCredentials(),
+		},
+	})
+	require.NoError(t, err)
+	// The values of the credentials are never returned
+	assert.Equal(t, []*models.IntegrationFieldChange{
+		{Field: aws.String("credentials"), Action: aws.String(models.FieldChanged)},
+	}, result.Changes)
+	assert.True(t, *result.HealthCheckRequired)
+}
+
+func TestCredentialsValidation(t *testing.T) {
+	validate, err := models.Validator()
+	require.NoError(t, err)
+
+	// Only the types which pull their logs have credentials
+	settings := validSettings(models.IntegrationTypeAWS3)
+	settings.Credentials = testCredentials()
+	assert.Error(t, validate.Struct(settings))
+
+	for _, credentials := range []map[string]*string{
+		{},
+		{"": aws.String("token-value")},
+		{"apiToken": aws.String("")},
+		{"apiToken": nil},
+	} {
+		settings.Credentials = credentials
+		assert.Error(t, validate.Struct(settings))
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// testNow is a Wednesday at noon
+	cases := []struct {
+		expression string
+		next       time.Time
+	}{
+		{"0 2 * * mon-fri", time.Date(2020, 3, 5, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * sat,sun", time.Date(2020, 3, 7, 2, 0, 0, 0, time.UTC)},
+		// Sunday is also 7
+		{"0 2 * * 7", time.Date(2020, 3, 8, 2, 0, 0, 0, time.UTC)},
+		{"30 */6 * * *", time.Date(2020, 3, 4, 12, 30, 0, 0, time.UTC)},
+		{"15 9-17/4 * * *", time.Date(2020, 3, 4, 13, 15, 0, 0, time.UTC)},
+		// The current minute is not strictly after now
+		{"0 12 * * *", time.Date(2020, 3, 5, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 10 * fri", time.Date(2020, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := models.ParseCronSchedule(c.expression)
+		require.NoError(t, err, c.expression)
+		from := testNow
+		if c.expression == "0 0 29 feb *" {
+			from = time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+		}
+		assert.Equal(t, c.next, schedule.Next(from), c.expression)
+	}
+}
+
+func TestCronScheduleValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	update := func(expression string) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{
+			IntegrationID: aws.String(testIntegrationID),
+			ScanSchedule:  aws.String(expression),
+		}
+	}
+
+	// Empty goes back to the scan interval
+	for _, expression := range []string{"0 2 * * mon-fri", "45 * * * *", "0 0 1 JAN *", "0 0 29 feb *", ""} {
+		assert.NoError(t, validator.Struct(update(expression)), expression)
+	}
+
+	invalid := []string{
+		"0 2 * *", "0 2 * * * *", "60 * * * *", "0 24 * * *", "0 0 0 * *", "0 0 * 13 *", "0 0 * * 8",
+		"0 0 * * fun", "0 5-1 * * *", "0 */0 * * *", "0 0 30 feb *",
+		// More than once an hour
+		"0,30 * * * *", "* 2 * * *", "5/15 1 * * *",
+	}
+	for _, expression := range invalid {
+		assert.Error(t, validator.Struct(update(expression)), expression)
+	}
+}
+
+func cronScheduledIntegration(lastScanEnd time.Time, windows ...*models.BlackoutWindow) *models.SourceIntegration {
+	integration := integrationWithBlackout(lastScanEnd, windows...)
+	integration.ScanSchedule = aws.String("0 2 * * mon-fri")
+	return integration
+}
+
+func TestNextScanTimeCronSchedule(t *testing.T) {
+	// Scanned this morning, the interval of the integration is ignored
+	lastScanEnd := time.Date(2020, 3, 4, 2, 20, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2020, 3, 5, 2, 0, 0, 0, time.UTC), NextScanTime(cronScheduledIntegration(lastScanEnd), testNow))
+
+	// Friday's scan is followed by Monday's
+	friday := time.Date(2020, 3, 6, 2, 20, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2020, 3, 9, 2, 0, 0, 0, time.UTC), NextScanTime(cronScheduledIntegration(friday), friday))
+
+	// A missed scan is due now
+	assert.Equal(t, testNow, NextScanTime(cronScheduledIntegration(lastScanEnd.AddDate(0, 0, -2)), testNow))
+
+	// Blackout windows still postpone the scans
+	window := &models.BlackoutWindow{DailyStart: aws.String("01:30"), DurationMins: aws.Int(90)}
+	assert.Equal(t, time.Date(2020, 3, 5, 3, 0, 0, 0, time.UTC), NextScanTime(cronScheduledIntegration(lastScanEnd, window), testNow))
+}
+
+func TestUpdateIntegrationLastScanEndCronSchedule(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	lastScanEnd := time.Date(2020, 3, 4, 2, 20, 0, 0, time.UTC)
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:   aws.String(testIntegrationID),
+			IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:     aws.Bool(true),
+			ScanSchedule:    aws.String("0 2 * * mon-fri"),
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{LastScanEndTime: aws.Time(lastScanEnd)},
+	})
+	require.NoError(t, err)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+
+	_, err = apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		LastScanEndTime: aws.Time(lastScanEnd),
+		ScanStatus:      aws.String(models.StatusOK),
+	})
+	require.NoError(t, err)
+
+	// Without a scan interval, the next scan is still scheduled
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 2)
+	refresh := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var values []string
+	for _, value := range refresh.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.Contains(t, values, "2020-03-05T02:00:00Z")
+}
@@ -0,0 +1,80 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The client used to check the delivery of CloudWatch events assumes the CWE role of the account
+var cweSNSClientFunc = func(roleCredentials *credentials.Credentials) snsiface.SNSAPI {
+	return sns.New(sess, &aws.Config{Credentials: roleCredentials})
+}
+
+// checkEventDelivery returns an error unless Panther receives the CloudWatch events of the account.
+//
+// The CloudWatch events stack of the account forwards the events to a topic the queue of the AWS event
+// processor is subscribed to. Without that subscription, enabling CWE would silently leave the integration
+// without events. Only the region of the source API is checked. A listing which fails transiently is a
+// transientError, the delivery is verified again in the background.
+func checkEventDelivery(accountID *string) error {
+	if awsEventsQueueArn == "" {
+		return nil
+	}
+	roleCredentials, status := getCredentialsWithStatus(zap.L(), aws.String(fmt.Sprintf(cweRoleFormat, *accountID)))
+	if !aws.BoolValue(status.Healthy) {
+		return &genericapi.InvalidInputError{
+			Message: "cweEnabled: the CloudWatch events role of the account can't be assumed: " + aws.StringValue(status.ErrorMessage)}
+	}
+
+	subscribed := false
+	err := cweSNSClientFunc(roleCredentials).ListSubscriptionsPages(&sns.ListSubscriptionsInput{},
+		func(page *sns.ListSubscriptionsOutput, _ bool) bool {
+			for _, subscription := range page.Subscriptions {
+				// A subscription which isn't confirmed yet doesn't deliver anything
+				if aws.StringValue(subscription.Endpoint) == awsEventsQueueArn &&
+					aws.StringValue(subscription.SubscriptionArn) != "PendingConfirmation" {
+
+					subscribed = true
+					return false
+				}
+			}
+			return true
+		})
+	if err != nil && isTransientError(err) {
+		return &transientError{sideEffect: sideEffectCWE, message: "cweEnabled: the subscriptions of the account can't be listed: " + err.Error()}
+	}
+	if err != nil {
+		return &genericapi.InvalidInputError{
+			Message: "cweEnabled: the subscriptions of the account can't be listed: " + err.Error()}
+	}
+	if !subscribed {
+		return &genericapi.InvalidInputError{Message: "cweEnabled: no topic of account " + *accountID +
+			" forwards its CloudWatch events to Panther, deploy the CloudWatch events stack first"}
+	}
+	return nil
+}
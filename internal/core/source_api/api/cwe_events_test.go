@@ -0,0 +1,148 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testAWSEventsQueueArn = "arn:aws:sqs:us-west-2:111111111111:panther-aws-events-queue"
+
+type mockSNSClient struct {
+	snsiface.SNSAPI
+	subscriptions []*sns.Subscription
+	err           error
+}
+
+func (client *mockSNSClient) ListSubscriptionsPages(
+	_ *sns.ListSubscriptionsInput, fn func(*sns.ListSubscriptionsOutput, bool) bool) error {
+
+	if client.err != nil {
+		return client.err
+	}
+	fn(&sns.ListSubscriptionsOutput{Subscriptions: client.subscriptions}, true)
+	return nil
+}
+
+// mockEventDelivery sets up a healthy CWE role and the subscriptions of the account.
+func mockEventDelivery(t *testing.T, subscriptions ...*sns.Subscription) *mockSNSClient {
+	previousArn := awsEventsQueueArn
+	awsEventsQueueArn = testAWSEventsQueueArn
+	t.Cleanup(func() { awsEventsQueueArn = previousArn })
+
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+	mockSNS := &mockSNSClient{subscriptions: subscriptions}
+	cweSNSClientFunc = func(_ *credentials.Credentials) snsiface.SNSAPI { return mockSNS }
+	return mockSNS
+}
+
+func eventsSubscription(subscriptionArn string) *sns.Subscription {
+	return &sns.Subscription{
+		Endpoint:        aws.String(testAWSEventsQueueArn),
+		Protocol:        aws.String("sqs"),
+		SubscriptionArn: aws.String(subscriptionArn),
+	}
+}
+
+func TestCheckEventDelivery(t *testing.T) {
+	mockSNS := mockEventDelivery(t, eventsSubscription("arn:aws:sns:us-west-2:123456789012:panther-events:1"))
+	assert.NoError(t, checkEventDelivery(aws.String(testAccountID)))
+
+	mockSNS.subscriptions = []*sns.Subscription{
+		eventsSubscription("PendingConfirmation"),
+		{
+			Endpoint:        aws.String("arn:aws:sqs:us-west-2:123456789012:other"),
+			SubscriptionArn: aws.String("arn:aws:sns:us-west-2:123456789012:other:1"),
+		},
+	}
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "cweEnabled: no topic of account " + testAccountID +
+		" forwards its CloudWatch events to Panther, deploy the CloudWatch events stack first"}, checkEventDelivery(aws.String(testAccountID)))
+
+	mockSNS.err = awserr.New("AccessDenied", "not authorized", nil)
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "cweEnabled: the subscriptions of the account can't be listed: AccessDenied: not authorized"},
+		checkEventDelivery(aws.String(testAccountID)))
+
+	// A throttled listing is verified again in the background
+	mockSNS.err = awserr.New("Throttling", "rate exceeded", nil)
+	err := checkEventDelivery(aws.String(testAccountID))
+	require.IsType(t, &transientError{}, err)
+	assert.Equal(t, sideEffectCWE, err.(*transientError).sideEffect)
+}
+
+func TestReconcileEventDelivery(t *testing.T) {
+	mockSNS := mockEventDelivery(t, eventsSubscription("arn:aws:sns:us-west-2:123456789012:panther-events:1"))
+	integration := &models.SourceIntegrationMetadata{
+		AWSAccountID:  aws.String(testAccountID),
+		IntegrationID: aws.String(testIntegrationID),
+		CWEEnabled:    aws.Bool(true),
+	}
+	mockClient := mockStoredIntegration(t, integration)
+	require.NoError(t, reconcileEventDelivery(integration))
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+
+	// The account doesn't deliver its events after all, CWE is disabled again
+	mockSNS.subscriptions = nil
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	require.NoError(t, reconcileEventDelivery(integration))
+	mockClient.AssertCalled(t, "UpdateItem", mock.Anything)
+	assert.Contains(t, retryableSideEffects, sideEffectCWE)
+}
+
+func TestUpdateIntegrationSettingsEnableCWE(t *testing.T) {
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockSNS := mockEventDelivery(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"cweEnabled": {BOOL: aws.Bool(true)},
+	}}, nil)
+	input := &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID), CWEEnabled: aws.Bool(true)}
+
+	// The event bus of the account isn't forwarding to Panther: nothing is written
+	_, err := apiTest.UpdateIntegrationSettings(input)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+
+	mockSNS.subscriptions = []*sns.Subscription{eventsSubscription("arn:aws:sns:us-west-2:123456789012:panther-events:1")}
+	result, err := apiTest.UpdateIntegrationSettings(input)
+	require.NoError(t, err)
+	assert.True(t, *result.CWEEnabled)
+	mockClient.AssertCalled(t, "UpdateItem", mock.Anything)
+}
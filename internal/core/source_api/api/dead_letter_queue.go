@@ -0,0 +1,96 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The client used to check a dead letter queue assumes the log processing role, in the region of the queue
+var deadLetterQueueClientFunc = func(roleCredentials *credentials.Credentials, region string) sqsiface.SQSAPI {
+	return sqs.New(sess, &aws.Config{Credentials: roleCredentials, Region: aws.String(region)})
+}
+
+// checkDeadLetterQueue verifies the role can reach the dead letter queue of an integration, and that it's a
+// standard queue: the notifications are moved from a standard queue, which can't have a FIFO dead letter queue.
+//
+// Nothing is sent to the queue, it would show up as a failed notification. The role is rather expected to be
+// granted sqs:SendMessage along with sqs:GetQueueUrl and sqs:GetQueueAttributes, like the policy document of
+// the integration does.
+func checkDeadLetterQueue(
+	logger *zap.Logger, roleCredentials *credentials.Credentials, queueArn *string) models.SourceIntegrationItemStatus {
+
+	start := time.Now()
+	failed := func(err error) models.SourceIntegrationItemStatus {
+		if isThrottlingError(err) {
+			logger.Warn("dead letter queue check throttled", zap.String("queue", *queueArn), zap.Error(err))
+			return models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		}
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	// The ARN is validated with the input
+	parsedArn, _ := arn.Parse(*queueArn)
+	sqsClient := deadLetterQueueClientFunc(roleCredentials, parsedArn.Region)
+
+	queue, err := sqsClient.GetQueueUrl(&sqs.GetQueueUrlInput{
+		QueueName:              aws.String(parsedArn.Resource),
+		QueueOwnerAWSAccountId: aws.String(parsedArn.AccountID),
+	})
+	if err != nil {
+		return failed(err)
+	}
+	attributes, err := sqsClient.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       queue.QueueUrl,
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameFifoQueue}),
+	})
+	if err != nil {
+		return failed(err)
+	}
+	if aws.StringValue(attributes.Attributes[sqs.QueueAttributeNameFifoQueue]) == "true" {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String("the dead letter queue of a standard queue can't be a FIFO queue"),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	return models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(true),
+		LatencyMillis: millisSince(start),
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testDeadLetterQueueArn = "arn:aws:sqs:eu-west-1:123456789012:panther-failed-notifications"
+	testDeadLetterQueueURL = "https://sqs.eu-west-1.amazonaws.com/123456789012/panther-failed-notifications"
+)
+
+func (client *mockSQSClient) GetQueueUrl(input *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*sqs.GetQueueUrlOutput), args.Error(1)
+}
+
+// mockDeadLetterQueue sets up a healthy log processing role and the client of the region of the queue.
+func mockDeadLetterQueue() *mockSQSClient {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+	mockSQS := &mockSQSClient{}
+	deadLetterQueueClientFunc = func(_ *credentials.Credentials, region string) sqsiface.SQSAPI {
+		if region != "eu-west-1" {
+			panic("the queue is checked in its own region")
+		}
+		return mockSQS
+	}
+	return mockSQS
+}
+
+func deadLetterQueueCheckInput() *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		AWSAccountID:       aws.String(testAccountID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWS3),
+		DeadLetterQueueArn: aws.String(testDeadLetterQueueArn),
+	}
+}
+
+func TestDeadLetterQueueValidation(t *testing.T) {
+	validate, err := models.Validator()
+	require.NoError(t, err)
+
+	settings := validSettings(models.IntegrationTypeAWS3)
+	settings.DeadLetterQueue = &models.DeadLetterQueue{QueueArn: aws.String(testDeadLetterQueueArn), MaxReceiveCount: aws.Int(5)}
+	assert.NoError(t, validate.Struct(settings))
+
+	for _, queueArn := range []string{
+		"panther-failed-notifications",
+		testStreamARN,
+		"arn:aws:sqs:eu-west-1:123456789012:",
+		"arn:aws:sqs::123456789012:panther-failed-notifications",
+	} {
+		settings.DeadLetterQueue.QueueArn = aws.String(queueArn)
+		assert.Error(t, validate.Struct(settings), queueArn)
+	}
+
+	settings.DeadLetterQueue.QueueArn = aws.String(testDeadLetterQueueArn)
+	for _, count := range []int{0, 1001} {
+		settings.DeadLetterQueue.MaxReceiveCount = aws.Int(count)
+		assert.Error(t, validate.Struct(settings), count)
+	}
+}
+
+func TestCheckDeadLetterQueueSettings(t *testing.T) {
+	complete := &models.DeadLetterQueue{QueueArn: aws.String(testDeadLetterQueueArn), MaxReceiveCount: aws.Int(5)}
+	assert.NoError(t, checkDeadLetterQueueSettings(aws.String(models.IntegrationTypeAWS3), complete))
+	// An empty queue removes it
+	assert.NoError(t, checkDeadLetterQueueSettings(aws.String(models.IntegrationTypeAWSScan), &models.DeadLetterQueue{}))
+
+	err := checkDeadLetterQueueSettings(aws.String(models.IntegrationTypeAWSKinesis), complete)
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "only the notifications of log analysis integrations have a dead letter queue"}, err)
+	err = checkDeadLetterQueueSettings(aws.String(models.IntegrationTypeAWS3), &models.DeadLetterQueue{QueueArn: complete.QueueArn})
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "deadLetterQueue: both queueArn and maxReceiveCount are required"}, err)
+}
+
+func TestCheckIntegrationDeadLetterQueue(t *testing.T) {
+	mockSQS := mockDeadLetterQueue()
+	mockSQS.On("GetQueueUrl", &sqs.GetQueueUrlInput{
+		QueueName:              aws.String("panther-failed-notifications"),
+		QueueOwnerAWSAccountId: aws.String(testAccountID),
+	}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String(testDeadLetterQueueURL)}, nil)
+	mockSQS.On("GetQueueAttributes", &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(testDeadLetterQueueURL),
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameFifoQueue}),
+	}).Return(&sqs.GetQueueAttributesOutput{}, nil)
+
+	result, err := apiTest.CheckIntegration(deadLetterQueueCheckInput())
+	require.NoError(t, err)
+	assert.True(t, *result.DeadLetterQueueStatus.Healthy)
+	mockSQS.AssertExpectations(t)
+
+	passing, _, err := evaluateIntegration(apiTest, deadLetterQueueCheckInput())
+	require.NoError(t, err)
+	assert.True(t, passing)
+}
+
+func TestCheckIntegrationDeadLetterQueueDenied(t *testing.T) {
+	mockSQS := mockDeadLetterQueue()
+	mockSQS.On("GetQueueUrl", mock.Anything).Return(&sqs.GetQueueUrlOutput{},
+		awserr.New("AccessDenied", "not authorized to perform: sqs:getqueueurl", nil))
+
+	result, err := apiTest.CheckIntegration(deadLetterQueueCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.DeadLetterQueueStatus.Healthy)
+	assert.Nil(t, result.DeadLetterQueueStatus.Inconclusive)
+	assert.Contains(t, *result.DeadLetterQueueStatus.ErrorMessage, "AccessDenied")
+	mockSQS.AssertNotCalled(t, "GetQueueAttributes", mock.Anything)
+
+	eval, err := evaluateIntegrationHealth(apiTest, deadLetterQueueCheckInput())
+	require.NoError(t, err)
+	assert.False(t, eval.passing())
+	assert.Equal(t, []*string{aws.String("deadLetterQueue:" + testDeadLetterQueueArn)}, eval.failedItems)
+}
+
+func TestCheckIntegrationDeadLetterQueueFifo(t *testing.T) {
+	mockSQS := mockDeadLetterQueue()
+	mockSQS.On("GetQueueUrl", mock.Anything).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String(testDeadLetterQueueURL)}, nil)
+	mockSQS.On("GetQueueAttributes", mock.Anything).Return(&sqs.GetQueueAttributesOutput{
+		Attributes: map[string]*string{sqs.QueueAttributeNameFifoQueue: aws.String("true")},
+	}, nil)
+
+	result, err := apiTest.CheckIntegration(deadLetterQueueCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.DeadLetterQueueStatus.Healthy)
+	assert.Equal(t, "the dead letter queue of a standard queue can't be a FIFO queue", *result.DeadLetterQueueStatus.ErrorMessage)
+}
+
+func TestUpdateIntegrationSettingsDeadLetterQueue(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	var checked *models.CheckIntegrationInput
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = input
+		return true, nil, nil
+	}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		DeadLetterQueue: &models.DeadLetterQueue{QueueArn: aws.String(testDeadLetterQueueArn), MaxReceiveCount: aws.Int(5)},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, checked)
+	assert.Equal(t, testDeadLetterQueueArn, *checked.DeadLetterQueueArn)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+		"queueArn":        {S: aws.String(testDeadLetterQueueArn)},
+		"maxReceiveCount": {N: aws.String("5")},
+	}})
+}
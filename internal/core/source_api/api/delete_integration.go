@@ -19,6 +19,9 @@ package api
  */
 
 import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
@@ -26,15 +29,23 @@ import (
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
+// How long a soft-deleted integration can be restored when the deletion doesn't say
+const defaultSoftDeleteRetentionDays = 7
+
 // DeleteIntegration deletes a specific integration.
-func (API) DeleteIntegration(input *models.DeleteIntegrationInput) (err error) {
+//
+// A soft-deleted integration loses its access like a deleted one, but keeps its settings so that it can be
+// restored by RestoreIntegration until its retention expires.
+func (api API) DeleteIntegration(input *models.DeleteIntegrationInput) (err error) {
+	api = api.withIntegration(input.IntegrationID)
+
 	var integrationForDeletePermissions *models.SourceIntegrationMetadata
 	defer func() {
 		if err != nil && integrationForDeletePermissions != nil {
 			// In case we have already removed the Permissions from SQS but some other operation failed
 			// re-add the permissions
 			if undoErr := AddPermissionToLogProcessorQueue(*integrationForDeletePermissions.AWSAccountID); undoErr != nil {
-				zap.L().Error("failed to re-add SQS permission for integration. SQS is missing permissions that have to be added manually",
+				api.log().Error("failed to re-add SQS permission for integration. SQS is missing permissions that have to be added manually",
 					zap.String("integrationId", *integrationForDeletePermissions.IntegrationID),
 					zap.Error(undoErr),
 					zap.Error(err))
@@ -43,10 +54,10 @@ func (API) DeleteIntegration(input *models.DeleteIntegrationInput) (err error) {
 	}()
 
 	var integration *models.SourceIntegrationMetadata
-	integration, err = db.GetIntegration(input.IntegrationID)
+	integration, err = db.GetIntegration(input.IntegrationID, false)
 	if err != nil {
 		errMsg := "failed to get integration"
-		zap.L().Error(errMsg,
+		api.log().Error(errMsg,
 			zap.String("integrationId", *input.IntegrationID),
 			zap.Error(errors.Wrap(err, errMsg)))
 		return &genericapi.InternalError{Message: errMsg}
@@ -56,15 +67,44 @@ func (API) DeleteIntegration(input *models.DeleteIntegrationInput) (err error) {
 		return &genericapi.DoesNotExistError{Message: "Integration does not exist"}
 	}
 
+	if err = checkDependents(input); err != nil {
+		return err
+	}
+
 	if *integration.IntegrationType == models.IntegrationTypeAWS3 {
 		if err = RemovePermissionFromLogProcessorQueue(*integration.AWSAccountID); err != nil {
-			zap.L().Error("failed to remove permission from SQS queue for integration",
+			api.log().Error("failed to remove permission from SQS queue for integration",
 				zap.String("integrationId", *input.IntegrationID),
 				zap.Error(errors.Wrap(err, "failed to remove permission from SQS queue for integration")))
 			return &genericapi.InternalError{Message: "failed to update integration"}
 		}
 		integrationForDeletePermissions = integration
 	}
-	err = db.DeleteIntegrationItem(input)
-	return err
+	if aws.BoolValue(input.SoftDelete) {
+		retention := defaultSoftDeleteRetentionDays
+		if input.RetentionDays != nil {
+			retention = *input.RetentionDays
+		}
+		err = db.SoftDeleteIntegrationItem(input.IntegrationID, time.Now().AddDate(0, 0, retention))
+	} else {
+		err = db.DeleteIntegrationItem(input)
+	}
+	if err != nil {
+		return err
+	}
+	retireIntegrationKmsGrants(integration)
+	if *integration.IntegrationType == models.IntegrationTypeSQSQueue {
+		removeSQSQueue(integration)
+	}
+	if *integration.IntegrationType == models.IntegrationTypeHTTPIngest {
+		deleteHTTPIngestKey(integration.IntegrationID, integration.HTTPIngestAPIKeyID)
+	}
+	if integration.KinesisConsumerARN != nil {
+		deregisterKinesisConsumer(integration, integration.KinesisConsumerARN)
+	}
+	// The credentials can't be provided again by a restore, they are kept until the settings are purged
+	if !aws.BoolValue(input.SoftDelete) {
+		deleteCredentials(integration, false)
+	}
+	return recordChange(input.IntegrationID, models.ChangeOperationDeleted, input.UserID, make([]*models.IntegrationFieldChange, 0))
 }
@@ -44,6 +44,7 @@ import (
 type mockDDBClient struct {
 	dynamodbiface.DynamoDBAPI
 	mock.Mock
+	scanItems []map[string]*dynamodb.AttributeValue
 }
 
 func (client *mockDDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
@@ -51,11 +52,20 @@ func (client *mockDDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.Ge
 	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
 }
 
+func (client *mockDDBClient) Scan(*dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return &dynamodb.ScanOutput{Items: client.scanItems}, nil
+}
+
 func (client *mockDDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
 	args := client.Called(input)
 	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
 }
 
+// PutItem accepts the change records of the deleted integrations.
+func (client *mockDDBClient) PutItem(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return &dynamodb.PutItemOutput{}, nil
+}
+
 func TestDeleteIntegrationItem(t *testing.T) {
 	mockClient := &mockDDBClient{}
 	db = &ddb.DDB{Client: mockClient, TableName: "test"}
@@ -0,0 +1,180 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// mockDryRunHealthCheck fails the health check of the "bad" bucket, and fails the test if anything is queued.
+func mockDryRunHealthCheck(t *testing.T) *mockSQSClient {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, errors.New("access denied"))
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+
+	evaluateHealthFunc := evaluateIntegrationHealthFunc
+	t.Cleanup(func() { evaluateIntegrationHealthFunc = evaluateHealthFunc })
+	evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+
+	mockSQS := &mockSQSClient{}
+	SQSClient = mockSQS
+	return mockSQS
+}
+
+func TestPutIntegrationDryRun(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := mockDryRunHealthCheck(t)
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:     aws.String(testAccountID),
+				IntegrationLabel: aws.String(testIntegrationLabel),
+				IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+				S3Buckets:        aws.StringSlice([]string{"bad"}),
+				UserID:           aws.String(testUserID),
+			},
+		},
+		DryRun: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+
+	// The failed bucket fails the write, the dry run reports it instead
+	report := out[0].DryRunReport
+	require.NotNil(t, report)
+	assert.Equal(t, models.HealthStatusUnhealthy, *report.HealthStatus)
+	assert.Equal(t, aws.StringSlice([]string{"s3Bucket:bad"}), report.FailedHealthChecks)
+	assert.False(t, *report.Health.S3BucketsStatus["bad"].Healthy)
+	assert.True(t, *report.Health.ProcessingRoleStatus.Healthy)
+	assert.Empty(t, mockClient.written)
+	mockSQS.AssertNotCalled(t, "AddPermission", mock.Anything)
+	mockSQS.AssertNotCalled(t, "SendMessageBatch", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsDryRun(t *testing.T) {
+	mockBucketOwnership(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		HealthStatus:    aws.String(models.HealthStatusHealthy),
+		S3Buckets:       aws.StringSlice([]string{"good"}),
+	})
+	mockDryRunHealthCheck(t)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		S3Buckets:          aws.StringSlice([]string{"bad"}),
+		AllowPartialHealth: aws.Bool(true),
+		DryRun:             aws.Bool(true),
+	})
+	require.NoError(t, err)
+
+	// The stored integration is returned as it is, along with what the update would do
+	assert.Equal(t, []string{"good"}, aws.StringValueSlice(result.S3Buckets))
+	assert.Equal(t, models.HealthStatusHealthy, *result.HealthStatus)
+	report := result.DryRunReport
+	assert.Equal(t, models.HealthStatusDegraded, *report.HealthStatus)
+	assert.Equal(t, aws.StringSlice([]string{"s3Bucket:bad"}), report.FailedHealthChecks)
+	assert.NotNil(t, report.Health)
+	assert.Len(t, report.Changes, 2)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsDryRunUncheckedSettings(t *testing.T) {
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWS3),
+		HealthStatus:       aws.String(models.HealthStatusDegraded),
+		FailedHealthChecks: aws.StringSlice([]string{"s3Bucket:bad"}),
+	})
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("team logs"),
+		DryRun:        aws.Bool(true),
+	})
+	require.NoError(t, err)
+	expected := &models.DryRunReport{
+		HealthStatus:       aws.String(models.HealthStatusDegraded),
+		FailedHealthChecks: aws.StringSlice([]string{"s3Bucket:bad"}),
+		Changes: []*models.IntegrationFieldChange{
+			{Field: aws.String("description"), Action: aws.String(models.FieldAdded), Desired: "team logs"},
+		},
+	}
+	assert.Equal(t, expected, result.DryRunReport)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestTransactUpdateIntegrationsDryRun(t *testing.T) {
+	result, err := apiTest.TransactUpdateIntegrations(&models.TransactUpdateIntegrationsInput{
+		Updates: []*models.UpdateIntegrationSettingsInput{
+			{IntegrationID: aws.String(testIntegrationID), ScanEnabled: aws.Bool(false), DryRun: aws.Bool(true)},
+		},
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestDryRunHealthChecksOnce(t *testing.T) {
+	evaluateHealthFunc := evaluateIntegrationHealthFunc
+	t.Cleanup(func() { evaluateIntegrationHealthFunc = evaluateHealthFunc })
+	evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+	checks := 0
+	healthCheckOverride = func(input *models.CheckIntegrationInput) *models.SourceIntegrationHealth {
+		checks++
+		return &models.SourceIntegrationHealth{
+			IntegrationType:      input.IntegrationType,
+			ProcessingRoleStatus: models.SourceIntegrationItemStatus{Healthy: aws.Bool(true)},
+			S3BucketsStatus: map[string]models.SourceIntegrationItemStatus{
+				"bad": {Healthy: aws.Bool(false), ErrorMessage: aws.String("AccessDenied")},
+			},
+		}
+	}
+	t.Cleanup(func() { healthCheckOverride = nil })
+
+	report, err := dryRunHealth(apiTest, &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bad"}),
+	}, true)
+	require.NoError(t, err)
+
+	// The report is made from the same health check as the evaluation
+	assert.Equal(t, 1, checks)
+	assert.Equal(t, models.HealthStatusDegraded, *report.HealthStatus)
+	assert.False(t, *report.Health.S3BucketsStatus["bad"].Healthy)
+}
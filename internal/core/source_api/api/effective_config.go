@@ -0,0 +1,133 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetEffectiveConfig returns the resolved behavior of an integration, the same way the scheduler resolves it.
+func (API) GetEffectiveConfig(input *models.GetEffectiveConfigInput) (*models.EffectiveIntegrationConfig, error) {
+	integration, err := db.GetSourceIntegration(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil || integration.SourceIntegrationMetadata == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	now := time.Now()
+	if input.Now != nil {
+		now = *input.Now
+	}
+	return effectiveConfig(integration, now), nil
+}
+
+func effectiveConfig(integration *models.SourceIntegration, now time.Time) *models.EffectiveIntegrationConfig {
+	config := &models.EffectiveIntegrationConfig{
+		IntegrationID:       integration.IntegrationID,
+		IntegrationType:     integration.IntegrationType,
+		Scheduled:           aws.Bool(scheduled(integration.SourceIntegrationMetadata)),
+		ScanIntervalMins:    integration.ScanIntervalMins,
+		RampingUp:           aws.Bool(false),
+		PostponedByBlackout: aws.Bool(false),
+		Features:            effectiveFeatures(integration.SourceIntegrationMetadata),
+	}
+
+	if integration.ScanIntervalMins != nil {
+		interval := currentScanIntervalMins(integration)
+		config.EffectiveScanIntervalMins = aws.Int(interval)
+		config.RampingUp = aws.Bool(interval < *integration.ScanIntervalMins)
+	}
+	if *config.Scheduled {
+		next := NextScanTime(integration, now)
+		config.NextScanTime = aws.Time(next)
+
+		withoutBlackouts := *integration.SourceIntegrationMetadata
+		withoutBlackouts.BlackoutWindows = nil
+		due := NextScanTime(&models.SourceIntegration{
+			SourceIntegrationMetadata:        &withoutBlackouts,
+			SourceIntegrationScanInformation: integration.SourceIntegrationScanInformation,
+		}, now)
+		config.PostponedByBlackout = aws.Bool(next.After(due))
+	}
+
+	if integration.MaxRecordBytes != nil {
+		config.OversizedRecordPolicy = integration.OversizedRecordPolicy
+		if config.OversizedRecordPolicy == nil {
+			config.OversizedRecordPolicy = aws.String(models.OversizedRecordDrop)
+		}
+	}
+	if aws.StringValue(integration.IntegrationType) == models.IntegrationTypeAWSKinesis {
+		config.ShardIteratorType = integration.ShardIteratorType
+		if config.ShardIteratorType == nil {
+			config.ShardIteratorType = aws.String(models.ShardIteratorTrimHorizon)
+		}
+	}
+	return config
+}
+
+// scheduled returns true if the scheduler scans the integration, see refreshScanSchedule.
+func scheduled(integration *models.SourceIntegrationMetadata) bool {
+	if !aws.BoolValue(integration.ScanEnabled) || !hasScanSchedule(integration) {
+		return false
+	}
+	for _, integrationType := range scheduledIntegrationTypes {
+		if aws.StringValue(integration.IntegrationType) == integrationType {
+			return true
+		}
+	}
+	return false
+}
+
+// effectiveFeatures lists the enabled feature flags, and the optional behaviors which are on by their setting.
+func effectiveFeatures(integration *models.SourceIntegrationMetadata) []*string {
+	features := make([]string, 0, len(integration.FeatureFlags))
+	for flag := range integration.FeatureFlags {
+		if integration.FeatureEnabled(flag) {
+			features = append(features, flag)
+		}
+	}
+	for setting, on := range map[string]bool{
+		"cweEnabled":         aws.BoolValue(integration.CWEEnabled),
+		"remediationEnabled": aws.BoolValue(integration.RemediationEnabled),
+		"createKmsGrants":    aws.BoolValue(integration.CreateKmsGrants),
+		"isOrgTrail":         aws.BoolValue(integration.IsOrgTrail),
+		"redactionRules":     len(integration.RedactionRules) > 0,
+		"includePatterns":    len(integration.IncludePatterns) > 0,
+		"excludePatterns":    len(integration.ExcludePatterns) > 0,
+		"dedupWindowMinutes": integration.DedupWindowMinutes != nil,
+		"sampleRate":         integration.SampleRate != nil && *integration.SampleRate < 1,
+		"timeExtraction":     integration.TimeExtraction != nil,
+		"timestampSkew":      integration.TimestampSkew != nil,
+		"deadLetterQueue":    integration.DeadLetterQueue != nil,
+		"archiveFormat":      integration.ArchiveFormat != nil,
+	} {
+		if on {
+			features = append(features, setting)
+		}
+	}
+	sort.Strings(features)
+	return aws.StringSlice(features)
+}
@@ -0,0 +1,143 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func mockStoredSourceIntegration(t *testing.T, integration *models.SourceIntegration) {
+	item, err := dynamodbattribute.MarshalMap(integration)
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+}
+
+func TestGetEffectiveConfig(t *testing.T) {
+	lastScanEnd := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	mockStoredSourceIntegration(t, &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String(testIntegrationID),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+			CWEEnabled:       aws.Bool(true),
+			ScanIntervalMins: aws.Int(1440),
+			// The first scans are hourly
+			ScanRampUp: &models.ScanRampUp{InitialIntervalMins: aws.Int(60), Scans: aws.Int(4)},
+			// The scan due at 01:00 falls in this window
+			BlackoutWindows: []*models.BlackoutWindow{{DailyStart: aws.String("00:30"), DurationMins: aws.Int(60)}},
+			FeatureFlags:    map[string]bool{models.FeatureFlagParserV2: true, models.FeatureFlagBetaEnrichment: false},
+			MaxRecordBytes:  aws.Int(1024),
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			LastScanEndTime: aws.Time(lastScanEnd),
+			CompletedScans:  aws.Int(1),
+		},
+	})
+
+	result, err := apiTest.GetEffectiveConfig(&models.GetEffectiveConfigInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Now:           aws.Time(lastScanEnd.Add(45 * time.Minute)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.EffectiveIntegrationConfig{
+		IntegrationID:             aws.String(testIntegrationID),
+		IntegrationType:           aws.String(models.IntegrationTypeAWSScan),
+		Scheduled:                 aws.Bool(true),
+		ScanIntervalMins:          aws.Int(1440),
+		EffectiveScanIntervalMins: aws.Int(60),
+		RampingUp:                 aws.Bool(true),
+		NextScanTime:              aws.Time(lastScanEnd.Add(90 * time.Minute)),
+		PostponedByBlackout:       aws.Bool(true),
+		Features:                  aws.StringSlice([]string{"cweEnabled", models.FeatureFlagParserV2}),
+		OversizedRecordPolicy:     aws.String(models.OversizedRecordDrop),
+	}, result)
+}
+
+func TestGetEffectiveConfigAfterRampUp(t *testing.T) {
+	lastScanEnd := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	mockStoredSourceIntegration(t, &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String(testIntegrationID),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+			ScanIntervalMins: aws.Int(1440),
+			ScanRampUp:       &models.ScanRampUp{InitialIntervalMins: aws.Int(60), Scans: aws.Int(4)},
+			BlackoutWindows:  []*models.BlackoutWindow{{DailyStart: aws.String("00:30"), DurationMins: aws.Int(60)}},
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			LastScanEndTime: aws.Time(lastScanEnd),
+			CompletedScans:  aws.Int(5),
+		},
+	})
+
+	result, err := apiTest.GetEffectiveConfig(&models.GetEffectiveConfigInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Now:           aws.Time(lastScanEnd.Add(45 * time.Minute)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1440, *result.EffectiveScanIntervalMins)
+	assert.False(t, *result.RampingUp)
+	// Due a day later at midnight, before the daily blackout window
+	assert.Equal(t, lastScanEnd.Add(24*time.Hour), *result.NextScanTime)
+	assert.False(t, *result.PostponedByBlackout)
+	assert.Empty(t, result.Features)
+}
+
+func TestGetEffectiveConfigStreamDefaults(t *testing.T) {
+	mockStoredSourceIntegration(t, &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:   aws.String(testIntegrationID),
+			IntegrationType: aws.String(models.IntegrationTypeAWSKinesis),
+			StreamARN:       aws.String(testStreamARN),
+		},
+	})
+
+	result, err := apiTest.GetEffectiveConfig(&models.GetEffectiveConfigInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.False(t, *result.Scheduled)
+	assert.Nil(t, result.NextScanTime)
+	assert.Nil(t, result.EffectiveScanIntervalMins)
+	assert.Equal(t, models.ShardIteratorTrimHorizon, *result.ShardIteratorType)
+	assert.Nil(t, result.OversizedRecordPolicy)
+}
+
+func TestGetEffectiveConfigDoesNotExist(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := apiTest.GetEffectiveConfig(&models.GetEffectiveConfigInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
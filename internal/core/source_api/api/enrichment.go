@@ -0,0 +1,53 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkEnrichmentSources returns an error unless every source is one of the configured enrichment sources.
+func checkEnrichmentSources(sources []*string) error {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	configured := make(map[string]struct{})
+	for _, name := range strings.Split(enrichmentSources, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			configured[name] = struct{}{}
+		}
+	}
+
+	var unknown []string
+	for _, source := range sources {
+		if _, ok := configured[*source]; !ok {
+			unknown = append(unknown, *source)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &genericapi.InvalidInputError{
+			Message: "unknown enrichment sources: " + strings.Join(unknown, ", ")}
+	}
+	return nil
+}
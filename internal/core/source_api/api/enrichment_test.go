@@ -0,0 +1,75 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestCheckEnrichmentSources(t *testing.T) {
+	enrichmentSources = "ip-reputation, asset-inventory"
+	defer func() { enrichmentSources = "" }()
+
+	assert.NoError(t, checkEnrichmentSources(nil))
+	assert.NoError(t, checkEnrichmentSources(aws.StringSlice([]string{"asset-inventory", "ip-reputation"})))
+	err := checkEnrichmentSources(aws.StringSlice([]string{"ip-reputation", "whois", "geo"}))
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Equal(t, "unknown enrichment sources: geo, whois", err.(*genericapi.InvalidInputError).Message)
+}
+
+func TestUpdateIntegrationSettingsEnrichmentSources(t *testing.T) {
+	enrichmentSources = "ip-reputation,asset-inventory"
+	defer func() { enrichmentSources = "" }()
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"enrichmentSources": {L: []*dynamodb.AttributeValue{{S: aws.String("ip-reputation")}}},
+	}}, nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:     aws.String(testIntegrationID),
+		EnrichmentSources: aws.StringSlice([]string{"ip-reputation"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"ip-reputation"}), result.EnrichmentSources)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "enrichmentSources")
+
+	_, err = apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:     aws.String(testIntegrationID),
+		EnrichmentSources: aws.StringSlice([]string{"whois"}),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
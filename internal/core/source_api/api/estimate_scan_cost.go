@@ -0,0 +1,121 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// At most this many pages of 1000 objects are listed in each bucket
+	maxEstimatePages = 10
+
+	// Published S3 prices in USD, used only for a rough estimate
+	listRequestPrice = 0.005 / 1000
+	getRequestPrice  = 0.0004 / 1000
+
+	lowCostObjectsPerDay    = 100000
+	mediumCostObjectsPerDay = 10000000
+)
+
+// EstimateScanCost returns an approximate cost of scanning the buckets at the proposed interval.
+//
+// The buckets are sampled with the log processing role. The estimate is advisory only: it ignores
+// data transfer, KMS and processing costs, and objects beyond the sampled listing.
+func (API) EstimateScanCost(input *models.EstimateScanCostInput) (*models.ScanCostEstimate, error) {
+	roleArn := fmt.Sprintf(logProcessingRoleFormat, *input.AWSAccountID)
+	s3Client := s3ClientFunc(stscreds.NewCredentials(sess, roleArn))
+
+	var objects, bytes, listRequests int64
+	truncated := false
+	for _, bucket := range input.S3Buckets {
+		sample, err := sampleBucket(s3Client, *bucket)
+		if err != nil {
+			return nil, &genericapi.InvalidInputError{Message: fmt.Sprintf("failed to list bucket %s: %s", *bucket, err)}
+		}
+		objects += sample.objects
+		bytes += sample.bytes
+		listRequests += sample.pages
+		truncated = truncated || sample.truncated
+	}
+
+	scansPerDay := int64(24 * 60 / *input.ScanIntervalMins)
+	objectsPerDay := objects * scansPerDay
+	monthlyCost := float64(listRequests*scansPerDay*30)*listRequestPrice + float64(objectsPerDay*30)*getRequestPrice
+
+	costBand := "high"
+	switch {
+	case objectsPerDay < lowCostObjectsPerDay:
+		costBand = "low"
+	case objectsPerDay < mediumCostObjectsPerDay:
+		costBand = "medium"
+	}
+
+	return &models.ScanCostEstimate{
+		Approximate:           aws.Bool(true),
+		ObjectsPerScan:        aws.Int64(objects),
+		BytesPerScan:          aws.Int64(bytes),
+		ObjectsPerDay:         aws.Int64(objectsPerDay),
+		SampleTruncated:       aws.Bool(truncated),
+		CostBand:              aws.String(costBand),
+		MonthlyRequestCostUSD: aws.Float64(monthlyCost),
+	}, nil
+}
+
+type bucketSample struct {
+	objects, bytes, pages int64
+	truncated             bool
+}
+
+// sampleBucket lists the objects of a bucket, which may be followed by a "/prefix" or "/prefix*".
+func sampleBucket(s3Client s3iface.S3API, bucket string) (*bucketSample, error) {
+	name, pattern := parseBucketEntry(bucket)
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(name)}
+	if prefix := patternPrefix(pattern); prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	sample := &bucketSample{}
+	for sample.pages < maxEstimatePages {
+		page, err := s3Client.ListObjectsV2(input)
+		if err != nil {
+			return nil, err
+		}
+		sample.pages++
+		for _, object := range page.Contents {
+			sample.objects++
+			sample.bytes += aws.Int64Value(object.Size)
+		}
+		if !aws.BoolValue(page.IsTruncated) {
+			return sample, nil
+		}
+		input.ContinuationToken = page.NextContinuationToken
+	}
+
+	sample.truncated = true
+	return sample, nil
+}
@@ -0,0 +1,115 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func (client *mockS3Client) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
+}
+
+// objectsOfSize returns count listed objects of the given size.
+func objectsOfSize(count int, size int64) []*s3.Object {
+	result := make([]*s3.Object, count)
+	for i := range result {
+		result[i] = &s3.Object{Size: aws.Int64(size)}
+	}
+	return result
+}
+
+func TestEstimateScanCost(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket-1")}).
+		Return(&s3.ListObjectsV2Output{
+			Contents:              objectsOfSize(1000, 10),
+			IsTruncated:           aws.Bool(true),
+			NextContinuationToken: aws.String("next"),
+		}, nil).Once()
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket-1"), ContinuationToken: aws.String("next")}).
+		Return(&s3.ListObjectsV2Output{Contents: objectsOfSize(500, 10), IsTruncated: aws.Bool(false)}, nil).Once()
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket-2"), Prefix: aws.String("logs/")}).
+		Return(&s3.ListObjectsV2Output{Contents: objectsOfSize(100, 1000), IsTruncated: aws.Bool(false)}, nil).Once()
+	mockHealthCheckClients(nil, mockS3, nil)
+
+	result, err := apiTest.EstimateScanCost(&models.EstimateScanCostInput{
+		AWSAccountID:     aws.String(testAccountID),
+		S3Buckets:        aws.StringSlice([]string{"bucket-1", "bucket-2/logs/*"}),
+		ScanIntervalMins: aws.Int(60),
+	})
+	require.NoError(t, err)
+
+	assert.True(t, *result.Approximate)
+	assert.False(t, *result.SampleTruncated)
+	assert.Equal(t, int64(1600), *result.ObjectsPerScan)
+	assert.Equal(t, int64(115000), *result.BytesPerScan)
+	assert.Equal(t, int64(1600*24), *result.ObjectsPerDay)
+	assert.Equal(t, "low", *result.CostBand)
+	// 3 list requests and 1600 objects, 24 times a day for 30 days
+	assert.InDelta(t, 3*24*30*listRequestPrice+1600*24*30*getRequestPrice, *result.MonthlyRequestCostUSD, 1e-9)
+	mockS3.AssertExpectations(t)
+}
+
+func TestEstimateScanCostSampleTruncated(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents:              objectsOfSize(1000, 1),
+		IsTruncated:           aws.Bool(true),
+		NextContinuationToken: aws.String("next"),
+	}, nil)
+	mockHealthCheckClients(nil, mockS3, nil)
+
+	result, err := apiTest.EstimateScanCost(&models.EstimateScanCostInput{
+		AWSAccountID:     aws.String(testAccountID),
+		S3Buckets:        aws.StringSlice([]string{"bucket"}),
+		ScanIntervalMins: aws.Int(60),
+	})
+	require.NoError(t, err)
+
+	assert.True(t, *result.SampleTruncated)
+	assert.Equal(t, int64(maxEstimatePages*1000), *result.ObjectsPerScan)
+	assert.Equal(t, "medium", *result.CostBand)
+	mockS3.AssertNumberOfCalls(t, "ListObjectsV2", maxEstimatePages)
+}
+
+func TestEstimateScanCostListFails(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, errors.New("access denied"))
+	mockHealthCheckClients(nil, mockS3, nil)
+
+	result, err := apiTest.EstimateScanCost(&models.EstimateScanCostInput{
+		AWSAccountID:     aws.String(testAccountID),
+		S3Buckets:        aws.StringSlice([]string{"bucket"}),
+		ScanIntervalMins: aws.Int(60),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
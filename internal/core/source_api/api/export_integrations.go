@@ -0,0 +1,98 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	exportKeyPrefix       = "source-integrations/"
+	exportTimestampFormat = "20060102T150405Z"
+
+	// Integrations read per scan of the table, at most one scan runs per exportPageInterval
+	exportPageSize = 100
+)
+
+// The export reads at most exportPageSize integrations per interval, so that it doesn't take the read
+// capacity of the table away from the scheduler and the UI
+var exportPageInterval = time.Second
+
+// ExportAllIntegrations writes the settings of every integration to a timestamped object of the export bucket.
+//
+// Only the settings are exported, the scan state and the health of the integrations are not. The integrations
+// hold no credentials: the roles are assumed by their conventional name.
+func (API) ExportAllIntegrations(*models.ExportAllIntegrationsInput) (*models.IntegrationsExport, error) {
+	if exportBucket == "" {
+		return nil, &genericapi.InvalidInputError{Message: "no export bucket is configured"}
+	}
+
+	exportedAt := time.Now().UTC()
+	export := &models.ExportedIntegrations{
+		ExportedAt:   aws.Time(exportedAt),
+		Integrations: make([]*models.SourceIntegrationMetadata, 0),
+	}
+	var startKey map[string]*dynamodb.AttributeValue
+	for page := 0; page == 0 || startKey != nil; page++ {
+		if page > 0 {
+			time.Sleep(exportPageInterval)
+		}
+		var integrations []*models.SourceIntegration
+		var err error
+		if integrations, startKey, err = db.ScanIntegrationsPage(exportPageSize, startKey); err != nil {
+			return nil, err
+		}
+		for _, integration := range integrations {
+			export.Integrations = append(export.Integrations, integration.SourceIntegrationMetadata)
+		}
+	}
+
+	body, err := json.Marshal(export)
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to marshal integrations export: " + err.Error()}
+	}
+	key := exportKeyPrefix + exportedAt.Format(exportTimestampFormat) + ".json"
+	_, err = exportClient.PutObject(&s3.PutObjectInput{
+		Body:        bytes.NewReader(body),
+		Bucket:      aws.String(exportBucket),
+		ContentType: aws.String("application/json"),
+		Key:         aws.String(key),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "S3.PutObject"}
+	}
+
+	zap.L().Info("exported integrations",
+		zap.String("bucket", exportBucket), zap.String("key", key), zap.Int("integrations", len(export.Integrations)))
+	return &models.IntegrationsExport{
+		Bucket:           aws.String(exportBucket),
+		Key:              aws.String(key),
+		IntegrationCount: aws.Int(len(export.Integrations)),
+	}, nil
+}
@@ -0,0 +1,138 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// mockPagedScanClient returns the items of the table in pages of the requested limit.
+type mockPagedScanClient struct {
+	*modelstest.MockDDBClient
+	items []map[string]*dynamodb.AttributeValue
+	scans []*dynamodb.ScanInput
+}
+
+func (client *mockPagedScanClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	client.scans = append(client.scans, input)
+	start := 0
+	if input.ExclusiveStartKey != nil {
+		start, _ = strconv.Atoi(*input.ExclusiveStartKey["integrationId"].S)
+	}
+	end := start + int(*input.Limit)
+	if end >= len(client.items) {
+		return &dynamodb.ScanOutput{Items: client.items[start:]}, nil
+	}
+	return &dynamodb.ScanOutput{
+		Items:            client.items[start:end],
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"integrationId": {S: aws.String(strconv.Itoa(end))}},
+	}, nil
+}
+
+func mockExport(t *testing.T, count int) (*mockPagedScanClient, *mockS3Client) {
+	client := &mockPagedScanClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	for i := 0; i < count; i++ {
+		item, err := dynamodbattribute.MarshalMap(&models.SourceIntegration{
+			SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+				IntegrationID:    aws.String("integration-" + strconv.Itoa(i)),
+				IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+				IntegrationLabel: aws.String("logs-" + strconv.Itoa(i)),
+				S3Buckets:        aws.StringSlice([]string{"bucket-" + strconv.Itoa(i)}),
+			},
+			SourceIntegrationStatus: &models.SourceIntegrationStatus{ScanStatus: aws.String(models.StatusOK)},
+		})
+		require.NoError(t, err)
+		client.items = append(client.items, item)
+	}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+
+	mockS3 := &mockS3Client{}
+	exportClient = mockS3
+	exportBucket = "backups"
+	exportPageInterval = 0
+	t.Cleanup(func() { exportBucket = "" })
+	return client, mockS3
+}
+
+func TestExportAllIntegrations(t *testing.T) {
+	client, mockS3 := mockExport(t, exportPageSize+1)
+	var uploaded *s3.PutObjectInput
+	mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).
+		Run(func(args mock.Arguments) { uploaded = args.Get(0).(*s3.PutObjectInput) })
+
+	result, err := apiTest.ExportAllIntegrations(&models.ExportAllIntegrationsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, "backups", *result.Bucket)
+	assert.True(t, strings.HasPrefix(*result.Key, exportKeyPrefix), *result.Key)
+	assert.Equal(t, exportPageSize+1, *result.IntegrationCount)
+	// The table is read in bounded pages
+	require.Len(t, client.scans, 2)
+	assert.Equal(t, int64(exportPageSize), *client.scans[0].Limit)
+
+	require.NotNil(t, uploaded)
+	assert.Equal(t, "backups", *uploaded.Bucket)
+	assert.Equal(t, *result.Key, *uploaded.Key)
+	body, err := ioutil.ReadAll(uploaded.Body)
+	require.NoError(t, err)
+	var exported models.ExportedIntegrations
+	require.NoError(t, json.Unmarshal(body, &exported))
+	require.Len(t, exported.Integrations, exportPageSize+1)
+	assert.Equal(t, "integration-0", *exported.Integrations[0].IntegrationID)
+	assert.Equal(t, "logs-100", *exported.Integrations[exportPageSize].IntegrationLabel)
+	assert.Equal(t, []*string{aws.String("bucket-100")}, exported.Integrations[exportPageSize].S3Buckets)
+	// Only the settings are exported
+	assert.NotContains(t, string(body), "scanStatus")
+}
+
+func TestExportAllIntegrationsEmptyTable(t *testing.T) {
+	_, mockS3 := mockExport(t, 0)
+	mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil)
+
+	result, err := apiTest.ExportAllIntegrations(&models.ExportAllIntegrationsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, *result.IntegrationCount)
+	mockS3.AssertExpectations(t)
+}
+
+func TestExportAllIntegrationsNoBucket(t *testing.T) {
+	_, mockS3 := mockExport(t, 1)
+	exportBucket = ""
+
+	result, err := apiTest.ExportAllIntegrations(&models.ExportAllIntegrationsInput{})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockS3.AssertNotCalled(t, "PutObject", mock.Anything)
+}
@@ -0,0 +1,256 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const (
+	// The scopes the service account reads the logs with
+	gcpScopes = "https://www.googleapis.com/auth/devstorage.read_only https://www.googleapis.com/auth/pubsub"
+
+	// The permission needed to pull the messages of a subscription
+	gcpConsumePermission = "pubsub.subscriptions.consume"
+)
+
+var (
+	// The endpoints of GCP, tests point them at a local server. The token URI of the service account key is
+	// ignored, the assertion is only ever sent to Google.
+	gcpTokenURL   = "https://oauth2.googleapis.com/token"
+	gcpStorageURL = "https://storage.googleapis.com"
+	gcpPubSubURL  = "https://pubsub.googleapis.com"
+
+	gcpClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// gcpHealthChecker checks the service account of the GCP integrations.
+type gcpHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration: the service account must sign in and read the log source.
+func (gcpHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	status, err := api.CheckIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+	return &integrationEvaluation{
+		rolesHealthy: aws.BoolValue(status.GCPServiceAccountStatus.Healthy) &&
+			aws.BoolValue(status.GCPLogSourceStatus.Healthy),
+		failedItems: make([]*string, 0),
+		health:      status,
+	}, nil
+}
+
+// gcpPermissions is the request and the response of testIamPermissions.
+type gcpPermissions struct {
+	Permissions []string `json:"permissions"`
+}
+
+// gcpServiceAccountKey is the JSON key of a service account, as downloaded from the GCP console.
+type gcpServiceAccountKey struct {
+	Type         string `json:"type"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+}
+
+// checkGCPLogSource signs in as the service account of the integration, then checks it can read its log source.
+func checkGCPLogSource(input *models.CheckIntegrationInput, out *models.SourceIntegrationHealth) {
+	start := time.Now()
+	failed := func(err error) models.SourceIntegrationItemStatus {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	token, err := gcpAccessToken(input)
+	if err != nil {
+		out.GCPServiceAccountStatus = failed(err)
+		return
+	}
+	out.GCPServiceAccountStatus = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+
+	start = time.Now()
+	if input.PubSubSubscription != nil {
+		err = checkPubSubSubscription(token, *input.PubSubSubscription)
+	} else {
+		err = checkGCSBucket(token, aws.StringValue(input.GCSBucket))
+	}
+	if err != nil {
+		out.GCPLogSourceStatus = failed(err)
+		return
+	}
+	out.GCPLogSourceStatus = models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+}
+
+// checkGCSBucket lists an object of the bucket.
+func checkGCSBucket(token, bucket string) error {
+	listURL := fmt.Sprintf("%s/storage/v1/b/%s/o?maxResults=1", gcpStorageURL, url.PathEscape(bucket))
+	request, err := http.NewRequest(http.MethodGet, listURL, nil)
+	if err != nil {
+		return err
+	}
+	response, err := gcpDo(request, token)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("listing the bucket responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// checkPubSubSubscription tests whether the service account is allowed to pull the messages of the subscription.
+func checkPubSubSubscription(token, subscription string) error {
+	body, err := jsoniter.Marshal(&gcpPermissions{Permissions: []string{gcpConsumePermission}})
+	if err != nil {
+		return err
+	}
+	// The name of the subscription is validated, its slashes are part of the path
+	testURL := fmt.Sprintf("%s/v1/%s:testIamPermissions", gcpPubSubURL, subscription)
+	request, err := http.NewRequest(http.MethodPost, testURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := gcpDo(request, token)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	var permissions gcpPermissions
+	switch {
+	case response.StatusCode != http.StatusOK:
+		return fmt.Errorf("testing the permissions of the subscription responded with status %d", response.StatusCode)
+	case jsoniter.NewDecoder(response.Body).Decode(&permissions) != nil:
+		return fmt.Errorf("the permissions of the subscription can't be decoded")
+	}
+	for _, permission := range permissions.Permissions {
+		if permission == gcpConsumePermission {
+			return nil
+		}
+	}
+	return fmt.Errorf("the service account is not allowed %s", gcpConsumePermission)
+}
+
+func gcpDo(request *http.Request, token string) (*http.Response, error) {
+	request.Header.Set("Authorization", "Bearer "+token)
+	return gcpClient.Do(request)
+}
+
+// gcpAccessToken exchanges an assertion signed with the key of the service account for an access token.
+func gcpAccessToken(input *models.CheckIntegrationInput) (string, error) {
+	secret, err := secretsClient.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: input.GCPServiceAccountSecretArn})
+	if err != nil {
+		return "", fmt.Errorf("the service account key can't be read: %s", err)
+	}
+	var key gcpServiceAccountKey
+	if err = jsoniter.UnmarshalFromString(aws.StringValue(secret.SecretString), &key); err != nil || key.Type != "service_account" {
+		return "", fmt.Errorf("the secret is not the JSON key of a service account")
+	}
+	assertion, err := gcpAssertion(&key, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	response, err := gcpClient.Post(gcpTokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = jsoniter.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("signing in responded with status %d", response.StatusCode)
+	}
+	if response.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("signing in failed with status %d: %s %s", response.StatusCode, token.Error, token.ErrorDescription)
+	}
+	return token.AccessToken, nil
+}
+
+// gcpAssertion is the JWT the service account signs in with, valid for an hour.
+func gcpAssertion(key *gcpServiceAccountKey, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("the private key of the service account is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("the private key of the service account can't be parsed: %s", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("the private key of the service account is not an RSA key")
+	}
+
+	header, err := jsoniter.Marshal(&struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+		Kid string `json:"kid"`
+	}{Alg: "RS256", Typ: "JWT", Kid: key.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := jsoniter.Marshal(&struct {
+		Iss   string `json:"iss"`
+		Scope string `json:"scope"`
+		Aud   string `json:"aud"`
+		Iat   int64  `json:"iat"`
+		Exp   int64  `json:"exp"`
+	}{Iss: key.ClientEmail, Scope: gcpScopes, Aud: gcpTokenURL, Iat: now.Unix(), Exp: now.Add(time.Hour).Unix()})
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,196 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const (
+	testGCPProjectID          = "audit-logs-123"
+	testGCPServiceAccountArn  = "arn:aws:secretsmanager:us-west-2:123456789012:secret:panther-gcp-test"
+	testGCSBucket             = "audit-logs-bucket"
+	testPubSubSubscription    = "projects/audit-logs-123/subscriptions/audit-logs"
+	testGCPServiceAccountMail = "panther@audit-logs-123.iam.gserviceaccount.com"
+)
+
+// mockGCP points the GCP endpoints at a local server, which only accepts assertions signed with the key of the
+// service account. The service account can list the bucket, and holds the permissions on the subscription.
+func mockGCP(t *testing.T, permissions ...string) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	key, err := jsoniter.MarshalToString(&gcpServiceAccountKey{
+		Type:         "service_account",
+		PrivateKeyID: "key-1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  testGCPServiceAccountMail,
+	})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		require.Len(t, parts, 3)
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid_grant", "error_description": "Invalid JWT Signature."}`))
+			return
+		}
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		assert.Equal(t, testGCPServiceAccountMail, jsoniter.Get(claims, "iss").ToString())
+		_, _ = w.Write([]byte(`{"access_token": "token"}`))
+	})
+	mux.HandleFunc("/storage/v1/b/"+testGCSBucket+"/o", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"kind": "storage#objects"}`))
+	})
+	mux.HandleFunc("/v1/"+testPubSubSubscription+":testIamPermissions", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, err := jsoniter.MarshalToString(&gcpPermissions{Permissions: permissions})
+		require.NoError(t, err)
+		_, _ = w.Write([]byte(body))
+	})
+	server := httptest.NewServer(mux)
+
+	tokenURL, storageURL, pubSubURL, client := gcpTokenURL, gcpStorageURL, gcpPubSubURL, secretsClient
+	gcpTokenURL, gcpStorageURL, gcpPubSubURL = server.URL+"/token", server.URL, server.URL
+	secretsClient = &mockSecretsClient{secret: key}
+	t.Cleanup(func() {
+		server.Close()
+		gcpTokenURL, gcpStorageURL, gcpPubSubURL, secretsClient = tokenURL, storageURL, pubSubURL, client
+	})
+}
+
+func gcpCheckInput() *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		IntegrationType:            aws.String(models.IntegrationTypeGCPLogSource),
+		GCPProjectID:               aws.String(testGCPProjectID),
+		GCPServiceAccountSecretArn: aws.String(testGCPServiceAccountArn),
+		GCSBucket:                  aws.String(testGCSBucket),
+	}
+}
+
+func TestCheckIntegrationGCPBucket(t *testing.T) {
+	mockGCP(t)
+
+	result, err := apiTest.CheckIntegration(gcpCheckInput())
+	require.NoError(t, err)
+	assert.True(t, *result.GCPServiceAccountStatus.Healthy)
+	assert.True(t, *result.GCPLogSourceStatus.Healthy)
+	assert.Nil(t, result.ProcessingRoleStatus.Healthy)
+
+	evaluation, err := gcpHealthChecker{}.Evaluate(apiTest, gcpCheckInput())
+	require.NoError(t, err)
+	assert.True(t, evaluation.rolesHealthy)
+}
+
+func TestCheckIntegrationGCPSubscription(t *testing.T) {
+	mockGCP(t, gcpConsumePermission)
+	input := gcpCheckInput()
+	input.GCSBucket, input.PubSubSubscription = nil, aws.String(testPubSubSubscription)
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.GCPLogSourceStatus.Healthy)
+}
+
+func TestCheckIntegrationGCPSubscriptionNotConsumable(t *testing.T) {
+	mockGCP(t, "pubsub.subscriptions.get")
+	input := gcpCheckInput()
+	input.GCSBucket, input.PubSubSubscription = nil, aws.String(testPubSubSubscription)
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.GCPServiceAccountStatus.Healthy)
+	assert.False(t, *result.GCPLogSourceStatus.Healthy)
+	assert.Equal(t, "the service account is not allowed pubsub.subscriptions.consume", *result.GCPLogSourceStatus.ErrorMessage)
+
+	evaluation, err := gcpHealthChecker{}.Evaluate(apiTest, input)
+	require.NoError(t, err)
+	assert.False(t, evaluation.rolesHealthy)
+}
+
+func TestCheckIntegrationGCPBucketUnreadable(t *testing.T) {
+	mockGCP(t)
+	input := gcpCheckInput()
+	input.GCSBucket = aws.String("other-bucket")
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.False(t, *result.GCPLogSourceStatus.Healthy)
+	assert.Equal(t, "listing the bucket responded with status 404", *result.GCPLogSourceStatus.ErrorMessage)
+}
+
+func TestCheckIntegrationGCPNotAServiceAccountKey(t *testing.T) {
+	mockGCP(t)
+	secretsClient = &mockSecretsClient{secret: `{"type": "authorized_user"}`}
+
+	result, err := apiTest.CheckIntegration(gcpCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.GCPServiceAccountStatus.Healthy)
+	assert.Equal(t, "the secret is not the JSON key of a service account", *result.GCPServiceAccountStatus.ErrorMessage)
+	assert.Nil(t, result.GCPLogSourceStatus.Healthy)
+}
+
+func TestCheckIntegrationGCPSignInFails(t *testing.T) {
+	mockGCP(t)
+	// A key the server doesn't know signs the assertion
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(otherKey)
+	require.NoError(t, err)
+	key, err := jsoniter.MarshalToString(&gcpServiceAccountKey{
+		Type:        "service_account",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail: testGCPServiceAccountMail,
+	})
+	require.NoError(t, err)
+	secretsClient = &mockSecretsClient{secret: key}
+
+	result, err := apiTest.CheckIntegration(gcpCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.GCPServiceAccountStatus.Healthy)
+	assert.Equal(t, "signing in failed with status 400: invalid_grant Invalid JWT Signature.",
+		*result.GCPServiceAccountStatus.ErrorMessage)
+}
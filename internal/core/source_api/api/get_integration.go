@@ -0,0 +1,58 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetIntegration returns a single integration, unless the caller already has its current version.
+//
+// Every write increments the version of the integration, so the ETag changes on every write.
+// If the primary table is unavailable, the integration is read from the replica and flagged as possibly stale.
+func (API) GetIntegration(input *models.GetIntegrationInput) (*models.GetIntegrationOutput, error) {
+	integration, stale, err := db.GetSourceIntegrationWithFallback(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+
+	etag := integrationETag(integration.SourceIntegrationMetadata)
+	if aws.StringValue(input.IfNoneMatch) == etag {
+		return &models.GetIntegrationOutput{ETag: aws.String(etag), NotModified: aws.Bool(true), PossiblyStale: aws.Bool(stale)}, nil
+	}
+	return &models.GetIntegrationOutput{
+		ETag:          aws.String(etag),
+		NotModified:   aws.Bool(false),
+		PossiblyStale: aws.Bool(stale),
+		Integration:   integration,
+	}, nil
+}
+
+// integrationETag is a quoted, HTTP style, entity tag for the version of an integration.
+func integrationETag(integration *models.SourceIntegrationMetadata) string {
+	return strconv.Quote(strconv.FormatInt(aws.Int64Value(integration.Version), 10))
+}
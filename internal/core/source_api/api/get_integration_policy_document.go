@@ -0,0 +1,152 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type policyDocument struct {
+	Version   string
+	Statement []policyStatement
+}
+
+type policyStatement struct {
+	Effect   string
+	Action   []string
+	Resource []string
+}
+
+// GetIntegrationPolicyDocument returns the IAM policy of the log processing role of an integration.
+//
+// The policy grants exactly the permissions the ReadData policy of the log processing template grants, for
+// the buckets and keys of the integration. The resources are the ones GetIntegrationTemplate fills in.
+// The policy of an SQS integration is the one its producers need to send log events to its queue.
+func (API) GetIntegrationPolicyDocument(
+	input *models.GetIntegrationPolicyDocumentInput) (*models.SourceIntegrationPolicyDocument, error) {
+
+	integration, err := db.GetIntegration(input.IntegrationID, false)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	var document policyDocument
+	switch aws.StringValue(integration.IntegrationType) {
+	case models.IntegrationTypeAWS3:
+		document = logProcessingPolicy(
+			integration.S3Buckets, integration.KmsKeys, aws.BoolValue(integration.CreateKmsGrants), aws.BoolValue(integration.IsOrgTrail))
+		addDeadLetterQueueStatement(&document, deadLetterQueueArn(integration.DeadLetterQueue))
+	case models.IntegrationTypeSQSQueue:
+		document = sqsProducerPolicy(integration.SQSQueueArn)
+	default:
+		// The cloud security roles use the AWS managed SecurityAudit policy, not one scoped to the integration
+		return nil, &genericapi.InvalidInputError{Message: "policy documents are only available for log analysis integrations"}
+	}
+	body, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to marshal policy document: " + err.Error()}
+	}
+	return &models.SourceIntegrationPolicyDocument{Body: aws.String(string(body))}, nil
+}
+
+// logProcessingPolicy is the least privileged policy of the log processing role for the given buckets and keys.
+func logProcessingPolicy(buckets, keys []*string, kmsGrants, orgTrail bool) policyDocument {
+	bucketArns, objectArns := logProcessingResources(buckets)
+	document := policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{Effect: "Allow", Action: []string{"s3:GetBucketLocation", "s3:ListBucket", "s3:GetEncryptionConfiguration"}, Resource: bucketArns},
+			{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: objectArns},
+			// The pipeline self test uploads a synthetic log
+			{Effect: "Allow", Action: []string{"s3:PutObject"}, Resource: selfTestObjectArns(buckets)},
+		},
+	}
+	if len(keys) > 0 {
+		document.Statement = append(document.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"kms:Decrypt", "kms:DescribeKey"},
+			Resource: sliceStringValue(keys),
+		})
+	}
+	if len(keys) > 0 && kmsGrants {
+		document.Statement = append(document.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"kms:CreateGrant", "kms:ListGrants", "kms:RetireGrant"},
+			Resource: sliceStringValue(keys),
+		})
+	}
+	if orgTrail {
+		document.Statement = append(document.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   []string{"organizations:DescribeOrganization"},
+			Resource: []string{"*"},
+		})
+	}
+	return document
+}
+
+// addDeadLetterQueueStatement allows the role to move the failed notifications to the dead letter queue, if any.
+func addDeadLetterQueueStatement(document *policyDocument, queueArn *string) {
+	if queueArn == nil {
+		return
+	}
+	document.Statement = append(document.Statement, policyStatement{
+		Effect:   "Allow",
+		Action:   []string{"sqs:GetQueueAttributes", "sqs:GetQueueUrl", "sqs:SendMessage"},
+		Resource: []string{*queueArn},
+	})
+}
+
+// logProcessingResources returns the bucket and object ARNs the log processing role needs access to.
+//
+// Buckets can be followed by an object prefix, e.g. "my-bucket/prefix*", otherwise every object is readable.
+// S3 ARNs have no region, so the resources cover the buckets of an integration in every region.
+func logProcessingResources(buckets []*string) (bucketArns, objectArns []string) {
+	bucketArns = make([]string, 0, len(buckets))
+	objectArns = make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		name, prefix := aws.StringValue(bucket), "*"
+		if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
+			name, prefix = parts[0], parts[1]
+		}
+		bucketArns = append(bucketArns, "arn:aws:s3:::"+name)
+		objectArns = append(objectArns, "arn:aws:s3:::"+name+"/"+prefix)
+	}
+	return bucketArns, objectArns
+}
+
+// selfTestObjectArns returns the ARNs of the synthetic logs the pipeline self test uploads to the buckets.
+//
+// They stay within the prefix of each bucket entry, like the key of the synthetic log.
+func selfTestObjectArns(buckets []*string) []string {
+	result := make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		name, pattern := parseBucketEntry(aws.StringValue(bucket))
+		result = append(result, "arn:aws:s3:::"+name+"/"+patternPrefix(pattern)+selfTestKeyPrefix+"*")
+	}
+	return result
+}
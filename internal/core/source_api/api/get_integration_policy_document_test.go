@@ -0,0 +1,148 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func mockLogIntegration(buckets []string, keys []string) {
+	item := map[string]*dynamodb.AttributeValue{
+		"integrationId":   {S: aws.String(testIntegrationID)},
+		"integrationType": {S: aws.String(models.IntegrationTypeAWS3)},
+		"awsAccountId":    {S: aws.String(testAccountID)},
+		"s3Buckets":       {L: stringList(buckets)},
+		"kmsKeys":         {L: stringList(keys)},
+	}
+
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+}
+
+func stringList(values []string) []*dynamodb.AttributeValue {
+	result := make([]*dynamodb.AttributeValue, len(values))
+	for i, value := range values {
+		result[i] = &dynamodb.AttributeValue{S: aws.String(value)}
+	}
+	return result
+}
+
+func getPolicyDocument(t *testing.T) policyDocument {
+	result, err := apiTest.GetIntegrationPolicyDocument(&models.GetIntegrationPolicyDocumentInput{
+		IntegrationID: aws.String(testIntegrationID),
+	})
+	require.NoError(t, err)
+	var document policyDocument
+	require.NoError(t, json.Unmarshal([]byte(*result.Body), &document))
+	return document
+}
+
+func TestGetIntegrationPolicyDocument(t *testing.T) {
+	mockLogIntegration([]string{"bucket-1", "bucket-2/logs*"}, []string{testKeyArn})
+
+	expected := policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{
+				Effect:   "Allow",
+				Action:   []string{"s3:GetBucketLocation", "s3:ListBucket", "s3:GetEncryptionConfiguration"},
+				Resource: []string{"arn:aws:s3:::bucket-1", "arn:aws:s3:::bucket-2"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   []string{"s3:GetObject"},
+				Resource: []string{"arn:aws:s3:::bucket-1/*", "arn:aws:s3:::bucket-2/logs*"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   []string{"s3:PutObject"},
+				Resource: []string{"arn:aws:s3:::bucket-1/panther-self-test/*", "arn:aws:s3:::bucket-2/logspanther-self-test/*"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   []string{"kms:Decrypt", "kms:DescribeKey"},
+				Resource: []string{testKeyArn},
+			},
+		},
+	}
+	assert.Equal(t, expected, getPolicyDocument(t))
+}
+
+func TestGetIntegrationPolicyDocumentWithoutKeys(t *testing.T) {
+	mockLogIntegration([]string{"bucket-1"}, nil)
+
+	document := getPolicyDocument(t)
+	require.Len(t, document.Statement, 3)
+	for _, statement := range document.Statement {
+		assert.NotContains(t, statement.Action, "kms:Decrypt")
+	}
+}
+
+func TestGetIntegrationPolicyDocumentCloudSecurity(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+
+	result, err := apiTest.GetIntegrationPolicyDocument(&models.GetIntegrationPolicyDocumentInput{
+		IntegrationID: aws.String(testIntegrationID),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+// The template must grant access to exactly the resources of the policy document.
+func TestGetIntegrationTemplateMatchesPolicyDocument(t *testing.T) {
+	templateCache[models.IntegrationTypeAWS3] = templateCacheItem{
+		Timestamp: time.Now(),
+		Body: []byte("Default: '' # MasterAccountId\nDefault: '' # S3Buckets\n" +
+			"Default: '' # S3ObjectPrefixes\nDefault: '' # SelfTestObjectPrefixes\nDefault: '' # EncryptionKeys\n"),
+	}
+	defer delete(templateCache, models.IntegrationTypeAWS3)
+
+	result, err := apiTest.GetIntegrationTemplate(&models.GetIntegrationTemplateInput{
+		AWSAccountID:       aws.String(testAccountID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWS3),
+		CWEEnabled:         aws.Bool(false),
+		RemediationEnabled: aws.Bool(false),
+		S3Buckets:          aws.StringSlice([]string{"bucket-1", "bucket-2/logs*"}),
+		KmsKeys:            aws.StringSlice([]string{testKeyArn}),
+	})
+	require.NoError(t, err)
+
+	expected := "Default: " + testAccountID + " # MasterAccountId\n" +
+		"Default: arn:aws:s3:::bucket-1,arn:aws:s3:::bucket-2 # S3Buckets\n" +
+		"Default: arn:aws:s3:::bucket-1/*,arn:aws:s3:::bucket-2/logs* # S3ObjectPrefixes\n" +
+		"Default: arn:aws:s3:::bucket-1/panther-self-test/*,arn:aws:s3:::bucket-2/logspanther-self-test/* # SelfTestObjectPrefixes\n" +
+		"Default: " + testKeyArn + " # EncryptionKeys\n"
+	assert.Equal(t, expected, *result.Body)
+}
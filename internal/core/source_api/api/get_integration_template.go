@@ -36,6 +36,7 @@ const (
 	TemplateBucket           = "panther-public-cloudformation-templates"
 	CloudSecurityTemplateKey = "panther-compliance-iam/latest/template.yml"
 	LogProcessingTemplateKey = "panther-log-processing-iam/latest/template.yml"
+	OrganizationTemplateKey  = "panther-organization-iam/latest/template.yml"
 	cacheTimout              = time.Minute * 30
 )
 
@@ -55,8 +56,18 @@ var (
 	// Formatting variables for Log Analysis
 	s3BucketFind    = []byte("Default: '' # S3Buckets")
 	s3BucketReplace = "Default: %s # S3Buckets"
+	s3ObjectFind    = []byte("Default: '' # S3ObjectPrefixes")
+	s3ObjectReplace = "Default: %s # S3ObjectPrefixes"
+	selfTestFind    = []byte("Default: '' # SelfTestObjectPrefixes")
+	selfTestReplace = "Default: %s # SelfTestObjectPrefixes"
 	kmsKeyFind      = []byte("Default: '' # EncryptionKeys")
 	kmsKeyReplace   = "Default: %s # EncryptionKeys"
+	kmsGrantFind    = []byte("Default: false # CreateKmsGrants")
+	kmsGrantReplace = "Default: %t # CreateKmsGrants"
+	orgTrailFind    = []byte("Default: false # OrgTrail")
+	orgTrailReplace = "Default: %t # OrgTrail"
+	streamFind      = []byte("Default: '' # KinesisStreams")
+	streamReplace   = "Default: %s # KinesisStreams"
 )
 
 type templateCacheItem struct {
@@ -84,11 +95,22 @@ func (API) GetIntegrationTemplate(input *models.GetIntegrationTemplateInput) (*m
 	formattedTemplate = bytes.Replace(formattedTemplate, remediationFind,
 		[]byte(fmt.Sprintf(remediationReplace, *input.RemediationEnabled)), 1)
 
-	// Log Analysis replacements
+	// Log Analysis replacements, the same resources as in the policy document of the integration
+	bucketArns, objectArns := logProcessingResources(input.S3Buckets)
 	formattedTemplate = bytes.Replace(formattedTemplate, s3BucketFind,
-		[]byte(fmt.Sprintf(s3BucketReplace, strings.Join(sliceStringValue(input.S3Buckets), ","))), 1)
+		[]byte(fmt.Sprintf(s3BucketReplace, strings.Join(bucketArns, ","))), 1)
+	formattedTemplate = bytes.Replace(formattedTemplate, s3ObjectFind,
+		[]byte(fmt.Sprintf(s3ObjectReplace, strings.Join(objectArns, ","))), 1)
+	formattedTemplate = bytes.Replace(formattedTemplate, selfTestFind,
+		[]byte(fmt.Sprintf(selfTestReplace, strings.Join(selfTestObjectArns(input.S3Buckets), ","))), 1)
 	formattedTemplate = bytes.Replace(formattedTemplate, kmsKeyFind,
 		[]byte(fmt.Sprintf(kmsKeyReplace, strings.Join(sliceStringValue(input.KmsKeys), ","))), 1)
+	formattedTemplate = bytes.Replace(formattedTemplate, kmsGrantFind,
+		[]byte(fmt.Sprintf(kmsGrantReplace, aws.BoolValue(input.CreateKmsGrants))), 1)
+	formattedTemplate = bytes.Replace(formattedTemplate, orgTrailFind,
+		[]byte(fmt.Sprintf(orgTrailReplace, aws.BoolValue(input.IsOrgTrail))), 1)
+	formattedTemplate = bytes.Replace(formattedTemplate, streamFind,
+		[]byte(fmt.Sprintf(streamReplace, aws.StringValue(input.StreamARN))), 1)
 
 	return &models.SourceIntegrationTemplate{
 		Body: aws.String(string(formattedTemplate)),
@@ -96,7 +118,7 @@ func (API) GetIntegrationTemplate(input *models.GetIntegrationTemplateInput) (*m
 }
 
 func sliceStringValue(stringPointers []*string) []string {
-	out := make([]string, 0, len(stringPointers))
+	out := make([]string, len(stringPointers))
 	for index, ptr := range stringPointers {
 		out[index] = aws.StringValue(ptr)
 	}
@@ -117,9 +139,12 @@ func getTemplate(integrationType *string) ([]byte, error) {
 	templateRequest := &s3.GetObjectInput{
 		Bucket: aws.String(TemplateBucket),
 	}
-	if *integrationType == models.IntegrationTypeAWSScan {
+	switch *integrationType {
+	case models.IntegrationTypeAWSScan:
 		templateRequest.Key = aws.String(CloudSecurityTemplateKey)
-	} else {
+	case models.IntegrationTypeAWSOrganization:
+		templateRequest.Key = aws.String(OrganizationTemplateKey)
+	default:
 		templateRequest.Key = aws.String(LogProcessingTemplateKey)
 	}
 	template, err := s3Svc.GetObject(templateRequest)
@@ -0,0 +1,161 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func mockGetIntegrationVersion(version string) *modelstest.MockDDBClient {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"integrationId": {S: aws.String(testIntegrationID)},
+		"scanStatus":    {S: aws.String(models.StatusOK)},
+		"version":       {N: aws.String(version)},
+	}}, nil)
+	return mockClient
+}
+
+func TestGetIntegration(t *testing.T) {
+	mockGetIntegrationVersion("3")
+
+	result, err := apiTest.GetIntegration(&models.GetIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, `"3"`, *result.ETag)
+	assert.False(t, *result.NotModified)
+	require.NotNil(t, result.Integration)
+	assert.Equal(t, testIntegrationID, *result.Integration.IntegrationID)
+	assert.Equal(t, models.StatusOK, *result.Integration.ScanStatus)
+}
+
+func TestGetIntegrationNotModified(t *testing.T) {
+	mockGetIntegrationVersion("3")
+
+	result, err := apiTest.GetIntegration(&models.GetIntegrationInput{
+		IntegrationID: aws.String(testIntegrationID),
+		IfNoneMatch:   aws.String(`"3"`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.GetIntegrationOutput{
+		ETag:          aws.String(`"3"`),
+		NotModified:   aws.Bool(true),
+		PossiblyStale: aws.Bool(false),
+	}, result)
+}
+
+func TestGetIntegrationModified(t *testing.T) {
+	// The client has the ETag from before the last write
+	mockGetIntegrationVersion("4")
+
+	result, err := apiTest.GetIntegration(&models.GetIntegrationInput{
+		IntegrationID: aws.String(testIntegrationID),
+		IfNoneMatch:   aws.String(`"3"`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `"4"`, *result.ETag)
+	assert.False(t, *result.NotModified)
+	assert.NotNil(t, result.Integration)
+}
+
+func TestGetIntegrationDoesNotExist(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := apiTest.GetIntegration(&models.GetIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestGetIntegrationReplicaFallback(t *testing.T) {
+	primary := &modelstest.MockDDBClient{}
+	primary.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{},
+		awserr.NewRequestFailure(awserr.New("InternalServerError", "internal server error", nil), 500, "id"))
+	replica := mockGetIntegrationVersion("3")
+	db = &ddb.DDB{Client: primary, TableName: "test", Replica: replica}
+
+	result, err := apiTest.GetIntegration(&models.GetIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.True(t, *result.PossiblyStale)
+	assert.Equal(t, `"3"`, *result.ETag)
+	require.NotNil(t, result.Integration)
+	assert.Equal(t, testIntegrationID, *result.Integration.IntegrationID)
+	primary.AssertExpectations(t)
+	replica.AssertExpectations(t)
+	// Replicas do not support consistent reads
+	assert.False(t, *replica.Calls[0].Arguments.Get(0).(*dynamodb.GetItemInput).ConsistentRead)
+}
+
+func TestGetIntegrationReplicaNotUsedForClientErrors(t *testing.T) {
+	primary := &modelstest.MockDDBClient{}
+	primary.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{},
+		awserr.NewRequestFailure(awserr.New("AccessDeniedException", "access denied", nil), 400, "id"))
+	replica := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: primary, TableName: "test", Replica: replica}
+
+	result, err := apiTest.GetIntegration(&models.GetIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	replica.AssertNotCalled(t, "GetItem", mock.Anything)
+}
+
+func TestGetIntegrationWithoutReplica(t *testing.T) {
+	primary := &modelstest.MockDDBClient{}
+	primary.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{},
+		awserr.NewRequestFailure(awserr.New("InternalServerError", "internal server error", nil), 500, "id"))
+	db = &ddb.DDB{Client: primary, TableName: "test"}
+
+	result, err := apiTest.GetIntegration(&models.GetIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+}
+
+func TestUpdateItemIncrementsVersion(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	_, err := apiTest.UpdateIntegrationLastScanStart(&models.UpdateIntegrationLastScanStartInput{
+		IntegrationID: aws.String(testIntegrationID),
+		ScanStatus:    aws.String(models.StatusScanning),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, *update.UpdateExpression, "ADD ")
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "version")
+}
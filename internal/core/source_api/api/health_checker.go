@@ -0,0 +1,63 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// HealthChecker evaluates the health of the configuration of an integration.
+//
+// Each integration type has its own checker: a new provider registers one with RegisterHealthChecker.
+type HealthChecker interface {
+	Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error)
+}
+
+// The health checker of each integration type
+var healthCheckers = map[string]HealthChecker{
+	models.IntegrationTypeAWSScan:      awsHealthChecker{},
+	models.IntegrationTypeAWS3:         awsHealthChecker{},
+	models.IntegrationTypeAWSKinesis:   awsHealthChecker{},
+	models.IntegrationTypeAzureScan:    azureHealthChecker{},
+	models.IntegrationTypeGCPLogSource: gcpHealthChecker{},
+	models.IntegrationTypeSQSQueue:     sqsHealthChecker{},
+	models.IntegrationTypeHTTPIngest:   httpIngestHealthChecker{},
+	models.IntegrationTypeOkta:         oktaHealthChecker{},
+	models.IntegrationTypeSyslog:       syslogHealthChecker{},
+
+	models.IntegrationTypeAWSOrganization: organizationHealthChecker{},
+}
+
+// RegisterHealthChecker sets the health checker of an integration type, replacing the one it had if any.
+func RegisterHealthChecker(integrationType string, checker HealthChecker) {
+	healthCheckers[integrationType] = checker
+}
+
+// evaluateIntegrationHealth dispatches the health check to the checker of the integration type.
+func evaluateIntegrationHealth(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	checker, ok := healthCheckers[aws.StringValue(integration.IntegrationType)]
+	if !ok {
+		return nil, &genericapi.InvalidInputError{
+			Message: "no health check for integration type " + aws.StringValue(integration.IntegrationType)}
+	}
+	return checker.Evaluate(api, integration)
+}
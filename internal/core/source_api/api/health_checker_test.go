@@ -0,0 +1,129 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// fakeHealthChecker records the integrations it checks, they all pass
+type fakeHealthChecker struct {
+	checked []*models.CheckIntegrationInput
+}
+
+func (checker *fakeHealthChecker) Evaluate(_ API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	checker.checked = append(checker.checked, integration)
+	return &integrationEvaluation{rolesHealthy: true, failedItems: make([]*string, 0)}, nil
+}
+
+// Registers fake checkers for the log integrations, the registry and the health check functions are restored after the test
+func registerFakeHealthCheckers(t *testing.T) (s3Checker, kinesisChecker *fakeHealthChecker) {
+	registered := make(map[string]HealthChecker, len(healthCheckers))
+	for integrationType, checker := range healthCheckers {
+		registered[integrationType] = checker
+	}
+	evaluateFunc, evaluateHealthFunc := evaluateIntegrationFunc, evaluateIntegrationHealthFunc
+	t.Cleanup(func() {
+		healthCheckers = registered
+		evaluateIntegrationFunc, evaluateIntegrationHealthFunc = evaluateFunc, evaluateHealthFunc
+	})
+
+	evaluateIntegrationFunc, evaluateIntegrationHealthFunc = evaluateIntegration, evaluateIntegrationHealth
+	s3Checker, kinesisChecker = &fakeHealthChecker{}, &fakeHealthChecker{}
+	RegisterHealthChecker(models.IntegrationTypeAWS3, s3Checker)
+	RegisterHealthChecker(models.IntegrationTypeAWSKinesis, kinesisChecker)
+	return s3Checker, kinesisChecker
+}
+
+func TestHealthCheckerOfEveryIntegrationType(t *testing.T) {
+	for _, capabilities := range models.IntegrationTypes() {
+		var expected HealthChecker = awsHealthChecker{}
+		switch *capabilities.IntegrationType {
+		case models.IntegrationTypeAzureScan:
+			expected = azureHealthChecker{}
+		case models.IntegrationTypeGCPLogSource:
+			expected = gcpHealthChecker{}
+		case models.IntegrationTypeSQSQueue:
+			expected = sqsHealthChecker{}
+		case models.IntegrationTypeHTTPIngest:
+			expected = httpIngestHealthChecker{}
+		case models.IntegrationTypeOkta:
+			expected = oktaHealthChecker{}
+		case models.IntegrationTypeSyslog:
+			expected = syslogHealthChecker{}
+		case models.IntegrationTypeAWSOrganization:
+			expected = organizationHealthChecker{}
+		}
+		assert.IsType(t, expected, healthCheckers[*capabilities.IntegrationType], *capabilities.IntegrationType)
+	}
+}
+
+func TestEvaluateIntegrationHealthDispatchesByType(t *testing.T) {
+	s3Checker, kinesisChecker := registerFakeHealthCheckers(t)
+
+	passing, _, err := evaluateIntegration(apiTest, &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSKinesis),
+	})
+	require.NoError(t, err)
+	assert.True(t, passing)
+	assert.Empty(t, s3Checker.checked)
+	require.Len(t, kinesisChecker.checked, 1)
+	assert.Equal(t, models.IntegrationTypeAWSKinesis, *kinesisChecker.checked[0].IntegrationType)
+}
+
+func TestEvaluateIntegrationHealthUnknownType(t *testing.T) {
+	registerFakeHealthCheckers(t)
+
+	eval, err := evaluateIntegrationHealth(apiTest, &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String("gcp-storage"),
+	})
+	assert.Nil(t, eval)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestUpdateIntegrationSettingsDispatchesHealthCheck(t *testing.T) {
+	mockBucketOwnership(t)
+	s3Checker, kinesisChecker := registerFakeHealthCheckers(t)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"test-bucket"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, s3Checker.checked, 1)
+	assert.Equal(t, []*string{aws.String("test-bucket")}, s3Checker.checked[0].S3Buckets)
+	assert.Empty(t, kinesisChecker.checked)
+}
@@ -0,0 +1,123 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+)
+
+const (
+	// The number of integrations whose health is re-checked at the same time
+	maxConcurrentHealthRechecks = 10
+
+	// The failed check of an unhealthy integration whose roles can no longer be assumed
+	failedRolesCheck = "roles"
+)
+
+// RecheckIntegrationHealth re-runs the health check of the stored configuration of the integrations.
+//
+// Integrations are otherwise only checked when they are saved, so a role deleted or a bucket policy revoked
+// afterwards goes unnoticed until the scans fail. Unlike a save, a failed role doesn't fail the re-check, the
+// integration is stored as unhealthy. A notification is sent whenever the health of an integration changes.
+func (api API) RecheckIntegrationHealth(input *models.RecheckIntegrationHealthInput) (*models.HealthRecheckSummary, error) {
+	stored, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+	integrations := make([]*models.SourceIntegrationMetadata, 0, len(stored))
+	for _, integration := range stored {
+		if integration.SourceIntegrationMetadata == nil {
+			continue
+		}
+		if input.IntegrationType != nil && aws.StringValue(integration.IntegrationType) != *input.IntegrationType {
+			continue
+		}
+		integrations = append(integrations, integration.SourceIntegrationMetadata)
+	}
+
+	statuses := make([]*string, len(integrations))
+	errs := forEachConcurrently(len(integrations), maxConcurrentHealthRechecks, func(i int) error {
+		var err error
+		statuses[i], err = api.recheckHealth(integrations[i])
+		return err
+	})
+
+	counts := map[string]int{}
+	summary := &models.HealthRecheckSummary{
+		CheckedCount: aws.Int(len(integrations)),
+		Failures:     make([]*models.BulkSetScanIntervalResult, 0),
+	}
+	for i, integration := range integrations {
+		if errs[i] != nil {
+			zap.L().Warn("failed to recheck integration health",
+				zap.String("integrationId", *integration.IntegrationID), zap.Error(errs[i]))
+			summary.Failures = append(summary.Failures, &models.BulkSetScanIntervalResult{
+				IntegrationID: integration.IntegrationID,
+				Success:       aws.Bool(false),
+				ErrorMessage:  aws.String(errs[i].Error()),
+			})
+			continue
+		}
+		counts[*statuses[i]]++
+	}
+	summary.HealthyCount = aws.Int(counts[models.HealthStatusHealthy])
+	summary.DegradedCount = aws.Int(counts[models.HealthStatusDegraded])
+	summary.UnhealthyCount = aws.Int(counts[models.HealthStatusUnhealthy])
+	return summary, nil
+}
+
+// recheckHealth checks the health of an integration, then stores and returns its health status.
+func (api API) recheckHealth(integration *models.SourceIntegrationMetadata) (*string, error) {
+	eval, err := evaluateIntegrationHealthFunc(api, healthCheckInputForUpdate(
+		integration, &models.UpdateIntegrationSettingsInput{IntegrationID: integration.IntegrationID}))
+	if err != nil {
+		return nil, err
+	}
+
+	healthStatus, failedChecks := evaluatedHealth(eval, true)
+	_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{
+		IntegrationID:       integration.IntegrationID,
+		HealthStatus:        healthStatus,
+		FailedHealthChecks:  failedChecks,
+		LastHealthyTime:     lastHealthyTime(healthStatus),
+		LastHealthCheckTime: aws.Time(time.Now()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = recordHistory(&models.IntegrationHistoryRecord{
+		IntegrationID:      integration.IntegrationID,
+		Kind:               aws.String(models.HistoryKindHealth),
+		HealthStatus:       healthStatus,
+		FailedHealthChecks: failedChecks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = notifyHealthChange(integration, healthStatus, failedChecks); err != nil {
+		return nil, err
+	}
+	return healthStatus, nil
+}
@@ -0,0 +1,128 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+// recheckItem is a stored healthy integration, the account tells the fake health check which evaluation to return.
+func recheckItem(integrationID, integrationType, accountID string) map[string]*dynamodb.AttributeValue {
+	item := scanItem(integrationID, integrationType)
+	item["awsAccountId"] = &dynamodb.AttributeValue{S: aws.String(accountID)}
+	item["healthStatus"] = &dynamodb.AttributeValue{S: aws.String(models.HealthStatusHealthy)}
+	return item
+}
+
+// mockRecheckEvaluations fakes the health check of each account, the health check function is restored after the test.
+func mockRecheckEvaluations(t *testing.T, evaluations map[string]*integrationEvaluation) {
+	evaluateHealthFunc := evaluateIntegrationHealthFunc
+	t.Cleanup(func() { evaluateIntegrationHealthFunc = evaluateHealthFunc })
+	evaluateIntegrationHealthFunc = func(_ API, input *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+		eval, ok := evaluations[*input.AWSAccountID]
+		if !ok {
+			return nil, errors.New("sts unavailable")
+		}
+		return eval, nil
+	}
+}
+
+func TestRecheckIntegrationHealth(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+		recheckItem("healthy", models.IntegrationTypeAWS3, "111111111111"),
+		recheckItem("degraded", models.IntegrationTypeAWS3, "222222222222"),
+		recheckItem("unhealthy", models.IntegrationTypeAWSScan, "333333333333"),
+		recheckItem("failing", models.IntegrationTypeAWS3, "444444444444"),
+	}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	mockRecheckEvaluations(t, map[string]*integrationEvaluation{
+		"111111111111": {rolesHealthy: true, failedItems: make([]*string, 0)},
+		"222222222222": {rolesHealthy: true, failedItems: aws.StringSlice([]string{"s3Bucket:revoked"})},
+		"333333333333": {rolesHealthy: false, failedItems: make([]*string, 0)},
+	})
+
+	summary, err := apiTest.RecheckIntegrationHealth(&models.RecheckIntegrationHealthInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, *summary.CheckedCount)
+	assert.Equal(t, 1, *summary.HealthyCount)
+	assert.Equal(t, 1, *summary.DegradedCount)
+	assert.Equal(t, 1, *summary.UnhealthyCount)
+	require.Len(t, summary.Failures, 1)
+	assert.Equal(t, "failing", *summary.Failures[0].IntegrationID)
+	assert.Contains(t, *summary.Failures[0].ErrorMessage, "sts unavailable")
+
+	// The integration which failed its check is left as it is
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 3)
+	for _, call := range mockClient.Calls {
+		update, ok := call.Arguments.Get(0).(*dynamodb.UpdateItemInput)
+		if !ok || *update.Key["integrationId"].S != "unhealthy" {
+			continue
+		}
+		var values []string
+		for _, value := range update.ExpressionAttributeValues {
+			if value.S != nil {
+				values = append(values, *value.S)
+			}
+		}
+		assert.Contains(t, values, models.HealthStatusUnhealthy)
+	}
+}
+
+func TestRecheckIntegrationHealthType(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+		recheckItem("logs", models.IntegrationTypeAWS3, "111111111111"),
+		recheckItem("scan", models.IntegrationTypeAWSScan, "111111111111"),
+	}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", updatedIntegrationID("scan")).Return(&dynamodb.UpdateItemOutput{}, nil)
+	mockRecheckEvaluations(t, map[string]*integrationEvaluation{
+		"111111111111": {rolesHealthy: true, failedItems: make([]*string, 0)},
+	})
+
+	summary, err := apiTest.RecheckIntegrationHealth(&models.RecheckIntegrationHealthInput{
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *summary.CheckedCount)
+	assert.Equal(t, 1, *summary.HealthyCount)
+	assert.Empty(t, summary.Failures)
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 1)
+}
+
+func TestRecheckIntegrationHealthScanFails(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{TestErr: true}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+
+	summary, err := apiTest.RecheckIntegrationHealth(&models.RecheckIntegrationHealthInput{})
+	assert.Nil(t, summary)
+	assert.Error(t, err)
+}
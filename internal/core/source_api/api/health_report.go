@@ -0,0 +1,119 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	healthReportKeyPrefix = "health-reports/"
+
+	defaultHealthReportExpiry = time.Hour
+
+	// Health checks and scans included in a report
+	healthReportHistorySize = 10
+)
+
+// GenerateHealthReport runs the health check of an integration and renders its result to the HEALTH_REPORT_BUCKET,
+// along with the recent history of the integration.
+//
+// The report is returned as a presigned URL of the object, which can be shared with whoever should see it
+// until it expires. Only the identity of the integration is included: none of its settings, which can be secret.
+func (api API) GenerateHealthReport(input *models.GenerateHealthReportInput) (*models.HealthReportLink, error) {
+	if healthReportBucket == "" {
+		return nil, &genericapi.InvalidInputError{Message: "no health report bucket is configured"}
+	}
+	integration, err := getIntegrationForEdit(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	report, err := healthReport(api, integration, now)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to marshal health report: " + err.Error()}
+	}
+
+	key := healthReportKeyPrefix + *integration.IntegrationID + "/" + now.Format(time.RFC3339) + ".json"
+	_, err = exportClient.PutObject(&s3.PutObjectInput{
+		Body:        bytes.NewReader(body),
+		Bucket:      aws.String(healthReportBucket),
+		ContentType: aws.String("application/json"),
+		Key:         aws.String(key),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "s3.PutObject"}
+	}
+
+	expiry := defaultHealthReportExpiry
+	if input.ExpiresInMinutes != nil {
+		expiry = time.Duration(*input.ExpiresInMinutes) * time.Minute
+	}
+	request, _ := exportClient.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(healthReportBucket),
+		Key:    aws.String(key),
+	})
+	url, err := request.Presign(expiry)
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "s3.GetObjectRequest.Presign"}
+	}
+	return &models.HealthReportLink{URL: aws.String(url), ExpiresAt: aws.Time(now.Add(expiry))}, nil
+}
+
+func healthReport(api API, integration *models.SourceIntegrationMetadata, now time.Time) (*models.HealthReport, error) {
+	health, err := api.CheckIntegration(healthCheckInputForUpdate(integration, &models.UpdateIntegrationSettingsInput{}))
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*models.IntegrationHistoryRecord, 0)
+	if db.HistoryTableName != "" {
+		records, _, err := db.ListHistory(integration.IntegrationID, nil, healthReportHistorySize, nil)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, records...)
+	}
+
+	return &models.HealthReport{
+		GeneratedAt:        aws.Time(now),
+		IntegrationID:      integration.IntegrationID,
+		IntegrationLabel:   integration.IntegrationLabel,
+		IntegrationType:    integration.IntegrationType,
+		AWSAccountID:       integration.AWSAccountID,
+		Health:             health,
+		HealthStatus:       integration.HealthStatus,
+		FailedHealthChecks: integration.FailedHealthChecks,
+		LastHealthyTime:    integration.LastHealthyTime,
+		History:            history,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetObjectRequest presigns with static credentials, without reaching S3
+func (client *mockS3Client) GetObjectRequest(input *s3.GetObjectInput) (*request.Request, *s3.GetObjectOutput) {
+	return s3.New(session.Must(session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials("AKID", "SECRET", ""),
+		Region:      aws.String("us-west-2"),
+	}))).GetObjectRequest(input)
+}
+
+func TestGenerateHealthReport(t *testing.T) {
+	healthReportBucket = "reports"
+	defer func() { healthReportBucket = "" }()
+	healthCheckOverride = func(input *models.CheckIntegrationInput) *models.SourceIntegrationHealth {
+		return &models.SourceIntegrationHealth{
+			AWSAccountID:         input.AWSAccountID,
+			IntegrationType:      input.IntegrationType,
+			ProcessingRoleStatus: models.SourceIntegrationItemStatus{Healthy: aws.Bool(true)},
+			S3BucketsStatus: map[string]models.SourceIntegrationItemStatus{
+				"logs": {Healthy: aws.Bool(false), ErrorMessage: aws.String("AccessDenied")},
+			},
+		}
+	}
+	defer func() { healthCheckOverride = nil }()
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:            aws.String(testAccountID),
+		IntegrationID:           aws.String(testIntegrationID),
+		IntegrationLabel:        aws.String("prod-logs"),
+		IntegrationType:         aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:               aws.StringSlice([]string{"logs"}),
+		HealthStatus:            aws.String(models.HealthStatusDegraded),
+		FailedHealthChecks:      aws.StringSlice([]string{"s3Bucket:logs"}),
+		ScanCompleteCallbackURL: aws.String("https://hooks.example.com/scans?token=callback-secret"),
+	})
+	db.HistoryTableName = "history"
+	mockClient.MockQueryAttributes = []map[string]*dynamodb.AttributeValue{{
+		"integrationId": {S: aws.String(testIntegrationID)},
+		"recordId":      {S: aws.String("2020-06-01T00:00:00.000000000Z-1")},
+		"kind":          {S: aws.String(models.HistoryKindHealth)},
+		"healthStatus":  {S: aws.String(models.HealthStatusDegraded)},
+	}}
+	mockS3 := &mockS3Client{}
+	exportClient = mockS3
+	var put *s3.PutObjectInput
+	mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).
+		Run(func(args mock.Arguments) { put = args.Get(0).(*s3.PutObjectInput) })
+
+	result, err := apiTest.GenerateHealthReport(&models.GenerateHealthReportInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		ExpiresInMinutes: aws.Int(30),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "reports", *put.Bucket)
+	assert.True(t, strings.HasPrefix(*put.Key, "health-reports/"+testIntegrationID+"/"))
+	link, err := url.Parse(*result.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "/"+*put.Key, link.Path)
+	assert.Equal(t, "1800", link.Query().Get("X-Amz-Expires"))
+
+	body, err := ioutil.ReadAll(put.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "callback-secret")
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &report))
+	for _, section := range []string{"generatedAt", "integrationId", "integrationLabel", "health", "healthStatus", "history"} {
+		assert.Contains(t, report, section)
+	}
+	assert.Equal(t, map[string]interface{}{"healthy": false, "errorMessage": "AccessDenied"},
+		report["health"].(map[string]interface{})["s3BucketsStatus"].(map[string]interface{})["logs"])
+	history := report["history"].([]interface{})
+	require.Len(t, history, 1)
+	assert.Equal(t, models.HistoryKindHealth, history[0].(map[string]interface{})["kind"])
+}
+
+func TestGenerateHealthReportNotConfigured(t *testing.T) {
+	result, err := apiTest.GenerateHealthReport(&models.GenerateHealthReportInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "no health report bucket is configured"}, err)
+}
@@ -0,0 +1,157 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const (
+	defaultHistoryPageSize = 25
+	compactionPageSize     = 100
+)
+
+// The most records the history of an integration keeps after a compaction
+var maxHistoryRecords = 1000
+
+// GetIntegrationHistory returns a page of the health and scan history of an integration, most recent first.
+//
+// Deployments without a history table keep no history, the page is then empty.
+func (API) GetIntegrationHistory(input *models.GetIntegrationHistoryInput) (*models.IntegrationHistoryPage, error) {
+	if db.HistoryTableName == "" {
+		return &models.IntegrationHistoryPage{Records: make([]*models.IntegrationHistoryRecord, 0)}, nil
+	}
+	pageSize := defaultHistoryPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+
+	records, lastRecordID, err := db.ListHistory(input.IntegrationID, input.Kind, pageSize, input.ExclusiveStartKey)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = make([]*models.IntegrationHistoryRecord, 0)
+	}
+	return &models.IntegrationHistoryPage{Records: records, LastEvaluatedKey: lastRecordID}, nil
+}
+
+// recordHistory adds a health check or a scan to the history of an integration, if the deployment keeps one.
+//
+// Like the change history, failing to record it only fails the operation with strict side effects.
+func recordHistory(record *models.IntegrationHistoryRecord) error {
+	if db.HistoryTableName == "" {
+		return nil
+	}
+	return runSideEffect(sideEffectHistory, record.IntegrationID, func() error {
+		now := time.Now().UTC()
+		record.RecordID = historyRecordID(now)
+		record.RecordedAt = aws.Time(now)
+		return db.PutHistoryRecord(record)
+	})
+}
+
+// historyRecordID returns the ID of a history record recorded at the given time, the IDs are sorted by time.
+func historyRecordID(recordedAt time.Time) *string {
+	return aws.String(recordedAt.Format(changeIDTimeFormat) + "-" + uuid.New().String())
+}
+
+// CompactIntegrationHistory removes the duplicated records of the history of an integration, which a migration
+// may leave behind, and the oldest records above the most an integration keeps.
+//
+// Records are compared in the order they were recorded, a record identical to the one before it is a duplicate.
+// Compacting a history again removes nothing.
+func (API) CompactIntegrationHistory(input *models.CompactIntegrationHistoryInput) (*models.HistoryCompaction, error) {
+	if db.HistoryTableName == "" {
+		return &models.HistoryCompaction{IntegrationID: input.IntegrationID, RecordsRemoved: aws.Int(0), RecordsKept: aws.Int(0)}, nil
+	}
+
+	var records []*models.IntegrationHistoryRecord
+	var exclusiveStartKey *string
+	for {
+		page, lastRecordID, err := db.ListHistory(input.IntegrationID, nil, compactionPageSize, exclusiveStartKey)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+		if lastRecordID == nil {
+			break
+		}
+		exclusiveStartKey = lastRecordID
+	}
+
+	removed := compactHistory(records)
+	recordIDs := make([]*string, len(removed))
+	for i, record := range removed {
+		recordIDs[i] = record.RecordID
+	}
+	if err := db.DeleteHistoryRecords(input.IntegrationID, recordIDs); err != nil {
+		return nil, err
+	}
+	return &models.HistoryCompaction{
+		IntegrationID:  input.IntegrationID,
+		RecordsRemoved: aws.Int(len(removed)),
+		RecordsKept:    aws.Int(len(records) - len(removed)),
+	}, nil
+}
+
+// compactHistory returns the records to remove from a history: the duplicates, then the oldest records above the cap.
+func compactHistory(records []*models.IntegrationHistoryRecord) []*models.IntegrationHistoryRecord {
+	sorted := make([]*models.IntegrationHistoryRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		left, right := aws.TimeValue(sorted[i].RecordedAt), aws.TimeValue(sorted[j].RecordedAt)
+		if !left.Equal(right) {
+			return left.Before(right)
+		}
+		return aws.StringValue(sorted[i].RecordID) < aws.StringValue(sorted[j].RecordID)
+	})
+
+	var removed, kept []*models.IntegrationHistoryRecord
+	for _, record := range sorted {
+		if len(kept) > 0 && sameHistoryRecord(kept[len(kept)-1], record) {
+			removed = append(removed, record)
+			continue
+		}
+		kept = append(kept, record)
+	}
+	if len(kept) > maxHistoryRecords {
+		removed = append(removed, kept[:len(kept)-maxHistoryRecords]...)
+	}
+	return removed
+}
+
+// sameHistoryRecord is whether two records describe the same health check or scan, whatever their IDs and expiry.
+func sameHistoryRecord(left, right *models.IntegrationHistoryRecord) bool {
+	if !aws.TimeValue(left.RecordedAt).Equal(aws.TimeValue(right.RecordedAt)) {
+		return false
+	}
+	leftContent, rightContent := *left, *right
+	leftContent.RecordID, rightContent.RecordID = nil, nil
+	leftContent.RecordedAt, rightContent.RecordedAt = nil, nil
+	leftContent.ExpiresAt, rightContent.ExpiresAt = nil, nil
+	return reflect.DeepEqual(leftContent, rightContent)
+}
@@ -0,0 +1,184 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func TestGetIntegrationHistory(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{MockQueryAttributes: []map[string]*dynamodb.AttributeValue{{
+		"integrationId": {S: aws.String(testIntegrationID)},
+		"recordId":      {S: aws.String("2020-06-01T12:00:00.000000000Z-1")},
+		"kind":          {S: aws.String(models.HistoryKindScan)},
+		"scanStatus":    {S: aws.String(models.StatusOK)},
+	}}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", HistoryTableName: "history"}
+
+	result, err := apiTest.GetIntegrationHistory(&models.GetIntegrationHistoryInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	require.Len(t, result.Records, 1)
+	assert.Equal(t, models.StatusOK, *result.Records[0].ScanStatus)
+	assert.Nil(t, result.LastEvaluatedKey)
+}
+
+func TestGetIntegrationHistoryNotKept(t *testing.T) {
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{TestErr: true}, TableName: "test"}
+
+	result, err := apiTest.GetIntegrationHistory(&models.GetIntegrationHistoryInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Empty(t, result.Records)
+	assert.NoError(t, recordHistory(&models.IntegrationHistoryRecord{
+		IntegrationID: aws.String(testIntegrationID),
+		Kind:          aws.String(models.HistoryKindHealth),
+	}))
+}
+
+// mockHistoryTableClient stores the history records of an integration, by record ID
+type mockHistoryTableClient struct {
+	*modelstest.MockDDBClient
+	records map[string]*models.IntegrationHistoryRecord
+}
+
+func (client *mockHistoryTableClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(client.records))
+	for _, record := range client.records {
+		item, err := dynamodbattribute.MarshalMap(record)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &dynamodb.QueryOutput{Items: items}, nil
+}
+
+func (client *mockHistoryTableClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	var record models.IntegrationHistoryRecord
+	if err := dynamodbattribute.UnmarshalMap(input.Item, &record); err != nil {
+		return nil, err
+	}
+	client.records[*record.RecordID] = &record
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (client *mockHistoryTableClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	for _, request := range input.RequestItems["history"] {
+		delete(client.records, *request.DeleteRequest.Key["recordId"].S)
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (client *mockHistoryTableClient) recordIDs() []string {
+	result := make([]string, 0, len(client.records))
+	for recordID := range client.records {
+		result = append(result, recordID)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func mockHistoryTable(records ...*models.IntegrationHistoryRecord) *mockHistoryTableClient {
+	client := &mockHistoryTableClient{
+		MockDDBClient: &modelstest.MockDDBClient{},
+		records:       make(map[string]*models.IntegrationHistoryRecord, len(records)),
+	}
+	for _, record := range records {
+		client.records[*record.RecordID] = record
+	}
+	db = &ddb.DDB{Client: client, TableName: "test", HistoryTableName: "history"}
+	return client
+}
+
+func scanRecord(recordID string, recordedAt time.Time, objectsProcessed int64) *models.IntegrationHistoryRecord {
+	return &models.IntegrationHistoryRecord{
+		IntegrationID:    aws.String(testIntegrationID),
+		RecordID:         aws.String(recordID),
+		Kind:             aws.String(models.HistoryKindScan),
+		RecordedAt:       aws.Time(recordedAt),
+		ScanStatus:       aws.String(models.StatusOK),
+		ObjectsProcessed: aws.Int64(objectsProcessed),
+	}
+}
+
+func TestCompactIntegrationHistory(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	client := mockHistoryTable(
+		scanRecord("c", start.Add(2*time.Minute), 10),
+		scanRecord("a", start, 10),
+		// The migration copied these records again, with another ID
+		scanRecord("a-copy", start, 10),
+		scanRecord("c-copy", start.Add(2*time.Minute), 10),
+		// Same time, other content
+		scanRecord("b", start, 20),
+		scanRecord("d", start.Add(3*time.Minute), 10),
+	)
+	input := &models.CompactIntegrationHistoryInput{IntegrationID: aws.String(testIntegrationID)}
+
+	result, err := apiTest.CompactIntegrationHistory(input)
+	require.NoError(t, err)
+	assert.Equal(t, &models.HistoryCompaction{
+		IntegrationID:  aws.String(testIntegrationID),
+		RecordsRemoved: aws.Int(2),
+		RecordsKept:    aws.Int(4),
+	}, result)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, client.recordIDs())
+
+	// Compacting the history again removes nothing
+	result, err = apiTest.CompactIntegrationHistory(input)
+	require.NoError(t, err)
+	assert.Equal(t, 0, *result.RecordsRemoved)
+	assert.Equal(t, 4, *result.RecordsKept)
+}
+
+func TestCompactIntegrationHistoryCap(t *testing.T) {
+	defer func(max int) { maxHistoryRecords = max }(maxHistoryRecords)
+	maxHistoryRecords = 2
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	client := mockHistoryTable(
+		scanRecord("3", start.Add(3*time.Minute), 30),
+		scanRecord("1", start.Add(time.Minute), 10),
+		scanRecord("1-copy", start.Add(time.Minute), 10),
+		scanRecord("2", start.Add(2*time.Minute), 20),
+	)
+
+	result, err := apiTest.CompactIntegrationHistory(&models.CompactIntegrationHistoryInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, 2, *result.RecordsRemoved)
+	assert.Equal(t, []string{"2", "3"}, client.recordIDs())
+}
+
+func TestCompactIntegrationHistoryNotKept(t *testing.T) {
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{TestErr: true}, TableName: "test"}
+
+	result, err := apiTest.CompactIntegrationHistory(&models.CompactIntegrationHistoryInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, 0, *result.RecordsRemoved)
+}
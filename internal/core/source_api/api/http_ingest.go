@@ -0,0 +1,190 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The API keys of the http-ingest integrations are named after the integration, the ingest function reads the
+// integration of a request from the name of its key
+const httpIngestKeyPrefix = "panther-http-ingest-"
+
+// httpIngestHealthChecker checks the API key of the http-ingest integrations.
+type httpIngestHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration: the API key the log events are posted with must be enabled.
+//
+// The key is created with the integration, so there is nothing to check until then.
+func (httpIngestHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	status, err := api.CheckIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+	eval := &integrationEvaluation{rolesHealthy: true, failedItems: make([]*string, 0), health: status}
+	if status.HTTPIngestKeyStatus.Healthy != nil {
+		eval.addItems("httpIngestKey:", map[string]models.SourceIntegrationItemStatus{
+			aws.StringValue(integration.HTTPIngestAPIKeyID): status.HTTPIngestKeyStatus,
+		})
+	}
+	return eval, nil
+}
+
+// checkHTTPIngestKey verifies the API key exists and is enabled.
+func checkHTTPIngestKey(apiKeyID *string) models.SourceIntegrationItemStatus {
+	start := time.Now()
+	key, err := apiGatewayClient.GetApiKey(&apigateway.GetApiKeyInput{ApiKey: apiKeyID})
+	switch {
+	case isThrottlingError(err):
+		zap.L().Warn("http ingest key check throttled", zap.String("apiKeyId", *apiKeyID), zap.Error(err))
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			Inconclusive:  aws.Bool(true),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	case err != nil:
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	case !aws.BoolValue(key.Enabled):
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String("the API key is disabled"),
+			LatencyMillis: millisSince(start),
+		}
+	}
+	return models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(true),
+		LatencyMillis: millisSince(start),
+	}
+}
+
+// provisionHTTPIngestKey creates the API key of a new http-ingest integration. The value of the key is only set
+// on the integration which is returned, it isn't stored.
+func provisionHTTPIngestKey(integration *models.SourceIntegrationMetadata) error {
+	key, err := createHTTPIngestKey(integration.IntegrationID)
+	if err != nil {
+		return err
+	}
+	integration.HTTPIngestEndpoint = aws.String(httpIngestEndpoint)
+	integration.HTTPIngestAPIKeyID = key.Id
+	integration.HTTPIngestAPIKey = key.Value
+	return nil
+}
+
+// createHTTPIngestKey creates an API key of an integration, and adds it to the usage plan of the ingest endpoint.
+func createHTTPIngestKey(integrationID *string) (*apigateway.ApiKey, error) {
+	key, err := apiGatewayClient.CreateApiKey(&apigateway.CreateApiKeyInput{
+		Name:        aws.String(httpIngestKeyPrefix + *integrationID),
+		Description: aws.String("Posts the log events of the Panther integration " + *integrationID),
+		Enabled:     aws.Bool(true),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "apigateway.CreateApiKey"}
+	}
+	_, err = apiGatewayClient.CreateUsagePlanKey(&apigateway.CreateUsagePlanKeyInput{
+		UsagePlanId: aws.String(httpIngestUsagePlanID),
+		KeyId:       key.Id,
+		KeyType:     aws.String("API_KEY"),
+	})
+	if err != nil {
+		deleteHTTPIngestKey(integrationID, key.Id)
+		return nil, &genericapi.AWSError{Err: err, Method: "apigateway.CreateUsagePlanKey"}
+	}
+	return key, nil
+}
+
+// deleteHTTPIngestKey deletes an API key of an integration, the events posted with it are rejected right away.
+//
+// Failures are logged, a key which couldn't be deleted has to be deleted manually.
+func deleteHTTPIngestKey(integrationID, apiKeyID *string) {
+	if apiKeyID == nil {
+		return
+	}
+	_, err := apiGatewayClient.DeleteApiKey(&apigateway.DeleteApiKeyInput{ApiKey: apiKeyID})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == apigateway.ErrCodeNotFoundException {
+		return
+	}
+	if err != nil {
+		zap.L().Error("failed to delete the API key of the integration, it has to be deleted manually",
+			zap.String("integrationId", *integrationID),
+			zap.String("apiKeyId", *apiKeyID),
+			zap.Error(err))
+	}
+}
+
+// RotateHTTPIngestKey replaces the API key of an http-ingest integration.
+//
+// The previous key is deleted once the new one is stored, the events posted with it are rejected from then on.
+func (API) RotateHTTPIngestKey(input *models.RotateHTTPIngestKeyInput) (*models.HTTPIngestKey, error) {
+	integration, err := db.GetIntegration(input.IntegrationID, false)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeHTTPIngest {
+		return nil, &genericapi.InvalidInputError{Message: "only http-ingest integrations have an API key"}
+	}
+
+	key, err := createHTTPIngestKey(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{
+		IntegrationID:      input.IntegrationID,
+		HTTPIngestAPIKeyID: key.Id,
+	})
+	if err != nil {
+		deleteHTTPIngestKey(input.IntegrationID, key.Id)
+		return nil, err
+	}
+	deleteHTTPIngestKey(input.IntegrationID, integration.HTTPIngestAPIKeyID)
+
+	err = recordChange(input.IntegrationID, models.ChangeOperationUpdated, input.UserID, []*models.IntegrationFieldChange{
+		{
+			Field:   aws.String("httpIngestApiKeyId"),
+			Action:  aws.String(models.FieldChanged),
+			Current: aws.StringValue(integration.HTTPIngestAPIKeyID),
+			Desired: *key.Id,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.HTTPIngestKey{
+		IntegrationID: input.IntegrationID,
+		Endpoint:      aws.String(httpIngestEndpoint),
+		APIKeyID:      key.Id,
+		APIKey:        key.Value,
+	}, nil
+}
@@ -0,0 +1,252 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testHTTPIngestEndpoint  = "https://abcdef1234.execute-api.us-west-2.amazonaws.com/v1/"
+	testHTTPIngestUsagePlan = "usage-plan-id"
+	testHTTPIngestKeyID     = "key-id"
+	testHTTPIngestKey       = "key-value"
+)
+
+type mockAPIGatewayClient struct {
+	apigatewayiface.APIGatewayAPI
+	mock.Mock
+}
+
+func (client *mockAPIGatewayClient) CreateApiKey(input *apigateway.CreateApiKeyInput) (*apigateway.ApiKey, error) {
+	args := client.Called(input)
+	return args.Get(0).(*apigateway.ApiKey), args.Error(1)
+}
+
+func (client *mockAPIGatewayClient) CreateUsagePlanKey(
+	input *apigateway.CreateUsagePlanKeyInput) (*apigateway.UsagePlanKey, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*apigateway.UsagePlanKey), args.Error(1)
+}
+
+func (client *mockAPIGatewayClient) DeleteApiKey(input *apigateway.DeleteApiKeyInput) (*apigateway.DeleteApiKeyOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*apigateway.DeleteApiKeyOutput), args.Error(1)
+}
+
+func (client *mockAPIGatewayClient) GetApiKey(input *apigateway.GetApiKeyInput) (*apigateway.ApiKey, error) {
+	args := client.Called(input)
+	return args.Get(0).(*apigateway.ApiKey), args.Error(1)
+}
+
+func mockHTTPIngest(t *testing.T) *mockAPIGatewayClient {
+	previousClient, previousEndpoint, previousPlan := apiGatewayClient, httpIngestEndpoint, httpIngestUsagePlanID
+	t.Cleanup(func() {
+		apiGatewayClient, httpIngestEndpoint, httpIngestUsagePlanID = previousClient, previousEndpoint, previousPlan
+	})
+	mockAPIGateway := &mockAPIGatewayClient{}
+	apiGatewayClient = mockAPIGateway
+	httpIngestEndpoint, httpIngestUsagePlanID = testHTTPIngestEndpoint, testHTTPIngestUsagePlan
+	return mockAPIGateway
+}
+
+func putHTTPIngestIntegrations(t *testing.T, labels ...string) ([]*models.SourceIntegrationMetadata, *mockBatchWriteDDBClient, error) {
+	// An http-ingest integration already exists
+	existing, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		IntegrationID:    aws.String(testIntegrationID),
+		IntegrationType:  aws.String(models.IntegrationTypeHTTPIngest),
+		IntegrationLabel: aws.String("okta"),
+	})
+	require.NoError(t, err)
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{
+		MockScanAttributes: []map[string]*dynamodb.AttributeValue{existing},
+	}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	input := &models.PutIntegrationInput{}
+	for _, label := range labels {
+		input.Integrations = append(input.Integrations, &models.PutIntegrationSettings{
+			IntegrationLabel: aws.String(label),
+			IntegrationType:  aws.String(models.IntegrationTypeHTTPIngest),
+			UserID:           aws.String(testUserID),
+		})
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+	out, err := apiTest.PutIntegration(input)
+	return out, mockClient, err
+}
+
+func TestPutHTTPIngestIntegration(t *testing.T) {
+	mockAPIGateway := mockHTTPIngest(t)
+	mockAPIGateway.On("CreateApiKey", mock.Anything).
+		Return(&apigateway.ApiKey{Id: aws.String(testHTTPIngestKeyID), Value: aws.String(testHTTPIngestKey)}, nil)
+	mockAPIGateway.On("CreateUsagePlanKey", &apigateway.CreateUsagePlanKeyInput{
+		UsagePlanId: aws.String(testHTTPIngestUsagePlan),
+		KeyId:       aws.String(testHTTPIngestKeyID),
+		KeyType:     aws.String("API_KEY"),
+	}).Return(&apigateway.UsagePlanKey{}, nil)
+
+	// Integrations without an account are never duplicates of one another
+	out, mockClient, err := putHTTPIngestIntegrations(t, "github")
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, testHTTPIngestEndpoint, *out[0].HTTPIngestEndpoint)
+	assert.Equal(t, testHTTPIngestKeyID, *out[0].HTTPIngestAPIKeyID)
+	assert.Equal(t, testHTTPIngestKey, *out[0].HTTPIngestAPIKey)
+
+	// The key is named after the integration
+	createInput := mockAPIGateway.Calls[0].Arguments.Get(0).(*apigateway.CreateApiKeyInput)
+	assert.Equal(t, "panther-http-ingest-"+*out[0].IntegrationID, *createInput.Name)
+	assert.True(t, *createInput.Enabled)
+
+	// The value of the key isn't stored
+	require.Len(t, mockClient.written, 1)
+	assert.Contains(t, mockClient.written[0], "httpIngestApiKeyId")
+	assert.NotContains(t, mockClient.written[0], "httpIngestApiKey")
+	mockAPIGateway.AssertExpectations(t)
+}
+
+func TestPutHTTPIngestIntegrationUsagePlanFails(t *testing.T) {
+	mockAPIGateway := mockHTTPIngest(t)
+	mockAPIGateway.On("CreateApiKey", mock.Anything).
+		Return(&apigateway.ApiKey{Id: aws.String(testHTTPIngestKeyID), Value: aws.String(testHTTPIngestKey)}, nil)
+	mockAPIGateway.On("CreateUsagePlanKey", mock.Anything).
+		Return((*apigateway.UsagePlanKey)(nil), awserr.New(apigateway.ErrCodeLimitExceededException, "limit", nil))
+	mockAPIGateway.On("DeleteApiKey", &apigateway.DeleteApiKeyInput{ApiKey: aws.String(testHTTPIngestKeyID)}).
+		Return(&apigateway.DeleteApiKeyOutput{}, nil)
+
+	out, mockClient, err := putHTTPIngestIntegrations(t, "github")
+	assert.Nil(t, out)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	assert.Empty(t, mockClient.written)
+	mockAPIGateway.AssertExpectations(t)
+}
+
+func TestPutHTTPIngestIntegrationsRollback(t *testing.T) {
+	mockAPIGateway := mockHTTPIngest(t)
+	mockAPIGateway.On("CreateApiKey", mock.Anything).
+		Return(&apigateway.ApiKey{Id: aws.String(testHTTPIngestKeyID), Value: aws.String(testHTTPIngestKey)}, nil).Once()
+	mockAPIGateway.On("CreateApiKey", mock.Anything).
+		Return((*apigateway.ApiKey)(nil), awserr.New(apigateway.ErrCodeLimitExceededException, "limit", nil)).Once()
+	mockAPIGateway.On("CreateUsagePlanKey", mock.Anything).Return(&apigateway.UsagePlanKey{}, nil)
+	mockAPIGateway.On("DeleteApiKey", &apigateway.DeleteApiKeyInput{ApiKey: aws.String(testHTTPIngestKeyID)}).
+		Return(&apigateway.DeleteApiKeyOutput{}, nil)
+
+	// The key of the first integration is deleted when the second one can't get a key
+	out, _, err := putHTTPIngestIntegrations(t, "github", "slack")
+	assert.Nil(t, out)
+	assert.Error(t, err)
+	mockAPIGateway.AssertExpectations(t)
+}
+
+func TestRotateHTTPIngestKey(t *testing.T) {
+	mockAPIGateway := mockHTTPIngest(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeHTTPIngest),
+		HTTPIngestEndpoint: aws.String(testHTTPIngestEndpoint),
+		HTTPIngestAPIKeyID: aws.String("old-key-id"),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	mockClient.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+	mockAPIGateway.On("CreateApiKey", mock.Anything).
+		Return(&apigateway.ApiKey{Id: aws.String(testHTTPIngestKeyID), Value: aws.String(testHTTPIngestKey)}, nil)
+	mockAPIGateway.On("CreateUsagePlanKey", mock.Anything).Return(&apigateway.UsagePlanKey{}, nil)
+	mockAPIGateway.On("DeleteApiKey", &apigateway.DeleteApiKeyInput{ApiKey: aws.String("old-key-id")}).
+		Return(&apigateway.DeleteApiKeyOutput{}, nil)
+
+	result, err := apiTest.RotateHTTPIngestKey(&models.RotateHTTPIngestKeyInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, &models.HTTPIngestKey{
+		IntegrationID: aws.String(testIntegrationID),
+		Endpoint:      aws.String(testHTTPIngestEndpoint),
+		APIKeyID:      aws.String(testHTTPIngestKeyID),
+		APIKey:        aws.String(testHTTPIngestKey),
+	}, result)
+	mockAPIGateway.AssertExpectations(t)
+	mockClient.AssertCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestRotateHTTPIngestKeyNotHTTPIngest(t *testing.T) {
+	mockHTTPIngest(t)
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+	})
+
+	result, err := apiTest.RotateHTTPIngestKey(&models.RotateHTTPIngestKeyInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestDeleteHTTPIngestIntegration(t *testing.T) {
+	mockAPIGateway := mockHTTPIngest(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeHTTPIngest),
+		HTTPIngestAPIKeyID: aws.String(testHTTPIngestKeyID),
+	})
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	mockAPIGateway.On("DeleteApiKey", &apigateway.DeleteApiKeyInput{ApiKey: aws.String(testHTTPIngestKeyID)}).
+		Return(&apigateway.DeleteApiKeyOutput{}, nil)
+
+	require.NoError(t, apiTest.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: aws.String(testIntegrationID)}))
+	mockAPIGateway.AssertExpectations(t)
+}
+
+func TestCheckHTTPIngestKey(t *testing.T) {
+	mockAPIGateway := mockHTTPIngest(t)
+	mockAPIGateway.On("GetApiKey", &apigateway.GetApiKeyInput{ApiKey: aws.String(testHTTPIngestKeyID)}).
+		Return(&apigateway.ApiKey{Id: aws.String(testHTTPIngestKeyID), Enabled: aws.Bool(false)}, nil)
+
+	eval, err := evaluateIntegrationHealth(apiTest, &models.CheckIntegrationInput{
+		IntegrationType:    aws.String(models.IntegrationTypeHTTPIngest),
+		HTTPIngestAPIKeyID: aws.String(testHTTPIngestKeyID),
+	})
+	require.NoError(t, err)
+	assert.False(t, eval.passing())
+	assert.Equal(t, aws.StringSlice([]string{"httpIngestKey:" + testHTTPIngestKeyID}), eval.failedItems)
+
+	// The key is created with the integration
+	eval, err = evaluateIntegrationHealth(apiTest, &models.CheckIntegrationInput{
+		IntegrationType: aws.String(models.IntegrationTypeHTTPIngest),
+	})
+	require.NoError(t, err)
+	assert.True(t, eval.passing())
+}
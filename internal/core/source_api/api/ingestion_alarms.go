@@ -0,0 +1,295 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"go.uber.org/zap"
+
+	metricsmodels "github.com/panther-labs/panther/api/lambda/metrics/models"
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+const (
+	// The number of integrations whose ingestion alarms are checked at the same time
+	maxConcurrentIngestionAlarmChecks = 10
+
+	// The period the classification error rate of an integration is computed over
+	eventErrorRatePeriod = 15 * time.Minute
+)
+
+// ingestionAlarm is the evaluation of an ingestion alarm of an integration, and what its alert says.
+type ingestionAlarm struct {
+	name        string
+	raised      bool
+	title       string
+	description string
+	severity    string
+}
+
+// CheckIngestionAlarms raises and resolves the ingestion alarms of the log analysis integrations.
+//
+// The alarms are evaluated from the metrics the log processor publishes per source: an integration is silent when it
+// delivered no events over its SilenceThresholdMinutes, and its error rate is the fraction of its log lines which
+// failed to classify over the last 15 minutes. Integrations created within their silence threshold aren't silent yet.
+// An alert is sent to the notification targets when an alarm is raised, and an INFO one when it's resolved.
+func (API) CheckIngestionAlarms(*models.CheckIngestionAlarmsInput) (*models.IngestionAlarmsSummary, error) {
+	stored, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+	integrations := make([]*models.SourceIntegrationMetadata, 0, len(stored))
+	for _, integration := range stored {
+		if integration.SourceIntegrationMetadata == nil || !logIntegration(integration.IntegrationType) {
+			continue
+		}
+		if integration.SilenceThresholdMinutes == nil && integration.EventErrorRateThreshold == nil &&
+			len(integration.IngestionAlarms) == 0 {
+
+			continue
+		}
+		integrations = append(integrations, integration.SourceIntegrationMetadata)
+	}
+
+	now := time.Now()
+	raised, resolved := make([]int, len(integrations)), make([]int, len(integrations))
+	errs := forEachConcurrently(len(integrations), maxConcurrentIngestionAlarmChecks, func(i int) error {
+		var err error
+		raised[i], resolved[i], err = checkIngestionAlarms(integrations[i], now)
+		return err
+	})
+
+	summary := &models.IngestionAlarmsSummary{
+		CheckedCount: aws.Int(len(integrations)),
+		Failures:     make([]*models.BulkSetScanIntervalResult, 0),
+	}
+	var raisedCount, resolvedCount int
+	for i, integration := range integrations {
+		if errs[i] != nil {
+			zap.L().Warn("failed to check ingestion alarms",
+				zap.String("integrationId", *integration.IntegrationID), zap.Error(errs[i]))
+			summary.Failures = append(summary.Failures, &models.BulkSetScanIntervalResult{
+				IntegrationID: integration.IntegrationID,
+				Success:       aws.Bool(false),
+				ErrorMessage:  aws.String(errs[i].Error()),
+			})
+			continue
+		}
+		raisedCount += raised[i]
+		resolvedCount += resolved[i]
+	}
+	summary.RaisedCount = aws.Int(raisedCount)
+	summary.ResolvedCount = aws.Int(resolvedCount)
+	return summary, nil
+}
+
+// checkIngestionAlarms evaluates the alarms of an integration, then stores and notifies those which changed.
+//
+// A stored alarm whose threshold was removed since it was raised is dropped without an alert.
+func checkIngestionAlarms(integration *models.SourceIntegrationMetadata, now time.Time) (int, int, error) {
+	alarms, err := evaluateIngestionAlarms(integration, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	previous := make(map[string]bool, len(integration.IngestionAlarms))
+	for _, name := range integration.IngestionAlarms {
+		previous[*name] = true
+	}
+
+	current := make([]*string, 0, len(alarms))
+	var changed []*ingestionAlarm
+	var raised, resolved int
+	for _, alarm := range alarms {
+		if alarm.raised {
+			current = append(current, aws.String(alarm.name))
+		}
+		switch {
+		case alarm.raised && !previous[alarm.name]:
+			changed = append(changed, alarm)
+			raised++
+		case !alarm.raised && previous[alarm.name]:
+			changed = append(changed, alarm)
+			resolved++
+		}
+	}
+	if len(changed) == 0 && len(current) == len(integration.IngestionAlarms) {
+		return 0, 0, nil
+	}
+
+	_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{
+		IntegrationID:   integration.IntegrationID,
+		IngestionAlarms: current,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, alarm := range changed {
+		if err = notifyIngestionAlarm(integration, alarm); err != nil {
+			return 0, 0, err
+		}
+	}
+	return raised, resolved, nil
+}
+
+// evaluateIngestionAlarms evaluates each ingestion alarm the integration configures as of now.
+func evaluateIngestionAlarms(integration *models.SourceIntegrationMetadata, now time.Time) ([]*ingestionAlarm, error) {
+	var alarms []*ingestionAlarm
+	label := aws.StringValue(integration.IntegrationLabel)
+
+	if integration.SilenceThresholdMinutes != nil {
+		period := time.Duration(*integration.SilenceThresholdMinutes) * time.Minute
+		if aws.TimeValue(integration.CreatedAtTime).Before(now.Add(-period)) {
+			sums, err := sumIngestionMetrics(integration.IntegrationID, period, now, metricsmodels.MetricEventsProcessed)
+			if err != nil {
+				return nil, err
+			}
+			alarms = append(alarms, &ingestionAlarm{
+				name:   models.IngestionAlarmSilence,
+				raised: sums[0] == 0,
+				title:  "Source silent: " + label,
+				description: fmt.Sprintf("%.0f events delivered over the last %d minutes",
+					sums[0], *integration.SilenceThresholdMinutes),
+				severity: models.SeverityHigh,
+			})
+		}
+	}
+
+	if integration.EventErrorRateThreshold != nil {
+		sums, err := sumIngestionMetrics(integration.IntegrationID, eventErrorRatePeriod, now,
+			metricsmodels.MetricEventsProcessed, metricsmodels.MetricClassificationErrors)
+		if err != nil {
+			return nil, err
+		}
+		events, failed := sums[0], sums[1]
+		var errorRate float64
+		if failed > 0 {
+			errorRate = failed / (events + failed)
+		}
+		alarms = append(alarms, &ingestionAlarm{
+			name:   models.IngestionAlarmErrorRate,
+			raised: errorRate > *integration.EventErrorRateThreshold,
+			title:  "Source event error rate: " + label,
+			description: fmt.Sprintf("%.0f of %.0f log lines failed to classify over the last %d minutes (%.1f%%), "+
+				"the threshold is %.1f%%", failed, events+failed, int(eventErrorRatePeriod/time.Minute),
+				100*errorRate, 100**integration.EventErrorRateThreshold),
+			severity: models.SeverityMedium,
+		})
+	}
+	return alarms, nil
+}
+
+// sumIngestionMetrics returns the sum of each metric the log processor published for an integration over the period.
+//
+// A metric without data points over the period sums to 0.
+func sumIngestionMetrics(integrationID *string, period time.Duration, now time.Time, metricNames ...string) ([]float64, error) {
+	queries := make([]*cloudwatch.MetricDataQuery, len(metricNames))
+	for i, metricName := range metricNames {
+		queries[i] = &cloudwatch.MetricDataQuery{
+			Id: aws.String("m" + strconv.Itoa(i)),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  aws.String(metrics.Namespace),
+					MetricName: aws.String(metricName),
+					Dimensions: []*cloudwatch.Dimension{
+						{Name: aws.String(metricsmodels.DimensionSourceID), Value: integrationID},
+					},
+				},
+				Period: aws.Int64(int64(period / time.Second)),
+				Stat:   aws.String(cloudwatch.StatisticSum),
+			},
+		}
+	}
+
+	sums := make([]float64, len(metricNames))
+	err := cloudWatchClient.GetMetricDataPages(&cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(now.Add(-period)),
+		EndTime:           aws.Time(now),
+		MetricDataQueries: queries,
+	}, func(page *cloudwatch.GetMetricDataOutput, _ bool) bool {
+		for _, result := range page.MetricDataResults {
+			i, err := strconv.Atoi(strings.TrimPrefix(aws.StringValue(result.Id), "m"))
+			if err != nil || i < 0 || i >= len(sums) {
+				continue
+			}
+			for _, value := range result.Values {
+				sums[i] += aws.Float64Value(value)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "cloudwatch.GetMetricData"}
+	}
+	return sums, nil
+}
+
+// notifyIngestionAlarm sends an alert to the notification targets of an integration when an ingestion alarm is
+// raised or resolved.
+//
+// Like the health changes, the alert is a side effect.
+func notifyIngestionAlarm(integration *models.SourceIntegrationMetadata, alarm *ingestionAlarm) error {
+	if alertQueueURL == "" {
+		return nil
+	}
+	severity, description := alarm.severity, alarm.description
+	if !alarm.raised {
+		severity, description = models.SeverityInfo, "resolved, "+description
+	}
+	alert := &alertmodels.Alert{
+		CreatedAt:           aws.Time(time.Now().UTC()),
+		OutputIDs:           integration.NotificationTargets,
+		PolicyID:            integration.IntegrationID,
+		PolicyName:          aws.String(alarm.title),
+		PolicyDescription:   aws.String(description),
+		Severity:            aws.String(severity),
+		SourceIntegrationID: integration.IntegrationID,
+	}
+	return sendAlert(integration.IntegrationID, "ingestion alarm", alert)
+}
+
+// checkIngestionAlarmSettings rejects ingestion alarms on an integration without logs.
+func checkIngestionAlarmSettings(integrationType *string, silenceThresholdMinutes *int, eventErrorRateThreshold *float64) error {
+	if silenceThresholdMinutes == nil && eventErrorRateThreshold == nil {
+		return nil
+	}
+	if !logIntegration(integrationType) {
+		return &genericapi.InvalidInputError{Message: "only log integrations have ingestion alarms"}
+	}
+	return nil
+}
+
+// logIntegration returns true if the log processor ingests the logs of the integration type.
+func logIntegration(integrationType *string) bool {
+	switch aws.StringValue(integrationType) {
+	case models.IntegrationTypeAWSScan, models.IntegrationTypeAzureScan, models.IntegrationTypeAWSOrganization:
+		return false
+	default:
+		return true
+	}
+}
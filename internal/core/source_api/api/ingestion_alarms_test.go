@@ -0,0 +1,226 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	metricsmodels "github.com/panther-labs/panther/api/lambda/metrics/models"
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// fakeCloudWatchClient answers the metric queries with a single data point, the sum of each metric by source.
+type fakeCloudWatchClient struct {
+	cloudwatchiface.CloudWatchAPI
+	sums map[string]map[string]float64
+	err  error
+}
+
+func (client *fakeCloudWatchClient) GetMetricDataPages(
+	input *cloudwatch.GetMetricDataInput, fn func(*cloudwatch.GetMetricDataOutput, bool) bool) error {
+
+	if client.err != nil {
+		return client.err
+	}
+	page := &cloudwatch.GetMetricDataOutput{}
+	for _, query := range input.MetricDataQueries {
+		metric := query.MetricStat.Metric
+		result := &cloudwatch.MetricDataResult{Id: query.Id}
+		if sum, ok := client.sums[*metric.Dimensions[0].Value][*metric.MetricName]; ok {
+			result.Values = aws.Float64Slice([]float64{sum})
+		}
+		page.MetricDataResults = append(page.MetricDataResults, result)
+	}
+	fn(page, true)
+	return nil
+}
+
+// mockAlarmIntegrations stores the integrations, and captures the alerts sent about their ingestion alarms.
+func mockAlarmIntegrations(
+	t *testing.T, sums map[string]map[string]float64, integrations ...*models.SourceIntegrationMetadata,
+) (*modelstest.MockDDBClient, *[]alertmodels.Alert) {
+
+	items := make([]map[string]*dynamodb.AttributeValue, len(integrations))
+	for i, integration := range integrations {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		items[i] = item
+	}
+	mockClient := &modelstest.MockDDBClient{MockScanAttributes: items}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	cloudWatchClient = &fakeCloudWatchClient{sums: sums}
+
+	var alerts []alertmodels.Alert
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Run(func(args mock.Arguments) {
+		var alert alertmodels.Alert
+		require.NoError(t, jsoniter.UnmarshalFromString(*args.Get(0).(*sqs.SendMessageInput).MessageBody, &alert))
+		alerts = append(alerts, alert)
+	})
+	SQSClient = mockSQS
+	alertQueueURL = "alert-queue"
+	t.Cleanup(func() { alertQueueURL = "" })
+	return mockClient, &alerts
+}
+
+func alarmIntegration(integrationID string, alarms ...string) *models.SourceIntegrationMetadata {
+	return &models.SourceIntegrationMetadata{
+		IntegrationID:    aws.String(integrationID),
+		IntegrationLabel: aws.String(integrationID),
+		IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+		CreatedAtTime:    aws.Time(time.Now().Add(-48 * time.Hour)),
+		IngestionAlarms:  aws.StringSlice(alarms),
+	}
+}
+
+func TestCheckIngestionAlarms(t *testing.T) {
+	silent := alarmIntegration("silent")
+	silent.SilenceThresholdMinutes = aws.Int(30)
+	failing := alarmIntegration("failing")
+	failing.EventErrorRateThreshold = aws.Float64(0.1)
+	recovered := alarmIntegration("recovered", models.IngestionAlarmSilence)
+	recovered.SilenceThresholdMinutes = aws.Int(30)
+	cloudSecurity := alarmIntegration("cloud-security")
+	cloudSecurity.IntegrationType = aws.String(models.IntegrationTypeAWSScan)
+	cloudSecurity.SilenceThresholdMinutes = aws.Int(30)
+	unconfigured := alarmIntegration("unconfigured")
+
+	mockClient, alerts := mockAlarmIntegrations(t, map[string]map[string]float64{
+		"failing":   {metricsmodels.MetricEventsProcessed: 90, metricsmodels.MetricClassificationErrors: 30},
+		"recovered": {metricsmodels.MetricEventsProcessed: 10},
+	}, silent, failing, recovered, cloudSecurity, unconfigured)
+
+	summary, err := apiTest.CheckIngestionAlarms(&models.CheckIngestionAlarmsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.IngestionAlarmsSummary{
+		CheckedCount:  aws.Int(3),
+		RaisedCount:   aws.Int(2),
+		ResolvedCount: aws.Int(1),
+		Failures:      []*models.BulkSetScanIntervalResult{},
+	}, summary)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 3)
+
+	require.Len(t, *alerts, 3)
+	byName := make(map[string]alertmodels.Alert, len(*alerts))
+	for _, alert := range *alerts {
+		byName[*alert.PolicyName] = alert
+	}
+	assert.Equal(t, "HIGH", *byName["Source silent: silent"].Severity)
+	assert.Equal(t, "0 events delivered over the last 30 minutes", *byName["Source silent: silent"].PolicyDescription)
+	assert.Equal(t, "MEDIUM", *byName["Source event error rate: failing"].Severity)
+	assert.Equal(t, "30 of 120 log lines failed to classify over the last 15 minutes (25.0%), the threshold is 10.0%",
+		*byName["Source event error rate: failing"].PolicyDescription)
+	assert.Equal(t, "INFO", *byName["Source silent: recovered"].Severity)
+	assert.Equal(t, "resolved, 10 events delivered over the last 30 minutes",
+		*byName["Source silent: recovered"].PolicyDescription)
+}
+
+func TestCheckIngestionAlarmsUnchanged(t *testing.T) {
+	// Still silent, and within the error rate threshold
+	silent := alarmIntegration("silent", models.IngestionAlarmSilence)
+	silent.SilenceThresholdMinutes = aws.Int(30)
+	silent.EventErrorRateThreshold = aws.Float64(0.5)
+	// Created within its threshold
+	created := alarmIntegration("created")
+	created.CreatedAtTime = aws.Time(time.Now().Add(-10 * time.Minute))
+	created.SilenceThresholdMinutes = aws.Int(30)
+
+	mockClient, alerts := mockAlarmIntegrations(t, map[string]map[string]float64{
+		"silent": {metricsmodels.MetricClassificationErrors: 0},
+	}, silent, created)
+
+	summary, err := apiTest.CheckIngestionAlarms(&models.CheckIngestionAlarmsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, *summary.CheckedCount)
+	assert.Equal(t, 0, *summary.RaisedCount)
+	assert.Equal(t, 0, *summary.ResolvedCount)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+	assert.Empty(t, *alerts)
+}
+
+func TestCheckIngestionAlarmsThresholdRemoved(t *testing.T) {
+	// The stored alarm is dropped without an alert
+	mockClient, alerts := mockAlarmIntegrations(t, nil, alarmIntegration("removed", models.IngestionAlarmErrorRate))
+
+	summary, err := apiTest.CheckIngestionAlarms(&models.CheckIngestionAlarmsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *summary.CheckedCount)
+	assert.Equal(t, 0, *summary.ResolvedCount)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 1)
+	assert.Empty(t, *alerts)
+}
+
+func TestCheckIngestionAlarmsMetricsFail(t *testing.T) {
+	silent := alarmIntegration("silent")
+	silent.SilenceThresholdMinutes = aws.Int(30)
+	mockClient, alerts := mockAlarmIntegrations(t, nil, silent)
+	cloudWatchClient = &fakeCloudWatchClient{err: errors.New("throttled")}
+
+	summary, err := apiTest.CheckIngestionAlarms(&models.CheckIngestionAlarmsInput{})
+	require.NoError(t, err)
+	require.Len(t, summary.Failures, 1)
+	assert.Equal(t, "silent", *summary.Failures[0].IntegrationID)
+	assert.Contains(t, *summary.Failures[0].ErrorMessage, "throttled")
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+	assert.Empty(t, *alerts)
+}
+
+func TestCheckIngestionAlarmSettings(t *testing.T) {
+	assert.NoError(t, checkIngestionAlarmSettings(aws.String(models.IntegrationTypeAWSScan), nil, nil))
+	assert.NoError(t, checkIngestionAlarmSettings(aws.String(models.IntegrationTypeAWS3), aws.Int(30), nil))
+	assert.NoError(t, checkIngestionAlarmSettings(aws.String(models.IntegrationTypeSQSQueue), nil, aws.Float64(0.1)))
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "only log integrations have ingestion alarms"},
+		checkIngestionAlarmSettings(aws.String(models.IntegrationTypeAWSScan), aws.Int(30), nil))
+}
+
+func TestIngestionAlarmSettingsValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(minutes int, threshold float64) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{
+			IntegrationID:           aws.String(testIntegrationID),
+			SilenceThresholdMinutes: aws.Int(minutes),
+			EventErrorRateThreshold: aws.Float64(threshold),
+		}
+	}
+
+	assert.NoError(t, validator.Struct(settings(5, 0)))
+	assert.NoError(t, validator.Struct(settings(10080, 1)))
+	assert.Error(t, validator.Struct(settings(4, 0.1)))
+	assert.Error(t, validator.Struct(settings(10081, 0.1)))
+	assert.Error(t, validator.Struct(settings(30, 1.5)))
+}
@@ -0,0 +1,93 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkDependencies verifies that every dependency of an integration exists and that there are no cycles.
+//
+// The integrationID is nil for new integrations, which nothing can depend on yet.
+func checkDependencies(integrationID *string, dependsOn []*string) error {
+	visited := make(map[string]struct{})
+	pending := append([]*string(nil), dependsOn...)
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = pending[1:]
+		if integrationID != nil && *id == *integrationID {
+			return &genericapi.InvalidInputError{Message: "integration can't depend on itself"}
+		}
+		if _, ok := visited[*id]; ok {
+			continue
+		}
+		visited[*id] = struct{}{}
+
+		dependency, err := db.GetIntegration(id, false)
+		if err != nil {
+			return err
+		}
+		if dependency == nil {
+			return &genericapi.InvalidInputError{Message: fmt.Sprintf("dependency %s does not exist", *id)}
+		}
+		pending = append(pending, dependency.DependsOn...)
+	}
+	return nil
+}
+
+// getDependents returns the IDs of the integrations which depend on the given one.
+func getDependents(integrationID *string) ([]string, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var dependents []string
+	for _, integration := range integrations {
+		for _, dependency := range integration.DependsOn {
+			if aws.StringValue(dependency) == *integrationID {
+				dependents = append(dependents, *integration.IntegrationID)
+				break
+			}
+		}
+	}
+	return dependents, nil
+}
+
+// checkDependents blocks the deletion of an integration other integrations depend on, unless it's forced.
+func checkDependents(input *models.DeleteIntegrationInput) error {
+	dependents, err := getDependents(input.IntegrationID)
+	if err != nil || len(dependents) == 0 {
+		return err
+	}
+
+	if aws.BoolValue(input.Force) {
+		zap.L().Warn("forcing delete of integration with dependents", zap.Strings("dependents", dependents))
+		return nil
+	}
+	return &genericapi.InUseError{
+		Message: fmt.Sprintf("integrations %s depend on this integration", strings.Join(dependents, ", "))}
+}
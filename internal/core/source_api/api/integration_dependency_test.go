@@ -0,0 +1,105 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testDependencyID = "f9186cf2-5fe5-4d3b-a8df-5d9a6a4d7b0a"
+
+// getItemFor matches the GetItem request of a single integration.
+func getItemFor(integrationID string) interface{} {
+	return mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+		return *input.Key["integrationId"].S == integrationID
+	})
+}
+
+func TestDeleteIntegrationBlockedByDependents(t *testing.T) {
+	mockClient := &mockDDBClient{scanItems: []map[string]*dynamodb.AttributeValue{{
+		"integrationId": {S: aws.String(testDependencyID)},
+		"dependsOn":     {L: []*dynamodb.AttributeValue{{S: aws.String(testIntegrationID)}}},
+	}}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+
+	err := apiTest.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.IsType(t, &genericapi.InUseError{}, err)
+	assert.Contains(t, err.Error(), testDependencyID)
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.Anything)
+}
+
+func TestDeleteIntegrationForcedWithDependents(t *testing.T) {
+	mockClient := &mockDDBClient{scanItems: []map[string]*dynamodb.AttributeValue{{
+		"integrationId": {S: aws.String(testDependencyID)},
+		"dependsOn":     {L: []*dynamodb.AttributeValue{{S: aws.String(testIntegrationID)}}},
+	}}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	err := apiTest.DeleteIntegration(&models.DeleteIntegrationInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Force:         aws.Bool(true),
+	})
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsDependencyDoesNotExist(t *testing.T) {
+	mockClient := &mockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", getItemFor(testIntegrationID)).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("GetItem", getItemFor(testDependencyID)).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		DependsOn:     aws.StringSlice([]string{testDependencyID}),
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "dependency " + testDependencyID + " does not exist"}, err)
+}
+
+func TestUpdateIntegrationSettingsDependencyCycle(t *testing.T) {
+	mockClient := &mockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", getItemFor(testIntegrationID)).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("GetItem", getItemFor(testDependencyID)).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"integrationId": {S: aws.String(testDependencyID)},
+			"dependsOn":     {L: []*dynamodb.AttributeValue{{S: aws.String(testIntegrationID)}}},
+		},
+	}, nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		DependsOn:     aws.StringSlice([]string{testDependencyID}),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
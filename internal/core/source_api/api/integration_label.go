@@ -0,0 +1,104 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// integrationLabels tracks the labels in use for each AWS account.
+//
+// Labels are compared case-insensitively and ignoring surrounding whitespace.
+type integrationLabels map[string]struct{}
+
+// accountOf is the cloud account of an integration: its AWS account, the subscription of an Azure integration
+// or the project of a GCP integration.
+func accountOf(awsAccountID, azureSubscriptionID, gcpProjectID *string) *string {
+	switch {
+	case awsAccountID != nil:
+		return awsAccountID
+	case azureSubscriptionID != nil:
+		return azureSubscriptionID
+	}
+	return gcpProjectID
+}
+
+func labelKey(awsAccountID, label *string) string {
+	return aws.StringValue(awsAccountID) + "/" + strings.ToLower(strings.TrimSpace(aws.StringValue(label)))
+}
+
+// getIntegrationLabels returns the labels used by all integrations except the one with the given ID.
+func getIntegrationLabels(excludeIntegrationID *string) (integrationLabels, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(integrationLabels, len(integrations))
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil {
+			continue
+		}
+		if excludeIntegrationID != nil && aws.StringValue(integration.IntegrationID) == *excludeIntegrationID {
+			continue
+		}
+		labels.add(accountOf(integration.AWSAccountID, integration.AzureSubscriptionID, integration.GCPProjectID), integration.IntegrationLabel)
+	}
+	return labels, nil
+}
+
+func (labels integrationLabels) add(awsAccountID, label *string) {
+	if strings.TrimSpace(aws.StringValue(label)) == "" {
+		return
+	}
+	labels[labelKey(awsAccountID, label)] = struct{}{}
+}
+
+// check returns a ConflictError if the label is already in use in the account.
+func (labels integrationLabels) check(awsAccountID, label *string) error {
+	if strings.TrimSpace(aws.StringValue(label)) == "" {
+		return nil
+	}
+	if _, found := labels[labelKey(awsAccountID, label)]; found {
+		return &genericapi.ConflictError{
+			Message: fmt.Sprintf("integration label %q is already used in account %s",
+				strings.TrimSpace(*label), aws.StringValue(awsAccountID)),
+		}
+	}
+	return nil
+}
+
+// sanitizeDescription strips the control characters from a description, except for line breaks and tabs.
+func sanitizeDescription(description *string) *string {
+	if description == nil {
+		return nil
+	}
+	return aws.String(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, *description))
+}
@@ -0,0 +1,234 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The enhanced fan-out consumer of an integration is named after it, the role of the customer template
+// can only manage the consumers with this prefix
+const kinesisConsumerPrefix = "panther-"
+
+// The client used to check a stream assumes the log processing role, in the region of the stream
+var kinesisClientFunc = func(roleCredentials *credentials.Credentials, region string) kinesisiface.KinesisAPI {
+	return kinesis.New(sess, &aws.Config{Credentials: roleCredentials, Region: aws.String(region)})
+}
+
+// checkKinesisStream verifies the stream is active and the role can describe it and read its records.
+//
+// The records are read from the latest position of the first shard, which returns nothing but still
+// requires kinesis:GetRecords.
+func checkKinesisStream(roleCredentials *credentials.Credentials, streamARN *string) models.SourceIntegrationItemStatus {
+	start := time.Now()
+	failed := func(err error) models.SourceIntegrationItemStatus {
+		if isThrottlingError(err) {
+			zap.L().Warn("kinesis stream check throttled", zap.String("stream", *streamARN), zap.Error(err))
+			return models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		}
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	// The ARN is validated with the input
+	parsedArn, _ := arn.Parse(*streamARN)
+	streamName := aws.String(strings.TrimPrefix(parsedArn.Resource, "stream/"))
+	kinesisClient := kinesisClientFunc(roleCredentials, parsedArn.Region)
+
+	output, err := kinesisClient.DescribeStream(&kinesis.DescribeStreamInput{StreamName: streamName, Limit: aws.Int64(1)})
+	if err != nil {
+		return failed(err)
+	}
+	// Records can still be read while the shards of the stream are updated
+	status := aws.StringValue(output.StreamDescription.StreamStatus)
+	if status != kinesis.StreamStatusActive && status != kinesis.StreamStatusUpdating {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String("stream is " + status),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	if len(output.StreamDescription.Shards) > 0 {
+		iterator, err := kinesisClient.GetShardIterator(&kinesis.GetShardIteratorInput{
+			StreamName:        streamName,
+			ShardId:           output.StreamDescription.Shards[0].ShardId,
+			ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
+		})
+		if err != nil {
+			return failed(err)
+		}
+		_, err = kinesisClient.GetRecords(&kinesis.GetRecordsInput{ShardIterator: iterator.ShardIterator, Limit: aws.Int64(1)})
+		if err != nil {
+			return failed(err)
+		}
+	}
+
+	return models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(true),
+		LatencyMillis: millisSince(start),
+	}
+}
+
+// checkKinesisConsumer verifies the consumer the stream is read with is still registered.
+//
+// A consumer which was just registered is still being created, it becomes active within seconds.
+func checkKinesisConsumer(roleCredentials *credentials.Credentials, consumerARN *string) models.SourceIntegrationItemStatus {
+	start := time.Now()
+	parsedArn, err := arn.Parse(*consumerARN)
+	if err != nil {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String("invalid consumer ARN: " + err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	output, err := kinesisClientFunc(roleCredentials, parsedArn.Region).DescribeStreamConsumer(
+		&kinesis.DescribeStreamConsumerInput{ConsumerARN: consumerARN})
+	if err != nil {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			Inconclusive:  aws.Bool(isThrottlingError(err)),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+	if status := aws.StringValue(output.ConsumerDescription.ConsumerStatus); status == kinesis.ConsumerStatusDeleting {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String("consumer is " + status),
+			LatencyMillis: millisSince(start),
+		}
+	}
+	return models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(true),
+		LatencyMillis: millisSince(start),
+	}
+}
+
+// kinesisRoleCredentials are the credentials the stream of an integration is read with: those of the log processing
+// role of its account, or of the last role of its role chain.
+func kinesisRoleCredentials(integration *models.SourceIntegrationMetadata) *credentials.Credentials {
+	if len(integration.RoleChain) == 0 {
+		return stscreds.NewCredentials(sess, fmt.Sprintf(logProcessingRoleFormat, *integration.AWSAccountID))
+	}
+	var roleCredentials *credentials.Credentials
+	for _, roleARN := range integration.RoleChain {
+		var provider client.ConfigProvider = sess
+		if roleCredentials != nil {
+			provider = sess.Copy(&aws.Config{Credentials: roleCredentials})
+		}
+		roleCredentials = stscreds.NewCredentials(provider, *roleARN)
+	}
+	return roleCredentials
+}
+
+// registerKinesisConsumer registers the enhanced fan-out consumer the stream of an integration is read with.
+//
+// The consumer of a restored integration may still be registered when its deregistration failed, it is reused.
+func registerKinesisConsumer(integration *models.SourceIntegrationMetadata) error {
+	// The ARN is validated with the input
+	parsedArn, _ := arn.Parse(*integration.StreamARN)
+	kinesisClient := kinesisClientFunc(kinesisRoleCredentials(integration), parsedArn.Region)
+	consumerName := aws.String(kinesisConsumerPrefix + *integration.IntegrationID)
+
+	output, err := kinesisClient.RegisterStreamConsumer(&kinesis.RegisterStreamConsumerInput{
+		ConsumerName: consumerName,
+		StreamARN:    integration.StreamARN,
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == kinesis.ErrCodeResourceInUseException {
+		existing, err := kinesisClient.DescribeStreamConsumer(&kinesis.DescribeStreamConsumerInput{
+			ConsumerName: consumerName,
+			StreamARN:    integration.StreamARN,
+		})
+		if err != nil {
+			return &genericapi.AWSError{Err: err, Method: "kinesis.DescribeStreamConsumer"}
+		}
+		integration.KinesisConsumerARN = existing.ConsumerDescription.ConsumerARN
+		return nil
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "kinesis.RegisterStreamConsumer"}
+	}
+	integration.KinesisConsumerARN = output.Consumer.ConsumerARN
+	return nil
+}
+
+// deregisterKinesisConsumer deregisters a consumer of the stream of an integration.
+//
+// Failures are logged, a consumer which couldn't be deregistered has to be deregistered manually.
+func deregisterKinesisConsumer(integration *models.SourceIntegrationMetadata, consumerARN *string) {
+	parsedArn, err := arn.Parse(aws.StringValue(consumerARN))
+	if err != nil {
+		return
+	}
+	_, err = kinesisClientFunc(kinesisRoleCredentials(integration), parsedArn.Region).DeregisterStreamConsumer(
+		&kinesis.DeregisterStreamConsumerInput{ConsumerARN: consumerARN})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == kinesis.ErrCodeResourceNotFoundException {
+		return
+	}
+	if err != nil {
+		zap.L().Error("failed to deregister the stream consumer of the integration, it has to be deregistered manually",
+			zap.String("integrationId", *integration.IntegrationID),
+			zap.String("consumerArn", *consumerARN),
+			zap.Error(err))
+	}
+}
+
+// UpdateKinesisCheckpoints saves how far the Kinesis reader processed the stream of an integration.
+//
+// The checkpoints of every shard are replaced together. They are only saved while the integration still reads
+// the stream they were read from.
+func (API) UpdateKinesisCheckpoints(input *models.UpdateKinesisCheckpointsInput) error {
+	_, err := db.UpdateItemWithCondition(&ddb.UpdateIntegrationItem{
+		IntegrationID:          input.IntegrationID,
+		KinesisCheckpoints:     input.Checkpoints,
+		KinesisCheckpointsFrom: input.StreamARN,
+	}, expression.Name("streamArn").Equal(expression.Value(*input.StreamARN)))
+	if _, ok := err.(*ddb.ConditionalCheckFailedError); ok {
+		return &genericapi.InvalidInputError{Message: "the integration no longer reads this stream"}
+	}
+	return err
+}
@@ -0,0 +1,413 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockKinesisClient struct {
+	kinesisiface.KinesisAPI
+	mock.Mock
+}
+
+func (client *mockKinesisClient) DescribeStream(input *kinesis.DescribeStreamInput) (*kinesis.DescribeStreamOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*kinesis.DescribeStreamOutput), args.Error(1)
+}
+
+func (client *mockKinesisClient) GetShardIterator(input *kinesis.GetShardIteratorInput) (*kinesis.GetShardIteratorOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*kinesis.GetShardIteratorOutput), args.Error(1)
+}
+
+func (client *mockKinesisClient) GetRecords(input *kinesis.GetRecordsInput) (*kinesis.GetRecordsOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*kinesis.GetRecordsOutput), args.Error(1)
+}
+
+func (client *mockKinesisClient) RegisterStreamConsumer(
+	input *kinesis.RegisterStreamConsumerInput) (*kinesis.RegisterStreamConsumerOutput, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*kinesis.RegisterStreamConsumerOutput), args.Error(1)
+}
+
+func (client *mockKinesisClient) DescribeStreamConsumer(
+	input *kinesis.DescribeStreamConsumerInput) (*kinesis.DescribeStreamConsumerOutput, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*kinesis.DescribeStreamConsumerOutput), args.Error(1)
+}
+
+func (client *mockKinesisClient) DeregisterStreamConsumer(
+	input *kinesis.DeregisterStreamConsumerInput) (*kinesis.DeregisterStreamConsumerOutput, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*kinesis.DeregisterStreamConsumerOutput), args.Error(1)
+}
+
+// mockKinesisStream sets up a healthy log processing role and a stream with the given status.
+func mockKinesisStream(status string) *mockKinesisClient {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+	mockKinesis := &mockKinesisClient{}
+	mockKinesis.On("DescribeStream", &kinesis.DescribeStreamInput{StreamName: aws.String("logs"), Limit: aws.Int64(1)}).
+		Return(&kinesis.DescribeStreamOutput{StreamDescription: &kinesis.StreamDescription{
+			StreamStatus: aws.String(status),
+			Shards:       []*kinesis.Shard{{ShardId: aws.String("shardId-000000000000")}},
+		}}, nil)
+	kinesisClientFunc = func(_ *credentials.Credentials, region string) kinesisiface.KinesisAPI {
+		if region != "us-west-2" {
+			panic("the stream is checked in its own region")
+		}
+		return mockKinesis
+	}
+	return mockKinesis
+}
+
+// mockKinesisConsumers lets the stream consumers be registered and deregistered in any region.
+func mockKinesisConsumers() *mockKinesisClient {
+	mockKinesis := &mockKinesisClient{}
+	kinesisClientFunc = func(_ *credentials.Credentials, _ string) kinesisiface.KinesisAPI { return mockKinesis }
+	return mockKinesis
+}
+
+func kinesisCheckInput() *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSKinesis),
+		StreamARN:       aws.String(testStreamARN),
+	}
+}
+
+func TestKinesisStreamValidation(t *testing.T) {
+	validate, err := models.Validator()
+	require.NoError(t, err)
+
+	settings := validSettings(models.IntegrationTypeAWSKinesis)
+	settings.ShardIteratorType = aws.String(models.ShardIteratorLatest)
+	assert.NoError(t, validate.Struct(settings))
+
+	for _, streamARN := range []string{
+		"logs",
+		"arn:aws:sqs:us-west-2:123456789012:logs",
+		"arn:aws:kinesis:us-west-2:123456789012:logs",
+		"arn:aws:kinesis:us-west-2:123456789012:stream/",
+	} {
+		settings.StreamARN = aws.String(streamARN)
+		assert.Error(t, validate.Struct(settings), streamARN)
+	}
+
+	settings = validSettings(models.IntegrationTypeAWSKinesis)
+	settings.ShardIteratorType = aws.String("AT_SEQUENCE_NUMBER")
+	assert.Error(t, validate.Struct(settings))
+}
+
+func TestCheckIntegrationKinesisStreamActive(t *testing.T) {
+	mockKinesis := mockKinesisStream(kinesis.StreamStatusActive)
+	mockKinesis.On("GetShardIterator", &kinesis.GetShardIteratorInput{
+		StreamName:        aws.String("logs"),
+		ShardId:           aws.String("shardId-000000000000"),
+		ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest),
+	}).Return(&kinesis.GetShardIteratorOutput{ShardIterator: aws.String("iterator")}, nil)
+	mockKinesis.On("GetRecords", &kinesis.GetRecordsInput{ShardIterator: aws.String("iterator"), Limit: aws.Int64(1)}).
+		Return(&kinesis.GetRecordsOutput{}, nil)
+
+	result, err := apiTest.CheckIntegration(kinesisCheckInput())
+	require.NoError(t, err)
+	assert.True(t, *result.ProcessingRoleStatus.Healthy)
+	assert.True(t, *result.KinesisStreamStatus.Healthy)
+	mockKinesis.AssertExpectations(t)
+
+	passing, _, err := evaluateIntegration(apiTest, kinesisCheckInput())
+	require.NoError(t, err)
+	assert.True(t, passing)
+}
+
+func TestCheckIntegrationKinesisStreamInactive(t *testing.T) {
+	mockKinesis := mockKinesisStream(kinesis.StreamStatusCreating)
+
+	result, err := apiTest.CheckIntegration(kinesisCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.KinesisStreamStatus.Healthy)
+	assert.Equal(t, "stream is CREATING", *result.KinesisStreamStatus.ErrorMessage)
+	// No records are read from a stream which isn't active
+	mockKinesis.AssertNotCalled(t, "GetShardIterator", mock.Anything)
+
+	eval, err := evaluateIntegrationHealth(apiTest, kinesisCheckInput())
+	require.NoError(t, err)
+	assert.False(t, eval.passing())
+	assert.Equal(t, aws.StringSlice([]string{"kinesisStream:" + testStreamARN}), eval.failedItems)
+}
+
+func TestCheckIntegrationKinesisStreamNotReadable(t *testing.T) {
+	mockKinesis := mockKinesisStream(kinesis.StreamStatusActive)
+	mockKinesis.On("GetShardIterator", mock.Anything).
+		Return(&kinesis.GetShardIteratorOutput{ShardIterator: aws.String("iterator")}, nil)
+	mockKinesis.On("GetRecords", mock.Anything).
+		Return(&kinesis.GetRecordsOutput{}, errors.New("not authorized to perform kinesis:GetRecords"))
+
+	result, err := apiTest.CheckIntegration(kinesisCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.KinesisStreamStatus.Healthy)
+	assert.Equal(t, "not authorized to perform kinesis:GetRecords", *result.KinesisStreamStatus.ErrorMessage)
+}
+
+func TestUpdateIntegrationSettingsKinesisStream(t *testing.T) {
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSKinesis),
+		AWSAccountID:    aws.String(testAccountID),
+		StreamARN:       aws.String(testStreamARN),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	var checked *models.CheckIntegrationInput
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = input
+		return true, nil, nil
+	}
+	mockKinesis := mockKinesisConsumers()
+	mockKinesis.On("RegisterStreamConsumer", mock.Anything).
+		Return(&kinesis.RegisterStreamConsumerOutput{Consumer: &kinesis.Consumer{ConsumerARN: aws.String(testConsumerARN)}}, nil)
+
+	// The stored stream is checked when only the iterator changes
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:     aws.String(testIntegrationID),
+		ShardIteratorType: aws.String(models.ShardIteratorLatest),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.IntegrationTypeAWSKinesis, *checked.IntegrationType)
+	assert.Equal(t, testStreamARN, *checked.StreamARN)
+
+	otherStream := "arn:aws:kinesis:us-west-2:123456789012:stream/other"
+	_, err = apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StreamARN:     aws.String(otherStream),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, otherStream, *checked.StreamARN)
+	// A consumer is registered for the new stream
+	mockKinesis.AssertCalled(t, "RegisterStreamConsumer", &kinesis.RegisterStreamConsumerInput{
+		ConsumerName: aws.String("panther-" + testIntegrationID),
+		StreamARN:    aws.String(otherStream),
+	})
+}
+
+func TestUpdateIntegrationSettingsStreamOfLogIntegration(t *testing.T) {
+	mockLogIntegration([]string{"bucket-1"}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		panic("the settings are rejected before the health check")
+	}
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StreamARN:     aws.String(testStreamARN),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+const testConsumerARN = "arn:aws:kinesis:us-west-2:123456789012:stream/logs/consumer/panther-" + testIntegrationID + ":1"
+
+func TestCheckIntegrationKinesisConsumerDeleting(t *testing.T) {
+	mockKinesis := mockKinesisStream(kinesis.StreamStatusActive)
+	mockKinesis.On("GetShardIterator", mock.Anything).
+		Return(&kinesis.GetShardIteratorOutput{ShardIterator: aws.String("iterator")}, nil)
+	mockKinesis.On("GetRecords", mock.Anything).Return(&kinesis.GetRecordsOutput{}, nil)
+	mockKinesis.On("DescribeStreamConsumer", &kinesis.DescribeStreamConsumerInput{ConsumerARN: aws.String(testConsumerARN)}).
+		Return(&kinesis.DescribeStreamConsumerOutput{ConsumerDescription: &kinesis.ConsumerDescription{
+			ConsumerStatus: aws.String(kinesis.ConsumerStatusDeleting),
+		}}, nil)
+
+	input := kinesisCheckInput()
+	input.KinesisConsumerARN = aws.String(testConsumerARN)
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.KinesisStreamStatus.Healthy)
+	assert.False(t, *result.KinesisConsumerStatus.Healthy)
+	assert.Equal(t, "consumer is DELETING", *result.KinesisConsumerStatus.ErrorMessage)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"kinesisConsumer:" + testConsumerARN}), eval.failedItems)
+}
+
+func TestPutKinesisIntegrationRegistersConsumer(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockKinesis := mockKinesisConsumers()
+	mockKinesis.On("RegisterStreamConsumer", mock.Anything).
+		Return(&kinesis.RegisterStreamConsumerOutput{Consumer: &kinesis.Consumer{ConsumerARN: aws.String(testConsumerARN)}}, nil)
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{{
+			AWSAccountID:     aws.String(testAccountID),
+			IntegrationLabel: aws.String(testIntegrationLabel),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSKinesis),
+			UserID:           aws.String(testUserID),
+			StreamARN:        aws.String(testStreamARN),
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, testConsumerARN, *out[0].KinesisConsumerARN)
+	register := mockKinesis.Calls[0].Arguments.Get(0).(*kinesis.RegisterStreamConsumerInput)
+	assert.Equal(t, "panther-"+*out[0].IntegrationID, *register.ConsumerName)
+	assert.Equal(t, testStreamARN, *register.StreamARN)
+	require.Len(t, mockClient.written, 1)
+	assert.Contains(t, mockClient.written[0], "kinesisConsumerArn")
+}
+
+func TestPutKinesisIntegrationConsumerAlreadyRegistered(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockKinesis := mockKinesisConsumers()
+	mockKinesis.On("RegisterStreamConsumer", mock.Anything).Return((*kinesis.RegisterStreamConsumerOutput)(nil),
+		awserr.New(kinesis.ErrCodeResourceInUseException, "consumer exists", nil))
+	mockKinesis.On("DescribeStreamConsumer", mock.Anything).
+		Return(&kinesis.DescribeStreamConsumerOutput{ConsumerDescription: &kinesis.ConsumerDescription{
+			ConsumerARN: aws.String(testConsumerARN),
+		}}, nil)
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{{
+			AWSAccountID:     aws.String(testAccountID),
+			IntegrationLabel: aws.String(testIntegrationLabel),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSKinesis),
+			UserID:           aws.String(testUserID),
+			StreamARN:        aws.String(testStreamARN),
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testConsumerARN, *out[0].KinesisConsumerARN)
+}
+
+func TestDeleteKinesisIntegrationDeregistersConsumer(t *testing.T) {
+	mockKinesis := mockKinesisConsumers()
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWSKinesis),
+		AWSAccountID:       aws.String(testAccountID),
+		StreamARN:          aws.String(testStreamARN),
+		KinesisConsumerARN: aws.String(testConsumerARN),
+	})
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	mockKinesis.On("DeregisterStreamConsumer", &kinesis.DeregisterStreamConsumerInput{ConsumerARN: aws.String(testConsumerARN)}).
+		Return(&kinesis.DeregisterStreamConsumerOutput{}, nil)
+
+	require.NoError(t, apiTest.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: aws.String(testIntegrationID)}))
+	mockKinesis.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsKinesisStreamReplacesConsumer(t *testing.T) {
+	mockKinesis := mockKinesisConsumers()
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWSKinesis),
+		AWSAccountID:       aws.String(testAccountID),
+		StreamARN:          aws.String(testStreamARN),
+		KinesisConsumerARN: aws.String(testConsumerARN),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	var checked *models.CheckIntegrationInput
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = input
+		return true, nil, nil
+	}
+	otherStream := "arn:aws:kinesis:us-west-2:123456789012:stream/other"
+	otherConsumer := otherStream + "/consumer/panther-" + testIntegrationID + ":1"
+	mockKinesis.On("RegisterStreamConsumer", mock.Anything).
+		Return(&kinesis.RegisterStreamConsumerOutput{Consumer: &kinesis.Consumer{ConsumerARN: aws.String(otherConsumer)}}, nil)
+	mockKinesis.On("DeregisterStreamConsumer", &kinesis.DeregisterStreamConsumerInput{ConsumerARN: aws.String(testConsumerARN)}).
+		Return(&kinesis.DeregisterStreamConsumerOutput{}, nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StreamARN:     aws.String(otherStream),
+	})
+	require.NoError(t, err)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var values []string
+	for _, value := range update.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.Contains(t, values, otherConsumer)
+	// The consumer of the old stream isn't checked with the new stream
+	assert.Nil(t, checked.KinesisConsumerARN)
+	mockKinesis.AssertExpectations(t)
+}
+
+func TestUpdateKinesisCheckpoints(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	require.NoError(t, apiTest.UpdateKinesisCheckpoints(&models.UpdateKinesisCheckpointsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StreamARN:     aws.String(testStreamARN),
+		Checkpoints:   map[string]*string{"shardId-000000000000": aws.String("49590338271490256608559692538361571095921575989136588898")},
+	}))
+	update := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	require.NotNil(t, update.ConditionExpression)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "kinesisCheckpoints")
+	assert.Contains(t, names, "kinesisCheckpointsFrom")
+	assert.Contains(t, names, "streamArn")
+}
+
+func TestUpdateKinesisCheckpointsStreamChanged(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return((*dynamodb.UpdateItemOutput)(nil),
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+
+	err := apiTest.UpdateKinesisCheckpoints(&models.UpdateKinesisCheckpointsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StreamARN:     aws.String(testStreamARN),
+		Checkpoints:   map[string]*string{"shardId-000000000000": aws.String("1")},
+	})
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Equal(t, "the integration no longer reads this stream", err.(*genericapi.InvalidInputError).Message)
+}
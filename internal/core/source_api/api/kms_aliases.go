@@ -0,0 +1,180 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The number of integrations whose KMS aliases are re-resolved at the same time
+const maxConcurrentKmsAliasChecks = 10
+
+var resolveKmsKeysFunc = resolveKmsKeys
+
+// isKmsAlias returns true if the key is referenced by an alias name or an alias ARN.
+func isKmsAlias(key string) bool {
+	return strings.HasPrefix(key, "alias/") || (strings.HasPrefix(key, "arn:") && strings.Contains(key, ":alias/"))
+}
+
+// resolveKmsKeys pins every KMS alias to the ARN of the key it currently points to.
+//
+// It returns the list of keys with each alias replaced by its key ARN, along with the alias => ARN mapping.
+// Keys which are not aliases are returned unchanged.
+func resolveKmsKeys(awsAccountID *string, keys []*string) ([]*string, map[string]*string, error) {
+	resolved := make([]*string, 0, len(keys))
+	aliases := make(map[string]*string)
+
+	var kmsClient kmsiface.KMSAPI
+	for _, key := range keys {
+		if !isKmsAlias(*key) {
+			resolved = append(resolved, key)
+			continue
+		}
+
+		if kmsClient == nil {
+			kmsClient = kmsClientFunc(stscreds.NewCredentials(sess, fmt.Sprintf(logProcessingRoleFormat, *awsAccountID)))
+		}
+		keyArn, err := resolveKmsAlias(kmsClient, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		resolved = append(resolved, keyArn)
+		aliases[*key] = keyArn
+	}
+
+	return resolved, aliases, nil
+}
+
+func resolveKmsAlias(kmsClient kmsiface.KMSAPI, alias *string) (*string, error) {
+	info, err := kmsClient.DescribeKey(&kms.DescribeKeyInput{KeyId: alias})
+	if err != nil {
+		return nil, &genericapi.InvalidInputError{Message: fmt.Sprintf("failed to resolve KMS alias %s: %s", *alias, err)}
+	}
+	return info.KeyMetadata.Arn, nil
+}
+
+// CheckKmsKeyAliases re-resolves the KMS aliases of an integration and reports the ones pointing to a different key.
+//
+// The pinned key ARNs are not modified: the integration settings have to be updated to follow the new targets.
+// A notification is sent when an alias points to a different key.
+func (API) CheckKmsKeyAliases(input *models.CheckKmsKeyAliasesInput) ([]*models.KmsKeyAliasChange, error) {
+	integration, err := db.GetIntegration(input.IntegrationID, false)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	return checkKmsKeyAliases(integration)
+}
+
+// RecheckKmsKeyAliases re-resolves the KMS aliases of every integration which pinned some.
+//
+// An alias repointed after the integration was saved otherwise goes unnoticed until the objects encrypted with the
+// new key fail to decrypt. Until the integration settings follow the new key, every re-check notifies again.
+func (API) RecheckKmsKeyAliases(*models.RecheckKmsKeyAliasesInput) (*models.KmsKeyAliasRecheckSummary, error) {
+	stored, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+	integrations := make([]*models.SourceIntegrationMetadata, 0, len(stored))
+	for _, integration := range stored {
+		if integration.SourceIntegrationMetadata == nil || len(integration.KmsKeyAliases) == 0 {
+			continue
+		}
+		integrations = append(integrations, integration.SourceIntegrationMetadata)
+	}
+
+	changes := make([][]*models.KmsKeyAliasChange, len(integrations))
+	errs := forEachConcurrently(len(integrations), maxConcurrentKmsAliasChecks, func(i int) error {
+		var err error
+		changes[i], err = checkKmsKeyAliases(integrations[i])
+		return err
+	})
+
+	summary := &models.KmsKeyAliasRecheckSummary{
+		CheckedCount: aws.Int(len(integrations)),
+		Failures:     make([]*models.BulkSetScanIntervalResult, 0),
+	}
+	var changedCount int
+	for i, integration := range integrations {
+		if errs[i] != nil {
+			zap.L().Warn("failed to recheck KMS aliases",
+				zap.String("integrationId", *integration.IntegrationID), zap.Error(errs[i]))
+			summary.Failures = append(summary.Failures, &models.BulkSetScanIntervalResult{
+				IntegrationID: integration.IntegrationID,
+				Success:       aws.Bool(false),
+				ErrorMessage:  aws.String(errs[i].Error()),
+			})
+			continue
+		}
+		if len(changes[i]) > 0 {
+			changedCount++
+		}
+	}
+	summary.ChangedCount = aws.Int(changedCount)
+	return summary, nil
+}
+
+// checkKmsKeyAliases returns the KMS aliases of an integration which point to a different key than the pinned one,
+// and notifies the notification targets of the integration about them.
+func checkKmsKeyAliases(integration *models.SourceIntegrationMetadata) ([]*models.KmsKeyAliasChange, error) {
+	aliases := make([]*string, 0, len(integration.KmsKeyAliases))
+	for alias := range integration.KmsKeyAliases {
+		aliases = append(aliases, aws.String(alias))
+	}
+	sort.Slice(aliases, func(i, j int) bool { return *aliases[i] < *aliases[j] })
+	_, current, err := resolveKmsKeysFunc(integration.AWSAccountID, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*models.KmsKeyAliasChange, 0)
+	for _, alias := range aliases {
+		pinnedArn := integration.KmsKeyAliases[*alias]
+		if aws.StringValue(current[*alias]) == aws.StringValue(pinnedArn) {
+			continue
+		}
+		zap.L().Warn("KMS alias now points to a different key",
+			zap.String("integrationId", *integration.IntegrationID),
+			zap.String("alias", *alias),
+			zap.String("pinnedArn", aws.StringValue(pinnedArn)),
+			zap.String("currentArn", aws.StringValue(current[*alias])))
+		changes = append(changes, &models.KmsKeyAliasChange{
+			Alias:      alias,
+			PinnedArn:  pinnedArn,
+			CurrentArn: current[*alias],
+		})
+	}
+	if err = notifyKmsKeyAliasChange(integration, changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,163 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+const (
+	testKeyArn    = "arn:aws:kms:us-west-2:123456789012:key/27803c7e-9fa5-4fcb-9525-ee11c953d329"
+	testNewKeyArn = "arn:aws:kms:us-west-2:123456789012:key/d6dfb3b1-430e-4e9c-a7e4-94b397d8b5e4"
+)
+
+func TestResolveKmsKeys(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockKMS.On("DescribeKey", &kms.DescribeKeyInput{KeyId: aws.String("alias/logs")}).
+		Return(&kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Arn: aws.String(testKeyArn)}}, nil)
+	mockHealthCheckClients(nil, nil, mockKMS)
+
+	otherKey := "arn:aws:kms:us-west-2:123456789012:key/0b7c1a3e-6f2f-4f0a-9c35-9c0b7b6f8c11"
+	keys, aliases, err := resolveKmsKeys(aws.String(testAccountID), aws.StringSlice([]string{"alias/logs", otherKey}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{testKeyArn, otherKey}, aws.StringValueSlice(keys))
+	assert.Equal(t, map[string]*string{"alias/logs": aws.String(testKeyArn)}, aliases)
+	mockKMS.AssertExpectations(t)
+}
+
+func TestIsKmsAlias(t *testing.T) {
+	assert.True(t, isKmsAlias("alias/logs"))
+	assert.True(t, isKmsAlias("arn:aws:kms:us-west-2:123456789012:alias/logs"))
+	assert.False(t, isKmsAlias(testKeyArn))
+	assert.False(t, isKmsAlias("27803c7e-9fa5-4fcb-9525-ee11c953d329"))
+}
+
+func TestCheckKmsKeyAliasesChanged(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"integrationId":   {S: aws.String(testIntegrationID)},
+			"integrationType": {S: aws.String(models.IntegrationTypeAWS3)},
+			"awsAccountId":    {S: aws.String(testAccountID)},
+			"kmsKeys":         {SS: aws.StringSlice([]string{testKeyArn})},
+			"kmsKeyAliases": {M: map[string]*dynamodb.AttributeValue{
+				"alias/logs": {S: aws.String(testKeyArn)},
+			}},
+		},
+	}, nil)
+
+	// The alias has been repointed to a new key since it was pinned
+	mockKMS := &mockKMSClient{}
+	mockKMS.On("DescribeKey", &kms.DescribeKeyInput{KeyId: aws.String("alias/logs")}).
+		Return(&kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{Arn: aws.String(testNewKeyArn)}}, nil)
+	mockHealthCheckClients(nil, nil, mockKMS)
+
+	changes, err := apiTest.CheckKmsKeyAliases(&models.CheckKmsKeyAliasesInput{
+		IntegrationID: aws.String(testIntegrationID),
+	})
+	require.NoError(t, err)
+	expected := []*models.KmsKeyAliasChange{
+		{
+			Alias:      aws.String("alias/logs"),
+			PinnedArn:  aws.String(testKeyArn),
+			CurrentArn: aws.String(testNewKeyArn),
+		},
+	}
+	assert.Equal(t, expected, changes)
+	mockClient.AssertExpectations(t)
+	mockKMS.AssertExpectations(t)
+}
+
+func TestRecheckKmsKeyAliases(t *testing.T) {
+	repointed := &models.SourceIntegrationMetadata{
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationLabel:    aws.String("team-logs"),
+		IntegrationType:     aws.String(models.IntegrationTypeAWS3),
+		AWSAccountID:        aws.String(testAccountID),
+		KmsKeyAliases:       map[string]*string{"alias/logs": aws.String(testKeyArn)},
+		NotificationTargets: aws.StringSlice([]string{testOutputID}),
+	}
+	unchanged := &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String("unchanged"),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		AWSAccountID:    aws.String("111111111111"),
+		KmsKeyAliases:   map[string]*string{"alias/logs": aws.String(testKeyArn)},
+	}
+	withoutAliases := &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String("without-aliases"),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+	}
+	items := make([]map[string]*dynamodb.AttributeValue, 0, 3)
+	for _, integration := range []*models.SourceIntegrationMetadata{repointed, unchanged, withoutAliases} {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{MockScanAttributes: items}, TableName: "test"}
+
+	resolveKmsKeysFunc = func(awsAccountID *string, keys []*string) ([]*string, map[string]*string, error) {
+		assert.Equal(t, []string{"alias/logs"}, aws.StringValueSlice(keys))
+		if *awsAccountID == testAccountID {
+			return nil, map[string]*string{"alias/logs": aws.String(testNewKeyArn)}, nil
+		}
+		return nil, map[string]*string{"alias/logs": aws.String(testKeyArn)}, nil
+	}
+	defer func() { resolveKmsKeysFunc = resolveKmsKeys }()
+
+	var message *sqs.SendMessageInput
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Once().
+		Run(func(args mock.Arguments) { message = args.Get(0).(*sqs.SendMessageInput) })
+	SQSClient = mockSQS
+	alertQueueURL = "alert-queue"
+	defer func() { alertQueueURL = "" }()
+
+	summary, err := apiTest.RecheckKmsKeyAliases(&models.RecheckKmsKeyAliasesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.KmsKeyAliasRecheckSummary{
+		CheckedCount: aws.Int(2),
+		ChangedCount: aws.Int(1),
+		Failures:     []*models.BulkSetScanIntervalResult{},
+	}, summary)
+	mockSQS.AssertExpectations(t)
+
+	var alert alertmodels.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*message.MessageBody, &alert))
+	assert.Equal(t, aws.StringSlice([]string{testOutputID}), alert.OutputIDs)
+	assert.Equal(t, testIntegrationID, *alert.SourceIntegrationID)
+	assert.Equal(t, "MEDIUM", *alert.Severity)
+	assert.Equal(t, "KMS aliases changed, update the integration settings: alias/logs now points to "+
+		testNewKeyArn+" instead of "+testKeyArn, *alert.PolicyDescription)
+}
@@ -0,0 +1,188 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// Grants are named after the integration, creating a grant with the same name and settings returns the existing one
+const kmsGrantNameFormat = "panther-%s"
+
+// kmsGrantChange is a change of the KMS grants of an integration which is not written yet.
+//
+// The grants it adds already exist, the ones it replaces are only retired once the change is written: the stored
+// grants are always live. If the write fails, the grants it added are retired instead.
+type kmsGrantChange struct {
+	kmsClient kmsiface.KMSAPI
+	// The grants of the keys once the change is written, by key ARN
+	grants  map[string]*string
+	created map[string]*string
+	removed map[string]*string
+}
+
+// result is the grants to store with the change, nil for no change.
+func (change *kmsGrantChange) result() map[string]*string {
+	if change == nil {
+		return nil
+	}
+	return change.grants
+}
+
+// written retires the grants the change replaced, now that it is stored.
+//
+// The change can no longer be undone, a grant which can't be retired only gives decrypt to Panther's own role.
+func (change *kmsGrantChange) written(integrationID *string) {
+	if change == nil {
+		return
+	}
+	if err := retireKmsGrants(change.kmsClient, change.removed); err != nil {
+		zap.L().Error("failed to retire the replaced KMS grants of the integration, they have to be retired manually",
+			zap.String("integrationId", *integrationID), zap.Error(err))
+	}
+}
+
+// discard retires the grants the change created, when it couldn't be written.
+func (change *kmsGrantChange) discard() {
+	if change == nil {
+		return
+	}
+	if err := retireKmsGrants(change.kmsClient, change.created); err != nil {
+		zap.L().Error("failed to retire the KMS grants of a change which wasn't written", zap.Error(err))
+	}
+}
+
+// syncKmsGrants creates a grant for decrypt on each key which has none, the grants of the other keys are retired
+// once the returned change is written.
+//
+// The log processing role creates the grants with itself as the grantee and retiring principal, so that it can
+// retire them when the integration is deleted.
+func syncKmsGrants(
+	awsAccountID, integrationID *string, keys []*string, grants map[string]*string) (*kmsGrantChange, error) {
+
+	roleArn := fmt.Sprintf(logProcessingRoleFormat, *awsAccountID)
+	change := &kmsGrantChange{
+		kmsClient: kmsClientFunc(stscreds.NewCredentials(sess, roleArn)),
+		grants:    make(map[string]*string, len(keys)),
+		created:   make(map[string]*string),
+		removed:   make(map[string]*string),
+	}
+	for _, key := range keys {
+		if grantID, ok := grants[*key]; ok {
+			change.grants[*key] = grantID
+			continue
+		}
+		output, err := change.kmsClient.CreateGrant(&kms.CreateGrantInput{
+			KeyId:             key,
+			GranteePrincipal:  aws.String(roleArn),
+			RetiringPrincipal: aws.String(roleArn),
+			Operations:        aws.StringSlice([]string{kms.GrantOperationDecrypt}),
+			Name:              aws.String(fmt.Sprintf(kmsGrantNameFormat, *integrationID)),
+		})
+		if err != nil {
+			// Don't leave the grants of a change which isn't saved behind
+			change.discard()
+			message := fmt.Sprintf("failed to create a KMS grant on %s: %s", *key, err)
+			if isTransientError(err) {
+				return nil, &transientError{sideEffect: sideEffectKmsGrants, message: message}
+			}
+			return nil, &genericapi.InvalidInputError{Message: message}
+		}
+		change.grants[*key], change.created[*key] = output.GrantId, output.GrantId
+	}
+
+	for key, grantID := range grants {
+		if _, ok := change.grants[key]; !ok {
+			change.removed[key] = grantID
+		}
+	}
+	return change, nil
+}
+
+// retireKmsGrants retires grants by key ARN, the grants which no longer exist are ignored.
+func retireKmsGrants(kmsClient kmsiface.KMSAPI, grants map[string]*string) error {
+	for key, grantID := range grants {
+		_, err := kmsClient.RetireGrant(&kms.RetireGrantInput{KeyId: aws.String(key), GrantId: grantID})
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == kms.ErrCodeNotFoundException {
+			continue
+		}
+		if err != nil {
+			return &genericapi.AWSError{Method: "kms.RetireGrant", Err: err}
+		}
+	}
+	return nil
+}
+
+// retireIntegrationKmsGrants retires the grants of an integration which is being deleted.
+//
+// The integration is deleted anyway, a grant which can't be retired only gives decrypt to Panther's own role.
+func retireIntegrationKmsGrants(integration *models.SourceIntegrationMetadata) {
+	if len(integration.KmsGrants) == 0 {
+		return
+	}
+	kmsClient := kmsClientFunc(stscreds.NewCredentials(sess, fmt.Sprintf(logProcessingRoleFormat, *integration.AWSAccountID)))
+	if err := retireKmsGrants(kmsClient, integration.KmsGrants); err != nil {
+		zap.L().Error("failed to retire the KMS grants of the integration, they have to be retired manually",
+			zap.String("integrationId", *integration.IntegrationID),
+			zap.Error(err))
+	}
+}
+
+// checkGrants verifies each grant still exists on its key.
+func checkGrants(roleCredentials *credentials.Credentials, grants map[string]*string) map[string]models.SourceIntegrationItemStatus {
+	kmsClient := kmsClientFunc(roleCredentials)
+
+	grantStatuses := make(map[string]models.SourceIntegrationItemStatus, len(grants))
+	for key, grantID := range grants {
+		start := time.Now()
+		found := false
+		err := kmsClient.ListGrantsPages(&kms.ListGrantsInput{KeyId: aws.String(key)},
+			func(page *kms.ListGrantsResponse, lastPage bool) bool {
+				for _, grant := range page.Grants {
+					if aws.StringValue(grant.GrantId) == aws.StringValue(grantID) {
+						found = true
+						return false
+					}
+				}
+				return true
+			})
+
+		status := models.SourceIntegrationItemStatus{Healthy: aws.Bool(found), LatencyMillis: millisSince(start)}
+		switch {
+		case err != nil:
+			status.ErrorMessage = aws.String(err.Error())
+		case !found:
+			status.ErrorMessage = aws.String(fmt.Sprintf("grant %s no longer exists", aws.StringValue(grantID)))
+		}
+		grantStatuses[key] = status
+	}
+	return grantStatuses
+}
@@ -0,0 +1,277 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testGrantKeyArn   = "arn:aws:kms:us-west-2:123456789012:key/b2a9c1f4-65d2-4b9e-8a63-0f3c7d2e5a18"
+	testRemovedKeyArn = "arn:aws:kms:us-west-2:123456789012:key/5e0d7b3a-2c4f-4a8e-9b16-d4f2a6c8e013"
+)
+
+func (client *mockKMSClient) CreateGrant(input *kms.CreateGrantInput) (*kms.CreateGrantOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*kms.CreateGrantOutput), args.Error(1)
+}
+
+func (client *mockKMSClient) RetireGrant(input *kms.RetireGrantInput) (*kms.RetireGrantOutput, error) {
+	args := client.Called(input)
+	return &kms.RetireGrantOutput{}, args.Error(0)
+}
+
+func (client *mockKMSClient) ListGrantsPages(input *kms.ListGrantsInput, fn func(*kms.ListGrantsResponse, bool) bool) error {
+	args := client.Called(input)
+	fn(args.Get(0).(*kms.ListGrantsResponse), true)
+	return args.Error(1)
+}
+
+func grantFor(key string) interface{} {
+	return mock.MatchedBy(func(input *kms.CreateGrantInput) bool { return *input.KeyId == key })
+}
+
+func TestSyncKmsGrants(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("CreateGrant", grantFor(testGrantKeyArn)).Return(&kms.CreateGrantOutput{GrantId: aws.String("grant-2")}, nil)
+	mockKMS.On("RetireGrant", &kms.RetireGrantInput{KeyId: aws.String(testRemovedKeyArn), GrantId: aws.String("grant-3")}).
+		Return(nil)
+
+	grants, err := syncKmsGrants(aws.String(testAccountID), aws.String(testIntegrationID),
+		aws.StringSlice([]string{testKeyArn, testGrantKeyArn}),
+		map[string]*string{testKeyArn: aws.String("grant-1"), testRemovedKeyArn: aws.String("grant-3")})
+	require.NoError(t, err)
+	// The existing grant is kept, the new key gets one and the grant of the removed key is retired once written
+	assert.Equal(t, map[string]*string{testKeyArn: aws.String("grant-1"), testGrantKeyArn: aws.String("grant-2")}, grants.result())
+	mockKMS.AssertNotCalled(t, "RetireGrant", mock.Anything)
+	grants.written(aws.String(testIntegrationID))
+	mockKMS.AssertExpectations(t)
+	mockKMS.AssertNumberOfCalls(t, "CreateGrant", 1)
+
+	roleArn := "arn:aws:iam::" + testAccountID + ":role/PantherLogProcessingRole"
+	assert.Equal(t, &kms.CreateGrantInput{
+		KeyId:             aws.String(testGrantKeyArn),
+		GranteePrincipal:  aws.String(roleArn),
+		RetiringPrincipal: aws.String(roleArn),
+		Operations:        aws.StringSlice([]string{kms.GrantOperationDecrypt}),
+		Name:              aws.String("panther-" + testIntegrationID),
+	}, mockKMS.Calls[0].Arguments.Get(0))
+}
+
+func TestSyncKmsGrantsFailure(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("CreateGrant", grantFor(testKeyArn)).Return(&kms.CreateGrantOutput{GrantId: aws.String("grant-1")}, nil)
+	mockKMS.On("CreateGrant", grantFor(testGrantKeyArn)).
+		Return(&kms.CreateGrantOutput{}, awserr.New("AccessDeniedException", "not authorized to perform kms:CreateGrant", nil))
+	mockKMS.On("RetireGrant", &kms.RetireGrantInput{KeyId: aws.String(testKeyArn), GrantId: aws.String("grant-1")}).Return(nil)
+
+	grants, err := syncKmsGrants(aws.String(testAccountID), aws.String(testIntegrationID),
+		aws.StringSlice([]string{testKeyArn, testGrantKeyArn}), nil)
+	assert.Nil(t, grants)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Contains(t, err.Error(), testGrantKeyArn)
+	// The grant created before the failure is retired
+	mockKMS.AssertExpectations(t)
+}
+
+func TestKmsGrantChangeDiscard(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("CreateGrant", grantFor(testGrantKeyArn)).Return(&kms.CreateGrantOutput{GrantId: aws.String("grant-2")}, nil)
+	mockKMS.On("RetireGrant", &kms.RetireGrantInput{KeyId: aws.String(testGrantKeyArn), GrantId: aws.String("grant-2")}).
+		Return(nil)
+
+	grants, err := syncKmsGrants(aws.String(testAccountID), aws.String(testIntegrationID),
+		aws.StringSlice([]string{testGrantKeyArn}), map[string]*string{testRemovedKeyArn: aws.String("grant-3")})
+	require.NoError(t, err)
+	// The change isn't written: the grant it created is retired, the one it replaced is still stored and kept
+	grants.discard()
+	mockKMS.AssertExpectations(t)
+	mockKMS.AssertNumberOfCalls(t, "RetireGrant", 1)
+}
+
+func TestRetireIntegrationKmsGrants(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("RetireGrant", &kms.RetireGrantInput{KeyId: aws.String(testKeyArn), GrantId: aws.String("grant-1")}).Return(nil)
+	// Already retired by the customer
+	mockKMS.On("RetireGrant", &kms.RetireGrantInput{KeyId: aws.String(testGrantKeyArn), GrantId: aws.String("grant-2")}).
+		Return(awserr.New(kms.ErrCodeNotFoundException, "grant not found", nil))
+
+	retireIntegrationKmsGrants(&models.SourceIntegrationMetadata{
+		AWSAccountID:  aws.String(testAccountID),
+		IntegrationID: aws.String(testIntegrationID),
+		KmsGrants:     map[string]*string{testKeyArn: aws.String("grant-1"), testGrantKeyArn: aws.String("grant-2")},
+	})
+	mockKMS.AssertExpectations(t)
+}
+
+func TestCheckIntegrationKmsGrants(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockKMS := &mockKMSClient{}
+	mockKMS.On("ListGrantsPages", &kms.ListGrantsInput{KeyId: aws.String(testKeyArn)}).Return(&kms.ListGrantsResponse{
+		Grants: []*kms.GrantListEntry{{GrantId: aws.String("other")}, {GrantId: aws.String("grant-1")}},
+	}, nil)
+	mockKMS.On("ListGrantsPages", &kms.ListGrantsInput{KeyId: aws.String(testGrantKeyArn)}).Return(&kms.ListGrantsResponse{
+		Grants: []*kms.GrantListEntry{{GrantId: aws.String("other")}},
+	}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, mockKMS)
+	input := &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		KmsGrants:       map[string]*string{testKeyArn: aws.String("grant-1"), testGrantKeyArn: aws.String("grant-2")},
+	}
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, aws.BoolValue(result.KMSGrantsStatus[testKeyArn].Healthy))
+	missing := result.KMSGrantsStatus[testGrantKeyArn]
+	assert.False(t, aws.BoolValue(missing.Healthy))
+	assert.Equal(t, "grant grant-2 no longer exists", aws.StringValue(missing.ErrorMessage))
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"kmsGrant:" + testGrantKeyArn}), eval.failedItems)
+}
+
+func TestCheckIntegrationKmsGrantsListFailure(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockKMS := &mockKMSClient{}
+	mockKMS.On("ListGrantsPages", mock.Anything).Return(&kms.ListGrantsResponse{}, errors.New("access denied"))
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, mockKMS)
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		KmsGrants:       map[string]*string{testKeyArn: aws.String("grant-1")},
+	})
+	require.NoError(t, err)
+	status := result.KMSGrantsStatus[testKeyArn]
+	assert.False(t, aws.BoolValue(status.Healthy))
+	assert.Equal(t, "access denied", aws.StringValue(status.ErrorMessage))
+}
+
+func TestUpdateIntegrationSettingsCreateKmsGrants(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("CreateGrant", grantFor(testKeyArn)).Return(&kms.CreateGrantOutput{GrantId: aws.String("grant-1")}, nil)
+	mockLogIntegration([]string{"bucket-1"}, []string{testKeyArn})
+	mockClient := db.Client.(*modelstest.MockDDBClient)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+	var checked *models.CheckIntegrationInput
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = input
+		return true, nil, nil
+	}
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		CreateKmsGrants: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	// The health check runs before the grants are created, there are none to verify yet
+	assert.Empty(t, checked.KmsGrants)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{
+		M: map[string]*dynamodb.AttributeValue{testKeyArn: {S: aws.String("grant-1")}}})
+	assert.Contains(t, values, &dynamodb.AttributeValue{BOOL: aws.Bool(true)})
+	mockKMS.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsKmsGrantsNotWritten(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("CreateGrant", grantFor(testKeyArn)).Return(&kms.CreateGrantOutput{GrantId: aws.String("grant-1")}, nil)
+	mockKMS.On("RetireGrant", &kms.RetireGrantInput{KeyId: aws.String(testKeyArn), GrantId: aws.String("grant-1")}).
+		Return(nil)
+	mockLogIntegration([]string{"bucket-1"}, []string{testKeyArn})
+	mockClient := db.Client.(*modelstest.MockDDBClient)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, errors.New("throttled"))
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		CreateKmsGrants: aws.Bool(true),
+	})
+	require.Error(t, err)
+	// The grant created for the update which failed is retired
+	mockKMS.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsWithoutKmsGrants(t *testing.T) {
+	// The keys of integrations which don't use grants are left to their key policies
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockLogIntegration([]string{"bucket-1"}, []string{testKeyArn})
+	mockClient := db.Client.(*modelstest.MockDDBClient)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationLabel:    aws.String("renamed"),
+		AllowDuplicateLabel: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	mockKMS.AssertNotCalled(t, "CreateGrant", mock.Anything)
+}
+
+func TestGetIntegrationPolicyDocumentKmsGrants(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		AWSAccountID:    aws.String(testAccountID),
+		S3Buckets:       aws.StringSlice([]string{"bucket-1"}),
+		KmsKeys:         aws.StringSlice([]string{testKeyArn}),
+		CreateKmsGrants: aws.Bool(true),
+	})
+
+	document := getPolicyDocument(t)
+	require.Len(t, document.Statement, 5)
+	assert.Equal(t, policyStatement{
+		Effect:   "Allow",
+		Action:   []string{"kms:CreateGrant", "kms:ListGrants", "kms:RetireGrant"},
+		Resource: []string{testKeyArn},
+	}, document.Statement[4])
+}
@@ -0,0 +1,170 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const testStreamARN = "arn:aws:kinesis:us-west-2:123456789012:stream/logs"
+
+// validSettings returns settings with every field which can be required set.
+//
+// The stream is only set for the types which read from a stream, the others reject it.
+func validSettings(integrationType string) models.PutIntegrationSettings {
+	settings := models.PutIntegrationSettings{
+		AWSAccountID:     aws.String(testAccountID),
+		IntegrationLabel: aws.String(testIntegrationLabel),
+		IntegrationType:  aws.String(integrationType),
+		UserID:           aws.String(testUserID),
+		S3Buckets:        aws.StringSlice([]string{"bucket"}),
+	}
+	if integrationType == models.IntegrationTypeAWSKinesis {
+		settings.StreamARN = aws.String(testStreamARN)
+	}
+	if integrationType == models.IntegrationTypeAzureScan {
+		settings.AzureTenantID = aws.String(testAzureTenantID)
+		settings.AzureSubscriptionID = aws.String(testAzureSubscriptionID)
+		settings.AzureClientID = aws.String(testAzureClientID)
+		settings.AzureClientSecretArn = aws.String(testAzureClientSecretArn)
+	}
+	if integrationType == models.IntegrationTypeGCPLogSource {
+		settings.GCPProjectID = aws.String(testGCPProjectID)
+		settings.GCPServiceAccountSecretArn = aws.String(testGCPServiceAccountArn)
+		settings.GCSBucket = aws.String(testGCSBucket)
+	}
+	if integrationType == models.IntegrationTypeOkta {
+		settings.OktaDomain = aws.String(testOktaDomain)
+		settings.Credentials = testCredentials()
+	}
+	if integrationType == models.IntegrationTypeSyslog {
+		settings.SyslogAllowedCIDRs = aws.StringSlice([]string{"10.0.0.0/16"})
+	}
+	return settings
+}
+
+func TestListIntegrationTypes(t *testing.T) {
+	result, err := apiTest.ListIntegrationTypes(&models.ListIntegrationTypesInput{})
+	require.NoError(t, err)
+	require.Len(t, result, 10)
+	assert.Equal(t, models.IntegrationTypeAWSScan, *result[0].IntegrationType)
+	assert.True(t, *result[0].SupportsCWE)
+	assert.Equal(t, models.IntegrationTypeAWS3, *result[1].IntegrationType)
+	assert.True(t, *result[1].SupportsPrefixes)
+	assert.True(t, *result[1].SupportsOrgTrail)
+	assert.True(t, *result[1].SupportsRoleChain)
+	assert.Equal(t, aws.StringSlice([]string{"awsAccountId", "s3Buckets"}), result[1].RequiredFields)
+	assert.Equal(t, models.IntegrationTypeAWSKinesis, *result[2].IntegrationType)
+	assert.True(t, *result[2].SupportsStreams)
+	assert.True(t, *result[2].SupportsRoleChain)
+	assert.Equal(t, aws.StringSlice([]string{"awsAccountId", "streamArn"}), result[2].RequiredFields)
+	assert.Equal(t, models.IntegrationTypeAzureScan, *result[3].IntegrationType)
+	assert.False(t, *result[3].SupportsCWE)
+	assert.Equal(t, aws.StringSlice([]string{"azureTenantId", "azureSubscriptionId", "azureClientId", "azureClientSecretArn"}),
+		result[3].RequiredFields)
+	assert.Empty(t, result[3].ExactlyOneOf)
+	assert.Equal(t, models.IntegrationTypeGCPLogSource, *result[4].IntegrationType)
+	assert.Equal(t, aws.StringSlice([]string{"gcpProjectId", "gcpServiceAccountSecretArn"}), result[4].RequiredFields)
+	assert.Equal(t, aws.StringSlice([]string{"gcsBucket", "pubSubSubscription"}), result[4].ExactlyOneOf)
+	assert.Equal(t, models.IntegrationTypeSQSQueue, *result[5].IntegrationType)
+	assert.True(t, *result[5].SupportsQueues)
+	assert.Equal(t, aws.StringSlice([]string{"awsAccountId"}), result[5].RequiredFields)
+	assert.Equal(t, models.IntegrationTypeHTTPIngest, *result[6].IntegrationType)
+	assert.False(t, *result[6].SupportsQueues)
+	assert.Empty(t, result[6].RequiredFields)
+	assert.Equal(t, models.IntegrationTypeOkta, *result[7].IntegrationType)
+	assert.Equal(t, aws.StringSlice([]string{"oktaDomain"}), result[7].RequiredFields)
+	assert.Equal(t, aws.StringSlice([]string{"apiToken"}), result[7].CredentialFields)
+	assert.Equal(t, models.IntegrationTypeSyslog, *result[8].IntegrationType)
+	assert.Equal(t, aws.StringSlice([]string{"syslogAllowedCidrs"}), result[8].RequiredFields)
+	assert.Equal(t, models.IntegrationTypeAWSOrganization, *result[9].IntegrationType)
+	assert.True(t, *result[9].SupportsCWE)
+	assert.Equal(t, aws.StringSlice([]string{"awsAccountId"}), result[9].RequiredFields)
+}
+
+// The validators must accept and reject exactly what the advertised capabilities say.
+func TestListIntegrationTypesMatchesValidator(t *testing.T) {
+	validate, err := models.Validator()
+	require.NoError(t, err)
+	types, err := apiTest.ListIntegrationTypes(&models.ListIntegrationTypesInput{})
+	require.NoError(t, err)
+
+	for _, capabilities := range types {
+		integrationType := *capabilities.IntegrationType
+		settings := validSettings(integrationType)
+		assert.NoError(t, validate.Struct(settings), integrationType)
+
+		settings = validSettings(integrationType)
+		settings.CWEEnabled = aws.Bool(true)
+		assert.Equal(t, *capabilities.SupportsCWE, validate.Struct(settings) == nil, integrationType)
+
+		settings = validSettings(integrationType)
+		settings.RemediationEnabled = aws.Bool(true)
+		assert.Equal(t, *capabilities.SupportsRemediation, validate.Struct(settings) == nil, integrationType)
+
+		settings = validSettings(integrationType)
+		settings.S3Buckets = aws.StringSlice([]string{"bucket/prefix"})
+		assert.Equal(t, *capabilities.SupportsPrefixes, validate.Struct(settings) == nil, integrationType)
+		settings = validSettings(integrationType)
+		settings.S3ObjectFilters = map[string]*models.S3ObjectFilter{"bucket": {Suffixes: aws.StringSlice([]string{".json.gz"})}}
+		assert.Equal(t, *capabilities.SupportsPrefixes, validate.Struct(settings) == nil, integrationType)
+
+		settings = validSettings(integrationType)
+		settings.IsOrgTrail = aws.Bool(true)
+		settings.ManagementAccountID = aws.String(testManagementAccountID)
+		assert.Equal(t, *capabilities.SupportsOrgTrail, validate.Struct(settings) == nil, integrationType)
+
+		settings = validSettings(integrationType)
+		settings.StreamARN = aws.String(testStreamARN)
+		assert.Equal(t, *capabilities.SupportsStreams, validate.Struct(settings) == nil, integrationType)
+
+		settings = validSettings(integrationType)
+		settings.RoleChain = aws.StringSlice([]string{"arn:aws:iam::123456789012:role/LogAccessRole"})
+		assert.Equal(t, *capabilities.SupportsRoleChain, validate.Struct(settings) == nil, integrationType)
+
+		settings = validSettings(integrationType)
+		settings.SQSQueueArn = aws.String(testSQSQueueArn)
+		assert.Equal(t, *capabilities.SupportsQueues, validate.Struct(settings) == nil, integrationType)
+
+		required := make(map[string]bool)
+		for _, field := range capabilities.RequiredFields {
+			required[*field] = true
+		}
+		settings = validSettings(integrationType)
+		settings.S3Buckets = nil
+		assert.Equal(t, !required["s3Buckets"], validate.Struct(settings) == nil, integrationType)
+		settings = validSettings(integrationType)
+		settings.StreamARN = nil
+		assert.Equal(t, !required["streamArn"], validate.Struct(settings) == nil, integrationType)
+
+		// Setting a second field of the ones exactly one of which must be set makes the settings invalid
+		settings = validSettings(integrationType)
+		settings.PubSubSubscription = aws.String(testPubSubSubscription)
+		assert.Equal(t, len(capabilities.ExactlyOneOf) == 0, validate.Struct(settings) == nil, integrationType)
+	}
+
+	settings := validSettings("aws-unknown")
+	assert.Error(t, validate.Struct(settings))
+}
@@ -19,7 +19,18 @@ package api
  */
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
 	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+)
+
+const (
+	defaultListPageSize = 25
+
+	// Bounds the read capacity a page uses when most of the integrations are filtered out
+	maxListPageScans = 10
 )
 
 // ListIntegrations returns all enabled integrations across each organization.
@@ -28,5 +39,81 @@ import (
 func (API) ListIntegrations(
 	input *models.ListIntegrationsInput) ([]*models.SourceIntegration, error) {
 
-	return db.ScanEnabledIntegrations(input)
+	integrations, err := db.ScanEnabledIntegrations(input)
+	if err != nil || input.ProcessingRegion == nil {
+		return integrations, err
+	}
+	result := make([]*models.SourceIntegration, 0, len(integrations))
+	for _, integration := range integrations {
+		if processingRegionOf(integration.SourceIntegrationMetadata) == *input.ProcessingRegion {
+			result = append(result, integration)
+		}
+	}
+	return result, nil
+}
+
+// ListIntegrationsPages returns all enabled integrations across each organization, like ListIntegrations.
+//
+// The table is read page by page: if a page fails after the first one, the integrations of the previous pages
+// are returned in an incomplete listing with the key to resume from, rather than failing the whole listing.
+func (API) ListIntegrationsPages(input *models.ListIntegrationsPagesInput) (*models.IntegrationsListing, error) {
+	listing := &models.IntegrationsListing{
+		Integrations: make([]*models.SourceIntegration, 0),
+		Incomplete:   aws.Bool(false),
+	}
+	startKey := input.ExclusiveStartKey
+	for page := 0; page == 0 || startKey != nil; page++ {
+		integrations, lastKey, err := db.ScanEnabledIntegrationsPage(
+			&ddb.IntegrationFilter{IntegrationType: input.IntegrationType}, 0, startKey)
+		if err != nil && page == 0 {
+			// Nothing was gathered, the request can be retried as it is
+			return nil, err
+		}
+		if err != nil {
+			zap.L().Warn("listing integrations stopped partway",
+				zap.Int("pages", page), zap.Int("integrations", len(listing.Integrations)), zap.Error(err))
+			listing.Incomplete = aws.Bool(true)
+			listing.ErrorMessage = aws.String(err.Error())
+			listing.ExclusiveStartKey = startKey
+			return listing, nil
+		}
+		listing.Integrations = append(listing.Integrations, integrations...)
+		startKey = lastKey
+	}
+	return listing, nil
+}
+
+// ListIntegrationsPage returns a page of the enabled integrations, filtered like ListIntegrations.
+//
+// The filters are applied by DynamoDB as the table is scanned, so the page can be short when most of the
+// integrations are filtered out: follow the LastEvaluatedKey until it's empty to list every integration.
+func (API) ListIntegrationsPage(input *models.ListIntegrationsPageInput) (*models.IntegrationsPage, error) {
+	filter := &ddb.IntegrationFilter{
+		IntegrationType: input.IntegrationType,
+		ScanStatus:      input.ScanStatus,
+		HealthStatus:    input.HealthStatus,
+		LabelPrefix:     input.LabelPrefix,
+		Tags:            input.Tags,
+	}
+	pageSize := defaultListPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+
+	page := &models.IntegrationsPage{Integrations: make([]*models.SourceIntegration, 0, pageSize)}
+	startKey := input.ExclusiveStartKey
+	for scans := 0; scans == 0 || startKey != nil; scans++ {
+		if scans == maxListPageScans || len(page.Integrations) == pageSize {
+			page.LastEvaluatedKey = startKey
+			return page, nil
+		}
+		// No more integrations are read than the page has room for, so the page resumes right after the last one
+		integrations, lastKey, err := db.ScanEnabledIntegrationsPage(filter, int64(pageSize-len(page.Integrations)), startKey)
+		if err != nil {
+			return nil, err
+		}
+		page.Integrations = append(page.Integrations, integrations...)
+		startKey = lastKey
+	}
+	return page, nil
 }
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,6 +32,7 @@ import (
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 func TestListIntegrations(t *testing.T) {
@@ -119,3 +121,154 @@ func TestHandleListIntegrationsScanError(t *testing.T) {
 	require.NotNil(t, err)
 	assert.Nil(t, out)
 }
+
+const (
+	firstPageIntegrationID  = "11111111-1111-4111-8111-111111111111"
+	secondPageIntegrationID = "22222222-2222-4222-8222-222222222222"
+	thirdPageIntegrationID  = "33333333-3333-4333-8333-333333333333"
+)
+
+// mockFailingScanClient returns one integration per page, the page started after failStartKey fails once.
+type mockFailingScanClient struct {
+	*modelstest.MockDDBClient
+	failStartKey *string
+	scans        []*dynamodb.ScanInput
+}
+
+func (client *mockFailingScanClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	client.scans = append(client.scans, input)
+	ids := []string{firstPageIntegrationID, secondPageIntegrationID, thirdPageIntegrationID}
+	page := 0
+	if input.ExclusiveStartKey != nil {
+		for i, id := range ids {
+			if id == *input.ExclusiveStartKey["integrationId"].S {
+				page = i + 1
+			}
+		}
+		if client.failStartKey != nil && *client.failStartKey == *input.ExclusiveStartKey["integrationId"].S {
+			client.failStartKey = nil
+			return nil, awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)
+		}
+	}
+	output := &dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{{
+		"integrationId": {S: aws.String(ids[page])},
+		"scanEnabled":   {BOOL: aws.Bool(true)},
+	}}}
+	if page < len(ids)-1 {
+		output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{"integrationId": {S: aws.String(ids[page])}}
+	}
+	return output, nil
+}
+
+func listedIDs(integrations []*models.SourceIntegration) []string {
+	ids := make([]string, len(integrations))
+	for i, integration := range integrations {
+		ids[i] = *integration.IntegrationID
+	}
+	return ids
+}
+
+func TestListIntegrationsFollowsPages(t *testing.T) {
+	client := &mockFailingScanClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+
+	out, err := apiTest.ListIntegrations(&models.ListIntegrationsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{firstPageIntegrationID, secondPageIntegrationID, thirdPageIntegrationID}, listedIDs(out))
+	assert.Len(t, client.scans, 3)
+}
+
+func TestListIntegrationsPagesFailsPartway(t *testing.T) {
+	client := &mockFailingScanClient{MockDDBClient: &modelstest.MockDDBClient{}, failStartKey: aws.String(secondPageIntegrationID)}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+
+	listing, err := apiTest.ListIntegrationsPages(&models.ListIntegrationsPagesInput{})
+	require.NoError(t, err)
+	assert.True(t, *listing.Incomplete)
+	assert.Contains(t, *listing.ErrorMessage, "throttled")
+	assert.Equal(t, []string{firstPageIntegrationID, secondPageIntegrationID}, listedIDs(listing.Integrations))
+	assert.Equal(t, aws.String(secondPageIntegrationID), listing.ExclusiveStartKey)
+
+	// Resuming goes on from the page which failed
+	listing, err = apiTest.ListIntegrationsPages(&models.ListIntegrationsPagesInput{ExclusiveStartKey: listing.ExclusiveStartKey})
+	require.NoError(t, err)
+	assert.False(t, *listing.Incomplete)
+	assert.Nil(t, listing.ErrorMessage)
+	assert.Nil(t, listing.ExclusiveStartKey)
+	assert.Equal(t, []string{thirdPageIntegrationID}, listedIDs(listing.Integrations))
+}
+
+func TestListIntegrationsPagesFirstPageFails(t *testing.T) {
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{TestErr: true}, TableName: "test"}
+
+	listing, err := apiTest.ListIntegrationsPages(&models.ListIntegrationsPagesInput{})
+	assert.Nil(t, listing)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+}
+
+func TestListIntegrationsPage(t *testing.T) {
+	client := &mockFailingScanClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+
+	page, err := apiTest.ListIntegrationsPage(&models.ListIntegrationsPageInput{PageSize: aws.Int(2)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{firstPageIntegrationID, secondPageIntegrationID}, listedIDs(page.Integrations))
+	assert.Equal(t, aws.String(secondPageIntegrationID), page.LastEvaluatedKey)
+	require.Len(t, client.scans, 2)
+	assert.Equal(t, aws.Int64(2), client.scans[0].Limit)
+	assert.Equal(t, aws.Int64(1), client.scans[1].Limit)
+
+	page, err = apiTest.ListIntegrationsPage(&models.ListIntegrationsPageInput{
+		PageSize:          aws.Int(2),
+		ExclusiveStartKey: page.LastEvaluatedKey,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{thirdPageIntegrationID}, listedIDs(page.Integrations))
+	assert.Nil(t, page.LastEvaluatedKey)
+}
+
+func TestListIntegrationsPageFilters(t *testing.T) {
+	client := &mockFailingScanClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+
+	_, err := apiTest.ListIntegrationsPage(&models.ListIntegrationsPageInput{
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		ScanStatus:      aws.String(models.StatusError),
+		HealthStatus:    aws.String(models.HealthStatusDegraded),
+		LabelPrefix:     aws.String("prod-"),
+	})
+	require.NoError(t, err)
+	scan := client.scans[0]
+	assert.Contains(t, *scan.FilterExpression, "begins_with")
+	values := make([]string, 0, len(scan.ExpressionAttributeValues))
+	for _, value := range scan.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.ElementsMatch(t, []string{models.IntegrationTypeAWS3, models.StatusError, models.HealthStatusDegraded, "prod-"}, values)
+	assert.Equal(t, aws.Int64(defaultListPageSize), scan.Limit)
+}
+
+func TestListIntegrationsPageTags(t *testing.T) {
+	client := &mockFailingScanClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+
+	_, err := apiTest.ListIntegrationsPage(&models.ListIntegrationsPageInput{
+		Tags: map[string]*string{"env": aws.String("prod"), "business-unit": aws.String("payments")},
+	})
+	require.NoError(t, err)
+	scan := client.scans[0]
+	names := make([]string, 0, len(scan.ExpressionAttributeNames))
+	for _, name := range scan.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Subset(t, names, []string{"tags", "env", "business-unit"})
+	values := make([]string, 0, len(scan.ExpressionAttributeValues))
+	for _, value := range scan.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.ElementsMatch(t, []string{"prod", "payments"}, values)
+}
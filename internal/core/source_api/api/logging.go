@@ -0,0 +1,43 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+)
+
+// withIntegration returns the API with a logger which tags every log message with the integration ID.
+//
+// The Lambda handler has already tagged the global logger with the correlation ID of the invocation, so
+// all of the log messages for one operation, including those of its health check, share both IDs. The
+// operations pass the returned API down their call path. Usage:
+//
+//	api = api.withIntegration(input.IntegrationID)
+func (api API) withIntegration(integrationID *string) API {
+	return API{logger: api.log().With(zap.String("integrationId", aws.StringValue(integrationID))), outputs: api.outputs}
+}
+
+// log returns the logger of the operation.
+func (api API) log() *zap.Logger {
+	if api.logger != nil {
+		return api.logger
+	}
+	return zap.L()
+}
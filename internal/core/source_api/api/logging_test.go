@@ -0,0 +1,92 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func TestUpdateIntegrationSettingsLogsCorrelationID(t *testing.T) {
+	// The Lambda handler tags the global logger with the correlation ID
+	core, logs := observer.New(zap.DebugLevel)
+	defer zap.ReplaceGlobals(zap.New(core).With(zap.String("correlationId", "test-correlation-id")))()
+
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"awsAccountId":    {S: aws.String(testAccountID)},
+		"integrationId":   {S: aws.String(testIntegrationID)},
+		"integrationType": {S: aws.String(models.IntegrationTypeAWSScan)},
+	}}, nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+	evaluateIntegrationFunc = evaluateIntegration
+	evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		ScanEnabled:   aws.Bool(true),
+	})
+	require.NoError(t, err)
+
+	// Every message of the operation, including the health check, carries both IDs.
+	// The DynamoDB helpers log with the global logger, their messages only carry the correlation ID.
+	require.NotEmpty(t, logs.FilterMessage("beginning source health check").All())
+	require.NotEmpty(t, logs.FilterMessage("checking role").All())
+	for _, entry := range logs.All() {
+		fields := entry.ContextMap()
+		assert.Equal(t, "test-correlation-id", fields["correlationId"], entry.Message)
+		if entry.Message != "update item input" {
+			assert.Equal(t, testIntegrationID, fields["integrationId"], entry.Message)
+		}
+	}
+
+	// The global logger is never tagged with the integration ID
+	zap.L().Info("after")
+	assert.NotContains(t, logs.FilterMessage("after").All()[0].ContextMap(), "integrationId")
+}
+
+func TestWithIntegration(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	first, second := API{}.withIntegration(aws.String("integration-1")), API{}.withIntegration(aws.String("integration-2"))
+	first.log().Info("first")
+	second.log().Info("second")
+	API{}.log().Info("global")
+
+	assert.Equal(t, "integration-1", logs.FilterMessage("first").All()[0].ContextMap()["integrationId"])
+	assert.Equal(t, "integration-2", logs.FilterMessage("second").All()[0].ContextMap()["integrationId"])
+	assert.NotContains(t, logs.FilterMessage("global").All()[0].ContextMap(), "integrationId")
+}
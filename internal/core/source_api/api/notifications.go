@@ -0,0 +1,205 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// alertOutputs are the IDs of the configured alert outputs, listed from the outputs-api the first time they're needed.
+type alertOutputs struct {
+	once       sync.Once
+	configured map[string]struct{}
+	err        error
+}
+
+// withAlertOutputs returns the API of an operation which checks the notification targets of several integrations.
+//
+// The alert outputs are then listed once for the whole operation, instead of once per integration. Usage:
+//
+//	api = api.withAlertOutputs()
+func (api API) withAlertOutputs() API {
+	if api.outputs == nil {
+		api.outputs = &alertOutputs{}
+	}
+	return api
+}
+
+func (outputs *alertOutputs) list() (map[string]struct{}, error) {
+	outputs.once.Do(func() {
+		input := outputmodels.LambdaInput{GetOutputs: &outputmodels.GetOutputsInput{}}
+		var listed outputmodels.GetOutputsOutput
+		if outputs.err = genericapi.Invoke(lambdaClient, outputsAPI, &input, &listed); outputs.err != nil {
+			return
+		}
+		outputs.configured = make(map[string]struct{}, len(listed))
+		for _, output := range listed {
+			outputs.configured[aws.StringValue(output.OutputID)] = struct{}{}
+		}
+	})
+	return outputs.configured, outputs.err
+}
+
+// checkNotificationTargets returns an error unless every target is a configured alert output.
+func (api API) checkNotificationTargets(targets []*string) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	outputs := api.outputs
+	if outputs == nil {
+		outputs = &alertOutputs{}
+	}
+	configured, err := outputs.list()
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	for _, target := range targets {
+		if _, ok := configured[*target]; !ok {
+			unknown = append(unknown, *target)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &genericapi.InvalidInputError{
+			Message: "unknown notification targets: " + strings.Join(unknown, ", ")}
+	}
+	return nil
+}
+
+// notifyHealthChange sends an alert to the notification targets of an integration when its health changes.
+//
+// Without notification targets, alert delivery routes the alert to the default outputs for its severity.
+// Notifications are a side effect: a failure only fails the change itself with strict side effects.
+func notifyHealthChange(integration *models.SourceIntegrationMetadata, healthStatus *string, failedChecks []*string) error {
+	previous := aws.StringValue(integration.HealthStatus)
+	if healthStatus == nil || previous == *healthStatus || alertQueueURL == "" {
+		return nil
+	}
+	// Integrations saved before health statuses were stored are not worth an alert when they pass
+	if previous == "" && *healthStatus == models.HealthStatusHealthy {
+		return nil
+	}
+
+	severity, description := "INFO", fmt.Sprintf("health changed from %s to %s", previous, *healthStatus)
+	switch *healthStatus {
+	case models.HealthStatusDegraded:
+		severity = "MEDIUM"
+		description += ", failed checks: " + strings.Join(aws.StringValueSlice(failedChecks), ", ")
+	case models.HealthStatusUnhealthy:
+		severity = "HIGH"
+		description += ", failed checks: " + strings.Join(aws.StringValueSlice(failedChecks), ", ")
+	}
+	alert := &alertmodels.Alert{
+		CreatedAt:         aws.Time(time.Now().UTC()),
+		OutputIDs:         integration.NotificationTargets,
+		PolicyID:          integration.IntegrationID,
+		PolicyName:        aws.String("Source health: " + aws.StringValue(integration.IntegrationLabel)),
+		PolicyDescription: aws.String(description),
+		Severity:          aws.String(severity),
+
+		SourceIntegrationID: integration.IntegrationID,
+	}
+	return sendAlert(integration.IntegrationID, "health change", alert)
+}
+
+// notifyKmsKeyAliasChange sends an alert to the notification targets of an integration when some of its KMS aliases
+// point to a different key than the one pinned, so the objects encrypted with the new key can't be decrypted.
+//
+// Like the health changes, the alert is a side effect.
+func notifyKmsKeyAliasChange(integration *models.SourceIntegrationMetadata, changes []*models.KmsKeyAliasChange) error {
+	if len(changes) == 0 || alertQueueURL == "" {
+		return nil
+	}
+
+	repointed := make([]string, len(changes))
+	for i, change := range changes {
+		repointed[i] = fmt.Sprintf("%s now points to %s instead of %s",
+			*change.Alias, aws.StringValue(change.CurrentArn), aws.StringValue(change.PinnedArn))
+	}
+	alert := &alertmodels.Alert{
+		CreatedAt:         aws.Time(time.Now().UTC()),
+		OutputIDs:         integration.NotificationTargets,
+		PolicyID:          integration.IntegrationID,
+		PolicyName:        aws.String("Source KMS aliases: " + aws.StringValue(integration.IntegrationLabel)),
+		PolicyDescription: aws.String("KMS aliases changed, update the integration settings: " + strings.Join(repointed, ", ")),
+		Severity:          aws.String("MEDIUM"),
+
+		SourceIntegrationID: integration.IntegrationID,
+	}
+	return sendAlert(integration.IntegrationID, "KMS alias change", alert)
+}
+
+// notifyErrorRate sends an alert to the notification targets of an integration when a scan failed on a larger
+// fraction of the objects it processed than the ErrorRateThreshold of the integration.
+//
+// Like the health changes, the alert is a side effect.
+func notifyErrorRate(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationLastScanEndInput) error {
+	processed, failed := aws.Int64Value(input.ObjectsProcessed), aws.Int64Value(input.ObjectsFailed)
+	if integration == nil || integration.ErrorRateThreshold == nil || processed == 0 || alertQueueURL == "" {
+		return nil
+	}
+	errorRate := float64(failed) / float64(processed)
+	if errorRate <= *integration.ErrorRateThreshold {
+		return nil
+	}
+
+	alert := &alertmodels.Alert{
+		CreatedAt:  aws.Time(time.Now().UTC()),
+		OutputIDs:  integration.NotificationTargets,
+		PolicyID:   integration.IntegrationID,
+		PolicyName: aws.String("Source error rate: " + aws.StringValue(integration.IntegrationLabel)),
+		PolicyDescription: aws.String(fmt.Sprintf("scan failed on %d of %d objects (%.1f%%), above the threshold of %.1f%%",
+			failed, processed, 100*errorRate, 100**integration.ErrorRateThreshold)),
+		Severity:            aws.String("MEDIUM"),
+		SourceIntegrationID: integration.IntegrationID,
+	}
+	return sendAlert(integration.IntegrationID, "error rate", alert)
+}
+
+// sendAlert queues an alert about an integration to alert delivery, as the notification side effect.
+func sendAlert(integrationID *string, kind string, alert *alertmodels.Alert) error {
+	return runSideEffect(sideEffectNotify, integrationID, func() error {
+		body, err := jsoniter.MarshalToString(alert)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal "+kind+" alert")
+		}
+		_, err = SQSClient.SendMessage(&sqs.SendMessageInput{
+			MessageBody: aws.String(body),
+			QueueUrl:    aws.String(alertQueueURL),
+		})
+		return errors.Wrap(err, "failed to send "+kind+" alert")
+	})
+}
@@ -0,0 +1,276 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sts"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	outputmodels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testOutputID      = "7d1c5854-f3ea-491c-8a52-0aa0d58cb456"
+	testOtherOutputID = "f6cfad0a-9bb0-4681-9503-02c54cc979c7"
+)
+
+type mockLambdaClient struct {
+	lambdaiface.LambdaAPI
+	mock.Mock
+}
+
+func (client *mockLambdaClient) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
+}
+
+func (client *mockSQSClient) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
+
+// mockOutputs configures the alert outputs returned by the outputs-api.
+func mockOutputs(t *testing.T, outputIDs ...string) *mockLambdaClient {
+	outputs := make(outputmodels.GetOutputsOutput, len(outputIDs))
+	for i, outputID := range outputIDs {
+		outputs[i] = &outputmodels.AlertOutput{OutputID: aws.String(outputID)}
+	}
+	payload, err := jsoniter.Marshal(outputs)
+	require.NoError(t, err)
+	mockLambda := &mockLambdaClient{}
+	mockLambda.On("Invoke", mock.Anything).Return(&lambda.InvokeOutput{Payload: payload}, nil)
+	lambdaClient = mockLambda
+	return mockLambda
+}
+
+// mockHealthChange stores a healthy integration whose next update degrades, and captures the alert sent.
+func mockHealthChange(t *testing.T, targets ...string) *sqs.SendMessageInput {
+	mockBucketOwnership(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:        aws.String(testAccountID),
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationLabel:    aws.String("team-logs"),
+		IntegrationType:     aws.String(models.IntegrationTypeAWS3),
+		HealthStatus:        aws.String(models.HealthStatusHealthy),
+		NotificationTargets: aws.StringSlice(targets),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, errors.New("access denied"))
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	evaluateIntegrationHealthFunc = evaluateIntegrationHealth
+
+	var message *sqs.SendMessageInput
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).
+		Run(func(args mock.Arguments) { message = args.Get(0).(*sqs.SendMessageInput) })
+	SQSClient = mockSQS
+	alertQueueURL = "alert-queue"
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		S3Buckets:          aws.StringSlice([]string{"bad"}),
+		AllowPartialHealth: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	alertQueueURL = ""
+	mockSQS.AssertExpectations(t)
+	return message
+}
+
+func TestHealthChangeRoutesToNotificationTargets(t *testing.T) {
+	message := mockHealthChange(t, testOutputID)
+
+	assert.Equal(t, "alert-queue", *message.QueueUrl)
+	var alert alertmodels.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*message.MessageBody, &alert))
+	assert.Equal(t, aws.StringSlice([]string{testOutputID}), alert.OutputIDs)
+	assert.Equal(t, testIntegrationID, *alert.PolicyID)
+	assert.Equal(t, "MEDIUM", *alert.Severity)
+	assert.Equal(t, "health changed from healthy to degraded, failed checks: s3Bucket:bad", *alert.PolicyDescription)
+}
+
+func TestHealthChangeDefaultOutputs(t *testing.T) {
+	message := mockHealthChange(t)
+
+	var alert alertmodels.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*message.MessageBody, &alert))
+	// Alert delivery routes the alert, the routing rules can match the integration
+	assert.Empty(t, alert.OutputIDs)
+	assert.Equal(t, testIntegrationID, *alert.SourceIntegrationID)
+}
+
+func TestNotifyHealthChangeUnchanged(t *testing.T) {
+	mockSQS := &mockSQSClient{}
+	SQSClient = mockSQS
+	alertQueueURL = "alert-queue"
+
+	notifyHealthChange(&models.SourceIntegrationMetadata{HealthStatus: aws.String(models.HealthStatusHealthy)},
+		aws.String(models.HealthStatusHealthy), nil)
+	alertQueueURL = ""
+	mockSQS.AssertNotCalled(t, "SendMessage", mock.Anything)
+}
+
+func TestNotifyHealthChangeUnhealthy(t *testing.T) {
+	var message *sqs.SendMessageInput
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).
+		Run(func(args mock.Arguments) { message = args.Get(0).(*sqs.SendMessageInput) })
+	SQSClient = mockSQS
+	alertQueueURL = "alert-queue"
+
+	err := notifyHealthChange(&models.SourceIntegrationMetadata{
+		IntegrationID:    aws.String(testIntegrationID),
+		IntegrationLabel: aws.String("team-logs"),
+		HealthStatus:     aws.String(models.HealthStatusHealthy),
+	}, aws.String(models.HealthStatusUnhealthy), aws.StringSlice([]string{failedRolesCheck}))
+	alertQueueURL = ""
+	require.NoError(t, err)
+
+	var alert alertmodels.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*message.MessageBody, &alert))
+	assert.Equal(t, "HIGH", *alert.Severity)
+	assert.Equal(t, "health changed from healthy to unhealthy, failed checks: roles", *alert.PolicyDescription)
+}
+
+func TestUpdateIntegrationSettingsUnknownNotificationTarget(t *testing.T) {
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+	})
+	mockLambda := mockOutputs(t, testOutputID)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:       aws.String(testIntegrationID),
+		NotificationTargets: aws.StringSlice([]string{testOutputID, testOtherOutputID}),
+	})
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Contains(t, err.Error(), testOtherOutputID)
+	assert.NotContains(t, err.Error(), testOutputID)
+	mockLambda.AssertExpectations(t)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestCheckNotificationTargets(t *testing.T) {
+	mockLambda := mockOutputs(t, testOutputID, testOtherOutputID)
+	assert.NoError(t, apiTest.checkNotificationTargets(aws.StringSlice([]string{testOtherOutputID})))
+	mockLambda.AssertExpectations(t)
+
+	// The outputs-api is not called when there are no targets
+	mockLambda = mockOutputs(t)
+	assert.NoError(t, apiTest.checkNotificationTargets(nil))
+	mockLambda.AssertNotCalled(t, "Invoke", mock.Anything)
+}
+
+func TestCheckNotificationTargetsOncePerOperation(t *testing.T) {
+	mockLambda := mockOutputs(t, testOutputID, testOtherOutputID)
+	api := apiTest.withAlertOutputs().withIntegration(aws.String(testIntegrationID))
+	assert.NoError(t, api.checkNotificationTargets(aws.StringSlice([]string{testOutputID})))
+	assert.NoError(t, api.checkNotificationTargets(aws.StringSlice([]string{testOtherOutputID})))
+	assert.IsType(t, &genericapi.InvalidInputError{}, api.checkNotificationTargets(aws.StringSlice([]string{"unknown"})))
+	mockLambda.AssertNumberOfCalls(t, "Invoke", 1)
+}
+
+// scanEndWithErrors ends a scan of an integration alerting above an error rate of 10%, and returns the alerts sent.
+func scanEndWithErrors(t *testing.T, objectsFailed int64) []*sqs.SendMessageInput {
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationLabel:    aws.String("team-logs"),
+		IntegrationType:     aws.String(models.IntegrationTypeAWS3),
+		NotificationTargets: aws.StringSlice([]string{testOutputID}),
+		ErrorRateThreshold:  aws.Float64(0.1),
+	})
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+
+	var messages []*sqs.SendMessageInput
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).
+		Run(func(args mock.Arguments) { messages = append(messages, args.Get(0).(*sqs.SendMessageInput)) })
+	SQSClient = mockSQS
+	alertQueueURL = "alert-queue"
+	defer func() { alertQueueURL = "" }()
+
+	_, err = apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		LastScanEndTime:  aws.Time(time.Now()),
+		ScanStatus:       aws.String(models.StatusOK),
+		ObjectsProcessed: aws.Int64(200),
+		ObjectsFailed:    aws.Int64(objectsFailed),
+	})
+	require.NoError(t, err)
+	return messages
+}
+
+func TestUpdateIntegrationLastScanEndErrorRateAlert(t *testing.T) {
+	messages := scanEndWithErrors(t, 50)
+
+	require.Len(t, messages, 1)
+	var alert alertmodels.Alert
+	require.NoError(t, jsoniter.UnmarshalFromString(*messages[0].MessageBody, &alert))
+	assert.Equal(t, aws.StringSlice([]string{testOutputID}), alert.OutputIDs)
+	assert.Equal(t, "Source error rate: team-logs", *alert.PolicyName)
+	assert.Equal(t, "scan failed on 50 of 200 objects (25.0%), above the threshold of 10.0%", *alert.PolicyDescription)
+}
+
+func TestUpdateIntegrationLastScanEndErrorRateBelowThreshold(t *testing.T) {
+	assert.Empty(t, scanEndWithErrors(t, 0))
+	// The threshold itself is tolerated
+	assert.Empty(t, scanEndWithErrors(t, 20))
+}
+
+func TestErrorRateThresholdValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(threshold float64) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID), ErrorRateThreshold: aws.Float64(threshold)}
+	}
+
+	for _, threshold := range []float64{0, 0.05, 1} {
+		assert.NoError(t, validator.Struct(settings(threshold)))
+	}
+	assert.Error(t, validator.Struct(settings(-0.01)))
+	assert.Error(t, validator.Struct(settings(2)))
+}
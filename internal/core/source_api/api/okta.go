@@ -0,0 +1,124 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The credential the System Log of an Okta organization is read with
+const oktaAPITokenCredential = "apiToken"
+
+var (
+	// The base URL of the API of an Okta organization, tests point it at a local server
+	oktaURL = func(domain string) string { return "https://" + domain }
+
+	oktaClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// oktaHealthChecker checks the API token of the Okta integrations.
+type oktaHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration: the API token must read the System Log.
+func (oktaHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	status, err := api.CheckIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+	return &integrationEvaluation{
+		rolesHealthy: aws.BoolValue(status.OktaSystemLogStatus.Healthy),
+		failedItems:  make([]*string, 0),
+		health:       status,
+	}, nil
+}
+
+// checkOktaSystemLog reads an event of the System Log of the organization with the API token of the integration.
+//
+// A rate limited read is inconclusive, the puller shares the rate limit of the organization.
+func checkOktaSystemLog(input *models.CheckIntegrationInput) models.SourceIntegrationItemStatus {
+	start := time.Now()
+	failed := func(err error) models.SourceIntegrationItemStatus {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	credentials := input.Credentials
+	if credentials == nil {
+		if input.CredentialsSecretArn == nil {
+			return failed(errors.New("the integration has no credentials"))
+		}
+		var err error
+		if credentials, err = loadCredentials(input.CredentialsSecretArn); err != nil {
+			return failed(err)
+		}
+	}
+	token := aws.StringValue(credentials[oktaAPITokenCredential])
+	if token == "" {
+		return failed(errors.New("the credentials have no " + oktaAPITokenCredential))
+	}
+
+	request, err := http.NewRequest(http.MethodGet, oktaURL(aws.StringValue(input.OktaDomain))+"/api/v1/logs?limit=1", nil)
+	if err != nil {
+		return failed(err)
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", "SSWS "+token)
+	response, err := oktaClient.Do(request)
+	if err != nil {
+		return failed(err)
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		return models.SourceIntegrationItemStatus{Healthy: aws.Bool(true), LatencyMillis: millisSince(start)}
+	case http.StatusTooManyRequests:
+		zap.L().Warn("okta system log check rate limited", zap.String("oktaDomain", aws.StringValue(input.OktaDomain)))
+		status := failed(errors.New("reading the system log is rate limited"))
+		status.Inconclusive = aws.Bool(true)
+		return status
+	default:
+		return failed(fmt.Errorf("reading the system log responded with status %d", response.StatusCode))
+	}
+}
+
+// UpdateOktaCursor saves where the next pull of the System Log of an Okta integration resumes from.
+func (API) UpdateOktaCursor(input *models.UpdateOktaCursorInput) error {
+	_, err := db.UpdateItemWithCondition(&ddb.UpdateIntegrationItem{
+		IntegrationID: input.IntegrationID,
+		OktaLogCursor: input.Cursor,
+	}, expression.Name("integrationType").Equal(expression.Value(models.IntegrationTypeOkta)))
+	if _, ok := err.(*ddb.ConditionalCheckFailedError); ok {
+		return &genericapi.InvalidInputError{Message: "only Okta integrations have a cursor"}
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testOktaDomain = "example.okta.com"
+
+// mockOkta serves the System Log of an Okta organization responding with the given status.
+func mockOkta(t *testing.T, status int) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/logs", r.URL.Path)
+		assert.Equal(t, "SSWS token-value", r.Header.Get("Authorization"))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+
+	previousURL := oktaURL
+	oktaURL = func(string) string { return server.URL }
+	t.Cleanup(func() {
+		server.Close()
+		oktaURL = previousURL
+	})
+}
+
+func oktaCheckInput() *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		IntegrationType: aws.String(models.IntegrationTypeOkta),
+		OktaDomain:      aws.String(testOktaDomain),
+		Credentials:     testCredentials(),
+	}
+}
+
+func TestCheckIntegrationOkta(t *testing.T) {
+	mockOkta(t, http.StatusOK)
+
+	result, err := apiTest.CheckIntegration(oktaCheckInput())
+	require.NoError(t, err)
+	assert.True(t, *result.OktaSystemLogStatus.Healthy)
+	assert.Nil(t, result.ProcessingRoleStatus.Healthy)
+
+	evaluation, err := oktaHealthChecker{}.Evaluate(apiTest, oktaCheckInput())
+	require.NoError(t, err)
+	assert.True(t, evaluation.rolesHealthy)
+}
+
+func TestCheckIntegrationOktaTokenRejected(t *testing.T) {
+	mockOkta(t, http.StatusUnauthorized)
+
+	result, err := apiTest.CheckIntegration(oktaCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.OktaSystemLogStatus.Healthy)
+	assert.Equal(t, "reading the system log responded with status 401", *result.OktaSystemLogStatus.ErrorMessage)
+}
+
+func TestCheckIntegrationOktaRateLimited(t *testing.T) {
+	mockOkta(t, http.StatusTooManyRequests)
+
+	result, err := apiTest.CheckIntegration(oktaCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.OktaSystemLogStatus.Healthy)
+	assert.True(t, *result.OktaSystemLogStatus.Inconclusive)
+}
+
+// The stored credentials are checked when the input doesn't change them
+func TestCheckIntegrationOktaStoredCredentials(t *testing.T) {
+	mockOkta(t, http.StatusOK)
+	previousClient := secretsClient
+	secretsClient = &mockSecretsClient{secret: `{"apiToken": "token-value"}`}
+	t.Cleanup(func() { secretsClient = previousClient })
+	input := oktaCheckInput()
+	input.Credentials, input.CredentialsSecretArn = nil, aws.String(testCredentialsSecretArn)
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.OktaSystemLogStatus.Healthy)
+}
+
+func TestCheckIntegrationOktaNoCredentials(t *testing.T) {
+	input := oktaCheckInput()
+	input.Credentials = nil
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.False(t, *result.OktaSystemLogStatus.Healthy)
+	assert.Equal(t, "the integration has no credentials", *result.OktaSystemLogStatus.ErrorMessage)
+}
+
+func TestCheckIntegrationOktaSecretUnreadable(t *testing.T) {
+	previousClient := secretsClient
+	secretsClient = &mockSecretsClient{err: errors.New("access denied")}
+	t.Cleanup(func() { secretsClient = previousClient })
+	input := oktaCheckInput()
+	input.Credentials, input.CredentialsSecretArn = nil, aws.String(testCredentialsSecretArn)
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.False(t, *result.OktaSystemLogStatus.Healthy)
+}
+
+func TestUpdateOktaCursor(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	err := apiTest.UpdateOktaCursor(&models.UpdateOktaCursorInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Cursor:        aws.String("1584948612733_1"),
+	})
+	require.NoError(t, err)
+	input := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	found := false
+	for _, value := range input.ExpressionAttributeValues {
+		found = found || aws.StringValue(value.S) == "1584948612733_1"
+	}
+	assert.True(t, found)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateOktaCursorNotOkta(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+
+	err := apiTest.UpdateOktaCursor(&models.UpdateOktaCursorInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Cursor:        aws.String("1584948612733_1"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, "only Okta integrations have a cursor", err.(*genericapi.InvalidInputError).Message)
+}
@@ -0,0 +1,128 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// onboardingStep is a single prerequisite check for a new integration.
+type onboardingStep struct {
+	name     string
+	nextStep string
+	check    func() models.SourceIntegrationItemStatus
+}
+
+// CheckOnboardingReadiness runs the prerequisite checks for a new integration, without creating anything.
+//
+// The checks are returned in the order they are run. Once a check fails, the following ones are skipped
+// since they depend on it (e.g. buckets can't be checked without the log processing role).
+func (API) CheckOnboardingReadiness(input *models.CheckOnboardingReadinessInput) ([]*models.OnboardingCheck, error) {
+	results := make([]*models.OnboardingCheck, 0)
+	failed := false
+	for _, step := range onboardingSteps(input) {
+		result := &models.OnboardingCheck{Name: aws.String(step.name)}
+		results = append(results, result)
+		if failed {
+			result.Status = aws.String(models.OnboardingCheckSkipped)
+			continue
+		}
+
+		status := step.check()
+		if aws.BoolValue(status.Healthy) {
+			result.Status = aws.String(models.OnboardingCheckPassed)
+			continue
+		}
+		failed = true
+		result.Status = aws.String(models.OnboardingCheckFailed)
+		result.ErrorMessage = status.ErrorMessage
+		result.NextStep = aws.String(step.nextStep)
+	}
+	return results, nil
+}
+
+// onboardingSteps returns the ordered prerequisites for the intended integration.
+func onboardingSteps(input *models.CheckOnboardingReadinessInput) []onboardingStep {
+	accountID := *input.AWSAccountID
+	deployStack := fmt.Sprintf("Deploy the Panther CloudFormation template in account %s, "+
+		"and make sure its role trusts the Panther account", accountID)
+
+	var steps []onboardingStep
+	roleStep := func(name, roleFormat string, roleCreds **credentials.Credentials) onboardingStep {
+		return onboardingStep{
+			name:     name,
+			nextStep: deployStack,
+			check: func() models.SourceIntegrationItemStatus {
+				creds, status := getCredentialsWithStatus(zap.L(), aws.String(fmt.Sprintf(roleFormat, accountID)))
+				if roleCreds != nil {
+					*roleCreds = creds
+				}
+				return status
+			},
+		}
+	}
+
+	switch *input.IntegrationType {
+	case models.IntegrationTypeAWSScan:
+		steps = append(steps, roleStep("auditRole", auditRoleFormat, nil))
+		if aws.BoolValue(input.EnableCWESetup) {
+			steps = append(steps, roleStep("cweRole", cweRoleFormat, nil))
+		}
+		if aws.BoolValue(input.EnableRemediation) {
+			steps = append(steps, roleStep("remediationRole", remediationRoleFormat, nil))
+		}
+
+	case models.IntegrationTypeAWSOrganization:
+		steps = append(steps, roleStep("organizationRole", organizationRoleFormat, nil))
+
+	case models.IntegrationTypeAWS3:
+		var roleCreds *credentials.Credentials
+		steps = append(steps, roleStep("logProcessingRole", logProcessingRoleFormat, &roleCreds))
+		for _, bucket := range input.S3Buckets {
+			bucket := bucket
+			steps = append(steps, onboardingStep{
+				name:     "s3Bucket:" + *bucket,
+				nextStep: fmt.Sprintf("Add bucket %s to the template and grant the log processing role read access", *bucket),
+				check: func() models.SourceIntegrationItemStatus {
+					statuses, _ := checkBuckets(zap.L(), roleCreds, []*string{bucket}, input.S3BucketRegions)
+					return statuses[*bucket]
+				},
+			})
+		}
+		for _, key := range input.KmsKeys {
+			key := key
+			steps = append(steps, onboardingStep{
+				name:     "kmsKey:" + *key,
+				nextStep: fmt.Sprintf("Enable key %s and allow the log processing role to decrypt with it", *key),
+				check: func() models.SourceIntegrationItemStatus {
+					statuses, _ := checkKeys(roleCreds, []*string{key})
+					return statuses[*key]
+				},
+			})
+		}
+	}
+
+	return steps
+}
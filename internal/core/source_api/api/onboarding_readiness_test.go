@@ -0,0 +1,83 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+func TestCheckOnboardingReadinessNotDeployed(t *testing.T) {
+	// The role does not exist yet in the customer account
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, errors.New("AccessDenied"))
+	mockS3 := &mockS3Client{}
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+
+	result, err := apiTest.CheckOnboardingReadiness(&models.CheckOnboardingReadinessInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bucket"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, "logProcessingRole", *result[0].Name)
+	assert.Equal(t, models.OnboardingCheckFailed, *result[0].Status)
+	assert.Equal(t, "AccessDenied", *result[0].ErrorMessage)
+	assert.Contains(t, *result[0].NextStep, "CloudFormation")
+
+	assert.Equal(t, "s3Bucket:bucket", *result[1].Name)
+	assert.Equal(t, models.OnboardingCheckSkipped, *result[1].Status)
+	mockSTS.AssertExpectations(t)
+	mockS3.AssertNotCalled(t, "GetBucketLocation", mock.Anything)
+}
+
+func TestCheckOnboardingReadinessBucketFails(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("good")}).
+		Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("GetBucketLocation", &s3.GetBucketLocationInput{Bucket: aws.String("bad")}).
+		Return(&s3.GetBucketLocationOutput{}, errors.New("AccessDenied"))
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+
+	result, err := apiTest.CheckOnboardingReadiness(&models.CheckOnboardingReadinessInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"good", "bad"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, models.OnboardingCheckPassed, *result[0].Status)
+	assert.Equal(t, models.OnboardingCheckPassed, *result[1].Status)
+	assert.Equal(t, models.OnboardingCheckFailed, *result[2].Status)
+	assert.Contains(t, *result[2].NextStep, "bad")
+	mockS3.AssertExpectations(t)
+}
@@ -0,0 +1,133 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The client used to describe the organization of an account assumes its log processing role
+var organizationsClientFunc = func(roleCredentials *credentials.Credentials) organizationsiface.OrganizationsAPI {
+	return organizations.New(sess, &aws.Config{Credentials: roleCredentials})
+}
+
+// checkOrgTrail verifies the account is a member of the organization managed by the management account.
+//
+// The ID of the organization is returned along with the status, it is empty unless the status is healthy.
+func checkOrgTrail(
+	logger *zap.Logger, roleCredentials *credentials.Credentials, managementAccountID *string) (string, models.SourceIntegrationItemStatus) {
+
+	start := time.Now()
+	output, err := organizationsClientFunc(roleCredentials).DescribeOrganization(&organizations.DescribeOrganizationInput{})
+	switch {
+	case isThrottlingError(err):
+		logger.Warn("organization check throttled", zap.Error(err))
+		return "", models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			Inconclusive:  aws.Bool(true),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	case err != nil:
+		return "", models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	case aws.StringValue(output.Organization.MasterAccountId) != aws.StringValue(managementAccountID):
+		return "", models.SourceIntegrationItemStatus{
+			Healthy: aws.Bool(false),
+			ErrorMessage: aws.String(fmt.Sprintf("organization %s is managed by %s, not %s",
+				aws.StringValue(output.Organization.Id), aws.StringValue(output.Organization.MasterAccountId),
+				aws.StringValue(managementAccountID))),
+			LatencyMillis: millisSince(start),
+		}
+	default:
+		return aws.StringValue(output.Organization.Id), models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(true),
+			LatencyMillis: millisSince(start),
+		}
+	}
+}
+
+// checkOrgTrailBuckets verifies the role can list the logs of the organization in each reachable bucket.
+//
+// An org trail delivers the logs of every account under "AWSLogs/<organization ID>/", after the prefix
+// of the bucket entry if it has one. A bucket without any such log is not receiving the org trail.
+func checkOrgTrailBuckets(logger *zap.Logger, roleCredentials *credentials.Credentials, organizationID string, buckets []*string,
+	regions map[string]*string, bucketStatuses map[string]models.SourceIntegrationItemStatus) map[string]models.SourceIntegrationItemStatus {
+
+	clientForBucket := bucketClients(roleCredentials, regions)
+	statuses := make(map[string]models.SourceIntegrationItemStatus, len(buckets))
+	for _, bucket := range buckets {
+		if !aws.BoolValue(bucketStatuses[*bucket].Healthy) {
+			// Already reported by the bucket check
+			continue
+		}
+		name, pattern := parseBucketEntry(*bucket)
+		s3Client, _ := clientForBucket(name)
+		prefix := path.Join(patternPrefix(pattern), "AWSLogs", organizationID) + "/"
+
+		start := time.Now()
+		output, err := s3Client.ListObjectsV2(&s3.ListObjectsV2Input{
+			Bucket:  aws.String(name),
+			Prefix:  aws.String(prefix),
+			MaxKeys: aws.Int64(1),
+		})
+		switch {
+		case isThrottlingError(err):
+			logger.Warn("org trail bucket check throttled", zap.String("bucket", *bucket), zap.Error(err))
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		case err != nil:
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		case len(output.Contents) == 0:
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				ErrorMessage:  aws.String("no org trail logs under " + prefix),
+				LatencyMillis: millisSince(start),
+			}
+		default:
+			statuses[*bucket] = models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(true),
+				LatencyMillis: millisSince(start),
+			}
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,216 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testManagementAccountID = "210987654321"
+	testOrganizationID      = "o-a1b2c3d4e5"
+)
+
+type mockOrganizationsClient struct {
+	organizationsiface.OrganizationsAPI
+	mock.Mock
+}
+
+func (client *mockOrganizationsClient) DescribeOrganization(
+	input *organizations.DescribeOrganizationInput) (*organizations.DescribeOrganizationOutput, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*organizations.DescribeOrganizationOutput), args.Error(1)
+}
+
+// mockOrgTrailClients sets up a healthy log processing role in an account of the given organization.
+func mockOrgTrailClients(managementAccountID string) (*mockS3Client, *mockOrganizationsClient) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	mockOrganizations := &mockOrganizationsClient{}
+	mockOrganizations.On("DescribeOrganization", mock.Anything).Return(&organizations.DescribeOrganizationOutput{
+		Organization: &organizations.Organization{
+			Id:              aws.String(testOrganizationID),
+			MasterAccountId: aws.String(managementAccountID),
+		},
+	}, nil)
+	organizationsClientFunc = func(*credentials.Credentials) organizationsiface.OrganizationsAPI { return mockOrganizations }
+	return mockS3, mockOrganizations
+}
+
+func orgTrailCheckInput(buckets ...string) *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		AWSAccountID:        aws.String(testAccountID),
+		IntegrationType:     aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:           aws.StringSlice(buckets),
+		IsOrgTrail:          aws.Bool(true),
+		ManagementAccountID: aws.String(testManagementAccountID),
+	}
+}
+
+func TestOrgTrailValidation(t *testing.T) {
+	validate, err := models.Validator()
+	require.NoError(t, err)
+
+	settings := validSettings(models.IntegrationTypeAWS3)
+	settings.IsOrgTrail = aws.Bool(true)
+	assert.Error(t, validate.Struct(settings), "the management account is required")
+
+	settings.ManagementAccountID = aws.String("not-an-account")
+	assert.Error(t, validate.Struct(settings))
+
+	settings.ManagementAccountID = aws.String(testManagementAccountID)
+	assert.NoError(t, validate.Struct(settings))
+
+	// Only org trails need a management account
+	settings = validSettings(models.IntegrationTypeAWS3)
+	settings.IsOrgTrail = aws.Bool(false)
+	assert.NoError(t, validate.Struct(settings))
+}
+
+func TestCheckIntegrationOrgTrail(t *testing.T) {
+	mockS3, mockOrganizations := mockOrgTrailClients(testManagementAccountID)
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{
+		Bucket:  aws.String("trail"),
+		Prefix:  aws.String("cloudtrail/AWSLogs/" + testOrganizationID + "/"),
+		MaxKeys: aws.Int64(1),
+	}).Return(&s3.ListObjectsV2Output{Contents: []*s3.Object{{Key: aws.String("log.json.gz")}}}, nil)
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{
+		Bucket:  aws.String("other"),
+		Prefix:  aws.String("AWSLogs/" + testOrganizationID + "/"),
+		MaxKeys: aws.Int64(1),
+	}).Return(&s3.ListObjectsV2Output{}, nil)
+	// The sample of the object check
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	input := orgTrailCheckInput("trail/cloudtrail/*", "other")
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *result.OrgTrailStatus.Healthy)
+	assert.True(t, *result.OrgTrailBucketsStatus["trail/cloudtrail/*"].Healthy)
+	empty := result.OrgTrailBucketsStatus["other"]
+	assert.False(t, *empty.Healthy)
+	assert.Equal(t, "no org trail logs under AWSLogs/"+testOrganizationID+"/", *empty.ErrorMessage)
+	mockOrganizations.AssertExpectations(t)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"orgTrailBucket:other"}), eval.failedItems)
+}
+
+func TestCheckIntegrationOrgTrailWrongManagementAccount(t *testing.T) {
+	mockS3, _ := mockOrgTrailClients("999999999999")
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	input := orgTrailCheckInput("trail")
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.False(t, *result.OrgTrailStatus.Healthy)
+	assert.Equal(t, "organization "+testOrganizationID+" is managed by 999999999999, not "+testManagementAccountID,
+		*result.OrgTrailStatus.ErrorMessage)
+	// The buckets can't be checked without the organization
+	assert.Nil(t, result.OrgTrailBucketsStatus)
+
+	eval, err := evaluateIntegrationHealth(apiTest, input)
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"orgTrail:" + testManagementAccountID}), eval.failedItems)
+}
+
+func TestCheckIntegrationOrgTrailNotAMember(t *testing.T) {
+	_, mockOrganizations := mockOrgTrailClients(testManagementAccountID)
+	mockOrganizations.ExpectedCalls = nil
+	mockOrganizations.On("DescribeOrganization", mock.Anything).Return(
+		&organizations.DescribeOrganizationOutput{}, errors.New("AWSOrganizationsNotInUseException"))
+
+	result, err := apiTest.CheckIntegration(orgTrailCheckInput())
+	require.NoError(t, err)
+	assert.False(t, *result.OrgTrailStatus.Healthy)
+	assert.Equal(t, "AWSOrganizationsNotInUseException", *result.OrgTrailStatus.ErrorMessage)
+}
+
+func TestCheckIntegrationNotOrgTrail(t *testing.T) {
+	_, mockOrganizations := mockOrgTrailClients(testManagementAccountID)
+	input := orgTrailCheckInput()
+	input.IsOrgTrail = nil
+
+	result, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.Nil(t, result.OrgTrailStatus.Healthy)
+	mockOrganizations.AssertNotCalled(t, "DescribeOrganization", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsOrgTrailWithoutManagementAccount(t *testing.T) {
+	mockLogIntegration([]string{"trail"}, nil)
+	evaluateIntegrationFunc = func(API, *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		t.Fatal("the health check should not run")
+		return false, nil, nil
+	}
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		IsOrgTrail:    aws.Bool(true),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestUpdateIntegrationSettingsOrgTrailHealthCheck(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationType:     aws.String(models.IntegrationTypeAWS3),
+		AWSAccountID:        aws.String(testAccountID),
+		S3Buckets:           aws.StringSlice([]string{"trail"}),
+		IsOrgTrail:          aws.Bool(true),
+		ManagementAccountID: aws.String(testManagementAccountID),
+	}).On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	var checked *models.CheckIntegrationInput
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = input
+		return true, nil, nil
+	}
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		IntegrationLabel: aws.String("trail"),
+	})
+	require.NoError(t, err)
+	// The stored org trail settings are checked again
+	assert.True(t, *checked.IsOrgTrail)
+	assert.Equal(t, testManagementAccountID, *checked.ManagementAccountID)
+}
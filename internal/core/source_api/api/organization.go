@@ -0,0 +1,271 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// organizationHealthChecker checks the management accounts of the aws-organization integrations.
+type organizationHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration: the organization role must describe the organization
+// managed by the account.
+func (organizationHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	status, err := api.CheckIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+	return &integrationEvaluation{
+		rolesHealthy: aws.BoolValue(status.OrganizationRoleStatus.Healthy) && aws.BoolValue(status.OrganizationStatus.Healthy),
+		failedItems:  make([]*string, 0),
+		health:       status,
+	}, nil
+}
+
+// checkOrganizationSettings rejects excluded accounts on an integration which is not an aws-organization one.
+func checkOrganizationSettings(integrationType *string, excludedAccounts []*string) error {
+	if len(excludedAccounts) > 0 && aws.StringValue(integrationType) != models.IntegrationTypeAWSOrganization {
+		return &genericapi.InvalidInputError{Message: "only aws-organization integrations have excluded accounts"}
+	}
+	return nil
+}
+
+// SyncOrganizations onboards the new member accounts of the aws-organization integrations as aws-scan integrations,
+// and deletes the ones it onboarded for the accounts which left their organization or were excluded since.
+//
+// The accounts with an aws-scan integration already are left alone, and so are the aws-scan integrations which
+// were not onboarded by an organization. A new integration fails its health check until the StackSet deployed the
+// audit role to its account, it's retried by the next sync. The integrations onboarded by an aws-organization
+// integration which was deleted are deleted when every organization is synced.
+func (api API) SyncOrganizations(input *models.SyncOrganizationsInput) ([]*models.OrganizationSyncResult, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		zap.L().Error("failed to scan the integrations", zap.Error(err))
+		return nil, &genericapi.InternalError{Message: "failed to sync the organizations"}
+	}
+
+	var synced []*models.SourceIntegrationMetadata
+	onboarded := make(map[string]bool)
+	members := make(map[string][]*models.SourceIntegrationMetadata)
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil {
+			continue
+		}
+		switch aws.StringValue(integration.IntegrationType) {
+		case models.IntegrationTypeAWSOrganization:
+			if input.IntegrationID == nil || *input.IntegrationID == *integration.IntegrationID {
+				synced = append(synced, integration.SourceIntegrationMetadata)
+			}
+		case models.IntegrationTypeAWSScan:
+			onboarded[*integration.AWSAccountID] = true
+			if parentID := aws.StringValue(integration.OrganizationIntegrationID); parentID != "" {
+				members[parentID] = append(members[parentID], integration.SourceIntegrationMetadata)
+			}
+		}
+	}
+	if input.IntegrationID != nil && len(synced) == 0 {
+		return nil, &genericapi.DoesNotExistError{Message: "aws-organization integration does not exist"}
+	}
+
+	dryRun := aws.BoolValue(input.DryRun)
+	results := make([]*models.OrganizationSyncResult, 0, len(synced))
+	for _, organization := range synced {
+		results = append(results, api.syncOrganization(organization, members[*organization.IntegrationID], onboarded, dryRun))
+		delete(members, *organization.IntegrationID)
+	}
+	if input.IntegrationID != nil {
+		return results, nil
+	}
+
+	// The remaining integrations were onboarded by organizations which no longer exist
+	orphaned := make([]string, 0, len(members))
+	for organizationID := range members {
+		orphaned = append(orphaned, organizationID)
+	}
+	sort.Strings(orphaned)
+	for _, organizationID := range orphaned {
+		result := &models.OrganizationSyncResult{
+			IntegrationID: aws.String(organizationID),
+			Results:       make([]*models.AccountManifestResult, 0, len(members[organizationID])),
+		}
+		for _, member := range members[organizationID] {
+			result.Results = append(result.Results, runOrganizationStep(api, memberDeletionStep(member), member.CreatedBy, dryRun))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// syncOrganization reconciles the aws-scan integrations of the member accounts of an organization.
+//
+// The failure of an account doesn't stop the others, it's reported in its result. The accounts are onboarded
+// and deleted on behalf of the user who created the aws-organization integration.
+func (api API) syncOrganization(organization *models.SourceIntegrationMetadata, members []*models.SourceIntegrationMetadata,
+	onboarded map[string]bool, dryRun bool) *models.OrganizationSyncResult {
+
+	result := &models.OrganizationSyncResult{
+		IntegrationID: organization.IntegrationID,
+		Results:       make([]*models.AccountManifestResult, 0),
+	}
+	organizationID, accounts, err := listOrganizationAccounts(organization.AWSAccountID)
+	if err != nil {
+		zap.L().Warn("failed to list the accounts of the organization",
+			zap.String("integrationId", *organization.IntegrationID), zap.Error(err))
+		result.ErrorMessage = aws.String(err.Error())
+		return result
+	}
+	result.OrganizationID = organizationID
+
+	// The management account is never onboarded, the StackSet doesn't deploy the audit role to it
+	excluded := map[string]bool{*organization.AWSAccountID: true}
+	for _, accountID := range organization.OrganizationExcludedAccounts {
+		excluded[*accountID] = true
+	}
+
+	active := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		if excluded[*account.Id] {
+			continue
+		}
+		active[*account.Id] = true
+		if onboarded[*account.Id] {
+			continue
+		}
+		step := memberCreationStep(organization, account)
+		result.Results = append(result.Results, runOrganizationStep(api, step, organization.CreatedBy, dryRun))
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return *members[i].AWSAccountID < *members[j].AWSAccountID
+	})
+	for _, member := range members {
+		if active[*member.AWSAccountID] {
+			continue
+		}
+		result.Results = append(result.Results, runOrganizationStep(api, memberDeletionStep(member), organization.CreatedBy, dryRun))
+	}
+	return result
+}
+
+// listOrganizationAccounts returns the ID of the organization managed by the account, and its active accounts
+// sorted by ID.
+func listOrganizationAccounts(managementAccountID *string) (*string, []*organizations.Account, error) {
+	roleCredentials, status := getCredentialsWithStatus(zap.L(), aws.String(fmt.Sprintf(organizationRoleFormat, *managementAccountID)))
+	if !aws.BoolValue(status.Healthy) {
+		return nil, nil, errors.New(aws.StringValue(status.ErrorMessage))
+	}
+	client := organizationsClientFunc(roleCredentials)
+
+	output, err := client.DescribeOrganization(&organizations.DescribeOrganizationInput{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if aws.StringValue(output.Organization.MasterAccountId) != *managementAccountID {
+		return nil, nil, fmt.Errorf("organization %s is managed by %s, not %s", aws.StringValue(output.Organization.Id),
+			aws.StringValue(output.Organization.MasterAccountId), *managementAccountID)
+	}
+
+	var accounts []*organizations.Account
+	err = client.ListAccountsPages(&organizations.ListAccountsInput{},
+		func(page *organizations.ListAccountsOutput, _ bool) bool {
+			for _, account := range page.Accounts {
+				// The suspended accounts are closing, they are offboarded like the ones which left
+				if aws.StringValue(account.Status) == organizations.AccountStatusActive {
+					accounts = append(accounts, account)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return *accounts[i].Id < *accounts[j].Id
+	})
+	return output.Organization.Id, accounts, nil
+}
+
+// memberCreationStep is the creation of the aws-scan integration of a member account.
+//
+// It's scanned with the scan settings of the organization, and set up for CWE and remediation like it.
+func memberCreationStep(organization *models.SourceIntegrationMetadata, account *organizations.Account) *manifestStep {
+	label := aws.StringValue(account.Name)
+	if label == "" {
+		label = *account.Id
+	}
+	settings := &models.PutIntegrationSettings{
+		AWSAccountID:       account.Id,
+		IntegrationLabel:   aws.String(label),
+		IntegrationType:    aws.String(models.IntegrationTypeAWSScan),
+		ScanEnabled:        organization.ScanEnabled,
+		ScanIntervalMins:   organization.ScanIntervalMins,
+		CWEEnabled:         organization.CWEEnabled,
+		RemediationEnabled: organization.RemediationEnabled,
+		UserID:             organization.CreatedBy,
+
+		OrganizationIntegrationID: organization.IntegrationID,
+	}
+	return &manifestStep{
+		settings: settings,
+		result: &models.AccountManifestResult{
+			IntegrationLabel: settings.IntegrationLabel,
+			IntegrationType:  settings.IntegrationType,
+			Action:           aws.String(models.ManifestActionCreate),
+			Changes:          creationChanges(generateNewIntegration(settings)),
+		},
+	}
+}
+
+// memberDeletionStep is the deletion of the aws-scan integration of an account which is no longer onboarded.
+func memberDeletionStep(member *models.SourceIntegrationMetadata) *manifestStep {
+	return &manifestStep{
+		existing: member,
+		result: &models.AccountManifestResult{
+			IntegrationID:    member.IntegrationID,
+			IntegrationLabel: member.IntegrationLabel,
+			IntegrationType:  member.IntegrationType,
+			Action:           aws.String(models.ManifestActionDelete),
+			Changes:          make([]*models.IntegrationFieldChange, 0),
+		},
+	}
+}
+
+// runOrganizationStep checks the step in a dry run, otherwise applies it, and records its outcome in its result.
+func runOrganizationStep(api API, step *manifestStep, userID *string, dryRun bool) *models.AccountManifestResult {
+	var err error
+	if dryRun {
+		err = step.check(api)
+	} else {
+		err = step.apply(api, userID)
+	}
+	step.result.Success = aws.Bool(err == nil)
+	if err != nil {
+		step.result.ErrorMessage = aws.String(err.Error())
+	}
+	return step.result
+}
@@ -0,0 +1,278 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testOrganizationIntegrationID = "3f0c2bde-8a51-4c1f-9b6e-2f4d8e7a9c10"
+	testMemberIntegrationID       = "7d5e1a2b-9c3f-4e6d-8a1b-0c2d3e4f5a6b"
+	testManualIntegrationID       = "b1c2d3e4-f5a6-4b7c-8d9e-0f1a2b3c4d5e"
+)
+
+func (client *mockOrganizationsClient) ListAccountsPages(
+	input *organizations.ListAccountsInput, fn func(*organizations.ListAccountsOutput, bool) bool) error {
+
+	args := client.Called(input)
+	if output := args.Get(0); output != nil {
+		fn(output.(*organizations.ListAccountsOutput), true)
+	}
+	return args.Error(1)
+}
+
+// mockOrganizationAccounts lists the management account, a new account, an excluded one, an account onboarded
+// manually and a suspended one.
+func mockOrganizationAccounts(managementAccountID string) *mockOrganizationsClient {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+	mockOrganizations := &mockOrganizationsClient{}
+	mockOrganizations.On("DescribeOrganization", mock.Anything).Return(&organizations.DescribeOrganizationOutput{
+		Organization: &organizations.Organization{
+			Id:              aws.String(testOrganizationID),
+			MasterAccountId: aws.String(managementAccountID),
+		},
+	}, nil)
+	mockOrganizations.On("ListAccountsPages", mock.Anything).Return(&organizations.ListAccountsOutput{
+		Accounts: []*organizations.Account{
+			{Id: aws.String(testManagementAccountID), Name: aws.String("management"), Status: aws.String("ACTIVE")},
+			{Id: aws.String("111111111111"), Name: aws.String("prod"), Status: aws.String("ACTIVE")},
+			{Id: aws.String("333333333333"), Name: aws.String("sandbox"), Status: aws.String("ACTIVE")},
+			{Id: aws.String(testAccountID), Name: aws.String("security"), Status: aws.String("ACTIVE")},
+			{Id: aws.String("666666666666"), Name: aws.String("closing"), Status: aws.String("SUSPENDED")},
+		},
+	}, nil)
+	organizationsClientFunc = func(*credentials.Credentials) organizationsiface.OrganizationsAPI { return mockOrganizations }
+	return mockOrganizations
+}
+
+// mockOrganizationIntegrations stores an aws-organization integration excluding an account, the integration it
+// onboarded for an account which left since, and an integration onboarded manually.
+func mockOrganizationIntegrations(t *testing.T, stored ...*models.SourceIntegrationMetadata) *mockAccountDDBClient {
+	if len(stored) == 0 {
+		stored = []*models.SourceIntegrationMetadata{
+			{
+				AWSAccountID:                 aws.String(testManagementAccountID),
+				CreatedBy:                    aws.String(testUserID),
+				IntegrationID:                aws.String(testOrganizationIntegrationID),
+				IntegrationLabel:             aws.String("organization"),
+				IntegrationType:              aws.String(models.IntegrationTypeAWSOrganization),
+				ScanEnabled:                  aws.Bool(true),
+				ScanIntervalMins:             aws.Int(720),
+				CWEEnabled:                   aws.Bool(true),
+				OrganizationExcludedAccounts: aws.StringSlice([]string{"333333333333"}),
+			},
+			{
+				AWSAccountID:              aws.String("444444444444"),
+				IntegrationID:             aws.String(testMemberIntegrationID),
+				IntegrationLabel:          aws.String("departed"),
+				IntegrationType:           aws.String(models.IntegrationTypeAWSScan),
+				OrganizationIntegrationID: aws.String(testOrganizationIntegrationID),
+			},
+			{
+				AWSAccountID:     aws.String(testAccountID),
+				IntegrationID:    aws.String(testManualIntegrationID),
+				IntegrationLabel: aws.String("security"),
+				IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			},
+		}
+	}
+
+	mockClient := &mockAccountDDBClient{&mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}}
+	for _, integration := range stored {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		mockClient.MockScanAttributes = append(mockClient.MockScanAttributes, item)
+		mockClient.On("GetItem", getItemFor(*integration.IntegrationID)).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+	}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	return mockClient
+}
+
+func TestSyncOrganizations(t *testing.T) {
+	mockClient := mockOrganizationIntegrations(t)
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	mockOrganizationAccounts(testManagementAccountID)
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	results, err := apiTest.SyncOrganizations(&models.SyncOrganizationsInput{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, testOrganizationIntegrationID, *results[0].IntegrationID)
+	assert.Equal(t, testOrganizationID, *results[0].OrganizationID)
+	assert.Nil(t, results[0].ErrorMessage)
+
+	// The management, excluded, manually onboarded and suspended accounts are left alone
+	require.Len(t, results[0].Results, 2)
+	create, deletion := results[0].Results[0], results[0].Results[1]
+	assert.Equal(t, models.ManifestActionCreate, *create.Action)
+	assert.Equal(t, "prod", *create.IntegrationLabel)
+	assert.True(t, *create.Success)
+	assert.Equal(t, models.ManifestActionDelete, *deletion.Action)
+	assert.Equal(t, testMemberIntegrationID, *deletion.IntegrationID)
+	assert.True(t, *deletion.Success)
+
+	require.Len(t, mockClient.written, 1)
+	var created models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &created))
+	assert.Equal(t, "111111111111", *created.AWSAccountID)
+	assert.Equal(t, models.IntegrationTypeAWSScan, *created.IntegrationType)
+	assert.Equal(t, testOrganizationIntegrationID, *created.OrganizationIntegrationID)
+	assert.Equal(t, testUserID, *created.CreatedBy)
+	// The scan and CWE settings are those of the organization
+	assert.Equal(t, 720, *created.ScanIntervalMins)
+	assert.True(t, *created.CWEEnabled)
+	assert.Equal(t, *created.IntegrationID, *create.IntegrationID)
+	mockSQS.AssertCalled(t, "SendMessageBatch", mock.Anything)
+
+	mockClient.AssertCalled(t, "DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return *input.Key["integrationId"].S == testMemberIntegrationID
+	}))
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return *input.Key["integrationId"].S == testManualIntegrationID
+	}))
+}
+
+func TestSyncOrganizationsDryRun(t *testing.T) {
+	mockClient := mockOrganizationIntegrations(t)
+	mockOrganizationAccounts(testManagementAccountID)
+	// The StackSet didn't deploy the audit role to the new account yet
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return false, nil, nil }
+
+	results, err := apiTest.SyncOrganizations(&models.SyncOrganizationsInput{
+		IntegrationID: aws.String(testOrganizationIntegrationID),
+		DryRun:        aws.Bool(true),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Results, 2)
+	assert.False(t, *results[0].Results[0].Success)
+	assert.Contains(t, *results[0].Results[0].ErrorMessage, "did not pass health check")
+	assert.True(t, *results[0].Results[1].Success)
+
+	// Nothing was written
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.Anything)
+	assert.Empty(t, mockClient.written)
+}
+
+func TestSyncOrganizationsNotManagementAccount(t *testing.T) {
+	mockClient := mockOrganizationIntegrations(t)
+	mockOrganizationAccounts("999999999999")
+
+	results, err := apiTest.SyncOrganizations(&models.SyncOrganizationsInput{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, *results[0].ErrorMessage, "managed by 999999999999")
+	assert.Empty(t, results[0].Results)
+
+	// The members are kept until the organization can be listed again
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.Anything)
+	assert.Empty(t, mockClient.written)
+}
+
+func TestSyncOrganizationsListFailure(t *testing.T) {
+	mockOrganizationIntegrations(t)
+	mockOrganizations := mockOrganizationAccounts(testManagementAccountID)
+	mockOrganizations.ExpectedCalls = mockOrganizations.ExpectedCalls[:1]
+	mockOrganizations.On("ListAccountsPages", mock.Anything).Return(nil, errors.New("AccessDeniedException"))
+
+	results, err := apiTest.SyncOrganizations(&models.SyncOrganizationsInput{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "AccessDeniedException", *results[0].ErrorMessage)
+}
+
+func TestSyncOrganizationsOrphanedMembers(t *testing.T) {
+	mockClient := mockOrganizationIntegrations(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:              aws.String("444444444444"),
+		IntegrationID:             aws.String(testMemberIntegrationID),
+		IntegrationLabel:          aws.String("orphan"),
+		IntegrationType:           aws.String(models.IntegrationTypeAWSScan),
+		OrganizationIntegrationID: aws.String(testOrganizationIntegrationID),
+	})
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	results, err := apiTest.SyncOrganizations(&models.SyncOrganizationsInput{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, testOrganizationIntegrationID, *results[0].IntegrationID)
+	require.Len(t, results[0].Results, 1)
+	assert.Equal(t, models.ManifestActionDelete, *results[0].Results[0].Action)
+	assert.True(t, *results[0].Results[0].Success)
+	mockClient.AssertCalled(t, "DeleteItem", mock.Anything)
+}
+
+func TestSyncOrganizationsDoesNotExist(t *testing.T) {
+	mockOrganizationIntegrations(t)
+
+	_, err := apiTest.SyncOrganizations(&models.SyncOrganizationsInput{IntegrationID: aws.String(testManualIntegrationID)})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestCheckIntegrationOrganization(t *testing.T) {
+	mockOrganizationAccounts(testManagementAccountID)
+	input := &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testManagementAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSOrganization),
+	}
+
+	health, err := apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.True(t, *health.OrganizationRoleStatus.Healthy)
+	assert.True(t, *health.OrganizationStatus.Healthy)
+
+	// A member account can't list the accounts of its organization
+	input.AWSAccountID = aws.String(testAccountID)
+	health, err = apiTest.CheckIntegration(input)
+	require.NoError(t, err)
+	assert.False(t, *health.OrganizationStatus.Healthy)
+	assert.Contains(t, *health.OrganizationStatus.ErrorMessage, "is managed by "+testManagementAccountID)
+}
+
+func TestCheckOrganizationSettings(t *testing.T) {
+	excluded := aws.StringSlice([]string{"333333333333"})
+	assert.NoError(t, checkOrganizationSettings(aws.String(models.IntegrationTypeAWSOrganization), excluded))
+	assert.NoError(t, checkOrganizationSettings(aws.String(models.IntegrationTypeAWSScan), nil))
+	assert.IsType(t, &genericapi.InvalidInputError{},
+		checkOrganizationSettings(aws.String(models.IntegrationTypeAWSScan), excluded))
+}
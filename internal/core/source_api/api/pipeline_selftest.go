@@ -0,0 +1,364 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	logmodels "github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The synthetic log is an osquery status log, a small format the classifier can't mistake for another one
+	selfTestLogType   = "Osquery.Status"
+	selfTestKeyPrefix = "panther-self-test/"
+
+	defaultSelfTestTimeout = 5 * time.Minute
+
+	// The timestamp in the keys of the objects written by the log processor, they are sorted by time
+	processedObjectTimestampFormat = "20060102T150405Z"
+
+	selfTestStageUpload    = "uploadObject"
+	selfTestStageRead      = "readObject"
+	selfTestStageProcessed = "processedData"
+)
+
+// pipelineSelfTest is a synthetic log going through the pipeline of a log analysis integration.
+type pipelineSelfTest struct {
+	id       string
+	s3Client s3iface.S3API
+	bucket   string
+	key      string
+	body     []byte
+
+	uploadedAt time.Time
+}
+
+// RunPipelineSelfTest drops a synthetic log into a bucket of an integration, its processing is polled with
+// GetPipelineSelfTest.
+//
+// The log is uploaded with the log processing role, which the onboarding template grants s3:PutObject on the
+// panther-self-test/ prefix of the buckets. The log is then read back like the log processor does. Once a stage
+// fails, the following ones are skipped and the self test is completed. Otherwise the processed data stage is
+// pending until the log shows up or the timeout expires. The self test is kept in the history of the integration.
+// The role can't delete objects so the synthetic object is left in place.
+func (API) RunPipelineSelfTest(input *models.RunPipelineSelfTestInput) (*models.PipelineSelfTestResult, error) {
+	if db.HistoryTableName == "" {
+		return nil, &genericapi.InternalError{Message: "the self test needs the history table, which the deployment doesn't have"}
+	}
+	integration, err := db.GetIntegration(input.IntegrationID, false)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWS3 {
+		return nil, &genericapi.InvalidInputError{Message: "the self test is only available for log analysis integrations"}
+	}
+	if len(integration.S3Buckets) == 0 {
+		return nil, &genericapi.InvalidInputError{Message: "the integration has no S3 bucket to upload the synthetic log to"}
+	}
+
+	timeout := defaultSelfTestTimeout
+	if input.TimeoutSeconds != nil {
+		timeout = time.Duration(*input.TimeoutSeconds) * time.Second
+	}
+
+	test := newPipelineSelfTest(integration)
+	zap.L().Info("running pipeline self test",
+		zap.String("testId", test.id), zap.String("bucket", test.bucket), zap.String("key", test.key))
+
+	stages := []struct {
+		name string
+		run  func() error
+	}{
+		{selfTestStageUpload, test.upload},
+		{selfTestStageRead, test.read},
+	}
+
+	result := &models.PipelineSelfTestResult{
+		TestID:    aws.String(test.id),
+		Completed: aws.Bool(false),
+		Passed:    aws.Bool(false),
+		Stages:    make([]*models.PipelineStageStatus, 0, len(stages)+1),
+	}
+	for _, stage := range stages {
+		status := &models.PipelineStageStatus{Stage: aws.String(stage.name)}
+		result.Stages = append(result.Stages, status)
+		if result.StalledStage != nil {
+			status.Status = aws.String(models.PipelineStageSkipped)
+			continue
+		}
+
+		start := time.Now()
+		err := stage.run()
+		status.LatencyMillis = millisSince(start)
+		if err == nil {
+			status.Status = aws.String(models.PipelineStagePassed)
+			continue
+		}
+		status.Status = aws.String(models.PipelineStageFailed)
+		status.ErrorMessage = aws.String(err.Error())
+		result.StalledStage = status.Stage
+		result.Completed = aws.Bool(true)
+	}
+
+	processed := &models.PipelineStageStatus{
+		Stage:  aws.String(selfTestStageProcessed),
+		Status: aws.String(models.PipelineStagePending),
+	}
+	if *result.Completed {
+		processed.Status = aws.String(models.PipelineStageSkipped)
+	}
+	result.Stages = append(result.Stages, processed)
+	result.StartedAt = aws.Time(test.uploadedAt)
+	result.Deadline = aws.Time(test.uploadedAt.Add(timeout))
+
+	now := time.Now().UTC()
+	err = db.PutHistoryRecord(&models.IntegrationHistoryRecord{
+		IntegrationID: integration.IntegrationID,
+		RecordID:      historyRecordID(now),
+		Kind:          aws.String(models.HistoryKindSelfTest),
+		RecordedAt:    aws.Time(now),
+		SelfTest:      result,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPipelineSelfTest returns the progress of a self test, it checks the processed data for the synthetic log
+// while the self test is pending.
+//
+// The self test is completed once the log shows up, the processed data can't be listed or the timeout expires.
+// A completed self test is returned as it was recorded.
+func (API) GetPipelineSelfTest(input *models.GetPipelineSelfTestInput) (*models.PipelineSelfTestResult, error) {
+	if db.HistoryTableName == "" {
+		return nil, &genericapi.InternalError{Message: "the self test needs the history table, which the deployment doesn't have"}
+	}
+	record, err := findSelfTestRecord(input.IntegrationID, input.TestID)
+	if err != nil {
+		return nil, err
+	}
+	result := record.SelfTest
+	if aws.BoolValue(result.Completed) {
+		return result, nil
+	}
+
+	var processed *models.PipelineStageStatus
+	for _, stage := range result.Stages {
+		if aws.StringValue(stage.Stage) == selfTestStageProcessed {
+			processed = stage
+		}
+	}
+	if processed == nil {
+		return nil, &genericapi.InternalError{Message: "the self test has no processed data stage"}
+	}
+
+	test := &pipelineSelfTest{id: *result.TestID, uploadedAt: aws.TimeValue(result.StartedAt)}
+	found, err := test.findProcessedData(make(map[string]struct{}))
+	switch {
+	case err != nil:
+		processed.Status = aws.String(models.PipelineStageFailed)
+		processed.ErrorMessage = aws.String(err.Error())
+	case found:
+		processed.Status = aws.String(models.PipelineStagePassed)
+		result.Passed = aws.Bool(true)
+	case time.Now().After(aws.TimeValue(result.Deadline)):
+		processed.Status = aws.String(models.PipelineStageTimedOut)
+		processed.ErrorMessage = aws.String(fmt.Sprintf(
+			"the synthetic log wasn't processed after %s, check the notifications of the bucket reach Panther",
+			aws.TimeValue(result.Deadline).Sub(test.uploadedAt).Round(time.Second)))
+	default:
+		return result, nil
+	}
+
+	processed.LatencyMillis = millisSince(test.uploadedAt)
+	if !*result.Passed {
+		result.StalledStage = processed.Stage
+	}
+	result.Completed = aws.Bool(true)
+	if err := db.PutHistoryRecord(record); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// findSelfTestRecord returns the history record of a self test of an integration.
+func findSelfTestRecord(integrationID, testID *string) (*models.IntegrationHistoryRecord, error) {
+	var exclusiveStartKey *string
+	for {
+		page, lastRecordID, err := db.ListHistory(integrationID, aws.String(models.HistoryKindSelfTest),
+			compactionPageSize, exclusiveStartKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range page {
+			if record.SelfTest != nil && aws.StringValue(record.SelfTest.TestID) == *testID {
+				return record, nil
+			}
+		}
+		if lastRecordID == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "Self test does not exist"}
+		}
+		exclusiveStartKey = lastRecordID
+	}
+}
+
+// newPipelineSelfTest prepares a synthetic log for the first bucket of the integration.
+func newPipelineSelfTest(integration *models.SourceIntegrationMetadata) *pipelineSelfTest {
+	roleCredentials := stscreds.NewCredentials(sess, fmt.Sprintf(logProcessingRoleFormat, *integration.AWSAccountID))
+	bucket, pattern := parseBucketEntry(*integration.S3Buckets[0])
+
+	test := &pipelineSelfTest{
+		id:       uuid.New().String(),
+		s3Client: s3ClientFunc(roleCredentials),
+		bucket:   bucket,
+	}
+	if region := aws.StringValue(integration.S3BucketRegions[bucket]); region != "" {
+		test.s3Client = regionalS3ClientFunc(roleCredentials, region)
+	}
+	// Stay within the prefix of the bucket entry, which the bucket notifications are likely limited to
+	test.key = patternPrefix(pattern) + selfTestKeyPrefix + test.id + ".json"
+
+	now := time.Now().UTC()
+	// This can't fail, the map only has strings
+	test.body, _ = json.Marshal(map[string]string{
+		"hostIdentifier": "panther-self-test",
+		"calendarTime":   now.Format("Mon Jan 2 15:04:05 2006 MST"),
+		"unixTime":       strconv.FormatInt(now.Unix(), 10),
+		"severity":       "0",
+		"filename":       "panther_self_test",
+		"line":           "1",
+		"message":        "Panther pipeline self test " + test.id,
+		"version":        "4.0.0",
+		"log_type":       "status",
+	})
+	return test
+}
+
+// upload puts the synthetic log in the bucket, the notifications of the bucket deliver it to the log processor.
+func (test *pipelineSelfTest) upload() error {
+	test.uploadedAt = time.Now().UTC()
+	_, err := test.s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(test.bucket),
+		Key:         aws.String(test.key),
+		Body:        bytes.NewReader(test.body),
+		ContentType: aws.String("application/json"),
+	})
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "AccessDenied" {
+		return fmt.Errorf("the log processing role can't upload the synthetic log, update the onboarding stack "+
+			"so it grants s3:PutObject on arn:aws:s3:::%s/%s*: %s",
+			test.bucket, strings.TrimSuffix(test.key, test.id+".json"), awsErr.Message())
+	}
+	return err
+}
+
+// read gets the synthetic log back with the log processing role, as the log processor does.
+func (test *pipelineSelfTest) read() error {
+	output, err := test.s3Client.GetObject(&s3.GetObjectInput{Bucket: aws.String(test.bucket), Key: aws.String(test.key)})
+	if err != nil {
+		return err
+	}
+	defer output.Body.Close()
+
+	body, err := ioutil.ReadAll(output.Body)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(body, test.body) {
+		return fmt.Errorf("s3://%s/%s doesn't hold the synthetic log", test.bucket, test.key)
+	}
+	return nil
+}
+
+// findProcessedData looks for the synthetic log in the processed objects written since it was uploaded.
+//
+// The processed objects are partitioned by the hour they were written in, every hour since the upload is listed.
+// Objects which were already looked at are added to checkedKeys.
+func (test *pipelineSelfTest) findProcessedData(checkedKeys map[string]struct{}) (bool, error) {
+	for hour := test.uploadedAt.Truncate(time.Hour); !hour.After(time.Now()); hour = hour.Add(time.Hour) {
+		prefix := awsglue.GetPartitionPrefix(logmodels.LogData, selfTestLogType, awsglue.GlueTableHourly, hour)
+		input := &s3.ListObjectsV2Input{
+			Bucket:     aws.String(processedDataBucket),
+			Prefix:     aws.String(prefix),
+			StartAfter: aws.String(prefix + test.uploadedAt.Add(-time.Second).Format(processedObjectTimestampFormat)),
+		}
+		for {
+			page, err := processedDataClient.ListObjectsV2(input)
+			if err != nil {
+				return false, err
+			}
+			for _, object := range page.Contents {
+				if _, ok := checkedKeys[*object.Key]; ok {
+					continue
+				}
+				checkedKeys[*object.Key] = struct{}{}
+				found, err := test.processedObjectHasLog(object.Key)
+				if err != nil || found {
+					return found, err
+				}
+			}
+			if !aws.BoolValue(page.IsTruncated) {
+				break
+			}
+			input.ContinuationToken = page.NextContinuationToken
+		}
+	}
+	return false, nil
+}
+
+// processedObjectHasLog returns true if the gzipped processed object holds the synthetic log.
+func (test *pipelineSelfTest) processedObjectHasLog(key *string) (bool, error) {
+	output, err := processedDataClient.GetObject(&s3.GetObjectInput{Bucket: aws.String(processedDataBucket), Key: key})
+	if err != nil {
+		return false, err
+	}
+	defer output.Body.Close()
+
+	reader, err := gzip.NewReader(output.Body)
+	if err != nil {
+		return false, err
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Contains(body, []byte(test.id)), nil
+}
@@ -0,0 +1,274 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func (client *mockS3Client) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+func (client *mockS3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
+}
+
+// mockSelfTestClients returns the client of the log processing role, which stores the uploaded synthetic log,
+// the client of the processed data and the history table the self tests are kept in.
+func mockSelfTestClients(t *testing.T, buckets []string) (roleClient, processedClient *mockS3Client,
+	uploaded *[]byte, history *mockHistoryTableClient) {
+
+	mockLogIntegration(buckets, nil)
+	history = &mockHistoryTableClient{
+		MockDDBClient: db.Client.(*modelstest.MockDDBClient),
+		records:       make(map[string]*models.IntegrationHistoryRecord),
+	}
+	db = &ddb.DDB{Client: history, TableName: "test", HistoryTableName: "history"}
+
+	roleClient, processedClient, uploaded = &mockS3Client{}, &mockS3Client{}, new([]byte)
+	s3ClientFunc = func(*credentials.Credentials) s3iface.S3API { return roleClient }
+	processedDataClient = processedClient
+	processedDataBucket = "processed"
+	t.Cleanup(func() { processedDataBucket = "" })
+
+	roleClient.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Run(func(args mock.Arguments) {
+		body, err := ioutil.ReadAll(args.Get(0).(*s3.PutObjectInput).Body)
+		require.NoError(t, err)
+		*uploaded = body
+	})
+	return roleClient, processedClient, uploaded, history
+}
+
+// startSelfTest starts a self test whose synthetic log is read back as it was uploaded.
+func startSelfTest(t *testing.T, input *models.RunPipelineSelfTestInput) (
+	result *models.PipelineSelfTestResult, processedClient *mockS3Client, uploaded *[]byte, history *mockHistoryTableClient) {
+
+	roleClient, processedClient, uploaded, history := mockSelfTestClients(t, []string{"bucket-1/logs/*.json"})
+	readBack, setReadBack := objectBody(func() []byte { return *uploaded })
+	roleClient.On("GetObject", mock.Anything).Return(readBack, nil).Run(setReadBack)
+
+	result, err := apiTest.RunPipelineSelfTest(input)
+	require.NoError(t, err)
+	return result, processedClient, uploaded, history
+}
+
+// objectBody returns a GetObject result whose body is set when it is returned.
+func objectBody(body func() []byte) (*s3.GetObjectOutput, func(mock.Arguments)) {
+	output := &s3.GetObjectOutput{}
+	return output, func(mock.Arguments) { output.Body = ioutil.NopCloser(bytes.NewReader(body())) }
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func TestRunPipelineSelfTest(t *testing.T) {
+	roleClient, _, uploaded, history := mockSelfTestClients(t, []string{"bucket-1/logs/*.json"})
+	readBack, setReadBack := objectBody(func() []byte { return *uploaded })
+	roleClient.On("GetObject", mock.Anything).Return(readBack, nil).Run(setReadBack)
+
+	result, err := apiTest.RunPipelineSelfTest(&models.RunPipelineSelfTestInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.False(t, *result.Completed)
+	assert.False(t, *result.Passed)
+	assert.Nil(t, result.StalledStage)
+	assert.Equal(t, defaultSelfTestTimeout, result.Deadline.Sub(*result.StartedAt))
+	require.Len(t, result.Stages, 3)
+	for i, stage := range []string{"uploadObject", "readObject"} {
+		assert.Equal(t, stage, *result.Stages[i].Stage)
+		assert.Equal(t, models.PipelineStagePassed, *result.Stages[i].Status)
+		assert.NotNil(t, result.Stages[i].LatencyMillis)
+	}
+	assert.Equal(t, "processedData", *result.Stages[2].Stage)
+	assert.Equal(t, models.PipelineStagePending, *result.Stages[2].Status)
+
+	// The synthetic log stays within the prefix of the bucket and identifies the test
+	upload := roleClient.Calls[0].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Equal(t, "bucket-1", *upload.Bucket)
+	assert.Equal(t, "logs/panther-self-test/"+*result.TestID+".json", *upload.Key)
+	assert.Contains(t, string(*uploaded), *result.TestID)
+	assert.Contains(t, string(*uploaded), `"log_type":"status"`)
+
+	// The self test is kept in the history of the integration
+	require.Len(t, history.records, 1)
+	for _, record := range history.records {
+		assert.Equal(t, models.HistoryKindSelfTest, *record.Kind)
+		assert.Equal(t, *result.TestID, *record.SelfTest.TestID)
+	}
+}
+
+func TestGetPipelineSelfTest(t *testing.T) {
+	started, processedClient, uploaded, history := startSelfTest(t,
+		&models.RunPipelineSelfTestInput{IntegrationID: aws.String(testIntegrationID)})
+
+	// The log shows up along with an unrelated object
+	partition := "logs/osquery_status/year="
+	processedClient.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+		{Key: aws.String(partition + "other.json.gz")},
+		{Key: aws.String(partition + "self-test.json.gz")},
+	}}, nil)
+	processedClient.On("GetObject", &s3.GetObjectInput{Bucket: aws.String("processed"), Key: aws.String(partition + "other.json.gz")}).
+		Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(gzipped(t, []byte(`{"message": "other"}`))))}, nil)
+	processed, setProcessed := objectBody(func() []byte { return gzipped(t, *uploaded) })
+	processedClient.On("GetObject", &s3.GetObjectInput{Bucket: aws.String("processed"), Key: aws.String(partition + "self-test.json.gz")}).
+		Return(processed, nil).Run(setProcessed)
+
+	input := &models.GetPipelineSelfTestInput{IntegrationID: aws.String(testIntegrationID), TestID: started.TestID}
+	result, err := apiTest.GetPipelineSelfTest(input)
+	require.NoError(t, err)
+	assert.True(t, *result.Completed)
+	assert.True(t, *result.Passed)
+	assert.Nil(t, result.StalledStage)
+	assert.Equal(t, models.PipelineStagePassed, *result.Stages[2].Status)
+	assert.NotNil(t, result.Stages[2].LatencyMillis)
+
+	listing := processedClient.Calls[0].Arguments.Get(0).(*s3.ListObjectsV2Input)
+	assert.Equal(t, "processed", *listing.Bucket)
+	assert.True(t, strings.HasPrefix(*listing.Prefix, partition))
+	assert.True(t, strings.HasPrefix(*listing.StartAfter, *listing.Prefix))
+	processedClient.AssertExpectations(t)
+
+	// The outcome is recorded, the processed data isn't checked again
+	for _, record := range history.records {
+		assert.True(t, *record.SelfTest.Completed)
+	}
+	calls := len(processedClient.Calls)
+	result, err = apiTest.GetPipelineSelfTest(input)
+	require.NoError(t, err)
+	assert.True(t, *result.Passed)
+	assert.Len(t, processedClient.Calls, calls)
+}
+
+func TestGetPipelineSelfTestPending(t *testing.T) {
+	started, processedClient, _, history := startSelfTest(t,
+		&models.RunPipelineSelfTestInput{IntegrationID: aws.String(testIntegrationID)})
+	processedClient.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+
+	result, err := apiTest.GetPipelineSelfTest(&models.GetPipelineSelfTestInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TestID:        started.TestID,
+	})
+	require.NoError(t, err)
+	assert.False(t, *result.Completed)
+	assert.Equal(t, models.PipelineStagePending, *result.Stages[2].Status)
+	for _, record := range history.records {
+		assert.False(t, *record.SelfTest.Completed)
+	}
+}
+
+func TestRunPipelineSelfTestUploadDenied(t *testing.T) {
+	roleClient := &mockS3Client{}
+	_, processedClient, _, _ := mockSelfTestClients(t, []string{"bucket-1"})
+	s3ClientFunc = func(*credentials.Credentials) s3iface.S3API { return roleClient }
+	roleClient.On("PutObject", mock.Anything).
+		Return(&s3.PutObjectOutput{}, awserr.New("AccessDenied", "Access Denied", nil))
+
+	result, err := apiTest.RunPipelineSelfTest(&models.RunPipelineSelfTestInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.True(t, *result.Completed)
+	assert.False(t, *result.Passed)
+	assert.Equal(t, "uploadObject", *result.StalledStage)
+	assert.Equal(t, models.PipelineStageFailed, *result.Stages[0].Status)
+	assert.Contains(t, *result.Stages[0].ErrorMessage, "s3:PutObject on arn:aws:s3:::bucket-1/panther-self-test/*")
+	assert.Equal(t, models.PipelineStageSkipped, *result.Stages[1].Status)
+	assert.Equal(t, models.PipelineStageSkipped, *result.Stages[2].Status)
+	roleClient.AssertNotCalled(t, "GetObject", mock.Anything)
+
+	// A completed self test isn't checked again
+	result, err = apiTest.GetPipelineSelfTest(&models.GetPipelineSelfTestInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TestID:        result.TestID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "uploadObject", *result.StalledStage)
+	processedClient.AssertNotCalled(t, "ListObjectsV2", mock.Anything)
+}
+
+func TestGetPipelineSelfTestTimeout(t *testing.T) {
+	started, processedClient, _, history := startSelfTest(t, &models.RunPipelineSelfTestInput{
+		IntegrationID:  aws.String(testIntegrationID),
+		TimeoutSeconds: aws.Int(0),
+	})
+	processedClient.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+
+	result, err := apiTest.GetPipelineSelfTest(&models.GetPipelineSelfTestInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TestID:        started.TestID,
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.Completed)
+	assert.False(t, *result.Passed)
+	assert.Equal(t, "processedData", *result.StalledStage)
+	assert.Equal(t, models.PipelineStageTimedOut, *result.Stages[2].Status)
+	assert.Contains(t, *result.Stages[2].ErrorMessage, "wasn't processed")
+	// The processed data is checked one last time
+	processedClient.AssertCalled(t, "ListObjectsV2", mock.Anything)
+	for _, record := range history.records {
+		assert.Equal(t, models.PipelineStageTimedOut, *record.SelfTest.Stages[2].Status)
+	}
+}
+
+func TestGetPipelineSelfTestDoesNotExist(t *testing.T) {
+	mockSelfTestClients(t, []string{"bucket-1"})
+
+	result, err := apiTest.GetPipelineSelfTest(&models.GetPipelineSelfTestInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TestID:        aws.String(testIntegrationID),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestRunPipelineSelfTestCloudSecurity(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+	})
+	db.HistoryTableName = "history"
+
+	result, err := apiTest.RunPipelineSelfTest(&models.RunPipelineSelfTestInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
@@ -0,0 +1,139 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const (
+	// The keys sampled at the top level of a bucket, the prefixes of a larger sample are not inferred
+	prefixSampleSize = 1000
+	// A bucket with more top level prefixes is left as it is, listing them all would be unwieldy
+	maxInferredPrefixes = 20
+)
+
+// MigrateToPrefixConfig proposes prefixes for the buckets of every log analysis integration matching the filters,
+// and applies them unless it's a dry run.
+//
+// The prefixes are the top level "directories" of the keys of each bucket, sampled with the log processing role.
+// A proposal is applied like ReplaceBuckets, so the health check must pass with the prefixes. A failed integration
+// doesn't stop the others, the outcome is reported for each matching integration.
+func (api API) MigrateToPrefixConfig(input *models.MigrateToPrefixConfigInput) ([]*models.PrefixMigration, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]struct{}, len(input.IntegrationIDs))
+	for _, integrationID := range input.IntegrationIDs {
+		selected[*integrationID] = struct{}{}
+	}
+
+	results := make([]*models.PrefixMigration, 0)
+	for _, integration := range integrations {
+		metadata := integration.SourceIntegrationMetadata
+		if metadata == nil || aws.StringValue(metadata.IntegrationType) != models.IntegrationTypeAWS3 {
+			continue
+		}
+		if input.AWSAccountID != nil && aws.StringValue(metadata.AWSAccountID) != *input.AWSAccountID {
+			continue
+		}
+		if _, ok := selected[*metadata.IntegrationID]; len(selected) > 0 && !ok {
+			continue
+		}
+
+		result := &models.PrefixMigration{
+			IntegrationID:  metadata.IntegrationID,
+			CurrentBuckets: metadata.S3Buckets,
+			Applied:        aws.Bool(false),
+		}
+		results = append(results, result)
+		if result.ProposedBuckets, err = proposePrefixes(metadata); err != nil {
+			result.ProposedBuckets = metadata.S3Buckets
+			result.ErrorMessage = aws.String(err.Error())
+			continue
+		}
+		if aws.BoolValue(input.DryRun) || reflect.DeepEqual(result.ProposedBuckets, metadata.S3Buckets) {
+			continue
+		}
+
+		_, err = api.ReplaceBuckets(&models.ReplaceBucketsInput{
+			IntegrationID: metadata.IntegrationID,
+			S3Buckets:     result.ProposedBuckets,
+			UserID:        input.UserID,
+		})
+		if err != nil {
+			zap.L().Warn("failed to migrate integration to prefixes",
+				zap.String("integrationId", *metadata.IntegrationID), zap.Error(err))
+			result.ErrorMessage = aws.String(err.Error())
+			continue
+		}
+		result.Applied = aws.Bool(true)
+	}
+	return results, nil
+}
+
+// proposePrefixes replaces each bucket of the integration without a key pattern by an entry per top level prefix.
+func proposePrefixes(integration *models.SourceIntegrationMetadata) ([]*string, error) {
+	roleCredentials := stscreds.NewCredentials(sess, fmt.Sprintf(logProcessingRoleFormat, aws.StringValue(integration.AWSAccountID)))
+	proposed := make([]*string, 0, len(integration.S3Buckets))
+	for _, entry := range integration.S3Buckets {
+		if strings.Contains(*entry, "/") {
+			proposed = append(proposed, entry)
+			continue
+		}
+
+		var s3Client s3iface.S3API
+		if region, ok := integration.S3BucketRegions[*entry]; ok {
+			s3Client = regionalS3ClientFunc(roleCredentials, *region)
+		} else {
+			s3Client = s3ClientFunc(roleCredentials)
+		}
+		page, err := s3Client.ListObjectsV2(&s3.ListObjectsV2Input{
+			Bucket:    entry,
+			Delimiter: aws.String("/"),
+			MaxKeys:   aws.Int64(prefixSampleSize),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list bucket %s: %s", *entry, err)
+		}
+
+		// Objects at the top level would no longer be ingested under any prefix
+		if len(page.Contents) > 0 || len(page.CommonPrefixes) == 0 ||
+			len(page.CommonPrefixes) > maxInferredPrefixes || aws.BoolValue(page.IsTruncated) {
+
+			proposed = append(proposed, entry)
+			continue
+		}
+		for _, prefix := range page.CommonPrefixes {
+			proposed = append(proposed, aws.String(*entry+"/"+aws.StringValue(prefix.Prefix)))
+		}
+	}
+	return proposed, nil
+}
@@ -0,0 +1,134 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+// mockFlatBuckets stores a log analysis integration with flat buckets, and a cloud security integration.
+//
+// The "logs" bucket has two top level prefixes, "mixed" also has objects at the top level.
+func mockFlatBuckets(t *testing.T) (*modelstest.MockDDBClient, *mockS3Client) {
+	mockBucketOwnership(t)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	logIntegration := &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs", "mixed", "scoped/cloudtrail/"}),
+	}
+	logItem, err := dynamodbattribute.MarshalMap(logIntegration)
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+		logItem,
+		{
+			"awsAccountId":    {S: aws.String(testAccountID)},
+			"integrationId":   {S: aws.String("45c378a7-2e36-4b12-8e16-2d3c49ff1371")},
+			"integrationType": {S: aws.String(models.IntegrationTypeAWSScan)},
+		},
+	}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: logItem}, nil)
+
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{
+		Bucket: aws.String("logs"), Delimiter: aws.String("/"), MaxKeys: aws.Int64(prefixSampleSize),
+	}).Return(&s3.ListObjectsV2Output{CommonPrefixes: []*s3.CommonPrefix{
+		{Prefix: aws.String("AWSLogs/")}, {Prefix: aws.String("elb/")},
+	}}, nil)
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{
+		Bucket: aws.String("mixed"), Delimiter: aws.String("/"), MaxKeys: aws.Int64(prefixSampleSize),
+	}).Return(&s3.ListObjectsV2Output{
+		CommonPrefixes: []*s3.CommonPrefix{{Prefix: aws.String("vpc/")}},
+		Contents:       []*s3.Object{{Key: aws.String("top-level.json.gz")}},
+	}, nil)
+	mockHealthCheckClients(&mockSTSClient{}, mockS3, &mockKMSClient{})
+	return mockClient, mockS3
+}
+
+var proposedPrefixBuckets = aws.StringSlice([]string{"logs/AWSLogs/", "logs/elb/", "mixed", "scoped/cloudtrail/"})
+
+func TestMigrateToPrefixConfigDryRun(t *testing.T) {
+	mockClient, mockS3 := mockFlatBuckets(t)
+
+	results, err := apiTest.MigrateToPrefixConfig(&models.MigrateToPrefixConfigInput{DryRun: aws.Bool(true)})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.PrefixMigration{{
+		IntegrationID:   aws.String(testIntegrationID),
+		CurrentBuckets:  aws.StringSlice([]string{"logs", "mixed", "scoped/cloudtrail/"}),
+		ProposedBuckets: proposedPrefixBuckets,
+		Applied:         aws.Bool(false),
+	}}, results)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+	mockS3.AssertExpectations(t)
+}
+
+func TestMigrateToPrefixConfig(t *testing.T) {
+	mockClient, _ := mockFlatBuckets(t)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"integrationId": {S: aws.String(testIntegrationID)},
+	}}, nil).Run(func(args mock.Arguments) {
+		if update == nil {
+			update = args.Get(0).(*dynamodb.UpdateItemInput)
+		}
+	})
+
+	results, err := apiTest.MigrateToPrefixConfig(&models.MigrateToPrefixConfigInput{
+		IntegrationIDs: aws.StringSlice([]string{testIntegrationID}),
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, *results[0].Applied)
+	assert.Nil(t, results[0].ErrorMessage)
+
+	require.NotNil(t, update)
+	var written []*string
+	for _, value := range update.ExpressionAttributeValues {
+		if len(value.L) == len(proposedPrefixBuckets) && aws.StringValue(value.L[0].S) == "logs/AWSLogs/" {
+			require.NoError(t, dynamodbattribute.Unmarshal(value, &written))
+		}
+	}
+	assert.Equal(t, proposedPrefixBuckets, written)
+}
+
+func TestMigrateToPrefixConfigFilters(t *testing.T) {
+	mockFlatBuckets(t)
+
+	results, err := apiTest.MigrateToPrefixConfig(&models.MigrateToPrefixConfigInput{
+		AWSAccountID: aws.String("210987654321"),
+		DryRun:       aws.Bool(true),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
@@ -0,0 +1,297 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// Settings verified by the health check of an integration
+var healthCheckedFields = map[string]struct{}{
+	"integrationType":        {},
+	"cweEnabled":             {},
+	"remediationEnabled":     {},
+	"s3Buckets":              {},
+	"kmsKeys":                {},
+	"s3BucketRegions":        {},
+	"disabledChecks":         {},
+	"isOrgTrail":             {},
+	"managementAccountId":    {},
+	"streamArn":              {},
+	"roleChain":              {},
+	"deadLetterQueue":        {},
+	"archiveFormat":          {},
+	"sessionDurationSeconds": {},
+	"credentials":            {},
+}
+
+// PreviewIntegrationChangeSet returns what UpdateIntegrationSettings would change with the desired configuration.
+//
+// Nothing is written and no health check is run, the change set only reports whether one would be needed.
+func (API) PreviewIntegrationChangeSet(input *models.PreviewIntegrationChangeSetInput) (*models.IntegrationChangeSet, error) {
+	integration, err := getIntegrationForEdit(input.Desired.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffIntegration(integration, input.Desired)
+	healthCheckRequired := false
+	for _, change := range changes {
+		// Changes of map entries are reported as "field.key"
+		if _, ok := healthCheckedFields[strings.SplitN(*change.Field, ".", 2)[0]]; ok {
+			healthCheckRequired = true
+			break
+		}
+	}
+	return &models.IntegrationChangeSet{
+		IntegrationID:       integration.IntegrationID,
+		Changes:             changes,
+		HealthCheckRequired: aws.Bool(healthCheckRequired),
+	}, nil
+}
+
+// changeSet accumulates the changes of the settings of an integration, in the order they are diffed.
+type changeSet []*models.IntegrationFieldChange
+
+func diffIntegration(
+	current *models.SourceIntegrationMetadata, desired *models.UpdateIntegrationSettingsInput) []*models.IntegrationFieldChange {
+
+	changes := make(changeSet, 0)
+	changes.setting("integrationType", current.IntegrationType, desired.IntegrationType)
+	changes.setting("integrationLabel", current.IntegrationLabel, desired.IntegrationLabel)
+	changes.setting("description", current.Description, sanitizeDescription(desired.Description))
+	changes.setting("scanEnabled", current.ScanEnabled, desired.ScanEnabled)
+	changes.setting("cweEnabled", current.CWEEnabled, desired.CWEEnabled)
+	changes.setting("remediationEnabled", current.RemediationEnabled, desired.RemediationEnabled)
+	changes.setting("scanIntervalMins", current.ScanIntervalMins, desired.ScanIntervalMins)
+	changes.list("s3Buckets", current.S3Buckets, desired.S3Buckets)
+	changes.list("kmsKeys", current.KmsKeys, pinnedKmsKeys(current, desired.KmsKeys))
+	changes.setting("createKmsGrants", current.CreateKmsGrants, desired.CreateKmsGrants)
+	changes.setting("isOrgTrail", current.IsOrgTrail, desired.IsOrgTrail)
+	changes.setting("managementAccountId", current.ManagementAccountID, desired.ManagementAccountID)
+	changes.setting("streamArn", current.StreamARN, desired.StreamARN)
+	changes.setting("shardIteratorType", current.ShardIteratorType, desired.ShardIteratorType)
+	changes.whole("roleChain", current.RoleChain, desired.RoleChain)
+	changes.setting("sessionDurationSeconds", current.SessionDurationSeconds, desired.SessionDurationSeconds)
+	changes.mapping("s3BucketRegions", current.S3BucketRegions, desired.S3BucketRegions)
+	changes.whole("s3ObjectFilters", current.S3ObjectFilters, desired.S3ObjectFilters)
+	changes.list("syslogAllowedCidrs", current.SyslogAllowedCIDRs, desired.SyslogAllowedCIDRs)
+	changes.list("organizationExcludedAccounts", current.OrganizationExcludedAccounts, desired.OrganizationExcludedAccounts)
+	changes.setting("maxConcurrentObjects", current.MaxConcurrentObjects, desired.MaxConcurrentObjects)
+	changes.setting("maxObjectsPerScan", current.MaxObjectsPerScan, desired.MaxObjectsPerScan)
+	changes.setting("orderingMode", current.OrderingMode, desired.OrderingMode)
+	changes.setting("maxParallelism", current.MaxParallelism, desired.MaxParallelism)
+	changes.setting("dedupWindowMinutes", current.DedupWindowMinutes, desired.DedupWindowMinutes)
+	changes.setting("maxRecordBytes", current.MaxRecordBytes, desired.MaxRecordBytes)
+	changes.setting("oversizedRecordPolicy", current.OversizedRecordPolicy, desired.OversizedRecordPolicy)
+	changes.setting("sampleRate", current.SampleRate, desired.SampleRate)
+	changes.setting("errorRateThreshold", current.ErrorRateThreshold, desired.ErrorRateThreshold)
+	changes.setting("silenceThresholdMinutes", current.SilenceThresholdMinutes, desired.SilenceThresholdMinutes)
+	changes.setting("eventErrorRateThreshold", current.EventErrorRateThreshold, desired.EventErrorRateThreshold)
+	changes.setting("timeExtraction", current.TimeExtraction, desired.TimeExtraction)
+	changes.setting("timestampSkew", current.TimestampSkew, desired.TimestampSkew)
+	changes.setting("deadLetterQueue", current.DeadLetterQueue, desired.DeadLetterQueue)
+	changes.setting("archiveFormat", current.ArchiveFormat, desired.ArchiveFormat)
+	changes.setting("dataClassification", current.DataClassification, desired.DataClassification)
+	changes.setting("processingRegion", current.ProcessingRegion, desired.ProcessingRegion)
+	changes.whole("blackoutWindows", current.BlackoutWindows, desired.BlackoutWindows)
+	changes.setting("scanSchedule", current.ScanSchedule, desired.ScanSchedule)
+	changes.list("enabledRegions", current.EnabledRegions, desired.EnabledRegions)
+	changes.list("excludedRegions", current.ExcludedRegions, desired.ExcludedRegions)
+	changes.list("resourceTypeAllowList", current.ResourceTypeAllowList, desired.ResourceTypeAllowList)
+	changes.list("resourceTypeDenyList", current.ResourceTypeDenyList, desired.ResourceTypeDenyList)
+	changes.whole("redactionRules", current.RedactionRules, desired.RedactionRules)
+	changes.list("includePatterns", current.IncludePatterns, desired.IncludePatterns)
+	changes.list("excludePatterns", current.ExcludePatterns, desired.ExcludePatterns)
+	changes.list("disabledChecks", current.DisabledChecks, desired.DisabledChecks)
+	changes.flags("featureFlags", current.FeatureFlags, desired.FeatureFlags)
+	changes.list("dependsOn", current.DependsOn, desired.DependsOn)
+	changes.list("notificationTargets", current.NotificationTargets, desired.NotificationTargets)
+	changes.list("enrichmentSources", current.EnrichmentSources, desired.EnrichmentSources)
+	changes.mapping("tags", current.Tags, desired.Tags)
+	changes.mapping("severityOverrides", current.SeverityOverrides, desired.SeverityOverrides)
+	changes.setting("scanCompleteCallbackUrl", current.ScanCompleteCallbackURL, desired.ScanCompleteCallbackURL)
+	changes.credentials(current.CredentialsSecretArn, desired.Credentials)
+	return changes
+}
+
+func (set *changeSet) add(field, action string, current, desired interface{}) {
+	*set = append(*set, &models.IntegrationFieldChange{
+		Field:   aws.String(field),
+		Action:  aws.String(action),
+		Current: current,
+		Desired: desired,
+	})
+}
+
+// setting diffs a setting given by pointer, a nil desired value leaves it unchanged.
+func (set *changeSet) setting(field string, current, desired interface{}) {
+	if reflect.ValueOf(desired).IsNil() {
+		return
+	}
+	desiredValue := reflect.ValueOf(desired).Elem().Interface()
+	if reflect.ValueOf(current).IsNil() {
+		set.add(field, models.FieldAdded, nil, desiredValue)
+		return
+	}
+	currentValue := reflect.ValueOf(current).Elem().Interface()
+	if !reflect.DeepEqual(currentValue, desiredValue) {
+		set.add(field, models.FieldChanged, currentValue, desiredValue)
+	}
+}
+
+// list diffs a list by element, a nil desired list leaves it unchanged.
+func (set *changeSet) list(field string, current, desired []*string) {
+	if desired == nil {
+		return
+	}
+	currentValues := make(map[string]struct{}, len(current))
+	for _, value := range current {
+		currentValues[*value] = struct{}{}
+	}
+	desiredValues := make(map[string]struct{}, len(desired))
+	for _, value := range desired {
+		if _, ok := desiredValues[*value]; ok {
+			continue
+		}
+		desiredValues[*value] = struct{}{}
+		if _, ok := currentValues[*value]; !ok {
+			set.add(field, models.FieldAdded, nil, *value)
+		}
+	}
+	for _, value := range current {
+		if _, ok := desiredValues[*value]; !ok {
+			set.add(field, models.FieldRemoved, *value, nil)
+		}
+	}
+}
+
+// mapping diffs a map by key, the change of each entry is reported as "field.key". A nil desired map leaves it unchanged.
+func (set *changeSet) mapping(field string, current, desired map[string]*string) {
+	if desired == nil {
+		return
+	}
+	keys := make([]string, 0, len(current)+len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		currentValue, desiredValue := aws.StringValue(current[key]), aws.StringValue(desired[key])
+		switch {
+		case currentValue == desiredValue:
+			continue
+		case currentValue == "":
+			set.add(field+"."+key, models.FieldAdded, nil, desiredValue)
+		case desiredValue == "":
+			set.add(field+"."+key, models.FieldRemoved, currentValue, nil)
+		default:
+			set.add(field+"."+key, models.FieldChanged, currentValue, desiredValue)
+		}
+	}
+}
+
+// flags diffs a map of flags by key like mapping, a flag which is not set is disabled.
+func (set *changeSet) flags(field string, current, desired map[string]bool) {
+	if desired == nil {
+		return
+	}
+	keys := make([]string, 0, len(current)+len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		currentValue, currentSet := current[key]
+		desiredValue, desiredSet := desired[key]
+		switch {
+		case currentSet && desiredSet && currentValue == desiredValue:
+			continue
+		case !currentSet:
+			set.add(field+"."+key, models.FieldAdded, nil, desiredValue)
+		case !desiredSet:
+			set.add(field+"."+key, models.FieldRemoved, currentValue, nil)
+		default:
+			set.add(field+"."+key, models.FieldChanged, currentValue, desiredValue)
+		}
+	}
+}
+
+// whole diffs a list as a whole, for lists of settings which have no identity such as blackout windows.
+// A nil desired list leaves it unchanged.
+func (set *changeSet) whole(field string, current, desired interface{}) {
+	currentLen, desiredValue := reflect.ValueOf(current).Len(), reflect.ValueOf(desired)
+	switch {
+	case desiredValue.IsNil() || reflect.DeepEqual(current, desired):
+		return
+	case currentLen == 0:
+		set.add(field, models.FieldAdded, nil, desired)
+	case desiredValue.Len() == 0:
+		set.add(field, models.FieldRemoved, current, nil)
+	default:
+		set.add(field, models.FieldChanged, current, desired)
+	}
+}
+
+// credentials reports new credentials without their values, which are never returned.
+func (set *changeSet) credentials(currentSecretArn *string, desired map[string]*string) {
+	switch {
+	case desired == nil:
+		return
+	case currentSecretArn == nil:
+		set.add("credentials", models.FieldAdded, nil, nil)
+	default:
+		set.add("credentials", models.FieldChanged, nil, nil)
+	}
+}
+
+// pinnedKmsKeys replaces the aliases the integration already has with the key they are pinned to.
+//
+// Other aliases are compared as given, they would be resolved when the change set is applied.
+func pinnedKmsKeys(integration *models.SourceIntegrationMetadata, keys []*string) []*string {
+	if keys == nil {
+		return nil
+	}
+	result := make([]*string, len(keys))
+	for i, key := range keys {
+		result[i] = key
+		if pinnedArn, ok := integration.KmsKeyAliases[*key]; ok {
+			result[i] = pinnedArn
+		}
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func mockStoredIntegration(t *testing.T, integration *models.SourceIntegrationMetadata) *modelstest.MockDDBClient {
+	item, err := dynamodbattribute.MarshalMap(integration)
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+	return mockClient
+}
+
+func TestPreviewIntegrationChangeSet(t *testing.T) {
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWS3),
+		IntegrationLabel:   aws.String("logs"),
+		ScanIntervalMins:   aws.Int(60),
+		S3Buckets:          aws.StringSlice([]string{"bucket-1", "bucket-2"}),
+		KmsKeys:            aws.StringSlice([]string{"arn:aws:kms:us-west-2:123456789012:key/pinned"}),
+		KmsKeyAliases:      map[string]*string{"alias/logs": aws.String("arn:aws:kms:us-west-2:123456789012:key/pinned")},
+		DedupWindowMinutes: aws.Int(30),
+	})
+
+	result, err := apiTest.PreviewIntegrationChangeSet(&models.PreviewIntegrationChangeSetInput{
+		Desired: &models.UpdateIntegrationSettingsInput{
+			IntegrationID:    aws.String(testIntegrationID),
+			IntegrationLabel: aws.String("prod-logs"),
+			ScanIntervalMins: aws.Int(60),
+			S3Buckets:        aws.StringSlice([]string{"bucket-2", "bucket-3"}),
+			// The alias the key was added with is not a change
+			KmsKeys:              aws.StringSlice([]string{"alias/logs"}),
+			MaxConcurrentObjects: aws.Int(10),
+		},
+	})
+	require.NoError(t, err)
+	expected := &models.IntegrationChangeSet{
+		IntegrationID: aws.String(testIntegrationID),
+		Changes: []*models.IntegrationFieldChange{
+			{Field: aws.String("integrationLabel"), Action: aws.String(models.FieldChanged), Current: "logs", Desired: "prod-logs"},
+			{Field: aws.String("s3Buckets"), Action: aws.String(models.FieldAdded), Desired: "bucket-3"},
+			{Field: aws.String("s3Buckets"), Action: aws.String(models.FieldRemoved), Current: "bucket-1"},
+			{Field: aws.String("maxConcurrentObjects"), Action: aws.String(models.FieldAdded), Desired: 10},
+		},
+		HealthCheckRequired: aws.Bool(true),
+	}
+	assert.Equal(t, expected, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestPreviewIntegrationChangeSetNoHealthCheck(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+		ScanEnabled:     aws.Bool(true),
+		BlackoutWindows: []*models.BlackoutWindow{{DailyStart: aws.String("02:00"), DurationMins: aws.Int(60)}},
+	})
+
+	result, err := apiTest.PreviewIntegrationChangeSet(&models.PreviewIntegrationChangeSetInput{
+		Desired: &models.UpdateIntegrationSettingsInput{
+			IntegrationID:   aws.String(testIntegrationID),
+			ScanEnabled:     aws.Bool(false),
+			BlackoutWindows: []*models.BlackoutWindow{},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, &models.IntegrationFieldChange{
+		Field: aws.String("scanEnabled"), Action: aws.String(models.FieldChanged), Current: true, Desired: false,
+	}, result.Changes[0])
+	assert.Equal(t, models.FieldRemoved, *result.Changes[1].Action)
+	assert.Equal(t, "blackoutWindows", *result.Changes[1].Field)
+	assert.False(t, *result.HealthCheckRequired)
+}
+
+func TestPreviewIntegrationChangeSetBucketRegions(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs-eu", "logs-us", "logs-ap"}),
+		S3BucketRegions: map[string]*string{"logs-eu": aws.String("eu-west-1"), "logs-ap": aws.String("ap-south-1")},
+	})
+
+	result, err := apiTest.PreviewIntegrationChangeSet(&models.PreviewIntegrationChangeSetInput{
+		Desired: &models.UpdateIntegrationSettingsInput{
+			IntegrationID:   aws.String(testIntegrationID),
+			S3BucketRegions: map[string]*string{"logs-eu": aws.String("eu-central-1"), "logs-us": aws.String("us-east-1")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.IntegrationFieldChange{
+		{Field: aws.String("s3BucketRegions.logs-ap"), Action: aws.String(models.FieldRemoved), Current: "ap-south-1"},
+		{Field: aws.String("s3BucketRegions.logs-eu"), Action: aws.String(models.FieldChanged), Current: "eu-west-1", Desired: "eu-central-1"},
+		{Field: aws.String("s3BucketRegions.logs-us"), Action: aws.String(models.FieldAdded), Desired: "us-east-1"},
+	}, result.Changes)
+	assert.True(t, *result.HealthCheckRequired)
+}
+
+func TestPreviewIntegrationChangeSetUnchanged(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-1"}),
+	})
+
+	result, err := apiTest.PreviewIntegrationChangeSet(&models.PreviewIntegrationChangeSetInput{
+		Desired: &models.UpdateIntegrationSettingsInput{
+			IntegrationID: aws.String(testIntegrationID),
+			S3Buckets:     aws.StringSlice([]string{"bucket-1"}),
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+	assert.False(t, *result.HealthCheckRequired)
+}
+
+func TestPreviewIntegrationChangeSetDoesNotExist(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := apiTest.PreviewIntegrationChangeSet(&models.PreviewIntegrationChangeSetInput{
+		Desired: &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID)},
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
@@ -0,0 +1,175 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	defaultPreviewPageSize = 20
+
+	// Bounds the S3 requests for one page when few objects match, a partial page is returned after this many
+	maxPreviewListRequests = 5
+)
+
+// previewPosition is where the listing of the next page starts, it's encoded in the LastEvaluatedKey.
+type previewPosition struct {
+	BucketIndex int    `json:"bucketIndex"`
+	StartAfter  string `json:"startAfter,omitempty"`
+}
+
+// PreviewMatchedObjects lists a page of the objects which match the bucket entries of an integration.
+//
+// Either an existing integration or a proposed configuration can be previewed, with the log processing role.
+// The objects skipped by the key patterns are not listed, nor those the first scan skips because they were
+// last modified before the backfill start.
+func (API) PreviewMatchedObjects(input *models.PreviewMatchedObjectsInput) (*models.PreviewMatchedObjectsOutput, error) {
+	accountID, buckets := input.AWSAccountID, input.S3Buckets
+	patterns := &models.SourceIntegrationMetadata{
+		IncludePatterns:   input.IncludePatterns,
+		ExcludePatterns:   input.ExcludePatterns,
+		BackfillStartTime: input.BackfillStartTime,
+	}
+	scope := &models.SourceIntegration{SourceIntegrationMetadata: patterns}
+	if input.IntegrationID == nil && (accountID == nil || len(buckets) == 0) {
+		return nil, &genericapi.InvalidInputError{Message: "either an integrationId or an awsAccountId and s3Buckets are required"}
+	}
+	if input.IntegrationID != nil {
+		// The scan information tells whether the backfill is done
+		integration, err := db.GetSourceIntegration(input.IntegrationID)
+		if err != nil {
+			return nil, err
+		}
+		if integration == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+		}
+		accountID, buckets = integration.AWSAccountID, integration.S3Buckets
+		patterns, scope = integration.SourceIntegrationMetadata, integration
+	}
+	keyFilter, err := patterns.KeyFilter()
+	if err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+
+	var position previewPosition
+	if input.ExclusiveStartKey != nil {
+		decoded, err := base64.URLEncoding.DecodeString(*input.ExclusiveStartKey)
+		if err == nil {
+			err = json.Unmarshal(decoded, &position)
+		}
+		if err != nil {
+			return nil, &genericapi.InvalidInputError{Message: "invalid exclusiveStartKey"}
+		}
+	}
+	pageSize := defaultPreviewPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+
+	s3Client := s3ClientFunc(stscreds.NewCredentials(sess, fmt.Sprintf(logProcessingRoleFormat, aws.StringValue(accountID))))
+	output := &models.PreviewMatchedObjectsOutput{Objects: make([]*models.MatchedObject, 0, pageSize)}
+	for requests := 0; position.BucketIndex < len(buckets); requests++ {
+		if requests == maxPreviewListRequests || len(output.Objects) == pageSize {
+			output.LastEvaluatedKey = encodePreviewPosition(position)
+			return output, nil
+		}
+
+		bucket, pattern := parseBucketEntry(aws.StringValue(buckets[position.BucketIndex]))
+		listInput := &s3.ListObjectsV2Input{Bucket: aws.String(bucket)}
+		if prefix := patternPrefix(pattern); prefix != "" {
+			listInput.Prefix = aws.String(prefix)
+		}
+		if position.StartAfter != "" {
+			listInput.StartAfter = aws.String(position.StartAfter)
+		}
+		page, err := s3Client.ListObjectsV2(listInput)
+		if err != nil {
+			return nil, &genericapi.InvalidInputError{Message: fmt.Sprintf("failed to list bucket %s: %s", bucket, err)}
+		}
+
+		matcher := patternRegexp(pattern)
+		for _, object := range page.Contents {
+			position.StartAfter = aws.StringValue(object.Key)
+			if !matcher.MatchString(position.StartAfter) || !keyFilter.Includes(position.StartAfter) ||
+				!scope.BackfillIncludes(aws.TimeValue(object.LastModified)) {
+
+				continue
+			}
+			output.Objects = append(output.Objects, &models.MatchedObject{
+				Bucket: aws.String(bucket),
+				Key:    object.Key,
+				Size:   object.Size,
+			})
+			if len(output.Objects) == pageSize {
+				break
+			}
+		}
+
+		if len(output.Objects) < pageSize && !aws.BoolValue(page.IsTruncated) {
+			// Done with this bucket
+			position = previewPosition{BucketIndex: position.BucketIndex + 1}
+		}
+	}
+	return output, nil
+}
+
+func encodePreviewPosition(position previewPosition) *string {
+	// This can't fail, the struct only has basic types
+	encoded, _ := json.Marshal(position)
+	return aws.String(base64.URLEncoding.EncodeToString(encoded))
+}
+
+// parseBucketEntry splits a bucket entry like "my-bucket/logs/*.gz" into the bucket and the key pattern.
+func parseBucketEntry(entry string) (bucket, pattern string) {
+	if parts := strings.SplitN(entry, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return entry, ""
+}
+
+// patternPrefix is the literal start of a key pattern, which can be used as the prefix of a listing.
+func patternPrefix(pattern string) string {
+	if i := strings.Index(pattern, "*"); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+// patternRegexp matches the keys matching a pattern, where * matches any characters including "/".
+//
+// A pattern without any * matches the keys it's a prefix of, and an empty pattern matches every key.
+func patternRegexp(pattern string) *regexp.Regexp {
+	expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if !strings.Contains(pattern, "*") {
+		expr += ".*"
+	}
+	return regexp.MustCompile("^" + expr + "$")
+}
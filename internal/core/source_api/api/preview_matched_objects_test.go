@@ -0,0 +1,269 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func objectsWithKeys(keys ...string) []*s3.Object {
+	result := make([]*s3.Object, len(keys))
+	for i, key := range keys {
+		result[i] = &s3.Object{Key: aws.String(key), Size: aws.Int64(1)}
+	}
+	return result
+}
+
+func matchedKeys(output *models.PreviewMatchedObjectsOutput) []string {
+	result := make([]string, len(output.Objects))
+	for i, object := range output.Objects {
+		result[i] = *object.Bucket + "/" + *object.Key
+	}
+	return result
+}
+
+func TestPreviewMatchedObjects(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket-1"), Prefix: aws.String("logs/")}).
+		Return(&s3.ListObjectsV2Output{
+			Contents:    objectsWithKeys("logs/a.gz", "logs/a.json", "logs/nested/b.gz"),
+			IsTruncated: aws.Bool(false),
+		}, nil).Once()
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket-2")}).
+		Return(&s3.ListObjectsV2Output{Contents: objectsWithKeys("c.log"), IsTruncated: aws.Bool(false)}, nil).Once()
+	mockHealthCheckClients(nil, mockS3, nil)
+
+	result, err := apiTest.PreviewMatchedObjects(&models.PreviewMatchedObjectsInput{
+		AWSAccountID: aws.String(testAccountID),
+		S3Buckets:    aws.StringSlice([]string{"bucket-1/logs/*.gz", "bucket-2"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket-1/logs/a.gz", "bucket-1/logs/nested/b.gz", "bucket-2/c.log"}, matchedKeys(result))
+	assert.Nil(t, result.LastEvaluatedKey)
+	mockS3.AssertExpectations(t)
+}
+
+func TestPreviewMatchedObjectsPagination(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket"), Prefix: aws.String("logs")}).
+		Return(&s3.ListObjectsV2Output{
+			Contents:    objectsWithKeys("logs/1", "other", "logs/2", "logs/3"),
+			IsTruncated: aws.Bool(false),
+		}, nil).Once()
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{
+		Bucket:     aws.String("bucket"),
+		Prefix:     aws.String("logs"),
+		StartAfter: aws.String("logs/2"),
+	}).Return(&s3.ListObjectsV2Output{Contents: objectsWithKeys("logs/3"), IsTruncated: aws.Bool(false)}, nil).Once()
+	mockHealthCheckClients(nil, mockS3, nil)
+
+	input := &models.PreviewMatchedObjectsInput{
+		AWSAccountID: aws.String(testAccountID),
+		S3Buckets:    aws.StringSlice([]string{"bucket/logs"}),
+		PageSize:     aws.Int(2),
+	}
+	result, err := apiTest.PreviewMatchedObjects(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket/logs/1", "bucket/logs/2"}, matchedKeys(result))
+	require.NotNil(t, result.LastEvaluatedKey)
+
+	input.ExclusiveStartKey = result.LastEvaluatedKey
+	result, err = apiTest.PreviewMatchedObjects(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket/logs/3"}, matchedKeys(result))
+	assert.Nil(t, result.LastEvaluatedKey)
+	mockS3.AssertExpectations(t)
+}
+
+func TestPreviewMatchedObjectsListingCap(t *testing.T) {
+	// Nothing matches, so at most maxPreviewListRequests pages are listed before returning
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents:    objectsWithKeys("other"),
+		IsTruncated: aws.Bool(true),
+	}, nil)
+	mockHealthCheckClients(nil, mockS3, nil)
+
+	result, err := apiTest.PreviewMatchedObjects(&models.PreviewMatchedObjectsInput{
+		AWSAccountID: aws.String(testAccountID),
+		S3Buckets:    aws.StringSlice([]string{"bucket/logs*"}),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Objects)
+	assert.NotNil(t, result.LastEvaluatedKey)
+	mockS3.AssertNumberOfCalls(t, "ListObjectsV2", maxPreviewListRequests)
+}
+
+func TestPreviewMatchedObjectsInvalidStartKey(t *testing.T) {
+	result, err := apiTest.PreviewMatchedObjects(&models.PreviewMatchedObjectsInput{
+		AWSAccountID:      aws.String(testAccountID),
+		S3Buckets:         aws.StringSlice([]string{"bucket"}),
+		ExclusiveStartKey: aws.String("not a key"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestPreviewMatchedObjectsNothingToPreview(t *testing.T) {
+	result, err := apiTest.PreviewMatchedObjects(&models.PreviewMatchedObjectsInput{AWSAccountID: aws.String(testAccountID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestPreviewMatchedObjectsValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	assert.NoError(t, validator.Struct(&models.PreviewMatchedObjectsInput{IntegrationID: aws.String(testIntegrationID)}))
+	assert.NoError(t, validator.Struct(&models.PreviewMatchedObjectsInput{
+		AWSAccountID: aws.String(testAccountID),
+		S3Buckets:    aws.StringSlice([]string{"bucket"}),
+	}))
+	assert.Error(t, validator.Struct(&models.PreviewMatchedObjectsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		PageSize:      aws.Int(101),
+	}))
+}
+
+func TestPreviewMatchedObjectsKeyPatterns(t *testing.T) {
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket")}).
+		Return(&s3.ListObjectsV2Output{
+			Contents:    objectsWithKeys("logs/a.gz", "logs/a.gz.tmp", "logs/b.json", "other/c.gz"),
+			IsTruncated: aws.Bool(false),
+		}, nil).Once()
+	mockHealthCheckClients(nil, mockS3, nil)
+
+	result, err := apiTest.PreviewMatchedObjects(&models.PreviewMatchedObjectsInput{
+		AWSAccountID:    aws.String(testAccountID),
+		S3Buckets:       aws.StringSlice([]string{"bucket"}),
+		IncludePatterns: aws.StringSlice([]string{`^logs/`, `\.gz$`}),
+		ExcludePatterns: aws.StringSlice([]string{`\.tmp$`, `^other/`}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket/logs/a.gz", "bucket/logs/b.json"}, matchedKeys(result))
+	mockS3.AssertExpectations(t)
+}
+
+func TestPreviewMatchedObjectsBackfill(t *testing.T) {
+	backfillStart := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	listing := &s3.ListObjectsV2Output{
+		Contents: []*s3.Object{
+			{Key: aws.String("old.gz"), Size: aws.Int64(1), LastModified: aws.Time(backfillStart.Add(-time.Hour))},
+			{Key: aws.String("new.gz"), Size: aws.Int64(1), LastModified: aws.Time(backfillStart.Add(time.Hour))},
+		},
+		IsTruncated: aws.Bool(false),
+	}
+	mockS3 := &mockS3Client{}
+	mockS3.On("ListObjectsV2", &s3.ListObjectsV2Input{Bucket: aws.String("bucket")}).Return(listing, nil)
+	mockHealthCheckClients(nil, mockS3, nil)
+	item := map[string]*dynamodb.AttributeValue{
+		"integrationId":     {S: aws.String(testIntegrationID)},
+		"integrationType":   {S: aws.String(models.IntegrationTypeAWS3)},
+		"awsAccountId":      {S: aws.String(testAccountID)},
+		"s3Buckets":         {L: []*dynamodb.AttributeValue{{S: aws.String("bucket")}}},
+		"backfillStartTime": {S: aws.String(backfillStart.Format(time.RFC3339))},
+	}
+	mockClient := &modelstest.MockDDBClient{}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+
+	// The first scan of the integration skips the objects last modified before the backfill start
+	input := &models.PreviewMatchedObjectsInput{IntegrationID: aws.String(testIntegrationID)}
+	result, err := apiTest.PreviewMatchedObjects(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket/new.gz"}, matchedKeys(result))
+
+	// Once a scan completed, every object is listed
+	item["completedScans"] = &dynamodb.AttributeValue{N: aws.String("1")}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+	result, err = apiTest.PreviewMatchedObjects(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket/old.gz", "bucket/new.gz"}, matchedKeys(result))
+
+	// A proposed configuration is bounded by its own backfill start
+	result, err = apiTest.PreviewMatchedObjects(&models.PreviewMatchedObjectsInput{
+		AWSAccountID:      aws.String(testAccountID),
+		S3Buckets:         aws.StringSlice([]string{"bucket"}),
+		BackfillStartTime: aws.Time(backfillStart),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket/new.gz"}, matchedKeys(result))
+	mockClient.AssertExpectations(t)
+}
+
+func TestKeyFilterPrecedence(t *testing.T) {
+	filter, err := (&models.SourceIntegrationMetadata{
+		IncludePatterns: aws.StringSlice([]string{`\.gz$`}),
+		ExcludePatterns: aws.StringSlice([]string{`^tmp/`}),
+	}).KeyFilter()
+	require.NoError(t, err)
+	assert.True(t, filter.Includes("logs/a.gz"))
+	// Excludes win over includes
+	assert.False(t, filter.Includes("tmp/a.gz"))
+	assert.False(t, filter.Includes("logs/a.json"))
+
+	// Without include patterns, everything which isn't excluded is ingested
+	filter, err = (&models.SourceIntegrationMetadata{ExcludePatterns: aws.StringSlice([]string{`^tmp/`})}).KeyFilter()
+	require.NoError(t, err)
+	assert.True(t, filter.Includes("logs/a.json"))
+	assert.False(t, filter.Includes("tmp/a.json"))
+
+	filter, err = (&models.SourceIntegrationMetadata{}).KeyFilter()
+	require.NoError(t, err)
+	assert.True(t, filter.Includes("anything"))
+
+	_, err = (&models.SourceIntegrationMetadata{IncludePatterns: aws.StringSlice([]string{`(`})}).KeyFilter()
+	assert.Error(t, err)
+}
+
+func TestKeyPatternValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	valid := func(pattern string) error {
+		return validator.Struct(&models.PreviewMatchedObjectsInput{IncludePatterns: []*string{aws.String(pattern)}})
+	}
+
+	assert.NoError(t, valid(`^logs/\d{4}/\d{2}/.*\.gz$`))
+	assert.NoError(t, valid(`(cloudtrail|vpcflow)/`))
+	// Uncompilable
+	assert.Error(t, valid(`logs/(`))
+	assert.Error(t, valid(`[z-a]`))
+	// Nested repetitions
+	assert.Error(t, valid(`(a+)+$`))
+	assert.Error(t, valid(`(.*/)*x`))
+	// Too large once compiled, or too long
+	assert.Error(t, valid(`(ab{1,100}){1,100}`))
+	assert.Error(t, valid(`[a-z]{999}`))
+	assert.Error(t, valid(strings.Repeat("a", 257)))
+}
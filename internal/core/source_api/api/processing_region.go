@@ -0,0 +1,85 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// deployedRegions are the regions Panther is deployed in: PROCESSING_REGIONS and the region of the source API.
+func deployedRegions() map[string]struct{} {
+	regions := make(map[string]struct{})
+	if homeRegion != "" {
+		regions[homeRegion] = struct{}{}
+	}
+	for _, region := range strings.Split(processingRegions, ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			regions[region] = struct{}{}
+		}
+	}
+	return regions
+}
+
+// checkProcessingRegion returns an error unless the region is one of the regions Panther is deployed in.
+//
+// An empty region unpins the integration, it is then processed in the region of the source API.
+func checkProcessingRegion(region *string) error {
+	if aws.StringValue(region) == "" {
+		return nil
+	}
+	regions := deployedRegions()
+	if _, ok := regions[*region]; ok {
+		return nil
+	}
+	deployed := make([]string, 0, len(regions))
+	for name := range regions {
+		deployed = append(deployed, name)
+	}
+	sort.Strings(deployed)
+	return &genericapi.InvalidInputError{Message: "processingRegion: Panther is not deployed in " + *region +
+		", it is deployed in " + strings.Join(deployed, ", ")}
+}
+
+// processingRegionOf is the region which processes the integration.
+func processingRegionOf(integration *models.SourceIntegrationMetadata) string {
+	if region := aws.StringValue(integration.ProcessingRegion); region != "" {
+		return region
+	}
+	return homeRegion
+}
+
+// regionalQueueURL is the URL of the queue of the same name in the region which processes the integration.
+//
+// The deployments in each region have the same queues, only the region in the host of their URL differs.
+func regionalQueueURL(queueURL string, integration *models.SourceIntegrationMetadata) string {
+	region := processingRegionOf(integration)
+	parsed, err := url.Parse(queueURL)
+	if err != nil || region == homeRegion || !strings.HasPrefix(parsed.Host, "sqs."+homeRegion+".") {
+		return queueURL
+	}
+	parsed.Host = "sqs." + region + "." + strings.TrimPrefix(parsed.Host, "sqs."+homeRegion+".")
+	return parsed.String()
+}
@@ -0,0 +1,133 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func mockProcessingRegions(t *testing.T) {
+	previousHome, previousRegions := homeRegion, processingRegions
+	homeRegion, processingRegions = "us-west-2", "eu-central-1, eu-west-1"
+	t.Cleanup(func() { homeRegion, processingRegions = previousHome, previousRegions })
+}
+
+func TestCheckProcessingRegion(t *testing.T) {
+	mockProcessingRegions(t)
+
+	assert.NoError(t, checkProcessingRegion(nil))
+	assert.NoError(t, checkProcessingRegion(aws.String("")))
+	assert.NoError(t, checkProcessingRegion(aws.String("us-west-2")))
+	assert.NoError(t, checkProcessingRegion(aws.String("eu-west-1")))
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "processingRegion: Panther is not deployed in ap-south-1, it is deployed in eu-central-1, eu-west-1, us-west-2",
+	}, checkProcessingRegion(aws.String("ap-south-1")))
+}
+
+func TestRegionalQueueURL(t *testing.T) {
+	mockProcessingRegions(t)
+	queueURL := "https://sqs.us-west-2.amazonaws.com/123456789012/panther-snapshot-queue"
+
+	assert.Equal(t, queueURL, regionalQueueURL(queueURL, &models.SourceIntegrationMetadata{}))
+	assert.Equal(t, "https://sqs.eu-west-1.amazonaws.com/123456789012/panther-snapshot-queue",
+		regionalQueueURL(queueURL, &models.SourceIntegrationMetadata{ProcessingRegion: aws.String("eu-west-1")}))
+}
+
+func TestUpdateIntegrationSettingsProcessingRegion(t *testing.T) {
+	mockProcessingRegions(t)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+	})
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"processingRegion": {S: aws.String("eu-west-1")},
+	}}, nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		ProcessingRegion: aws.String("eu-west-1"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", *result.ProcessingRegion)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "processingRegion")
+
+	_, err = apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		ProcessingRegion: aws.String("ap-south-1"),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestListIntegrationsProcessingRegion(t *testing.T) {
+	mockProcessingRegions(t)
+	integration := func(id, region string) map[string]*dynamodb.AttributeValue {
+		item := map[string]*dynamodb.AttributeValue{
+			"integrationId":   {S: aws.String(id)},
+			"integrationType": {S: aws.String(models.IntegrationTypeAWSScan)},
+			"scanEnabled":     {BOOL: aws.Bool(true)},
+		}
+		if region != "" {
+			item["processingRegion"] = &dynamodb.AttributeValue{S: aws.String(region)}
+		}
+		return item
+	}
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+		integration("unpinned", ""),
+		integration("pinned-home", "us-west-2"),
+		integration("pinned-eu", "eu-west-1"),
+	}}, TableName: "test"}
+	ids := func(integrations []*models.SourceIntegration) []string {
+		result := make([]string, len(integrations))
+		for i, integration := range integrations {
+			result[i] = *integration.IntegrationID
+		}
+		return result
+	}
+
+	out, err := apiTest.ListIntegrations(&models.ListIntegrationsInput{ProcessingRegion: aws.String("eu-west-1")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned-eu"}, ids(out))
+
+	out, err = apiTest.ListIntegrations(&models.ListIntegrationsInput{ProcessingRegion: aws.String("us-west-2")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unpinned", "pinned-home"}, ids(out))
+
+	out, err = apiTest.ListIntegrations(&models.ListIntegrationsInput{})
+	require.NoError(t, err)
+	assert.Len(t, out, 3)
+}
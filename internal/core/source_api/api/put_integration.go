@@ -31,30 +31,108 @@ import (
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
-	awspoller "github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws"
 	"github.com/panther-labs/panther/pkg/awsbatch/sqsbatch"
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 // PutIntegration adds a set of new integrations in a batch.
+//
+// A dry run stops before the first write: the new integrations are returned with their DryRunReport.
 func (api API) PutIntegration(input *models.PutIntegrationInput) ([]*models.SourceIntegrationMetadata, error) {
+	api = api.withAlertOutputs()
 	// Validate the new integrations
+	type integrationHealth struct {
+		status           *string
+		failedChecks     []*string
+		credentialExpiry *time.Time
+		dryRunReport     *models.DryRunReport
+	}
+	health := make(map[*models.PutIntegrationSettings]integrationHealth, len(input.Integrations))
+	enablingRemediation := 0
 	for _, integration := range input.Integrations {
-		passing, err := evaluateIntegrationFunc(api, &models.CheckIntegrationInput{
-			AWSAccountID:      integration.AWSAccountID,
-			IntegrationType:   integration.IntegrationType,
-			EnableCWESetup:    integration.CWEEnabled,
-			EnableRemediation: integration.RemediationEnabled,
-			S3Buckets:         integration.S3Buckets,
-			KmsKeys:           integration.KmsKeys,
-		})
-		if err != nil {
+		if aws.BoolValue(integration.RemediationEnabled) {
+			enablingRemediation++
+		}
+	}
+	// A quota which can't be checked for now is checked again in the background, once the integrations are written
+	quotaErr := checkRemediationQuota(nil, enablingRemediation)
+	pendingQuota, _ := quotaErr.(*transientError)
+	if quotaErr != nil && pendingQuota == nil {
+		return nil, quotaErr
+	}
+	for _, integration := range input.Integrations {
+		if err := checkBucketRegions(integration.S3Buckets, integration.S3BucketRegions); err != nil {
+			return nil, err
+		}
+		if err := checkObjectFilters(nil, integration.S3Buckets, integration.S3ObjectFilters); err != nil {
+			return nil, err
+		}
+		if err := checkTimeExtraction(integration.IntegrationType, integration.TimeExtraction); err != nil {
+			return nil, err
+		}
+		if err := checkTimestampSkew(integration.IntegrationType, integration.TimestampSkew); err != nil {
+			return nil, err
+		}
+		if err := checkOrdering(integration.IntegrationType, integration.OrderingMode, integration.MaxParallelism); err != nil {
+			return nil, err
+		}
+		if err := checkIngestionAlarmSettings(
+			integration.IntegrationType, integration.SilenceThresholdMinutes, integration.EventErrorRateThreshold); err != nil {
+
+			return nil, err
+		}
+		if err := checkBackfillStartTime(integration.IntegrationType, integration.BackfillStartTime, time.Now()); err != nil {
+			return nil, err
+		}
+		if err := checkSessionDurationSettings(integration.IntegrationType, healthCheckInputForNew(integration)); err != nil {
 			return nil, err
 		}
-		if !passing {
-			return nil, &genericapi.InvalidInputError{
-				Message: fmt.Sprintf("integration %s did not pass health check", *integration.AWSAccountID),
+		if err := checkDeadLetterQueueSettings(integration.IntegrationType, integration.DeadLetterQueue); err != nil {
+			return nil, err
+		}
+		if err := checkArchiveFormat(integration.IntegrationType, integration.ArchiveFormat); err != nil {
+			return nil, err
+		}
+		if err := checkScanRegions(integration.IntegrationType, integration.EnabledRegions, integration.ExcludedRegions); err != nil {
+			return nil, err
+		}
+		if err := checkResourceTypes(
+			integration.IntegrationType, integration.ResourceTypeAllowList, integration.ResourceTypeDenyList); err != nil {
+
+			return nil, err
+		}
+		if err := checkSQSQueueSettings(integration.SQSQueueArn); err != nil {
+			return nil, err
+		}
+		if err := checkOrganizationSettings(integration.IntegrationType, integration.OrganizationExcludedAccounts); err != nil {
+			return nil, err
+		}
+		if aws.BoolValue(integration.DiscoverKmsKeys) {
+			integration.KmsKeys = append(integration.KmsKeys, discoverKmsKeysFunc(healthCheckInputForNew(integration))...)
+		}
+		healthCheckInput := healthCheckInputForNew(integration)
+		if aws.BoolValue(input.DryRun) {
+			report, err := dryRunHealth(api, healthCheckInput, aws.BoolValue(integration.AllowPartialHealth))
+			if err != nil {
+				return nil, err
 			}
+			health[integration] = integrationHealth{
+				status:           report.HealthStatus,
+				failedChecks:     report.FailedHealthChecks,
+				credentialExpiry: report.Health.CredentialExpiry,
+				dryRunReport:     report,
+			}
+			continue
+		}
+		status, failedChecks, expiry, err := checkIntegrationHealth(
+			api, healthCheckInput, aws.BoolValue(integration.AllowPartialHealth))
+		if err != nil {
+			return nil, err
+		}
+		health[integration] = integrationHealth{
+			status:           status,
+			failedChecks:     failedChecks,
+			credentialExpiry: expiry,
 		}
 	}
 
@@ -64,16 +142,101 @@ func (api API) PutIntegration(input *models.PutIntegrationInput) ([]*models.Sour
 		return nil, err
 	}
 
+	// Labels must be unique within an account, including among the new integrations.
+	// Dependencies and notification targets must exist.
+	labels, err := getIntegrationLabels(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, integration := range integrations {
+		account := accountOf(integration.AWSAccountID, integration.AzureSubscriptionID, integration.GCPProjectID)
+		if !aws.BoolValue(integration.AllowDuplicateLabel) {
+			if err = labels.check(account, integration.IntegrationLabel); err != nil {
+				return nil, err
+			}
+		}
+		labels.add(account, integration.IntegrationLabel)
+		if err = checkDependencies(nil, integration.DependsOn); err != nil {
+			return nil, err
+		}
+		if err = api.checkNotificationTargets(integration.NotificationTargets); err != nil {
+			return nil, err
+		}
+		if err = checkEnrichmentSources(integration.EnrichmentSources); err != nil {
+			return nil, err
+		}
+		if err = checkProcessingRegion(integration.ProcessingRegion); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate the new integrations
 	newIntegrations := make([]*models.SourceIntegrationMetadata, len(integrations))
 	for i, integration := range integrations {
 		newIntegrations[i] = generateNewIntegration(integration)
+		newIntegrations[i].HealthStatus = health[integration].status
+		newIntegrations[i].FailedHealthChecks = health[integration].failedChecks
+		newIntegrations[i].HealthCheckResult = healthCheckResult(health[integration].status)
+		newIntegrations[i].LastHealthyTime = lastHealthyTime(health[integration].status)
+		newIntegrations[i].LastHealthCheckTime = aws.Time(time.Now())
+		newIntegrations[i].CredentialExpiry = health[integration].credentialExpiry
+
+		// Pin KMS aliases to the keys they currently point to
+		if len(integration.KmsKeys) > 0 {
+			newIntegrations[i].KmsKeys, newIntegrations[i].KmsKeyAliases, err = resolveKmsKeysFunc(
+				integration.AWSAccountID, integration.KmsKeys)
+			if err != nil {
+				return nil, err
+			}
+		}
+		newIntegrations[i].DryRunReport = health[integration].dryRunReport
+	}
+	if aws.BoolValue(input.DryRun) {
+		return newIntegrations, nil
 	}
 
 	// Get ready to add appropriate permissions to the SQS queue
 	permissionsAddedForIntegrations := []*models.SourceIntegrationMetadata{}
+	var queuesProvisionedForIntegrations, keysCreatedForIntegrations, consumersRegisteredForIntegrations []*models.SourceIntegrationMetadata
+	var credentialsStoredForIntegrations []*models.SourceIntegrationMetadata
+	// The change records and the retries are written before the integrations, they are deleted if these aren't
+	recordedChanges := make(map[*string]*string)  // integration ID -> change ID
+	enqueuedRetries := make(map[*string][]string) // integration ID -> side effects
 	defer func() {
 		if err != nil {
+			for integrationID, changeID := range recordedChanges {
+				if undoErr := db.DeleteChange(integrationID, changeID); undoErr != nil {
+					zap.L().Error("failed to delete the change record of an integration which wasn't created, it has to be deleted manually",
+						zap.String("integrationId", *integrationID),
+						zap.String("changeId", *changeID),
+						zap.Error(undoErr))
+				}
+			}
+			for integrationID, sideEffects := range enqueuedRetries {
+				for _, sideEffect := range sideEffects {
+					if undoErr := db.DeleteRetry(integrationID, sideEffect); undoErr != nil {
+						zap.L().Error("failed to delete the retry of an integration which wasn't created, it has to be deleted manually",
+							zap.String("integrationId", *integrationID),
+							zap.String("sideEffect", sideEffect),
+							zap.Error(undoErr))
+					}
+				}
+			}
+			for _, integration := range newIntegrations {
+				retireIntegrationKmsGrants(integration)
+			}
+			for _, integration := range queuesProvisionedForIntegrations {
+				removeSQSQueue(integration)
+			}
+			for _, integration := range keysCreatedForIntegrations {
+				deleteHTTPIngestKey(integration.IntegrationID, integration.HTTPIngestAPIKeyID)
+			}
+			for _, integration := range consumersRegisteredForIntegrations {
+				deregisterKinesisConsumer(integration, integration.KinesisConsumerARN)
+			}
+			for _, integration := range credentialsStoredForIntegrations {
+				deleteCredentials(integration, true)
+			}
 			// In case there has been any error, try to undo granting of permissions to SQS queue.
 			for _, integration := range permissionsAddedForIntegrations {
 				if undoErr := RemovePermissionFromLogProcessorQueue(*integration.AWSAccountID); undoErr != nil {
@@ -86,6 +249,76 @@ func (api API) PutIntegration(input *models.PutIntegrationInput) ([]*models.Sour
 		}
 	}()
 
+	// Create the KMS grants of the integrations which use grants instead of key policies.
+	// The integrations whose grants fail transiently are saved without them, they are created in the background
+	// along with the checks of the remediation quota which failed transiently.
+	pendingRetries := make(map[*string][]*transientError)
+	for i, integration := range integrations {
+		if pendingQuota != nil && aws.BoolValue(integration.RemediationEnabled) {
+			pendingRetries[newIntegrations[i].IntegrationID] = append(pendingRetries[newIntegrations[i].IntegrationID], pendingQuota)
+		}
+		if !aws.BoolValue(integration.CreateKmsGrants) || *integration.IntegrationType != models.IntegrationTypeAWS3 ||
+			len(newIntegrations[i].KmsKeys) == 0 {
+
+			continue
+		}
+		var grants *kmsGrantChange
+		grants, err = syncKmsGrants(integration.AWSAccountID, newIntegrations[i].IntegrationID, newIntegrations[i].KmsKeys, nil)
+		if transientErr, ok := err.(*transientError); ok {
+			pendingRetries[newIntegrations[i].IntegrationID] = append(pendingRetries[newIntegrations[i].IntegrationID], transientErr)
+			err = nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		newIntegrations[i].KmsGrants = grants.result()
+	}
+
+	// Create the queues of the SQS integrations which don't have one, and map them to the log processor
+	for _, integration := range newIntegrations {
+		if *integration.IntegrationType != models.IntegrationTypeSQSQueue {
+			continue
+		}
+		if err = provisionSQSQueue(integration); err != nil {
+			return nil, err
+		}
+		queuesProvisionedForIntegrations = append(queuesProvisionedForIntegrations, integration)
+	}
+
+	// Create the API keys the log events of the HTTP ingest integrations are posted with
+	for _, integration := range newIntegrations {
+		if *integration.IntegrationType != models.IntegrationTypeHTTPIngest {
+			continue
+		}
+		if err = provisionHTTPIngestKey(integration); err != nil {
+			return nil, err
+		}
+		keysCreatedForIntegrations = append(keysCreatedForIntegrations, integration)
+	}
+
+	// Register the consumers the streams of the Kinesis integrations are read with
+	for _, integration := range newIntegrations {
+		if *integration.IntegrationType != models.IntegrationTypeAWSKinesis {
+			continue
+		}
+		if err = registerKinesisConsumer(integration); err != nil {
+			return nil, err
+		}
+		consumersRegisteredForIntegrations = append(consumersRegisteredForIntegrations, integration)
+	}
+
+	// Store the credentials the logs of the pull sources are read with
+	for i, integration := range integrations {
+		if integration.Credentials == nil {
+			continue
+		}
+		if err = storeCredentials(newIntegrations[i], integration.Credentials); err != nil {
+			return nil, err
+		}
+		credentialsStoredForIntegrations = append(credentialsStoredForIntegrations, newIntegrations[i])
+	}
+
 	// Add appropriate permissions to the SQS queue
 	for _, integration := range newIntegrations {
 		if *integration.IntegrationType != models.IntegrationTypeAWS3 {
@@ -98,10 +331,32 @@ func (api API) PutIntegration(input *models.PutIntegrationInput) ([]*models.Sour
 		permissionsAddedForIntegrations = append(permissionsAddedForIntegrations, integration)
 	}
 
+	// The side effects are written before the integrations: with strict side effects, a failure must fail the
+	// creation before it is committed. They are deleted if the integrations end up not being written.
+	for integrationID, causes := range pendingRetries {
+		for _, cause := range causes {
+			if err = enqueueRetry(integrationID, cause); err != nil {
+				return nil, err
+			}
+			enqueuedRetries[integrationID] = append(enqueuedRetries[integrationID], cause.sideEffect)
+		}
+	}
+	for _, integration := range newIntegrations {
+		var changeID *string
+		changeID, err = recordChangeWithID(
+			integration.IntegrationID, models.ChangeOperationCreated, integration.CreatedBy, creationChanges(integration))
+		if err != nil {
+			return nil, err
+		}
+		recordedChanges[integration.IntegrationID] = changeID
+	}
+
 	// Batch write to DynamoDB
 	if err = db.BatchPutSourceIntegrations(newIntegrations); err != nil {
 		return nil, err
 	}
+	// The integrations are committed along with their change records and retries
+	recordedChanges, enqueuedRetries = nil, nil
 
 	// Return early to skip sending to the snapshot queue
 	if aws.BoolValue(input.SkipScanQueue) {
@@ -110,8 +365,8 @@ func (api API) PutIntegration(input *models.PutIntegrationInput) ([]*models.Sour
 
 	var integrationsToScan []*models.SourceIntegrationMetadata
 	for _, integration := range newIntegrations {
-		//We don't want to trigger scanning for aws-s3 type integrations
-		if aws.StringValue(integration.IntegrationType) == models.IntegrationTypeAWS3 {
+		// Only cloud security integrations have resources to scan
+		if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWSScan {
 			continue
 		}
 		integrationsToScan = append(integrationsToScan, integration)
@@ -132,12 +387,19 @@ func (api API) filterOutExistingIntegrations(inputIntegrations []*models.PutInte
 	}
 	currentIntegrationsMap := make(map[string]struct{})
 	for _, integration := range currentIntegrations {
-		currentIntegrationsMap[*integration.AWSAccountID+*integration.IntegrationType] = struct{}{}
+		accountID := accountOf(integration.AWSAccountID, integration.AzureSubscriptionID, integration.GCPProjectID)
+		currentIntegrationsMap[aws.StringValue(accountID)+*integration.IntegrationType] = struct{}{}
 	}
 	for _, integration := range inputIntegrations {
-		if _, found := currentIntegrationsMap[*integration.AWSAccountID+*integration.IntegrationType]; found {
+		accountID := aws.StringValue(accountOf(integration.AWSAccountID, integration.AzureSubscriptionID, integration.GCPProjectID))
+		// The integrations which aren't tied to an account, such as http-ingest, can be added as many times as needed
+		if accountID == "" {
+			existingIntegrations = append(existingIntegrations, integration)
+			continue
+		}
+		if _, found := currentIntegrationsMap[accountID+*integration.IntegrationType]; found {
 			zap.L().Warn(fmt.Sprintf("integration exists for: %s:%s skipping PutIntegration()",
-				*integration.AWSAccountID, *integration.IntegrationType))
+				accountID, *integration.IntegrationType))
 		} else {
 			existingIntegrations = append(existingIntegrations, integration)
 		}
@@ -145,55 +407,181 @@ func (api API) filterOutExistingIntegrations(inputIntegrations []*models.PutInte
 	return existingIntegrations, nil
 }
 
+// checkBackfillStartTime rejects a backfill start in the future, or on an integration without buckets.
+func checkBackfillStartTime(integrationType *string, start *time.Time, now time.Time) error {
+	if start == nil {
+		return nil
+	}
+	if aws.StringValue(integrationType) != models.IntegrationTypeAWS3 {
+		return &genericapi.InvalidInputError{Message: "only log analysis integrations have a backfill"}
+	}
+	if start.After(now) {
+		return &genericapi.InvalidInputError{Message: "backfillStartTime: the backfill can't start in the future"}
+	}
+	return nil
+}
+
 // ScanAllResources schedules scans for each Resource type for each integration.
 //
-// Each Resource type is sent within its own SQS message.
+// Each Resource type is sent within its own SQS message, to the queue of the region which processes the integration.
 func ScanAllResources(integrations []*models.SourceIntegrationMetadata) error {
-	var sqsEntries []*sqs.SendMessageBatchRequestEntry
+	queueEntries := make(map[string][]*sqs.SendMessageBatchRequestEntry)
+	var queueURLs []string
 
 	// For each integration, add a ScanMsg to the queue per service
 	for _, integration := range integrations {
-		if !aws.BoolValue(integration.ScanEnabled) {
+		// The snapshot pollers only scan AWS accounts, an organization is scanned through its member accounts
+		integrationType := aws.StringValue(integration.IntegrationType)
+		if !aws.BoolValue(integration.ScanEnabled) || integrationType == models.IntegrationTypeAzureScan ||
+			integrationType == models.IntegrationTypeAWSOrganization {
+
 			continue
 		}
+		queueURL := regionalQueueURL(snapshotPollersQueueURL, integration)
+		if _, ok := queueEntries[queueURL]; !ok {
+			queueURLs = append(queueURLs, queueURL)
+		}
 
-		for resourceType := range awspoller.ServicePollers {
-			scanMsg := &pollermodels.ScanMsg{
-				Entries: []*pollermodels.ScanEntry{
-					{
-						AWSAccountID:  integration.AWSAccountID,
-						IntegrationID: integration.IntegrationID,
-						ResourceType:  aws.String(resourceType),
-					},
-				},
-			}
+		resourceTypes, err := triggeredResourceTypes(integration, nil)
+		if err != nil {
+			return err
+		}
+		entries, err := scanRequestEntries(integration, nil, resourceTypes)
+		if err != nil {
+			return err
+		}
+		queueEntries[queueURL] = append(queueEntries[queueURL], entries...)
+	}
 
-			messageBodyBytes, err := jsoniter.MarshalToString(scanMsg)
-			if err != nil {
-				return &genericapi.InternalError{Message: err.Error()}
-			}
+	// Batch send all the messages to SQS
+	for _, queueURL := range queueURLs {
+		zap.L().Info(
+			"scheduling new scans",
+			zap.String("queueUrl", queueURL),
+			zap.Int("count", len(queueEntries[queueURL])),
+		)
+		err := sqsbatch.SendMessageBatch(SQSClient, maxElapsedTime, &sqs.SendMessageBatchInput{
+			Entries:  queueEntries[queueURL],
+			QueueUrl: aws.String(queueURL),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			sqsEntries = append(sqsEntries, &sqs.SendMessageBatchRequestEntry{
-				// Generates an ID of: IntegrationID-AWSResourceType
-				Id: aws.String(
-					*integration.IntegrationID + "-" + strings.Replace(resourceType, ".", "", -1),
-				),
-				MessageBody: aws.String(messageBodyBytes),
-			})
+const (
+	// The delay between the waves of the resource types of an integration limited to MaxConcurrentObjects at once
+	scanWaveDelay = 2 * time.Minute
+	// The longest SQS can delay a message
+	maxSQSDelaySecs = 900
+)
+
+// scanRequestEntries are the messages of the scans of the resource types of the integration, which enforce its limits.
+//
+// The MaxObjectsPerScan of the integration are shared evenly by the resource types, the poller of each reports no
+// more than its share. Only MaxConcurrentObjects resource types are scanned at once: the messages of the others are
+// delayed by a scanWaveDelay per wave, up to the longest delay of SQS after which the remaining waves start together.
+func scanRequestEntries(
+	integration *models.SourceIntegrationMetadata, region *string, resourceTypes []string) ([]*sqs.SendMessageBatchRequestEntry, error) {
+
+	var maxResources *int
+	if integration.MaxObjectsPerScan != nil && len(resourceTypes) > 0 {
+		maxResources = aws.Int(*integration.MaxObjectsPerScan / len(resourceTypes))
+		if *maxResources == 0 {
+			maxResources = aws.Int(1)
 		}
 	}
 
-	zap.L().Info(
-		"scheduling new scans",
-		zap.String("queueUrl", snapshotPollersQueueURL),
-		zap.Int("count", len(sqsEntries)),
-	)
+	entries := make([]*sqs.SendMessageBatchRequestEntry, len(resourceTypes))
+	for i, resourceType := range resourceTypes {
+		entry, err := scanRequestEntry(integration, region, resourceType, maxResources)
+		if err != nil {
+			return nil, err
+		}
+		if integration.MaxConcurrentObjects != nil {
+			delay := int64(i / *integration.MaxConcurrentObjects) * int64(scanWaveDelay/time.Second)
+			if delay > maxSQSDelaySecs {
+				delay = maxSQSDelaySecs
+			}
+			if delay > 0 {
+				entry.DelaySeconds = aws.Int64(delay)
+			}
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
 
-	// Batch send all the messages to SQS
-	return sqsbatch.SendMessageBatch(SQSClient, maxElapsedTime, &sqs.SendMessageBatchInput{
-		Entries:  sqsEntries,
-		QueueUrl: &snapshotPollersQueueURL,
-	})
+// scanRequestEntry is the message of the scan of a resource type of the integration, in every region of the
+// integration unless one is given.
+func scanRequestEntry(integration *models.SourceIntegrationMetadata, region *string, resourceType string,
+	maxResources *int) (*sqs.SendMessageBatchRequestEntry, error) {
+
+	scanMsg := &pollermodels.ScanMsg{
+		Entries: []*pollermodels.ScanEntry{
+			{
+				AWSAccountID:    integration.AWSAccountID,
+				IntegrationID:   integration.IntegrationID,
+				Region:          region,
+				ResourceType:    aws.String(resourceType),
+				EnabledRegions:  integration.EnabledRegions,
+				ExcludedRegions: integration.ExcludedRegions,
+				MaxResources:    maxResources,
+			},
+		},
+	}
+
+	messageBodyBytes, err := jsoniter.MarshalToString(scanMsg)
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: err.Error()}
+	}
+
+	return &sqs.SendMessageBatchRequestEntry{
+		// Generates an ID of: IntegrationID-AWSResourceType
+		Id: aws.String(
+			*integration.IntegrationID + "-" + strings.Replace(resourceType, ".", "", -1),
+		),
+		MessageBody: aws.String(messageBodyBytes),
+	}, nil
+}
+
+// healthCheckInputForNew is the configuration the health check of a new integration runs with.
+func healthCheckInputForNew(settings *models.PutIntegrationSettings) *models.CheckIntegrationInput {
+	return &models.CheckIntegrationInput{
+		AWSAccountID:        settings.AWSAccountID,
+		IntegrationType:     settings.IntegrationType,
+		EnableCWESetup:      settings.CWEEnabled,
+		EnableRemediation:   settings.RemediationEnabled,
+		S3Buckets:           settings.S3Buckets,
+		KmsKeys:             settings.KmsKeys,
+		S3BucketRegions:     settings.S3BucketRegions,
+		DisabledChecks:      settings.DisabledChecks,
+		IsOrgTrail:          settings.IsOrgTrail,
+		ManagementAccountID: settings.ManagementAccountID,
+		StreamARN:           settings.StreamARN,
+		DeadLetterQueueArn:  deadLetterQueueArn(settings.DeadLetterQueue),
+		ArchiveFormat:       settings.ArchiveFormat,
+		RoleChain:           settings.RoleChain,
+
+		AzureTenantID:        settings.AzureTenantID,
+		AzureSubscriptionID:  settings.AzureSubscriptionID,
+		AzureClientID:        settings.AzureClientID,
+		AzureClientSecretArn: settings.AzureClientSecretArn,
+
+		GCPProjectID:               settings.GCPProjectID,
+		GCPServiceAccountSecretArn: settings.GCPServiceAccountSecretArn,
+		GCSBucket:                  settings.GCSBucket,
+		PubSubSubscription:         settings.PubSubSubscription,
+
+		SQSQueueArn: settings.SQSQueueArn,
+
+		OktaDomain:  settings.OktaDomain,
+		Credentials: settings.Credentials,
+
+		SessionDurationSeconds: settings.SessionDurationSeconds,
+	}
 }
 
 func generateNewIntegration(input *models.PutIntegrationSettings) *models.SourceIntegrationMetadata {
@@ -203,13 +591,84 @@ func generateNewIntegration(input *models.PutIntegrationSettings) *models.Source
 		CreatedBy:          input.UserID,
 		IntegrationID:      aws.String(uuid.New().String()),
 		IntegrationLabel:   input.IntegrationLabel,
+		Description:        sanitizeDescription(input.Description),
 		IntegrationType:    input.IntegrationType,
 		ScanEnabled:        input.ScanEnabled,
 		CWEEnabled:         input.CWEEnabled,
 		RemediationEnabled: input.RemediationEnabled,
 		ScanIntervalMins:   input.ScanIntervalMins,
 		// For log analysis integrations
-		S3Buckets: input.S3Buckets,
-		KmsKeys:   input.KmsKeys,
+		S3Buckets:       input.S3Buckets,
+		KmsKeys:         input.KmsKeys,
+		S3BucketRegions: input.S3BucketRegions,
+		S3ObjectFilters: input.S3ObjectFilters,
+		// For Azure integrations
+		AzureTenantID:        input.AzureTenantID,
+		AzureSubscriptionID:  input.AzureSubscriptionID,
+		AzureClientID:        input.AzureClientID,
+		AzureClientSecretArn: input.AzureClientSecretArn,
+		// For GCP integrations
+		GCPProjectID:               input.GCPProjectID,
+		GCPServiceAccountSecretArn: input.GCPServiceAccountSecretArn,
+		GCSBucket:                  input.GCSBucket,
+		PubSubSubscription:         input.PubSubSubscription,
+		// For SQS integrations, the queue is created with the integration when it's not set
+		SQSQueueArn: input.SQSQueueArn,
+		// For Okta integrations, the API token is stored in the secret of the integration
+		OktaDomain: input.OktaDomain,
+		// For syslog integrations, the collector attributes the messages to them by sender
+		SyslogAllowedCIDRs: input.SyslogAllowedCIDRs,
+		// For aws-organization integrations, the member accounts are onboarded by SyncOrganizations
+		OrganizationExcludedAccounts: input.OrganizationExcludedAccounts,
+		OrganizationIntegrationID:    input.OrganizationIntegrationID,
+
+		MaxConcurrentObjects:  input.MaxConcurrentObjects,
+		MaxObjectsPerScan:     input.MaxObjectsPerScan,
+		OrderingMode:          input.OrderingMode,
+		MaxParallelism:        input.MaxParallelism,
+		DedupWindowMinutes:    input.DedupWindowMinutes,
+		MaxRecordBytes:        input.MaxRecordBytes,
+		OversizedRecordPolicy: input.OversizedRecordPolicy,
+		SampleRate:            input.SampleRate,
+		ErrorRateThreshold:    input.ErrorRateThreshold,
+
+		SilenceThresholdMinutes: input.SilenceThresholdMinutes,
+		EventErrorRateThreshold: input.EventErrorRateThreshold,
+
+		TimeExtraction:        input.TimeExtraction,
+		TimestampSkew:         input.TimestampSkew,
+		DeadLetterQueue:       input.DeadLetterQueue,
+		ArchiveFormat:         input.ArchiveFormat,
+		DataClassification:    input.DataClassification,
+		ProcessingRegion:      input.ProcessingRegion,
+		BlackoutWindows:       input.BlackoutWindows,
+		ScanSchedule:          input.ScanSchedule,
+		EnabledRegions:        input.EnabledRegions,
+		ExcludedRegions:       input.ExcludedRegions,
+		ResourceTypeAllowList: input.ResourceTypeAllowList,
+		ResourceTypeDenyList:  input.ResourceTypeDenyList,
+		RedactionRules:        input.RedactionRules,
+		IncludePatterns:       input.IncludePatterns,
+		ExcludePatterns:       input.ExcludePatterns,
+		DisabledChecks:        input.DisabledChecks,
+		FeatureFlags:          input.FeatureFlags,
+		ScanRampUp:            input.ScanRampUp,
+		CreateKmsGrants:       input.CreateKmsGrants,
+		IsOrgTrail:            input.IsOrgTrail,
+		ManagementAccountID:   input.ManagementAccountID,
+		StreamARN:             input.StreamARN,
+		RoleChain:             input.RoleChain,
+		ShardIteratorType:     input.ShardIteratorType,
+		BackfillStartTime:     input.BackfillStartTime,
+		DependsOn:             input.DependsOn,
+		NotificationTargets:   input.NotificationTargets,
+		EnrichmentSources:     input.EnrichmentSources,
+		Tags:                  input.Tags,
+		SeverityOverrides:     input.SeverityOverrides,
+
+		SessionDurationSeconds:  input.SessionDurationSeconds,
+		ScanCompleteCallbackURL: input.ScanCompleteCallbackURL,
+
+		Version: aws.Int64(1),
 	}
 }
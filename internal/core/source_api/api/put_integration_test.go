@@ -20,11 +20,14 @@ package api
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	jsoniter "github.com/json-iterator/go"
@@ -40,6 +43,7 @@ import (
 	awspoller "github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 // Mocks
@@ -151,7 +155,7 @@ func TestPutIntegration(t *testing.T) {
 	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
 	SQSClient = mockSQS
 	db = &ddb.DDB{Client: &modelstest.MockDDBClient{TestErr: false}, TableName: "test"}
-	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, error) { return true, nil }
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
 
 	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
 		Integrations: []*models.PutIntegrationSettings{
@@ -169,6 +173,70 @@ func TestPutIntegration(t *testing.T) {
 	require.NotEmpty(t, out)
 }
 
+// failingBatchPutClient can't write the integrations, the change records written before them are kept
+type failingBatchPutClient struct {
+	*modelstest.MockDDBClient
+	scans   int
+	changes []map[string]*dynamodb.AttributeValue
+}
+
+// Scan is throttled once, the remediation quota is checked first and then pending
+func (client *failingBatchPutClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	if client.scans++; client.scans == 1 {
+		return nil, awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil)
+	}
+	return client.MockDDBClient.Scan(input)
+}
+
+func (client *failingBatchPutClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if *input.TableName == "changes" {
+		client.changes = append(client.changes, input.Item)
+	}
+	return client.MockDDBClient.PutItem(input)
+}
+
+func (client *failingBatchPutClient) BatchWriteItem(*dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	return nil, errors.New("down")
+}
+
+func TestPutIntegrationBatchPutFailure(t *testing.T) {
+	remediationQuota = "5"
+	defer func() { remediationQuota = "" }()
+	mockClient := &failingBatchPutClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", ChangesTableName: "changes", RetriesTableName: "retries"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	result, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{{
+			AWSAccountID:       aws.String(testAccountID),
+			IntegrationLabel:   aws.String(testIntegrationLabel),
+			IntegrationType:    aws.String(testIntegrationType),
+			RemediationEnabled: aws.Bool(true),
+			ScanEnabled:        aws.Bool(true),
+			UserID:             aws.String(testUserID),
+		}},
+	})
+	assert.Nil(t, result)
+	require.Error(t, err)
+
+	// The change record and the retry of the quota are deleted along with the integration which wasn't created
+	require.Len(t, mockClient.changes, 1)
+	integrationID := mockClient.changes[0]["integrationId"]
+	mockClient.AssertCalled(t, "UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.TableName == "retries"
+	}))
+	mockClient.AssertCalled(t, "DeleteItem", &dynamodb.DeleteItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"integrationId": integrationID, "changeId": mockClient.changes[0]["changeId"]},
+		TableName: aws.String("changes"),
+	})
+	mockClient.AssertCalled(t, "DeleteItem", &dynamodb.DeleteItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"integrationId": integrationID, "sideEffect": {S: aws.String(sideEffectRemediation)}},
+		TableName: aws.String("retries"),
+	})
+}
+
 func TestPutIntegrationExists(t *testing.T) {
 	mockSQS := &mockSQSClient{}
 	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
@@ -203,6 +271,406 @@ func TestPutIntegrationExists(t *testing.T) {
 	require.Empty(t, out) // should do nothing
 }
 
+func TestPutIntegrationDuplicateLabel(t *testing.T) {
+	db = &ddb.DDB{
+		Client: &modelstest.MockDDBClient{
+			MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+				{
+					"awsAccountId":     {S: aws.String(testAccountID)},
+					"integrationType":  {S: aws.String(models.IntegrationTypeAWS3)},
+					"integrationLabel": {S: aws.String(testIntegrationLabel)},
+				},
+			},
+		},
+		TableName: "test",
+	}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:     aws.String(testAccountID),
+				IntegrationLabel: aws.String("PRODAWS"),
+				IntegrationType:  aws.String(testIntegrationType),
+				UserID:           aws.String(testUserID),
+			},
+		},
+	})
+	assert.Empty(t, out)
+	assert.IsType(t, &genericapi.ConflictError{}, err)
+}
+
+func TestPutIntegrationScanLimits(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	input := &models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:         aws.String(testAccountID),
+				IntegrationType:      aws.String(testIntegrationType),
+				UserID:               aws.String(testUserID),
+				MaxConcurrentObjects: aws.Int(10),
+				MaxObjectsPerScan:    aws.Int(5000),
+			},
+		},
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+
+	out, err := apiTest.PutIntegration(input)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, 10, *out[0].MaxConcurrentObjects)
+	assert.Equal(t, 5000, *out[0].MaxObjectsPerScan)
+
+	// The limits are stored with the integration
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.Equal(t, out[0].MaxConcurrentObjects, stored.MaxConcurrentObjects)
+	assert.Equal(t, out[0].MaxObjectsPerScan, stored.MaxObjectsPerScan)
+}
+
+func TestPutIntegrationScanLimitsOutOfRange(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	for _, settings := range []*models.PutIntegrationSettings{
+		{MaxConcurrentObjects: aws.Int(0)},
+		{MaxConcurrentObjects: aws.Int(101)},
+		{MaxObjectsPerScan: aws.Int(0)},
+		{MaxObjectsPerScan: aws.Int(1000001)},
+	} {
+		settings.AWSAccountID = aws.String(testAccountID)
+		settings.IntegrationType = aws.String(testIntegrationType)
+		settings.UserID = aws.String(testUserID)
+		assert.Error(t, validator.Struct(&models.PutIntegrationInput{
+			Integrations: []*models.PutIntegrationSettings{settings},
+		}))
+	}
+}
+
+func TestPutIntegrationDedupWindow(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	input := &models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:       aws.String(testAccountID),
+				IntegrationType:    aws.String(testIntegrationType),
+				UserID:             aws.String(testUserID),
+				DedupWindowMinutes: aws.Int(30),
+			},
+		},
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+
+	out, err := apiTest.PutIntegration(input)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, 30, *out[0].DedupWindowMinutes)
+
+	// The window is stored with the integration and read back
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.Equal(t, aws.Int(30), stored.DedupWindowMinutes)
+}
+
+func TestDedupWindowOutOfRange(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	for _, window := range []int{0, 10081} {
+		assert.Error(t, validator.Struct(&models.PutIntegrationInput{
+			Integrations: []*models.PutIntegrationSettings{{
+				AWSAccountID:       aws.String(testAccountID),
+				IntegrationType:    aws.String(testIntegrationType),
+				UserID:             aws.String(testUserID),
+				DedupWindowMinutes: aws.Int(window),
+			}},
+		}))
+		assert.Error(t, validator.Struct(&models.UpdateIntegrationSettingsInput{
+			IntegrationID:      aws.String(testIntegrationID),
+			DedupWindowMinutes: aws.Int(window),
+		}))
+	}
+	assert.NoError(t, validator.Struct(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		DedupWindowMinutes: aws.Int(10080),
+	}))
+}
+
+func TestPutIntegrationRecordSizeLimit(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	input := &models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:          aws.String(testAccountID),
+				IntegrationType:       aws.String(testIntegrationType),
+				UserID:                aws.String(testUserID),
+				MaxRecordBytes:        aws.Int(4096),
+				OversizedRecordPolicy: aws.String(models.OversizedRecordTruncate),
+			},
+		},
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+
+	out, err := apiTest.PutIntegration(input)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+
+	// The limit and its policy are stored with the integration and read back
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.Equal(t, aws.Int(4096), stored.MaxRecordBytes)
+	assert.Equal(t, aws.String(models.OversizedRecordTruncate), stored.OversizedRecordPolicy)
+}
+
+func TestOversizedRecordPolicyValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(maxRecordBytes int, policy string) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{
+			IntegrationID:         aws.String(testIntegrationID),
+			MaxRecordBytes:        aws.Int(maxRecordBytes),
+			OversizedRecordPolicy: aws.String(policy),
+		}
+	}
+
+	for _, policy := range []string{models.OversizedRecordDrop, models.OversizedRecordTruncate, models.OversizedRecordError} {
+		assert.NoError(t, validator.Struct(settings(1024, policy)))
+	}
+	assert.Error(t, validator.Struct(settings(1024, "split")))
+	assert.Error(t, validator.Struct(settings(1024, "")))
+	assert.Error(t, validator.Struct(settings(0, models.OversizedRecordDrop)))
+	assert.Error(t, validator.Struct(settings(10485761, models.OversizedRecordDrop)))
+}
+
+func TestLimitRecord(t *testing.T) {
+	record := []byte(`{"message": "too long"}`)
+	limited := func(policy *string) *models.SourceIntegrationMetadata {
+		return &models.SourceIntegrationMetadata{MaxRecordBytes: aws.Int(12), OversizedRecordPolicy: policy}
+	}
+
+	result, err := (&models.SourceIntegrationMetadata{}).LimitRecord(record)
+	require.NoError(t, err)
+	assert.Equal(t, record, result)
+	result, err = (&models.SourceIntegrationMetadata{MaxRecordBytes: aws.Int(len(record))}).LimitRecord(record)
+	require.NoError(t, err)
+	assert.Equal(t, record, result)
+
+	// Oversized records are dropped by default
+	result, err = limited(nil).LimitRecord(record)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	result, err = limited(aws.String(models.OversizedRecordTruncate)).LimitRecord(record)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"message": `), result)
+	_, err = limited(aws.String(models.OversizedRecordError)).LimitRecord(record)
+	assert.Error(t, err)
+}
+
+func TestPutIntegrationSampleRate(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	input := &models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:    aws.String(testAccountID),
+				IntegrationType: aws.String(testIntegrationType),
+				UserID:          aws.String(testUserID),
+				SampleRate:      aws.Float64(0.25),
+			},
+		},
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+
+	out, err := apiTest.PutIntegration(input)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, aws.Float64(0.25), out[0].SampleRate)
+
+	// The rate is stored with the integration and read back
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.Equal(t, aws.Float64(0.25), stored.SampleRate)
+}
+
+func TestSampleRateValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(rate float64) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID), SampleRate: aws.Float64(rate)}
+	}
+
+	for _, rate := range []float64{0, 0.001, 0.5, 1} {
+		assert.NoError(t, validator.Struct(settings(rate)))
+	}
+	assert.Error(t, validator.Struct(settings(-0.1)))
+	assert.Error(t, validator.Struct(settings(1.5)))
+}
+
+func TestSampleRecord(t *testing.T) {
+	// Without a rate, or with a rate of 1, every record is ingested
+	for _, integration := range []*models.SourceIntegrationMetadata{{}, {SampleRate: aws.Float64(1)}} {
+		assert.True(t, integration.SampleRecord(0))
+		assert.True(t, integration.SampleRecord(0.999999))
+	}
+
+	sampled := &models.SourceIntegrationMetadata{SampleRate: aws.Float64(0.25)}
+	assert.True(t, sampled.SampleRecord(0.1))
+	assert.False(t, sampled.SampleRecord(0.25))
+	assert.False(t, sampled.SampleRecord(0.9))
+	assert.False(t, (&models.SourceIntegrationMetadata{SampleRate: aws.Float64(0)}).SampleRecord(0))
+}
+
+func TestPutIntegrationFeatureFlags(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	input := &models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:    aws.String(testAccountID),
+				IntegrationType: aws.String(testIntegrationType),
+				UserID:          aws.String(testUserID),
+				FeatureFlags:    map[string]bool{models.FeatureFlagBetaEnrichment: true},
+			},
+		},
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+
+	out, err := apiTest.PutIntegration(input)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.True(t, out[0].FeatureEnabled(models.FeatureFlagBetaEnrichment))
+
+	// The flags are stored with the integration and read back
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.True(t, stored.FeatureEnabled(models.FeatureFlagBetaEnrichment))
+
+	// Unknown flags are rejected on creation too
+	input.Integrations[0].FeatureFlags["fasterParser"] = true
+	assert.Error(t, validator.Struct(input))
+}
+
+func TestPutIntegrationRedactionRules(t *testing.T) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	rules := []*models.RedactionRule{
+		{FieldPath: aws.String("userIdentity.principalId"), Strategy: aws.String(models.MaskStrategyHash)},
+		{FieldPath: aws.String("requestParameters.*.password"), Strategy: aws.String(models.MaskStrategyRemove)},
+	}
+	input := &models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:    aws.String(testAccountID),
+				IntegrationType: aws.String(testIntegrationType),
+				UserID:          aws.String(testUserID),
+				RedactionRules:  rules,
+			},
+		},
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+
+	out, err := apiTest.PutIntegration(input)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, rules, out[0].RedactionRules)
+
+	// The rules are stored with the integration and read back
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.Equal(t, rules, stored.RedactionRules)
+}
+
+func TestRedactionRulesValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(rules ...*models.RedactionRule) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID), RedactionRules: rules}
+	}
+	rule := func(path, strategy string) *models.RedactionRule {
+		return &models.RedactionRule{FieldPath: aws.String(path), Strategy: aws.String(strategy)}
+	}
+
+	assert.NoError(t, validator.Struct(settings(
+		rule("email", models.MaskStrategyRedact),
+		rule("user.*.ssn", models.MaskStrategyPartial),
+		rule("records.*", models.MaskStrategyHash),
+		rule("@metadata.client-ip", models.MaskStrategyRemove),
+	)))
+
+	for _, invalid := range []*models.RedactionRule{
+		rule("email", "encrypt"),
+		rule("email", ""),
+		rule("", models.MaskStrategyRedact),
+		rule("user..email", models.MaskStrategyRedact),
+		rule(".email", models.MaskStrategyRedact),
+		rule("user.", models.MaskStrategyRedact),
+		rule("user[0].email", models.MaskStrategyRedact),
+		rule("user email", models.MaskStrategyRedact),
+		rule(strings.Repeat("a", 257), models.MaskStrategyRedact),
+		{FieldPath: aws.String("email")},
+		nil,
+	} {
+		assert.Error(t, validator.Struct(settings(rule("valid", models.MaskStrategyRedact), invalid)))
+	}
+
+	tooMany := make([]*models.RedactionRule, 51)
+	for i := range tooMany {
+		tooMany[i] = rule("field", models.MaskStrategyRedact)
+	}
+	assert.Error(t, validator.Struct(settings(tooMany...)))
+}
+
 func TestPutIntegrationValidInput(t *testing.T) {
 	validator, err := models.Validator()
 	require.NoError(t, err)
@@ -366,3 +834,51 @@ func TestPutLogIntegrationUpdateSqsQueuePermissionsFailure(t *testing.T) {
 	require.Error(t, err)
 	require.Empty(t, out)
 }
+
+func TestPutIntegrationBackfillStartTime(t *testing.T) {
+	backfillStart := time.Now().UTC().Add(-30 * 24 * time.Hour)
+	settings := &models.PutIntegrationSettings{
+		AWSAccountID:      aws.String(testAccountID),
+		IntegrationType:   aws.String(models.IntegrationTypeAWS3),
+		UserID:            aws.String(testUserID),
+		BackfillStartTime: aws.Time(backfillStart),
+	}
+	assert.Equal(t, aws.Time(backfillStart), generateNewIntegration(settings).BackfillStartTime)
+
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{}, TableName: "test"}
+	settings.BackfillStartTime = aws.Time(time.Now().Add(time.Hour))
+	_, err := apiTest.PutIntegration(&models.PutIntegrationInput{Integrations: []*models.PutIntegrationSettings{settings}})
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "backfillStartTime: the backfill can't start in the future"}, err)
+}
+
+func TestCheckBackfillStartTime(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	logs := aws.String(models.IntegrationTypeAWS3)
+
+	assert.NoError(t, checkBackfillStartTime(logs, nil, now))
+	assert.NoError(t, checkBackfillStartTime(logs, aws.Time(now), now))
+	assert.NoError(t, checkBackfillStartTime(logs, aws.Time(now.AddDate(-1, 0, 0)), now))
+	assert.Error(t, checkBackfillStartTime(logs, aws.Time(now.Add(time.Second)), now))
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "only log analysis integrations have a backfill"},
+		checkBackfillStartTime(aws.String(models.IntegrationTypeAWSScan), aws.Time(now), now))
+}
+
+func TestBackfillIncludes(t *testing.T) {
+	backfillStart := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	integration := &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{BackfillStartTime: aws.Time(backfillStart)},
+	}
+
+	// The first scan only lists the objects modified since the backfill start
+	assert.False(t, integration.BackfillIncludes(backfillStart.Add(-time.Second)))
+	assert.True(t, integration.BackfillIncludes(backfillStart))
+	assert.True(t, integration.BackfillIncludes(backfillStart.Add(time.Hour)))
+
+	// Once the backfill completed it is ignored
+	integration.SourceIntegrationScanInformation = &models.SourceIntegrationScanInformation{CompletedScans: aws.Int(1)}
+	assert.True(t, integration.BackfillIncludes(backfillStart.AddDate(-1, 0, 0)))
+
+	// Without a backfill start, every object is listed
+	unbounded := &models.SourceIntegration{SourceIntegrationMetadata: &models.SourceIntegrationMetadata{}}
+	assert.True(t, unbounded.BackfillIncludes(backfillStart.AddDate(-1, 0, 0)))
+}
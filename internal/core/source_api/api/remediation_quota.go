@@ -0,0 +1,63 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkRemediationQuota rejects enabling the remediation of more integrations than the REMEDIATION_QUOTA of the deployment.
+//
+// The integration excluded is the one being updated, which shouldn't count against the quota it is checked for.
+// Without a quota any number of integrations can have remediation enabled. A listing which fails transiently is
+// a transientError, the quota is checked again in the background.
+func checkRemediationQuota(excludeIntegrationID *string, enabling int) error {
+	quota, err := strconv.Atoi(remediationQuota)
+	if err != nil || quota < 1 || enabling == 0 {
+		return nil
+	}
+
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil && isTransientError(err) {
+		return &transientError{sideEffect: sideEffectRemediation, message: "remediationEnabled: " + err.Error()}
+	}
+	if err != nil {
+		return err
+	}
+	enabled := 0
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil || !aws.BoolValue(integration.RemediationEnabled) {
+			continue
+		}
+		if excludeIntegrationID != nil && aws.StringValue(integration.IntegrationID) == *excludeIntegrationID {
+			continue
+		}
+		enabled++
+	}
+	if enabled+enabling > quota {
+		return &genericapi.InvalidInputError{Message: fmt.Sprintf(
+			"remediationEnabled: %d integrations already have remediation enabled, the quota is %d", enabled, quota)}
+	}
+	return nil
+}
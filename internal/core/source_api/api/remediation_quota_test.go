@@ -0,0 +1,91 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func remediationItem(integrationID string, enabled bool) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"integrationId":      {S: aws.String(integrationID)},
+		"integrationType":    {S: aws.String(models.IntegrationTypeAWSScan)},
+		"remediationEnabled": {BOOL: aws.Bool(enabled)},
+	}
+}
+
+func TestCheckRemediationQuota(t *testing.T) {
+	remediationQuota = "2"
+	defer func() { remediationQuota = "" }()
+	db = &ddb.DDB{
+		Client: &modelstest.MockDDBClient{
+			MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+				remediationItem("integration-1", true),
+				remediationItem("integration-2", false),
+				remediationItem(testIntegrationID, true),
+			},
+		},
+		TableName: "test",
+	}
+
+	// The integration updated doesn't count against the quota
+	assert.NoError(t, checkRemediationQuota(aws.String(testIntegrationID), 1))
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "remediationEnabled: 2 integrations already have remediation enabled, the quota is 2",
+	}, checkRemediationQuota(aws.String("integration-2"), 1))
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "remediationEnabled: 1 integrations already have remediation enabled, the quota is 2",
+	}, checkRemediationQuota(aws.String(testIntegrationID), 2))
+	assert.NoError(t, checkRemediationQuota(nil, 0))
+
+	remediationQuota = ""
+	assert.NoError(t, checkRemediationQuota(aws.String("integration-2"), 1))
+}
+
+func TestUpdateIntegrationSettingsRemediationQuota(t *testing.T) {
+	remediationQuota = "1"
+	defer func() { remediationQuota = "" }()
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:       aws.String(testAccountID),
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWSScan),
+		RemediationEnabled: aws.Bool(false),
+	})
+	mockClient.MockScanAttributes = []map[string]*dynamodb.AttributeValue{remediationItem("integration-1", true)}
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		RemediationEnabled: aws.Bool(true),
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "remediationEnabled: 1 integrations already have remediation enabled, the quota is 1",
+	}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
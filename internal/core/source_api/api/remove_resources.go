@@ -0,0 +1,252 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The prefixes of the failed health checks of the S3 buckets and of the KMS keys, see awsHealthChecker.Evaluate
+var (
+	bucketHealthChecks = []string{"s3Bucket:", "s3ObjectRead:", "s3ObjectDecrypt:", "s3Archive:", "s3BucketEncryption:", "orgTrailBucket:"}
+	keyHealthChecks    = []string{"kmsKey:"}
+)
+
+// RemoveBuckets removes S3 buckets from an integration without resending the full list.
+//
+// The remaining buckets are unchanged so a healthy integration needs no health check. When the integration failed
+// its last one, the remaining buckets which failed are checked again. Buckets which are not part of the
+// integration are ignored. The last bucket of an enabled integration can't be removed.
+func (api API) RemoveBuckets(input *models.RemoveBucketsInput) (*models.SourceIntegration, error) {
+	integration, err := getIntegrationForEdit(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := listIndices(integration.S3Buckets, input.S3Buckets)
+	if len(indices) == 0 {
+		return &models.SourceIntegration{SourceIntegrationMetadata: integration}, nil
+	}
+	if len(indices) == len(integration.S3Buckets) {
+		if err = checkBucketsRemain(integration, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	result, err := removeFromLists(input.IntegrationID, map[string]map[int]string{"s3Buckets": indices})
+	if err != nil {
+		return nil, err
+	}
+	if err = recordUpdate(input.IntegrationID, input.UserID, removalChanges("s3Buckets", indices)); err != nil {
+		return nil, err
+	}
+	return api.recheckRemaining(integration, result, bucketHealthChecks, func(check *models.CheckIntegrationInput, failed []*string) {
+		check.S3Buckets = failed
+	})
+}
+
+// RemoveKmsKeys removes KMS keys from an integration without resending the full list.
+//
+// Keys can be given by ARN or by the alias they were added with. Keys which are not part of the
+// integration are ignored. Like with RemoveBuckets, the remaining keys of an integration which failed its last
+// health check are checked again when they failed.
+func (api API) RemoveKmsKeys(input *models.RemoveKmsKeysInput) (*models.SourceIntegration, error) {
+	integration, err := getIntegrationForEdit(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace aliases with the key they were pinned to
+	keys := make([]*string, len(input.KmsKeys))
+	removedKeys := make(map[string]struct{}, len(input.KmsKeys))
+	for i, key := range input.KmsKeys {
+		keys[i] = key
+		if pinnedArn, ok := integration.KmsKeyAliases[*key]; ok {
+			keys[i] = pinnedArn
+		}
+		removedKeys[*keys[i]] = struct{}{}
+	}
+
+	// Forget the aliases pointing to a removed key
+	var aliasNames []string
+	for alias, pinnedArn := range integration.KmsKeyAliases {
+		if _, ok := removedKeys[aws.StringValue(pinnedArn)]; ok {
+			aliasNames = append(aliasNames, "kmsKeyAliases."+alias)
+		}
+	}
+
+	indices := listIndices(integration.KmsKeys, keys)
+	if len(indices) == 0 {
+		return &models.SourceIntegration{SourceIntegrationMetadata: integration}, nil
+	}
+	result, err := removeFromLists(input.IntegrationID, map[string]map[int]string{"kmsKeys": indices}, aliasNames...)
+	if err != nil {
+		return nil, err
+	}
+	if err = recordUpdate(input.IntegrationID, input.UserID, removalChanges("kmsKeys", indices)); err != nil {
+		return nil, err
+	}
+	return api.recheckRemaining(integration, result, keyHealthChecks, func(check *models.CheckIntegrationInput, failed []*string) {
+		check.S3Buckets, check.KmsKeys = nil, failed
+	})
+}
+
+// recheckRemaining re-runs the health sub-checks of the remaining resources which failed the last health check of an
+// integration, once some resources of the same list were removed.
+//
+// The stored result can't tell whether they still fail, so only the checks with one of the prefixes are run again,
+// for the resources restrict leaves in the input of the check. The failed checks of the removed resources are dropped
+// and the other failed checks are kept as they were. The new health is stored and notified like a re-check.
+func (api API) recheckRemaining(
+	before *models.SourceIntegrationMetadata, result *models.SourceIntegration, prefixes []string,
+	restrict func(check *models.CheckIntegrationInput, failed []*string),
+) (*models.SourceIntegration, error) {
+
+	if len(before.FailedHealthChecks) == 0 || result == nil || result.SourceIntegrationMetadata == nil {
+		return result, nil
+	}
+	after := result.SourceIntegrationMetadata
+	remaining := make(map[string]struct{}, len(after.S3Buckets)+len(after.KmsKeys))
+	for _, resource := range append(append([]*string{}, after.S3Buckets...), after.KmsKeys...) {
+		remaining[*resource] = struct{}{}
+	}
+
+	failedChecks := make([]*string, 0, len(before.FailedHealthChecks))
+	var failed []*string
+	rolesFailed, rechecked := false, make(map[string]struct{})
+	for _, check := range before.FailedHealthChecks {
+		resource, ok := checkedResource(*check, prefixes)
+		if !ok {
+			failedChecks = append(failedChecks, check)
+			rolesFailed = rolesFailed || *check == failedRolesCheck
+			continue
+		}
+		if _, ok := remaining[resource]; !ok {
+			continue
+		}
+		if _, ok := rechecked[resource]; !ok {
+			rechecked[resource] = struct{}{}
+			failed = append(failed, aws.String(resource))
+		}
+	}
+
+	if len(failed) > 0 {
+		check := healthCheckInputForUpdate(after, &models.UpdateIntegrationSettingsInput{IntegrationID: after.IntegrationID})
+		restrict(check, failed)
+		eval, err := evaluateIntegrationHealthFunc(api, check)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range eval.failedItems {
+			if _, ok := checkedResource(*item, prefixes); ok {
+				failedChecks = append(failedChecks, item)
+			}
+		}
+		if !eval.rolesHealthy && !rolesFailed {
+			failedChecks, rolesFailed = append(failedChecks, aws.String(failedRolesCheck)), true
+		}
+	}
+	sort.Slice(failedChecks, func(i, j int) bool { return *failedChecks[i] < *failedChecks[j] })
+
+	healthStatus := before.HealthStatus
+	switch {
+	case len(failedChecks) == 0:
+		healthStatus = aws.String(models.HealthStatusHealthy)
+	case rolesFailed:
+		healthStatus = aws.String(models.HealthStatusUnhealthy)
+	}
+	_, err := db.UpdateItem(&ddb.UpdateIntegrationItem{
+		IntegrationID:       after.IntegrationID,
+		HealthStatus:        healthStatus,
+		FailedHealthChecks:  failedChecks,
+		LastHealthyTime:     lastHealthyTime(healthStatus),
+		LastHealthCheckTime: aws.Time(time.Now()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = recordHistory(&models.IntegrationHistoryRecord{
+		IntegrationID:      after.IntegrationID,
+		Kind:               aws.String(models.HistoryKindHealth),
+		HealthStatus:       healthStatus,
+		FailedHealthChecks: failedChecks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = notifyHealthChange(before, healthStatus, failedChecks); err != nil {
+		return nil, err
+	}
+	after.HealthStatus, after.FailedHealthChecks = healthStatus, failedChecks
+	return result, nil
+}
+
+// checkedResource returns the resource of a failed health check which has one of the prefixes.
+func checkedResource(check string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(check, prefix) {
+			return strings.TrimPrefix(check, prefix), true
+		}
+	}
+	return "", false
+}
+
+func getIntegrationForEdit(integrationID *string) (*models.SourceIntegrationMetadata, error) {
+	integration, err := db.GetIntegration(integrationID, true)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	return integration, nil
+}
+
+// listIndices returns the index => value of every element of the list which is one of the values to remove.
+func listIndices(list []*string, remove []*string) map[int]string {
+	toRemove := make(map[string]struct{}, len(remove))
+	for _, value := range remove {
+		toRemove[*value] = struct{}{}
+	}
+
+	indices := make(map[int]string)
+	for i, value := range list {
+		if _, ok := toRemove[aws.StringValue(value)]; ok {
+			indices[i] = *value
+		}
+	}
+	return indices
+}
+
+func removeFromLists(
+	integrationID *string, elements map[string]map[int]string, removeNames ...string) (*models.SourceIntegration, error) {
+
+	result, err := db.RemoveFromList(integrationID, elements, removeNames...)
+	if _, ok := err.(*ddb.ConditionalCheckFailedError); ok {
+		return nil, &genericapi.ConflictError{Message: "integration was modified concurrently, please retry"}
+	}
+	return result, err
+}
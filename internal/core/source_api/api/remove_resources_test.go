@@ -0,0 +1,253 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func getLogIntegrationItem() *dynamodb.GetItemOutput {
+	return &dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"integrationId":   {S: aws.String(testIntegrationID)},
+			"integrationType": {S: aws.String(models.IntegrationTypeAWS3)},
+			"awsAccountId":    {S: aws.String(testAccountID)},
+			"s3Buckets": {L: []*dynamodb.AttributeValue{
+				{S: aws.String("bucket-1")},
+				{S: aws.String("bucket-2")},
+			}},
+			"kmsKeys": {L: []*dynamodb.AttributeValue{
+				{S: aws.String(testKeyArn)},
+			}},
+			"kmsKeyAliases": {M: map[string]*dynamodb.AttributeValue{
+				"alias/logs": {S: aws.String(testKeyArn)},
+			}},
+		},
+	}
+}
+
+func TestRemoveBuckets(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", Clock: func() time.Time { return now }}
+	mockClient.On("GetItem", mock.Anything).Return(getLogIntegrationItem(), nil)
+
+	name := expression.Name("s3Buckets[1]")
+	expr, err := expression.NewBuilder().
+		WithUpdate(expression.Remove(name).Add(expression.Name("version"), expression.Value(1)).
+			Set(expression.Name("lastModifiedAt"), expression.Value(ddb.ModifiedTime(now))).
+			Set(expression.Name("modifiedFeed"), expression.Value("integrations"))).
+		WithCondition(name.Equal(expression.Value("bucket-2"))).
+		Build()
+	require.NoError(t, err)
+	expected := &dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			"integrationId": {S: aws.String(testIntegrationID)},
+		},
+		ReturnValues:     aws.String("ALL_NEW"),
+		TableName:        aws.String("test"),
+		UpdateExpression: expr.Update(),
+	}
+	mockClient.On("UpdateItem", expected).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	result, err := apiTest.RemoveBuckets(&models.RemoveBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-2"}),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestRemoveBucketsMissing(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getLogIntegrationItem(), nil)
+
+	result, err := apiTest.RemoveBuckets(&models.RemoveBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-does-not-exist"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bucket-1", "bucket-2"}, aws.StringValueSlice(result.S3Buckets))
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
+func TestRemoveBucketsEveryBucket(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getLogIntegrationItem(), nil)
+
+	result, err := apiTest.RemoveBuckets(&models.RemoveBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-1", "bucket-2"}),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestRemoveKmsKeysByAlias(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getLogIntegrationItem(), nil)
+
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	_, err := apiTest.RemoveKmsKeys(&models.RemoveKmsKeysInput{
+		IntegrationID: aws.String(testIntegrationID),
+		KmsKeys:       aws.StringSlice([]string{"alias/logs"}),
+	})
+	require.NoError(t, err)
+
+	// Both the pinned key and its alias are removed, and the write is stamped
+	require.NotNil(t, update)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.ElementsMatch(t, []string{"kmsKeys", "kmsKeyAliases", "alias/logs", "version", "lastModifiedAt", "modifiedFeed"}, names)
+	mockClient.AssertExpectations(t)
+}
+
+func TestRemoveBucketsConcurrentEdit(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getLogIntegrationItem(), nil)
+	conditionErr := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", errors.New("fake"))
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, conditionErr)
+
+	result, err := apiTest.RemoveBuckets(&models.RemoveBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-1"}),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.ConflictError{}, err)
+}
+
+// mockFailedIntegration stores a degraded integration whose buckets and key failed their last health check, the
+// removal returns the remaining buckets and keys.
+func mockFailedIntegration(remainingBuckets, remainingKeys []string) *modelstest.MockDDBClient {
+	item := getLogIntegrationItem()
+	item.Item["healthStatus"] = &dynamodb.AttributeValue{S: aws.String(models.HealthStatusDegraded)}
+	item.Item["failedHealthChecks"] = &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+		{S: aws.String("kmsKey:" + testKeyArn)},
+		{S: aws.String("s3Bucket:bucket-1")},
+		{S: aws.String("s3ObjectRead:bucket-2")},
+	}}
+	buckets, keys := make([]*dynamodb.AttributeValue, len(remainingBuckets)), make([]*dynamodb.AttributeValue, len(remainingKeys))
+	for i, bucket := range remainingBuckets {
+		buckets[i] = &dynamodb.AttributeValue{S: aws.String(bucket)}
+	}
+	for i, key := range remainingKeys {
+		keys[i] = &dynamodb.AttributeValue{S: aws.String(key)}
+	}
+
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(item, nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"integrationId":   {S: aws.String(testIntegrationID)},
+		"integrationType": {S: aws.String(models.IntegrationTypeAWS3)},
+		"awsAccountId":    {S: aws.String(testAccountID)},
+		"s3Buckets":       {L: buckets},
+		"kmsKeys":         {L: keys},
+	}}, nil)
+	return mockClient
+}
+
+func TestRemoveBucketsRechecksFailedBuckets(t *testing.T) {
+	mockClient := mockFailedIntegration([]string{"bucket-1"}, []string{testKeyArn})
+	var checked *models.CheckIntegrationInput
+	evaluateHealthFunc := evaluateIntegrationHealthFunc
+	defer func() { evaluateIntegrationHealthFunc = evaluateHealthFunc }()
+	evaluateIntegrationHealthFunc = func(_ API, input *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+		checked = input
+		return &integrationEvaluation{rolesHealthy: true, failedItems: make([]*string, 0)}, nil
+	}
+
+	result, err := apiTest.RemoveBuckets(&models.RemoveBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-2"}),
+	})
+	require.NoError(t, err)
+
+	// Only the remaining bucket which failed is checked again, the removed one's failure is dropped
+	require.NotNil(t, checked)
+	assert.Equal(t, []string{"bucket-1"}, aws.StringValueSlice(checked.S3Buckets))
+	assert.Equal(t, models.HealthStatusDegraded, *result.HealthStatus)
+	assert.Equal(t, []string{"kmsKey:" + testKeyArn}, aws.StringValueSlice(result.FailedHealthChecks))
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 2)
+}
+
+func TestRemoveBucketsRecheckStillFailing(t *testing.T) {
+	mockFailedIntegration([]string{"bucket-1"}, []string{testKeyArn})
+	evaluateHealthFunc := evaluateIntegrationHealthFunc
+	defer func() { evaluateIntegrationHealthFunc = evaluateHealthFunc }()
+	evaluateIntegrationHealthFunc = func(_ API, input *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+		// Failures of the checks which weren't asked for are ignored
+		return &integrationEvaluation{rolesHealthy: true, failedItems: aws.StringSlice([]string{
+			"kmsKey:other", "s3Bucket:bucket-1"})}, nil
+	}
+
+	result, err := apiTest.RemoveBuckets(&models.RemoveBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"bucket-2"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kmsKey:" + testKeyArn, "s3Bucket:bucket-1"}, aws.StringValueSlice(result.FailedHealthChecks))
+}
+
+func TestRemoveKmsKeysRecheck(t *testing.T) {
+	mockFailedIntegration([]string{"bucket-1", "bucket-2"}, nil)
+	evaluateHealthFunc := evaluateIntegrationHealthFunc
+	defer func() { evaluateIntegrationHealthFunc = evaluateHealthFunc }()
+	evaluateIntegrationHealthFunc = func(_ API, input *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+		return nil, errors.New("no key remains failing, nothing should be checked")
+	}
+
+	result, err := apiTest.RemoveKmsKeys(&models.RemoveKmsKeysInput{
+		IntegrationID: aws.String(testIntegrationID),
+		KmsKeys:       aws.StringSlice([]string{"alias/logs"}),
+	})
+	require.NoError(t, err)
+	// The removed key passes, the bucket failures are kept as they were
+	assert.Equal(t, []string{"s3Bucket:bucket-1", "s3ObjectRead:bucket-2"}, aws.StringValueSlice(result.FailedHealthChecks))
+}
@@ -0,0 +1,76 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// ReplaceBuckets swaps the whole set of S3 buckets of a log analysis integration for a new one.
+//
+// The new buckets are checked like an update of the settings, and a failed health check leaves the current
+// buckets as they are. The swap is only written if the buckets weren't changed since they were checked,
+// otherwise it is rejected with a PreconditionFailedError and nothing is written.
+func (api API) ReplaceBuckets(input *models.ReplaceBucketsInput) (*models.SourceIntegration, error) {
+	api = api.withIntegration(input.IntegrationID)
+
+	integration, err := getIntegrationForEdit(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWS3 {
+		return nil, &genericapi.InvalidInputError{Message: "only log analysis integrations have buckets"}
+	}
+
+	prepared, err := api.prepareUpdate(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:            input.IntegrationID,
+		S3Buckets:                input.S3Buckets,
+		AllowCrossAccountBuckets: input.AllowCrossAccountBuckets,
+		DelegatingAccountID:      input.DelegatingAccountID,
+		AllowPartialHealth:       input.AllowPartialHealth,
+		UserID:                   input.UserID,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The buckets which were checked are the ones replaced
+	buckets := expression.Name("s3Buckets")
+	condition := expression.AttributeNotExists(buckets)
+	if len(prepared.integration.S3Buckets) > 0 {
+		condition = buckets.Equal(expression.Value(prepared.integration.S3Buckets))
+	}
+	result, err := db.UpdateItemWithCondition(prepared.item, condition)
+	if err != nil {
+		prepared.grants.discard()
+	}
+	if _, ok := err.(*ddb.ConditionalCheckFailedError); ok {
+		return nil, &genericapi.PreconditionFailedError{
+			Message: "the buckets of the integration changed while they were being replaced"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prepared.written(result)
+}
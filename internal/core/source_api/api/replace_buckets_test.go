@@ -0,0 +1,127 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func mockBucketsIntegration(t *testing.T) *modelstest.MockDDBClient {
+	mockBucketOwnership(t)
+	return mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"old-logs-1", "old-logs-2"}),
+	})
+}
+
+func TestReplaceBuckets(t *testing.T) {
+	mockClient := mockBucketsIntegration(t)
+	var checked []*string
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = input.S3Buckets
+		return true, nil, nil
+	}
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"integrationId": {S: aws.String(testIntegrationID)},
+		"s3Buckets":     {L: []*dynamodb.AttributeValue{{S: aws.String("new-logs")}}},
+	}}, nil).Run(func(args mock.Arguments) {
+		if update == nil {
+			update = args.Get(0).(*dynamodb.UpdateItemInput)
+		}
+	})
+
+	result, err := apiTest.ReplaceBuckets(&models.ReplaceBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"new-logs"}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"new-logs"}), result.S3Buckets)
+	assert.Equal(t, aws.StringSlice([]string{"new-logs"}), checked)
+
+	// The swap is conditioned on the buckets which were read
+	require.NotNil(t, update.ConditionExpression)
+	var lists [][]string
+	for _, value := range update.ExpressionAttributeValues {
+		var list []string
+		for _, element := range value.L {
+			list = append(list, aws.StringValue(element.S))
+		}
+		lists = append(lists, list)
+	}
+	assert.Contains(t, lists, []string{"old-logs-1", "old-logs-2"})
+	assert.Contains(t, lists, []string{"new-logs"})
+}
+
+func TestReplaceBucketsFailedHealthCheck(t *testing.T) {
+	mockClient := mockBucketsIntegration(t)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return false, nil, nil }
+
+	result, err := apiTest.ReplaceBuckets(&models.ReplaceBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"new-logs"}),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	// The old buckets are left intact
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestReplaceBucketsChangedConcurrently(t *testing.T) {
+	mockClient := mockBucketsIntegration(t)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "the conditional request failed", nil))
+
+	result, err := apiTest.ReplaceBuckets(&models.ReplaceBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"new-logs"}),
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, &genericapi.PreconditionFailedError{
+		Message: "the buckets of the integration changed while they were being replaced"}, err)
+}
+
+func TestReplaceBucketsNotLogAnalysis(t *testing.T) {
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+	})
+
+	_, err := apiTest.ReplaceBuckets(&models.ReplaceBucketsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     aws.StringSlice([]string{"new-logs"}),
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "only log analysis integrations have buckets"}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
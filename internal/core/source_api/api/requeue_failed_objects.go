@@ -0,0 +1,151 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/awsbatch/sqsbatch"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// Longer time ranges are only requeued with confirmation
+	maxUnconfirmedRequeueRange = 24 * time.Hour
+
+	// The log processor only reads the account of the topic, to read the objects with the log processing role
+	requeueTopicName = "panther-requeue-failed-objects"
+)
+
+// RequeueFailedObjects re-submits the objects scans of an integration failed on in a time range to the log processor.
+//
+// The failed objects are taken from the scan error samples, so only the most recent failures can be requeued.
+// Each object is sent once, like the notifications of its bucket would have delivered it.
+func (API) RequeueFailedObjects(input *models.RequeueFailedObjectsInput) (*models.RequeueFailedObjectsOutput, error) {
+	if !input.EndTime.After(*input.StartTime) {
+		return nil, &genericapi.InvalidInputError{Message: "endTime must be after startTime"}
+	}
+	if input.EndTime.Sub(*input.StartTime) > maxUnconfirmedRequeueRange && !aws.BoolValue(input.Confirm) {
+		return nil, &genericapi.InvalidInputError{
+			Message: fmt.Sprintf("the time range is longer than %s, set confirm to requeue it", maxUnconfirmedRequeueRange)}
+	}
+
+	integration, err := db.GetIntegration(input.IntegrationID, false)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWS3 {
+		return nil, &genericapi.InvalidInputError{Message: "only the objects of log analysis integrations can be requeued"}
+	}
+	samples, err := db.GetScanErrorSamples(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	topicArn := fmt.Sprintf("arn:aws:sns:%s:%s:%s", aws.StringValue(sess.Config.Region), *integration.AWSAccountID, requeueTopicName)
+	requeued := make(map[string]struct{})
+	var sqsEntries []*sqs.SendMessageBatchRequestEntry
+	skipped := 0
+	for _, sample := range samples {
+		if sample.ObjectKey == nil || sample.ObservedAt == nil ||
+			sample.ObservedAt.Before(*input.StartTime) || !sample.ObservedAt.Before(*input.EndTime) {
+
+			continue
+		}
+		key := *sample.ObjectKey
+		if _, ok := requeued[key]; ok {
+			continue
+		}
+		requeued[key] = struct{}{}
+
+		bucket := failedObjectBucket(integration, key)
+		if bucket == "" {
+			zap.L().Warn("failed object doesn't match any bucket of the integration",
+				zap.String("integrationId", *integration.IntegrationID), zap.String("key", key))
+			skipped++
+			continue
+		}
+		body, err := requeueNotification(topicArn, bucket, key)
+		if err != nil {
+			return nil, &genericapi.InternalError{Message: err.Error()}
+		}
+		sqsEntries = append(sqsEntries, &sqs.SendMessageBatchRequestEntry{
+			Id:          aws.String(strconv.Itoa(len(sqsEntries))),
+			MessageBody: aws.String(body),
+		})
+	}
+
+	if len(sqsEntries) > 0 {
+		zap.L().Info("requeueing failed objects",
+			zap.String("integrationId", *integration.IntegrationID), zap.Int("count", len(sqsEntries)))
+		err = sqsbatch.SendMessageBatch(SQSClient, maxElapsedTime, &sqs.SendMessageBatchInput{
+			Entries:  sqsEntries,
+			QueueUrl: aws.String(regionalQueueURL(logProcessorQueueURL, integration)),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &models.RequeueFailedObjectsOutput{
+		RequeuedCount: aws.Int(len(sqsEntries)),
+		SkippedCount:  aws.Int(skipped),
+	}, nil
+}
+
+// failedObjectBucket is the first bucket of the integration with a pattern matching the key, "" if there is none.
+func failedObjectBucket(integration *models.SourceIntegrationMetadata, key string) string {
+	for _, entry := range integration.S3Buckets {
+		bucket, pattern := parseBucketEntry(aws.StringValue(entry))
+		if patternRegexp(pattern).MatchString(key) {
+			return bucket
+		}
+	}
+	return ""
+}
+
+// requeueNotification is the SNS notification of the S3 event of an object, which the log processor reads it for.
+func requeueNotification(topicArn, bucket, key string) (string, error) {
+	event := events.S3Event{
+		Records: []events.S3EventRecord{{
+			EventSource: "aws:s3",
+			EventName:   "ObjectCreated:Put",
+			S3: events.S3Entity{
+				Bucket: events.S3Bucket{Name: bucket},
+				Object: events.S3Object{Key: key},
+			},
+		}},
+	}
+	message, err := jsoniter.MarshalToString(event)
+	if err != nil {
+		return "", err
+	}
+	return jsoniter.MarshalToString(events.SNSEntity{Type: "Notification", TopicArn: topicArn, Message: message})
+}
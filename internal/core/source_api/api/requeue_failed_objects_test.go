@@ -0,0 +1,143 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var requeueStart = time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// The integration and its error samples are returned by the same item
+func mockFailedObjects(t *testing.T, samples []*models.ScanErrorSample) {
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"cloudtrail-logs", "app-logs/app/*"}),
+	})
+	require.NoError(t, err)
+	item["scanErrorSamples"], err = dynamodbattribute.Marshal(samples)
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+}
+
+func failedObject(key string, observedAt time.Time) *models.ScanErrorSample {
+	sample := errorSample(key)
+	sample.ObservedAt = &observedAt
+	return sample
+}
+
+// Returns the bucket/key of each object in the notifications sent to the log processor
+func requeuedObjects(t *testing.T, input *sqs.SendMessageBatchInput) []string {
+	var objects []string
+	for _, entry := range input.Entries {
+		var notification events.SNSEntity
+		require.NoError(t, jsoniter.UnmarshalFromString(*entry.MessageBody, &notification))
+		assert.Equal(t, "Notification", notification.Type)
+		assert.Contains(t, notification.TopicArn, ":"+testAccountID+":")
+		var event events.S3Event
+		require.NoError(t, jsoniter.UnmarshalFromString(notification.Message, &event))
+		for _, record := range event.Records {
+			objects = append(objects, record.S3.Bucket.Name+"/"+record.S3.Object.Key)
+		}
+	}
+	return objects
+}
+
+func TestRequeueFailedObjects(t *testing.T) {
+	mockFailedObjects(t, []*models.ScanErrorSample{
+		failedObject("before.json", requeueStart.Add(-time.Minute)),
+		failedObject("AWSLogs/trail.json.gz", requeueStart),
+		failedObject("app/events.json", requeueStart.Add(time.Hour)),
+		// Another record of an object which is already requeued
+		failedObject("app/events.json", requeueStart.Add(2*time.Hour)),
+		// The end of the range is excluded
+		failedObject("after.json", requeueStart.Add(6*time.Hour)),
+	})
+	mockSQS := &mockSQSClient{}
+	SQSClient = mockSQS
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+
+	result, err := apiTest.RequeueFailedObjects(&models.RequeueFailedObjectsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StartTime:     aws.Time(requeueStart),
+		EndTime:       aws.Time(requeueStart.Add(6 * time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.RequeueFailedObjectsOutput{RequeuedCount: aws.Int(2), SkippedCount: aws.Int(0)}, result)
+	mockSQS.AssertNumberOfCalls(t, "SendMessageBatch", 1)
+	input := mockSQS.Calls[0].Arguments.Get(0).(*sqs.SendMessageBatchInput)
+	assert.Equal(t, []string{"cloudtrail-logs/AWSLogs/trail.json.gz", "cloudtrail-logs/app/events.json"}, requeuedObjects(t, input))
+}
+
+func TestRequeueFailedObjectsNoneInRange(t *testing.T) {
+	mockFailedObjects(t, []*models.ScanErrorSample{failedObject("before.json", requeueStart.Add(-time.Minute))})
+	mockSQS := &mockSQSClient{}
+	SQSClient = mockSQS
+
+	result, err := apiTest.RequeueFailedObjects(&models.RequeueFailedObjectsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StartTime:     aws.Time(requeueStart),
+		EndTime:       aws.Time(requeueStart.Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, *result.RequeuedCount)
+	mockSQS.AssertNotCalled(t, "SendMessageBatch", mock.Anything)
+}
+
+func TestRequeueFailedObjectsRequiresConfirmation(t *testing.T) {
+	mockFailedObjects(t, []*models.ScanErrorSample{failedObject("AWSLogs/trail.json.gz", requeueStart.Add(48*time.Hour))})
+	mockSQS := &mockSQSClient{}
+	SQSClient = mockSQS
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	input := &models.RequeueFailedObjectsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StartTime:     aws.Time(requeueStart),
+		EndTime:       aws.Time(requeueStart.Add(72 * time.Hour)),
+	}
+
+	result, err := apiTest.RequeueFailedObjects(input)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockSQS.AssertNotCalled(t, "SendMessageBatch", mock.Anything)
+
+	input.Confirm = aws.Bool(true)
+	result, err = apiTest.RequeueFailedObjects(input)
+	require.NoError(t, err)
+	assert.Equal(t, 1, *result.RequeuedCount)
+}
@@ -0,0 +1,132 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// RestoreIntegration restores an integration which was soft-deleted, before its retention expires.
+//
+// The access the deletion removed is given back: the queue permission of its account, its KMS grants, the
+// mapping of the queue of an SQS integration to the log processor, the API key of an http-ingest integration
+// and the stream consumer of a Kinesis integration. The new key is returned with the integration.
+func (api API) RestoreIntegration(input *models.RestoreIntegrationInput) (*models.SourceIntegration, error) {
+	api = api.withIntegration(input.IntegrationID)
+
+	integration, err := db.RestoreIntegrationItem(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	metadata := integration.SourceIntegrationMetadata
+
+	if *metadata.IntegrationType == models.IntegrationTypeAWS3 {
+		// The queue already allows the accounts which have other log integrations
+		err = AddPermissionToLogProcessorQueue(*metadata.AWSAccountID)
+		if _, ok := errors.Cause(err).(*genericapi.AlreadyExistsError); err != nil && !ok {
+			api.log().Error("failed to add SQS permission for restored integration", zap.Error(err))
+			return nil, &genericapi.InternalError{Message: "failed to restore integration"}
+		}
+	}
+	if *metadata.IntegrationType == models.IntegrationTypeSQSQueue {
+		if err = reprovisionSQSQueue(metadata); err != nil {
+			return nil, err
+		}
+		_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{
+			IntegrationID:           input.IntegrationID,
+			SQSEventSourceMappingID: metadata.SQSEventSourceMappingID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if *metadata.IntegrationType == models.IntegrationTypeHTTPIngest {
+		// The deleted key can't be restored, the integration gets a new one
+		if err = provisionHTTPIngestKey(metadata); err != nil {
+			return nil, err
+		}
+		_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{
+			IntegrationID:      input.IntegrationID,
+			HTTPIngestAPIKeyID: metadata.HTTPIngestAPIKeyID,
+		})
+		if err != nil {
+			deleteHTTPIngestKey(input.IntegrationID, metadata.HTTPIngestAPIKeyID)
+			return nil, err
+		}
+	}
+	if *metadata.IntegrationType == models.IntegrationTypeAWSKinesis {
+		// The checkpoints are kept, reading resumes where it stopped if the stream still has the records
+		if err = registerKinesisConsumer(metadata); err != nil {
+			return nil, err
+		}
+		_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{
+			IntegrationID:      input.IntegrationID,
+			KinesisConsumerARN: metadata.KinesisConsumerARN,
+		})
+		if err != nil {
+			deregisterKinesisConsumer(metadata, metadata.KinesisConsumerARN)
+			return nil, err
+		}
+	}
+	if len(metadata.KmsKeys) > 0 && aws.BoolValue(metadata.CreateKmsGrants) {
+		err = reconcileKmsGrants(metadata)
+		if transientErr, ok := err.(*transientError); ok {
+			err = enqueueRetry(input.IntegrationID, transientErr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if integration, err = refreshScanSchedule(integration); err != nil {
+		return nil, err
+	}
+	err = recordChange(input.IntegrationID, models.ChangeOperationRestored, input.UserID, make([]*models.IntegrationFieldChange, 0))
+	if err != nil {
+		return nil, err
+	}
+	return integration, nil
+}
+
+// PurgeDeletedIntegrations drops the settings of the soft-deleted integrations whose retention expired, they can
+// no longer be restored.
+func (API) PurgeDeletedIntegrations(*models.PurgeDeletedIntegrationsInput) (*models.PurgedIntegrations, error) {
+	purged, err := db.PurgeSoftDeletedIntegrations()
+	if len(purged) > 0 {
+		zap.L().Info("purged soft-deleted integrations", zap.Int("purged", len(purged)))
+	}
+	// The secret is named after the integration, it is left to be deleted with the rest of the settings
+	for _, integrationID := range purged {
+		deleteCredentials(&models.SourceIntegrationMetadata{
+			IntegrationID:        integrationID,
+			CredentialsSecretArn: aws.String(credentialsSecretPrefix + *integrationID),
+		}, false)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &models.PurgedIntegrations{IntegrationIDs: purged}, nil
+}
@@ -0,0 +1,171 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// restorableUntil returns the time a soft delete keeps the integration restorable until.
+func restorableUntil(t *testing.T, input *dynamodb.UpdateItemInput) time.Time {
+	for name, value := range input.ExpressionAttributeNames {
+		if *value == "restorableUntil" && strings.Contains(*input.UpdateExpression, name+" = ") {
+			placeholder := strings.SplitN(strings.SplitN(*input.UpdateExpression, name+" = ", 2)[1], ",", 2)[0]
+			restorable, err := time.Parse(time.RFC3339, *input.ExpressionAttributeValues[strings.TrimSpace(placeholder)].S)
+			require.NoError(t, err)
+			return restorable
+		}
+	}
+	require.Fail(t, "restorableUntil is not set")
+	return time.Time{}
+}
+
+func TestDeleteIntegrationSoftDelete(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	err := apiTest.DeleteIntegration(&models.DeleteIntegrationInput{
+		IntegrationID: aws.String(testIntegrationID),
+		SoftDelete:    aws.Bool(true),
+		RetentionDays: aws.Int(3),
+	})
+	require.NoError(t, err)
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.Anything)
+
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *update.UpdateExpression, "REMOVE")
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 3), restorableUntil(t, update), time.Minute)
+}
+
+func TestDeleteIntegrationSoftDeleteDefaultRetention(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	err := apiTest.DeleteIntegration(&models.DeleteIntegrationInput{
+		IntegrationID: aws.String(testIntegrationID),
+		SoftDelete:    aws.Bool(true),
+	})
+	require.NoError(t, err)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, defaultSoftDeleteRetentionDays), restorableUntil(t, update), time.Minute)
+}
+
+func TestRestoreIntegration(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"integrationId":    {S: aws.String(testIntegrationID)},
+			"integrationType":  {S: aws.String(models.IntegrationTypeAWSScan)},
+			"integrationLabel": {S: aws.String(testIntegrationLabel)},
+		},
+	}, nil)
+
+	result, err := apiTest.RestoreIntegration(&models.RestoreIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, testIntegrationLabel, *result.IntegrationLabel)
+
+	update := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Contains(t, *update.UpdateExpression, "REMOVE")
+	assert.Contains(t, *update.ConditionExpression, "attribute_exists")
+}
+
+func TestRestoreIntegrationNotRestorable(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+
+	result, err := apiTest.RestoreIntegration(&models.RestoreIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+// mockPurgeClient lists the soft-deleted integrations to purge, the integration restored in the meantime
+// fails the condition of its marker.
+type mockPurgeClient struct {
+	*modelstest.MockDDBClient
+	restoredID string
+	puts       []*dynamodb.PutItemInput
+}
+
+func (client *mockPurgeClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	client.puts = append(client.puts, input)
+	if *input.Item["integrationId"].S == client.restoredID {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestPurgeDeletedIntegrations(t *testing.T) {
+	client := &mockPurgeClient{
+		MockDDBClient: &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+			{
+				"integrationId":   {S: aws.String(firstPageIntegrationID)},
+				"integrationType": {S: aws.String(models.IntegrationTypeAWS3)},
+				"s3Buckets":       {L: []*dynamodb.AttributeValue{{S: aws.String("bucket")}}},
+				"deleted":         {BOOL: aws.Bool(true)},
+				"restorableUntil": {S: aws.String("2020-01-01T00:00:00.000Z")},
+			},
+			{
+				"integrationId":   {S: aws.String(secondPageIntegrationID)},
+				"deleted":         {BOOL: aws.Bool(true)},
+				"restorableUntil": {S: aws.String("2020-01-01T00:00:00.000Z")},
+			},
+		}},
+		restoredID: secondPageIntegrationID,
+	}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+	// The purged integration had no credentials
+	mockSecrets := mockCredentials(t)
+	mockSecrets.On("DeleteSecret", mock.Anything).Return(
+		(*secretsmanager.DeleteSecretOutput)(nil), awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil))
+
+	result, err := apiTest.PurgeDeletedIntegrations(&models.PurgeDeletedIntegrationsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{firstPageIntegrationID}), result.IntegrationIDs)
+
+	require.Len(t, client.puts, 2)
+	marker := client.puts[0].Item
+	assert.Equal(t, models.IntegrationTypeAWS3, *marker["integrationType"].S)
+	assert.True(t, *marker["deleted"].BOOL)
+	assert.NotContains(t, marker, "s3Buckets")
+	assert.NotContains(t, marker, "restorableUntil")
+	deleteInput := mockSecrets.Calls[0].Arguments.Get(0).(*secretsmanager.DeleteSecretInput)
+	assert.Equal(t, "panther-source-credentials-"+firstPageIntegrationID, *deleteInput.SecretId)
+}
@@ -0,0 +1,232 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The side effects which are retried in the background when they fail transiently
+const (
+	sideEffectKmsGrants = "kmsGrants"
+	// The verification of the enablement of CWE and remediation, see checkEventDelivery and checkRemediationQuota
+	sideEffectCWE         = "cweEnablement"
+	sideEffectRemediation = "remediationEnablement"
+)
+
+// The delay before the first retry of a side effect, it doubles after every failed attempt up to retryMaxDelay
+var (
+	retryBaseDelay = time.Minute
+	retryMaxDelay  = 6 * time.Hour
+)
+
+// retryableSideEffects reconcile an integration with its current settings.
+//
+// A retry doesn't replay the change which enqueued it, so a retry enqueued by an older change converges to the
+// latest settings, and enqueuing the same side effect twice is harmless.
+var retryableSideEffects = map[string]func(*models.SourceIntegrationMetadata) error{
+	sideEffectKmsGrants:   reconcileKmsGrants,
+	sideEffectCWE:         reconcileEventDelivery,
+	sideEffectRemediation: reconcileRemediationQuota,
+}
+
+// transientError is a failed side effect which may succeed later, e.g. because it was throttled.
+type transientError struct {
+	sideEffect string
+	message    string
+}
+
+func (e *transientError) Error() string {
+	return e.message
+}
+
+// isTransientError returns true if the same request may be accepted later: it was throttled, AWS failed
+// to process it, or it didn't reach AWS.
+func isTransientError(err error) bool {
+	if awsErr, ok := err.(*genericapi.AWSError); ok {
+		err = awsErr.Err
+	}
+	if isThrottlingError(err) || request.IsErrorRetryable(err) {
+		return true
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// enqueueRetry schedules the retry of a side effect of an integration which failed transiently.
+func enqueueRetry(integrationID *string, cause *transientError) error {
+	zap.L().Warn("side effect failed transiently, it will be retried",
+		zap.String("sideEffect", cause.sideEffect),
+		zap.String("integrationId", aws.StringValue(integrationID)),
+		zap.Error(cause))
+	now := time.Now()
+	return runSideEffect(sideEffectRetry, integrationID, func() error {
+		return db.EnqueueRetry(integrationID, cause.sideEffect, cause.Error(), now, now.Add(retryBaseDelay))
+	})
+}
+
+// ListPendingRetries returns the side effects which failed and are still being retried.
+func (API) ListPendingRetries(input *models.ListPendingRetriesInput) ([]*models.PendingRetry, error) {
+	return db.ListRetries(input.IntegrationID)
+}
+
+// RetryFailedSideEffects attempts every pending retry which is due.
+//
+// A side effect which succeeds is no longer pending: the integration is reconciled. One which fails again is
+// attempted after a longer backoff. A retry whose outcome can't be stored is logged and counted as pending,
+// the other retries are still attempted.
+func (API) RetryFailedSideEffects(*models.RetryFailedSideEffectsInput) (*models.SideEffectRetries, error) {
+	retries, err := db.ListRetries(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	attempted, reconciled, pending := 0, 0, 0
+	for _, retry := range retries {
+		if retry.NextAttemptTime != nil && retry.NextAttemptTime.After(now) {
+			pending++
+			continue
+		}
+
+		attempted++
+		if err := retrySideEffect(retry); err != nil {
+			attempts := aws.IntValue(retry.Attempts) + 1
+			zap.L().Warn("side effect retry failed",
+				zap.String("sideEffect", *retry.SideEffect),
+				zap.String("integrationId", *retry.IntegrationID),
+				zap.Int("attempts", attempts),
+				zap.Error(err))
+			retry.Attempts = aws.Int(attempts)
+			retry.LastError = aws.String(err.Error())
+			retry.NextAttemptTime = aws.Time(now.Add(retryBackoff(attempts)))
+			if err := db.PutRetry(retry); err != nil {
+				zap.L().Error("failed to store the side effect retry",
+					zap.String("sideEffect", *retry.SideEffect), zap.String("integrationId", *retry.IntegrationID), zap.Error(err))
+			}
+			pending++
+			continue
+		}
+
+		zap.L().Info("side effect reconciled",
+			zap.String("sideEffect", *retry.SideEffect), zap.String("integrationId", *retry.IntegrationID))
+		if err := db.DeleteRetry(retry.IntegrationID, *retry.SideEffect); err != nil {
+			// The retry is attempted again, reconciling an integration twice is harmless
+			zap.L().Error("failed to delete the reconciled side effect retry",
+				zap.String("sideEffect", *retry.SideEffect), zap.String("integrationId", *retry.IntegrationID), zap.Error(err))
+			pending++
+			continue
+		}
+		reconciled++
+	}
+
+	return &models.SideEffectRetries{
+		Attempted:  aws.Int(attempted),
+		Reconciled: aws.Int(reconciled),
+		Pending:    aws.Int(pending),
+	}, nil
+}
+
+// retrySideEffect reconciles the integration of a retry, there is nothing to do once the integration is deleted.
+func retrySideEffect(retry *models.PendingRetry) error {
+	reconcile, ok := retryableSideEffects[aws.StringValue(retry.SideEffect)]
+	if !ok {
+		return fmt.Errorf("unknown side effect %s", aws.StringValue(retry.SideEffect))
+	}
+	integration, err := db.GetIntegration(retry.IntegrationID, true)
+	if err != nil {
+		return err
+	}
+	if integration == nil {
+		return nil
+	}
+	return reconcile(integration)
+}
+
+// retryBackoff is the delay before the next attempt of a side effect which failed the given number of times.
+func retryBackoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 0; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// reconcileKmsGrants creates the missing grants of the keys of an integration, or retires them once it no longer
+// uses grants. The grants are retired only once the ones which remain are stored.
+func reconcileKmsGrants(integration *models.SourceIntegrationMetadata) error {
+	keys := integration.KmsKeys
+	if !aws.BoolValue(integration.CreateKmsGrants) {
+		keys = nil
+	}
+	grants, err := syncKmsGrants(integration.AWSAccountID, integration.IntegrationID, keys, integration.KmsGrants)
+	if err != nil {
+		return err
+	}
+	if _, err = db.UpdateItem(&ddb.UpdateIntegrationItem{IntegrationID: integration.IntegrationID, KmsGrants: grants.result()}); err != nil {
+		grants.discard()
+		return err
+	}
+	grants.written(integration.IntegrationID)
+	return nil
+}
+
+// reconcileEventDelivery verifies the CloudWatch events of an integration whose CWE was enabled while the delivery
+// couldn't be verified. CWE is disabled again when the account doesn't deliver its events.
+func reconcileEventDelivery(integration *models.SourceIntegrationMetadata) error {
+	if !aws.BoolValue(integration.CWEEnabled) {
+		return nil
+	}
+	err := checkEventDelivery(integration.AWSAccountID)
+	if _, ok := err.(*genericapi.InvalidInputError); ok {
+		zap.L().Warn("disabling the CWE of the integration", zap.String("integrationId", *integration.IntegrationID), zap.Error(err))
+		_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{IntegrationID: integration.IntegrationID, CWEEnabled: aws.Bool(false)})
+	}
+	return err
+}
+
+// reconcileRemediationQuota checks the quota of an integration whose remediation was enabled while the quota
+// couldn't be checked. Remediation is disabled again when the quota is exceeded.
+func reconcileRemediationQuota(integration *models.SourceIntegrationMetadata) error {
+	if !aws.BoolValue(integration.RemediationEnabled) {
+		return nil
+	}
+	err := checkRemediationQuota(integration.IntegrationID, 1)
+	if _, ok := err.(*genericapi.InvalidInputError); ok {
+		zap.L().Warn("disabling the remediation of the integration", zap.String("integrationId", *integration.IntegrationID), zap.Error(err))
+		_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{IntegrationID: integration.IntegrationID, RemediationEnabled: aws.Bool(false)})
+	}
+	return err
+}
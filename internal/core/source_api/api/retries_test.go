@@ -0,0 +1,331 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testRetriesTable = "test-retries"
+
+// mockRetriesClient records the retries which are put, the mock client doesn't keep them
+type mockRetriesClient struct {
+	*modelstest.MockDDBClient
+	put []*models.PendingRetry
+}
+
+func (client *mockRetriesClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	var retry models.PendingRetry
+	if err := dynamodbattribute.UnmarshalMap(input.Item, &retry); err != nil {
+		return nil, err
+	}
+	client.put = append(client.put, &retry)
+	return client.MockDDBClient.PutItem(input)
+}
+
+func onTable(table string) interface{} {
+	return mock.MatchedBy(func(input interface{}) bool {
+		switch input := input.(type) {
+		case *dynamodb.UpdateItemInput:
+			return *input.TableName == table
+		case *dynamodb.DeleteItemInput:
+			return *input.TableName == table
+		}
+		return false
+	})
+}
+
+func mockPendingRetries(t *testing.T, integration *models.SourceIntegrationMetadata, retries ...*models.PendingRetry) *mockRetriesClient {
+	mockClient := &mockRetriesClient{MockDDBClient: mockStoredIntegration(t, integration)}
+	for _, retry := range retries {
+		item, err := dynamodbattribute.MarshalMap(retry)
+		require.NoError(t, err)
+		mockClient.MockScanAttributes = append(mockClient.MockScanAttributes, item)
+	}
+	mockClient.MockQueryAttributes = mockClient.MockScanAttributes
+	db = &ddb.DDB{Client: mockClient, TableName: "test", RetriesTableName: testRetriesTable}
+	return mockClient
+}
+
+func grantsIntegration() *models.SourceIntegrationMetadata {
+	return &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		AWSAccountID:    aws.String(testAccountID),
+		S3Buckets:       aws.StringSlice([]string{"bucket-1"}),
+		KmsKeys:         aws.StringSlice([]string{testKeyArn}),
+		CreateKmsGrants: aws.Bool(true),
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, isTransientError(awserr.New("ThrottlingException", "rate exceeded", nil)))
+	assert.True(t, isTransientError(awserr.NewRequestFailure(awserr.New("KMSInternalException", "internal", nil), 500, "")))
+	assert.False(t, isTransientError(awserr.New("AccessDeniedException", "not authorized", nil)))
+	assert.False(t, isTransientError(awserr.NewRequestFailure(awserr.New("NotFoundException", "no key", nil), 400, "")))
+	// The errors of the table of the integrations are wrapped
+	assert.True(t, isTransientError(&genericapi.AWSError{Err: awserr.New("ThrottlingException", "rate exceeded", nil)}))
+}
+
+func TestTransientKmsGrantFailureIsRetried(t *testing.T) {
+	// The grant cannot be created while KMS fails
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("CreateGrant", grantFor(testKeyArn)).Return(&kms.CreateGrantOutput{},
+		awserr.NewRequestFailure(awserr.New("KMSInternalException", "internal error", nil), 500, "")).Once()
+	mockClient := mockPendingRetries(t, grantsIntegration())
+	mockClient.On("UpdateItem", onTable("test")).Return(&dynamodb.UpdateItemOutput{}, nil)
+	var enqueued *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", onTable(testRetriesTable)).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { enqueued = args.Get(0).(*dynamodb.UpdateItemInput) })
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	// The update succeeds, the grant is left to the retry
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		CreateKmsGrants: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, enqueued)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"integrationId": {S: aws.String(testIntegrationID)},
+		"sideEffect":    {S: aws.String(sideEffectKmsGrants)},
+	}, enqueued.Key)
+	// Enqueuing again doesn't reset the attempts of a pending retry
+	assert.Equal(t, 3, strings.Count(*enqueued.UpdateExpression, "if_not_exists"))
+
+	// KMS recovered by the time the retry is due
+	mockKMS.On("CreateGrant", grantFor(testKeyArn)).Return(&kms.CreateGrantOutput{GrantId: aws.String("grant-1")}, nil).Once()
+	mockClient = mockPendingRetries(t, grantsIntegration(), &models.PendingRetry{
+		IntegrationID:   aws.String(testIntegrationID),
+		SideEffect:      aws.String(sideEffectKmsGrants),
+		EnqueuedAt:      aws.Time(time.Now().Add(-retryBaseDelay)),
+		Attempts:        aws.Int(0),
+		NextAttemptTime: aws.Time(time.Now()),
+		LastError:       aws.String("failed to create a KMS grant"),
+	})
+	var reconciled *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", onTable("test")).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { reconciled = args.Get(0).(*dynamodb.UpdateItemInput) })
+	mockClient.On("DeleteItem", onTable(testRetriesTable)).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	result, err := apiTest.RetryFailedSideEffects(&models.RetryFailedSideEffectsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.SideEffectRetries{Attempted: aws.Int(1), Reconciled: aws.Int(1), Pending: aws.Int(0)}, result)
+	require.NotNil(t, reconciled)
+	var values []*dynamodb.AttributeValue
+	for _, value := range reconciled.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{
+		M: map[string]*dynamodb.AttributeValue{testKeyArn: {S: aws.String("grant-1")}}})
+	assert.Empty(t, mockClient.put)
+	mockClient.AssertExpectations(t)
+	mockKMS.AssertExpectations(t)
+}
+
+func TestRetryFailedSideEffectsBackoff(t *testing.T) {
+	mockKMS := &mockKMSClient{}
+	mockHealthCheckClients(&mockSTSClient{}, &mockS3Client{}, mockKMS)
+	mockKMS.On("CreateGrant", grantFor(testKeyArn)).Return(&kms.CreateGrantOutput{},
+		awserr.New("ThrottlingException", "rate exceeded", nil))
+	mockClient := mockPendingRetries(t, grantsIntegration(),
+		&models.PendingRetry{
+			IntegrationID:   aws.String(testIntegrationID),
+			SideEffect:      aws.String(sideEffectKmsGrants),
+			Attempts:        aws.Int(2),
+			NextAttemptTime: aws.Time(time.Now().Add(-time.Minute)),
+		},
+		// Not due yet
+		&models.PendingRetry{
+			IntegrationID:   aws.String("a1b2c3d4-0000-4000-8000-000000000000"),
+			SideEffect:      aws.String(sideEffectKmsGrants),
+			Attempts:        aws.Int(1),
+			NextAttemptTime: aws.Time(time.Now().Add(time.Hour)),
+		})
+
+	start := time.Now()
+	result, err := apiTest.RetryFailedSideEffects(&models.RetryFailedSideEffectsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.SideEffectRetries{Attempted: aws.Int(1), Reconciled: aws.Int(0), Pending: aws.Int(2)}, result)
+	require.Len(t, mockClient.put, 1)
+	retry := mockClient.put[0]
+	assert.Equal(t, 3, *retry.Attempts)
+	assert.Contains(t, *retry.LastError, "rate exceeded")
+	assert.False(t, retry.NextAttemptTime.Before(start.Add(retryBackoff(3))))
+	mockClient.AssertNotCalled(t, "DeleteItem", mock.Anything)
+}
+
+func TestRetryFailedSideEffectsDeletedIntegration(t *testing.T) {
+	mockClient := &mockRetriesClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	item, err := dynamodbattribute.MarshalMap(&models.PendingRetry{
+		IntegrationID: aws.String(testIntegrationID),
+		SideEffect:    aws.String(sideEffectKmsGrants),
+	})
+	require.NoError(t, err)
+	mockClient.MockScanAttributes = []map[string]*dynamodb.AttributeValue{item}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", RetriesTableName: testRetriesTable}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+	mockClient.On("DeleteItem", onTable(testRetriesTable)).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	// There is nothing left to reconcile
+	result, err := apiTest.RetryFailedSideEffects(&models.RetryFailedSideEffectsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *result.Reconciled)
+	mockClient.AssertExpectations(t)
+}
+
+func TestRetryFailedSideEffectsStoreFailure(t *testing.T) {
+	mockClient := &mockRetriesClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	for _, integrationID := range []string{testIntegrationID, "a1b2c3d4-0000-4000-8000-000000000000"} {
+		item, err := dynamodbattribute.MarshalMap(&models.PendingRetry{
+			IntegrationID: aws.String(integrationID),
+			SideEffect:    aws.String(sideEffectKmsGrants),
+		})
+		require.NoError(t, err)
+		mockClient.MockScanAttributes = append(mockClient.MockScanAttributes, item)
+	}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", RetriesTableName: testRetriesTable}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+	mockClient.On("DeleteItem", onTable(testRetriesTable)).
+		Return(&dynamodb.DeleteItemOutput{}, awserr.New("ThrottlingException", "rate exceeded", nil)).Once()
+	mockClient.On("DeleteItem", onTable(testRetriesTable)).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	// The retry which can't be deleted stays pending, the next one is still attempted
+	result, err := apiTest.RetryFailedSideEffects(&models.RetryFailedSideEffectsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.SideEffectRetries{Attempted: aws.Int(2), Reconciled: aws.Int(1), Pending: aws.Int(1)}, result)
+	mockClient.AssertExpectations(t)
+}
+
+// throttledScanClient fails the scans of the integrations while it's throttled
+type throttledScanClient struct {
+	*mockRetriesClient
+	throttled bool
+}
+
+func (client *throttledScanClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	if client.throttled && *input.TableName == "test" {
+		return nil, awserr.New("ThrottlingException", "rate exceeded", nil)
+	}
+	return client.mockRetriesClient.Scan(input)
+}
+
+func TestTransientRemediationQuotaIsRetried(t *testing.T) {
+	remediationQuota = "1"
+	defer func() { remediationQuota = "" }()
+	integration := &models.SourceIntegrationMetadata{
+		AWSAccountID:       aws.String(testAccountID),
+		IntegrationID:      aws.String(testIntegrationID),
+		IntegrationType:    aws.String(models.IntegrationTypeAWSScan),
+		RemediationEnabled: aws.Bool(false),
+	}
+	mockClient := &throttledScanClient{mockRetriesClient: mockPendingRetries(t, integration), throttled: true}
+	db.Client = mockClient
+	mockClient.On("UpdateItem", onTable("test")).Return(&dynamodb.UpdateItemOutput{}, nil)
+	var enqueued *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", onTable(testRetriesTable)).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { enqueued = args.Get(0).(*dynamodb.UpdateItemInput) })
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	// The quota can't be checked, remediation is enabled and the quota is left to the retry
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		RemediationEnabled: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, enqueued)
+	assert.Equal(t, sideEffectRemediation, *enqueued.Key["sideEffect"].S)
+
+	assert.Contains(t, retryableSideEffects, sideEffectRemediation)
+}
+
+func TestReconcileRemediationQuota(t *testing.T) {
+	remediationQuota = "1"
+	defer func() { remediationQuota = "" }()
+	integration := &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(testIntegrationID),
+		RemediationEnabled: aws.Bool(true),
+	}
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+
+	// Within the quota there is nothing to do
+	mockClient.MockScanAttributes = []map[string]*dynamodb.AttributeValue{remediationItem(testIntegrationID, true)}
+	require.NoError(t, reconcileRemediationQuota(integration))
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+
+	// Another integration took the quota meanwhile, the remediation is disabled again
+	mockClient.MockScanAttributes = append(mockClient.MockScanAttributes, remediationItem("integration-1", true))
+	var disabled *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { disabled = args.Get(0).(*dynamodb.UpdateItemInput) })
+	require.NoError(t, reconcileRemediationQuota(integration))
+	require.NotNil(t, disabled)
+	var names []string
+	for _, name := range disabled.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "remediationEnabled")
+	var values []*dynamodb.AttributeValue
+	for _, value := range disabled.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{BOOL: aws.Bool(false)})
+
+	// A quota which still can't be checked is retried later
+	mockClient.TestErr = true
+	assert.Error(t, reconcileRemediationQuota(integration))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, retryBaseDelay, retryBackoff(0))
+	assert.Equal(t, 4*retryBaseDelay, retryBackoff(2))
+	assert.Equal(t, retryMaxDelay, retryBackoff(100))
+}
+
+func TestListPendingRetries(t *testing.T) {
+	retry := &models.PendingRetry{
+		IntegrationID: aws.String(testIntegrationID),
+		SideEffect:    aws.String(sideEffectKmsGrants),
+		Attempts:      aws.Int(1),
+		LastError:     aws.String("rate exceeded"),
+	}
+	mockPendingRetries(t, grantsIntegration(), retry)
+
+	result, err := apiTest.ListPendingRetries(&models.ListPendingRetriesInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.PendingRetry{retry}, result)
+}
@@ -0,0 +1,265 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// rolePolicy is a policy of a role, inline or attached, as IAM returns it.
+type rolePolicy struct {
+	name     string
+	document iamPolicyDocument
+}
+
+// iamPolicyDocument is the part of an IAM policy the permissions advisory inspects.
+type iamPolicyDocument struct {
+	Statement iamStatements
+}
+
+type iamStatement struct {
+	Effect      string
+	Action      stringOrList
+	NotAction   stringOrList
+	Resource    stringOrList
+	NotResource stringOrList
+}
+
+// iamStatements are the statements of a policy, IAM allows a single statement instead of a list.
+type iamStatements []iamStatement
+
+func (statements *iamStatements) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		var statement iamStatement
+		if err := json.Unmarshal(data, &statement); err != nil {
+			return err
+		}
+		*statements = iamStatements{statement}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]iamStatement)(statements))
+}
+
+// stringOrList is an element of a statement, IAM allows a single string instead of a list.
+type stringOrList []string
+
+func (list *stringOrList) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*list = stringOrList{value}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(list))
+}
+
+// checkRolePolicy compares the policies of the role the logs are read with to the least privileged policy.
+//
+// The check is advisory, its status is always informational. The onboarding template doesn't allow the role
+// to read its own policies (iam:ListRolePolicies, iam:GetRolePolicy, iam:ListAttachedRolePolicies, iam:GetPolicy
+// and iam:GetPolicyVersion), the check is inconclusive until the customer grants them.
+//
+// Statements with NotAction or NotResource are taken as granting every action or every resource, and
+// conditions are ignored.
+func checkRolePolicy(roleCredentials *credentials.Credentials, roleARN string,
+	input *models.CheckIntegrationInput) (models.SourceIntegrationItemStatus, []*models.ExcessPermission) {
+
+	start := time.Now()
+	policies, err := rolePolicies(iamClientFunc(roleCredentials), roleARN[strings.LastIndex(roleARN, "/")+1:])
+	if err != nil {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			Inconclusive:  aws.Bool(true),
+			Informational: aws.Bool(true),
+			ErrorMessage:  aws.String("failed to read the policies of the role: " + err.Error()),
+			LatencyMillis: millisSince(start),
+		}, nil
+	}
+
+	needed := logProcessingPolicy(input.S3Buckets, input.KmsKeys, len(input.KmsGrants) > 0, aws.BoolValue(input.IsOrgTrail))
+	addDeadLetterQueueStatement(&needed, input.DeadLetterQueueArn)
+	excess := excessPermissions(policies, needed)
+	if len(excess) == 0 {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(true),
+			Informational: aws.Bool(true),
+			LatencyMillis: millisSince(start),
+		}, nil
+	}
+	return models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(false),
+		Informational: aws.Bool(true),
+		ErrorMessage:  aws.String(fmt.Sprintf("the role grants %d permissions Panther doesn't need", len(excess))),
+		LatencyMillis: millisSince(start),
+	}, excess
+}
+
+// rolePolicies returns the inline and the attached policies of a role.
+func rolePolicies(iamClient iamiface.IAMAPI, roleName string) ([]*rolePolicy, error) {
+	var policies []*rolePolicy
+	inline := &iam.ListRolePoliciesInput{RoleName: aws.String(roleName)}
+	for {
+		page, err := iamClient.ListRolePolicies(inline)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range page.PolicyNames {
+			policy, err := iamClient.GetRolePolicy(&iam.GetRolePolicyInput{RoleName: inline.RoleName, PolicyName: name})
+			if err != nil {
+				return nil, err
+			}
+			document, err := parsePolicyDocument(policy.PolicyDocument)
+			if err != nil {
+				return nil, err
+			}
+			policies = append(policies, &rolePolicy{name: *name, document: document})
+		}
+		if !aws.BoolValue(page.IsTruncated) {
+			break
+		}
+		inline.Marker = page.Marker
+	}
+
+	attached := &iam.ListAttachedRolePoliciesInput{RoleName: aws.String(roleName)}
+	for {
+		page, err := iamClient.ListAttachedRolePolicies(attached)
+		if err != nil {
+			return nil, err
+		}
+		for _, attachedPolicy := range page.AttachedPolicies {
+			policy, err := iamClient.GetPolicy(&iam.GetPolicyInput{PolicyArn: attachedPolicy.PolicyArn})
+			if err != nil {
+				return nil, err
+			}
+			version, err := iamClient.GetPolicyVersion(&iam.GetPolicyVersionInput{
+				PolicyArn: attachedPolicy.PolicyArn,
+				VersionId: policy.Policy.DefaultVersionId,
+			})
+			if err != nil {
+				return nil, err
+			}
+			document, err := parsePolicyDocument(version.PolicyVersion.Document)
+			if err != nil {
+				return nil, err
+			}
+			policies = append(policies, &rolePolicy{name: *attachedPolicy.PolicyName, document: document})
+		}
+		if !aws.BoolValue(page.IsTruncated) {
+			break
+		}
+		attached.Marker = page.Marker
+	}
+	return policies, nil
+}
+
+// parsePolicyDocument parses a policy document returned by IAM, which is URL encoded.
+func parsePolicyDocument(encoded *string) (iamPolicyDocument, error) {
+	var document iamPolicyDocument
+	decoded, err := url.PathUnescape(aws.StringValue(encoded))
+	if err != nil {
+		return document, err
+	}
+	err = json.Unmarshal([]byte(decoded), &document)
+	return document, err
+}
+
+// excessPermissions lists the actions on resources the policies allow, which the needed policy doesn't.
+//
+// A granted permission is only covered if the needed policy allows it as a whole, e.g. "s3:Get*" is in
+// excess even though "s3:GetObject" is needed.
+func excessPermissions(policies []*rolePolicy, needed policyDocument) []*models.ExcessPermission {
+	excess := make([]*models.ExcessPermission, 0)
+	// The same permission may be granted by several statements of a policy
+	seen := make(map[[3]string]struct{})
+	for _, policy := range policies {
+		for _, statement := range policy.document.Statement {
+			if statement.Effect != "Allow" {
+				continue
+			}
+			actions, resources := []string(statement.Action), []string(statement.Resource)
+			if len(statement.NotAction) > 0 {
+				actions = []string{"*"}
+			}
+			if len(statement.NotResource) > 0 {
+				resources = []string{"*"}
+			}
+			for _, action := range actions {
+				for _, resource := range resources {
+					if permissionNeeded(needed, action, resource) {
+						continue
+					}
+					key := [3]string{policy.name, action, resource}
+					if _, ok := seen[key]; ok {
+						continue
+					}
+					seen[key] = struct{}{}
+					excess = append(excess, &models.ExcessPermission{
+						Policy: aws.String(policy.name), Action: aws.String(action), Resource: aws.String(resource)})
+				}
+			}
+		}
+	}
+	return excess
+}
+
+// permissionNeeded returns true if a statement of the needed policy allows the action on the resource.
+//
+// Actions are case insensitive, the wildcards of the needed resources match the ones of the granted resource.
+func permissionNeeded(needed policyDocument, action, resource string) bool {
+	for _, statement := range needed.Statement {
+		actionNeeded := false
+		for _, neededAction := range statement.Action {
+			if strings.EqualFold(neededAction, action) {
+				actionNeeded = true
+				break
+			}
+		}
+		if !actionNeeded {
+			continue
+		}
+		for _, neededResource := range statement.Resource {
+			if wildcardPattern(neededResource).MatchString(resource) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wildcardPattern compiles an IAM resource pattern, where * matches any characters and ? a single one.
+func wildcardPattern(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("^" + quoted + "$")
+}
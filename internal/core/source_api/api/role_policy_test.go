@@ -0,0 +1,178 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+type mockIAMClient struct {
+	iamiface.IAMAPI
+	mock.Mock
+}
+
+func (client *mockIAMClient) ListRolePolicies(input *iam.ListRolePoliciesInput) (*iam.ListRolePoliciesOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*iam.ListRolePoliciesOutput), args.Error(1)
+}
+
+func (client *mockIAMClient) GetRolePolicy(input *iam.GetRolePolicyInput) (*iam.GetRolePolicyOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*iam.GetRolePolicyOutput), args.Error(1)
+}
+
+func (client *mockIAMClient) ListAttachedRolePolicies(
+	input *iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*iam.ListAttachedRolePoliciesOutput), args.Error(1)
+}
+
+func (client *mockIAMClient) GetPolicy(input *iam.GetPolicyInput) (*iam.GetPolicyOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*iam.GetPolicyOutput), args.Error(1)
+}
+
+func (client *mockIAMClient) GetPolicyVersion(input *iam.GetPolicyVersionInput) (*iam.GetPolicyVersionOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*iam.GetPolicyVersionOutput), args.Error(1)
+}
+
+// mockRolePolicies makes the log processing role have the given inline policy and attached policy.
+func mockRolePolicies(inline, attached string) *mockIAMClient {
+	roleName := aws.String("PantherLogProcessingRole")
+	policyARN := aws.String("arn:aws:iam::aws:policy/AmazonS3ReadOnlyAccess")
+	client := &mockIAMClient{}
+	client.On("ListRolePolicies", &iam.ListRolePoliciesInput{RoleName: roleName}).
+		Return(&iam.ListRolePoliciesOutput{PolicyNames: aws.StringSlice([]string{"ReadData"})}, nil)
+	client.On("GetRolePolicy", &iam.GetRolePolicyInput{RoleName: roleName, PolicyName: aws.String("ReadData")}).
+		Return(&iam.GetRolePolicyOutput{PolicyDocument: aws.String(url.PathEscape(inline))}, nil)
+	client.On("ListAttachedRolePolicies", &iam.ListAttachedRolePoliciesInput{RoleName: roleName}).
+		Return(&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []*iam.AttachedPolicy{
+			{PolicyName: aws.String("AmazonS3ReadOnlyAccess"), PolicyArn: policyARN},
+		}}, nil)
+	client.On("GetPolicy", &iam.GetPolicyInput{PolicyArn: policyARN}).
+		Return(&iam.GetPolicyOutput{Policy: &iam.Policy{DefaultVersionId: aws.String("v2")}}, nil)
+	client.On("GetPolicyVersion", &iam.GetPolicyVersionInput{PolicyArn: policyARN, VersionId: aws.String("v2")}).
+		Return(&iam.GetPolicyVersionOutput{PolicyVersion: &iam.PolicyVersion{Document: aws.String(url.PathEscape(attached))}}, nil)
+	return client
+}
+
+func TestCheckIntegrationOverBroadRolePolicy(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	mockIAM := mockRolePolicies(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Effect": "Allow", "Action": ["s3:GetBucketLocation", "s3:ListBucket"], "Resource": "arn:aws:s3:::logs"},
+			{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::logs/cloudtrail/*"},
+			{"Effect": "Deny", "Action": "s3:*", "Resource": "*"}
+		]
+	}`, `{
+		"Version": "2012-10-17",
+		"Statement": {"Effect": "Allow", "Action": ["s3:Get*", "s3:List*"], "Resource": "*"}
+	}`)
+	iamClientFunc = func(*credentials.Credentials) iamiface.IAMAPI { return mockIAM }
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs/cloudtrail/*"}),
+	})
+	require.NoError(t, err)
+	assert.False(t, *result.RolePolicyStatus.Healthy)
+	assert.True(t, *result.RolePolicyStatus.Informational)
+	assert.Equal(t, "the role grants 2 permissions Panther doesn't need", *result.RolePolicyStatus.ErrorMessage)
+	assert.Equal(t, []*models.ExcessPermission{
+		{Policy: aws.String("AmazonS3ReadOnlyAccess"), Action: aws.String("s3:Get*"), Resource: aws.String("*")},
+		{Policy: aws.String("AmazonS3ReadOnlyAccess"), Action: aws.String("s3:List*"), Resource: aws.String("*")},
+	}, result.ExcessPermissions)
+	mockIAM.AssertExpectations(t)
+
+	// The advisory doesn't affect the health of the integration
+	eval, err := evaluateIntegrationHealth(apiTest, &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs/cloudtrail/*"}),
+	})
+	require.NoError(t, err)
+	assert.True(t, eval.passing())
+}
+
+func TestCheckIntegrationLeastPrivilegedRolePolicy(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+	// Narrower resources than the ones needed are not in excess
+	mockIAM := mockRolePolicies(`{
+		"Statement": [
+			{"Effect": "Allow", "Action": ["s3:GetBucketLocation", "s3:ListBucket"], "Resource": ["arn:aws:s3:::logs"]},
+			{"Effect": "Allow", "Action": "S3:GetObject", "Resource": "arn:aws:s3:::logs/prefix*"}
+		]
+	}`, `{"Statement": []}`)
+	iamClientFunc = func(*credentials.Credentials) iamiface.IAMAPI { return mockIAM }
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs"}),
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.RolePolicyStatus.Healthy)
+	assert.Empty(t, result.ExcessPermissions)
+}
+
+func TestCheckIntegrationRolePolicyNotReadable(t *testing.T) {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockS3 := &mockS3Client{}
+	mockS3.On("GetBucketLocation", mock.Anything).Return(&s3.GetBucketLocationOutput{}, nil)
+	mockS3.On("ListObjectsV2", mock.Anything).Return(&s3.ListObjectsV2Output{}, nil)
+	mockHealthCheckClients(mockSTS, mockS3, &mockKMSClient{})
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"logs"}),
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.RolePolicyStatus.Inconclusive)
+	assert.True(t, *result.RolePolicyStatus.Informational)
+	assert.Contains(t, *result.RolePolicyStatus.ErrorMessage, "iam:ListRolePolicies")
+}
@@ -0,0 +1,97 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkObjectFilters rejects the filters of buckets the integration doesn't read, and the filters ingesting objects
+// which another integration also ingests from the same bucket: each object would be ingested twice.
+//
+// The integration is nil for a new integration. A bucket read without a filter shares all its objects.
+func checkObjectFilters(integrationID *string, buckets []*string, filters map[string]*models.S3ObjectFilter) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	names := make(map[string]struct{}, len(buckets))
+	for _, bucket := range buckets {
+		name, _ := parseBucketEntry(aws.StringValue(bucket))
+		names[name] = struct{}{}
+	}
+	filtered := make([]string, 0, len(filters))
+	for name := range filters {
+		if _, ok := names[name]; !ok {
+			return &genericapi.InvalidInputError{Message: fmt.Sprintf("s3ObjectFilters: %s is not one of the s3Buckets", name)}
+		}
+		filtered = append(filtered, name)
+	}
+	sort.Strings(filtered)
+
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return err
+	}
+	for _, name := range filtered {
+		for _, other := range integrations {
+			if aws.StringValue(other.IntegrationType) != models.IntegrationTypeAWS3 ||
+				aws.StringValue(other.IntegrationID) == aws.StringValue(integrationID) || !readsBucket(other.S3Buckets, name) {
+
+				continue
+			}
+			if filters[name].Overlaps(other.S3ObjectFilters[name]) {
+				return &genericapi.InvalidInputError{Message: fmt.Sprintf(
+					"s3ObjectFilters.%s: the filtered objects overlap those ingested by the integration %s",
+					name, aws.StringValue(other.IntegrationLabel))}
+			}
+		}
+	}
+	return nil
+}
+
+// objectFiltersFor returns the filters of the given buckets only, nil if none of them has a filter.
+func objectFiltersFor(buckets []*string, filters map[string]*models.S3ObjectFilter) map[string]*models.S3ObjectFilter {
+	var result map[string]*models.S3ObjectFilter
+	for _, bucket := range buckets {
+		name, _ := parseBucketEntry(aws.StringValue(bucket))
+		if filter, ok := filters[name]; ok {
+			if result == nil {
+				result = make(map[string]*models.S3ObjectFilter)
+			}
+			result[name] = filter
+		}
+	}
+	return result
+}
+
+// readsBucket returns true if one of the bucket entries is of the bucket.
+func readsBucket(buckets []*string, name string) bool {
+	for _, bucket := range buckets {
+		if entryName, _ := parseBucketEntry(aws.StringValue(bucket)); entryName == name {
+			return true
+		}
+	}
+	return false
+}
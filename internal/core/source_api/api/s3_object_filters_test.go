@@ -0,0 +1,189 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testOtherIntegrationID = "d0ea5cbd-6d3b-4a5f-8d1b-9f7e1b1d2c3e"
+
+// mockObjectFilterIntegrations stores the updated integration, the other integrations are those of the scan.
+func mockObjectFilterIntegrations(t *testing.T, others ...*models.SourceIntegrationMetadata) *modelstest.MockDDBClient {
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		AWSAccountID:    aws.String(testAccountID),
+		S3Buckets:       aws.StringSlice([]string{"logs", "archive/prefix"}),
+	})
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{}
+	for _, other := range others {
+		otherItem, err := dynamodbattribute.MarshalMap(other)
+		require.NoError(t, err)
+		mockClient.MockScanAttributes = append(mockClient.MockScanAttributes, otherItem)
+	}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil)
+	return mockClient
+}
+
+func otherS3Integration(filter *models.S3ObjectFilter) *models.SourceIntegrationMetadata {
+	integration := &models.SourceIntegrationMetadata{
+		IntegrationID:    aws.String(testOtherIntegrationID),
+		IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+		IntegrationLabel: aws.String("database-logs"),
+		S3Buckets:        aws.StringSlice([]string{"logs"}),
+	}
+	if filter != nil {
+		integration.S3ObjectFilters = map[string]*models.S3ObjectFilter{"logs": filter}
+	}
+	return integration
+}
+
+func TestUpdateIntegrationSettingsObjectFilters(t *testing.T) {
+	mockClient := mockObjectFilterIntegrations(t,
+		otherS3Integration(&models.S3ObjectFilter{Prefixes: aws.StringSlice([]string{"db/"})}))
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	previousEvaluate := evaluateIntegrationFunc
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	t.Cleanup(func() { evaluateIntegrationFunc = previousEvaluate })
+
+	filters := map[string]*models.S3ObjectFilter{
+		"logs": {Prefixes: aws.StringSlice([]string{"app/"}), Suffixes: aws.StringSlice([]string{".json.gz"})},
+	}
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		S3ObjectFilters: filters,
+	})
+	require.NoError(t, err)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "s3ObjectFilters")
+	var stored map[string]*models.S3ObjectFilter
+	for _, value := range update.ExpressionAttributeValues {
+		if value.M["logs"] != nil {
+			require.NoError(t, dynamodbattribute.Unmarshal(value, &stored))
+		}
+	}
+	assert.Equal(t, filters, stored)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsObjectFiltersOverlap(t *testing.T) {
+	for _, other := range []*models.S3ObjectFilter{
+		// The other integration reads the whole bucket
+		nil,
+		{Prefixes: aws.StringSlice([]string{"app/2020/"})},
+		{Prefixes: aws.StringSlice([]string{"db/", "ap"}), Suffixes: aws.StringSlice([]string{"gz"})},
+	} {
+		mockObjectFilterIntegrations(t, otherS3Integration(other))
+
+		_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+			IntegrationID: aws.String(testIntegrationID),
+			S3ObjectFilters: map[string]*models.S3ObjectFilter{
+				"logs": {Prefixes: aws.StringSlice([]string{"app/"}), Suffixes: aws.StringSlice([]string{".json.gz"})},
+			},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "s3ObjectFilters.logs: the filtered objects overlap those ingested by the integration database-logs",
+			err.(*genericapi.InvalidInputError).Message)
+	}
+}
+
+func TestUpdateIntegrationSettingsObjectFiltersNotABucket(t *testing.T) {
+	mockObjectFilterIntegrations(t)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		S3ObjectFilters: map[string]*models.S3ObjectFilter{"other": {Prefixes: aws.StringSlice([]string{"app/"})}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "s3ObjectFilters: other is not one of the s3Buckets", err.(*genericapi.InvalidInputError).Message)
+}
+
+func TestPutIntegrationObjectFiltersNotABucket(t *testing.T) {
+	mockObjectFilterIntegrations(t)
+
+	_, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{{
+			AWSAccountID:     aws.String(testAccountID),
+			IntegrationLabel: aws.String(testIntegrationLabel),
+			IntegrationType:  aws.String(models.IntegrationTypeAWS3),
+			UserID:           aws.String(testUserID),
+			S3Buckets:        aws.StringSlice([]string{"logs/prefix"}),
+			S3ObjectFilters:  map[string]*models.S3ObjectFilter{"archive": {Suffixes: aws.StringSlice([]string{".gz"})}},
+		}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "s3ObjectFilters: archive is not one of the s3Buckets", err.(*genericapi.InvalidInputError).Message)
+}
+
+func TestS3ObjectFilterOverlaps(t *testing.T) {
+	filter := &models.S3ObjectFilter{
+		Prefixes: aws.StringSlice([]string{"app/", "web/"}),
+		Suffixes: aws.StringSlice([]string{".json.gz"}),
+	}
+	for _, testCase := range []struct {
+		other    *models.S3ObjectFilter
+		overlaps bool
+	}{
+		{nil, true},
+		{&models.S3ObjectFilter{}, true},
+		{&models.S3ObjectFilter{Prefixes: aws.StringSlice([]string{"web/access/"})}, true},
+		{&models.S3ObjectFilter{Prefixes: aws.StringSlice([]string{"w"})}, true},
+		{&models.S3ObjectFilter{Suffixes: aws.StringSlice([]string{".gz"})}, true},
+		{&models.S3ObjectFilter{Prefixes: aws.StringSlice([]string{"db/"})}, false},
+		{&models.S3ObjectFilter{Suffixes: aws.StringSlice([]string{".log"})}, false},
+		{&models.S3ObjectFilter{Prefixes: aws.StringSlice([]string{"app/"}), Suffixes: aws.StringSlice([]string{".csv"})}, false},
+	} {
+		assert.Equal(t, testCase.overlaps, filter.Overlaps(testCase.other), testCase.other)
+		assert.Equal(t, testCase.overlaps, testCase.other.Overlaps(filter), testCase.other)
+	}
+}
+
+func TestPreviewIntegrationChangeSetObjectFilters(t *testing.T) {
+	mockObjectFilterIntegrations(t)
+	filters := map[string]*models.S3ObjectFilter{"logs": {Prefixes: aws.StringSlice([]string{"app/"})}}
+
+	result, err := apiTest.PreviewIntegrationChangeSet(&models.PreviewIntegrationChangeSetInput{
+		Desired: &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID), S3ObjectFilters: filters},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.IntegrationFieldChange{
+		{Field: aws.String("s3ObjectFilters"), Action: aws.String(models.FieldAdded), Desired: filters},
+	}, result.Changes)
+	assert.False(t, *result.HealthCheckRequired)
+}
@@ -0,0 +1,108 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The headers of the scan complete callbacks
+const (
+	callbackTimestampHeader = "X-Panther-Timestamp"
+	callbackSignatureHeader = "X-Panther-Signature"
+)
+
+// callbackClient posts the scan complete callbacks, a slow endpoint must not hold the end of the scan for long.
+var callbackClient = &http.Client{Timeout: 5 * time.Second}
+
+// postScanComplete posts the summary of a scan which ended to the callback of its integration.
+//
+// The callback is best-effort: it's an endpoint of the customer, so a failure is logged and never fails the scan,
+// even with strict side effects. Scans which are still running have no summary to post.
+func postScanComplete(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationLastScanEndInput) {
+	if integration == nil || aws.StringValue(integration.ScanCompleteCallbackURL) == "" ||
+		aws.StringValue(input.ScanStatus) == models.StatusScanning {
+
+		return
+	}
+
+	summary := &models.ScanCompleteSummary{
+		IntegrationID:    input.IntegrationID,
+		ScanID:           input.ScanID,
+		ScanStatus:       input.ScanStatus,
+		ScanEndTime:      input.LastScanEndTime,
+		ObjectsProcessed: input.ObjectsProcessed,
+		ErrorCount:       aws.Int(len(input.ErrorSamples)),
+		Truncated:        aws.Bool(aws.BoolValue(input.ScanTruncated)),
+	}
+	if err := postSigned(*integration.ScanCompleteCallbackURL, summary, time.Now()); err != nil {
+		zap.L().Warn("scan complete callback failed",
+			zap.String("integrationId", aws.StringValue(input.IntegrationID)),
+			zap.Error(err))
+	}
+}
+
+// postSigned posts a JSON payload signed with the callback secret.
+//
+// The signature is the hex HMAC-SHA256 of "<timestamp>.<body>", so that the receiver can reject replayed payloads.
+func postSigned(url string, payload interface{}, now time.Time) error {
+	body, err := jsoniter.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(callbackTimestampHeader, timestamp)
+	request.Header.Set(callbackSignatureHeader, "sha256="+callbackSignature(timestamp, body))
+
+	response, err := callbackClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("callback responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+func callbackSignature(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(scanCallbackSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,144 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+type callbackRequest struct {
+	timestamp, signature string
+	body                 []byte
+}
+
+// mockCallbackEndpoint records the requests posted to an https endpoint which responds with the given status.
+func mockCallbackEndpoint(t *testing.T, status int) (*httptest.Server, *[]callbackRequest) {
+	var requests []callbackRequest
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, callbackRequest{
+			timestamp: r.Header.Get(callbackTimestampHeader),
+			signature: r.Header.Get(callbackSignatureHeader),
+			body:      body,
+		})
+		w.WriteHeader(status)
+	}))
+	callbackClient = server.Client()
+	return server, &requests
+}
+
+func mockScanEnd(t *testing.T, callbackURL string) *modelstest.MockDDBClient {
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		IntegrationID:           aws.String(testIntegrationID),
+		IntegrationType:         aws.String(models.IntegrationTypeAWS3),
+		ScanCompleteCallbackURL: aws.String(callbackURL),
+	})
+	require.NoError(t, err)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+	return mockClient
+}
+
+func TestUpdateIntegrationLastScanEndPostsSignedSummary(t *testing.T) {
+	scanCallbackSecret = "secret"
+	defer func() { scanCallbackSecret = "" }()
+	server, requests := mockCallbackEndpoint(t, http.StatusNoContent)
+	defer server.Close()
+	mockScanEnd(t, server.URL)
+
+	scanEnd := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, err := apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		ScanID:           aws.String("scan-1"),
+		LastScanEndTime:  aws.Time(scanEnd),
+		ScanStatus:       aws.String(models.StatusOK),
+		ObjectsProcessed: aws.Int64(42),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, *requests, 1)
+	request := (*requests)[0]
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(request.timestamp + "."))
+	mac.Write(request.body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), request.signature)
+
+	var summary models.ScanCompleteSummary
+	require.NoError(t, jsoniter.Unmarshal(request.body, &summary))
+	assert.Equal(t, models.ScanCompleteSummary{
+		IntegrationID:    aws.String(testIntegrationID),
+		ScanID:           aws.String("scan-1"),
+		ScanStatus:       aws.String(models.StatusOK),
+		ScanEndTime:      aws.Time(scanEnd),
+		ObjectsProcessed: aws.Int64(42),
+		ErrorCount:       aws.Int(0),
+		Truncated:        aws.Bool(false),
+	}, summary)
+}
+
+// A failing callback doesn't fail the end of the scan, even with strict side effects.
+func TestUpdateIntegrationLastScanEndCallbackFails(t *testing.T) {
+	strictSideEffects = true
+	defer func() { strictSideEffects = false }()
+	server, requests := mockCallbackEndpoint(t, http.StatusInternalServerError)
+	defer server.Close()
+	mockScanEnd(t, server.URL)
+
+	_, err := apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		LastScanEndTime: aws.Time(time.Now()),
+		ScanStatus:      aws.String(models.StatusError),
+	})
+	require.NoError(t, err)
+	assert.Len(t, *requests, 1)
+}
+
+func TestScanCompleteCallbackURLValidation(t *testing.T) {
+	validate, err := models.Validator()
+	require.NoError(t, err)
+	input := &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID)}
+
+	input.ScanCompleteCallbackURL = aws.String("https://example.com/scans")
+	assert.NoError(t, validate.Struct(input))
+	input.ScanCompleteCallbackURL = aws.String("http://example.com/scans")
+	assert.Error(t, validate.Struct(input))
+	input.ScanCompleteCallbackURL = aws.String("example.com/scans")
+	assert.Error(t, validate.Struct(input))
+}
@@ -0,0 +1,106 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// Bound the storage of the samples: the oldest samples are evicted first
+	maxScanErrorSamples      = 20
+	maxScanErrorSampleLength = 1024
+
+	redactedValue = "<redacted>"
+
+	// How many times the samples are read and written when the integration is written concurrently
+	maxScanErrorSampleAttempts = 3
+)
+
+var (
+	// Values of fields which are likely to hold credentials, e.g. "password": "hunter2" or token=abc
+	secretFieldRegexp = regexp.MustCompile(
+		`(?i)("?(?:password|passwd|secret|token|api[_-]?key|authorization)"?\s*[:=]\s*)((?:Bearer|Basic)\s+[^\s,;&}]+|"[^"]*"|[^\s,;&}]+)`)
+	accessKeyRegexp = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+)
+
+// GetScanErrorSamples returns the records which recent scans of an integration failed on, oldest first.
+func (API) GetScanErrorSamples(input *models.GetScanErrorSamplesInput) ([]*models.ScanErrorSample, error) {
+	samples, err := db.GetScanErrorSamples(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	if samples == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	return samples, nil
+}
+
+// addScanErrorSamples returns the stored samples followed by the new ones, evicting the oldest above the cap,
+// and the version of the integration the stored samples were read at.
+func addScanErrorSamples(integrationID *string, newSamples []*models.ScanErrorSample) (
+	[]*models.ScanErrorSample, int64, error) {
+
+	samples, version, err := db.GetScanErrorSamplesWithVersion(integrationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now().UTC()
+	for _, sample := range newSamples {
+		stored := &models.ScanErrorSample{
+			ObjectKey:    sample.ObjectKey,
+			Record:       aws.String(redactRecord(*sample.Record)),
+			ErrorMessage: sample.ErrorMessage,
+			ObservedAt:   sample.ObservedAt,
+		}
+		if stored.ObservedAt == nil {
+			stored.ObservedAt = &now
+		}
+		samples = append(samples, stored)
+	}
+
+	if len(samples) > maxScanErrorSamples {
+		samples = samples[len(samples)-maxScanErrorSamples:]
+	}
+	return samples, version, nil
+}
+
+// redactRecord masks the credentials a record may hold and truncates it to the maximum sample length.
+func redactRecord(record string) string {
+	record = secretFieldRegexp.ReplaceAllString(record, `${1}"`+redactedValue+`"`)
+	record = accessKeyRegexp.ReplaceAllString(record, redactedValue)
+	if len(record) <= maxScanErrorSampleLength {
+		return record
+	}
+
+	// Don't cut a multi-byte character in half
+	end := maxScanErrorSampleLength
+	for end > 0 && !utf8.RuneStart(record[end]) {
+		end--
+	}
+	return record[:end]
+}
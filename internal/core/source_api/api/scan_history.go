@@ -0,0 +1,118 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"math"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The most scans the history of an integration keeps, and the most older scans trimmed once a scan ends
+var (
+	maxScanHistoryRecords = 100
+	maxTrimmedScans       = 1000
+)
+
+// ListScanHistory returns the most recent scans of an integration, with how many failed and how long they took.
+//
+// Deployments without a history table keep no history, there are then no scans.
+func (API) ListScanHistory(input *models.ListScanHistoryInput) (*models.ScanHistory, error) {
+	history := &models.ScanHistory{
+		IntegrationID: input.IntegrationID,
+		Scans:         make([]*models.IntegrationHistoryRecord, 0),
+		FailedScans:   aws.Int(0),
+	}
+	if db.HistoryTableName == "" {
+		return history, nil
+	}
+	limit := maxScanHistoryRecords
+	if input.Limit != nil {
+		limit = *input.Limit
+	}
+
+	scans, err := recentScans(input.IntegrationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	var failed, timed int
+	var totalSecs int64
+	for _, scan := range scans {
+		if aws.StringValue(scan.ScanStatus) == models.StatusError {
+			failed++
+		}
+		if scan.ScanDurationSecs != nil {
+			timed++
+			totalSecs += *scan.ScanDurationSecs
+		}
+	}
+	history.Scans = append(history.Scans, scans...)
+	history.FailedScans = aws.Int(failed)
+	if timed > 0 {
+		history.AverageScanDurationSecs = aws.Int64(int64(math.Round(float64(totalSecs) / float64(timed))))
+	}
+	return history, nil
+}
+
+// recentScans returns up to limit of the most recent scans in the history of an integration, most recent first.
+//
+// The history query filters on the kind of the records, it pages until it has enough scans or there are no more.
+func recentScans(integrationID *string, limit int) ([]*models.IntegrationHistoryRecord, error) {
+	var scans []*models.IntegrationHistoryRecord
+	var exclusiveStartKey *string
+	for len(scans) < limit {
+		page, lastRecordID, err := db.ListHistory(
+			integrationID, aws.String(models.HistoryKindScan), compactionPageSize, exclusiveStartKey)
+		if err != nil {
+			return nil, err
+		}
+		scans = append(scans, page...)
+		if lastRecordID == nil {
+			break
+		}
+		exclusiveStartKey = lastRecordID
+	}
+	if len(scans) > limit {
+		scans = scans[:limit]
+	}
+	return scans, nil
+}
+
+// trimScanHistory deletes the scans of an integration older than the most recent ones the history keeps.
+//
+// It runs as each scan ends, a history longer than the most trimmed at once is trimmed over the next scans.
+func trimScanHistory(integrationID *string) error {
+	if db.HistoryTableName == "" {
+		return nil
+	}
+	return runSideEffect(sideEffectHistory, integrationID, func() error {
+		scans, err := recentScans(integrationID, maxScanHistoryRecords+maxTrimmedScans)
+		if err != nil || len(scans) <= maxScanHistoryRecords {
+			return err
+		}
+		older := scans[maxScanHistoryRecords:]
+		recordIDs := make([]*string, len(older))
+		for i, scan := range older {
+			recordIDs[i] = scan.RecordID
+		}
+		return db.DeleteHistoryRecords(integrationID, recordIDs)
+	})
+}
@@ -0,0 +1,157 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+// mockScanHistoryClient returns the stored history records most recent first, and stores the records put
+type mockScanHistoryClient struct {
+	*mockHistoryTableClient
+}
+
+func (client *mockScanHistoryClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	recordIDs := client.recordIDs()
+	sort.Sort(sort.Reverse(sort.StringSlice(recordIDs)))
+	items := make([]map[string]*dynamodb.AttributeValue, len(recordIDs))
+	for i, recordID := range recordIDs {
+		item, err := dynamodbattribute.MarshalMap(client.records[recordID])
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return &dynamodb.QueryOutput{Items: items}, nil
+}
+
+func (client *mockScanHistoryClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if aws.StringValue(input.TableName) == "history" {
+		var record models.IntegrationHistoryRecord
+		if err := dynamodbattribute.UnmarshalMap(input.Item, &record); err != nil {
+			return nil, err
+		}
+		client.records[*record.RecordID] = &record
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func mockScanHistory(records ...*models.IntegrationHistoryRecord) *mockScanHistoryClient {
+	client := &mockScanHistoryClient{mockHistoryTableClient: mockHistoryTable(records...)}
+	db = &ddb.DDB{Client: client, TableName: "test", HistoryTableName: "history"}
+	return client
+}
+
+func timedScanRecord(recordID, status string, durationSecs *int64) *models.IntegrationHistoryRecord {
+	record := scanRecord(recordID, time.Now(), 10)
+	record.ScanStatus = aws.String(status)
+	record.ScanDurationSecs = durationSecs
+	return record
+}
+
+func TestListScanHistory(t *testing.T) {
+	mockScanHistory(
+		timedScanRecord("1", models.StatusOK, aws.Int64(60)),
+		timedScanRecord("2", models.StatusError, aws.Int64(121)),
+		timedScanRecord("3", models.StatusOK, nil),
+	)
+
+	result, err := apiTest.ListScanHistory(&models.ListScanHistoryInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	require.Len(t, result.Scans, 3)
+	assert.Equal(t, "3", *result.Scans[0].RecordID)
+	assert.Equal(t, "1", *result.Scans[2].RecordID)
+	assert.Equal(t, 1, *result.FailedScans)
+	// The scan without a duration is left out of the average
+	assert.Equal(t, int64(91), *result.AverageScanDurationSecs)
+
+	result, err = apiTest.ListScanHistory(&models.ListScanHistoryInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Limit:         aws.Int(1),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Scans, 1)
+	assert.Equal(t, "3", *result.Scans[0].RecordID)
+	assert.Equal(t, 0, *result.FailedScans)
+	assert.Nil(t, result.AverageScanDurationSecs)
+}
+
+func TestListScanHistoryNotKept(t *testing.T) {
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{TestErr: true}, TableName: "test"}
+
+	result, err := apiTest.ListScanHistory(&models.ListScanHistoryInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Empty(t, result.Scans)
+	assert.Equal(t, 0, *result.FailedScans)
+	assert.NoError(t, trimScanHistory(aws.String(testIntegrationID)))
+}
+
+func TestUpdateIntegrationLastScanEndTrimsScanHistory(t *testing.T) {
+	defer func(max int) { maxScanHistoryRecords = max }(maxScanHistoryRecords)
+	maxScanHistoryRecords = 2
+	client := mockScanHistory(
+		timedScanRecord("2020-06-01T10:00:00.000000000Z-a", models.StatusOK, nil),
+		timedScanRecord("2020-06-01T11:00:00.000000000Z-b", models.StatusOK, nil),
+	)
+	lastScanStart := time.Now().Add(-90 * time.Second)
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:   aws.String(testIntegrationID),
+			IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			LastScanStartTime: aws.Time(lastScanStart),
+			LastScanEndTime:   aws.Time(lastScanStart.Add(90 * time.Second)),
+		},
+	})
+	require.NoError(t, err)
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+
+	_, err = apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		LastScanEndTime:  aws.Time(lastScanStart.Add(90 * time.Second)),
+		ScanStatus:       aws.String(models.StatusError),
+		ObjectsProcessed: aws.Int64(40),
+		ObjectsFailed:    aws.Int64(3),
+	})
+	require.NoError(t, err)
+
+	// The scan is recorded with its duration and errors, and the oldest scan is trimmed
+	recordIDs := client.recordIDs()
+	require.Len(t, recordIDs, 2)
+	assert.Equal(t, "2020-06-01T11:00:00.000000000Z-b", recordIDs[0])
+	scan := client.records[recordIDs[1]]
+	assert.Equal(t, int64(90), *scan.ScanDurationSecs)
+	assert.Equal(t, lastScanStart.Unix(), scan.ScanStartTime.Unix())
+	assert.Equal(t, int64(40), *scan.ObjectsProcessed)
+	assert.Equal(t, int64(3), *scan.ObjectsFailed)
+}
@@ -0,0 +1,132 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// Scans of more objects than this take long enough to delay the data, unless the integration sets its own cap
+	defaultTargetObjectsPerScan = 10000
+
+	// A scan should take at most this share of the interval, shorter intervals mostly repeat the listing
+	maxScanShareOfInterval = 0.25
+)
+
+// RecommendScanInterval suggests the scan interval of an integration from the volume of its scans.
+//
+// The recommendation is the longest supported interval whose scans stay within the object cap of the integration,
+// so that the scans keep up with the new objects without scanning more often than needed. The interval is
+// lengthened if the scans take too long for it. The volume is the one of the last scan, and the duration the
+// rolling average of the scans: without them the current interval is kept.
+func (API) RecommendScanInterval(input *models.RecommendScanIntervalInput) (*models.ScanIntervalRecommendation, error) {
+	integration, err := db.GetSourceIntegration(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil || integration.SourceIntegrationMetadata == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if !scheduled(integration.SourceIntegrationMetadata) {
+		return nil, &genericapi.InvalidInputError{Message: "integration is not scanned on a schedule"}
+	}
+
+	recommendation := &models.ScanIntervalRecommendation{
+		IntegrationID:           integration.IntegrationID,
+		Advisory:                aws.Bool(true),
+		CurrentIntervalMins:     integration.ScanIntervalMins,
+		RecommendedIntervalMins: integration.ScanIntervalMins,
+	}
+	scan := integration.SourceIntegrationScanInformation
+	if scan == nil || scan.LastScanObjectsProcessed == nil || scan.AverageScanDurationSecs == nil {
+		addReason(recommendation, "the integration has no scan history yet, the current interval is kept")
+		return recommendation, nil
+	}
+	recommendScanInterval(recommendation, integration)
+	return recommendation, nil
+}
+
+func recommendScanInterval(recommendation *models.ScanIntervalRecommendation, integration *models.SourceIntegration) {
+	scan := integration.SourceIntegrationScanInformation
+	coveredMins := currentScanIntervalMins(integration)
+	objectsPerMin := float64(*scan.LastScanObjectsProcessed) / float64(coveredMins)
+	recommendation.ObjectsPerHour = aws.Int64(int64(math.Round(objectsPerMin * 60)))
+	addReason(recommendation, "the last scan processed %d objects over %d minutes, about %d objects per hour",
+		*scan.LastScanObjectsProcessed, coveredMins, *recommendation.ObjectsPerHour)
+
+	target := int64(defaultTargetObjectsPerScan)
+	if integration.MaxObjectsPerScan != nil {
+		target = int64(*integration.MaxObjectsPerScan)
+	}
+
+	// The longest interval whose scans stay within the target
+	byVolume := models.ScanIntervalsMins[0]
+	for _, interval := range models.ScanIntervalsMins {
+		if objectsPerMin*float64(interval) <= float64(target) {
+			byVolume = interval
+		}
+	}
+	if objectsPerMin*float64(byVolume) > float64(target) {
+		addReason(recommendation, "even the shortest interval of %d minutes exceeds the target of %d objects per scan",
+			byVolume, target)
+	} else {
+		addReason(recommendation, "%d minutes is the longest interval with at most %d objects per scan", byVolume, target)
+	}
+
+	// The shortest interval of which the scans take at most their share
+	byDuration := models.ScanIntervalsMins[len(models.ScanIntervalsMins)-1]
+	for i := len(models.ScanIntervalsMins) - 1; i >= 0; i-- {
+		if float64(*scan.AverageScanDurationSecs) <= maxScanShareOfInterval*float64(models.ScanIntervalsMins[i]*60) {
+			byDuration = models.ScanIntervalsMins[i]
+		}
+	}
+
+	recommended := byVolume
+	if byDuration > recommended {
+		recommended = byDuration
+		addReason(recommendation, "scans take %d seconds on average, the interval is lengthened to %d minutes "+
+			"for them to take at most a quarter of it: more concurrent objects would shorten them",
+			*scan.AverageScanDurationSecs, recommended)
+	}
+
+	// The volume of truncated scans is capped, more objects are waiting than were observed
+	current := int64(aws.IntValue(integration.ScanIntervalMins))
+	if aws.BoolValue(scan.BacklogSuspected) && recommended >= current && current > models.ScanIntervalsMins[0] {
+		for i := len(models.ScanIntervalsMins) - 1; i >= 0; i-- {
+			if models.ScanIntervalsMins[i] < current {
+				recommended = models.ScanIntervalsMins[i]
+				break
+			}
+		}
+		addReason(recommendation, "a backlog is suspected, the interval is shortened to %d minutes", recommended)
+	}
+
+	recommendation.RecommendedIntervalMins = aws.Int(int(recommended))
+}
+
+func addReason(recommendation *models.ScanIntervalRecommendation, format string, args ...interface{}) {
+	recommendation.Reasons = append(recommendation.Reasons, aws.String(fmt.Sprintf(format, args...)))
+}
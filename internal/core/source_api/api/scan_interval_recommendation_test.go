@@ -0,0 +1,130 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func observedIntegration(intervalMins int, objectsProcessed, averageScanDurationSecs int64) *models.SourceIntegration {
+	return &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String(testIntegrationID),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+			ScanIntervalMins: aws.Int(intervalMins),
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			LastScanObjectsProcessed: aws.Int64(objectsProcessed),
+			AverageScanDurationSecs:  aws.Int64(averageScanDurationSecs),
+		},
+	}
+}
+
+func TestRecommendScanIntervalByVolume(t *testing.T) {
+	// 20000 objects per hour: only hourly scans stay close to the target
+	mockStoredSourceIntegration(t, observedIntegration(360, 120000, 60))
+	highVolume, err := apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+
+	// 50 objects per hour: daily scans are enough
+	mockStoredSourceIntegration(t, observedIntegration(360, 300, 60))
+	lowVolume, err := apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+
+	assert.Equal(t, 60, *highVolume.RecommendedIntervalMins)
+	assert.Equal(t, int64(20000), *highVolume.ObjectsPerHour)
+	assert.Equal(t, 1440, *lowVolume.RecommendedIntervalMins)
+	assert.Equal(t, int64(50), *lowVolume.ObjectsPerHour)
+	assert.Less(t, *highVolume.RecommendedIntervalMins, *lowVolume.RecommendedIntervalMins)
+
+	// The configuration is left as it is
+	assert.True(t, *lowVolume.Advisory)
+	assert.Equal(t, 360, *lowVolume.CurrentIntervalMins)
+	assert.Equal(t, []*string{
+		aws.String("the last scan processed 300 objects over 360 minutes, about 50 objects per hour"),
+		aws.String("1440 minutes is the longest interval with at most 10000 objects per scan"),
+	}, lowVolume.Reasons)
+	assert.Contains(t, *highVolume.Reasons[1], "exceeds the target of 10000 objects per scan")
+}
+
+func TestRecommendScanIntervalObjectCap(t *testing.T) {
+	integration := observedIntegration(60, 1000, 60)
+	integration.MaxObjectsPerScan = aws.Int(5000)
+	mockStoredSourceIntegration(t, integration)
+
+	result, err := apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, 180, *result.RecommendedIntervalMins)
+}
+
+func TestRecommendScanIntervalLongScans(t *testing.T) {
+	// Few objects, but the scans take an hour and a half
+	mockStoredSourceIntegration(t, observedIntegration(60, 10, 5400))
+
+	result, err := apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	// The volume alone would allow daily scans
+	assert.Equal(t, 1440, *result.RecommendedIntervalMins)
+
+	mockStoredSourceIntegration(t, observedIntegration(60, 3000, 5400))
+	result, err = apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, 360, *result.RecommendedIntervalMins)
+	assert.Contains(t, *result.Reasons[2], "lengthened to 360 minutes")
+}
+
+func TestRecommendScanIntervalBacklog(t *testing.T) {
+	integration := observedIntegration(720, 100, 60)
+	integration.BacklogSuspected = aws.Bool(true)
+	mockStoredSourceIntegration(t, integration)
+
+	result, err := apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, 360, *result.RecommendedIntervalMins)
+}
+
+func TestRecommendScanIntervalNoHistory(t *testing.T) {
+	integration := observedIntegration(180, 0, 0)
+	integration.SourceIntegrationScanInformation = nil
+	mockStoredSourceIntegration(t, integration)
+
+	result, err := apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, 180, *result.RecommendedIntervalMins)
+	assert.Nil(t, result.ObjectsPerHour)
+	require.Len(t, result.Reasons, 1)
+}
+
+func TestRecommendScanIntervalNotScheduled(t *testing.T) {
+	integration := observedIntegration(60, 100, 60)
+	integration.ScanEnabled = aws.Bool(false)
+	mockStoredSourceIntegration(t, integration)
+
+	result, err := apiTest.RecommendScanInterval(&models.RecommendScanIntervalInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
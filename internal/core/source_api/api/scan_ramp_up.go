@@ -0,0 +1,54 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"math"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// currentScanIntervalMins returns the interval between the last scan of an integration and the next one.
+//
+// While the integration ramps up, the interval after the first scan is the initial one and each following
+// interval is multiplied by the same factor, until the steady ScanIntervalMins is reached after the ramp-up scans.
+func currentScanIntervalMins(integration *models.SourceIntegration) int {
+	steady := aws.IntValue(integration.ScanIntervalMins)
+	rampUp := integration.ScanRampUp
+	if rampUp == nil || integration.SourceIntegrationScanInformation == nil {
+		return steady
+	}
+
+	initial, scans := aws.IntValue(rampUp.InitialIntervalMins), aws.IntValue(rampUp.Scans)
+	completed := aws.IntValue(integration.CompletedScans)
+	if initial <= 0 || initial >= steady || completed > scans {
+		return steady
+	}
+	if completed < 1 {
+		completed = 1
+	}
+
+	factor := math.Pow(float64(steady)/float64(initial), float64(completed-1)/float64(scans))
+	if interval := int(math.Round(float64(initial) * factor)); interval < steady {
+		return interval
+	}
+	return steady
+}
@@ -0,0 +1,83 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+func rampingIntegration(completedScans int) *models.SourceIntegration {
+	return &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			ScanIntervalMins: aws.Int(720),
+			ScanRampUp:       &models.ScanRampUp{InitialIntervalMins: aws.Int(45), Scans: aws.Int(4)},
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			LastScanEndTime: aws.Time(time.Date(2020, 3, 2, 12, 0, 0, 0, time.UTC)),
+			CompletedScans:  aws.Int(completedScans),
+		},
+	}
+}
+
+func TestScanRampUpProgression(t *testing.T) {
+	now := time.Date(2020, 3, 2, 12, 0, 0, 0, time.UTC)
+	// The interval doubles after each of the 4 ramp-up scans, then the steady interval applies
+	for completed, expected := range []int{45, 45, 90, 180, 360, 720, 720} {
+		next := NextScanTime(rampingIntegration(completed), now)
+		assert.Equal(t, now.Add(time.Duration(expected)*time.Minute), next, "after %d scans", completed)
+	}
+}
+
+func TestScanRampUpNotConfigured(t *testing.T) {
+	integration := rampingIntegration(1)
+	integration.ScanRampUp = nil
+	assert.Equal(t, 720, currentScanIntervalMins(integration))
+
+	// A ramp-up starting above the steady interval doesn't slow the scans down
+	integration.ScanRampUp = &models.ScanRampUp{InitialIntervalMins: aws.Int(1440), Scans: aws.Int(3)}
+	assert.Equal(t, 720, currentScanIntervalMins(integration))
+}
+
+func TestScanRampUpValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(rampUp *models.ScanRampUp) *models.PutIntegrationSettings {
+		return &models.PutIntegrationSettings{
+			AWSAccountID:     aws.String(testAccountID),
+			IntegrationLabel: aws.String("cloudsec"),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanIntervalMins: aws.Int(1440),
+			UserID:           aws.String(testUserID),
+			ScanRampUp:       rampUp,
+		}
+	}
+
+	assert.NoError(t, validator.Struct(settings(nil)))
+	assert.NoError(t, validator.Struct(settings(&models.ScanRampUp{InitialIntervalMins: aws.Int(30), Scans: aws.Int(5)})))
+	assert.Error(t, validator.Struct(settings(&models.ScanRampUp{InitialIntervalMins: aws.Int(30)})))
+	assert.Error(t, validator.Struct(settings(&models.ScanRampUp{InitialIntervalMins: aws.Int(0), Scans: aws.Int(5)})))
+	assert.Error(t, validator.Struct(settings(&models.ScanRampUp{InitialIntervalMins: aws.Int(30), Scans: aws.Int(51)})))
+}
@@ -0,0 +1,90 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkScanRegions returns an error unless the enabled and excluded regions of an aws-scan integration are regions
+// of the partition Panther is deployed in, and no region is both enabled and excluded.
+func checkScanRegions(integrationType *string, enabledRegions, excludedRegions []*string) error {
+	if len(enabledRegions) == 0 && len(excludedRegions) == 0 {
+		return nil
+	}
+	if aws.StringValue(integrationType) != models.IntegrationTypeAWSScan {
+		return &genericapi.InvalidInputError{Message: "only the scans of aws-scan integrations can be limited to regions"}
+	}
+
+	partition := homePartition()
+	if region := regionOutside(partition, enabledRegions); region != nil {
+		return &genericapi.InvalidInputError{
+			Message: "enabledRegions: " + *region + " is not a region of the " + partition.ID() + " partition"}
+	}
+	if region := regionOutside(partition, excludedRegions); region != nil {
+		return &genericapi.InvalidInputError{
+			Message: "excludedRegions: " + *region + " is not a region of the " + partition.ID() + " partition"}
+	}
+
+	excluded := make(map[string]struct{}, len(excludedRegions))
+	for _, region := range excludedRegions {
+		excluded[*region] = struct{}{}
+	}
+	for _, region := range enabledRegions {
+		if _, ok := excluded[*region]; ok {
+			return &genericapi.InvalidInputError{Message: "enabledRegions: " + *region + " is also excluded"}
+		}
+	}
+	return nil
+}
+
+// checkScanRegionsForUpdate checks the regions of the integration after the update, each list the update doesn't
+// set is kept.
+func checkScanRegionsForUpdate(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	enabledRegions, excludedRegions := input.EnabledRegions, input.ExcludedRegions
+	if enabledRegions == nil {
+		enabledRegions = integration.EnabledRegions
+	}
+	if excludedRegions == nil {
+		excludedRegions = integration.ExcludedRegions
+	}
+	return checkScanRegions(integration.IntegrationType, enabledRegions, excludedRegions)
+}
+
+// regionOutside returns the first of the regions which is not in the partition, nil if there is none.
+func regionOutside(partition endpoints.Partition, regions []*string) *string {
+	for _, region := range regions {
+		if _, ok := partition.Regions()[*region]; !ok {
+			return region
+		}
+	}
+	return nil
+}
+
+// homePartition is the partition of the region of the source API, the aws partition if it is unknown.
+func homePartition() endpoints.Partition {
+	if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), homeRegion); ok {
+		return partition
+	}
+	return endpoints.AwsPartition()
+}
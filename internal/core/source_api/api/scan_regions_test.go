@@ -0,0 +1,143 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func usePartitionOf(t *testing.T, region string) {
+	previousHome := homeRegion
+	homeRegion = region
+	t.Cleanup(func() { homeRegion = previousHome })
+}
+
+func TestPutIntegrationScanRegions(t *testing.T) {
+	usePartitionOf(t, "us-west-2")
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:    aws.String(testAccountID),
+				IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+				ScanEnabled:     aws.Bool(true),
+				UserID:          aws.String(testUserID),
+				EnabledRegions:  aws.StringSlice([]string{"us-east-1", "us-west-2"}),
+				ExcludedRegions: aws.StringSlice([]string{"eu-west-1"}),
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+
+	// The regions are stored with the integration
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.Equal(t, aws.StringSlice([]string{"us-east-1", "us-west-2"}), stored.EnabledRegions)
+	assert.Equal(t, aws.StringSlice([]string{"eu-west-1"}), stored.ExcludedRegions)
+
+	// And every scan is limited to them
+	require.NotEmpty(t, mockSQS.Calls)
+	for _, call := range mockSQS.Calls {
+		for _, entry := range call.Arguments.Get(0).(*sqs.SendMessageBatchInput).Entries {
+			var msg pollermodels.ScanMsg
+			require.NoError(t, jsoniter.UnmarshalFromString(*entry.MessageBody, &msg))
+			require.Len(t, msg.Entries, 1)
+			assert.Equal(t, stored.EnabledRegions, msg.Entries[0].EnabledRegions)
+			assert.Equal(t, stored.ExcludedRegions, msg.Entries[0].ExcludedRegions)
+		}
+	}
+}
+
+func TestCheckScanRegions(t *testing.T) {
+	usePartitionOf(t, "us-west-2")
+	scan := aws.String(models.IntegrationTypeAWSScan)
+
+	assert.NoError(t, checkScanRegions(aws.String(models.IntegrationTypeAWS3), nil, []*string{}))
+	assert.NoError(t, checkScanRegions(scan, aws.StringSlice([]string{"us-east-1", "eu-west-1"}), nil))
+	assert.NoError(t, checkScanRegions(scan, nil, aws.StringSlice([]string{"ap-south-1"})))
+
+	for _, check := range []struct {
+		integrationType          *string
+		enabled, excluded, error []string
+	}{
+		{aws.String(models.IntegrationTypeAWS3), []string{"us-east-1"}, nil, nil},
+		{scan, []string{"us-east-1", "cn-north-1"}, nil, []string{"enabledRegions: cn-north-1"}},
+		{scan, nil, []string{"us-gov-west-1"}, []string{"excludedRegions: us-gov-west-1"}},
+		{scan, []string{"us-east-1", "eu-west-1"}, []string{"eu-west-1"}, []string{"eu-west-1 is also excluded"}},
+	} {
+		err := checkScanRegions(check.integrationType, aws.StringSlice(check.enabled), aws.StringSlice(check.excluded))
+		assert.IsType(t, &genericapi.InvalidInputError{}, err)
+		for _, message := range check.error {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
+
+func TestCheckScanRegionsOtherPartition(t *testing.T) {
+	usePartitionOf(t, "cn-north-1")
+	scan := aws.String(models.IntegrationTypeAWSScan)
+
+	assert.NoError(t, checkScanRegions(scan, aws.StringSlice([]string{"cn-northwest-1"}), nil))
+	err := checkScanRegions(scan, aws.StringSlice([]string{"us-east-1"}), nil)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Contains(t, err.Error(), "aws-cn partition")
+}
+
+func TestCheckScanRegionsForUpdate(t *testing.T) {
+	usePartitionOf(t, "us-west-2")
+	integration := &models.SourceIntegrationMetadata{
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+		EnabledRegions:  aws.StringSlice([]string{"us-east-1", "eu-west-1"}),
+	}
+
+	// The stored enabled regions are kept, and one of them is now excluded
+	err := checkScanRegionsForUpdate(integration, &models.UpdateIntegrationSettingsInput{
+		ExcludedRegions: aws.StringSlice([]string{"eu-west-1"}),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	// Unless they are replaced by the update
+	assert.NoError(t, checkScanRegionsForUpdate(integration, &models.UpdateIntegrationSettingsInput{
+		EnabledRegions:  aws.StringSlice([]string{"us-east-1"}),
+		ExcludedRegions: aws.StringSlice([]string{"eu-west-1"}),
+	}))
+}
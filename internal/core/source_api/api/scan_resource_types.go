@@ -0,0 +1,94 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	awspoller "github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkResourceTypes returns an error unless the allowed and denied resource types of an aws-scan integration are
+// types the snapshot pollers scan, and at least one type is left to scan.
+func checkResourceTypes(integrationType *string, allowList, denyList []*string) error {
+	if len(allowList) == 0 && len(denyList) == 0 {
+		return nil
+	}
+	if aws.StringValue(integrationType) != models.IntegrationTypeAWSScan {
+		return &genericapi.InvalidInputError{Message: "only the scans of aws-scan integrations can be limited to resource types"}
+	}
+	if resourceType := unknownResourceType(allowList); resourceType != nil {
+		return &genericapi.InvalidInputError{Message: "resourceTypeAllowList: " + *resourceType + " is not a scanned resource type"}
+	}
+	if resourceType := unknownResourceType(denyList); resourceType != nil {
+		return &genericapi.InvalidInputError{Message: "resourceTypeDenyList: " + *resourceType + " is not a scanned resource type"}
+	}
+
+	scope := &models.SourceIntegrationMetadata{ResourceTypeAllowList: allowList, ResourceTypeDenyList: denyList}
+	for resourceType := range awspoller.ServicePollers {
+		if resourceTypeInScope(scope, resourceType) {
+			return nil
+		}
+	}
+	return &genericapi.InvalidInputError{Message: "resourceTypeDenyList: every allowed resource type is denied"}
+}
+
+// checkResourceTypesForUpdate checks the resource types of the integration after the update, each list the update
+// doesn't set is kept.
+func checkResourceTypesForUpdate(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	allowList, denyList := input.ResourceTypeAllowList, input.ResourceTypeDenyList
+	if allowList == nil {
+		allowList = integration.ResourceTypeAllowList
+	}
+	if denyList == nil {
+		denyList = integration.ResourceTypeDenyList
+	}
+	return checkResourceTypes(integration.IntegrationType, allowList, denyList)
+}
+
+// unknownResourceType returns the first of the resource types which no snapshot poller scans, nil if there is none.
+func unknownResourceType(resourceTypes []*string) *string {
+	for _, resourceType := range resourceTypes {
+		if _, ok := awspoller.ServicePollers[*resourceType]; !ok {
+			return resourceType
+		}
+	}
+	return nil
+}
+
+// resourceTypeInScope returns whether the integration is scanned for the resource type: only the allowed types are
+// when there are some, and never the denied types.
+func resourceTypeInScope(integration *models.SourceIntegrationMetadata, resourceType string) bool {
+	for _, denied := range integration.ResourceTypeDenyList {
+		if *denied == resourceType {
+			return false
+		}
+	}
+	if len(integration.ResourceTypeAllowList) == 0 {
+		return true
+	}
+	for _, allowed := range integration.ResourceTypeAllowList {
+		if *allowed == resourceType {
+			return true
+		}
+	}
+	return false
+}
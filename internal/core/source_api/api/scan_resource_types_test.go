@@ -0,0 +1,189 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	awspoller "github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// mockScanQueue records the scans sent to the snapshot pollers.
+//
+// They are read as each batch is sent, the entries of the input change from one batch to the next.
+func mockScanQueue(t *testing.T, scans *[]*pollermodels.ScanEntry) *mockSQSClient {
+	snapshotPollersQueueURL = "test-url"
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Run(func(args mock.Arguments) {
+		for _, entry := range args.Get(0).(*sqs.SendMessageBatchInput).Entries {
+			var msg pollermodels.ScanMsg
+			require.NoError(t, jsoniter.UnmarshalFromString(*entry.MessageBody, &msg))
+			*scans = append(*scans, msg.Entries...)
+		}
+	}).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+	return mockSQS
+}
+
+func resourceTypesOf(scans []*pollermodels.ScanEntry) []string {
+	resourceTypes := make([]string, len(scans))
+	for i, scan := range scans {
+		resourceTypes[i] = *scan.ResourceType
+	}
+	return resourceTypes
+}
+
+func TestScanAllResourcesResourceTypes(t *testing.T) {
+	var scans []*pollermodels.ScanEntry
+	mockScanQueue(t, &scans)
+
+	require.NoError(t, ScanAllResources([]*models.SourceIntegrationMetadata{
+		{
+			AWSAccountID:          aws.String(testAccountID),
+			CreatedAtTime:         aws.Time(time.Time{}),
+			IntegrationID:         aws.String(testIntegrationID),
+			IntegrationType:       aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:           aws.Bool(true),
+			ResourceTypeAllowList: aws.StringSlice([]string{"AWS.IAM.Role", "AWS.IAM.User", "AWS.S3.Bucket"}),
+			ResourceTypeDenyList:  aws.StringSlice([]string{"AWS.IAM.User"}),
+		},
+	}))
+	assert.ElementsMatch(t, []string{"AWS.IAM.Role", "AWS.S3.Bucket"}, resourceTypesOf(scans))
+}
+
+func TestScanAllResourcesDeniedResourceTypes(t *testing.T) {
+	var scans []*pollermodels.ScanEntry
+	mockScanQueue(t, &scans)
+
+	require.NoError(t, ScanAllResources([]*models.SourceIntegrationMetadata{
+		{
+			AWSAccountID:         aws.String(testAccountID),
+			IntegrationID:        aws.String(testIntegrationID),
+			IntegrationType:      aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:          aws.Bool(true),
+			ResourceTypeDenyList: aws.StringSlice([]string{"AWS.IAM.User"}),
+		},
+	}))
+	resourceTypes := resourceTypesOf(scans)
+	assert.Len(t, resourceTypes, len(awspoller.ServicePollers)-1)
+	assert.NotContains(t, resourceTypes, "AWS.IAM.User")
+}
+
+func TestScanAllResourcesLimits(t *testing.T) {
+	snapshotPollersQueueURL = "test-url"
+	var entries []*sqs.SendMessageBatchRequestEntry
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("SendMessageBatch", mock.Anything).Run(func(args mock.Arguments) {
+		entries = append(entries, args.Get(0).(*sqs.SendMessageBatchInput).Entries...)
+	}).Return(&sqs.SendMessageBatchOutput{}, nil)
+	SQSClient = mockSQS
+
+	require.NoError(t, ScanAllResources([]*models.SourceIntegrationMetadata{
+		{
+			AWSAccountID:          aws.String(testAccountID),
+			IntegrationID:         aws.String(testIntegrationID),
+			IntegrationType:       aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:           aws.Bool(true),
+			ResourceTypeAllowList: aws.StringSlice([]string{"AWS.IAM.Role", "AWS.IAM.User", "AWS.S3.Bucket"}),
+			MaxConcurrentObjects:  aws.Int(2),
+			MaxObjectsPerScan:     aws.Int(100),
+		},
+	}))
+
+	// The resource types share the objects of the scan, two of them are scanned at once
+	require.Len(t, entries, 3)
+	var delays []int64
+	for _, entry := range entries {
+		var msg pollermodels.ScanMsg
+		require.NoError(t, jsoniter.UnmarshalFromString(*entry.MessageBody, &msg))
+		assert.Equal(t, 33, *msg.Entries[0].MaxResources)
+		delays = append(delays, aws.Int64Value(entry.DelaySeconds))
+	}
+	assert.Equal(t, []int64{0, 0, int64(scanWaveDelay / time.Second)}, delays)
+}
+
+func TestScanRequestEntriesLongestDelay(t *testing.T) {
+	integration := &models.SourceIntegrationMetadata{
+		AWSAccountID:         aws.String(testAccountID),
+		IntegrationID:        aws.String(testIntegrationID),
+		MaxConcurrentObjects: aws.Int(1),
+	}
+	resourceTypes := make([]string, 0, len(awspoller.ServicePollers))
+	for resourceType := range awspoller.ServicePollers {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+
+	entries, err := scanRequestEntries(integration, nil, resourceTypes)
+	require.NoError(t, err)
+	assert.Nil(t, entries[0].DelaySeconds)
+	assert.Equal(t, int64(maxSQSDelaySecs), *entries[len(entries)-1].DelaySeconds)
+}
+
+func TestCheckResourceTypes(t *testing.T) {
+	scan := aws.String(models.IntegrationTypeAWSScan)
+
+	assert.NoError(t, checkResourceTypes(aws.String(models.IntegrationTypeAWS3), nil, []*string{}))
+	assert.NoError(t, checkResourceTypes(scan, aws.StringSlice([]string{"AWS.IAM.Role", "AWS.S3.Bucket"}), nil))
+	assert.NoError(t, checkResourceTypes(scan, nil, aws.StringSlice([]string{"AWS.EC2.Instance"})))
+
+	for _, check := range []struct {
+		integrationType *string
+		allow, deny     []string
+		message         string
+	}{
+		{aws.String(models.IntegrationTypeAWS3), []string{"AWS.IAM.Role"}, nil, "only the scans of aws-scan integrations"},
+		{scan, []string{"AWS.IAM.Role", "AWS.Nothing"}, nil, "resourceTypeAllowList: AWS.Nothing"},
+		{scan, nil, []string{"AWS.Nothing"}, "resourceTypeDenyList: AWS.Nothing"},
+		{scan, []string{"AWS.IAM.Role"}, []string{"AWS.IAM.Role"}, "every allowed resource type is denied"},
+	} {
+		err := checkResourceTypes(check.integrationType, aws.StringSlice(check.allow), aws.StringSlice(check.deny))
+		assert.IsType(t, &genericapi.InvalidInputError{}, err)
+		assert.Contains(t, err.Error(), check.message)
+	}
+}
+
+func TestCheckResourceTypesForUpdate(t *testing.T) {
+	integration := &models.SourceIntegrationMetadata{
+		IntegrationType:       aws.String(models.IntegrationTypeAWSScan),
+		ResourceTypeAllowList: aws.StringSlice([]string{"AWS.IAM.Role"}),
+	}
+
+	// The stored allow list is kept, and its only type is now denied
+	err := checkResourceTypesForUpdate(integration, &models.UpdateIntegrationSettingsInput{
+		ResourceTypeDenyList: aws.StringSlice([]string{"AWS.IAM.Role"}),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	// An empty allow list scans every type again
+	assert.NoError(t, checkResourceTypesForUpdate(integration, &models.UpdateIntegrationSettingsInput{
+		ResourceTypeAllowList: []*string{},
+		ResourceTypeDenyList:  aws.StringSlice([]string{"AWS.IAM.Role"}),
+	}))
+}
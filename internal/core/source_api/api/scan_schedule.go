@@ -0,0 +1,159 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	defaultDueForScanPageSize = 25
+
+	// Bounds the read capacity a page uses when most of the due integrations are filtered out
+	maxDueForScanQueries = 10
+)
+
+// Integration types which are scanned on a schedule, in the order they are paged through
+var scheduledIntegrationTypes = []string{models.IntegrationTypeAWSScan, models.IntegrationTypeAWS3}
+
+// dueForScanPosition is where the next page starts, it's encoded in the LastEvaluatedKey.
+type dueForScanPosition struct {
+	TypeIndex int              `json:"typeIndex"`
+	Key       *ddb.ScheduleKey `json:"key,omitempty"`
+}
+
+// GetIntegrationsDueForScan returns a page of the enabled integrations whose next scan is due, and which
+// are not being scanned.
+//
+// Integrations are only indexed by their next scan time once it's set, when they are added or their
+// schedule is refreshed after an update or a scan.
+func (API) GetIntegrationsDueForScan(input *models.GetIntegrationsDueForScanInput) (*models.IntegrationsDueForScanPage, error) {
+	integrationTypes := scheduledIntegrationTypes
+	if input.IntegrationType != nil {
+		integrationTypes = []string{*input.IntegrationType}
+	}
+
+	var position dueForScanPosition
+	if input.ExclusiveStartKey != nil {
+		decoded, err := base64.URLEncoding.DecodeString(*input.ExclusiveStartKey)
+		if err == nil {
+			err = json.Unmarshal(decoded, &position)
+		}
+		if err != nil || position.TypeIndex < 0 || position.TypeIndex >= len(integrationTypes) {
+			return nil, &genericapi.InvalidInputError{Message: "invalid exclusiveStartKey"}
+		}
+	}
+	pageSize := defaultDueForScanPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+
+	page := &models.IntegrationsDueForScanPage{Integrations: make([]*models.SourceIntegration, 0, pageSize)}
+	for queries := 0; position.TypeIndex < len(integrationTypes); queries++ {
+		if queries == maxDueForScanQueries || len(page.Integrations) == pageSize {
+			page.LastEvaluatedKey = encodeDueForScanPosition(position)
+			return page, nil
+		}
+
+		integrations, last, err := db.ListDueForScan(
+			integrationTypes[position.TypeIndex], *input.Now, pageSize-len(page.Integrations), position.Key)
+		if err != nil {
+			return nil, err
+		}
+		page.Integrations = append(page.Integrations, integrations...)
+		if last == nil {
+			// Done with this type
+			position = dueForScanPosition{TypeIndex: position.TypeIndex + 1}
+		} else {
+			position.Key = last
+		}
+	}
+	return page, nil
+}
+
+// hasScanSchedule returns true if the integration is scanned at an interval or on a cron schedule.
+func hasScanSchedule(integration *models.SourceIntegrationMetadata) bool {
+	return integration.ScanIntervalMins != nil || scanSchedule(integration) != nil
+}
+
+// scanSchedule returns the cron schedule of an integration, nil if it's scanned at its interval.
+func scanSchedule(integration *models.SourceIntegrationMetadata) *models.CronSchedule {
+	if aws.StringValue(integration.ScanSchedule) == "" {
+		return nil
+	}
+	schedule, err := models.ParseCronSchedule(*integration.ScanSchedule)
+	if err != nil {
+		// Rejected by the validator
+		return nil
+	}
+	return schedule
+}
+
+func encodeDueForScanPosition(position dueForScanPosition) *string {
+	// This can't fail, the struct only has basic types
+	encoded, _ := json.Marshal(position)
+	return aws.String(base64.URLEncoding.EncodeToString(encoded))
+}
+
+// refreshScanSchedule stores when the next scan of an enabled integration is due, after its settings or scans changed.
+//
+// The next scan is computed from the last one, or from when the integration was added, so that it only changes
+// when the schedule does. The refreshed integration is returned, the given one if nothing changed.
+func refreshScanSchedule(integration *models.SourceIntegration) (*models.SourceIntegration, error) {
+	if integration == nil || integration.SourceIntegrationMetadata == nil ||
+		!aws.BoolValue(integration.ScanEnabled) || !hasScanSchedule(integration.SourceIntegrationMetadata) {
+
+		return integration, nil
+	}
+
+	from := time.Now()
+	switch {
+	case integration.SourceIntegrationScanInformation != nil && integration.LastScanEndTime != nil:
+		from = *integration.LastScanEndTime
+	case integration.CreatedAtTime != nil:
+		from = *integration.CreatedAtTime
+	}
+	next := ddb.ScheduleTime(NextScanTime(integration, from))
+	if integration.SourceIntegrationScanInformation != nil && integration.NextScanTime != nil &&
+		integration.NextScanTime.Equal(next) {
+
+		return integration, nil
+	}
+
+	result := integration
+	err := runSideEffect(sideEffectSchedule, integration.IntegrationID, func() error {
+		refreshed, err := db.UpdateItem(&ddb.UpdateIntegrationItem{
+			IntegrationID: integration.IntegrationID,
+			NextScanTime:  aws.Time(next),
+		})
+		if err == nil {
+			result = refreshed
+		}
+		return err
+	})
+	return result, err
+}
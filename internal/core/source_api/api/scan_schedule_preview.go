@@ -0,0 +1,103 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// Bounds the projected scans of an integration, e.g. while it ramps up from a short initial interval
+const maxProjectedScans = 500
+
+// GetScanSchedulePreview projects when the scheduler would start the scans of the enabled integrations in a window.
+//
+// After each projected scan the next one is computed the way the scheduler does, honoring the scan interval,
+// the ramp-up and the blackout windows. Scans which are already due are projected at the start of the window.
+func (API) GetScanSchedulePreview(input *models.GetScanSchedulePreviewInput) (*models.ScanSchedulePreview, error) {
+	start := time.Now().UTC()
+	if input.Now != nil {
+		start = *input.Now
+	}
+	end := start.Add(time.Duration(*input.WindowHours) * time.Hour)
+
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.ScanSchedulePreview{
+		WindowStart: aws.Time(start),
+		WindowEnd:   aws.Time(end),
+		Scans:       make([]*models.ProjectedScan, 0),
+	}
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil || !scheduled(integration.SourceIntegrationMetadata) {
+			continue
+		}
+		preview.Scans = append(preview.Scans, projectScans(integration, start, end)...)
+	}
+	sort.SliceStable(preview.Scans, func(i, j int) bool {
+		return preview.Scans[i].ScanTime.Before(*preview.Scans[j].ScanTime)
+	})
+	return preview, nil
+}
+
+// projectScans returns the scans of an integration starting in the window, each one following the previous one.
+func projectScans(integration *models.SourceIntegration, start, end time.Time) []*models.ProjectedScan {
+	var scanInfo models.SourceIntegrationScanInformation
+	if integration.SourceIntegrationScanInformation != nil {
+		scanInfo = *integration.SourceIntegrationScanInformation
+	}
+	projected := &models.SourceIntegration{
+		SourceIntegrationMetadata:        integration.SourceIntegrationMetadata,
+		SourceIntegrationScanInformation: &scanInfo,
+	}
+	withoutBlackouts := *integration.SourceIntegrationMetadata
+	withoutBlackouts.BlackoutWindows = nil
+	unblocked := &models.SourceIntegration{
+		SourceIntegrationMetadata:        &withoutBlackouts,
+		SourceIntegrationScanInformation: &scanInfo,
+	}
+
+	var scans []*models.ProjectedScan
+	for from := start; len(scans) < maxProjectedScans; {
+		next := NextScanTime(projected, from)
+		if !next.Before(end) {
+			break
+		}
+		scans = append(scans, &models.ProjectedScan{
+			IntegrationID:       integration.IntegrationID,
+			IntegrationLabel:    integration.IntegrationLabel,
+			IntegrationType:     integration.IntegrationType,
+			ScanTime:            aws.Time(next),
+			PostponedByBlackout: aws.Bool(next.After(NextScanTime(unblocked, from))),
+		})
+
+		// The projected scan becomes the last one
+		scanInfo.LastScanEndTime = aws.Time(next)
+		scanInfo.CompletedScans = aws.Int(aws.IntValue(scanInfo.CompletedScans) + 1)
+		from = next
+	}
+	return scans
+}
@@ -0,0 +1,116 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+// 2020-03-02 is a Monday
+var previewStart = time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)
+
+func scheduledItem(t *testing.T, integrationID string, intervalMins int, lastScanEnd time.Time,
+	windows ...*models.BlackoutWindow) map[string]*dynamodb.AttributeValue {
+
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String(integrationID),
+			IntegrationLabel: aws.String(integrationID),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+			ScanIntervalMins: aws.Int(intervalMins),
+			BlackoutWindows:  windows,
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{LastScanEndTime: aws.Time(lastScanEnd)},
+	})
+	require.NoError(t, err)
+	return item
+}
+
+func projectedTimes(preview *models.ScanSchedulePreview) map[string][]string {
+	result := make(map[string][]string)
+	for _, scan := range preview.Scans {
+		result[*scan.IntegrationID] = append(result[*scan.IntegrationID], scan.ScanTime.Format("Jan 2 15:04"))
+	}
+	return result
+}
+
+func TestGetScanSchedulePreviewRespectsBlackouts(t *testing.T) {
+	disabled := scheduledItem(t, "disabled", 60, previewStart)
+	disabled["scanEnabled"] = &dynamodb.AttributeValue{BOOL: aws.Bool(false)}
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+		scheduledItem(t, "every-6h", 360, previewStart.Add(-6*time.Hour),
+			&models.BlackoutWindow{DailyStart: aws.String("11:00"), DurationMins: aws.Int(120)}),
+		scheduledItem(t, "daily", 1440, previewStart.Add(-2*time.Hour),
+			&models.BlackoutWindow{Start: aws.Time(previewStart.Add(20 * time.Hour)), End: aws.Time(previewStart.Add(23 * time.Hour))}),
+		disabled,
+	}}, TableName: "test"}
+
+	preview, err := apiTest.GetScanSchedulePreview(&models.GetScanSchedulePreviewInput{
+		WindowHours: aws.Int(24),
+		Now:         aws.Time(previewStart),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, previewStart.Add(24*time.Hour), *preview.WindowEnd)
+	assert.Equal(t, map[string][]string{
+		// The scan due at 12:00 is postponed to the end of the daily window
+		"every-6h": {"Mar 2 00:00", "Mar 2 06:00", "Mar 2 13:00", "Mar 2 19:00"},
+		// The scan due at 22:00 is postponed to the end of the one-off window
+		"daily": {"Mar 2 23:00"},
+	}, projectedTimes(preview))
+
+	var postponed []string
+	for i, scan := range preview.Scans {
+		if i > 0 {
+			assert.False(t, scan.ScanTime.Before(*preview.Scans[i-1].ScanTime), "the timeline is sorted")
+		}
+		if *scan.PostponedByBlackout {
+			postponed = append(postponed, *scan.IntegrationID+" "+scan.ScanTime.Format("15:04"))
+		}
+	}
+	assert.Equal(t, []string{"every-6h 13:00", "daily 23:00"}, postponed)
+}
+
+func TestGetScanSchedulePreviewRampUp(t *testing.T) {
+	item := scheduledItem(t, "ramping-up", 1440, previewStart.Add(-time.Hour))
+	rampUp, err := dynamodbattribute.Marshal(&models.ScanRampUp{InitialIntervalMins: aws.Int(60), Scans: aws.Int(2)})
+	require.NoError(t, err)
+	item["scanRampUp"] = rampUp
+	item["completedScans"] = &dynamodb.AttributeValue{N: aws.String("1")}
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{MockScanAttributes: []map[string]*dynamodb.AttributeValue{item}}, TableName: "test"}
+
+	preview, err := apiTest.GetScanSchedulePreview(&models.GetScanSchedulePreviewInput{
+		WindowHours: aws.Int(24),
+		Now:         aws.Time(previewStart),
+	})
+	require.NoError(t, err)
+	// The interval grows from 1 hour to 294 minutes, then to the steady day once the ramp-up is over
+	assert.Equal(t, map[string][]string{"ramping-up": {"Mar 2 00:00", "Mar 2 04:54"}}, projectedTimes(preview))
+}
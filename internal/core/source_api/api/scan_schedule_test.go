@@ -0,0 +1,260 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var scheduleNow = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// mockScheduleIndexClient imitates the next scan time index over the seeded integrations.
+type mockScheduleIndexClient struct {
+	*modelstest.MockDDBClient
+	integrations []*models.SourceIntegration
+	queries      []*dynamodb.QueryInput
+}
+
+func (client *mockScheduleIndexClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	client.queries = append(client.queries, input)
+
+	// The key condition compares the integration type and the time given as values
+	var integrationType string
+	var now time.Time
+	for _, value := range input.ExpressionAttributeValues {
+		if value.S == nil {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, *value.S); err == nil {
+			now = t
+		} else if *value.S == models.IntegrationTypeAWSScan || *value.S == models.IntegrationTypeAWS3 {
+			integrationType = *value.S
+		}
+	}
+
+	var indexed []*models.SourceIntegration
+	for _, integration := range client.integrations {
+		if *integration.IntegrationType == integrationType && integration.NextScanTime != nil &&
+			!integration.NextScanTime.After(now) {
+
+			indexed = append(indexed, integration)
+		}
+	}
+	sort.Slice(indexed, func(i, j int) bool { return indexed[i].NextScanTime.Before(*indexed[j].NextScanTime) })
+	if input.ExclusiveStartKey != nil {
+		for i, integration := range indexed {
+			if *integration.IntegrationID == *input.ExclusiveStartKey["integrationId"].S {
+				indexed = indexed[i+1:]
+				break
+			}
+		}
+	}
+
+	output := &dynamodb.QueryOutput{}
+	for i, integration := range indexed {
+		if int64(i) == *input.Limit {
+			last := indexed[i-1]
+			output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{
+				"integrationId":   {S: last.IntegrationID},
+				"integrationType": {S: last.IntegrationType},
+				"nextScanTime":    {S: aws.String(last.NextScanTime.Format(time.RFC3339))},
+			}
+			break
+		}
+		// The filter expression
+		if !aws.BoolValue(integration.ScanEnabled) || aws.StringValue(integration.ScanStatus) == models.StatusScanning {
+			continue
+		}
+		item, err := dynamodbattribute.MarshalMap(integration)
+		if err != nil {
+			return nil, err
+		}
+		output.Items = append(output.Items, item)
+	}
+	return output, nil
+}
+
+func scheduledIntegration(id, integrationType string, nextScan time.Duration, enabled bool, status string) *models.SourceIntegration {
+	return &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String(id),
+			IntegrationType:  aws.String(integrationType),
+			ScanEnabled:      aws.Bool(enabled),
+			ScanIntervalMins: aws.Int(60),
+		},
+		SourceIntegrationStatus: &models.SourceIntegrationStatus{ScanStatus: aws.String(status)},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			NextScanTime: aws.Time(scheduleNow.Add(nextScan)),
+		},
+	}
+}
+
+func mockScheduleIndex() *mockScheduleIndexClient {
+	client := &mockScheduleIndexClient{
+		MockDDBClient: &modelstest.MockDDBClient{},
+		integrations: []*models.SourceIntegration{
+			scheduledIntegration("due-1", models.IntegrationTypeAWSScan, -time.Hour, true, models.StatusOK),
+			scheduledIntegration("due-2", models.IntegrationTypeAWSScan, -2*time.Hour, true, models.StatusError),
+			scheduledIntegration("not-due", models.IntegrationTypeAWSScan, time.Hour, true, models.StatusOK),
+			scheduledIntegration("disabled", models.IntegrationTypeAWSScan, -time.Hour, false, models.StatusOK),
+			scheduledIntegration("scanning", models.IntegrationTypeAWSScan, -time.Hour, true, models.StatusScanning),
+			scheduledIntegration("due-logs", models.IntegrationTypeAWS3, 0, true, models.StatusOK),
+			scheduledIntegration("not-due-logs", models.IntegrationTypeAWS3, time.Minute, true, models.StatusOK),
+		},
+	}
+	db = &ddb.DDB{Client: client, TableName: "test"}
+	return client
+}
+
+func integrationIDs(integrations []*models.SourceIntegration) []string {
+	ids := make([]string, len(integrations))
+	for i, integration := range integrations {
+		ids[i] = *integration.IntegrationID
+	}
+	return ids
+}
+
+func TestGetIntegrationsDueForScan(t *testing.T) {
+	client := mockScheduleIndex()
+
+	result, err := apiTest.GetIntegrationsDueForScan(&models.GetIntegrationsDueForScanInput{Now: aws.Time(scheduleNow)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"due-2", "due-1", "due-logs"}, integrationIDs(result.Integrations))
+	assert.Nil(t, result.LastEvaluatedKey)
+	require.Len(t, client.queries, 2)
+	for _, query := range client.queries {
+		assert.Equal(t, "next-scan-time-index", *query.IndexName)
+	}
+}
+
+func TestGetIntegrationsDueForScanType(t *testing.T) {
+	client := mockScheduleIndex()
+
+	result, err := apiTest.GetIntegrationsDueForScan(&models.GetIntegrationsDueForScanInput{
+		Now:             aws.Time(scheduleNow),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"due-logs"}, integrationIDs(result.Integrations))
+	assert.Len(t, client.queries, 1)
+}
+
+func TestGetIntegrationsDueForScanPages(t *testing.T) {
+	mockScheduleIndex()
+
+	input := &models.GetIntegrationsDueForScanInput{Now: aws.Time(scheduleNow), PageSize: aws.Int(1)}
+	var ids []string
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+		result, err := apiTest.GetIntegrationsDueForScan(input)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(result.Integrations), 1)
+		ids = append(ids, integrationIDs(result.Integrations)...)
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+	assert.Equal(t, []string{"due-2", "due-1", "due-logs"}, ids)
+}
+
+func TestGetIntegrationsDueForScanNone(t *testing.T) {
+	mockScheduleIndex()
+
+	result, err := apiTest.GetIntegrationsDueForScan(&models.GetIntegrationsDueForScanInput{
+		Now: aws.Time(scheduleNow.Add(-24 * time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, result.Integrations)
+	assert.Empty(t, result.Integrations)
+}
+
+func TestGetIntegrationsDueForScanInvalidKey(t *testing.T) {
+	mockScheduleIndex()
+
+	result, err := apiTest.GetIntegrationsDueForScan(&models.GetIntegrationsDueForScanInput{
+		Now:               aws.Time(scheduleNow),
+		ExclusiveStartKey: aws.String("not-a-key"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestUpdateIntegrationLastScanEndSchedulesNextScan(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	lastScanEnd := scheduleNow.Add(500 * time.Millisecond)
+	item, err := dynamodbattribute.MarshalMap(&models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String(testIntegrationID),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+			ScanIntervalMins: aws.Int(60),
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{LastScanEndTime: aws.Time(lastScanEnd)},
+	})
+	require.NoError(t, err)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+
+	_, err = apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		LastScanEndTime: aws.Time(lastScanEnd),
+		ScanStatus:      aws.String(models.StatusOK),
+	})
+	require.NoError(t, err)
+
+	// The scan end is written, then the next scan time truncated to the second
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 2)
+	refresh := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var values []string
+	for _, value := range refresh.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.Contains(t, values, "2020-06-01T13:00:00Z")
+}
+
+func TestUpdateIntegrationLastScanEndDisabledNotScheduled(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: getItem(models.IntegrationTypeAWSScan).Item}, nil)
+
+	_, err := apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		LastScanEndTime: aws.Time(scheduleNow),
+		ScanStatus:      aws.String(models.StatusOK),
+	})
+	require.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 1)
+}
@@ -0,0 +1,92 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/iam"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkSessionDurationSettings rejects a session duration on an integration without logs, or one longer than
+// AWS allows for the role chain of the integration.
+//
+// The range is validated with the input, the maximum of the role is verified by the health check.
+func checkSessionDurationSettings(integrationType *string, healthCheckInput *models.CheckIntegrationInput) error {
+	duration := healthCheckInput.SessionDurationSeconds
+	if duration == nil {
+		return nil
+	}
+	if aws.StringValue(integrationType) == models.IntegrationTypeAWSScan {
+		return &genericapi.InvalidInputError{Message: "only the roles of log integrations have a session duration"}
+	}
+	if len(healthCheckInput.RoleChain) > 0 && *duration > models.MaxChainedSessionDurationSeconds {
+		return &genericapi.InvalidInputError{Message: fmt.Sprintf(
+			"sessionDurationSeconds: sessions of a role chain last at most %d seconds", models.MaxChainedSessionDurationSeconds)}
+	}
+	return nil
+}
+
+// checkSessionDuration compares the session duration of an integration to the maximum of the role the logs are
+// read with.
+//
+// The onboarding template doesn't allow the role to read itself (iam:GetRole), the check is inconclusive until
+// the customer grants it.
+func checkSessionDuration(
+	roleCredentials *credentials.Credentials, roleARN string, durationSeconds int) models.SourceIntegrationItemStatus {
+
+	start := time.Now()
+	role, err := iamClientFunc(roleCredentials).GetRole(&iam.GetRoleInput{
+		RoleName: aws.String(roleARN[strings.LastIndex(roleARN, "/")+1:]),
+	})
+	if err != nil {
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			Inconclusive:  aws.Bool(true),
+			ErrorMessage:  aws.String("failed to read the maximum session duration of the role: " + err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	// Roles which don't set a maximum allow sessions of an hour
+	maxSeconds := int64(models.DefaultSessionDurationSeconds)
+	if role.Role != nil && role.Role.MaxSessionDuration != nil {
+		maxSeconds = *role.Role.MaxSessionDuration
+	}
+	if int64(durationSeconds) > maxSeconds {
+		return models.SourceIntegrationItemStatus{
+			Healthy: aws.Bool(false),
+			ErrorMessage: aws.String(fmt.Sprintf(
+				"sessionDurationSeconds: %d seconds exceeds the maximum session duration of %d seconds of role %s",
+				durationSeconds, maxSeconds, roleARN)),
+			LatencyMillis: millisSince(start),
+		}
+	}
+	return models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(true),
+		LatencyMillis: millisSince(start),
+	}
+}
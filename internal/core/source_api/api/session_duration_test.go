@@ -0,0 +1,166 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func (client *mockIAMClient) GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*iam.GetRoleOutput), args.Error(1)
+}
+
+// mockMaxSessionDuration makes the log processing role allow sessions up to the given duration.
+func mockMaxSessionDuration(maxSeconds int64) *mockIAMClient {
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil)
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+
+	mockIAM := &mockIAMClient{}
+	mockIAM.On("ListRolePolicies", mock.Anything).
+		Return(&iam.ListRolePoliciesOutput{}, awserr.New("AccessDenied", "not authorized to perform: iam:ListRolePolicies", nil))
+	mockIAM.On("GetRole", &iam.GetRoleInput{RoleName: aws.String("PantherLogProcessingRole")}).
+		Return(&iam.GetRoleOutput{Role: &iam.Role{MaxSessionDuration: aws.Int64(maxSeconds)}}, nil)
+	iamClientFunc = func(*credentials.Credentials) iamiface.IAMAPI { return mockIAM }
+	return mockIAM
+}
+
+func TestCheckIntegrationSessionDuration(t *testing.T) {
+	mockIAM := mockMaxSessionDuration(4 * 3600)
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:           aws.String(testAccountID),
+		IntegrationType:        aws.String(models.IntegrationTypeAWS3),
+		SessionDurationSeconds: aws.Int(4 * 3600),
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.SessionDurationStatus.Healthy)
+	mockIAM.AssertExpectations(t)
+
+	result, err = apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:           aws.String(testAccountID),
+		IntegrationType:        aws.String(models.IntegrationTypeAWS3),
+		SessionDurationSeconds: aws.Int(6 * 3600),
+	})
+	require.NoError(t, err)
+	assert.False(t, *result.SessionDurationStatus.Healthy)
+	assert.Nil(t, result.SessionDurationStatus.Inconclusive)
+	assert.Equal(t, "sessionDurationSeconds: 21600 seconds exceeds the maximum session duration of 14400 seconds of role "+
+		"arn:aws:iam::123456789012:role/PantherLogProcessingRole", *result.SessionDurationStatus.ErrorMessage)
+}
+
+func TestCheckIntegrationSessionDurationNotReadable(t *testing.T) {
+	mockMaxSessionDuration(3600)
+	deniedIAM := &mockIAMClient{}
+	deniedIAM.On("ListRolePolicies", mock.Anything).
+		Return(&iam.ListRolePoliciesOutput{}, awserr.New("AccessDenied", "not authorized to perform: iam:ListRolePolicies", nil))
+	deniedIAM.On("GetRole", mock.Anything).
+		Return(&iam.GetRoleOutput{}, awserr.New("AccessDenied", "not authorized to perform: iam:GetRole", nil))
+	iamClientFunc = func(*credentials.Credentials) iamiface.IAMAPI { return deniedIAM }
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:           aws.String(testAccountID),
+		IntegrationType:        aws.String(models.IntegrationTypeAWS3),
+		SessionDurationSeconds: aws.Int(7200),
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.SessionDurationStatus.Inconclusive)
+	assert.Contains(t, *result.SessionDurationStatus.ErrorMessage, "iam:GetRole")
+}
+
+func TestUpdateIntegrationSettingsSessionDuration(t *testing.T) {
+	evaluateIntegrationFunc = evaluateIntegration
+	mockMaxSessionDuration(4 * 3600)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"sessionDurationSeconds": {N: aws.String("7200")},
+	}}, nil).Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:          aws.String(testIntegrationID),
+		SessionDurationSeconds: aws.Int(7200),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{N: aws.String("7200")})
+	assert.Equal(t, 7200, *result.SessionDurationSeconds)
+}
+
+func TestUpdateIntegrationSettingsSessionDurationExceedsRoleMax(t *testing.T) {
+	evaluateIntegrationFunc = evaluateIntegration
+	mockMaxSessionDuration(3600)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:          aws.String(testIntegrationID),
+		SessionDurationSeconds: aws.Int(7200),
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "sessionDurationSeconds: 7200 seconds exceeds the maximum " +
+		"session duration of 3600 seconds of role arn:aws:iam::123456789012:role/PantherLogProcessingRole"}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsChainedSessionDuration(t *testing.T) {
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		AWSAccountID:    aws.String(testAccountID),
+		RoleChain:       aws.StringSlice([]string{"arn:aws:iam::123456789012:role/Jump", "arn:aws:iam::210987654321:role/Logs"}),
+	})
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:          aws.String(testIntegrationID),
+		SessionDurationSeconds: aws.Int(7200),
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "sessionDurationSeconds: sessions of a role chain last at most 3600 seconds"}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestSessionDuration(t *testing.T) {
+	assert.Equal(t, time.Hour, (&models.SourceIntegrationMetadata{}).SessionDuration())
+	assert.Equal(t, 2*time.Hour, (&models.SourceIntegrationMetadata{SessionDurationSeconds: aws.Int(7200)}).SessionDuration())
+}
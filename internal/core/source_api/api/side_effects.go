@@ -0,0 +1,73 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+// The side-effect writes which accompany the operations on integrations
+const (
+	sideEffectAudit    = "audit"
+	sideEffectNotify   = "notify"
+	sideEffectSchedule = "schedule"
+	sideEffectRetry    = "retry"
+	sideEffectHistory  = "history"
+)
+
+// The failed side-effect writes are counted in CloudWatch, per kind of side effect
+const (
+	sideEffectFailuresMetric = "SideEffectFailures"
+	sideEffectDimension      = "SideEffect"
+)
+
+var sideEffectMetrics = metrics.NewLogger([]string{sideEffectDimension})
+
+// runSideEffect runs a write which accompanies an operation, such as recording its change, once the operation succeeded.
+//
+// The operation isn't undone if the side effect fails: by default the failure is logged and counted in CloudWatch, and the
+// operation still succeeds. With strict side effects, e.g. when compliance requires every change to be audited,
+// the failure is returned so that the caller learns about it and retries.
+func runSideEffect(kind string, integrationID *string, write func() error) error {
+	err := write()
+	if err == nil {
+		return nil
+	}
+
+	zap.L().Error("side effect failed",
+		zap.String("sideEffect", kind),
+		zap.String("integrationId", aws.StringValue(integrationID)),
+		zap.Bool("strict", strictSideEffects),
+		zap.Error(err))
+	metricErr := sideEffectMetrics.Log(map[string]string{sideEffectDimension: kind},
+		metrics.Metric{Name: sideEffectFailuresMetric, Unit: metrics.UnitCount, Value: 1})
+	if metricErr != nil {
+		zap.L().Warn("failed to publish side effect metrics", zap.Error(metricErr))
+	}
+	if strictSideEffects {
+		return &genericapi.InternalError{Message: fmt.Sprintf("%s side effect failed: %s", kind, err)}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// updateWithFailingAudit updates the label of an integration while the change history can't be written.
+func updateWithFailingAudit(t *testing.T) (*modelstest.MockDDBClient, *models.SourceIntegration, error) {
+	// PutItem of the change record fails
+	mockClient := &modelstest.MockDDBClient{TestErr: true}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", ChangesTableName: "changes"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationLabel:    aws.String("renamed"),
+		AllowDuplicateLabel: aws.Bool(true),
+	})
+	return mockClient, result, err
+}
+
+func TestUpdateIntegrationSettingsSideEffectFailure(t *testing.T) {
+	var out bytes.Buffer
+	defer func(stdout io.Writer) { sideEffectMetrics.Out = stdout }(sideEffectMetrics.Out)
+	sideEffectMetrics.Out = &out
+
+	mockClient, result, err := updateWithFailingAudit(t)
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	mockClient.AssertCalled(t, "UpdateItem", mock.Anything)
+	// The failure is counted
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "audit", line["SideEffect"])
+	assert.Equal(t, float64(1), line["SideEffectFailures"])
+}
+
+func TestUpdateIntegrationSettingsStrictSideEffectFailure(t *testing.T) {
+	strictSideEffects = true
+	defer func() { strictSideEffects = false }()
+
+	mockClient, result, err := updateWithFailingAudit(t)
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.InternalError{}, err)
+	assert.Contains(t, err.Error(), "audit side effect failed")
+	// The primary write was made before the side effect failed
+	mockClient.AssertCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestRunSideEffect(t *testing.T) {
+	assert.NoError(t, runSideEffect(sideEffectNotify, aws.String(testIntegrationID), func() error { return nil }))
+	assert.NoError(t, runSideEffect(sideEffectNotify, aws.String(testIntegrationID), func() error { return errors.New("down") }))
+
+	strictSideEffects = true
+	defer func() { strictSideEffects = false }()
+	assert.NoError(t, runSideEffect(sideEffectNotify, aws.String(testIntegrationID), func() error { return nil }))
+	assert.Error(t, runSideEffect(sideEffectNotify, aws.String(testIntegrationID), func() error { return errors.New("down") }))
+}
+
+// failingChangesClient can't write the change history, the integrations written are counted
+type failingChangesClient struct {
+	*modelstest.MockDDBClient
+	batchWrites int
+}
+
+func (client *failingChangesClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if *input.TableName == "changes" {
+		return nil, errors.New("down")
+	}
+	return client.MockDDBClient.PutItem(input)
+}
+
+func (client *failingChangesClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	client.batchWrites++
+	return client.MockDDBClient.BatchWriteItem(input)
+}
+
+func TestPutIntegrationStrictSideEffectFailure(t *testing.T) {
+	strictSideEffects = true
+	defer func() { strictSideEffects = false }()
+	mockClient := &failingChangesClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test", ChangesTableName: "changes"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	result, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{{
+			AWSAccountID:     aws.String(testAccountID),
+			IntegrationLabel: aws.String(testIntegrationLabel),
+			IntegrationType:  aws.String(testIntegrationType),
+			ScanEnabled:      aws.Bool(true),
+			UserID:           aws.String(testUserID),
+		}},
+	})
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.InternalError{}, err)
+	// The creation fails before the integration is written
+	assert.Equal(t, 0, mockClient.batchWrites)
+}
@@ -0,0 +1,111 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// Scan records read at once from the history while looking for data in the period
+const silentScanPageSize = 100
+
+// ListSilentIntegrations returns the enabled integrations whose scans processed no objects over the period.
+//
+// The scan records of the history are used when the deployment keeps one, otherwise only the last scan of each
+// integration is known. A scan which doesn't report how many objects it processed counts as delivering data, and
+// integrations created within the period aren't silent yet. Those never scanned come first, then the least
+// recently scanned.
+func (API) ListSilentIntegrations(input *models.ListSilentIntegrationsInput) ([]*models.SilentIntegration, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-time.Duration(*input.PeriodMinutes) * time.Minute)
+	silent := make([]*models.SilentIntegration, 0)
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil || !scheduled(integration.SourceIntegrationMetadata) ||
+			aws.TimeValue(integration.CreatedAtTime).After(cutoff) {
+
+			continue
+		}
+		scans, delivered, err := scansInPeriod(integration, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		if delivered {
+			continue
+		}
+		result := &models.SilentIntegration{
+			IntegrationID:    integration.IntegrationID,
+			IntegrationLabel: integration.IntegrationLabel,
+			IntegrationType:  integration.IntegrationType,
+			ScansInPeriod:    aws.Int(scans),
+		}
+		if integration.SourceIntegrationScanInformation != nil {
+			result.LastScanEndTime = integration.LastScanEndTime
+		}
+		silent = append(silent, result)
+	}
+	sort.SliceStable(silent, func(i, j int) bool {
+		return aws.TimeValue(silent[i].LastScanEndTime).Before(aws.TimeValue(silent[j].LastScanEndTime))
+	})
+	return silent, nil
+}
+
+// scansInPeriod counts the scans of an integration which ended after the cutoff, and whether any of them
+// delivered data.
+func scansInPeriod(integration *models.SourceIntegration, cutoff time.Time) (int, bool, error) {
+	if db.HistoryTableName == "" {
+		scan := integration.SourceIntegrationScanInformation
+		if scan == nil || scan.LastScanEndTime == nil || scan.LastScanEndTime.Before(cutoff) {
+			return 0, false, nil
+		}
+		return 1, scan.LastScanObjectsProcessed == nil || *scan.LastScanObjectsProcessed > 0, nil
+	}
+
+	scans := 0
+	var exclusiveStartRecordID *string
+	for {
+		records, lastRecordID, err := db.ListHistory(
+			integration.IntegrationID, aws.String(models.HistoryKindScan), silentScanPageSize, exclusiveStartRecordID)
+		if err != nil {
+			return 0, false, err
+		}
+		for _, record := range records {
+			// The records are the most recent first, the rest of them are older than the period
+			if aws.TimeValue(record.RecordedAt).Before(cutoff) {
+				return scans, false, nil
+			}
+			scans++
+			if record.ObjectsProcessed == nil || *record.ObjectsProcessed > 0 {
+				return scans, true, nil
+			}
+		}
+		if lastRecordID == nil {
+			return scans, false, nil
+		}
+		exclusiveStartRecordID = lastRecordID
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+// mockHistoryClient returns the scan records of each integration from the history, by integration ID.
+type mockHistoryClient struct {
+	*modelstest.MockDDBClient
+	history map[string][]*models.IntegrationHistoryRecord
+}
+
+func (client *mockHistoryClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	for _, value := range input.ExpressionAttributeValues {
+		if records, ok := client.history[aws.StringValue(value.S)]; ok {
+			items := make([]map[string]*dynamodb.AttributeValue, len(records))
+			for i, record := range records {
+				item, err := dynamodbattribute.MarshalMap(record)
+				if err != nil {
+					return nil, err
+				}
+				items[i] = item
+			}
+			return &dynamodb.QueryOutput{Items: items}, nil
+		}
+	}
+	return &dynamodb.QueryOutput{}, nil
+}
+
+func mockSilentIntegrations(t *testing.T, historyTableName string, integrations ...*models.SourceIntegration) *mockHistoryClient {
+	var items []map[string]*dynamodb.AttributeValue
+	for _, integration := range integrations {
+		integration.IntegrationType = aws.String(models.IntegrationTypeAWS3)
+		integration.IntegrationLabel = integration.IntegrationID
+		integration.ScanEnabled = aws.Bool(true)
+		integration.ScanIntervalMins = aws.Int(60)
+		if integration.CreatedAtTime == nil {
+			integration.CreatedAtTime = aws.Time(time.Now().Add(-30 * 24 * time.Hour))
+		}
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	client := &mockHistoryClient{
+		MockDDBClient: &modelstest.MockDDBClient{MockScanAttributes: items},
+		history:       make(map[string][]*models.IntegrationHistoryRecord),
+	}
+	db = &ddb.DDB{Client: client, TableName: "test", HistoryTableName: historyTableName}
+	return client
+}
+
+func scanRecords(objectsProcessed ...int64) []*models.IntegrationHistoryRecord {
+	records := make([]*models.IntegrationHistoryRecord, len(objectsProcessed))
+	for i, objects := range objectsProcessed {
+		records[i] = &models.IntegrationHistoryRecord{
+			Kind:             aws.String(models.HistoryKindScan),
+			RecordedAt:       aws.Time(time.Now().Add(-time.Duration(i+1) * time.Hour)),
+			ScanStatus:       aws.String(models.StatusOK),
+			ObjectsProcessed: aws.Int64(objects),
+		}
+	}
+	return records
+}
+
+func TestListSilentIntegrations(t *testing.T) {
+	now := time.Now()
+	withScan := func(id string, lastScanEnd time.Time) *models.SourceIntegration {
+		return &models.SourceIntegration{
+			SourceIntegrationMetadata: &models.SourceIntegrationMetadata{IntegrationID: aws.String(id)},
+			SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+				LastScanEndTime: aws.Time(lastScanEnd), LastScanObjectsProcessed: aws.Int64(0)},
+		}
+	}
+	client := mockSilentIntegrations(t, "history",
+		withScan("active", now.Add(-time.Hour)),
+		withScan("silent", now.Add(-time.Hour)),
+		withScan("silent-no-recent-scan", now.Add(-72*time.Hour)),
+		// Delivered data before the period only
+		withScan("silent-since-a-while", now.Add(-time.Hour)),
+		&models.SourceIntegration{SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID: aws.String("never-scanned")}},
+		// Too recent to be silent
+		&models.SourceIntegration{SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID: aws.String("just-created"), CreatedAtTime: aws.Time(now.Add(-time.Hour))}},
+	)
+	client.history["active"] = scanRecords(0, 0, 120, 0)
+	client.history["silent"] = scanRecords(0, 0, 0)
+	client.history["silent-since-a-while"] = append(scanRecords(0, 0), &models.IntegrationHistoryRecord{
+		Kind:             aws.String(models.HistoryKindScan),
+		RecordedAt:       aws.Time(now.Add(-48 * time.Hour)),
+		ObjectsProcessed: aws.Int64(500),
+	})
+
+	result, err := apiTest.ListSilentIntegrations(&models.ListSilentIntegrationsInput{PeriodMinutes: aws.Int(24 * 60)})
+	require.NoError(t, err)
+	var ids []string
+	for _, integration := range result {
+		ids = append(ids, *integration.IntegrationID)
+	}
+	assert.Equal(t, []string{"never-scanned", "silent-no-recent-scan", "silent", "silent-since-a-while"}, ids)
+	assert.Equal(t, 0, *result[0].ScansInPeriod)
+	assert.Nil(t, result[0].LastScanEndTime)
+	assert.Equal(t, 3, *result[2].ScansInPeriod)
+	assert.Equal(t, 2, *result[3].ScansInPeriod)
+}
+
+func TestListSilentIntegrationsWithoutHistory(t *testing.T) {
+	now := time.Now()
+	withLastScan := func(id string, lastScanEnd time.Time, objects *int64) *models.SourceIntegration {
+		return &models.SourceIntegration{
+			SourceIntegrationMetadata: &models.SourceIntegrationMetadata{IntegrationID: aws.String(id)},
+			SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+				LastScanEndTime: aws.Time(lastScanEnd), LastScanObjectsProcessed: objects},
+		}
+	}
+	mockSilentIntegrations(t, "",
+		withLastScan("active", now.Add(-time.Hour), aws.Int64(10)),
+		// Scanners which don't report the objects they processed aren't reported
+		withLastScan("not-counted", now.Add(-time.Hour), nil),
+		withLastScan("silent", now.Add(-time.Hour), aws.Int64(0)),
+		withLastScan("silent-no-recent-scan", now.Add(-72*time.Hour), aws.Int64(10)),
+	)
+
+	result, err := apiTest.ListSilentIntegrations(&models.ListSilentIntegrationsInput{PeriodMinutes: aws.Int(24 * 60)})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "silent-no-recent-scan", *result[0].IntegrationID)
+	assert.Equal(t, 0, *result[0].ScansInPeriod)
+	assert.Equal(t, "silent", *result[1].IntegrationID)
+	assert.Equal(t, 1, *result[1].ScansInPeriod)
+}
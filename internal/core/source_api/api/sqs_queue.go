@@ -0,0 +1,288 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The queues Panther creates for the SQS integrations, the log processor can read any of them
+	sqsSourceQueuePrefix = "panther-sqs-source-"
+
+	// The messages the log processor reads at once from the queue of an SQS integration
+	sqsSourceBatchSize = 10
+	// Should match the timeout of the log processor
+	sqsSourceVisibilityTimeout = "180"
+)
+
+// sqsQueuePolicy is the policy of a queue Panther creates, allowing the account of the integration to send to it.
+type sqsQueuePolicy struct {
+	Version   string
+	Statement []sqsQueuePolicyStatement
+}
+
+type sqsQueuePolicyStatement struct {
+	Sid       string
+	Effect    string
+	Principal struct{ AWS string }
+	Action    []string
+	Resource  string
+}
+
+// sqsHealthChecker checks the queue of the SQS integrations.
+type sqsHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration: the queue the log events are sent to must be reachable.
+//
+// A queue Panther creates doesn't exist before the integration does, so there is nothing to check until then.
+func (sqsHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	status, err := api.CheckIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+	eval := &integrationEvaluation{rolesHealthy: true, failedItems: make([]*string, 0), health: status}
+	if status.SQSQueueStatus.Healthy != nil {
+		eval.addItems("sqsQueue:", map[string]models.SourceIntegrationItemStatus{
+			aws.StringValue(integration.SQSQueueArn): status.SQSQueueStatus,
+		})
+	}
+	return eval, nil
+}
+
+// checkSQSQueue verifies the queue exists and its attributes can be read.
+func checkSQSQueue(queueArn *string) models.SourceIntegrationItemStatus {
+	start := time.Now()
+	failed := func(err error) models.SourceIntegrationItemStatus {
+		if isThrottlingError(err) {
+			zap.L().Warn("sqs queue check throttled", zap.String("queue", *queueArn), zap.Error(err))
+			return models.SourceIntegrationItemStatus{
+				Healthy:       aws.Bool(false),
+				Inconclusive:  aws.Bool(true),
+				ErrorMessage:  aws.String(err.Error()),
+				LatencyMillis: millisSince(start),
+			}
+		}
+		return models.SourceIntegrationItemStatus{
+			Healthy:       aws.Bool(false),
+			ErrorMessage:  aws.String(err.Error()),
+			LatencyMillis: millisSince(start),
+		}
+	}
+
+	queueURL, err := sqsQueueURL(queueArn)
+	if err != nil {
+		return failed(err)
+	}
+	_, err = SQSClient.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       queueURL,
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameQueueArn}),
+	})
+	if err != nil {
+		return failed(err)
+	}
+	return models.SourceIntegrationItemStatus{
+		Healthy:       aws.Bool(true),
+		LatencyMillis: millisSince(start),
+	}
+}
+
+// sqsQueueURL returns the URL of a queue. The ARN is validated with the input.
+func sqsQueueURL(queueArn *string) (*string, error) {
+	parsedArn, _ := arn.Parse(*queueArn)
+	output, err := SQSClient.GetQueueUrl(&sqs.GetQueueUrlInput{
+		QueueName:              aws.String(parsedArn.Resource),
+		QueueOwnerAWSAccountId: aws.String(parsedArn.AccountID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.QueueUrl, nil
+}
+
+// checkSQSQueueSettings rejects a queue the log processor can't read: it only reads the queues of its own region.
+// The ARN is validated with the input.
+func checkSQSQueueSettings(queueArn *string) error {
+	if queueArn == nil {
+		return nil
+	}
+	if parsedArn, _ := arn.Parse(*queueArn); parsedArn.Region != homeRegion {
+		return &genericapi.InvalidInputError{Message: "sqsQueueArn: the queue must be in " + homeRegion}
+	}
+	return nil
+}
+
+// sqsSourceQueueArn is the ARN of the queue Panther creates for an integration, next to the log processor queue.
+func sqsSourceQueueArn(integrationID string) string {
+	queueArn, _ := arn.Parse(logProcessorQueueArn)
+	queueArn.Resource = sqsSourceQueuePrefix + integrationID
+	return queueArn.String()
+}
+
+// provisionSQSQueue creates the queue of a new SQS integration unless it has one, and maps the queue to the
+// log processor.
+func provisionSQSQueue(integration *models.SourceIntegrationMetadata) error {
+	if integration.SQSQueueArn == nil {
+		queueURL, err := createSQSSourceQueue(integration)
+		if err != nil {
+			return err
+		}
+		integration.SQSQueueArn = aws.String(sqsSourceQueueArn(*integration.IntegrationID))
+		integration.SQSQueueURL = queueURL
+		integration.SQSQueueProvisioned = aws.Bool(true)
+	} else {
+		queueURL, err := sqsQueueURL(integration.SQSQueueArn)
+		if err != nil {
+			return &genericapi.AWSError{Err: err, Method: "sqs.GetQueueUrl"}
+		}
+		integration.SQSQueueURL = queueURL
+	}
+
+	if err := attachSQSQueue(integration); err != nil {
+		removeSQSQueue(integration)
+		return err
+	}
+	return nil
+}
+
+// reprovisionSQSQueue gives a restored SQS integration its queue back: the queue Panther created is created again,
+// then the queue is mapped to the log processor.
+//
+// SQS doesn't allow creating a queue again within a minute of its deletion, the restore has to be retried then.
+func reprovisionSQSQueue(integration *models.SourceIntegrationMetadata) error {
+	if aws.BoolValue(integration.SQSQueueProvisioned) {
+		if _, err := createSQSSourceQueue(integration); err != nil {
+			return err
+		}
+	}
+	if err := attachSQSQueue(integration); err != nil {
+		removeSQSQueue(integration)
+		return err
+	}
+	return nil
+}
+
+// createSQSSourceQueue creates the queue of an integration, which the AWS account of the integration can send to.
+func createSQSSourceQueue(integration *models.SourceIntegrationMetadata) (*string, error) {
+	queueArn := sqsSourceQueueArn(*integration.IntegrationID)
+	parsedArn, _ := arn.Parse(queueArn)
+	statement := sqsQueuePolicyStatement{
+		Sid:      "PantherSQSSource",
+		Effect:   "Allow",
+		Action:   []string{"sqs:GetQueueAttributes", "sqs:GetQueueUrl", "sqs:SendMessage"},
+		Resource: queueArn,
+	}
+	statement.Principal.AWS = "arn:" + parsedArn.Partition + ":iam::" + aws.StringValue(integration.AWSAccountID) + ":root"
+	policy, err := json.Marshal(sqsQueuePolicy{Version: "2012-10-17", Statement: []sqsQueuePolicyStatement{statement}})
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to marshal queue policy: " + err.Error()}
+	}
+
+	output, err := SQSClient.CreateQueue(&sqs.CreateQueueInput{
+		QueueName: aws.String(parsedArn.Resource),
+		Attributes: map[string]*string{
+			sqs.QueueAttributeNamePolicy:            aws.String(string(policy)),
+			sqs.QueueAttributeNameVisibilityTimeout: aws.String(sqsSourceVisibilityTimeout),
+		},
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "sqs.CreateQueue"}
+	}
+	return output.QueueUrl, nil
+}
+
+// attachSQSQueue maps the queue of an integration to the log processor, which then reads its messages.
+func attachSQSQueue(integration *models.SourceIntegrationMetadata) error {
+	mapping, err := lambdaClient.CreateEventSourceMapping(&lambda.CreateEventSourceMappingInput{
+		EventSourceArn: integration.SQSQueueArn,
+		FunctionName:   aws.String(logProcessorFunction),
+		BatchSize:      aws.Int64(sqsSourceBatchSize),
+		Enabled:        aws.Bool(true),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "lambda.CreateEventSourceMapping"}
+	}
+	integration.SQSEventSourceMappingID = mapping.UUID
+	return nil
+}
+
+// detachSQSQueue stops the log processor from reading the queue of an integration, the queue is kept.
+func detachSQSQueue(integration *models.SourceIntegrationMetadata) error {
+	if integration.SQSEventSourceMappingID == nil {
+		return nil
+	}
+	_, err := lambdaClient.DeleteEventSourceMapping(&lambda.DeleteEventSourceMappingInput{
+		UUID: integration.SQSEventSourceMappingID,
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == lambda.ErrCodeResourceNotFoundException {
+		return nil
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "lambda.DeleteEventSourceMapping"}
+	}
+	return nil
+}
+
+// removeSQSQueue detaches the queue of an integration, and deletes it if Panther created it.
+//
+// Failures are logged, what couldn't be removed has to be removed manually.
+func removeSQSQueue(integration *models.SourceIntegrationMetadata) {
+	if err := detachSQSQueue(integration); err != nil {
+		zap.L().Error("failed to remove the mapping of the queue of the integration, it has to be removed manually",
+			zap.String("integrationId", *integration.IntegrationID),
+			zap.Error(err))
+	}
+	if !aws.BoolValue(integration.SQSQueueProvisioned) {
+		return
+	}
+	_, err := SQSClient.DeleteQueue(&sqs.DeleteQueueInput{QueueUrl: integration.SQSQueueURL})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == sqs.ErrCodeQueueDoesNotExist {
+		return
+	}
+	if err != nil {
+		zap.L().Error("failed to delete the queue of the integration, it has to be deleted manually",
+			zap.String("integrationId", *integration.IntegrationID),
+			zap.Error(err))
+	}
+}
+
+// sqsProducerPolicy is the policy the producers of an SQS integration need to send log events to its queue.
+func sqsProducerPolicy(queueArn *string) policyDocument {
+	return policyDocument{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{
+				Effect:   "Allow",
+				Action:   []string{"sqs:GetQueueUrl", "sqs:SendMessage"},
+				Resource: []string{aws.StringValue(queueArn)},
+			},
+		},
+	}
+}
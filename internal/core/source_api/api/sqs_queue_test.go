@@ -0,0 +1,329 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testSQSQueueArn      = "arn:aws:sqs:us-west-2:123456789012:app-logs"
+	testSQSQueueURL      = "https://sqs.us-west-2.amazonaws.com/123456789012/app-logs"
+	testSourceQueueArn   = "arn:aws:sqs:us-west-2:111122223333:panther-sqs-source-" + testIntegrationID
+	testSourceQueueURL   = "https://sqs.us-west-2.amazonaws.com/111122223333/panther-sqs-source-" + testIntegrationID
+	testEventSourceUUID  = "a1b2c3d4-5678-90ab-cdef-11111EXAMPLE"
+	testLogProcessorName = "panther-log-processor"
+)
+
+func (client *mockSQSClient) CreateQueue(input *sqs.CreateQueueInput) (*sqs.CreateQueueOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*sqs.CreateQueueOutput), args.Error(1)
+}
+
+func (client *mockSQSClient) DeleteQueue(input *sqs.DeleteQueueInput) (*sqs.DeleteQueueOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*sqs.DeleteQueueOutput), args.Error(1)
+}
+
+func (client *mockLambdaClient) CreateEventSourceMapping(
+	input *lambda.CreateEventSourceMappingInput) (*lambda.EventSourceMappingConfiguration, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*lambda.EventSourceMappingConfiguration), args.Error(1)
+}
+
+func (client *mockLambdaClient) DeleteEventSourceMapping(
+	input *lambda.DeleteEventSourceMappingInput) (*lambda.EventSourceMappingConfiguration, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*lambda.EventSourceMappingConfiguration), args.Error(1)
+}
+
+// mockSQSSource sets up the queue and the function of the SQS integrations of a deployment in us-west-2.
+func mockSQSSource(t *testing.T) (*mockSQSClient, *mockLambdaClient) {
+	previousQueueArn, previousFunction := logProcessorQueueArn, logProcessorFunction
+	logProcessorQueueArn = "arn:aws:sqs:us-west-2:111122223333:panther-input-data-notifications-queue"
+	logProcessorFunction = testLogProcessorName
+	usePartitionOf(t, "us-west-2")
+	t.Cleanup(func() { logProcessorQueueArn, logProcessorFunction = previousQueueArn, previousFunction })
+
+	mockSQS, mockLambda := &mockSQSClient{}, &mockLambdaClient{}
+	SQSClient, lambdaClient = mockSQS, mockLambda
+	return mockSQS, mockLambda
+}
+
+func putSQSIntegration(t *testing.T, queueArn *string) ([]*models.SourceIntegrationMetadata, *mockBatchWriteDDBClient, error) {
+	mockClient := &mockBatchWriteDDBClient{MockDDBClient: &modelstest.MockDDBClient{}}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		assert.Equal(t, queueArn, input.SQSQueueArn)
+		return true, nil, nil
+	}
+
+	input := &models.PutIntegrationInput{
+		Integrations: []*models.PutIntegrationSettings{
+			{
+				AWSAccountID:     aws.String(testAccountID),
+				IntegrationLabel: aws.String(testIntegrationLabel),
+				IntegrationType:  aws.String(models.IntegrationTypeSQSQueue),
+				UserID:           aws.String(testUserID),
+				SQSQueueArn:      queueArn,
+			},
+		},
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	require.NoError(t, validator.Struct(input))
+	out, err := apiTest.PutIntegration(input)
+	return out, mockClient, err
+}
+
+func TestPutSQSIntegrationProvisionsQueue(t *testing.T) {
+	mockSQS, mockLambda := mockSQSSource(t)
+	mockSQS.On("CreateQueue", mock.Anything).Return(&sqs.CreateQueueOutput{QueueUrl: aws.String(testSourceQueueURL)}, nil)
+	mockLambda.On("CreateEventSourceMapping", mock.Anything).
+		Return(&lambda.EventSourceMappingConfiguration{UUID: aws.String(testEventSourceUUID)}, nil)
+
+	out, mockClient, err := putSQSIntegration(t, nil)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	// The ID of the integration names its queue
+	queueArn := "arn:aws:sqs:us-west-2:111122223333:panther-sqs-source-" + *out[0].IntegrationID
+	assert.Equal(t, queueArn, *out[0].SQSQueueArn)
+	assert.Equal(t, testSourceQueueURL, *out[0].SQSQueueURL)
+	assert.True(t, *out[0].SQSQueueProvisioned)
+	assert.Equal(t, testEventSourceUUID, *out[0].SQSEventSourceMappingID)
+
+	// The account of the integration can send to the queue
+	createInput := mockSQS.Calls[0].Arguments.Get(0).(*sqs.CreateQueueInput)
+	assert.Equal(t, "panther-sqs-source-"+*out[0].IntegrationID, *createInput.QueueName)
+	var policy sqsQueuePolicy
+	require.NoError(t, json.Unmarshal([]byte(*createInput.Attributes["Policy"]), &policy))
+	require.Len(t, policy.Statement, 1)
+	assert.Equal(t, "arn:aws:iam::"+testAccountID+":root", policy.Statement[0].Principal.AWS)
+	assert.Contains(t, policy.Statement[0].Action, "sqs:SendMessage")
+	assert.Equal(t, queueArn, policy.Statement[0].Resource)
+
+	mockLambda.AssertCalled(t, "CreateEventSourceMapping", &lambda.CreateEventSourceMappingInput{
+		EventSourceArn: aws.String(queueArn),
+		FunctionName:   aws.String(testLogProcessorName),
+		BatchSize:      aws.Int64(10),
+		Enabled:        aws.Bool(true),
+	})
+
+	// The queue is stored with the integration
+	require.Len(t, mockClient.written, 1)
+	var stored models.SourceIntegrationMetadata
+	require.NoError(t, dynamodbattribute.UnmarshalMap(mockClient.written[0], &stored))
+	assert.Equal(t, queueArn, *stored.SQSQueueArn)
+	assert.Equal(t, testEventSourceUUID, *stored.SQSEventSourceMappingID)
+}
+
+func TestPutSQSIntegrationExistingQueue(t *testing.T) {
+	mockSQS, mockLambda := mockSQSSource(t)
+	mockSQS.On("GetQueueUrl", &sqs.GetQueueUrlInput{
+		QueueName:              aws.String("app-logs"),
+		QueueOwnerAWSAccountId: aws.String(testAccountID),
+	}).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String(testSQSQueueURL)}, nil)
+	mockLambda.On("CreateEventSourceMapping", mock.Anything).
+		Return(&lambda.EventSourceMappingConfiguration{UUID: aws.String(testEventSourceUUID)}, nil)
+
+	out, _, err := putSQSIntegration(t, aws.String(testSQSQueueArn))
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, testSQSQueueURL, *out[0].SQSQueueURL)
+	assert.Nil(t, out[0].SQSQueueProvisioned)
+	mockSQS.AssertNotCalled(t, "CreateQueue", mock.Anything)
+}
+
+func TestPutSQSIntegrationQueueOfOtherRegion(t *testing.T) {
+	mockSQSSource(t)
+	out, _, err := putSQSIntegration(t, aws.String("arn:aws:sqs:eu-west-1:123456789012:app-logs"))
+	assert.Nil(t, out)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Equal(t, "sqsQueueArn: the queue must be in us-west-2", err.(*genericapi.InvalidInputError).Message)
+}
+
+func TestPutSQSIntegrationMappingFails(t *testing.T) {
+	mockSQS, mockLambda := mockSQSSource(t)
+	mockSQS.On("CreateQueue", mock.Anything).Return(&sqs.CreateQueueOutput{QueueUrl: aws.String(testSourceQueueURL)}, nil)
+	mockSQS.On("DeleteQueue", &sqs.DeleteQueueInput{QueueUrl: aws.String(testSourceQueueURL)}).
+		Return(&sqs.DeleteQueueOutput{}, nil)
+	mockLambda.On("CreateEventSourceMapping", mock.Anything).
+		Return((*lambda.EventSourceMappingConfiguration)(nil), awserr.New(lambda.ErrCodeServiceException, "failure", nil))
+
+	out, mockClient, err := putSQSIntegration(t, nil)
+	assert.Nil(t, out)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+	// The queue which was created is deleted, and the integration isn't stored
+	mockSQS.AssertExpectations(t)
+	assert.Empty(t, mockClient.written)
+}
+
+func TestDeleteSQSIntegration(t *testing.T) {
+	mockSQS, mockLambda := mockSQSSource(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:            aws.String(testAccountID),
+		IntegrationID:           aws.String(testIntegrationID),
+		IntegrationType:         aws.String(models.IntegrationTypeSQSQueue),
+		SQSQueueArn:             aws.String(testSourceQueueArn),
+		SQSQueueURL:             aws.String(testSourceQueueURL),
+		SQSQueueProvisioned:     aws.Bool(true),
+		SQSEventSourceMappingID: aws.String(testEventSourceUUID),
+	})
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	mockLambda.On("DeleteEventSourceMapping", &lambda.DeleteEventSourceMappingInput{UUID: aws.String(testEventSourceUUID)}).
+		Return(&lambda.EventSourceMappingConfiguration{}, nil)
+	mockSQS.On("DeleteQueue", &sqs.DeleteQueueInput{QueueUrl: aws.String(testSourceQueueURL)}).
+		Return(&sqs.DeleteQueueOutput{}, nil)
+
+	require.NoError(t, apiTest.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: aws.String(testIntegrationID)}))
+	mockLambda.AssertExpectations(t)
+	mockSQS.AssertExpectations(t)
+}
+
+func TestDeleteSQSIntegrationExistingQueue(t *testing.T) {
+	mockSQS, mockLambda := mockSQSSource(t)
+	mockClient := mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeSQSQueue),
+		SQSQueueArn:     aws.String(testSQSQueueArn),
+		SQSQueueURL:     aws.String(testSQSQueueURL),
+		// The mapping was already removed
+		SQSEventSourceMappingID: aws.String(testEventSourceUUID),
+	})
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil)
+	mockLambda.On("DeleteEventSourceMapping", mock.Anything).Return(
+		(*lambda.EventSourceMappingConfiguration)(nil), awserr.New(lambda.ErrCodeResourceNotFoundException, "not found", nil))
+
+	require.NoError(t, apiTest.DeleteIntegration(&models.DeleteIntegrationInput{IntegrationID: aws.String(testIntegrationID)}))
+	// The queue of the producers is kept
+	mockSQS.AssertNotCalled(t, "DeleteQueue", mock.Anything)
+}
+
+func TestRestoreSQSIntegration(t *testing.T) {
+	mockSQS, mockLambda := mockSQSSource(t)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"integrationId":       {S: aws.String(testIntegrationID)},
+			"integrationType":     {S: aws.String(models.IntegrationTypeSQSQueue)},
+			"awsAccountId":        {S: aws.String(testAccountID)},
+			"sqsQueueArn":         {S: aws.String(testSourceQueueArn)},
+			"sqsQueueUrl":         {S: aws.String(testSourceQueueURL)},
+			"sqsQueueProvisioned": {BOOL: aws.Bool(true)},
+		},
+	}, nil).Twice()
+	mockSQS.On("CreateQueue", mock.Anything).Return(&sqs.CreateQueueOutput{QueueUrl: aws.String(testSourceQueueURL)}, nil)
+	mockLambda.On("CreateEventSourceMapping", mock.Anything).
+		Return(&lambda.EventSourceMappingConfiguration{UUID: aws.String("b2c3d4e5-6789-01ab-cdef-22222EXAMPLE")}, nil)
+
+	result, err := apiTest.RestoreIntegration(&models.RestoreIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, "b2c3d4e5-6789-01ab-cdef-22222EXAMPLE", *result.SQSEventSourceMappingID)
+
+	// The new mapping is stored
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var names, values []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, aws.StringValue(name))
+	}
+	assert.Contains(t, names, "sqsEventSourceMappingId")
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, aws.StringValue(value.S))
+	}
+	assert.Contains(t, values, "b2c3d4e5-6789-01ab-cdef-22222EXAMPLE")
+}
+
+func TestCheckIntegrationSQSQueue(t *testing.T) {
+	mockSQS, _ := mockSQSSource(t)
+	mockSQS.On("GetQueueUrl", mock.Anything).Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String(testSQSQueueURL)}, nil)
+	mockSQS.On("GetQueueAttributes", &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(testSQSQueueURL),
+		AttributeNames: aws.StringSlice([]string{"QueueArn"}),
+	}).Return(&sqs.GetQueueAttributesOutput{}, nil)
+
+	result, err := apiTest.CheckIntegration(&models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeSQSQueue),
+		SQSQueueArn:     aws.String(testSQSQueueArn),
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.SQSQueueStatus.Healthy)
+	assert.Nil(t, result.ProcessingRoleStatus.Healthy)
+}
+
+func TestEvaluateSQSIntegrationQueueUnreachable(t *testing.T) {
+	mockSQS, _ := mockSQSSource(t)
+	mockSQS.On("GetQueueUrl", mock.Anything).Return(
+		(*sqs.GetQueueUrlOutput)(nil), awserr.New(sqs.ErrCodeQueueDoesNotExist, "queue does not exist", nil))
+
+	eval, err := evaluateIntegrationHealth(apiTest, &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeSQSQueue),
+		SQSQueueArn:     aws.String(testSQSQueueArn),
+	})
+	require.NoError(t, err)
+	assert.False(t, eval.passing())
+	assert.Equal(t, aws.StringSlice([]string{"sqsQueue:" + testSQSQueueArn}), eval.failedItems)
+
+	// A queue Panther creates has nothing to check before the integration exists
+	eval, err = evaluateIntegrationHealth(apiTest, &models.CheckIntegrationInput{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationType: aws.String(models.IntegrationTypeSQSQueue),
+	})
+	require.NoError(t, err)
+	assert.True(t, eval.passing())
+}
+
+func TestGetIntegrationPolicyDocumentSQSQueue(t *testing.T) {
+	mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeSQSQueue),
+		SQSQueueArn:     aws.String(testSourceQueueArn),
+	})
+
+	result, err := apiTest.GetIntegrationPolicyDocument(
+		&models.GetIntegrationPolicyDocumentInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	var document policyDocument
+	require.NoError(t, json.Unmarshal([]byte(*result.Body), &document))
+	assert.Equal(t, []policyStatement{
+		{Effect: "Allow", Action: []string{"sqs:GetQueueUrl", "sqs:SendMessage"}, Resource: []string{testSourceQueueArn}},
+	}, document.Statement)
+}
@@ -0,0 +1,69 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// lastHealthyTime is the LastHealthyTime to store after a health check with the given result, nil unless it passed.
+func lastHealthyTime(healthStatus *string) *time.Time {
+	if aws.StringValue(healthStatus) != models.HealthStatusHealthy {
+		return nil
+	}
+	return aws.Time(time.Now())
+}
+
+// ListStaleHealthIntegrations returns the integrations which haven't passed a health check within the threshold.
+//
+// Unlike a failed health check, staleness also covers integrations whose health is unknown: those saved as
+// degraded and never fixed, and those which never passed a check. An integration which never passed one
+// is stale once it was created longer ago than the threshold. The least recently healthy come first.
+func (API) ListStaleHealthIntegrations(input *models.ListStaleHealthIntegrationsInput) ([]*models.SourceIntegration, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-time.Duration(*input.ThresholdMinutes) * time.Minute)
+	stale := make([]*models.SourceIntegration, 0)
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata == nil {
+			continue
+		}
+		if healthWatermark(integration).Before(cutoff) {
+			stale = append(stale, integration)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool { return healthWatermark(stale[i]).Before(healthWatermark(stale[j])) })
+	return stale, nil
+}
+
+// healthWatermark is the last time the integration was known to be healthy, or its creation if it never was.
+func healthWatermark(integration *models.SourceIntegration) time.Time {
+	if integration.LastHealthyTime != nil {
+		return *integration.LastHealthyTime
+	}
+	return aws.TimeValue(integration.CreatedAtTime)
+}
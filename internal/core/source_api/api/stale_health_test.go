@@ -0,0 +1,105 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func TestListStaleHealthIntegrations(t *testing.T) {
+	now := time.Now()
+	var items []map[string]*dynamodb.AttributeValue
+	for _, integration := range []*models.SourceIntegrationMetadata{
+		{
+			IntegrationID:   aws.String("recently-healthy"),
+			CreatedAtTime:   aws.Time(now.Add(-48 * time.Hour)),
+			HealthStatus:    aws.String(models.HealthStatusHealthy),
+			LastHealthyTime: aws.Time(now.Add(-time.Hour)),
+		},
+		{
+			// Healthy a while ago, then saved as degraded
+			IntegrationID:   aws.String("stale-degraded"),
+			CreatedAtTime:   aws.Time(now.Add(-48 * time.Hour)),
+			HealthStatus:    aws.String(models.HealthStatusDegraded),
+			LastHealthyTime: aws.Time(now.Add(-30 * time.Hour)),
+		},
+		{
+			// Saved before the watermark existed, its health is unknown
+			IntegrationID: aws.String("never-healthy"),
+			CreatedAtTime: aws.Time(now.Add(-72 * time.Hour)),
+		},
+		{
+			// Not confirmed yet, but too recent to be stale
+			IntegrationID: aws.String("just-created"),
+			CreatedAtTime: aws.Time(now.Add(-time.Hour)),
+		},
+	} {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{MockScanAttributes: items}, TableName: "test"}
+
+	result, err := apiTest.ListStaleHealthIntegrations(&models.ListStaleHealthIntegrationsInput{ThresholdMinutes: aws.Int(24 * 60)})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "never-healthy", *result[0].IntegrationID)
+	assert.Equal(t, "stale-degraded", *result[1].IntegrationID)
+}
+
+func TestUpdateIntegrationSettingsLastHealthyTime(t *testing.T) {
+	mockLogIntegration([]string{"bucket-1"}, nil)
+	mockClient := db.Client.(*modelstest.MockDDBClient)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationLabel:    aws.String("renamed"),
+		AllowDuplicateLabel: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, update)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "lastHealthyTime")
+}
+
+func TestLastHealthyTime(t *testing.T) {
+	assert.NotNil(t, lastHealthyTime(aws.String(models.HealthStatusHealthy)))
+	// Saving a degraded integration doesn't confirm it is healthy
+	assert.Nil(t, lastHealthyTime(aws.String(models.HealthStatusDegraded)))
+	assert.Nil(t, lastHealthyTime(nil))
+}
@@ -0,0 +1,56 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// syslogHealthChecker checks the syslog integrations.
+type syslogHealthChecker struct{}
+
+// Evaluate runs the health check of CheckIntegration.
+//
+// The devices of a syslog integration connect to the collector, Panther has nothing of theirs to reach.
+func (syslogHealthChecker) Evaluate(api API, integration *models.CheckIntegrationInput) (*integrationEvaluation, error) {
+	status, err := api.CheckIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+	return &integrationEvaluation{rolesHealthy: true, failedItems: make([]*string, 0), health: status}, nil
+}
+
+// checkSyslogCIDRs rejects the networks of an update which doesn't leave a syslog integration with at least one.
+//
+// The networks are cleared when the type of a syslog integration is changed.
+func checkSyslogCIDRs(integrationType *string, cidrs []*string) error {
+	if aws.StringValue(integrationType) != models.IntegrationTypeSyslog {
+		if len(cidrs) > 0 {
+			return &genericapi.InvalidInputError{Message: "only syslog integrations have allowed networks"}
+		}
+		return nil
+	}
+	if len(cidrs) == 0 {
+		return &genericapi.InvalidInputError{Message: "a syslog integration must allow at least one network"}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestSyslogCIDRsValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	settings := validSettings(models.IntegrationTypeSyslog)
+	assert.NoError(t, validator.Struct(settings))
+	settings.SyslogAllowedCIDRs = aws.StringSlice([]string{"10.0.0.1"})
+	assert.Error(t, validator.Struct(settings))
+	settings.SyslogAllowedCIDRs = nil
+	assert.Error(t, validator.Struct(settings))
+}
+
+func TestCheckSyslogCIDRs(t *testing.T) {
+	cidrs := aws.StringSlice([]string{"10.0.0.0/16"})
+	assert.NoError(t, checkSyslogCIDRs(aws.String(models.IntegrationTypeSyslog), cidrs))
+	assert.IsType(t, &genericapi.InvalidInputError{}, checkSyslogCIDRs(aws.String(models.IntegrationTypeSyslog), []*string{}))
+	assert.IsType(t, &genericapi.InvalidInputError{}, checkSyslogCIDRs(aws.String(models.IntegrationTypeHTTPIngest), cidrs))
+	// The networks are cleared with a change of the type
+	assert.NoError(t, checkSyslogCIDRs(aws.String(models.IntegrationTypeHTTPIngest), []*string{}))
+}
+
+func TestCheckTypeChangeFromSyslog(t *testing.T) {
+	integration := &models.SourceIntegrationMetadata{
+		IntegrationType:    aws.String(models.IntegrationTypeSyslog),
+		SyslogAllowedCIDRs: aws.StringSlice([]string{"10.0.0.0/16"}),
+	}
+	input := &models.UpdateIntegrationSettingsInput{IntegrationType: aws.String(models.IntegrationTypeHTTPIngest)}
+	err := checkTypeChange(integration, input)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Contains(t, err.Error(), "syslogAllowedCidrs")
+
+	input.SyslogAllowedCIDRs = []*string{}
+	assert.NoError(t, checkTypeChange(integration, input))
+}
@@ -0,0 +1,133 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+)
+
+// ApplyTagPolicy applies the settings of a policy to every integration with all the tags of the selector.
+//
+// Schedule settings are written directly, any other setting goes through UpdateIntegrationSettings and its
+// health check. Integrations which already have the settings are left alone, and a failed update doesn't
+// stop the others.
+func (api API) ApplyTagPolicy(input *models.ApplyTagPolicyInput) (*models.ApplyTagPolicyOutput, error) {
+	api = api.withAlertOutputs()
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched, updated int
+	failures := make([]*models.BulkSetScanIntervalResult, 0)
+	for _, integration := range integrations {
+		if !matchesTags(integration.Tags, input.TagSelector) {
+			continue
+		}
+		matched++
+
+		update := tagPolicyUpdate(integration.IntegrationID, input)
+		changes := diffIntegration(integration.SourceIntegrationMetadata, update)
+		if len(changes) == 0 {
+			continue
+		}
+		if err := api.applyTagPolicyUpdate(update, changes); err != nil {
+			zap.L().Warn("failed to apply tag policy",
+				zap.String("integrationId", *integration.IntegrationID), zap.Error(err))
+			failures = append(failures, &models.BulkSetScanIntervalResult{
+				IntegrationID: integration.IntegrationID,
+				Success:       aws.Bool(false),
+				ErrorMessage:  aws.String(err.Error()),
+			})
+			continue
+		}
+		updated++
+	}
+	return &models.ApplyTagPolicyOutput{
+		MatchedCount: aws.Int(matched),
+		UpdatedCount: aws.Int(updated),
+		FailedCount:  aws.Int(len(failures)),
+		Failures:     failures,
+	}, nil
+}
+
+// matchesTags returns true if the integration has every tag of the selector, with the same value.
+func matchesTags(tags, selector map[string]*string) bool {
+	for key, value := range selector {
+		if tag, ok := tags[key]; !ok || aws.StringValue(tag) != aws.StringValue(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// tagPolicyUpdate is the update of the settings of an integration by a tag policy.
+func tagPolicyUpdate(integrationID *string, input *models.ApplyTagPolicyInput) *models.UpdateIntegrationSettingsInput {
+	settings := input.Settings
+	return &models.UpdateIntegrationSettingsInput{
+		IntegrationID:      integrationID,
+		ScanEnabled:        settings.ScanEnabled,
+		ScanIntervalMins:   settings.ScanIntervalMins,
+		BlackoutWindows:    settings.BlackoutWindows,
+		CWEEnabled:         settings.CWEEnabled,
+		RemediationEnabled: settings.RemediationEnabled,
+		DedupWindowMinutes: settings.DedupWindowMinutes,
+		DataClassification: settings.DataClassification,
+		UserID:             input.UserID,
+	}
+}
+
+func (api API) applyTagPolicyUpdate(update *models.UpdateIntegrationSettingsInput, changes []*models.IntegrationFieldChange) error {
+	if !onlyScheduleSettings(update) {
+		_, err := api.UpdateIntegrationSettings(update)
+		return err
+	}
+
+	result, err := db.UpdateItem(&ddb.UpdateIntegrationItem{
+		IntegrationID:    update.IntegrationID,
+		ScanEnabled:      update.ScanEnabled,
+		ScanIntervalMins: update.ScanIntervalMins,
+		BlackoutWindows:  update.BlackoutWindows,
+	})
+	if err != nil {
+		return err
+	}
+	if err = recordUpdate(update.IntegrationID, update.UserID, changes); err != nil {
+		return err
+	}
+	_, err = refreshScanSchedule(result)
+	return err
+}
+
+// onlyScheduleSettings returns true if the update only changes when the integration is scanned.
+func onlyScheduleSettings(input *models.UpdateIntegrationSettingsInput) bool {
+	return reflect.DeepEqual(*input, models.UpdateIntegrationSettingsInput{
+		IntegrationID:    input.IntegrationID,
+		ScanEnabled:      input.ScanEnabled,
+		ScanIntervalMins: input.ScanIntervalMins,
+		BlackoutWindows:  input.BlackoutWindows,
+		UserID:           input.UserID,
+	})
+}
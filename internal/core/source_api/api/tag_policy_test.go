@@ -0,0 +1,86 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func TestApplyTagPolicy(t *testing.T) {
+	var items []map[string]*dynamodb.AttributeValue
+	for _, integration := range []*models.SourceIntegrationMetadata{
+		{IntegrationID: aws.String("dev-1"), ScanIntervalMins: aws.Int(60),
+			Tags: map[string]*string{"env": aws.String("dev"), "team": aws.String("infra")}},
+		{IntegrationID: aws.String("prod-1"), ScanIntervalMins: aws.Int(60), Tags: map[string]*string{"env": aws.String("prod")}},
+		{IntegrationID: aws.String("dev-2"), ScanIntervalMins: aws.Int(360), Tags: map[string]*string{"env": aws.String("dev")}},
+		{IntegrationID: aws.String("untagged"), ScanIntervalMins: aws.Int(60)},
+	} {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+	mockClient := &modelstest.MockDDBClient{MockScanAttributes: items}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("UpdateItem", updatedIntegrationID("dev-1")).Return(&dynamodb.UpdateItemOutput{}, nil)
+	evaluateIntegrationFunc = func(API, *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		t.Fatal("schedule settings need no health check")
+		return false, nil, nil
+	}
+
+	result, err := apiTest.ApplyTagPolicy(&models.ApplyTagPolicyInput{
+		TagSelector: map[string]*string{"env": aws.String("dev")},
+		Settings:    &models.TagPolicySettings{ScanIntervalMins: aws.Int(360)},
+	})
+	require.NoError(t, err)
+	// dev-2 already scans every 6 hours
+	assert.Equal(t, &models.ApplyTagPolicyOutput{
+		MatchedCount: aws.Int(2),
+		UpdatedCount: aws.Int(1),
+		FailedCount:  aws.Int(0),
+		Failures:     []*models.BulkSetScanIntervalResult{},
+	}, result)
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 1)
+}
+
+func TestApplyTagPolicyValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	settings := &models.TagPolicySettings{ScanIntervalMins: aws.Int(360)}
+	assert.NoError(t, validator.Struct(&models.ApplyTagPolicyInput{
+		TagSelector: map[string]*string{"env": aws.String("dev")}, Settings: settings}))
+	assert.Error(t, validator.Struct(&models.ApplyTagPolicyInput{TagSelector: map[string]*string{}, Settings: settings}))
+	assert.Error(t, validator.Struct(&models.ApplyTagPolicyInput{TagSelector: map[string]*string{"env": aws.String("dev")}}))
+	assert.Error(t, validator.Struct(&models.ApplyTagPolicyInput{
+		TagSelector: map[string]*string{"env": aws.String("dev")},
+		Settings:    &models.TagPolicySettings{ScanIntervalMins: aws.Int(90)},
+	}))
+}
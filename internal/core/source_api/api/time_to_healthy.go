@@ -0,0 +1,107 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// Weight of the last scan in the rolling average of the scan durations
+	scanDurationWeight = 0.3
+
+	// Assumed while an integration has no scan history, on the long side of what scans take
+	defaultEstimatedScanDuration = time.Hour
+)
+
+// GetTimeToHealthyEstimate estimates when data flows again from an integration, e.g. after its configuration was fixed.
+//
+// The data is expected once the next scan has run: the estimate is the next scan time plus the rolling average
+// of the scan durations of the integration, or a conservative default while there is no scan history.
+func (API) GetTimeToHealthyEstimate(input *models.GetTimeToHealthyEstimateInput) (*models.TimeToHealthyEstimate, error) {
+	integration, err := db.GetSourceIntegration(input.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil || integration.SourceIntegrationMetadata == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if !scheduled(integration.SourceIntegrationMetadata) {
+		return nil, &genericapi.InvalidInputError{Message: "integration is not scanned on a schedule"}
+	}
+	now := time.Now()
+	if input.Now != nil {
+		now = *input.Now
+	}
+
+	next := NextScanTime(integration, now)
+	duration, fromHistory := defaultEstimatedScanDuration, false
+	if integration.SourceIntegrationScanInformation != nil && integration.AverageScanDurationSecs != nil {
+		duration, fromHistory = time.Duration(*integration.AverageScanDurationSecs)*time.Second, true
+	}
+	return &models.TimeToHealthyEstimate{
+		IntegrationID:          integration.IntegrationID,
+		Estimate:               aws.Bool(true),
+		NextScanTime:           aws.Time(next),
+		ScanDurationSecs:       aws.Int64(int64(duration / time.Second)),
+		DefaultScanDuration:    aws.Bool(!fromHistory),
+		EstimatedDataFlowingBy: aws.Time(next.Add(duration)),
+	}, nil
+}
+
+// recordScanDuration adds the duration of the scan which just ended to the rolling average of the integration.
+//
+// The average only feeds estimates, failing to store it doesn't fail the end of the scan.
+func recordScanDuration(integration *models.SourceIntegration) *models.SourceIntegration {
+	if integration == nil || integration.SourceIntegrationScanInformation == nil {
+		return integration
+	}
+	scan := integration.SourceIntegrationScanInformation
+	if scan.LastScanStartTime == nil || scan.LastScanEndTime == nil || !scan.LastScanStartTime.Before(*scan.LastScanEndTime) {
+		return integration
+	}
+
+	average := averageScanDuration(scan.AverageScanDurationSecs, scan.LastScanEndTime.Sub(*scan.LastScanStartTime))
+	result, err := db.UpdateItem(&ddb.UpdateIntegrationItem{
+		IntegrationID:           integration.IntegrationID,
+		AverageScanDurationSecs: aws.Int64(average),
+	})
+	if err != nil {
+		zap.L().Warn("failed to record scan duration", zap.String("integrationId", *integration.IntegrationID), zap.Error(err))
+		return integration
+	}
+	return result
+}
+
+// averageScanDuration returns the rolling average of the scan durations in seconds, once the given scan is added.
+func averageScanDuration(previousSecs *int64, scan time.Duration) int64 {
+	secs := scan.Seconds()
+	if previousSecs != nil {
+		secs = scanDurationWeight*secs + (1-scanDurationWeight)*float64(*previousSecs)
+	}
+	return int64(math.Round(secs))
+}
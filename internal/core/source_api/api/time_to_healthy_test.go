@@ -0,0 +1,134 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func scannedIntegration(lastScanEnd time.Time, averageScanDurationSecs *int64) *models.SourceIntegration {
+	return &models.SourceIntegration{
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			IntegrationID:    aws.String(testIntegrationID),
+			IntegrationType:  aws.String(models.IntegrationTypeAWSScan),
+			ScanEnabled:      aws.Bool(true),
+			ScanIntervalMins: aws.Int(60),
+		},
+		SourceIntegrationScanInformation: &models.SourceIntegrationScanInformation{
+			LastScanEndTime:         aws.Time(lastScanEnd),
+			AverageScanDurationSecs: averageScanDurationSecs,
+		},
+	}
+}
+
+func TestGetTimeToHealthyEstimate(t *testing.T) {
+	lastScanEnd := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockStoredSourceIntegration(t, scannedIntegration(lastScanEnd, aws.Int64(300)))
+
+	result, err := apiTest.GetTimeToHealthyEstimate(&models.GetTimeToHealthyEstimateInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Now:           aws.Time(lastScanEnd.Add(10 * time.Minute)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.TimeToHealthyEstimate{
+		IntegrationID:          aws.String(testIntegrationID),
+		Estimate:               aws.Bool(true),
+		NextScanTime:           aws.Time(lastScanEnd.Add(time.Hour)),
+		ScanDurationSecs:       aws.Int64(300),
+		DefaultScanDuration:    aws.Bool(false),
+		EstimatedDataFlowingBy: aws.Time(lastScanEnd.Add(65 * time.Minute)),
+	}, result)
+}
+
+func TestGetTimeToHealthyEstimateNoHistory(t *testing.T) {
+	lastScanEnd := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockStoredSourceIntegration(t, scannedIntegration(lastScanEnd, nil))
+
+	// The scan is overdue, it's expected right away
+	now := lastScanEnd.Add(3 * time.Hour)
+	result, err := apiTest.GetTimeToHealthyEstimate(&models.GetTimeToHealthyEstimateInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Now:           aws.Time(now),
+	})
+	require.NoError(t, err)
+	assert.True(t, *result.Estimate)
+	assert.True(t, *result.DefaultScanDuration)
+	assert.Equal(t, now, *result.NextScanTime)
+	assert.Equal(t, int64(3600), *result.ScanDurationSecs)
+	assert.Equal(t, now.Add(defaultEstimatedScanDuration), *result.EstimatedDataFlowingBy)
+}
+
+func TestGetTimeToHealthyEstimateNotScheduled(t *testing.T) {
+	integration := scannedIntegration(time.Now(), nil)
+	integration.ScanEnabled = aws.Bool(false)
+	mockStoredSourceIntegration(t, integration)
+
+	result, err := apiTest.GetTimeToHealthyEstimate(&models.GetTimeToHealthyEstimateInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestUpdateIntegrationLastScanEndRecordsScanDuration(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	lastScanEnd := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	integration := scannedIntegration(lastScanEnd, aws.Int64(100))
+	integration.LastScanStartTime = aws.Time(lastScanEnd.Add(-200 * time.Second))
+	integration.NextScanTime = aws.Time(lastScanEnd.Add(time.Hour))
+	item, err := dynamodbattribute.MarshalMap(integration)
+	require.NoError(t, err)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+
+	_, err = apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		LastScanEndTime: aws.Time(lastScanEnd),
+		ScanStatus:      aws.String(models.StatusOK),
+	})
+	require.NoError(t, err)
+
+	// The scan end is written, then the average, the next scan is already scheduled
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 2)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var values []string
+	for _, value := range update.ExpressionAttributeValues {
+		if value.N != nil {
+			values = append(values, *value.N)
+		}
+	}
+	assert.Contains(t, values, "130")
+}
+
+func TestAverageScanDuration(t *testing.T) {
+	assert.Equal(t, int64(90), averageScanDuration(nil, 90*time.Second))
+	assert.Equal(t, int64(130), averageScanDuration(aws.Int64(100), 200*time.Second))
+	assert.Equal(t, int64(70), averageScanDuration(aws.Int64(100), 0))
+}
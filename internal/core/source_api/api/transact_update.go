@@ -0,0 +1,116 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// TransactUpdateIntegrations updates the settings of several integrations in a single transaction.
+//
+// Every update is checked, including its health check, before anything is written. If one of them fails, or the
+// condition of one of them no longer holds when they are written, none of the integrations is updated.
+func (api API) TransactUpdateIntegrations(input *models.TransactUpdateIntegrationsInput) ([]*models.SourceIntegration, error) {
+	api = api.withAlertOutputs()
+	if len(input.Updates) > ddb.MaxTransactItems {
+		return nil, &genericapi.InvalidInputError{
+			Message: fmt.Sprintf("a transaction updates at most %d integrations", ddb.MaxTransactItems)}
+	}
+	seen := make(map[string]struct{}, len(input.Updates))
+	for _, update := range input.Updates {
+		if _, ok := seen[*update.IntegrationID]; ok {
+			return nil, &genericapi.InvalidInputError{
+				Message: "integration " + *update.IntegrationID + " is updated more than once"}
+		}
+		if aws.BoolValue(update.DryRun) {
+			return nil, &genericapi.InvalidInputError{Message: "a transaction can't include a dry run"}
+		}
+		seen[*update.IntegrationID] = struct{}{}
+	}
+
+	prepared := make([]*preparedUpdate, len(input.Updates))
+	updates := make([]*ddb.TransactUpdate, len(input.Updates))
+	for i, update := range input.Updates {
+		var err error
+		if prepared[i], err = api.prepareUpdate(update, false); err != nil {
+			return nil, err
+		}
+		// The integration could be deleted between its checks and the transaction
+		condition := expression.Name("integrationId").AttributeExists()
+		if update.ExpectedScanStatus != nil {
+			condition = condition.And(expression.Name("scanStatus").Equal(expression.Value(*update.ExpectedScanStatus)))
+		}
+		updates[i] = &ddb.TransactUpdate{Item: prepared[i].item, Condition: &condition}
+	}
+
+	if err := db.TransactUpdateItems(updates); err != nil {
+		if failed, ok := err.(*ddb.TransactionConditionFailedError); ok {
+			return nil, &genericapi.PreconditionFailedError{
+				Message: "no integration was updated, the condition of " +
+					strings.Join(aws.StringValueSlice(failed.IntegrationIDs), ", ") + " does not hold"}
+		}
+		return nil, err
+	}
+
+	results := make([]*models.SourceIntegration, len(prepared))
+	for i, update := range prepared {
+		update.syncGrantsAfterWrite()
+		result, err := db.GetSourceIntegration(update.input.IntegrationID)
+		if err != nil {
+			return nil, err
+		}
+		if results[i], err = update.written(result); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// syncGrantsAfterWrite syncs the KMS grants of an update which was written without them.
+//
+// The update can no longer be undone, so grants which fail for any reason are retried in the background.
+func (prepared *preparedUpdate) syncGrantsAfterWrite() {
+	if !prepared.healthChecked {
+		return
+	}
+	grants, err := kmsGrantsForUpdate(prepared.integration, prepared.input, prepared.item.KmsKeys)
+	if err == nil && grants != nil {
+		_, err = db.UpdateItem(&ddb.UpdateIntegrationItem{IntegrationID: prepared.input.IntegrationID, KmsGrants: grants.result()})
+		if err != nil {
+			grants.discard()
+		}
+	}
+	if err == nil {
+		// The grants which were replaced are retired with the other side effects of the write
+		prepared.grants = grants
+		return
+	}
+	zap.L().Warn("failed to sync the kms grants after the transaction, retrying in the background",
+		zap.String("integrationId", *prepared.input.IntegrationID), zap.Error(err))
+	prepared.pendingRetries = append(prepared.pendingRetries, &transientError{sideEffect: sideEffectKmsGrants, message: err.Error()})
+}
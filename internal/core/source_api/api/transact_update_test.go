@@ -0,0 +1,147 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// mockTransactClient records the transactions, which fail with transactErr
+type mockTransactClient struct {
+	*modelstest.MockDDBClient
+	transactions []*dynamodb.TransactWriteItemsInput
+	transactErr  error
+}
+
+func (client *mockTransactClient) TransactWriteItems(
+	input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+
+	client.transactions = append(client.transactions, input)
+	if client.transactErr != nil {
+		return nil, client.transactErr
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func mockTransact(transactErr error) *mockTransactClient {
+	mockClient := &mockTransactClient{MockDDBClient: &modelstest.MockDDBClient{}, transactErr: transactErr}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"integrationId": {S: aws.String(testIntegrationID)},
+		"awsAccountId":  {S: aws.String(testAccountID)},
+	}}, nil)
+	return mockClient
+}
+
+func labelUpdates(ids ...string) *models.TransactUpdateIntegrationsInput {
+	input := &models.TransactUpdateIntegrationsInput{}
+	for _, id := range ids {
+		input.Updates = append(input.Updates, &models.UpdateIntegrationSettingsInput{
+			IntegrationID:    aws.String(id),
+			IntegrationLabel: aws.String("label-" + id),
+		})
+	}
+	return input
+}
+
+func TestTransactUpdateIntegrations(t *testing.T) {
+	mockClient := mockTransact(nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	result, err := apiTest.TransactUpdateIntegrations(labelUpdates("integration-1", "integration-2"))
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+	require.Len(t, mockClient.transactions, 1)
+	require.Len(t, mockClient.transactions[0].TransactItems, 2)
+	assert.Equal(t, "integration-1", *mockClient.transactions[0].TransactItems[0].Update.Key["integrationId"].S)
+	assert.Equal(t, "integration-2", *mockClient.transactions[0].TransactItems[1].Update.Key["integrationId"].S)
+	// The integrations are written by the transaction only
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestTransactUpdateIntegrationsConditionFails(t *testing.T) {
+	mockClient := mockTransact(&dynamodb.TransactionCanceledException{
+		CancellationReasons: []*dynamodb.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ConditionalCheckFailed")},
+		},
+	})
+	var healthChecks int
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		// Every health check runs before the transaction
+		assert.Empty(t, mockClient.transactions)
+		healthChecks++
+		return true, nil, nil
+	}
+
+	input := labelUpdates("integration-1", "integration-2")
+	input.Updates[1].ExpectedScanStatus = aws.String(models.StatusOK)
+	result, err := apiTest.TransactUpdateIntegrations(input)
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.PreconditionFailedError{}, err)
+	assert.Contains(t, err.Error(), "integration-2")
+	assert.NotContains(t, err.Error(), "integration-1")
+	assert.Equal(t, 2, healthChecks)
+	require.Len(t, mockClient.transactions, 1)
+	// Nothing was written outside of the canceled transaction
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestTransactUpdateIntegrationsHealthCheckFails(t *testing.T) {
+	mockClient := mockTransact(nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return false, nil, nil }
+
+	result, err := apiTest.TransactUpdateIntegrations(labelUpdates("integration-1", "integration-2"))
+	assert.Nil(t, result)
+	assert.Error(t, err)
+	assert.Empty(t, mockClient.transactions)
+}
+
+func TestTransactUpdateIntegrationsDuplicate(t *testing.T) {
+	mockClient := mockTransact(nil)
+
+	result, err := apiTest.TransactUpdateIntegrations(labelUpdates("integration-1", "integration-1"))
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Empty(t, mockClient.transactions)
+}
+
+func TestTransactUpdateIntegrationsValidation(t *testing.T) {
+	ids := make([]string, ddb.MaxTransactItems+1)
+	for i := range ids {
+		ids[i] = testIntegrationID
+	}
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	assert.Error(t, validator.Struct(labelUpdates(ids...)))
+	assert.Error(t, validator.Struct(labelUpdates()))
+	assert.NoError(t, validator.Struct(labelUpdates(ids[1:]...)))
+}
@@ -0,0 +1,117 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	awspoller "github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws"
+	pollerutils "github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/utils"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/awsbatch/sqsbatch"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// TriggerScan starts a scan of an aws-scan integration right away, instead of waiting for its next scheduled scan.
+//
+// The scan covers every resource type of the integration in all its regions, or only the region and the resource
+// type of the input. The start of the scan is recorded as by UpdateIntegrationLastScanStart.
+func (API) TriggerScan(input *models.TriggerScanInput) (*models.SourceIntegration, error) {
+	integration, err := db.GetIntegration(input.IntegrationID, false)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWSScan {
+		return nil, &genericapi.InvalidInputError{Message: "only aws-scan integrations can be scanned on demand"}
+	}
+	if !aws.BoolValue(integration.ScanEnabled) {
+		return nil, &genericapi.InvalidInputError{Message: "scans of the integration are disabled"}
+	}
+	if input.Region != nil &&
+		!pollerutils.InScope(*input.Region, integration.EnabledRegions, integration.ExcludedRegions) {
+
+		return nil, &genericapi.InvalidInputError{Message: "region: " + *input.Region + " is not scanned for the integration"}
+	}
+	resourceTypes, err := triggeredResourceTypes(integration, input.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if end, inBlackout := blackoutEnd(integration.BlackoutWindows, now); inBlackout {
+		return nil, &genericapi.UnavailableError{
+			Message: "integration is in a blackout window until " + end.Format(time.RFC3339)}
+	}
+
+	entries, err := scanRequestEntries(integration, input.Region, resourceTypes)
+	if err != nil {
+		return nil, err
+	}
+	queueURL := regionalQueueURL(snapshotPollersQueueURL, integration)
+	zap.L().Info("triggering scan",
+		zap.String("integrationId", *input.IntegrationID),
+		zap.String("queueUrl", queueURL),
+		zap.Int("count", len(entries)),
+	)
+	err = sqsbatch.SendMessageBatch(SQSClient, maxElapsedTime, &sqs.SendMessageBatchInput{
+		Entries:  entries,
+		QueueUrl: aws.String(queueURL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.UpdateItem(&ddb.UpdateIntegrationItem{
+		IntegrationID:     input.IntegrationID,
+		LastScanStartTime: aws.Time(now),
+		ScanStatus:        aws.String(models.StatusScanning),
+	})
+}
+
+// triggeredResourceTypes are the resource types a triggered scan covers: the given one, which must be in the scope
+// of the integration, or else every resource type in scope.
+func triggeredResourceTypes(integration *models.SourceIntegrationMetadata, resourceType *string) ([]string, error) {
+	if resourceType != nil {
+		if _, ok := awspoller.ServicePollers[*resourceType]; !ok {
+			return nil, &genericapi.InvalidInputError{Message: "resourceType: " + *resourceType + " is not a scanned resource type"}
+		}
+		if !resourceTypeInScope(integration, *resourceType) {
+			return nil, &genericapi.InvalidInputError{
+				Message: "resourceType: " + *resourceType + " is not scanned for the integration"}
+		}
+		return []string{*resourceType}, nil
+	}
+
+	var resourceTypes []string
+	for name := range awspoller.ServicePollers {
+		if resourceTypeInScope(integration, name) {
+			resourceTypes = append(resourceTypes, name)
+		}
+	}
+	sort.Strings(resourceTypes)
+	return resourceTypes, nil
+}
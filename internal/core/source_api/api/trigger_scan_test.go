@@ -0,0 +1,141 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	pollermodels "github.com/panther-labs/panther/internal/compliance/snapshot_poller/models/poller"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func scanTriggeredIntegration() *models.SourceIntegrationMetadata {
+	return &models.SourceIntegrationMetadata{
+		AWSAccountID:          aws.String(testAccountID),
+		IntegrationID:         aws.String(testIntegrationID),
+		IntegrationType:       aws.String(models.IntegrationTypeAWSScan),
+		ScanEnabled:           aws.Bool(true),
+		ExcludedRegions:       aws.StringSlice([]string{"eu-west-1"}),
+		ResourceTypeAllowList: aws.StringSlice([]string{"AWS.IAM.Role", "AWS.S3.Bucket"}),
+	}
+}
+
+func TestTriggerScan(t *testing.T) {
+	mockClient := mockStoredIntegration(t, scanTriggeredIntegration())
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+	var scans []*pollermodels.ScanEntry
+	mockScanQueue(t, &scans)
+
+	_, err := apiTest.TriggerScan(&models.TriggerScanInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+
+	// Every resource type of the integration, in all its regions
+	assert.Equal(t, []string{"AWS.IAM.Role", "AWS.S3.Bucket"}, resourceTypesOf(scans))
+	for _, scan := range scans {
+		assert.Nil(t, scan.Region)
+		assert.Equal(t, aws.StringSlice([]string{"eu-west-1"}), scan.ExcludedRegions)
+	}
+
+	// The scan is started like a scheduled one
+	require.NotNil(t, update)
+	var values []string
+	for _, value := range update.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.Contains(t, values, models.StatusScanning)
+}
+
+func TestTriggerScanRegionAndResourceType(t *testing.T) {
+	mockClient := mockStoredIntegration(t, scanTriggeredIntegration())
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	var scans []*pollermodels.ScanEntry
+	mockScanQueue(t, &scans)
+
+	_, err := apiTest.TriggerScan(&models.TriggerScanInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Region:        aws.String("us-east-1"),
+		ResourceType:  aws.String("AWS.S3.Bucket"),
+	})
+	require.NoError(t, err)
+	require.Len(t, scans, 1)
+	assert.Equal(t, "us-east-1", *scans[0].Region)
+	assert.Equal(t, "AWS.S3.Bucket", *scans[0].ResourceType)
+	mockClient.AssertExpectations(t)
+}
+
+func TestTriggerScanOutOfScope(t *testing.T) {
+	for _, input := range []*models.TriggerScanInput{
+		{Region: aws.String("eu-west-1")},
+		{ResourceType: aws.String("AWS.EC2.Instance")},
+		{ResourceType: aws.String("AWS.Nothing")},
+	} {
+		mockStoredIntegration(t, scanTriggeredIntegration())
+		var scans []*pollermodels.ScanEntry
+		mockScanQueue(t, &scans)
+
+		input.IntegrationID = aws.String(testIntegrationID)
+		result, err := apiTest.TriggerScan(input)
+		assert.Nil(t, result)
+		assert.IsType(t, &genericapi.InvalidInputError{}, err)
+		assert.Empty(t, scans)
+	}
+}
+
+func TestTriggerScanNotScannable(t *testing.T) {
+	logs := scanTriggeredIntegration()
+	logs.IntegrationType = aws.String(models.IntegrationTypeAWS3)
+	disabled := scanTriggeredIntegration()
+	disabled.ScanEnabled = aws.Bool(false)
+
+	for _, integration := range []*models.SourceIntegrationMetadata{logs, disabled} {
+		mockStoredIntegration(t, integration)
+		var scans []*pollermodels.ScanEntry
+		mockScanQueue(t, &scans)
+
+		_, err := apiTest.TriggerScan(&models.TriggerScanInput{IntegrationID: aws.String(testIntegrationID)})
+		assert.IsType(t, &genericapi.InvalidInputError{}, err)
+		assert.Empty(t, scans)
+	}
+}
+
+func TestTriggerScanInBlackout(t *testing.T) {
+	integration := scanTriggeredIntegration()
+	integration.BlackoutWindows = []*models.BlackoutWindow{
+		{Start: aws.Time(time.Now().Add(-time.Hour)), End: aws.Time(time.Now().Add(time.Hour))},
+	}
+	mockStoredIntegration(t, integration)
+	var scans []*pollermodels.ScanEntry
+	mockScanQueue(t, &scans)
+
+	_, err := apiTest.TriggerScan(&models.TriggerScanInput{IntegrationID: aws.String(testIntegrationID)})
+	assert.IsType(t, &genericapi.UnavailableError{}, err)
+	assert.Empty(t, scans)
+}
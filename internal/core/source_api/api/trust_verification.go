@@ -0,0 +1,125 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// VerifyTrustForAccount assumes the roles of every integration of an AWS account, and nothing else.
+//
+// It's the role part of the health check, run across the integrations of the account e.g. after their trust
+// policies changed. Each role (or role chain) is assumed once, even when several integrations use it. Nothing is
+// stored: the health of the integrations is only updated by their next full health check.
+func (API) VerifyTrustForAccount(input *models.VerifyTrustForAccountInput) (*models.AccountTrustVerification, error) {
+	integrations, err := db.ScanAllIntegrations()
+	if err != nil {
+		return nil, err
+	}
+	var accountIntegrations []*models.SourceIntegrationMetadata
+	for _, integration := range integrations {
+		if integration.SourceIntegrationMetadata != nil && aws.StringValue(integration.AWSAccountID) == *input.AWSAccountID {
+			accountIntegrations = append(accountIntegrations, integration.SourceIntegrationMetadata)
+		}
+	}
+	sort.Slice(accountIntegrations, func(i, j int) bool {
+		return *accountIntegrations[i].IntegrationID < *accountIntegrations[j].IntegrationID
+	})
+
+	result := &models.AccountTrustVerification{
+		AWSAccountID: input.AWSAccountID,
+		Integrations: make([]*models.IntegrationTrustStatus, 0, len(accountIntegrations)),
+		PassingCount: aws.Int(0),
+		FailingCount: aws.Int(0),
+	}
+	verified := make(map[string]models.SourceIntegrationItemStatus)
+	for _, integration := range accountIntegrations {
+		status := &models.IntegrationTrustStatus{
+			IntegrationID:    integration.IntegrationID,
+			IntegrationLabel: integration.IntegrationLabel,
+			IntegrationType:  integration.IntegrationType,
+			Healthy:          aws.Bool(true),
+			RoleStatuses:     make(map[string]models.SourceIntegrationItemStatus),
+		}
+		for _, chain := range trustedRoles(integration) {
+			key := strings.Join(aws.StringValueSlice(chain), ",")
+			roleStatus, ok := verified[key]
+			if !ok {
+				roleStatus = verifyRole(chain)
+				verified[key] = roleStatus
+			}
+			status.RoleStatuses[*chain[len(chain)-1]] = roleStatus
+			if !aws.BoolValue(roleStatus.Healthy) {
+				status.Healthy = aws.Bool(false)
+			}
+		}
+
+		if *status.Healthy {
+			*result.PassingCount++
+		} else {
+			*result.FailingCount++
+		}
+		result.Integrations = append(result.Integrations, status)
+	}
+	return result, nil
+}
+
+// trustedRoles returns the roles the health check of an integration assumes, each one as a role chain.
+//
+// A role assumed directly is a chain of one role.
+func trustedRoles(integration *models.SourceIntegrationMetadata) [][]*string {
+	accountID := aws.StringValue(integration.AWSAccountID)
+	switch aws.StringValue(integration.IntegrationType) {
+	case models.IntegrationTypeAWSScan:
+		roles := [][]*string{{aws.String(fmt.Sprintf(auditRoleFormat, accountID))}}
+		if aws.BoolValue(integration.CWEEnabled) {
+			roles = append(roles, []*string{aws.String(fmt.Sprintf(cweRoleFormat, accountID))})
+		}
+		if aws.BoolValue(integration.RemediationEnabled) {
+			roles = append(roles, []*string{aws.String(fmt.Sprintf(remediationRoleFormat, accountID))})
+		}
+		return roles
+	case models.IntegrationTypeAWSOrganization:
+		return [][]*string{{aws.String(fmt.Sprintf(organizationRoleFormat, accountID))}}
+	case models.IntegrationTypeAWS3, models.IntegrationTypeAWSKinesis:
+		if len(integration.RoleChain) > 0 {
+			return [][]*string{integration.RoleChain}
+		}
+		return [][]*string{{aws.String(fmt.Sprintf(logProcessingRoleFormat, accountID))}}
+	default:
+		return nil
+	}
+}
+
+// verifyRole assumes a role chain the way the health check does.
+func verifyRole(chain []*string) models.SourceIntegrationItemStatus {
+	if len(chain) == 1 {
+		_, status := getCredentialsWithStatus(zap.L(), chain[0])
+		return status
+	}
+	_, status, _ := getChainedCredentialsWithStatus(zap.L(), chain)
+	return status
+}
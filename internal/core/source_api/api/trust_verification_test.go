@@ -0,0 +1,117 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func mockStoredIntegrations(t *testing.T, integrations ...*models.SourceIntegrationMetadata) {
+	items := make([]map[string]*dynamodb.AttributeValue, len(integrations))
+	for i, integration := range integrations {
+		item, err := dynamodbattribute.MarshalMap(integration)
+		require.NoError(t, err)
+		items[i] = item
+	}
+	db = &ddb.DDB{Client: &modelstest.MockDDBClient{MockScanAttributes: items}, TableName: "test"}
+}
+
+func trustedIntegration(id, integrationType string) *models.SourceIntegrationMetadata {
+	return &models.SourceIntegrationMetadata{
+		IntegrationID:    aws.String(id),
+		IntegrationLabel: aws.String(id),
+		IntegrationType:  aws.String(integrationType),
+		AWSAccountID:     aws.String(testAccountID),
+	}
+}
+
+func TestVerifyTrustForAccount(t *testing.T) {
+	cloudSecurity := trustedIntegration("1-cloud-security", models.IntegrationTypeAWSScan)
+	cloudSecurity.CWEEnabled = aws.Bool(true)
+	chained := trustedIntegration("4-chained-logs", models.IntegrationTypeAWS3)
+	chained.RoleChain = aws.StringSlice([]string{
+		"arn:aws:iam::123456789012:role/JumpRole",
+		"arn:aws:iam::123456789012:role/LogAccessRole",
+	})
+	otherAccount := trustedIntegration("0-other-account", models.IntegrationTypeAWS3)
+	otherAccount.AWSAccountID = aws.String("210987654321")
+	mockStoredIntegrations(t, chained, trustedIntegration("3-more-logs", models.IntegrationTypeAWS3),
+		otherAccount, cloudSecurity, trustedIntegration("2-logs", models.IntegrationTypeAWS3))
+
+	// The roles are assumed in the order of the integrations, the log processing role only once
+	denied := errors.New("AccessDenied: not authorized to perform sts:AssumeRole")
+	mockSTS := &mockSTSClient{}
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil).Once()    // audit role
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, denied).Once() // CWE role
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil).Once()    // log processing role
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, nil).Once()    // jump role
+	mockSTS.On("GetCallerIdentity", mock.Anything).Return(&sts.GetCallerIdentityOutput{}, denied).Once() // log access role
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+
+	result, err := apiTest.VerifyTrustForAccount(&models.VerifyTrustForAccountInput{AWSAccountID: aws.String(testAccountID)})
+	require.NoError(t, err)
+	mockSTS.AssertExpectations(t)
+	assert.Equal(t, 2, *result.PassingCount)
+	assert.Equal(t, 2, *result.FailingCount)
+	require.Len(t, result.Integrations, 4)
+
+	security := result.Integrations[0]
+	assert.Equal(t, "1-cloud-security", *security.IntegrationID)
+	assert.False(t, *security.Healthy)
+	assert.True(t, *security.RoleStatuses["arn:aws:iam::123456789012:role/PantherAuditRole"].Healthy)
+	cweStatus := security.RoleStatuses["arn:aws:iam::123456789012:role/PantherCloudFormationStackSetExecutionRole"]
+	assert.False(t, *cweStatus.Healthy)
+	assert.Contains(t, *cweStatus.ErrorMessage, "AccessDenied")
+
+	for _, logs := range result.Integrations[1:3] {
+		assert.True(t, *logs.Healthy)
+		assert.True(t, *logs.RoleStatuses["arn:aws:iam::123456789012:role/PantherLogProcessingRole"].Healthy)
+	}
+
+	chainStatus := result.Integrations[3]
+	assert.False(t, *chainStatus.Healthy)
+	require.Len(t, chainStatus.RoleStatuses, 1)
+	assert.Contains(t, *chainStatus.RoleStatuses["arn:aws:iam::123456789012:role/LogAccessRole"].ErrorMessage,
+		"role 2 of 2 of the chain")
+}
+
+func TestVerifyTrustForAccountWithoutIntegrations(t *testing.T) {
+	mockStoredIntegrations(t)
+	mockSTS := &mockSTSClient{}
+	mockHealthCheckClients(mockSTS, &mockS3Client{}, &mockKMSClient{})
+
+	result, err := apiTest.VerifyTrustForAccount(&models.VerifyTrustForAccountInput{AWSAccountID: aws.String(testAccountID)})
+	require.NoError(t, err)
+	assert.Empty(t, result.Integrations)
+	assert.Equal(t, 0, *result.PassingCount)
+	mockSTS.AssertNotCalled(t, "GetCallerIdentity", mock.Anything)
+}
@@ -0,0 +1,146 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The integration types which use each type-specific setting, by JSON name
+var typeSpecificSettings = map[string][]string{
+	"cweEnabled":         {models.IntegrationTypeAWSScan, models.IntegrationTypeAWSOrganization},
+	"remediationEnabled": {models.IntegrationTypeAWSScan, models.IntegrationTypeAWSOrganization},
+	"s3Buckets":          {models.IntegrationTypeAWS3},
+	"kmsKeys":            {models.IntegrationTypeAWS3},
+	"s3BucketRegions":    {models.IntegrationTypeAWS3},
+	"s3ObjectFilters":    {models.IntegrationTypeAWS3},
+	"createKmsGrants":    {models.IntegrationTypeAWS3},
+	"isOrgTrail":         {models.IntegrationTypeAWS3},
+	"includePatterns":    {models.IntegrationTypeAWS3},
+	"excludePatterns":    {models.IntegrationTypeAWS3},
+	"streamArn":          {models.IntegrationTypeAWSKinesis},
+	"shardIteratorType":  {models.IntegrationTypeAWSKinesis},
+	"roleChain":          {models.IntegrationTypeAWS3, models.IntegrationTypeAWSKinesis},
+	"syslogAllowedCidrs": {models.IntegrationTypeSyslog},
+
+	"organizationExcludedAccounts": {models.IntegrationTypeAWSOrganization},
+}
+
+// checkTypeChange rejects a change of the integration type which leaves settings of the old type populated,
+// or the settings the new type requires unset, once the update is applied.
+func checkTypeChange(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	newType := *input.IntegrationType
+	populated := populatedSettings(integration, input)
+
+	var incompatible, missing []string
+	for setting, types := range typeSpecificSettings {
+		if populated[setting] && !usedBy(types, newType) {
+			incompatible = append(incompatible, setting)
+		}
+	}
+	for _, capabilities := range models.IntegrationTypes() {
+		if *capabilities.IntegrationType != newType {
+			continue
+		}
+		for _, field := range capabilities.RequiredFields {
+			// The account of an integration can't change, it's always set
+			if isPopulated, ok := populated[*field]; ok && !isPopulated {
+				missing = append(missing, *field)
+			}
+		}
+	}
+	if len(incompatible) == 0 && len(missing) == 0 {
+		return nil
+	}
+
+	sort.Strings(incompatible)
+	var problems []string
+	if len(incompatible) > 0 {
+		problems = append(problems, "clear the incompatible settings: "+strings.Join(incompatible, ", "))
+	}
+	if len(missing) > 0 {
+		problems = append(problems, "set the required settings: "+strings.Join(missing, ", "))
+	}
+	return &genericapi.InvalidInputError{
+		Message: "changing the integration type to " + newType + " needs the same update to " + strings.Join(problems, " and ")}
+}
+
+// populatedSettings returns whether each type-specific setting is populated once the update is applied.
+func populatedSettings(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) map[string]bool {
+	lists := func(updated, current []*string) bool {
+		if updated != nil {
+			return len(updated) > 0
+		}
+		return len(current) > 0
+	}
+	flags := func(updated, current *bool) bool {
+		if updated != nil {
+			return *updated
+		}
+		return aws.BoolValue(current)
+	}
+	values := func(updated, current *string) bool {
+		if updated != nil {
+			return *updated != ""
+		}
+		return aws.StringValue(current) != ""
+	}
+
+	bucketRegions := len(integration.S3BucketRegions) > 0
+	if input.S3BucketRegions != nil {
+		bucketRegions = len(input.S3BucketRegions) > 0
+	}
+	objectFilters := len(integration.S3ObjectFilters) > 0
+	if input.S3ObjectFilters != nil {
+		objectFilters = len(input.S3ObjectFilters) > 0
+	}
+	return map[string]bool{
+		"cweEnabled":         flags(input.CWEEnabled, integration.CWEEnabled),
+		"remediationEnabled": flags(input.RemediationEnabled, integration.RemediationEnabled),
+		"s3Buckets":          lists(input.S3Buckets, integration.S3Buckets),
+		"kmsKeys":            lists(input.KmsKeys, integration.KmsKeys),
+		"s3BucketRegions":    bucketRegions,
+		"s3ObjectFilters":    objectFilters,
+		"createKmsGrants":    flags(input.CreateKmsGrants, integration.CreateKmsGrants),
+		"isOrgTrail":         flags(input.IsOrgTrail, integration.IsOrgTrail),
+		"includePatterns":    lists(input.IncludePatterns, integration.IncludePatterns),
+		"excludePatterns":    lists(input.ExcludePatterns, integration.ExcludePatterns),
+		"streamArn":          values(input.StreamARN, integration.StreamARN),
+		"shardIteratorType":  values(input.ShardIteratorType, integration.ShardIteratorType),
+		"roleChain":          lists(input.RoleChain, integration.RoleChain),
+		"syslogAllowedCidrs": lists(input.SyslogAllowedCIDRs, integration.SyslogAllowedCIDRs),
+
+		"organizationExcludedAccounts": lists(input.OrganizationExcludedAccounts, integration.OrganizationExcludedAccounts),
+	}
+}
+
+func usedBy(types []string, integrationType string) bool {
+	for _, candidate := range types {
+		if candidate == integrationType {
+			return true
+		}
+	}
+	return false
+}
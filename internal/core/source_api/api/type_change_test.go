@@ -0,0 +1,153 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func storedLogIntegration() *models.SourceIntegrationMetadata {
+	return &models.SourceIntegrationMetadata{
+		AWSAccountID:    aws.String(testAccountID),
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bucket"}),
+		KmsKeys:         aws.StringSlice([]string{"arn:aws:kms:us-west-2:123456789012:key/logs"}),
+	}
+}
+
+func TestUpdateIntegrationTypeLeftoverSettings(t *testing.T) {
+	mockClient := mockStoredIntegration(t, storedLogIntegration())
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSKinesis),
+		StreamARN:       aws.String(testStreamARN),
+	})
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Equal(t, "changing the integration type to aws-kinesis needs the same update to "+
+		"clear the incompatible settings: kmsKeys, s3Buckets", err.(*genericapi.InvalidInputError).Message)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationTypeMissingRequiredSettings(t *testing.T) {
+	integration := storedLogIntegration()
+	integration.IntegrationType, integration.S3Buckets, integration.KmsKeys = aws.String(models.IntegrationTypeAWSKinesis), nil, nil
+	integration.StreamARN = aws.String(testStreamARN)
+	mockStoredIntegration(t, integration)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+	})
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Equal(t, "changing the integration type to aws-s3 needs the same update to "+
+		"clear the incompatible settings: streamArn and set the required settings: s3Buckets",
+		err.(*genericapi.InvalidInputError).Message)
+}
+
+func TestUpdateIntegrationTypeCleared(t *testing.T) {
+	mockClient := mockStoredIntegration(t, storedLogIntegration())
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		assert.Equal(t, models.IntegrationTypeAWSKinesis, *input.IntegrationType)
+		assert.Empty(t, input.S3Buckets)
+		return true, nil, nil
+	}
+	mockKinesis := mockKinesisConsumers()
+	mockKinesis.On("RegisterStreamConsumer", mock.Anything).
+		Return(&kinesis.RegisterStreamConsumerOutput{Consumer: &kinesis.Consumer{ConsumerARN: aws.String(testConsumerARN)}}, nil)
+	resolveKmsKeysFunc = func(_ *string, keys []*string) ([]*string, map[string]*string, error) { return keys, nil, nil }
+	defer func() { resolveKmsKeysFunc = resolveKmsKeys }()
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSKinesis),
+		S3Buckets:       []*string{},
+		KmsKeys:         []*string{},
+		StreamARN:       aws.String(testStreamARN),
+	})
+	require.NoError(t, err)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	var values []string
+	for _, value := range update.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.Contains(t, values, models.IntegrationTypeAWSKinesis)
+}
+
+// Changing to a log analysis integration clears the stream and lets the account send to the log processing queue.
+func TestUpdateIntegrationTypeToLogAnalysis(t *testing.T) {
+	mockBucketOwnership(t)
+	integration := storedLogIntegration()
+	integration.IntegrationType, integration.S3Buckets, integration.KmsKeys = aws.String(models.IntegrationTypeAWSKinesis), nil, nil
+	integration.StreamARN = aws.String(testStreamARN)
+	mockClient := mockStoredIntegration(t, integration)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	// The account already sends to the queue for another log integration
+	policy, err := json.Marshal(&SqsPolicy{Statements: []SqsPolicyStatement{getStatementForAccount(testAccountID)}})
+	require.NoError(t, err)
+	mockSQS := &mockSQSClient{}
+	mockSQS.On("GetQueueAttributes", mock.Anything).
+		Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]*string{"Policy": aws.String(string(policy))}}, nil)
+	SQSClient = mockSQS
+
+	_, err = apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		S3Buckets:       aws.StringSlice([]string{"bucket"}),
+		StreamARN:       aws.String(""),
+	})
+	require.NoError(t, err)
+	update := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.True(t, strings.Contains(*update.UpdateExpression, "REMOVE"), *update.UpdateExpression)
+	mockSQS.AssertExpectations(t)
+}
+
+// A Kinesis integration can't clear its stream without changing its type.
+func TestUpdateIntegrationClearStreamOfKinesis(t *testing.T) {
+	integration := storedLogIntegration()
+	integration.IntegrationType, integration.S3Buckets, integration.KmsKeys = aws.String(models.IntegrationTypeAWSKinesis), nil, nil
+	integration.StreamARN = aws.String(testStreamARN)
+	mockStoredIntegration(t, integration)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		StreamARN:     aws.String(""),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
@@ -0,0 +1,87 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// The number of updates of a batch whose checks run at the same time
+const maxConcurrentUpdateChecks = 10
+
+// UpdateIntegrationsBatch updates the settings of several integrations, the outcome is reported for each update.
+//
+// The checks of the updates, including their health checks, run concurrently. The updates which pass them are then
+// written one at a time, in the order they are given. Unlike TransactUpdateIntegrations, an update which fails
+// doesn't prevent the others. Note the remediation quota is checked against the stored integrations, so a batch
+// enabling remediation for several of them at once can go over it. A dry run update is checked, never written.
+func (api API) UpdateIntegrationsBatch(input *models.UpdateIntegrationsBatchInput) ([]*models.IntegrationUpdateResult, error) {
+	api = api.withAlertOutputs()
+	results := make([]*models.IntegrationUpdateResult, len(input.Updates))
+	prepared := make([]*preparedUpdate, len(input.Updates))
+	duplicates := make([]error, len(input.Updates))
+	seen := make(map[string]struct{}, len(input.Updates))
+	for i, update := range input.Updates {
+		results[i] = &models.IntegrationUpdateResult{IntegrationID: update.IntegrationID, Success: aws.Bool(false)}
+		if _, ok := seen[*update.IntegrationID]; ok {
+			duplicates[i] = fmt.Errorf("integration %s is updated more than once", *update.IntegrationID)
+		}
+		seen[*update.IntegrationID] = struct{}{}
+	}
+
+	errs := forEachConcurrently(len(input.Updates), maxConcurrentUpdateChecks, func(i int) error {
+		if duplicates[i] != nil {
+			return duplicates[i]
+		}
+		var err error
+		prepared[i], err = api.withIntegration(input.Updates[i].IntegrationID).prepareUpdate(input.Updates[i], true)
+		return err
+	})
+
+	for i, update := range prepared {
+		if errs[i] == nil {
+			results[i].Integration, errs[i] = writeBatchUpdate(update)
+		}
+		if errs[i] != nil {
+			zap.L().Warn("failed to update integration in batch",
+				zap.String("integrationId", *results[i].IntegrationID), zap.Error(errs[i]))
+			results[i].ErrorMessage = aws.String(errs[i].Error())
+			continue
+		}
+		results[i].Success = aws.Bool(true)
+	}
+	return results, nil
+}
+
+func writeBatchUpdate(prepared *preparedUpdate) (*models.SourceIntegration, error) {
+	if aws.BoolValue(prepared.input.DryRun) {
+		return prepared.dryRun(), nil
+	}
+	result, err := updateWithExpectedScanStatus(prepared.item, prepared.input.ExpectedScanStatus)
+	if err != nil {
+		prepared.grants.discard()
+		return nil, err
+	}
+	return prepared.written(result)
+}
@@ -0,0 +1,119 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+const (
+	testBatchUnhealthyID = "5d0a6c57-3f1b-4d38-9e2a-8c4b7f1e6a90"
+	testBatchMissingID   = "7b3e9f12-6c4d-4a85-b1f0-2d9e8c7a5b43"
+	testUnhealthyAccount = "210987654321"
+)
+
+func TestUpdateIntegrationsBatch(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		return *input.AWSAccountID != testUnhealthyAccount, nil, nil
+	}
+	mockClient.On("GetItem", getItemFor(testIntegrationID)).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("GetItem", getItemFor(testBatchUnhealthyID)).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"integrationId":   {S: aws.String(testBatchUnhealthyID)},
+			"integrationType": {S: aws.String(models.IntegrationTypeAWSScan)},
+			"awsAccountId":    {S: aws.String(testUnhealthyAccount)},
+		},
+	}, nil)
+	mockClient.On("GetItem", getItemFor(testBatchMissingID)).Return(&dynamodb.GetItemOutput{}, nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"integrationId": {S: aws.String(testIntegrationID)},
+	}}, nil)
+
+	results, err := apiTest.UpdateIntegrationsBatch(&models.UpdateIntegrationsBatchInput{
+		Updates: []*models.UpdateIntegrationSettingsInput{
+			{IntegrationID: aws.String(testIntegrationID), ScanIntervalMins: aws.Int(360)},
+			{IntegrationID: aws.String(testBatchUnhealthyID), ScanIntervalMins: aws.Int(360)},
+			{IntegrationID: aws.String(testBatchMissingID), ScanIntervalMins: aws.Int(360)},
+			{IntegrationID: aws.String(testIntegrationID), ScanIntervalMins: aws.Int(720)},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.True(t, *results[0].Success)
+	assert.Equal(t, testIntegrationID, *results[0].Integration.IntegrationID)
+	assert.Nil(t, results[0].ErrorMessage)
+	for _, result := range results[1:] {
+		assert.False(t, *result.Success)
+		assert.Nil(t, result.Integration)
+	}
+	assert.Equal(t, testBatchUnhealthyID, *results[1].IntegrationID)
+	assert.NotEmpty(t, *results[1].ErrorMessage)
+	assert.Contains(t, *results[2].ErrorMessage, "Integration does not exist")
+	assert.Equal(t, "integration "+testIntegrationID+" is updated more than once", *results[3].ErrorMessage)
+
+	// Only the integration which passed its checks is written
+	mockClient.AssertNumberOfCalls(t, "UpdateItem", 1)
+}
+
+// The health checks of a batch run at the same time.
+func TestUpdateIntegrationsBatchConcurrentHealthChecks(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	// Each health check waits for the other one, they both pass only if they overlap
+	arrived := make(chan struct{}, 2)
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		arrived <- struct{}{}
+		deadline := time.After(5 * time.Second)
+		for len(arrived) < 2 {
+			select {
+			case <-deadline:
+				return false, nil, nil
+			case <-time.After(time.Millisecond):
+			}
+		}
+		return true, nil, nil
+	}
+
+	results, err := apiTest.UpdateIntegrationsBatch(&models.UpdateIntegrationsBatchInput{
+		Updates: []*models.UpdateIntegrationSettingsInput{
+			{IntegrationID: aws.String(testIntegrationID), ScanIntervalMins: aws.Int(360)},
+			{IntegrationID: aws.String(testBatchUnhealthyID), ScanIntervalMins: aws.Int(360)},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, *results[0].Success)
+	assert.True(t, *results[1].Success)
+}
@@ -0,0 +1,73 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// checkIfMatch rejects an update made from a version of the integration which is no longer current.
+//
+// Without the check, two users editing the same integration silently overwrite each other's changes.
+func checkIfMatch(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	if input.IfMatch == nil || aws.BoolValue(input.ForceUpdate) {
+		return nil
+	}
+	if etag := integrationETag(integration); *input.IfMatch != etag {
+		return versionConflict(etag)
+	}
+	return nil
+}
+
+// expectedVersion is the version the update is written under, so that a write in between its checks and its own
+// write is detected too. Updates which don't check the ETag are written whatever the version.
+func expectedVersion(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) *int64 {
+	if input.IfMatch == nil || aws.BoolValue(input.ForceUpdate) {
+		return nil
+	}
+	return aws.Int64(aws.Int64Value(integration.Version))
+}
+
+// checkVersionUnchanged returns a ConflictError if the update failed its condition because of its expected version.
+func checkVersionUnchanged(update *ddb.UpdateIntegrationItem) error {
+	if update.ExpectedVersion == nil {
+		return nil
+	}
+	integration, err := db.GetIntegration(update.IntegrationID, true)
+	if err != nil {
+		return err
+	}
+	if integration == nil {
+		return &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if aws.Int64Value(integration.Version) != *update.ExpectedVersion {
+		return versionConflict(integrationETag(integration))
+	}
+	return nil
+}
+
+func versionConflict(currentETag string) error {
+	return &genericapi.ConflictError{
+		Message: "the integration was modified since it was read, its current ETag is " + currentETag +
+			", read it again or force the update to overwrite the changes"}
+}
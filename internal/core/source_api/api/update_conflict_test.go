@@ -0,0 +1,120 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func storedVersion(version int64) *models.SourceIntegrationMetadata {
+	return &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		Version:         aws.Int64(version),
+	}
+}
+
+func TestUpdateIntegrationSettingsIfMatch(t *testing.T) {
+	mockClient := mockStoredIntegration(t, storedVersion(3))
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("logs"),
+		IfMatch:       aws.String(`"3"`),
+	})
+	require.NoError(t, err)
+
+	// The write is conditioned on the version which was checked
+	require.NotNil(t, update.ConditionExpression)
+	var names, versions []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	for _, value := range update.ExpressionAttributeValues {
+		if value.N != nil {
+			versions = append(versions, *value.N)
+		}
+	}
+	assert.Contains(t, names, "version")
+	assert.Contains(t, versions, "3")
+}
+
+func TestUpdateIntegrationSettingsIfMatchStale(t *testing.T) {
+	mockClient := mockStoredIntegration(t, storedVersion(4))
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("logs"),
+		IfMatch:       aws.String(`"3"`),
+	})
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.ConflictError{}, err)
+	assert.Contains(t, err.Error(), `its current ETag is "4"`)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsForceUpdate(t *testing.T) {
+	mockClient := mockStoredIntegration(t, storedVersion(4))
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("logs"),
+		IfMatch:       aws.String(`"3"`),
+		ForceUpdate:   aws.Bool(true),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, update.ConditionExpression)
+}
+
+// The integration is written by someone else between the checks of the update and its write.
+func TestUpdateIntegrationSettingsIfMatchWrittenInBetween(t *testing.T) {
+	mockClient := mockStoredIntegration(t, storedVersion(3))
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)).
+		Run(func(args mock.Arguments) {
+			item, err := dynamodbattribute.MarshalMap(storedVersion(5))
+			require.NoError(t, err)
+			mockClient.ExpectedCalls[0].ReturnArguments = mock.Arguments{&dynamodb.GetItemOutput{Item: item}, nil}
+		})
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("logs"),
+		IfMatch:       aws.String(`"3"`),
+	})
+	require.IsType(t, &genericapi.ConflictError{}, err)
+	assert.Contains(t, err.Error(), `its current ETag is "5"`)
+}
@@ -20,6 +20,13 @@ package api
 
 import (
 	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb"
@@ -29,46 +36,810 @@ import (
 // UpdateIntegrationSettings makes an update to an integration from the UI.
 //
 // This endpoint updates attributes such as the behavior of the integration, or display information.
+// With an ExpectedScanStatus, the update is rejected with a PreconditionFailedError if the status is different.
 func (api API) UpdateIntegrationSettings(input *models.UpdateIntegrationSettingsInput) (*models.SourceIntegration, error) {
+	api = api.withIntegration(input.IntegrationID)
+	api.log().Debug("updating integration settings")
+
+	prepared, err := api.prepareUpdate(input, true)
+	if err != nil {
+		return nil, err
+	}
+	if aws.BoolValue(input.DryRun) {
+		return prepared.dryRun(), nil
+	}
+	result, err := updateWithExpectedScanStatus(prepared.item, input.ExpectedScanStatus)
+	if err != nil {
+		prepared.grants.discard()
+		return nil, err
+	}
+	return prepared.written(result)
+}
+
+// preparedUpdate is an update of the settings of an integration which passed its checks, ready to be written.
+type preparedUpdate struct {
+	integration *models.SourceIntegrationMetadata
+	input       *models.UpdateIntegrationSettingsInput
+	item        *ddb.UpdateIntegrationItem
+	changes     []*models.IntegrationFieldChange
+
+	// Unset when only settings which need no health check are updated
+	healthChecked      bool
+	healthStatus       *string
+	failedHealthChecks []*string
+	// The result of each check of a dry run
+	health *models.SourceIntegrationHealth
+
+	// The change of the KMS grants, the grants it replaces are retired once the update is written
+	grants *kmsGrantChange
+	// The side effects which failed transiently, such as the KMS grants, they are retried once the update is written
+	pendingRetries []*transientError
+	// The consumer of the stream the update replaced, it is deregistered once the update is written
+	replacedConsumerARN *string
+}
+
+// prepareUpdate runs the checks of an update, including its health check, and builds the item to write.
+//
+// Unless syncGrants is set, the KMS grants are left as they are, see syncGrantsAfterWrite.
+// A dry run changes nothing: neither the grants nor the permissions of the queue, and a failed health check is
+// reported rather than returned.
+func (api API) prepareUpdate(input *models.UpdateIntegrationSettingsInput, syncGrants bool) (*preparedUpdate, error) {
 	// First get the current integration settings so that we can properly evaluate it
-	integration, err := db.GetIntegration(input.IntegrationID)
+	integration, err := db.GetIntegration(input.IntegrationID, true)
 	if err != nil {
 		return nil, err
 	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if err = checkIfMatch(integration, input); err != nil {
+		return nil, err
+	}
+	prepared := &preparedUpdate{integration: integration, input: input, changes: diffIntegration(integration, input)}
+
+	// The description, the data classification, the tags and the severity overrides don't affect ingestion,
+	// they don't need a health check
+	if onlyUncheckedSettings(input) {
+		prepared.item = &ddb.UpdateIntegrationItem{
+			IntegrationID:      input.IntegrationID,
+			Description:        sanitizeDescription(input.Description),
+			DataClassification: input.DataClassification,
+			Tags:               input.Tags,
+			SeverityOverrides:  input.SeverityOverrides,
+			ExpectedVersion:    expectedVersion(integration, input),
+		}
+		return prepared, nil
+	}
+
+	typeChanged := input.IntegrationType != nil && *input.IntegrationType != aws.StringValue(integration.IntegrationType)
+	if typeChanged {
+		if err = checkTypeChange(integration, input); err != nil {
+			return nil, err
+		}
+		// The remaining checks run against the new type
+		retyped := *integration
+		retyped.IntegrationType = input.IntegrationType
+		integration = &retyped
+	}
+
+	if input.IntegrationLabel != nil && !aws.BoolValue(input.AllowDuplicateLabel) {
+		labels, err := getIntegrationLabels(input.IntegrationID)
+		if err != nil {
+			return nil, err
+		}
+		account := accountOf(integration.AWSAccountID, integration.AzureSubscriptionID, integration.GCPProjectID)
+		if err = labels.check(account, input.IntegrationLabel); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.S3Buckets != nil {
+		if err = checkBucketsRemain(integration, input.S3Buckets, input.ScanEnabled); err != nil {
+			return nil, err
+		}
+	}
+
+	healthCheckInput := healthCheckInputForUpdate(integration, input)
+	if err = checkOrgTrailSettings(integration, healthCheckInput); err != nil {
+		return nil, err
+	}
+	if err = checkBucketOwnership(integration, input, healthCheckInput.S3BucketRegions); err != nil {
+		return nil, err
+	}
+	if err = checkStreamSettings(integration, input); err != nil {
+		return nil, err
+	}
+	if err = checkRoleChainSettings(integration, input); err != nil {
+		return nil, err
+	}
+	if err = checkCredentialsSettings(integration, input); err != nil {
+		return nil, err
+	}
+	if err = checkSessionDurationSettings(integration.IntegrationType, healthCheckInput); err != nil {
+		return nil, err
+	}
+	if err = checkTimeExtraction(integration.IntegrationType, input.TimeExtraction); err != nil {
+		return nil, err
+	}
+	if err = checkTimestampSkew(integration.IntegrationType, input.TimestampSkew); err != nil {
+		return nil, err
+	}
+	if err = checkOrderingForUpdate(integration, input); err != nil {
+		return nil, err
+	}
+	if err = checkIngestionAlarmSettings(
+		integration.IntegrationType, input.SilenceThresholdMinutes, input.EventErrorRateThreshold); err != nil {
+
+		return nil, err
+	}
+	if err = checkDeadLetterQueueSettings(integration.IntegrationType, input.DeadLetterQueue); err != nil {
+		return nil, err
+	}
+	if err = checkArchiveFormat(integration.IntegrationType, input.ArchiveFormat); err != nil {
+		return nil, err
+	}
+	if input.EnabledRegions != nil || input.ExcludedRegions != nil {
+		if err = checkScanRegionsForUpdate(integration, input); err != nil {
+			return nil, err
+		}
+	}
+	if input.ResourceTypeAllowList != nil || input.ResourceTypeDenyList != nil {
+		if err = checkResourceTypesForUpdate(integration, input); err != nil {
+			return nil, err
+		}
+	}
+	if input.S3BucketRegions != nil {
+		if err = checkBucketRegions(healthCheckInput.S3Buckets, healthCheckInput.S3BucketRegions); err != nil {
+			return nil, err
+		}
+	}
+	if input.S3ObjectFilters != nil {
+		buckets := input.S3Buckets
+		if buckets == nil {
+			buckets = integration.S3Buckets
+		}
+		if err = checkObjectFilters(integration.IntegrationID, buckets, input.S3ObjectFilters); err != nil {
+			return nil, err
+		}
+	}
+	if input.SyslogAllowedCIDRs != nil {
+		integrationType := input.IntegrationType
+		if integrationType == nil {
+			integrationType = integration.IntegrationType
+		}
+		if err = checkSyslogCIDRs(integrationType, input.SyslogAllowedCIDRs); err != nil {
+			return nil, err
+		}
+	}
+	if input.OrganizationExcludedAccounts != nil {
+		integrationType := input.IntegrationType
+		if integrationType == nil {
+			integrationType = integration.IntegrationType
+		}
+		if err = checkOrganizationSettings(integrationType, input.OrganizationExcludedAccounts); err != nil {
+			return nil, err
+		}
+	}
+	if input.DependsOn != nil {
+		if err = checkDependencies(input.IntegrationID, input.DependsOn); err != nil {
+			return nil, err
+		}
+	}
+	if err = api.checkNotificationTargets(input.NotificationTargets); err != nil {
+		return nil, err
+	}
+	if err = checkEnrichmentSources(input.EnrichmentSources); err != nil {
+		return nil, err
+	}
+	if err = checkProcessingRegion(input.ProcessingRegion); err != nil {
+		return nil, err
+	}
+	// An enablement which can't be verified for now is verified again in the background, see retryableSideEffects
+	if aws.BoolValue(input.RemediationEnabled) && !aws.BoolValue(integration.RemediationEnabled) {
+		if err = prepared.pendingRetry(checkRemediationQuota(input.IntegrationID, 1)); err != nil {
+			return nil, err
+		}
+	}
+	if aws.BoolValue(input.CWEEnabled) && !aws.BoolValue(integration.CWEEnabled) {
+		if err = prepared.pendingRetry(checkEventDelivery(integration.AWSAccountID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if aws.BoolValue(input.DiscoverKmsKeys) {
+		discoverKmsKeysForUpdate(prepared, healthCheckInput)
+	}
 
 	// Validate the updated integration settings
-	passing, err := evaluateIntegrationFunc(api, &models.CheckIntegrationInput{
-		// From existing integration
-		AWSAccountID:    integration.AWSAccountID,
-		IntegrationType: integration.IntegrationType,
+	var healthStatus *string
+	var failedHealthChecks []*string
+	var credentialExpiry *time.Time
+	if aws.BoolValue(input.DryRun) {
+		report, err := dryRunHealth(api, healthCheckInput, aws.BoolValue(input.AllowPartialHealth))
+		if err != nil {
+			return nil, err
+		}
+		healthStatus, failedHealthChecks, prepared.health = report.HealthStatus, report.FailedHealthChecks, report.Health
+		credentialExpiry = report.Health.CredentialExpiry
+	} else if healthStatus, failedHealthChecks, credentialExpiry, err = checkIntegrationHealth(
+		api, healthCheckInput, aws.BoolValue(input.AllowPartialHealth)); err != nil {
 
-		// From update integration request
-		EnableCWESetup:    input.CWEEnabled,
-		EnableRemediation: input.RemediationEnabled,
-		S3Buckets:         input.S3Buckets,
-		KmsKeys:           input.KmsKeys,
-	})
-	if err != nil {
 		return nil, err
 	}
-	if !passing {
-		return nil, &genericapi.InvalidInputError{Message: fmt.Sprintf("integration %s did not pass health check", *integration.AWSAccountID)}
+	prepared.healthChecked, prepared.healthStatus, prepared.failedHealthChecks = true, healthStatus, failedHealthChecks
+	// The keys of the update replace every key of the integration, the stored expiry holds when they don't change
+	if input.KmsKeys != nil {
+		credentialExpiry = storedCredentialExpiry(credentialExpiry)
+	} else {
+		credentialExpiry = nil
 	}
 
-	return db.UpdateItem(&ddb.UpdateIntegrationItem{
+	update := &ddb.UpdateIntegrationItem{
 		IntegrationID:      input.IntegrationID,
+		IntegrationType:    input.IntegrationType,
 		IntegrationLabel:   input.IntegrationLabel,
+		Description:        sanitizeDescription(input.Description),
 		ScanIntervalMins:   input.ScanIntervalMins,
 		ScanEnabled:        input.ScanEnabled,
 		CWEEnabled:         input.CWEEnabled,
 		RemediationEnabled: input.RemediationEnabled,
 		S3Buckets:          input.S3Buckets,
-		KmsKeys:            input.KmsKeys,
+		S3BucketRegions:    input.S3BucketRegions,
+		S3ObjectFilters:    input.S3ObjectFilters,
+		SyslogAllowedCIDRs: input.SyslogAllowedCIDRs,
+
+		OrganizationExcludedAccounts: input.OrganizationExcludedAccounts,
+
+		HealthStatus:       healthStatus,
+		FailedHealthChecks: failedHealthChecks,
+		LastHealthyTime:    lastHealthyTime(healthStatus),
+		CredentialExpiry:   credentialExpiry,
+
+		LastHealthCheckTime: aws.Time(time.Now()),
+
+		MaxConcurrentObjects:  input.MaxConcurrentObjects,
+		MaxObjectsPerScan:     input.MaxObjectsPerScan,
+		OrderingMode:          input.OrderingMode,
+		MaxParallelism:        input.MaxParallelism,
+		DedupWindowMinutes:    input.DedupWindowMinutes,
+		MaxRecordBytes:        input.MaxRecordBytes,
+		OversizedRecordPolicy: input.OversizedRecordPolicy,
+		SampleRate:            input.SampleRate,
+		ErrorRateThreshold:    input.ErrorRateThreshold,
+
+		SilenceThresholdMinutes: input.SilenceThresholdMinutes,
+		EventErrorRateThreshold: input.EventErrorRateThreshold,
+
+		TimeExtraction:        input.TimeExtraction,
+		TimestampSkew:         input.TimestampSkew,
+		DeadLetterQueue:       input.DeadLetterQueue,
+		ArchiveFormat:         input.ArchiveFormat,
+		DataClassification:    input.DataClassification,
+		ProcessingRegion:      input.ProcessingRegion,
+		BlackoutWindows:       input.BlackoutWindows,
+		ScanSchedule:          input.ScanSchedule,
+		EnabledRegions:        input.EnabledRegions,
+		ExcludedRegions:       input.ExcludedRegions,
+		ResourceTypeAllowList: input.ResourceTypeAllowList,
+		ResourceTypeDenyList:  input.ResourceTypeDenyList,
+		RedactionRules:        input.RedactionRules,
+		IncludePatterns:       input.IncludePatterns,
+		ExcludePatterns:       input.ExcludePatterns,
+		DisabledChecks:        input.DisabledChecks,
+		FeatureFlags:          input.FeatureFlags,
+		CreateKmsGrants:       input.CreateKmsGrants,
+		IsOrgTrail:            input.IsOrgTrail,
+		ManagementAccountID:   input.ManagementAccountID,
+		StreamARN:             input.StreamARN,
+		RoleChain:             input.RoleChain,
+		ShardIteratorType:     input.ShardIteratorType,
+		DependsOn:             input.DependsOn,
+		NotificationTargets:   input.NotificationTargets,
+		EnrichmentSources:     input.EnrichmentSources,
+		Tags:                  input.Tags,
+		SeverityOverrides:     input.SeverityOverrides,
+
+		SessionDurationSeconds:  input.SessionDurationSeconds,
+		ScanCompleteCallbackURL: input.ScanCompleteCallbackURL,
+		ExpectedVersion:         expectedVersion(integration, input),
+	}
+
+	// Pin KMS aliases to the keys they currently point to
+	if input.KmsKeys != nil {
+		if update.KmsKeys, update.KmsKeyAliases, err = resolveKmsKeysFunc(integration.AWSAccountID, input.KmsKeys); err != nil {
+			return nil, err
+		}
+	}
+	prepared.item = update
+	if aws.BoolValue(input.DryRun) {
+		return prepared, nil
+	}
+	if typeChanged && *input.IntegrationType == models.IntegrationTypeAWS3 {
+		// The queue already allows the accounts which have other log integrations
+		err = AddPermissionToLogProcessorQueue(*integration.AWSAccountID)
+		if _, ok := errors.Cause(err).(*genericapi.AlreadyExistsError); err != nil && !ok {
+			return nil, err
+		}
+	}
+	if input.Credentials != nil {
+		// The credentials are replaced in place, the secret keeps its ARN
+		rotated := *integration
+		if err = rotateCredentials(&rotated, input.Credentials); err != nil {
+			return nil, err
+		}
+		update.CredentialsSecretArn, update.CredentialsUpdatedTime = rotated.CredentialsSecretArn, rotated.CredentialsUpdatedTime
+	}
+	if input.StreamARN != nil && *input.StreamARN != aws.StringValue(integration.StreamARN) {
+		// The stream is read with a consumer of its own, the consumer of the previous stream is deregistered
+		// once the update is written
+		update.KinesisConsumerARN = aws.String("")
+		if *input.StreamARN != "" {
+			reading := *integration
+			reading.StreamARN, reading.RoleChain = input.StreamARN, healthCheckInput.RoleChain
+			if err = registerKinesisConsumer(&reading); err != nil {
+				return nil, err
+			}
+			update.KinesisConsumerARN = reading.KinesisConsumerARN
+		}
+		prepared.replacedConsumerARN = prepared.integration.KinesisConsumerARN
+	}
+	if !syncGrants {
+		return prepared, nil
+	}
+	// Grants which fail transiently are left as they are, they are reconciled in the background
+	prepared.grants, err = kmsGrantsForUpdate(integration, input, update.KmsKeys)
+	if err = prepared.pendingRetry(err); err != nil {
+		return nil, err
+	}
+	update.KmsGrants = prepared.grants.result()
+	return prepared, nil
+}
+
+// pendingRetry keeps a transient error of a side effect to retry it once the update is written, other errors are returned.
+func (prepared *preparedUpdate) pendingRetry(err error) error {
+	if transientErr, ok := err.(*transientError); ok {
+		prepared.pendingRetries = append(prepared.pendingRetries, transientErr)
+		return nil
+	}
+	return err
+}
+
+// dryRun is the stored integration along with the report of the dry run of the update.
+func (prepared *preparedUpdate) dryRun() *models.SourceIntegration {
+	integration := *prepared.integration
+	integration.DryRunReport = &models.DryRunReport{
+		HealthStatus:       integration.HealthStatus,
+		FailedHealthChecks: integration.FailedHealthChecks,
+		Changes:            prepared.changes,
+	}
+	if prepared.healthChecked {
+		integration.DryRunReport.HealthStatus = prepared.healthStatus
+		integration.DryRunReport.FailedHealthChecks = prepared.failedHealthChecks
+		integration.DryRunReport.Health = prepared.health
+	}
+	return &models.SourceIntegration{SourceIntegrationMetadata: &integration}
+}
+
+// written runs what follows the write of the update: the side effects, the change and health history and the notifications.
+func (prepared *preparedUpdate) written(result *models.SourceIntegration) (*models.SourceIntegration, error) {
+	input, integration := prepared.input, prepared.integration
+	prepared.grants.written(input.IntegrationID)
+	for _, cause := range prepared.pendingRetries {
+		if err := enqueueRetry(input.IntegrationID, cause); err != nil {
+			return nil, err
+		}
+	}
+	if prepared.replacedConsumerARN != nil {
+		deregisterKinesisConsumer(integration, prepared.replacedConsumerARN)
+	}
+	if err := recordUpdate(input.IntegrationID, input.UserID, prepared.changes); err != nil {
+		return nil, err
+	}
+	if !prepared.healthChecked {
+		return result, nil
+	}
+	err := recordHistory(&models.IntegrationHistoryRecord{
+		IntegrationID:      input.IntegrationID,
+		Kind:               aws.String(models.HistoryKindHealth),
+		HealthStatus:       prepared.healthStatus,
+		FailedHealthChecks: prepared.failedHealthChecks,
 	})
+	if err != nil {
+		return nil, err
+	}
+	if input.NotificationTargets != nil {
+		integration.NotificationTargets = input.NotificationTargets
+	}
+	if err := notifyHealthChange(integration, prepared.healthStatus, prepared.failedHealthChecks); err != nil {
+		return nil, err
+	}
+	if result != nil && result.SourceIntegrationMetadata != nil {
+		result.HealthCheckResult = healthCheckResult(prepared.healthStatus)
+	}
+	return refreshScanSchedule(result)
+}
+
+// updateWithExpectedScanStatus writes the update, only if the scan status is the expected one when it is set.
+//
+// The update also fails with a ConflictError if it expects a version of the integration which is no longer current.
+func updateWithExpectedScanStatus(update *ddb.UpdateIntegrationItem, expectedScanStatus *string) (*models.SourceIntegration, error) {
+	var result *models.SourceIntegration
+	var err error
+	if expectedScanStatus == nil {
+		result, err = db.UpdateItem(update)
+	} else {
+		condition := expression.Name("scanStatus").Equal(expression.Value(*expectedScanStatus))
+		result, err = db.UpdateItemWithCondition(update, condition)
+	}
+	if _, ok := err.(*ddb.ConditionalCheckFailedError); ok {
+		if conflict := checkVersionUnchanged(update); conflict != nil {
+			return nil, conflict
+		}
+		if expectedScanStatus != nil {
+			return nil, &genericapi.PreconditionFailedError{
+				Message: "the scan status of the integration is not " + *expectedScanStatus}
+		}
+	}
+	return result, err
+}
+
+// healthCheckInputForUpdate is the configuration the health check of an update runs with.
+//
+// The stored regions of the buckets are used unless new ones are given, and vice versa.
+func healthCheckInputForUpdate(
+	integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) *models.CheckIntegrationInput {
+
+	buckets, bucketRegions := input.S3Buckets, input.S3BucketRegions
+	if bucketRegions == nil {
+		bucketRegions = integration.S3BucketRegions
+	} else if buckets == nil {
+		buckets = integration.S3Buckets
+	}
+	disabledChecks := input.DisabledChecks
+	if disabledChecks == nil {
+		disabledChecks = integration.DisabledChecks
+	}
+	isOrgTrail, managementAccountID := input.IsOrgTrail, input.ManagementAccountID
+	if isOrgTrail == nil {
+		isOrgTrail = integration.IsOrgTrail
+	}
+	if managementAccountID == nil {
+		managementAccountID = integration.ManagementAccountID
+	}
+	streamARN := input.StreamARN
+	if streamARN == nil {
+		streamARN = integration.StreamARN
+	} else if *streamARN == "" {
+		streamARN = nil
+	}
+	// A new stream gets a new consumer once the update passes its checks
+	var consumerARN *string
+	if aws.StringValue(streamARN) == aws.StringValue(integration.StreamARN) {
+		consumerARN = integration.KinesisConsumerARN
+	}
+	roleChain := input.RoleChain
+	if roleChain == nil {
+		roleChain = integration.RoleChain
+	}
+	deadLetterQueue := input.DeadLetterQueue
+	if deadLetterQueue == nil {
+		deadLetterQueue = integration.DeadLetterQueue
+	}
+	archiveFormat := input.ArchiveFormat
+	if archiveFormat == nil {
+		archiveFormat = integration.ArchiveFormat
+	}
+	sessionDuration := input.SessionDurationSeconds
+	if sessionDuration == nil {
+		sessionDuration = integration.SessionDurationSeconds
+	}
+	return &models.CheckIntegrationInput{
+		// From existing integration
+		AWSAccountID:    integration.AWSAccountID,
+		IntegrationType: integration.IntegrationType,
+
+		AzureTenantID:        integration.AzureTenantID,
+		AzureSubscriptionID:  integration.AzureSubscriptionID,
+		AzureClientID:        integration.AzureClientID,
+		AzureClientSecretArn: integration.AzureClientSecretArn,
+
+		GCPProjectID:               integration.GCPProjectID,
+		GCPServiceAccountSecretArn: integration.GCPServiceAccountSecretArn,
+		GCSBucket:                  integration.GCSBucket,
+		PubSubSubscription:         integration.PubSubSubscription,
+
+		SQSQueueArn:        integration.SQSQueueArn,
+		HTTPIngestAPIKeyID: integration.HTTPIngestAPIKeyID,
+
+		// The credentials of the update are checked before they replace the stored ones
+		OktaDomain:           integration.OktaDomain,
+		Credentials:          input.Credentials,
+		CredentialsSecretArn: integration.CredentialsSecretArn,
+
+		// From update integration request
+		EnableCWESetup:    input.CWEEnabled,
+		EnableRemediation: input.RemediationEnabled,
+		S3Buckets:         buckets,
+		KmsKeys:           input.KmsKeys,
+		S3BucketRegions:   bucketRegions,
+		DisabledChecks:    disabledChecks,
+		KmsGrants:         integration.KmsGrants,
+
+		IsOrgTrail:          isOrgTrail,
+		ManagementAccountID: managementAccountID,
+		StreamARN:           streamARN,
+		KinesisConsumerARN:  consumerARN,
+		DeadLetterQueueArn:  deadLetterQueueArn(deadLetterQueue),
+		ArchiveFormat:       archiveFormat,
+		RoleChain:           roleChain,
+
+		SessionDurationSeconds: sessionDuration,
+	}
+}
+
+// discoverKmsKeysForUpdate adds the KMS keys the buckets of the update are encrypted with to the keys of the update.
+//
+// The discovered keys are added to the stored ones when the update doesn't change the keys.
+func discoverKmsKeysForUpdate(prepared *preparedUpdate, healthCheckInput *models.CheckIntegrationInput) {
+	keys := prepared.input.KmsKeys
+	if keys == nil {
+		keys = prepared.integration.KmsKeys
+	}
+	discoverInput := *healthCheckInput
+	discoverInput.KmsKeys = keys
+	discovered := discoverKmsKeysFunc(&discoverInput)
+	if len(discovered) == 0 {
+		return
+	}
+	prepared.input.KmsKeys = append(append(make([]*string, 0, len(keys)+len(discovered)), keys...), discovered...)
+	healthCheckInput.KmsKeys = prepared.input.KmsKeys
+	prepared.changes = diffIntegration(prepared.integration, prepared.input)
+}
+
+// checkOrgTrailSettings rejects org trail settings the update would leave incomplete, or set on a type without org trails.
+func checkOrgTrailSettings(integration *models.SourceIntegrationMetadata, healthCheckInput *models.CheckIntegrationInput) error {
+	if !aws.BoolValue(healthCheckInput.IsOrgTrail) {
+		return nil
+	}
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWS3 {
+		return &genericapi.InvalidInputError{Message: "only log analysis integrations can be org trails"}
+	}
+	if healthCheckInput.ManagementAccountID == nil {
+		return &genericapi.InvalidInputError{Message: "an org trail needs the management account of its organization"}
+	}
+	return nil
+}
+
+// checkStreamSettings rejects stream settings on an integration which doesn't read from a Kinesis stream.
+//
+// Empty settings clear the stream, which a Kinesis integration can't be without.
+func checkStreamSettings(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	isKinesis := aws.StringValue(integration.IntegrationType) == models.IntegrationTypeAWSKinesis
+	if (aws.StringValue(input.StreamARN) != "" || aws.StringValue(input.ShardIteratorType) != "") && !isKinesis {
+		return &genericapi.InvalidInputError{Message: "only Kinesis integrations have a stream"}
+	}
+	if input.StreamARN != nil && *input.StreamARN == "" && isKinesis {
+		return &genericapi.InvalidInputError{Message: "a Kinesis integration can't be without a stream"}
+	}
+	return nil
+}
+
+// checkRoleChainSettings rejects a role chain on an integration which doesn't read logs.
+func checkRoleChainSettings(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	if len(input.RoleChain) > 0 && aws.StringValue(integration.IntegrationType) == models.IntegrationTypeAWSScan {
+		return &genericapi.InvalidInputError{Message: "only log integrations can assume a role chain"}
+	}
+	return nil
+}
+
+// checkOrderingForUpdate checks the ordering settings of an integration merged with those the update changes.
+func checkOrderingForUpdate(integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput) error {
+	if input.OrderingMode == nil && input.MaxParallelism == nil {
+		return nil
+	}
+	mode, parallelism := integration.OrderingMode, integration.MaxParallelism
+	if input.OrderingMode != nil {
+		mode = input.OrderingMode
+	}
+	if input.MaxParallelism != nil {
+		parallelism = input.MaxParallelism
+	}
+	return checkOrdering(integration.IntegrationType, mode, parallelism)
+}
+
+// checkTimeExtraction rejects a time extraction which can't be applied, or which is set on an integration without logs.
+//
+// An empty extraction removes it, there is nothing to check.
+func checkTimeExtraction(integrationType *string, extraction *models.TimeExtraction) error {
+	if extraction == nil || *extraction == (models.TimeExtraction{}) {
+		return nil
+	}
+	switch aws.StringValue(integrationType) {
+	case models.IntegrationTypeAWSScan:
+		return &genericapi.InvalidInputError{Message: "only log integrations have a time extraction"}
+	case models.IntegrationTypeAWSKinesis:
+		if extraction.KeyPattern != nil {
+			return &genericapi.InvalidInputError{Message: "timeExtraction.keyPattern: Kinesis records have no object key"}
+		}
+	}
+	if err := extraction.Validate(); err != nil {
+		return &genericapi.InvalidInputError{Message: err.Error()}
+	}
+	return nil
+}
+
+// checkTimestampSkew rejects a timestamp skew without any bound, or set on an integration without logs.
+//
+// The bounds and the policy are validated with the input, an empty skew removes it.
+func checkTimestampSkew(integrationType *string, skew *models.TimestampSkew) error {
+	if skew == nil || *skew == (models.TimestampSkew{}) {
+		return nil
+	}
+	if aws.StringValue(integrationType) == models.IntegrationTypeAWSScan {
+		return &genericapi.InvalidInputError{Message: "only log integrations have a timestamp skew"}
+	}
+	if skew.MaxFutureSkewMins == nil && skew.MaxPastSkewMins == nil {
+		return &genericapi.InvalidInputError{Message: "timestampSkew: either maxFutureSkewMins or maxPastSkewMins is required"}
+	}
+	return nil
+}
+
+// checkOrdering rejects strict ordering with more than one object ingested at once, or ordering settings on an
+// integration without logs.
+//
+// The mode and the parallelism are those the integration has once the change is applied.
+func checkOrdering(integrationType, mode *string, parallelism *int) error {
+	if mode == nil && parallelism == nil {
+		return nil
+	}
+	if aws.StringValue(integrationType) == models.IntegrationTypeAWSScan {
+		return &genericapi.InvalidInputError{Message: "only the ingestion of log integrations can be ordered"}
+	}
+	if aws.StringValue(mode) == models.OrderingModeStrict && aws.IntValue(parallelism) > 1 {
+		return &genericapi.InvalidInputError{Message: "maxParallelism: strict ordering ingests a single object at a time"}
+	}
+	return nil
+}
+
+// checkDeadLetterQueueSettings rejects a dead letter queue which is incomplete, or set on an integration without notifications.
+//
+// An empty queue removes it, there is nothing to check.
+func checkDeadLetterQueueSettings(integrationType *string, queue *models.DeadLetterQueue) error {
+	if queue == nil || *queue == (models.DeadLetterQueue{}) {
+		return nil
+	}
+	if aws.StringValue(integrationType) != models.IntegrationTypeAWS3 {
+		return &genericapi.InvalidInputError{Message: "only the notifications of log analysis integrations have a dead letter queue"}
+	}
+	if queue.QueueArn == nil || queue.MaxReceiveCount == nil {
+		return &genericapi.InvalidInputError{Message: "deadLetterQueue: both queueArn and maxReceiveCount are required"}
+	}
+	return nil
+}
+
+// checkArchiveFormat rejects an archive format on an integration which doesn't read objects from S3.
+// The format itself is validated with the input.
+func checkArchiveFormat(integrationType, format *string) error {
+	if format == nil || aws.StringValue(integrationType) == models.IntegrationTypeAWS3 {
+		return nil
+	}
+	return &genericapi.InvalidInputError{Message: "only the objects of log analysis integrations can be archives"}
+}
+
+// deadLetterQueueArn returns the ARN of a dead letter queue, nil if there is none.
+func deadLetterQueueArn(queue *models.DeadLetterQueue) *string {
+	if queue == nil {
+		return nil
+	}
+	return queue.QueueArn
+}
+
+// kmsGrantsForUpdate creates the grants of the keys of an integration after the update, the others are retired once
+// the update is written, all of them when grants are turned off. Nil is returned when the grants don't change.
+func kmsGrantsForUpdate(
+	integration *models.SourceIntegrationMetadata, input *models.UpdateIntegrationSettingsInput, keys []*string) (*kmsGrantChange, error) {
+
+	createGrants := input.CreateKmsGrants
+	if createGrants == nil {
+		createGrants = integration.CreateKmsGrants
+	}
+	// Only log analysis integrations decrypt objects, the grants left by another type are retired
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWS3 {
+		createGrants = aws.Bool(false)
+	}
+	if !aws.BoolValue(createGrants) && len(integration.KmsGrants) == 0 {
+		return nil, nil
+	}
+
+	if keys == nil {
+		keys = integration.KmsKeys
+	}
+	if !aws.BoolValue(createGrants) {
+		keys = nil
+	}
+	return syncKmsGrants(integration.AWSAccountID, integration.IntegrationID, keys, integration.KmsGrants)
+}
+
+// onlyUncheckedSettings returns true if the update sets the settings which don't affect ingestion, and nothing else.
+func onlyUncheckedSettings(input *models.UpdateIntegrationSettingsInput) bool {
+	return (input.Description != nil || input.DataClassification != nil || input.Tags != nil || input.SeverityOverrides != nil) &&
+		reflect.DeepEqual(*input, models.UpdateIntegrationSettingsInput{
+			IntegrationID:      input.IntegrationID,
+			Description:        input.Description,
+			DataClassification: input.DataClassification,
+			Tags:               input.Tags,
+			SeverityOverrides:  input.SeverityOverrides,
+			UserID:             input.UserID,
+			IfMatch:            input.IfMatch,
+			ForceUpdate:        input.ForceUpdate,
+			DryRun:             input.DryRun,
+		})
+}
+
+// checkBucketsRemain rejects leaving an enabled log integration without buckets, which silently stops its ingestion.
+//
+// Log integrations don't set scanEnabled, so they are enabled unless it is explicitly false after the update.
+func checkBucketsRemain(integration *models.SourceIntegrationMetadata, buckets []*string, scanEnabled *bool) error {
+	if aws.StringValue(integration.IntegrationType) != models.IntegrationTypeAWS3 || len(buckets) > 0 {
+		return nil
+	}
+	if scanEnabled == nil {
+		scanEnabled = integration.ScanEnabled
+	}
+	if scanEnabled != nil && !*scanEnabled {
+		return nil
+	}
+	return &genericapi.InvalidInputError{
+		Message: "an enabled log integration needs at least one S3 bucket, disable it to remove every bucket"}
+}
+
+// checkBucketRegions rejects regions given for a bucket which isn't one of the buckets of the integration.
+func checkBucketRegions(buckets []*string, regions map[string]*string) error {
+	names := make(map[string]struct{}, len(buckets))
+	for _, bucket := range buckets {
+		name, _ := parseBucketEntry(aws.StringValue(bucket))
+		names[name] = struct{}{}
+	}
+	for name := range regions {
+		if _, ok := names[name]; !ok {
+			return &genericapi.InvalidInputError{Message: fmt.Sprintf("s3BucketRegions: %s is not one of the s3Buckets", name)}
+		}
+	}
+	return nil
+}
+
+// bucketRegionsFor returns the regions of the given buckets only, nil if none of them has a region.
+func bucketRegionsFor(buckets []*string, regions map[string]*string) map[string]*string {
+	var result map[string]*string
+	for _, bucket := range buckets {
+		name, _ := parseBucketEntry(aws.StringValue(bucket))
+		if region, ok := regions[name]; ok {
+			if result == nil {
+				result = make(map[string]*string)
+			}
+			result[name] = region
+		}
+	}
+	return result
 }
 
 // UpdateIntegrationLastScanStart updates an integration when a new scan is started.
+//
+// Scans can't start during a blackout window of the integration, it is read consistently so that a cached copy
+// doesn't miss a window which was just added.
 func (API) UpdateIntegrationLastScanStart(input *models.UpdateIntegrationLastScanStartInput) (*models.SourceIntegration, error) {
+	integration, err := db.GetIntegration(input.IntegrationID, true)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if end, inBlackout := blackoutEnd(integration.BlackoutWindows, time.Now()); inBlackout {
+		return nil, &genericapi.UnavailableError{
+			Message: "integration is in a blackout window until " + end.Format(time.RFC3339)}
+	}
+
 	return db.UpdateItem(&ddb.UpdateIntegrationItem{
 		IntegrationID:     input.IntegrationID,
 		LastScanStartTime: input.LastScanStartTime,
@@ -76,12 +847,163 @@ func (API) UpdateIntegrationLastScanStart(input *models.UpdateIntegrationLastSca
 	})
 }
 
+// UpdateIntegrationsLastScanStart updates many integrations when their scans are started, the outcome is reported
+// for each of them.
+//
+// The integrations are read with a batch request rather than a round-trip for each of them, the starts are then
+// written in transactions of up to 25 integrations, each unless the integration was updated concurrently. It is then
+// read and checked again.
+func (API) UpdateIntegrationsLastScanStart(
+	input *models.UpdateIntegrationsLastScanStartInput) ([]*models.IntegrationUpdateResult, error) {
+
+	now := time.Now()
+	updates := make([]*ddb.ScanStatusUpdate, len(input.Updates))
+	for i, update := range input.Updates {
+		updates[i] = &ddb.ScanStatusUpdate{
+			IntegrationID:     update.IntegrationID,
+			ScanStatus:        update.ScanStatus,
+			LastScanStartTime: update.LastScanStartTime,
+		}
+	}
+	integrations, errs := db.BatchUpdateScanStatus(updates, func(integration *models.SourceIntegration) error {
+		if end, inBlackout := blackoutEnd(integration.BlackoutWindows, now); inBlackout {
+			return &genericapi.UnavailableError{
+				Message: "integration is in a blackout window until " + end.Format(time.RFC3339)}
+		}
+		return nil
+	})
+
+	results := make([]*models.IntegrationUpdateResult, len(updates))
+	for i, update := range updates {
+		results[i] = &models.IntegrationUpdateResult{IntegrationID: update.IntegrationID, Success: aws.Bool(errs[i] == nil)}
+		if errs[i] != nil {
+			zap.L().Warn("failed to record the start of the scan",
+				zap.String("integrationId", *update.IntegrationID), zap.Error(errs[i]))
+			results[i].ErrorMessage = aws.String(errs[i].Error())
+			continue
+		}
+		results[i].Integration = integrations[i]
+	}
+	return results, nil
+}
+
 // UpdateIntegrationLastScanEnd updates an integration when a scan ends.
+//
+// If the scan reports a bookmark, it must not be behind the one already stored.
+// Scans truncated by the object cap are counted, a backlog is suspected when too many happen in a row. A scan which
+// doesn't report whether it was truncated counts as complete.
+// Every scan is counted towards the ramp-up of the scan interval.
+// Error samples are redacted and added to the ones of the previous scans, a concurrent scan's samples are kept.
+// The duration of the scan is added to the rolling average of the integration.
+// The next scan is scheduled from the end of this one, and a summary of the scan is posted to its callback.
+// The scan is added to the history of the integration.
 func (API) UpdateIntegrationLastScanEnd(input *models.UpdateIntegrationLastScanEndInput) (*models.SourceIntegration, error) {
-	return db.UpdateItem(&ddb.UpdateIntegrationItem{
+	// Scanners which don't report truncation never truncate, their scans reset the counter
+	truncated := aws.Bool(aws.BoolValue(input.ScanTruncated))
+	update := &ddb.UpdateIntegrationItem{
 		IntegrationID:        input.IntegrationID,
 		LastScanEndTime:      input.LastScanEndTime,
 		LastScanErrorMessage: input.LastScanErrorMessage,
 		ScanStatus:           input.ScanStatus,
-	})
+
+		LastScanTruncated:         truncated,
+		LastScanObjectsProcessed:  input.ObjectsProcessed,
+		ConsecutiveTruncatedScans: truncated,
+		CompletedScans:            aws.Bool(true),
+	}
+	var condition *expression.ConditionBuilder
+	if input.LastScanBookmark != nil {
+		update.LastScanBookmark = input.LastScanBookmark
+		bookmark := expression.Name("lastScanBookmark")
+		bookmarkCondition := expression.Or(
+			expression.AttributeNotExists(bookmark),
+			bookmark.LessThanEqual(expression.Value(*input.LastScanBookmark)),
+		)
+		condition = &bookmarkCondition
+	}
+
+	// The samples are rewritten whole, under the condition that the integration didn't change since they were read.
+	// They are read again when it did, so that the samples of concurrent scans are not lost
+	var readVersion *int64
+	for attempt := 1; ; attempt++ {
+		if len(input.ErrorSamples) > 0 {
+			samples, version, err := addScanErrorSamples(input.IntegrationID, input.ErrorSamples)
+			if err != nil {
+				return nil, err
+			}
+			if readVersion != nil && *readVersion == version && condition != nil {
+				// The integration didn't change, the bookmark failed the condition
+				return nil, bookmarkBackwardsError()
+			}
+			readVersion = aws.Int64(version)
+			update.ScanErrorSamples, update.ExpectedVersion = samples, readVersion
+		}
+
+		var result *models.SourceIntegration
+		var err error
+		if condition == nil {
+			result, err = db.UpdateItem(update)
+		} else {
+			result, err = db.UpdateItemWithCondition(update, *condition)
+		}
+		if _, conflict := err.(*ddb.ConditionalCheckFailedError); conflict {
+			if readVersion != nil && attempt < maxScanErrorSampleAttempts {
+				continue
+			}
+			if readVersion == nil {
+				return nil, bookmarkBackwardsError()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return scanEnded(input, result)
+	}
+}
+
+func bookmarkBackwardsError() error {
+	return &genericapi.InvalidInputError{
+		Message: "scan bookmark can't move backwards, reset the bookmark to start a full scan"}
+}
+
+// scanEnded records the duration of the scan which ended, schedules the next scan of the integration,
+// posts the summary of the scan, alerts on its error rate and adds it to the history, which keeps the most
+// recent scans.
+func scanEnded(input *models.UpdateIntegrationLastScanEndInput, result *models.SourceIntegration) (*models.SourceIntegration, error) {
+	result = recordScanDuration(result)
+	result, err := refreshScanSchedule(result)
+	if err != nil {
+		return nil, err
+	}
+	postScanComplete(result.SourceIntegrationMetadata, input)
+	if err = notifyErrorRate(result.SourceIntegrationMetadata, input); err != nil {
+		return nil, err
+	}
+	record := &models.IntegrationHistoryRecord{
+		IntegrationID:    input.IntegrationID,
+		Kind:             aws.String(models.HistoryKindScan),
+		ScanStatus:       input.ScanStatus,
+		ScanErrorMessage: input.LastScanErrorMessage,
+		ObjectsProcessed: input.ObjectsProcessed,
+		ObjectsFailed:    input.ObjectsFailed,
+		ScanTruncated:    input.ScanTruncated,
+	}
+	if scan := result.SourceIntegrationScanInformation; scan != nil && scan.LastScanStartTime != nil {
+		record.ScanStartTime = scan.LastScanStartTime
+		if scan.LastScanStartTime.Before(*input.LastScanEndTime) {
+			record.ScanDurationSecs = aws.Int64(int64(input.LastScanEndTime.Sub(*scan.LastScanStartTime).Seconds()))
+		}
+	}
+	if err = recordHistory(record); err != nil {
+		return nil, err
+	}
+	if err = trimScanHistory(input.IntegrationID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResetIntegrationBookmark clears the scan bookmark of an integration so that the next scan lists every object.
+func (API) ResetIntegrationBookmark(input *models.ResetIntegrationBookmarkInput) (*models.SourceIntegration, error) {
+	return db.RemoveAttributes(input.IntegrationID, "lastScanBookmark")
 }
@@ -19,11 +19,15 @@ package api
  */
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -32,12 +36,13 @@ import (
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 func TestUpdateIntegrationSettings(t *testing.T) {
 	mockClient := &modelstest.MockDDBClient{}
 	db = &ddb.DDB{Client: mockClient, TableName: "test"}
-	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, error) { return true, nil }
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
 
 	getResponse := &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
 		"AWSAccountID":  {S: aws.String("123456789012")},
@@ -58,7 +63,10 @@ func TestUpdateIntegrationSettings(t *testing.T) {
 	})
 
 	expected := &models.SourceIntegration{
-		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{ScanEnabled: aws.Bool(false)},
+		SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+			ScanEnabled:       aws.Bool(false),
+			HealthCheckResult: aws.String(models.HealthCheckSuccess),
+		},
 	}
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
@@ -88,6 +96,416 @@ func TestUpdateIntegrationSettingsAwsS3Type(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestUpdateIntegrationSettingsRemoveEveryBucket(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		S3Buckets:     []*string{},
+	})
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Contains(t, err.Error(), "at least one S3 bucket")
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsRemoveEveryBucketWhileDisabling(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		ScanEnabled:   aws.Bool(false),
+		S3Buckets:     []*string{},
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestCheckBucketsRemain(t *testing.T) {
+	logs := &models.SourceIntegrationMetadata{IntegrationType: aws.String(models.IntegrationTypeAWS3)}
+	assert.Error(t, checkBucketsRemain(logs, nil, nil))
+	assert.Error(t, checkBucketsRemain(logs, nil, aws.Bool(true)))
+	assert.NoError(t, checkBucketsRemain(logs, aws.StringSlice([]string{"bucket"}), nil))
+	assert.NoError(t, checkBucketsRemain(logs, nil, aws.Bool(false)))
+
+	// Already disabled
+	logs.ScanEnabled = aws.Bool(false)
+	assert.NoError(t, checkBucketsRemain(logs, nil, nil))
+
+	// Cloud security integrations have no buckets
+	assert.NoError(t, checkBucketsRemain(
+		&models.SourceIntegrationMetadata{IntegrationType: aws.String(models.IntegrationTypeAWSScan)}, nil, nil))
+}
+
+func TestUpdateIntegrationSettingsDescription(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", Clock: func() time.Time { return now }}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		t.Fatal("no health check is needed to update the description")
+		return false, nil, nil
+	}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("Owned by\x1b[31m team-logs\x00\nRunbook:\thttps://example.com"),
+	})
+	require.NoError(t, err)
+
+	var values []string
+	for _, value := range update.ExpressionAttributeValues {
+		if value.S != nil {
+			values = append(values, *value.S)
+		}
+	}
+	assert.ElementsMatch(t, []string{"Owned by[31m team-logs\nRunbook:\thttps://example.com", ddb.ModifiedTime(now), "integrations"}, values)
+}
+
+// The classification is written without a health check, and read back from the stored integration.
+func TestUpdateIntegrationSettingsDataClassification(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		t.Fatal("no health check is needed to update the data classification")
+		return false, nil, nil
+	}
+	stored := getItem(models.IntegrationTypeAWS3)
+	mockClient.On("GetItem", mock.Anything).Return(stored, nil)
+	updated := &dynamodb.UpdateItemOutput{Attributes: stored.Item}
+	mockClient.On("UpdateItem", mock.Anything).Return(updated, nil).Run(func(args mock.Arguments) {
+		for _, value := range args.Get(0).(*dynamodb.UpdateItemInput).ExpressionAttributeValues {
+			if aws.StringValue(value.S) == models.DataClassificationConfidential {
+				stored.Item["dataClassification"] = value
+			}
+		}
+	})
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		DataClassification: aws.String(models.DataClassificationConfidential),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.DataClassificationConfidential, aws.StringValue(result.DataClassification))
+
+	read, err := apiTest.GetIntegration(&models.GetIntegrationInput{IntegrationID: aws.String(testIntegrationID)})
+	require.NoError(t, err)
+	assert.Equal(t, models.DataClassificationConfidential, aws.StringValue(read.Integration.DataClassification))
+}
+
+func TestDataClassificationValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	input := &models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID)}
+	for _, level := range []string{
+		models.DataClassificationPublic, models.DataClassificationInternal,
+		models.DataClassificationConfidential, models.DataClassificationRestricted,
+	} {
+		input.DataClassification = aws.String(level)
+		assert.NoError(t, validator.Struct(input), level)
+	}
+	input.DataClassification = aws.String("secret")
+	assert.Error(t, validator.Struct(input))
+
+	settings := validSettings(models.IntegrationTypeAWS3)
+	settings.DataClassification = aws.String("Internal")
+	assert.Error(t, validator.Struct(settings), "levels are case sensitive")
+}
+
+func TestUpdateIntegrationSettingsBucketRegions(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	var checked *models.CheckIntegrationInput
+	evaluateIntegrationFunc = func(_ API, input *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		checked = input
+		return true, nil, nil
+	}
+	item := getItem(models.IntegrationTypeAWS3)
+	item.Item["s3Buckets"] = &dynamodb.AttributeValue{SS: aws.StringSlice([]string{"logs-us", "logs-eu/cloudtrail"})}
+	mockClient.On("GetItem", mock.Anything).Return(item, nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	regions := map[string]*string{"logs-us": aws.String("us-east-1"), "logs-eu": aws.String("eu-west-1")}
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		S3BucketRegions: regions,
+	})
+	require.NoError(t, err)
+
+	// The stored buckets are checked in their new region
+	assert.Equal(t, aws.StringSlice([]string{"logs-us", "logs-eu/cloudtrail"}), checked.S3Buckets)
+	assert.Equal(t, regions, checked.S3BucketRegions)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsRegionOfUnknownBucket(t *testing.T) {
+	mockBucketOwnership(t)
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		S3Buckets:       aws.StringSlice([]string{"logs"}),
+		S3BucketRegions: map[string]*string{"other": aws.String("eu-west-1")},
+	})
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	assert.Contains(t, err.Error(), "other is not one of the s3Buckets")
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsInvalidRegion(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	input := &models.UpdateIntegrationSettingsInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		S3BucketRegions: map[string]*string{"logs": aws.String("eu-west-1"), "archive": aws.String("ap-southeast-2")},
+	}
+	assert.NoError(t, validator.Struct(input))
+	input.S3BucketRegions["logs"] = aws.String("moon-1")
+	assert.Error(t, validator.Struct(input))
+}
+
+func TestUpdateIntegrationSettingsFeatureFlagsValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	input := &models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		FeatureFlags:  map[string]bool{models.FeatureFlagParserV2: true, models.FeatureFlagBetaEnrichment: false},
+	}
+	assert.NoError(t, validator.Struct(input))
+	input.FeatureFlags["fasterParser"] = true
+	assert.Error(t, validator.Struct(input))
+}
+
+func TestUpdateIntegrationSettingsFeatureFlags(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"featureFlags": {M: map[string]*dynamodb.AttributeValue{models.FeatureFlagParserV2: {BOOL: aws.Bool(true)}}},
+	}}, nil).Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		FeatureFlags:  map[string]bool{models.FeatureFlagParserV2: true},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{
+		M: map[string]*dynamodb.AttributeValue{models.FeatureFlagParserV2: {BOOL: aws.Bool(true)}}})
+	assert.True(t, result.FeatureEnabled(models.FeatureFlagParserV2))
+	assert.False(t, result.FeatureEnabled(models.FeatureFlagBetaEnrichment))
+}
+
+func TestSeverityOverridesValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	input := &models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		SeverityOverrides: map[string]*string{
+			"AWS.CloudTrail.*":         aws.String(models.SeverityHigh),
+			"AWS.S3.Bucket.PublicRead": aws.String(models.SeverityInfo),
+		},
+	}
+	assert.NoError(t, validator.Struct(input))
+	input.SeverityOverrides["AWS.IAM.RootLogin"] = aws.String("URGENT")
+	assert.Error(t, validator.Struct(input))
+	// Severities are case sensitive
+	input.SeverityOverrides["AWS.IAM.RootLogin"] = aws.String("critical")
+	assert.Error(t, validator.Struct(input))
+	input.SeverityOverrides = map[string]*string{"": aws.String(models.SeverityLow)}
+	assert.Error(t, validator.Struct(input))
+	input.SeverityOverrides = map[string]*string{"AWS.IAM.RootLogin": nil}
+	assert.Error(t, validator.Struct(input))
+}
+
+func TestUpdateIntegrationSettingsSeverityOverrides(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	overrides := map[string]*dynamodb.AttributeValue{"AWS.CloudTrail.*": {S: aws.String(models.SeverityCritical)}}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"severityOverrides": {M: overrides},
+	}}, nil).Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	// The overrides don't affect ingestion, no health check is run
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:     aws.String(testIntegrationID),
+		SeverityOverrides: map[string]*string{"AWS.CloudTrail.*": aws.String(models.SeverityCritical)},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{M: overrides})
+	assert.Equal(t, map[string]*string{"AWS.CloudTrail.*": aws.String(models.SeverityCritical)}, result.SeverityOverrides)
+}
+
+func TestBucketRegionsFor(t *testing.T) {
+	regions := map[string]*string{"logs": aws.String("eu-west-1"), "gone": aws.String("us-east-2")}
+	assert.Equal(t, map[string]*string{"logs": aws.String("eu-west-1")},
+		bucketRegionsFor(aws.StringSlice([]string{"logs/prefix", "other"}), regions))
+	assert.Nil(t, bucketRegionsFor(aws.StringSlice([]string{"other"}), regions))
+}
+
+func TestUpdateIntegrationSettingsDescriptionTooLong(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+
+	input := &models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String(strings.Repeat("a", 1000)),
+	}
+	assert.NoError(t, validator.Struct(input))
+	input.Description = aws.String(strings.Repeat("a", 1001))
+	assert.Error(t, validator.Struct(input))
+}
+
+func TestOnlyUncheckedSettings(t *testing.T) {
+	assert.True(t, onlyUncheckedSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("logs"),
+	}))
+	assert.True(t, onlyUncheckedSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		Description:        aws.String("logs"),
+		DataClassification: aws.String(models.DataClassificationRestricted),
+	}))
+	assert.True(t, onlyUncheckedSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:     aws.String(testIntegrationID),
+		SeverityOverrides: map[string]*string{"AWS.CloudTrail.*": aws.String(models.SeverityLow)},
+	}))
+	assert.False(t, onlyUncheckedSettings(&models.UpdateIntegrationSettingsInput{IntegrationID: aws.String(testIntegrationID)}))
+	assert.False(t, onlyUncheckedSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		Description:   aws.String("logs"),
+		S3Buckets:     aws.StringSlice([]string{"bucket"}),
+	}))
+}
+
+func TestUpdateIntegrationSettingsExpectedScanStatus(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "the conditional request failed", nil)).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:      aws.String(testIntegrationID),
+		ScanIntervalMins:   aws.Int(60),
+		ExpectedScanStatus: aws.String(models.StatusOK),
+	})
+	assert.Nil(t, result)
+	require.IsType(t, &genericapi.PreconditionFailedError{}, err)
+
+	// The status is checked by the write itself
+	require.NotNil(t, update.ConditionExpression)
+	var values []string
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, aws.StringValue(value.S))
+	}
+	assert.Contains(t, values, models.StatusOK)
+	var names []string
+	for _, name := range update.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	assert.Contains(t, names, "scanStatus")
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsConsistentRead(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	expectedGet := &dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String("test"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"integrationId": {S: aws.String(testIntegrationID)},
+		},
+	}
+	getResponse := &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"awsAccountId":  {S: aws.String(testAccountID)},
+		"integrationId": {S: aws.String(testIntegrationID)},
+	}}
+	mockClient.On("GetItem", expectedGet).Return(getResponse, nil)
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		ScanEnabled:   aws.Bool(true),
+	})
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsDuplicateLabel(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{
+		MockScanAttributes: []map[string]*dynamodb.AttributeValue{
+			{
+				"integrationId":    {S: aws.String("ebb4d69f-177b-4eff-a7a6-9251fdc72d21")},
+				"awsAccountId":     {S: aws.String(testAccountID)},
+				"integrationLabel": {S: aws.String("Prod AWS")},
+			},
+		},
+	}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+
+	getResponse := &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		"awsAccountId":  {S: aws.String(testAccountID)},
+		"integrationId": {S: aws.String(testIntegrationID)},
+	}}
+	mockClient.On("GetItem", mock.Anything).Return(getResponse, nil)
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		IntegrationLabel: aws.String("  prod aws "),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.ConflictError{}, err)
+
+	// The collision is ignored when duplicates are explicitly allowed
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	result, err = apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:       aws.String(testIntegrationID),
+		IntegrationLabel:    aws.String("  prod aws "),
+		AllowDuplicateLabel: aws.Bool(true),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
 func TestUpdateIntegrationValidTime(t *testing.T) {
 	now := time.Now()
 	validator, err := models.Validator()
@@ -103,6 +521,7 @@ func TestUpdateIntegrationLastScanStart(t *testing.T) {
 	mockClient := &modelstest.MockDDBClient{}
 	db = &ddb.DDB{Client: mockClient, TableName: "test"}
 
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
 	resp := &dynamodb.UpdateItemOutput{}
 	mockClient.On("UpdateItem", mock.Anything).Return(resp, nil)
 
@@ -120,10 +539,59 @@ func TestUpdateIntegrationLastScanStart(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestUpdateIntegrationLastScanEnd(t *testing.T) {
+func TestUpdateIntegrationsLastScanStart(t *testing.T) {
 	mockClient := &modelstest.MockDDBClient{}
 	db = &ddb.DDB{Client: mockClient, TableName: "test"}
 
+	blackoutID := "9a9ba8e0-4a0b-4d39-a0c0-4cf4df4c1b5e"
+	blackout, err := dynamodbattribute.MarshalMap(&models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(blackoutID),
+		IntegrationType: aws.String(models.IntegrationTypeAWSScan),
+		BlackoutWindows: []*models.BlackoutWindow{
+			{Start: aws.Time(time.Now().Add(-time.Hour)), End: aws.Time(time.Now().Add(time.Hour))},
+		},
+	})
+	require.NoError(t, err)
+	mockClient.On("BatchGetItemPages", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{
+			"test": {getItem(models.IntegrationTypeAWSScan).Item, blackout},
+		},
+	}, nil).Once()
+	scanStartTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	// Only the integration outside of a blackout window is written
+	mockClient.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+		return len(input.TransactItems) == 1
+	})).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	start := func(integrationID string) *models.UpdateIntegrationLastScanStartInput {
+		return &models.UpdateIntegrationLastScanStartInput{
+			IntegrationID:     aws.String(integrationID),
+			LastScanStartTime: &scanStartTime,
+			ScanStatus:        aws.String(models.StatusScanning),
+		}
+	}
+	results, err := apiTest.UpdateIntegrationsLastScanStart(&models.UpdateIntegrationsLastScanStartInput{
+		Updates: []*models.UpdateIntegrationLastScanStartInput{
+			start(testIntegrationID), start(blackoutID), start("6c0c4c4e-7f42-4b3e-9bd1-1f4b0d6c1f33"),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, *results[0].Success)
+	assert.Equal(t, models.StatusScanning, *results[0].Integration.ScanStatus)
+	assert.Equal(t, scanStartTime, *results[0].Integration.LastScanStartTime)
+	assert.False(t, *results[1].Success)
+	assert.Contains(t, *results[1].ErrorMessage, "blackout window")
+	assert.False(t, *results[2].Success)
+	assert.Contains(t, *results[2].ErrorMessage, "does not exist")
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationLastScanEnd(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", Clock: func() time.Time { return now }}
+
 	resp := &dynamodb.UpdateItemOutput{}
 
 	update := expression.Set(
@@ -134,10 +602,21 @@ func TestUpdateIntegrationLastScanEnd(t *testing.T) {
 		expression.Name("lastScanErrorMessage"),
 		expression.Value("something went wrong"),
 	)
+	update = update.Set(
+		expression.Name("lastScanTruncated"),
+		expression.Value(false),
+	)
 	update = update.Set(
 		expression.Name("scanStatus"),
 		expression.Value(models.StatusError),
 	)
+	// The scan doesn't report truncation, it resets the counter
+	update = update.Set(expression.Name("consecutiveTruncatedScans"), expression.Value(0))
+	completed := expression.Name("completedScans")
+	update = update.Set(completed, expression.Plus(expression.IfNotExists(completed, expression.Value(0)), expression.Value(1)))
+	update = update.Add(expression.Name("version"), expression.Value(1)).
+		Set(expression.Name("lastModifiedAt"), expression.Value(ddb.ModifiedTime(now))).
+		Set(expression.Name("modifiedFeed"), expression.Value("integrations"))
 	expr, err := expression.NewBuilder().WithUpdate(update).Build()
 	require.NoError(t, err)
 
@@ -167,3 +646,440 @@ func TestUpdateIntegrationLastScanEnd(t *testing.T) {
 	assert.NotNil(t, result)
 	mockClient.AssertExpectations(t)
 }
+
+func TestUpdateIntegrationLastScanEndTruncated(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", Clock: func() time.Time { return now }}
+
+	counter := expression.Name("consecutiveTruncatedScans")
+	update := expression.Set(expression.Name("lastScanEndTime"), expression.Value("2009-11-10T23:00:00Z"))
+	update = update.Set(expression.Name("lastScanTruncated"), expression.Value(true))
+	update = update.Set(expression.Name("lastScanObjectsProcessed"), expression.Value(1000))
+	update = update.Set(expression.Name("scanStatus"), expression.Value(models.StatusOK))
+	update = update.Set(counter, expression.Plus(expression.IfNotExists(counter, expression.Value(0)), expression.Value(1)))
+	completed := expression.Name("completedScans")
+	update = update.Set(completed, expression.Plus(expression.IfNotExists(completed, expression.Value(0)), expression.Value(1)))
+	update = update.Add(expression.Name("version"), expression.Value(1)).
+		Set(expression.Name("lastModifiedAt"), expression.Value(ddb.ModifiedTime(now))).
+		Set(expression.Name("modifiedFeed"), expression.Value("integrations"))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	require.NoError(t, err)
+
+	// The stored counter reached the threshold with this scan
+	mockClient.On("UpdateItem", &dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       map[string]*dynamodb.AttributeValue{"integrationId": {S: aws.String(testIntegrationID)}},
+		ReturnValues:              aws.String("ALL_NEW"),
+		TableName:                 aws.String("test"),
+		UpdateExpression:          expr.Update(),
+	}).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"integrationId":             {S: aws.String(testIntegrationID)},
+		"lastScanTruncated":         {BOOL: aws.Bool(true)},
+		"lastScanObjectsProcessed":  {N: aws.String("1000")},
+		"consecutiveTruncatedScans": {N: aws.String("3")},
+	}}, nil)
+
+	lastScanEndTime, err := time.Parse(time.RFC3339, "2009-11-10T23:00:00Z")
+	require.NoError(t, err)
+	result, err := apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		LastScanEndTime:  &lastScanEndTime,
+		ScanStatus:       aws.String(models.StatusOK),
+		ScanTruncated:    aws.Bool(true),
+		ObjectsProcessed: aws.Int64(1000),
+	})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	assert.True(t, *result.LastScanTruncated)
+	assert.Equal(t, int64(1000), *result.LastScanObjectsProcessed)
+	assert.True(t, aws.BoolValue(result.BacklogSuspected))
+}
+
+func TestUpdateIntegrationLastScanEndNotTruncated(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"integrationId":             {S: aws.String(testIntegrationID)},
+		"consecutiveTruncatedScans": {N: aws.String("0")},
+	}}, nil).Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	result, err := apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:   aws.String(testIntegrationID),
+		LastScanEndTime: aws.Time(time.Now()),
+		ScanStatus:      aws.String(models.StatusOK),
+		ScanTruncated:   aws.Bool(false),
+	})
+	require.NoError(t, err)
+	// A complete scan resets the counter, and the backlog is no longer suspected
+	// Only the completed scans are incremented
+	assert.Equal(t, 1, strings.Count(*update.UpdateExpression, "if_not_exists"))
+	assert.Nil(t, result.BacklogSuspected)
+}
+
+func TestUpdateIntegrationLastScanEndBookmark(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+
+	resp := &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"lastScanBookmark": {S: aws.String("logs/2020/03/02/file.gz")},
+	}}
+	mockClient.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return input.ConditionExpression != nil
+	})).Return(resp, nil)
+
+	result, err := apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		LastScanEndTime:  aws.Time(time.Now()),
+		ScanStatus:       aws.String(models.StatusOK),
+		LastScanBookmark: aws.String("logs/2020/03/02/file.gz"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "logs/2020/03/02/file.gz", *result.LastScanBookmark)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationLastScanEndBookmarkBackwards(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+
+	conditionErr := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", errors.New("fake"))
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, conditionErr)
+
+	result, err := apiTest.UpdateIntegrationLastScanEnd(&models.UpdateIntegrationLastScanEndInput{
+		IntegrationID:    aws.String(testIntegrationID),
+		LastScanEndTime:  aws.Time(time.Now()),
+		ScanStatus:       aws.String(models.StatusOK),
+		LastScanBookmark: aws.String("logs/2019/01/01/file.gz"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestResetIntegrationBookmark(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	db = &ddb.DDB{Client: mockClient, TableName: "test", Clock: func() time.Time { return now }}
+
+	expr, err := expression.NewBuilder().
+		WithUpdate(expression.Remove(expression.Name("lastScanBookmark")).Add(expression.Name("version"), expression.Value(1)).
+			Set(expression.Name("lastModifiedAt"), expression.Value(ddb.ModifiedTime(now))).
+			Set(expression.Name("modifiedFeed"), expression.Value("integrations"))).
+		Build()
+	require.NoError(t, err)
+	expected := &dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			"integrationId": {S: aws.String(testIntegrationID)},
+		},
+		ReturnValues:     aws.String("ALL_NEW"),
+		TableName:        aws.String("test"),
+		UpdateExpression: expr.Update(),
+	}
+	mockClient.On("UpdateItem", expected).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	result, err := apiTest.ResetIntegrationBookmark(&models.ResetIntegrationBookmarkInput{
+		IntegrationID: aws.String(testIntegrationID),
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateIntegrationSettingsTimeExtraction(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).
+		Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TimeExtraction: &models.TimeExtraction{
+			KeyPattern: aws.String(`^cdn/(?P<date>\d{4}/\d{2}/\d{2}/\d{2})/`),
+			TimeFormat: aws.String("2006/01/02/15"),
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{
+		"keyPattern": {S: aws.String(`^cdn/(?P<date>\d{4}/\d{2}/\d{2}/\d{2})/`)},
+		"timeFormat": {S: aws.String("2006/01/02/15")},
+	}})
+}
+
+func TestUpdateIntegrationSettingsInvalidTimeExtraction(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) {
+		t.Fatal("an invalid time extraction is rejected before the health check")
+		return false, nil, nil
+	}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+
+	for _, test := range []struct {
+		extraction *models.TimeExtraction
+		message    string
+	}{
+		{
+			&models.TimeExtraction{TimeFormat: aws.String(models.TimeFormatRFC3339)},
+			"timeExtraction: either fieldPath or keyPattern is required",
+		},
+		{
+			&models.TimeExtraction{
+				FieldPath: aws.String("ts"), KeyPattern: aws.String("(?P<date>.+)"), TimeFormat: aws.String(models.TimeFormatUnix)},
+			"timeExtraction: fieldPath and keyPattern are exclusive",
+		},
+		{
+			&models.TimeExtraction{FieldPath: aws.String("events.*.ts"), TimeFormat: aws.String(models.TimeFormatUnix)},
+			`timeExtraction.fieldPath: "events.*.ts" is not a dot separated path of keys`,
+		},
+		{
+			&models.TimeExtraction{KeyPattern: aws.String(`^logs/(?P<date>\d{4}`), TimeFormat: aws.String("2006")},
+			"timeExtraction.keyPattern: error parsing regexp: missing closing ): `^logs/(?P<date>\\d{4}`",
+		},
+		{
+			&models.TimeExtraction{KeyPattern: aws.String(`^logs/(\d{4}-\d{2}-\d{2})/`), TimeFormat: aws.String("2006-01-02")},
+			`timeExtraction.keyPattern: no capture group named date, e.g. (?P<date>\d{4}-\d{2}-\d{2})`,
+		},
+		{
+			&models.TimeExtraction{KeyPattern: aws.String(`(?P<date>(\d+-)+)`), TimeFormat: aws.String("2006-01-02")},
+			"timeExtraction.keyPattern: nested repetitions are not supported",
+		},
+		{
+			&models.TimeExtraction{FieldPath: aws.String("meta.ts")},
+			"timeExtraction.timeFormat: required",
+		},
+		{
+			&models.TimeExtraction{FieldPath: aws.String("meta.ts"), TimeFormat: aws.String("YYYY-MM-DD")},
+			`timeExtraction.timeFormat: "YYYY-MM-DD" is not a time layout with a year, a month and a day, e.g. 2006-01-02`,
+		},
+		{
+			&models.TimeExtraction{FieldPath: aws.String("meta.ts"), TimeFormat: aws.String("15:04:05")},
+			`timeExtraction.timeFormat: "15:04:05" is not a time layout with a year, a month and a day, e.g. 2006-01-02`,
+		},
+	} {
+		_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+			IntegrationID:  aws.String(testIntegrationID),
+			TimeExtraction: test.extraction,
+		})
+		assert.Equal(t, &genericapi.InvalidInputError{Message: test.message}, err)
+	}
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestUpdateIntegrationSettingsTimeExtractionWithoutLogs(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TimeExtraction: &models.TimeExtraction{
+			FieldPath: aws.String("eventTime"), TimeFormat: aws.String(models.TimeFormatRFC3339)},
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "only log integrations have a time extraction"}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestTimestampSkewValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(future, past int, policy string) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{
+			IntegrationID: aws.String(testIntegrationID),
+			TimestampSkew: &models.TimestampSkew{
+				MaxFutureSkewMins: aws.Int(future), MaxPastSkewMins: aws.Int(past), Policy: aws.String(policy)},
+		}
+	}
+
+	for _, policy := range []string{models.SkewPolicyClamp, models.SkewPolicyDrop, models.SkewPolicyAccept} {
+		assert.NoError(t, validator.Struct(settings(5, 1440, policy)))
+	}
+	assert.NoError(t, validator.Struct(settings(0, 0, models.SkewPolicyDrop)))
+	assert.Error(t, validator.Struct(settings(-1, 1440, models.SkewPolicyClamp)))
+	assert.Error(t, validator.Struct(settings(5, -1, models.SkewPolicyClamp)))
+	assert.Error(t, validator.Struct(settings(5, 1440, "reject")))
+	assert.Error(t, validator.Struct(settings(5, 1440, "")))
+}
+
+func TestUpdateIntegrationSettingsTimestampSkew(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	stored := map[string]*dynamodb.AttributeValue{
+		"maxFutureSkewMins": {N: aws.String("5")},
+		"policy":            {S: aws.String(models.SkewPolicyDrop)},
+	}
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"timestampSkew": {M: stored},
+	}}, nil).Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	skew := &models.TimestampSkew{MaxFutureSkewMins: aws.Int(5), Policy: aws.String(models.SkewPolicyDrop)}
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TimestampSkew: skew,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{M: stored})
+	assert.Equal(t, skew, result.TimestampSkew)
+}
+
+func TestUpdateIntegrationSettingsInvalidTimestampSkew(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TimestampSkew: &models.TimestampSkew{Policy: aws.String(models.SkewPolicyClamp)},
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "timestampSkew: either maxFutureSkewMins or maxPastSkewMins is required"}, err)
+
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+
+	mockClient = &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWSScan), nil)
+	_, err = apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		TimestampSkew: &models.TimestampSkew{MaxFutureSkewMins: aws.Int(5)},
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "only log integrations have a timestamp skew"}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestApplyTimestampSkew(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	skewed := func(policy *string) *models.SourceIntegrationMetadata {
+		return &models.SourceIntegrationMetadata{TimestampSkew: &models.TimestampSkew{
+			MaxFutureSkewMins: aws.Int(5), MaxPastSkewMins: aws.Int(60), Policy: policy}}
+	}
+	future, past, within := now.Add(time.Hour), now.Add(-2*time.Hour), now.Add(time.Minute)
+
+	timestamp, keep := (&models.SourceIntegrationMetadata{}).ApplyTimestampSkew(future, now)
+	assert.True(t, keep)
+	assert.Equal(t, future, timestamp)
+	timestamp, keep = skewed(aws.String(models.SkewPolicyDrop)).ApplyTimestampSkew(within, now)
+	assert.True(t, keep)
+	assert.Equal(t, within, timestamp)
+
+	// Events beyond a bound are clamped to it by default
+	timestamp, keep = skewed(nil).ApplyTimestampSkew(future, now)
+	assert.True(t, keep)
+	assert.Equal(t, now.Add(5*time.Minute), timestamp)
+	timestamp, _ = skewed(nil).ApplyTimestampSkew(past, now)
+	assert.Equal(t, now.Add(-time.Hour), timestamp)
+	_, keep = skewed(aws.String(models.SkewPolicyDrop)).ApplyTimestampSkew(past, now)
+	assert.False(t, keep)
+	timestamp, keep = skewed(aws.String(models.SkewPolicyAccept)).ApplyTimestampSkew(future, now)
+	assert.True(t, keep)
+	assert.Equal(t, future, timestamp)
+}
+
+func TestOrderingValidation(t *testing.T) {
+	validator, err := models.Validator()
+	require.NoError(t, err)
+	settings := func(mode string, parallelism int) *models.UpdateIntegrationSettingsInput {
+		return &models.UpdateIntegrationSettingsInput{
+			IntegrationID:  aws.String(testIntegrationID),
+			OrderingMode:   aws.String(mode),
+			MaxParallelism: aws.Int(parallelism),
+		}
+	}
+
+	for _, mode := range []string{models.OrderingModeNone, models.OrderingModePerPrefix, models.OrderingModeStrict} {
+		assert.NoError(t, validator.Struct(settings(mode, 1)))
+	}
+	assert.NoError(t, validator.Struct(settings(models.OrderingModePerPrefix, 100)))
+	assert.Error(t, validator.Struct(settings("fifo", 1)))
+	assert.Error(t, validator.Struct(settings("", 1)))
+	assert.Error(t, validator.Struct(settings(models.OrderingModeNone, 0)))
+	assert.Error(t, validator.Struct(settings(models.OrderingModeNone, 101)))
+}
+
+func TestUpdateIntegrationSettingsOrdering(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	evaluateIntegrationFunc = func(_ API, _ *models.CheckIntegrationInput) (bool, *time.Time, error) { return true, nil, nil }
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+	var update *dynamodb.UpdateItemInput
+	mockClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"orderingMode":   {S: aws.String(models.OrderingModePerPrefix)},
+		"maxParallelism": {N: aws.String("4")},
+	}}, nil).Run(func(args mock.Arguments) { update = args.Get(0).(*dynamodb.UpdateItemInput) })
+
+	result, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:  aws.String(testIntegrationID),
+		OrderingMode:   aws.String(models.OrderingModePerPrefix),
+		MaxParallelism: aws.Int(4),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, update)
+	var values []*dynamodb.AttributeValue
+	for _, value := range update.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.Contains(t, values, &dynamodb.AttributeValue{S: aws.String(models.OrderingModePerPrefix)})
+	assert.Contains(t, values, &dynamodb.AttributeValue{N: aws.String("4")})
+	assert.Equal(t, models.OrderingModePerPrefix, *result.OrderingMode)
+	assert.Equal(t, 4, *result.MaxParallelism)
+}
+
+func TestUpdateIntegrationSettingsStrictOrderingWithParallelism(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db = &ddb.DDB{Client: mockClient, TableName: "test"}
+	mockClient.On("GetItem", mock.Anything).Return(getItem(models.IntegrationTypeAWS3), nil)
+
+	_, err := apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID:  aws.String(testIntegrationID),
+		OrderingMode:   aws.String(models.OrderingModeStrict),
+		MaxParallelism: aws.Int(2),
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "maxParallelism: strict ordering ingests a single object at a time"}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+
+	// The parallelism the integration already has conflicts with strict ordering as well
+	mockClient = mockStoredIntegration(t, &models.SourceIntegrationMetadata{
+		IntegrationID:   aws.String(testIntegrationID),
+		IntegrationType: aws.String(models.IntegrationTypeAWS3),
+		MaxParallelism:  aws.Int(4),
+	})
+	_, err = apiTest.UpdateIntegrationSettings(&models.UpdateIntegrationSettingsInput{
+		IntegrationID: aws.String(testIntegrationID),
+		OrderingMode:  aws.String(models.OrderingModeStrict),
+	})
+	assert.Equal(t, &genericapi.InvalidInputError{
+		Message: "maxParallelism: strict ordering ingests a single object at a time"}, err)
+	mockClient.AssertNotCalled(t, "UpdateItem", mock.Anything)
+}
+
+func TestOrderingGroup(t *testing.T) {
+	key := "logs/2020/06/01/events.json.gz"
+	assert.Equal(t, key, (&models.SourceIntegrationMetadata{}).OrderingGroup(key))
+	assert.Equal(t, "logs/2020/06/01/",
+		(&models.SourceIntegrationMetadata{OrderingMode: aws.String(models.OrderingModePerPrefix)}).OrderingGroup(key))
+	assert.Equal(t, "",
+		(&models.SourceIntegrationMetadata{OrderingMode: aws.String(models.OrderingModePerPrefix)}).OrderingGroup("events.json"))
+	assert.Equal(t, "",
+		(&models.SourceIntegrationMetadata{OrderingMode: aws.String(models.OrderingModeStrict)}).OrderingGroup(key))
+}
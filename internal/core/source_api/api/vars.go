@@ -20,25 +20,98 @@ package api
 
 import (
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/internal/core/source_api/ddb"
 )
 
 var (
-	db                                      = ddb.New(tableName)
-	sess                                    = session.Must(session.NewSession())
-	SQSClient               sqsiface.SQSAPI = sqs.New(sess)
-	maxElapsedTime                          = 5 * time.Second
-	snapshotPollersQueueURL                 = os.Getenv("SNAPSHOT_POLLERS_QUEUE_URL")
-	logProcessorQueueURL                    = os.Getenv("LOG_PROCESSOR_QUEUE_URL")
-	logProcessorQueueArn                    = os.Getenv("LOG_PROCESSOR_QUEUE_ARN")
-	tableName                               = os.Getenv("TABLE_NAME")
+	db                                            = ddb.New(tableName, changesTableName, retriesTableName, replicaRegion, ddbOptions()...)
+	sess                                          = session.Must(session.NewSession())
+	SQSClient               sqsiface.SQSAPI       = sqs.New(sess)
+	lambdaClient            lambdaiface.LambdaAPI = lambda.New(sess)
+	processedDataClient     s3iface.S3API         = s3.New(sess)
+	exportClient            s3iface.S3API         = s3.New(sess)
+	maxElapsedTime                                = 5 * time.Second
+	snapshotPollersQueueURL                       = os.Getenv("SNAPSHOT_POLLERS_QUEUE_URL")
+	logProcessorQueueURL                          = os.Getenv("LOG_PROCESSOR_QUEUE_URL")
+	logProcessorQueueArn                          = os.Getenv("LOG_PROCESSOR_QUEUE_ARN")
+	logProcessorFunction                          = os.Getenv("LOG_PROCESSOR_FUNCTION")
+	httpIngestEndpoint                            = os.Getenv("HTTP_INGEST_ENDPOINT")
+	httpIngestUsagePlanID                         = os.Getenv("HTTP_INGEST_USAGE_PLAN_ID")
+	tableName                                     = os.Getenv("TABLE_NAME")
+	changesTableName                              = os.Getenv("CHANGES_TABLE_NAME")
+	retriesTableName                              = os.Getenv("RETRIES_TABLE_NAME")
+	historyTableName                              = os.Getenv("HISTORY_TABLE_NAME")
+	historyRetentionDays                          = os.Getenv("HISTORY_RETENTION_DAYS")
+	integrationsCacheTTL                          = os.Getenv("INTEGRATIONS_CACHE_TTL_SECONDS")
+	replicaRegion                                 = os.Getenv("REPLICA_REGION")
+	dynamoDBEndpoint                              = os.Getenv("DYNAMODB_ENDPOINT")
+	outputsAPI                                    = os.Getenv("OUTPUTS_API")
+	alertQueueURL                                 = os.Getenv("ALERT_QUEUE_URL")
+	awsEventsQueueArn                             = os.Getenv("AWS_EVENTS_QUEUE_ARN")
+	processedDataBucket                           = os.Getenv("PROCESSED_DATA_BUCKET")
+	exportBucket                                  = os.Getenv("EXPORT_BUCKET")
+	auditExportBucket                             = os.Getenv("AUDIT_EXPORT_BUCKET")
+	auditExportQueueURL                           = os.Getenv("AUDIT_EXPORT_QUEUE_URL")
+	healthReportBucket                            = os.Getenv("HEALTH_REPORT_BUCKET")
+	enrichmentSources                             = os.Getenv("ENRICHMENT_SOURCES")
+	scanCallbackSecret                            = os.Getenv("SCAN_CALLBACK_SECRET")
+	remediationQuota                              = os.Getenv("REMEDIATION_QUOTA")
+	processingRegions                             = os.Getenv("PROCESSING_REGIONS")
+	homeRegion                                    = os.Getenv("AWS_REGION")
+	strictSideEffects                             = os.Getenv("STRICT_SIDE_EFFECTS") == "true"
 )
 
+// Manages the API keys of the http-ingest integrations
+var apiGatewayClient apigatewayiface.APIGatewayAPI = apigateway.New(sess)
+
+// Reads the ingestion metrics the log processor publishes per source, for the ingestion alarms
+var cloudWatchClient cloudwatchiface.CloudWatchAPI = cloudwatch.New(sess)
+
 // API provides receiver methods for each route handler.
-type API struct{}
+type API struct {
+	// Tags the log messages of an operation on one integration, the global logger is used when it is unset
+	logger *zap.Logger
+	// The alert outputs shared by the integrations of an operation, see withAlertOutputs
+	outputs *alertOutputs
+}
+
+// Retention of the health and scan history when HISTORY_RETENTION_DAYS isn't a number of days
+const defaultHistoryRetentionDays = 90
+
+// ddbOptions are the options of the client of the tables, DYNAMODB_ENDPOINT points it at e.g. DynamoDB local.
+//
+// The history of the integrations is only kept when the deployment has a HISTORY_TABLE_NAME. The integrations
+// read are cached for INTEGRATIONS_CACHE_TTL_SECONDS, they are not cached if it isn't a positive number.
+func ddbOptions() []ddb.Option {
+	var options []ddb.Option
+	if dynamoDBEndpoint != "" {
+		options = append(options, ddb.WithEndpoint(dynamoDBEndpoint))
+	}
+	if historyTableName != "" {
+		days, err := strconv.Atoi(historyRetentionDays)
+		if err != nil || days < 1 {
+			days = defaultHistoryRetentionDays
+		}
+		options = append(options, ddb.WithHistory(historyTableName, time.Duration(days)*24*time.Hour))
+	}
+	if seconds, err := strconv.Atoi(integrationsCacheTTL); err == nil && seconds > 0 {
+		options = append(options, ddb.WithCache(time.Duration(seconds)*time.Second))
+	}
+	return options
+}
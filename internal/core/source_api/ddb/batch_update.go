@@ -0,0 +1,227 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// ScanStatusUpdate sets the scan status of an integration, and the times of its last scan which are not nil.
+type ScanStatusUpdate struct {
+	IntegrationID     *string
+	ScanStatus        *string
+	LastScanStartTime *time.Time
+	LastScanEndTime   *time.Time
+}
+
+// maxScanStatusAttempts is how many times an update of the scan status is tried when the integration is written
+// concurrently.
+const maxScanStatusAttempts = 3
+
+// BatchUpdateScanStatus applies the scan status updates of many integrations with a single batch read and a
+// transaction for every MaxTransactItems of them, rather than a read and a write for each. The updated integrations
+// are returned, or the error of each update.
+//
+// The integrations are read consistently, the check, if not nil, can reject the update of each of them. Only the
+// attributes of the update are then written, under the condition that the version read is still the stored one.
+// BatchWriteItem can't take that condition, hence the transactions. A transaction in which an integration was written
+// in between writes nothing: that integration is read again and checked again, and the transaction is tried again
+// with the others as they were read. A concurrent write is never lost.
+//
+// The transactions don't return the items, the updated integrations are the ones read with the update applied.
+func (ddb *DDB) BatchUpdateScanStatus(updates []*ScanStatusUpdate, check func(*models.SourceIntegration) error) (
+	[]*models.SourceIntegration, []error) {
+
+	results := make([]*models.SourceIntegration, len(updates))
+	errs := make([]error, len(updates))
+	if len(updates) == 0 {
+		return results, errs
+	}
+
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(updates))
+	pending := make([]int, 0, len(updates))
+	seen := make(map[string]struct{}, len(updates))
+	for i, update := range updates {
+		if _, ok := seen[*update.IntegrationID]; ok {
+			errs[i] = &genericapi.InvalidInputError{
+				Message: fmt.Sprintf("integration %s is updated more than once", *update.IntegrationID)}
+			continue
+		}
+		seen[*update.IntegrationID] = struct{}{}
+		keys = append(keys, map[string]*dynamodb.AttributeValue{hashKey: {S: update.IntegrationID}})
+		pending = append(pending, i)
+	}
+
+	output, err := dynamodbbatch.BatchGetItem(ddb.Client, &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]*dynamodb.KeysAndAttributes{
+			ddb.TableName: {ConsistentRead: aws.Bool(true), Keys: keys},
+		},
+	})
+	if err != nil {
+		return results, failAll(errs, &genericapi.AWSError{Err: err, Method: "Dynamodb.BatchGetItem"})
+	}
+	items := make(map[string]map[string]*dynamodb.AttributeValue, len(keys))
+	for _, item := range output.Responses[ddb.TableName] {
+		items[aws.StringValue(item[hashKey].S)] = item
+	}
+
+	for attempt := 1; len(pending) > 0; attempt++ {
+		writes := make([]int, 0, len(pending))
+		for _, i := range pending {
+			if results[i], errs[i] = checkScanStatus(items[*updates[i].IntegrationID], check); errs[i] == nil {
+				writes = append(writes, i)
+			}
+		}
+
+		pending = nil
+		for start := 0; start < len(writes); start += MaxTransactItems {
+			end := start + MaxTransactItems
+			if end > len(writes) {
+				end = len(writes)
+			}
+			retries, err := ddb.writeScanStatus(updates, results, writes[start:end], items, attempt == maxScanStatusAttempts)
+			for _, i := range writes[start:end] {
+				if err != nil {
+					results[i], errs[i] = nil, err
+				}
+			}
+			pending = append(pending, retries...)
+		}
+	}
+	return results, errs
+}
+
+// checkScanStatus reads the integration to update from its item, nil if it doesn't exist, and checks it.
+func checkScanStatus(item map[string]*dynamodb.AttributeValue, check func(*models.SourceIntegration) error) (
+	*models.SourceIntegration, error) {
+
+	if item == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	if _, deleted := item[deletedKey]; deleted {
+		return nil, &genericapi.DoesNotExistError{Message: "Integration does not exist"}
+	}
+	integration, err := unmarshalIntegration(item)
+	if err != nil {
+		return nil, err
+	}
+	if check != nil {
+		if err = check(integration); err != nil {
+			return nil, err
+		}
+	}
+	return integration, nil
+}
+
+// writeScanStatus writes the updates at the given indexes in a single transaction, on the versions of the
+// integrations which were read, and applies them to the results.
+//
+// If an integration was written concurrently, its item is read again and the indexes to try again are returned,
+// unless it is the last attempt. The error is set otherwise.
+func (ddb *DDB) writeScanStatus(updates []*ScanStatusUpdate, results []*models.SourceIntegration, indexes []int,
+	items map[string]map[string]*dynamodb.AttributeValue, lastAttempt bool) ([]int, error) {
+
+	// The integration must not have been deleted either, the update would otherwise write a new item
+	condition := expression.Name(hashKey).AttributeExists().And(expression.Name(deletedKey).AttributeNotExists())
+	transaction := make([]*TransactUpdate, len(indexes))
+	for j, i := range indexes {
+		transaction[j] = &TransactUpdate{
+			Item: &UpdateIntegrationItem{
+				IntegrationID:     updates[i].IntegrationID,
+				ScanStatus:        updates[i].ScanStatus,
+				LastScanStartTime: updates[i].LastScanStartTime,
+				LastScanEndTime:   updates[i].LastScanEndTime,
+				ExpectedVersion:   aws.Int64(aws.Int64Value(results[i].Version)),
+			},
+			Condition: &condition,
+		}
+	}
+
+	now := ddb.now()
+	err := ddb.transactUpdateItems(transaction, now)
+	if failed, conflict := err.(*TransactionConditionFailedError); conflict && !lastAttempt {
+		for _, integrationID := range failed.IntegrationIDs {
+			item, err := ddb.getItem(integrationID, true)
+			if err != nil {
+				return nil, err
+			}
+			items[*integrationID] = item
+		}
+		return indexes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range indexes {
+		applyScanStatus(results[i], updates[i], now)
+	}
+	return nil, nil
+}
+
+// applyScanStatus sets what the update wrote on the integration it was made on.
+func applyScanStatus(integration *models.SourceIntegration, update *ScanStatusUpdate, now time.Time) {
+	if integration.SourceIntegrationStatus == nil {
+		integration.SourceIntegrationStatus = &models.SourceIntegrationStatus{}
+	}
+	if integration.SourceIntegrationScanInformation == nil {
+		integration.SourceIntegrationScanInformation = &models.SourceIntegrationScanInformation{}
+	}
+	if update.ScanStatus != nil {
+		integration.ScanStatus = update.ScanStatus
+	}
+	if update.LastScanStartTime != nil {
+		integration.LastScanStartTime = update.LastScanStartTime
+	}
+	if update.LastScanEndTime != nil {
+		integration.LastScanEndTime = update.LastScanEndTime
+	}
+	integration.Version = aws.Int64(aws.Int64Value(integration.Version) + 1)
+	// As stored, to the millisecond
+	integration.LastModifiedAt = aws.Time(now.UTC().Truncate(time.Millisecond))
+}
+
+func unmarshalIntegration(item map[string]*dynamodb.AttributeValue) (*models.SourceIntegration, error) {
+	var integration models.SourceIntegration
+	if err := dynamodbattribute.UnmarshalMap(item, &integration); err != nil {
+		return nil, &genericapi.InternalError{Message: "unmarshal failed: " + err.Error()}
+	}
+	deriveFields(&integration)
+	return &integration, nil
+}
+
+// failAll sets the error of each update which didn't fail yet
+func failAll(errs []error, err error) []error {
+	for i := range errs {
+		if errs[i] == nil {
+			errs[i] = err
+		}
+	}
+	return errs
+}
@@ -0,0 +1,234 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// updatedNames are the attributes an update sets, removes or conditions on
+func updatedNames(input *dynamodb.Update) []string {
+	names := make([]string, 0, len(input.ExpressionAttributeNames))
+	for _, name := range input.ExpressionAttributeNames {
+		names = append(names, *name)
+	}
+	return names
+}
+
+func TestBatchUpdateScanStatus(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	db := New("integrations", "changes", "retries", "",
+		WithClient(mockClient), WithClock(func() time.Time { return now }), WithCache(time.Minute))
+
+	blackout := cachedItem("integration-2", "blackout")
+	item := cachedItem("integration-1", "first")
+	item[versionKey] = &dynamodb.AttributeValue{N: aws.String("4")}
+	item["integrationType"] = &dynamodb.AttributeValue{S: aws.String("aws-s3")}
+	mockClient.On("BatchGetItemPages", &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]*dynamodb.KeysAndAttributes{
+			"integrations": {
+				ConsistentRead: aws.Bool(true),
+				Keys: []map[string]*dynamodb.AttributeValue{
+					{hashKey: {S: aws.String("integration-1")}},
+					{hashKey: {S: aws.String("integration-2")}},
+					{hashKey: {S: aws.String("integration-3")}},
+				},
+			},
+		},
+	}, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{
+			"integrations": {item, blackout},
+		},
+	}, nil).Once()
+	var written *dynamodb.TransactWriteItemsInput
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).
+		Run(func(args mock.Arguments) { written = args.Get(0).(*dynamodb.TransactWriteItemsInput) }).Once()
+
+	startTime := now.Add(-time.Minute)
+	update := func(integrationID string) *ScanStatusUpdate {
+		return &ScanStatusUpdate{
+			IntegrationID:     aws.String(integrationID),
+			ScanStatus:        aws.String(models.StatusScanning),
+			LastScanStartTime: &startTime,
+		}
+	}
+	results, errs := db.BatchUpdateScanStatus(
+		[]*ScanStatusUpdate{update("integration-1"), update("integration-2"), update("integration-3"), update("integration-1")},
+		func(integration *models.SourceIntegration) error {
+			if *integration.IntegrationLabel == "blackout" {
+				return errors.New("in a blackout window")
+			}
+			return nil
+		})
+	mockClient.AssertExpectations(t)
+
+	// The update is applied to the integration which was read
+	require.NoError(t, errs[0])
+	assert.Equal(t, models.StatusScanning, *results[0].ScanStatus)
+	assert.Equal(t, startTime, *results[0].LastScanStartTime)
+	assert.Equal(t, "first", *results[0].IntegrationLabel)
+	assert.Equal(t, int64(5), *results[0].Version)
+	assert.Equal(t, now, *results[0].LastModifiedAt)
+	assert.EqualError(t, errs[1], "in a blackout window")
+	assert.IsType(t, &genericapi.DoesNotExistError{}, errs[2])
+	assert.IsType(t, &genericapi.InvalidInputError{}, errs[3])
+	assert.Nil(t, results[1])
+
+	// Only the scan status is written, on the version which was read
+	require.NotNil(t, written)
+	require.Len(t, written.TransactItems, 1)
+	assert.ElementsMatch(t, []string{"integrationId", "deleted", "version", "lastModifiedAt", "modifiedFeed",
+		"scanStatus", "lastScanStartTime"}, updatedNames(written.TransactItems[0].Update))
+	var versions []string
+	for _, value := range written.TransactItems[0].Update.ExpressionAttributeValues {
+		if value.N != nil {
+			versions = append(versions, *value.N)
+		}
+	}
+	assert.Contains(t, versions, "4")
+}
+
+func TestBatchUpdateScanStatusChunks(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db := New("integrations", "changes", "retries", "", WithClient(mockClient))
+	var items []map[string]*dynamodb.AttributeValue
+	updates := make([]*ScanStatusUpdate, MaxTransactItems+5)
+	for i := range updates {
+		integrationID := fmt.Sprintf("integration-%d", i)
+		items = append(items, cachedItem(integrationID, "label"))
+		updates[i] = &ScanStatusUpdate{IntegrationID: aws.String(integrationID), ScanStatus: aws.String(models.StatusOK)}
+	}
+	mockClient.On("BatchGetItemPages", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{"integrations": items},
+	}, nil).Once()
+	// A transaction for every MaxTransactItems updates
+	for _, size := range []int{MaxTransactItems, 5} {
+		size := size
+		mockClient.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+			return len(input.TransactItems) == size
+		})).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+	}
+
+	results, errs := db.BatchUpdateScanStatus(updates, nil)
+	for i := range updates {
+		require.NoError(t, errs[i])
+		assert.Equal(t, models.StatusOK, *results[i].ScanStatus)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestBatchUpdateScanStatusConcurrentWrite(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db := New("integrations", "changes", "retries", "", WithClient(mockClient))
+	mockClient.On("BatchGetItemPages", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{
+			"integrations": {cachedItem("integration-1", "first"), cachedItem("integration-2", "second")},
+		},
+	}, nil).Once()
+	conflict := &dynamodb.TransactionCanceledException{
+		Message_: aws.String("version changed"),
+		CancellationReasons: []*dynamodb.CancellationReason{
+			{Code: aws.String("ConditionalCheckFailed")}, {Code: aws.String("None")},
+		},
+	}
+	mockClient.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+		return len(input.TransactItems) == 2
+	})).Return(&dynamodb.TransactWriteItemsOutput{}, conflict).Once()
+	// The integration was put in a blackout window in between, which the check of the update finds
+	reread := cachedItem("integration-1", "blackout")
+	reread[versionKey] = &dynamodb.AttributeValue{N: aws.String("1")}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: reread}, nil).Once()
+	// The other integration of the transaction, which wrote nothing, is written again
+	mockClient.On("TransactWriteItems", mock.MatchedBy(func(input *dynamodb.TransactWriteItemsInput) bool {
+		return len(input.TransactItems) == 1 && *input.TransactItems[0].Update.Key[hashKey].S == "integration-2"
+	})).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+
+	results, errs := db.BatchUpdateScanStatus([]*ScanStatusUpdate{
+		{IntegrationID: aws.String("integration-1"), ScanStatus: aws.String(models.StatusScanning)},
+		{IntegrationID: aws.String("integration-2"), ScanStatus: aws.String(models.StatusScanning)},
+	}, func(integration *models.SourceIntegration) error {
+		if *integration.IntegrationLabel == "blackout" {
+			return errors.New("in a blackout window")
+		}
+		return nil
+	})
+	assert.Nil(t, results[0])
+	assert.EqualError(t, errs[0], "in a blackout window")
+	require.NoError(t, errs[1])
+	assert.Equal(t, models.StatusScanning, *results[1].ScanStatus)
+	assert.True(t, *mockClient.Calls[2].Arguments.Get(0).(*dynamodb.GetItemInput).ConsistentRead)
+	mockClient.AssertExpectations(t)
+}
+
+func TestBatchUpdateScanStatusConflicts(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db := New("integrations", "changes", "retries", "", WithClient(mockClient))
+	mockClient.On("BatchGetItemPages", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{
+			"integrations": {cachedItem("integration-1", "first")},
+		},
+	}, nil).Once()
+	conflict := &dynamodb.TransactionCanceledException{
+		Message_:            aws.String("version changed"),
+		CancellationReasons: []*dynamodb.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}},
+	}
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, conflict).
+		Times(maxScanStatusAttempts)
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: cachedItem("integration-1", "first")}, nil).
+		Times(maxScanStatusAttempts - 1)
+
+	results, errs := db.BatchUpdateScanStatus([]*ScanStatusUpdate{
+		{IntegrationID: aws.String("integration-1"), ScanStatus: aws.String(models.StatusOK)},
+	}, nil)
+	assert.Nil(t, results[0])
+	assert.IsType(t, &TransactionConditionFailedError{}, errs[0])
+	mockClient.AssertExpectations(t)
+}
+
+func TestBatchUpdateScanStatusWriteFailed(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db := New("integrations", "changes", "retries", "", WithClient(mockClient))
+	mockClient.On("BatchGetItemPages", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{
+			"integrations": {cachedItem("integration-1", "first")},
+		},
+	}, nil).Once()
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, errors.New("throttled")).Once()
+
+	results, errs := db.BatchUpdateScanStatus([]*ScanStatusUpdate{
+		{IntegrationID: aws.String("integration-1"), ScanStatus: aws.String(models.StatusOK)},
+	}, nil)
+	assert.Nil(t, results[0])
+	assert.IsType(t, &genericapi.AWSError{}, errs[0])
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,174 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// integrationCache keeps the integrations recently read from the table, and the listings of the enabled ones,
+// in the memory of the Lambda container for the TTL.
+//
+// The raw items are cached, so that every hit unmarshals its own copy of them. The writes of the DDB invalidate
+// what they change, the writes made from other containers are only seen once the cached copies expire.
+type integrationCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	lock  sync.Mutex
+	items map[string]*cachedItems
+	scans map[string]*cachedItems
+	// Incremented on every invalidation, so that a read which started before it isn't cached after it
+	generation uint64
+}
+
+type cachedItems struct {
+	items     []map[string]*dynamodb.AttributeValue
+	expiresAt time.Time
+}
+
+// WithCache makes the DDB cache the integrations it reads for the given TTL, to save the round-trips of the
+// rules engine and the pollers listing them on every invocation.
+//
+// Only the reads which are not consistent are served from the cache. Every write of the DDB invalidates the
+// integration it writes and the cached listings, the cache can also be invalidated with InvalidateCache.
+func WithCache(ttl time.Duration) Option {
+	return func(ddb *DDB) {
+		ddb.cache = &integrationCache{
+			ttl:   ttl,
+			now:   time.Now,
+			items: make(map[string]*cachedItems),
+			scans: make(map[string]*cachedItems),
+		}
+	}
+}
+
+// InvalidateCache drops the cached copies of the given integrations and every cached listing, or the whole
+// cache without integrations. It does nothing if the cache isn't enabled.
+func (ddb *DDB) InvalidateCache(integrationIDs ...*string) {
+	cache := ddb.cache
+	if cache == nil {
+		return
+	}
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.generation++
+	if len(integrationIDs) == 0 {
+		clearEntries(cache.items)
+	}
+	for _, id := range integrationIDs {
+		delete(cache.items, aws.StringValue(id))
+	}
+	// Any write can add an integration to a listing or remove it
+	clearEntries(cache.scans)
+}
+
+func clearEntries(entries map[string]*cachedItems) {
+	for key := range entries {
+		delete(entries, key)
+	}
+}
+
+// currentGeneration is taken before a read, the read is only cached if nothing was invalidated meanwhile
+func (c *integrationCache) currentGeneration() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generation
+}
+
+func (c *integrationCache) getItem(integrationID *string) (map[string]*dynamodb.AttributeValue, bool) {
+	if c == nil {
+		return nil, false
+	}
+	items, ok := c.get(c.items, aws.StringValue(integrationID))
+	if !ok {
+		return nil, false
+	}
+	return items[0], true
+}
+
+func (c *integrationCache) putItem(integrationID *string, item map[string]*dynamodb.AttributeValue, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.put(c.items, aws.StringValue(integrationID),
+		[]map[string]*dynamodb.AttributeValue{item}, generation)
+}
+
+func (c *integrationCache) getScan(filter *IntegrationFilter) ([]map[string]*dynamodb.AttributeValue, bool) {
+	if c == nil {
+		return nil, false
+	}
+	return c.get(c.scans, scanKey(filter))
+}
+
+func (c *integrationCache) putScan(filter *IntegrationFilter, items []map[string]*dynamodb.AttributeValue, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.put(c.scans, scanKey(filter), items, generation)
+}
+
+func (c *integrationCache) get(
+	entries map[string]*cachedItems, key string) ([]map[string]*dynamodb.AttributeValue, bool) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cached, ok := entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(cached.expiresAt) {
+		delete(entries, key)
+		return nil, false
+	}
+	return cached.items, true
+}
+
+func (c *integrationCache) put(
+	entries map[string]*cachedItems, key string, items []map[string]*dynamodb.AttributeValue, generation uint64) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	entries[key] = &cachedItems{items: items, expiresAt: c.now().Add(c.ttl)}
+}
+
+// scanKey identifies the listings of the same filter, the tags of a map are marshaled in the order of their keys
+func scanKey(filter *IntegrationFilter) string {
+	key, err := json.Marshal(filter)
+	if err != nil {
+		// Only the values of the filter are marshaled, which can't fail
+		panic(err)
+	}
+	return string(key)
+}
@@ -0,0 +1,153 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func cachedItem(integrationID, label string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		hashKey:            {S: aws.String(integrationID)},
+		"integrationLabel": {S: aws.String(label)},
+		"scanEnabled":      {BOOL: aws.Bool(true)},
+	}
+}
+
+func TestCacheGetIntegration(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db := New("integrations", "changes", "retries", "", WithClient(mockClient), WithCache(time.Minute))
+	now := time.Now()
+	db.cache.now = func() time.Time { return now }
+
+	getInput := func(consistent bool) *dynamodb.GetItemInput {
+		return &dynamodb.GetItemInput{
+			ConsistentRead: aws.Bool(consistent),
+			TableName:      aws.String("integrations"),
+			Key:            map[string]*dynamodb.AttributeValue{hashKey: {S: aws.String("integration-1")}},
+		}
+	}
+	mockClient.On("GetItem", getInput(false)).
+		Return(&dynamodb.GetItemOutput{Item: cachedItem("integration-1", "first")}, nil).Once()
+
+	// The second read is served from the cache
+	for i := 0; i < 2; i++ {
+		integration, err := db.GetIntegration(aws.String("integration-1"), false)
+		require.NoError(t, err)
+		assert.Equal(t, "first", *integration.IntegrationLabel)
+	}
+	mockClient.AssertExpectations(t)
+
+	// Consistent reads always go to the table
+	mockClient.On("GetItem", getInput(true)).
+		Return(&dynamodb.GetItemOutput{Item: cachedItem("integration-1", "second")}, nil).Once()
+	integration, err := db.GetSourceIntegration(aws.String("integration-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", *integration.IntegrationLabel)
+
+	// A write invalidates the integration
+	mockClient.On("UpdateItem", mock.Anything).
+		Return(&dynamodb.UpdateItemOutput{Attributes: cachedItem("integration-1", "third")}, nil).Once()
+	_, err = db.UpdateItem(&UpdateIntegrationItem{
+		IntegrationID: aws.String("integration-1"), IntegrationLabel: aws.String("third")})
+	require.NoError(t, err)
+	mockClient.On("GetItem", getInput(false)).
+		Return(&dynamodb.GetItemOutput{Item: cachedItem("integration-1", "third")}, nil).Once()
+	integration2, err := db.GetIntegration(aws.String("integration-1"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "third", *integration2.IntegrationLabel)
+
+	// Until the TTL expires
+	now = now.Add(time.Minute)
+	mockClient.On("GetItem", getInput(false)).
+		Return(&dynamodb.GetItemOutput{Item: cachedItem("integration-1", "fourth")}, nil).Once()
+	integration2, err = db.GetIntegration(aws.String("integration-1"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth", *integration2.IntegrationLabel)
+	mockClient.AssertExpectations(t)
+}
+
+func TestCacheMissingIntegration(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	db := New("integrations", "changes", "retries", "", WithClient(mockClient), WithCache(time.Minute))
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Twice()
+
+	// An integration which doesn't exist isn't cached
+	for i := 0; i < 2; i++ {
+		integration, err := db.GetIntegration(aws.String("integration-1"), false)
+		require.NoError(t, err)
+		assert.Nil(t, integration)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestCacheScanEnabledIntegrations(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{
+		MockScanAttributes: []map[string]*dynamodb.AttributeValue{cachedItem("integration-1", "first")},
+	}
+	db := New("integrations", "changes", "retries", "", WithClient(mockClient), WithCache(time.Minute))
+	list := func(input *models.ListIntegrationsInput) []string {
+		integrations, err := db.ScanEnabledIntegrations(input)
+		require.NoError(t, err)
+		labels := make([]string, len(integrations))
+		for i, integration := range integrations {
+			labels[i] = *integration.IntegrationLabel
+		}
+		return labels
+	}
+
+	assert.Equal(t, []string{"first"}, list(&models.ListIntegrationsInput{}))
+	mockClient.MockScanAttributes = []map[string]*dynamodb.AttributeValue{cachedItem("integration-2", "second")}
+	assert.Equal(t, []string{"first"}, list(&models.ListIntegrationsInput{}))
+	// Each filter is cached on its own
+	assert.Equal(t, []string{"second"}, list(&models.ListIntegrationsInput{IntegrationType: aws.String("aws-s3")}))
+
+	// Any invalidation drops the listings
+	db.InvalidateCache(aws.String("integration-3"))
+	assert.Equal(t, []string{"second"}, list(&models.ListIntegrationsInput{}))
+}
+
+func TestCacheReadDuringInvalidation(t *testing.T) {
+	db := New("integrations", "changes", "retries", "", WithCache(time.Minute))
+	generation := db.cache.currentGeneration()
+	db.InvalidateCache()
+	// The read may have returned the item as it was before the write
+	db.cache.putItem(aws.String("integration-1"), cachedItem("integration-1", "stale"), generation)
+	_, ok := db.cache.getItem(aws.String("integration-1"))
+	assert.False(t, ok)
+}
+
+func TestCacheDisabled(t *testing.T) {
+	db := New("integrations", "changes", "retries", "")
+	assert.Nil(t, db.cache)
+	// Nothing to invalidate
+	db.InvalidateCache(aws.String("integration-1"))
+	_, ok := db.cache.getItem(aws.String("integration-1"))
+	assert.False(t, ok)
+}
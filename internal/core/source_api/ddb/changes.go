@@ -0,0 +1,152 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// PutChange adds a record to the change history of an integration.
+func (ddb *DDB) PutChange(change *models.IntegrationChangeRecord) error {
+	item, err := dynamodbattribute.MarshalMap(change)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+
+	_, err = ddb.Client.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(ddb.ChangesTableName),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// DeleteChange removes a record from the change history of an integration, when the change it records is undone.
+func (ddb *DDB) DeleteChange(integrationID, changeID *string) error {
+	_, err := ddb.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey:     {S: integrationID},
+			changeIDKey: {S: changeID},
+		},
+		TableName: aws.String(ddb.ChangesTableName),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
+
+// ListChanges returns a page of the change history of an integration, most recent first.
+//
+// Only the changes from the first change ID and before the other are returned, when they are not empty.
+// The change ID of the last returned change is set if there may be more changes, the next page starts after it.
+func (ddb *DDB) ListChanges(integrationID *string, fromChangeID, beforeChangeID string, limit int,
+	exclusiveStartChangeID *string) ([]*models.IntegrationChangeRecord, *string, error) {
+
+	keyCondition := expression.Key(hashKey).Equal(expression.Value(integrationID))
+	changeID := expression.Key(changeIDKey)
+	switch {
+	case fromChangeID != "" && beforeChangeID != "":
+		// The bounds are times, a change ID only equals one if it's a bare time
+		keyCondition = keyCondition.And(changeID.Between(expression.Value(fromChangeID), expression.Value(beforeChangeID)))
+	case fromChangeID != "":
+		keyCondition = keyCondition.And(changeID.GreaterThanEqual(expression.Value(fromChangeID)))
+	case beforeChangeID != "":
+		keyCondition = keyCondition.And(changeID.LessThan(expression.Value(beforeChangeID)))
+	}
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to build ListChanges ddb expression"}
+	}
+
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     aws.Int64(int64(limit)),
+		ScanIndexForward:          aws.Bool(false),
+		TableName:                 aws.String(ddb.ChangesTableName),
+	}
+	if exclusiveStartChangeID != nil {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			hashKey:     {S: integrationID},
+			changeIDKey: {S: exclusiveStartChangeID},
+		}
+	}
+
+	output, err := ddb.Client.Query(input)
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Query"}
+	}
+
+	var changes []*models.IntegrationChangeRecord
+	if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &changes); err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	var lastChangeID *string
+	if key, ok := output.LastEvaluatedKey[changeIDKey]; ok {
+		lastChangeID = key.S
+	}
+	return changes, lastChangeID, nil
+}
+
+// ScanChanges returns the changes of every integration whose change ID is after the first one and before the
+// other, in no particular order.
+//
+// Change IDs start with the time of the change, so this selects the changes of a time range. The changes of
+// the deleted integrations are included.
+func (ddb *DDB) ScanChanges(afterChangeID, beforeChangeID string) ([]*models.IntegrationChangeRecord, error) {
+	changeID := expression.Name(changeIDKey)
+	filter := changeID.GreaterThan(expression.Value(afterChangeID)).And(changeID.LessThan(expression.Value(beforeChangeID)))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build ScanChanges ddb expression"}
+	}
+
+	input := &dynamodb.ScanInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		TableName:                 aws.String(ddb.ChangesTableName),
+	}
+	var changes []*models.IntegrationChangeRecord
+	for {
+		output, err := ddb.Client.Scan(input)
+		if err != nil {
+			return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Scan"}
+		}
+		var page []*models.IntegrationChangeRecord
+		if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &page); err != nil {
+			return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+		}
+		changes = append(changes, page...)
+		if output.LastEvaluatedKey == nil {
+			return changes, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
@@ -19,6 +19,9 @@ package ddb
  */
 
 import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
@@ -26,18 +29,134 @@ import (
 
 const (
 	hashKey = "integrationId"
+
+	// Incremented on every write to an integration
+	versionKey = "version"
+
+	scanErrorSamplesKey = "scanErrorSamples"
+
+	// Range key of the changes table, it sorts the changes of an integration chronologically
+	changeIDKey = "changeId"
+
+	// Range key of the retries table, there is at most one pending retry of each side effect of an integration
+	sideEffectKey = "sideEffect"
+
+	// Range key of the history table, it sorts the health checks and the scans of an integration chronologically
+	recordIDKey = "recordId"
+
+	// Index of the integrations by type and by when their next scan is due
+	nextScanTimeIndex = "next-scan-time-index"
+	nextScanTimeKey   = "nextScanTime"
+
+	// Index of the integrations by when they were last written, every integration is in the same partition of it
+	lastModifiedIndex = "last-modified-at-index"
+	lastModifiedKey   = "lastModifiedAt"
+	modifiedFeedKey   = "modifiedFeed"
+	modifiedFeed      = "integrations"
+
+	// A deleted integration is replaced by a marker, so that its deletion is listed among the changes until it expires
+	deletedKey   = "deleted"
+	expiresAtKey = "expiresAt"
+
+	// A soft-deleted integration is marked as deleted but keeps its settings, it can be restored until then
+	restorableUntilKey = "restorableUntil"
 )
 
 // DDB is a struct containing the DynamoDB client, and the table name to retrieve data.
 type DDB struct {
 	Client    dynamodbiface.DynamoDBAPI
 	TableName string
+
+	// Table of the change history of the integrations
+	ChangesTableName string
+
+	// Table of the side effects which failed and are retried in the background
+	RetriesTableName string
+
+	// Optional table of the health and scan history of the integrations, the records expire after the retention
+	HistoryTableName string
+	HistoryRetention time.Duration
+
+	// Optional client of a replica of the table in a secondary region (e.g. a global table).
+	// It is never written to and only read from when the primary table is unavailable.
+	Replica dynamodbiface.DynamoDBAPI
+
+	// Optional source of the time writes are stamped with, the current time by default
+	Clock func() time.Time
+
+	// Optional cache of the integrations read, see WithCache
+	cache *integrationCache
+}
+
+// Option customizes the DDB returned by New.
+type Option func(*DDB)
+
+// WithClient makes the DDB use the given client for the tables, e.g. a mock client in unit tests.
+func WithClient(client dynamodbiface.DynamoDBAPI) Option {
+	return func(ddb *DDB) {
+		ddb.Client = client
+	}
+}
+
+// WithEndpoint makes the DDB reach the tables at another endpoint, e.g. "http://localhost:8000" for DynamoDB local.
+//
+// The replica, if any, is still reached at the endpoint of its region.
+func WithEndpoint(endpoint string) Option {
+	return func(ddb *DDB) {
+		ddb.Client = dynamodb.New(session.Must(session.NewSession()), aws.NewConfig().WithEndpoint(endpoint))
+	}
+}
+
+// WithClock makes the DDB stamp the writes with the times of the given clock instead of the current time.
+func WithClock(clock func() time.Time) Option {
+	return func(ddb *DDB) {
+		ddb.Clock = clock
+	}
+}
+
+// WithHistory makes the DDB keep the health and scan history of the integrations in the given table.
+//
+// Each record expires the retention after it's written, by the TTL of the table.
+func WithHistory(tableName string, retention time.Duration) Option {
+	return func(ddb *DDB) {
+		ddb.HistoryTableName = tableName
+		ddb.HistoryRetention = retention
+	}
 }
 
 // New instantiates a new client.
-func New(tableName string) *DDB {
-	return &DDB{
-		Client:    dynamodb.New(session.Must(session.NewSession())),
-		TableName: tableName,
+//
+// If replicaRegion is set, reads can fall back to the replica of the table in that region.
+// Without options, the tables are reached with a client of the default session.
+func New(tableName, changesTableName, retriesTableName, replicaRegion string, options ...Option) *DDB {
+	sess := session.Must(session.NewSession())
+	result := &DDB{
+		Client:           dynamodb.New(sess),
+		TableName:        tableName,
+		ChangesTableName: changesTableName,
+		RetriesTableName: retriesTableName,
+	}
+	if replicaRegion != "" {
+		result.Replica = dynamodb.New(sess, aws.NewConfig().WithRegion(replicaRegion))
+	}
+	for _, option := range options {
+		option(result)
+	}
+	return result
+}
+
+// ConditionalCheckFailedError is returned when the condition of a conditional write does not hold.
+type ConditionalCheckFailedError struct {
+	Err error
+}
+
+func (e *ConditionalCheckFailedError) Error() string {
+	return "conditional check failed: " + e.Err.Error()
+}
+
+func (ddb *DDB) now() time.Time {
+	if ddb.Clock != nil {
+		return ddb.Clock()
 	}
+	return time.Now()
 }
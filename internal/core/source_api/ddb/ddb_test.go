@@ -0,0 +1,64 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+func TestNewWithClient(t *testing.T) {
+	mockClient := &modelstest.MockDDBClient{}
+	mockClient.On("GetItem", &dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String("tenant-integrations"),
+		Key:            map[string]*dynamodb.AttributeValue{hashKey: {S: aws.String("integration-1")}},
+	}).Return(&dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+		hashKey:           {S: aws.String("integration-1")},
+		"integrationType": {S: aws.String("aws-s3")},
+	}}, nil)
+
+	db := New("tenant-integrations", "tenant-changes", "tenant-retries", "", WithClient(mockClient))
+	assert.Equal(t, "tenant-changes", db.ChangesTableName)
+	assert.Nil(t, db.Replica)
+
+	integration, err := db.GetIntegration(aws.String("integration-1"), true)
+	require.NoError(t, err)
+	assert.Equal(t, "aws-s3", *integration.IntegrationType)
+	mockClient.AssertExpectations(t)
+}
+
+func TestNewWithEndpoint(t *testing.T) {
+	db := New("integrations", "changes", "retries", "us-west-2", WithEndpoint("http://localhost:8000"))
+	assert.Equal(t, "http://localhost:8000", db.Client.(*dynamodb.DynamoDB).Endpoint)
+	// The replica is still reached in its region
+	assert.Equal(t, "us-west-2", *db.Replica.(*dynamodb.DynamoDB).Config.Region)
+}
+
+func TestNewWithoutOptions(t *testing.T) {
+	db := New("integrations", "changes", "retries", "")
+	assert.IsType(t, &dynamodb.DynamoDB{}, db.Client)
+	assert.Equal(t, "integrations", db.TableName)
+}
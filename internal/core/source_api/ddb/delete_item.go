@@ -19,18 +19,31 @@ package ddb
  */
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
+// DeletedRetention is how long the marker of a deleted integration is kept, the changes listed as of an older time
+// may miss the deletion.
+const DeletedRetention = 30 * 24 * time.Hour
+
 // DeleteIntegrationItem deletes an integration from the database based on the integration ID
+//
+// The integration is replaced by a marker, which only lists its deletion among the changed integrations.
 func (ddb *DDB) DeleteIntegrationItem(input *models.DeleteIntegrationInput) error {
-	condition := expression.AttributeExists(expression.Name("integrationId"))
+	defer ddb.InvalidateCache(input.IntegrationID)
+	condition := expression.AttributeExists(expression.Name("integrationId")).
+		And(expression.AttributeNotExists(expression.Name(deletedKey)))
 
 	builder := expression.NewBuilder().WithCondition(condition)
 	expr, err := builder.Build()
@@ -38,14 +51,15 @@ func (ddb *DDB) DeleteIntegrationItem(input *models.DeleteIntegrationInput) erro
 		return &genericapi.InternalError{Message: "failed to build DeleteIntegration ddb expression"}
 	}
 
-	_, err = ddb.Client.DeleteItem(&dynamodb.DeleteItemInput{
+	output, err := ddb.Client.DeleteItem(&dynamodb.DeleteItemInput{
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		ConditionExpression:       expr.Condition(),
 		Key: map[string]*dynamodb.AttributeValue{
 			hashKey: {S: input.IntegrationID},
 		},
-		TableName: aws.String(ddb.TableName),
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+		TableName:    aws.String(ddb.TableName),
 	})
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
@@ -59,5 +73,182 @@ func (ddb *DDB) DeleteIntegrationItem(input *models.DeleteIntegrationInput) erro
 		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
 	}
 
+	// The integration is gone either way, a missing marker only hides its deletion from the changes
+	if err := ddb.putDeletedMarker(input.IntegrationID, output.Attributes); err != nil {
+		zap.L().Warn("failed to mark integration as deleted",
+			zap.String("integrationId", aws.StringValue(input.IntegrationID)), zap.Error(err))
+	}
 	return nil
 }
+
+func (ddb *DDB) putDeletedMarker(integrationID *string, deleted map[string]*dynamodb.AttributeValue) error {
+	_, err := ddb.Client.PutItem(&dynamodb.PutItemInput{
+		Item:      ddb.deletedMarker(integrationID, deleted),
+		TableName: aws.String(ddb.TableName),
+	})
+	return err
+}
+
+func (ddb *DDB) deletedMarker(integrationID *string, deleted map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	now := ddb.now()
+	item := map[string]*dynamodb.AttributeValue{
+		hashKey:         {S: integrationID},
+		deletedKey:      {BOOL: aws.Bool(true)},
+		lastModifiedKey: {S: aws.String(ModifiedTime(now))},
+		modifiedFeedKey: {S: aws.String(modifiedFeed)},
+		// Expiry of the marker by the TTL of the table, in epoch seconds
+		expiresAtKey: {N: aws.String(strconv.FormatInt(now.Add(DeletedRetention).Unix(), 10))},
+	}
+	// The type lets mirrors tell what kind of integration was deleted, the marker isn't scheduled for scans
+	// as long as it has no next scan time
+	if integrationType, ok := deleted["integrationType"]; ok {
+		item["integrationType"] = integrationType
+	}
+	return item
+}
+
+// SoftDeleteIntegrationItem marks an integration as deleted, but keeps its settings so that it can be restored
+// by RestoreIntegrationItem until the given time.
+//
+// The integration is hidden like a deleted one and isn't scheduled for scans, its KMS grants are dropped as they
+// are retired when it's deleted. Once it is no longer restorable,
+// PurgeSoftDeletedIntegrations replaces it by the marker of a deleted integration.
+func (ddb *DDB) SoftDeleteIntegrationItem(integrationID *string, restorableUntil time.Time) error {
+	defer ddb.InvalidateCache(integrationID)
+	condition := expression.AttributeExists(expression.Name(hashKey)).
+		And(expression.AttributeNotExists(expression.Name(deletedKey)))
+	update := expression.Set(expression.Name(deletedKey), expression.Value(true)).
+		Set(expression.Name(restorableUntilKey), expression.Value(ModifiedTime(restorableUntil))).
+		Remove(expression.Name(nextScanTimeKey)).
+		Remove(expression.Name("kmsGrants"))
+	expr, err := expression.NewBuilder().WithCondition(condition).WithUpdate(stampWrite(update, ddb.now())).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build SoftDeleteIntegration ddb expression"}
+	}
+
+	_, err = ddb.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey: {S: integrationID},
+		},
+		TableName:        aws.String(ddb.TableName),
+		UpdateExpression: expr.Update(),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return &genericapi.DoesNotExistError{Message: aerr.Error()}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.UpdateItem"}
+	}
+	return nil
+}
+
+// RestoreIntegrationItem restores an integration which was soft-deleted, as long as it's still restorable.
+//
+// A DoesNotExistError is returned for an integration which was not soft-deleted or whose retention expired.
+// The next scan of the restored integration is not scheduled, its schedule has to be refreshed.
+func (ddb *DDB) RestoreIntegrationItem(integrationID *string) (*models.SourceIntegration, error) {
+	defer ddb.InvalidateCache(integrationID)
+	now := ddb.now()
+	// The times are stored with the same format, so they compare as strings
+	condition := expression.AttributeExists(expression.Name(deletedKey)).
+		And(expression.Name(restorableUntilKey).GreaterThan(expression.Value(ModifiedTime(now))))
+	update := expression.Remove(expression.Name(deletedKey)).Remove(expression.Name(restorableUntilKey))
+	expr, err := expression.NewBuilder().WithCondition(condition).WithUpdate(stampWrite(update, now)).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build RestoreIntegration ddb expression"}
+	}
+
+	response, err := ddb.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey: {S: integrationID},
+		},
+		ReturnValues:     aws.String(dynamodb.ReturnValueAllNew),
+		TableName:        aws.String(ddb.TableName),
+		UpdateExpression: expr.Update(),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil, &genericapi.DoesNotExistError{Message: "integration is not restorable"}
+	}
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UpdateItem"}
+	}
+
+	var result models.SourceIntegration
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, &result); err != nil {
+		return nil, &genericapi.InternalError{Message: "restore unmarshal failed: " + err.Error()}
+	}
+	deriveFields(&result)
+	return &result, nil
+}
+
+// PurgeSoftDeletedIntegrations replaces the soft-deleted integrations which are no longer restorable by the
+// marker of a deleted integration, dropping their settings. It returns the IDs of the purged integrations.
+//
+// The table is read page by page, a failed page stops the purge and the rest is purged on the next run.
+func (ddb *DDB) PurgeSoftDeletedIntegrations() ([]*string, error) {
+	now := ddb.now()
+	filter := expression.Name(restorableUntilKey).LessThanEqual(expression.Value(ModifiedTime(now)))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build PurgeSoftDeletedIntegrations ddb expression"}
+	}
+
+	purged := make([]*string, 0)
+	input := &dynamodb.ScanInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		TableName:                 aws.String(ddb.TableName),
+	}
+	for page := 0; page == 0 || input.ExclusiveStartKey != nil; page++ {
+		output, err := ddb.Client.Scan(input)
+		if err != nil {
+			return purged, &genericapi.AWSError{Err: err, Method: "Dynamodb.Scan"}
+		}
+		for _, item := range output.Items {
+			integrationID := item[hashKey].S
+			replaced, err := ddb.putPurgedMarker(integrationID, item)
+			if err != nil {
+				return purged, err
+			}
+			if replaced {
+				purged = append(purged, integrationID)
+			}
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+	return purged, nil
+}
+
+// putPurgedMarker replaces a soft-deleted integration by a marker, unless it was restored in the meantime.
+func (ddb *DDB) putPurgedMarker(integrationID *string, deleted map[string]*dynamodb.AttributeValue) (bool, error) {
+	defer ddb.InvalidateCache(integrationID)
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(restorableUntilKey))).
+		Build()
+	if err != nil {
+		return false, &genericapi.InternalError{Message: "failed to build PutItem ddb expression: " + err.Error()}
+	}
+
+	_, err = ddb.Client.PutItem(&dynamodb.PutItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Item:                     ddb.deletedMarker(integrationID, deleted),
+		TableName:                aws.String(ddb.TableName),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		zap.L().Info("soft-deleted integration was restored before it was purged",
+			zap.String("integrationId", aws.StringValue(integrationID)))
+		return false, nil
+	}
+	if err != nil {
+		return false, &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return true, nil
+}
@@ -19,33 +19,167 @@ package ddb
  */
 
 import (
+	"net/http"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 // GetIntegration returns an integration by its ID
-func (ddb *DDB) GetIntegration(integrationID *string) (*models.SourceIntegrationMetadata, error) {
+//
+// A consistent read is needed to reflect a write made immediately before, otherwise the item may be stale.
+func (ddb *DDB) GetIntegration(integrationID *string, consistentRead bool) (*models.SourceIntegrationMetadata, error) {
+	item, err := ddb.getItem(integrationID, consistentRead)
+	if item == nil || err != nil {
+		return nil, err
+	}
+
+	var integration models.SourceIntegrationMetadata
+	if err := dynamodbattribute.UnmarshalMap(item, &integration); err != nil {
+		return nil, err
+	}
+
+	return &integration, nil
+}
+
+// GetSourceIntegration returns an integration by its ID, including its status and scan information.
+//
+// The read is consistent, it reflects the writes made immediately before.
+func (ddb *DDB) GetSourceIntegration(integrationID *string) (*models.SourceIntegration, error) {
+	item, err := ddb.getItem(integrationID, true)
+	if item == nil || err != nil {
+		return nil, err
+	}
+
+	var integration models.SourceIntegration
+	if err := dynamodbattribute.UnmarshalMap(item, &integration); err != nil {
+		return nil, err
+	}
+	deriveFields(&integration)
+	return &integration, nil
+}
+
+// GetScanErrorSamples returns the scan error samples of an integration, nil if the integration doesn't exist.
+func (ddb *DDB) GetScanErrorSamples(integrationID *string) ([]*models.ScanErrorSample, error) {
+	samples, _, err := ddb.GetScanErrorSamplesWithVersion(integrationID)
+	return samples, err
+}
+
+// GetScanErrorSamplesWithVersion returns the scan error samples of an integration and the version they were read at,
+// so that they can be rewritten only if the integration didn't change in between.
+func (ddb *DDB) GetScanErrorSamplesWithVersion(integrationID *string) ([]*models.ScanErrorSample, int64, error) {
 	output, err := ddb.Client.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(ddb.TableName),
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(ddb.TableName),
 		Key: map[string]*dynamodb.AttributeValue{
 			hashKey: {S: integrationID},
 		},
+		// The key is projected too, so that an integration without samples is still found
+		ProjectionExpression:     aws.String(hashKey + ", " + scanErrorSamplesKey + ", " + deletedKey + ", #version"),
+		ExpressionAttributeNames: map[string]*string{"#version": aws.String(versionKey)},
 	})
+	if err != nil {
+		return nil, 0, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if _, deleted := output.Item[deletedKey]; output.Item == nil || deleted {
+		return nil, 0, nil
+	}
+
+	var item struct {
+		ScanErrorSamples []*models.ScanErrorSample `json:"scanErrorSamples"`
+		Version          int64                     `json:"version"`
+	}
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, 0, err
+	}
+	if item.ScanErrorSamples == nil {
+		item.ScanErrorSamples = make([]*models.ScanErrorSample, 0)
+	}
+	return item.ScanErrorSamples, item.Version, nil
+}
+
+// GetSourceIntegrationWithFallback returns an integration by its ID, including its status and scan information.
+//
+// If the primary table is unavailable, the integration is read from the replica instead. Replication is asynchronous, so stale is true when the integration was read from the replica.
+func (ddb *DDB) GetSourceIntegrationWithFallback(integrationID *string) (
+	integration *models.SourceIntegration, stale bool, err error) {
+
+	item, err := ddb.getItemFrom(ddb.Client, integrationID, false)
+	if err != nil && ddb.Replica != nil && primaryUnavailable(err) {
+		zap.L().Warn("primary table unavailable, reading from replica",
+			zap.String("integrationId", aws.StringValue(integrationID)), zap.Error(err))
+		stale = true
+		// Consistent reads are not supported across regions
+		item, err = ddb.getItemFrom(ddb.Replica, integrationID, false)
+	}
+	if err != nil {
+		return nil, stale, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if item == nil {
+		return nil, stale, nil
+	}
+
+	integration = &models.SourceIntegration{}
+	if err := dynamodbattribute.UnmarshalMap(item, integration); err != nil {
+		return nil, stale, err
+	}
+	deriveFields(integration)
+	return integration, stale, nil
+}
+
+// primaryUnavailable returns true if the error was caused by the table or the network rather than the request.
+func primaryUnavailable(err error) bool {
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() >= http.StatusInternalServerError
+	}
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == request.ErrCodeRequestError || awsErr.Code() == request.ErrCodeResponseTimeout
+	}
+	return false
+}
+
+// getItem serves the reads which are not consistent from the cache, if it's enabled. The integrations which don't
+// exist are never cached, so that they are found as soon as they are added.
+func (ddb *DDB) getItem(integrationID *string, consistentRead bool) (map[string]*dynamodb.AttributeValue, error) {
+	if !consistentRead {
+		if item, ok := ddb.cache.getItem(integrationID); ok {
+			return item, nil
+		}
+	}
+	generation := ddb.cache.currentGeneration()
+	item, err := ddb.getItemFrom(ddb.Client, integrationID, consistentRead)
 	if err != nil {
 		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
 	}
+	if item != nil {
+		ddb.cache.putItem(integrationID, item, generation)
+	}
+	return item, nil
+}
 
-	var integration models.SourceIntegrationMetadata
-	if output.Item == nil {
+func (ddb *DDB) getItemFrom(
+	client dynamodbiface.DynamoDBAPI, integrationID *string, consistentRead bool) (map[string]*dynamodb.AttributeValue, error) {
+
+	output, err := client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(consistentRead),
+		TableName:      aws.String(ddb.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey: {S: integrationID},
+		},
+	})
+	if err != nil {
 		return nil, err
 	}
-	if err := dynamodbattribute.UnmarshalMap(output.Item, &integration); err != nil {
-		return nil, err
+	if _, deleted := output.Item[deletedKey]; deleted {
+		return nil, nil
 	}
-
-	return &integration, nil
+	return output.Item, nil
 }
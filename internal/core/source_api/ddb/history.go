@@ -0,0 +1,123 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// PutHistoryRecord adds a record to the health and scan history of an integration.
+//
+// The record expires after the retention of the history, the expiry is set on the given record.
+func (ddb *DDB) PutHistoryRecord(record *models.IntegrationHistoryRecord) error {
+	record.ExpiresAt = aws.Int64(ddb.now().Add(ddb.HistoryRetention).Unix())
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+
+	_, err = ddb.Client.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(ddb.HistoryTableName),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// ListHistory returns a page of the health and scan history of an integration, most recent first.
+//
+// DynamoDB deletes the expired records up to a few days after they expire, they are filtered out until then.
+// The records are limited to the given kind if it is set. The record ID of the last evaluated record is set
+// if there may be more records, the next page starts after it.
+func (ddb *DDB) ListHistory(integrationID, kind *string, limit int,
+	exclusiveStartRecordID *string) ([]*models.IntegrationHistoryRecord, *string, error) {
+
+	keyCondition := expression.Key(hashKey).Equal(expression.Value(integrationID))
+	filter := expression.Name(expiresAtKey).GreaterThan(expression.Value(ddb.now().Unix()))
+	if kind != nil {
+		filter = filter.And(expression.Name("kind").Equal(expression.Value(*kind)))
+	}
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).WithFilter(filter).Build()
+	if err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to build ListHistory ddb expression"}
+	}
+
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     aws.Int64(int64(limit)),
+		ScanIndexForward:          aws.Bool(false),
+		TableName:                 aws.String(ddb.HistoryTableName),
+	}
+	if exclusiveStartRecordID != nil {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			hashKey:     {S: integrationID},
+			recordIDKey: {S: exclusiveStartRecordID},
+		}
+	}
+
+	output, err := ddb.Client.Query(input)
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Query"}
+	}
+
+	var records []*models.IntegrationHistoryRecord
+	if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &records); err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	var lastRecordID *string
+	if key, ok := output.LastEvaluatedKey[recordIDKey]; ok {
+		lastRecordID = key.S
+	}
+	return records, lastRecordID, nil
+}
+
+// DeleteHistoryRecords deletes records of the health and scan history of an integration, by record ID.
+func (ddb *DDB) DeleteHistoryRecords(integrationID *string, recordIDs []*string) error {
+	if len(recordIDs) == 0 {
+		return nil
+	}
+	requests := make([]*dynamodb.WriteRequest, len(recordIDs))
+	for i, recordID := range recordIDs {
+		requests[i] = &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{
+			Key: map[string]*dynamodb.AttributeValue{
+				hashKey:     {S: integrationID},
+				recordIDKey: {S: recordID},
+			},
+		}}
+	}
+
+	err := dynamodbbatch.BatchWriteItem(ddb.Client, maxElapsedTime, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{ddb.HistoryTableName: requests}})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.BatchWriteItem"}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+var historyNow = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// historyClient stores the items it's given and applies the expiry filter of the queries like DynamoDB.
+type historyClient struct {
+	dynamodbiface.DynamoDBAPI
+	items []map[string]*dynamodb.AttributeValue
+	query *dynamodb.QueryInput
+}
+
+func (client *historyClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	client.items = append(client.items, input.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+var greaterThanFilter = regexp.MustCompile(`(#\d+) > (:\d+)`)
+
+func (client *historyClient) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	client.query = input
+	match := greaterThanFilter.FindStringSubmatch(aws.StringValue(input.FilterExpression))
+	var minimum int64
+	if match != nil && *input.ExpressionAttributeNames[match[1]] == expiresAtKey {
+		minimum, _ = strconv.ParseInt(*input.ExpressionAttributeValues[match[2]].N, 10, 64)
+	}
+	output := &dynamodb.QueryOutput{}
+	for _, item := range client.items {
+		if expiresAt, _ := strconv.ParseInt(*item[expiresAtKey].N, 10, 64); expiresAt > minimum {
+			output.Items = append(output.Items, item)
+		}
+	}
+	return output, nil
+}
+
+func historyDB(client dynamodbiface.DynamoDBAPI, now *time.Time) *DDB {
+	return New("integrations", "changes", "retries", "", WithClient(client),
+		WithHistory("history", 30*24*time.Hour), WithClock(func() time.Time { return *now }))
+}
+
+func TestPutHistoryRecordExpiry(t *testing.T) {
+	client := &historyClient{}
+	db := historyDB(client, &historyNow)
+
+	require.NoError(t, db.PutHistoryRecord(&models.IntegrationHistoryRecord{
+		IntegrationID: aws.String("integration-1"),
+		RecordID:      aws.String("2020-06-01T12:00:00.000000000Z-1"),
+		Kind:          aws.String(models.HistoryKindScan),
+		ScanStatus:    aws.String(models.StatusOK),
+	}))
+	require.Len(t, client.items, 1)
+	// The TTL attribute is in epoch seconds, the retention after the write
+	assert.Equal(t, strconv.FormatInt(historyNow.Add(30*24*time.Hour).Unix(), 10), *client.items[0][expiresAtKey].N)
+	assert.Equal(t, "2020-06-01T12:00:00.000000000Z-1", *client.items[0][recordIDKey].S)
+}
+
+func TestListHistoryExcludesExpired(t *testing.T) {
+	client := &historyClient{}
+	now := historyNow
+	db := historyDB(client, &now)
+	for i, kind := range []string{models.HistoryKindHealth, models.HistoryKindScan} {
+		now = historyNow.Add(time.Duration(i) * 24 * time.Hour)
+		require.NoError(t, db.PutHistoryRecord(&models.IntegrationHistoryRecord{
+			IntegrationID: aws.String("integration-1"),
+			RecordID:      aws.String(now.Format(time.RFC3339)),
+			Kind:          aws.String(kind),
+		}))
+	}
+
+	// The first record expired but DynamoDB hasn't deleted it yet
+	now = historyNow.Add(30*24*time.Hour + time.Hour)
+	records, lastRecordID, err := db.ListHistory(aws.String("integration-1"), nil, 25, nil)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, models.HistoryKindScan, *records[0].Kind)
+	assert.Nil(t, lastRecordID)
+	assert.Equal(t, "history", *client.query.TableName)
+	assert.False(t, *client.query.ScanIndexForward)
+}
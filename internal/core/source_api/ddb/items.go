@@ -18,24 +18,129 @@ package ddb
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-import "time"
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
 
 // UpdateIntegrationItem updates almost every attribute in the table.
 //
 // It's used for attributes that can change, which is almost all of them except for the
 // creation based ones (CreatedAtTime and CreatedBy).
 type UpdateIntegrationItem struct {
-	ScanEnabled          *bool      `json:"scanEnabled"`
-	RemediationEnabled   *bool      `json:"remediationEnabled"`
-	CWEEnabled           *bool      `json:"cweEnabled"`
-	IntegrationID        *string    `json:"integrationId"`
-	IntegrationLabel     *string    `json:"integrationLabel"`
-	IntegrationType      *string    `json:"integrationType"`
-	LastScanEndTime      *time.Time `json:"lastScanEndTime"`
-	LastScanErrorMessage *string    `json:"lastScanErrorMessage"`
-	LastScanStartTime    *time.Time `json:"lastScanStartTime"`
-	ScanStatus           *string    `json:"scanStatus"`
-	ScanIntervalMins     *int       `json:"scanIntervalMins"`
-	S3Buckets            []*string  `json:"s3Buckets" dynamodbav:"s3Buckets,stringset"`
-	KmsKeys              []*string  `json:"kmsKeys" dynamodbav:"kmsKeys,stringset"`
+	ScanEnabled              *bool                             `json:"scanEnabled"`
+	RemediationEnabled       *bool                             `json:"remediationEnabled"`
+	CWEEnabled               *bool                             `json:"cweEnabled"`
+	IntegrationID            *string                           `json:"integrationId"`
+	IntegrationLabel         *string                           `json:"integrationLabel"`
+	Description              *string                           `json:"description"`
+	IntegrationType          *string                           `json:"integrationType"`
+	LastScanEndTime          *time.Time                        `json:"lastScanEndTime"`
+	LastScanErrorMessage     *string                           `json:"lastScanErrorMessage"`
+	LastScanStartTime        *time.Time                        `json:"lastScanStartTime"`
+	LastScanBookmark         *string                           `json:"lastScanBookmark"`
+	LastScanTruncated        *bool                             `json:"lastScanTruncated"`
+	LastScanObjectsProcessed *int64                            `json:"lastScanObjectsProcessed"`
+	ScanStatus               *string                           `json:"scanStatus"`
+	ScanIntervalMins         *int                              `json:"scanIntervalMins"`
+	S3Buckets                []*string                         `json:"s3Buckets" dynamodbav:"s3Buckets,stringset"`
+	KmsKeys                  []*string                         `json:"kmsKeys" dynamodbav:"kmsKeys,stringset"`
+	KmsKeyAliases            map[string]*string                `json:"kmsKeyAliases"`
+	S3BucketRegions          map[string]*string                `json:"s3BucketRegions"`
+	S3ObjectFilters          map[string]*models.S3ObjectFilter `json:"s3ObjectFilters"`
+	SyslogAllowedCIDRs       []*string                         `json:"syslogAllowedCidrs" dynamodbav:"syslogAllowedCidrs,stringset"`
+	MaxConcurrentObjects     *int                              `json:"maxConcurrentObjects"`
+	MaxObjectsPerScan        *int                              `json:"maxObjectsPerScan"`
+	OrderingMode             *string                           `json:"orderingMode"`
+	MaxParallelism           *int                              `json:"maxParallelism"`
+	DedupWindowMinutes       *int                              `json:"dedupWindowMinutes"`
+	MaxRecordBytes           *int                              `json:"maxRecordBytes"`
+	OversizedRecordPolicy    *string                           `json:"oversizedRecordPolicy"`
+	SampleRate               *float64                          `json:"sampleRate"`
+	ErrorRateThreshold       *float64                          `json:"errorRateThreshold"`
+	SilenceThresholdMinutes  *int                              `json:"silenceThresholdMinutes"`
+	EventErrorRateThreshold  *float64                          `json:"eventErrorRateThreshold"`
+	IngestionAlarms          []*string                         `json:"ingestionAlarms"`
+	TimeExtraction           *models.TimeExtraction            `json:"timeExtraction" update:"removeEmpty"`
+	TimestampSkew            *models.TimestampSkew             `json:"timestampSkew" update:"removeEmpty"`
+	DeadLetterQueue          *models.DeadLetterQueue           `json:"deadLetterQueue" update:"removeEmpty"`
+	ArchiveFormat            *string                           `json:"archiveFormat" update:"removeEmpty"`
+	DataClassification       *string                           `json:"dataClassification"`
+	ProcessingRegion         *string                           `json:"processingRegion" update:"removeEmpty"`
+	BlackoutWindows          []*models.BlackoutWindow          `json:"blackoutWindows"`
+	ScanSchedule             *string                           `json:"scanSchedule" update:"removeEmpty"`
+	EnabledRegions           []*string                         `json:"enabledRegions"`
+	ExcludedRegions          []*string                         `json:"excludedRegions"`
+	ResourceTypeAllowList    []*string                         `json:"resourceTypeAllowList"`
+	ResourceTypeDenyList     []*string                         `json:"resourceTypeDenyList"`
+	RedactionRules           []*models.RedactionRule           `json:"redactionRules"`
+	IncludePatterns          []*string                         `json:"includePatterns"`
+	ExcludePatterns          []*string                         `json:"excludePatterns"`
+	DisabledChecks           []*string                         `json:"disabledChecks"`
+	FeatureFlags             map[string]bool                   `json:"featureFlags"`
+	CreateKmsGrants          *bool                             `json:"createKmsGrants"`
+	KmsGrants                map[string]*string                `json:"kmsGrants"`
+	IsOrgTrail               *bool                             `json:"isOrgTrail"`
+	ManagementAccountID      *string                           `json:"managementAccountId"`
+	RoleChain                []*string                         `json:"roleChain"`
+	SessionDurationSeconds   *int                              `json:"sessionDurationSeconds"`
+	HealthStatus             *string                           `json:"healthStatus"`
+	FailedHealthChecks       []*string                         `json:"failedHealthChecks"`
+	LastHealthyTime          *time.Time                        `json:"lastHealthyTime"`
+	LastHealthCheckTime      *time.Time                        `json:"lastHealthCheckTime"`
+	DependsOn                []*string                         `json:"dependsOn"`
+	NotificationTargets      []*string                         `json:"notificationTargets"`
+	EnrichmentSources        []*string                         `json:"enrichmentSources"`
+	Tags                     map[string]*string                `json:"tags"`
+	SeverityOverrides        map[string]*string                `json:"severityOverrides"`
+	ScanCompleteCallbackURL  *string                           `json:"scanCompleteCallbackUrl"`
+	NextScanTime             *time.Time                        `json:"nextScanTime"`
+	AverageScanDurationSecs  *int64                            `json:"averageScanDurationSecs"`
+	SQSEventSourceMappingID  *string                           `json:"sqsEventSourceMappingId"`
+	HTTPIngestAPIKeyID       *string                           `json:"httpIngestApiKeyId"`
+	CredentialsSecretArn     *string                           `json:"credentialsSecretArn"`
+	CredentialsUpdatedTime   *time.Time                        `json:"credentialsUpdatedTime"`
+
+	// Not part of the integration models, they are read by GetScanErrorSamples
+	ScanErrorSamples []*models.ScanErrorSample `json:"scanErrorSamples"`
+
+	// A counter rather than a value: true increments it in place, false resets it to 0
+	ConsecutiveTruncatedScans *bool `json:"consecutiveTruncatedScans" update:"counter"`
+	CompletedScans            *bool `json:"completedScans" update:"counter"`
+
+	// An empty value removes the attribute, the stream is cleared when the integration changes to another type
+	StreamARN         *string `json:"streamArn" update:"removeEmpty"`
+	ShardIteratorType *string `json:"shardIteratorType" update:"removeEmpty"`
+	// The consumer is removed with the stream
+	KinesisConsumerARN *string `json:"kinesisConsumerArn" update:"removeEmpty"`
+
+	// Not part of the settings, they are written by the Kinesis reader
+	KinesisCheckpoints     map[string]*string `json:"kinesisCheckpoints"`
+	KinesisCheckpointsFrom *string            `json:"kinesisCheckpointsFrom"`
+	// Not part of the settings, it's written by the Okta puller
+	OktaLogCursor *string `json:"oktaLogCursor"`
+
+	// The member accounts an aws-organization integration doesn't onboard
+	OrganizationExcludedAccounts []*string `json:"organizationExcludedAccounts"`
+
+	// The zero time removes the attribute, once the integration has no credential which expires
+	CredentialExpiry *time.Time `json:"credentialExpiry" update:"removeEmpty"`
+
+	// A condition rather than a value: the update only succeeds if the version of the integration is still this one.
+	// The version 0 is the one of integrations written before versions were stamped
+	ExpectedVersion *int64 `json:"version" update:"expected"`
+}
+
+// deriveFields sets the attributes of an integration which are computed from the stored ones.
+func deriveFields(integration *models.SourceIntegration) {
+	if integration.SourceIntegrationScanInformation == nil {
+		return
+	}
+	info := integration.SourceIntegrationScanInformation
+	if aws.IntValue(info.ConsecutiveTruncatedScans) >= models.BacklogSuspectedAfter {
+		info.BacklogSuspected = aws.Bool(true)
+	}
 }
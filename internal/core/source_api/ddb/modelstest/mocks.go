@@ -51,6 +51,14 @@ func (client *MockDDBClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynam
 	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
 }
 
+// TransactWriteItems is a mock method to write items of a dynamodb table in a transaction.
+func (client *MockDDBClient) TransactWriteItems(
+	input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+
+	args := client.Called(input)
+	return args.Get(0).(*dynamodb.TransactWriteItemsOutput), args.Error(1)
+}
+
 // Scan is a mock DynamoDB Scan request.
 func (client *MockDDBClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 	if client.TestErr {
@@ -87,3 +95,14 @@ func (client *MockDDBClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput)
 	}
 	return &dynamodb.BatchWriteItemOutput{}, nil
 }
+
+// BatchGetItemPages is a mock DynamoDB BatchGetItem request, the output is returned as a single page.
+func (client *MockDDBClient) BatchGetItemPages(
+	input *dynamodb.BatchGetItemInput, fn func(*dynamodb.BatchGetItemOutput, bool) bool) error {
+
+	args := client.Called(input)
+	if output, ok := args.Get(0).(*dynamodb.BatchGetItemOutput); ok {
+		fn(output, true)
+	}
+	return args.Error(1)
+}
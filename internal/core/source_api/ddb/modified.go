@@ -0,0 +1,108 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The times of the writes are sorted as strings in the index, they must all have the same zone and number of digits
+const modifiedTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// ModifiedKey is the position of an integration in the index of the last writes.
+type ModifiedKey struct {
+	IntegrationID  string `json:"integrationId"`
+	LastModifiedAt string `json:"lastModifiedAt"`
+}
+
+// ModifiedTime is how the time of a write to an integration is stored, with a millisecond precision.
+func ModifiedTime(t time.Time) string {
+	return t.UTC().Format(modifiedTimeFormat)
+}
+
+// ListModifiedSince returns a page of the integrations written to after the given time, the least recent first.
+//
+// Deleted integrations are returned as a marker which only has their ID and type, with Deleted set.
+// The key of the last integration read is set if there may be more, the next page starts after it.
+func (ddb *DDB) ListModifiedSince(
+	since time.Time, limit int, exclusiveStart *ModifiedKey) ([]*models.SourceIntegration, *ModifiedKey, error) {
+
+	keyCondition := expression.Key(modifiedFeedKey).Equal(expression.Value(modifiedFeed)).
+		And(expression.Key(lastModifiedKey).GreaterThan(expression.Value(ModifiedTime(since))))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to build ListModifiedSince ddb expression"}
+	}
+
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		IndexName:                 aws.String(lastModifiedIndex),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     aws.Int64(int64(limit)),
+		ScanIndexForward:          aws.Bool(true),
+		TableName:                 aws.String(ddb.TableName),
+	}
+	if exclusiveStart != nil {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			hashKey:         {S: aws.String(exclusiveStart.IntegrationID)},
+			modifiedFeedKey: {S: aws.String(modifiedFeed)},
+			lastModifiedKey: {S: aws.String(exclusiveStart.LastModifiedAt)},
+		}
+	}
+
+	output, err := ddb.Client.Query(input)
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Query"}
+	}
+
+	integrations := make([]*models.SourceIntegration, 0, len(output.Items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &integrations); err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	for i, integration := range integrations {
+		if integration.SourceIntegrationMetadata != nil && aws.BoolValue(integration.Deleted) {
+			// Soft-deleted integrations still have their settings, they are listed like the other deleted ones
+			integrations[i] = &models.SourceIntegration{SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+				IntegrationID:   integration.IntegrationID,
+				IntegrationType: integration.IntegrationType,
+				LastModifiedAt:  integration.LastModifiedAt,
+				Deleted:         integration.Deleted,
+			}}
+			continue
+		}
+		deriveFields(integration)
+	}
+	var last *ModifiedKey
+	if len(output.LastEvaluatedKey) > 0 {
+		last = &ModifiedKey{
+			IntegrationID:  aws.StringValue(output.LastEvaluatedKey[hashKey].S),
+			LastModifiedAt: aws.StringValue(output.LastEvaluatedKey[lastModifiedKey].S),
+		}
+	}
+	return integrations, last, nil
+}
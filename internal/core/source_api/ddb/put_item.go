@@ -21,8 +21,11 @@ package ddb
 import (
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/pkg/awsbatch/dynamodbbatch"
@@ -34,17 +37,21 @@ var maxElapsedTime = 15 * time.Second
 // BatchPutSourceIntegrations adds a batch of new Snapshot Integrations to the database.
 func (ddb *DDB) BatchPutSourceIntegrations(input []*models.SourceIntegrationMetadata) error {
 	writeRequests := make([]*dynamodb.WriteRequest, len(input))
+	integrationIDs := make([]*string, len(input))
+	now := ddb.now()
 
 	// Marshal each new integration and add to the write request
-	for i, item := range input {
-		item, err := dynamodbattribute.MarshalMap(item)
+	for i, integration := range input {
+		integrationIDs[i] = integration.IntegrationID
+		item, err := newIntegrationItem(integration, now)
 		if err != nil {
-			return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+			return err
 		}
 		writeRequests[i] = &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}}
 	}
 
 	// Do the batch write
+	defer ddb.InvalidateCache(integrationIDs...)
 	err := dynamodbbatch.BatchWriteItem(ddb.Client, maxElapsedTime, &dynamodb.BatchWriteItemInput{
 		RequestItems: map[string][]*dynamodb.WriteRequest{ddb.TableName: writeRequests}})
 	if err != nil {
@@ -53,3 +60,52 @@ func (ddb *DDB) BatchPutSourceIntegrations(input []*models.SourceIntegrationMeta
 
 	return nil
 }
+
+// PutNewSourceIntegration adds a new integration, unless an integration with the same ID already exists.
+//
+// An existing integration is left as it is and a ConditionalCheckFailedError is returned.
+func (ddb *DDB) PutNewSourceIntegration(integration *models.SourceIntegrationMetadata) error {
+	defer ddb.InvalidateCache(integration.IntegrationID)
+	item, err := newIntegrationItem(integration, ddb.now())
+	if err != nil {
+		return err
+	}
+	// The marker of a deleted integration doesn't prevent adding it again
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name(hashKey)).
+			Or(expression.AttributeExists(expression.Name(deletedKey)))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build PutItem ddb expression: " + err.Error()}
+	}
+
+	_, err = ddb.Client.PutItem(&dynamodb.PutItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Item:                     item,
+		TableName:                aws.String(ddb.TableName),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return &ConditionalCheckFailedError{Err: err}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+func newIntegrationItem(integration *models.SourceIntegrationMetadata, now time.Time) (map[string]*dynamodb.AttributeValue, error) {
+	item, err := dynamodbattribute.MarshalMap(integration)
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	// New integrations are due for their first scan right away
+	if aws.BoolValue(integration.ScanEnabled) && integration.CreatedAtTime != nil {
+		if item[nextScanTimeKey], err = dynamodbattribute.Marshal(ScheduleTime(*integration.CreatedAtTime)); err != nil {
+			return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Marshal"}
+		}
+	}
+	item[lastModifiedKey] = &dynamodb.AttributeValue{S: aws.String(ModifiedTime(now))}
+	item[modifiedFeedKey] = &dynamodb.AttributeValue{S: aws.String(modifiedFeed)}
+	return item, nil
+}
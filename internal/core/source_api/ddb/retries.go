@@ -0,0 +1,138 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// EnqueueRetry adds a pending retry of a side effect of an integration which failed.
+//
+// Enqueuing is idempotent: a side effect which is already pending keeps its attempts and its next attempt time,
+// only its last error is replaced.
+func (ddb *DDB) EnqueueRetry(integrationID *string, sideEffect, lastError string, enqueuedAt, nextAttemptTime time.Time) error {
+	update := expression.
+		Set(expression.Name("enqueuedAt"), expression.IfNotExists(expression.Name("enqueuedAt"), expression.Value(enqueuedAt))).
+		Set(expression.Name("attempts"), expression.IfNotExists(expression.Name("attempts"), expression.Value(0))).
+		Set(expression.Name("nextAttemptTime"),
+			expression.IfNotExists(expression.Name("nextAttemptTime"), expression.Value(nextAttemptTime))).
+		Set(expression.Name("lastError"), expression.Value(lastError))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build EnqueueRetry ddb expression"}
+	}
+
+	_, err = ddb.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey:       {S: integrationID},
+			sideEffectKey: {S: aws.String(sideEffect)},
+		},
+		TableName:        aws.String(ddb.RetriesTableName),
+		UpdateExpression: expr.Update(),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.UpdateItem"}
+	}
+	return nil
+}
+
+// PutRetry replaces a pending retry, e.g. with its next attempt after a failed one.
+func (ddb *DDB) PutRetry(retry *models.PendingRetry) error {
+	item, err := dynamodbattribute.MarshalMap(retry)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+
+	_, err = ddb.Client.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(ddb.RetriesTableName),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// DeleteRetry removes the pending retry of a side effect once it succeeded.
+func (ddb *DDB) DeleteRetry(integrationID *string, sideEffect string) error {
+	_, err := ddb.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey:       {S: integrationID},
+			sideEffectKey: {S: aws.String(sideEffect)},
+		},
+		TableName: aws.String(ddb.RetriesTableName),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
+
+// ListRetries returns the pending retries of an integration, or of every integration when integrationID is nil.
+//
+// There is no pending retry unless side effects fail, the table is small enough to be read in full.
+func (ddb *DDB) ListRetries(integrationID *string) ([]*models.PendingRetry, error) {
+	var items []map[string]*dynamodb.AttributeValue
+	if integrationID != nil {
+		keyCondition := expression.Key(hashKey).Equal(expression.Value(integrationID))
+		expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+		if err != nil {
+			return nil, &genericapi.InternalError{Message: "failed to build ListRetries ddb expression"}
+		}
+		output, err := ddb.Client.Query(&dynamodb.QueryInput{
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+			TableName:                 aws.String(ddb.RetriesTableName),
+		})
+		if err != nil {
+			return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Query"}
+		}
+		items = output.Items
+	} else {
+		input := &dynamodb.ScanInput{TableName: aws.String(ddb.RetriesTableName)}
+		for {
+			output, err := ddb.Client.Scan(input)
+			if err != nil {
+				return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Scan"}
+			}
+			items = append(items, output.Items...)
+			if output.LastEvaluatedKey == nil {
+				break
+			}
+			input.ExclusiveStartKey = output.LastEvaluatedKey
+		}
+	}
+
+	retries := make([]*models.PendingRetry, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &retries); err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	return retries, nil
+}
@@ -19,6 +19,8 @@ package ddb
  */
 
 import (
+	"sort"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
@@ -28,35 +30,197 @@ import (
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
+// IntegrationFilter selects the integrations a scan returns, the fields which are not set match every integration.
+type IntegrationFilter struct {
+	IntegrationType *string
+	ScanStatus      *string
+	HealthStatus    *string
+	LabelPrefix     *string
+	// Every tag must have the given value
+	Tags map[string]*string
+}
+
 // ScanEnabledIntegrations returns all enabled integrations based on type (if type is specified).
-// It performs a DDB scan of the entire table with a filter expression.
+// It performs a DDB scan of the entire table with a filter expression, page by page.
+//
+// The listing is served from the cache, if it's enabled and the same filter was listed before.
 func (ddb *DDB) ScanEnabledIntegrations(input *models.ListIntegrationsInput) ([]*models.SourceIntegration, error) {
-	filt := expression.Name("scanEnabled").Equal(expression.Value(true))
-	if input.IntegrationType != nil {
-		filt = expression.And(filt, expression.Name("integrationType").Equal(expression.Value(input.IntegrationType)))
+	filter := &IntegrationFilter{
+		IntegrationType: input.IntegrationType,
+		ScanStatus:      input.ScanStatus,
+		HealthStatus:    input.HealthStatus,
+		LabelPrefix:     input.LabelPrefix,
+		Tags:            input.Tags,
+	}
+	items, ok := ddb.cache.getScan(filter)
+	if !ok {
+		generation := ddb.cache.currentGeneration()
+		var startKey *string
+		for page := 0; page == 0 || startKey != nil; page++ {
+			pageItems, lastKey, err := ddb.scanEnabledItemsPage(filter, 0, startKey)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, pageItems...)
+			startKey = lastKey
+		}
+		ddb.cache.putScan(filter, items, generation)
+	}
+	return unmarshalIntegrations(items)
+}
+
+// ScanEnabledIntegrationsPage returns a page of the enabled integrations matching the filter, starting after
+// the given integration ID. At most limit integrations are read, the whole page DynamoDB returns if it's zero.
+//
+// The ID of the last integration the page read is returned to continue the scan from, it is nil once every
+// integration was read. The page is filtered after it is read, so it can be short or even empty.
+func (ddb *DDB) ScanEnabledIntegrationsPage(filter *IntegrationFilter, limit int64, exclusiveStartID *string) (
+	[]*models.SourceIntegration, *string, error) {
+
+	items, lastID, err := ddb.scanEnabledItemsPage(filter, limit, exclusiveStartID)
+	if err != nil {
+		return nil, nil, err
+	}
+	integrations, err := unmarshalIntegrations(items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return integrations, lastID, nil
+}
+
+func (ddb *DDB) scanEnabledItemsPage(filter *IntegrationFilter, limit int64, exclusiveStartID *string) (
+	[]map[string]*dynamodb.AttributeValue, *string, error) {
+
+	// Soft-deleted integrations are still enabled
+	filt := expression.Name("scanEnabled").Equal(expression.Value(true)).
+		And(expression.AttributeNotExists(expression.Name(deletedKey)))
+	if filter.IntegrationType != nil {
+		filt = expression.And(filt, expression.Name("integrationType").Equal(expression.Value(filter.IntegrationType)))
+	}
+	if filter.ScanStatus != nil {
+		filt = expression.And(filt, expression.Name("scanStatus").Equal(expression.Value(filter.ScanStatus)))
+	}
+	if filter.HealthStatus != nil {
+		filt = expression.And(filt, expression.Name("healthStatus").Equal(expression.Value(filter.HealthStatus)))
+	}
+	if filter.LabelPrefix != nil {
+		filt = expression.And(filt, expression.Name("integrationLabel").BeginsWith(*filter.LabelPrefix))
+	}
+	tagKeys := make([]string, 0, len(filter.Tags))
+	for key := range filter.Tags {
+		tagKeys = append(tagKeys, key)
+	}
+	// Sorted so that the same filter always builds the same expression
+	sort.Strings(tagKeys)
+	for _, key := range tagKeys {
+		filt = expression.And(filt, expression.Name("tags."+key).Equal(expression.Value(filter.Tags[key])))
 	}
 	expr, err := expression.NewBuilder().WithFilter(filt).Build()
 	if err != nil {
-		return nil, &genericapi.InternalError{Message: "failed to build dynamodb expression"}
+		return nil, nil, &genericapi.InternalError{Message: "failed to build dynamodb expression"}
 	}
 
-	output, err := ddb.Client.Scan(&dynamodb.ScanInput{
+	input := &dynamodb.ScanInput{
 		FilterExpression:          expr.Filter(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		TableName:                 aws.String(ddb.TableName),
-	})
+	}
+	if limit > 0 {
+		input.Limit = aws.Int64(limit)
+	}
+	if exclusiveStartID != nil {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{hashKey: {S: exclusiveStartID}}
+	}
+	output, err := ddb.Client.Scan(input)
 	if err != nil {
-		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Scan"}
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Scan"}
 	}
 
-	var enabledIntegrations []*models.SourceIntegration
-	if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &enabledIntegrations); err != nil {
+	var lastID *string
+	if key, ok := output.LastEvaluatedKey[hashKey]; ok {
+		lastID = key.S
+	}
+	return output.Items, lastID, nil
+}
+
+func unmarshalIntegrations(items []map[string]*dynamodb.AttributeValue) ([]*models.SourceIntegration, error) {
+	integrations := make([]*models.SourceIntegration, 0, len(items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, &integrations); err != nil {
 		return nil, err
 	}
+	for _, integration := range integrations {
+		deriveFields(integration)
+	}
+	return integrations, nil
+}
+
+// ScanAllIntegrations returns every integration in the table, regardless of whether it is enabled.
+//
+// The scan continues from the last evaluated key until the whole table was read.
+func (ddb *DDB) ScanAllIntegrations() ([]*models.SourceIntegration, error) {
+	expr, err := notDeletedExpression()
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.ScanInput{
+		ExpressionAttributeNames: expr.Names(),
+		FilterExpression:         expr.Filter(),
+		TableName:                aws.String(ddb.TableName),
+	}
+	var items []map[string]*dynamodb.AttributeValue
+	for {
+		output, err := ddb.Client.Scan(input)
+		if err != nil {
+			return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Scan"}
+		}
+		items = append(items, output.Items...)
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	return unmarshalIntegrations(items)
+}
+
+// ScanIntegrationsPage returns a page of at most limit integrations of the table, in no particular order.
+//
+// The key to continue the scan from is returned, it is nil once every integration was returned.
+// The limit applies before the markers of the deleted integrations are filtered out, so a page can be short.
+func (ddb *DDB) ScanIntegrationsPage(limit int64, exclusiveStartKey map[string]*dynamodb.AttributeValue) (
+	[]*models.SourceIntegration, map[string]*dynamodb.AttributeValue, error) {
 
-	if enabledIntegrations == nil {
-		enabledIntegrations = make([]*models.SourceIntegration, 0)
+	expr, err := notDeletedExpression()
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := ddb.Client.Scan(&dynamodb.ScanInput{
+		ExclusiveStartKey:        exclusiveStartKey,
+		ExpressionAttributeNames: expr.Names(),
+		FilterExpression:         expr.Filter(),
+		Limit:                    aws.Int64(limit),
+		TableName:                aws.String(ddb.TableName),
+	})
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Scan"}
+	}
+
+	integrations := make([]*models.SourceIntegration, 0, len(output.Items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &integrations); err != nil {
+		return nil, nil, err
+	}
+	for _, integration := range integrations {
+		deriveFields(integration)
+	}
+	return integrations, output.LastEvaluatedKey, nil
+}
+
+// notDeletedExpression filters out the markers the deleted integrations are replaced with.
+func notDeletedExpression() (expression.Expression, error) {
+	expr, err := expression.NewBuilder().WithFilter(expression.AttributeNotExists(expression.Name(deletedKey))).Build()
+	if err != nil {
+		return expr, &genericapi.InternalError{Message: "failed to build dynamodb expression"}
 	}
-	return enabledIntegrations, nil
+	return expr, nil
 }
@@ -0,0 +1,84 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+)
+
+// pagedScanClient returns one page of a scan per call, continuing from the start key of the request
+type pagedScanClient struct {
+	*modelstest.MockDDBClient
+	pages  [][]map[string]*dynamodb.AttributeValue
+	inputs []*dynamodb.ScanInput
+}
+
+func (client *pagedScanClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	copied := *input
+	client.inputs = append(client.inputs, &copied)
+	page := len(client.inputs) - 1
+	output := &dynamodb.ScanOutput{Items: client.pages[page]}
+	if page < len(client.pages)-1 {
+		output.LastEvaluatedKey = client.pages[page][len(client.pages[page])-1]
+	}
+	return output, nil
+}
+
+func integrationItem(id string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		hashKey:           {S: aws.String(id)},
+		"integrationType": {S: aws.String("aws-s3")},
+	}
+}
+
+func TestScanAllIntegrationsPages(t *testing.T) {
+	client := &pagedScanClient{
+		MockDDBClient: &modelstest.MockDDBClient{},
+		pages: [][]map[string]*dynamodb.AttributeValue{
+			{integrationItem("integration-1"), integrationItem("integration-2")},
+			{integrationItem("integration-3")},
+		},
+	}
+	db := &DDB{Client: client, TableName: "integrations"}
+
+	integrations, err := db.ScanAllIntegrations()
+	require.NoError(t, err)
+	require.Len(t, integrations, 3)
+	assert.Equal(t, "integration-3", *integrations[2].IntegrationID)
+
+	require.Len(t, client.inputs, 2)
+	assert.Nil(t, client.inputs[0].ExclusiveStartKey)
+	assert.Equal(t, "integration-2", *client.inputs[1].ExclusiveStartKey[hashKey].S)
+}
+
+func TestScanAllIntegrationsEmpty(t *testing.T) {
+	db := &DDB{Client: &modelstest.MockDDBClient{}, TableName: "integrations"}
+
+	integrations, err := db.ScanAllIntegrations()
+	require.NoError(t, err)
+	assert.NotNil(t, integrations)
+	assert.Empty(t, integrations)
+}
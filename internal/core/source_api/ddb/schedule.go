@@ -0,0 +1,101 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// ScheduleKey is the position of an integration in the index of the next scans.
+type ScheduleKey struct {
+	IntegrationID string `json:"integrationId"`
+	NextScanTime  string `json:"nextScanTime"`
+}
+
+// ScheduleTime is the precision the next scan times are stored with.
+//
+// The times are sorted as strings in the index, they must all have the same zone and the same number of digits.
+func ScheduleTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// ListDueForScan returns a page of the enabled integrations of a type whose next scan is due at the given time,
+// and which are not being scanned.
+//
+// The limit applies before the disabled and scanning integrations are filtered out, so a page can be short
+// or even empty. The key of the last integration read is set if there may be more, the next page starts after it.
+func (ddb *DDB) ListDueForScan(
+	integrationType string, now time.Time, limit int, exclusiveStart *ScheduleKey) ([]*models.SourceIntegration, *ScheduleKey, error) {
+
+	keyCondition := expression.Key("integrationType").Equal(expression.Value(integrationType)).
+		And(expression.Key(nextScanTimeKey).LessThanEqual(expression.Value(ScheduleTime(now))))
+	scanStatus := expression.Name("scanStatus")
+	filter := expression.Name("scanEnabled").Equal(expression.Value(true)).
+		And(expression.Or(expression.AttributeNotExists(scanStatus), scanStatus.NotEqual(expression.Value(models.StatusScanning))))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).WithFilter(filter).Build()
+	if err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to build ListDueForScan ddb expression"}
+	}
+
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		IndexName:                 aws.String(nextScanTimeIndex),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     aws.Int64(int64(limit)),
+		TableName:                 aws.String(ddb.TableName),
+	}
+	if exclusiveStart != nil {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			hashKey:           {S: aws.String(exclusiveStart.IntegrationID)},
+			"integrationType": {S: aws.String(integrationType)},
+			nextScanTimeKey:   {S: aws.String(exclusiveStart.NextScanTime)},
+		}
+	}
+
+	output, err := ddb.Client.Query(input)
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Query"}
+	}
+
+	integrations := make([]*models.SourceIntegration, 0, len(output.Items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &integrations); err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	for _, integration := range integrations {
+		deriveFields(integration)
+	}
+	var last *ScheduleKey
+	if len(output.LastEvaluatedKey) > 0 {
+		last = &ScheduleKey{
+			IntegrationID: aws.StringValue(output.LastEvaluatedKey[hashKey].S),
+			NextScanTime:  aws.StringValue(output.LastEvaluatedKey[nextScanTimeKey].S),
+		}
+	}
+	return integrations, last, nil
+}
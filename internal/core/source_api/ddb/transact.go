@@ -0,0 +1,112 @@
+package ddb
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// MaxTransactItems is the most items DynamoDB writes in a single transaction.
+const MaxTransactItems = 25
+
+// TransactUpdate is an update of an integration which is part of a transaction, made only if its condition holds.
+type TransactUpdate struct {
+	Item      *UpdateIntegrationItem
+	Condition *expression.ConditionBuilder
+}
+
+// TransactionConditionFailedError is returned when the condition of an update of a transaction does not hold.
+//
+// Nothing was written: the transaction is all-or-nothing.
+type TransactionConditionFailedError struct {
+	Err error
+	// The integrations whose condition failed, in the order of the transaction
+	IntegrationIDs []*string
+}
+
+func (e *TransactionConditionFailedError) Error() string {
+	return fmt.Sprintf("transaction canceled, the condition of %d update(s) failed: %s", len(e.IntegrationIDs), e.Err)
+}
+
+// TransactUpdateItems applies every update in a single transaction, either all of them are written or none are.
+//
+// An integration can appear only once in a transaction.
+func (ddb *DDB) TransactUpdateItems(updates []*TransactUpdate) error {
+	return ddb.transactUpdateItems(updates, ddb.now())
+}
+
+// transactUpdateItems applies the updates in a single transaction, stamped with the given time.
+func (ddb *DDB) transactUpdateItems(updates []*TransactUpdate, now time.Time) error {
+	if len(updates) > MaxTransactItems {
+		return &genericapi.InvalidInputError{
+			Message: fmt.Sprintf("a transaction updates at most %d integrations, not %d", MaxTransactItems, len(updates))}
+	}
+
+	integrationIDs := make([]*string, len(updates))
+	for i, update := range updates {
+		integrationIDs[i] = update.Item.IntegrationID
+	}
+	defer ddb.InvalidateCache(integrationIDs...)
+
+	// The updates of a transaction are stamped with the same time
+	items := make([]*dynamodb.TransactWriteItem, len(updates))
+	for i, update := range updates {
+		expr, err := updateExpression(update.Item, update.Condition, now)
+		if err != nil {
+			return err
+		}
+		items[i] = &dynamodb.TransactWriteItem{
+			Update: &dynamodb.Update{
+				ConditionExpression:       expr.Condition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+				Key: map[string]*dynamodb.AttributeValue{
+					hashKey: {S: update.Item.IntegrationID},
+				},
+				TableName:        aws.String(ddb.TableName),
+				UpdateExpression: expr.Update(),
+			},
+		}
+	}
+
+	_, err := ddb.Client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if canceled, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		// The reasons are in the order of the items, "None" for the items which were not the cause
+		failed := &TransactionConditionFailedError{Err: err}
+		for i, reason := range canceled.CancellationReasons {
+			if aws.StringValue(reason.Code) == "ConditionalCheckFailed" && i < len(updates) {
+				failed.IntegrationIDs = append(failed.IntegrationIDs, updates[i].Item.IntegrationID)
+			}
+		}
+		if len(failed.IntegrationIDs) > 0 {
+			return failed
+		}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.TransactWriteItems"}
+	}
+	return nil
+}
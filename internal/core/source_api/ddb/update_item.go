@@ -19,9 +19,12 @@ package ddb
  */
 
 import (
+	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
@@ -35,7 +38,65 @@ import (
 //
 // It inspects the input struct to identify non-nil fields, and then only updates them.
 func (ddb *DDB) UpdateItem(input *UpdateIntegrationItem) (*models.SourceIntegration, error) {
+	return ddb.updateItem(input, nil)
+}
+
+// UpdateItemWithCondition updates an item only if the condition holds for its current attributes.
+//
+// A failed condition is returned as a ConditionalCheckFailedError.
+func (ddb *DDB) UpdateItemWithCondition(
+	input *UpdateIntegrationItem, condition expression.ConditionBuilder) (*models.SourceIntegration, error) {
+
+	return ddb.updateItem(input, &condition)
+}
+
+func (ddb *DDB) updateItem(input *UpdateIntegrationItem, condition *expression.ConditionBuilder) (*models.SourceIntegration, error) {
+	defer ddb.InvalidateCache(input.IntegrationID)
+	expr, err := updateExpression(input, condition, ddb.now())
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Debug(
+		"update item input",
+		zap.String("updateExpression", *expr.Update()),
+		zap.Any("expressionAttributeNames", expr.Names()),
+		zap.Any("expressionAttributeValues", expr.Values()),
+	)
+
+	response, err := ddb.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey: {S: input.IntegrationID},
+		},
+		ReturnValues:     aws.String("ALL_NEW"),
+		TableName:        aws.String(ddb.TableName),
+		UpdateExpression: expr.Update(),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, &ConditionalCheckFailedError{Err: err}
+		}
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UpdateItem"}
+	}
+
+	var result models.SourceIntegration
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, &result); err != nil {
+		return nil, &genericapi.InternalError{Message: "update unmarshal failed: " + err.Error()}
+	}
+
+	deriveFields(&result)
+	return &result, nil
+}
+
+// updateExpression sets the non-nil fields of the input and stamps the write, under the condition if there is one.
+func updateExpression(
+	input *UpdateIntegrationItem, condition *expression.ConditionBuilder, now time.Time) (expression.Expression, error) {
+
 	var update expression.UpdateBuilder
+	var expected []expression.ConditionBuilder
 	val := reflect.ValueOf(input).Elem()
 	st := reflect.TypeOf(input).Elem()
 
@@ -55,6 +116,19 @@ func (ddb *DDB) UpdateItem(input *UpdateIntegrationItem) (*models.SourceIntegrat
 		// The update expression builds on itself, and then is added to
 		// the builder after iterating through the input struct.
 		if keyName, ok := st.Field(i).Tag.Lookup("json"); ok {
+			switch st.Field(i).Tag.Get("update") {
+			case "counter":
+				update = updateCounter(update, expression.Name(keyName), field.Elem().Bool())
+				continue
+			case "expected":
+				expected = append(expected, expectedValue(expression.Name(keyName), field.Elem()))
+				continue
+			case "removeEmpty":
+				if field.Elem().IsZero() {
+					update = update.Remove(expression.Name(keyName))
+					continue
+				}
+			}
 			switch field.Kind() {
 			case reflect.Ptr:
 				update = update.Set(
@@ -70,30 +144,136 @@ func (ddb *DDB) UpdateItem(input *UpdateIntegrationItem) (*models.SourceIntegrat
 		}
 	}
 
+	update = stampWrite(update, now)
 	builder := expression.NewBuilder().WithUpdate(update)
+	if condition != nil {
+		expected = append(expected, *condition)
+	}
+	switch len(expected) {
+	case 0:
+	case 1:
+		builder = builder.WithCondition(expected[0])
+	default:
+		builder = builder.WithCondition(expression.And(expected[0], expected[1], expected[2:]...))
+	}
 	expr, err := builder.Build()
+	if err != nil {
+		return expr, &genericapi.InternalError{Message: err.Error()}
+	}
+	return expr, nil
+}
+
+// expectedValue is the condition that an attribute still has the value, the zero value matches a missing attribute.
+func expectedValue(name expression.NameBuilder, value reflect.Value) expression.ConditionBuilder {
+	if value.IsZero() {
+		return name.AttributeNotExists()
+	}
+	return name.Equal(expression.Value(value.Interface()))
+}
+
+// updateCounter increments a numeric attribute in place, starting from 0 if it doesn't exist, or resets it.
+func updateCounter(update expression.UpdateBuilder, name expression.NameBuilder, increment bool) expression.UpdateBuilder {
+	if !increment {
+		return update.Set(name, expression.Value(0))
+	}
+	return update.Set(name, expression.Plus(expression.IfNotExists(name, expression.Value(0)), expression.Value(1)))
+}
+
+// stampWrite adds what every write must set: the version bump, so that cached copies can be detected as stale,
+// and the time of the write, which lists the integration among the changed ones.
+func stampWrite(update expression.UpdateBuilder, now time.Time) expression.UpdateBuilder {
+	return update.Add(expression.Name(versionKey), expression.Value(1)).
+		Set(expression.Name(lastModifiedKey), expression.Value(ModifiedTime(now))).
+		Set(expression.Name(modifiedFeedKey), expression.Value(modifiedFeed))
+}
+
+// RemoveAttributes deletes the given attributes from an item in the table.
+func (ddb *DDB) RemoveAttributes(integrationID *string, names ...string) (*models.SourceIntegration, error) {
+	defer ddb.InvalidateCache(integrationID)
+	var update expression.UpdateBuilder
+	for _, name := range names {
+		update = update.Remove(expression.Name(name))
+	}
+	expr, err := expression.NewBuilder().WithUpdate(stampWrite(update, ddb.now())).Build()
 	if err != nil {
 		return nil, &genericapi.InternalError{Message: err.Error()}
 	}
 
-	zap.L().Debug(
-		"update item input",
-		zap.String("updateExpression", *expr.Update()),
-		zap.Any("expressionAttributeNames", expr.Names()),
-		zap.Any("expressionAttributeValues", expr.Values()),
-	)
+	response, err := ddb.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key: map[string]*dynamodb.AttributeValue{
+			hashKey: {S: integrationID},
+		},
+		ReturnValues:     aws.String("ALL_NEW"),
+		TableName:        aws.String(ddb.TableName),
+		UpdateExpression: expr.Update(),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UpdateItem"}
+	}
+
+	var result models.SourceIntegration
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, &result); err != nil {
+		return nil, &genericapi.InternalError{Message: "update unmarshal failed: " + err.Error()}
+	}
+
+	deriveFields(&result)
+	return &result, nil
+}
+
+// RemoveFromList removes elements of list attributes, given as attribute => index => expected value.
+//
+// The update only succeeds if every index still holds its expected value, otherwise the list was
+// modified concurrently and a ConditionalCheckFailedError is returned.
+// Any other attribute paths given in removeNames are removed in the same update.
+func (ddb *DDB) RemoveFromList(
+	integrationID *string, elements map[string]map[int]string, removeNames ...string) (*models.SourceIntegration, error) {
+
+	defer ddb.InvalidateCache(integrationID)
+	var update expression.UpdateBuilder
+	var condition *expression.ConditionBuilder
+	for attribute, values := range elements {
+		for index, value := range values {
+			name := expression.Name(fmt.Sprintf("%s[%d]", attribute, index))
+			update = update.Remove(name)
+			check := name.Equal(expression.Value(value))
+			if condition == nil {
+				condition = &check
+			} else {
+				combined := condition.And(check)
+				condition = &combined
+			}
+		}
+	}
+	for _, name := range removeNames {
+		update = update.Remove(expression.Name(name))
+	}
+
+	builder := expression.NewBuilder().WithUpdate(stampWrite(update, ddb.now()))
+	if condition != nil {
+		builder = builder.WithCondition(*condition)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: err.Error()}
+	}
 
 	response, err := ddb.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		Key: map[string]*dynamodb.AttributeValue{
-			hashKey: {S: input.IntegrationID},
+			hashKey: {S: integrationID},
 		},
 		ReturnValues:     aws.String("ALL_NEW"),
 		TableName:        aws.String(ddb.TableName),
 		UpdateExpression: expr.Update(),
 	})
 	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, &ConditionalCheckFailedError{Err: err}
+		}
 		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UpdateItem"}
 	}
 
@@ -102,5 +282,6 @@ func (ddb *DDB) UpdateItem(input *UpdateIntegrationItem) (*models.SourceIntegrat
 		return nil, &genericapi.InternalError{Message: "update unmarshal failed: " + err.Error()}
 	}
 
+	deriveFields(&result)
 	return &result, nil
 }
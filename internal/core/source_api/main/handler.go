@@ -22,8 +22,11 @@ import (
 	"context"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/google/uuid"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
+	usermodels "github.com/panther-labs/panther/api/lambda/users/models"
 	"github.com/panther-labs/panther/internal/core/source_api/api"
 	"github.com/panther-labs/panther/pkg/genericapi"
 	"github.com/panther-labs/panther/pkg/lambdalogger"
@@ -36,14 +39,67 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	router = genericapi.NewRouter("cloudsec", "snapshot", validator, api.API{})
+	router = genericapi.NewRouter("cloudsec", "snapshot", validator, api.API{}).
+		RequirePermission(usermodels.PermissionSourceModify,
+			"ApplyAccountManifest", "ApplyTagPolicy", "BulkSetScanInterval", "CheckIngestionAlarms", "CloneIntegration",
+			"CompactIntegrationHistory", "CreateOrUpdateIntegration", "DeleteIntegration", "MigrateToPrefixConfig",
+			"GetPipelineSelfTest", "PurgeDeletedIntegrations",
+			"PutIntegration", "RecheckIntegrationHealth", "RecheckKmsKeyAliases", "RemoveBuckets", "RemoveKmsKeys", "ReplaceBuckets",
+			"RequeueFailedObjects", "ResetIntegrationBookmark", "RestoreIntegration", "RetryFailedSideEffects",
+			"RotateHTTPIngestKey", "RunPipelineSelfTest", "SyncOrganizations", "TransactUpdateIntegrations", "TriggerScan",
+			"UpdateIntegrationSettings", "UpdateIntegrationsBatch").
+		RequirePermission(usermodels.PermissionSourceRead,
+			"CheckIntegration", "CheckKmsKeyAliases", "CheckOnboardingReadiness", "CompareIntegrations", "EstimateScanCost",
+			"ExportAllIntegrations", "ExportAuditLog", "GenerateHealthReport", "GetAccountHealthSummary", "GetEffectiveConfig",
+			"GetIntegration", "GetIntegrationAuditLog", "GetIntegrationChangeHistory", "GetIntegrationHistory",
+			"GetIntegrationPolicyDocument", "GetIntegrationTemplate", "GetScanErrorSamples", "GetScanSchedulePreview",
+			"GetTimeToHealthyEstimate", "ListExpiringCredentials", "ListIntegrationTypes", "ListIntegrations",
+			"ListIntegrationsPage", "ListIntegrationsPages", "ListPendingRetries", "ListScanHistory", "ListSilentIntegrations",
+			"ListStaleHealthIntegrations", "PreviewIntegrationChangeSet", "PreviewMatchedObjects", "RecommendScanInterval",
+			"VerifyTrustForAccount")
 }
 
+// correlationIDKey is the client context field used by callers to propagate their correlation ID.
+const correlationIDKey = "correlationId"
+
 func lambdaHandler(ctx context.Context, request *models.LambdaInput) (interface{}, error) {
-	lambdalogger.ConfigureGlobal(ctx, nil)
+	lambdalogger.ConfigureGlobal(ctx, map[string]interface{}{correlationIDKey: correlationID(ctx)})
+	api.SetCallerIdentity(callerIdentity(ctx))
 	return router.Handle(request)
 }
 
+// callerIdentityKey is the client context field used by callers to identify themselves in the audit log.
+const callerIdentityKey = "callerIdentity"
+
+// callerIdentity returns the identity passed by the caller, falling back to its Cognito identity.
+//
+// It is empty if neither is available.
+func callerIdentity(ctx context.Context) string {
+	lc, ok := lambdacontext.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if identity := lc.ClientContext.Custom[callerIdentityKey]; identity != "" {
+		return identity
+	}
+	return lc.Identity.CognitoIdentityID
+}
+
+// correlationID returns the correlation ID passed by the caller, falling back to the Lambda request ID.
+//
+// A new ID is generated if neither is available.
+func correlationID(ctx context.Context) string {
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		if id := lc.ClientContext.Custom[correlationIDKey]; id != "" {
+			return id
+		}
+		if lc.AwsRequestID != "" {
+			return lc.AwsRequestID
+		}
+	}
+	return uuid.New().String()
+}
+
 func main() {
 	lambda.Start(lambdaHandler)
 }
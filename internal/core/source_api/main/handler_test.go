@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"testing"
 
+	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
@@ -29,3 +31,32 @@ import (
 func TestRouter(t *testing.T) {
 	assert.Nil(t, router.VerifyHandlers(&models.LambdaInput{}))
 }
+
+func TestCorrelationID(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "request-id"})
+	assert.Equal(t, "request-id", correlationID(ctx))
+
+	ctx = lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID:  "request-id",
+		ClientContext: lambdacontext.ClientContext{Custom: map[string]string{correlationIDKey: "caller-id"}},
+	})
+	assert.Equal(t, "caller-id", correlationID(ctx))
+
+	// Generated if there is no Lambda context
+	assert.Len(t, correlationID(context.Background()), 36)
+}
+
+func TestCallerIdentity(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		Identity: lambdacontext.CognitoIdentity{CognitoIdentityID: "us-west-2:identity"},
+	})
+	assert.Equal(t, "us-west-2:identity", callerIdentity(ctx))
+
+	ctx = lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		Identity:      lambdacontext.CognitoIdentity{CognitoIdentityID: "us-west-2:identity"},
+		ClientContext: lambdacontext.ClientContext{Custom: map[string]string{callerIdentityKey: "panther-appsync"}},
+	})
+	assert.Equal(t, "panther-appsync", callerIdentity(ctx))
+
+	assert.Empty(t, callerIdentity(context.Background()))
+}
@@ -0,0 +1,140 @@
+package authorizer
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/core/tokens_api/table"
+)
+
+const (
+	// The stage variable naming the API in the scopes of the tokens, e.g. "analysis"
+	serviceVariable = "service"
+
+	bearerPrefix = "Bearer "
+)
+
+var (
+	awsSession                   = session.Must(session.NewSession())
+	tokensTable table.API        = table.New(os.Getenv("TOKENS_TABLE_NAME"), awsSession)
+	now         func() time.Time = time.Now
+
+	// API Gateway responds with a 401 to this exact error message
+	errUnauthorized = errors.New("Unauthorized") //nolint:stylecheck
+
+	// The methods allowed by each access of a scope
+	scopeMethods = map[string]string{
+		"read":  "GET",
+		"write": "*",
+	}
+)
+
+// Authorize validates the API token in the Authorization header of a request to a token-authorized API.
+//
+// The policy allows all the methods of the API the scopes of the token grant, API Gateway caches it for the token.
+func Authorize(event *events.APIGatewayCustomAuthorizerRequestTypeRequest) (*events.APIGatewayCustomAuthorizerResponse, error) {
+	token := bearerToken(event.Headers)
+	id, secret, ok := table.ParseToken(token)
+	if !ok {
+		return nil, errUnauthorized
+	}
+
+	item, err := tokensTable.Get(&id)
+	if err != nil {
+		zap.L().Error("failed to get token", zap.String("tokenId", id), zap.Error(err))
+		return nil, err
+	}
+	if item == nil || !item.VerifySecret(secret) {
+		zap.L().Warn("invalid token", zap.String("tokenId", id))
+		return nil, errUnauthorized
+	}
+	currentTime := now().UTC()
+	if item.RevokedAt != nil || (item.ExpiresAt != nil && currentTime.After(*item.ExpiresAt)) {
+		zap.L().Info("revoked or expired token", zap.String("tokenId", id))
+		return nil, errUnauthorized
+	}
+
+	// The usage is tracked at the pace of the cache of the authorizer, a failure doesn't deny the request
+	if err := tokensTable.Touch(&id, currentTime); err != nil {
+		zap.L().Warn("failed to track token usage", zap.String("tokenId", id), zap.Error(err))
+	}
+
+	return policy(event, item), nil
+}
+
+// Allow the methods the scopes of the token grant on the API, or deny the request if none of them applies
+func policy(event *events.APIGatewayCustomAuthorizerRequestTypeRequest, item *table.TokenItem) *events.APIGatewayCustomAuthorizerResponse {
+	service := event.StageVariables[serviceVariable]
+	var resources []string
+	for _, scope := range aws.StringValueSlice(item.Scopes) {
+		parts := strings.SplitN(scope, ":", 2)
+		if len(parts) != 2 || parts[0] != service {
+			continue
+		}
+		if method, ok := scopeMethods[parts[1]]; ok {
+			resources = append(resources, methodResource(event.MethodArn, method))
+		}
+	}
+
+	statement := events.IAMPolicyStatement{Action: []string{"execute-api:Invoke"}, Effect: "Allow", Resource: resources}
+	if len(resources) == 0 {
+		statement.Effect = "Deny"
+		statement.Resource = []string{event.MethodArn}
+	}
+	return &events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: *item.ID,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version:   "2012-10-17",
+			Statement: []events.IAMPolicyStatement{statement},
+		},
+		Context: map[string]interface{}{
+			"tokenId": *item.ID,
+			"userId":  aws.StringValue(item.CreatedBy),
+		},
+	}
+}
+
+// The Authorization header, the names of the headers are case-insensitive
+func bearerToken(headers map[string]string) string {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Authorization") && strings.HasPrefix(value, bearerPrefix) {
+			return strings.TrimSpace(strings.TrimPrefix(value, bearerPrefix))
+		}
+	}
+	return ""
+}
+
+// The resource of all the paths of a method, from the ARN of the method being called:
+// arn:aws:execute-api:{region}:{account}:{api}/{stage}/{method}/{path}
+func methodResource(methodArn string, method string) string {
+	parts := strings.SplitN(methodArn, "/", 3)
+	if len(parts) < 2 {
+		return methodArn
+	}
+	return parts[0] + "/" + parts[1] + "/" + method + "/*"
+}
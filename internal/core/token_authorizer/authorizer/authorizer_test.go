@@ -0,0 +1,171 @@
+package authorizer
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/tokens/models"
+	"github.com/panther-labs/panther/internal/core/tokens_api/table"
+)
+
+type mockTable struct {
+	table.API
+	mock.Mock
+}
+
+func (m *mockTable) Get(id *string) (*table.TokenItem, error) {
+	args := m.Called(id)
+	return args.Get(0).(*table.TokenItem), args.Error(1)
+}
+
+func (m *mockTable) Touch(id *string, now time.Time) error {
+	args := m.Called(id, now)
+	return args.Error(0)
+}
+
+const (
+	testTokenID = "39c78f99-0149-482e-8f7b-090d75f253bf"
+	methodArn   = "arn:aws:execute-api:us-west-2:123456789012:abcdef1234/v1/POST/upload"
+)
+
+var testNow = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// A stored token with the given scopes, and the header authorizing requests with it
+func testToken(t *testing.T, scopes ...string) (*table.TokenItem, map[string]string) {
+	token, secretHash, err := table.NewSecret(testTokenID)
+	require.NoError(t, err)
+	item := &table.TokenItem{
+		TokenInfo: models.TokenInfo{
+			ID:        aws.String(testTokenID),
+			Scopes:    aws.StringSlice(scopes),
+			CreatedBy: aws.String("user"),
+		},
+		SecretHash: &secretHash,
+	}
+	return item, map[string]string{"authorization": "Bearer " + token}
+}
+
+func request(headers map[string]string) *events.APIGatewayCustomAuthorizerRequestTypeRequest {
+	return &events.APIGatewayCustomAuthorizerRequestTypeRequest{
+		Headers:        headers,
+		MethodArn:      methodArn,
+		StageVariables: map[string]string{"service": "analysis"},
+	}
+}
+
+func TestAuthorizeWrite(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	now = func() time.Time { return testNow }
+	item, headers := testToken(t, models.ScopeAnalysisWrite, models.ScopeComplianceRead)
+	mockTokens.On("Get", aws.String(testTokenID)).Return(item, nil)
+	mockTokens.On("Touch", aws.String(testTokenID), testNow).Return(nil)
+
+	result, err := Authorize(request(headers))
+	require.NoError(t, err)
+	mockTokens.AssertExpectations(t)
+	assert.Equal(t, testTokenID, result.PrincipalID)
+	assert.Equal(t, "Allow", result.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, []string{"arn:aws:execute-api:us-west-2:123456789012:abcdef1234/v1/*/*"},
+		result.PolicyDocument.Statement[0].Resource)
+	assert.Equal(t, "user", result.Context["userId"])
+}
+
+func TestAuthorizeReadOnly(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	now = func() time.Time { return testNow }
+	item, headers := testToken(t, models.ScopeAnalysisRead)
+	mockTokens.On("Get", aws.String(testTokenID)).Return(item, nil)
+	mockTokens.On("Touch", aws.String(testTokenID), testNow).Return(nil)
+
+	result, err := Authorize(request(headers))
+	require.NoError(t, err)
+	assert.Equal(t, "Allow", result.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, []string{"arn:aws:execute-api:us-west-2:123456789012:abcdef1234/v1/GET/*"},
+		result.PolicyDocument.Statement[0].Resource)
+}
+
+func TestAuthorizeOtherService(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	now = func() time.Time { return testNow }
+	item, headers := testToken(t, models.ScopeComplianceRead)
+	mockTokens.On("Get", aws.String(testTokenID)).Return(item, nil)
+	mockTokens.On("Touch", aws.String(testTokenID), testNow).Return(nil)
+
+	result, err := Authorize(request(headers))
+	require.NoError(t, err)
+	assert.Equal(t, "Deny", result.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, []string{methodArn}, result.PolicyDocument.Statement[0].Resource)
+}
+
+func TestAuthorizeRevoked(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	now = func() time.Time { return testNow }
+	item, headers := testToken(t, models.ScopeAnalysisWrite)
+	item.RevokedAt = &testNow
+	mockTokens.On("Get", aws.String(testTokenID)).Return(item, nil)
+
+	result, err := Authorize(request(headers))
+	assert.Nil(t, result)
+	assert.Equal(t, errUnauthorized, err)
+	mockTokens.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything)
+}
+
+func TestAuthorizeExpired(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	now = func() time.Time { return testNow }
+	item, headers := testToken(t, models.ScopeAnalysisWrite)
+	expiresAt := testNow.Add(-time.Minute)
+	item.ExpiresAt = &expiresAt
+	mockTokens.On("Get", aws.String(testTokenID)).Return(item, nil)
+
+	_, err := Authorize(request(headers))
+	assert.Equal(t, errUnauthorized, err)
+}
+
+func TestAuthorizeWrongSecret(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	item, _ := testToken(t, models.ScopeAnalysisWrite)
+	mockTokens.On("Get", aws.String(testTokenID)).Return(item, nil)
+
+	_, err := Authorize(request(map[string]string{"Authorization": "Bearer " + testTokenID + ".secret"}))
+	assert.Equal(t, errUnauthorized, err)
+}
+
+func TestAuthorizeMissingHeader(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+
+	_, err := Authorize(request(map[string]string{}))
+	assert.Equal(t, errUnauthorized, err)
+	mockTokens.AssertNotCalled(t, "Get", mock.Anything)
+}
@@ -0,0 +1,37 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/panther-labs/panther/internal/core/tokens_api/table"
+)
+
+var (
+	awsSession                   = session.Must(session.NewSession())
+	tokensTable table.API        = table.New(os.Getenv("TOKENS_TABLE_NAME"), awsSession)
+	now         func() time.Time = time.Now
+)
+
+// API has all of the handlers as receiver methods.
+type API struct{}
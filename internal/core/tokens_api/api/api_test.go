@@ -0,0 +1,86 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/tokens/models"
+	"github.com/panther-labs/panther/internal/core/tokens_api/table"
+)
+
+type mockTable struct {
+	table.API
+	mock.Mock
+}
+
+func (m *mockTable) List() ([]*models.TokenInfo, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.TokenInfo), args.Error(1)
+}
+
+func (m *mockTable) Put(item *table.TokenItem) error {
+	args := m.Called(item)
+	return args.Error(0)
+}
+
+var testNow = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestCreateToken(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	now = func() time.Time { return testNow }
+	mockTokens.On("Put", mock.Anything).Return(nil)
+
+	result, err := (API{}).CreateToken(&models.CreateTokenInput{
+		Name:          aws.String("ci"),
+		Scopes:        aws.StringSlice([]string{models.ScopeAnalysisWrite, models.ScopeAnalysisWrite}),
+		UserID:        aws.String("39c78f99-0149-482e-8f7b-090d75f253bf"),
+		ExpiresInDays: aws.Int64(30),
+	})
+	require.NoError(t, err)
+	mockTokens.AssertExpectations(t)
+
+	assert.Equal(t, []string{models.ScopeAnalysisWrite}, aws.StringValueSlice(result.Detail.Scopes))
+	assert.Equal(t, testNow.Add(30*24*time.Hour), *result.Detail.ExpiresAt)
+
+	// The stored item has the hash of the returned token, not the token itself
+	item := mockTokens.Calls[0].Arguments.Get(0).(*table.TokenItem)
+	id, secret, ok := table.ParseToken(*result.Token)
+	require.True(t, ok)
+	assert.Equal(t, *item.ID, id)
+	assert.True(t, item.VerifySecret(secret))
+	assert.NotContains(t, *item.SecretHash, secret)
+}
+
+func TestListTokensEmpty(t *testing.T) {
+	mockTokens := &mockTable{}
+	tokensTable = mockTokens
+	mockTokens.On("List").Return(([]*models.TokenInfo)(nil), nil)
+
+	result, err := (API{}).ListTokens(&models.ListTokensInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TokenInfo{}, result.Tokens)
+}
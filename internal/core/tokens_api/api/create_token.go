@@ -0,0 +1,74 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/tokens/models"
+	"github.com/panther-labs/panther/internal/core/tokens_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// CreateToken generates a new API token, the token is only returned once.
+func (API) CreateToken(input *models.CreateTokenInput) (*models.CreateTokenOutput, error) {
+	id := uuid.New().String()
+	token, secretHash, err := table.NewSecret(id)
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to generate token secret: " + err.Error()}
+	}
+
+	createdAt := now().UTC()
+	item := &table.TokenItem{
+		TokenInfo: models.TokenInfo{
+			ID:        aws.String(id),
+			Name:      input.Name,
+			Scopes:    uniqueScopes(input.Scopes),
+			CreatedAt: &createdAt,
+			CreatedBy: input.UserID,
+		},
+		SecretHash: aws.String(secretHash),
+	}
+	if input.ExpiresInDays != nil {
+		expiresAt := createdAt.Add(time.Duration(*input.ExpiresInDays) * 24 * time.Hour)
+		item.ExpiresAt = &expiresAt
+	}
+
+	if err := tokensTable.Put(item); err != nil {
+		zap.L().Error("error storing token", zap.Error(err))
+		return nil, err
+	}
+	return &models.CreateTokenOutput{Token: aws.String(token), Detail: &item.TokenInfo}, nil
+}
+
+func uniqueScopes(scopes []*string) []*string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]*string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[*scope] {
+			seen[*scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/tokens/models"
+)
+
+// RevokeToken revokes an API token.
+//
+// The token authorizer caches its decisions, a revoked token may authorize requests for a few more minutes.
+func (API) RevokeToken(input *models.RevokeTokenInput) (*models.RevokeTokenOutput, error) {
+	result, err := tokensTable.Revoke(input.ID, input.UserID, now().UTC())
+	if err != nil {
+		zap.L().Error("error revoking token", zap.Error(err))
+		return nil, err
+	}
+	return result, nil
+}
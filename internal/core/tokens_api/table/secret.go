@@ -0,0 +1,63 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// The tokens are the token ID and its secret, as <id>.<secret>
+const secretSeparator = "."
+
+// NewSecret generates a random secret for a new token, returning the token and the hash to store.
+func NewSecret(id string) (token string, secretHash string, err error) {
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	encoded := hex.EncodeToString(secret)
+	return id + secretSeparator + encoded, hashSecret(encoded), nil
+}
+
+// ParseToken splits a token into its ID and secret, ok is false if it isn't formatted as a token.
+func ParseToken(token string) (id string, secret string, ok bool) {
+	parts := strings.SplitN(token, secretSeparator, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// VerifySecret checks the secret of a token against the stored hash.
+func (item *TokenItem) VerifySecret(secret string) bool {
+	if item.SecretHash == nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(*item.SecretHash)) == 1
+}
+
+// The secrets are random, a plain hash is enough to not store them
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
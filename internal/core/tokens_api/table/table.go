@@ -0,0 +1,66 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/panther-labs/panther/api/lambda/tokens/models"
+)
+
+// API defines the interface for the table which can be used for mocking.
+type API interface {
+	Get(id *string) (*TokenItem, error)
+	List() ([]*models.TokenInfo, error)
+	Put(item *TokenItem) error
+	Revoke(id *string, userID *string, now time.Time) (*models.TokenInfo, error)
+	Touch(id *string, now time.Time) error
+}
+
+// TokensTable encapsulates a connection to the Dynamo table.
+type TokensTable struct {
+	Name   *string
+	client dynamodbiface.DynamoDBAPI
+}
+
+// The TokensTable must satisfy the API interface.
+var _ API = (*TokensTable)(nil)
+
+// New creates a new Dynamo client which talks to the given table name.
+func New(tableName string, sess *session.Session) *TokensTable {
+	return &TokensTable{Name: aws.String(tableName), client: dynamodb.New(sess)}
+}
+
+// TokenItem is the token stored in the table, the secret itself is never stored.
+type TokenItem struct {
+	models.TokenInfo
+	SecretHash *string `json:"secretHash"`
+}
+
+// DynamoItem is a type alias for the item format expected by the Dynamo SDK.
+type DynamoItem = map[string]*dynamodb.AttributeValue
+
+func tokenKey(id *string) DynamoItem {
+	return DynamoItem{"id": {S: id}}
+}
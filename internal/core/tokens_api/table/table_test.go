@@ -0,0 +1,142 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/tokens/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) ScanPages(input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool) error {
+	args := m.Called(input)
+	fn(args.Get(0).(*dynamodb.ScanOutput), true)
+	return args.Error(1)
+}
+
+func (m *mockDynamoClient) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
+func TestNew(t *testing.T) {
+	assert.NotNil(t, New("table", session.Must(session.NewSession())))
+}
+
+func testItem(t *testing.T, id string, createdAt time.Time) DynamoItem {
+	item, err := dynamodbattribute.MarshalMap(&TokenItem{
+		TokenInfo: models.TokenInfo{
+			ID:        aws.String(id),
+			Name:      aws.String("ci"),
+			Scopes:    aws.StringSlice([]string{models.ScopeAnalysisWrite}),
+			CreatedAt: &createdAt,
+		},
+		SecretHash: aws.String("hash"),
+	})
+	require.NoError(t, err)
+	return item
+}
+
+func TestGetMissing(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+	table := &TokensTable{client: mockClient, Name: aws.String("test-table")}
+
+	result, err := table.Get(aws.String("id"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestGet(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("GetItem", mock.Anything).Return(
+		&dynamodb.GetItemOutput{Item: testItem(t, "id", time.Now())}, nil)
+	table := &TokensTable{client: mockClient, Name: aws.String("test-table")}
+
+	result, err := table.Get(aws.String("id"))
+	require.NoError(t, err)
+	assert.Equal(t, "hash", *result.SecretHash)
+	assert.Equal(t, "ci", *result.Name)
+}
+
+func TestListSorted(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	now := time.Now()
+	mockClient.On("ScanPages", mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []DynamoItem{testItem(t, "new", now), testItem(t, "old", now.Add(-time.Hour))},
+	}, nil)
+	table := &TokensTable{client: mockClient, Name: aws.String("test-table")}
+
+	result, err := table.List()
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "old", *result[0].ID)
+	assert.Equal(t, "new", *result[1].ID)
+}
+
+func TestRevokeDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("UpdateItem", mock.Anything).Return(
+		(*dynamodb.UpdateItemOutput)(nil),
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "not found", nil))
+	table := &TokensTable{client: mockClient, Name: aws.String("test-table")}
+
+	result, err := table.Revoke(aws.String("id"), aws.String("user"), time.Now())
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestSecret(t *testing.T) {
+	token, secretHash, err := NewSecret("39c78f99-0149-482e-8f7b-090d75f253bf")
+	require.NoError(t, err)
+
+	id, secret, ok := ParseToken(token)
+	require.True(t, ok)
+	assert.Equal(t, "39c78f99-0149-482e-8f7b-090d75f253bf", id)
+
+	item := &TokenItem{SecretHash: &secretHash}
+	assert.True(t, item.VerifySecret(secret))
+	assert.False(t, item.VerifySecret(secret+"0"))
+	assert.False(t, (&TokenItem{}).VerifySecret(secret))
+
+	_, _, ok = ParseToken("not-a-token")
+	assert.False(t, ok)
+}
@@ -0,0 +1,147 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/tokens/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// Get retrieves a token, nil if it doesn't exist.
+func (table *TokensTable) Get(id *string) (*TokenItem, error) {
+	response, err := table.client.GetItem(&dynamodb.GetItemInput{Key: tokenKey(id), TableName: table.Name})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.GetItem", Err: err}
+	}
+	if len(response.Item) == 0 {
+		return nil, nil
+	}
+
+	var item TokenItem
+	if err = dynamodbattribute.UnmarshalMap(response.Item, &item); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo item to TokenItem: " + err.Error()}
+	}
+	return &item, nil
+}
+
+// List returns all the tokens, oldest first.
+func (table *TokensTable) List() ([]*models.TokenInfo, error) {
+	var result []*models.TokenInfo
+	var unmarshalErr error
+	err := table.client.ScanPages(&dynamodb.ScanInput{TableName: table.Name},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var items []*TokenItem
+			if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+				return false // stop paginating
+			}
+			for _, item := range items {
+				info := item.TokenInfo
+				result = append(result, &info)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.Scan", Err: err}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo items: " + unmarshalErr.Error()}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(*result[j].CreatedAt) })
+	return result, nil
+}
+
+// Put stores a new token.
+func (table *TokensTable) Put(item *TokenItem) error {
+	dynamoItem, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal TokenItem to dynamo item: " + err.Error()}
+	}
+
+	zap.L().Debug("storing new token", zap.String("id", *item.ID))
+	_, err = table.client.PutItem(&dynamodb.PutItemInput{Item: dynamoItem, TableName: table.Name})
+	if err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
+
+// Revoke marks a token as revoked, the token stays listed.
+func (table *TokensTable) Revoke(id *string, userID *string, now time.Time) (*models.TokenInfo, error) {
+	update := expression.Set(expression.Name("revokedAt"), expression.Value(now)).
+		Set(expression.Name("revokedBy"), expression.Value(userID))
+	condition := expression.AttributeExists(expression.Name("id"))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to build update expression: " + err.Error()}
+	}
+
+	response, err := table.client.UpdateItem(&dynamodb.UpdateItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       tokenKey(id),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+		TableName:                 table.Name,
+		UpdateExpression:          expr.Update(),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil, &genericapi.DoesNotExistError{Message: "token " + *id + " does not exist"}
+	}
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.UpdateItem", Err: err}
+	}
+
+	var item TokenItem
+	if err = dynamodbattribute.UnmarshalMap(response.Attributes, &item); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo item to TokenItem: " + err.Error()}
+	}
+	return &item.TokenInfo, nil
+}
+
+// Touch records the last time a token authorized a request.
+func (table *TokensTable) Touch(id *string, now time.Time) error {
+	update := expression.Set(expression.Name("lastUsedAt"), expression.Value(now))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build update expression: " + err.Error()}
+	}
+
+	_, err = table.client.UpdateItem(&dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       tokenKey(id),
+		TableName:                 table.Name,
+		UpdateExpression:          expr.Update(),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.UpdateItem", Err: err}
+	}
+	return nil
+}
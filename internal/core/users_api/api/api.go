@@ -20,9 +20,12 @@ package api
  */
 
 import (
+	"os"
+
 	"github.com/aws/aws-sdk-go/aws/session"
 
 	"github.com/panther-labs/panther/internal/core/users_api/gateway"
+	"github.com/panther-labs/panther/internal/core/users_api/table"
 )
 
 // The API has receiver methods for each of the handlers.
@@ -31,4 +34,5 @@ type API struct{}
 var (
 	awsSession              = session.Must(session.NewSession())
 	userGateway gateway.API = gateway.New(awsSession)
+	rolesTable  table.API   = table.New(os.Getenv("ROLES_TABLE_NAME"), awsSession)
 )
@@ -0,0 +1,62 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+)
+
+// GetIdentityProvider returns the single sign-on identity provider of the users.
+func (API) GetIdentityProvider(_ *models.GetIdentityProviderInput) (*models.GetIdentityProviderOutput, error) {
+	result, err := userGateway.GetIdentityProvider()
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return &models.IdentityProvider{}, nil
+	}
+	return result, nil
+}
+
+// PutIdentityProvider configures single sign-on with a SAML or OIDC identity provider.
+func (API) PutIdentityProvider(input *models.PutIdentityProviderInput) (*models.PutIdentityProviderOutput, error) {
+	if input.DefaultRole != nil {
+		if err := validateRole(input.DefaultRole); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := userGateway.PutIdentityProvider(input)
+	if err != nil {
+		zap.L().Error("error configuring identity provider", zap.Error(err))
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveIdentityProvider turns off single sign-on.
+func (API) RemoveIdentityProvider(_ *models.RemoveIdentityProviderInput) error {
+	if err := userGateway.DeleteIdentityProvider(); err != nil {
+		zap.L().Error("error removing identity provider", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/users_api/gateway"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestGetIdentityProviderNone(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockGateway.On("GetIdentityProvider").Return((*models.IdentityProvider)(nil), nil)
+
+	result, err := (API{}).GetIdentityProvider(&models.GetIdentityProviderInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.IdentityProvider{}, result)
+	mockGateway.AssertExpectations(t)
+}
+
+func TestPutIdentityProvider(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	input := &models.PutIdentityProviderInput{
+		Name:        aws.String("Okta"),
+		Type:        aws.String("SAML"),
+		MetadataURL: aws.String("https://example.okta.com/app/exk1/sso/saml/metadata"),
+		DefaultRole: aws.String("Analyst"),
+	}
+	expected := &models.IdentityProvider{Name: input.Name, Type: input.Type, DefaultRole: input.DefaultRole}
+	mockGateway.On("PutIdentityProvider", input).Return(expected, nil)
+
+	result, err := (API{}).PutIdentityProvider(input)
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockGateway.AssertExpectations(t)
+}
+
+func TestRemoveIdentityProviderGatewayErr(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockGateway.On("DeleteIdentityProvider").Return(&genericapi.AWSError{})
+
+	assert.Error(t, (API{}).RemoveIdentityProvider(&models.RemoveIdentityProviderInput{}))
+	mockGateway.AssertExpectations(t)
+}
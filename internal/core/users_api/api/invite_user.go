@@ -19,16 +19,35 @@ package api
  */
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
 	"github.com/panther-labs/panther/api/lambda/users/models"
 )
 
 // InviteUser adds a new user to the Cognito user pool.
 func (API) InviteUser(input *models.InviteUserInput) (*models.InviteUserOutput, error) {
+	role := input.Role
+	if role == nil {
+		role = aws.String(models.RoleReadOnly)
+	}
+	if err := validateRole(role); err != nil {
+		return nil, err
+	}
+
 	// Create user in Cognito
 	id, err := userGateway.CreateUser(input)
 	if err != nil {
 		return nil, err
 	}
+	if err = userGateway.SetUserRole(id, role); err != nil {
+		// A user without a role has no permission, don't leave one behind
+		if deleteErr := userGateway.DeleteUser(id); deleteErr != nil {
+			zap.L().Error("failed to delete the user without a role",
+				zap.String("userId", *id), zap.Error(deleteErr))
+		}
+		return nil, err
+	}
 
 	return &models.InviteUserOutput{ID: id}, nil
 }
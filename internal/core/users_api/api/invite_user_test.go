@@ -23,6 +23,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/panther-labs/panther/api/lambda/users/models"
 	"github.com/panther-labs/panther/internal/core/users_api/gateway"
@@ -82,6 +83,7 @@ func TestInviteUserHandle(t *testing.T) {
 
 	// setup gateway expectations
 	mockGateway.On("CreateUser", input).Return(userID, nil)
+	mockGateway.On("SetUserRole", userID, aws.String(models.RoleReadOnly)).Return(nil)
 
 	// call the code we are testing
 	result, err := (API{}).InviteUser(input)
@@ -94,3 +96,37 @@ func TestInviteUserHandle(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+func TestInviteUserSetRoleErr(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+
+	mockGateway.On("CreateUser", input).Return(userID, nil)
+	mockGateway.On("SetUserRole", userID, aws.String(models.RoleReadOnly)).Return(&genericapi.AWSError{})
+	mockGateway.On("DeleteUser", userID).Return(nil)
+
+	result, err := (API{}).InviteUser(input)
+
+	// The user is deleted rather than left without a role
+	mockGateway.AssertExpectations(t)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+}
+
+func TestInviteUserMissingRole(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("Get", aws.String("Triage")).Return((*models.Role)(nil), nil)
+
+	result, err := (API{}).InviteUser(&models.InviteUserInput{
+		GivenName:  aws.String("Joe"),
+		Email:      aws.String("joe.blow@panther.io"),
+		FamilyName: aws.String("Blow"),
+		Role:       aws.String("Triage"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockGateway.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
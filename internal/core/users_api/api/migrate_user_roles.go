@@ -0,0 +1,60 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+)
+
+const userRolesMigration = "userRoles"
+
+// MigrateUserRoles gives the Admin role to the users without a role, once.
+//
+// It runs after the deployment which introduces the roles. A user later left without a role, e.g. because their
+// provisioning by single sign-on failed, has no permission rather than every permission.
+func (API) MigrateUserRoles(*models.MigrateUserRolesInput) (*models.MigrateUserRolesOutput, error) {
+	result := &models.MigrateUserRolesOutput{MigratedUsers: []*string{}}
+	done, err := rolesTable.MigrationDone(userRolesMigration)
+	if err != nil || done {
+		return result, err
+	}
+
+	users, err := userGateway.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.Role != nil {
+			continue
+		}
+		zap.L().Info("giving the Admin role to a user without a role", zap.String("userId", *user.ID))
+		if err = userGateway.SetUserRole(user.ID, aws.String(models.RoleAdmin)); err != nil {
+			return nil, err // the migration isn't recorded, it runs again
+		}
+		result.MigratedUsers = append(result.MigratedUsers, user.ID)
+	}
+
+	if err = rolesTable.RecordMigration(userRolesMigration); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/users_api/gateway"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestMigrateUserRoles(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+
+	mockTable.On("MigrationDone", userRolesMigration).Return(false, nil)
+	mockGateway.On("ListUsers").Return([]*models.User{
+		{ID: aws.String("user-1")},
+		{ID: aws.String("user-2"), Role: aws.String(models.RoleAnalyst)},
+	}, nil)
+	mockGateway.On("SetUserRole", aws.String("user-1"), aws.String(models.RoleAdmin)).Return(nil)
+	mockTable.On("RecordMigration", userRolesMigration).Return(nil)
+
+	result, err := (API{}).MigrateUserRoles(&models.MigrateUserRolesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, aws.StringSlice([]string{"user-1"}), result.MigratedUsers)
+	mockGateway.AssertExpectations(t)
+	mockTable.AssertExpectations(t)
+}
+
+func TestMigrateUserRolesDone(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("MigrationDone", userRolesMigration).Return(true, nil)
+
+	// The users left without a role since then have no permission
+	result, err := (API{}).MigrateUserRoles(&models.MigrateUserRolesInput{})
+	require.NoError(t, err)
+	assert.Empty(t, result.MigratedUsers)
+	mockGateway.AssertNotCalled(t, "ListUsers")
+	mockGateway.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything)
+}
+
+func TestMigrateUserRolesSetRoleErr(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+
+	mockTable.On("MigrationDone", userRolesMigration).Return(false, nil)
+	mockGateway.On("ListUsers").Return([]*models.User{{ID: aws.String("user-1")}}, nil)
+	mockGateway.On("SetUserRole", aws.String("user-1"), aws.String(models.RoleAdmin)).Return(&genericapi.AWSError{})
+
+	// The migration isn't recorded, the next deployment runs it again
+	result, err := (API{}).MigrateUserRoles(&models.MigrateUserRolesInput{})
+	assert.Nil(t, result)
+	assert.Error(t, err)
+	mockTable.AssertNotCalled(t, "RecordMigration", mock.Anything)
+}
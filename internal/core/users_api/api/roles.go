@@ -0,0 +1,125 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/users_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// DeleteRole deletes a custom role which no user has.
+func (API) DeleteRole(input *models.DeleteRoleInput) error {
+	if models.BuiltInRole(*input.Name) != nil {
+		return &genericapi.InvalidInputError{Message: "built-in role " + *input.Name + " can't be deleted"}
+	}
+	role, err := rolesTable.Get(input.Name)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return &genericapi.DoesNotExistError{Message: "role " + *input.Name + " does not exist"}
+	}
+
+	users, err := userGateway.ListRoleUsers(input.Name)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return &genericapi.InUseError{Message: fmt.Sprintf("role %s is assigned to %d users", *input.Name, len(users))}
+	}
+	identityProvider, err := userGateway.GetIdentityProvider()
+	if err != nil {
+		return err
+	}
+	if identityProvider != nil && aws.StringValue(identityProvider.DefaultRole) == *input.Name {
+		return &genericapi.InUseError{Message: "role " + *input.Name + " is the default role of single sign-on"}
+	}
+
+	if err = userGateway.DeleteRoleGroup(input.Name); err != nil {
+		return err
+	}
+	return rolesTable.Delete(input.Name)
+}
+
+// ListRoles lists the built-in roles, then the custom ones.
+func (API) ListRoles(*models.ListRolesInput) (*models.ListRolesOutput, error) {
+	custom, err := rolesTable.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*models.Role{
+		models.BuiltInRole(models.RoleAdmin),
+		models.BuiltInRole(models.RoleAnalyst),
+		models.BuiltInRole(models.RoleReadOnly),
+	}
+	return &models.ListRolesOutput{Roles: append(result, custom...)}, nil
+}
+
+// PutRole creates or replaces a custom role.
+func (API) PutRole(input *models.PutRoleInput) (*models.PutRoleOutput, error) {
+	if models.BuiltInRole(*input.Name) != nil {
+		return nil, &genericapi.InvalidInputError{Message: "built-in role " + *input.Name + " can't be modified"}
+	}
+
+	role := &models.Role{
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: uniquePermissions(input.Permissions),
+		BuiltIn:     aws.Bool(false),
+	}
+	if err := userGateway.CreateRoleGroup(role.Name); err != nil {
+		return nil, err
+	}
+	if err := rolesTable.Put(role); err != nil {
+		zap.L().Error("error storing role", zap.Error(err))
+		return nil, err
+	}
+	return role, nil
+}
+
+// Returns an InvalidInputError if the role doesn't exist
+func validateRole(name *string) error {
+	role, err := table.FindRole(rolesTable, name)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return &genericapi.InvalidInputError{Message: "role " + *name + " does not exist"}
+	}
+	return nil
+}
+
+func uniquePermissions(permissions []*string) []*string {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]*string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !seen[*permission] {
+			seen[*permission] = true
+			result = append(result, permission)
+		}
+	}
+	return result
+}
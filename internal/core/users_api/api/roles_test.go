@@ -0,0 +1,157 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/users_api/gateway"
+	"github.com/panther-labs/panther/internal/core/users_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockRolesTable struct {
+	table.API
+	mock.Mock
+}
+
+func (m *mockRolesTable) Delete(name *string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *mockRolesTable) Get(name *string) (*models.Role, error) {
+	args := m.Called(name)
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *mockRolesTable) List() ([]*models.Role, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.Role), args.Error(1)
+}
+
+func (m *mockRolesTable) MigrationDone(name string) (bool, error) {
+	args := m.Called(name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRolesTable) RecordMigration(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *mockRolesTable) Put(role *models.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+var customRole = &models.Role{
+	Name:        aws.String("Triage"),
+	Permissions: aws.StringSlice([]string{models.PermissionAlertRead, models.PermissionAlertModify}),
+	BuiltIn:     aws.Bool(false),
+}
+
+func TestListRoles(t *testing.T) {
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("List").Return([]*models.Role{customRole}, nil)
+
+	result, err := (API{}).ListRoles(&models.ListRolesInput{})
+	require.NoError(t, err)
+	require.Len(t, result.Roles, 4)
+	assert.Equal(t, models.RoleAdmin, *result.Roles[0].Name)
+	assert.Equal(t, customRole, result.Roles[3])
+}
+
+func TestPutRole(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockGateway.On("CreateRoleGroup", customRole.Name).Return(nil)
+	mockTable.On("Put", customRole).Return(nil)
+
+	result, err := (API{}).PutRole(&models.PutRoleInput{
+		Name: aws.String("Triage"),
+		Permissions: aws.StringSlice(
+			[]string{models.PermissionAlertRead, models.PermissionAlertModify, models.PermissionAlertRead}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, customRole, result)
+	mockGateway.AssertExpectations(t)
+	mockTable.AssertExpectations(t)
+}
+
+func TestPutRoleBuiltIn(t *testing.T) {
+	result, err := (API{}).PutRole(&models.PutRoleInput{
+		Name:        aws.String(models.RoleAnalyst),
+		Permissions: aws.StringSlice([]string{models.PermissionUserModify}),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestDeleteRole(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("Get", customRole.Name).Return(customRole, nil)
+	mockGateway.On("ListRoleUsers", customRole.Name).Return([]*string{}, nil)
+	mockGateway.On("GetIdentityProvider").Return((*models.IdentityProvider)(nil), nil)
+	mockGateway.On("DeleteRoleGroup", customRole.Name).Return(nil)
+	mockTable.On("Delete", customRole.Name).Return(nil)
+
+	require.NoError(t, (API{}).DeleteRole(&models.DeleteRoleInput{Name: customRole.Name}))
+	mockGateway.AssertExpectations(t)
+	mockTable.AssertExpectations(t)
+}
+
+func TestDeleteRoleInUse(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("Get", customRole.Name).Return(customRole, nil)
+	mockGateway.On("ListRoleUsers", customRole.Name).Return([]*string{aws.String("user123")}, nil)
+
+	err := (API{}).DeleteRole(&models.DeleteRoleInput{Name: customRole.Name})
+	assert.Equal(t, &genericapi.InUseError{Message: "role Triage is assigned to 1 users"}, err)
+	mockTable.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestDeleteRoleMissing(t *testing.T) {
+	mockTable := &mockRolesTable{}
+	rolesTable = mockTable
+	mockTable.On("Get", aws.String("Missing")).Return((*models.Role)(nil), nil)
+
+	err := (API{}).DeleteRole(&models.DeleteRoleInput{Name: aws.String("Missing")})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestDeleteRoleBuiltIn(t *testing.T) {
+	err := (API{}).DeleteRole(&models.DeleteRoleInput{Name: aws.String(models.RoleAdmin)})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
@@ -24,8 +24,21 @@ import (
 
 // UpdateUser modifies user attributes and roles.
 func (API) UpdateUser(input *models.UpdateUserInput) (*models.UpdateUserOutput, error) {
-	if err := userGateway.UpdateUser(input); err != nil {
-		return nil, err
+	if input.Role != nil {
+		if err := validateRole(input.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.GivenName != nil || input.FamilyName != nil || input.Email != nil {
+		if err := userGateway.UpdateUser(input); err != nil {
+			return nil, err
+		}
+	}
+	if input.Role != nil {
+		if err := userGateway.SetUserRole(input.ID, input.Role); err != nil {
+			return nil, err
+		}
 	}
 
 	// Return updated user attributes
@@ -68,3 +68,20 @@ func TestUpdateUserHandle(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	mockGateway := &gateway.MockUserGateway{}
+	userGateway = mockGateway
+	input := &models.UpdateUserInput{
+		ID:   aws.String("user123"),
+		Role: aws.String(models.RoleAnalyst),
+	}
+	mockGateway.On("SetUserRole", input.ID, input.Role).Return(nil)
+	mockGateway.On("GetUser", input.ID).Return(&models.User{ID: input.ID, Role: input.Role}, nil)
+
+	result, err := (API{}).UpdateUser(input)
+	assert.NoError(t, err)
+	assert.Equal(t, input.Role, result.Role)
+	mockGateway.AssertExpectations(t)
+	mockGateway.AssertNotCalled(t, "UpdateUser", input) // no attribute to update
+}
@@ -56,5 +56,9 @@ func (g *UsersGateway) GetUser(id *string) (*models.User, error) {
 	if err != nil {
 		return nil, &genericapi.AWSError{Method: "cognito.AdminGetUser", Err: err}
 	}
-	return mapGetUserOutputToPantherUser(user), nil
+	result := mapGetUserOutputToPantherUser(user)
+	if result.Role, err = g.userRole(id); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
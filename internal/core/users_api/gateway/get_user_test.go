@@ -64,11 +64,23 @@ func (m *mockGetUserClient) AdminGetUser(
 	}, nil
 }
 
+func (m *mockGetUserClient) AdminListGroupsForUserPages(
+	input *provider.AdminListGroupsForUserInput,
+	pager func(*provider.AdminListGroupsForUserOutput, bool) bool,
+) error {
+
+	pager(&provider.AdminListGroupsForUserOutput{
+		Groups: []*provider.GroupType{{GroupName: aws.String("Analyst")}},
+	}, true)
+	return nil
+}
+
 func TestGetUser(t *testing.T) {
 	gw := &UsersGateway{userPoolClient: &mockGetUserClient{}}
 	result, err := gw.GetUser(aws.String("user123"))
 	assert.NotNil(t, result)
 	assert.NoError(t, err)
+	assert.Equal(t, aws.String("Analyst"), result.Role)
 }
 
 func TestGetUserFailed(t *testing.T) {
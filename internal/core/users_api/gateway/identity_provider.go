@@ -0,0 +1,339 @@
+package gateway
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	provider "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The user pool tag holding the group the users provisioned by the identity provider join
+	defaultRoleTag = "PantherSSODefaultRole"
+
+	// The users always keep signing in with their Panther password, next to the identity provider
+	cognitoProvider = "COGNITO"
+)
+
+var (
+	appClientID    = os.Getenv("APP_CLIENT_ID")
+	appDomainURL   = os.Getenv("APP_DOMAIN_URL")
+	userPoolDomain = os.Getenv("USER_POOL_DOMAIN")
+	awsRegion      = os.Getenv("AWS_REGION")
+
+	// The attribute the email of the users is mapped from by default
+	defaultEmailAttributes = map[string]string{
+		provider.IdentityProviderTypeTypeSaml: "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		provider.IdentityProviderTypeTypeOidc: "email",
+	}
+)
+
+// GetIdentityProvider returns the single sign-on identity provider of the user pool, or nil if there is none.
+func (g *UsersGateway) GetIdentityProvider() (*models.IdentityProvider, error) {
+	name, err := g.identityProviderName()
+	if err != nil || name == nil {
+		return nil, err
+	}
+
+	response, err := g.userPoolClient.DescribeIdentityProvider(&provider.DescribeIdentityProviderInput{
+		ProviderName: name,
+		UserPoolId:   &userPoolID,
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "cognito.DescribeIdentityProvider", Err: err}
+	}
+	defaultRole, err := g.defaultRole()
+	if err != nil {
+		return nil, err
+	}
+
+	details := response.IdentityProvider.ProviderDetails
+	result := &models.IdentityProvider{
+		Name:             response.IdentityProvider.ProviderName,
+		Type:             response.IdentityProvider.ProviderType,
+		AttributeMapping: aws.StringValueMap(response.IdentityProvider.AttributeMapping),
+		DefaultRole:      defaultRole,
+	}
+	if *result.Type == provider.IdentityProviderTypeTypeSaml {
+		result.MetadataURL = details["MetadataURL"]
+	} else {
+		result.ClientID = details["client_id"]
+		result.Issuer = details["oidc_issuer"]
+		result.Scopes = details["authorize_scopes"]
+	}
+	serviceProviderSettings(result)
+	return result, nil
+}
+
+// PutIdentityProvider creates or replaces the single sign-on identity provider and enables it on the app client.
+func (g *UsersGateway) PutIdentityProvider(input *models.PutIdentityProviderInput) (*models.IdentityProvider, error) {
+	current, err := g.identityProviderName()
+	if err != nil {
+		return nil, err
+	}
+	// There is a single identity provider, a new one replaces the current one
+	if current != nil && *current != *input.Name {
+		if err := g.deleteIdentityProvider(current); err != nil {
+			return nil, err
+		}
+	}
+
+	details := providerDetails(input)
+	mapping := make(map[string]string, len(input.AttributeMapping)+1)
+	mapping["email"] = defaultEmailAttributes[*input.Type]
+	for attribute, providerAttribute := range input.AttributeMapping {
+		mapping[attribute] = providerAttribute
+	}
+
+	if current != nil && *current == *input.Name {
+		_, err = g.userPoolClient.UpdateIdentityProvider(&provider.UpdateIdentityProviderInput{
+			AttributeMapping: aws.StringMap(mapping),
+			ProviderDetails:  aws.StringMap(details),
+			ProviderName:     input.Name,
+			UserPoolId:       &userPoolID,
+		})
+		if err != nil {
+			return nil, &genericapi.AWSError{Method: "cognito.UpdateIdentityProvider", Err: err}
+		}
+	} else {
+		_, err = g.userPoolClient.CreateIdentityProvider(&provider.CreateIdentityProviderInput{
+			AttributeMapping: aws.StringMap(mapping),
+			ProviderDetails:  aws.StringMap(details),
+			ProviderName:     input.Name,
+			ProviderType:     input.Type,
+			UserPoolId:       &userPoolID,
+		})
+		if err != nil {
+			return nil, &genericapi.AWSError{Method: "cognito.CreateIdentityProvider", Err: err}
+		}
+	}
+
+	if err := g.enableIdentityProvider(input.Name); err != nil {
+		return nil, err
+	}
+	if err := g.setDefaultRole(input.DefaultRole); err != nil {
+		return nil, err
+	}
+
+	result := &models.IdentityProvider{
+		Name:             input.Name,
+		Type:             input.Type,
+		MetadataURL:      input.MetadataURL,
+		ClientID:         input.ClientID,
+		Issuer:           input.Issuer,
+		AttributeMapping: mapping,
+		DefaultRole:      input.DefaultRole,
+	}
+	if scopes, ok := details["authorize_scopes"]; ok {
+		result.Scopes = &scopes
+	}
+	serviceProviderSettings(result)
+	return result, nil
+}
+
+// DeleteIdentityProvider removes the single sign-on identity provider, if there is one.
+//
+// The users it provisioned stay in the user pool, they can reset their password to sign in.
+func (g *UsersGateway) DeleteIdentityProvider() error {
+	name, err := g.identityProviderName()
+	if err != nil || name == nil {
+		return err
+	}
+	if err := g.deleteIdentityProvider(name); err != nil {
+		return err
+	}
+	return g.setDefaultRole(nil)
+}
+
+// ProvisionUser gives a user signing in with the identity provider for the first time the default role.
+//
+// The users have the ReadOnly role if the identity provider has no default role.
+func (g *UsersGateway) ProvisionUser(id *string) error {
+	defaultRole, err := g.defaultRole()
+	if err != nil {
+		return err
+	}
+	if defaultRole == nil {
+		defaultRole = aws.String(models.RoleReadOnly)
+	}
+	return g.SetUserRole(id, defaultRole)
+}
+
+// The name of the identity provider of the user pool, there is at most one
+func (g *UsersGateway) identityProviderName() (*string, error) {
+	response, err := g.userPoolClient.ListIdentityProviders(&provider.ListIdentityProvidersInput{
+		MaxResults: aws.Int64(60),
+		UserPoolId: &userPoolID,
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "cognito.ListIdentityProviders", Err: err}
+	}
+	for _, identityProvider := range response.Providers {
+		switch aws.StringValue(identityProvider.ProviderType) {
+		case provider.IdentityProviderTypeTypeSaml, provider.IdentityProviderTypeTypeOidc:
+			return identityProvider.ProviderName, nil
+		}
+	}
+	return nil, nil
+}
+
+func (g *UsersGateway) deleteIdentityProvider(name *string) error {
+	// The app client can't refer to a missing identity provider
+	if err := g.enableIdentityProvider(nil); err != nil {
+		return err
+	}
+	_, err := g.userPoolClient.DeleteIdentityProvider(&provider.DeleteIdentityProviderInput{
+		ProviderName: name,
+		UserPoolId:   &userPoolID,
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "cognito.DeleteIdentityProvider", Err: err}
+	}
+	return nil
+}
+
+// Set the identity providers of the app client, a nil name only leaves the Panther passwords
+func (g *UsersGateway) enableIdentityProvider(name *string) error {
+	response, err := g.userPoolClient.DescribeUserPoolClient(&provider.DescribeUserPoolClientInput{
+		ClientId:   &appClientID,
+		UserPoolId: &userPoolID,
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "cognito.DescribeUserPoolClient", Err: err}
+	}
+
+	// The settings which are not specified are reset, keep the current ones
+	client := response.UserPoolClient
+	input := &provider.UpdateUserPoolClientInput{
+		ClientId:                        client.ClientId,
+		ClientName:                      client.ClientName,
+		DefaultRedirectURI:              client.DefaultRedirectURI,
+		ExplicitAuthFlows:               client.ExplicitAuthFlows,
+		LogoutURLs:                      client.LogoutURLs,
+		PreventUserExistenceErrors:      client.PreventUserExistenceErrors,
+		ReadAttributes:                  client.ReadAttributes,
+		RefreshTokenValidity:            client.RefreshTokenValidity,
+		SupportedIdentityProviders:      []*string{aws.String(cognitoProvider)},
+		UserPoolId:                      client.UserPoolId,
+		WriteAttributes:                 client.WriteAttributes,
+		AllowedOAuthFlowsUserPoolClient: aws.Bool(false),
+	}
+	if name != nil {
+		input.SupportedIdentityProviders = append(input.SupportedIdentityProviders, name)
+		input.AllowedOAuthFlows = []*string{aws.String(provider.OAuthFlowTypeCode)}
+		input.AllowedOAuthFlowsUserPoolClient = aws.Bool(true)
+		input.AllowedOAuthScopes = aws.StringSlice([]string{"email", "openid", "profile"})
+		input.CallbackURLs = []*string{aws.String(fmt.Sprintf("https://%s/sign-in", appDomainURL))}
+	}
+
+	if _, err = g.userPoolClient.UpdateUserPoolClient(input); err != nil {
+		return &genericapi.AWSError{Method: "cognito.UpdateUserPoolClient", Err: err}
+	}
+	return nil
+}
+
+func (g *UsersGateway) userPoolArn() (*string, map[string]*string, error) {
+	response, err := g.userPoolClient.DescribeUserPool(&provider.DescribeUserPoolInput{UserPoolId: &userPoolID})
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Method: "cognito.DescribeUserPool", Err: err}
+	}
+	return response.UserPool.Arn, response.UserPool.UserPoolTags, nil
+}
+
+func (g *UsersGateway) defaultRole() (*string, error) {
+	_, tags, err := g.userPoolArn()
+	if err != nil {
+		return nil, err
+	}
+	return tags[defaultRoleTag], nil
+}
+
+// The group of the default role is created if it doesn't exist yet, a nil role removes the default role
+func (g *UsersGateway) setDefaultRole(role *string) error {
+	arn, _, err := g.userPoolArn()
+	if err != nil {
+		return err
+	}
+
+	if role == nil {
+		_, err = g.userPoolClient.UntagResource(&provider.UntagResourceInput{
+			ResourceArn: arn,
+			TagKeys:     []*string{aws.String(defaultRoleTag)},
+		})
+		if err != nil {
+			return &genericapi.AWSError{Method: "cognito.UntagResource", Err: err}
+		}
+		return nil
+	}
+
+	if err = g.CreateRoleGroup(role); err != nil {
+		return err
+	}
+
+	_, err = g.userPoolClient.TagResource(&provider.TagResourceInput{
+		ResourceArn: arn,
+		Tags:        map[string]*string{defaultRoleTag: role},
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "cognito.TagResource", Err: err}
+	}
+	return nil
+}
+
+func providerDetails(input *models.PutIdentityProviderInput) map[string]string {
+	if *input.Type == provider.IdentityProviderTypeTypeSaml {
+		if input.MetadataURL != nil {
+			return map[string]string{"MetadataURL": *input.MetadataURL}
+		}
+		return map[string]string{"MetadataFile": *input.MetadataFile}
+	}
+
+	scopes := "openid email profile"
+	if input.Scopes != nil {
+		scopes = *input.Scopes
+	}
+	return map[string]string{
+		"attributes_request_method": "GET",
+		"authorize_scopes":          scopes,
+		"client_id":                 *input.ClientID,
+		"client_secret":             *input.ClientSecret,
+		"oidc_issuer":               *input.Issuer,
+	}
+}
+
+// The settings the identity provider needs to trust Panther as a service provider
+func serviceProviderSettings(result *models.IdentityProvider) {
+	domain := fmt.Sprintf("https://%s.auth.%s.amazoncognito.com", userPoolDomain, awsRegion)
+	result.EntityID = aws.String("urn:amazon:cognito:sp:" + userPoolID)
+	if *result.Type == provider.IdentityProviderTypeTypeSaml {
+		result.RedirectURL = aws.String(domain + "/saml2/idpresponse")
+	} else {
+		result.RedirectURL = aws.String(domain + "/oauth2/idpresponse")
+	}
+	result.SignInURL = aws.String(fmt.Sprintf(
+		"%s/oauth2/authorize?identity_provider=%s&response_type=code&client_id=%s&redirect_uri=https://%s/sign-in",
+		domain, url.QueryEscape(*result.Name), appClientID, appDomainURL))
+}
@@ -0,0 +1,155 @@
+package gateway
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	provider "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+)
+
+var userPoolArn = aws.String("arn:aws:cognito-idp:us-west-2:123456789012:userpool/us-west-2_abc")
+
+func TestPutIdentityProvider(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("ListIdentityProviders", mock.Anything).Return(&provider.ListIdentityProvidersOutput{}, nil)
+	mockClient.On("CreateIdentityProvider", mock.Anything).Return(&provider.CreateIdentityProviderOutput{}, nil)
+	mockClient.On("DescribeUserPoolClient", mock.Anything).Return(&provider.DescribeUserPoolClientOutput{
+		UserPoolClient: &provider.UserPoolClientType{ClientId: aws.String("client"), ClientName: aws.String("Panther")},
+	}, nil)
+	mockClient.On("UpdateUserPoolClient", mock.Anything).Return(&provider.UpdateUserPoolClientOutput{}, nil)
+	mockClient.On("DescribeUserPool", mock.Anything).Return(&provider.DescribeUserPoolOutput{
+		UserPool: &provider.UserPoolType{Arn: userPoolArn},
+	}, nil)
+	mockClient.On("CreateGroup", mock.Anything).Return(&provider.CreateGroupOutput{}, nil)
+	mockClient.On("TagResource", &provider.TagResourceInput{
+		ResourceArn: userPoolArn,
+		Tags:        map[string]*string{defaultRoleTag: aws.String("Analyst")},
+	}).Return(&provider.TagResourceOutput{}, nil)
+
+	result, err := gw.PutIdentityProvider(&models.PutIdentityProviderInput{
+		Name:             aws.String("Okta"),
+		Type:             aws.String("SAML"),
+		MetadataURL:      aws.String("https://example.okta.com/app/exk1/sso/saml/metadata"),
+		AttributeMapping: map[string]string{"given_name": "firstName"},
+		DefaultRole:      aws.String("Analyst"),
+	})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	createInput := mockClient.Calls[1].Arguments.Get(0).(*provider.CreateIdentityProviderInput)
+	assert.Equal(t, map[string]string{
+		"email":      "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+		"given_name": "firstName",
+	}, aws.StringValueMap(createInput.AttributeMapping))
+	assert.Equal(t, "https://example.okta.com/app/exk1/sso/saml/metadata", *createInput.ProviderDetails["MetadataURL"])
+
+	clientInput := mockClient.Calls[3].Arguments.Get(0).(*provider.UpdateUserPoolClientInput)
+	assert.Equal(t, []string{"COGNITO", "Okta"}, aws.StringValueSlice(clientInput.SupportedIdentityProviders))
+	assert.Equal(t, "Panther", *clientInput.ClientName)
+
+	assert.Nil(t, result.Scopes)
+	assert.Contains(t, *result.RedirectURL, "/saml2/idpresponse")
+}
+
+func TestPutIdentityProviderReplaces(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("ListIdentityProviders", mock.Anything).Return(&provider.ListIdentityProvidersOutput{
+		Providers: []*provider.ProviderDescription{
+			{ProviderName: aws.String("Okta"), ProviderType: aws.String(provider.IdentityProviderTypeTypeSaml)},
+		},
+	}, nil)
+	mockClient.On("DescribeUserPoolClient", mock.Anything).Return(&provider.DescribeUserPoolClientOutput{
+		UserPoolClient: &provider.UserPoolClientType{ClientId: aws.String("client")},
+	}, nil)
+	mockClient.On("UpdateUserPoolClient", mock.Anything).Return(&provider.UpdateUserPoolClientOutput{}, nil)
+	mockClient.On("DeleteIdentityProvider", &provider.DeleteIdentityProviderInput{
+		ProviderName: aws.String("Okta"),
+		UserPoolId:   &userPoolID,
+	}).Return(&provider.DeleteIdentityProviderOutput{}, nil)
+	mockClient.On("CreateIdentityProvider", mock.Anything).Return(&provider.CreateIdentityProviderOutput{}, nil)
+	mockClient.On("DescribeUserPool", mock.Anything).Return(&provider.DescribeUserPoolOutput{
+		UserPool: &provider.UserPoolType{Arn: userPoolArn},
+	}, nil)
+	mockClient.On("UntagResource", mock.Anything).Return(&provider.UntagResourceOutput{}, nil)
+
+	result, err := gw.PutIdentityProvider(&models.PutIdentityProviderInput{
+		Name:         aws.String("Google"),
+		Type:         aws.String("OIDC"),
+		ClientID:     aws.String("client"),
+		ClientSecret: aws.String("secret"),
+		Issuer:       aws.String("https://accounts.google.com"),
+	})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	assert.Equal(t, "openid email profile", *result.Scopes)
+	mockClient.AssertNotCalled(t, "UpdateIdentityProvider", mock.Anything)
+}
+
+func TestProvisionUser(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("DescribeUserPool", mock.Anything).Return(&provider.DescribeUserPoolOutput{
+		UserPool: &provider.UserPoolType{
+			Arn:          userPoolArn,
+			UserPoolTags: map[string]*string{defaultRoleTag: aws.String("Analyst")},
+		},
+	}, nil)
+	mockClient.On("AdminListGroupsForUserPages", mock.Anything).Return(&provider.AdminListGroupsForUserOutput{}, nil)
+	mockClient.On("CreateGroup", mock.Anything).Return(&provider.CreateGroupOutput{}, nil)
+	mockClient.On("AdminAddUserToGroup", &provider.AdminAddUserToGroupInput{
+		GroupName:  aws.String("Analyst"),
+		UserPoolId: &userPoolID,
+		Username:   aws.String("Okta_joe@blow.com"),
+	}).Return(&provider.AdminAddUserToGroupOutput{}, nil)
+
+	require.NoError(t, gw.ProvisionUser(aws.String("Okta_joe@blow.com")))
+	mockClient.AssertExpectations(t)
+}
+
+func TestProvisionUserNoDefaultRole(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("DescribeUserPool", mock.Anything).Return(&provider.DescribeUserPoolOutput{
+		UserPool: &provider.UserPoolType{Arn: userPoolArn},
+	}, nil)
+	mockClient.On("AdminListGroupsForUserPages", mock.Anything).Return(&provider.AdminListGroupsForUserOutput{}, nil)
+	mockClient.On("CreateGroup", mock.Anything).Return(&provider.CreateGroupOutput{}, nil)
+	mockClient.On("AdminAddUserToGroup", &provider.AdminAddUserToGroupInput{
+		GroupName:  aws.String(models.RoleReadOnly),
+		UserPoolId: &userPoolID,
+		Username:   aws.String("Okta_joe@blow.com"),
+	}).Return(&provider.AdminAddUserToGroupOutput{}, nil)
+
+	// The users have the ReadOnly role by default
+	require.NoError(t, gw.ProvisionUser(aws.String("Okta_joe@blow.com")))
+	mockClient.AssertExpectations(t)
+}
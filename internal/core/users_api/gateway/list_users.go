@@ -64,5 +64,41 @@ func (g *UsersGateway) ListUsers() ([]*models.User, error) {
 		return nil, &genericapi.AWSError{Method: "cognito.ListUsers", Err: err}
 	}
 
+	roles, err := g.usersRoles()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range result {
+		user.Role = roles[*user.ID] // nil for the users without a role
+	}
+	return result, nil
+}
+
+// Map the IDs of the users to their role, the users without a role are not listed
+func (g *UsersGateway) usersRoles() (map[string]*string, error) {
+	var groups []*string
+	err := g.userPoolClient.ListGroupsPages(&provider.ListGroupsInput{UserPoolId: &userPoolID},
+		func(page *provider.ListGroupsOutput, lastPage bool) bool {
+			for _, group := range page.Groups {
+				groups = append(groups, group.GroupName)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "cognito.ListGroups", Err: err}
+	}
+
+	result := make(map[string]*string)
+	for _, group := range groups {
+		users, err := g.ListRoleUsers(group)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range users {
+			if _, ok := result[*id]; !ok { // a user keeps the first role, see RoleOfGroups
+				result[*id] = group
+			}
+		}
+	}
 	return result, nil
 }
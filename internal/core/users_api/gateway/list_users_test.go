@@ -71,11 +71,36 @@ func (m *mockListUsersClient) ListUsersPages(
 	return nil
 }
 
+func (m *mockListUsersClient) ListGroupsPages(
+	input *provider.ListGroupsInput,
+	pager func(*provider.ListGroupsOutput, bool) bool,
+) error {
+
+	pager(&provider.ListGroupsOutput{
+		Groups: []*provider.GroupType{{GroupName: aws.String("Analyst")}, {GroupName: aws.String("ReadOnly")}},
+	}, true)
+	return nil
+}
+
+func (m *mockListUsersClient) ListUsersInGroupPages(
+	input *provider.ListUsersInGroupInput,
+	pager func(*provider.ListUsersInGroupOutput, bool) bool,
+) error {
+
+	if *input.GroupName == "Analyst" {
+		pager(&provider.ListUsersInGroupOutput{
+			Users: []*provider.UserType{{Username: aws.String("user123")}},
+		}, true)
+	}
+	return nil
+}
+
 func TestListUsers(t *testing.T) {
 	gw := &UsersGateway{userPoolClient: &mockListUsersClient{}}
 	result, err := gw.ListUsers()
 	assert.NotNil(t, result)
 	assert.NoError(t, err)
+	assert.Equal(t, aws.String("Analyst"), result[0].Role)
 }
 
 func TestListUsersFailed(t *testing.T) {
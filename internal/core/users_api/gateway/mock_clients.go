@@ -37,3 +37,133 @@ func (m *MockCognitoClient) AdminCreateUser(
 	args := m.Called(input)
 	return args.Get(0).(*provider.AdminCreateUserOutput), args.Error(1)
 }
+
+// AdminAddUserToGroup mocks AdminAddUserToGroup for testing
+func (m *MockCognitoClient) AdminAddUserToGroup(
+	input *provider.AdminAddUserToGroupInput) (*provider.AdminAddUserToGroupOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.AdminAddUserToGroupOutput), args.Error(1)
+}
+
+// AdminListGroupsForUserPages mocks AdminListGroupsForUserPages for testing
+func (m *MockCognitoClient) AdminListGroupsForUserPages(
+	input *provider.AdminListGroupsForUserInput, pager func(*provider.AdminListGroupsForUserOutput, bool) bool) error {
+
+	args := m.Called(input)
+	pager(args.Get(0).(*provider.AdminListGroupsForUserOutput), true)
+	return args.Error(1)
+}
+
+// AdminRemoveUserFromGroup mocks AdminRemoveUserFromGroup for testing
+func (m *MockCognitoClient) AdminRemoveUserFromGroup(
+	input *provider.AdminRemoveUserFromGroupInput) (*provider.AdminRemoveUserFromGroupOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.AdminRemoveUserFromGroupOutput), args.Error(1)
+}
+
+// CreateGroup mocks CreateGroup for testing
+func (m *MockCognitoClient) CreateGroup(
+	input *provider.CreateGroupInput) (*provider.CreateGroupOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.CreateGroupOutput), args.Error(1)
+}
+
+// CreateIdentityProvider mocks CreateIdentityProvider for testing
+func (m *MockCognitoClient) CreateIdentityProvider(
+	input *provider.CreateIdentityProviderInput) (*provider.CreateIdentityProviderOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.CreateIdentityProviderOutput), args.Error(1)
+}
+
+// DeleteGroup mocks DeleteGroup for testing
+func (m *MockCognitoClient) DeleteGroup(
+	input *provider.DeleteGroupInput) (*provider.DeleteGroupOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.DeleteGroupOutput), args.Error(1)
+}
+
+// DeleteIdentityProvider mocks DeleteIdentityProvider for testing
+func (m *MockCognitoClient) DeleteIdentityProvider(
+	input *provider.DeleteIdentityProviderInput) (*provider.DeleteIdentityProviderOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.DeleteIdentityProviderOutput), args.Error(1)
+}
+
+// DescribeIdentityProvider mocks DescribeIdentityProvider for testing
+func (m *MockCognitoClient) DescribeIdentityProvider(
+	input *provider.DescribeIdentityProviderInput) (*provider.DescribeIdentityProviderOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.DescribeIdentityProviderOutput), args.Error(1)
+}
+
+// DescribeUserPool mocks DescribeUserPool for testing
+func (m *MockCognitoClient) DescribeUserPool(
+	input *provider.DescribeUserPoolInput) (*provider.DescribeUserPoolOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.DescribeUserPoolOutput), args.Error(1)
+}
+
+// DescribeUserPoolClient mocks DescribeUserPoolClient for testing
+func (m *MockCognitoClient) DescribeUserPoolClient(
+	input *provider.DescribeUserPoolClientInput) (*provider.DescribeUserPoolClientOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.DescribeUserPoolClientOutput), args.Error(1)
+}
+
+// ListIdentityProviders mocks ListIdentityProviders for testing
+func (m *MockCognitoClient) ListIdentityProviders(
+	input *provider.ListIdentityProvidersInput) (*provider.ListIdentityProvidersOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.ListIdentityProvidersOutput), args.Error(1)
+}
+
+// ListUsersInGroupPages mocks ListUsersInGroupPages for testing
+func (m *MockCognitoClient) ListUsersInGroupPages(
+	input *provider.ListUsersInGroupInput, pager func(*provider.ListUsersInGroupOutput, bool) bool) error {
+
+	args := m.Called(input)
+	pager(args.Get(0).(*provider.ListUsersInGroupOutput), true)
+	return args.Error(1)
+}
+
+// TagResource mocks TagResource for testing
+func (m *MockCognitoClient) TagResource(
+	input *provider.TagResourceInput) (*provider.TagResourceOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.TagResourceOutput), args.Error(1)
+}
+
+// UntagResource mocks UntagResource for testing
+func (m *MockCognitoClient) UntagResource(
+	input *provider.UntagResourceInput) (*provider.UntagResourceOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.UntagResourceOutput), args.Error(1)
+}
+
+// UpdateIdentityProvider mocks UpdateIdentityProvider for testing
+func (m *MockCognitoClient) UpdateIdentityProvider(
+	input *provider.UpdateIdentityProviderInput) (*provider.UpdateIdentityProviderOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.UpdateIdentityProviderOutput), args.Error(1)
+}
+
+// UpdateUserPoolClient mocks UpdateUserPoolClient for testing
+func (m *MockCognitoClient) UpdateUserPoolClient(
+	input *provider.UpdateUserPoolClientInput) (*provider.UpdateUserPoolClientOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*provider.UpdateUserPoolClientOutput), args.Error(1)
+}
@@ -34,36 +34,84 @@ type MockUserGateway struct {
 // The following methods implement the API interface
 // and just record the activity, and returns what the Mock object tells it to.
 
+// CreateRoleGroup mocks CreateRoleGroup for testing
+func (m *MockUserGateway) CreateRoleGroup(role *string) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
 // CreateUser mocks CreateUser for testing
 func (m *MockUserGateway) CreateUser(input *models.InviteUserInput) (*string, error) {
 	args := m.Called(input)
 	return args.Get(0).(*string), args.Error(1)
 }
 
+// DeleteIdentityProvider mocks DeleteIdentityProvider for testing
+func (m *MockUserGateway) DeleteIdentityProvider() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// DeleteRoleGroup mocks DeleteRoleGroup for testing
+func (m *MockUserGateway) DeleteRoleGroup(role *string) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
 // DeleteUser mocks DeleteUser for testing
 func (m *MockUserGateway) DeleteUser(id *string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// GetIdentityProvider mocks GetIdentityProvider for testing
+func (m *MockUserGateway) GetIdentityProvider() (*models.IdentityProvider, error) {
+	args := m.Called()
+	return args.Get(0).(*models.IdentityProvider), args.Error(1)
+}
+
 // GetUser mocks GetUser for testing
 func (m *MockUserGateway) GetUser(id *string) (*models.User, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+// ListRoleUsers mocks ListRoleUsers for testing
+func (m *MockUserGateway) ListRoleUsers(role *string) ([]*string, error) {
+	args := m.Called(role)
+	return args.Get(0).([]*string), args.Error(1)
+}
+
 // ListUsers mocks ListUsers for testing
 func (m *MockUserGateway) ListUsers() ([]*models.User, error) {
 	args := m.Called()
 	return args.Get(0).([]*models.User), args.Error(1)
 }
 
+// ProvisionUser mocks ProvisionUser for testing
+func (m *MockUserGateway) ProvisionUser(id *string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// PutIdentityProvider mocks PutIdentityProvider for testing
+func (m *MockUserGateway) PutIdentityProvider(input *models.PutIdentityProviderInput) (*models.IdentityProvider, error) {
+	args := m.Called(input)
+	return args.Get(0).(*models.IdentityProvider), args.Error(1)
+}
+
 // ResetUserPassword mocks ResetUserPassword for testing
 func (m *MockUserGateway) ResetUserPassword(id *string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// SetUserRole mocks SetUserRole for testing
+func (m *MockUserGateway) SetUserRole(id *string, role *string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
 // UpdateUser mocks UpdateUser for testing
 func (m *MockUserGateway) UpdateUser(input *models.UpdateUserInput) error {
 	args := m.Called(input)
@@ -0,0 +1,157 @@
+package gateway
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	provider "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The role of a user is the user pool group they are in, each role has its own group.
+
+// CreateRoleGroup creates the group of a role, it is not an error if it already exists.
+func (g *UsersGateway) CreateRoleGroup(role *string) error {
+	_, err := g.userPoolClient.CreateGroup(&provider.CreateGroupInput{
+		Description: aws.String("Users with the Panther role " + *role),
+		GroupName:   role,
+		UserPoolId:  &userPoolID,
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == provider.ErrCodeGroupExistsException {
+		zap.L().Debug("role group already exists", zap.String("group", *role))
+		return nil
+	}
+	if err != nil {
+		return &genericapi.AWSError{Method: "cognito.CreateGroup", Err: err}
+	}
+	return nil
+}
+
+// DeleteRoleGroup deletes the group of a role, it is not an error if it doesn't exist.
+func (g *UsersGateway) DeleteRoleGroup(role *string) error {
+	_, err := g.userPoolClient.DeleteGroup(&provider.DeleteGroupInput{GroupName: role, UserPoolId: &userPoolID})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == provider.ErrCodeResourceNotFoundException {
+		return nil
+	}
+	if err != nil {
+		return &genericapi.AWSError{Method: "cognito.DeleteGroup", Err: err}
+	}
+	return nil
+}
+
+// ListRoleUsers returns the IDs of the users with the given role.
+func (g *UsersGateway) ListRoleUsers(role *string) ([]*string, error) {
+	var result []*string
+	err := g.userPoolClient.ListUsersInGroupPages(
+		&provider.ListUsersInGroupInput{GroupName: role, UserPoolId: &userPoolID},
+		func(page *provider.ListUsersInGroupOutput, lastPage bool) bool {
+			for _, user := range page.Users {
+				result = append(result, user.Username)
+			}
+			return true
+		})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == provider.ErrCodeResourceNotFoundException {
+		return nil, nil // nobody was ever given the role
+	}
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "cognito.ListUsersInGroup", Err: err}
+	}
+	return result, nil
+}
+
+// SetUserRole moves a user to the group of the given role, out of the group of their previous role.
+//
+// The user gets the permissions of the new role the next time their session is refreshed.
+func (g *UsersGateway) SetUserRole(id *string, role *string) error {
+	groups, err := g.userGroups(id)
+	if err != nil {
+		return err
+	}
+
+	hasRole := false
+	for _, group := range groups {
+		if *group == *role {
+			hasRole = true
+			continue
+		}
+		_, err = g.userPoolClient.AdminRemoveUserFromGroup(&provider.AdminRemoveUserFromGroupInput{
+			GroupName:  group,
+			UserPoolId: &userPoolID,
+			Username:   id,
+		})
+		if err != nil {
+			return &genericapi.AWSError{Method: "cognito.AdminRemoveUserFromGroup", Err: err}
+		}
+	}
+	if hasRole {
+		return nil
+	}
+
+	if err = g.CreateRoleGroup(role); err != nil {
+		return err
+	}
+	_, err = g.userPoolClient.AdminAddUserToGroup(&provider.AdminAddUserToGroupInput{
+		GroupName:  role,
+		UserPoolId: &userPoolID,
+		Username:   id,
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "cognito.AdminAddUserToGroup", Err: err}
+	}
+	return nil
+}
+
+// The role of a user, from the groups they are in
+func (g *UsersGateway) userRole(id *string) (*string, error) {
+	groups, err := g.userGroups(id)
+	if err != nil {
+		return nil, err
+	}
+	return RoleOfGroups(groups), nil
+}
+
+func (g *UsersGateway) userGroups(id *string) ([]*string, error) {
+	var result []*string
+	err := g.userPoolClient.AdminListGroupsForUserPages(
+		&provider.AdminListGroupsForUserInput{UserPoolId: &userPoolID, Username: id},
+		func(page *provider.AdminListGroupsForUserOutput, lastPage bool) bool {
+			for _, group := range page.Groups {
+				result = append(result, group.GroupName)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "cognito.AdminListGroupsForUser", Err: err}
+	}
+	return result, nil
+}
+
+// RoleOfGroups returns the role of a user in the given groups.
+//
+// A user in no group has no role, nil, and no permission: e.g. their provisioning failed.
+// A user is only in several groups while their role is changed: they keep the first one.
+func RoleOfGroups(groups []*string) *string {
+	if len(groups) == 0 {
+		return nil
+	}
+	return groups[0]
+}
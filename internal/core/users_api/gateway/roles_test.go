@@ -0,0 +1,99 @@
+package gateway
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	provider "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+)
+
+func TestSetUserRole(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("AdminListGroupsForUserPages", mock.Anything).Return(&provider.AdminListGroupsForUserOutput{
+		Groups: []*provider.GroupType{{GroupName: aws.String(models.RoleReadOnly)}},
+	}, nil)
+	mockClient.On("AdminRemoveUserFromGroup", &provider.AdminRemoveUserFromGroupInput{
+		GroupName:  aws.String(models.RoleReadOnly),
+		UserPoolId: &userPoolID,
+		Username:   aws.String("user123"),
+	}).Return(&provider.AdminRemoveUserFromGroupOutput{}, nil)
+	mockClient.On("CreateGroup", mock.Anything).Return(
+		&provider.CreateGroupOutput{}, awserr.New(provider.ErrCodeGroupExistsException, "exists", nil))
+	mockClient.On("AdminAddUserToGroup", &provider.AdminAddUserToGroupInput{
+		GroupName:  aws.String(models.RoleAnalyst),
+		UserPoolId: &userPoolID,
+		Username:   aws.String("user123"),
+	}).Return(&provider.AdminAddUserToGroupOutput{}, nil)
+
+	require.NoError(t, gw.SetUserRole(aws.String("user123"), aws.String(models.RoleAnalyst)))
+	mockClient.AssertExpectations(t)
+}
+
+func TestSetUserRoleUnchanged(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("AdminListGroupsForUserPages", mock.Anything).Return(&provider.AdminListGroupsForUserOutput{
+		Groups: []*provider.GroupType{{GroupName: aws.String(models.RoleAnalyst)}},
+	}, nil)
+
+	require.NoError(t, gw.SetUserRole(aws.String("user123"), aws.String(models.RoleAnalyst)))
+	mockClient.AssertNotCalled(t, "AdminAddUserToGroup", mock.Anything)
+}
+
+func TestListRoleUsers(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("ListUsersInGroupPages", &provider.ListUsersInGroupInput{
+		GroupName:  aws.String("Triage"),
+		UserPoolId: &userPoolID,
+	}).Return(&provider.ListUsersInGroupOutput{
+		Users: []*provider.UserType{{Username: aws.String("user123")}},
+	}, nil)
+
+	result, err := gw.ListRoleUsers(aws.String("Triage"))
+	require.NoError(t, err)
+	assert.Equal(t, []*string{aws.String("user123")}, result)
+}
+
+func TestDeleteRoleGroupMissing(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	gw := &UsersGateway{userPoolClient: mockClient}
+
+	mockClient.On("DeleteGroup", mock.Anything).Return(
+		&provider.DeleteGroupOutput{}, awserr.New(provider.ErrCodeResourceNotFoundException, "not found", nil))
+
+	assert.NoError(t, gw.DeleteRoleGroup(aws.String("Triage")))
+}
+
+func TestRoleOfGroups(t *testing.T) {
+	assert.Nil(t, RoleOfGroups(nil))
+	assert.Equal(t, aws.String("Triage"), RoleOfGroups(aws.StringSlice([]string{"Triage", models.RoleAdmin})))
+}
@@ -32,11 +32,19 @@ var userPoolID = os.Getenv("USER_POOL_ID")
 
 // API defines the interface for the user gateway which can be used for mocking.
 type API interface {
+	CreateRoleGroup(role *string) error
 	CreateUser(input *models.InviteUserInput) (*string, error)
+	DeleteIdentityProvider() error
+	DeleteRoleGroup(role *string) error
 	DeleteUser(id *string) error
+	GetIdentityProvider() (*models.IdentityProvider, error)
 	GetUser(id *string) (*models.User, error)
+	ListRoleUsers(role *string) ([]*string, error)
 	ListUsers() ([]*models.User, error)
+	ProvisionUser(id *string) error
+	PutIdentityProvider(input *models.PutIdentityProviderInput) (*models.IdentityProvider, error)
 	ResetUserPassword(id *string) error
+	SetUserRole(id *string, role *string) error
 	UpdateUser(input *models.UpdateUserInput) error
 }
 
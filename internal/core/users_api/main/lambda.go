@@ -29,7 +29,12 @@ import (
 	"github.com/panther-labs/panther/pkg/lambdalogger"
 )
 
-var router = genericapi.NewRouter("api", "users", models.Validator(), &api.API{})
+var router = genericapi.NewRouter("api", "users", models.Validator(), &api.API{}).
+	RequirePermission(models.PermissionSettingsModify, "PutIdentityProvider", "RemoveIdentityProvider").
+	RequirePermission(models.PermissionSettingsRead, "GetIdentityProvider").
+	RequirePermission(models.PermissionUserModify,
+		"DeleteRole", "InviteUser", "PutRole", "RemoveUser", "ResetUserPassword", "UpdateUser").
+	RequirePermission(models.PermissionUserRead, "GetUser", "ListRoles", "ListUsers")
 
 func lambdaHandler(ctx context.Context, input *models.LambdaInput) (interface{}, error) {
 	lambdalogger.ConfigureGlobal(ctx, nil)
@@ -0,0 +1,138 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// Delete removes a custom role, it is not an error if it doesn't exist.
+func (table *RolesTable) Delete(name *string) error {
+	zap.L().Debug("deleting role", zap.String("name", *name))
+	_, err := table.client.DeleteItem(&dynamodb.DeleteItemInput{Key: roleKey(name), TableName: table.Name})
+	if err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.DeleteItem", Err: err}
+	}
+	return nil
+}
+
+// Get retrieves a custom role, nil if it doesn't exist.
+func (table *RolesTable) Get(name *string) (*models.Role, error) {
+	if isMigration(name) {
+		return nil, nil
+	}
+	response, err := table.client.GetItem(&dynamodb.GetItemInput{Key: roleKey(name), TableName: table.Name})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.GetItem", Err: err}
+	}
+	if len(response.Item) == 0 {
+		return nil, nil
+	}
+
+	var role models.Role
+	if err = dynamodbattribute.UnmarshalMap(response.Item, &role); err != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo item to Role: " + err.Error()}
+	}
+	return &role, nil
+}
+
+// List returns all the custom roles sorted by name.
+func (table *RolesTable) List() ([]*models.Role, error) {
+	var result []*models.Role
+	var unmarshalErr error
+	err := table.client.ScanPages(&dynamodb.ScanInput{TableName: table.Name},
+		func(page *dynamodb.ScanOutput, lastPage bool) bool {
+			var roles []*models.Role
+			if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &roles); unmarshalErr != nil {
+				return false // stop paginating
+			}
+			for _, role := range roles {
+				if !isMigration(role.Name) {
+					result = append(result, role)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "dynamodb.Scan", Err: err}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.InternalError{Message: "failed to unmarshal dynamo items: " + unmarshalErr.Error()}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return *result[i].Name < *result[j].Name })
+	return result, nil
+}
+
+// Put creates or replaces a custom role.
+func (table *RolesTable) Put(role *models.Role) error {
+	item, err := dynamodbattribute.MarshalMap(role)
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to marshal Role to dynamo item: " + err.Error()}
+	}
+
+	zap.L().Debug("storing role", zap.String("name", *role.Name))
+	_, err = table.client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: table.Name})
+	if err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
+
+// FindRole returns the built-in or custom role with the given name, nil if there is none.
+func FindRole(table API, name *string) (*models.Role, error) {
+	if role := models.BuiltInRole(*name); role != nil {
+		return role, nil
+	}
+	return table.Get(name)
+}
+
+// MigrationDone returns whether the migration with the given name already ran.
+func (table *RolesTable) MigrationDone(name string) (bool, error) {
+	response, err := table.client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            roleKey(aws.String(migrationPrefix + name)),
+		TableName:      table.Name,
+	})
+	if err != nil {
+		return false, &genericapi.AWSError{Method: "dynamodb.GetItem", Err: err}
+	}
+	return len(response.Item) > 0, nil
+}
+
+// RecordMigration records that the migration with the given name ran.
+func (table *RolesTable) RecordMigration(name string) error {
+	zap.L().Info("recording migration", zap.String("name", name))
+	_, err := table.client.PutItem(&dynamodb.PutItemInput{
+		Item:      roleKey(aws.String(migrationPrefix + name)),
+		TableName: table.Name,
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "dynamodb.PutItem", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) ScanPages(input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool) error {
+	args := m.Called(input)
+	fn(args.Get(0).(*dynamodb.ScanOutput), true)
+	return args.Error(1)
+}
+
+func testRole(name string) *models.Role {
+	return &models.Role{
+		Name:        aws.String(name),
+		Description: aws.String("Writes detections"),
+		Permissions: aws.StringSlice([]string{models.PermissionAlertRead, models.PermissionDataAnalyticsModify}),
+		BuiltIn:     aws.Bool(false),
+	}
+}
+
+func testItem(t *testing.T, role *models.Role) DynamoItem {
+	item, err := dynamodbattribute.MarshalMap(role)
+	require.NoError(t, err)
+	return item
+}
+
+func TestNew(t *testing.T) {
+	assert.NotNil(t, New("table", session.Must(session.NewSession())))
+}
+
+func TestGet(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+	role := testRole("DetectionEngineer")
+	mockClient.On("GetItem", &dynamodb.GetItemInput{Key: roleKey(role.Name), TableName: table.Name}).Return(
+		&dynamodb.GetItemOutput{Item: testItem(t, role)}, nil)
+
+	result, err := table.Get(role.Name)
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	assert.Equal(t, role, result)
+}
+
+func TestGetMissing(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	result, err := table.Get(aws.String("Missing"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestList(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+	mockClient.On("ScanPages", &dynamodb.ScanInput{TableName: table.Name}).Return(&dynamodb.ScanOutput{
+		Items: []DynamoItem{testItem(t, testRole("Triage")), testItem(t, testRole("DetectionEngineer"))},
+	}, nil)
+
+	result, err := table.List()
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Role{testRole("DetectionEngineer"), testRole("Triage")}, result)
+}
+
+func TestListSkipsMigrations(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+	mockClient.On("ScanPages", mock.Anything).Return(&dynamodb.ScanOutput{
+		Items: []DynamoItem{testItem(t, testRole("Triage")), roleKey(aws.String("migration:userRoles"))},
+	}, nil)
+
+	result, err := table.List()
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Role{testRole("Triage")}, result)
+}
+
+func TestGetMigration(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+
+	// A migration is not a role
+	result, err := table.Get(aws.String("migration:userRoles"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	mockClient.AssertNotCalled(t, "GetItem", mock.Anything)
+}
+
+func TestMigrationDone(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+	key := roleKey(aws.String("migration:userRoles"))
+	mockClient.On("GetItem", &dynamodb.GetItemInput{ConsistentRead: aws.Bool(true), Key: key, TableName: table.Name}).
+		Return(&dynamodb.GetItemOutput{}, nil).Once()
+	mockClient.On("PutItem", &dynamodb.PutItemInput{Item: key, TableName: table.Name}).Return(&dynamodb.PutItemOutput{}, nil)
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: key}, nil).Once()
+
+	done, err := table.MigrationDone("userRoles")
+	require.NoError(t, err)
+	assert.False(t, done)
+	require.NoError(t, table.RecordMigration("userRoles"))
+	done, err = table.MigrationDone("userRoles")
+	require.NoError(t, err)
+	assert.True(t, done)
+	mockClient.AssertExpectations(t)
+}
+
+func TestPut(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+	role := testRole("DetectionEngineer")
+	mockClient.On("PutItem", &dynamodb.PutItemInput{Item: testItem(t, role), TableName: table.Name}).Return(
+		&dynamodb.PutItemOutput{}, nil)
+
+	require.NoError(t, table.Put(role))
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteServiceError(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, errors.New("service unavailable"))
+
+	err := table.Delete(aws.String("DetectionEngineer"))
+	assert.IsType(t, &genericapi.AWSError{}, err)
+}
+
+func TestFindRoleBuiltIn(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	table := &RolesTable{Name: aws.String("table"), client: mockClient}
+
+	result, err := FindRole(table, aws.String(models.RoleReadOnly))
+	require.NoError(t, err)
+	assert.True(t, *result.BuiltIn)
+	assert.Equal(t, aws.StringSlice(models.BuiltInRoles[models.RoleReadOnly]), result.Permissions)
+	mockClient.AssertNotCalled(t, "GetItem", mock.Anything)
+}
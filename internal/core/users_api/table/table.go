@@ -0,0 +1,71 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/panther-labs/panther/api/lambda/users/models"
+)
+
+// API defines the interface for the table which can be used for mocking.
+type API interface {
+	Delete(name *string) error
+	Get(name *string) (*models.Role, error)
+	List() ([]*models.Role, error)
+	MigrationDone(name string) (bool, error)
+	Put(role *models.Role) error
+	RecordMigration(name string) error
+}
+
+// RolesTable encapsulates a connection to the Dynamo table of the custom roles.
+//
+// The built-in roles are not stored, see models.BuiltInRoles. The migrations of the users which ran are stored
+// along with the custom roles, under names which can't be role names.
+type RolesTable struct {
+	Name   *string
+	client dynamodbiface.DynamoDBAPI
+}
+
+// The RolesTable must satisfy the API interface.
+var _ API = (*RolesTable)(nil)
+
+// New creates a new Dynamo client which talks to the given table name.
+func New(tableName string, sess *session.Session) *RolesTable {
+	return &RolesTable{Name: aws.String(tableName), client: dynamodb.New(sess)}
+}
+
+// DynamoItem is a type alias for the item format expected by the Dynamo SDK.
+type DynamoItem = map[string]*dynamodb.AttributeValue
+
+func roleKey(name *string) DynamoItem {
+	return DynamoItem{"name": {S: name}}
+}
+
+// Role names can't contain a colon, see models.PutRoleInput
+const migrationPrefix = "migration:"
+
+func isMigration(name *string) bool {
+	return strings.HasPrefix(*name, migrationPrefix)
+}
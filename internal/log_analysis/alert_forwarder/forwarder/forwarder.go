@@ -19,11 +19,13 @@ package forwarder
  */
 
 import (
+	"sort"
 	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
@@ -32,7 +34,10 @@ import (
 	alertModel "github.com/panther-labs/panther/internal/core/alert_delivery/models"
 )
 
-const defaultTimePartition = "defaultPartition"
+const (
+	defaultTimePartition = "defaultPartition"
+	alertIDKey           = "id"
+)
 
 func Store(event *AlertDedupEvent) error {
 	alert := &Alert{
@@ -45,11 +50,33 @@ func Store(event *AlertDedupEvent) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal alert")
 	}
-	putItemRequest := &dynamodb.PutItemInput{
-		Item:      marshaledAlert,
-		TableName: aws.String(env.AlertsTable),
+
+	// Only the fields of the alert from the dedup table are updated,
+	// the status, assignee and comments of the alert are kept
+	key := map[string]*dynamodb.AttributeValue{alertIDKey: marshaledAlert[alertIDKey]}
+	delete(marshaledAlert, alertIDKey)
+	names := make([]string, 0, len(marshaledAlert))
+	for name := range marshaledAlert {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var update expression.UpdateBuilder
+	for _, name := range names {
+		update = update.Set(expression.Name(name), expression.Value(marshaledAlert[name]))
+	}
+	updateExpression, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return errors.Wrap(err, "failed to build update expression")
+	}
+
+	updateItemRequest := &dynamodb.UpdateItemInput{
+		Key:                       key,
+		TableName:                 aws.String(env.AlertsTable),
+		ExpressionAttributeNames:  updateExpression.Names(),
+		ExpressionAttributeValues: updateExpression.Values(),
+		UpdateExpression:          updateExpression.Update(),
 	}
-	_, err = ddbClient.PutItem(putItemRequest)
+	_, err = ddbClient.UpdateItem(updateItemRequest)
 	if err != nil {
 		return errors.Wrap(err, "failed to update store alert")
 	}
@@ -74,6 +101,7 @@ func SendAlert(event *AlertDedupEvent) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to send notification")
 	}
+	publishAlertMetrics(event)
 	return nil
 }
 
@@ -100,6 +128,7 @@ func getAlert(alert *AlertDedupEvent) (*alertModel.Alert, error) {
 		Runbook:           aws.String(string(rule.Payload.Runbook)),
 		Severity:          aws.String(alert.Severity),
 		Tags:              aws.StringSlice(rule.Payload.Tags),
+		LogTypes:          aws.StringSlice(alert.LogTypes),
 		Type:              aws.String(alertModel.RuleType),
 		AlertID:           aws.String(generateAlertID(alert)),
 	}, nil
@@ -19,7 +19,9 @@ package forwarder
  */
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -47,9 +49,9 @@ type mockDynamoDB struct {
 	mock.Mock
 }
 
-func (m *mockDynamoDB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+func (m *mockDynamoDB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
 	args := m.Called(input)
-	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
 }
 
 type mockSqs struct {
@@ -111,13 +113,24 @@ func TestStore(t *testing.T) {
 	expectedMarshaledAlert, err := dynamodbattribute.MarshalMap(expectedAlert)
 	assert.NoError(t, err)
 
-	expectedPutItemRequest := &dynamodb.PutItemInput{
-		Item:      expectedMarshaledAlert,
-		TableName: aws.String("alertsTable"),
-	}
-
-	ddbMock.On("PutItem", expectedPutItemRequest).Return(&dynamodb.PutItemOutput{}, nil)
+	ddbMock.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
 	assert.NoError(t, Store(testAlertDedupEvent))
+
+	request := ddbMock.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Equal(t, aws.String("alertsTable"), request.TableName)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{"id": expectedMarshaledAlert["id"]}, request.Key)
+	// Every other field of the alert is set, the fields of the triage workflow are not changed
+	var expectedValues, values []*dynamodb.AttributeValue
+	for name, value := range expectedMarshaledAlert {
+		if name != "id" {
+			expectedValues = append(expectedValues, value)
+		}
+	}
+	for _, value := range request.ExpressionAttributeValues {
+		values = append(values, value)
+	}
+	assert.ElementsMatch(t, expectedValues, values)
+	assert.NotContains(t, *request.UpdateExpression, "REMOVE")
 }
 
 // The handler signatures must match those in the LambdaInput struct.
@@ -125,7 +138,7 @@ func TestStoreDDBError(t *testing.T) {
 	ddbMock := &mockDynamoDB{}
 	ddbClient = ddbMock
 
-	ddbMock.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, errors.New("error"))
+	ddbMock.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, errors.New("error"))
 	assert.Error(t, Store(testAlertDedupEvent))
 }
 
@@ -150,6 +163,7 @@ func TestSendAlert(t *testing.T) {
 		Runbook:           aws.String("Runbook"),
 		Severity:          aws.String(testAlertDedupEvent.Severity),
 		Tags:              aws.StringSlice([]string{"Tag"}),
+		LogTypes:          aws.StringSlice(testAlertDedupEvent.LogTypes),
 		Type:              aws.String(alertModel.RuleType),
 		AlertID:           aws.String("ruleId:dedupString:10"),
 	}
@@ -162,7 +176,17 @@ func TestSendAlert(t *testing.T) {
 
 	mockRoundTripper.On("RoundTrip", mock.Anything).Return(generateResponse(testRuleResponse, http.StatusOK), nil).Once()
 	sqsMock.On("SendMessage", expectedSendMessageInput).Return(&sqs.SendMessageOutput{}, nil)
+
+	var metricsOut bytes.Buffer
+	defer func(stdout io.Writer) { metricsLogger.Out = stdout }(metricsLogger.Out)
+	metricsLogger.Out = &metricsOut
 	assert.NoError(t, SendAlert(testAlertDedupEvent))
+
+	// An alert is counted for each log type
+	lines := strings.Split(strings.TrimSpace(metricsOut.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"AlertsCreated":1,"LogType":"Log.Type.1"`)
+	assert.Contains(t, lines[1], `"AlertsCreated":1,"LogType":"Log.Type.2"`)
 }
 
 func TestSendAlertFailureToGetRule(t *testing.T) {
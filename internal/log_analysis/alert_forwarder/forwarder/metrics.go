@@ -0,0 +1,42 @@
+package forwarder
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/metrics/models"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+var metricsLogger = metrics.NewLogger([]string{models.DimensionLogType})
+
+// publishAlertMetrics counts a new alert for each log type of its events.
+//
+// A failure is logged but does not fail sending the alert.
+func publishAlertMetrics(event *AlertDedupEvent) {
+	for _, logType := range event.LogTypes {
+		err := metricsLogger.Log(map[string]string{models.DimensionLogType: logType},
+			metrics.Metric{Name: models.MetricAlertsCreated, Unit: metrics.UnitCount, Value: 1})
+		if err != nil {
+			zap.L().Warn("failed to publish alert metrics", zap.Error(err))
+			return
+		}
+	}
+}
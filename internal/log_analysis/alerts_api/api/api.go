@@ -24,6 +24,8 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	jsoniter "github.com/json-iterator/go"
@@ -36,10 +38,11 @@ import (
 type API struct{}
 
 var (
-	env        envConfig
-	awsSession *session.Session
-	alertsDB   table.API
-	s3Client   s3iface.S3API
+	env          envConfig
+	awsSession   *session.Session
+	alertsDB     table.API
+	s3Client     s3iface.S3API
+	lambdaClient lambdaiface.LambdaAPI
 )
 
 type envConfig struct {
@@ -49,6 +52,8 @@ type envConfig struct {
 	RuleIndexName       string `required:"true" split_words:"true"`
 	TimeIndexName       string `required:"true" split_words:"true"`
 	ProcessedDataBucket string `required:"true" split_words:"true"`
+	// Closes the ServiceNow incidents of the resolved alerts, if ServiceNow is deployed
+	ServicenowSyncFunction string `split_words:"true"`
 }
 
 // Setup parses the environment and builds the AWS and http clients.
@@ -63,6 +68,7 @@ func Setup() {
 		TimePartitionCreationTimeIndexName: env.TimeIndexName,
 	}
 	s3Client = s3.New(awsSession)
+	lambdaClient = lambda.New(awsSession)
 }
 
 // Token used for paginating through the events in an alert
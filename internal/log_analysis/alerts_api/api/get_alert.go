@@ -97,6 +97,14 @@ func (API) GetAlert(input *models.GetAlertInput) (result *models.GetAlertOutput,
 		EventsMatched:          &alertItem.EventCount,
 		Events:                 aws.StringSlice(events),
 		EventsLastEvaluatedKey: aws.String(encodedToken),
+		Status:                 aws.String(alertStatus(alertItem)),
+		Assignee:               optionalString(alertItem.Assignee),
+		LastUpdatedBy:          optionalString(alertItem.LastUpdatedBy),
+		LastUpdatedByTime:      alertItem.LastUpdatedByTime,
+		Comments:               make([]*models.Comment, len(alertItem.Comments)),
+	}
+	for i, comment := range alertItem.Comments {
+		result.Comments[i] = commentItemToComment(comment)
 	}
 
 	gatewayapi.ReplaceMapSliceNils(result)
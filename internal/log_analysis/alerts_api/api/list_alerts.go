@@ -19,6 +19,8 @@ package api
  */
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
+
 	"github.com/panther-labs/panther/api/lambda/alerts/models"
 	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
@@ -35,10 +37,11 @@ func (API) ListAlerts(input *models.ListAlertsInput) (result *models.ListAlertsO
 
 	result = &models.ListAlertsOutput{}
 	var alertItems []*table.AlertItem
+	filter := &table.ListFilter{Status: input.Status, Assignee: input.Assignee}
 	if input.RuleID != nil { // list per specific ruleId
-		alertItems, result.LastEvaluatedKey, err = alertsDB.ListByRule(*input.RuleID, input.ExclusiveStartKey, input.PageSize)
+		alertItems, result.LastEvaluatedKey, err = alertsDB.ListByRule(*input.RuleID, input.ExclusiveStartKey, input.PageSize, filter)
 	} else { // list all alerts time desc order
-		alertItems, result.LastEvaluatedKey, err = alertsDB.ListAll(input.ExclusiveStartKey, input.PageSize)
+		alertItems, result.LastEvaluatedKey, err = alertsDB.ListAll(input.ExclusiveStartKey, input.PageSize, filter)
 	}
 	if err != nil {
 		return nil, err
@@ -55,15 +58,41 @@ func alertItemsToAlertSummary(items []*table.AlertItem) []*models.AlertSummary {
 	result := make([]*models.AlertSummary, len(items))
 
 	for i, item := range items {
-		result[i] = &models.AlertSummary{
-			AlertID:       &item.AlertID,
-			RuleID:        &item.RuleID,
-			CreationTime:  &item.CreationTime,
-			Severity:      &item.Severity,
-			UpdateTime:    &item.UpdateTime,
-			EventsMatched: &item.EventCount,
-		}
+		result[i] = alertItemToAlertSummary(item)
 	}
 
 	return result
 }
+
+// alertItemToAlertSummary converts a DDB Alert Item to the Alert Summary of a single alert
+func alertItemToAlertSummary(item *table.AlertItem) *models.AlertSummary {
+	return &models.AlertSummary{
+		AlertID:       &item.AlertID,
+		RuleID:        &item.RuleID,
+		CreationTime:  &item.CreationTime,
+		Severity:      &item.Severity,
+		UpdateTime:    &item.UpdateTime,
+		EventsMatched: &item.EventCount,
+		Status:        aws.String(alertStatus(item)),
+		Assignee:      optionalString(item.Assignee),
+	}
+}
+
+// commentItemToComment converts a DDB Comment Item to a Comment that will be returned by the API
+func commentItemToComment(item *table.CommentItem) *models.Comment {
+	return &models.Comment{
+		CommentID: &item.CommentID,
+		ParentID:  optionalString(item.ParentID),
+		UserID:    &item.UserID,
+		CreatedAt: &item.CreatedAt,
+		Body:      &item.Body,
+	}
+}
+
+// optionalString returns nil for an empty string
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
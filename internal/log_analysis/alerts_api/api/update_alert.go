@@ -0,0 +1,194 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	servicenowmodels "github.com/panther-labs/panther/api/lambda/servicenow/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// statusTransitions are the statuses an alert with each status can be changed to
+var statusTransitions = map[string][]string{
+	models.StatusOpen:          {models.StatusTriaged, models.StatusResolved, models.StatusFalsePositive},
+	models.StatusTriaged:       {models.StatusOpen, models.StatusResolved, models.StatusFalsePositive},
+	models.StatusResolved:      {models.StatusOpen},
+	models.StatusFalsePositive: {models.StatusOpen},
+}
+
+// UpdateAlertStatus changes the triage status of an alert.
+func (API) UpdateAlertStatus(input *models.UpdateAlertStatusInput) (result *models.UpdateAlertStatusOutput, err error) {
+	operation := common.OpLogManager.Start("updateAlertStatus")
+	defer func() {
+		operation.Stop()
+		operation.Log(err)
+	}()
+
+	alertItem, err := getAlertItem(input.AlertID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentStatus := alertStatus(alertItem)
+	if currentStatus == *input.Status {
+		return alertItemToAlertSummary(alertItem), nil
+	}
+	if !canTransition(currentStatus, *input.Status) {
+		return nil, &genericapi.InvalidInputError{
+			Message: "alert " + *input.AlertID + " can't be changed from " + currentStatus + " to " + *input.Status}
+	}
+
+	alertItem, err = alertsDB.UpdateAlertStatus(input.AlertID, currentStatus, *input.Status, *input.UserID, time.Now().UTC())
+	if err == table.ErrConditionalCheckFailed {
+		return nil, &genericapi.ConflictError{Message: "alert " + *input.AlertID + " was modified, try again"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if *input.Status == models.StatusResolved || *input.Status == models.StatusFalsePositive {
+		syncAlertStatus(input.AlertID, input.Status)
+	}
+	return alertItemToAlertSummary(alertItem), nil
+}
+
+// syncAlertStatus closes the ServiceNow incidents of a resolved alert in the background.
+//
+// The alert is resolved regardless, a failure to start the sync is only logged.
+func syncAlertStatus(alertID, status *string) {
+	if env.ServicenowSyncFunction == "" {
+		return
+	}
+	payload, err := jsoniter.Marshal(&servicenowmodels.LambdaInput{
+		SyncAlertStatus: &servicenowmodels.SyncAlertStatusInput{AlertID: alertID, Status: status},
+	})
+	if err == nil {
+		_, err = lambdaClient.Invoke(&lambda.InvokeInput{
+			FunctionName:   aws.String(env.ServicenowSyncFunction),
+			InvocationType: aws.String(lambda.InvocationTypeEvent),
+			Payload:        payload,
+		})
+	}
+	if err != nil {
+		zap.L().Error("failed to sync the alert status to ServiceNow", zap.String("alertId", *alertID), zap.Error(err))
+	}
+}
+
+// UpdateAlertAssignee assigns an alert to an analyst, or removes its assignee.
+func (API) UpdateAlertAssignee(input *models.UpdateAlertAssigneeInput) (result *models.UpdateAlertAssigneeOutput, err error) {
+	operation := common.OpLogManager.Start("updateAlertAssignee")
+	defer func() {
+		operation.Stop()
+		operation.Log(err)
+	}()
+
+	alertItem, err := alertsDB.UpdateAlertAssignee(input.AlertID, input.Assignee, *input.UserID, time.Now().UTC())
+	if err == table.ErrConditionalCheckFailed {
+		return nil, &genericapi.DoesNotExistError{Message: "alert " + *input.AlertID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return alertItemToAlertSummary(alertItem), nil
+}
+
+// AddAlertComment adds a comment to an alert, or a reply to one of its comments.
+func (API) AddAlertComment(input *models.AddAlertCommentInput) (result *models.AddAlertCommentOutput, err error) {
+	operation := common.OpLogManager.Start("addAlertComment")
+	defer func() {
+		operation.Stop()
+		operation.Log(err)
+	}()
+
+	comment := &table.CommentItem{
+		CommentID: uuid.New().String(),
+		UserID:    *input.UserID,
+		CreatedAt: time.Now().UTC(),
+		Body:      *input.Body,
+	}
+
+	// Comments are never removed, the parent of a reply can be verified before the reply is added
+	if input.ParentID != nil {
+		alertItem, err := getAlertItem(input.AlertID)
+		if err != nil {
+			return nil, err
+		}
+		if !hasComment(alertItem, *input.ParentID) {
+			return nil, &genericapi.InvalidInputError{
+				Message: "alert " + *input.AlertID + " has no comment " + *input.ParentID}
+		}
+		comment.ParentID = *input.ParentID
+	}
+
+	err = alertsDB.AddAlertComment(input.AlertID, comment)
+	if err == table.ErrConditionalCheckFailed {
+		return nil, &genericapi.DoesNotExistError{Message: "alert " + *input.AlertID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return commentItemToComment(comment), nil
+}
+
+// getAlertItem returns an alert, or a DoesNotExistError if there isn't one with the alert ID
+func getAlertItem(alertID *string) (*table.AlertItem, error) {
+	alertItem, err := alertsDB.GetAlert(alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alertItem.AlertID == "" {
+		return nil, &genericapi.DoesNotExistError{Message: "alert " + *alertID}
+	}
+	return alertItem, nil
+}
+
+// alertStatus returns the status of an alert, the alerts stored without status are open
+func alertStatus(item *table.AlertItem) string {
+	if item.Status == "" {
+		return models.StatusOpen
+	}
+	return item.Status
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func hasComment(item *table.AlertItem, commentID string) bool {
+	for _, comment := range item.Comments {
+		if comment.CommentID == commentID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockLambdaClient struct {
+	lambdaiface.LambdaAPI
+	mock.Mock
+}
+
+func (m *mockLambdaClient) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*lambda.InvokeOutput), args.Error(1)
+}
+
+func (m *tableMock) UpdateAlertStatus(alertID *string, currentStatus, status, userID string, now time.Time) (*table.AlertItem, error) {
+	args := m.Called(alertID, currentStatus, status, userID, now)
+	return args.Get(0).(*table.AlertItem), args.Error(1)
+}
+
+func (m *tableMock) UpdateAlertAssignee(alertID, assignee *string, userID string, now time.Time) (*table.AlertItem, error) {
+	args := m.Called(alertID, assignee, userID, now)
+	return args.Get(0).(*table.AlertItem), args.Error(1)
+}
+
+func (m *tableMock) AddAlertComment(alertID *string, comment *table.CommentItem) error {
+	args := m.Called(alertID, comment)
+	return args.Error(0)
+}
+
+var testAlertItem = &table.AlertItem{
+	AlertID:      "alertId",
+	RuleID:       "ruleId",
+	CreationTime: time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC),
+	UpdateTime:   time.Date(2020, 1, 1, 1, 59, 0, 0, time.UTC),
+	Severity:     "INFO",
+	EventCount:   5,
+	LogTypes:     []string{"logtype"},
+}
+
+func TestUpdateAlertStatus(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+
+	triaged := *testAlertItem
+	triaged.Status = models.StatusTriaged
+	tableMock.On("GetAlert", aws.String("alertId")).Return(testAlertItem, nil)
+	tableMock.On("UpdateAlertStatus", aws.String("alertId"), models.StatusOpen, models.StatusTriaged, "userId", mock.Anything).
+		Return(&triaged, nil)
+
+	result, err := API{}.UpdateAlertStatus(&models.UpdateAlertStatusInput{
+		AlertID: aws.String("alertId"),
+		Status:  aws.String(models.StatusTriaged),
+		UserID:  aws.String("userId"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusTriaged, *result.Status)
+	tableMock.AssertExpectations(t)
+}
+
+func TestUpdateAlertStatusResolvedSync(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+	lambdaMock := &mockLambdaClient{}
+	lambdaClient = lambdaMock
+	env.ServicenowSyncFunction = "panther-servicenow-sync"
+	defer func() { env.ServicenowSyncFunction = "" }()
+
+	resolved := *testAlertItem
+	resolved.Status = models.StatusResolved
+	tableMock.On("GetAlert", aws.String("alertId")).Return(testAlertItem, nil)
+	tableMock.On("UpdateAlertStatus", aws.String("alertId"), models.StatusOpen, models.StatusResolved, "userId", mock.Anything).
+		Return(&resolved, nil)
+	lambdaMock.On("Invoke", &lambda.InvokeInput{
+		FunctionName:   aws.String("panther-servicenow-sync"),
+		InvocationType: aws.String(lambda.InvocationTypeEvent),
+		Payload:        []byte(`{"syncAlertStatus":{"alertId":"alertId","status":"RESOLVED"}}`),
+	}).Return(&lambda.InvokeOutput{}, nil)
+
+	result, err := API{}.UpdateAlertStatus(&models.UpdateAlertStatusInput{
+		AlertID: aws.String("alertId"),
+		Status:  aws.String(models.StatusResolved),
+		UserID:  aws.String("userId"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusResolved, *result.Status)
+	tableMock.AssertExpectations(t)
+	lambdaMock.AssertExpectations(t)
+}
+
+func TestUpdateAlertStatusInvalidTransition(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+
+	resolved := *testAlertItem
+	resolved.Status = models.StatusResolved
+	tableMock.On("GetAlert", aws.String("alertId")).Return(&resolved, nil)
+
+	result, err := API{}.UpdateAlertStatus(&models.UpdateAlertStatusInput{
+		AlertID: aws.String("alertId"),
+		Status:  aws.String(models.StatusFalsePositive),
+		UserID:  aws.String("userId"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	tableMock.AssertExpectations(t)
+}
+
+func TestUpdateAlertStatusModified(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+
+	tableMock.On("GetAlert", aws.String("alertId")).Return(testAlertItem, nil)
+	tableMock.On("UpdateAlertStatus", aws.String("alertId"), models.StatusOpen, models.StatusResolved, "userId", mock.Anything).
+		Return((*table.AlertItem)(nil), table.ErrConditionalCheckFailed)
+
+	result, err := API{}.UpdateAlertStatus(&models.UpdateAlertStatusInput{
+		AlertID: aws.String("alertId"),
+		Status:  aws.String(models.StatusResolved),
+		UserID:  aws.String("userId"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.ConflictError{}, err)
+}
+
+func TestUpdateAlertStatusDoesNotExist(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+
+	tableMock.On("GetAlert", aws.String("alertId")).Return(&table.AlertItem{}, nil)
+
+	result, err := API{}.UpdateAlertStatus(&models.UpdateAlertStatusInput{
+		AlertID: aws.String("alertId"),
+		Status:  aws.String(models.StatusResolved),
+		UserID:  aws.String("userId"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestUpdateAlertAssignee(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+
+	assigned := *testAlertItem
+	assigned.Assignee = "analyst"
+	tableMock.On("UpdateAlertAssignee", aws.String("alertId"), aws.String("analyst"), "userId", mock.Anything).
+		Return(&assigned, nil)
+
+	result, err := API{}.UpdateAlertAssignee(&models.UpdateAlertAssigneeInput{
+		AlertID:  aws.String("alertId"),
+		Assignee: aws.String("analyst"),
+		UserID:   aws.String("userId"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.AlertSummary{
+		AlertID:       aws.String("alertId"),
+		RuleID:        aws.String("ruleId"),
+		CreationTime:  aws.Time(testAlertItem.CreationTime),
+		UpdateTime:    aws.Time(testAlertItem.UpdateTime),
+		EventsMatched: aws.Int(5),
+		Severity:      aws.String("INFO"),
+		Status:        aws.String(models.StatusOpen),
+		Assignee:      aws.String("analyst"),
+	}, result)
+}
+
+func TestAddAlertCommentReply(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+
+	commented := *testAlertItem
+	commented.Comments = []*table.CommentItem{{CommentID: "commentId", UserID: "userId", Body: "first"}}
+	tableMock.On("GetAlert", aws.String("alertId")).Return(&commented, nil)
+	tableMock.On("AddAlertComment", aws.String("alertId"), mock.Anything).Return(nil)
+
+	result, err := API{}.AddAlertComment(&models.AddAlertCommentInput{
+		AlertID:  aws.String("alertId"),
+		ParentID: aws.String("commentId"),
+		Body:     aws.String("reply"),
+		UserID:   aws.String("userId"),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, *result.CommentID)
+	assert.Equal(t, "commentId", *result.ParentID)
+	assert.Equal(t, "reply", *result.Body)
+	tableMock.AssertExpectations(t)
+}
+
+func TestAddAlertCommentUnknownParent(t *testing.T) {
+	tableMock := &tableMock{}
+	alertsDB = tableMock
+
+	tableMock.On("GetAlert", aws.String("alertId")).Return(testAlertItem, nil)
+
+	result, err := API{}.AddAlertComment(&models.AddAlertCommentInput{
+		AlertID:  aws.String("alertId"),
+		ParentID: aws.String("commentId"),
+		Body:     aws.String("reply"),
+		UserID:   aws.String("userId"),
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
@@ -24,19 +24,27 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 
 	"github.com/panther-labs/panther/api/lambda/alerts/models"
+	usermodels "github.com/panther-labs/panther/api/lambda/users/models"
 	"github.com/panther-labs/panther/internal/log_analysis/alerts_api/api"
 	"github.com/panther-labs/panther/pkg/genericapi"
 	"github.com/panther-labs/panther/pkg/lambdalogger"
 )
 
-var router = genericapi.NewRouter("log_analysis", "alerts", nil, api.API{})
+var router = genericapi.NewRouter("log_analysis", "alerts", nil, api.API{}).
+	RequirePermission(usermodels.PermissionAlertModify, "AddAlertComment", "UpdateAlertAssignee", "UpdateAlertStatus").
+	RequirePermission(usermodels.PermissionAlertRead, "GetAlert", "ListAlerts")
 
 func lambdaHandler(ctx context.Context, input *models.LambdaInput) (interface{}, error) {
 	lambdalogger.ConfigureGlobal(ctx, nil)
 	event, err := router.Handle(input)
 	if err != nil {
-		// wrap for api, InternalError the only kind of error from this lambda
-		err = &genericapi.InternalError{Message: err.Error()}
+		switch err.(type) {
+		case *genericapi.InvalidInputError, *genericapi.DoesNotExistError, *genericapi.ConflictError, *genericapi.PermissionDeniedError:
+			// the client can correct these requests of the triage workflow, or ask for the permission
+		default:
+			// wrap for api, InternalError the only other kind of error from this lambda
+			err = &genericapi.InternalError{Message: err.Error()}
+		}
 	}
 	return event, err
 }
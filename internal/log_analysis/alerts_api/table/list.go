@@ -26,22 +26,24 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
 )
 
-func (table *AlertsTable) ListByRule(ruleID string, exclusiveStartKey *string, pageSize *int) (
+func (table *AlertsTable) ListByRule(ruleID string, exclusiveStartKey *string, pageSize *int, filter *ListFilter) (
 	summaries []*AlertItem, lastEvaluatedKey *string, err error) {
 
-	return table.list(RuleIDKey, ruleID, exclusiveStartKey, pageSize)
+	return table.list(RuleIDKey, ruleID, exclusiveStartKey, pageSize, filter)
 }
 
-func (table *AlertsTable) ListAll(exclusiveStartKey *string, pageSize *int) (
+func (table *AlertsTable) ListAll(exclusiveStartKey *string, pageSize *int, filter *ListFilter) (
 	summaries []*AlertItem, lastEvaluatedKey *string, err error) {
 
-	return table.list(TimePartitionKey, TimePartitionValue, exclusiveStartKey, pageSize)
+	return table.list(TimePartitionKey, TimePartitionValue, exclusiveStartKey, pageSize, filter)
 }
 
 // list returns a page of alerts ordered by creationTime, last evaluated key, any error
-func (table *AlertsTable) list(ddbKey, ddbValue string, exclusiveStartKey *string, pageSize *int) (
+func (table *AlertsTable) list(ddbKey, ddbValue string, exclusiveStartKey *string, pageSize *int, filter *ListFilter) (
 	summaries []*AlertItem, lastEvaluatedKey *string, err error) {
 
 	// pick index
@@ -57,9 +59,11 @@ func (table *AlertsTable) list(ddbKey, ddbValue string, exclusiveStartKey *strin
 	// queries require and = condition on primary key
 	keyCondition := expression.Key(ddbKey).Equal(expression.Value(&ddbValue))
 
-	queryExpression, err := expression.NewBuilder().
-		WithKeyCondition(keyCondition).
-		Build()
+	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
+	if condition, ok := filterCondition(filter); ok {
+		builder = builder.WithFilter(condition)
+	}
+	queryExpression, err := builder.Build()
 
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to build expression")
@@ -85,6 +89,7 @@ func (table *AlertsTable) list(ddbKey, ddbValue string, exclusiveStartKey *strin
 		ExpressionAttributeNames:  queryExpression.Names(),
 		ExpressionAttributeValues: queryExpression.Values(),
 		KeyConditionExpression:    queryExpression.KeyCondition(),
+		FilterExpression:          queryExpression.Filter(),
 		ExclusiveStartKey:         queryExclusiveStartKey,
 		IndexName:                 aws.String(index),
 		Limit:                     queryResultsLimit,
@@ -114,3 +119,27 @@ func (table *AlertsTable) list(ddbKey, ddbValue string, exclusiveStartKey *strin
 
 	return summaries, lastEvaluatedKey, nil
 }
+
+// filterCondition returns the condition of the alerts selected by the filter, false if it selects all the alerts
+func filterCondition(filter *ListFilter) (expression.ConditionBuilder, bool) {
+	var conditions []expression.ConditionBuilder
+	if filter != nil && filter.Status != nil {
+		condition := expression.Name(StatusKey).Equal(expression.Value(*filter.Status))
+		if *filter.Status == models.StatusOpen {
+			condition = condition.Or(expression.AttributeNotExists(expression.Name(StatusKey)))
+		}
+		conditions = append(conditions, condition)
+	}
+	if filter != nil && filter.Assignee != nil {
+		conditions = append(conditions, expression.Name(AssigneeKey).Equal(expression.Value(*filter.Assignee)))
+	}
+
+	switch len(conditions) {
+	case 0:
+		return expression.ConditionBuilder{}, false
+	case 1:
+		return conditions[0], true
+	default:
+		return conditions[0].And(conditions[1]), true
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -31,13 +32,28 @@ const (
 	AlertIDKey         = "id"
 	TimePartitionKey   = "timePartition"
 	TimePartitionValue = "defaultPartition"
+	StatusKey          = "status"
+	AssigneeKey        = "assignee"
+	CommentsKey        = "comments"
 )
 
+// ErrConditionalCheckFailed is returned if the alert to update doesn't exist or it was modified since it was read
+var ErrConditionalCheckFailed = errors.New("conditional check failed")
+
 // API defines the interface for the alerts table which can be used for mocking.
 type API interface {
 	GetAlert(*string) (*AlertItem, error)
-	ListByRule(string, *string, *int) ([]*AlertItem, *string, error)
-	ListAll(*string, *int) ([]*AlertItem, *string, error)
+	ListByRule(string, *string, *int, *ListFilter) ([]*AlertItem, *string, error)
+	ListAll(*string, *int, *ListFilter) ([]*AlertItem, *string, error)
+	UpdateAlertStatus(*string, string, string, string, time.Time) (*AlertItem, error)
+	UpdateAlertAssignee(*string, *string, string, time.Time) (*AlertItem, error)
+	AddAlertComment(*string, *CommentItem) error
+}
+
+// ListFilter selects the listed alerts by their status and assignee
+type ListFilter struct {
+	Status   *string
+	Assignee *string
 }
 
 // AlertsTable encapsulates a connection to the Dynamo alerts table.
@@ -63,4 +79,19 @@ type AlertItem struct {
 	Severity     string    `json:"severity"`
 	EventCount   int       `json:"eventCount"`
 	LogTypes     []string  `json:"logTypes"`
+	// The alerts stored before the triage workflow have no status, they are open
+	Status            string         `json:"status,omitempty"`
+	Assignee          string         `json:"assignee,omitempty"`
+	LastUpdatedBy     string         `json:"lastUpdatedBy,omitempty"`
+	LastUpdatedByTime *time.Time     `json:"lastUpdatedByTime,omitempty"`
+	Comments          []*CommentItem `json:"comments,omitempty"`
+}
+
+// CommentItem is a DDB representation of a comment on an Alert
+type CommentItem struct {
+	CommentID string    `json:"commentId"`
+	ParentID  string    `json:"parentId,omitempty"`
+	UserID    string    `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+	Body      string    `json:"body"`
 }
@@ -0,0 +1,117 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+const (
+	lastUpdatedByKey     = "lastUpdatedBy"
+	lastUpdatedByTimeKey = "lastUpdatedByTime"
+)
+
+// UpdateAlertStatus changes the status of an alert, unless its status isn't the current status anymore
+func (table *AlertsTable) UpdateAlertStatus(alertID *string, currentStatus, status, userID string, now time.Time) (*AlertItem, error) {
+	update := expression.
+		Set(expression.Name(StatusKey), expression.Value(status)).
+		Set(expression.Name(lastUpdatedByKey), expression.Value(userID)).
+		Set(expression.Name(lastUpdatedByTimeKey), expression.Value(now))
+
+	statusCondition := expression.Name(StatusKey).Equal(expression.Value(currentStatus))
+	if currentStatus == models.StatusOpen {
+		statusCondition = statusCondition.Or(expression.AttributeNotExists(expression.Name(StatusKey)))
+	}
+	condition := expression.AttributeExists(expression.Name(AlertIDKey)).And(statusCondition)
+
+	return table.updateAlert(alertID, expression.NewBuilder().WithUpdate(update).WithCondition(condition))
+}
+
+// UpdateAlertAssignee assigns an alert, or removes the assignee of the alert if it is nil
+func (table *AlertsTable) UpdateAlertAssignee(alertID, assignee *string, userID string, now time.Time) (*AlertItem, error) {
+	update := expression.
+		Set(expression.Name(lastUpdatedByKey), expression.Value(userID)).
+		Set(expression.Name(lastUpdatedByTimeKey), expression.Value(now))
+	if assignee == nil {
+		update = update.Remove(expression.Name(AssigneeKey))
+	} else {
+		update = update.Set(expression.Name(AssigneeKey), expression.Value(*assignee))
+	}
+	condition := expression.AttributeExists(expression.Name(AlertIDKey))
+
+	return table.updateAlert(alertID, expression.NewBuilder().WithUpdate(update).WithCondition(condition))
+}
+
+// AddAlertComment appends a comment to the comments of an alert
+func (table *AlertsTable) AddAlertComment(alertID *string, comment *CommentItem) error {
+	comments, err := dynamodbattribute.Marshal([]*CommentItem{comment})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal comment")
+	}
+
+	// The comments of an alert are created with its first comment
+	update := expression.Set(expression.Name(CommentsKey), expression.ListAppend(
+		expression.IfNotExists(expression.Name(CommentsKey), expression.Value(&dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}})),
+		expression.Value(comments)))
+	condition := expression.AttributeExists(expression.Name(AlertIDKey))
+
+	_, err = table.updateAlert(alertID, expression.NewBuilder().WithUpdate(update).WithCondition(condition))
+	return err
+}
+
+// updateAlert applies the update of the expression to an alert and returns the updated alert
+func (table *AlertsTable) updateAlert(alertID *string, builder expression.Builder) (*AlertItem, error) {
+	updateExpression, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build update expression")
+	}
+
+	ddbResult, err := table.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			AlertIDKey: {S: alertID},
+		},
+		TableName:                 aws.String(table.AlertsTableName),
+		ConditionExpression:       updateExpression.Condition(),
+		ExpressionAttributeNames:  updateExpression.Names(),
+		ExpressionAttributeValues: updateExpression.Values(),
+		UpdateExpression:          updateExpression.Update(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil, ErrConditionalCheckFailed
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "UpdateItem() failed for: "+*alertID)
+	}
+
+	alertItem := &AlertItem{}
+	if err = dynamodbattribute.UnmarshalMap(ddbResult.Attributes, alertItem); err != nil {
+		return nil, errors.Wrap(err, "UnmarshalMap() failed for: "+*alertID)
+	}
+	return alertItem, nil
+}
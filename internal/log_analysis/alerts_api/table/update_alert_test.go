@@ -0,0 +1,91 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/alerts/models"
+)
+
+func (m *mockDynamoDB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
+func TestUpdateAlertStatus(t *testing.T) {
+	mockDdbClient := &mockDynamoDB{}
+	table := AlertsTable{AlertsTableName: "alertsTableName", Client: mockDdbClient}
+
+	now := time.Now().UTC()
+	expectedAlert := &AlertItem{
+		AlertID:           "alertId",
+		RuleID:            "ruleId",
+		Status:            models.StatusTriaged,
+		LastUpdatedBy:     "userId",
+		LastUpdatedByTime: &now,
+	}
+	item, err := dynamodbattribute.MarshalMap(expectedAlert)
+	require.NoError(t, err)
+
+	mockDdbClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+
+	result, err := table.UpdateAlertStatus(aws.String("alertId"), models.StatusOpen, models.StatusTriaged, "userId", now)
+	require.NoError(t, err)
+	assert.Equal(t, expectedAlert, result)
+
+	request := mockDdbClient.Calls[0].Arguments.Get(0).(*dynamodb.UpdateItemInput)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{"id": {S: aws.String("alertId")}}, request.Key)
+	// The alerts without status are open
+	assert.Equal(t, "(attribute_exists (#0)) AND ((#1 = :0) OR (attribute_not_exists (#1)))", *request.ConditionExpression)
+	assert.Equal(t, "SET #1 = :1, #2 = :2, #3 = :3\n", *request.UpdateExpression)
+}
+
+func TestUpdateAlertStatusConditionFailed(t *testing.T) {
+	mockDdbClient := &mockDynamoDB{}
+	table := AlertsTable{AlertsTableName: "alertsTableName", Client: mockDdbClient}
+
+	mockDdbClient.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "", nil))
+
+	result, err := table.UpdateAlertStatus(aws.String("alertId"), models.StatusTriaged, models.StatusResolved, "userId", time.Now())
+	assert.Nil(t, result)
+	assert.Equal(t, ErrConditionalCheckFailed, err)
+}
+
+func TestFilterCondition(t *testing.T) {
+	_, ok := filterCondition(&ListFilter{})
+	assert.False(t, ok)
+
+	condition, ok := filterCondition(&ListFilter{Status: aws.String(models.StatusResolved), Assignee: aws.String("analyst")})
+	require.True(t, ok)
+	filter, err := expression.NewBuilder().WithFilter(condition).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "(#0 = :0) AND (#1 = :1)", *filter.Filter())
+}
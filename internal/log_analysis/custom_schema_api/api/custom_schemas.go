@@ -0,0 +1,46 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetCustomSchema returns a custom schema by its name.
+func (API) GetCustomSchema(input *models.GetCustomSchemaInput) (*models.GetCustomSchemaOutput, error) {
+	schema, err := schemasTable.GetSchema(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "custom schema " + *input.Name + " does not exist"}
+	}
+	return schema, nil
+}
+
+// ListCustomSchemas returns all the custom schemas.
+func (API) ListCustomSchemas(_ *models.ListCustomSchemasInput) (models.ListCustomSchemasOutput, error) {
+	return schemasTable.ListSchemas()
+}
+
+// DeleteCustomSchema deletes a custom schema, its Glue tables are kept.
+func (API) DeleteCustomSchema(input *models.DeleteCustomSchemaInput) error {
+	return schemasTable.DeleteSchema(input.Name)
+}
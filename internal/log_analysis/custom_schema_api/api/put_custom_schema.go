@@ -0,0 +1,109 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	schemamodels "github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/customlogs"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// PutCustomSchema creates or updates a custom schema and its Glue tables.
+//
+// The tables are written before the schema, so the log processor never classifies events without a table.
+func (API) PutCustomSchema(input *schemamodels.PutCustomSchemaInput) (*schemamodels.PutCustomSchemaOutput, error) {
+	now := nowFunc().UTC()
+	schema := &schemamodels.CustomSchema{
+		Name:            input.Name,
+		LogType:         aws.String(customlogs.LogType(*input.Name)),
+		Description:     input.Description,
+		Layout:          input.Layout,
+		Fields:          input.Fields,
+		TimestampField:  input.TimestampField,
+		TimestampFormat: input.TimestampFormat,
+		Delimiter:       input.Delimiter,
+		Pattern:         input.Pattern,
+		CreatedAtTime:   &now,
+		CreatedBy:       input.UserID,
+		LastModified:    &now,
+		LastModifiedBy:  input.UserID,
+	}
+	if _, err := customlogs.NewParser(schema); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+
+	existing, err := schemasTable.GetSchema(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if err = checkFieldsKept(existing, schema); err != nil {
+			return nil, err
+		}
+		schema.CreatedAtTime, schema.CreatedBy = existing.CreatedAtTime, existing.CreatedBy
+	}
+
+	if err = putTables(schema); err != nil {
+		return nil, err
+	}
+	if err = schemasTable.PutSchema(schema); err != nil {
+		return nil, err
+	}
+	zap.L().Info("put custom schema", zap.String("logType", *schema.LogType), zap.Bool("created", existing == nil))
+	return schema, nil
+}
+
+// The processed events keep the columns of the existing fields, so an update can only add fields
+func checkFieldsKept(existing, schema *schemamodels.CustomSchema) error {
+	types := make(map[string]string, len(schema.Fields))
+	for _, field := range schema.Fields {
+		types[*field.Name] = *field.Type
+	}
+	for _, field := range existing.Fields {
+		fieldType, ok := types[*field.Name]
+		if !ok {
+			return &genericapi.InvalidInputError{Message: "field " + *field.Name + " can't be removed from the schema"}
+		}
+		if fieldType != *field.Type {
+			return &genericapi.InvalidInputError{
+				Message: "field " + *field.Name + " can't change its type from " + *field.Type + " to " + fieldType,
+			}
+		}
+	}
+	return nil
+}
+
+// putTables creates or updates the tables of the events and of the rule matches of a schema
+func putTables(schema *schemamodels.CustomSchema) error {
+	description := aws.StringValue(schema.Description)
+	logsTable := awsglue.NewGlueTableMetadata(models.LogData, *schema.LogType, description, awsglue.GlueTableHourly, nil)
+	if err := logsTable.CreateOrUpdateJSONTable(glueClient, env.ProcessedDataBucket, customlogs.Columns(schema)); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "glue.CreateTable"}
+	}
+	ruleTable := awsglue.NewGlueTableMetadata(models.RuleData, *schema.LogType, description, awsglue.GlueTableHourly, nil)
+	if err := ruleTable.CreateOrUpdateJSONTable(glueClient, env.ProcessedDataBucket, customlogs.RuleMatchColumns(schema)); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "glue.CreateTable"}
+	}
+	return nil
+}
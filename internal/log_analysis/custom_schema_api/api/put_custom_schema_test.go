@@ -0,0 +1,177 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testUserID = "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+
+var testTime = time.Date(2020, 3, 14, 21, 37, 42, 0, time.UTC)
+
+type mockTable struct {
+	mock.Mock
+}
+
+func (m *mockTable) GetSchema(name *string) (*models.CustomSchema, error) {
+	args := m.Called(name)
+	schema, _ := args.Get(0).(*models.CustomSchema)
+	return schema, args.Error(1)
+}
+
+func (m *mockTable) ListSchemas() ([]*models.CustomSchema, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.CustomSchema), args.Error(1)
+}
+
+func (m *mockTable) PutSchema(schema *models.CustomSchema) error {
+	return m.Called(schema).Error(0)
+}
+
+func (m *mockTable) DeleteSchema(name *string) error {
+	return m.Called(name).Error(0)
+}
+
+type mockGlue struct {
+	glueiface.GlueAPI
+	mock.Mock
+}
+
+func (m *mockGlue) CreateTable(input *glue.CreateTableInput) (*glue.CreateTableOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.CreateTableOutput), args.Error(1)
+}
+
+func (m *mockGlue) UpdateTable(input *glue.UpdateTableInput) (*glue.UpdateTableOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.UpdateTableOutput), args.Error(1)
+}
+
+func setupMocks() (*mockTable, *mockGlue) {
+	mockSchemas, mockGlueClient := &mockTable{}, &mockGlue{}
+	schemasTable, glueClient = mockSchemas, mockGlueClient
+	env.ProcessedDataBucket = "processed-data"
+	nowFunc = func() time.Time { return testTime }
+	return mockSchemas, mockGlueClient
+}
+
+func testInput() *models.PutCustomSchemaInput {
+	return &models.PutCustomSchemaInput{
+		Name:   aws.String("FirewallAudit"),
+		Layout: aws.String(models.LayoutCSV),
+		Fields: []*models.CustomSchemaField{
+			{Name: aws.String("time"), Type: aws.String(models.FieldTypeTimestamp)},
+			{Name: aws.String("src_ip"), Type: aws.String(models.FieldTypeString), Indicator: aws.String(models.IndicatorIP)},
+		},
+		TimestampField:  aws.String("time"),
+		TimestampFormat: aws.String("unix"),
+		UserID:          aws.String(testUserID),
+	}
+}
+
+func TestPutCustomSchema(t *testing.T) {
+	mockSchemas, mockGlueClient := setupMocks()
+	mockSchemas.On("GetSchema", aws.String("FirewallAudit")).Return(nil, nil)
+	mockSchemas.On("PutSchema", mock.Anything).Return(nil)
+	mockGlueClient.On("CreateTable", mock.Anything).Return(&glue.CreateTableOutput{}, nil).Twice()
+
+	result, err := (API{}).PutCustomSchema(testInput())
+	require.NoError(t, err)
+	assert.Equal(t, "Custom.FirewallAudit", *result.LogType)
+	assert.Equal(t, testTime, *result.CreatedAtTime)
+	assert.Equal(t, testUserID, *result.CreatedBy)
+	mockSchemas.AssertExpectations(t)
+	mockGlueClient.AssertExpectations(t)
+
+	logsTable := mockGlueClient.Calls[0].Arguments.Get(0).(*glue.CreateTableInput)
+	assert.Equal(t, "panther_logs", *logsTable.DatabaseName)
+	assert.Equal(t, "custom_firewallaudit", *logsTable.TableInput.Name)
+	assert.Equal(t, "s3://processed-data/logs/custom_firewallaudit/", *logsTable.TableInput.StorageDescriptor.Location)
+	assert.Equal(t, "time", *logsTable.TableInput.StorageDescriptor.Columns[0].Name)
+	assert.Equal(t, "timestamp", *logsTable.TableInput.StorageDescriptor.Columns[0].Type)
+	ruleTable := mockGlueClient.Calls[1].Arguments.Get(0).(*glue.CreateTableInput)
+	assert.Equal(t, "panther_rule_matches", *ruleTable.DatabaseName)
+	assert.Len(t, ruleTable.TableInput.StorageDescriptor.Columns, len(logsTable.TableInput.StorageDescriptor.Columns)+4)
+}
+
+func TestPutCustomSchemaUpdate(t *testing.T) {
+	mockSchemas, mockGlueClient := setupMocks()
+	created := testTime.Add(-time.Hour)
+	mockSchemas.On("GetSchema", aws.String("FirewallAudit")).Return(&models.CustomSchema{
+		Name:          aws.String("FirewallAudit"),
+		Fields:        testInput().Fields,
+		CreatedAtTime: &created,
+		CreatedBy:     aws.String("creator"),
+	}, nil)
+	mockSchemas.On("PutSchema", mock.Anything).Return(nil)
+	exists := awserr.New(glue.ErrCodeAlreadyExistsException, "table exists", nil)
+	mockGlueClient.On("CreateTable", mock.Anything).Return(&glue.CreateTableOutput{}, exists).Twice()
+	mockGlueClient.On("UpdateTable", mock.Anything).Return(&glue.UpdateTableOutput{}, nil).Twice()
+
+	input := testInput()
+	input.Fields = append(input.Fields, &models.CustomSchemaField{Name: aws.String("bytes"), Type: aws.String(models.FieldTypeBigInt)})
+	result, err := (API{}).PutCustomSchema(input)
+	require.NoError(t, err)
+	assert.Equal(t, created, *result.CreatedAtTime)
+	assert.Equal(t, "creator", *result.CreatedBy)
+	assert.Equal(t, testTime, *result.LastModified)
+	mockGlueClient.AssertExpectations(t)
+}
+
+func TestPutCustomSchemaInvalid(t *testing.T) {
+	mockSchemas, mockGlueClient := setupMocks()
+	mockSchemas.On("GetSchema", aws.String("FirewallAudit")).Return(&models.CustomSchema{
+		Name:   aws.String("FirewallAudit"),
+		Fields: []*models.CustomSchemaField{{Name: aws.String("src_ip"), Type: aws.String(models.FieldTypeBigInt)}},
+	}, nil)
+
+	// The parser of the schema must be valid
+	input := testInput()
+	input.TimestampField = aws.String("src_ip")
+	_, err := (API{}).PutCustomSchema(input)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	// The type of an existing field can't change
+	_, err = (API{}).PutCustomSchema(testInput())
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockGlueClient.AssertNotCalled(t, "CreateTable", mock.Anything)
+	mockSchemas.AssertNotCalled(t, "PutSchema", mock.Anything)
+}
+
+func TestGetCustomSchemaDoesNotExist(t *testing.T) {
+	mockSchemas, _ := setupMocks()
+	mockSchemas.On("GetSchema", aws.String("Missing")).Return(nil, nil)
+
+	result, err := (API{}).GetCustomSchema(&models.GetCustomSchemaInput{Name: aws.String("Missing")})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
@@ -0,0 +1,131 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const hashKey = "name"
+
+// API defines the interface for the custom schemas table which can be used for mocking.
+type API interface {
+	GetSchema(name *string) (*models.CustomSchema, error)
+	ListSchemas() ([]*models.CustomSchema, error)
+	PutSchema(schema *models.CustomSchema) error
+	DeleteSchema(name *string) error
+}
+
+// SchemasTable encapsulates a connection to the Dynamo custom schemas table.
+type SchemasTable struct {
+	Name   string
+	Client dynamodbiface.DynamoDBAPI
+}
+
+// The SchemasTable must satisfy the API interface.
+var _ API = (*SchemasTable)(nil)
+
+// GetSchema returns a custom schema by its name, nil if it doesn't exist.
+func (table *SchemasTable) GetSchema(name *string) (*models.CustomSchema, error) {
+	output, err := table.Client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{hashKey: {S: name}},
+		TableName:      aws.String(table.Name),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+
+	var schema models.CustomSchema
+	if err = dynamodbattribute.UnmarshalMap(output.Item, &schema); err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalMap"}
+	}
+	return &schema, nil
+}
+
+// ListSchemas returns all the custom schemas, page by page.
+func (table *SchemasTable) ListSchemas() ([]*models.CustomSchema, error) {
+	schemas := make([]*models.CustomSchema, 0)
+	var unmarshalErr error
+	err := table.Client.ScanPages(&dynamodb.ScanInput{
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(table.Name),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var pageSchemas []*models.CustomSchema
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageSchemas); unmarshalErr != nil {
+			return false // stop paginating
+		}
+		schemas = append(schemas, pageSchemas...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.ScanPages"}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.AWSError{Err: unmarshalErr, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	return schemas, nil
+}
+
+// PutSchema writes a custom schema, replacing the one with the same name.
+func (table *SchemasTable) PutSchema(schema *models.CustomSchema) error {
+	item, err := dynamodbattribute.MarshalMap(schema)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	if _, err = table.Client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: aws.String(table.Name)}); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// DeleteSchema deletes a custom schema, a DoesNotExistError is returned if there is none with that name.
+func (table *SchemasTable) DeleteSchema(name *string) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(hashKey))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build DeleteSchema ddb expression: " + err.Error()}
+	}
+
+	_, err = table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Key:                      map[string]*dynamodb.AttributeValue{hashKey: {S: name}},
+		TableName:                aws.String(table.Name),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return &genericapi.DoesNotExistError{Message: "custom schema " + aws.StringValue(name) + " does not exist"}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
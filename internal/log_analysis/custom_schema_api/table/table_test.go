@@ -0,0 +1,77 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func TestGetSchema(t *testing.T) {
+	schema := &models.CustomSchema{Name: aws.String("FirewallAudit"), Layout: aws.String(models.LayoutJSON)}
+	item, err := dynamodbattribute.MarshalMap(schema)
+	require.NoError(t, err)
+	mockClient := &mockDynamoClient{}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	table := &SchemasTable{Name: "test", Client: mockClient}
+
+	result, err := table.GetSchema(aws.String("FirewallAudit"))
+	require.NoError(t, err)
+	assert.Equal(t, schema, result)
+
+	result, err = table.GetSchema(aws.String("Missing"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestDeleteSchemaDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+	table := &SchemasTable{Name: "test", Client: mockClient}
+
+	assert.IsType(t, &genericapi.DoesNotExistError{}, table.DeleteSchema(aws.String("Missing")))
+}
@@ -20,6 +20,7 @@ package main
 
 import (
 	"context"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -33,6 +34,7 @@ import (
 	"gopkg.in/go-playground/validator.v9"
 
 	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	scheduledqueries "github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 	"github.com/panther-labs/panther/pkg/awsglue"
 	"github.com/panther-labs/panther/pkg/lambdalogger"
@@ -73,7 +75,16 @@ func process(event events.SQSEvent) error {
 			continue
 		}
 
-		gluePartition, err := awsglue.GetPartitionFromS3(*notification.S3Bucket, *notification.S3ObjectKey)
+		// The object of the Parquet log types in the table is not the staging copy the rules engine reads
+		s3ObjectKey := notification.S3ObjectKey
+		if notification.TableS3ObjectKey != nil {
+			s3ObjectKey = notification.TableS3ObjectKey
+		}
+		// The results of the scheduled queries have no tables, their rule matches are not partitioned
+		if strings.HasPrefix(*s3ObjectKey, scheduledqueries.RuleMatchesKeyPrefix) {
+			continue
+		}
+		gluePartition, err := awsglue.GetPartitionFromS3(*notification.S3Bucket, *s3ObjectKey)
 		if err != nil {
 			zap.L().Error("failed to get partition information from notification",
 				zap.Any("notification", notification), zap.Error(errors.WithStack(err)))
@@ -91,6 +102,24 @@ func process(event events.SQSEvent) error {
 			return err
 		}
 		partitionPrefixCache[gluePartition.GetPartitionLocation()] = struct{}{}
+		createNextPartition(gluePartition)
 	}
 	return nil
 }
+
+// createNextPartition creates the partition of the next time range of a table as soon as data lands in it,
+// so that the data of the next hour is searchable before its first notification is processed.
+//
+// A failure is only logged: the partition is created anyway with the first data of the next hour.
+func createNextPartition(gluePartition *awsglue.GluePartition) {
+	next := gluePartition.NextPartition()
+	if _, ok := partitionPrefixCache[next.GetPartitionLocation()]; ok {
+		return
+	}
+	if err := next.CreatePartition(glueClient); err != nil {
+		zap.L().Warn("failed to create the next partition",
+			zap.String("location", next.GetPartitionLocation()), zap.Error(err))
+		return
+	}
+	partitionPrefixCache[next.GetPartitionLocation()] = struct{}{}
+}
@@ -45,8 +45,8 @@ func TestProcessSuccess(t *testing.T) {
 func TestProcessSuccessAlreadyCreatedPartition(t *testing.T) {
 	mockClient := initTest()
 
-	// We should attempt to create the partition only once. We shouldn't try to re-create it a second time
-	mockClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, nil).Once()
+	// We should attempt to create the partition (and the next one) only once. We shouldn't try to re-create it a second time
+	mockClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, nil).Twice()
 
 	// First object should invoke Glue API
 	assert.NoError(t, process(getEvent(t, "rules/table/year=2020/month=02/day=26/hour=15/rule_id=Rule.Id/item.json.gz")))
@@ -60,8 +60,8 @@ func TestProcessSuccessDontPopulateCacheOnFailure(t *testing.T) {
 
 	// First glue operation fails
 	mockClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, errors.New("err")).Once()
-	// Second glue operation succeeds
-	mockClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, nil).Once()
+	// Second glue operation succeeds, along with the creation of the next partition
+	mockClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, nil).Twice()
 
 	// First invocation fails
 	assert.Error(t, process(getEvent(t, "rules/table/year=2020/month=02/day=26/hour=15/rule_id=Rule.Id/item.json.gz")))
@@ -78,6 +78,53 @@ func TestProcessGlueFailure(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestProcessParquetTableObject(t *testing.T) {
+	mockClient := initTest()
+
+	for _, hour := range []string{"15", "16"} {
+		location := "s3://bucket/logs/table/year=2020/month=02/day=26/hour=" + hour + "/"
+		mockClient.On("CreatePartition", mock.MatchedBy(func(input *glue.CreatePartitionInput) bool {
+			return *input.PartitionInput.StorageDescriptor.Location == location &&
+				*input.PartitionInput.StorageDescriptor.SerdeInfo.SerializationLibrary ==
+					"org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"
+		})).Return(&glue.CreatePartitionOutput{}, nil).Once()
+	}
+	event := getEvent(t, "staging/logs/table/year=2020/month=02/day=26/hour=15/item.json.gz")
+	notification := &models.S3Notification{}
+	require.NoError(t, jsoniter.UnmarshalFromString(event.Records[0].Body, notification))
+	notification.TableS3ObjectKey = aws.String("logs/table/year=2020/month=02/day=26/hour=15/item.parquet")
+	body, err := jsoniter.MarshalToString(notification)
+	require.NoError(t, err)
+	event.Records[0].Body = body
+
+	assert.NoError(t, process(event))
+	mockClient.AssertExpectations(t)
+}
+
+func TestProcessCreatesNextPartition(t *testing.T) {
+	mockClient := initTest()
+
+	mockClient.On("CreatePartition", mock.MatchedBy(func(input *glue.CreatePartitionInput) bool {
+		return *input.PartitionInput.StorageDescriptor.Location == "s3://bucket/logs/table/year=2020/month=02/day=26/hour=23/"
+	})).Return(&glue.CreatePartitionOutput{}, nil).Once()
+	// The failure to create the next partition doesn't fail the notification, nor is it cached
+	mockClient.On("CreatePartition", mock.MatchedBy(func(input *glue.CreatePartitionInput) bool {
+		return *input.PartitionInput.StorageDescriptor.Location == "s3://bucket/logs/table/year=2020/month=02/day=27/hour=00/"
+	})).Return(&glue.CreatePartitionOutput{}, errors.New("throttled")).Once()
+
+	assert.NoError(t, process(getEvent(t, "logs/table/year=2020/month=02/day=26/hour=23/item.json.gz")))
+	assert.Len(t, partitionPrefixCache, 1)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProcessScheduledQueryRuleMatches(t *testing.T) {
+	mockClient := initTest()
+
+	assert.NoError(t, process(getEvent(t,
+		"rules/scheduledquery_failedlogins/year=2020/month=02/day=26/hour=15/rule_id=Rule.Id/item.json.gz")))
+	mockClient.AssertExpectations(t)
+}
+
 func TestProcessInvalidS3Key(t *testing.T) {
 	//Invalid keys should just be ignored
 	assert.NoError(t, process(getEvent(t, "test")))
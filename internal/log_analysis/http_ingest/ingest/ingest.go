@@ -0,0 +1,205 @@
+package ingest
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+const (
+	// The API keys of the http-ingest integrations are named after the integration, see the source-api
+	apiKeyPrefix = "panther-http-ingest-"
+
+	// The objects of an integration are under its own prefix, by the hour they were posted in
+	objectKeyFormat = "http-ingest/%s/%s/%s-%s.json.gz"
+)
+
+// The header of gzip data, JSON never starts with it
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// The integration of each API key, the keys of an integration don't change. A deleted key is rejected by
+// API Gateway before the request gets here.
+var integrationsOfKeys = make(map[string]string)
+
+// IngestResponse is the response to the events posted to an integration.
+type IngestResponse struct {
+	EventCount int `json:"eventCount"`
+}
+
+// ErrorResponse explains why the events posted to an integration were rejected.
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// IngestEvents writes the events posted to an http-ingest integration to the http-ingest bucket.
+//
+// The body is either a JSON array of events, or JSON events one after the other such as newline-delimited JSON.
+// Every event must be a JSON object. The body can be compressed with gzip.
+func IngestEvents(request *events.APIGatewayProxyRequest) *events.APIGatewayProxyResponse {
+	integrationID, err := integrationOfKey(request.RequestContext.Identity.APIKeyID)
+	if err != nil {
+		zap.L().Error("failed to get the integration of the API key", zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	if integrationID == "" {
+		return gatewayapi.MarshalResponse(&ErrorResponse{Message: "the API key isn't the one of an integration"},
+			http.StatusForbidden)
+	}
+
+	body, err := requestBody(request)
+	if err != nil {
+		return gatewayapi.MarshalResponse(&ErrorResponse{Message: err.Error()}, http.StatusBadRequest)
+	}
+	lines, count, err := readEvents(body)
+	if err != nil {
+		return gatewayapi.MarshalResponse(&ErrorResponse{Message: err.Error()}, http.StatusBadRequest)
+	}
+	if count == 0 {
+		return gatewayapi.MarshalResponse(&ErrorResponse{Message: "no events were posted"}, http.StatusBadRequest)
+	}
+
+	if err = writeEvents(integrationID, lines, time.Now().UTC()); err != nil {
+		zap.L().Error("failed to write the posted events",
+			zap.String("integrationId", integrationID),
+			zap.Error(err))
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+	zap.L().Debug("wrote posted events", zap.String("integrationId", integrationID), zap.Int("eventCount", count))
+	return gatewayapi.MarshalResponse(&IngestResponse{EventCount: count}, http.StatusAccepted)
+}
+
+// integrationOfKey returns the ID of the integration of an API key, or "" if the key isn't the one of an integration.
+func integrationOfKey(apiKeyID string) (string, error) {
+	if apiKeyID == "" {
+		return "", nil
+	}
+	if integrationID, ok := integrationsOfKeys[apiKeyID]; ok {
+		return integrationID, nil
+	}
+	key, err := apiGatewayClient.GetApiKey(&apigateway.GetApiKeyInput{ApiKey: aws.String(apiKeyID)})
+	if err != nil {
+		return "", errors.Wrap(err, "apigateway.GetApiKey failed")
+	}
+	if !strings.HasPrefix(aws.StringValue(key.Name), apiKeyPrefix) {
+		return "", nil
+	}
+	integrationsOfKeys[apiKeyID] = strings.TrimPrefix(*key.Name, apiKeyPrefix)
+	return integrationsOfKeys[apiKeyID], nil
+}
+
+// requestBody returns the body of a request, decoded and decompressed.
+func requestBody(request *events.APIGatewayProxyRequest) (io.Reader, error) {
+	body := []byte(request.Body)
+	if request.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(request.Body)
+		if err != nil {
+			return nil, errors.New("the body isn't valid base64")
+		}
+		body = decoded
+	}
+
+	// API Gateway passes compressed bodies as they are, whatever their headers
+	if bytes.HasPrefix(body, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, errors.New("the body isn't valid gzip")
+		}
+		return reader, nil
+	}
+	return bytes.NewReader(body), nil
+}
+
+// readEvents returns the events of a body one per line, and how many there are.
+func readEvents(body io.Reader) ([]byte, int, error) {
+	var lines bytes.Buffer
+	count := 0
+	decoder := json.NewDecoder(body)
+	for {
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			return lines.Bytes(), count, nil
+		}
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "the body isn't valid JSON")
+		}
+
+		// The events of an array are read one by one
+		values := []json.RawMessage{value}
+		if bytes.HasPrefix(value, []byte("[")) {
+			values = nil
+			if err = json.Unmarshal(value, &values); err != nil {
+				return nil, 0, errors.Wrap(err, "the body isn't valid JSON")
+			}
+		}
+		for _, event := range values {
+			if !bytes.HasPrefix(event, []byte("{")) {
+				return nil, 0, errors.Errorf("event %d isn't a JSON object", count+1)
+			}
+			if err = json.Compact(&lines, event); err != nil {
+				return nil, 0, errors.Wrap(err, "the body isn't valid JSON")
+			}
+			lines.WriteByte('\n')
+			count++
+		}
+	}
+}
+
+// writeEvents writes the events of an integration to an object of the http-ingest bucket, compressed with gzip.
+func writeEvents(integrationID string, lines []byte, now time.Time) error {
+	var object bytes.Buffer
+	writer := gzip.NewWriter(&object)
+	if _, err := writer.Write(lines); err != nil {
+		return errors.Wrap(err, "failed to compress the events")
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "failed to compress the events")
+	}
+
+	key := fmt.Sprintf(objectKeyFormat, integrationID, now.Format("2006/01/02/15"), now.Format("20060102T150405Z"),
+		uuid.New().String())
+	_, err := s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(env.HTTPIngestBucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(object.Bytes()),
+		ContentType: aws.String("application/x-gzip"),
+	})
+	if err != nil {
+		return errors.Wrap(err, "s3.PutObject failed")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package ingest
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAPIKeyID      = "key-id"
+	testIntegrationID = "45c378a7-2e36-4b12-8e16-2d3c49ff1371"
+)
+
+type mockS3Client struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (client *mockS3Client) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+type mockAPIGatewayClient struct {
+	apigatewayiface.APIGatewayAPI
+	mock.Mock
+}
+
+func (client *mockAPIGatewayClient) GetApiKey(input *apigateway.GetApiKeyInput) (*apigateway.ApiKey, error) {
+	args := client.Called(input)
+	return args.Get(0).(*apigateway.ApiKey), args.Error(1)
+}
+
+func setupMocks(keyName string) (*mockS3Client, *mockAPIGatewayClient) {
+	env.HTTPIngestBucket = "panther-http-ingest"
+	integrationsOfKeys = make(map[string]string)
+	mockS3, mockAPIGateway := &mockS3Client{}, &mockAPIGatewayClient{}
+	s3Client, apiGatewayClient = mockS3, mockAPIGateway
+	mockAPIGateway.On("GetApiKey", &apigateway.GetApiKeyInput{ApiKey: aws.String(testAPIKeyID)}).
+		Return(&apigateway.ApiKey{Id: aws.String(testAPIKeyID), Name: aws.String(keyName)}, nil)
+	mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil)
+	return mockS3, mockAPIGateway
+}
+
+func ingestRequest(body string) *events.APIGatewayProxyRequest {
+	request := &events.APIGatewayProxyRequest{Body: body}
+	request.RequestContext.Identity.APIKeyID = testAPIKeyID
+	return request
+}
+
+// writtenEvents returns the events written to the object of a PutObject call
+func writtenEvents(t *testing.T, call mock.Call) string {
+	input := call.Arguments.Get(0).(*s3.PutObjectInput)
+	reader, err := gzip.NewReader(input.Body)
+	require.NoError(t, err)
+	lines, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	return string(lines)
+}
+
+func TestIngestEvents(t *testing.T) {
+	mockS3, _ := setupMocks("panther-http-ingest-" + testIntegrationID)
+
+	response := IngestEvents(ingestRequest("{\"user\": \"alice\"}\n{\"user\": \"bob\"}\n"))
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, `{"eventCount":2}`, response.Body)
+
+	require.Len(t, mockS3.Calls, 1)
+	input := mockS3.Calls[0].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Equal(t, "panther-http-ingest", *input.Bucket)
+	assert.True(t, strings.HasPrefix(*input.Key, "http-ingest/"+testIntegrationID+"/"), *input.Key)
+	assert.True(t, strings.HasSuffix(*input.Key, ".json.gz"), *input.Key)
+	assert.Equal(t, "{\"user\":\"alice\"}\n{\"user\":\"bob\"}\n", writtenEvents(t, mockS3.Calls[0]))
+}
+
+func TestIngestEventsArray(t *testing.T) {
+	mockS3, mockAPIGateway := setupMocks("panther-http-ingest-" + testIntegrationID)
+
+	response := IngestEvents(ingestRequest(`[{"user": "alice"}, {"user": "bob"}]`))
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, "{\"user\":\"alice\"}\n{\"user\":\"bob\"}\n", writtenEvents(t, mockS3.Calls[0]))
+
+	// The integration of the key is cached
+	response = IngestEvents(ingestRequest(`{"user": "carol"}`))
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	mockAPIGateway.AssertNumberOfCalls(t, "GetApiKey", 1)
+}
+
+func TestIngestEventsGzip(t *testing.T) {
+	mockS3, _ := setupMocks("panther-http-ingest-" + testIntegrationID)
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	_, err := writer.Write([]byte(`{"user": "alice"}`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	request := ingestRequest(base64.StdEncoding.EncodeToString(body.Bytes()))
+	request.IsBase64Encoded = true
+	response := IngestEvents(request)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, "{\"user\":\"alice\"}\n", writtenEvents(t, mockS3.Calls[0]))
+}
+
+func TestIngestEventsInvalid(t *testing.T) {
+	for body, message := range map[string]string{
+		`{"user": "alice"} {"user"`: "the body isn't valid JSON: unexpected EOF",
+		`{"user": "alice"} "bob"`:   "event 2 isn't a JSON object",
+		`[{"user": "alice"}, 1]`:    "event 2 isn't a JSON object",
+		"  \n":                      "no events were posted",
+	} {
+		mockS3, _ := setupMocks("panther-http-ingest-" + testIntegrationID)
+		response := IngestEvents(ingestRequest(body))
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, body)
+		assert.Equal(t, `{"message":"`+message+`"}`, response.Body, body)
+		mockS3.AssertNotCalled(t, "PutObject", mock.Anything)
+	}
+}
+
+func TestIngestEventsNotAnIntegrationKey(t *testing.T) {
+	mockS3, _ := setupMocks("another-key")
+
+	response := IngestEvents(ingestRequest(`{"user": "alice"}`))
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	mockS3.AssertNotCalled(t, "PutObject", mock.Anything)
+}
@@ -0,0 +1,264 @@
+package reader
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/sources"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const sourceAPIFunctionName = "panther-source-api"
+
+var (
+	lambdaClient lambdaiface.LambdaAPI = lambda.New(common.Session)
+
+	// The client reading a stream assumes the role of its integration, in the region of the stream
+	kinesisClientFunc = func(awsCreds *credentials.Credentials, region string) kinesisiface.KinesisAPI {
+		return kinesis.New(common.Session, &aws.Config{Credentials: awsCreds, Region: aws.String(region)})
+	}
+
+	listIntegrationsFunc = func() (integrations []*models.SourceIntegration, err error) {
+		err = genericapi.Invoke(lambdaClient, sourceAPIFunctionName, &models.LambdaInput{
+			ListIntegrations: &models.ListIntegrationsInput{IntegrationType: aws.String(models.IntegrationTypeAWSKinesis)},
+		}, &integrations)
+		return
+	}
+
+	readShardFunc = readShard
+
+	processFunc = func(dataStreams []*common.DataStream) error {
+		return processor.Process(dataStreams, destinations.CreateDestination())
+	}
+
+	saveCheckpointsFunc = func(input *models.UpdateKinesisCheckpointsInput) error {
+		return genericapi.Invoke(lambdaClient, sourceAPIFunctionName,
+			&models.LambdaInput{UpdateKinesisCheckpoints: input}, nil)
+	}
+)
+
+// The records read from a shard and the sequence number the next read continues after
+type shardRecords struct {
+	records      []*kinesis.Record
+	continuation *string
+}
+
+// ReadStreams reads the new records of the streams of the Kinesis integrations until the deadline and processes them.
+//
+// The streams are read concurrently. The records of a stream are checkpointed once they are processed, a failed
+// read or processing is read again by the next invocation.
+func ReadStreams(deadline time.Time) error {
+	integrations, err := listIntegrationsFunc()
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	var failedLock sync.Mutex
+	var wg sync.WaitGroup
+	for _, integration := range integrations {
+		// The consumer is registered when the integration is added
+		if integration.KinesisConsumerARN == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(integration *models.SourceIntegrationMetadata) {
+			defer wg.Done()
+			if err := readIntegration(integration, deadline); err != nil {
+				zap.L().Error("failed to read the stream of the integration",
+					zap.String("integrationId", *integration.IntegrationID),
+					zap.String("streamArn", *integration.StreamARN),
+					zap.Error(err))
+				failedLock.Lock()
+				failed++
+				failedLock.Unlock()
+			}
+		}(integration.SourceIntegrationMetadata)
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		return errors.Errorf("failed to read the streams of %d integrations", failed)
+	}
+	return nil
+}
+
+// readIntegration reads every shard of the stream of an integration, processes the records and saves the checkpoints.
+//
+// The records of the shards which were read are still processed when another shard fails.
+func readIntegration(integration *models.SourceIntegrationMetadata, deadline time.Time) error {
+	parsedArn, err := arn.Parse(*integration.StreamARN)
+	if err != nil {
+		return errors.Wrap(err, "invalid stream ARN")
+	}
+	kinesisClient := kinesisClientFunc(
+		sources.GetKinesisCredentials(*integration.AWSAccountID, integration.RoleChain), parsedArn.Region)
+	shardIDs, err := listShards(kinesisClient, aws.String(strings.TrimPrefix(parsedArn.Resource, "stream/")))
+	if err != nil {
+		return err
+	}
+
+	// The checkpoints of a previous stream of the integration don't apply
+	checkpoints := make(map[string]*string, len(shardIDs))
+	if aws.StringValue(integration.KinesisCheckpointsFrom) == *integration.StreamARN {
+		for shardID, sequenceNumber := range integration.KinesisCheckpoints {
+			checkpoints[shardID] = sequenceNumber
+		}
+	}
+
+	results, errs := make([]*shardRecords, len(shardIDs)), make([]error, len(shardIDs))
+	var wg sync.WaitGroup
+	for i, shardID := range shardIDs {
+		wg.Add(1)
+		go func(i int, shardID *string) {
+			defer wg.Done()
+			position := startingPosition(integration, checkpoints[*shardID])
+			results[i], errs[i] = readShardFunc(kinesisClient, integration.KinesisConsumerARN, shardID, position, deadline)
+		}(i, shardID)
+	}
+	wg.Wait()
+
+	var dataStreams []*common.DataStream
+	var readErr error
+	for i, shardID := range shardIDs {
+		if errs[i] != nil {
+			readErr = errs[i]
+			continue
+		}
+		if len(results[i].records) > 0 {
+			dataStreams = append(dataStreams, sources.ReadKinesisRecords(*integration.StreamARN, *shardID, results[i].records))
+		}
+		if results[i].continuation != nil {
+			checkpoints[*shardID] = results[i].continuation
+		}
+	}
+	if len(dataStreams) > 0 {
+		if err = processFunc(dataStreams); err != nil {
+			return err
+		}
+	}
+	if len(checkpoints) > 0 {
+		err = saveCheckpointsFunc(&models.UpdateKinesisCheckpointsInput{
+			IntegrationID: integration.IntegrationID,
+			StreamARN:     integration.StreamARN,
+			Checkpoints:   checkpoints,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return readErr
+}
+
+// listShards returns the IDs of the shards of a stream, including the closed shards which can still have records.
+func listShards(kinesisClient kinesisiface.KinesisAPI, streamName *string) ([]*string, error) {
+	var shardIDs []*string
+	input := &kinesis.ListShardsInput{StreamName: streamName}
+	for {
+		output, err := kinesisClient.ListShards(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list shards")
+		}
+		for _, shard := range output.Shards {
+			shardIDs = append(shardIDs, shard.ShardId)
+		}
+		if output.NextToken == nil {
+			return shardIDs, nil
+		}
+		// The stream can't be given along with the token
+		input = &kinesis.ListShardsInput{NextToken: output.NextToken}
+	}
+}
+
+// startingPosition returns where a shard is read from: after its checkpoint, or where the integration starts reading
+// streams when the shard wasn't read yet.
+func startingPosition(integration *models.SourceIntegrationMetadata, checkpoint *string) *kinesis.StartingPosition {
+	if checkpoint != nil {
+		return &kinesis.StartingPosition{
+			Type:           aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber),
+			SequenceNumber: checkpoint,
+		}
+	}
+	if integration.ShardIteratorType != nil {
+		return &kinesis.StartingPosition{Type: integration.ShardIteratorType}
+	}
+	return &kinesis.StartingPosition{Type: aws.String(models.ShardIteratorTrimHorizon)}
+}
+
+// readShard reads the records of a shard with the consumer of the integration until the deadline, or until it's
+// caught up with the latest record.
+func readShard(kinesisClient kinesisiface.KinesisAPI, consumerARN, shardID *string,
+	position *kinesis.StartingPosition, deadline time.Time) (*shardRecords, error) {
+
+	output, err := kinesisClient.SubscribeToShard(&kinesis.SubscribeToShardInput{
+		ConsumerARN:      consumerARN,
+		ShardId:          shardID,
+		StartingPosition: position,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to subscribe to shard %s", *shardID)
+	}
+	stream := output.GetStream()
+	defer stream.Close()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	result := &shardRecords{}
+	for {
+		select {
+		case <-timer.C:
+			return result, nil
+		case event, ok := <-stream.Events():
+			// The subscription ends after 5 minutes, or once a closed shard has been read
+			if !ok {
+				return result, errors.Wrapf(stream.Err(), "failed to read shard %s", *shardID)
+			}
+			shardEvent, ok := event.(*kinesis.SubscribeToShardEvent)
+			if !ok {
+				continue
+			}
+			result.records = append(result.records, shardEvent.Records...)
+			result.continuation = shardEvent.ContinuationSequenceNumber
+			// A closed shard has no continuation once its last record was read
+			if result.continuation == nil && len(result.records) > 0 {
+				result.continuation = result.records[len(result.records)-1].SequenceNumber
+			}
+			if aws.Int64Value(shardEvent.MillisBehindLatest) == 0 {
+				return result, nil
+			}
+		}
+	}
+}
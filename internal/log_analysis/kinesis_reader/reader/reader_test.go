@@ -0,0 +1,213 @@
+package reader
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+const (
+	testIntegrationID = "45c378a7-2e36-4b12-8e16-2d3c49ff1371"
+	testStreamARN     = "arn:aws:kinesis:us-west-2:123456789012:stream/logs"
+	testConsumerARN   = testStreamARN + "/consumer/panther-" + testIntegrationID + ":1"
+)
+
+type mockKinesisClient struct {
+	kinesisiface.KinesisAPI
+	mock.Mock
+}
+
+func (client *mockKinesisClient) ListShards(input *kinesis.ListShardsInput) (*kinesis.ListShardsOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*kinesis.ListShardsOutput), args.Error(1)
+}
+
+// The read of a shard, by the shard it's read from
+type shardRead struct {
+	position *kinesis.StartingPosition
+	records  *shardRecords
+	err      error
+}
+
+// mockReader lists the integrations and reads their shards, the processed streams and saved checkpoints are recorded.
+func mockReader(t *testing.T, integrations []*models.SourceIntegration, reads map[string]*shardRead) (
+	processed *[]*common.DataStream, saved *[]*models.UpdateKinesisCheckpointsInput) {
+
+	listIntegrationsFunc = func() ([]*models.SourceIntegration, error) { return integrations, nil }
+
+	mockKinesis := &mockKinesisClient{}
+	mockKinesis.On("ListShards", &kinesis.ListShardsInput{StreamName: aws.String("logs")}).
+		Return(&kinesis.ListShardsOutput{
+			Shards:    []*kinesis.Shard{{ShardId: aws.String("shardId-000000000000")}},
+			NextToken: aws.String("token"),
+		}, nil)
+	mockKinesis.On("ListShards", &kinesis.ListShardsInput{NextToken: aws.String("token")}).
+		Return(&kinesis.ListShardsOutput{Shards: []*kinesis.Shard{{ShardId: aws.String("shardId-000000000001")}}}, nil)
+	kinesisClientFunc = func(_ *credentials.Credentials, region string) kinesisiface.KinesisAPI {
+		assert.Equal(t, "us-west-2", region)
+		return mockKinesis
+	}
+
+	readShardFunc = func(_ kinesisiface.KinesisAPI, consumerARN, shardID *string,
+		position *kinesis.StartingPosition, _ time.Time) (*shardRecords, error) {
+
+		assert.Equal(t, testConsumerARN, *consumerARN)
+		read := reads[*shardID]
+		read.position = position
+		return read.records, read.err
+	}
+
+	processed, saved = &[]*common.DataStream{}, &[]*models.UpdateKinesisCheckpointsInput{}
+	processFunc = func(dataStreams []*common.DataStream) error {
+		*processed = append(*processed, dataStreams...)
+		return nil
+	}
+	saveCheckpointsFunc = func(input *models.UpdateKinesisCheckpointsInput) error {
+		*saved = append(*saved, input)
+		return nil
+	}
+	return processed, saved
+}
+
+func kinesisIntegration() *models.SourceIntegration {
+	return &models.SourceIntegration{SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+		IntegrationID:          aws.String(testIntegrationID),
+		IntegrationType:        aws.String(models.IntegrationTypeAWSKinesis),
+		AWSAccountID:           aws.String("123456789012"),
+		StreamARN:              aws.String(testStreamARN),
+		KinesisConsumerARN:     aws.String(testConsumerARN),
+		KinesisCheckpoints:     map[string]*string{"shardId-000000000000": aws.String("100")},
+		KinesisCheckpointsFrom: aws.String(testStreamARN),
+	}}
+}
+
+func TestReadStreams(t *testing.T) {
+	reads := map[string]*shardRead{
+		"shardId-000000000000": {records: &shardRecords{
+			records:      []*kinesis.Record{{Data: []byte(`{"user": "alice"}`), SequenceNumber: aws.String("101")}},
+			continuation: aws.String("101"),
+		}},
+		// Nothing was added to the shard yet
+		"shardId-000000000001": {records: &shardRecords{continuation: aws.String("200")}},
+	}
+	processed, saved := mockReader(t, []*models.SourceIntegration{kinesisIntegration()}, reads)
+
+	require.NoError(t, ReadStreams(time.Now().Add(time.Second)))
+	// The checkpointed shard continues after its checkpoint, the other one is read from the oldest record
+	assert.Equal(t, &kinesis.StartingPosition{
+		Type:           aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber),
+		SequenceNumber: aws.String("100"),
+	}, reads["shardId-000000000000"].position)
+	assert.Equal(t, &kinesis.StartingPosition{Type: aws.String(kinesis.ShardIteratorTypeTrimHorizon)},
+		reads["shardId-000000000001"].position)
+
+	require.Len(t, *processed, 1)
+	assert.Equal(t, "shardId-000000000000", (*processed)[0].Hints.Kinesis.ShardID)
+	lines, err := ioutil.ReadAll((*processed)[0].Reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user": "alice"}`+"\n", string(lines))
+
+	assert.Equal(t, []*models.UpdateKinesisCheckpointsInput{{
+		IntegrationID: aws.String(testIntegrationID),
+		StreamARN:     aws.String(testStreamARN),
+		Checkpoints: map[string]*string{
+			"shardId-000000000000": aws.String("101"),
+			"shardId-000000000001": aws.String("200"),
+		},
+	}}, *saved)
+}
+
+func TestReadStreamsCheckpointsOfOtherStream(t *testing.T) {
+	integration := kinesisIntegration()
+	integration.KinesisCheckpointsFrom = aws.String("arn:aws:kinesis:us-west-2:123456789012:stream/old")
+	integration.ShardIteratorType = aws.String(models.ShardIteratorLatest)
+	reads := map[string]*shardRead{
+		"shardId-000000000000": {records: &shardRecords{}},
+		"shardId-000000000001": {records: &shardRecords{}},
+	}
+	_, saved := mockReader(t, []*models.SourceIntegration{integration}, reads)
+
+	require.NoError(t, ReadStreams(time.Now().Add(time.Second)))
+	assert.Equal(t, &kinesis.StartingPosition{Type: aws.String(kinesis.ShardIteratorTypeLatest)},
+		reads["shardId-000000000000"].position)
+	assert.Empty(t, *saved)
+}
+
+func TestReadStreamsShardFails(t *testing.T) {
+	reads := map[string]*shardRead{
+		"shardId-000000000000": {err: errors.New("subscription failed")},
+		"shardId-000000000001": {records: &shardRecords{
+			records:      []*kinesis.Record{{Data: []byte(`{"user": "bob"}`), SequenceNumber: aws.String("201")}},
+			continuation: aws.String("201"),
+		}},
+	}
+	processed, saved := mockReader(t, []*models.SourceIntegration{kinesisIntegration()}, reads)
+
+	// The other shard is still processed and checkpointed, the failed one keeps its checkpoint
+	assert.Error(t, ReadStreams(time.Now().Add(time.Second)))
+	require.Len(t, *processed, 1)
+	require.Len(t, *saved, 1)
+	assert.Equal(t, map[string]*string{
+		"shardId-000000000000": aws.String("100"),
+		"shardId-000000000001": aws.String("201"),
+	}, (*saved)[0].Checkpoints)
+}
+
+func TestReadStreamsProcessingFails(t *testing.T) {
+	reads := map[string]*shardRead{
+		"shardId-000000000000": {records: &shardRecords{
+			records:      []*kinesis.Record{{Data: []byte(`{"user": "alice"}`), SequenceNumber: aws.String("101")}},
+			continuation: aws.String("101"),
+		}},
+		"shardId-000000000001": {records: &shardRecords{}},
+	}
+	_, saved := mockReader(t, []*models.SourceIntegration{kinesisIntegration()}, reads)
+	processFunc = func([]*common.DataStream) error { return errors.New("destination failed") }
+
+	// Nothing is checkpointed, the records are read again
+	assert.Error(t, ReadStreams(time.Now().Add(time.Second)))
+	assert.Empty(t, *saved)
+}
+
+func TestReadStreamsWithoutConsumer(t *testing.T) {
+	integration := kinesisIntegration()
+	integration.KinesisConsumerARN = nil
+	_, saved := mockReader(t, []*models.SourceIntegration{integration}, nil)
+	readShardFunc = func(kinesisiface.KinesisAPI, *string, *string, *kinesis.StartingPosition, time.Time) (*shardRecords, error) {
+		panic("the integration has no consumer to read with")
+	}
+
+	require.NoError(t, ReadStreams(time.Now().Add(time.Second)))
+	assert.Empty(t, *saved)
+}
@@ -23,6 +23,8 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
 )
 
 const (
@@ -36,6 +38,8 @@ var Session = session.Must(session.NewSession(aws.NewConfig().WithMaxRetries(Max
 type ParsedEvent struct {
 	Event   interface{} `json:"event"`
 	LogType string      `json:"logType"`
+	// The ID of the source integration of the event, if known
+	SourceID string `json:"sourceId,omitempty"`
 }
 
 // DataStream represents a data stream that read by the processor
@@ -45,11 +49,18 @@ type DataStream struct {
 	// The log type if known
 	// If it is nil, it means the log type hasn't been identified yet
 	LogType *string
+	// The ID of the source integration of the data, if known
+	SourceID string
+	// The settings of the source integration of the data, if known
+	Source *models.SourceIntegrationMetadata
 }
 
 // Used in a DataStream as meta data to describe the data
 type DataStreamHints struct {
-	S3 *S3DataStreamHints // if nil, no hint
+	S3      *S3DataStreamHints      // if nil, no hint
+	SQS     *SQSDataStreamHints     // if nil, no hint
+	Kinesis *KinesisDataStreamHints // if nil, no hint
+	Okta    *OktaDataStreamHints    // if nil, no hint
 }
 
 // Used in a DataStreamHints as meta data to describe the S3 object backing the stream
@@ -58,3 +69,22 @@ type S3DataStreamHints struct {
 	Key         string
 	ContentType string
 }
+
+// Used in a DataStreamHints as meta data to describe the SQS queue the events of the stream were sent to
+type SQSDataStreamHints struct {
+	QueueArn     string
+	MessageCount int
+}
+
+// Used in a DataStreamHints as meta data to describe the shard of the Kinesis stream the records were read from
+type KinesisDataStreamHints struct {
+	StreamArn   string
+	ShardID     string
+	RecordCount int
+}
+
+// Used in a DataStreamHints as meta data to describe the Okta organization the System Log events were pulled from
+type OktaDataStreamHints struct {
+	Domain     string
+	EventCount int
+}
@@ -20,8 +20,10 @@ package destinations
 
 import (
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/glue"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"go.uber.org/zap"
@@ -56,9 +58,22 @@ func createFirehoseDestination() Destination {
 
 func createS3Destination(s3BucketName string) Destination {
 	return &S3Destination{
-		s3Client:    s3.New(common.Session),
-		snsClient:   sns.New(common.Session),
-		s3Bucket:    s3BucketName,
-		snsTopicArn: os.Getenv("SNS_TOPIC_ARN"),
+		s3Client:        s3.New(common.Session),
+		snsClient:       sns.New(common.Session),
+		s3Bucket:        s3BucketName,
+		snsTopicArn:     os.Getenv("SNS_TOPIC_ARN"),
+		glueClient:      glue.New(common.Session),
+		parquetLogTypes: parseParquetLogTypes(os.Getenv("PARQUET_LOG_TYPES")),
 	}
 }
+
+// parseParquetLogTypes returns the log types of a comma delimited list
+func parseParquetLogTypes(list string) map[string]bool {
+	logTypes := make(map[string]bool)
+	for _, logType := range strings.Split(list, ",") {
+		if logType = strings.TrimSpace(logType); logType != "" {
+			logTypes[logType] = true
+		}
+	}
+	return logTypes
+}
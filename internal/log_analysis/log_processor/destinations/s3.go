@@ -22,9 +22,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"fmt"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sns"
@@ -37,6 +43,8 @@ import (
 	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/parquet"
 )
 
 const (
@@ -45,6 +53,9 @@ const (
 	// 1. The key prefix 2. Timestamp in format `s3ObjectTimestampFormat` 3. UUID4
 	s3ObjectKeyFormat = "%s%s-%s.json.gz"
 
+	// stagingS3Prefix is the prefix of the gzip JSON copies of the Parquet objects, read by the rules engine
+	stagingS3Prefix = "staging/"
+
 	// The timestamp format in the S3 objects with second precision: yyyyMMddTHHmmssZ
 	S3ObjectTimestampFormat = "20060102T150405Z"
 
@@ -52,6 +63,8 @@ const (
 	logTypeAttributeName     = "id"
 
 	messageAttributeDataType = "String"
+
+	defaultMemoryBytes = 512 * 1024 * 1024
 )
 
 var (
@@ -61,8 +74,28 @@ var (
 	newLineDelimiter = []byte("\n")
 
 	parserRegistry registry.Interface = registry.AvailableParsers() // initialize
+
+	// The buffers are flushed early, largest first, while the heap is above this share of the memory of the lambda,
+	// or of the default memory of the log processor when it isn't known
+	memoryPressureThreshold = 0.6
+	memoryLimitBytes        = lambdaMemoryBytes(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE"))
+	// The heap is only checked every so many events, reading it stops the world
+	memoryCheckInterval = 1000
+	heapBytesFunc       = func() uint64 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
 )
 
+// lambdaMemoryBytes returns the memory of the lambda from its size in MB
+func lambdaMemoryBytes(memorySize string) uint64 {
+	if mb, err := strconv.ParseUint(memorySize, 10, 64); err == nil && mb > 0 {
+		return mb * 1024 * 1024
+	}
+	return defaultMemoryBytes
+}
+
 // S3Destination sends normalized events to S3
 type S3Destination struct {
 	s3Client  s3iface.S3API
@@ -72,15 +105,20 @@ type S3Destination struct {
 	// snsTopic is the SNS Topic ARN where we will send the notification
 	// when we store new data in S3
 	snsTopicArn string
+	glueClient  glueiface.GlueAPI
+	// parquetLogTypes are the log types stored as Parquet in their Glue tables
+	parquetLogTypes map[string]bool
+	// parquetSchemas are the schemas of the Parquet log types, read from their Glue tables once per invocation
+	parquetSchemas map[string]*parquet.Schema
 }
 
 // SendEvents stores events in S3.
-// It continuously reads events from outputChannel, groups them in batches per log type
+// It continuously reads events from outputChannel, groups them in batches per log type and source
 // and stores them in the appropriate S3 path. If the method encounters an error
 // it writes an error to the errorChannel and continues until channel is closed (skipping events).
 func (destination *S3Destination) SendEvents(parsedEventChannel chan *common.ParsedEvent, errChan chan error) {
 	failed := false // set to true on error and loop will drain channel
-	logTypeToBuffer := make(map[string]*s3EventBuffer)
+	logTypeToBuffer := make(map[bufferKey]*s3EventBuffer)
 	eventsProcessed := 0
 	zap.L().Debug("starting to read events from channel")
 	for event := range parsedEventChannel {
@@ -96,10 +134,15 @@ func (destination *S3Destination) SendEvents(parsedEventChannel chan *common.Par
 			continue
 		}
 
-		buffer, ok := logTypeToBuffer[event.LogType]
+		key := bufferKey{logType: event.LogType, sourceID: event.SourceID}
+		buffer, ok := logTypeToBuffer[key]
 		if !ok {
-			buffer = &s3EventBuffer{}
-			logTypeToBuffer[event.LogType] = buffer
+			if buffer, err = destination.newBuffer(key); err != nil {
+				failed = true
+				errChan <- err
+				continue
+			}
+			logTypeToBuffer[key] = buffer
 		}
 
 		canAdd, err := buffer.addEvent(data)
@@ -109,7 +152,7 @@ func (destination *S3Destination) SendEvents(parsedEventChannel chan *common.Par
 			continue
 		}
 		if !canAdd {
-			if err = destination.sendData(event.LogType, buffer); err != nil {
+			if err = destination.sendData(key, buffer); err != nil {
 				failed = true
 				errChan <- err
 				continue
@@ -136,6 +179,14 @@ func (destination *S3Destination) SendEvents(parsedEventChannel chan *common.Par
 			errChan <- err
 			continue
 		}
+
+		if eventsProcessed%memoryCheckInterval == 0 {
+			if err = destination.sendLargestData(logTypeToBuffer); err != nil {
+				failed = true
+				errChan <- err
+				continue
+			}
+		}
 	}
 
 	if failed {
@@ -145,8 +196,11 @@ func (destination *S3Destination) SendEvents(parsedEventChannel chan *common.Par
 	zap.L().Debug("output channel closed, sending last events")
 	// If the channel has been closed
 	// send the buffered messages before terminating
-	for logType, data := range logTypeToBuffer {
-		if err := destination.sendData(logType, data); err != nil {
+	for key, data := range logTypeToBuffer {
+		if data.events == 0 { // the first event of the log type failed
+			continue
+		}
+		if err := destination.sendData(key, data); err != nil {
 			errChan <- err
 			return
 		}
@@ -154,26 +208,87 @@ func (destination *S3Destination) SendEvents(parsedEventChannel chan *common.Par
 	zap.L().Debug("finished sending messages", zap.Int("events", eventsProcessed))
 }
 
-func (destination *S3Destination) sendExpiredData(logTypeToEvents map[string]*s3EventBuffer) error {
+func (destination *S3Destination) sendExpiredData(logTypeToEvents map[bufferKey]*s3EventBuffer) error {
 	currentTime := time.Now().UTC()
-	for logType, buffer := range logTypeToEvents {
+	for key, buffer := range logTypeToEvents {
 		if currentTime.Sub(buffer.firstEventProcessedTime) > maxDuration {
-			err := destination.sendData(logType, buffer)
+			err := destination.sendData(key, buffer)
 			if err != nil {
 				return err
 			}
 			// delete the entry after sending the data
-			delete(logTypeToEvents, logType)
+			delete(logTypeToEvents, key)
+		}
+	}
+	return nil
+}
+
+// sendLargestData sends the largest buffer when the memory is running low, so the size of the objects adapts to the
+// memory left by the processing of the data streams
+func (destination *S3Destination) sendLargestData(logTypeToEvents map[bufferKey]*s3EventBuffer) error {
+	if float64(heapBytesFunc()) < memoryPressureThreshold*float64(memoryLimitBytes) {
+		return nil
+	}
+	var largestKey bufferKey
+	var largest *s3EventBuffer
+	for key, buffer := range logTypeToEvents {
+		if buffer.events > 0 && (largest == nil || buffer.bytes > largest.bytes) {
+			largestKey, largest = key, buffer
 		}
 	}
+	if largest == nil {
+		return nil
+	}
+	zap.L().Debug("sending buffer early on memory pressure",
+		zap.String("logType", largestKey.logType), zap.Int("bytes", largest.bytes))
+	if err := destination.sendData(largestKey, largest); err != nil {
+		return err
+	}
+	// The buffer is released along with its Parquet writer, a new one is created for the next event
+	delete(logTypeToEvents, largestKey)
 	return nil
 }
 
+// newBuffer returns a buffer for the events of a log type, which are also written as Parquet for the Parquet log types
+func (destination *S3Destination) newBuffer(key bufferKey) (*s3EventBuffer, error) {
+	logType := key.logType
+	if !destination.parquetLogTypes[logType] {
+		return &s3EventBuffer{}, nil
+	}
+	schema, ok := destination.parquetSchemas[logType]
+	if !ok {
+		tableMetadata := parserRegistry.LookupParser(logType).GlueTableMetadata
+		var err error
+		schema, err = awsglue.GetParquetSchema(destination.glueClient, tableMetadata.DatabaseName(), tableMetadata.TableName())
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot write %s events as Parquet", logType)
+		}
+		if destination.parquetSchemas == nil {
+			destination.parquetSchemas = make(map[string]*parquet.Schema)
+		}
+		destination.parquetSchemas[logType] = schema
+	}
+	return &s3EventBuffer{parquet: parquet.NewWriter(schema)}, nil
+}
+
 // sendData puts data in S3 and sends notification to SNS
-func (destination *S3Destination) sendData(logType string, buffer *s3EventBuffer) (err error) {
+//
+// The events of the Parquet log types are put in the Glue table as Parquet, along with a gzip JSON staging copy.
+// The objects of the events of a known source are tagged with its ID, for the retention rules of the source.
+func (destination *S3Destination) sendData(events bufferKey, buffer *s3EventBuffer) (err error) {
 	var contentLength int64 = 0
+	logType := events.logType
+	var tagging *string
+	if events.sourceID != "" {
+		tagging = aws.String(url.Values{models.SourceIDTagKey: {events.sourceID}}.Encode())
+	}
 
 	key := getS3ObjectKey(logType, buffer.firstEventProcessedTime)
+	var tableKey string
+	if buffer.parquet != nil {
+		tableKey = strings.TrimSuffix(key, awsglue.JSONObjectSuffix) + awsglue.ParquetObjectSuffix
+		key = stagingS3Prefix + key
+	}
 
 	operation := common.OpLogManager.Start("sendData", common.OpLogS3ServiceDim)
 	defer func() {
@@ -200,22 +315,38 @@ func (destination *S3Destination) sendData(logType string, buffer *s3EventBuffer
 
 	contentLength = int64(len(payload)) // for logging
 
+	if tableKey != "" {
+		parquetPayload := buffer.parquet.Bytes()
+		contentLength += int64(len(parquetPayload))
+		request := &s3.PutObjectInput{
+			Bucket:  aws.String(destination.s3Bucket),
+			Key:     aws.String(tableKey),
+			Body:    bytes.NewReader(parquetPayload),
+			Tagging: tagging,
+		}
+		if _, err = destination.s3Client.PutObject(request); err != nil {
+			err = errors.Wrap(err, "PutObject")
+			return err
+		}
+	}
+
 	request := &s3.PutObjectInput{
-		Bucket: aws.String(destination.s3Bucket),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(payload),
+		Bucket:  aws.String(destination.s3Bucket),
+		Key:     aws.String(key),
+		Body:    bytes.NewReader(payload),
+		Tagging: tagging,
 	}
 	if _, err = destination.s3Client.PutObject(request); err != nil {
 		err = errors.Wrap(err, "PutObject")
 		return err
 	}
 
-	err = destination.sendSNSNotification(key, logType, buffer) // if send fails we fail whole operation
+	err = destination.sendSNSNotification(key, tableKey, logType, buffer) // if send fails we fail whole operation
 
 	return err
 }
 
-func (destination *S3Destination) sendSNSNotification(key, logType string, buffer *s3EventBuffer) error {
+func (destination *S3Destination) sendSNSNotification(key, tableKey, logType string, buffer *s3EventBuffer) error {
 	var err error
 	operation := common.OpLogManager.Start("sendSNSNotification", common.OpLogSNSServiceDim)
 	defer func() {
@@ -232,6 +363,9 @@ func (destination *S3Destination) sendSNSNotification(key, logType string, buffe
 		Type:        aws.String(models.LogData.String()),
 		ID:          aws.String(logType),
 	}
+	if tableKey != "" {
+		s3Notification.TableS3ObjectKey = aws.String(tableKey)
+	}
 
 	marshalledNotification, err := jsoniter.MarshalToString(s3Notification)
 	if err != nil {
@@ -268,11 +402,20 @@ func getS3ObjectKey(logType string, timestamp time.Time) string {
 		uuid.New().String())
 }
 
+// bufferKey identifies the events stored in the same S3 objects: those of a log type from the same source.
+//
+// The source ID is empty for the events of the sources which aren't known.
+type bufferKey struct {
+	logType  string
+	sourceID string
+}
+
 // s3EventBuffer is a group of events of the same type
 // that will be stored in the same S3 object
 type s3EventBuffer struct {
 	buffer                  *bytes.Buffer
 	writer                  *gzip.Writer
+	parquet                 *parquet.Writer // set for the Parquet log types
 	bytes                   int
 	events                  int
 	firstEventProcessedTime time.Time
@@ -294,6 +437,12 @@ func (b *s3EventBuffer) addEvent(event []byte) (bool, error) {
 		return false, nil
 	}
 
+	if b.parquet != nil {
+		if err := b.parquet.WriteJSON(event); err != nil {
+			return false, errors.WithMessage(err, "event doesn't match the columns of its Glue table")
+		}
+	}
+
 	_, err := b.writer.Write(event)
 	if err != nil {
 		err = errors.Wrap(err, "failed to add data to buffer %s")
@@ -323,6 +472,9 @@ func (b *s3EventBuffer) getBytes() ([]byte, error) {
 func (b *s3EventBuffer) reset() error {
 	b.bytes = 0
 	b.events = 0
+	if b.parquet != nil {
+		b.parquet.Reset()
+	}
 	if err := b.writer.Close(); err != nil {
 		return err
 	}
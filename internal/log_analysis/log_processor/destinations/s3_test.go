@@ -23,16 +23,20 @@ import (
 	"compress/gzip"
 	"errors"
 	"io/ioutil"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -40,6 +44,7 @@ import (
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/pkg/awsglue"
 )
 
 type mockParser struct {
@@ -309,6 +314,76 @@ func TestSendDataToS3FromMultipleLogTypesBeforeTerminating(t *testing.T) {
 	runSendEvents(t, destination, eventChannel, false)
 }
 
+func TestSendDataToS3PerSource(t *testing.T) {
+	initTest()
+
+	destination := newS3Destination()
+	eventChannel := make(chan *common.ParsedEvent, 3)
+
+	testEvent := testEvent{data: "test"}
+
+	// wire it up
+	logType := "testtype"
+	registerMockParser(logType, &testEvent)
+
+	// The events of each source are written to their own objects, tagged with the source
+	maxFileSize, maxDuration = 1000, time.Minute
+	eventChannel <- &common.ParsedEvent{Event: testEvent, LogType: logType, SourceID: "source-1"}
+	eventChannel <- &common.ParsedEvent{Event: testEvent, LogType: logType, SourceID: "source-1"}
+	eventChannel <- &common.ParsedEvent{Event: testEvent, LogType: logType}
+
+	destination.mockS3.On("PutObject", mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+		return aws.StringValue(input.Tagging) == models.SourceIDTagKey+"=source-1"
+	})).Return(&s3.PutObjectOutput{}, nil).Once()
+	destination.mockS3.On("PutObject", mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+		return input.Tagging == nil
+	})).Return(&s3.PutObjectOutput{}, nil).Once()
+	destination.mockSns.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Twice()
+
+	runSendEvents(t, destination, eventChannel, false)
+}
+
+func TestSendDataOnMemoryPressure(t *testing.T) {
+	initTest()
+
+	destination := newS3Destination()
+	eventChannel := make(chan *common.ParsedEvent, 4)
+
+	testEvent := testEvent{data: "test"}
+
+	// wire it up
+	logType1 := "testtype1"
+	registerMockParser(logType1, &testEvent)
+	logType2 := "testtype2"
+	registerMockParser(logType2, &testEvent)
+
+	// The heap is checked after the second event, when it's above the threshold only once:
+	// the largest buffer is sent early, the others before terminating
+	maxFileSize, maxDuration, memoryCheckInterval = 1000, time.Minute, 2
+	checks, heapBytes := 0, heapBytesFunc
+	heapBytesFunc = func() uint64 {
+		checks++
+		if checks == 1 {
+			return memoryLimitBytes
+		}
+		return 0
+	}
+	defer func() {
+		memoryCheckInterval, heapBytesFunc = 1000, heapBytes
+	}()
+	eventChannel <- &common.ParsedEvent{Event: testEvent, LogType: logType1}
+	eventChannel <- &common.ParsedEvent{Event: testEvent, LogType: logType1}
+	eventChannel <- &common.ParsedEvent{Event: testEvent, LogType: logType2}
+	eventChannel <- &common.ParsedEvent{Event: testEvent, LogType: logType1}
+
+	destination.mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Times(3)
+	destination.mockSns.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Times(3)
+
+	runSendEvents(t, destination, eventChannel, false)
+	destination.mockS3.AssertExpectations(t)
+	assert.Equal(t, 2, checks)
+}
+
 func TestSendDataFailsIfS3Fails(t *testing.T) {
 	initTest()
 
@@ -354,6 +429,101 @@ func TestSendDataFailsIfSnsFails(t *testing.T) {
 	runSendEvents(t, destination, eventChannel, true)
 }
 
+type mockGlue struct {
+	glueiface.GlueAPI
+	mock.Mock
+}
+
+func (m *mockGlue) GetTable(input *glue.GetTableInput) (*glue.GetTableOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.GetTableOutput), args.Error(1)
+}
+
+type parquetEvent struct {
+	EventName string `json:"eventName"`
+	Bytes     int    `json:"bytes"`
+}
+
+func newParquetDestination(logType string, columns ...*glue.Column) (*testS3Destination, *mockGlue) {
+	initTest()
+	testParser := &mockParser{}
+	testParser.On("LogType").Return(logType)
+	testRegistry.Add(registry.DefaultLogParser(testParser, &parquetEvent{}, "Test "+logType))
+
+	mockGlue := &mockGlue{}
+	mockGlue.On("GetTable", &glue.GetTableInput{
+		DatabaseName: aws.String(awsglue.LogProcessingDatabaseName),
+		Name:         aws.String("parquet_type"),
+	}).Return(&glue.GetTableOutput{Table: &glue.TableData{
+		StorageDescriptor: &glue.StorageDescriptor{Columns: columns},
+	}}, nil).Once()
+	destination := newS3Destination()
+	destination.glueClient = mockGlue
+	destination.parquetLogTypes = map[string]bool{logType: true}
+	return destination, mockGlue
+}
+
+func TestSendParquetData(t *testing.T) {
+	maxFileSize, maxDuration = 100*1000*1000, time.Minute
+	destination, mockGlue := newParquetDestination("Parquet.Type",
+		&glue.Column{Name: aws.String("eventName"), Type: aws.String("string")},
+		&glue.Column{Name: aws.String("bytes"), Type: aws.String("bigint")},
+	)
+	eventChannel := make(chan *common.ParsedEvent, 2)
+	eventChannel <- &common.ParsedEvent{Event: &parquetEvent{EventName: "GetObject", Bytes: 10}, LogType: "Parquet.Type"}
+	eventChannel <- &common.ParsedEvent{Event: &parquetEvent{EventName: "PutObject"}, LogType: "Parquet.Type"}
+
+	destination.mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Twice()
+	destination.mockSns.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+
+	runSendEvents(t, destination, eventChannel, false)
+	mockGlue.AssertExpectations(t)
+	destination.mockS3.AssertExpectations(t)
+
+	// The Parquet object is put in the table, and the gzip JSON copy in the staging prefix
+	tableInput := destination.mockS3.Calls[0].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Regexp(t, `^logs/parquet_type/year=\d{4}/month=\d{2}/day=\d{2}/hour=\d{2}/\d{8}T\d{6}Z-[-0-9a-f]{36}\.parquet$`,
+		*tableInput.Key)
+	body, err := ioutil.ReadAll(tableInput.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "PAR1", string(body[:4]))
+	assert.Equal(t, "PAR1", string(body[len(body)-4:]))
+
+	stagingInput := destination.mockS3.Calls[1].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Equal(t, "staging/"+strings.TrimSuffix(*tableInput.Key, ".parquet")+".json.gz", *stagingInput.Key)
+	reader, err := gzip.NewReader(stagingInput.Body)
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"eventName\":\"GetObject\",\"bytes\":10}\n{\"eventName\":\"PutObject\",\"bytes\":0}\n", string(body))
+
+	publishInput := destination.mockSns.Calls[0].Arguments.Get(0).(*sns.PublishInput)
+	notification := &models.S3Notification{}
+	require.NoError(t, jsoniter.UnmarshalFromString(*publishInput.Message, notification))
+	assert.Equal(t, stagingInput.Key, notification.S3ObjectKey)
+	assert.Equal(t, tableInput.Key, notification.TableS3ObjectKey)
+	assert.Equal(t, 2, *notification.Events)
+}
+
+func TestSendParquetDataColumnMismatch(t *testing.T) {
+	maxFileSize, maxDuration = 100*1000*1000, time.Minute
+	// The column was changed to a type the events don't have
+	destination, _ := newParquetDestination("Parquet.Type",
+		&glue.Column{Name: aws.String("eventName"), Type: aws.String("bigint")},
+	)
+	eventChannel := make(chan *common.ParsedEvent, 1)
+	eventChannel <- &common.ParsedEvent{Event: &parquetEvent{EventName: "GetObject"}, LogType: "Parquet.Type"}
+
+	runSendEvents(t, destination, eventChannel, true)
+	destination.mockS3.AssertNotCalled(t, "PutObject", mock.Anything)
+}
+
+func TestParseParquetLogTypes(t *testing.T) {
+	assert.Equal(t, map[string]bool{}, parseParquetLogTypes(""))
+	assert.Equal(t, map[string]bool{"AWS.CloudTrail": true, "AWS.VPCFlow": true},
+		parseParquetLogTypes("AWS.CloudTrail, AWS.VPCFlow,"))
+}
+
 func runSendEvents(t *testing.T, destination Destination, eventChannel chan *common.ParsedEvent, expectErr bool) {
 	errChan := make(chan error)
 
@@ -53,10 +53,29 @@ func process(lc *lambdacontext.LambdaContext, event events.SQSEvent) (err error)
 		return err
 	}
 
-	dataStreams, err := sources.ReadSQSMessages(event.Records)
+	// The S3 notifications point at the objects to read, the messages of the other queues are log events
+	var notifications, httpIngestNotifications []events.SQSMessage
+	var logEvents []*events.SQSMessage
+	for i := range event.Records {
+		switch {
+		case sources.IsNotificationMessage(&event.Records[i]):
+			notifications = append(notifications, event.Records[i])
+		case sources.IsHTTPIngestMessage(&event.Records[i]):
+			httpIngestNotifications = append(httpIngestNotifications, event.Records[i])
+		default:
+			logEvents = append(logEvents, &event.Records[i])
+		}
+	}
+	dataStreams, err := sources.ReadSQSMessages(notifications)
+	if err != nil {
+		return err
+	}
+	httpIngestStreams, err := sources.ReadHTTPIngestMessages(httpIngestNotifications)
 	if err != nil {
 		return err
 	}
+	dataStreams = append(dataStreams, httpIngestStreams...)
+	dataStreams = append(dataStreams, sources.ReadQueueMessages(logEvents)...)
 	err = processor.Process(dataStreams, destinations.CreateDestination())
 	return err
 }
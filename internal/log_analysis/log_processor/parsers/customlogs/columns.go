@@ -0,0 +1,83 @@
+package customlogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+)
+
+// The Glue column types of the field types, which are named after them
+var columnTypes = map[string]string{
+	models.FieldTypeString:    "string",
+	models.FieldTypeInt:       "int",
+	models.FieldTypeBigInt:    "bigint",
+	models.FieldTypeDouble:    "double",
+	models.FieldTypeBoolean:   "boolean",
+	models.FieldTypeTimestamp: "timestamp",
+}
+
+// The columns of the Panther fields of the events, in the order of parsers.PantherLog
+var pantherColumns = []*glue.Column{
+	column("p_log_type", "string", "Panther added field with type of log"),
+	column("p_row_id", "string", "Panther added field with unique id (within table)"),
+	column("p_event_time", "timestamp", "Panther added standardize event time (UTC)"),
+	column("p_parse_time", "timestamp", "Panther added standardize log parse time (UTC)"),
+	column("p_any_ip_addresses", "array<string>", "Panther added field with collection of ip addresses associated with the row"),
+	column("p_any_ip_domain_names", "array<string>", "Panther added field with collection of domain names associated with the row"),
+	column("p_any_sha1_hashes", "array<string>", "Panther added field with collection of SHA1 hashes associated with the row"),
+	column("p_any_md5_hashes", "array<string>", "Panther added field with collection of MD5 hashes associated with the row"),
+	column("p_threat_intel_matches", "array<struct<set_id:string,set_name:string,indicator:string,field:string>>",
+		"Panther added field with the indicators of the row found in threat intel sets"),
+	column("p_ip_enrichment", "array<struct<ip:string,country_code:string,country:string,city:string,"+
+		"latitude:double,longitude:double,asn:bigint,as_organization:string>>",
+		"Panther added field with the geolocation and autonomous system of the ip addresses of the row"),
+}
+
+// The columns the rules engine appends to the events of the rule match tables
+var ruleMatchColumns = []*glue.Column{
+	column("p_rule_id", "string", "Rule id"),
+	column("p_alert_id", "string", "Alert id"),
+	column("p_alert_creation_time", "timestamp", "The time the alert was initially created (first match)"),
+	column("p_alert_update_time", "timestamp", "The time the alert last updated (last match)"),
+}
+
+func column(name, columnType, comment string) *glue.Column {
+	return &glue.Column{Name: aws.String(name), Type: aws.String(columnType), Comment: aws.String(comment)}
+}
+
+// Columns returns the columns of the Glue table of the events of a custom schema
+func Columns(schema *models.CustomSchema) []*glue.Column {
+	columns := make([]*glue.Column, 0, len(schema.Fields)+len(pantherColumns))
+	for _, f := range schema.Fields {
+		c := &glue.Column{Name: f.Name, Type: aws.String(columnTypes[aws.StringValue(f.Type)])}
+		if aws.StringValue(f.Description) != "" {
+			c.Comment = f.Description
+		}
+		columns = append(columns, c)
+	}
+	return append(columns, pantherColumns...)
+}
+
+// RuleMatchColumns returns the columns of the Glue table of the rule matches of a custom schema
+func RuleMatchColumns(schema *models.CustomSchema) []*glue.Column {
+	return append(Columns(schema), ruleMatchColumns...)
+}
@@ -0,0 +1,74 @@
+package customlogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+)
+
+// Event is an event of a custom schema: the values of its fields, followed by the Panther fields
+type Event struct {
+	fields []*field
+	values []interface{} // nil for the absent fields
+
+	parsers.PantherLog
+}
+
+// MarshalJSON writes the fields with a value as the keys of a JSON object, followed by the Panther fields
+func (event *Event) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, value := range event.values {
+		if value == nil {
+			continue
+		}
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		key, err := jsoniter.Marshal(event.fields[i].name)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		encoded, err := jsoniter.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encoded)
+	}
+
+	pantherFields, err := jsoniter.Marshal(&event.PantherLog)
+	if err != nil {
+		return nil, err
+	}
+	// Skip the braces of the object of the Panther fields
+	if pantherFields = pantherFields[1 : len(pantherFields)-1]; len(pantherFields) > 0 {
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(pantherFields)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
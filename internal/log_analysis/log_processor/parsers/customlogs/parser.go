@@ -0,0 +1,426 @@
+package customlogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
+)
+
+// LogTypePrefix is the prefix of the log types of custom schemas
+const LogTypePrefix = "Custom."
+
+// The timestamp formats which are not Go time layouts
+const (
+	TimestampFormatRFC3339 = "rfc3339"
+	TimestampFormatUnix    = "unix"
+	TimestampFormatUnixMS  = "unix_ms"
+)
+
+// The values of csv and regex lines which mean the field is absent
+const absentValue = "-"
+
+var (
+	schemaNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+	fieldNameRegex  = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+	// decodes the numbers of JSON lines as json.Number, to parse them according to the type of their field
+	jsonAPI = jsoniter.Config{UseNumber: true}.Froze()
+)
+
+// LogType returns the log type of the custom schema with the given name
+func LogType(name string) string {
+	return LogTypePrefix + name
+}
+
+// Parser parses the log lines of a custom schema
+type Parser struct {
+	logType         string
+	layout          string
+	fields          []*field
+	timestampField  int // -1 without a timestamp field
+	timestampFormat string
+	delimiter       rune
+	pattern         *regexp.Regexp
+	groups          []int // the index in the submatches of the pattern of each field
+}
+
+type field struct {
+	name      string
+	fieldType string
+	required  bool
+	indicator string
+}
+
+// NewParser validates a custom schema and returns the parser of its log lines
+func NewParser(schema *models.CustomSchema) (*Parser, error) {
+	name := aws.StringValue(schema.Name)
+	if !schemaNameRegex.MatchString(name) {
+		return nil, errors.Errorf("schema name %q must start with a letter and contain only letters and digits", name)
+	}
+	p := &Parser{
+		logType:         LogType(name),
+		layout:          aws.StringValue(schema.Layout),
+		timestampField:  -1,
+		timestampFormat: aws.StringValue(schema.TimestampFormat),
+		delimiter:       ',',
+	}
+	if p.timestampFormat == "" {
+		p.timestampFormat = TimestampFormatRFC3339
+	}
+	if err := validateTimestampFormat(p.timestampFormat); err != nil {
+		return nil, err
+	}
+
+	if len(schema.Fields) == 0 {
+		return nil, errors.New("schema has no fields")
+	}
+	names := make(map[string]bool, len(schema.Fields))
+	for i, schemaField := range schema.Fields {
+		f := &field{
+			name:      aws.StringValue(schemaField.Name),
+			fieldType: aws.StringValue(schemaField.Type),
+			required:  aws.BoolValue(schemaField.Required),
+			indicator: aws.StringValue(schemaField.Indicator),
+		}
+		if !fieldNameRegex.MatchString(f.name) {
+			// The Glue columns are lower case
+			return nil, errors.Errorf("field name %q must contain only lower case letters, digits and underscores", f.name)
+		}
+		if strings.HasPrefix(f.name, parsers.PantherFieldPrefix) {
+			return nil, errors.Errorf("field name %q can't start with %q", f.name, parsers.PantherFieldPrefix)
+		}
+		if names[f.name] {
+			return nil, errors.Errorf("duplicate field name %q", f.name)
+		}
+		names[f.name] = true
+		if _, ok := columnTypes[f.fieldType]; !ok {
+			return nil, errors.Errorf("field %q has unknown type %q", f.name, f.fieldType)
+		}
+		if f.indicator != "" && f.fieldType != models.FieldTypeString {
+			return nil, errors.Errorf("indicator field %q must be a string", f.name)
+		}
+		if f.name == aws.StringValue(schema.TimestampField) {
+			if f.fieldType != models.FieldTypeTimestamp {
+				return nil, errors.Errorf("timestamp field %q must be a timestamp", f.name)
+			}
+			p.timestampField = i
+			f.required = true
+		}
+		p.fields = append(p.fields, f)
+	}
+	if schema.TimestampField != nil && p.timestampField < 0 {
+		return nil, errors.Errorf("timestamp field %q is not a field of the schema", *schema.TimestampField)
+	}
+
+	if schema.Delimiter != nil && p.layout != models.LayoutCSV {
+		return nil, errors.New("only csv schemas have a delimiter")
+	}
+	if schema.Pattern != nil && p.layout != models.LayoutRegex {
+		return nil, errors.New("only regex schemas have a pattern")
+	}
+	switch p.layout {
+	case models.LayoutJSON:
+		// Any JSON object would be of the log type otherwise
+		if !p.hasRequiredField() {
+			return nil, errors.New("json schemas need a required field or a timestamp field")
+		}
+	case models.LayoutCSV:
+		if schema.Delimiter != nil {
+			delimiter := []rune(*schema.Delimiter)
+			if len(delimiter) != 1 || delimiter[0] == '"' || delimiter[0] == '\n' || delimiter[0] == '\r' {
+				return nil, errors.Errorf("invalid delimiter %q", *schema.Delimiter)
+			}
+			p.delimiter = delimiter[0]
+		}
+	case models.LayoutRegex:
+		if err := p.compilePattern(aws.StringValue(schema.Pattern)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unknown layout %q", p.layout)
+	}
+	return p, nil
+}
+
+// Matching the whole line keeps a pattern from matching the lines of other log types by accident
+func (p *Parser) compilePattern(pattern string) error {
+	if pattern == "" {
+		return errors.New("regex schemas need a pattern")
+	}
+	compiled, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return errors.Wrap(err, "invalid pattern")
+	}
+	groups := make(map[string]int)
+	for i, group := range compiled.SubexpNames() {
+		if group != "" {
+			groups[group] = i
+		}
+	}
+	for _, f := range p.fields {
+		i, ok := groups[f.name]
+		if !ok {
+			return errors.Errorf("pattern has no group named %q", f.name)
+		}
+		p.groups = append(p.groups, i)
+		delete(groups, f.name)
+	}
+	for group := range groups {
+		return errors.Errorf("pattern group %q is not a field of the schema", group)
+	}
+	p.pattern = compiled
+	return nil
+}
+
+func (p *Parser) hasRequiredField() bool {
+	for _, f := range p.fields {
+		if f.required {
+			return true
+		}
+	}
+	return false
+}
+
+// The Go layouts are checked to have at least an element and to parse the times they format
+func validateTimestampFormat(format string) error {
+	switch format {
+	case TimestampFormatRFC3339, TimestampFormatUnix, TimestampFormatUnixMS:
+		return nil
+	}
+	example := time.Date(2020, time.March, 14, 21, 37, 42, 0, time.UTC).Format(format)
+	if example == format {
+		return errors.Errorf("timestamp format %q is not a Go time layout", format)
+	}
+	if _, err := time.Parse(format, example); err != nil {
+		return errors.Errorf("timestamp format %q is not a Go time layout", format)
+	}
+	return nil
+}
+
+// New returns a parser of the same schema
+func (p *Parser) New() parsers.LogParser {
+	return p // stateless
+}
+
+// LogType returns the log type of the schema
+func (p *Parser) LogType() string {
+	return p.logType
+}
+
+// Parse returns the parsed events or nil if the log line is not of the schema
+func (p *Parser) Parse(log string) []interface{} {
+	var values []interface{}
+	switch p.layout {
+	case models.LayoutJSON:
+		values = p.jsonValues(log)
+	case models.LayoutCSV:
+		var isHeader bool
+		if values, isHeader = p.csvValues(log); isHeader {
+			return []interface{}{}
+		}
+	case models.LayoutRegex:
+		values = p.regexValues(log)
+	}
+	if values == nil {
+		return nil
+	}
+
+	event := &Event{fields: p.fields, values: make([]interface{}, len(p.fields))}
+	for i, f := range p.fields {
+		if values[i] == nil {
+			if f.required {
+				zap.L().Debug("failed to parse log (missing required field)", zap.String("field", f.name))
+				return nil
+			}
+			continue
+		}
+		value, err := p.convert(f, values[i])
+		if err != nil {
+			zap.L().Debug("failed to parse log", zap.String("field", f.name), zap.Error(err))
+			return nil
+		}
+		event.values[i] = value
+	}
+	p.updatePantherFields(event)
+	return []interface{}{event}
+}
+
+// The values of JSON lines are the decoded values of their keys
+func (p *Parser) jsonValues(log string) []interface{} {
+	if !strings.HasPrefix(log, "{") {
+		return nil
+	}
+	var object map[string]interface{}
+	if err := jsonAPI.UnmarshalFromString(log, &object); err != nil {
+		zap.L().Debug("failed to parse log (not a JSON object)", zap.Error(err))
+		return nil
+	}
+	values := make([]interface{}, len(p.fields))
+	for i, f := range p.fields {
+		values[i] = object[f.name]
+	}
+	return values
+}
+
+// The values of csv lines are their columns, in the order of the fields
+func (p *Parser) csvValues(log string) (values []interface{}, isHeader bool) {
+	reader := csv.NewReader(strings.NewReader(log))
+	reader.Comma = p.delimiter
+	reader.FieldsPerRecord = len(p.fields)
+	records, err := reader.ReadAll()
+	if err != nil || len(records) != 1 {
+		zap.L().Debug("failed to parse log (not a csv line of the schema)")
+		return nil, false
+	}
+
+	isHeader = true
+	values = make([]interface{}, len(p.fields))
+	for i, column := range records[0] {
+		isHeader = isHeader && column == p.fields[i].name
+		values[i] = textValue(column)
+	}
+	return values, isHeader
+}
+
+// The values of regex lines are the groups named after the fields
+func (p *Parser) regexValues(log string) []interface{} {
+	match := p.pattern.FindStringSubmatch(log)
+	if match == nil {
+		zap.L().Debug("failed to parse log (pattern not matched)")
+		return nil
+	}
+	values := make([]interface{}, len(p.fields))
+	for i := range p.fields {
+		values[i] = textValue(match[p.groups[i]])
+	}
+	return values
+}
+
+func textValue(value string) interface{} {
+	if value == "" || value == absentValue {
+		return nil
+	}
+	return value
+}
+
+// convert returns the value of a field as the Go type of its column
+func (p *Parser) convert(f *field, value interface{}) (interface{}, error) {
+	var text string
+	switch value := value.(type) {
+	case string:
+		text = value
+	case json.Number:
+		text = value.String()
+	case bool:
+		text = strconv.FormatBool(value)
+	default:
+		// Nested JSON objects and arrays are stored as strings
+		if f.fieldType != models.FieldTypeString {
+			return nil, errors.Errorf("unexpected %T value", value)
+		}
+		result, err := jsoniter.MarshalToString(value)
+		return result, err
+	}
+
+	switch f.fieldType {
+	case models.FieldTypeString:
+		return text, nil
+	case models.FieldTypeInt:
+		result, err := strconv.ParseInt(text, 10, 32)
+		return int32(result), err
+	case models.FieldTypeBigInt:
+		return strconv.ParseInt(text, 10, 64)
+	case models.FieldTypeDouble:
+		return strconv.ParseFloat(text, 64)
+	case models.FieldTypeBoolean:
+		return strconv.ParseBool(text)
+	case models.FieldTypeTimestamp:
+		return p.parseTimestamp(text)
+	default:
+		return nil, errors.Errorf("unknown type %q", f.fieldType)
+	}
+}
+
+func (p *Parser) parseTimestamp(text string) (*timestamp.RFC3339, error) {
+	var result time.Time
+	switch p.timestampFormat {
+	case TimestampFormatRFC3339:
+		parsed, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			return nil, err
+		}
+		result = parsed
+	case TimestampFormatUnix, TimestampFormatUnixMS:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, err
+		}
+		if p.timestampFormat == TimestampFormatUnixMS {
+			number /= 1000
+		}
+		sec := int64(number)
+		result = time.Unix(sec, int64((number-float64(sec))*float64(time.Second)))
+	default:
+		parsed, err := time.Parse(p.timestampFormat, text)
+		if err != nil {
+			return nil, err
+		}
+		result = parsed
+	}
+	ts := timestamp.RFC3339(result.UTC())
+	return &ts, nil
+}
+
+func (p *Parser) updatePantherFields(event *Event) {
+	var eventTime *timestamp.RFC3339
+	if p.timestampField >= 0 {
+		eventTime = event.values[p.timestampField].(*timestamp.RFC3339)
+	}
+	event.SetCoreFields(p.logType, eventTime)
+	for i, f := range p.fields {
+		value, ok := event.values[i].(string)
+		if !ok {
+			continue
+		}
+		switch f.indicator {
+		case models.IndicatorIP:
+			event.AppendAnyIPAddresses(value)
+		case models.IndicatorDomain:
+			event.AppendAnyDomainNames(value)
+		case models.IndicatorSHA1:
+			event.AppendAnySHA1Hashes(value)
+		case models.IndicatorMD5:
+			event.AppendAnyMD5Hashes(value)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package customlogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+)
+
+func testFields() []*models.CustomSchemaField {
+	return []*models.CustomSchemaField{
+		{Name: aws.String("time"), Type: aws.String(models.FieldTypeTimestamp)},
+		{Name: aws.String("src_ip"), Type: aws.String(models.FieldTypeString), Indicator: aws.String(models.IndicatorIP)},
+		{Name: aws.String("bytes"), Type: aws.String(models.FieldTypeBigInt)},
+		{Name: aws.String("allowed"), Type: aws.String(models.FieldTypeBoolean)},
+	}
+}
+
+func newTestParser(t *testing.T, schema *models.CustomSchema) *Parser {
+	schema.Name = aws.String("FirewallAudit")
+	schema.Fields = testFields()
+	schema.TimestampField = aws.String("time")
+	parser, err := NewParser(schema)
+	require.NoError(t, err)
+	return parser
+}
+
+// parseOne returns the JSON of the single event of a log line, without the generated Panther fields
+func parseOne(t *testing.T, parser *Parser, log string) map[string]interface{} {
+	events := parser.Parse(log)
+	require.Len(t, events, 1)
+	data, err := json.Marshal(events[0])
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.NotEmpty(t, result["p_row_id"])
+	assert.NotEmpty(t, result["p_parse_time"])
+	delete(result, "p_row_id")
+	delete(result, "p_parse_time")
+	return result
+}
+
+func TestParseJSON(t *testing.T) {
+	parser := newTestParser(t, &models.CustomSchema{Layout: aws.String(models.LayoutJSON)})
+	assert.Equal(t, "Custom.FirewallAudit", parser.LogType())
+
+	result := parseOne(t, parser, `{"time": "2020-03-14T21:37:42.5Z", "src_ip": "10.0.0.1", "bytes": 3000000000, "other": 1}`)
+	assert.Equal(t, map[string]interface{}{
+		"time":               "2020-03-14 21:37:42.500000000",
+		"src_ip":             "10.0.0.1",
+		"bytes":              float64(3000000000),
+		"p_log_type":         "Custom.FirewallAudit",
+		"p_event_time":       "2020-03-14 21:37:42.500000000",
+		"p_any_ip_addresses": []interface{}{"10.0.0.1"},
+	}, result)
+
+	// The timestamp field is required
+	assert.Nil(t, parser.Parse(`{"src_ip": "10.0.0.1"}`))
+	// The values must be of the type of their field
+	assert.Nil(t, parser.Parse(`{"time": "2020-03-14T21:37:42Z", "bytes": 1.5}`))
+	assert.Nil(t, parser.Parse(`{"time": "2020-03-14T21:37:42Z", "allowed": "maybe"}`))
+	assert.Nil(t, parser.Parse(`not json`))
+}
+
+func TestParseCSV(t *testing.T) {
+	parser := newTestParser(t, &models.CustomSchema{
+		Layout:          aws.String(models.LayoutCSV),
+		Delimiter:       aws.String("|"),
+		TimestampFormat: aws.String(TimestampFormatUnix),
+	})
+
+	assert.Equal(t, []interface{}{}, parser.Parse("time|src_ip|bytes|allowed"))
+	result := parseOne(t, parser, "1584221862|-|42|true")
+	assert.Equal(t, map[string]interface{}{
+		"time":         "2020-03-14 21:37:42.000000000",
+		"bytes":        float64(42),
+		"allowed":      true,
+		"p_log_type":   "Custom.FirewallAudit",
+		"p_event_time": "2020-03-14 21:37:42.000000000",
+	}, result)
+
+	assert.Nil(t, parser.Parse("1584221862|10.0.0.1|42"))
+	assert.Nil(t, parser.Parse("1584221862,10.0.0.1,42,true"))
+}
+
+func TestParseRegex(t *testing.T) {
+	parser := newTestParser(t, &models.CustomSchema{
+		Layout:          aws.String(models.LayoutRegex),
+		Pattern:         aws.String(`\[(?P<time>[^\]]+)\] (?P<src_ip>\S+) sent (?P<bytes>\d+) bytes( (?P<allowed>allowed|denied))?`),
+		TimestampFormat: aws.String("02/Jan/2006:15:04:05 -0700"),
+	})
+
+	result := parseOne(t, parser, "[14/Mar/2020:22:37:42 +0100] 10.0.0.1 sent 42 bytes")
+	assert.Equal(t, map[string]interface{}{
+		"time":               "2020-03-14 21:37:42.000000000",
+		"src_ip":             "10.0.0.1",
+		"bytes":              float64(42),
+		"p_log_type":         "Custom.FirewallAudit",
+		"p_event_time":       "2020-03-14 21:37:42.000000000",
+		"p_any_ip_addresses": []interface{}{"10.0.0.1"},
+	}, result)
+
+	// The pattern must match the whole line
+	assert.Nil(t, parser.Parse("[14/Mar/2020:22:37:42 +0100] 10.0.0.1 sent 42 bytes twice"))
+	assert.Nil(t, parser.Parse("[14/Mar/2020:22:37:42 +0100] 10.0.0.1 sent 42 bytes denied"))
+}
+
+func TestNewParserInvalid(t *testing.T) {
+	valid := func() *models.CustomSchema {
+		return &models.CustomSchema{
+			Name:           aws.String("FirewallAudit"),
+			Layout:         aws.String(models.LayoutRegex),
+			Fields:         testFields(),
+			TimestampField: aws.String("time"),
+			Pattern:        aws.String(`(?P<time>\S+) (?P<src_ip>\S+) (?P<bytes>\d+) (?P<allowed>\w+)`),
+		}
+	}
+	_, err := NewParser(valid())
+	require.NoError(t, err)
+
+	for name, update := range map[string]func(*models.CustomSchema){
+		"name":              func(s *models.CustomSchema) { s.Name = aws.String("1Firewall") },
+		"field name":        func(s *models.CustomSchema) { s.Fields[1].Name = aws.String("srcIp") },
+		"panther field":     func(s *models.CustomSchema) { s.Fields[1].Name = aws.String("p_src_ip") },
+		"duplicate field":   func(s *models.CustomSchema) { s.Fields[1].Name = aws.String("time") },
+		"indicator":         func(s *models.CustomSchema) { s.Fields[2].Indicator = aws.String(models.IndicatorIP) },
+		"timestamp type":    func(s *models.CustomSchema) { s.TimestampField = aws.String("bytes") },
+		"timestamp field":   func(s *models.CustomSchema) { s.TimestampField = aws.String("missing") },
+		"timestamp format":  func(s *models.CustomSchema) { s.TimestampFormat = aws.String("yesterday") },
+		"missing group":     func(s *models.CustomSchema) { s.Pattern = aws.String(`(?P<time>\S+) (?P<src_ip>\S+)`) },
+		"extra group":       func(s *models.CustomSchema) { *s.Pattern += ` (?P<extra>\S+)` },
+		"invalid pattern":   func(s *models.CustomSchema) { s.Pattern = aws.String(`(?P<time>`) },
+		"delimiter of json": func(s *models.CustomSchema) { s.Delimiter = aws.String(",") },
+		"json required": func(s *models.CustomSchema) {
+			s.Layout, s.Pattern, s.TimestampField = aws.String(models.LayoutJSON), nil, nil
+		},
+	} {
+		schema := valid()
+		update(schema)
+		_, err := NewParser(schema)
+		assert.Error(t, err, name)
+	}
+}
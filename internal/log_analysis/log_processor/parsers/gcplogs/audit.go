@@ -0,0 +1,162 @@
+package gcplogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
+)
+
+var AuditLogDesc = `GCP AuditLog is a Cloud Audit Logs entry of a Google Cloud project, as exported to GCS or Pub/Sub.
+Reference: https://cloud.google.com/logging/docs/reference/audit/auditlog/rest/Shared.Types/AuditLog`
+
+// AuditLog is a LogEntry of Cloud Logging whose payload is an AuditLog.
+// nolint:lll
+type AuditLog struct {
+	LogName          *string            `json:"logName,omitempty" validate:"required" description:"The resource name of the log to which this log entry belongs, such as projects/my-project/logs/cloudaudit.googleapis.com%2Factivity."`
+	Resource         *MonitoredResource `json:"resource,omitempty" validate:"required" description:"The monitored resource that produced this log entry."`
+	Timestamp        *timestamp.RFC3339 `json:"timestamp,omitempty" validate:"required" description:"The time the event described by the log entry occurred."`
+	ReceiveTimestamp *timestamp.RFC3339 `json:"receiveTimestamp,omitempty" description:"The time the log entry was received by Cloud Logging."`
+	Severity         *string            `json:"severity,omitempty" description:"The severity of the log entry, such as NOTICE or ERROR."`
+	InsertID         *string            `json:"insertId,omitempty" validate:"required" description:"A unique identifier for the log entry."`
+	Labels           map[string]string  `json:"labels,omitempty" description:"User-defined key:value pairs that provide additional information about the log entry."`
+	Operation        *LogEntryOperation `json:"operation,omitempty" description:"Information about an operation associated with the log entry, if applicable."`
+	ProtoPayload     *AuditLogPayload   `json:"protoPayload,omitempty" validate:"required" description:"The audit log payload of the entry."`
+
+	// NOTE: added to end of struct to allow expansion later
+	parsers.PantherLog
+}
+
+// MonitoredResource is the resource a log entry is about, such as a GCS bucket or a Compute Engine instance.
+type MonitoredResource struct {
+	Type   *string           `json:"type,omitempty" validate:"required" description:"The monitored resource type, such as gcs_bucket."`
+	Labels map[string]string `json:"labels,omitempty" description:"The labels identifying the resource, such as project_id."`
+}
+
+// LogEntryOperation groups the log entries of a long running operation.
+type LogEntryOperation struct {
+	ID       *string `json:"id,omitempty" description:"An arbitrary operation identifier."`
+	Producer *string `json:"producer,omitempty" description:"An arbitrary producer identifier."`
+	First    *bool   `json:"first,omitempty" description:"Set if this is the first log entry in the operation."`
+	Last     *bool   `json:"last,omitempty" description:"Set if this is the last log entry in the operation."`
+}
+
+// AuditLogPayload is the AuditLog of a Google Cloud API call.
+// nolint:lll
+type AuditLogPayload struct {
+	Type               *string                      `json:"@type,omitempty" validate:"required,eq=type.googleapis.com/google.cloud.audit.AuditLog" description:"The type of the payload."`
+	ServiceName        *string                      `json:"serviceName,omitempty" validate:"required" description:"The name of the API service performing the operation, such as storage.googleapis.com."`
+	MethodName         *string                      `json:"methodName,omitempty" validate:"required" description:"The name of the service method or operation, such as storage.buckets.delete."`
+	ResourceName       *string                      `json:"resourceName,omitempty" description:"The resource or collection that is the target of the operation."`
+	ResourceLocation   *AuditLogResourceLocation    `json:"resourceLocation,omitempty" description:"The resource location information."`
+	NumResponseItems   *int64                       `json:"numResponseItems,omitempty,string" description:"The number of items returned from a List or Query API method, if applicable."`
+	Status             *AuditLogStatus              `json:"status,omitempty" description:"The status of the overall operation."`
+	AuthenticationInfo *AuditLogAuthenticationInfo  `json:"authenticationInfo,omitempty" description:"Authentication information."`
+	AuthorizationInfo  []*AuditLogAuthorizationInfo `json:"authorizationInfo,omitempty" description:"Authorization information. If there are multiple resources or permissions involved, then there is one entry for each."`
+	RequestMetadata    *AuditLogRequestMetadata     `json:"requestMetadata,omitempty" description:"Metadata about the operation."`
+	Request            *jsoniter.RawMessage         `json:"request,omitempty" description:"The operation request, which may not include all request parameters."`
+	Response           *jsoniter.RawMessage         `json:"response,omitempty" description:"The operation response, which may not include all response elements."`
+	Metadata           *jsoniter.RawMessage         `json:"metadata,omitempty" description:"Other service-specific data about the request, response, and other information associated with the current audited event."`
+	ServiceData        *jsoniter.RawMessage         `json:"serviceData,omitempty" description:"Deprecated, other service-specific data about the request, response, and other activities."`
+}
+
+// AuditLogResourceLocation is where the target of the operation is.
+type AuditLogResourceLocation struct {
+	CurrentLocations  []string `json:"currentLocations,omitempty" description:"The locations of the resource after the execution of the operation."`
+	OriginalLocations []string `json:"originalLocations,omitempty" description:"The locations of the resource prior to the execution of the operation."`
+}
+
+// AuditLogStatus is the outcome of the operation, a zero code means it succeeded.
+type AuditLogStatus struct {
+	Code    *int32               `json:"code,omitempty" description:"The status code, which should be an enum value of google.rpc.Code."`
+	Message *string              `json:"message,omitempty" description:"A developer-facing error message."`
+	Details *jsoniter.RawMessage `json:"details,omitempty" description:"A list of messages that carry the error details."`
+}
+
+// AuditLogAuthenticationInfo is who made the request.
+// nolint:lll
+type AuditLogAuthenticationInfo struct {
+	PrincipalEmail               *string              `json:"principalEmail,omitempty" description:"The email address of the authenticated user or service account making the request."`
+	PrincipalSubject             *string              `json:"principalSubject,omitempty" description:"String representation of identity of requesting party."`
+	AuthoritySelector            *string              `json:"authoritySelector,omitempty" description:"The authority selector specified by the requestor, if any."`
+	ServiceAccountKeyName        *string              `json:"serviceAccountKeyName,omitempty" description:"The name of the service account key used to create or exchange credentials for authenticating the service account making the request."`
+	ServiceAccountDelegationInfo *jsoniter.RawMessage `json:"serviceAccountDelegationInfo,omitempty" description:"Identity delegation history of an authenticated service account that makes the request."`
+}
+
+// AuditLogAuthorizationInfo is whether a permission was granted on a resource.
+type AuditLogAuthorizationInfo struct {
+	Resource           *string              `json:"resource,omitempty" description:"The resource being accessed."`
+	Permission         *string              `json:"permission,omitempty" description:"The required IAM permission."`
+	Granted            *bool                `json:"granted,omitempty" description:"Whether or not authorization for resource and permission was granted."`
+	ResourceAttributes *jsoniter.RawMessage `json:"resourceAttributes,omitempty" description:"Resource attributes used in IAM condition evaluation."`
+}
+
+// AuditLogRequestMetadata is where the request came from.
+// nolint:lll
+type AuditLogRequestMetadata struct {
+	CallerIP                *string `json:"callerIp,omitempty" description:"The IP address of the caller, or gce-internal-ip for a request from a Compute Engine instance without an external IP address."`
+	CallerSuppliedUserAgent *string `json:"callerSuppliedUserAgent,omitempty" description:"The user agent of the caller."`
+	CallerNetwork           *string `json:"callerNetwork,omitempty" description:"The network of the caller, set only for requests from within Google Cloud."`
+}
+
+// AuditLogParser parses GCP audit logs, one log entry per line
+type AuditLogParser struct{}
+
+func (p *AuditLogParser) New() parsers.LogParser {
+	return &AuditLogParser{}
+}
+
+// Parse returns the parsed events or nil if parsing failed
+func (p *AuditLogParser) Parse(log string) []interface{} {
+	event := &AuditLog{}
+	err := jsoniter.UnmarshalFromString(log, event)
+	if err != nil {
+		zap.L().Debug("failed to parse log", zap.Error(err))
+		return nil
+	}
+
+	event.updatePantherFields(p)
+
+	if err := parsers.Validator.Struct(event); err != nil {
+		zap.L().Debug("failed to validate log", zap.Error(err))
+		return nil
+	}
+	return []interface{}{event}
+}
+
+// LogType returns the log type supported by this parser
+func (p *AuditLogParser) LogType() string {
+	return "GCP.AuditLog"
+}
+
+func (event *AuditLog) updatePantherFields(p *AuditLogParser) {
+	event.SetCoreFields(p.LogType(), event.Timestamp)
+	if event.ProtoPayload == nil || event.ProtoPayload.RequestMetadata == nil {
+		return
+	}
+	// Requests from Compute Engine instances without an external address have the placeholder gce-internal-ip
+	if callerIP := event.ProtoPayload.RequestMetadata.CallerIP; callerIP != nil && net.ParseIP(*callerIP) != nil {
+		event.AppendAnyIPAddresses(*callerIP)
+	}
+}
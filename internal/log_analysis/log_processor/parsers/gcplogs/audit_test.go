@@ -0,0 +1,115 @@
+package gcplogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
+)
+
+func TestAuditLog(t *testing.T) {
+	//nolint:lll
+	log := `{"protoPayload":{"@type":"type.googleapis.com/google.cloud.audit.AuditLog","status":{},"authenticationInfo":{"principalEmail":"admin@example.com"},"requestMetadata":{"callerIp":"203.0.113.7","callerSuppliedUserAgent":"gcloud/290.0.1"},"serviceName":"storage.googleapis.com","methodName":"storage.setIamPermissions","authorizationInfo":[{"resource":"projects/_/buckets/logs","permission":"storage.buckets.setIamPolicy","granted":true}],"resourceName":"projects/_/buckets/logs","request":{"policy":{"etag":"CAI="}}},"insertId":"1x2y3z","resource":{"type":"gcs_bucket","labels":{"project_id":"my-project","bucket_name":"logs","location":"us"}},"timestamp":"2020-03-04T12:00:00.123456Z","severity":"NOTICE","logName":"projects/my-project/logs/cloudaudit.googleapis.com%2Factivity","receiveTimestamp":"2020-03-04T12:00:01.5Z"}`
+
+	expectedTime := time.Date(2020, 3, 4, 12, 0, 0, 123456000, time.UTC)
+	receiveTime := time.Date(2020, 3, 4, 12, 0, 1, 500000000, time.UTC)
+	request := jsoniter.RawMessage(`{"policy":{"etag":"CAI="}}`)
+	expectedEvent := &AuditLog{
+		LogName: aws.String("projects/my-project/logs/cloudaudit.googleapis.com%2Factivity"),
+		Resource: &MonitoredResource{
+			Type:   aws.String("gcs_bucket"),
+			Labels: map[string]string{"project_id": "my-project", "bucket_name": "logs", "location": "us"},
+		},
+		Timestamp:        (*timestamp.RFC3339)(&expectedTime),
+		ReceiveTimestamp: (*timestamp.RFC3339)(&receiveTime),
+		Severity:         aws.String("NOTICE"),
+		InsertID:         aws.String("1x2y3z"),
+		ProtoPayload: &AuditLogPayload{
+			Type:               aws.String("type.googleapis.com/google.cloud.audit.AuditLog"),
+			ServiceName:        aws.String("storage.googleapis.com"),
+			MethodName:         aws.String("storage.setIamPermissions"),
+			ResourceName:       aws.String("projects/_/buckets/logs"),
+			Status:             &AuditLogStatus{},
+			AuthenticationInfo: &AuditLogAuthenticationInfo{PrincipalEmail: aws.String("admin@example.com")},
+			AuthorizationInfo: []*AuditLogAuthorizationInfo{
+				{
+					Resource:   aws.String("projects/_/buckets/logs"),
+					Permission: aws.String("storage.buckets.setIamPolicy"),
+					Granted:    aws.Bool(true),
+				},
+			},
+			RequestMetadata: &AuditLogRequestMetadata{
+				CallerIP:                aws.String("203.0.113.7"),
+				CallerSuppliedUserAgent: aws.String("gcloud/290.0.1"),
+			},
+			Request: &request,
+		},
+	}
+
+	// panther fields
+	expectedEvent.PantherLogType = aws.String("GCP.AuditLog")
+	expectedEvent.PantherEventTime = (*timestamp.RFC3339)(&expectedTime)
+	expectedEvent.AppendAnyIPAddresses("203.0.113.7")
+
+	checkAuditLog(t, log, expectedEvent)
+}
+
+func TestAuditLogInternalCaller(t *testing.T) {
+	//nolint:lll
+	log := `{"protoPayload":{"@type":"type.googleapis.com/google.cloud.audit.AuditLog","requestMetadata":{"callerIp":"gce-internal-ip"},"serviceName":"compute.googleapis.com","methodName":"v1.compute.instances.delete"},"insertId":"abc","resource":{"type":"gce_instance"},"timestamp":"2020-03-04T12:00:00Z","logName":"projects/my-project/logs/cloudaudit.googleapis.com%2Factivity"}`
+
+	events := (&AuditLogParser{}).Parse(log)
+	require.Len(t, events, 1)
+	require.Nil(t, events[0].(*AuditLog).PantherAnyIPAddresses)
+}
+
+func TestAuditLogNotAuditPayload(t *testing.T) {
+	//nolint:lll
+	log := `{"protoPayload":{"@type":"type.googleapis.com/google.appengine.logging.v1.RequestLog","serviceName":"appengine.googleapis.com","methodName":"get"},"insertId":"abc","resource":{"type":"gae_app"},"timestamp":"2020-03-04T12:00:00Z","logName":"projects/my-project/logs/appengine.googleapis.com%2Frequest_log"}`
+
+	require.Nil(t, (&AuditLogParser{}).Parse(log))
+}
+
+func TestAuditLogType(t *testing.T) {
+	parser := &AuditLogParser{}
+	require.Equal(t, "GCP.AuditLog", parser.LogType())
+}
+
+func checkAuditLog(t *testing.T, log string, expectedEvent *AuditLog) {
+	parser := &AuditLogParser{}
+	events := parser.Parse(log)
+	require.Equal(t, 1, len(events))
+	event := events[0].(*AuditLog)
+
+	// rowid changes each time
+	require.Greater(t, len(*event.PantherRowID), 0) // ensure something is there.
+	expectedEvent.PantherRowID = event.PantherRowID
+
+	// PantherParseTime is set to time.Now().UTC(). Require not nil
+	require.NotNil(t, event.PantherParseTime)
+	expectedEvent.PantherParseTime = event.PantherParseTime
+
+	require.Equal(t, expectedEvent, event)
+}
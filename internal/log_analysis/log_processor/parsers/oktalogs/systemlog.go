@@ -0,0 +1,208 @@
+package oktalogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
+)
+
+var SystemLogDesc = `Okta SystemLog is an event of the System Log of an Okta organization, as pulled from its API.
+Reference: https://developer.okta.com/docs/reference/api/system-log/#logevent-object`
+
+// SystemLog is a LogEvent of the Okta System Log API.
+// nolint:lll
+type SystemLog struct {
+	UUID                  *string                `json:"uuid,omitempty" validate:"required" description:"Unique identifier for an individual event."`
+	Published             *timestamp.RFC3339     `json:"published,omitempty" validate:"required" description:"Timestamp when the event is published."`
+	EventType             *string                `json:"eventType,omitempty" validate:"required" description:"Type of event that is published, such as user.session.start."`
+	Version               *string                `json:"version,omitempty" validate:"required" description:"Versioning indicator."`
+	Severity              *string                `json:"severity,omitempty" validate:"required,oneof=DEBUG INFO WARN ERROR" description:"Indicates how severe the event is: DEBUG, INFO, WARN, ERROR."`
+	LegacyEventType       *string                `json:"legacyEventType,omitempty" description:"Associated Events API Action objectType attribute value."`
+	DisplayMessage        *string                `json:"displayMessage,omitempty" description:"The display message for an event."`
+	Actor                 *Actor                 `json:"actor,omitempty" description:"Describes the entity that performed an action."`
+	Client                *Client                `json:"client,omitempty" description:"The client that requested an action."`
+	Request               *Request               `json:"request,omitempty" description:"The request that initiated an action."`
+	Outcome               *Outcome               `json:"outcome,omitempty" description:"The outcome of an action."`
+	Target                []*Target              `json:"target,omitempty" description:"Zero or more targets of an action."`
+	Transaction           *Transaction           `json:"transaction,omitempty" description:"The transaction details of an action."`
+	DebugContext          *DebugContext          `json:"debugContext,omitempty" description:"The debug request data of an action."`
+	AuthenticationContext *AuthenticationContext `json:"authenticationContext,omitempty" description:"The authentication data of an action."`
+	SecurityContext       *SecurityContext       `json:"securityContext,omitempty" description:"The security data of an action."`
+
+	// NOTE: added to end of struct to allow expansion later
+	parsers.PantherLog
+}
+
+// Actor is the entity that performed an action, such as a user or an app.
+type Actor struct {
+	ID          *string              `json:"id,omitempty" validate:"required" description:"ID of the actor."`
+	Type        *string              `json:"type,omitempty" validate:"required" description:"Type of the actor, such as User."`
+	AlternateID *string              `json:"alternateId,omitempty" description:"Alternative ID of the actor, such as the login of a user."`
+	DisplayName *string              `json:"displayName,omitempty" description:"Display name of the actor."`
+	DetailEntry *jsoniter.RawMessage `json:"detailEntry,omitempty" description:"Details about the actor."`
+}
+
+// Target is an entity an action was performed on.
+type Target struct {
+	ID          *string              `json:"id,omitempty" validate:"required" description:"ID of the target."`
+	Type        *string              `json:"type,omitempty" validate:"required" description:"Type of the target, such as AppInstance."`
+	AlternateID *string              `json:"alternateId,omitempty" description:"Alternative ID of the target."`
+	DisplayName *string              `json:"displayName,omitempty" description:"Display name of the target."`
+	DetailEntry *jsoniter.RawMessage `json:"detailEntry,omitempty" description:"Details about the target."`
+}
+
+// Client is the client that requested an action.
+// nolint:lll
+type Client struct {
+	ID                  *string              `json:"id,omitempty" description:"For OAuth requests, the ID of the OAuth client making the request."`
+	UserAgent           *UserAgent           `json:"userAgent,omitempty" description:"The user agent of the client."`
+	Zone                *string              `json:"zone,omitempty" description:"The name of the Zone the IP address of the client belongs to."`
+	Device              *string              `json:"device,omitempty" description:"Type of device the client operated from, such as Computer."`
+	IPAddress           *string              `json:"ipAddress,omitempty" description:"IP address the client made its request from."`
+	GeographicalContext *GeographicalContext `json:"geographicalContext,omitempty" description:"The physical location the client made its request from."`
+}
+
+// UserAgent is the user agent of a client.
+type UserAgent struct {
+	RawUserAgent *string `json:"rawUserAgent,omitempty" description:"The raw user agent string of the client."`
+	OS           *string `json:"os,omitempty" description:"The operating system the client runs on, such as Windows 10."`
+	Browser      *string `json:"browser,omitempty" description:"The browser the client uses, such as CHROME."`
+}
+
+// GeographicalContext is the physical location of an IP address.
+type GeographicalContext struct {
+	City        *string      `json:"city,omitempty" description:"The city encompassing the area of the IP address."`
+	State       *string      `json:"state,omitempty" description:"The state encompassing the area of the IP address."`
+	Country     *string      `json:"country,omitempty" description:"The country encompassing the area of the IP address."`
+	PostalCode  *string      `json:"postalCode,omitempty" description:"The postal code encompassing the area of the IP address."`
+	Geolocation *Geolocation `json:"geolocation,omitempty" description:"The latitude and longitude of the IP address."`
+}
+
+// Geolocation is a point on Earth.
+type Geolocation struct {
+	Lat *float64 `json:"lat,omitempty" description:"Latitude of the point."`
+	Lon *float64 `json:"lon,omitempty" description:"Longitude of the point."`
+}
+
+// Request is the request that initiated an action.
+type Request struct {
+	IPChain []*IPAddress `json:"ipChain,omitempty" description:"The IP addresses the request went through, starting with the client."`
+}
+
+// IPAddress is an IP address the request went through.
+type IPAddress struct {
+	IP                  *string              `json:"ip,omitempty" description:"The IP address."`
+	GeographicalContext *GeographicalContext `json:"geographicalContext,omitempty" description:"The physical location of the IP address."`
+	Version             *string              `json:"version,omitempty" description:"The IP version: V4 or V6."`
+	Source              *string              `json:"source,omitempty" description:"Details regarding the source of the IP address."`
+}
+
+// Outcome is the result of an action.
+type Outcome struct {
+	Result *string `json:"result,omitempty" description:"Result of the action: SUCCESS, FAILURE, SKIPPED, ALLOW, DENY, CHALLENGE, UNKNOWN."`
+	Reason *string `json:"reason,omitempty" description:"Reason for the result, such as INVALID_CREDENTIALS."`
+}
+
+// Transaction is the request an action was performed in.
+type Transaction struct {
+	ID     *string              `json:"id,omitempty" description:"Unique identifier for the transaction."`
+	Type   *string              `json:"type,omitempty" description:"Type of the transaction: WEB or JOB."`
+	Detail *jsoniter.RawMessage `json:"detail,omitempty" description:"Details of the transaction."`
+}
+
+// DebugContext is the debug data of an action.
+type DebugContext struct {
+	DebugData *jsoniter.RawMessage `json:"debugData,omitempty" description:"Dynamic field of the debug information of the action."`
+}
+
+// AuthenticationContext is how the actor authenticated.
+// nolint:lll
+type AuthenticationContext struct {
+	AuthenticationProvider *string              `json:"authenticationProvider,omitempty" description:"The system that proves the identity of the actor, such as OKTA_AUTHENTICATION_PROVIDER."`
+	CredentialProvider     *string              `json:"credentialProvider,omitempty" description:"The system or app that provides the credentials of the actor, such as OKTA_CREDENTIAL_PROVIDER."`
+	CredentialType         *string              `json:"credentialType,omitempty" description:"The type of credentials the actor used, such as PASSWORD."`
+	Issuer                 *jsoniter.RawMessage `json:"issuer,omitempty" description:"The specific software entity that created and issued the credentials."`
+	ExternalSessionID      *string              `json:"externalSessionId,omitempty" description:"A proxy for the session ID of the actor."`
+	Interface              *string              `json:"interface,omitempty" description:"The third party user interface the actor authenticated through, if any."`
+	AuthenticationStep     *int32               `json:"authenticationStep,omitempty" description:"The zero-based step number in the authentication pipeline."`
+}
+
+// SecurityContext is the network the request came from.
+// nolint:lll
+type SecurityContext struct {
+	AsNumber *int64  `json:"asNumber,omitempty" description:"Autonomous system number associated with the autonomous system the request came from."`
+	AsOrg    *string `json:"asOrg,omitempty" description:"Organization associated with the autonomous system the request came from."`
+	ISP      *string `json:"isp,omitempty" description:"Internet service provider used to send the request."`
+	Domain   *string `json:"domain,omitempty" description:"The domain name associated with the IP address of the client."`
+	IsProxy  *bool   `json:"isProxy,omitempty" description:"Whether the request is from a known proxy."`
+}
+
+// SystemLogParser parses Okta System Log events, one event per line
+type SystemLogParser struct{}
+
+func (p *SystemLogParser) New() parsers.LogParser {
+	return &SystemLogParser{}
+}
+
+// Parse returns the parsed events or nil if parsing failed
+func (p *SystemLogParser) Parse(log string) []interface{} {
+	event := &SystemLog{}
+	err := jsoniter.UnmarshalFromString(log, event)
+	if err != nil {
+		zap.L().Debug("failed to parse log", zap.Error(err))
+		return nil
+	}
+
+	event.updatePantherFields(p)
+
+	if err := parsers.Validator.Struct(event); err != nil {
+		zap.L().Debug("failed to validate log", zap.Error(err))
+		return nil
+	}
+	return []interface{}{event}
+}
+
+// LogType returns the log type supported by this parser
+func (p *SystemLogParser) LogType() string {
+	return "Okta.SystemLog"
+}
+
+func (event *SystemLog) updatePantherFields(p *SystemLogParser) {
+	event.SetCoreFields(p.LogType(), event.Published)
+	if event.Client != nil && event.Client.IPAddress != nil && net.ParseIP(*event.Client.IPAddress) != nil {
+		event.AppendAnyIPAddresses(*event.Client.IPAddress)
+	}
+	if event.Request != nil {
+		for _, address := range event.Request.IPChain {
+			if address.IP != nil && net.ParseIP(*address.IP) != nil {
+				event.AppendAnyIPAddresses(*address.IP)
+			}
+		}
+	}
+	// The domain is "." when it isn't known
+	if event.SecurityContext != nil && event.SecurityContext.Domain != nil && *event.SecurityContext.Domain != "." {
+		event.AppendAnyDomainNames(*event.SecurityContext.Domain)
+	}
+}
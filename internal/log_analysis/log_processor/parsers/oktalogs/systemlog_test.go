@@ -0,0 +1,129 @@
+package oktalogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
+)
+
+func TestSystemLog(t *testing.T) {
+	//nolint:lll
+	log := `{"actor":{"id":"00u1qw1mqitPHM8AJ0g7","type":"User","alternateId":"admin@example.com","displayName":"Jane Doe","detailEntry":null},"client":{"userAgent":{"rawUserAgent":"Mozilla/5.0","os":"Mac OS X","browser":"CHROME"},"zone":"null","device":"Computer","id":null,"ipAddress":"203.0.113.7","geographicalContext":{"city":"Seattle","state":"Washington","country":"United States","postalCode":"98101","geolocation":{"lat":47.6,"lon":-122.3}}},"authenticationContext":{"authenticationProvider":null,"credentialProvider":null,"credentialType":null,"issuer":null,"interface":null,"authenticationStep":0,"externalSessionId":"102bZDNFfWaQSyEZQuDgWt-uQ"},"displayMessage":"User login to Okta","eventType":"user.session.start","outcome":{"result":"SUCCESS","reason":null},"published":"2020-03-04T12:00:00.396Z","securityContext":{"asNumber":null,"asOrg":null,"isp":null,"domain":".","isProxy":null},"severity":"INFO","debugContext":{"debugData":{"requestUri":"/api/v1/authn"}},"legacyEventType":"core.user_auth.login_success","transaction":{"type":"WEB","id":"Xl-XAXkkiTHDy4cGDA6r","detail":{}},"uuid":"f8c5a7b4-5e1d-11ea-9a3b-c1a1b1a2d2e3","version":"0","request":{"ipChain":[{"ip":"203.0.113.7","geographicalContext":{"city":"Seattle"},"version":"V4","source":null},{"ip":"198.51.100.1","version":"V4"}]},"target":[{"id":"0oa1gjh63g214q0Hq0g4","type":"AppInstance","alternateId":"Panther","displayName":"Panther"}]}`
+
+	expectedTime := time.Date(2020, 3, 4, 12, 0, 0, 396000000, time.UTC)
+	debugData := jsoniter.RawMessage(`{"requestUri":"/api/v1/authn"}`)
+	detail := jsoniter.RawMessage(`{}`)
+	expectedEvent := &SystemLog{
+		UUID:            aws.String("f8c5a7b4-5e1d-11ea-9a3b-c1a1b1a2d2e3"),
+		Published:       (*timestamp.RFC3339)(&expectedTime),
+		EventType:       aws.String("user.session.start"),
+		Version:         aws.String("0"),
+		Severity:        aws.String("INFO"),
+		LegacyEventType: aws.String("core.user_auth.login_success"),
+		DisplayMessage:  aws.String("User login to Okta"),
+		Actor: &Actor{
+			ID:          aws.String("00u1qw1mqitPHM8AJ0g7"),
+			Type:        aws.String("User"),
+			AlternateID: aws.String("admin@example.com"),
+			DisplayName: aws.String("Jane Doe"),
+		},
+		Client: &Client{
+			UserAgent: &UserAgent{RawUserAgent: aws.String("Mozilla/5.0"), OS: aws.String("Mac OS X"), Browser: aws.String("CHROME")},
+			Zone:      aws.String("null"),
+			Device:    aws.String("Computer"),
+			IPAddress: aws.String("203.0.113.7"),
+			GeographicalContext: &GeographicalContext{
+				City:        aws.String("Seattle"),
+				State:       aws.String("Washington"),
+				Country:     aws.String("United States"),
+				PostalCode:  aws.String("98101"),
+				Geolocation: &Geolocation{Lat: aws.Float64(47.6), Lon: aws.Float64(-122.3)},
+			},
+		},
+		Request: &Request{IPChain: []*IPAddress{
+			{IP: aws.String("203.0.113.7"), GeographicalContext: &GeographicalContext{City: aws.String("Seattle")}, Version: aws.String("V4")},
+			{IP: aws.String("198.51.100.1"), Version: aws.String("V4")},
+		}},
+		Outcome: &Outcome{Result: aws.String("SUCCESS")},
+		Target: []*Target{
+			{
+				ID:          aws.String("0oa1gjh63g214q0Hq0g4"),
+				Type:        aws.String("AppInstance"),
+				AlternateID: aws.String("Panther"),
+				DisplayName: aws.String("Panther"),
+			},
+		},
+		Transaction:  &Transaction{ID: aws.String("Xl-XAXkkiTHDy4cGDA6r"), Type: aws.String("WEB"), Detail: &detail},
+		DebugContext: &DebugContext{DebugData: &debugData},
+		AuthenticationContext: &AuthenticationContext{
+			ExternalSessionID:  aws.String("102bZDNFfWaQSyEZQuDgWt-uQ"),
+			AuthenticationStep: aws.Int32(0),
+		},
+		SecurityContext: &SecurityContext{Domain: aws.String(".")},
+	}
+
+	// panther fields
+	expectedEvent.PantherLogType = aws.String("Okta.SystemLog")
+	expectedEvent.PantherEventTime = (*timestamp.RFC3339)(&expectedTime)
+	expectedEvent.AppendAnyIPAddresses("203.0.113.7", "198.51.100.1")
+
+	checkSystemLog(t, log, expectedEvent)
+}
+
+func TestSystemLogMissingActor(t *testing.T) {
+	//nolint:lll
+	log := `{"actor":{"displayName":"Jane Doe"},"eventType":"user.session.start","published":"2020-03-04T12:00:00.396Z","severity":"INFO","uuid":"f8c5a7b4-5e1d-11ea-9a3b-c1a1b1a2d2e3","version":"0"}`
+
+	require.Nil(t, (&SystemLogParser{}).Parse(log))
+}
+
+func TestSystemLogNotOkta(t *testing.T) {
+	log := `{"eventType":"user.session.start","published":"2020-03-04T12:00:00Z"}`
+
+	require.Nil(t, (&SystemLogParser{}).Parse(log))
+}
+
+func TestSystemLogType(t *testing.T) {
+	parser := &SystemLogParser{}
+	require.Equal(t, "Okta.SystemLog", parser.LogType())
+}
+
+func checkSystemLog(t *testing.T, log string, expectedEvent *SystemLog) {
+	parser := &SystemLogParser{}
+	events := parser.Parse(log)
+	require.Equal(t, 1, len(events))
+	event := events[0].(*SystemLog)
+
+	// rowid changes each time
+	require.Greater(t, len(*event.PantherRowID), 0) // ensure something is there.
+	expectedEvent.PantherRowID = event.PantherRowID
+
+	// PantherParseTime is set to time.Now().UTC(). Require not nil
+	require.NotNil(t, event.PantherParseTime)
+	expectedEvent.PantherParseTime = event.PantherParseTime
+
+	require.Equal(t, expectedEvent, event)
+}
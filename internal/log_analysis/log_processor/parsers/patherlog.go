@@ -50,6 +50,42 @@ type PantherLog struct {
 	PantherAnyDomainNames *PantherAnyString `json:"p_any_ip_domain_names,omitempty" description:"Panther added field with collection of domain names associated with the row"`
 	PantherAnySHA1Hashes  *PantherAnyString `json:"p_any_sha1_hashes,omitempty" description:"Panther added field with collection of SHA1 hashes associated with the row"`
 	PantherAnyMD5Hashes   *PantherAnyString `json:"p_any_md5_hashes,omitempty" description:"Panther added field with collection of MD5 hashes associated with the row"`
+
+	// optional (enrichment)
+	PantherThreatIntelMatches []ThreatIntelMatch `json:"p_threat_intel_matches,omitempty" description:"Panther added field with the indicators of the row found in threat intel sets"`
+	PantherIPEnrichment       []IPEnrichment     `json:"p_ip_enrichment,omitempty" description:"Panther added field with the geolocation and autonomous system of the ip addresses of the row"`
+}
+
+// ThreatIntelMatch is an indicator of a row which is in a threat intel set
+// nolint(lll)
+type ThreatIntelMatch struct {
+	SetID     string `json:"set_id" description:"The id of the threat intel set"`
+	SetName   string `json:"set_name" description:"The name of the threat intel set"`
+	Indicator string `json:"indicator" description:"The indicator of the set found in the row"`
+	Field     string `json:"field" description:"The Panther field holding the indicator"`
+}
+
+// IPEnrichment is the geolocation and autonomous system of an ip address of a row, from the GeoLite2 databases
+// nolint(lll)
+type IPEnrichment struct {
+	IP             string   `json:"ip" description:"The ip address"`
+	CountryCode    string   `json:"country_code,omitempty" description:"The ISO 3166-1 code of the country of the ip address"`
+	Country        string   `json:"country,omitempty" description:"The English name of the country of the ip address"`
+	City           string   `json:"city,omitempty" description:"The English name of the city of the ip address"`
+	Latitude       *float64 `json:"latitude,omitempty" description:"The approximate latitude of the ip address"`
+	Longitude      *float64 `json:"longitude,omitempty" description:"The approximate longitude of the ip address"`
+	ASN            *int64   `json:"asn,omitempty" description:"The number of the autonomous system of the ip address"`
+	ASOrganization string   `json:"as_organization,omitempty" description:"The organization of the autonomous system of the ip address"`
+}
+
+// PantherEvent is implemented by the events of all the parsers, through the PantherLog they extend
+type PantherEvent interface {
+	GetPantherLog() *PantherLog
+}
+
+// GetPantherLog returns the standardized fields of an event
+func (pl *PantherLog) GetPantherLog() *PantherLog {
+	return pl
 }
 
 type PantherAnyString struct { // needed to declare as struct (rather than map) for CF generation
@@ -62,16 +98,24 @@ func NewPantherAnyString() *PantherAnyString {
 	}
 }
 
+// Values returns the sorted values, nil for a nil any string
+func (any *PantherAnyString) Values() []string {
+	if any == nil {
+		return nil
+	}
+	values := make([]string, len(any.set))
+	i := 0
+	for k := range any.set {
+		values[i] = k
+		i++
+	}
+	sort.Strings(values) // sort for consistency and to improve compression when stored
+	return values
+}
+
 func (any *PantherAnyString) MarshalJSON() ([]byte, error) {
 	if any != nil { // copy to slice
-		values := make([]string, len(any.set))
-		i := 0
-		for k := range any.set {
-			values[i] = k
-			i++
-		}
-		sort.Strings(values) // sort for consistency and to improve compression when stored
-		return jsoniter.Marshal(values)
+		return jsoniter.Marshal(any.Values())
 	}
 	return []byte{}, nil
 }
@@ -21,6 +21,7 @@ package sysloglogs
 import (
 	"errors"
 	"net"
+	"regexp"
 	"time"
 
 	"github.com/influxdata/go-syslog/v3"
@@ -50,6 +51,9 @@ type RFC3164 struct {
 	parsers.PantherLog
 }
 
+// The version which follows the priority of RFC5424 messages, the best effort parsing of RFC3164 would accept them
+var rfc5424Header = regexp.MustCompile(`^<[0-9]{1,3}>[1-9][0-9]{0,2} `)
+
 // RFC3164Parser parses Syslog logs in the RFC3164 format
 type RFC3164Parser struct {
 	parser syslog.Machine
@@ -73,6 +77,10 @@ func (p *RFC3164Parser) Parse(log string) []interface{} {
 		zap.L().Debug("failed to parse log", zap.Error(errors.New("parser can not be nil")))
 		return nil
 	}
+	if rfc5424Header.MatchString(log) {
+		zap.L().Debug("failed to parse log", zap.Error(errors.New("the log is an RFC5424 message")))
+		return nil
+	}
 	msg, err := p.parser.Parse([]byte(log))
 	if err != nil {
 		zap.L().Debug("failed to parse log", zap.Error(err))
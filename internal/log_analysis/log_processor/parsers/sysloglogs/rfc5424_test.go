@@ -0,0 +1,160 @@
+package sysloglogs
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
+)
+
+func TestRFC5424(t *testing.T) {
+	zap.ReplaceGlobals(zaptest.NewLogger(t))
+
+	t.Run("Example1", testRFC5424Example1)
+	t.Run("Example3", testRFC5424Example3)
+	t.Run("IPHostname", testRFC5424IPHostname)
+	t.Run("RFC3164", testRFC5424RejectsRFC3164)
+}
+
+// Example1 from https://tools.ietf.org/html/rfc5424#section-6.5
+func testRFC5424Example1(t *testing.T) {
+	//nolint:lll
+	log := `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick on /dev/pts/8`
+
+	expectedTime := time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC)
+
+	expectedEvent := &RFC5424{
+		Priority:  aws.Uint8(34),
+		Facility:  aws.Uint8(4),
+		Severity:  aws.Uint8(2),
+		Version:   aws.Uint16(1),
+		Timestamp: (*timestamp.RFC3339)(&expectedTime),
+		Hostname:  aws.String("mymachine.example.com"),
+		Appname:   aws.String("su"),
+		ProcID:    nil,
+		MsgID:     aws.String("ID47"),
+		Message:   aws.String("'su root' failed for lonvick on /dev/pts/8"),
+	}
+
+	expectedEvent.AppendAnyDomainNamePtrs(expectedEvent.Hostname)
+
+	// panther fields
+	expectedEvent.PantherLogType = aws.String("Syslog.RFC5424")
+	expectedEvent.PantherEventTime = (*timestamp.RFC3339)(&expectedTime)
+
+	checkRFC5424(t, log, expectedEvent)
+}
+
+// Example3 from https://tools.ietf.org/html/rfc5424#section-6.5, the timestamp is converted to UTC
+func testRFC5424Example3(t *testing.T) {
+	//nolint:lll
+	log := `<165>1 2003-10-11T22:14:15.003-07:00 mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"] An application event log entry...`
+
+	expectedTime := time.Date(2003, 10, 12, 5, 14, 15, 3000000, time.UTC)
+
+	expectedEvent := &RFC5424{
+		Priority:  aws.Uint8(165),
+		Facility:  aws.Uint8(20),
+		Severity:  aws.Uint8(5),
+		Version:   aws.Uint16(1),
+		Timestamp: (*timestamp.RFC3339)(&expectedTime),
+		Hostname:  aws.String("mymachine.example.com"),
+		Appname:   aws.String("evntslog"),
+		ProcID:    nil,
+		MsgID:     aws.String("ID47"),
+		StructuredData: &map[string]map[string]string{
+			"exampleSDID@32473": {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+		},
+		Message: aws.String("An application event log entry..."),
+	}
+
+	expectedEvent.AppendAnyDomainNamePtrs(expectedEvent.Hostname)
+
+	// panther fields
+	expectedEvent.PantherLogType = aws.String("Syslog.RFC5424")
+	expectedEvent.PantherEventTime = (*timestamp.RFC3339)(&expectedTime)
+
+	checkRFC5424(t, log, expectedEvent)
+}
+
+func testRFC5424IPHostname(t *testing.T) {
+	log := `<13>1 2020-04-02T16:31:03Z 10.0.0.99 app 23410 - - Test`
+
+	expectedTime := time.Date(2020, 4, 2, 16, 31, 3, 0, time.UTC)
+
+	expectedEvent := &RFC5424{
+		Priority:  aws.Uint8(13),
+		Facility:  aws.Uint8(1),
+		Severity:  aws.Uint8(5),
+		Version:   aws.Uint16(1),
+		Timestamp: (*timestamp.RFC3339)(&expectedTime),
+		Hostname:  aws.String("10.0.0.99"),
+		Appname:   aws.String("app"),
+		ProcID:    aws.String("23410"),
+		MsgID:     nil,
+		Message:   aws.String("Test"),
+	}
+
+	expectedEvent.AppendAnyIPAddressPtrs(expectedEvent.Hostname)
+
+	// panther fields
+	expectedEvent.PantherLogType = aws.String("Syslog.RFC5424")
+	expectedEvent.PantherEventTime = (*timestamp.RFC3339)(&expectedTime)
+
+	checkRFC5424(t, log, expectedEvent)
+}
+
+// The messages of the two formats are told apart by the version of RFC5424
+func testRFC5424RejectsRFC3164(t *testing.T) {
+	rfc3164Log := `<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8`
+	rfc5424Log := `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - 'su root' failed for lonvick on /dev/pts/8`
+
+	require.Nil(t, (&RFC5424Parser{}).New().Parse(rfc3164Log))
+	require.Nil(t, (&RFC3164Parser{}).New().Parse(rfc5424Log))
+}
+
+func TestRFC5424Type(t *testing.T) {
+	parser := &RFC5424Parser{}
+	require.Equal(t, "Syslog.RFC5424", parser.LogType())
+}
+
+func checkRFC5424(t *testing.T, log string, expectedEvent *RFC5424) {
+	var parser parsers.LogParser = (&RFC5424Parser{}).New()
+	events := parser.Parse(log)
+	require.Equal(t, 1, len(events))
+	event := events[0].(*RFC5424)
+
+	// rowid changes each time
+	require.Greater(t, len(*event.PantherRowID), 0) // ensure something is there.
+	expectedEvent.PantherRowID = event.PantherRowID
+
+	// PantherParseTime is set to time.Now().UTC(). Require not nil
+	require.NotNil(t, event.PantherParseTime)
+	expectedEvent.PantherParseTime = event.PantherParseTime
+
+	require.Equal(t, expectedEvent, event)
+}
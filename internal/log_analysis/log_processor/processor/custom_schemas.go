@@ -0,0 +1,73 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	customSchemaAPIFunctionName = "panther-custom-schema-api"
+
+	// How long the parsers of the custom schemas are used before the schemas are listed again
+	customSchemasTTL = 5 * time.Minute
+)
+
+var (
+	lambdaClient lambdaiface.LambdaAPI = lambda.New(common.Session)
+
+	listCustomSchemasFunc = func() (schemas []*models.CustomSchema, err error) {
+		err = genericapi.Invoke(lambdaClient, customSchemaAPIFunctionName, &models.LambdaInput{
+			ListCustomSchemas: &models.ListCustomSchemasInput{},
+		}, &schemas)
+		return
+	}
+
+	customSchemasExpiry  time.Time
+	customSchemasLock    sync.Mutex
+	customSchemasNowFunc = time.Now
+)
+
+// refreshCustomParsers registers the parsers of the custom schemas, once their last listing expired
+func refreshCustomParsers() error {
+	customSchemasLock.Lock()
+	defer customSchemasLock.Unlock()
+
+	now := customSchemasNowFunc()
+	if now.Before(customSchemasExpiry) {
+		return nil
+	}
+	schemas, err := listCustomSchemasFunc()
+	if err != nil {
+		return errors.Wrap(err, "failed to list the custom schemas")
+	}
+	registry.SetCustomParsers(schemas)
+	customSchemasExpiry = now.Add(customSchemasTTL)
+	return nil
+}
@@ -0,0 +1,233 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/pkg/maxmind"
+)
+
+const (
+	geoIPCityDatabase = "GeoLite2-City.mmdb"
+	geoIPASNDatabase  = "GeoLite2-ASN.mmdb"
+
+	// How long the GeoLite2 databases are used before S3 is checked for updated ones
+	geoIPTTL = time.Hour
+)
+
+var (
+	// The S3 location of the GeoLite2 databases as bucket/prefix, the enrichment is disabled without one
+	geoIPDatabasePath = os.Getenv("GEOIP_DATABASE_PATH")
+
+	// getGeoIPDatabaseFunc returns a database file unless it still has the given ETag, a missing file is nil
+	getGeoIPDatabaseFunc = func(name, etag string) (file []byte, newETag string, err error) {
+		bucket, prefix := splitGeoIPDatabasePath()
+		input := &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(prefix + name)}
+		if etag != "" {
+			input.IfNoneMatch = aws.String(etag)
+		}
+		output, err := s3Client.GetObject(input)
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case "NotModified":
+				return nil, etag, nil
+			case s3.ErrCodeNoSuchKey:
+				return nil, "", nil
+			}
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		defer output.Body.Close()
+		if file, err = ioutil.ReadAll(output.Body); err != nil {
+			return nil, "", err
+		}
+		return file, aws.StringValue(output.ETag), nil
+	}
+
+	geoIPDatabases = make(map[string]*geoIPDatabase) // by file name
+	geoIPExpiry    time.Time
+	geoIPLock      sync.Mutex
+	geoIPNowFunc   = time.Now
+)
+
+type geoIPDatabase struct {
+	etag   string
+	reader *maxmind.Reader
+}
+
+func splitGeoIPDatabasePath() (bucket, prefix string) {
+	path := strings.Trim(geoIPDatabasePath, "/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i], path[i+1:] + "/"
+	}
+	return path, ""
+}
+
+// geoIPEnricher adds the geolocation and autonomous system of the ip addresses to the events
+type geoIPEnricher struct {
+	city *maxmind.Reader
+	asn  *maxmind.Reader
+}
+
+// loadGeoIP returns the enricher of the GeoLite2 databases, reading them again once they were updated in S3.
+//
+// It is nil when the enrichment is not configured, or none of the databases are in S3.
+func loadGeoIP() (*geoIPEnricher, error) {
+	if geoIPDatabasePath == "" {
+		return nil, nil
+	}
+	geoIPLock.Lock()
+	defer geoIPLock.Unlock()
+
+	now := geoIPNowFunc()
+	if !now.Before(geoIPExpiry) {
+		for _, name := range []string{geoIPCityDatabase, geoIPASNDatabase} {
+			if err := refreshGeoIPDatabase(name); err != nil {
+				return nil, err
+			}
+		}
+		geoIPExpiry = now.Add(geoIPTTL)
+	}
+
+	enricher := &geoIPEnricher{}
+	if database, ok := geoIPDatabases[geoIPCityDatabase]; ok {
+		enricher.city = database.reader
+	}
+	if database, ok := geoIPDatabases[geoIPASNDatabase]; ok {
+		enricher.asn = database.reader
+	}
+	if enricher.city == nil && enricher.asn == nil {
+		return nil, nil
+	}
+	return enricher, nil
+}
+
+func refreshGeoIPDatabase(name string) error {
+	var etag string
+	if database, ok := geoIPDatabases[name]; ok {
+		etag = database.etag
+	}
+	file, newETag, err := getGeoIPDatabaseFunc(name, etag)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the GeoIP database %s", name)
+	}
+	switch {
+	case newETag == "":
+		delete(geoIPDatabases, name)
+	case file != nil:
+		reader, err := maxmind.Open(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open the GeoIP database %s", name)
+		}
+		zap.L().Info("loaded GeoIP database", zap.String("name", name), zap.String("type", reader.Metadata.DatabaseType),
+			zap.Time("buildTime", time.Unix(int64(reader.Metadata.BuildEpoch), 0).UTC()))
+		geoIPDatabases[name] = &geoIPDatabase{etag: newETag, reader: reader}
+	}
+	return nil
+}
+
+// enrich sets the p_ip_enrichment of an event, the enricher of a processor is optional.
+//
+// The redacted ip addresses are not geolocated, their enrichment would be stored along with them.
+func (e *geoIPEnricher) enrich(event interface{}, redacted map[string]struct{}) {
+	if e == nil {
+		return
+	}
+	pantherEvent, ok := event.(parsers.PantherEvent)
+	if !ok {
+		return
+	}
+	pantherLog := pantherEvent.GetPantherLog()
+
+	var enrichments []parsers.IPEnrichment
+	for _, value := range pantherLog.PantherAnyIPAddresses.Values() {
+		if _, ok := redacted[value]; ok {
+			continue
+		}
+		ip := net.ParseIP(value)
+		if ip == nil {
+			continue
+		}
+		enrichment := parsers.IPEnrichment{IP: value}
+		found := e.lookupCity(ip, &enrichment)
+		if e.lookupASN(ip, &enrichment) {
+			found = true
+		}
+		if found {
+			enrichments = append(enrichments, enrichment)
+		}
+	}
+	pantherLog.PantherIPEnrichment = enrichments
+}
+
+// Lookup failures are a corrupted database, the events are still stored without the enrichment
+func (e *geoIPEnricher) lookup(reader *maxmind.Reader, ip net.IP) interface{} {
+	if reader == nil {
+		return nil
+	}
+	record, err := reader.Lookup(ip)
+	if err != nil {
+		zap.L().Warn("failed to look up ip address", zap.String("database", reader.Metadata.DatabaseType), zap.Error(err))
+		return nil
+	}
+	return record
+}
+
+func (e *geoIPEnricher) lookupCity(ip net.IP, enrichment *parsers.IPEnrichment) bool {
+	record := e.lookup(e.city, ip)
+	if record == nil {
+		return false
+	}
+	enrichment.CountryCode, _ = maxmind.Value(record, "country", "iso_code").(string)
+	enrichment.Country, _ = maxmind.Value(record, "country", "names", "en").(string)
+	enrichment.City, _ = maxmind.Value(record, "city", "names", "en").(string)
+	if latitude, ok := maxmind.Value(record, "location", "latitude").(float64); ok {
+		enrichment.Latitude = &latitude
+	}
+	if longitude, ok := maxmind.Value(record, "location", "longitude").(float64); ok {
+		enrichment.Longitude = &longitude
+	}
+	return true
+}
+
+func (e *geoIPEnricher) lookupASN(ip net.IP, enrichment *parsers.IPEnrichment) bool {
+	record := e.lookup(e.asn, ip)
+	if record == nil {
+		return false
+	}
+	if asn, ok := maxmind.Value(record, "autonomous_system_number").(uint64); ok {
+		enrichment.ASN = aws.Int64(int64(asn))
+	}
+	enrichment.ASOrganization, _ = maxmind.Value(record, "autonomous_system_organization").(string)
+	return true
+}
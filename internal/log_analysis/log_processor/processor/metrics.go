@@ -0,0 +1,163 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/metrics/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+// The metrics of the ingestion are published per log type and per source
+var metricsLogger = metrics.NewLogger([]string{models.DimensionLogType}, []string{models.DimensionSourceID})
+
+// ingestionMetrics counts the data ingested by a processor, per log type.
+type ingestionMetrics struct {
+	sourceID string
+	now      func() time.Time
+	logTypes map[string]*ingestionCounts
+
+	// The log type of the data stream is unknown for the lines which could not be classified
+	classificationErrors uint64
+}
+
+type ingestionCounts struct {
+	events uint64
+	bytes  uint64
+
+	// The delay between the time of the events and their processing
+	latencyTotal  time.Duration
+	latencyEvents uint64
+}
+
+func newIngestionMetrics(sourceID string) *ingestionMetrics {
+	return &ingestionMetrics{sourceID: sourceID, now: time.Now, logTypes: make(map[string]*ingestionCounts)}
+}
+
+func (m *ingestionMetrics) counts(logType string) *ingestionCounts {
+	counts, ok := m.logTypes[logType]
+	if !ok {
+		counts = &ingestionCounts{}
+		m.logTypes[logType] = counts
+	}
+	return counts
+}
+
+// addLatency measures the delay of a parsed event, the events without a time are skipped.
+func (m *ingestionMetrics) addLatency(event interface{}, logType string) {
+	if m == nil {
+		return
+	}
+	pantherEvent, ok := event.(parsers.PantherEvent)
+	if !ok || pantherEvent.GetPantherLog().PantherEventTime == nil {
+		return
+	}
+	counts := m.counts(logType)
+	counts.latencyTotal += m.now().Sub(time.Time(*pantherEvent.GetPantherLog().PantherEventTime))
+	counts.latencyEvents++
+}
+
+// addStats counts the data of the data stream, once it is processed.
+func (m *ingestionMetrics) addStats(stats *classification.ClassifierStats, parserStats map[string]*classification.ParserStats) {
+	if m == nil {
+		return
+	}
+	m.classificationErrors += stats.ClassificationFailureCount
+	for _, parserStat := range parserStats {
+		counts := m.counts(parserStat.LogType)
+		counts.events += parserStat.EventCount
+		counts.bytes += parserStat.BytesProcessedCount
+	}
+}
+
+// publishIngestionMetrics sums the metrics of the processors by source and log type and publishes them.
+//
+// A failure is logged but does not fail the processing.
+func publishIngestionMetrics(processors []*Processor) {
+	type key struct{ sourceID, logType string }
+	totals := make(map[key]*ingestionCounts)
+	classificationErrors := make(map[string]uint64)
+	for _, p := range processors {
+		if p.metrics == nil {
+			continue
+		}
+		classificationErrors[p.metrics.sourceID] += p.metrics.classificationErrors
+		for logType, counts := range p.metrics.logTypes {
+			k := key{sourceID: p.metrics.sourceID, logType: logType}
+			if totals[k] == nil {
+				totals[k] = &ingestionCounts{}
+			}
+			totals[k].events += counts.events
+			totals[k].bytes += counts.bytes
+			totals[k].latencyTotal += counts.latencyTotal
+			totals[k].latencyEvents += counts.latencyEvents
+		}
+	}
+
+	keys := make([]key, 0, len(totals))
+	for k := range totals {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { // deterministic logs
+		return keys[i].sourceID < keys[j].sourceID || keys[i].sourceID == keys[j].sourceID && keys[i].logType < keys[j].logType
+	})
+	for _, k := range keys {
+		counts := totals[k]
+		values := []metrics.Metric{
+			{Name: models.MetricEventsProcessed, Unit: metrics.UnitCount, Value: float64(counts.events)},
+			{Name: models.MetricBytesIngested, Unit: metrics.UnitBytes, Value: float64(counts.bytes)},
+		}
+		if counts.latencyEvents > 0 {
+			latency := counts.latencyTotal / time.Duration(counts.latencyEvents)
+			values = append(values, metrics.Metric{
+				Name:  models.MetricProcessingLatency,
+				Unit:  metrics.UnitMilliseconds,
+				Value: float64(latency / time.Millisecond),
+			})
+		}
+		logMetrics(map[string]string{models.DimensionLogType: k.logType, models.DimensionSourceID: k.sourceID}, values...)
+	}
+
+	sourceIDs := make([]string, 0, len(classificationErrors))
+	for sourceID, count := range classificationErrors {
+		if count > 0 {
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+	}
+	sort.Strings(sourceIDs)
+	for _, sourceID := range sourceIDs {
+		logMetrics(map[string]string{models.DimensionSourceID: sourceID}, metrics.Metric{
+			Name:  models.MetricClassificationErrors,
+			Unit:  metrics.UnitCount,
+			Value: float64(classificationErrors[sourceID]),
+		})
+	}
+}
+
+func logMetrics(dimensions map[string]string, values ...metrics.Metric) {
+	if err := metricsLogger.Log(dimensions, values...); err != nil {
+		zap.L().Warn("failed to publish ingestion metrics", zap.Error(err))
+	}
+}
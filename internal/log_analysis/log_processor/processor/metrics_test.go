@@ -0,0 +1,104 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
+)
+
+func metricsLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var parsed map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &parsed))
+		delete(parsed, "_aws")
+		result = append(result, parsed)
+	}
+	return result
+}
+
+func TestPublishIngestionMetrics(t *testing.T) {
+	var out bytes.Buffer
+	defer func(stdout io.Writer) { metricsLogger.Out = stdout }(metricsLogger.Out)
+	metricsLogger.Out = &out
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	eventTime := timestamp.RFC3339(now.Add(-2 * time.Second))
+	event := &parsers.PantherLog{PantherEventTime: &eventTime}
+
+	first := newIngestionMetrics("source-1")
+	first.now = func() time.Time { return now }
+	first.addLatency(event, "AWS.CloudTrail")
+	first.addLatency("not a panther event", "AWS.CloudTrail")
+	first.addStats(&classification.ClassifierStats{ClassificationFailureCount: 2},
+		map[string]*classification.ParserStats{
+			"AWS.CloudTrail": {LogType: "AWS.CloudTrail", EventCount: 10, BytesProcessedCount: 1000},
+		})
+	second := newIngestionMetrics("source-1")
+	second.addStats(&classification.ClassifierStats{ClassificationFailureCount: 1},
+		map[string]*classification.ParserStats{
+			"AWS.CloudTrail": {LogType: "AWS.CloudTrail", EventCount: 5, BytesProcessedCount: 500},
+			"AWS.S3":         {LogType: "AWS.S3", EventCount: 1, BytesProcessedCount: 100},
+		})
+
+	publishIngestionMetrics([]*Processor{{metrics: first}, {metrics: second}, {}})
+
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"LogType": "AWS.CloudTrail", "SourceId": "source-1",
+			"EventsProcessed": 15.0, "BytesIngested": 1500.0, "ProcessingLatency": 2000.0,
+		},
+		{
+			"LogType": "AWS.S3", "SourceId": "source-1",
+			"EventsProcessed": 1.0, "BytesIngested": 100.0,
+		},
+		{"SourceId": "source-1", "ClassificationErrors": 3.0},
+	}, metricsLines(t, &out))
+}
+
+func TestPublishIngestionMetricsUnknownSource(t *testing.T) {
+	var out bytes.Buffer
+	defer func(stdout io.Writer) { metricsLogger.Out = stdout }(metricsLogger.Out)
+	metricsLogger.Out = &out
+
+	m := newIngestionMetrics("")
+	m.addStats(&classification.ClassifierStats{ClassificationFailureCount: 1},
+		map[string]*classification.ParserStats{"AWS.S3": {LogType: "AWS.S3", EventCount: 1}})
+	publishIngestionMetrics([]*Processor{{metrics: m}})
+
+	// Only published per log type
+	assert.Equal(t, []map[string]interface{}{
+		{"LogType": "AWS.S3", "EventsProcessed": 1.0, "BytesIngested": 0.0},
+	}, metricsLines(t, &out))
+}
@@ -21,11 +21,14 @@ package processor
 import (
 	"bufio"
 	"io"
+	"os"
+	"strconv"
 	"sync"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	sourcemodels "github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
@@ -46,12 +49,68 @@ var (
 	// to avoid using up lot of memory.
 	// see also: https://golang.org/doc/effective_go.html#channels
 	ParsedEventBufferSize = 1000
+
+	// concurrency is how many data streams are decompressed and parsed at the same time, set with
+	// PROCESSOR_CONCURRENCY. The other streams wait for a worker, so a large object doesn't starve the others of memory
+	// and the objects behind it in the batch aren't opened before they are read.
+	concurrency = parseConcurrency(os.Getenv("PROCESSOR_CONCURRENCY"))
 )
 
+const defaultConcurrency = 8
+
+// parseConcurrency returns the configured concurrency, the default one if it isn't a positive number
+func parseConcurrency(value string) int {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return n
+	}
+	return defaultConcurrency
+}
+
 // Process orchestrates the tasks of parsing logs, classification, normalization
 // and forwarding the logs to the appropriate destination. Any errors will cause Lambda invocation to fail
 func Process(dataStreams []*common.DataStream, destination destinations.Destination) error {
-	return process(dataStreams, destination, NewProcessor)
+	// The classifiers of the data streams include the parsers of the custom schemas
+	if err := refreshCustomParsers(); err != nil {
+		return err
+	}
+	// The events are annotated with their indicators found in the threat intel sets
+	index, err := loadThreatIntel()
+	if err != nil {
+		return err
+	}
+	matcher := newThreatIntelMatcher(index)
+	// The ip addresses of the events are geolocated with the GeoLite2 databases, when they are configured, for the
+	// integrations opted into the beta enrichments
+	enricher, err := loadGeoIP()
+	if err != nil {
+		return err
+	}
+	// The sensitive fields of the events are redacted before they are stored
+	redactor, err := loadRedactionPolicies()
+	if err != nil {
+		return err
+	}
+	var processors []*Processor
+	err = process(dataStreams, destination, func(input *common.DataStream) *Processor {
+		p := NewProcessor(input)
+		p.threatIntel, p.geoIP, p.redactor = matcher, betaEnricher(input, enricher), redactor
+		processors = append(processors, p)
+		return p
+	})
+	if err != nil {
+		return err
+	}
+	matcher.sendAlerts()
+	publishIngestionMetrics(processors)
+	return nil
+}
+
+// betaEnricher returns the GeoIP enricher for the source integrations opted into the beta enrichments, nil otherwise
+func betaEnricher(input *common.DataStream, enricher *geoIPEnricher) *geoIPEnricher {
+	if input.Source == nil || !input.Source.FeatureEnabled(sourcemodels.FeatureFlagBetaEnrichment) {
+		return nil
+	}
+	return enricher
 }
 
 // entry point to allow customizing processor for testing
@@ -69,12 +128,16 @@ func process(dataStreams []*common.DataStream, destination destinations.Destinat
 		sendEventsWg.Done()
 	}()
 
+	// The data streams are processed by a bounded pool of workers
 	var streamProcessingWg sync.WaitGroup
+	workers := make(chan struct{}, concurrency)
 	for _, dataStream := range dataStreams {
 		processor := newProcessorFunc(dataStream)
 		streamProcessingWg.Add(1)
 		go func(p *Processor) {
+			workers <- struct{}{}
 			err := p.run(parsedEventChannel)
+			<-workers
 			if err != nil {
 				errorChannel <- err
 			}
@@ -143,17 +206,47 @@ func (p *Processor) classifyLogLine(line string) *classification.ClassifierResul
 				zap.String("bucket", p.input.Hints.S3.Bucket),
 				zap.String("key", p.input.Hints.S3.Key))
 		}
+		if p.input.Hints.SQS != nil {
+			p.operation.LogWarn(errors.New("failed to classify log event"),
+				zap.Uint64("lineNum", p.classifier.Stats().LogLineCount),
+				zap.String("queueArn", p.input.Hints.SQS.QueueArn))
+		}
+		if p.input.Hints.Kinesis != nil {
+			p.operation.LogWarn(errors.New("failed to classify log record"),
+				zap.Uint64("lineNum", p.classifier.Stats().LogLineCount),
+				zap.String("streamArn", p.input.Hints.Kinesis.StreamArn),
+				zap.String("shardId", p.input.Hints.Kinesis.ShardID))
+		}
+		if p.input.Hints.Okta != nil {
+			p.operation.LogWarn(errors.New("failed to classify log event"),
+				zap.Uint64("lineNum", p.classifier.Stats().LogLineCount),
+				zap.String("oktaDomain", p.input.Hints.Okta.Domain))
+		}
 	}
 	return result
 }
 
 func (p *Processor) sendEvents(result *classification.ClassifierResult, outputChan chan *common.ParsedEvent) {
 	for _, parsedEvent := range result.Events {
-		message := &common.ParsedEvent{
-			Event:   parsedEvent,
-			LogType: *result.LogType,
+		// The redacted values are known before the enrichment, which skips them
+		redacted, err := p.redactor.redactedValues(parsedEvent, *result.LogType)
+		if err != nil { // an event which can't be redacted is not stored
+			p.operation.LogError(errors.Wrap(err, "failed to redact event"), zap.String("logType", *result.LogType))
+			continue
+		}
+		p.threatIntel.enrich(parsedEvent, *result.LogType, redacted)
+		p.geoIP.enrich(parsedEvent, redacted)
+		p.metrics.addLatency(parsedEvent, *result.LogType)
+		event, err := p.redactor.redact(parsedEvent, *result.LogType)
+		if err != nil {
+			p.operation.LogError(errors.Wrap(err, "failed to redact event"), zap.String("logType", *result.LogType))
+			continue
+		}
+		outputChan <- &common.ParsedEvent{
+			Event:    event,
+			LogType:  *result.LogType,
+			SourceID: p.input.SourceID,
 		}
-		outputChan <- message
 	}
 }
 
@@ -163,12 +256,22 @@ func (p *Processor) logStats(err error) {
 	for _, parserStats := range p.classifier.ParserStats() {
 		p.operation.Log(err, zap.Any(statsKey, *parserStats))
 	}
+	p.metrics.addStats(p.classifier.Stats(), p.classifier.ParserStats())
 }
 
 type Processor struct {
 	input      *common.DataStream
 	classifier classification.ClassifierAPI
 	operation  *oplog.Operation
+
+	// Optional, add the threat intel matches and the GeoIP enrichment of the values which are not redacted to the
+	// events, then redact them
+	threatIntel *threatIntelMatcher
+	geoIP       *geoIPEnricher
+	redactor    *eventRedactor
+
+	// Optional, counts the data for the ingestion metrics
+	metrics *ingestionMetrics
 }
 
 func NewProcessor(input *common.DataStream) *Processor {
@@ -176,5 +279,6 @@ func NewProcessor(input *common.DataStream) *Processor {
 		input:      input,
 		classifier: classification.NewClassifier(),
 		operation:  common.OpLogManager.Start(operationName),
+		metrics:    newIngestionMetrics(input.SourceID),
 	}
 }
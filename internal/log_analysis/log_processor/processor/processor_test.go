@@ -19,11 +19,17 @@ package processor
  */
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -32,9 +38,16 @@ import (
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
 
+	schemamodels "github.com/panther-labs/panther/api/lambda/customschema/models"
+	redactionmodels "github.com/panther-labs/panther/api/lambda/redaction/models"
+	sourcemodels "github.com/panther-labs/panther/api/lambda/source/models"
+	threatmodels "github.com/panther-labs/panther/api/lambda/threatintel/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/pkg/maxmind"
 	"github.com/panther-labs/panther/pkg/oplog"
 )
 
@@ -91,6 +104,51 @@ func TestProcess(t *testing.T) {
 	require.Equal(t, testLogEvents, destination.nEvents)
 }
 
+// concurrentReader records how many readers are read at the same time
+type concurrentReader struct {
+	lock    *sync.Mutex
+	active  *int
+	maximum *int
+}
+
+func (r *concurrentReader) Read([]byte) (int, error) {
+	r.lock.Lock()
+	*r.active++
+	if *r.active > *r.maximum {
+		*r.maximum = *r.active
+	}
+	r.lock.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	r.lock.Lock()
+	*r.active--
+	r.lock.Unlock()
+	return 0, io.EOF
+}
+
+func TestProcessConcurrency(t *testing.T) {
+	destination := (&testDestination{}).standardMock()
+
+	defer func(previous int) { concurrency = previous }(concurrency)
+	concurrency = 2
+	var lock sync.Mutex
+	var active, maximum int
+	var dataStreams []*common.DataStream
+	for i := 0; i < 6; i++ {
+		dataStreams = append(dataStreams, &common.DataStream{
+			Reader: &concurrentReader{lock: &lock, active: &active, maximum: &maximum},
+			Hints:  common.DataStreamHints{S3: s3Hint},
+		})
+	}
+
+	err := process(dataStreams, destination, NewProcessor)
+	require.NoError(t, err)
+	assert.Equal(t, 2, maximum)
+
+	assert.Equal(t, 4, parseConcurrency("4"))
+	assert.Equal(t, defaultConcurrency, parseConcurrency(""))
+	assert.Equal(t, defaultConcurrency, parseConcurrency("0"))
+}
+
 func TestProcessDataStreamError(t *testing.T) {
 	logs := mockLogger()
 
@@ -413,3 +471,437 @@ func mockLogger() *observer.ObservedLogs {
 	zap.ReplaceGlobals(zap.New(core))
 	return mockLog
 }
+
+func TestRefreshCustomParsers(t *testing.T) {
+	defer registry.SetCustomParsers(nil)
+	now := time.Now()
+	customSchemasNowFunc = func() time.Time { return now }
+	defer func() {
+		customSchemasNowFunc = time.Now
+		customSchemasExpiry = time.Time{}
+	}()
+	var calls int
+	listCustomSchemasFunc = func() ([]*schemamodels.CustomSchema, error) {
+		calls++
+		return []*schemamodels.CustomSchema{{
+			Name:   aws.String("FirewallAudit"),
+			Layout: aws.String(schemamodels.LayoutCSV),
+			Fields: []*schemamodels.CustomSchemaField{{Name: aws.String("src_ip"), Type: aws.String(schemamodels.FieldTypeString)}},
+		}}, nil
+	}
+
+	require.NoError(t, refreshCustomParsers())
+	assert.Contains(t, registry.AvailableParsers().Elements(), "Custom.FirewallAudit")
+	require.NoError(t, refreshCustomParsers())
+	assert.Equal(t, 1, calls)
+
+	// The schemas are listed again once they expire
+	now = now.Add(customSchemasTTL + time.Second)
+	listCustomSchemasFunc = func() ([]*schemamodels.CustomSchema, error) { return nil, errors.New("invoke failed") }
+	assert.Error(t, refreshCustomParsers())
+}
+
+type mockSQS struct {
+	sqsiface.SQSAPI
+	mock.Mock
+}
+
+func (m *mockSQS) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
+
+func mockThreatIntel(t *testing.T, sets []*threatmodels.ThreatIntelSet, indicators map[string][]string) (reads *int) {
+	now := time.Now()
+	threatIntelNowFunc = func() time.Time { return now }
+	t.Cleanup(func() {
+		threatIntelNowFunc = time.Now
+		threatIntel, threatIntelExpiry = nil, time.Time{}
+	})
+	listThreatIntelSetsFunc = func() ([]*threatmodels.ThreatIntelSet, error) { return sets, nil }
+	reads = new(int)
+	getIndicatorsFunc = func(setID string) ([]string, error) {
+		*reads++
+		return indicators[setID], nil
+	}
+	return reads
+}
+
+func TestLoadThreatIntel(t *testing.T) {
+	updated := time.Now().Add(-time.Hour)
+	set := &threatmodels.ThreatIntelSet{SetID: aws.String("c2"), Enabled: aws.Bool(true), IndicatorsUpdatedAt: &updated}
+	disabled := &threatmodels.ThreatIntelSet{SetID: aws.String("old"), Enabled: aws.Bool(false), IndicatorsUpdatedAt: &updated}
+	reads := mockThreatIntel(t, []*threatmodels.ThreatIntelSet{set, disabled}, map[string][]string{
+		"c2":  {"198.51.100.7", "evil.example.com"},
+		"old": {"203.0.113.9"},
+	})
+
+	index, err := loadThreatIntel()
+	require.NoError(t, err)
+	assert.Equal(t, []*threatmodels.ThreatIntelSet{set}, index.indicators["198.51.100.7"])
+	assert.NotContains(t, index.indicators, "203.0.113.9")
+	assert.Equal(t, 1, *reads)
+
+	// Once the sets expire, only the updated indicators are read again
+	threatIntelExpiry = time.Time{}
+	_, err = loadThreatIntel()
+	require.NoError(t, err)
+	assert.Equal(t, 1, *reads)
+	changed := *set
+	changed.IndicatorsUpdatedAt = aws.Time(updated.Add(time.Minute))
+	listThreatIntelSetsFunc = func() ([]*threatmodels.ThreatIntelSet, error) { return []*threatmodels.ThreatIntelSet{&changed}, nil }
+	threatIntelExpiry = time.Time{}
+	_, err = loadThreatIntel()
+	require.NoError(t, err)
+	assert.Equal(t, 2, *reads)
+
+	listThreatIntelSetsFunc = func() ([]*threatmodels.ThreatIntelSet, error) { return nil, errors.New("invoke failed") }
+	threatIntelExpiry = time.Time{}
+	_, err = loadThreatIntel()
+	assert.Error(t, err)
+}
+
+func TestThreatIntelMatcher(t *testing.T) {
+	updated := time.Now()
+	c2 := &threatmodels.ThreatIntelSet{
+		SetID:               aws.String("c2"),
+		Name:                aws.String("Known C2 servers"),
+		Enabled:             aws.Bool(true),
+		AlertOnMatch:        aws.Bool(true),
+		Severity:            aws.String("HIGH"),
+		IndicatorsUpdatedAt: &updated,
+	}
+	hashes := &threatmodels.ThreatIntelSet{
+		SetID:               aws.String("hashes"),
+		Name:                aws.String("Malware hashes"),
+		Enabled:             aws.Bool(true),
+		AlertOnMatch:        aws.Bool(false),
+		IndicatorsUpdatedAt: &updated,
+	}
+	mockThreatIntel(t, []*threatmodels.ThreatIntelSet{c2, hashes}, map[string][]string{
+		"c2":     {"198.51.100.7", "evil.example.com"},
+		"hashes": {"d41d8cd98f00b204e9800998ecf8427e"},
+	})
+	index, err := loadThreatIntel()
+	require.NoError(t, err)
+	matcher := newThreatIntelMatcher(index)
+
+	event := &parsers.PantherLog{}
+	event.AppendAnyIPAddresses("198.51.100.7", "10.0.0.1")
+	event.AppendAnyDomainNames("EVIL.example.com")
+	event.AppendAnyMD5Hashes("d41d8cd98f00b204e9800998ecf8427e")
+	matcher.enrich(event, "AWS.VPCFlow", nil)
+	assert.Equal(t, []parsers.ThreatIntelMatch{
+		{SetID: "c2", SetName: "Known C2 servers", Indicator: "198.51.100.7", Field: "p_any_ip_addresses"},
+		{SetID: "c2", SetName: "Known C2 servers", Indicator: "EVIL.example.com", Field: "p_any_ip_domain_names"},
+		{SetID: "hashes", SetName: "Malware hashes", Indicator: "d41d8cd98f00b204e9800998ecf8427e", Field: "p_any_md5_hashes"},
+	}, event.PantherThreatIntelMatches)
+
+	unmatched := &parsers.PantherLog{}
+	unmatched.AppendAnyIPAddresses("10.0.0.1")
+	matcher.enrich(unmatched, "AWS.VPCFlow", nil)
+	assert.Nil(t, unmatched.PantherThreatIntelMatches)
+
+	// Only the sets alerting on matches have an alert, with the matched events
+	require.Len(t, matcher.alerts, 1)
+	alert := matcher.alerts["c2"].alert()
+	assert.Equal(t, "c2", *alert.PolicyID)
+	assert.Equal(t, "Threat intel match: Known C2 servers", *alert.PolicyName)
+	assert.Equal(t, "1 events matched 2 indicators of the threat intel set: 198.51.100.7, EVIL.example.com", *alert.PolicyDescription)
+	assert.Equal(t, "HIGH", *alert.Severity)
+	assert.Equal(t, []*string{aws.String("AWS.VPCFlow")}, alert.LogTypes)
+
+	mockClient := &mockSQS{}
+	sqsClient, threatIntelAlertURL = mockClient, "alert-queue"
+	defer func() { threatIntelAlertURL = "" }()
+	mockClient.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Once()
+	matcher.sendAlerts()
+	mockClient.AssertExpectations(t)
+}
+
+func testGeoIPDatabase(t *testing.T, databaseType string, networks []maxmind.Network) []byte {
+	file, err := maxmind.Write(&maxmind.Metadata{DatabaseType: databaseType, IPVersion: 6, RecordSize: 28}, networks)
+	require.NoError(t, err)
+	return file
+}
+
+func TestLoadGeoIP(t *testing.T) {
+	now := time.Now()
+	geoIPNowFunc = func() time.Time { return now }
+	geoIPDatabasePath = "geoip-bucket/geolite2"
+	defer func() {
+		geoIPNowFunc, geoIPDatabasePath = time.Now, ""
+		geoIPDatabases, geoIPExpiry = make(map[string]*geoIPDatabase), time.Time{}
+	}()
+	bucket, prefix := splitGeoIPDatabasePath()
+	assert.Equal(t, "geoip-bucket", bucket)
+	assert.Equal(t, "geolite2/", prefix)
+
+	city := testGeoIPDatabase(t, "GeoLite2-City", []maxmind.Network{{CIDR: "1.2.3.0/24", Record: map[string]interface{}{}}})
+	var reads []string
+	getGeoIPDatabaseFunc = func(name, etag string) ([]byte, string, error) {
+		reads = append(reads, name+":"+etag)
+		switch {
+		case name != geoIPCityDatabase: // the ASN database is not in S3
+			return nil, "", nil
+		case etag == "v1":
+			return nil, etag, nil
+		default:
+			return city, "v1", nil
+		}
+	}
+
+	enricher, err := loadGeoIP()
+	require.NoError(t, err)
+	require.NotNil(t, enricher.city)
+	assert.Nil(t, enricher.asn)
+
+	// The databases are only read again once they expire, unless they were not modified
+	_, err = loadGeoIP()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GeoLite2-City.mmdb:", "GeoLite2-ASN.mmdb:"}, reads)
+	now = now.Add(geoIPTTL)
+	enricher, err = loadGeoIP()
+	require.NoError(t, err)
+	assert.NotNil(t, enricher.city)
+	assert.Equal(t, "GeoLite2-City.mmdb:v1", reads[2])
+
+	getGeoIPDatabaseFunc = func(name, etag string) ([]byte, string, error) { return nil, "", errors.New("access denied") }
+	now = now.Add(geoIPTTL)
+	_, err = loadGeoIP()
+	assert.Error(t, err)
+}
+
+func TestGeoIPEnricher(t *testing.T) {
+	city, err := maxmind.Open(testGeoIPDatabase(t, "GeoLite2-City", []maxmind.Network{{
+		CIDR: "1.2.3.0/24",
+		Record: map[string]interface{}{
+			"city":     map[string]interface{}{"names": map[string]interface{}{"en": "Mountain View", "de": "Mountain View"}},
+			"country":  map[string]interface{}{"iso_code": "US", "names": map[string]interface{}{"en": "United States"}},
+			"location": map[string]interface{}{"latitude": 37.386, "longitude": -122.0838},
+		},
+	}}))
+	require.NoError(t, err)
+	asn, err := maxmind.Open(testGeoIPDatabase(t, "GeoLite2-ASN", []maxmind.Network{
+		{CIDR: "1.2.0.0/16", Record: map[string]interface{}{
+			"autonomous_system_number":       uint64(15169),
+			"autonomous_system_organization": "Google LLC",
+		}},
+		{CIDR: "2001:db8::/32", Record: map[string]interface{}{"autonomous_system_number": uint64(64496)}},
+	}))
+	require.NoError(t, err)
+	enricher := &geoIPEnricher{city: city, asn: asn}
+
+	event := &parsers.PantherLog{}
+	event.AppendAnyIPAddresses("1.2.3.4", "1.2.200.1", "2001:db8::1", "10.0.0.1", "not an ip")
+	enricher.enrich(event, nil)
+	assert.Equal(t, []parsers.IPEnrichment{
+		{
+			IP:             "1.2.200.1",
+			ASN:            aws.Int64(15169),
+			ASOrganization: "Google LLC",
+		},
+		{
+			IP:             "1.2.3.4",
+			CountryCode:    "US",
+			Country:        "United States",
+			City:           "Mountain View",
+			Latitude:       aws.Float64(37.386),
+			Longitude:      aws.Float64(-122.0838),
+			ASN:            aws.Int64(15169),
+			ASOrganization: "Google LLC",
+		},
+		{IP: "2001:db8::1", ASN: aws.Int64(64496)},
+	}, event.PantherIPEnrichment)
+
+	// Without an enricher the events are unchanged
+	unchanged := &parsers.PantherLog{}
+	unchanged.AppendAnyIPAddresses("1.2.3.4")
+	(*geoIPEnricher)(nil).enrich(unchanged, nil)
+	assert.Nil(t, unchanged.PantherIPEnrichment)
+}
+
+func TestBetaEnricher(t *testing.T) {
+	enricher := &geoIPEnricher{}
+	optedIn := &sourcemodels.SourceIntegrationMetadata{
+		FeatureFlags: map[string]bool{sourcemodels.FeatureFlagBetaEnrichment: true},
+	}
+	assert.Equal(t, enricher, betaEnricher(&common.DataStream{Source: optedIn}, enricher))
+
+	// The data of unknown sources and of the integrations which are not opted in is not enriched
+	optedOut := &sourcemodels.SourceIntegrationMetadata{
+		FeatureFlags: map[string]bool{sourcemodels.FeatureFlagBetaEnrichment: false, sourcemodels.FeatureFlagParserV2: true},
+	}
+	assert.Nil(t, betaEnricher(&common.DataStream{Source: optedOut}, enricher))
+	assert.Nil(t, betaEnricher(&common.DataStream{Source: &sourcemodels.SourceIntegrationMetadata{}}, enricher))
+	assert.Nil(t, betaEnricher(&common.DataStream{}, enricher))
+}
+
+func TestLoadRedactionPolicies(t *testing.T) {
+	now := time.Now()
+	redactionNowFunc = func() time.Time { return now }
+	getKey := getRedactionHashKeyFunc
+	t.Cleanup(func() {
+		redactionNowFunc, getRedactionHashKeyFunc = time.Now, getKey
+		redactor, redactionExpiry, redactionHashKey = nil, time.Time{}, nil
+	})
+	keyGets := 0
+	getRedactionHashKeyFunc = func() ([]byte, error) {
+		keyGets++
+		return []byte("test-key"), nil
+	}
+	lists := 0
+	listRedactionPoliciesFunc = func() ([]*redactionmodels.RedactionPolicy, error) {
+		lists++
+		return []*redactionmodels.RedactionPolicy{
+			{
+				LogType: aws.String("AWS.CloudTrail"),
+				Enabled: aws.Bool(true),
+				Fields: []*redactionmodels.RedactedField{
+					{Path: aws.String("userIdentity.arn"), Action: aws.String(redactionmodels.ActionHash)},
+				},
+			},
+			{
+				LogType: aws.String("Okta.SystemLog"),
+				Enabled: aws.Bool(false),
+				Fields:  []*redactionmodels.RedactedField{{Path: aws.String("actor"), Action: aws.String(redactionmodels.ActionDrop)}},
+			},
+		}, nil
+	}
+
+	loaded, err := loadRedactionPolicies()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]*redactedField{
+		"AWS.CloudTrail": {{path: []string{"userIdentity", "arn"}, action: redactionmodels.ActionHash, hashKey: []byte("test-key")}},
+	}, loaded.fields)
+
+	// The policies are listed again once they expire, the hash key is only got once
+	_, err = loadRedactionPolicies()
+	require.NoError(t, err)
+	assert.Equal(t, 1, lists)
+	now = now.Add(redactionPoliciesTTL)
+	_, err = loadRedactionPolicies()
+	require.NoError(t, err)
+	assert.Equal(t, 2, lists)
+	assert.Equal(t, 1, keyGets)
+
+	// The fields can't be hashed without the key
+	now = now.Add(redactionPoliciesTTL)
+	redactionHashKey = nil
+	getRedactionHashKeyFunc = func() ([]byte, error) { return nil, errors.New("secret not found") }
+	_, err = loadRedactionPolicies()
+	assert.Error(t, err)
+
+	now = now.Add(redactionPoliciesTTL)
+	listRedactionPoliciesFunc = func() ([]*redactionmodels.RedactionPolicy, error) { return nil, errors.New("invoke failed") }
+	_, err = loadRedactionPolicies()
+	assert.Error(t, err)
+}
+
+type testRedactedUser struct {
+	Email   string   `json:"email,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Age     int      `json:"age,omitempty"`
+	Devices []string `json:"devices,omitempty"`
+}
+
+type testRedactedEvent struct {
+	Users  []*testRedactedUser `json:"users,omitempty"`
+	Card   string              `json:"card,omitempty"`
+	Amount float64             `json:"amount"`
+	parsers.PantherLog
+}
+
+func TestEventRedactor(t *testing.T) {
+	r := &eventRedactor{fields: map[string][]*redactedField{
+		"Test.Payments": {
+			{path: []string{"users", "email"}, action: redactionmodels.ActionHash, hashKey: []byte("test-key")},
+			{path: []string{"users", "name"}, action: redactionmodels.ActionTruncate, length: 2},
+			{path: []string{"users", "age"}, action: redactionmodels.ActionTruncate, length: 2},
+			{path: []string{"users", "devices"}, action: redactionmodels.ActionDrop},
+			{path: []string{"card"}, action: redactionmodels.ActionTruncate, length: 4},
+			{path: []string{"missing", "field"}, action: redactionmodels.ActionDrop},
+		},
+	}}
+	event := &testRedactedEvent{
+		Users: []*testRedactedUser{
+			{Email: "alice@example.com", Name: "Alice", Age: 34, Devices: []string{"10.0.0.1"}},
+			{Email: "bob@example.com"},
+		},
+		Card:   "4111111111111111",
+		Amount: 12.5,
+	}
+	event.AppendAnyIPAddresses("10.0.0.1", "10.0.0.2")
+	event.AppendAnyDomainNames("alice@example.com")
+
+	redacted, err := r.redact(event, "Test.Payments")
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(redacted.(json.RawMessage), &fields))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"email": "f4ec100211f13d19d596a3b4a8d60f6a5ccf3d3a3c3c9fece41d1ff21e5475dd",
+			"name":  "Al",
+		},
+		map[string]interface{}{"email": "30f050000475abe14008c9063d27e977ea23f2049b708f77176837aa94aa46a5"},
+	}, fields["users"])
+	assert.Equal(t, "4111", fields["card"])
+	assert.Equal(t, 12.5, fields["amount"])
+	// The redacted values are removed from the p_any fields
+	assert.Equal(t, []interface{}{"10.0.0.2"}, fields["p_any_ip_addresses"])
+	assert.NotContains(t, fields, "p_any_ip_domain_names")
+
+	// The events of the other log types are unchanged
+	unchanged, err := r.redact(event, "AWS.CloudTrail")
+	require.NoError(t, err)
+	assert.Equal(t, event, unchanged)
+	unchanged, err = (*eventRedactor)(nil).redact(event, "Test.Payments")
+	require.NoError(t, err)
+	assert.Equal(t, event, unchanged)
+}
+
+func TestSendEventsRedactsBeforeEnriching(t *testing.T) {
+	updated := time.Now()
+	c2 := &threatmodels.ThreatIntelSet{
+		SetID:               aws.String("c2"),
+		Name:                aws.String("Known C2 servers"),
+		Enabled:             aws.Bool(true),
+		AlertOnMatch:        aws.Bool(true),
+		IndicatorsUpdatedAt: &updated,
+	}
+	mockThreatIntel(t, []*threatmodels.ThreatIntelSet{c2}, map[string][]string{"c2": {"198.51.100.7", "1.2.3.4"}})
+	index, err := loadThreatIntel()
+	require.NoError(t, err)
+	city, err := maxmind.Open(testGeoIPDatabase(t, "GeoLite2-City", []maxmind.Network{
+		{CIDR: "198.51.100.0/24", Record: map[string]interface{}{"country": map[string]interface{}{"iso_code": "US"}}},
+		{CIDR: "1.2.3.0/24", Record: map[string]interface{}{"country": map[string]interface{}{"iso_code": "US"}}},
+	}))
+	require.NoError(t, err)
+
+	p := &Processor{
+		input:       &common.DataStream{},
+		operation:   common.OpLogManager.Start(operationName),
+		threatIntel: newThreatIntelMatcher(index),
+		geoIP:       &geoIPEnricher{city: city},
+		redactor: &eventRedactor{fields: map[string][]*redactedField{
+			"Test.Payments": {{path: []string{"users", "devices"}, action: redactionmodels.ActionDrop}},
+		}},
+	}
+	event := &testRedactedEvent{Users: []*testRedactedUser{{Name: "Alice", Devices: []string{"198.51.100.7"}}}}
+	event.AppendAnyIPAddresses("198.51.100.7", "1.2.3.4")
+
+	outputChan := make(chan *common.ParsedEvent, 1)
+	p.sendEvents(&classification.ClassifierResult{Events: []interface{}{event}, LogType: aws.String("Test.Payments")}, outputChan)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal((<-outputChan).Event.(json.RawMessage), &fields))
+
+	// The redacted ip address is neither in the matches, the enrichment nor the alert
+	assert.Equal(t, []interface{}{"1.2.3.4"}, fields["p_any_ip_addresses"])
+	matches := fields["p_threat_intel_matches"].([]interface{})
+	require.Len(t, matches, 1)
+	assert.Equal(t, "1.2.3.4", matches[0].(map[string]interface{})["indicator"])
+	enrichments := fields["p_ip_enrichment"].([]interface{})
+	require.Len(t, enrichments, 1)
+	assert.Equal(t, "1.2.3.4", enrichments[0].(map[string]interface{})["ip"])
+	assert.Equal(t, map[string]struct{}{"1.2.3.4": {}}, p.threatIntel.alerts["c2"].indicators)
+}
@@ -0,0 +1,313 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/redaction/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	redactionAPIFunctionName = "panther-redaction-api"
+
+	// How long the redaction policies are used before they are listed again
+	redactionPoliciesTTL = 5 * time.Minute
+
+	// The prefix of the p_any fields, the values of the redacted fields are removed from them
+	pantherAnyPrefix = "p_any_"
+)
+
+var (
+	listRedactionPoliciesFunc = func() (policies []*models.RedactionPolicy, err error) {
+		err = genericapi.Invoke(lambdaClient, redactionAPIFunctionName, &models.LambdaInput{
+			ListRedactionPolicies: &models.ListRedactionPoliciesInput{},
+		}, &policies)
+		return
+	}
+
+	secretsClient secretsmanageriface.SecretsManagerAPI = secretsmanager.New(common.Session)
+
+	// The hashed values are HMACs keyed by the secret of the deployment: without it, the hashes of the likely
+	// values (ip addresses, emails...) can't be computed to reverse them
+	getRedactionHashKeyFunc = func() ([]byte, error) {
+		secretID := os.Getenv("REDACTION_HASH_KEY_SECRET")
+		if secretID == "" {
+			return nil, errors.New("the redaction hash key secret is not configured")
+		}
+		output, err := secretsClient.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the redaction hash key secret %s", secretID)
+		}
+		return []byte(aws.StringValue(output.SecretString)), nil
+	}
+
+	redactor         *eventRedactor
+	redactionExpiry  time.Time
+	redactionLock    sync.Mutex
+	redactionNowFunc = time.Now
+	redactionHashKey []byte // got once, when a policy first hashes a field
+)
+
+// eventRedactor redacts the fields of the events of the log types with an enabled redaction policy
+type eventRedactor struct {
+	fields map[string][]*redactedField // by log type
+}
+
+type redactedField struct {
+	path    []string
+	action  string
+	length  int
+	hashKey []byte
+}
+
+// loadRedactionPolicies returns the redactor of the enabled redaction policies, listing them again once their
+// last listing expired
+func loadRedactionPolicies() (*eventRedactor, error) {
+	redactionLock.Lock()
+	defer redactionLock.Unlock()
+
+	now := redactionNowFunc()
+	if now.Before(redactionExpiry) {
+		return redactor, nil
+	}
+	policies, err := listRedactionPoliciesFunc()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the redaction policies")
+	}
+
+	loaded := &eventRedactor{fields: make(map[string][]*redactedField, len(policies))}
+	for _, policy := range policies {
+		if !aws.BoolValue(policy.Enabled) {
+			continue
+		}
+		fields := make([]*redactedField, len(policy.Fields))
+		for i, field := range policy.Fields {
+			fields[i] = &redactedField{
+				path:   strings.Split(aws.StringValue(field.Path), "."),
+				action: aws.StringValue(field.Action),
+				length: aws.IntValue(field.Length),
+			}
+			if fields[i].action != models.ActionHash {
+				continue
+			}
+			if redactionHashKey == nil {
+				if redactionHashKey, err = getRedactionHashKeyFunc(); err != nil {
+					return nil, err
+				}
+			}
+			fields[i].hashKey = redactionHashKey
+		}
+		loaded.fields[aws.StringValue(policy.LogType)] = fields
+	}
+	redactor, redactionExpiry = loaded, now.Add(redactionPoliciesTTL)
+	return redactor, nil
+}
+
+// redact returns the event with the fields of its log type redacted, the event itself if the log type has none.
+//
+// The redacted event is its JSON, and the values of its redacted fields are removed from its p_any fields.
+// Hashed and truncated values stay strings, the values of other types are removed, so the columns of the tables
+// keep their types. The redactor of a processor is optional.
+func (r *eventRedactor) redact(event interface{}, logType string) (interface{}, error) {
+	if r == nil || len(r.fields[logType]) == 0 {
+		return event, nil
+	}
+	fields, err := decodeEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
+	redactedValues := make(map[string]struct{})
+	for _, field := range r.fields[logType] {
+		field.redactPath(fields, field.path, redactedValues)
+	}
+	if len(redactedValues) > 0 {
+		removeAnyValues(fields, redactedValues)
+	}
+
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the redacted event")
+	}
+	return json.RawMessage(redacted), nil
+}
+
+// redactedValues returns the string values of the redacted fields of an event, before it is enriched: the redacted
+// values are neither matched against the threat intel nor geolocated. It is nil if the log type has no redacted field.
+func (r *eventRedactor) redactedValues(event interface{}, logType string) (map[string]struct{}, error) {
+	if r == nil || len(r.fields[logType]) == 0 {
+		return nil, nil
+	}
+	fields, err := decodeEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]struct{})
+	for _, field := range r.fields[logType] {
+		collectPath(fields, field.path, values)
+	}
+	return values, nil
+}
+
+// decodeEvent returns the fields of the JSON of an event
+func decodeEvent(event interface{}) (map[string]interface{}, error) {
+	data, err := jsoniter.Marshal(event)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the event")
+	}
+	// The numbers are kept as they are
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the event")
+	}
+	return fields, nil
+}
+
+// collectPath collects the string values of the field at the path of a value, see redactPath
+func collectPath(value interface{}, path []string, values map[string]struct{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		child, ok := value[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) > 1 {
+			collectPath(child, path[1:], values)
+			return
+		}
+		collectStrings(child, values)
+	case []interface{}:
+		for _, element := range value {
+			collectPath(element, path, values)
+		}
+	}
+}
+
+// redactPath redacts the field at the path of a value, in each element of the arrays on the path
+func (f *redactedField) redactPath(value interface{}, path []string, redactedValues map[string]struct{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		child, ok := value[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) > 1 {
+			f.redactPath(child, path[1:], redactedValues)
+			return
+		}
+		if redacted, ok := f.apply(child, redactedValues); ok {
+			value[path[0]] = redacted
+		} else {
+			delete(value, path[0])
+		}
+	case []interface{}:
+		for _, element := range value {
+			f.redactPath(element, path, redactedValues)
+		}
+	}
+}
+
+// apply returns the redacted value, false if it is removed. The original string values are collected.
+func (f *redactedField) apply(value interface{}, redactedValues map[string]struct{}) (interface{}, bool) {
+	collectStrings(value, redactedValues)
+	if f.action == models.ActionDrop {
+		return nil, false
+	}
+	switch value := value.(type) {
+	case string:
+		if f.action == models.ActionHash {
+			mac := hmac.New(sha256.New, f.hashKey)
+			mac.Write([]byte(value))
+			return hex.EncodeToString(mac.Sum(nil)), true
+		}
+		if runes := []rune(value); len(runes) > f.length {
+			return string(runes[:f.length]), true
+		}
+		return value, true
+	case []interface{}:
+		elements := make([]interface{}, 0, len(value))
+		for _, element := range value {
+			if redacted, ok := f.apply(element, redactedValues); ok {
+				elements = append(elements, redacted)
+			}
+		}
+		return elements, true
+	default:
+		// Hashing or truncating would change the type of the value
+		return nil, false
+	}
+}
+
+func collectStrings(value interface{}, values map[string]struct{}) {
+	switch value := value.(type) {
+	case string:
+		values[value] = struct{}{}
+	case []interface{}:
+		for _, element := range value {
+			collectStrings(element, values)
+		}
+	case map[string]interface{}:
+		for _, child := range value {
+			collectStrings(child, values)
+		}
+	}
+}
+
+// removeAnyValues removes the redacted values from the p_any fields of an event, and the fields left empty
+func removeAnyValues(fields map[string]interface{}, redactedValues map[string]struct{}) {
+	for name, value := range fields {
+		values, ok := value.([]interface{})
+		if !ok || !strings.HasPrefix(name, pantherAnyPrefix) {
+			continue
+		}
+		kept := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				if _, redacted := redactedValues[s]; redacted {
+					continue
+				}
+			}
+			kept = append(kept, v)
+		}
+		if len(kept) == 0 {
+			delete(fields, name)
+		} else {
+			fields[name] = kept
+		}
+	}
+}
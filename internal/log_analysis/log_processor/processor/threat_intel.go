@@ -0,0 +1,288 @@
+package processor
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	alertmodels "github.com/panther-labs/panther/internal/core/alert_delivery/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	threatIntelAPIFunctionName = "panther-threat-intel-api"
+
+	// How long the threat intel sets are used before they are listed again
+	threatIntelTTL = 5 * time.Minute
+
+	// The most indicators listed in the description of a threat intel alert
+	maxAlertIndicators = 10
+)
+
+var (
+	s3Client  s3iface.S3API   = s3.New(common.Session)
+	sqsClient sqsiface.SQSAPI = sqs.New(common.Session)
+
+	listThreatIntelSetsFunc = func() (sets []*models.ThreatIntelSet, err error) {
+		err = genericapi.Invoke(lambdaClient, threatIntelAPIFunctionName, &models.LambdaInput{
+			ListThreatIntelSets: &models.ListThreatIntelSetsInput{},
+		}, &sets)
+		return
+	}
+
+	// The indicators of the sets are stored in the processed data bucket, one per line
+	getIndicatorsFunc = func(setID string) ([]string, error) {
+		output, err := s3Client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(os.Getenv("S3_BUCKET")),
+			Key:    aws.String(models.IndicatorsKey(setID)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer output.Body.Close()
+		body, err := ioutil.ReadAll(output.Body)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Split(string(body), "\n"), nil
+	}
+
+	threatIntel         *threatIntelIndex
+	threatIntelExpiry   time.Time
+	threatIntelLock     sync.Mutex
+	threatIntelNowFunc  = time.Now
+	threatIntelAlertURL = os.Getenv("ALERT_QUEUE_URL")
+)
+
+// threatIntelIndex maps the indicators of the enabled threat intel sets to their sets
+type threatIntelIndex struct {
+	sets       map[string]*loadedThreatIntelSet // by set id
+	indicators map[string][]*models.ThreatIntelSet
+}
+
+type loadedThreatIntelSet struct {
+	set        *models.ThreatIntelSet
+	indicators []string
+}
+
+// loadThreatIntel returns the index of the threat intel sets, listing them again once their last listing expired.
+//
+// Only the indicators of the sets updated since they were loaded are read again.
+func loadThreatIntel() (*threatIntelIndex, error) {
+	threatIntelLock.Lock()
+	defer threatIntelLock.Unlock()
+
+	now := threatIntelNowFunc()
+	if now.Before(threatIntelExpiry) {
+		return threatIntel, nil
+	}
+	sets, err := listThreatIntelSetsFunc()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the threat intel sets")
+	}
+
+	index := &threatIntelIndex{
+		sets:       make(map[string]*loadedThreatIntelSet, len(sets)),
+		indicators: make(map[string][]*models.ThreatIntelSet),
+	}
+	for _, set := range sets {
+		if !aws.BoolValue(set.Enabled) || set.IndicatorsUpdatedAt == nil {
+			continue
+		}
+		indicators, ok := threatIntel.unchangedIndicators(set)
+		if !ok {
+			if indicators, err = getIndicatorsFunc(*set.SetID); err != nil {
+				return nil, errors.Wrapf(err, "failed to read the indicators of threat intel set %s", *set.SetID)
+			}
+		}
+		loaded := &loadedThreatIntelSet{set: set, indicators: indicators}
+		index.sets[*set.SetID] = loaded
+		for _, indicator := range loaded.indicators {
+			if indicator != "" {
+				index.indicators[indicator] = append(index.indicators[indicator], set)
+			}
+		}
+	}
+	threatIntel, threatIntelExpiry = index, now.Add(threatIntelTTL)
+	return threatIntel, nil
+}
+
+// unchangedIndicators returns the loaded indicators of a set, if they were not updated since
+func (index *threatIntelIndex) unchangedIndicators(set *models.ThreatIntelSet) ([]string, bool) {
+	if index == nil {
+		return nil, false
+	}
+	loaded, ok := index.sets[*set.SetID]
+	if !ok || !loaded.set.IndicatorsUpdatedAt.Equal(*set.IndicatorsUpdatedAt) {
+		return nil, false
+	}
+	return loaded.indicators, true
+}
+
+// threatIntelMatcher adds the threat intel matches to the events, and collects the alerts of the sets alerting on them
+type threatIntelMatcher struct {
+	index *threatIntelIndex
+
+	lock   sync.Mutex
+	alerts map[string]*threatIntelAlert // by set id
+}
+
+type threatIntelAlert struct {
+	set        *models.ThreatIntelSet
+	events     int
+	indicators map[string]struct{}
+	logTypes   map[string]struct{}
+}
+
+func newThreatIntelMatcher(index *threatIntelIndex) *threatIntelMatcher {
+	return &threatIntelMatcher{index: index, alerts: make(map[string]*threatIntelAlert)}
+}
+
+// enrich sets the p_threat_intel_matches of an event, the matcher of a processor is optional.
+//
+// The redacted values are not matched: they would be stored in the matches, and listed in the alerts.
+func (m *threatIntelMatcher) enrich(event interface{}, logType string, redacted map[string]struct{}) {
+	if m == nil || m.index == nil || len(m.index.indicators) == 0 {
+		return
+	}
+	pantherEvent, ok := event.(parsers.PantherEvent)
+	if !ok {
+		return
+	}
+	pantherLog := pantherEvent.GetPantherLog()
+
+	var matches []parsers.ThreatIntelMatch
+	for _, field := range []struct {
+		name   string
+		values *parsers.PantherAnyString
+	}{
+		{"p_any_ip_addresses", pantherLog.PantherAnyIPAddresses},
+		{"p_any_ip_domain_names", pantherLog.PantherAnyDomainNames},
+		{"p_any_sha1_hashes", pantherLog.PantherAnySHA1Hashes},
+		{"p_any_md5_hashes", pantherLog.PantherAnyMD5Hashes},
+	} {
+		for _, value := range field.values.Values() {
+			if _, ok := redacted[value]; ok {
+				continue
+			}
+			for _, set := range m.index.indicators[models.NormalizeIndicator(value)] {
+				matches = append(matches, parsers.ThreatIntelMatch{
+					SetID:     *set.SetID,
+					SetName:   aws.StringValue(set.Name),
+					Indicator: value,
+					Field:     field.name,
+				})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+	pantherLog.PantherThreatIntelMatches = matches
+	m.collect(matches, logType)
+}
+
+// collect counts the event in the alerts of the matched sets which alert on matches
+func (m *threatIntelMatcher) collect(matches []parsers.ThreatIntelMatch, logType string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	counted := make(map[string]bool)
+	for _, match := range matches {
+		set := m.index.sets[match.SetID].set
+		if !aws.BoolValue(set.AlertOnMatch) {
+			continue
+		}
+		alert, ok := m.alerts[match.SetID]
+		if !ok {
+			alert = &threatIntelAlert{set: set, indicators: make(map[string]struct{}), logTypes: make(map[string]struct{})}
+			m.alerts[match.SetID] = alert
+		}
+		if !counted[match.SetID] {
+			alert.events++
+			counted[match.SetID] = true
+		}
+		alert.indicators[match.Indicator] = struct{}{}
+		alert.logTypes[logType] = struct{}{}
+	}
+}
+
+// sendAlerts queues an alert to alert delivery for each set alerting on the matches of the processed events.
+//
+// The events are already stored, so failures are only logged: failing the invocation would store them again.
+func (m *threatIntelMatcher) sendAlerts() {
+	if threatIntelAlertURL == "" {
+		return
+	}
+	for setID, alert := range m.alerts {
+		body, err := jsoniter.MarshalToString(alert.alert())
+		if err == nil {
+			_, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+				MessageBody: aws.String(body),
+				QueueUrl:    aws.String(threatIntelAlertURL),
+			})
+		}
+		if err != nil {
+			zap.L().Error("failed to send threat intel alert", zap.String("setId", setID), zap.Error(err))
+		}
+	}
+}
+
+func (alert *threatIntelAlert) alert() *alertmodels.Alert {
+	indicators, logTypes := sortedKeys(alert.indicators), sortedKeys(alert.logTypes)
+	description := fmt.Sprintf("%d events matched %d indicators of the threat intel set", alert.events, len(indicators))
+	if len(indicators) > maxAlertIndicators {
+		indicators = append(indicators[:maxAlertIndicators], "...")
+	}
+	return &alertmodels.Alert{
+		CreatedAt:         aws.Time(threatIntelNowFunc().UTC()),
+		PolicyID:          alert.set.SetID,
+		PolicyName:        aws.String("Threat intel match: " + aws.StringValue(alert.set.Name)),
+		PolicyDescription: aws.String(description + ": " + strings.Join(indicators, ", ")),
+		Severity:          alert.set.Severity,
+		LogTypes:          aws.StringSlice(logTypes),
+	}
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
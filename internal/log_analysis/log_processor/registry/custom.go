@@ -0,0 +1,83 @@
+package registry
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/customlogs"
+)
+
+var (
+	// the parsers of the current custom schemas, which classify the log lines
+	customParsers Registry
+	// the parsers of every custom schema seen by this lambda execution, so the events classified before a schema
+	// is deleted can still be written to its table
+	customTables     = Registry{}
+	customParsersMux sync.RWMutex
+)
+
+// SetCustomParsers replaces the parsers of the custom schemas, the invalid schemas are skipped.
+//
+// It is safe to call while logs are processed, the classifiers created afterwards use the new parsers.
+func SetCustomParsers(schemas []*models.CustomSchema) {
+	parsers := make(Registry, len(schemas))
+	for _, schema := range schemas {
+		parser, err := customlogs.NewParser(schema)
+		if err != nil {
+			zap.L().Error("skipping invalid custom schema", zap.String("name", aws.StringValue(schema.Name)), zap.Error(err))
+			continue
+		}
+		parsers[parser.LogType()] = DefaultLogParser(parser, &customlogs.Event{}, aws.StringValue(schema.Description))
+	}
+
+	customParsersMux.Lock()
+	defer customParsersMux.Unlock()
+	customParsers = parsers
+	for logType, lpm := range parsers {
+		customTables[logType] = lpm
+	}
+}
+
+func withCustomParsers(r Registry) Registry {
+	customParsersMux.RLock()
+	defer customParsersMux.RUnlock()
+	if len(customParsers) == 0 {
+		return r
+	}
+	result := make(Registry, len(r)+len(customParsers))
+	for logType, lpm := range customParsers {
+		result[logType] = lpm
+	}
+	for logType, lpm := range r {
+		result[logType] = lpm
+	}
+	return result
+}
+
+func lookupCustomParser(logType string) (*LogParserMetadata, bool) {
+	customParsersMux.RLock()
+	defer customParsersMux.RUnlock()
+	lpm, found := customTables[logType]
+	return lpm, found
+}
@@ -22,7 +22,9 @@ import (
 	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/awslogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/gcplogs"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/nginxlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/oktalogs"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/osquerylogs"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/osseclogs"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/sysloglogs"
@@ -50,8 +52,12 @@ var (
 			&awslogs.AuroraMySQLAudit{}, awslogs.AuroraMySQLAuditDesc),
 		(&awslogs.GuardDutyParser{}).LogType(): DefaultLogParser(&awslogs.GuardDutyParser{},
 			&awslogs.GuardDuty{}, awslogs.GuardDutyDesc),
+		(&gcplogs.AuditLogParser{}).LogType(): DefaultLogParser(&gcplogs.AuditLogParser{},
+			&gcplogs.AuditLog{}, gcplogs.AuditLogDesc),
 		(&nginxlogs.AccessParser{}).LogType(): DefaultLogParser(&nginxlogs.AccessParser{},
 			&nginxlogs.Access{}, nginxlogs.AccessDesc),
+		(&oktalogs.SystemLogParser{}).LogType(): DefaultLogParser(&oktalogs.SystemLogParser{},
+			&oktalogs.SystemLog{}, oktalogs.SystemLogDesc),
 		(&osquerylogs.DifferentialParser{}).LogType(): DefaultLogParser(&osquerylogs.DifferentialParser{},
 			&osquerylogs.Differential{}, osquerylogs.DifferentialDesc),
 		(&osquerylogs.BatchParser{}).LogType(): DefaultLogParser(&osquerylogs.BatchParser{},
@@ -64,6 +70,8 @@ var (
 			&osseclogs.EventInfo{}, osseclogs.EventInfoDesc),
 		(&sysloglogs.RFC3164Parser{}).LogType(): DefaultLogParser(&sysloglogs.RFC3164Parser{},
 			&sysloglogs.RFC3164{}, sysloglogs.RFC3164Desc),
+		(&sysloglogs.RFC5424Parser{}).LogType(): DefaultLogParser(&sysloglogs.RFC5424Parser{},
+			&sysloglogs.RFC5424{}, sysloglogs.RFC5424Desc),
 	}
 )
 
@@ -98,14 +106,17 @@ func AvailableTables() (tables []*awsglue.GlueTableMetadata) {
 	return
 }
 
-// Provides access to underlying type so 'range' will work
+// Provides access to underlying type so 'range' will work, including the parsers of the custom schemas
 func (r Registry) Elements() map[string]*LogParserMetadata {
-	return r
+	return withCustomParsers(r)
 }
 
 // Provides mapping from LogType -> metadata (panics!), used in core code to ensure ALL parsers are registered
 func (r Registry) LookupParser(logType string) (lpm *LogParserMetadata) {
 	lpm, found := r[logType]
+	if !found {
+		lpm, found = lookupCustomParser(logType)
+	}
 	if !found {
 		panic("Cannot find LogType: " + logType) // super serious error, die die die
 	}
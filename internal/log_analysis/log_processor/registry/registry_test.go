@@ -21,9 +21,43 @@ package registry
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
 )
 
 func TestPanic(t *testing.T) {
 	assert.Panics(t, func() { AvailableParsers().LookupParser("doesnotexist") }, "Failed to panic, this is very dangerous!")
 }
+
+func TestSetCustomParsers(t *testing.T) {
+	defer SetCustomParsers(nil)
+	schema := &models.CustomSchema{
+		Name:   aws.String("FirewallAudit"),
+		Layout: aws.String(models.LayoutCSV),
+		Fields: []*models.CustomSchemaField{{Name: aws.String("src_ip"), Type: aws.String(models.FieldTypeString)}},
+	}
+	invalid := &models.CustomSchema{Name: aws.String("Invalid"), Layout: aws.String(models.LayoutRegex)}
+	SetCustomParsers([]*models.CustomSchema{schema, invalid})
+
+	parsers := AvailableParsers().Elements()
+	require.Contains(t, parsers, "Custom.FirewallAudit")
+	assert.NotContains(t, parsers, "Custom.Invalid")
+	assert.Equal(t, len(AvailableParsers())+1, len(parsers))
+	assert.Equal(t, "custom_firewallaudit", AvailableParsers().LookupParser("Custom.FirewallAudit").GlueTableMetadata.TableName())
+
+	// The events of a deleted schema can still be written
+	SetCustomParsers(nil)
+	assert.NotContains(t, AvailableParsers().Elements(), "Custom.FirewallAudit")
+	assert.NotNil(t, AvailableParsers().LookupParser("Custom.FirewallAudit"))
+}
+
+// The threat intel matches are added to the events through their PantherLog
+func TestEventsArePantherEvents(t *testing.T) {
+	for logType, parser := range AvailableParsers() {
+		assert.Implements(t, (*parsers.PantherEvent)(nil), parser.GlueTableMetadata.EventStruct(), logType)
+	}
+}
@@ -0,0 +1,62 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+// The queue S3 notifies directly of the objects of the http-ingest integrations, without SNS
+const httpIngestQueueName = "panther-http-ingest-queue"
+
+// The bucket the events posted to the http-ingest integrations and the batches of the syslog collector are
+// written to. It's in Panther's own account,
+// the log processor reads it with its own role
+var httpIngestBucket = os.Getenv("HTTP_INGEST_BUCKET")
+
+// IsHTTPIngestMessage returns whether a message was read from the queue of the http-ingest notifications.
+func IsHTTPIngestMessage(message *events.SQSMessage) bool {
+	return strings.HasSuffix(message.EventSourceARN, ":"+httpIngestQueueName)
+}
+
+// ReadHTTPIngestMessages reads incoming messages containing S3 notifications of the http-ingest bucket and
+// returns a slice of DataStream items
+func ReadHTTPIngestMessages(messages []events.SQSMessage) (result []*common.DataStream, err error) {
+	zap.L().Debug("reading data for messages", zap.Int("numMessages", len(messages)))
+	for _, message := range messages {
+		s3Objects, err := ParseNotification(message.Body)
+		if err != nil {
+			return nil, err
+		}
+		for _, s3Object := range s3Objects {
+			dataStream, err := readS3Object(s3Object, "", nil)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, dataStream)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsHTTPIngestMessage(t *testing.T) {
+	assert.True(t, IsHTTPIngestMessage(&events.SQSMessage{
+		EventSourceARN: "arn:aws:sqs:us-west-2:123456789012:panther-http-ingest-queue"}))
+	assert.False(t, IsHTTPIngestMessage(&events.SQSMessage{EventSourceARN: testNotificationsQueue}))
+	assert.False(t, IsHTTPIngestMessage(&events.SQSMessage{EventSourceARN: testSourceQueue}))
+}
+
+func TestReadHTTPIngestMessagesTestEvent(t *testing.T) {
+	// S3 sends a test event when the notifications of the bucket are configured
+	dataStreams, err := ReadHTTPIngestMessages([]events.SQSMessage{
+		{Body: `{"Service":"Amazon S3","Event":"s3:TestEvent","Time":"2020-05-01T00:00:00.000Z",` +
+			`"Bucket":"panther-http-ingest","RequestId":"ABCDEF","HostId":"host"}`},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, dataStreams)
+}
+
+func TestReadHTTPIngestMessagesUnknown(t *testing.T) {
+	_, err := ReadHTTPIngestMessages([]events.SQSMessage{{Body: `{"unknown":"message"}`}})
+	assert.Error(t, err)
+}
+
+func TestGetS3ClientHTTPIngestBucket(t *testing.T) {
+	previousBucket := httpIngestBucket
+	httpIngestBucket = "panther-http-ingest"
+	defer func() { httpIngestBucket = previousBucket }()
+
+	// No credentials of another account are needed for the bucket
+	client, err := getS3Client("panther-http-ingest", "")
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+	_, err = getS3Client("customer-bucket", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+// GetKinesisCredentials returns the credentials the stream of an integration is read with: those of the log
+// processing role of its account, or of the last role of its role chain.
+func GetKinesisCredentials(awsAccountID string, roleChain []*string) *credentials.Credentials {
+	if len(roleChain) == 0 {
+		return getAwsCredentials(awsAccountID)
+	}
+	var awsCreds *credentials.Credentials
+	for _, roleArn := range roleChain {
+		provider := common.Session
+		if awsCreds != nil {
+			provider = common.Session.Copy(&aws.Config{Credentials: awsCreds})
+		}
+		awsCreds = stscreds.NewCredentials(provider, *roleArn)
+	}
+	return awsCreds
+}
+
+// ReadKinesisRecords returns a DataStream of the records read from a shard of a Kinesis stream
+//
+// Each record is read as a line, a record can hold several lines.
+func ReadKinesisRecords(streamArn, shardID string, records []*kinesis.Record) *common.DataStream {
+	var buffer bytes.Buffer
+	for _, record := range records {
+		buffer.Write(record.Data)
+		if len(record.Data) > 0 && record.Data[len(record.Data)-1] != '\n' {
+			buffer.WriteByte('\n')
+		}
+	}
+	return &common.DataStream{
+		Reader: &buffer,
+		Hints: common.DataStreamHints{
+			Kinesis: &common.KinesisDataStreamHints{
+				StreamArn:   streamArn,
+				ShardID:     shardID,
+				RecordCount: len(records),
+			},
+		},
+	}
+}
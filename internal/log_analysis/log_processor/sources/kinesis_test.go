@@ -0,0 +1,49 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+const testStreamArn = "arn:aws:kinesis:us-west-2:123456789012:stream/logs"
+
+func TestReadKinesisRecords(t *testing.T) {
+	stream := ReadKinesisRecords(testStreamArn, "shardId-000000000000", []*kinesis.Record{
+		{Data: []byte(`{"user": "alice"}`)},
+		{Data: []byte(`{"user": "bob"}` + "\n")},
+		{Data: []byte{}},
+		// A record can hold several lines
+		{Data: []byte(`{"user": "carol"}` + "\n" + `{"user": "dave"}`)},
+	})
+
+	assert.Equal(t, common.DataStreamHints{
+		Kinesis: &common.KinesisDataStreamHints{StreamArn: testStreamArn, ShardID: "shardId-000000000000", RecordCount: 4},
+	}, stream.Hints)
+	lines, err := ioutil.ReadAll(stream.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user": "alice"}`+"\n"+`{"user": "bob"}`+"\n"+`{"user": "carol"}`+"\n"+`{"user": "dave"}`+"\n", string(lines))
+}
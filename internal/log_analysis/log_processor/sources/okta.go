@@ -0,0 +1,48 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+// ReadOktaEvents returns a DataStream of the events pulled from the System Log of an Okta organization
+//
+// Each event is compacted to a line, the events of a page of the API are indented.
+func ReadOktaEvents(domain string, events []json.RawMessage) (*common.DataStream, error) {
+	var buffer bytes.Buffer
+	for _, event := range events {
+		if err := json.Compact(&buffer, event); err != nil {
+			return nil, err
+		}
+		buffer.WriteByte('\n')
+	}
+	return &common.DataStream{
+		Reader: &buffer,
+		Hints: common.DataStreamHints{
+			Okta: &common.OktaDataStreamHints{
+				Domain:     domain,
+				EventCount: len(events),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,50 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+func TestReadOktaEvents(t *testing.T) {
+	stream, err := ReadOktaEvents("example.okta.com", []json.RawMessage{
+		json.RawMessage(`{"uuid": "1"}`),
+		json.RawMessage("{\n  \"uuid\": \"2\",\n  \"actor\": {\"id\": \"a\"}\n}"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, common.DataStreamHints{
+		Okta: &common.OktaDataStreamHints{Domain: "example.okta.com", EventCount: 2},
+	}, stream.Hints)
+	lines, err := ioutil.ReadAll(stream.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"uuid":"1"}`+"\n"+`{"uuid":"2","actor":{"id":"a"}}`+"\n", string(lines))
+}
+
+func TestReadOktaEventsInvalid(t *testing.T) {
+	_, err := ReadOktaEvents("example.okta.com", []json.RawMessage{json.RawMessage(`{"uuid": `)})
+	assert.Error(t, err)
+}
@@ -29,11 +29,13 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sns"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 )
 
@@ -102,26 +104,35 @@ func handleNotificationMessage(notification *SnsNotification) (result []*common.
 		return nil, err
 	}
 	for _, s3Object := range s3Objects {
+		var included bool
+		var integration *models.SourceIntegrationMetadata
+		integration, included, err = objectIntegration(s3Object.S3Bucket, s3Object.S3ObjectKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list the object filters")
+		}
+		if !included {
+			zap.L().Debug("object is filtered out by its integrations",
+				zap.String("bucket", s3Object.S3Bucket), zap.String("key", s3Object.S3ObjectKey))
+			continue
+		}
 		var dataStream *common.DataStream
-		dataStream, err = readS3Object(s3Object, notification.TopicArn)
+		dataStream, err = readS3Object(s3Object, notification.TopicArn, integration)
 		if err != nil {
 			return
 		}
+		if integration != nil {
+			dataStream.SourceID, dataStream.Source = aws.StringValue(integration.IntegrationID), integration
+		}
 		result = append(result, dataStream)
 	}
 	return result, err
 }
 
-func readS3Object(s3Object *S3ObjectInfo, topicArn string) (dataStream *common.DataStream, err error) {
-	operation := common.OpLogManager.Start("readS3Object", common.OpLogS3ServiceDim)
-	defer func() {
-		operation.Stop()
-		operation.Log(err,
-			// s3 dim info
-			zap.String("bucket", s3Object.S3Bucket),
-			zap.String("key", s3Object.S3ObjectKey))
-	}()
-
+// readS3Object returns the data stream of an S3 object, which is only read once the processor starts on it.
+//
+// The objects waiting for a worker of the processor don't hold a connection open meanwhile. The records of the
+// object are limited to the MaxRecordBytes of its integration, if it has one.
+func readS3Object(s3Object *S3ObjectInfo, topicArn string, integration *models.SourceIntegrationMetadata) (*common.DataStream, error) {
 	s3Client, err := getS3Client(s3Object.S3Bucket, topicArn)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to get S3 client for s3://%s/%s",
@@ -129,16 +140,77 @@ func readS3Object(s3Object *S3ObjectInfo, topicArn string) (dataStream *common.D
 		return nil, err
 	}
 
+	hints := &common.S3DataStreamHints{
+		Bucket: s3Object.S3Bucket,
+		Key:    s3Object.S3ObjectKey,
+	}
+	dataStream := &common.DataStream{
+		Reader: &s3ObjectReader{client: s3Client, hints: hints, integration: integration},
+		Hints: common.DataStreamHints{
+			S3: hints,
+		},
+	}
+	return dataStream, nil
+}
+
+// s3ObjectReader reads an S3 object from its first read, decompressing it if needed.
+//
+// The content type of the object is set in its hints once it's opened.
+type s3ObjectReader struct {
+	client      s3iface.S3API
+	hints       *common.S3DataStreamHints
+	integration *models.SourceIntegrationMetadata
+	body        io.ReadCloser
+	reader      io.Reader
+}
+
+func (r *s3ObjectReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		if err := r.open(); err != nil {
+			r.close()
+			return 0, err
+		}
+	}
+	n, err := r.reader.Read(p)
+	if err != nil {
+		// The object is read once, the connection is released as soon as it ends
+		r.close()
+	}
+	return n, err
+}
+
+func (r *s3ObjectReader) close() {
+	if r.body == nil {
+		return
+	}
+	if err := r.body.Close(); err != nil {
+		zap.L().Warn("failed to close S3 object", zap.String("bucket", r.hints.Bucket),
+			zap.String("key", r.hints.Key), zap.Error(err))
+	}
+	r.body = nil
+}
+
+func (r *s3ObjectReader) open() (err error) {
+	operation := common.OpLogManager.Start("readS3Object", common.OpLogS3ServiceDim)
+	defer func() {
+		operation.Stop()
+		operation.Log(err,
+			// s3 dim info
+			zap.String("bucket", r.hints.Bucket),
+			zap.String("key", r.hints.Key))
+	}()
+
 	getObjectInput := &s3.GetObjectInput{
-		Bucket: &s3Object.S3Bucket,
-		Key:    &s3Object.S3ObjectKey,
+		Bucket: &r.hints.Bucket,
+		Key:    &r.hints.Key,
 	}
-	output, err := s3Client.GetObject(getObjectInput)
+	output, err := r.client.GetObject(getObjectInput)
 	if err != nil {
 		err = errors.Wrapf(err, "GetObject() failed for s3://%s/%s",
-			s3Object.S3Bucket, s3Object.S3ObjectKey)
-		return nil, err
+			r.hints.Bucket, r.hints.Key)
+		return err
 	}
+	r.body = output.Body
 
 	bufferedReader := bufio.NewReader(output.Body)
 
@@ -148,41 +220,31 @@ func readS3Object(s3Object *S3ObjectInfo, topicArn string) (dataStream *common.D
 	if err != nil {
 		if err != bufio.ErrBufferFull && err != io.EOF { // EOF or ErrBufferFull means file is shorter than n
 			err = errors.Wrapf(err, "failed to Peek() in S3 payload for s3://%s/%s",
-				s3Object.S3Bucket, s3Object.S3ObjectKey)
-			return nil, err
+				r.hints.Bucket, r.hints.Key)
+			return err
 		}
 		err = nil // not really an error
 	}
 	contentType := http.DetectContentType(headerBytes)
+	r.hints.ContentType = contentType
 
-	var streamReader io.Reader
+	var streamReader io.Reader = bufferedReader
 
 	// Checking for prefix because the returned type can have also charset used
-	if strings.HasPrefix(contentType, "text/plain") {
-		// if it's plain text, just return the buffered reader
-		streamReader = bufferedReader
-	} else if strings.HasPrefix(contentType, "application/x-gzip") {
+	if strings.HasPrefix(contentType, "application/x-gzip") {
 		var gzipReader *gzip.Reader
 		gzipReader, err = gzip.NewReader(bufferedReader)
 		if err != nil {
 			err = errors.Wrapf(err, "failed to created gzip reader for s3://%s/%s",
-				s3Object.S3Bucket, s3Object.S3ObjectKey)
-			return nil, err
+				r.hints.Bucket, r.hints.Key)
+			return err
 		}
 		streamReader = gzipReader
 	}
 
-	dataStream = &common.DataStream{
-		Reader: streamReader,
-		Hints: common.DataStreamHints{
-			S3: &common.S3DataStreamHints{
-				Bucket:      s3Object.S3Bucket,
-				Key:         s3Object.S3ObjectKey,
-				ContentType: contentType,
-			},
-		},
-	}
-	return dataStream, err
+	// The records of the CloudTrail log files are read one at a time
+	r.reader = newLimitedReader(newRecordsReader(streamReader), r.integration)
+	return nil
 }
 
 // ParseNotification parses a message received
@@ -70,6 +70,11 @@ func init() {
 // getS3Client Fetches S3 client with permissions to read data from the account
 // that owns the SNS Topic
 func getS3Client(s3Bucket string, topicArn string) (*s3.S3, error) {
+	// The http-ingest bucket is notified without a topic, it's read with the role of the function
+	if httpIngestBucket != "" && s3Bucket == httpIngestBucket {
+		return s3.New(common.Session), nil
+	}
+
 	parsedTopicArn, err := arn.Parse(topicArn)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Cannot parse topic arn: %s", topicArn)
@@ -0,0 +1,113 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	sourceAPIFunctionName = "panther-source-api"
+
+	// How long the object filters of the integrations are used before they are listed again
+	objectFiltersTTL = 5 * time.Minute
+)
+
+var (
+	lambdaClient lambdaiface.LambdaAPI = lambda.New(common.Session)
+
+	listS3IntegrationsFunc = func() (integrations []*models.SourceIntegration, err error) {
+		err = genericapi.Invoke(lambdaClient, sourceAPIFunctionName, &models.LambdaInput{
+			ListIntegrations: &models.ListIntegrationsInput{IntegrationType: aws.String(models.IntegrationTypeAWS3)},
+		}, &integrations)
+		return
+	}
+
+	// Bucket name -> the filters of the integrations reading it, a nil filter reads the whole bucket
+	objectFilters        map[string][]*integrationFilter
+	objectFiltersExpiry  time.Time
+	objectFiltersLock    sync.Mutex
+	objectFiltersNowFunc = time.Now
+)
+
+// integrationFilter is the object filter of an integration reading a bucket
+type integrationFilter struct {
+	integration *models.SourceIntegrationMetadata
+	filter      *models.S3ObjectFilter
+}
+
+// objectIncluded returns true if one of the integrations reading the bucket ingests the object.
+//
+// The objects of buckets no integration reads are always ingested, such as those of the http-ingest bucket.
+func objectIncluded(bucket, key string) (bool, error) {
+	_, included, err := objectIntegration(bucket, key)
+	return included, err
+}
+
+// objectIntegration returns the first integration reading the bucket which ingests the object.
+//
+// The integration is nil for the objects of the buckets no integration reads, which are always ingested.
+func objectIntegration(bucket, key string) (integration *models.SourceIntegrationMetadata, included bool, err error) {
+	objectFiltersLock.Lock()
+	defer objectFiltersLock.Unlock()
+
+	if now := objectFiltersNowFunc(); objectFilters == nil || now.After(objectFiltersExpiry) {
+		integrations, err := listS3IntegrationsFunc()
+		if err != nil {
+			return nil, false, err
+		}
+		objectFilters = make(map[string][]*integrationFilter)
+		for _, integration := range integrations {
+			for _, entry := range integration.S3Buckets {
+				name := bucketName(aws.StringValue(entry))
+				objectFilters[name] = append(objectFilters[name], &integrationFilter{
+					integration: integration.SourceIntegrationMetadata,
+					filter:      integration.S3ObjectFilters[name],
+				})
+			}
+		}
+		objectFiltersExpiry = now.Add(objectFiltersTTL)
+	}
+
+	filters, ok := objectFilters[bucket]
+	if !ok {
+		return nil, true, nil
+	}
+	for _, entry := range filters {
+		if entry.filter.Includes(key) {
+			return entry.integration, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// bucketName returns the name of the bucket of a bucket entry, which can be followed by a key prefix.
+func bucketName(entry string) string {
+	return strings.SplitN(entry, "/", 2)[0]
+}
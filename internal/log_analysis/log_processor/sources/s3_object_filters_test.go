@@ -0,0 +1,140 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+// mockObjectFilters lists the given integrations and counts the listings.
+func mockObjectFilters(t *testing.T, integrations ...*models.SourceIntegrationMetadata) *int {
+	previousList, previousNow := listS3IntegrationsFunc, objectFiltersNowFunc
+	listings := 0
+	listS3IntegrationsFunc = func() ([]*models.SourceIntegration, error) {
+		listings++
+		result := make([]*models.SourceIntegration, len(integrations))
+		for i, integration := range integrations {
+			result[i] = &models.SourceIntegration{SourceIntegrationMetadata: integration}
+		}
+		return result, nil
+	}
+	objectFilters = nil
+	t.Cleanup(func() {
+		listS3IntegrationsFunc, objectFiltersNowFunc = previousList, previousNow
+		objectFilters = nil
+	})
+	return &listings
+}
+
+func TestObjectIncluded(t *testing.T) {
+	mockObjectFilters(t,
+		&models.SourceIntegrationMetadata{
+			S3Buckets: aws.StringSlice([]string{"logs", "other/prefix"}),
+			S3ObjectFilters: map[string]*models.S3ObjectFilter{
+				"logs": {Prefixes: aws.StringSlice([]string{"app/", "web/"}), Suffixes: aws.StringSlice([]string{".json.gz"})},
+			},
+		},
+		&models.SourceIntegrationMetadata{
+			S3Buckets:       aws.StringSlice([]string{"logs"}),
+			S3ObjectFilters: map[string]*models.S3ObjectFilter{"logs": {Prefixes: aws.StringSlice([]string{"db/"})}},
+		},
+	)
+
+	for key, expected := range map[string]bool{
+		"app/2020/05/01/events.json.gz": true,
+		"web/access.json.gz":            true,
+		"db/slow.log":                   true,
+		"app/events.log":                false,
+		"cache/events.json.gz":          false,
+	} {
+		included, err := objectIncluded("logs", key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, included, key)
+	}
+
+	// A bucket read without a filter, and a bucket no integration reads
+	included, err := objectIncluded("other", "anything")
+	require.NoError(t, err)
+	assert.True(t, included)
+	included, err = objectIncluded("panther-http-ingest", "anything")
+	require.NoError(t, err)
+	assert.True(t, included)
+}
+
+func TestObjectIntegration(t *testing.T) {
+	mockObjectFilters(t,
+		&models.SourceIntegrationMetadata{
+			IntegrationID:   aws.String("app"),
+			S3Buckets:       aws.StringSlice([]string{"logs"}),
+			S3ObjectFilters: map[string]*models.S3ObjectFilter{"logs": {Prefixes: aws.StringSlice([]string{"app/"})}},
+		},
+		&models.SourceIntegrationMetadata{
+			IntegrationID: aws.String("all"),
+			S3Buckets:     aws.StringSlice([]string{"logs"}),
+		},
+	)
+
+	integration, included, err := objectIntegration("logs", "app/events.json")
+	require.NoError(t, err)
+	assert.True(t, included)
+	assert.Equal(t, "app", *integration.IntegrationID)
+
+	integration, included, err = objectIntegration("logs", "web/events.json")
+	require.NoError(t, err)
+	assert.True(t, included)
+	assert.Equal(t, "all", *integration.IntegrationID)
+
+	integration, included, err = objectIntegration("panther-http-ingest", "anything")
+	require.NoError(t, err)
+	assert.True(t, included)
+	assert.Nil(t, integration)
+}
+
+func TestObjectIncludedCachesFilters(t *testing.T) {
+	listings := mockObjectFilters(t, &models.SourceIntegrationMetadata{S3Buckets: aws.StringSlice([]string{"logs"})})
+	now := time.Now()
+	objectFiltersNowFunc = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := objectIncluded("logs", "key")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, *listings)
+
+	now = now.Add(objectFiltersTTL + time.Second)
+	_, err := objectIncluded("logs", "key")
+	require.NoError(t, err)
+	assert.Equal(t, 2, *listings)
+}
+
+func TestObjectIncludedListFails(t *testing.T) {
+	mockObjectFilters(t)
+	listS3IntegrationsFunc = func() ([]*models.SourceIntegration, error) { return nil, errors.New("unavailable") }
+
+	_, err := objectIncluded("logs", "key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+const (
+	// The start of the JSON objects whose records are read one at a time, the format of the CloudTrail log files
+	recordsField  = "Records"
+	recordsPrefix = `{"Records":[`
+	recordsSuffix = "]}\n"
+
+	// How much of the data is read ahead to find the records and between them
+	recordsBufferSize = 64 * 1024
+)
+
+// newRecordsReader returns a reader of the lines of some data.
+//
+// When the data is a JSON object with a Records array, such as a CloudTrail log file, each record is returned as a
+// line of its own object with a single record. The processor then parses the records as they are read, instead of
+// buffering and parsing the whole file as a single line.
+func newRecordsReader(r io.Reader) io.Reader {
+	buffered := bufio.NewReaderSize(r, recordsBufferSize)
+	// The error is also returned by the next read, a short header is not a Records object
+	header, _ := buffered.Peek(len(recordsPrefix) + 16)
+	if !isRecordsObject(header) {
+		return buffered
+	}
+	source := &recordsSource{reader: buffered}
+	return &recordsReader{
+		source: source,
+		iter:   jsoniter.Parse(jsoniter.ConfigDefault, source, recordsBufferSize),
+	}
+}
+
+// isRecordsObject returns whether the start of some data is the start of a Records object
+func isRecordsObject(header []byte) bool {
+	compact := bytes.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		default:
+			return r
+		}
+	}, header)
+	return bytes.HasPrefix(compact, []byte(recordsPrefix))
+}
+
+// recordsReader returns the records of a Records object as lines
+type recordsReader struct {
+	source  *recordsSource
+	iter    *jsoniter.Iterator
+	started bool
+	done    bool
+	line    []byte // the rest of the line of the current record
+}
+
+func (r *recordsReader) Read(p []byte) (int, error) {
+	for len(r.line) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.line)
+	r.line = r.line[n:]
+	return n, nil
+}
+
+// next reads the next record, done is set after the last one
+func (r *recordsReader) next() error {
+	var more bool
+	if !r.started {
+		r.started = true
+		// The header was checked, so Records is the first field
+		more = r.iter.ReadObject() == recordsField && r.iter.ReadArray()
+	} else {
+		more = r.iter.ReadArray()
+	}
+	if more {
+		record := bytes.TrimSpace(r.iter.SkipAndReturnBytes())
+		if r.iter.Error == nil {
+			r.line = make([]byte, 0, len(recordsPrefix)+len(record)+len(recordsSuffix))
+			r.line = append(append(append(r.line, recordsPrefix...), record...), recordsSuffix...)
+			// The line breaks of an indented record are whitespace between its tokens, the strings can't have any
+			for i, c := range r.line[len(recordsPrefix) : len(r.line)-len(recordsSuffix)] {
+				if c == '\n' || c == '\r' {
+					r.line[len(recordsPrefix)+i] = ' '
+				}
+			}
+			return nil
+		}
+	}
+	r.done = true
+	if r.source.err != nil {
+		// The data couldn't be read, the processing of the stream fails
+		return r.source.err
+	}
+	if r.iter.Error != nil && r.iter.Error != io.EOF {
+		// Like a log line which can't be parsed, the rest of a malformed object is skipped
+		zap.L().Warn("failed to read the records of a log file", zap.Error(r.iter.Error))
+	}
+	return nil
+}
+
+// recordsSource keeps the error of the data read by the records iterator, as it doesn't tell them from the JSON errors
+type recordsSource struct {
+	reader io.Reader
+	err    error
+}
+
+func (s *recordsSource) Read(p []byte) (int, error) {
+	n, err := s.reader.Read(p)
+	if err != nil && err != io.EOF {
+		s.err = err
+	}
+	return n, err
+}
+
+// newLimitedReader returns a reader of the lines of some data which applies the MaxRecordBytes of an integration.
+//
+// Each line is a record, such as one returned by the records reader. The oversized ones are dropped or truncated,
+// or fail the stream, according to the OversizedRecordPolicy of the integration.
+func newLimitedReader(r io.Reader, integration *models.SourceIntegrationMetadata) io.Reader {
+	if integration == nil || integration.MaxRecordBytes == nil {
+		return r
+	}
+	return &limitedReader{reader: bufio.NewReaderSize(r, recordsBufferSize), integration: integration}
+}
+
+// limitedReader returns the lines of some data within the MaxRecordBytes of an integration
+type limitedReader struct {
+	reader      *bufio.Reader
+	integration *models.SourceIntegrationMetadata
+	done        bool
+	line        []byte // the rest of the current line
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	for len(r.line) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.line)
+	r.line = r.line[n:]
+	return n, nil
+}
+
+// next reads the next line which isn't dropped, done is set after the last one
+func (r *limitedReader) next() error {
+	line, err := r.reader.ReadBytes('\n')
+	if err != nil {
+		if err != io.EOF {
+			return err
+		}
+		r.done = true
+		if len(line) == 0 {
+			return nil
+		}
+	}
+	record, newline := line, []byte(nil)
+	if bytes.HasSuffix(record, []byte("\n")) {
+		record, newline = record[:len(record)-1], record[len(record)-1:]
+	}
+	limited, err := r.integration.LimitRecord(record)
+	if err != nil {
+		return errors.Wrapf(err, "integration %s", aws.StringValue(r.integration.IntegrationID))
+	}
+	if limited == nil {
+		zap.L().Debug("dropped an oversized record", zap.String("integrationId", aws.StringValue(r.integration.IntegrationID)),
+			zap.Int("size", len(record)))
+		return nil
+	}
+	r.line = append(limited, newline...)
+	return nil
+}
@@ -0,0 +1,165 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+func TestRecordsReader(t *testing.T) {
+	data := `{"Records": [{"eventName":"GetObject","requestParameters":{"key":"a]b"}},` + "\n" +
+		"  {\n    \"eventName\": \"PutObject\"\n  }\n]}\n"
+	lines, err := ioutil.ReadAll(newRecordsReader(strings.NewReader(data)))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Records":[{"eventName":"GetObject","requestParameters":{"key":"a]b"}}]}`+"\n"+
+		`{"Records":[{     "eventName": "PutObject"   }]}`+"\n", string(lines))
+}
+
+func TestRecordsReaderOtherData(t *testing.T) {
+	for _, data := range []string{"", "line 1\nline 2\n", `{"awsAccountId":"123456789012","Records":[]}`} {
+		lines, err := ioutil.ReadAll(newRecordsReader(strings.NewReader(data)))
+		require.NoError(t, err)
+		assert.Equal(t, data, string(lines))
+	}
+}
+
+func TestRecordsReaderMalformed(t *testing.T) {
+	// The records before the malformed one are read
+	lines, err := ioutil.ReadAll(newRecordsReader(strings.NewReader(`{"Records":[{"a":1},{"b":`)))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Records":[{"a":1}]}`+"\n", string(lines))
+
+	// The errors of the data fail the stream
+	failing := io.MultiReader(strings.NewReader(`{"Records":[{"a":1},{"b":`), &failingReader{})
+	_, err = ioutil.ReadAll(newRecordsReader(failing))
+	assert.EqualError(t, err, "connection reset")
+}
+
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string][]byte
+	gets    int
+	body    *closeRecorder
+}
+
+func (f *fakeS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	f.gets++
+	object, ok := f.objects[*input.Key]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	f.body = &closeRecorder{Reader: bytes.NewReader(object)}
+	return &s3.GetObjectOutput{Body: f.body}, nil
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestS3ObjectReader(t *testing.T) {
+	var object bytes.Buffer
+	writer := gzip.NewWriter(&object)
+	_, err := writer.Write([]byte(`{"Records":[{"a":1},{"b":2}]}`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	client := &fakeS3{objects: map[string][]byte{"AWSLogs/trail.json.gz": object.Bytes()}}
+
+	// The object is only read once the processor reads its stream
+	hints := &common.S3DataStreamHints{Bucket: "bucket", Key: "AWSLogs/trail.json.gz"}
+	reader := &s3ObjectReader{client: client, hints: hints}
+	assert.Equal(t, 0, client.gets)
+	lines, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Records":[{"a":1}]}`+"\n"+`{"Records":[{"b":2}]}`+"\n", string(lines))
+	assert.Equal(t, 1, client.gets)
+	assert.Equal(t, "application/x-gzip", hints.ContentType)
+	assert.True(t, client.body.closed)
+
+	missing := &s3ObjectReader{client: client, hints: &common.S3DataStreamHints{Bucket: "bucket", Key: "missing"}}
+	_, err = ioutil.ReadAll(missing)
+	assert.Error(t, err)
+}
+
+func TestLimitedReader(t *testing.T) {
+	data := "short\na much longer line\nlast"
+	limited := func(policy *string) *models.SourceIntegrationMetadata {
+		return &models.SourceIntegrationMetadata{
+			IntegrationID:         aws.String("integration"),
+			MaxRecordBytes:        aws.Int(8),
+			OversizedRecordPolicy: policy,
+		}
+	}
+
+	// Without a limit the data is read unchanged
+	lines, err := ioutil.ReadAll(newLimitedReader(strings.NewReader(data), &models.SourceIntegrationMetadata{}))
+	require.NoError(t, err)
+	assert.Equal(t, data, string(lines))
+
+	lines, err = ioutil.ReadAll(newLimitedReader(strings.NewReader(data), limited(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, "short\nlast", string(lines))
+
+	lines, err = ioutil.ReadAll(newLimitedReader(strings.NewReader(data), limited(aws.String(models.OversizedRecordTruncate))))
+	require.NoError(t, err)
+	assert.Equal(t, "short\na much l\nlast", string(lines))
+
+	_, err = ioutil.ReadAll(newLimitedReader(strings.NewReader(data), limited(aws.String(models.OversizedRecordError))))
+	assert.EqualError(t, err, "integration integration: record of 18 bytes exceeds the maximum of 8 bytes")
+}
+
+func TestS3ObjectReaderLimitsRecords(t *testing.T) {
+	client := &fakeS3{objects: map[string][]byte{
+		"AWSLogs/trail.json": []byte(`{"Records":[{"a":1},{"eventName":"PutObject"}]}`),
+	}}
+	integration := &models.SourceIntegrationMetadata{MaxRecordBytes: aws.Int(32)}
+
+	// The records of the CloudTrail log file are limited one at a time
+	hints := &common.S3DataStreamHints{Bucket: "bucket", Key: "AWSLogs/trail.json"}
+	reader := &s3ObjectReader{client: client, hints: hints, integration: integration}
+	lines, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Records":[{"a":1}]}`+"\n", string(lines))
+}
@@ -0,0 +1,94 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+// The queue S3 notifications are sent to, any other queue the log processor reads from has log events
+const notificationsQueueName = "panther-input-data-notifications-queue"
+
+// IsNotificationMessage returns whether a message was read from the queue of the S3 notifications.
+func IsNotificationMessage(message *events.SQSMessage) bool {
+	return strings.HasSuffix(message.EventSourceARN, ":"+notificationsQueueName)
+}
+
+// ReadQueueMessages reads incoming messages containing JSON log events and returns a DataStream for each queue
+//
+// A message can hold several events, each of them is read as a line. Messages which aren't JSON are
+// dropped: they would never be read successfully, and failing would retry the whole batch.
+func ReadQueueMessages(messages []*events.SQSMessage) []*common.DataStream {
+	zap.L().Debug("reading log events of messages", zap.Int("numMessages", len(messages)))
+	var result []*common.DataStream
+	streams, buffers := make(map[string]*common.DataStream), make(map[string]*bytes.Buffer)
+	for _, message := range messages {
+		lines, err := compactEvents(message.Body)
+		if err != nil {
+			zap.L().Warn("dropped message which isn't JSON",
+				zap.String("queueArn", message.EventSourceARN),
+				zap.String("messageId", message.MessageId),
+				zap.Error(err))
+			continue
+		}
+		stream, ok := streams[message.EventSourceARN]
+		if !ok {
+			buffers[message.EventSourceARN] = &bytes.Buffer{}
+			stream = &common.DataStream{
+				Reader: buffers[message.EventSourceARN],
+				Hints: common.DataStreamHints{
+					SQS: &common.SQSDataStreamHints{QueueArn: message.EventSourceARN},
+				},
+			}
+			streams[message.EventSourceARN] = stream
+			result = append(result, stream)
+		}
+		buffers[message.EventSourceARN].Write(lines)
+		stream.Hints.SQS.MessageCount++
+	}
+	return result
+}
+
+// compactEvents returns the JSON values of a message body, one per line.
+func compactEvents(body string) ([]byte, error) {
+	var result bytes.Buffer
+	decoder := json.NewDecoder(strings.NewReader(body))
+	for {
+		var event json.RawMessage
+		err := decoder.Decode(&event)
+		if err == io.EOF {
+			return result.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Compact(&result, event); err != nil {
+			return nil, err
+		}
+		result.WriteByte('\n')
+	}
+}
@@ -0,0 +1,69 @@
+package sources
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+const (
+	testNotificationsQueue = "arn:aws:sqs:us-west-2:123456789012:panther-input-data-notifications-queue"
+	testSourceQueue        = "arn:aws:sqs:us-west-2:123456789012:panther-sqs-source-1"
+	testOtherSourceQueue   = "arn:aws:sqs:us-west-2:123456789012:app-logs"
+)
+
+func TestIsNotificationMessage(t *testing.T) {
+	assert.True(t, IsNotificationMessage(&events.SQSMessage{EventSourceARN: testNotificationsQueue}))
+	assert.False(t, IsNotificationMessage(&events.SQSMessage{EventSourceARN: testSourceQueue}))
+	assert.False(t, IsNotificationMessage(&events.SQSMessage{EventSourceARN: testNotificationsQueue + "-dlq"}))
+}
+
+func TestReadQueueMessages(t *testing.T) {
+	streams := ReadQueueMessages([]*events.SQSMessage{
+		{EventSourceARN: testSourceQueue, Body: `{"user": "alice",` + "\n" + `"action": "login"}`},
+		{EventSourceARN: testOtherSourceQueue, Body: `{"app": "web"}`},
+		{EventSourceARN: testSourceQueue, MessageId: "not-json", Body: `user=bob action=login`},
+		// Several events can be sent in a message
+		{EventSourceARN: testSourceQueue, Body: `{"user": "bob"} {"user": "carol"}`},
+	})
+	require.Len(t, streams, 2)
+
+	assert.Equal(t, common.DataStreamHints{
+		SQS: &common.SQSDataStreamHints{QueueArn: testSourceQueue, MessageCount: 2},
+	}, streams[0].Hints)
+	lines, err := ioutil.ReadAll(streams[0].Reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user":"alice","action":"login"}`+"\n"+`{"user":"bob"}`+"\n"+`{"user":"carol"}`+"\n", string(lines))
+
+	assert.Equal(t, testOtherSourceQueue, streams[1].Hints.SQS.QueueArn)
+	lines, err = ioutil.ReadAll(streams[1].Reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"app":"web"}`+"\n", string(lines))
+}
+
+func TestReadQueueMessagesNotJSON(t *testing.T) {
+	assert.Empty(t, ReadQueueMessages([]*events.SQSMessage{{EventSourceARN: testSourceQueue, Body: "not json"}}))
+}
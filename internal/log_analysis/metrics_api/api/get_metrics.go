@@ -0,0 +1,142 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+
+	"github.com/panther-labs/panther/api/lambda/metrics/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+	"github.com/panther-labs/panther/pkg/metrics"
+)
+
+// The most queries of a GetMetricData request
+const maxMetricDataQueries = 500
+
+// GetMetrics returns the time series of the metrics for each value of the dimension grouping them.
+func (API) GetMetrics(input *models.GetMetricsInput) (*models.GetMetricsOutput, error) {
+	if !input.ToDate.After(*input.FromDate) {
+		return nil, &genericapi.InvalidInputError{Message: "toDate must be after fromDate"}
+	}
+
+	// The dimension values are those which had data in the last two weeks
+	var queries []*cloudwatch.MetricDataQuery
+	var series []*models.MetricSeries
+	for _, metricName := range input.MetricNames {
+		found, err := listMetrics(metricName, input.GroupBy)
+		if err != nil {
+			return nil, err
+		}
+		for _, metric := range found {
+			queries = append(queries, &cloudwatch.MetricDataQuery{
+				Id: aws.String(fmt.Sprintf("m%d", len(queries))),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: metric,
+					Period: aws.Int64(*input.IntervalMinutes * 60),
+					Stat:   aws.String(statistic(*metricName)),
+				},
+			})
+			series = append(series, &models.MetricSeries{
+				MetricName: metricName,
+				Dimension:  metric.Dimensions[0].Value,
+				Timestamps: []*time.Time{},
+				Values:     []*float64{},
+			})
+		}
+	}
+
+	for start := 0; start < len(queries); start += maxMetricDataQueries {
+		end := start + maxMetricDataQueries
+		if end > len(queries) {
+			end = len(queries)
+		}
+		err := getMetricData(input, queries[start:end], series[start:end])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &models.GetMetricsOutput{Series: series}, nil
+}
+
+// The latency is averaged over each interval, the counts are summed.
+func statistic(metricName string) string {
+	if metricName == models.MetricProcessingLatency {
+		return cloudwatch.StatisticAverage
+	}
+	return cloudwatch.StatisticSum
+}
+
+// Returns the metrics of the given name which have only the given dimension, sorted by its value.
+func listMetrics(metricName, dimension *string) ([]*cloudwatch.Metric, error) {
+	var result []*cloudwatch.Metric
+	err := cloudWatchClient.ListMetricsPages(&cloudwatch.ListMetricsInput{
+		Dimensions: []*cloudwatch.DimensionFilter{{Name: dimension}},
+		MetricName: metricName,
+		Namespace:  aws.String(metrics.Namespace),
+	}, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+		for _, metric := range page.Metrics {
+			if len(metric.Dimensions) == 1 {
+				result = append(result, metric)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "cloudwatch.ListMetricsPages", Err: err}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return aws.StringValue(result[i].Dimensions[0].Value) < aws.StringValue(result[j].Dimensions[0].Value)
+	})
+	return result, nil
+}
+
+// Adds the data points of the queries to their series, the results of the query "m12" go to the 13th series.
+func getMetricData(input *models.GetMetricsInput, queries []*cloudwatch.MetricDataQuery, series []*models.MetricSeries) error {
+	seriesByID := make(map[string]*models.MetricSeries, len(queries))
+	for i, query := range queries {
+		seriesByID[*query.Id] = series[i]
+	}
+
+	err := cloudWatchClient.GetMetricDataPages(&cloudwatch.GetMetricDataInput{
+		EndTime:           input.ToDate,
+		MetricDataQueries: queries,
+		ScanBy:            aws.String(cloudwatch.ScanByTimestampAscending),
+		StartTime:         input.FromDate,
+	}, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+		for _, result := range page.MetricDataResults {
+			s := seriesByID[aws.StringValue(result.Id)]
+			if s == nil {
+				continue
+			}
+			s.Timestamps = append(s.Timestamps, result.Timestamps...)
+			s.Values = append(s.Values, result.Values...)
+		}
+		return true
+	})
+	if err != nil {
+		return &genericapi.AWSError{Method: "cloudwatch.GetMetricDataPages", Err: err}
+	}
+	return nil
+}
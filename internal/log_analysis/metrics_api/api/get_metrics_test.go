@@ -0,0 +1,198 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/metrics/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockCloudWatch struct {
+	cloudwatchiface.CloudWatchAPI
+	mock.Mock
+}
+
+func (m *mockCloudWatch) ListMetricsPages(
+	input *cloudwatch.ListMetricsInput, fn func(*cloudwatch.ListMetricsOutput, bool) bool) error {
+
+	args := m.Called(input)
+	fn(args.Get(0).(*cloudwatch.ListMetricsOutput), true)
+	return args.Error(1)
+}
+
+func (m *mockCloudWatch) GetMetricDataPages(
+	input *cloudwatch.GetMetricDataInput, fn func(*cloudwatch.GetMetricDataOutput, bool) bool) error {
+
+	args := m.Called(input)
+	for _, page := range args.Get(0).([]*cloudwatch.GetMetricDataOutput) {
+		fn(page, false)
+	}
+	return args.Error(1)
+}
+
+var (
+	fromDate = time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	toDate   = fromDate.Add(2 * time.Hour)
+)
+
+func logTypeMetric(name, logType string) *cloudwatch.Metric {
+	return &cloudwatch.Metric{
+		Dimensions: []*cloudwatch.Dimension{{Name: aws.String(models.DimensionLogType), Value: aws.String(logType)}},
+		MetricName: aws.String(name),
+		Namespace:  aws.String("Panther"),
+	}
+}
+
+func getMetricsInput(metricNames ...string) *models.GetMetricsInput {
+	return &models.GetMetricsInput{
+		MetricNames:     aws.StringSlice(metricNames),
+		GroupBy:         aws.String(models.DimensionLogType),
+		FromDate:        &fromDate,
+		ToDate:          &toDate,
+		IntervalMinutes: aws.Int64(60),
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	mockClient := &mockCloudWatch{}
+	cloudWatchClient = mockClient
+
+	mockClient.On("ListMetricsPages", &cloudwatch.ListMetricsInput{
+		Dimensions: []*cloudwatch.DimensionFilter{{Name: aws.String(models.DimensionLogType)}},
+		MetricName: aws.String(models.MetricEventsProcessed),
+		Namespace:  aws.String("Panther"),
+	}).Return(&cloudwatch.ListMetricsOutput{Metrics: []*cloudwatch.Metric{
+		logTypeMetric(models.MetricEventsProcessed, "AWS.S3"),
+		logTypeMetric(models.MetricEventsProcessed, "AWS.CloudTrail"),
+	}}, nil)
+	mockClient.On("ListMetricsPages", &cloudwatch.ListMetricsInput{
+		Dimensions: []*cloudwatch.DimensionFilter{{Name: aws.String(models.DimensionLogType)}},
+		MetricName: aws.String(models.MetricProcessingLatency),
+		Namespace:  aws.String("Panther"),
+	}).Return(&cloudwatch.ListMetricsOutput{Metrics: []*cloudwatch.Metric{
+		logTypeMetric(models.MetricProcessingLatency, "AWS.S3"),
+	}}, nil)
+
+	expectedQueries := []*cloudwatch.MetricDataQuery{
+		{
+			Id: aws.String("m0"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: logTypeMetric(models.MetricEventsProcessed, "AWS.CloudTrail"),
+				Period: aws.Int64(3600),
+				Stat:   aws.String("Sum"),
+			},
+		},
+		{
+			Id: aws.String("m1"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: logTypeMetric(models.MetricEventsProcessed, "AWS.S3"),
+				Period: aws.Int64(3600),
+				Stat:   aws.String("Sum"),
+			},
+		},
+		{
+			Id: aws.String("m2"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: logTypeMetric(models.MetricProcessingLatency, "AWS.S3"),
+				Period: aws.Int64(3600),
+				Stat:   aws.String("Average"),
+			},
+		},
+	}
+	secondHour := fromDate.Add(time.Hour)
+	mockClient.On("GetMetricDataPages", &cloudwatch.GetMetricDataInput{
+		EndTime:           &toDate,
+		MetricDataQueries: expectedQueries,
+		ScanBy:            aws.String("TimestampAscending"),
+		StartTime:         &fromDate,
+	}).Return([]*cloudwatch.GetMetricDataOutput{
+		{MetricDataResults: []*cloudwatch.MetricDataResult{
+			{Id: aws.String("m0"), Timestamps: []*time.Time{&fromDate}, Values: aws.Float64Slice([]float64{10})},
+			{Id: aws.String("m2"), Timestamps: []*time.Time{&fromDate}, Values: aws.Float64Slice([]float64{1500})},
+		}},
+		{MetricDataResults: []*cloudwatch.MetricDataResult{
+			{Id: aws.String("m0"), Timestamps: []*time.Time{&secondHour}, Values: aws.Float64Slice([]float64{20})},
+		}},
+	}, nil)
+
+	result, err := API{}.GetMetrics(getMetricsInput(models.MetricEventsProcessed, models.MetricProcessingLatency))
+	require.NoError(t, err)
+	assert.Equal(t, &models.GetMetricsOutput{Series: []*models.MetricSeries{
+		{
+			MetricName: aws.String(models.MetricEventsProcessed),
+			Dimension:  aws.String("AWS.CloudTrail"),
+			Timestamps: []*time.Time{&fromDate, &secondHour},
+			Values:     aws.Float64Slice([]float64{10, 20}),
+		},
+		{
+			MetricName: aws.String(models.MetricEventsProcessed),
+			Dimension:  aws.String("AWS.S3"),
+			Timestamps: []*time.Time{},
+			Values:     []*float64{},
+		},
+		{
+			MetricName: aws.String(models.MetricProcessingLatency),
+			Dimension:  aws.String("AWS.S3"),
+			Timestamps: []*time.Time{&fromDate},
+			Values:     aws.Float64Slice([]float64{1500}),
+		},
+	}}, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetMetricsNoData(t *testing.T) {
+	mockClient := &mockCloudWatch{}
+	cloudWatchClient = mockClient
+	mockClient.On("ListMetricsPages", mock.Anything).Return(&cloudwatch.ListMetricsOutput{}, nil)
+
+	result, err := API{}.GetMetrics(getMetricsInput(models.MetricAlertsCreated))
+	require.NoError(t, err)
+	assert.Empty(t, result.Series)
+	mockClient.AssertExpectations(t) // no GetMetricData without queries
+}
+
+func TestGetMetricsInvalidRange(t *testing.T) {
+	input := getMetricsInput(models.MetricEventsProcessed)
+	input.ToDate = input.FromDate
+
+	result, err := API{}.GetMetrics(input)
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestGetMetricsListError(t *testing.T) {
+	mockClient := &mockCloudWatch{}
+	cloudWatchClient = mockClient
+	mockClient.On("ListMetricsPages", mock.Anything).Return(&cloudwatch.ListMetricsOutput{}, errors.New("throttled"))
+
+	result, err := API{}.GetMetrics(getMetricsInput(models.MetricEventsProcessed))
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.AWSError{}, err)
+}
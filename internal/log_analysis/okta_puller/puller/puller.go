@@ -0,0 +1,251 @@
+package puller
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/sources"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	sourceAPIFunctionName = "panther-source-api"
+
+	// The most events the System Log API returns in a page
+	pageSize = 1000
+)
+
+var (
+	lambdaClient  lambdaiface.LambdaAPI                 = lambda.New(common.Session)
+	secretsClient secretsmanageriface.SecretsManagerAPI = secretsmanager.New(common.Session)
+
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+
+	// The base URL of the API of an Okta organization, tests point it at a local server
+	oktaURL = func(domain string) string { return "https://" + domain }
+
+	// The link to the next page of the System Log, the cursor is its after parameter
+	nextLinkRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+	listIntegrationsFunc = func() (integrations []*models.SourceIntegration, err error) {
+		err = genericapi.Invoke(lambdaClient, sourceAPIFunctionName, &models.LambdaInput{
+			ListIntegrations: &models.ListIntegrationsInput{IntegrationType: aws.String(models.IntegrationTypeOkta)},
+		}, &integrations)
+		return
+	}
+
+	processFunc = func(dataStreams []*common.DataStream) error {
+		return processor.Process(dataStreams, destinations.CreateDestination())
+	}
+
+	saveCursorFunc = func(input *models.UpdateOktaCursorInput) error {
+		return genericapi.Invoke(lambdaClient, sourceAPIFunctionName, &models.LambdaInput{UpdateOktaCursor: input}, nil)
+	}
+)
+
+// The events pulled from the System Log and the cursor the next pull resumes from
+type pulledEvents struct {
+	events []json.RawMessage
+	cursor *string
+}
+
+// PullLogs pulls the new events of the System Log of the Okta integrations until the deadline and processes them.
+//
+// The integrations are pulled concurrently. The cursor of an integration is saved once its events are processed, a
+// failed pull or processing is pulled again by the next invocation.
+func PullLogs(deadline time.Time) error {
+	integrations, err := listIntegrationsFunc()
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	var failedLock sync.Mutex
+	var wg sync.WaitGroup
+	for _, integration := range integrations {
+		// The credentials are stored when the integration is added
+		if integration.OktaDomain == nil || integration.CredentialsSecretArn == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(integration *models.SourceIntegrationMetadata) {
+			defer wg.Done()
+			if err := pullIntegration(integration, deadline); err != nil {
+				zap.L().Error("failed to pull the system log of the integration",
+					zap.String("integrationId", *integration.IntegrationID),
+					zap.String("oktaDomain", *integration.OktaDomain),
+					zap.Error(err))
+				failedLock.Lock()
+				failed++
+				failedLock.Unlock()
+			}
+		}(integration.SourceIntegrationMetadata)
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		return errors.Errorf("failed to pull the system logs of %d integrations", failed)
+	}
+	return nil
+}
+
+// pullIntegration pulls the System Log of an integration, processes the events and saves the cursor.
+//
+// The events pulled before a page fails are still processed.
+func pullIntegration(integration *models.SourceIntegrationMetadata, deadline time.Time) error {
+	token, err := getAPIToken(integration.CredentialsSecretArn)
+	if err != nil {
+		return err
+	}
+
+	result, pullErr := pullEvents(*integration.OktaDomain, token, integration.OktaLogCursor, integration.CreatedAtTime, deadline)
+	if len(result.events) > 0 {
+		dataStream, err := sources.ReadOktaEvents(*integration.OktaDomain, result.events)
+		if err != nil {
+			return errors.Wrap(err, "invalid system log event")
+		}
+		if err = processFunc([]*common.DataStream{dataStream}); err != nil {
+			return err
+		}
+	}
+	if result.cursor != nil && aws.StringValue(result.cursor) != aws.StringValue(integration.OktaLogCursor) {
+		err = saveCursorFunc(&models.UpdateOktaCursorInput{
+			IntegrationID: integration.IntegrationID,
+			Cursor:        result.cursor,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return pullErr
+}
+
+// getAPIToken reads the API token from the credentials of an integration.
+func getAPIToken(secretArn *string) (string, error) {
+	secret, err := secretsClient.GetSecretValue(&secretsmanager.GetSecretValueInput{SecretId: secretArn})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read the credentials")
+	}
+	var credentials map[string]string
+	if err = json.Unmarshal([]byte(aws.StringValue(secret.SecretString)), &credentials); err != nil {
+		return "", errors.Wrap(err, "invalid credentials")
+	}
+	if credentials["apiToken"] == "" {
+		return "", errors.New("the credentials have no apiToken")
+	}
+	return credentials["apiToken"], nil
+}
+
+// pullEvents pages through the System Log after the cursor until the deadline, or until it's caught up with the
+// latest event. The first pull of an integration starts with the events published since it was added.
+//
+// The pull stops early once the rate limit of the organization is used up, the next invocation resumes from the cursor.
+func pullEvents(domain, token string, cursor *string, since *time.Time, deadline time.Time) (*pulledEvents, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(pageSize))
+	query.Set("sortOrder", "ASCENDING")
+	if cursor != nil {
+		query.Set("after", *cursor)
+	} else if since != nil {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+
+	result := &pulledEvents{cursor: cursor}
+	for time.Now().Before(deadline) {
+		request, err := http.NewRequest(http.MethodGet, oktaURL(domain)+"/api/v1/logs?"+query.Encode(), nil)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to build the system log request")
+		}
+		request.Header.Set("Accept", "application/json")
+		request.Header.Set("Authorization", "SSWS "+token)
+		response, err := httpClient.Do(request)
+		if err != nil {
+			return result, errors.Wrap(err, "failed to read the system log")
+		}
+
+		var page []json.RawMessage
+		if response.StatusCode == http.StatusOK {
+			err = json.NewDecoder(response.Body).Decode(&page)
+		}
+		response.Body.Close()
+		if response.StatusCode == http.StatusTooManyRequests {
+			zap.L().Warn("system log pull rate limited",
+				zap.String("oktaDomain", domain),
+				zap.String("rateLimitReset", response.Header.Get("X-Rate-Limit-Reset")))
+			return result, nil
+		}
+		if response.StatusCode != http.StatusOK {
+			return result, errors.Errorf("reading the system log responded with status %d", response.StatusCode)
+		}
+		if err != nil {
+			return result, errors.Wrap(err, "failed to decode the system log")
+		}
+		if len(page) == 0 {
+			return result, nil
+		}
+		result.events = append(result.events, page...)
+
+		next := nextCursor(response.Header)
+		if next == nil {
+			return result, nil
+		}
+		result.cursor = next
+		query.Del("since")
+		query.Set("after", *next)
+		if response.Header.Get("X-Rate-Limit-Remaining") == "0" {
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// nextCursor returns the after parameter of the link to the next page.
+func nextCursor(header http.Header) *string {
+	for _, link := range header["Link"] {
+		match := nextLinkRegex.FindStringSubmatch(link)
+		if match == nil {
+			continue
+		}
+		next, err := url.Parse(match[1])
+		if err != nil || next.Query().Get("after") == "" {
+			return nil
+		}
+		return aws.String(next.Query().Get("after"))
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package puller
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+)
+
+const (
+	testIntegrationID = "45c378a7-2e36-4b12-8e16-2d3c49ff1371"
+	testOktaDomain    = "example.okta.com"
+	testSecretArn     = "arn:aws:secretsmanager:us-west-2:123456789012:secret:panther-source-credentials-" + testIntegrationID
+)
+
+type mockSecretsClient struct {
+	secretsmanageriface.SecretsManagerAPI
+}
+
+func (*mockSecretsClient) GetSecretValue(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return &secretsmanager.GetSecretValueOutput{ARN: input.SecretId, SecretString: aws.String(`{"apiToken": "token"}`)}, nil
+}
+
+// A page of the System Log, by the after parameter it's requested with
+type page struct {
+	status int
+	header http.Header
+	body   string
+}
+
+// mockPuller serves the pages of the System Log and lists the integrations, the processed streams and saved cursors
+// are recorded along with the after parameters of the requests.
+func mockPuller(t *testing.T, integrations []*models.SourceIntegration, pages map[string]*page) (
+	processed *[]*common.DataStream, saved *[]*models.UpdateOktaCursorInput, requested *[]string) {
+
+	requested = &[]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/logs", r.URL.Path)
+		assert.Equal(t, "SSWS token", r.Header.Get("Authorization"))
+		assert.Equal(t, "ASCENDING", r.URL.Query().Get("sortOrder"))
+		after := r.URL.Query().Get("after")
+		*requested = append(*requested, after+r.URL.Query().Get("since"))
+		response, ok := pages[after]
+		require.True(t, ok, after)
+		for key, values := range response.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(response.status)
+		_, _ = w.Write([]byte(response.body))
+	}))
+
+	previousURL, previousClient := oktaURL, secretsClient
+	oktaURL = func(domain string) string {
+		assert.Equal(t, testOktaDomain, domain)
+		return server.URL
+	}
+	secretsClient = &mockSecretsClient{}
+	t.Cleanup(func() {
+		server.Close()
+		oktaURL, secretsClient = previousURL, previousClient
+	})
+
+	listIntegrationsFunc = func() ([]*models.SourceIntegration, error) { return integrations, nil }
+	processed, saved = &[]*common.DataStream{}, &[]*models.UpdateOktaCursorInput{}
+	processFunc = func(dataStreams []*common.DataStream) error {
+		*processed = append(*processed, dataStreams...)
+		return nil
+	}
+	saveCursorFunc = func(input *models.UpdateOktaCursorInput) error {
+		*saved = append(*saved, input)
+		return nil
+	}
+	return processed, saved, requested
+}
+
+func nextLink(after string) http.Header {
+	return http.Header{"Link": []string{
+		`<https://example.okta.com/api/v1/logs?limit=1000>; rel="self"`,
+		`<https://example.okta.com/api/v1/logs?limit=1000&after=` + after + `>; rel="next"`,
+	}}
+}
+
+func oktaIntegration() *models.SourceIntegration {
+	return &models.SourceIntegration{SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+		IntegrationID:        aws.String(testIntegrationID),
+		IntegrationType:      aws.String(models.IntegrationTypeOkta),
+		OktaDomain:           aws.String(testOktaDomain),
+		OktaLogCursor:        aws.String("1"),
+		CredentialsSecretArn: aws.String(testSecretArn),
+	}}
+}
+
+func TestPullLogs(t *testing.T) {
+	processed, saved, requested := mockPuller(t, []*models.SourceIntegration{oktaIntegration()}, map[string]*page{
+		"1": {status: http.StatusOK, header: nextLink("2"), body: `[{"uuid": "a"}, {"uuid": "b"}]`},
+		"2": {status: http.StatusOK, header: nextLink("3"), body: `[{"uuid": "c"}]`},
+		// Caught up with the latest event
+		"3": {status: http.StatusOK, header: nextLink("3"), body: `[]`},
+	})
+
+	require.NoError(t, PullLogs(time.Now().Add(time.Minute)))
+	assert.Equal(t, []string{"1", "2", "3"}, *requested)
+	require.Len(t, *processed, 1)
+	assert.Equal(t, &common.OktaDataStreamHints{Domain: testOktaDomain, EventCount: 3}, (*processed)[0].Hints.Okta)
+	lines, err := ioutil.ReadAll((*processed)[0].Reader)
+	require.NoError(t, err)
+	assert.Equal(t, `{"uuid":"a"}`+"\n"+`{"uuid":"b"}`+"\n"+`{"uuid":"c"}`+"\n", string(lines))
+	assert.Equal(t, []*models.UpdateOktaCursorInput{
+		{IntegrationID: aws.String(testIntegrationID), Cursor: aws.String("3")},
+	}, *saved)
+}
+
+// The first pull of an integration starts with the events published since it was added
+func TestPullLogsFirstPull(t *testing.T) {
+	integration := oktaIntegration()
+	createdAt := time.Date(2020, 3, 23, 7, 30, 0, 0, time.UTC)
+	integration.OktaLogCursor, integration.CreatedAtTime = nil, &createdAt
+	processed, saved, requested := mockPuller(t, []*models.SourceIntegration{integration}, map[string]*page{
+		"":  {status: http.StatusOK, header: nextLink("2"), body: `[{"uuid": "a"}]`},
+		"2": {status: http.StatusOK, header: nextLink("2"), body: `[]`},
+	})
+
+	require.NoError(t, PullLogs(time.Now().Add(time.Minute)))
+	assert.Equal(t, []string{"2020-03-23T07:30:00Z", "2"}, *requested)
+	assert.Len(t, *processed, 1)
+	require.Len(t, *saved, 1)
+	assert.Equal(t, "2", *(*saved)[0].Cursor)
+}
+
+// Nothing is saved when there are no new events
+func TestPullLogsNoNewEvents(t *testing.T) {
+	processed, saved, _ := mockPuller(t, []*models.SourceIntegration{oktaIntegration()}, map[string]*page{
+		"1": {status: http.StatusOK, header: nextLink("1"), body: `[]`},
+	})
+
+	require.NoError(t, PullLogs(time.Now().Add(time.Minute)))
+	assert.Empty(t, *processed)
+	assert.Empty(t, *saved)
+}
+
+// A rate limited pull keeps the events pulled so far and the next invocation resumes after them
+func TestPullLogsRateLimited(t *testing.T) {
+	processed, saved, requested := mockPuller(t, []*models.SourceIntegration{oktaIntegration()}, map[string]*page{
+		"1": {status: http.StatusOK, header: nextLink("2"), body: `[{"uuid": "a"}]`},
+		"2": {status: http.StatusTooManyRequests, header: http.Header{"X-Rate-Limit-Reset": []string{"1584948660"}}},
+	})
+
+	require.NoError(t, PullLogs(time.Now().Add(time.Minute)))
+	assert.Equal(t, []string{"1", "2"}, *requested)
+	assert.Len(t, *processed, 1)
+	require.Len(t, *saved, 1)
+	assert.Equal(t, "2", *(*saved)[0].Cursor)
+}
+
+// The pull stops once the rate limit is used up, before the organization is rate limited
+func TestPullLogsRateLimitUsedUp(t *testing.T) {
+	header := nextLink("2")
+	header.Set("X-Rate-Limit-Remaining", "0")
+	_, saved, requested := mockPuller(t, []*models.SourceIntegration{oktaIntegration()}, map[string]*page{
+		"1": {status: http.StatusOK, header: header, body: `[{"uuid": "a"}]`},
+	})
+
+	require.NoError(t, PullLogs(time.Now().Add(time.Minute)))
+	assert.Equal(t, []string{"1"}, *requested)
+	require.Len(t, *saved, 1)
+	assert.Equal(t, "2", *(*saved)[0].Cursor)
+}
+
+// The events pulled before a failed page are processed, the pull fails
+func TestPullLogsPageFails(t *testing.T) {
+	processed, saved, _ := mockPuller(t, []*models.SourceIntegration{oktaIntegration()}, map[string]*page{
+		"1": {status: http.StatusOK, header: nextLink("2"), body: `[{"uuid": "a"}]`},
+		"2": {status: http.StatusUnauthorized, body: `{"errorCode": "E0000011"}`},
+	})
+
+	assert.EqualError(t, PullLogs(time.Now().Add(time.Minute)), "failed to pull the system logs of 1 integrations")
+	assert.Len(t, *processed, 1)
+	require.Len(t, *saved, 1)
+	assert.Equal(t, "2", *(*saved)[0].Cursor)
+}
+
+// The cursor isn't saved when the events fail to be processed, they are pulled again
+func TestPullLogsProcessingFails(t *testing.T) {
+	_, saved, _ := mockPuller(t, []*models.SourceIntegration{oktaIntegration()}, map[string]*page{
+		"1": {status: http.StatusOK, header: nextLink("2"), body: `[{"uuid": "a"}]`},
+		"2": {status: http.StatusOK, header: nextLink("2"), body: `[]`},
+	})
+	processFunc = func([]*common.DataStream) error { return errors.New("failed") }
+
+	assert.Error(t, PullLogs(time.Now().Add(time.Minute)))
+	assert.Empty(t, *saved)
+}
+
+// Integrations which have no credentials yet are skipped
+func TestPullLogsSkipsIntegrationsWithoutCredentials(t *testing.T) {
+	integration := oktaIntegration()
+	integration.CredentialsSecretArn = nil
+	_, saved, requested := mockPuller(t, []*models.SourceIntegration{integration}, map[string]*page{})
+
+	require.NoError(t, PullLogs(time.Now().Add(time.Minute)))
+	assert.Empty(t, *requested)
+	assert.Empty(t, *saved)
+}
@@ -0,0 +1,227 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+
+	logprocessormodels "github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/api/lambda/partitions/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The most partitions a BatchGetPartition request can get
+	maxGetPartitions = 1000
+
+	// How many times the partitions which Glue didn't process are requested again
+	maxGetPartitionsAttempts = 3
+)
+
+// SyncPartitions creates the missing partitions of a log type and repairs those with the wrong location.
+func (API) SyncPartitions(input *models.SyncPartitionsInput) (*models.SyncPartitionsOutput, error) {
+	start, end := input.Start.UTC(), input.End.UTC()
+	if !end.After(start) {
+		return nil, &genericapi.InvalidInputError{Message: "the end of the time range must be after its start"}
+	}
+	if end.Sub(start) > models.MaxSyncHours*time.Hour {
+		return nil, &genericapi.InvalidInputError{
+			Message: fmt.Sprintf("the time range can't be longer than %d hours", models.MaxSyncHours)}
+	}
+
+	output := &models.SyncPartitionsOutput{Tables: make([]*models.TableSync, 0, 2)}
+	for _, dataType := range []logprocessormodels.DataType{logprocessormodels.LogData, logprocessormodels.RuleData} {
+		result, err := syncTable(dataType, *input.LogType, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			output.Tables = append(output.Tables, result)
+		}
+	}
+	if len(output.Tables) == 0 {
+		return nil, &genericapi.DoesNotExistError{Message: "log type " + *input.LogType + " has no Glue tables"}
+	}
+	return output, nil
+}
+
+// syncTable syncs the partitions of the table of a log type for a data type, nil is returned if there is no table.
+func syncTable(dataType logprocessormodels.DataType, logType string, start, end time.Time) (*models.TableSync, error) {
+	metadata := awsglue.NewGlueTableMetadata(dataType, logType, "", awsglue.GlueTableHourly, nil)
+	tableOutput, err := glueClient.GetTable(&glue.GetTableInput{
+		DatabaseName: aws.String(metadata.DatabaseName()),
+		Name:         aws.String(metadata.TableName()),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == glue.ErrCodeEntityNotFoundException {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "glue.GetTable"}
+	}
+	// One partition key for each time resolution after the year: monthly, daily then hourly
+	timebin := awsglue.GlueTableTimebin(len(tableOutput.Table.PartitionKeys) - 1)
+	if err = timebin.Validate(); err != nil {
+		return nil, &genericapi.InternalError{Message: "table " + metadata.TableName() + " isn't partitioned by time"}
+	}
+
+	result := &models.TableSync{
+		DatabaseName: aws.String(metadata.DatabaseName()),
+		TableName:    aws.String(metadata.TableName()),
+		Created:      aws.Int(0),
+		Updated:      aws.Int(0),
+		Unchanged:    aws.Int(0),
+		Empty:        aws.Int(0),
+	}
+
+	// The partitions are those of the time ranges with data, in the format of their first object
+	var partitions []*awsglue.GluePartition
+	for t := timebinStart(timebin, start); t.Before(end); t = timebin.Next(t) {
+		prefix := awsglue.GetPartitionPrefix(dataType, logType, timebin, t)
+		listOutput, err := s3Client.ListObjectsV2(&s3.ListObjectsV2Input{
+			Bucket:  aws.String(env.ProcessedDataBucket),
+			Prefix:  aws.String(prefix),
+			MaxKeys: aws.Int64(1),
+		})
+		if err != nil {
+			return nil, &genericapi.AWSError{Err: err, Method: "s3.ListObjectsV2"}
+		}
+		if len(listOutput.Contents) == 0 {
+			*result.Empty++
+			continue
+		}
+		partition, err := awsglue.GetPartitionFromS3(env.ProcessedDataBucket, aws.StringValue(listOutput.Contents[0].Key))
+		if err != nil {
+			result.Errors = append(result.Errors, prefix+": "+err.Error())
+			continue
+		}
+		partitions = append(partitions, partition)
+	}
+
+	existing, err := getPartitions(metadata.DatabaseName(), metadata.TableName(), partitions)
+	if err != nil {
+		return nil, err
+	}
+	for _, partition := range partitions {
+		current, ok := existing[partitionKey(partitionValues(partition))]
+		switch {
+		case !ok:
+			if err := partition.CreatePartition(glueClient); err != nil {
+				result.Errors = append(result.Errors, partition.GetPartitionLocation()+": "+err.Error())
+				continue
+			}
+			*result.Created++
+		case currentFormat(current) != partition.GetDataFormat():
+			// The objects of the partition would be unreadable in the other format
+			result.Errors = append(result.Errors, fmt.Sprintf(
+				"%s: the partition is %s but its objects are %s: migrate it with the parquetmigrate tool",
+				partition.GetPartitionLocation(), currentFormat(current), partition.GetDataFormat()))
+		case aws.StringValue(current.StorageDescriptor.Location) != partition.GetPartitionLocation():
+			if err := partition.UpdatePartition(glueClient); err != nil {
+				result.Errors = append(result.Errors, partition.GetPartitionLocation()+": "+err.Error())
+				continue
+			}
+			*result.Updated++
+		default:
+			*result.Unchanged++
+		}
+	}
+
+	zap.L().Info("synced partitions",
+		zap.String("database", *result.DatabaseName), zap.String("table", *result.TableName),
+		zap.Int("created", *result.Created), zap.Int("updated", *result.Updated),
+		zap.Int("unchanged", *result.Unchanged), zap.Int("empty", *result.Empty), zap.Int("errors", len(result.Errors)))
+	return result, nil
+}
+
+// getPartitions returns the existing partitions among the given ones, by their partition key.
+func getPartitions(database, tableName string, partitions []*awsglue.GluePartition) (map[string]*glue.Partition, error) {
+	result := make(map[string]*glue.Partition, len(partitions))
+	for start := 0; start < len(partitions); start += maxGetPartitions {
+		end := start + maxGetPartitions
+		if end > len(partitions) {
+			end = len(partitions)
+		}
+		toGet := make([]*glue.PartitionValueList, 0, end-start)
+		for _, partition := range partitions[start:end] {
+			toGet = append(toGet, &glue.PartitionValueList{Values: partitionValues(partition)})
+		}
+
+		for attempt := 0; len(toGet) > 0; attempt++ {
+			if attempt == maxGetPartitionsAttempts {
+				return nil, &genericapi.UnavailableError{Message: "Glue didn't return all the partitions of " + tableName}
+			}
+			output, err := glueClient.BatchGetPartition(&glue.BatchGetPartitionInput{
+				DatabaseName:    aws.String(database),
+				TableName:       aws.String(tableName),
+				PartitionsToGet: toGet,
+			})
+			if err != nil {
+				return nil, &genericapi.AWSError{Err: err, Method: "glue.BatchGetPartition"}
+			}
+			for _, partition := range output.Partitions {
+				result[partitionKey(partition.Values)] = partition
+			}
+			toGet = output.UnprocessedKeys
+		}
+	}
+	return result, nil
+}
+
+// timebinStart returns the start of the time range of the timebin which includes the time.
+func timebinStart(timebin awsglue.GlueTableTimebin, t time.Time) time.Time {
+	switch timebin {
+	case awsglue.GlueTableHourly:
+		return t.Truncate(time.Hour)
+	case awsglue.GlueTableDaily:
+		return t.Truncate(24 * time.Hour)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// currentFormat returns the format of an existing partition, "parquet" or "json".
+func currentFormat(partition *glue.Partition) string {
+	serde := aws.StringValue(partition.StorageDescriptor.SerdeInfo.SerializationLibrary)
+	if strings.Contains(strings.ToLower(serde), "parquet") {
+		return "parquet"
+	}
+	return "json"
+}
+
+func partitionValues(partition *awsglue.GluePartition) []*string {
+	columns := partition.GetPartitionColumnsInfo()
+	values := make([]*string, len(columns))
+	for i, column := range columns {
+		values[i] = aws.String(column.Value)
+	}
+	return values
+}
+
+func partitionKey(values []*string) string {
+	return strings.Join(aws.StringValueSlice(values), "/")
+}
@@ -0,0 +1,253 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/partitions/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testLocation   = "s3://processed-data/logs/aws_cloudtrail/year=2020/month=05/day=14/"
+	jsonSerde      = "org.openx.data.jsonserde.JsonSerDe"
+	parquetSerde   = "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"
+	testObjectName = "20200514T213742Z-5a8bd4ea-2fbe-4d74-bbb6-9a0758a6f0b2"
+)
+
+var (
+	testStart = time.Date(2020, 5, 14, 20, 30, 0, 0, time.UTC)
+	testEnd   = time.Date(2020, 5, 14, 23, 0, 0, 0, time.UTC)
+	testTime  = time.Date(2020, 5, 14, 21, 37, 42, 0, time.UTC)
+)
+
+type mockGlue struct {
+	glueiface.GlueAPI
+	mock.Mock
+}
+
+func (m *mockGlue) GetTable(input *glue.GetTableInput) (*glue.GetTableOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.GetTableOutput), args.Error(1)
+}
+
+func (m *mockGlue) BatchGetPartition(input *glue.BatchGetPartitionInput) (*glue.BatchGetPartitionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.BatchGetPartitionOutput), args.Error(1)
+}
+
+func (m *mockGlue) CreatePartition(input *glue.CreatePartitionInput) (*glue.CreatePartitionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.CreatePartitionOutput), args.Error(1)
+}
+
+func (m *mockGlue) UpdatePartition(input *glue.UpdatePartitionInput) (*glue.UpdatePartitionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.UpdatePartitionOutput), args.Error(1)
+}
+
+type mockS3 struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (m *mockS3) ListObjectsV2(input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
+}
+
+func setupMocks() (*mockGlue, *mockS3) {
+	mockGlueClient, mockS3Client := &mockGlue{}, &mockS3{}
+	glueClient, s3Client = mockGlueClient, mockS3Client
+	env.ProcessedDataBucket = "processed-data"
+	return mockGlueClient, mockS3Client
+}
+
+// mockTables mocks an hourly table of events and no table of rule matches.
+func mockTables(mockGlueClient *mockGlue) {
+	mockGlueClient.On("GetTable", &glue.GetTableInput{
+		DatabaseName: aws.String("panther_logs"),
+		Name:         aws.String("aws_cloudtrail"),
+	}).Return(&glue.GetTableOutput{Table: &glue.TableData{
+		PartitionKeys: []*glue.Column{
+			{Name: aws.String("year")}, {Name: aws.String("month")}, {Name: aws.String("day")}, {Name: aws.String("hour")},
+		},
+	}}, nil).Once()
+	mockGlueClient.On("GetTable", &glue.GetTableInput{
+		DatabaseName: aws.String("panther_rule_matches"),
+		Name:         aws.String("aws_cloudtrail"),
+	}).Return(&glue.GetTableOutput{}, awserr.New(glue.ErrCodeEntityNotFoundException, "no table", nil)).Once()
+}
+
+// mockObjects mocks the first object of each hour, an empty key is an hour without data.
+func mockObjects(mockS3Client *mockS3, keys map[string]string) {
+	for prefix, key := range keys {
+		output := &s3.ListObjectsV2Output{}
+		if key != "" {
+			output.Contents = []*s3.Object{{Key: aws.String(key)}}
+		}
+		mockS3Client.On("ListObjectsV2", &s3.ListObjectsV2Input{
+			Bucket:  aws.String("processed-data"),
+			Prefix:  aws.String(prefix),
+			MaxKeys: aws.Int64(1),
+		}).Return(output, nil).Once()
+	}
+}
+
+func existingPartition(hour, location, serde string) *glue.Partition {
+	return &glue.Partition{
+		Values: aws.StringSlice([]string{"2020", "05", "14", hour}),
+		StorageDescriptor: &glue.StorageDescriptor{
+			Location:  aws.String(location),
+			SerdeInfo: &glue.SerDeInfo{SerializationLibrary: aws.String(serde)},
+		},
+	}
+}
+
+func TestSyncPartitions(t *testing.T) {
+	mockGlueClient, mockS3Client := setupMocks()
+	mockTables(mockGlueClient)
+	mockObjects(mockS3Client, map[string]string{
+		"logs/aws_cloudtrail/year=2020/month=05/day=14/hour=20/": "",
+		"logs/aws_cloudtrail/year=2020/month=05/day=14/hour=21/": "logs/aws_cloudtrail/year=2020/month=05/day=14/hour=21/" +
+			testObjectName + ".json.gz",
+		"logs/aws_cloudtrail/year=2020/month=05/day=14/hour=22/": "logs/aws_cloudtrail/year=2020/month=05/day=14/hour=22/" +
+			testObjectName + ".json.gz",
+	})
+	mockGlueClient.On("BatchGetPartition", &glue.BatchGetPartitionInput{
+		DatabaseName: aws.String("panther_logs"),
+		TableName:    aws.String("aws_cloudtrail"),
+		PartitionsToGet: []*glue.PartitionValueList{
+			{Values: aws.StringSlice([]string{"2020", "05", "14", "21"})},
+			{Values: aws.StringSlice([]string{"2020", "05", "14", "22"})},
+		},
+	}).Return(&glue.BatchGetPartitionOutput{Partitions: []*glue.Partition{
+		existingPartition("22", "s3://old-processed-data/logs/aws_cloudtrail/year=2020/month=05/day=14/hour=22/", jsonSerde),
+	}}, nil).Once()
+	mockGlueClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, nil).Once()
+	mockGlueClient.On("UpdatePartition", mock.Anything).Return(&glue.UpdatePartitionOutput{}, nil).Once()
+
+	result, err := (API{}).SyncPartitions(&models.SyncPartitionsInput{
+		LogType: aws.String("AWS.CloudTrail"),
+		Start:   &testStart,
+		End:     &testEnd,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.SyncPartitionsOutput{Tables: []*models.TableSync{{
+		DatabaseName: aws.String("panther_logs"),
+		TableName:    aws.String("aws_cloudtrail"),
+		Created:      aws.Int(1),
+		Updated:      aws.Int(1),
+		Unchanged:    aws.Int(0),
+		Empty:        aws.Int(1),
+	}}}, result)
+	mockGlueClient.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+
+	created := mockGlueClient.Calls[2].Arguments.Get(0).(*glue.CreatePartitionInput)
+	assert.Equal(t, testLocation+"hour=21/", *created.PartitionInput.StorageDescriptor.Location)
+	updated := mockGlueClient.Calls[3].Arguments.Get(0).(*glue.UpdatePartitionInput)
+	assert.Equal(t, testLocation+"hour=22/", *updated.PartitionInput.StorageDescriptor.Location)
+}
+
+func TestSyncPartitionsWrongFormat(t *testing.T) {
+	mockGlueClient, mockS3Client := setupMocks()
+	mockTables(mockGlueClient)
+	mockObjects(mockS3Client, map[string]string{
+		"logs/aws_cloudtrail/year=2020/month=05/day=14/hour=20/": "logs/aws_cloudtrail/year=2020/month=05/day=14/hour=20/" +
+			testObjectName + ".json.gz",
+		"logs/aws_cloudtrail/year=2020/month=05/day=14/hour=21/": "logs/aws_cloudtrail/year=2020/month=05/day=14/hour=21/" +
+			testObjectName + ".parquet",
+		"logs/aws_cloudtrail/year=2020/month=05/day=14/hour=22/": "",
+	})
+	mockGlueClient.On("BatchGetPartition", mock.Anything).Return(&glue.BatchGetPartitionOutput{
+		Partitions: []*glue.Partition{
+			existingPartition("20", testLocation+"hour=20/", jsonSerde),
+		},
+		UnprocessedKeys: []*glue.PartitionValueList{{Values: aws.StringSlice([]string{"2020", "05", "14", "21"})}},
+	}, nil).Once()
+	mockGlueClient.On("BatchGetPartition", &glue.BatchGetPartitionInput{
+		DatabaseName: aws.String("panther_logs"),
+		TableName:    aws.String("aws_cloudtrail"),
+		PartitionsToGet: []*glue.PartitionValueList{
+			{Values: aws.StringSlice([]string{"2020", "05", "14", "21"})},
+		},
+	}).Return(&glue.BatchGetPartitionOutput{Partitions: []*glue.Partition{
+		existingPartition("21", testLocation+"hour=21/", jsonSerde),
+	}}, nil).Once()
+
+	result, err := (API{}).SyncPartitions(&models.SyncPartitionsInput{
+		LogType: aws.String("AWS.CloudTrail"),
+		Start:   &testStart,
+		End:     &testEnd,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Tables, 1)
+	assert.Equal(t, 1, *result.Tables[0].Unchanged)
+	assert.Equal(t, 1, *result.Tables[0].Empty)
+	assert.Equal(t, []string{testLocation + "hour=21/: the partition is json but its objects are parquet: " +
+		"migrate it with the parquetmigrate tool"}, result.Tables[0].Errors)
+	mockGlueClient.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+}
+
+func TestSyncPartitionsNoTables(t *testing.T) {
+	mockGlueClient, _ := setupMocks()
+	mockGlueClient.On("GetTable", mock.Anything).Return(&glue.GetTableOutput{},
+		awserr.New(glue.ErrCodeEntityNotFoundException, "no table", nil)).Twice()
+
+	result, err := (API{}).SyncPartitions(&models.SyncPartitionsInput{
+		LogType: aws.String("AWS.CloudTrail"),
+		Start:   &testStart,
+		End:     &testEnd,
+	})
+	assert.Nil(t, result)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockGlueClient.AssertExpectations(t)
+}
+
+func TestSyncPartitionsInvalidRange(t *testing.T) {
+	setupMocks()
+	end := testStart.Add(-time.Hour)
+	_, err := (API{}).SyncPartitions(&models.SyncPartitionsInput{LogType: aws.String("AWS.CloudTrail"), Start: &testStart, End: &end})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	end = testStart.Add((models.MaxSyncHours + 1) * time.Hour)
+	_, err = (API{}).SyncPartitions(&models.SyncPartitionsInput{LogType: aws.String("AWS.CloudTrail"), Start: &testStart, End: &end})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestTimebinStart(t *testing.T) {
+	assert.Equal(t, time.Date(2020, 5, 14, 21, 0, 0, 0, time.UTC), timebinStart(awsglue.GlueTableHourly, testTime))
+	assert.Equal(t, time.Date(2020, 5, 14, 0, 0, 0, 0, time.UTC), timebinStart(awsglue.GlueTableDaily, testTime))
+	assert.Equal(t, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), timebinStart(awsglue.GlueTableMonthly, testTime))
+}
@@ -0,0 +1,67 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/panther-labs/panther/internal/log_analysis/query_api/table"
+)
+
+// API has all of the handlers as receiver methods.
+type API struct{}
+
+var (
+	env             envConfig
+	awsSession      *session.Session
+	executionsTable table.ExecutionsAPI
+	searchesTable   table.SearchesAPI
+	athenaClient    athenaiface.AthenaAPI
+
+	nowFunc = time.Now
+)
+
+type envConfig struct {
+	QueryExecutionsTableName string `required:"true" split_words:"true"`
+	SavedSearchesTableName   string `required:"true" split_words:"true"`
+	AthenaResultsBucket      string `required:"true" split_words:"true"`
+	// The workgroup of the queries, which cancels those scanning more than MaxScannedBytes
+	AthenaWorkgroup string `required:"true" split_words:"true"`
+
+	// The quotas of each user: the queries running at the same time, and the bytes scanned in 24 hours
+	MaxConcurrentQueries int   `default:"5" split_words:"true"`
+	MaxScannedBytes      int64 `default:"1099511627776" split_words:"true"`
+}
+
+// Setup parses the environment and builds the AWS clients.
+func Setup() {
+	envconfig.MustProcess("", &env)
+
+	awsSession = session.Must(session.NewSession())
+	dynamoClient := dynamodb.New(awsSession)
+	executionsTable = &table.ExecutionsTable{Name: env.QueryExecutionsTableName, Client: dynamoClient}
+	searchesTable = &table.SearchesTable{Name: env.SavedSearchesTableName, Client: dynamoClient}
+	athenaClient = athena.New(awsSession)
+}
@@ -0,0 +1,346 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/query/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The window of the scanned bytes quota
+	quotaPeriod = 24 * time.Hour
+	// The most IDs of a BatchGetQueryExecution request
+	maxBatchGetExecutions = 50
+	defaultPageSize       = 100
+	// The most rows of a GetQueryResults request
+	maxPageSize = 1000
+	// A query slot reserved for a query which didn't start is free again after this, longer than the Lambda timeout
+	slotReservationTTL = time.Minute
+)
+
+// ExecuteQuery starts the query or the saved search of a user, quotas permitting.
+func (API) ExecuteQuery(input *models.ExecuteQueryInput) (*models.ExecuteQueryOutput, error) {
+	if (input.SQL == nil) == (input.SearchID == nil) {
+		return nil, &genericapi.InvalidInputError{Message: "either the sql or a searchId is required"}
+	}
+	sql, database := input.SQL, input.Database
+	if input.SearchID != nil {
+		search, err := searchesTable.GetSearch(input.UserID, input.SearchID)
+		if err != nil {
+			return nil, err
+		}
+		if search == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "saved search " + *input.SearchID + " does not exist"}
+		}
+		expanded, err := expandSearch(search, input.Parameters)
+		if err != nil {
+			return nil, &genericapi.InvalidInputError{Message: err.Error()}
+		}
+		sql = &expanded
+		if database == nil {
+			database = search.Database
+		}
+	} else if len(input.Parameters) > 0 {
+		return nil, &genericapi.InvalidInputError{Message: "parameters are only given to saved searches"}
+	}
+	if database == nil {
+		database = aws.String(awsglue.LogProcessingDatabaseName)
+	}
+
+	now := nowFunc().UTC()
+	slot, err := checkQuotas(input.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The workgroup cancels the queries which scan more than the bytes quota of a user
+	output, err := athenaClient.StartQueryExecution(&athena.StartQueryExecutionInput{
+		QueryExecutionContext: &athena.QueryExecutionContext{Database: database},
+		QueryString:           sql,
+		ResultConfiguration: &athena.ResultConfiguration{
+			OutputLocation: aws.String("s3://" + env.AthenaResultsBucket + "/queries/"),
+		},
+		WorkGroup: aws.String(env.AthenaWorkgroup),
+	})
+	if err != nil {
+		if releaseErr := executionsTable.ReleaseSlot(input.UserID, slot, nil); releaseErr != nil {
+			zap.L().Warn("failed to release the query slot, it expires", zap.String("userId", *input.UserID),
+				zap.Int("slot", slot), zap.Error(releaseErr))
+		}
+	}
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == athena.ErrCodeInvalidRequestException {
+		return nil, &genericapi.InvalidInputError{Message: aerr.Message()}
+	}
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Athena.StartQueryExecution"}
+	}
+
+	execution := &models.QueryExecution{
+		QueryExecutionID: output.QueryExecutionId,
+		UserID:           input.UserID,
+		SQL:              sql,
+		Database:         database,
+		SearchID:         input.SearchID,
+		Status:           aws.String(models.StatusQueued),
+		SubmittedAt:      &now,
+	}
+	if err = executionsTable.PutExecution(execution); err != nil {
+		return nil, err
+	}
+	// The execution is stored before it holds the slot: the slots whose execution isn't stored are released
+	if err = executionsTable.AssignSlot(input.UserID, slot, execution.QueryExecutionID); err != nil {
+		zap.L().Warn("failed to assign the query slot, it expires", zap.String("userId", *input.UserID),
+			zap.Int("slot", slot), zap.Error(err))
+	}
+	zap.L().Info("started query", zap.String("userId", *input.UserID),
+		zap.String("queryExecutionId", *execution.QueryExecutionID))
+	return execution, nil
+}
+
+// checkQuotas reserves a query slot of the user for a new query, an UnavailableError is returned if the user runs
+// the most queries at the same time, or has scanned their quota of bytes in the last 24 hours.
+//
+// The slots held by the executions which finished are released first.
+func checkQuotas(userID *string, now time.Time) (int, error) {
+	// The slots are listed before the executions, which are stored before they hold a slot
+	slots, err := executionsTable.ListSlots(userID)
+	if err != nil {
+		return -1, err
+	}
+	executions, err := executionsTable.ListExecutions(userID)
+	if err != nil {
+		return -1, err
+	}
+	if err = refreshExecutions(executions); err != nil {
+		return -1, err
+	}
+
+	running, scannedBytes := make(map[string]bool), int64(0)
+	for _, execution := range executions {
+		running[*execution.QueryExecutionID] = !finished(execution)
+		if execution.SubmittedAt.After(now.Add(-quotaPeriod)) {
+			scannedBytes += aws.Int64Value(execution.ScannedBytes)
+		}
+	}
+	if scannedBytes >= env.MaxScannedBytes {
+		return -1, &genericapi.UnavailableError{Message: fmt.Sprintf(
+			"%d bytes were scanned in the last 24 hours, the quota is %d bytes", scannedBytes, env.MaxScannedBytes)}
+	}
+
+	held := 0
+	for _, slot := range slots {
+		// The slots reserved for the queries which are starting expire
+		if slot.QueryExecutionID == nil || running[*slot.QueryExecutionID] {
+			held++
+			continue
+		}
+		if err = executionsTable.ReleaseSlot(userID, slot.Slot, slot.QueryExecutionID); err != nil {
+			return -1, err
+		}
+	}
+	slot, err := executionsTable.ReserveSlot(userID, env.MaxConcurrentQueries, now, now.Add(slotReservationTTL))
+	if err != nil {
+		return -1, err
+	}
+	if slot < 0 {
+		return -1, &genericapi.UnavailableError{Message: fmt.Sprintf(
+			"%d queries are already running, the most a user can run at the same time", held)}
+	}
+	return slot, nil
+}
+
+// GetQueryStatus returns a query execution of the user with its current status.
+func (API) GetQueryStatus(input *models.GetQueryStatusInput) (*models.GetQueryStatusOutput, error) {
+	return getExecution(input.UserID, input.QueryExecutionID)
+}
+
+// GetQueryResults returns a page of the results of a succeeded query execution of the user.
+func (API) GetQueryResults(input *models.GetQueryResultsInput) (*models.GetQueryResultsOutput, error) {
+	execution, err := getExecution(input.UserID, input.QueryExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	switch *execution.Status {
+	case models.StatusSucceeded:
+	case models.StatusQueued, models.StatusRunning:
+		return nil, &genericapi.UnavailableError{Message: "query execution " + *input.QueryExecutionID + " is " + *execution.Status}
+	default:
+		return nil, &genericapi.InvalidInputError{
+			Message: "query execution " + *input.QueryExecutionID + " is " + *execution.Status + ": " + aws.StringValue(execution.Error)}
+	}
+
+	// The first page starts with a header row
+	pageSize := aws.Int64Value(input.PageSize)
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if input.PaginationToken == nil && pageSize < maxPageSize {
+		pageSize++
+	}
+	output, err := athenaClient.GetQueryResults(&athena.GetQueryResultsInput{
+		MaxResults:       aws.Int64(pageSize),
+		NextToken:        input.PaginationToken,
+		QueryExecutionId: input.QueryExecutionID,
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == athena.ErrCodeInvalidRequestException {
+		return nil, &genericapi.InvalidInputError{Message: aerr.Message()}
+	}
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Athena.GetQueryResults"}
+	}
+
+	result := &models.GetQueryResultsOutput{
+		Columns:         make([]*models.Column, 0),
+		Rows:            make([][]*string, 0, len(output.ResultSet.Rows)),
+		PaginationToken: output.NextToken,
+	}
+	for _, column := range output.ResultSet.ResultSetMetadata.ColumnInfo {
+		result.Columns = append(result.Columns, &models.Column{Name: column.Name, Type: column.Type})
+	}
+	for i, row := range output.ResultSet.Rows {
+		if i == 0 && input.PaginationToken == nil && isHeader(row, result.Columns) {
+			continue
+		}
+		values := make([]*string, len(row.Data))
+		for j, datum := range row.Data {
+			values[j] = datum.VarCharValue
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, nil
+}
+
+// The results of the statements other than SELECT have no header row
+func isHeader(row *athena.Row, columns []*models.Column) bool {
+	if len(row.Data) != len(columns) {
+		return false
+	}
+	for i, datum := range row.Data {
+		if aws.StringValue(datum.VarCharValue) != aws.StringValue(columns[i].Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// StopQuery cancels a query execution of the user, a finished one is left as it is.
+func (API) StopQuery(input *models.StopQueryInput) (*models.StopQueryOutput, error) {
+	execution, err := getExecution(input.UserID, input.QueryExecutionID)
+	if err != nil || finished(execution) {
+		return execution, err
+	}
+	if _, err = athenaClient.StopQueryExecution(&athena.StopQueryExecutionInput{
+		QueryExecutionId: input.QueryExecutionID,
+	}); err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Athena.StopQueryExecution"}
+	}
+	if err = refreshExecutions([]*models.QueryExecution{execution}); err != nil {
+		return nil, err
+	}
+	zap.L().Info("stopped query", zap.String("userId", *input.UserID),
+		zap.String("queryExecutionId", *input.QueryExecutionID))
+	return execution, nil
+}
+
+// getExecution returns a query execution of the user with its current status.
+func getExecution(userID, queryExecutionID *string) (*models.QueryExecution, error) {
+	execution, err := executionsTable.GetExecution(userID, queryExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	if execution == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "query execution " + *queryExecutionID + " does not exist"}
+	}
+	if err = refreshExecutions([]*models.QueryExecution{execution}); err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+func finished(execution *models.QueryExecution) bool {
+	switch *execution.Status {
+	case models.StatusQueued, models.StatusRunning:
+		return false
+	default:
+		return true
+	}
+}
+
+// refreshExecutions updates the unfinished executions with their status in Athena, and stores the changed ones.
+func refreshExecutions(executions []*models.QueryExecution) error {
+	unfinished := make(map[string]*models.QueryExecution)
+	var ids []*string
+	for _, execution := range executions {
+		if !finished(execution) {
+			unfinished[*execution.QueryExecutionID] = execution
+			ids = append(ids, execution.QueryExecutionID)
+		}
+	}
+
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > maxBatchGetExecutions {
+			batch = batch[:maxBatchGetExecutions]
+		}
+		ids = ids[len(batch):]
+
+		output, err := athenaClient.BatchGetQueryExecution(&athena.BatchGetQueryExecutionInput{QueryExecutionIds: batch})
+		if err != nil {
+			return &genericapi.AWSError{Err: err, Method: "Athena.BatchGetQueryExecution"}
+		}
+		for _, athenaExecution := range output.QueryExecutions {
+			execution := unfinished[aws.StringValue(athenaExecution.QueryExecutionId)]
+			if execution == nil || !updateExecution(execution, athenaExecution) {
+				continue
+			}
+			if err = executionsTable.PutExecution(execution); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateExecution copies the status and statistics of the Athena execution, true is returned if they changed.
+func updateExecution(execution *models.QueryExecution, athenaExecution *athena.QueryExecution) bool {
+	status, statistics := athenaExecution.Status, athenaExecution.Statistics
+	if status == nil {
+		return false
+	}
+	changed := aws.StringValue(status.State) != *execution.Status
+	execution.Status = status.State
+	execution.Error = status.StateChangeReason
+	execution.CompletedAt = status.CompletionDateTime
+	if statistics != nil {
+		changed = changed || aws.Int64Value(statistics.DataScannedInBytes) != aws.Int64Value(execution.ScannedBytes)
+		execution.ScannedBytes = statistics.DataScannedInBytes
+		execution.ExecutionTimeMillis = statistics.EngineExecutionTimeInMillis
+	}
+	return changed
+}
@@ -0,0 +1,388 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/query/models"
+	"github.com/panther-labs/panther/internal/log_analysis/query_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testUserID      = "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+	testExecutionID = "c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e"
+)
+
+var testTime = time.Date(2020, 3, 14, 21, 37, 42, 0, time.UTC)
+
+type mockExecutions struct {
+	mock.Mock
+}
+
+func (m *mockExecutions) GetExecution(userID, queryExecutionID *string) (*models.QueryExecution, error) {
+	args := m.Called(userID, queryExecutionID)
+	execution, _ := args.Get(0).(*models.QueryExecution)
+	return execution, args.Error(1)
+}
+
+func (m *mockExecutions) ListExecutions(userID *string) ([]*models.QueryExecution, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*models.QueryExecution), args.Error(1)
+}
+
+func (m *mockExecutions) PutExecution(execution *models.QueryExecution) error {
+	return m.Called(execution).Error(0)
+}
+
+func (m *mockExecutions) ListSlots(userID *string) ([]*table.QuerySlot, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*table.QuerySlot), args.Error(1)
+}
+
+func (m *mockExecutions) ReserveSlot(userID *string, slots int, now, until time.Time) (int, error) {
+	args := m.Called(userID, slots, now, until)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockExecutions) AssignSlot(userID *string, slot int, queryExecutionID *string) error {
+	return m.Called(userID, slot, queryExecutionID).Error(0)
+}
+
+func (m *mockExecutions) ReleaseSlot(userID *string, slot int, queryExecutionID *string) error {
+	return m.Called(userID, slot, queryExecutionID).Error(0)
+}
+
+// mockSlots mocks the query slots of the test user, one of them is reserved with the given result
+func (m *mockExecutions) mockSlots(slots []*table.QuerySlot, reserved int) {
+	m.On("ListSlots", aws.String(testUserID)).Return(slots, nil).Once()
+	m.On("ReserveSlot", aws.String(testUserID), env.MaxConcurrentQueries, testTime, testTime.Add(slotReservationTTL)).
+		Return(reserved, nil).Once()
+}
+
+type mockSearches struct {
+	mock.Mock
+}
+
+func (m *mockSearches) GetSearch(userID, searchID *string) (*models.SavedSearch, error) {
+	args := m.Called(userID, searchID)
+	search, _ := args.Get(0).(*models.SavedSearch)
+	return search, args.Error(1)
+}
+
+func (m *mockSearches) ListSearches(userID *string) ([]*models.SavedSearch, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]*models.SavedSearch), args.Error(1)
+}
+
+func (m *mockSearches) PutSearch(search *models.SavedSearch) error {
+	return m.Called(search).Error(0)
+}
+
+func (m *mockSearches) DeleteSearch(userID, searchID *string) error {
+	return m.Called(userID, searchID).Error(0)
+}
+
+type mockAthena struct {
+	athenaiface.AthenaAPI
+	mock.Mock
+}
+
+func (m *mockAthena) StartQueryExecution(input *athena.StartQueryExecutionInput) (*athena.StartQueryExecutionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*athena.StartQueryExecutionOutput), args.Error(1)
+}
+
+func (m *mockAthena) BatchGetQueryExecution(
+	input *athena.BatchGetQueryExecutionInput) (*athena.BatchGetQueryExecutionOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*athena.BatchGetQueryExecutionOutput), args.Error(1)
+}
+
+func (m *mockAthena) GetQueryResults(input *athena.GetQueryResultsInput) (*athena.GetQueryResultsOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*athena.GetQueryResultsOutput), args.Error(1)
+}
+
+func (m *mockAthena) StopQueryExecution(input *athena.StopQueryExecutionInput) (*athena.StopQueryExecutionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*athena.StopQueryExecutionOutput), args.Error(1)
+}
+
+type mocks struct {
+	executions *mockExecutions
+	searches   *mockSearches
+	athena     *mockAthena
+}
+
+func setupMocks() *mocks {
+	result := &mocks{executions: &mockExecutions{}, searches: &mockSearches{}, athena: &mockAthena{}}
+	executionsTable, searchesTable, athenaClient = result.executions, result.searches, result.athena
+	env.AthenaResultsBucket = "athena-results"
+	env.AthenaWorkgroup = "panther-data-explorer"
+	env.MaxConcurrentQueries = 2
+	env.MaxScannedBytes = 1000
+	nowFunc = func() time.Time { return testTime }
+	return result
+}
+
+func testExecution(id, status string, scannedBytes int64, submitted time.Time) *models.QueryExecution {
+	return &models.QueryExecution{
+		QueryExecutionID: aws.String(id),
+		UserID:           aws.String(testUserID),
+		SQL:              aws.String("SELECT 1"),
+		Database:         aws.String("panther_logs"),
+		Status:           aws.String(status),
+		ScannedBytes:     aws.Int64(scannedBytes),
+		SubmittedAt:      &submitted,
+	}
+}
+
+func athenaExecution(id, state string, scannedBytes int64) *athena.QueryExecution {
+	return &athena.QueryExecution{
+		QueryExecutionId: aws.String(id),
+		Status:           &athena.QueryExecutionStatus{State: aws.String(state)},
+		Statistics:       &athena.QueryExecutionStatistics{DataScannedInBytes: aws.Int64(scannedBytes)},
+	}
+}
+
+func TestExecuteQuery(t *testing.T) {
+	mocks := setupMocks()
+	env.MaxConcurrentQueries = 3
+	// The slots of the finished execution and of the one which wasn't stored are released, the new query takes
+	// the first one. The slot reserved for a query which is starting is kept.
+	mocks.executions.mockSlots([]*table.QuerySlot{
+		{Slot: 0, QueryExecutionID: aws.String("recent")},
+		{Slot: 1, QueryExecutionID: aws.String("lost")},
+		{Slot: 2},
+	}, 0)
+	mocks.executions.On("ListExecutions", aws.String(testUserID)).Return([]*models.QueryExecution{
+		testExecution("old", models.StatusSucceeded, 5000, testTime.Add(-25*time.Hour)),
+		testExecution("recent", models.StatusSucceeded, 500, testTime.Add(-time.Hour)),
+	}, nil).Once()
+	mocks.executions.On("ReleaseSlot", aws.String(testUserID), 0, aws.String("recent")).Return(nil).Once()
+	mocks.executions.On("ReleaseSlot", aws.String(testUserID), 1, aws.String("lost")).Return(nil).Once()
+	mocks.athena.On("StartQueryExecution", mock.Anything).Return(
+		&athena.StartQueryExecutionOutput{QueryExecutionId: aws.String(testExecutionID)}, nil).Once()
+	mocks.executions.On("PutExecution", mock.Anything).Return(nil).Once()
+	mocks.executions.On("AssignSlot", aws.String(testUserID), 0, aws.String(testExecutionID)).Return(nil).Once()
+
+	result, err := (API{}).ExecuteQuery(&models.ExecuteQueryInput{
+		SQL:    aws.String("SELECT 1"),
+		UserID: aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testExecutionID, *result.QueryExecutionID)
+	assert.Equal(t, models.StatusQueued, *result.Status)
+	assert.Equal(t, "panther_logs", *result.Database)
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+
+	start := mocks.athena.Calls[0].Arguments.Get(0).(*athena.StartQueryExecutionInput)
+	assert.Equal(t, "s3://athena-results/queries/", *start.ResultConfiguration.OutputLocation)
+	assert.Equal(t, "panther-data-explorer", *start.WorkGroup)
+}
+
+func TestExecuteQueryStartFailure(t *testing.T) {
+	mocks := setupMocks()
+	mocks.executions.mockSlots([]*table.QuerySlot{}, 1)
+	mocks.executions.On("ListExecutions", aws.String(testUserID)).Return([]*models.QueryExecution{}, nil).Once()
+	mocks.athena.On("StartQueryExecution", mock.Anything).Return(
+		(*athena.StartQueryExecutionOutput)(nil), awserr.New(athena.ErrCodeInvalidRequestException, "syntax error", nil)).Once()
+	// The slot is released, the query didn't start
+	mocks.executions.On("ReleaseSlot", aws.String(testUserID), 1, (*string)(nil)).Return(nil).Once()
+
+	_, err := (API{}).ExecuteQuery(&models.ExecuteQueryInput{SQL: aws.String("SELEC 1"), UserID: aws.String(testUserID)})
+	assert.Equal(t, &genericapi.InvalidInputError{Message: "syntax error"}, err)
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+}
+
+func TestExecuteSavedSearch(t *testing.T) {
+	mocks := setupMocks()
+	mocks.searches.On("GetSearch", aws.String(testUserID), aws.String(testSearchID)).Return(testSearch(), nil).Once()
+	mocks.executions.mockSlots([]*table.QuerySlot{}, 0)
+	mocks.executions.On("ListExecutions", aws.String(testUserID)).Return([]*models.QueryExecution{}, nil).Once()
+	mocks.athena.On("StartQueryExecution", mock.Anything).Return(
+		&athena.StartQueryExecutionOutput{QueryExecutionId: aws.String(testExecutionID)}, nil).Once()
+	mocks.executions.On("PutExecution", mock.Anything).Return(nil).Once()
+	mocks.executions.On("AssignSlot", aws.String(testUserID), 0, aws.String(testExecutionID)).Return(nil).Once()
+
+	result, err := (API{}).ExecuteQuery(&models.ExecuteQueryInput{
+		SearchID:   aws.String(testSearchID),
+		Parameters: map[string]string{"ip": "1.2.3.4"},
+		UserID:     aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM all_logs WHERE contains(p_any_ip_addresses, '1.2.3.4') LIMIT 10", *result.SQL)
+	assert.Equal(t, "panther_views", *result.Database)
+	assert.Equal(t, testSearchID, *result.SearchID)
+	mocks.searches.AssertExpectations(t)
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+}
+
+func TestExecuteQueryConcurrencyQuota(t *testing.T) {
+	mocks := setupMocks()
+	// The slots are all held by running executions
+	mocks.executions.mockSlots([]*table.QuerySlot{
+		{Slot: 0, QueryExecutionID: aws.String("first")},
+		{Slot: 1, QueryExecutionID: aws.String("second")},
+	}, -1)
+	mocks.executions.On("ListExecutions", aws.String(testUserID)).Return([]*models.QueryExecution{
+		testExecution("first", models.StatusRunning, 0, testTime),
+		testExecution("second", models.StatusQueued, 0, testTime),
+	}, nil).Once()
+	mocks.athena.On("BatchGetQueryExecution", mock.Anything).Return(&athena.BatchGetQueryExecutionOutput{
+		QueryExecutions: []*athena.QueryExecution{
+			athenaExecution("first", athena.QueryExecutionStateRunning, 0),
+			athenaExecution("second", athena.QueryExecutionStateRunning, 0),
+		},
+	}, nil).Once()
+	// The second execution is now running
+	mocks.executions.On("PutExecution", mock.Anything).Return(nil).Once()
+
+	_, err := (API{}).ExecuteQuery(&models.ExecuteQueryInput{SQL: aws.String("SELECT 1"), UserID: aws.String(testUserID)})
+	require.IsType(t, &genericapi.UnavailableError{}, err)
+	assert.Contains(t, err.Error(), "2 queries are already running")
+	mocks.executions.AssertNotCalled(t, "ReleaseSlot", mock.Anything, mock.Anything, mock.Anything)
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+}
+
+func TestExecuteQueryScannedBytesQuota(t *testing.T) {
+	mocks := setupMocks()
+	mocks.executions.On("ListSlots", aws.String(testUserID)).Return([]*table.QuerySlot{}, nil).Once()
+	mocks.executions.On("ListExecutions", aws.String(testUserID)).Return([]*models.QueryExecution{
+		testExecution("finished", models.StatusSucceeded, 600, testTime.Add(-time.Hour)),
+		testExecution("running", models.StatusRunning, 100, testTime),
+	}, nil).Once()
+	mocks.athena.On("BatchGetQueryExecution", mock.Anything).Return(&athena.BatchGetQueryExecutionOutput{
+		QueryExecutions: []*athena.QueryExecution{athenaExecution("running", athena.QueryExecutionStateSucceeded, 400)},
+	}, nil).Once()
+	mocks.executions.On("PutExecution", mock.Anything).Return(nil).Once()
+
+	_, err := (API{}).ExecuteQuery(&models.ExecuteQueryInput{SQL: aws.String("SELECT 1"), UserID: aws.String(testUserID)})
+	require.IsType(t, &genericapi.UnavailableError{}, err)
+	assert.Contains(t, err.Error(), "1000 bytes were scanned in the last 24 hours")
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+}
+
+func TestGetQueryResults(t *testing.T) {
+	mocks := setupMocks()
+	mocks.executions.On("GetExecution", aws.String(testUserID), aws.String(testExecutionID)).Return(
+		testExecution(testExecutionID, models.StatusSucceeded, 100, testTime), nil).Once()
+	mocks.athena.On("GetQueryResults", mock.Anything).Return(&athena.GetQueryResultsOutput{
+		NextToken: aws.String("next"),
+		ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: []*athena.ColumnInfo{
+				{Name: aws.String("p_log_type"), Type: aws.String("varchar")},
+				{Name: aws.String("count"), Type: aws.String("bigint")},
+			}},
+			Rows: []*athena.Row{
+				{Data: []*athena.Datum{{VarCharValue: aws.String("p_log_type")}, {VarCharValue: aws.String("count")}}},
+				{Data: []*athena.Datum{{VarCharValue: aws.String("AWS.CloudTrail")}, {VarCharValue: aws.String("12")}}},
+				{Data: []*athena.Datum{{}, {VarCharValue: aws.String("3")}}},
+			},
+		},
+	}, nil).Once()
+
+	result, err := (API{}).GetQueryResults(&models.GetQueryResultsInput{
+		QueryExecutionID: aws.String(testExecutionID),
+		PageSize:         aws.Int64(2),
+		UserID:           aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Column{
+		{Name: aws.String("p_log_type"), Type: aws.String("varchar")},
+		{Name: aws.String("count"), Type: aws.String("bigint")},
+	}, result.Columns)
+	assert.Equal(t, [][]*string{{aws.String("AWS.CloudTrail"), aws.String("12")}, {nil, aws.String("3")}}, result.Rows)
+	assert.Equal(t, "next", *result.PaginationToken)
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+
+	// The header row is requested along with the first page
+	get := mocks.athena.Calls[0].Arguments.Get(0).(*athena.GetQueryResultsInput)
+	assert.Equal(t, int64(3), *get.MaxResults)
+}
+
+func TestGetQueryResultsRunning(t *testing.T) {
+	mocks := setupMocks()
+	mocks.executions.On("GetExecution", aws.String(testUserID), aws.String(testExecutionID)).Return(
+		testExecution(testExecutionID, models.StatusRunning, 0, testTime), nil).Once()
+	mocks.athena.On("BatchGetQueryExecution", mock.Anything).Return(&athena.BatchGetQueryExecutionOutput{
+		QueryExecutions: []*athena.QueryExecution{athenaExecution(testExecutionID, athena.QueryExecutionStateRunning, 0)},
+	}, nil).Once()
+
+	_, err := (API{}).GetQueryResults(&models.GetQueryResultsInput{
+		QueryExecutionID: aws.String(testExecutionID),
+		UserID:           aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.UnavailableError{}, err)
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+}
+
+func TestStopQuery(t *testing.T) {
+	mocks := setupMocks()
+	mocks.executions.On("GetExecution", aws.String(testUserID), aws.String(testExecutionID)).Return(
+		testExecution(testExecutionID, models.StatusRunning, 0, testTime), nil).Once()
+	mocks.athena.On("BatchGetQueryExecution", mock.Anything).Return(&athena.BatchGetQueryExecutionOutput{
+		QueryExecutions: []*athena.QueryExecution{athenaExecution(testExecutionID, athena.QueryExecutionStateRunning, 0)},
+	}, nil).Once()
+	mocks.athena.On("StopQueryExecution", mock.Anything).Return(&athena.StopQueryExecutionOutput{}, nil).Once()
+	mocks.athena.On("BatchGetQueryExecution", mock.Anything).Return(&athena.BatchGetQueryExecutionOutput{
+		QueryExecutions: []*athena.QueryExecution{athenaExecution(testExecutionID, athena.QueryExecutionStateCancelled, 0)},
+	}, nil).Once()
+	mocks.executions.On("PutExecution", mock.Anything).Return(nil).Once()
+
+	result, err := (API{}).StopQuery(&models.StopQueryInput{
+		QueryExecutionID: aws.String(testExecutionID),
+		UserID:           aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, *result.Status)
+	mocks.executions.AssertExpectations(t)
+	mocks.athena.AssertExpectations(t)
+}
+
+func TestGetQueryStatusOtherUser(t *testing.T) {
+	mocks := setupMocks()
+	mocks.executions.On("GetExecution", aws.String(testUserID), aws.String(testExecutionID)).Return(nil, nil).Once()
+
+	_, err := (API{}).GetQueryStatus(&models.GetQueryStatusInput{
+		QueryExecutionID: aws.String(testExecutionID),
+		UserID:           aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mocks.executions.AssertExpectations(t)
+}
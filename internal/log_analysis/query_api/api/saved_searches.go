@@ -0,0 +1,183 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/query/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var (
+	parameterNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// A reference to a parameter in the SQL of a saved search, e.g. {{ip}}
+	parameterRegex = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+	// The values of the number parameters are inserted as they are
+	numberRegex = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+)
+
+// PutSavedSearch creates or updates a saved search of the user.
+func (API) PutSavedSearch(input *models.PutSavedSearchInput) (*models.PutSavedSearchOutput, error) {
+	if err := validateParameters(*input.SQL, input.Parameters); err != nil {
+		return nil, &genericapi.InvalidInputError{Message: err.Error()}
+	}
+
+	now := nowFunc().UTC()
+	search := &models.SavedSearch{
+		SearchID:      input.SearchID,
+		UserID:        input.UserID,
+		Name:          input.Name,
+		Description:   input.Description,
+		SQL:           input.SQL,
+		Database:      input.Database,
+		Parameters:    input.Parameters,
+		CreatedAtTime: &now,
+		LastModified:  &now,
+	}
+	if search.Database == nil {
+		search.Database = aws.String(awsglue.LogProcessingDatabaseName)
+	}
+	if search.Parameters == nil {
+		search.Parameters = make([]*models.SearchParameter, 0)
+	}
+
+	if input.SearchID != nil {
+		existing, err := searchesTable.GetSearch(input.UserID, input.SearchID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "saved search " + *input.SearchID + " does not exist"}
+		}
+		search.CreatedAtTime = existing.CreatedAtTime
+	} else {
+		search.SearchID = aws.String(uuid.New().String())
+	}
+
+	if err := searchesTable.PutSearch(search); err != nil {
+		return nil, err
+	}
+	zap.L().Info("put saved search", zap.String("userId", *search.UserID), zap.String("searchId", *search.SearchID))
+	return search, nil
+}
+
+// GetSavedSearch returns a saved search of the user.
+func (API) GetSavedSearch(input *models.GetSavedSearchInput) (*models.GetSavedSearchOutput, error) {
+	search, err := searchesTable.GetSearch(input.UserID, input.SearchID)
+	if err != nil {
+		return nil, err
+	}
+	if search == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "saved search " + *input.SearchID + " does not exist"}
+	}
+	return search, nil
+}
+
+// ListSavedSearches returns the saved searches of the user.
+func (API) ListSavedSearches(input *models.ListSavedSearchesInput) (models.ListSavedSearchesOutput, error) {
+	return searchesTable.ListSearches(input.UserID)
+}
+
+// DeleteSavedSearch deletes a saved search of the user.
+func (API) DeleteSavedSearch(input *models.DeleteSavedSearchInput) error {
+	return searchesTable.DeleteSearch(input.UserID, input.SearchID)
+}
+
+// validateParameters checks that the parameters of a search are the ones its SQL references.
+func validateParameters(sql string, parameters []*models.SearchParameter) error {
+	declared := make(map[string]*models.SearchParameter, len(parameters))
+	for _, parameter := range parameters {
+		name := *parameter.Name
+		if !parameterNameRegex.MatchString(name) {
+			return fmt.Errorf("parameter name %q can only have letters, digits and underscores", name)
+		}
+		if declared[name] != nil {
+			return fmt.Errorf("parameter %s is declared twice", name)
+		}
+		if *parameter.Type == models.ParameterNumber && parameter.DefaultValue != nil &&
+			!numberRegex.MatchString(*parameter.DefaultValue) {
+
+			return fmt.Errorf("the default value of parameter %s is not a number", name)
+		}
+		declared[name] = parameter
+	}
+
+	referenced := make(map[string]bool)
+	for _, match := range parameterRegex.FindAllStringSubmatch(sql, -1) {
+		if declared[match[1]] == nil {
+			return fmt.Errorf("parameter %s is not declared", match[1])
+		}
+		referenced[match[1]] = true
+	}
+	for name := range declared {
+		if !referenced[name] {
+			return fmt.Errorf("parameter %s is not used in the sql", name)
+		}
+	}
+	return nil
+}
+
+// expandSearch returns the SQL of a saved search with its parameters replaced by their values as SQL literals.
+func expandSearch(search *models.SavedSearch, values map[string]string) (string, error) {
+	parameters := make(map[string]*models.SearchParameter, len(search.Parameters))
+	for _, parameter := range search.Parameters {
+		parameters[*parameter.Name] = parameter
+	}
+	for name := range values {
+		if parameters[name] == nil {
+			return "", fmt.Errorf("saved search has no parameter %s", name)
+		}
+	}
+
+	var err error
+	sql := parameterRegex.ReplaceAllStringFunc(*search.SQL, func(match string) string {
+		parameter := parameters[parameterRegex.FindStringSubmatch(match)[1]]
+		if parameter == nil {
+			err = fmt.Errorf("parameter of %s is not declared", match)
+			return match
+		}
+		value, ok := values[*parameter.Name]
+		if !ok {
+			if parameter.DefaultValue == nil {
+				err = fmt.Errorf("parameter %s has no value", *parameter.Name)
+				return match
+			}
+			value = *parameter.DefaultValue
+		}
+		if *parameter.Type == models.ParameterNumber {
+			if !numberRegex.MatchString(value) {
+				err = fmt.Errorf("parameter %s is not a number", *parameter.Name)
+			}
+			return value
+		}
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	})
+	if err != nil {
+		return "", err
+	}
+	return sql, nil
+}
@@ -0,0 +1,105 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/query/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testSearchID = "1e6f4f62-8d8b-4f4b-95c7-1c0f5f3b2a7d"
+
+func testSearch() *models.SavedSearch {
+	return &models.SavedSearch{
+		SearchID: aws.String(testSearchID),
+		UserID:   aws.String(testUserID),
+		Name:     aws.String("Activity"),
+		SQL:      aws.String("SELECT * FROM all_logs WHERE contains(p_any_ip_addresses, {{ ip }}) LIMIT {{limit}}"),
+		Database: aws.String("panther_views"),
+		Parameters: []*models.SearchParameter{
+			{Name: aws.String("ip"), Type: aws.String(models.ParameterString)},
+			{Name: aws.String("limit"), Type: aws.String(models.ParameterNumber), DefaultValue: aws.String("10")},
+		},
+	}
+}
+
+func TestPutSavedSearch(t *testing.T) {
+	mocks := setupMocks()
+	mocks.searches.On("PutSearch", mock.Anything).Return(nil).Once()
+
+	search := testSearch()
+	result, err := (API{}).PutSavedSearch(&models.PutSavedSearchInput{
+		Name:       search.Name,
+		SQL:        search.SQL,
+		Parameters: search.Parameters,
+		UserID:     search.UserID,
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, testSearchID, *result.SearchID)
+	assert.Equal(t, "panther_logs", *result.Database)
+	assert.Equal(t, testTime, *result.CreatedAtTime)
+	mocks.searches.AssertExpectations(t)
+}
+
+func TestValidateParameters(t *testing.T) {
+	search := testSearch()
+	assert.NoError(t, validateParameters(*search.SQL, search.Parameters))
+	assert.EqualError(t, validateParameters("SELECT {{other}}", search.Parameters), "parameter other is not declared")
+	assert.EqualError(t, validateParameters("SELECT {{ip}}", search.Parameters), "parameter limit is not used in the sql")
+	assert.EqualError(t, validateParameters("SELECT {{ip}}", []*models.SearchParameter{
+		{Name: aws.String("ip"), Type: aws.String(models.ParameterString)},
+		{Name: aws.String("ip"), Type: aws.String(models.ParameterString)},
+	}), "parameter ip is declared twice")
+	assert.EqualError(t, validateParameters("SELECT {{n}}", []*models.SearchParameter{
+		{Name: aws.String("n"), Type: aws.String(models.ParameterNumber), DefaultValue: aws.String("1 OR 1=1")},
+	}), "the default value of parameter n is not a number")
+}
+
+func TestExpandSearch(t *testing.T) {
+	sql, err := expandSearch(testSearch(), map[string]string{"ip": "1.2.3.4' OR 'a'='a"})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM all_logs WHERE contains(p_any_ip_addresses, '1.2.3.4'' OR ''a''=''a') LIMIT 10", sql)
+
+	sql, err = expandSearch(testSearch(), map[string]string{"ip": "1.2.3.4", "limit": "100"})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM all_logs WHERE contains(p_any_ip_addresses, '1.2.3.4') LIMIT 100", sql)
+
+	_, err = expandSearch(testSearch(), map[string]string{"limit": "100"})
+	assert.EqualError(t, err, "parameter ip has no value")
+	_, err = expandSearch(testSearch(), map[string]string{"ip": "1.2.3.4", "limit": "1; DROP TABLE x"})
+	assert.EqualError(t, err, "parameter limit is not a number")
+	_, err = expandSearch(testSearch(), map[string]string{"ip": "1.2.3.4", "other": "1"})
+	assert.EqualError(t, err, "saved search has no parameter other")
+}
+
+func TestGetSavedSearchDoesNotExist(t *testing.T) {
+	mocks := setupMocks()
+	mocks.searches.On("GetSearch", aws.String(testUserID), aws.String(testSearchID)).Return(nil, nil).Once()
+
+	_, err := (API{}).GetSavedSearch(&models.GetSavedSearchInput{SearchID: aws.String(testSearchID), UserID: aws.String(testUserID)})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mocks.searches.AssertExpectations(t)
+}
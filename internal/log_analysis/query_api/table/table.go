@@ -0,0 +1,391 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/query/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	hashKey           = "userId"
+	executionRangeKey = "queryExecutionId"
+	searchRangeKey    = "searchId"
+
+	// The query executions are kept for a week, their results can be retrieved until then
+	executionTTL = 7 * 24 * time.Hour
+
+	// The range keys of the query slots of a user start with it, those of the executions are Athena IDs
+	slotPrefix = "slot:"
+)
+
+// ExecutionsAPI defines the interface for the query executions table which can be used for mocking.
+type ExecutionsAPI interface {
+	GetExecution(userID, queryExecutionID *string) (*models.QueryExecution, error)
+	ListExecutions(userID *string) ([]*models.QueryExecution, error)
+	PutExecution(execution *models.QueryExecution) error
+
+	ListSlots(userID *string) ([]*QuerySlot, error)
+	ReserveSlot(userID *string, slots int, now, until time.Time) (int, error)
+	AssignSlot(userID *string, slot int, queryExecutionID *string) error
+	ReleaseSlot(userID *string, slot int, queryExecutionID *string) error
+}
+
+// SearchesAPI defines the interface for the saved searches table which can be used for mocking.
+type SearchesAPI interface {
+	GetSearch(userID, searchID *string) (*models.SavedSearch, error)
+	ListSearches(userID *string) ([]*models.SavedSearch, error)
+	PutSearch(search *models.SavedSearch) error
+	DeleteSearch(userID, searchID *string) error
+}
+
+// ExecutionsTable encapsulates a connection to the Dynamo query executions table, keyed by user.
+type ExecutionsTable struct {
+	Name   string
+	Client dynamodbiface.DynamoDBAPI
+}
+
+// SearchesTable encapsulates a connection to the Dynamo saved searches table, keyed by user.
+type SearchesTable struct {
+	Name   string
+	Client dynamodbiface.DynamoDBAPI
+}
+
+// The tables must satisfy their API interfaces.
+var (
+	_ ExecutionsAPI = (*ExecutionsTable)(nil)
+	_ SearchesAPI   = (*SearchesTable)(nil)
+)
+
+// The stored query execution
+type executionItem struct {
+	models.QueryExecution
+
+	// The TTL of the item, in epoch seconds
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// QuerySlot is one of the queries a user can run at the same time. It is reserved before the query starts,
+// and held by its execution until it finishes.
+type QuerySlot struct {
+	Slot             int     `json:"slot"`
+	QueryExecutionID *string `json:"executionId,omitempty"` // nil until the query started
+}
+
+// The stored query slot
+type slotItem struct {
+	UserID  *string `json:"userId"`
+	SlotKey string  `json:"queryExecutionId"`
+	QuerySlot
+
+	// The reservation expires at this time, in epoch seconds, it is also the TTL of the item
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+func slotKey(slot int) string {
+	return slotPrefix + strconv.Itoa(slot)
+}
+
+// GetExecution returns a query execution of a user, nil if it doesn't exist.
+func (table *ExecutionsTable) GetExecution(userID, queryExecutionID *string) (*models.QueryExecution, error) {
+	var item executionItem
+	found, err := getItem(table.Client, table.Name, map[string]*dynamodb.AttributeValue{
+		hashKey:           {S: userID},
+		executionRangeKey: {S: queryExecutionID},
+	}, &item)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &item.QueryExecution, nil
+}
+
+// ListExecutions returns the query executions of a user from the last week.
+func (table *ExecutionsTable) ListExecutions(userID *string) ([]*models.QueryExecution, error) {
+	var items []*executionItem
+	if err := queryItems(table.Client, table.Name, userID, &items); err != nil {
+		return nil, err
+	}
+	executions := make([]*models.QueryExecution, 0, len(items))
+	for _, item := range items {
+		if strings.HasPrefix(aws.StringValue(item.QueryExecutionID), slotPrefix) {
+			continue
+		}
+		executions = append(executions, &item.QueryExecution)
+	}
+	return executions, nil
+}
+
+// PutExecution writes a query execution, replacing the one with the same ID.
+func (table *ExecutionsTable) PutExecution(execution *models.QueryExecution) error {
+	item := &executionItem{
+		QueryExecution: *execution,
+		ExpiresAt:      execution.SubmittedAt.Add(executionTTL).Unix(),
+	}
+	return putItem(table.Client, table.Name, item)
+}
+
+// ListSlots returns the reserved query slots of a user, including those whose reservation expired.
+func (table *ExecutionsTable) ListSlots(userID *string) ([]*QuerySlot, error) {
+	var items []*slotItem
+	keyCondition := expression.Key(hashKey).Equal(expression.Value(*userID)).
+		And(expression.Key(executionRangeKey).BeginsWith(slotPrefix))
+	if err := queryItemsWithKeyCondition(table.Client, table.Name, keyCondition, &items); err != nil {
+		return nil, err
+	}
+	slots := make([]*QuerySlot, len(items))
+	for i, item := range items {
+		slots[i] = &item.QuerySlot
+	}
+	return slots, nil
+}
+
+// ReserveSlot reserves the first free query slot of a user until the given time, -1 is returned if the slots
+// are all taken.
+//
+// A slot is free if it isn't reserved or its reservation expired. The reservation is a conditional write, so
+// the queries started at the same time can't take the same slot.
+func (table *ExecutionsTable) ReserveSlot(userID *string, slots int, now, until time.Time) (int, error) {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeNotExists(expression.Name(hashKey)).
+			Or(expression.Name("expiresAt").LessThan(expression.Value(now.Unix())))).
+		Build()
+	if err != nil {
+		return -1, &genericapi.InternalError{Message: "failed to build ReserveSlot ddb expression: " + err.Error()}
+	}
+
+	for slot := 0; slot < slots; slot++ {
+		item, err := dynamodbattribute.MarshalMap(&slotItem{
+			UserID:    userID,
+			SlotKey:   slotKey(slot),
+			QuerySlot: QuerySlot{Slot: slot},
+			ExpiresAt: until.Unix(),
+		})
+		if err != nil {
+			return -1, &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+		}
+		_, err = table.Client.PutItem(&dynamodb.PutItemInput{
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			Item:                      item,
+			TableName:                 aws.String(table.Name),
+		})
+		if isConditionalCheckFailed(err) {
+			continue
+		}
+		if err != nil {
+			return -1, &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+		}
+		return slot, nil
+	}
+	return -1, nil
+}
+
+// AssignSlot gives a reserved query slot to the execution of the query, which holds it until it finishes.
+//
+// The slot is kept as long as the execution, it is released once the execution is seen finished.
+func (table *ExecutionsTable) AssignSlot(userID *string, slot int, queryExecutionID *string) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(hashKey)).
+			And(expression.AttributeNotExists(expression.Name("executionId")))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build AssignSlot ddb expression: " + err.Error()}
+	}
+
+	item, err := dynamodbattribute.MarshalMap(&slotItem{
+		UserID:    userID,
+		SlotKey:   slotKey(slot),
+		QuerySlot: QuerySlot{Slot: slot, QueryExecutionID: queryExecutionID},
+		ExpiresAt: time.Now().Add(executionTTL).Unix(),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	_, err = table.Client.PutItem(&dynamodb.PutItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Item:                      item,
+		TableName:                 aws.String(table.Name),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// ReleaseSlot frees a query slot held by an execution, or reserved for a query which didn't start when the
+// execution ID is nil. A slot which was already released, or taken again since, is left as it is.
+func (table *ExecutionsTable) ReleaseSlot(userID *string, slot int, queryExecutionID *string) error {
+	condition := expression.AttributeNotExists(expression.Name("executionId"))
+	if queryExecutionID != nil {
+		condition = expression.Name("executionId").Equal(expression.Value(*queryExecutionID))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build ReleaseSlot ddb expression: " + err.Error()}
+	}
+
+	_, err = table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Key:                       map[string]*dynamodb.AttributeValue{hashKey: {S: userID}, executionRangeKey: {S: aws.String(slotKey(slot))}},
+		TableName:                 aws.String(table.Name),
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
+
+// GetSearch returns a saved search of a user, nil if it doesn't exist.
+func (table *SearchesTable) GetSearch(userID, searchID *string) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	found, err := getItem(table.Client, table.Name, map[string]*dynamodb.AttributeValue{
+		hashKey:        {S: userID},
+		searchRangeKey: {S: searchID},
+	}, &search)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &search, nil
+}
+
+// ListSearches returns the saved searches of a user.
+func (table *SearchesTable) ListSearches(userID *string) ([]*models.SavedSearch, error) {
+	searches := make([]*models.SavedSearch, 0)
+	if err := queryItems(table.Client, table.Name, userID, &searches); err != nil {
+		return nil, err
+	}
+	return searches, nil
+}
+
+// PutSearch writes a saved search, replacing the one with the same ID.
+func (table *SearchesTable) PutSearch(search *models.SavedSearch) error {
+	return putItem(table.Client, table.Name, search)
+}
+
+// DeleteSearch deletes a saved search, a DoesNotExistError is returned if the user has none with that ID.
+func (table *SearchesTable) DeleteSearch(userID, searchID *string) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(hashKey))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build DeleteSearch ddb expression: " + err.Error()}
+	}
+
+	_, err = table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Key:                      map[string]*dynamodb.AttributeValue{hashKey: {S: userID}, searchRangeKey: {S: searchID}},
+		TableName:                aws.String(table.Name),
+	})
+	if isConditionalCheckFailed(err) {
+		return &genericapi.DoesNotExistError{Message: "saved search " + aws.StringValue(searchID) + " does not exist"}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
+
+func getItem(client dynamodbiface.DynamoDBAPI, tableName string,
+	key map[string]*dynamodb.AttributeValue, result interface{}) (bool, error) {
+
+	output, err := client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            key,
+		TableName:      aws.String(tableName),
+	})
+	if err != nil {
+		return false, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if len(output.Item) == 0 {
+		return false, nil
+	}
+	if err = dynamodbattribute.UnmarshalMap(output.Item, result); err != nil {
+		return false, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalMap"}
+	}
+	return true, nil
+}
+
+func isConditionalCheckFailed(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+// queryItems appends all the items of a user to the result slice, page by page.
+func queryItems(client dynamodbiface.DynamoDBAPI, tableName string, userID *string, result interface{}) error {
+	return queryItemsWithKeyCondition(client, tableName, expression.Key(hashKey).Equal(expression.Value(*userID)), result)
+}
+
+// queryItemsWithKeyCondition appends all the items matching the key condition to the result slice, page by page.
+func queryItemsWithKeyCondition(client dynamodbiface.DynamoDBAPI, tableName string,
+	keyCondition expression.KeyConditionBuilder, result interface{}) error {
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyCondition).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build Query ddb expression: " + err.Error()}
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	err = client.QueryPages(&dynamodb.QueryInput{
+		ConsistentRead:            aws.Bool(true),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		TableName:                 aws.String(tableName),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, page.Items...)
+		return true
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.QueryPages"}
+	}
+	if err = dynamodbattribute.UnmarshalListOfMaps(items, result); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	return nil
+}
+
+func putItem(client dynamodbiface.DynamoDBAPI, tableName string, value interface{}) error {
+	item, err := dynamodbattribute.MarshalMap(value)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	if _, err = client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: aws.String(tableName)}); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
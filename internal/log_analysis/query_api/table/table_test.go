@@ -0,0 +1,166 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/query/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) QueryPages(input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool) error {
+	args := m.Called(input, fn)
+	fn(args.Get(0).(*dynamodb.QueryOutput), true)
+	return args.Error(1)
+}
+
+func (m *mockDynamoClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func TestPutGetExecution(t *testing.T) {
+	submitted := time.Date(2020, 3, 14, 21, 37, 42, 0, time.UTC)
+	execution := &models.QueryExecution{
+		QueryExecutionID: aws.String("c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e"),
+		UserID:           aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"),
+		SQL:              aws.String("SELECT 1"),
+		Database:         aws.String("panther_logs"),
+		Status:           aws.String(models.StatusQueued),
+		SubmittedAt:      &submitted,
+	}
+	mockClient := &mockDynamoClient{}
+	mockClient.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	table := &ExecutionsTable{Name: "test", Client: mockClient}
+
+	require.NoError(t, table.PutExecution(execution))
+	item := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.PutItemInput).Item
+	assert.Equal(t, "1584826662", *item["expiresAt"].N)
+	assert.Equal(t, *execution.UserID, *item["userId"].S)
+	assert.Equal(t, *execution.QueryExecutionID, *item["queryExecutionId"].S)
+
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+	result, err := table.GetExecution(execution.UserID, execution.QueryExecutionID)
+	require.NoError(t, err)
+	assert.Equal(t, execution, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListSearchesEmpty(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("QueryPages", mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{}, nil).Once()
+	table := &SearchesTable{Name: "test", Client: mockClient}
+
+	result, err := table.ListSearches(aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"))
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteSearchDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+	table := &SearchesTable{Name: "test", Client: mockClient}
+
+	err := table.DeleteSearch(aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"), aws.String("1e6f4f62-8d8b-4f4b-95c7-1c0f5f3b2a7d"))
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
+
+func TestReserveSlot(t *testing.T) {
+	now := time.Date(2020, 3, 14, 21, 37, 42, 0, time.UTC)
+	conditionFailed := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	mockClient := &mockDynamoClient{}
+	// The first slot is taken, the second one is free
+	mockClient.On("PutItem", mock.Anything).Return((*dynamodb.PutItemOutput)(nil), conditionFailed).Once()
+	mockClient.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	table := &ExecutionsTable{Name: "test", Client: mockClient}
+
+	slot, err := table.ReserveSlot(aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"), 2, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, slot)
+	input := mockClient.Calls[1].Arguments.Get(0).(*dynamodb.PutItemInput)
+	assert.Equal(t, "slot:1", *input.Item["queryExecutionId"].S)
+	assert.Equal(t, "1584221922", *input.Item["expiresAt"].N)
+	assert.NotContains(t, input.Item, "executionId")
+	assert.Equal(t, "(attribute_not_exists (#0)) OR (#1 < :0)", *input.ConditionExpression)
+
+	// The slots are all taken
+	mockClient.On("PutItem", mock.Anything).Return((*dynamodb.PutItemOutput)(nil), conditionFailed).Twice()
+	slot, err = table.ReserveSlot(aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"), 2, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, -1, slot)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListExecutionsSkipsSlots(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("QueryPages", mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"queryExecutionId": {S: aws.String("c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e")}, "status": {S: aws.String(models.StatusRunning)}},
+			{"queryExecutionId": {S: aws.String("slot:0")}, "slot": {N: aws.String("0")}},
+		},
+	}, nil).Once()
+	table := &ExecutionsTable{Name: "test", Client: mockClient}
+
+	result, err := table.ListExecutions(aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"))
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e", *result[0].QueryExecutionID)
+}
+
+func TestReleaseSlot(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	// The slot was already released or taken by another execution
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)).Once()
+	table := &ExecutionsTable{Name: "test", Client: mockClient}
+
+	require.NoError(t, table.ReleaseSlot(aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"), 1,
+		aws.String("c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e")))
+	input := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.DeleteItemInput)
+	assert.Equal(t, "slot:1", *input.Key["queryExecutionId"].S)
+	assert.Equal(t, "c3b2efc5-3d2e-4c1a-9a3c-6b4b5f1b8f1e", *input.ExpressionAttributeValues[":0"].S)
+}
@@ -0,0 +1,105 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/redaction/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// Fixed width, so that change IDs sort chronologically
+const changeIDTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// PutRedactionPolicy creates or replaces the redaction policy of a log type, and records the change.
+func (API) PutRedactionPolicy(input *models.PutRedactionPolicyInput) (*models.PutRedactionPolicyOutput, error) {
+	paths := make(map[string]struct{}, len(input.Fields))
+	for _, field := range input.Fields {
+		if err := validatePath(*field.Path); err != nil {
+			return nil, err
+		}
+		if _, ok := paths[*field.Path]; ok {
+			return nil, &genericapi.InvalidInputError{Message: "field " + *field.Path + " is redacted more than once"}
+		}
+		paths[*field.Path] = struct{}{}
+		if *field.Action == models.ActionTruncate && field.Length == nil {
+			return nil, &genericapi.InvalidInputError{Message: "field " + *field.Path + " is truncated without a length"}
+		}
+	}
+
+	existing, err := policiesTable.GetPolicy(input.LogType)
+	if err != nil {
+		return nil, err
+	}
+
+	now := nowFunc().UTC()
+	policy := &models.RedactionPolicy{
+		LogType:        input.LogType,
+		Description:    input.Description,
+		Fields:         input.Fields,
+		Enabled:        aws.Bool(aws.BoolValue(input.Enabled)),
+		CreatedAtTime:  &now,
+		CreatedBy:      input.UserID,
+		LastModified:   &now,
+		LastModifiedBy: input.UserID,
+	}
+	change := newChange(input.LogType, input.UserID, now, models.OperationCreated)
+	if existing != nil {
+		policy.CreatedAtTime, policy.CreatedBy = existing.CreatedAtTime, existing.CreatedBy
+		change.Operation, change.Before = aws.String(models.OperationUpdated), existing
+	}
+	change.After = policy
+
+	if err := policiesTable.PutPolicy(policy, change); err != nil {
+		return nil, err
+	}
+	zap.L().Info("put redaction policy",
+		zap.String("logType", *policy.LogType), zap.String("operation", *change.Operation), zap.Int("fields", len(policy.Fields)))
+	return policy, nil
+}
+
+// validatePath checks a dot-separated field path, the p_ fields added by Panther can't be redacted.
+func validatePath(path string) error {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return &genericapi.InvalidInputError{Message: "field path " + path + " has an empty segment"}
+		}
+	}
+	if strings.HasPrefix(segments[0], "p_") {
+		return &genericapi.InvalidInputError{Message: "field " + path + " is added by Panther, it can't be redacted"}
+	}
+	return nil
+}
+
+func newChange(logType, userID *string, now time.Time, operation string) *models.RedactionPolicyChange {
+	return &models.RedactionPolicyChange{
+		LogType:   logType,
+		ChangeID:  aws.String(now.Format(changeIDTimeFormat) + "-" + uuid.New().String()),
+		ChangedAt: &now,
+		ChangedBy: userID,
+		Operation: aws.String(operation),
+	}
+}
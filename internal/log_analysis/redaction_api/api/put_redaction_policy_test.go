@@ -0,0 +1,183 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/redaction/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testUserID = "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+
+var testTime = time.Date(2020, 3, 14, 21, 37, 42, 0, time.UTC)
+
+type mockTable struct {
+	mock.Mock
+}
+
+func (m *mockTable) GetPolicy(logType *string) (*models.RedactionPolicy, error) {
+	args := m.Called(logType)
+	policy, _ := args.Get(0).(*models.RedactionPolicy)
+	return policy, args.Error(1)
+}
+
+func (m *mockTable) ListPolicies() ([]*models.RedactionPolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.RedactionPolicy), args.Error(1)
+}
+
+func (m *mockTable) PutPolicy(policy *models.RedactionPolicy, change *models.RedactionPolicyChange) error {
+	return m.Called(policy, change).Error(0)
+}
+
+func (m *mockTable) DeletePolicy(logType *string, change *models.RedactionPolicyChange) error {
+	return m.Called(logType, change).Error(0)
+}
+
+func (m *mockTable) ListChanges(logType *string, limit int,
+	exclusiveStartChangeID *string) ([]*models.RedactionPolicyChange, *string, error) {
+
+	args := m.Called(logType, limit, exclusiveStartChangeID)
+	lastChangeID, _ := args.Get(1).(*string)
+	return args.Get(0).([]*models.RedactionPolicyChange), lastChangeID, args.Error(2)
+}
+
+func setupMocks() *mockTable {
+	mockPolicies := &mockTable{}
+	policiesTable = mockPolicies
+	nowFunc = func() time.Time { return testTime }
+	return mockPolicies
+}
+
+func putInput(fields ...*models.RedactedField) *models.PutRedactionPolicyInput {
+	return &models.PutRedactionPolicyInput{
+		LogType: aws.String("AWS.ALB"),
+		Fields:  fields,
+		Enabled: aws.Bool(true),
+		UserID:  aws.String(testUserID),
+	}
+}
+
+func field(path, action string) *models.RedactedField {
+	return &models.RedactedField{Path: aws.String(path), Action: aws.String(action)}
+}
+
+func TestPutRedactionPolicyCreates(t *testing.T) {
+	mockPolicies := setupMocks()
+	mockPolicies.On("GetPolicy", aws.String("AWS.ALB")).Return(nil, nil).Once()
+	mockPolicies.On("PutPolicy", mock.Anything, mock.Anything).Return(nil).Once()
+
+	result, err := (API{}).PutRedactionPolicy(putInput(field("clientIp", models.ActionHash)))
+	require.NoError(t, err)
+	assert.Equal(t, testTime, *result.CreatedAtTime)
+	assert.Equal(t, testUserID, *result.CreatedBy)
+	mockPolicies.AssertExpectations(t)
+
+	change := mockPolicies.Calls[1].Arguments.Get(1).(*models.RedactionPolicyChange)
+	assert.Equal(t, models.OperationCreated, *change.Operation)
+	assert.True(t, strings.HasPrefix(*change.ChangeID, "2020-03-14T21:37:42.000000000Z-"))
+	assert.Equal(t, testUserID, *change.ChangedBy)
+	assert.Nil(t, change.Before)
+	assert.Equal(t, result, change.After)
+}
+
+func TestPutRedactionPolicyUpdates(t *testing.T) {
+	mockPolicies := setupMocks()
+	created := testTime.Add(-time.Hour)
+	existing := &models.RedactionPolicy{
+		LogType:       aws.String("AWS.ALB"),
+		Fields:        []*models.RedactedField{field("clientIp", models.ActionHash)},
+		Enabled:       aws.Bool(true),
+		CreatedAtTime: &created,
+		CreatedBy:     aws.String("creator"),
+	}
+	mockPolicies.On("GetPolicy", aws.String("AWS.ALB")).Return(existing, nil).Once()
+	mockPolicies.On("PutPolicy", mock.Anything, mock.Anything).Return(nil).Once()
+
+	truncated := field("requestUrl", models.ActionTruncate)
+	truncated.Length = aws.Int(64)
+	result, err := (API{}).PutRedactionPolicy(putInput(field("clientIp", models.ActionDrop), truncated))
+	require.NoError(t, err)
+	assert.Equal(t, created, *result.CreatedAtTime)
+	assert.Equal(t, "creator", *result.CreatedBy)
+	assert.Equal(t, testTime, *result.LastModified)
+
+	change := mockPolicies.Calls[1].Arguments.Get(1).(*models.RedactionPolicyChange)
+	assert.Equal(t, models.OperationUpdated, *change.Operation)
+	assert.Equal(t, existing, change.Before)
+	assert.Equal(t, result, change.After)
+}
+
+func TestPutRedactionPolicyInvalidFields(t *testing.T) {
+	setupMocks()
+	for _, fields := range [][]*models.RedactedField{
+		{field("p_event_time", models.ActionDrop)},
+		{field("userIdentity..arn", models.ActionHash)},
+		{field("clientIp", models.ActionHash), field("clientIp", models.ActionDrop)},
+		{field("requestUrl", models.ActionTruncate)},
+	} {
+		_, err := (API{}).PutRedactionPolicy(putInput(fields...))
+		assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	}
+}
+
+func TestDeleteRedactionPolicy(t *testing.T) {
+	mockPolicies := setupMocks()
+	existing := &models.RedactionPolicy{LogType: aws.String("AWS.ALB")}
+	mockPolicies.On("GetPolicy", aws.String("AWS.ALB")).Return(existing, nil).Once()
+	mockPolicies.On("DeletePolicy", aws.String("AWS.ALB"), mock.Anything).Return(nil).Once()
+	mockPolicies.On("GetPolicy", aws.String("AWS.CloudTrail")).Return(nil, nil).Once()
+
+	require.NoError(t, (API{}).DeleteRedactionPolicy(&models.DeleteRedactionPolicyInput{
+		LogType: aws.String("AWS.ALB"),
+		UserID:  aws.String(testUserID),
+	}))
+	change := mockPolicies.Calls[1].Arguments.Get(1).(*models.RedactionPolicyChange)
+	assert.Equal(t, models.OperationDeleted, *change.Operation)
+	assert.Equal(t, existing, change.Before)
+	assert.Nil(t, change.After)
+
+	err := (API{}).DeleteRedactionPolicy(&models.DeleteRedactionPolicyInput{
+		LogType: aws.String("AWS.CloudTrail"),
+		UserID:  aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockPolicies.AssertExpectations(t)
+}
+
+func TestListRedactionPolicyChanges(t *testing.T) {
+	mockPolicies := setupMocks()
+	changes := []*models.RedactionPolicyChange{{LogType: aws.String("AWS.ALB")}}
+	mockPolicies.On("ListChanges", aws.String("AWS.ALB"), defaultChangesPageSize, (*string)(nil)).
+		Return(changes, aws.String("last"), nil).Once()
+
+	result, err := (API{}).ListRedactionPolicyChanges(&models.ListRedactionPolicyChangesInput{LogType: aws.String("AWS.ALB")})
+	require.NoError(t, err)
+	assert.Equal(t, changes, result.Changes)
+	assert.Equal(t, "last", *result.LastEvaluatedKey)
+}
@@ -0,0 +1,72 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/api/lambda/redaction/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const defaultChangesPageSize = 25
+
+// GetRedactionPolicy returns the redaction policy of a log type.
+func (API) GetRedactionPolicy(input *models.GetRedactionPolicyInput) (*models.GetRedactionPolicyOutput, error) {
+	policy, err := policiesTable.GetPolicy(input.LogType)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "redaction policy of " + *input.LogType + " does not exist"}
+	}
+	return policy, nil
+}
+
+// ListRedactionPolicies returns all the redaction policies.
+func (API) ListRedactionPolicies(_ *models.ListRedactionPoliciesInput) (models.ListRedactionPoliciesOutput, error) {
+	return policiesTable.ListPolicies()
+}
+
+// DeleteRedactionPolicy deletes the redaction policy of a log type, and records the change.
+func (API) DeleteRedactionPolicy(input *models.DeleteRedactionPolicyInput) error {
+	existing, err := policiesTable.GetPolicy(input.LogType)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return &genericapi.DoesNotExistError{Message: "redaction policy of " + *input.LogType + " does not exist"}
+	}
+	change := newChange(input.LogType, input.UserID, nowFunc().UTC(), models.OperationDeleted)
+	change.Before = existing
+	return policiesTable.DeletePolicy(input.LogType, change)
+}
+
+// ListRedactionPolicyChanges returns a page of the changes to the redaction policy of a log type, most recent first.
+func (API) ListRedactionPolicyChanges(
+	input *models.ListRedactionPolicyChangesInput) (*models.ListRedactionPolicyChangesOutput, error) {
+
+	pageSize := defaultChangesPageSize
+	if input.PageSize != nil {
+		pageSize = *input.PageSize
+	}
+	changes, lastChangeID, err := policiesTable.ListChanges(input.LogType, pageSize, input.ExclusiveStartKey)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListRedactionPolicyChangesOutput{Changes: changes, LastEvaluatedKey: lastChangeID}, nil
+}
@@ -0,0 +1,216 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/redaction/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	hashKey     = "logType"
+	changeIDKey = "changeId"
+)
+
+// API defines the interface for the redaction policies tables which can be used for mocking.
+type API interface {
+	GetPolicy(logType *string) (*models.RedactionPolicy, error)
+	ListPolicies() ([]*models.RedactionPolicy, error)
+	PutPolicy(policy *models.RedactionPolicy, change *models.RedactionPolicyChange) error
+	DeletePolicy(logType *string, change *models.RedactionPolicyChange) error
+	ListChanges(logType *string, limit int, exclusiveStartChangeID *string) ([]*models.RedactionPolicyChange, *string, error)
+}
+
+// PoliciesTable encapsulates a connection to the Dynamo tables of the redaction policies and of their changes.
+type PoliciesTable struct {
+	Name        string
+	ChangesName string
+	Client      dynamodbiface.DynamoDBAPI
+}
+
+// The PoliciesTable must satisfy the API interface.
+var _ API = (*PoliciesTable)(nil)
+
+// GetPolicy returns the redaction policy of a log type, nil if it doesn't exist.
+func (table *PoliciesTable) GetPolicy(logType *string) (*models.RedactionPolicy, error) {
+	output, err := table.Client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{hashKey: {S: logType}},
+		TableName:      aws.String(table.Name),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+
+	var policy models.RedactionPolicy
+	if err = dynamodbattribute.UnmarshalMap(output.Item, &policy); err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalMap"}
+	}
+	return &policy, nil
+}
+
+// ListPolicies returns all the redaction policies, page by page.
+func (table *PoliciesTable) ListPolicies() ([]*models.RedactionPolicy, error) {
+	policies := make([]*models.RedactionPolicy, 0)
+	var unmarshalErr error
+	err := table.Client.ScanPages(&dynamodb.ScanInput{
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(table.Name),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var pagePolicies []*models.RedactionPolicy
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pagePolicies); unmarshalErr != nil {
+			return false // stop paginating
+		}
+		policies = append(policies, pagePolicies...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.ScanPages"}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.AWSError{Err: unmarshalErr, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	return policies, nil
+}
+
+// PutPolicy writes a redaction policy, replacing the one of the same log type, along with its change.
+//
+// Both are written in a single transaction: a policy is never changed without its change being recorded.
+func (table *PoliciesTable) PutPolicy(policy *models.RedactionPolicy, change *models.RedactionPolicyChange) error {
+	item, err := dynamodbattribute.MarshalMap(policy)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	changeItem, err := table.changeItem(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.Client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Put: &dynamodb.Put{Item: item, TableName: aws.String(table.Name)}},
+			changeItem,
+		},
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.TransactWriteItems"}
+	}
+	return nil
+}
+
+// DeletePolicy deletes the redaction policy of a log type along with recording its change,
+// a DoesNotExistError is returned if there is none.
+func (table *PoliciesTable) DeletePolicy(logType *string, change *models.RedactionPolicyChange) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(hashKey))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build DeletePolicy ddb expression: " + err.Error()}
+	}
+	changeItem, err := table.changeItem(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = table.Client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Delete: &dynamodb.Delete{
+					ConditionExpression:      expr.Condition(),
+					ExpressionAttributeNames: expr.Names(),
+					Key:                      map[string]*dynamodb.AttributeValue{hashKey: {S: logType}},
+					TableName:                aws.String(table.Name),
+				},
+			},
+			changeItem,
+		},
+	})
+	if canceled, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		// The reasons are in the order of the items, the first one is the deletion
+		reasons := canceled.CancellationReasons
+		if len(reasons) > 0 && aws.StringValue(reasons[0].Code) == "ConditionalCheckFailed" {
+			return &genericapi.DoesNotExistError{Message: "redaction policy of " + aws.StringValue(logType) + " does not exist"}
+		}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.TransactWriteItems"}
+	}
+	return nil
+}
+
+func (table *PoliciesTable) changeItem(change *models.RedactionPolicyChange) (*dynamodb.TransactWriteItem, error) {
+	item, err := dynamodbattribute.MarshalMap(change)
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	return &dynamodb.TransactWriteItem{Put: &dynamodb.Put{Item: item, TableName: aws.String(table.ChangesName)}}, nil
+}
+
+// ListChanges returns a page of the changes to the redaction policy of a log type, most recent first.
+//
+// The change ID of the last returned change is set if there may be more changes, the next page starts after it.
+func (table *PoliciesTable) ListChanges(logType *string, limit int,
+	exclusiveStartChangeID *string) ([]*models.RedactionPolicyChange, *string, error) {
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.Key(hashKey).Equal(expression.Value(logType))).
+		Build()
+	if err != nil {
+		return nil, nil, &genericapi.InternalError{Message: "failed to build ListChanges ddb expression: " + err.Error()}
+	}
+
+	input := &dynamodb.QueryInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		Limit:                     aws.Int64(int64(limit)),
+		ScanIndexForward:          aws.Bool(false),
+		TableName:                 aws.String(table.ChangesName),
+	}
+	if exclusiveStartChangeID != nil {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			hashKey:     {S: logType},
+			changeIDKey: {S: exclusiveStartChangeID},
+		}
+	}
+
+	output, err := table.Client.Query(input)
+	if err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.Query"}
+	}
+
+	changes := make([]*models.RedactionPolicyChange, 0, len(output.Items))
+	if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &changes); err != nil {
+		return nil, nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	var lastChangeID *string
+	if key, ok := output.LastEvaluatedKey[changeIDKey]; ok {
+		lastChangeID = key.S
+	}
+	return changes, lastChangeID, nil
+}
@@ -0,0 +1,80 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/redaction/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.TransactWriteItemsOutput), args.Error(1)
+}
+
+func testChange(operation string) *models.RedactionPolicyChange {
+	return &models.RedactionPolicyChange{
+		LogType:   aws.String("AWS.ALB"),
+		ChangeID:  aws.String("2020-05-01T00:00:00.000000000Z-8c2e7e84-6b4c-4f86-9b36-1c3d0f8e8a61"),
+		ChangedBy: aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"),
+		Operation: aws.String(operation),
+	}
+}
+
+func TestPutPolicyRecordsChange(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{}, nil).Once()
+	table := &PoliciesTable{Name: "policies", ChangesName: "changes", Client: mockClient}
+
+	policy := &models.RedactionPolicy{LogType: aws.String("AWS.ALB"), Enabled: aws.Bool(true)}
+	require.NoError(t, table.PutPolicy(policy, testChange(models.OperationCreated)))
+
+	input := mockClient.Calls[0].Arguments.Get(0).(*dynamodb.TransactWriteItemsInput)
+	require.Len(t, input.TransactItems, 2)
+	assert.Equal(t, "policies", *input.TransactItems[0].Put.TableName)
+	assert.Equal(t, "AWS.ALB", *input.TransactItems[0].Put.Item[hashKey].S)
+	assert.Equal(t, "changes", *input.TransactItems[1].Put.TableName)
+	assert.Equal(t, models.OperationCreated, *input.TransactItems[1].Put.Item["operation"].S)
+}
+
+func TestDeletePolicyDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("TransactWriteItems", mock.Anything).Return(&dynamodb.TransactWriteItemsOutput{},
+		&dynamodb.TransactionCanceledException{CancellationReasons: []*dynamodb.CancellationReason{
+			{Code: aws.String("ConditionalCheckFailed")}, {Code: aws.String("None")},
+		}})
+	table := &PoliciesTable{Name: "policies", ChangesName: "changes", Client: mockClient}
+
+	err := table.DeletePolicy(aws.String("AWS.ALB"), testChange(models.OperationDeleted))
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+}
@@ -0,0 +1,149 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	logmodels "github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The layout of the p_event_time of the processed events
+const eventTimeLayout = "2006-01-02 15:04:05.000000000"
+
+// historicalEvent is a processed event of the data lake.
+type historicalEvent struct {
+	logType string
+	time    time.Time
+	data    json.RawMessage
+}
+
+// The events are selected by their p_event_time, the other fields are only analyzed by the rule.
+type eventTime struct {
+	PantherEventTime string `json:"p_event_time"`
+}
+
+// readEvents calls handle with the events of the log types in the time range, until it returns false.
+//
+// The partitions of the processed data are those of the time the events were processed, so the partitions
+// up to an hour after the end of the range are read for the events processed late. Only the gzip JSON objects are read,
+// the Parquet ones are skipped.
+func readEvents(logTypes []string, start, end time.Time, handle func(*historicalEvent) bool) error {
+	for hour := start.Truncate(time.Hour); !hour.After(end.Add(time.Hour)); hour = hour.Add(time.Hour) {
+		for _, logType := range logTypes {
+			prefix := awsglue.GetPartitionPrefix(logmodels.LogData, logType, awsglue.GlueTableHourly, hour)
+			keys, err := listObjects(prefix)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				more, err := readObject(logType, key, start, end, handle)
+				if err != nil {
+					return err
+				}
+				if !more {
+					return nil
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// listObjects returns the keys of the gzip JSON objects with the prefix.
+func listObjects(prefix string) ([]string, error) {
+	var keys []string
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(env.ProcessedDataBucket),
+		Prefix: aws.String(prefix),
+	}
+	err := s3Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			if strings.HasSuffix(*object.Key, awsglue.JSONObjectSuffix) {
+				keys = append(keys, *object.Key)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Method: "s3.ListObjectsV2Pages", Err: err}
+	}
+	return keys, nil
+}
+
+// readObject calls handle with the events of the object in the time range, it returns false once handle does.
+func readObject(logType, key string, start, end time.Time, handle func(*historicalEvent) bool) (bool, error) {
+	response, err := s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(env.ProcessedDataBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return false, &genericapi.AWSError{Method: "s3.GetObject", Err: err}
+	}
+	defer response.Body.Close()
+
+	gzipReader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		return false, &genericapi.InternalError{Message: "failed to read " + key + ": " + err.Error()}
+	}
+	reader := bufio.NewReader(gzipReader)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			event, ok := parseEvent(logType, line)
+			if !ok {
+				zap.L().Warn("skipped an event without a valid p_event_time", zap.String("key", key))
+			} else if !event.time.Before(start) && event.time.Before(end) && !handle(event) {
+				return false, nil
+			}
+		}
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, &genericapi.InternalError{Message: "failed to read " + key + ": " + err.Error()}
+		}
+	}
+}
+
+func parseEvent(logType string, line []byte) (*historicalEvent, bool) {
+	var fields eventTime
+	if err := jsoniter.Unmarshal(line, &fields); err != nil {
+		return nil, false
+	}
+	t, err := time.Parse(eventTimeLayout, fields.PantherEventTime)
+	if err != nil {
+		return nil, false
+	}
+	// ReadBytes returns a new slice for each line, it is not copied
+	return &historicalEvent{logType: logType, time: t, data: bytes.TrimSpace(line)}, true
+}
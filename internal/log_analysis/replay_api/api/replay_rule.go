@@ -0,0 +1,249 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	enginemodels "github.com/panther-labs/panther/api/gateway/analysis"
+	"github.com/panther-labs/panther/api/gateway/analysis/client/operations"
+	"github.com/panther-labs/panther/api/lambda/replay/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The ID of the new rules, replayed with their body
+	newRuleID = "ReplayedRule"
+
+	defaultDedupPeriodMinutes = 60
+	defaultMaxEvents          = 100000
+	maxReplayRange            = 31 * 24 * time.Hour
+
+	maxErrors       = 10
+	maxSampleEvents = 10
+
+	// The events are analyzed in batches, below the 6 MB payload limit of a Lambda invocation
+	maxBatchBytes  = 4 * 1024 * 1024
+	maxBatchEvents = 1000
+)
+
+// ReplayRule runs a rule against the historical events of the data lake, it reports the alerts without delivering them.
+func (API) ReplayRule(input *models.ReplayRuleInput) (*models.ReplayRuleOutput, error) {
+	start, end := input.StartTime.UTC(), input.EndTime.UTC()
+	if !end.After(start) {
+		return nil, &genericapi.InvalidInputError{Message: "endTime must be after startTime"}
+	}
+	if end.Sub(start) > maxReplayRange {
+		return nil, &genericapi.InvalidInputError{Message: "the time range can not exceed 31 days"}
+	}
+
+	rule, dedupPeriod, err := getReplayedRule(input)
+	if err != nil {
+		return nil, err
+	}
+	maxEvents := defaultMaxEvents
+	if input.MaxEvents != nil {
+		maxEvents = *input.MaxEvents
+	}
+
+	r := &replay{
+		rule: rule,
+		output: &models.ReplayRuleOutput{
+			RuleID:    rule.ID,
+			LogTypes:  rule.LogTypes,
+			StartTime: start,
+			EndTime:   end,
+			Errors:    []*models.ReplayError{},
+		},
+	}
+	var analysisErr error
+	err = readEvents(rule.LogTypes, start, end, func(event *historicalEvent) bool {
+		if r.output.EventsScanned == maxEvents {
+			r.output.Truncated = true
+			return false
+		}
+		r.output.EventsScanned++
+		analysisErr = r.add(event)
+		return analysisErr == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if analysisErr != nil {
+		return nil, analysisErr
+	}
+	if err := r.analyze(); err != nil {
+		return nil, err
+	}
+
+	r.output.Alerts = mergeAlerts(r.matches, dedupPeriod)
+	zap.L().Info("replayed rule",
+		zap.String("ruleId", rule.ID),
+		zap.Int("eventsScanned", r.output.EventsScanned),
+		zap.Int("eventsMatched", r.output.EventsMatched),
+		zap.Int("alerts", len(r.output.Alerts)))
+	return r.output, nil
+}
+
+// getReplayedRule returns the rule of the input and its dedup period, an existing one is read from the analysis-api.
+func getReplayedRule(input *models.ReplayRuleInput) (*enginemodels.Rule, time.Duration, error) {
+	if (input.RuleID == nil) == (input.Body == nil) {
+		return nil, 0, &genericapi.InvalidInputError{Message: "exactly one of ruleId and body is required"}
+	}
+
+	rule := &enginemodels.Rule{ID: newRuleID}
+	dedupPeriodMinutes := defaultDedupPeriodMinutes
+	if input.RuleID != nil {
+		response, err := analysisClient.Operations.GetRule(&operations.GetRuleParams{
+			RuleID:     *input.RuleID,
+			HTTPClient: httpClient,
+		})
+		if err != nil {
+			if _, ok := err.(*operations.GetRuleNotFound); ok {
+				return nil, 0, &genericapi.DoesNotExistError{Message: "rule " + *input.RuleID}
+			}
+			return nil, 0, &genericapi.InternalError{Message: "failed to get rule " + *input.RuleID + ": " + err.Error()}
+		}
+		rule.ID = *input.RuleID
+		rule.Body = string(response.Payload.Body)
+		rule.LogTypes = response.Payload.LogTypes
+		rule.DedupKey = string(response.Payload.DedupKey)
+		if response.Payload.DedupPeriodMinutes != 0 {
+			dedupPeriodMinutes = int(response.Payload.DedupPeriodMinutes)
+		}
+	} else {
+		rule.Body = *input.Body
+	}
+
+	if len(input.LogTypes) > 0 {
+		rule.LogTypes = input.LogTypes
+	}
+	if len(rule.LogTypes) == 0 {
+		return nil, 0, &genericapi.InvalidInputError{Message: "logTypes is required"}
+	}
+	if input.DedupKey != nil {
+		rule.DedupKey = *input.DedupKey
+	}
+	if input.DedupPeriodMinutes != nil {
+		dedupPeriodMinutes = *input.DedupPeriodMinutes
+	}
+	return rule, time.Duration(dedupPeriodMinutes) * time.Minute, nil
+}
+
+// replay analyzes the events with the rule, in batches.
+type replay struct {
+	rule       *enginemodels.Rule
+	batch      []*historicalEvent
+	batchBytes int
+	matches    []*ruleMatch
+	output     *models.ReplayRuleOutput
+}
+
+type ruleMatch struct {
+	event *historicalEvent
+	dedup string
+}
+
+// add adds an event to the batch, the full batch is analyzed first.
+func (r *replay) add(event *historicalEvent) error {
+	if len(r.batch) == maxBatchEvents || r.batchBytes+len(event.data) > maxBatchBytes {
+		if err := r.analyze(); err != nil {
+			return err
+		}
+	}
+	r.batch = append(r.batch, event)
+	r.batchBytes += len(event.data)
+	return nil
+}
+
+// analyze runs the rule against the events of the batch.
+//
+// The rules engine is invoked in direct mode, like when a rule is tested: the matches are returned to the caller,
+// they are neither stored nor alerted on.
+func (r *replay) analyze() error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+	input := &enginemodels.RulesEngineInput{
+		Rules:  []enginemodels.Rule{*r.rule},
+		Events: make([]enginemodels.Event, len(r.batch)),
+	}
+	for i, event := range r.batch {
+		input.Events[i] = enginemodels.Event{Data: event.data, ID: strconv.Itoa(i), Type: event.logType}
+	}
+	var output enginemodels.RulesEngineOutput
+	if err := genericapi.Invoke(lambdaClient, env.RulesEngine, input, &output); err != nil {
+		return err
+	}
+
+	for _, result := range output.Events {
+		i, err := strconv.Atoi(result.ID)
+		if err != nil || i < 0 || i >= len(r.batch) {
+			return &genericapi.InternalError{Message: "unexpected event ID in the rules engine output: " + result.ID}
+		}
+		event := r.batch[i]
+		switch {
+		case len(result.Errored) > 0:
+			r.output.EventsErrored++
+			if len(r.output.Errors) < maxErrors {
+				r.output.Errors = append(r.output.Errors, &models.ReplayError{
+					EventTime: event.time,
+					Message:   result.Errored[0].Message,
+					Event:     event.data,
+				})
+			}
+		case len(result.Matched) > 0:
+			r.output.EventsMatched++
+			r.matches = append(r.matches, &ruleMatch{event: event, dedup: result.Dedup})
+		}
+	}
+
+	r.batch, r.batchBytes = nil, 0
+	return nil
+}
+
+// mergeAlerts merges the matches into alerts like the rules engine does, by the time of the events: the matches
+// with the same dedup string are merged into the alert of the first one for the dedup period, the next one
+// creates a new alert.
+func mergeAlerts(matches []*ruleMatch, dedupPeriod time.Duration) []*models.ReplayAlert {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].event.time.Before(matches[j].event.time)
+	})
+
+	alerts := []*models.ReplayAlert{}
+	openAlerts := make(map[string]*models.ReplayAlert)
+	for _, match := range matches {
+		alert := openAlerts[match.dedup]
+		if alert == nil || match.event.time.After(alert.FirstEventTime.Add(dedupPeriod)) {
+			alert = &models.ReplayAlert{Dedup: match.dedup, FirstEventTime: match.event.time}
+			openAlerts[match.dedup] = alert
+			alerts = append(alerts, alert)
+		}
+		alert.LastEventTime = match.event.time
+		alert.EventCount++
+		if len(alert.SampleEvents) < maxSampleEvents {
+			alert.SampleEvents = append(alert.SampleEvents, match.event.data)
+		}
+	}
+	return alerts
+}
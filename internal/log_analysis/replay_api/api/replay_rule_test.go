@@ -0,0 +1,313 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	enginemodels "github.com/panther-labs/panther/api/gateway/analysis"
+	analysisclient "github.com/panther-labs/panther/api/gateway/analysis/client"
+	analysismodels "github.com/panther-labs/panther/api/gateway/analysis/models"
+	"github.com/panther-labs/panther/api/lambda/replay/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+var testStart = time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+
+// fakeS3 serves the gzip JSON lines of the objects of the processed data bucket.
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string][]string
+}
+
+func (m *fakeS3) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	page := &s3.ListObjectsV2Output{}
+	for key := range m.objects {
+		if strings.HasPrefix(key, *input.Prefix) {
+			page.Contents = append(page.Contents, &s3.Object{Key: aws.String(key)})
+		}
+	}
+	sort.Slice(page.Contents, func(i, j int) bool { return *page.Contents[i].Key < *page.Contents[j].Key })
+	fn(page, true)
+	return nil
+}
+
+func (m *fakeS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	var body bytes.Buffer
+	writer := gzip.NewWriter(&body)
+	_, _ = writer.Write([]byte(strings.Join(m.objects[*input.Key], "\n") + "\n"))
+	_ = writer.Close()
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(&body)}, nil
+}
+
+// fakeRulesEngine matches the events with "match": true, their dedup string is their user.
+type fakeRulesEngine struct {
+	lambdaiface.LambdaAPI
+	inputs []*enginemodels.RulesEngineInput
+}
+
+func (m *fakeRulesEngine) Invoke(input *lambda.InvokeInput) (*lambda.InvokeOutput, error) {
+	var request struct {
+		Rules  []enginemodels.Rule `json:"rules"`
+		Events []struct {
+			ID   string `json:"id"`
+			Data struct {
+				Match bool   `json:"match"`
+				Error bool   `json:"error"`
+				User  string `json:"user"`
+			} `json:"data"`
+		} `json:"events"`
+	}
+	if err := jsoniter.Unmarshal(input.Payload, &request); err != nil {
+		return nil, err
+	}
+	m.inputs = append(m.inputs, &enginemodels.RulesEngineInput{Rules: request.Rules})
+
+	var output enginemodels.RulesEngineOutput
+	for _, event := range request.Events {
+		result := enginemodels.EventAnalysis{ID: event.ID}
+		switch {
+		case event.Data.Error:
+			result.Errored = []enginemodels.PolicyError{{ID: request.Rules[0].ID, Message: "KeyError: 'user'"}}
+		case event.Data.Match:
+			result.Matched, result.Dedup = []string{request.Rules[0].ID}, event.Data.User
+		default:
+			result.NotMatched = []string{request.Rules[0].ID}
+		}
+		output.Events = append(output.Events, result)
+	}
+	payload, err := jsoniter.Marshal(&output)
+	return &lambda.InvokeOutput{Payload: payload}, err
+}
+
+type mockRoundTripper struct {
+	http.RoundTripper
+	mock.Mock
+}
+
+func (m *mockRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	args := m.Called(request)
+	return args.Get(0).(*http.Response), args.Error(1)
+}
+
+func setupFakes(objects map[string][]string) *fakeRulesEngine {
+	rulesEngine := &fakeRulesEngine{}
+	s3Client, lambdaClient = &fakeS3{objects: objects}, rulesEngine
+	env.ProcessedDataBucket = "processed-data"
+	env.RulesEngine = "panther-rules-engine"
+	return rulesEngine
+}
+
+func testEvent(t time.Time, fields string) string {
+	return `{"p_event_time":"` + t.Format(eventTimeLayout) + `",` + fields + `}`
+}
+
+func replayInput(hours int) *models.ReplayRuleInput {
+	end := testStart.Add(time.Duration(hours) * time.Hour)
+	return &models.ReplayRuleInput{
+		Body:      aws.String("def rule(event):\n    return event['match']"),
+		LogTypes:  []string{"AWS.CloudTrail"},
+		StartTime: &testStart,
+		EndTime:   &end,
+	}
+}
+
+func TestReplayRuleNewRule(t *testing.T) {
+	rulesEngine := setupFakes(map[string][]string{
+		"logs/aws_cloudtrail/year=2020/month=05/day=01/hour=09/20200501T095900Z-1.json.gz": {
+			testEvent(testStart.Add(-time.Minute), `"match":true,"user":"alice"`), // before the range
+		},
+		"logs/aws_cloudtrail/year=2020/month=05/day=01/hour=10/20200501T100100Z-2.json.gz": {
+			testEvent(testStart.Add(time.Minute), `"match":true,"user":"alice"`),
+			testEvent(testStart.Add(2*time.Minute), `"match":false,"user":"bob"`),
+			testEvent(testStart.Add(3*time.Minute), `"match":true,"user":"bob"`),
+		},
+		"logs/aws_cloudtrail/year=2020/month=05/day=01/hour=11/20200501T110100Z-3.json.gz": {
+			testEvent(testStart.Add(30*time.Minute), `"match":true,"user":"alice"`), // processed late
+			testEvent(testStart.Add(70*time.Minute), `"match":true,"user":"alice"`),
+			testEvent(testStart.Add(80*time.Minute), `"error":true`),
+		},
+		"logs/aws_cloudtrail/year=2020/month=05/day=01/hour=11/20200501T110100Z-3.parquet": {
+			testEvent(testStart.Add(30*time.Minute), `"match":true,"user":"alice"`),
+		},
+		"logs/aws_cloudtrail/year=2020/month=05/day=01/hour=12/20200501T120100Z-4.json.gz": {
+			testEvent(testStart.Add(2*time.Hour), `"match":true,"user":"alice"`), // after the range
+		},
+	})
+
+	output, err := API{}.ReplayRule(replayInput(2))
+	require.NoError(t, err)
+	assert.Equal(t, newRuleID, output.RuleID)
+	assert.Equal(t, 6, output.EventsScanned)
+	assert.Equal(t, 4, output.EventsMatched)
+	assert.Equal(t, 1, output.EventsErrored)
+	assert.False(t, output.Truncated)
+	require.Len(t, output.Errors, 1)
+	assert.Equal(t, "KeyError: 'user'", output.Errors[0].Message)
+	assert.Equal(t, testStart.Add(80*time.Minute), output.Errors[0].EventTime)
+
+	// The third match of alice is after the dedup period of her first alert
+	require.Len(t, output.Alerts, 3)
+	assert.Equal(t, "alice", output.Alerts[0].Dedup)
+	assert.Equal(t, 2, output.Alerts[0].EventCount)
+	assert.Equal(t, testStart.Add(time.Minute), output.Alerts[0].FirstEventTime)
+	assert.Equal(t, testStart.Add(30*time.Minute), output.Alerts[0].LastEventTime)
+	assert.Len(t, output.Alerts[0].SampleEvents, 2)
+	assert.Equal(t, "bob", output.Alerts[1].Dedup)
+	assert.Equal(t, 1, output.Alerts[1].EventCount)
+	assert.Equal(t, "alice", output.Alerts[2].Dedup)
+	assert.Equal(t, testStart.Add(70*time.Minute), output.Alerts[2].FirstEventTime)
+
+	// The events were analyzed in a single batch
+	require.Len(t, rulesEngine.inputs, 1)
+	assert.Equal(t, newRuleID, rulesEngine.inputs[0].Rules[0].ID)
+	assert.Equal(t, []string{"AWS.CloudTrail"}, rulesEngine.inputs[0].Rules[0].LogTypes)
+}
+
+func TestReplayRuleTruncated(t *testing.T) {
+	setupFakes(map[string][]string{
+		"logs/aws_cloudtrail/year=2020/month=05/day=01/hour=10/20200501T100100Z-1.json.gz": {
+			testEvent(testStart.Add(time.Minute), `"match":true,"user":"alice"`),
+			testEvent(testStart.Add(2*time.Minute), `"match":true,"user":"alice"`),
+			testEvent(testStart.Add(3*time.Minute), `"match":true,"user":"alice"`),
+		},
+	})
+	input := replayInput(1)
+	input.MaxEvents = aws.Int(2)
+
+	output, err := API{}.ReplayRule(input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, output.EventsScanned)
+	assert.True(t, output.Truncated)
+	require.Len(t, output.Alerts, 1)
+	assert.Equal(t, 2, output.Alerts[0].EventCount)
+}
+
+func TestReplayRuleBatches(t *testing.T) {
+	var events []string
+	for i := 0; i < maxBatchEvents+1; i++ {
+		events = append(events, testEvent(testStart.Add(time.Second), `"match":false`))
+	}
+	rulesEngine := setupFakes(map[string][]string{
+		"logs/aws_cloudtrail/year=2020/month=05/day=01/hour=10/20200501T100100Z-1.json.gz": events,
+	})
+
+	output, err := API{}.ReplayRule(replayInput(1))
+	require.NoError(t, err)
+	assert.Equal(t, maxBatchEvents+1, output.EventsScanned)
+	assert.Len(t, rulesEngine.inputs, 2)
+	assert.Empty(t, output.Alerts)
+}
+
+func TestReplayRuleExistingRule(t *testing.T) {
+	rulesEngine := setupFakes(map[string][]string{})
+	roundTripper := &mockRoundTripper{}
+	httpClient = &http.Client{Transport: roundTripper}
+	analysisClient = analysisclient.NewHTTPClientWithConfig(nil, analysisclient.DefaultTransportConfig().
+		WithHost("host").
+		WithBasePath("path"))
+	rule, err := jsoniter.MarshalToString(&analysismodels.Rule{
+		Body:               "def rule(event):\n    return True",
+		DedupKey:           "{user}",
+		DedupPeriodMinutes: 15,
+		ID:                 "AWS.CloudTrail.Denied",
+		LogTypes:           []string{"AWS.CloudTrail"},
+	})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		roundTripper.On("RoundTrip", mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(rule)),
+		}, nil).Once()
+	}
+
+	input := replayInput(1)
+	input.Body, input.LogTypes = nil, nil
+	input.RuleID = aws.String("AWS.CloudTrail.Denied")
+	output, err := API{}.ReplayRule(input)
+	require.NoError(t, err)
+	assert.Equal(t, "AWS.CloudTrail.Denied", output.RuleID)
+	assert.Equal(t, []string{"AWS.CloudTrail"}, output.LogTypes)
+	assert.Equal(t, 0, output.EventsScanned)
+	assert.Empty(t, rulesEngine.inputs)
+
+	replayed, dedupPeriod, err := getReplayedRule(input)
+	require.NoError(t, err)
+	assert.Equal(t, "{user}", replayed.DedupKey)
+	assert.Equal(t, 15*time.Minute, dedupPeriod)
+
+	roundTripper.On("RoundTrip", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+	}, nil).Once()
+	_, err = API{}.ReplayRule(input)
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	roundTripper.AssertExpectations(t)
+}
+
+func TestReplayRuleInvalidInput(t *testing.T) {
+	setupFakes(map[string][]string{})
+
+	input := replayInput(1)
+	input.RuleID = aws.String("AWS.CloudTrail.Denied")
+	_, err := API{}.ReplayRule(input)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	input = replayInput(1)
+	input.LogTypes = nil
+	_, err = API{}.ReplayRule(input)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	_, err = API{}.ReplayRule(replayInput(0))
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	_, err = API{}.ReplayRule(replayInput(32 * 24))
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestMergeAlertsDedupPeriod(t *testing.T) {
+	match := func(minutes int, dedup string) *ruleMatch {
+		event := &historicalEvent{time: testStart.Add(time.Duration(minutes) * time.Minute), data: []byte(`{}`)}
+		return &ruleMatch{event: event, dedup: dedup}
+	}
+	// The matches are merged by the time of their events, not their order
+	alerts := mergeAlerts([]*ruleMatch{match(16, "a"), match(0, "a"), match(15, "a"), match(5, "b")}, 15*time.Minute)
+	require.Len(t, alerts, 3)
+	assert.Equal(t, 2, alerts[0].EventCount)
+	assert.Equal(t, testStart.Add(15*time.Minute), alerts[0].LastEventTime)
+	assert.Equal(t, "b", alerts[1].Dedup)
+	assert.Equal(t, testStart.Add(16*time.Minute), alerts[2].FirstEventTime)
+}
@@ -0,0 +1,64 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/panther-labs/panther/internal/log_analysis/retention_api/table"
+)
+
+// API has all of the handlers as receiver methods.
+type API struct{}
+
+var (
+	env           envConfig
+	awsSession    *session.Session
+	policiesTable table.API
+	glueClient    glueiface.GlueAPI
+	s3Client      s3iface.S3API
+
+	nowFunc = time.Now
+)
+
+type envConfig struct {
+	RetentionPoliciesTableName string `required:"true" split_words:"true"`
+	ProcessedDataBucket        string `required:"true" split_words:"true"`
+}
+
+// Setup parses the environment and builds the AWS clients.
+func Setup() {
+	envconfig.MustProcess("", &env)
+
+	awsSession = session.Must(session.NewSession())
+	policiesTable = &table.PoliciesTable{
+		Name:   env.RetentionPoliciesTableName,
+		Client: dynamodb.New(awsSession),
+	}
+	glueClient = glue.New(awsSession)
+	s3Client = s3.New(awsSession)
+}
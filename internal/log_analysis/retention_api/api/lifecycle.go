@@ -0,0 +1,146 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"go.uber.org/zap"
+
+	logprocessormodels "github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/internal/log_analysis/retention_api/table"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	// The lifecycle rules of the retention policies, the other rules of the bucket are kept as they are
+	lifecycleRuleIDPrefix = "panther-retention-"
+
+	// The most lifecycle rules a bucket can have
+	maxLifecycleRules = 1000
+
+	// The prefixes of the objects of the Glue tables: the events, tagged with the ID of their source when it's known,
+	// and their rule matches
+	logsPrefix        = "logs/"
+	ruleMatchesPrefix = "rules/"
+)
+
+// syncLifecycleRules replaces the lifecycle rules of the retention policies in the processed data bucket.
+//
+// The other rules of the bucket, such as the one of the staging objects defined with the bucket, are kept.
+// It returns the number of lifecycle rules of the policies.
+func syncLifecycleRules(policies []*models.RetentionPolicy) (int, error) {
+	rules := retentionRules(policies)
+
+	output, err := s3Client.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(env.ProcessedDataBucket),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != "NoSuchLifecycleConfiguration" {
+			return 0, &genericapi.AWSError{Err: err, Method: "s3.GetBucketLifecycleConfiguration"}
+		}
+		output = &s3.GetBucketLifecycleConfigurationOutput{}
+	}
+
+	allRules := make([]*s3.LifecycleRule, 0, len(output.Rules)+len(rules))
+	for _, rule := range output.Rules {
+		if strings.HasPrefix(aws.StringValue(rule.ID), lifecycleRuleIDPrefix) {
+			continue
+		}
+		// A configuration can't mix the rules with a prefix and those with a filter
+		if rule.Prefix != nil {
+			rule.Filter = &s3.LifecycleRuleFilter{Prefix: rule.Prefix}
+			rule.Prefix = nil
+		}
+		allRules = append(allRules, rule)
+	}
+	allRules = append(allRules, rules...)
+	if len(allRules) > maxLifecycleRules {
+		return 0, &genericapi.InvalidInputError{
+			Message: "too many retention policies: the bucket can't have more than " +
+				strconv.Itoa(maxLifecycleRules) + " lifecycle rules"}
+	}
+
+	if len(allRules) == 0 {
+		_, err = s3Client.DeleteBucketLifecycle(&s3.DeleteBucketLifecycleInput{Bucket: aws.String(env.ProcessedDataBucket)})
+		if err != nil {
+			return 0, &genericapi.AWSError{Err: err, Method: "s3.DeleteBucketLifecycle"}
+		}
+		return 0, nil
+	}
+	_, err = s3Client.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(env.ProcessedDataBucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: allRules},
+	})
+	if err != nil {
+		return 0, &genericapi.AWSError{Err: err, Method: "s3.PutBucketLifecycleConfiguration"}
+	}
+	zap.L().Debug("updated the lifecycle rules", zap.Int("retentionRules", len(rules)), zap.Int("rules", len(allRules)))
+	return len(rules), nil
+}
+
+// retentionRules returns the lifecycle rules of the policies, sorted by ID.
+//
+// The policy of a log type expires the objects of its Glue tables, both its events and their rule matches,
+// and the policy of a source expires the objects of its events. When the rules of a log type and of a source
+// apply to the same object, S3 expires it on the earliest date.
+func retentionRules(policies []*models.RetentionPolicy) []*s3.LifecycleRule {
+	rules := make([]*s3.LifecycleRule, 0, len(policies))
+	for _, policy := range policies {
+		days := aws.Int64(int64(aws.IntValue(policy.RetentionDays)))
+		if policy.SourceID != nil {
+			rules = append(rules, expirationRule(table.PolicyID(policy), days, &s3.LifecycleRuleFilter{
+				And: &s3.LifecycleRuleAndOperator{
+					Prefix: aws.String(logsPrefix),
+					Tags:   []*s3.Tag{{Key: aws.String(logprocessormodels.SourceIDTagKey), Value: policy.SourceID}},
+				},
+			}))
+			continue
+		}
+		for _, dataType := range []logprocessormodels.DataType{logprocessormodels.LogData, logprocessormodels.RuleData} {
+			prefix := tablePrefix(dataType, *policy.LogType)
+			rules = append(rules, expirationRule(prefix, days, &s3.LifecycleRuleFilter{Prefix: aws.String(prefix)}))
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return *rules[i].ID < *rules[j].ID })
+	return rules
+}
+
+// expirationRule returns a rule expiring the objects of the filter after the days, their previous versions a day later.
+func expirationRule(name string, days *int64, filter *s3.LifecycleRuleFilter) *s3.LifecycleRule {
+	return &s3.LifecycleRule{
+		ID:                          aws.String(lifecycleRuleIDPrefix + name),
+		Expiration:                  &s3.LifecycleExpiration{Days: days},
+		Filter:                      filter,
+		NoncurrentVersionExpiration: &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(1)},
+		Status:                      aws.String(s3.ExpirationStatusEnabled),
+	}
+}
+
+// tablePrefix returns the prefix of the objects of the Glue table of a log type, e.g. "logs/aws_cloudtrail/".
+func tablePrefix(dataType logprocessormodels.DataType, logType string) string {
+	return awsglue.NewGlueTableMetadata(dataType, logType, "", awsglue.GlueTableHourly, nil).Prefix()
+}
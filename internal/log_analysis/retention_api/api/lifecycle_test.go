@@ -0,0 +1,107 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestRetentionRules(t *testing.T) {
+	rules := retentionRules([]*models.RetentionPolicy{
+		{SourceID: aws.String(testSourceID), RetentionDays: aws.Int(7)},
+		{LogType: aws.String("AWS.CloudTrail"), RetentionDays: aws.Int(365)},
+	})
+
+	expiration := func(days int64) *s3.LifecycleExpiration { return &s3.LifecycleExpiration{Days: aws.Int64(days)} }
+	noncurrent := &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(1)}
+	assert.Equal(t, []*s3.LifecycleRule{
+		{
+			ID:                          aws.String("panther-retention-logs/aws_cloudtrail/"),
+			Expiration:                  expiration(365),
+			Filter:                      &s3.LifecycleRuleFilter{Prefix: aws.String("logs/aws_cloudtrail/")},
+			NoncurrentVersionExpiration: noncurrent,
+			Status:                      aws.String("Enabled"),
+		},
+		{
+			ID:                          aws.String("panther-retention-rules/aws_cloudtrail/"),
+			Expiration:                  expiration(365),
+			Filter:                      &s3.LifecycleRuleFilter{Prefix: aws.String("rules/aws_cloudtrail/")},
+			NoncurrentVersionExpiration: noncurrent,
+			Status:                      aws.String("Enabled"),
+		},
+		{
+			ID:         aws.String("panther-retention-source/" + testSourceID),
+			Expiration: expiration(7),
+			Filter: &s3.LifecycleRuleFilter{And: &s3.LifecycleRuleAndOperator{
+				Prefix: aws.String("logs/"),
+				Tags:   []*s3.Tag{{Key: aws.String("panther-source-id"), Value: aws.String(testSourceID)}},
+			}},
+			NoncurrentVersionExpiration: noncurrent,
+			Status:                      aws.String("Enabled"),
+		},
+	}, rules)
+}
+
+func TestSyncLifecycleRulesKeepsOtherRules(t *testing.T) {
+	_, mockS3Client, _ := setupMocks()
+	staging := &s3.LifecycleRule{
+		ID:         aws.String("staging"),
+		Expiration: &s3.LifecycleExpiration{Days: aws.Int64(1)},
+		Prefix:     aws.String("staging/"),
+		Status:     aws.String("Enabled"),
+	}
+	previous := &s3.LifecycleRule{ID: aws.String("panther-retention-logs/aws_vpcflow/")}
+	rules := mockLifecycle(mockS3Client, staging, previous)
+
+	count, err := syncLifecycleRules([]*models.RetentionPolicy{{SourceID: aws.String(testSourceID), RetentionDays: aws.Int(7)}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// The staging rule is kept with a filter, the rule of the deleted policy is removed
+	require.Len(t, *rules, 2)
+	assert.Equal(t, "staging", *(*rules)[0].ID)
+	assert.Nil(t, (*rules)[0].Prefix)
+	assert.Equal(t, "staging/", *(*rules)[0].Filter.Prefix)
+	assert.Equal(t, "panther-retention-source/"+testSourceID, *(*rules)[1].ID)
+	mockS3Client.AssertExpectations(t)
+}
+
+func TestSyncLifecycleRulesTooMany(t *testing.T) {
+	_, mockS3Client, _ := setupMocks()
+	mockS3Client.On("GetBucketLifecycleConfiguration", mock.Anything).Return(
+		&s3.GetBucketLifecycleConfigurationOutput{}, nil).Once()
+
+	policies := make([]*models.RetentionPolicy, maxLifecycleRules/2+1)
+	for i := range policies {
+		policies[i] = &models.RetentionPolicy{LogType: aws.String("Custom.Type" + strconv.Itoa(i)), RetentionDays: aws.Int(30)}
+	}
+	_, err := syncLifecycleRules(policies)
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	mockS3Client.AssertNotCalled(t, "PutBucketLifecycleConfiguration", mock.Anything)
+}
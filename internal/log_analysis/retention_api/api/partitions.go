@@ -0,0 +1,153 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The most partitions a BatchDeletePartition request can delete
+const maxDeletePartitions = 25
+
+// ApplyRetention updates the lifecycle rules of the retention policies and drops the expired Glue partitions.
+//
+// The rules are applied again every run, since updating the stack of the bucket replaces its lifecycle rules.
+// A partition of a log type is dropped once all of its hours are older than the retention of the log type.
+func (API) ApplyRetention(_ *models.ApplyRetentionInput) (*models.ApplyRetentionOutput, error) {
+	policies, err := policiesTable.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := syncLifecycleRules(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	retention := logTypeRetention(policies)
+	logTypes := make([]string, 0, len(retention))
+	for logType := range retention {
+		logTypes = append(logTypes, logType)
+	}
+	sort.Strings(logTypes)
+
+	// A failing table doesn't prevent dropping the partitions of the others, the next run retries it
+	now := nowFunc().UTC()
+	dropped := 0
+	var dropErr error
+	for _, logType := range logTypes {
+		cutoff := now.AddDate(0, 0, -retention[logType])
+		tableName := awsglue.GetTableName(logType)
+		for _, database := range []string{awsglue.LogProcessingDatabaseName, awsglue.RuleMatchDatabaseName} {
+			count, err := dropExpiredPartitions(database, tableName, cutoff)
+			dropped += count
+			if err != nil {
+				zap.L().Error("failed to drop the expired partitions",
+					zap.String("database", database), zap.String("table", tableName), zap.Error(err))
+				dropErr = err
+			}
+		}
+	}
+	if dropErr != nil {
+		return nil, dropErr
+	}
+
+	zap.L().Info("applied the retention policies", zap.Int("lifecycleRules", rules), zap.Int("droppedPartitions", dropped))
+	return &models.ApplyRetentionOutput{LifecycleRules: &rules, DroppedPartitions: &dropped}, nil
+}
+
+// dropExpiredPartitions drops the partitions of a table which end before the cutoff, a missing table has none.
+func dropExpiredPartitions(database, tableName string, cutoff time.Time) (int, error) {
+	var expired []*glue.PartitionValueList
+	err := glueClient.GetPartitionsPages(&glue.GetPartitionsInput{
+		DatabaseName: aws.String(database),
+		TableName:    aws.String(tableName),
+	}, func(page *glue.GetPartitionsOutput, lastPage bool) bool {
+		for _, partition := range page.Partitions {
+			end, ok := partitionEnd(partition.Values)
+			if ok && !end.After(cutoff) {
+				expired = append(expired, &glue.PartitionValueList{Values: partition.Values})
+			}
+		}
+		return true
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == glue.ErrCodeEntityNotFoundException {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, &genericapi.AWSError{Err: err, Method: "glue.GetPartitions"}
+	}
+
+	dropped := 0
+	for start := 0; start < len(expired); start += maxDeletePartitions {
+		end := start + maxDeletePartitions
+		if end > len(expired) {
+			end = len(expired)
+		}
+		output, err := glueClient.BatchDeletePartition(&glue.BatchDeletePartitionInput{
+			DatabaseName:       aws.String(database),
+			TableName:          aws.String(tableName),
+			PartitionsToDelete: expired[start:end],
+		})
+		if err != nil {
+			return dropped, &genericapi.AWSError{Err: err, Method: "glue.BatchDeletePartition"}
+		}
+		dropped += end - start - len(output.Errors)
+		if len(output.Errors) > 0 {
+			return dropped, errors.Errorf("failed to delete %d partitions of %s.%s: %s", len(output.Errors),
+				database, tableName, output.Errors[0])
+		}
+	}
+	if dropped > 0 {
+		zap.L().Info("dropped the expired partitions",
+			zap.String("database", database), zap.String("table", tableName), zap.Int("partitions", dropped))
+	}
+	return dropped, nil
+}
+
+// partitionEnd returns the end of the time range of a partition from its year, month, day and hour values.
+//
+// The partitions which aren't time ranges are never dropped.
+func partitionEnd(values []*string) (time.Time, bool) {
+	if len(values) < 2 || len(values) > 4 {
+		return time.Time{}, false
+	}
+	fields := []int{0, 1, 1, 0} // year, month, day, hour
+	for i, value := range values {
+		number, err := strconv.Atoi(aws.StringValue(value))
+		if err != nil {
+			return time.Time{}, false
+		}
+		fields[i] = number
+	}
+	start := time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], 0, 0, 0, time.UTC)
+	// One value for each partition key after the year: monthly, daily then hourly
+	return awsglue.GlueTableTimebin(len(values) - 1).Next(start), true
+}
@@ -0,0 +1,110 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+)
+
+func partition(values ...string) *glue.Partition {
+	return &glue.Partition{Values: aws.StringSlice(values)}
+}
+
+func TestPartitionEnd(t *testing.T) {
+	end, ok := partitionEnd(aws.StringSlice([]string{"2020", "05", "14", "21"}))
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2020, 5, 14, 22, 0, 0, 0, time.UTC), end)
+
+	end, ok = partitionEnd(aws.StringSlice([]string{"2020", "05", "14"}))
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2020, 5, 15, 0, 0, 0, 0, time.UTC), end)
+
+	end, ok = partitionEnd(aws.StringSlice([]string{"2020", "12"}))
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	_, ok = partitionEnd(aws.StringSlice([]string{"2020"}))
+	assert.False(t, ok)
+	_, ok = partitionEnd(aws.StringSlice([]string{"2020", "may"}))
+	assert.False(t, ok)
+}
+
+func TestApplyRetention(t *testing.T) {
+	mockPolicies, mockS3Client, mockGlueClient := setupMocks()
+	mockPolicies.On("ListPolicies").Return([]*models.RetentionPolicy{
+		{LogType: aws.String("AWS.CloudTrail"), RetentionDays: aws.Int(30)},
+		{SourceID: aws.String(testSourceID), RetentionDays: aws.Int(7)},
+	}, nil).Once()
+	mockLifecycle(mockS3Client)
+
+	// The cutoff is 2020-04-14T21:37:42Z: only the hours which ended before it have expired
+	mockGlueClient.On("GetPartitionsPages", &glue.GetPartitionsInput{
+		DatabaseName: aws.String("panther_logs"),
+		TableName:    aws.String("aws_cloudtrail"),
+	}, mock.Anything).Return(&glue.GetPartitionsOutput{Partitions: []*glue.Partition{
+		partition("2020", "04", "14", "20"),
+		partition("2020", "04", "14", "21"),
+		partition("2020", "05", "14", "21"),
+	}}, nil).Once()
+	mockGlueClient.On("GetPartitionsPages", &glue.GetPartitionsInput{
+		DatabaseName: aws.String("panther_rule_matches"),
+		TableName:    aws.String("aws_cloudtrail"),
+	}, mock.Anything).Return(&glue.GetPartitionsOutput{},
+		awserr.New(glue.ErrCodeEntityNotFoundException, "no table", nil)).Once()
+	mockGlueClient.On("BatchDeletePartition", &glue.BatchDeletePartitionInput{
+		DatabaseName: aws.String("panther_logs"),
+		TableName:    aws.String("aws_cloudtrail"),
+		PartitionsToDelete: []*glue.PartitionValueList{
+			{Values: aws.StringSlice([]string{"2020", "04", "14", "20"})},
+		},
+	}).Return(&glue.BatchDeletePartitionOutput{}, nil).Once()
+
+	result, err := (API{}).ApplyRetention(&models.ApplyRetentionInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.ApplyRetentionOutput{LifecycleRules: aws.Int(3), DroppedPartitions: aws.Int(1)}, result)
+	mockPolicies.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+	mockGlueClient.AssertExpectations(t)
+}
+
+func TestDropExpiredPartitionsInBatches(t *testing.T) {
+	_, _, mockGlueClient := setupMocks()
+	partitions := make([]*glue.Partition, maxDeletePartitions+1)
+	for i := range partitions {
+		partitions[i] = partition("2019", "01", "01", "00")
+	}
+	mockGlueClient.On("GetPartitionsPages", mock.Anything, mock.Anything).Return(
+		&glue.GetPartitionsOutput{Partitions: partitions}, nil).Once()
+	mockGlueClient.On("BatchDeletePartition", mock.Anything).Return(&glue.BatchDeletePartitionOutput{}, nil).Twice()
+
+	dropped, err := dropExpiredPartitions("panther_logs", "aws_cloudtrail", testTime)
+	require.NoError(t, err)
+	assert.Equal(t, maxDeletePartitions+1, dropped)
+	mockGlueClient.AssertExpectations(t)
+}
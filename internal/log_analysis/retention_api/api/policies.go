@@ -0,0 +1,128 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/internal/log_analysis/retention_api/table"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// PutRetentionPolicy creates or updates the retention policy of a log type or of a source.
+//
+// The lifecycle rules of the bucket are updated right away, the Glue partitions are dropped by the next ApplyRetention.
+func (API) PutRetentionPolicy(input *models.PutRetentionPolicyInput) (*models.PutRetentionPolicyOutput, error) {
+	policy, err := policyTarget(input.LogType, input.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	now := nowFunc().UTC()
+	policy.RetentionDays = input.RetentionDays
+	policy.LastModified = &now
+	policy.LastModifiedBy = input.UserID
+
+	policies, err := policiesTable.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	policies = append(withoutPolicy(policies, table.PolicyID(policy)), policy)
+	// The policy is only stored if the bucket has room for its lifecycle rules
+	if _, err = syncLifecycleRules(policies); err != nil {
+		return nil, err
+	}
+	if err = policiesTable.PutPolicy(policy); err != nil {
+		return nil, err
+	}
+	zap.L().Info("put retention policy",
+		zap.String("policyId", table.PolicyID(policy)), zap.Int("retentionDays", *policy.RetentionDays))
+	return policy, nil
+}
+
+// GetRetentionPolicy returns the retention policy of a log type or of a source.
+func (API) GetRetentionPolicy(input *models.GetRetentionPolicyInput) (*models.GetRetentionPolicyOutput, error) {
+	target, err := policyTarget(input.LogType, input.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := policiesTable.GetPolicy(table.PolicyID(target))
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "retention policy " + table.PolicyID(target) + " does not exist"}
+	}
+	return policy, nil
+}
+
+// ListRetentionPolicies returns all the retention policies.
+func (API) ListRetentionPolicies(_ *models.ListRetentionPoliciesInput) (models.ListRetentionPoliciesOutput, error) {
+	return policiesTable.ListPolicies()
+}
+
+// DeleteRetentionPolicy deletes the retention policy of a log type or of a source, along with its lifecycle rules.
+func (API) DeleteRetentionPolicy(input *models.DeleteRetentionPolicyInput) error {
+	target, err := policyTarget(input.LogType, input.SourceID)
+	if err != nil {
+		return err
+	}
+	policyID := table.PolicyID(target)
+	if err = policiesTable.DeletePolicy(policyID); err != nil {
+		return err
+	}
+
+	policies, err := policiesTable.ListPolicies()
+	if err != nil {
+		return err
+	}
+	_, err = syncLifecycleRules(withoutPolicy(policies, policyID))
+	return err
+}
+
+// policyTarget returns a policy of either a log type or a source.
+func policyTarget(logType, sourceID *string) (*models.RetentionPolicy, error) {
+	if (logType == nil) == (sourceID == nil) {
+		return nil, &genericapi.InvalidInputError{Message: "either a logType or a sourceId is required"}
+	}
+	return &models.RetentionPolicy{LogType: logType, SourceID: sourceID}, nil
+}
+
+// withoutPolicy returns the policies without the one with the given ID.
+func withoutPolicy(policies []*models.RetentionPolicy, policyID string) []*models.RetentionPolicy {
+	result := make([]*models.RetentionPolicy, 0, len(policies))
+	for _, policy := range policies {
+		if table.PolicyID(policy) != policyID {
+			result = append(result, policy)
+		}
+	}
+	return result
+}
+
+// logTypeRetention returns the retention days of each log type with a policy.
+func logTypeRetention(policies []*models.RetentionPolicy) map[string]int {
+	result := make(map[string]int)
+	for _, policy := range policies {
+		if policy.LogType != nil {
+			result[*policy.LogType] = aws.IntValue(policy.RetentionDays)
+		}
+	}
+	return result
+}
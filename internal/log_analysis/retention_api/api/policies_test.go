@@ -0,0 +1,202 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testUserID   = "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+	testSourceID = "45c378a7-2e36-4b12-8e16-2d3c49ff1371"
+)
+
+var testTime = time.Date(2020, 5, 14, 21, 37, 42, 0, time.UTC)
+
+type mockTable struct {
+	mock.Mock
+}
+
+func (m *mockTable) GetPolicy(policyID string) (*models.RetentionPolicy, error) {
+	args := m.Called(policyID)
+	policy, _ := args.Get(0).(*models.RetentionPolicy)
+	return policy, args.Error(1)
+}
+
+func (m *mockTable) ListPolicies() ([]*models.RetentionPolicy, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.RetentionPolicy), args.Error(1)
+}
+
+func (m *mockTable) PutPolicy(policy *models.RetentionPolicy) error {
+	return m.Called(policy).Error(0)
+}
+
+func (m *mockTable) DeletePolicy(policyID string) error {
+	return m.Called(policyID).Error(0)
+}
+
+type mockS3 struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (m *mockS3) GetBucketLifecycleConfiguration(
+	input *s3.GetBucketLifecycleConfigurationInput) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*s3.GetBucketLifecycleConfigurationOutput), args.Error(1)
+}
+
+func (m *mockS3) PutBucketLifecycleConfiguration(
+	input *s3.PutBucketLifecycleConfigurationInput) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+
+	args := m.Called(input)
+	return args.Get(0).(*s3.PutBucketLifecycleConfigurationOutput), args.Error(1)
+}
+
+func (m *mockS3) DeleteBucketLifecycle(input *s3.DeleteBucketLifecycleInput) (*s3.DeleteBucketLifecycleOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.DeleteBucketLifecycleOutput), args.Error(1)
+}
+
+func (m *mockS3) ListObjectsV2Pages(input *s3.ListObjectsV2Input, handler func(*s3.ListObjectsV2Output, bool) bool) error {
+	args := m.Called(input, handler)
+	handler(args.Get(0).(*s3.ListObjectsV2Output), true)
+	return args.Error(1)
+}
+
+type mockGlue struct {
+	glueiface.GlueAPI
+	mock.Mock
+}
+
+func (m *mockGlue) GetPartitionsPages(input *glue.GetPartitionsInput, handler func(*glue.GetPartitionsOutput, bool) bool) error {
+	args := m.Called(input, handler)
+	handler(args.Get(0).(*glue.GetPartitionsOutput), true)
+	return args.Error(1)
+}
+
+func (m *mockGlue) BatchDeletePartition(input *glue.BatchDeletePartitionInput) (*glue.BatchDeletePartitionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.BatchDeletePartitionOutput), args.Error(1)
+}
+
+func setupMocks() (*mockTable, *mockS3, *mockGlue) {
+	mockPolicies, mockS3Client, mockGlueClient := &mockTable{}, &mockS3{}, &mockGlue{}
+	policiesTable, s3Client, glueClient = mockPolicies, mockS3Client, mockGlueClient
+	env.ProcessedDataBucket = "processed-data"
+	nowFunc = func() time.Time { return testTime }
+	return mockPolicies, mockS3Client, mockGlueClient
+}
+
+// mockLifecycle returns the lifecycle rules put in the bucket, which has the given rules.
+func mockLifecycle(mockS3Client *mockS3, rules ...*s3.LifecycleRule) *[]*s3.LifecycleRule {
+	put := new([]*s3.LifecycleRule)
+	mockS3Client.On("GetBucketLifecycleConfiguration", mock.Anything).Return(
+		&s3.GetBucketLifecycleConfigurationOutput{Rules: rules}, nil).Once()
+	mockS3Client.On("PutBucketLifecycleConfiguration", mock.Anything).Return(
+		&s3.PutBucketLifecycleConfigurationOutput{}, nil).Once().Run(func(args mock.Arguments) {
+		*put = args.Get(0).(*s3.PutBucketLifecycleConfigurationInput).LifecycleConfiguration.Rules
+	})
+	return put
+}
+
+func TestPutRetentionPolicy(t *testing.T) {
+	mockPolicies, mockS3Client, _ := setupMocks()
+	mockPolicies.On("ListPolicies").Return([]*models.RetentionPolicy{
+		{LogType: aws.String("AWS.CloudTrail"), RetentionDays: aws.Int(30)},
+		{SourceID: aws.String(testSourceID), RetentionDays: aws.Int(7)},
+	}, nil).Once()
+	mockPolicies.On("PutPolicy", mock.Anything).Return(nil).Once()
+	rules := mockLifecycle(mockS3Client)
+
+	result, err := (API{}).PutRetentionPolicy(&models.PutRetentionPolicyInput{
+		LogType:       aws.String("AWS.CloudTrail"),
+		RetentionDays: aws.Int(365),
+		UserID:        aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.RetentionPolicy{
+		LogType:        aws.String("AWS.CloudTrail"),
+		RetentionDays:  aws.Int(365),
+		LastModified:   &testTime,
+		LastModifiedBy: aws.String(testUserID),
+	}, result)
+
+	// The updated policy replaces the previous one of the log type
+	require.Len(t, *rules, 3)
+	assert.Equal(t, "panther-retention-logs/aws_cloudtrail/", *(*rules)[0].ID)
+	assert.Equal(t, int64(365), *(*rules)[0].Expiration.Days)
+	mockPolicies.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+}
+
+func TestPutRetentionPolicyTarget(t *testing.T) {
+	setupMocks()
+	_, err := (API{}).PutRetentionPolicy(&models.PutRetentionPolicyInput{
+		RetentionDays: aws.Int(365),
+		UserID:        aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	_, err = (API{}).PutRetentionPolicy(&models.PutRetentionPolicyInput{
+		LogType:       aws.String("AWS.CloudTrail"),
+		SourceID:      aws.String(testSourceID),
+		RetentionDays: aws.Int(365),
+		UserID:        aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
+
+func TestGetRetentionPolicyDoesNotExist(t *testing.T) {
+	mockPolicies, _, _ := setupMocks()
+	mockPolicies.On("GetPolicy", "source/"+testSourceID).Return(nil, nil).Once()
+
+	_, err := (API{}).GetRetentionPolicy(&models.GetRetentionPolicyInput{SourceID: aws.String(testSourceID)})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockPolicies.AssertExpectations(t)
+}
+
+func TestDeleteRetentionPolicy(t *testing.T) {
+	mockPolicies, mockS3Client, _ := setupMocks()
+	mockPolicies.On("DeletePolicy", "logType/AWS.CloudTrail").Return(nil).Once()
+	mockPolicies.On("ListPolicies").Return([]*models.RetentionPolicy{}, nil).Once()
+	mockS3Client.On("GetBucketLifecycleConfiguration", mock.Anything).Return(
+		(*s3.GetBucketLifecycleConfigurationOutput)(nil), awserr.New("NoSuchLifecycleConfiguration", "none", nil)).Once()
+	mockS3Client.On("DeleteBucketLifecycle", mock.Anything).Return(&s3.DeleteBucketLifecycleOutput{}, nil).Once()
+
+	require.NoError(t, (API{}).DeleteRetentionPolicy(&models.DeleteRetentionPolicyInput{LogType: aws.String("AWS.CloudTrail")}))
+	mockPolicies.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+}
@@ -0,0 +1,129 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetStorageUsage lists the objects of the Glue tables in the processed data bucket and sums their sizes per log type.
+func (API) GetStorageUsage(_ *models.GetStorageUsageInput) (*models.GetStorageUsageOutput, error) {
+	policies, err := policiesTable.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+	retention := logTypeRetention(policies)
+
+	// Table name -> log type, for the native log types and those of the policies
+	logTypes := make(map[string]string)
+	for _, table := range registry.AvailableTables() {
+		logTypes[table.TableName()] = table.LogType()
+	}
+	for logType := range retention {
+		logTypes[awsglue.GetTableName(logType)] = logType
+	}
+
+	usage := make(map[string]*models.LogTypeUsage)
+	tableUsage := func(tableName string) *models.LogTypeUsage {
+		if result, ok := usage[tableName]; ok {
+			return result
+		}
+		result := &models.LogTypeUsage{
+			TableName:          aws.String(tableName),
+			LogsBytes:          aws.Int64(0),
+			LogsObjects:        aws.Int64(0),
+			RuleMatchesBytes:   aws.Int64(0),
+			RuleMatchesObjects: aws.Int64(0),
+		}
+		if logType, ok := logTypes[tableName]; ok {
+			result.LogType = aws.String(logType)
+			if days, ok := retention[logType]; ok {
+				result.RetentionDays = aws.Int(days)
+			}
+		}
+		usage[tableName] = result
+		return result
+	}
+
+	err = listTableObjects(logsPrefix, func(tableName string, size int64) {
+		result := tableUsage(tableName)
+		*result.LogsBytes += size
+		*result.LogsObjects++
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = listTableObjects(ruleMatchesPrefix, func(tableName string, size int64) {
+		result := tableUsage(tableName)
+		*result.RuleMatchesBytes += size
+		*result.RuleMatchesObjects++
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &models.GetStorageUsageOutput{LogTypes: make([]*models.LogTypeUsage, 0, len(usage)), TotalBytes: aws.Int64(0)}
+	for _, result := range usage {
+		output.LogTypes = append(output.LogTypes, result)
+		*output.TotalBytes += usageBytes(result)
+	}
+	sort.Slice(output.LogTypes, func(i, j int) bool {
+		left, right := output.LogTypes[i], output.LogTypes[j]
+		if usageBytes(left) != usageBytes(right) {
+			return usageBytes(left) > usageBytes(right)
+		}
+		return *left.TableName < *right.TableName
+	})
+	return output, nil
+}
+
+// listTableObjects calls the handler with the table name and size of each object under the prefix of the tables.
+func listTableObjects(prefix string, handler func(tableName string, size int64)) error {
+	err := s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(env.ProcessedDataBucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			// e.g. logs/aws_cloudtrail/year=2020/month=05/day=01/hour=00/20200501T000102Z-uuid.json.gz
+			parts := strings.SplitN(strings.TrimPrefix(aws.StringValue(object.Key), prefix), "/", 2)
+			if len(parts) < 2 || parts[0] == "" {
+				continue
+			}
+			handler(parts[0], aws.Int64Value(object.Size))
+		}
+		return true
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "s3.ListObjectsV2Pages"}
+	}
+	return nil
+}
+
+func usageBytes(usage *models.LogTypeUsage) int64 {
+	return *usage.LogsBytes + *usage.RuleMatchesBytes
+}
@@ -0,0 +1,89 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+)
+
+func object(key string, size int64) *s3.Object {
+	return &s3.Object{Key: aws.String(key), Size: aws.Int64(size)}
+}
+
+func TestGetStorageUsage(t *testing.T) {
+	mockPolicies, mockS3Client, _ := setupMocks()
+	mockPolicies.On("ListPolicies").Return([]*models.RetentionPolicy{
+		{LogType: aws.String("AWS.CloudTrail"), RetentionDays: aws.Int(30)},
+	}, nil).Once()
+	mockS3Client.On("ListObjectsV2Pages", &s3.ListObjectsV2Input{
+		Bucket: aws.String("processed-data"),
+		Prefix: aws.String("logs/"),
+	}, mock.Anything).Return(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+		object("logs/aws_cloudtrail/year=2020/month=05/day=14/hour=21/20200514T213742Z-uuid.json.gz", 100),
+		object("logs/aws_cloudtrail/year=2020/month=05/day=14/hour=22/20200514T223742Z-uuid.json.gz", 200),
+		object("logs/removed_type/year=2020/month=05/day=14/hour=21/20200514T213742Z-uuid.json.gz", 50),
+	}}, nil).Once()
+	mockS3Client.On("ListObjectsV2Pages", &s3.ListObjectsV2Input{
+		Bucket: aws.String("processed-data"),
+		Prefix: aws.String("rules/"),
+	}, mock.Anything).Return(&s3.ListObjectsV2Output{Contents: []*s3.Object{
+		object("rules/aws_s3serveraccess/year=2020/month=05/day=14/hour=21/rule_id=Rule/20200514T213742Z-uuid.json.gz", 400),
+	}}, nil).Once()
+
+	result, err := (API{}).GetStorageUsage(&models.GetStorageUsageInput{})
+	require.NoError(t, err)
+	assert.Equal(t, &models.GetStorageUsageOutput{
+		LogTypes: []*models.LogTypeUsage{
+			{
+				LogType:            aws.String("AWS.S3ServerAccess"),
+				TableName:          aws.String("aws_s3serveraccess"),
+				LogsBytes:          aws.Int64(0),
+				LogsObjects:        aws.Int64(0),
+				RuleMatchesBytes:   aws.Int64(400),
+				RuleMatchesObjects: aws.Int64(1),
+			},
+			{
+				LogType:            aws.String("AWS.CloudTrail"),
+				TableName:          aws.String("aws_cloudtrail"),
+				RetentionDays:      aws.Int(30),
+				LogsBytes:          aws.Int64(300),
+				LogsObjects:        aws.Int64(2),
+				RuleMatchesBytes:   aws.Int64(0),
+				RuleMatchesObjects: aws.Int64(0),
+			},
+			{
+				TableName:          aws.String("removed_type"),
+				LogsBytes:          aws.Int64(50),
+				LogsObjects:        aws.Int64(1),
+				RuleMatchesBytes:   aws.Int64(0),
+				RuleMatchesObjects: aws.Int64(0),
+			},
+		},
+		TotalBytes: aws.Int64(750),
+	}, result)
+	mockS3Client.AssertExpectations(t)
+}
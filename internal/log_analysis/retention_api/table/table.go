@@ -0,0 +1,147 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const hashKey = "policyId"
+
+// API defines the interface for the retention policies table which can be used for mocking.
+type API interface {
+	GetPolicy(policyID string) (*models.RetentionPolicy, error)
+	ListPolicies() ([]*models.RetentionPolicy, error)
+	PutPolicy(policy *models.RetentionPolicy) error
+	DeletePolicy(policyID string) error
+}
+
+// PoliciesTable encapsulates a connection to the Dynamo retention policies table.
+type PoliciesTable struct {
+	Name   string
+	Client dynamodbiface.DynamoDBAPI
+}
+
+// The PoliciesTable must satisfy the API interface.
+var _ API = (*PoliciesTable)(nil)
+
+// policyItem is a retention policy with its key
+type policyItem struct {
+	models.RetentionPolicy
+	PolicyID string `json:"policyId"`
+}
+
+// PolicyID is the key of the retention policy of a log type or of a source, e.g. "logType/AWS.CloudTrail".
+func PolicyID(policy *models.RetentionPolicy) string {
+	if policy.SourceID != nil {
+		return "source/" + *policy.SourceID
+	}
+	return "logType/" + aws.StringValue(policy.LogType)
+}
+
+// GetPolicy returns a retention policy by its ID, nil if it doesn't exist.
+func (table *PoliciesTable) GetPolicy(policyID string) (*models.RetentionPolicy, error) {
+	output, err := table.Client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{hashKey: {S: aws.String(policyID)}},
+		TableName:      aws.String(table.Name),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+
+	var item policyItem
+	if err = dynamodbattribute.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalMap"}
+	}
+	return &item.RetentionPolicy, nil
+}
+
+// ListPolicies returns all the retention policies, page by page.
+func (table *PoliciesTable) ListPolicies() ([]*models.RetentionPolicy, error) {
+	policies := make([]*models.RetentionPolicy, 0)
+	var unmarshalErr error
+	err := table.Client.ScanPages(&dynamodb.ScanInput{
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(table.Name),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var items []*policyItem
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &items); unmarshalErr != nil {
+			return false // stop paginating
+		}
+		for _, item := range items {
+			policies = append(policies, &item.RetentionPolicy)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.ScanPages"}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.AWSError{Err: unmarshalErr, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	return policies, nil
+}
+
+// PutPolicy writes a retention policy, replacing the one of the same log type or source.
+func (table *PoliciesTable) PutPolicy(policy *models.RetentionPolicy) error {
+	item, err := dynamodbattribute.MarshalMap(&policyItem{RetentionPolicy: *policy, PolicyID: PolicyID(policy)})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	if _, err = table.Client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: aws.String(table.Name)}); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// DeletePolicy deletes a retention policy, a DoesNotExistError is returned if there is none with that ID.
+func (table *PoliciesTable) DeletePolicy(policyID string) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(hashKey))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build DeletePolicy ddb expression: " + err.Error()}
+	}
+
+	_, err = table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Key:                      map[string]*dynamodb.AttributeValue{hashKey: {S: aws.String(policyID)}},
+		TableName:                aws.String(table.Name),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return &genericapi.DoesNotExistError{Message: "retention policy " + policyID + " does not exist"}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/retention/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func TestPolicyID(t *testing.T) {
+	assert.Equal(t, "logType/AWS.CloudTrail", PolicyID(&models.RetentionPolicy{LogType: aws.String("AWS.CloudTrail")}))
+	assert.Equal(t, "source/45c378a7-2e36-4b12-8e16-2d3c49ff1371",
+		PolicyID(&models.RetentionPolicy{SourceID: aws.String("45c378a7-2e36-4b12-8e16-2d3c49ff1371")}))
+}
+
+func TestPutAndGetPolicy(t *testing.T) {
+	policy := &models.RetentionPolicy{
+		LogType:        aws.String("AWS.CloudTrail"),
+		RetentionDays:  aws.Int(365),
+		LastModified:   aws.Time(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)),
+		LastModifiedBy: aws.String("97c4db4e-61d5-40a7-82de-6dd63b199bd2"),
+	}
+	mockClient := &mockDynamoClient{}
+	table := &PoliciesTable{Name: "test", Client: mockClient}
+
+	var stored map[string]*dynamodb.AttributeValue
+	mockClient.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once().Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*dynamodb.PutItemInput).Item
+	})
+	require.NoError(t, table.PutPolicy(policy))
+	assert.Equal(t, "logType/AWS.CloudTrail", *stored[hashKey].S)
+
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: stored}, nil).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	result, err := table.GetPolicy("logType/AWS.CloudTrail")
+	require.NoError(t, err)
+	assert.Equal(t, policy, result)
+
+	result, err = table.GetPolicy("logType/Missing")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestDeletePolicyDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+	table := &PoliciesTable{Name: "test", Client: mockClient}
+
+	assert.IsType(t, &genericapi.DoesNotExistError{}, table.DeletePolicy("logType/Missing"))
+}
@@ -17,6 +17,9 @@ from dataclasses import dataclass
 from datetime import datetime
 from typing import Any, Dict
 
+# Rules created before the dedup period was configurable merged their alerts per hour
+DEFAULT_DEDUP_PERIOD_MINS = 60
+
 
 @dataclass
 class EventMatch:
@@ -27,6 +30,7 @@ class EventMatch:
     dedup: str
     severity: str
     event: Dict[str, Any]
+    dedup_period_mins: int = DEFAULT_DEDUP_PERIOD_MINS
 
 
 @dataclass
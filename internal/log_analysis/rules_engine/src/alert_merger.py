@@ -36,10 +36,6 @@ _ALERT_EVENT_COUNT = 'eventCount'
 _ALERT_SEVERITY_ATTR_NAME = 'severity'
 _ALERT_LOG_TYPES = "logTypes"
 
-# TODO Once rules store alert merge period, retrieve it from there
-# Currently grouping in 1hr periods
-_ALERT_MERGE_PERIOD_SECONDS = 3600
-
 
 def _generate_dedup_key(rule_id: str, dedup: str) -> str:
     return rule_id + ':' + dedup
@@ -49,23 +45,30 @@ def _generate_alert_id(rule_id: str, dedup: str, count: str) -> str:
     return rule_id + ':' + dedup + ':' + count
 
 
-def update_get_alert_info(match_time: datetime, num_matches: int, key: OutputGroupingKey, severity: str, version: str) -> AlertInfo:
+# pylint: disable=too-many-arguments
+def update_get_alert_info(
+    match_time: datetime, num_matches: int, key: OutputGroupingKey, severity: str, version: str, dedup_period_mins: int
+) -> AlertInfo:
     """Updates the alert information and returns the result.
 
     The method will update the alertCreationTime, eventCount of an alert. If a new alert will have to be created,
-    it will also create a new alertId with the appropriate alertCreationTime. """
+    it will also create a new alertId with the appropriate alertCreationTime.
+    The matches are merged into the alert of the rule with the same dedup string for dedup_period_mins after it was created."""
     try:
-        return _update_get_conditional(match_time, num_matches, key, severity, version)
+        return _update_get_conditional(match_time, num_matches, key, severity, version, dedup_period_mins)
     except _DDB_CLIENT.exceptions.ConditionalCheckFailedException:
         # If conditional update failed on Condition, the event needs to be merged
         return _update_get(match_time, num_matches, key)
 
 
-def _update_get_conditional(match_time: datetime, num_matches: int, key: OutputGroupingKey, severity: str, version: str) -> AlertInfo:
+# pylint: disable=too-many-arguments
+def _update_get_conditional(
+    match_time: datetime, num_matches: int, key: OutputGroupingKey, severity: str, version: str, dedup_period_mins: int
+) -> AlertInfo:
     """Performs a conditional update to DDB to verify whether we need to create a new alert.
     The condition will succeed only if:
     1. It is the first time this rule with this dedup string fires
-    2. This rule with the same dedup string has fired before, but it fired more than _ALERT_MERGE_PERIOD_SECONDS earlier
+    2. This rule with the same dedup string has fired before, but it fired more than dedup_period_mins earlier
     """
     response = _DDB_CLIENT.update_item(
         TableName=_DDB_TABLE_NAME,
@@ -117,7 +120,7 @@ def _update_get_conditional(match_time: datetime, num_matches: int, key: OutputG
                 'N': '1'
             },
             ':10': {
-                'N': '{}'.format(int(match_time.timestamp()) - _ALERT_MERGE_PERIOD_SECONDS)
+                'N': '{}'.format(int(match_time.timestamp()) - dedup_period_mins * 60)
             }
         },
         ReturnValues='ALL_NEW'
@@ -57,7 +57,8 @@ class Engine:
                     log_type=log_type,
                     dedup=result.dedup_string,  # type: ignore
                     event=event,
-                    severity=rule.rule_severity
+                    severity=rule.rule_severity,
+                    dedup_period_mins=rule.rule_dedup_period_mins
                 )
                 matched.append(match)
 
@@ -97,7 +98,9 @@ class Engine:
                     rule_id=raw_rule.get('id'),
                     rule_body=raw_rule.get('body'),
                     rule_severity=raw_rule.get('severity'),
-                    rule_version=raw_rule.get('versionId')
+                    rule_version=raw_rule.get('versionId'),
+                    rule_dedup_key=raw_rule.get('dedupKey'),
+                    rule_dedup_period_mins=raw_rule.get('dedupPeriodMinutes')
                 )
             except Exception as err:  # pylint: disable=broad-except
                 self.logger.error('Failed to import rule %s', err)
@@ -45,7 +45,7 @@ def lambda_handler(event: Dict[str, Any], unused_context: Any) -> Optional[Dict[
 
 def direct_analysis(event: Dict[str, Any]) -> Dict[str, Any]:
     """
-    Evaluates a single rule against a set of events, and returns the results. Currently used for testing policies directly.
+    Evaluates a single rule against a set of events, and returns the results. Used by the analysis API to test rules against sample events.
     """
     # Since this is used for testing single rules, it should only ever have one rule
     if len(event['rules']) != 1:
@@ -55,7 +55,18 @@ def direct_analysis(event: Dict[str, Any]) -> Dict[str, Any]:
     rule_severity = raw_rule.get('severity', 'INFO')
     # It is possible that during direct analysis the rule doesn't include a severity
     # in this case, we set it to a default value
-    test_rule = Rule(rule_id=raw_rule['id'], rule_version='default', rule_body=raw_rule['body'], rule_severity=rule_severity)
+    # A rule which fails to import (e.g. a syntax error) is an error of each event, like its runtime errors
+    import_error: Optional[Exception] = None
+    try:
+        test_rule = Rule(
+            rule_id=raw_rule['id'],
+            rule_version='default',
+            rule_body=raw_rule['body'],
+            rule_severity=rule_severity,
+            rule_dedup_key=raw_rule.get('dedupKey')
+        )
+    except Exception as err:  # pylint: disable=broad-except
+        import_error = err
     results: Dict[str, Any] = {'events': []}
     for single_event in event['events']:
         result = {
@@ -64,6 +75,13 @@ def direct_analysis(event: Dict[str, Any]) -> Dict[str, Any]:
             'notMatched': [],
             'errored': [],
         }
+        if import_error:
+            result['errored'] = [{
+                'id': raw_rule['id'],
+                'message': str(import_error),
+            }]
+            results['events'].append(result)
+            continue
         rule_result = test_rule.run(single_event['data'])
         if rule_result.exception:
             result['errored'] = [{
@@ -72,6 +90,7 @@ def direct_analysis(event: Dict[str, Any]) -> Dict[str, Any]:
             }]
         elif rule_result.matched:
             result['matched'] = [raw_rule['id']]
+            result['dedup'] = rule_result.dedup_string
         else:
             result['notMatched'] = [raw_rule['id']]
 
@@ -117,7 +117,8 @@ def _write_to_s3(time: datetime, key: OutputGroupingKey, events: List[EventMatch
     rule_version = events[0].rule_version  # severity and version of a rule might differ if the rule was modified
     # while the rules engine was running. We pick the first encountered severity and version.
     rule_severity = events[0].severity
-    alert_info = update_get_alert_info(time, len(events), key, rule_severity, rule_version)
+    dedup_period_mins = events[0].dedup_period_mins
+    alert_info = update_get_alert_info(time, len(events), key, rule_severity, rule_version, dedup_period_mins)
     data_stream = BytesIO()
     writer = gzip.GzipFile(fileobj=data_stream, mode='wb')
     for event in events:
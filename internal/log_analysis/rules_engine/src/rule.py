@@ -15,6 +15,7 @@
 # along with this program.  If not, see <https://www.gnu.org/licenses/>.
 
 import os
+import re
 import sys
 import tempfile
 from dataclasses import dataclass
@@ -22,6 +23,7 @@ from importlib import util as import_util
 from pathlib import Path
 from typing import Any, Dict, Optional, Callable
 
+from . import DEFAULT_DEDUP_PERIOD_MINS
 from .logging import get_logger
 
 _RULE_FOLDER = os.path.join(tempfile.gettempdir(), 'rules')
@@ -29,6 +31,9 @@ _RULE_FOLDER = os.path.join(tempfile.gettempdir(), 'rules')
 # Rule with ID 'aws_globals' contains common Python logic used by other rules
 COMMON_MODULE_RULE_ID = 'aws_globals'
 
+# The {field.path} placeholders of the dedup key of a rule
+_DEDUP_KEY_PLACEHOLDER = re.compile(r'{([^{}]+)}')
+
 
 @dataclass
 class RuleResult:
@@ -42,7 +47,16 @@ class Rule:
     """Panther rule metadata and imported module."""
     logger = get_logger()
 
-    def __init__(self, rule_id: Optional[str], rule_body: Optional[str], rule_severity: Optional[str], rule_version: Optional[str]):
+    # pylint: disable=too-many-arguments
+    def __init__(
+        self,
+        rule_id: Optional[str],
+        rule_body: Optional[str],
+        rule_severity: Optional[str],
+        rule_version: Optional[str],
+        rule_dedup_key: Optional[str] = None,
+        rule_dedup_period_mins: Optional[int] = None
+    ):
         """Create new rule.
 
         Args:
@@ -50,12 +64,16 @@ class Rule:
             rule_body: The rule body
             rule_severity: The severity of the rule
             rule_version: The version of the rule
+            rule_dedup_key: Template of the dedup string, used if the rule has no dedup function
+            rule_dedup_period_mins: The period during which the matches with the same dedup string are merged into an alert
         """
         if not rule_id or not rule_body or not rule_severity or not rule_version:
             raise AssertionError('id, body, severity and version are required fields')
         self.rule_id = rule_id
         self.rule_body = rule_body
         self.rule_severity = rule_severity
+        self.rule_dedup_key = rule_dedup_key
+        self.rule_dedup_period_mins = rule_dedup_period_mins or DEFAULT_DEDUP_PERIOD_MINS
 
         self._store_rule()
         self._module = self._import_rule_as_module()
@@ -81,6 +99,8 @@ class Rule:
             rule_result = _run_command(self._module.rule, event, bool)
             if rule_result and self._has_dedup:
                 dedup_string = _run_command(self._module.dedup, event, str)
+            elif rule_result and self.rule_dedup_key:
+                dedup_string = _render_dedup_key(self.rule_dedup_key, event)
         except Exception as err:  # pylint: disable=broad-except
             return RuleResult(exception=err)
 
@@ -127,6 +147,22 @@ def _run_command(function: Callable, event: Dict[str, Any], expected_type: Any)
     return result
 
 
+def _render_dedup_key(template: str, event: Dict[str, Any]) -> str:
+    """Replaces each {field.path} placeholder of the template with the value of the event field.
+
+    Missing fields are replaced with an empty string."""
+
+    def field_value(match: Any) -> str:
+        value: Any = event
+        for key in match.group(1).strip().split('.'):
+            if not isinstance(value, dict) or key not in value:
+                return ''
+            value = value[key]
+        return '' if value is None else str(value)
+
+    return _DEDUP_KEY_PLACEHOLDER.sub(field_value, template)
+
+
 def _rule_id_to_path(rule_id: str) -> str:
     """Method returns the file path where the rule will be stored"""
     safe_id = ''.join(x if _allowed_char(x) else '_' for x in rule_id)
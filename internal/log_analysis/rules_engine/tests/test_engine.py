@@ -38,6 +38,35 @@ class TestEngine(TestCase):
         self.assertEqual(len(engine.log_type_to_rules['log']), 1)
         self.assertEqual(engine.log_type_to_rules['log'][0].rule_id, 'rule_id')
 
+    def test_loading_rule_dedup_settings(self) -> None:
+        analysis_api = mock.MagicMock()
+        analysis_api.get_enabled_rules.return_value = [
+            {
+                'id': 'rule_id',
+                'resourceTypes': ['log'],
+                'body': 'def rule(event):\n\treturn True',
+                'severity': 'INFO',
+                'versionId': 'version',
+                'dedupKey': '{sourceIp}',
+                'dedupPeriodMinutes': 15
+            }
+        ]
+        engine = Engine(analysis_api)
+        result = engine.analyze('log', {'sourceIp': '1.2.3.4'})
+
+        expected_event_matches = [
+            EventMatch(
+                rule_id='rule_id',
+                rule_version='version',
+                log_type='log',
+                severity='INFO',
+                dedup='1.2.3.4',
+                event={'sourceIp': '1.2.3.4'},
+                dedup_period_mins=15
+            )
+        ]
+        self.assertEqual(result, expected_event_matches)
+
     def test_load_global_library(self) -> None:
         analysis_api = mock.MagicMock()
         analysis_api.get_enabled_rules.return_value = [
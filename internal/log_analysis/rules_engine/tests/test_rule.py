@@ -116,3 +116,32 @@ class TestRule(TestCase):
         self.assertIsNone(rule_result.matched)
         self.assertIsNone(rule_result.dedup_string)
         self.assertIsNotNone(rule_result.exception)
+
+    def test_rule_with_dedup_key(self) -> None:
+        rule_body = 'def rule(event):\n\treturn True'
+        rule = Rule(
+            rule_id='id', rule_body=rule_body, rule_severity='INFO', rule_version='version', rule_dedup_key='{user.name}-{sourceIp}'
+        )
+        expected_rule = RuleResult(matched=True, dedup_string='admin-1.2.3.4')
+        self.assertEqual(rule.run({'user': {'name': 'admin'}, 'sourceIp': '1.2.3.4'}), expected_rule)
+
+    def test_rule_dedup_key_missing_field(self) -> None:
+        rule_body = 'def rule(event):\n\treturn True'
+        rule = Rule(
+            rule_id='id', rule_body=rule_body, rule_severity='INFO', rule_version='version', rule_dedup_key='{user.name}-{sourceIp}'
+        )
+        expected_rule = RuleResult(matched=True, dedup_string='-1.2.3.4')
+        self.assertEqual(rule.run({'user': 'admin', 'sourceIp': '1.2.3.4'}), expected_rule)
+
+    def test_rule_dedup_function_overrides_dedup_key(self) -> None:
+        rule_body = 'def rule(event):\n\treturn True\ndef dedup(event):\n\treturn "testdedup"'
+        rule = Rule(rule_id='id', rule_body=rule_body, rule_severity='INFO', rule_version='version', rule_dedup_key='{sourceIp}')
+        expected_rule = RuleResult(matched=True, dedup_string='testdedup')
+        self.assertEqual(rule.run({'sourceIp': '1.2.3.4'}), expected_rule)
+
+    def test_rule_default_dedup_period(self) -> None:
+        rule_body = 'def rule(event):\n\treturn True'
+        rule = Rule(rule_id='id', rule_body=rule_body, rule_severity='INFO', rule_version='version')
+        self.assertEqual(rule.rule_dedup_period_mins, 60)
+        rule = Rule(rule_id='id', rule_body=rule_body, rule_severity='INFO', rule_version='version', rule_dedup_period_mins=15)
+        self.assertEqual(rule.rule_dedup_period_mins, 15)
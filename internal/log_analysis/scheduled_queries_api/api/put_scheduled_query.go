@@ -0,0 +1,78 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
+	sourcemodels "github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The query ID is part of the log type of the results, which is a Glue table name in the rules engine output
+var queryIDRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// PutScheduledQuery creates or updates a scheduled query.
+//
+// The next run is scheduled from now, the status of the last run of an existing query is kept.
+func (API) PutScheduledQuery(input *models.PutScheduledQueryInput) (*models.PutScheduledQueryOutput, error) {
+	if !queryIDRegex.MatchString(*input.QueryID) {
+		return nil, &genericapi.InvalidInputError{Message: "queryId can only have letters, digits and underscores"}
+	}
+	schedule, err := sourcemodels.ParseCronSchedule(*input.Schedule)
+	if err != nil {
+		return nil, &genericapi.InvalidInputError{Message: "invalid schedule: " + err.Error()}
+	}
+
+	now := nowFunc().UTC()
+	nextRunTime := schedule.Next(now)
+	query := &models.ScheduledQuery{
+		QueryID:        input.QueryID,
+		Description:    input.Description,
+		SQL:            input.SQL,
+		Schedule:       input.Schedule,
+		Enabled:        aws.Bool(aws.BoolValue(input.Enabled)),
+		LogType:        aws.String(models.LogType(*input.QueryID)),
+		NextRunTime:    &nextRunTime,
+		CreatedAtTime:  &now,
+		CreatedBy:      input.UserID,
+		LastModified:   &now,
+		LastModifiedBy: input.UserID,
+	}
+
+	existing, err := queriesTable.GetQuery(input.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		query.CreatedAtTime, query.CreatedBy = existing.CreatedAtTime, existing.CreatedBy
+		query.QueryExecutionID, query.LastRunTime = existing.QueryExecutionID, existing.LastRunTime
+		query.LastRunStatus, query.LastRunError, query.LastRunRows = existing.LastRunStatus, existing.LastRunError, existing.LastRunRows
+	}
+
+	if err := queriesTable.PutQuery(query); err != nil {
+		return nil, err
+	}
+	zap.L().Info("put scheduled query", zap.String("queryId", *query.QueryID), zap.Time("nextRunTime", nextRunTime))
+	return query, nil
+}
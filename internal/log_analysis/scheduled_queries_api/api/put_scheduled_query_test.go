@@ -0,0 +1,125 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const testUserID = "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+
+var testTime = time.Date(2020, 3, 14, 21, 37, 42, 0, time.UTC)
+
+type mockTable struct {
+	mock.Mock
+}
+
+func (m *mockTable) GetQuery(queryID *string) (*models.ScheduledQuery, error) {
+	args := m.Called(queryID)
+	query, _ := args.Get(0).(*models.ScheduledQuery)
+	return query, args.Error(1)
+}
+
+func (m *mockTable) ListQueries() ([]*models.ScheduledQuery, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.ScheduledQuery), args.Error(1)
+}
+
+func (m *mockTable) PutQuery(query *models.ScheduledQuery) error {
+	return m.Called(query).Error(0)
+}
+
+func (m *mockTable) DeleteQuery(queryID *string) error {
+	return m.Called(queryID).Error(0)
+}
+
+func TestPutScheduledQuery(t *testing.T) {
+	mockQueries, _, _, _ := setupMocks()
+	mockQueries.On("GetQuery", aws.String("FailedLogins")).Return(nil, nil).Once()
+	mockQueries.On("PutQuery", mock.Anything).Return(nil).Once()
+
+	result, err := (API{}).PutScheduledQuery(&models.PutScheduledQueryInput{
+		QueryID:  aws.String("FailedLogins"),
+		SQL:      aws.String("SELECT 1"),
+		Schedule: aws.String("0 * * * *"),
+		Enabled:  aws.Bool(true),
+		UserID:   aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ScheduledQuery.FailedLogins", *result.LogType)
+	assert.Equal(t, time.Date(2020, 3, 14, 22, 0, 0, 0, time.UTC), *result.NextRunTime)
+	assert.Equal(t, testTime, *result.CreatedAtTime)
+	mockQueries.AssertExpectations(t)
+}
+
+func TestPutScheduledQueryUpdate(t *testing.T) {
+	mockQueries, _, _, _ := setupMocks()
+	created := testTime.Add(-time.Hour)
+	mockQueries.On("GetQuery", aws.String("FailedLogins")).Return(&models.ScheduledQuery{
+		QueryID:          aws.String("FailedLogins"),
+		QueryExecutionID: aws.String("execution"),
+		LastRunStatus:    aws.String(models.StatusRunning),
+		CreatedAtTime:    &created,
+		CreatedBy:        aws.String("creator"),
+	}, nil).Once()
+	mockQueries.On("PutQuery", mock.Anything).Return(nil).Once()
+
+	result, err := (API{}).PutScheduledQuery(&models.PutScheduledQueryInput{
+		QueryID:  aws.String("FailedLogins"),
+		SQL:      aws.String("SELECT 2"),
+		Schedule: aws.String("*/15 * * * *"),
+		UserID:   aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 2", *result.SQL)
+	assert.False(t, *result.Enabled)
+	assert.Equal(t, created, *result.CreatedAtTime)
+	assert.Equal(t, "creator", *result.CreatedBy)
+	assert.Equal(t, "execution", *result.QueryExecutionID)
+	assert.Equal(t, time.Date(2020, 3, 14, 21, 45, 0, 0, time.UTC), *result.NextRunTime)
+	mockQueries.AssertExpectations(t)
+}
+
+func TestPutScheduledQueryInvalid(t *testing.T) {
+	setupMocks()
+	_, err := (API{}).PutScheduledQuery(&models.PutScheduledQueryInput{
+		QueryID:  aws.String("Failed.Logins"),
+		SQL:      aws.String("SELECT 1"),
+		Schedule: aws.String("0 * * * *"),
+		UserID:   aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	_, err = (API{}).PutScheduledQuery(&models.PutScheduledQueryInput{
+		QueryID:  aws.String("FailedLogins"),
+		SQL:      aws.String("SELECT 1"),
+		Schedule: aws.String("every hour"),
+		UserID:   aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
@@ -0,0 +1,168 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	logmodels "github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
+)
+
+const (
+	// The most result rows of a run which are analyzed by the rules, the rest are dropped
+	maxResultRows = 10000
+
+	resultsTimestampFormat = "20060102T150405Z"
+	// The format of p_event_time, see the timestamp parsers package
+	eventTimeFormat = "2006-01-02 15:04:05.000000000"
+)
+
+// deliverResults writes the result rows of a succeeded query to S3 and notifies the rules engine, like the log processor.
+//
+// Each row is a JSON object keyed by column name with the p_log_type of the query, and the time of the run as p_event_time.
+func deliverResults(query *models.ScheduledQuery, runTime time.Time) (int, error) {
+	rows, err := readResults(query.QueryExecutionID)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if len(rows) > maxResultRows {
+		zap.L().Warn("dropped scheduled query results",
+			zap.String("queryId", *query.QueryID), zap.Int("rows", len(rows)), zap.Int("maxRows", maxResultRows))
+		rows = rows[:maxResultRows]
+	}
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	size := 0
+	for _, row := range rows {
+		row["p_log_type"] = *query.LogType
+		row["p_event_time"] = runTime.Format(eventTimeFormat)
+		line, err := json.Marshal(row)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to marshal a result row")
+		}
+		line = append(line, '\n')
+		if _, err = writer.Write(line); err != nil {
+			return 0, errors.Wrap(err, "failed to compress the results")
+		}
+		size += len(line)
+	}
+	if err = writer.Close(); err != nil {
+		return 0, errors.Wrap(err, "failed to compress the results")
+	}
+
+	key := models.ResultsKeyPrefix + *query.QueryID + "/" + runTime.Format(resultsTimestampFormat) + "-" +
+		uuid.New().String() + ".json.gz"
+	if _, err = s3Client.PutObject(&s3.PutObjectInput{
+		Body:   bytes.NewReader(buffer.Bytes()),
+		Bucket: aws.String(env.ProcessedDataBucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return 0, errors.Wrap(err, "failed to upload the results")
+	}
+
+	notification, err := jsoniter.MarshalToString(&logmodels.S3Notification{
+		S3Bucket:    aws.String(env.ProcessedDataBucket),
+		S3ObjectKey: aws.String(key),
+		Events:      aws.Int(len(rows)),
+		Bytes:       aws.Int(size),
+		Type:        aws.String(logmodels.LogData.String()),
+		ID:          query.LogType,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to marshal the notification")
+	}
+	if _, err = sqsClient.SendMessage(&sqs.SendMessageInput{
+		MessageBody: aws.String(notification),
+		QueueUrl:    aws.String(env.RulesEngineQueueURL),
+	}); err != nil {
+		return 0, errors.Wrap(err, "failed to notify the rules engine")
+	}
+	return len(rows), nil
+}
+
+// readResults returns the rows of the results of a query execution, the header row is skipped.
+func readResults(queryExecutionID *string) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	var columns []*athena.ColumnInfo
+	header := true
+	err := athenaClient.GetQueryResultsPages(&athena.GetQueryResultsInput{
+		QueryExecutionId: queryExecutionID,
+	}, func(page *athena.GetQueryResultsOutput, lastPage bool) bool {
+		if columns == nil {
+			columns = page.ResultSet.ResultSetMetadata.ColumnInfo
+		}
+		for _, row := range page.ResultSet.Rows {
+			if header {
+				header = false
+				continue
+			}
+			rows = append(rows, resultRow(columns, row))
+		}
+		return len(rows) <= maxResultRows
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the results")
+	}
+	return rows, nil
+}
+
+// resultRow converts the values of a result row to JSON by the types of their columns, the null values are left out.
+func resultRow(columns []*athena.ColumnInfo, row *athena.Row) map[string]interface{} {
+	result := make(map[string]interface{}, len(columns))
+	for i, datum := range row.Data {
+		if i >= len(columns) || datum.VarCharValue == nil {
+			continue
+		}
+		value := *datum.VarCharValue
+		result[*columns[i].Name] = value
+		switch aws.StringValue(columns[i].Type) {
+		case "boolean":
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				result[*columns[i].Name] = parsed
+			}
+		case "tinyint", "smallint", "integer", "bigint":
+			if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+				result[*columns[i].Name] = parsed
+			}
+		case "float", "real", "double", "decimal":
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				result[*columns[i].Name] = parsed
+			}
+		}
+	}
+	return result
+}
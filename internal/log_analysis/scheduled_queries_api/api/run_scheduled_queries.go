@@ -0,0 +1,137 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
+	sourcemodels "github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/awsglue"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// RunScheduledQueries starts the due queries and delivers the results of the finished ones, or starts the requested one.
+//
+// It runs every minute: a query which fails to start or to deliver its results is stored as failed,
+// and doesn't stop the other queries. A query isn't started again while it's still running.
+func (API) RunScheduledQueries(input *models.RunScheduledQueriesInput) (models.RunScheduledQueriesOutput, error) {
+	now := nowFunc().UTC()
+	if input.QueryID != nil {
+		query, err := queriesTable.GetQuery(input.QueryID)
+		if err != nil {
+			return nil, err
+		}
+		if query == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "scheduled query " + *input.QueryID + " does not exist"}
+		}
+		if query.QueryExecutionID != nil {
+			return nil, &genericapi.InvalidInputError{Message: "scheduled query " + *input.QueryID + " is already running"}
+		}
+		startQuery(query, now)
+		if err = queriesTable.PutQuery(query); err != nil {
+			return nil, err
+		}
+		return models.RunScheduledQueriesOutput{query}, nil
+	}
+
+	queries, err := queriesTable.ListQueries()
+	if err != nil {
+		return nil, err
+	}
+	result := make(models.RunScheduledQueriesOutput, 0)
+	for _, query := range queries {
+		switch {
+		case query.QueryExecutionID != nil:
+			if !finishQuery(query, now) {
+				continue // still running
+			}
+		case aws.BoolValue(query.Enabled) && query.NextRunTime != nil && !query.NextRunTime.After(now):
+			startQuery(query, now)
+		default:
+			continue
+		}
+		if err := queriesTable.PutQuery(query); err != nil {
+			return nil, err
+		}
+		result = append(result, query)
+	}
+	return result, nil
+}
+
+// startQuery starts the Athena execution of a query and schedules its next run.
+//
+// The runs missed while the query was running or disabled are skipped.
+func startQuery(query *models.ScheduledQuery, now time.Time) {
+	query.LastRunTime, query.LastRunError, query.LastRunRows = &now, nil, nil
+	if schedule, err := sourcemodels.ParseCronSchedule(*query.Schedule); err == nil {
+		nextRunTime := schedule.Next(now)
+		query.NextRunTime = &nextRunTime
+	}
+
+	output, err := athenaClient.StartQueryExecution(&athena.StartQueryExecutionInput{
+		QueryExecutionContext: &athena.QueryExecutionContext{Database: aws.String(awsglue.LogProcessingDatabaseName)},
+		QueryString:           query.SQL,
+		ResultConfiguration: &athena.ResultConfiguration{
+			OutputLocation: aws.String("s3://" + env.AthenaResultsBucket + "/" + models.ResultsKeyPrefix),
+		},
+	})
+	if err != nil {
+		failQuery(query, "failed to start the query: "+err.Error())
+		return
+	}
+	query.QueryExecutionID, query.LastRunStatus = output.QueryExecutionId, aws.String(models.StatusRunning)
+	zap.L().Info("started scheduled query",
+		zap.String("queryId", *query.QueryID), zap.String("queryExecutionId", *query.QueryExecutionID))
+}
+
+// finishQuery delivers the results of a query which finished running, false is returned while it's still running.
+func finishQuery(query *models.ScheduledQuery, now time.Time) bool {
+	output, err := athenaClient.GetQueryExecution(&athena.GetQueryExecutionInput{QueryExecutionId: query.QueryExecutionID})
+	if err != nil {
+		failQuery(query, "failed to get the query execution: "+err.Error())
+		return true
+	}
+
+	switch status := output.QueryExecution.Status; aws.StringValue(status.State) {
+	case athena.QueryExecutionStateQueued, athena.QueryExecutionStateRunning:
+		return false
+	case athena.QueryExecutionStateSucceeded:
+		rows, err := deliverResults(query, now)
+		if err != nil {
+			failQuery(query, "failed to deliver the results: "+err.Error())
+			break
+		}
+		query.LastRunStatus, query.LastRunRows = aws.String(models.StatusSucceeded), aws.Int(rows)
+		zap.L().Info("finished scheduled query", zap.String("queryId", *query.QueryID), zap.Int("rows", rows))
+	default:
+		failQuery(query, "the query "+aws.StringValue(status.State)+": "+aws.StringValue(status.StateChangeReason))
+	}
+	query.QueryExecutionID = nil
+	return true
+}
+
+func failQuery(query *models.ScheduledQuery, message string) {
+	zap.L().Warn("scheduled query failed", zap.String("queryId", *query.QueryID), zap.String("error", message))
+	query.LastRunStatus, query.LastRunError = aws.String(models.StatusFailed), aws.String(message)
+}
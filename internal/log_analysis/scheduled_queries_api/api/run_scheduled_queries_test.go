@@ -0,0 +1,256 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	logmodels "github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
+)
+
+type mockAthena struct {
+	athenaiface.AthenaAPI
+	mock.Mock
+}
+
+func (m *mockAthena) StartQueryExecution(input *athena.StartQueryExecutionInput) (*athena.StartQueryExecutionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*athena.StartQueryExecutionOutput), args.Error(1)
+}
+
+func (m *mockAthena) GetQueryExecution(input *athena.GetQueryExecutionInput) (*athena.GetQueryExecutionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*athena.GetQueryExecutionOutput), args.Error(1)
+}
+
+func (m *mockAthena) GetQueryResultsPages(input *athena.GetQueryResultsInput,
+	fn func(*athena.GetQueryResultsOutput, bool) bool) error {
+
+	args := m.Called(input, fn)
+	for _, page := range args.Get(0).([]*athena.GetQueryResultsOutput) {
+		if !fn(page, false) {
+			break
+		}
+	}
+	return args.Error(1)
+}
+
+type mockS3 struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (m *mockS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+type mockSQS struct {
+	sqsiface.SQSAPI
+	mock.Mock
+}
+
+func (m *mockSQS) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*sqs.SendMessageOutput), args.Error(1)
+}
+
+func setupMocks() (*mockTable, *mockAthena, *mockS3, *mockSQS) {
+	mockQueries, mockAthenaClient, mockS3Client, mockSQSClient := &mockTable{}, &mockAthena{}, &mockS3{}, &mockSQS{}
+	queriesTable, athenaClient, s3Client, sqsClient = mockQueries, mockAthenaClient, mockS3Client, mockSQSClient
+	env.ProcessedDataBucket = "processed-data"
+	env.AthenaResultsBucket = "athena-results"
+	env.RulesEngineQueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/panther-rules-engine-queue"
+	nowFunc = func() time.Time { return testTime }
+	return mockQueries, mockAthenaClient, mockS3Client, mockSQSClient
+}
+
+func testQuery(id string) *models.ScheduledQuery {
+	due := testTime.Add(-time.Minute)
+	return &models.ScheduledQuery{
+		QueryID:     aws.String(id),
+		SQL:         aws.String("SELECT 1"),
+		Schedule:    aws.String("0 * * * *"),
+		Enabled:     aws.Bool(true),
+		LogType:     aws.String(models.LogType(id)),
+		NextRunTime: &due,
+	}
+}
+
+func executionState(state string) *athena.GetQueryExecutionOutput {
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{
+		Status: &athena.QueryExecutionStatus{State: aws.String(state), StateChangeReason: aws.String("reason")},
+	}}
+}
+
+func TestRunScheduledQueriesStartsDueQueries(t *testing.T) {
+	mockQueries, mockAthenaClient, _, _ := setupMocks()
+	notDue, disabled := testQuery("NotDue"), testQuery("Disabled")
+	later := testTime.Add(time.Minute)
+	notDue.NextRunTime, disabled.Enabled = &later, aws.Bool(false)
+	mockQueries.On("ListQueries").Return([]*models.ScheduledQuery{testQuery("Due"), notDue, disabled}, nil).Once()
+	mockQueries.On("PutQuery", mock.Anything).Return(nil).Once()
+	mockAthenaClient.On("StartQueryExecution", mock.Anything).Return(
+		&athena.StartQueryExecutionOutput{QueryExecutionId: aws.String("execution")}, nil).Once()
+
+	result, err := (API{}).RunScheduledQueries(&models.RunScheduledQueriesInput{})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "Due", *result[0].QueryID)
+	assert.Equal(t, "execution", *result[0].QueryExecutionID)
+	assert.Equal(t, models.StatusRunning, *result[0].LastRunStatus)
+	assert.Equal(t, testTime, *result[0].LastRunTime)
+	assert.Equal(t, time.Date(2020, 3, 14, 22, 0, 0, 0, time.UTC), *result[0].NextRunTime)
+	mockQueries.AssertExpectations(t)
+	mockAthenaClient.AssertExpectations(t)
+
+	start := mockAthenaClient.Calls[0].Arguments.Get(0).(*athena.StartQueryExecutionInput)
+	assert.Equal(t, "panther_logs", *start.QueryExecutionContext.Database)
+	assert.Equal(t, "s3://athena-results/scheduled_queries/", *start.ResultConfiguration.OutputLocation)
+}
+
+func TestRunScheduledQueriesDeliversResults(t *testing.T) {
+	mockQueries, mockAthenaClient, mockS3Client, mockSQSClient := setupMocks()
+	query := testQuery("FailedLogins")
+	query.QueryExecutionID = aws.String("execution")
+	mockQueries.On("ListQueries").Return([]*models.ScheduledQuery{query}, nil).Once()
+	mockQueries.On("PutQuery", mock.Anything).Return(nil).Once()
+	mockAthenaClient.On("GetQueryExecution", mock.Anything).Return(
+		executionState(athena.QueryExecutionStateSucceeded), nil).Once()
+	columns := []*athena.ColumnInfo{
+		{Name: aws.String("user"), Type: aws.String("varchar")},
+		{Name: aws.String("failures"), Type: aws.String("bigint")},
+	}
+	mockAthenaClient.On("GetQueryResultsPages", mock.Anything, mock.Anything).Return([]*athena.GetQueryResultsOutput{
+		{ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: columns},
+			Rows: []*athena.Row{
+				{Data: []*athena.Datum{{VarCharValue: aws.String("user")}, {VarCharValue: aws.String("failures")}}},
+				{Data: []*athena.Datum{{VarCharValue: aws.String("alice")}, {VarCharValue: aws.String("120")}}},
+			},
+		}},
+		{ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: columns},
+			Rows: []*athena.Row{
+				{Data: []*athena.Datum{{}, {VarCharValue: aws.String("300")}}},
+			},
+		}},
+	}, nil).Once()
+	mockS3Client.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+	mockSQSClient.On("SendMessage", mock.Anything).Return(&sqs.SendMessageOutput{}, nil).Once()
+
+	result, err := (API{}).RunScheduledQueries(&models.RunScheduledQueriesInput{})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Nil(t, result[0].QueryExecutionID)
+	assert.Equal(t, models.StatusSucceeded, *result[0].LastRunStatus)
+	assert.Equal(t, 2, *result[0].LastRunRows)
+	mockQueries.AssertExpectations(t)
+	mockAthenaClient.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+	mockSQSClient.AssertExpectations(t)
+
+	put := mockS3Client.Calls[0].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Equal(t, "processed-data", *put.Bucket)
+	assert.Regexp(t, `^scheduled_queries/FailedLogins/20200314T213742Z-.*\.json\.gz$`, *put.Key)
+	reader, err := gzip.NewReader(put.Body)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"failures":120,"p_event_time":"2020-03-14 21:37:42.000000000","p_log_type":"ScheduledQuery.FailedLogins","user":"alice"}`+"\n"+
+			`{"failures":300,"p_event_time":"2020-03-14 21:37:42.000000000","p_log_type":"ScheduledQuery.FailedLogins"}`+"\n",
+		string(body))
+
+	send := mockSQSClient.Calls[0].Arguments.Get(0).(*sqs.SendMessageInput)
+	assert.Equal(t, env.RulesEngineQueueURL, *send.QueueUrl)
+	var notification logmodels.S3Notification
+	require.NoError(t, jsoniter.UnmarshalFromString(*send.MessageBody, &notification))
+	assert.Equal(t, *put.Key, *notification.S3ObjectKey)
+	assert.Equal(t, "LogData", *notification.Type)
+	assert.Equal(t, "ScheduledQuery.FailedLogins", *notification.ID)
+	assert.Equal(t, 2, *notification.Events)
+}
+
+func TestRunScheduledQueriesStillRunning(t *testing.T) {
+	mockQueries, mockAthenaClient, _, _ := setupMocks()
+	query := testQuery("FailedLogins")
+	query.QueryExecutionID = aws.String("execution")
+	mockQueries.On("ListQueries").Return([]*models.ScheduledQuery{query}, nil).Once()
+	mockAthenaClient.On("GetQueryExecution", mock.Anything).Return(
+		executionState(athena.QueryExecutionStateRunning), nil).Once()
+
+	result, err := (API{}).RunScheduledQueries(&models.RunScheduledQueriesInput{})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	mockQueries.AssertExpectations(t)
+	mockAthenaClient.AssertExpectations(t)
+}
+
+func TestRunScheduledQueriesFailed(t *testing.T) {
+	mockQueries, mockAthenaClient, _, _ := setupMocks()
+	query := testQuery("FailedLogins")
+	query.QueryExecutionID = aws.String("execution")
+	mockQueries.On("ListQueries").Return([]*models.ScheduledQuery{query}, nil).Once()
+	mockQueries.On("PutQuery", mock.Anything).Return(nil).Once()
+	mockAthenaClient.On("GetQueryExecution", mock.Anything).Return(
+		executionState(athena.QueryExecutionStateFailed), nil).Once()
+
+	result, err := (API{}).RunScheduledQueries(&models.RunScheduledQueriesInput{})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Nil(t, result[0].QueryExecutionID)
+	assert.Equal(t, models.StatusFailed, *result[0].LastRunStatus)
+	assert.Equal(t, "the query FAILED: reason", *result[0].LastRunError)
+	mockQueries.AssertExpectations(t)
+	mockAthenaClient.AssertExpectations(t)
+}
+
+func TestRunScheduledQueryNow(t *testing.T) {
+	mockQueries, mockAthenaClient, _, _ := setupMocks()
+	query := testQuery("FailedLogins")
+	query.Enabled = aws.Bool(false)
+	mockQueries.On("GetQuery", aws.String("FailedLogins")).Return(query, nil).Once()
+	mockQueries.On("PutQuery", query).Return(nil).Once()
+	mockAthenaClient.On("StartQueryExecution", mock.Anything).Return(
+		&athena.StartQueryExecutionOutput{QueryExecutionId: aws.String("execution")}, nil).Once()
+
+	result, err := (API{}).RunScheduledQueries(&models.RunScheduledQueriesInput{QueryID: aws.String("FailedLogins")})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "execution", *result[0].QueryExecutionID)
+	mockQueries.AssertExpectations(t)
+	mockAthenaClient.AssertExpectations(t)
+}
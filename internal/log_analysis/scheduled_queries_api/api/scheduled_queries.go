@@ -0,0 +1,46 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetScheduledQuery returns a scheduled query by its ID.
+func (API) GetScheduledQuery(input *models.GetScheduledQueryInput) (*models.GetScheduledQueryOutput, error) {
+	query, err := queriesTable.GetQuery(input.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	if query == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "scheduled query " + *input.QueryID + " does not exist"}
+	}
+	return query, nil
+}
+
+// ListScheduledQueries returns all the scheduled queries.
+func (API) ListScheduledQueries(_ *models.ListScheduledQueriesInput) (models.ListScheduledQueriesOutput, error) {
+	return queriesTable.ListQueries()
+}
+
+// DeleteScheduledQuery deletes a scheduled query.
+func (API) DeleteScheduledQuery(input *models.DeleteScheduledQueryInput) error {
+	return queriesTable.DeleteQuery(input.QueryID)
+}
@@ -0,0 +1,131 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/scheduledqueries/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const hashKey = "queryId"
+
+// API defines the interface for the scheduled queries table which can be used for mocking.
+type API interface {
+	GetQuery(queryID *string) (*models.ScheduledQuery, error)
+	ListQueries() ([]*models.ScheduledQuery, error)
+	PutQuery(query *models.ScheduledQuery) error
+	DeleteQuery(queryID *string) error
+}
+
+// QueriesTable encapsulates a connection to the Dynamo scheduled queries table.
+type QueriesTable struct {
+	Name   string
+	Client dynamodbiface.DynamoDBAPI
+}
+
+// The QueriesTable must satisfy the API interface.
+var _ API = (*QueriesTable)(nil)
+
+// GetQuery returns a scheduled query by its ID, nil if it doesn't exist.
+func (table *QueriesTable) GetQuery(queryID *string) (*models.ScheduledQuery, error) {
+	output, err := table.Client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{hashKey: {S: queryID}},
+		TableName:      aws.String(table.Name),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+
+	var query models.ScheduledQuery
+	if err = dynamodbattribute.UnmarshalMap(output.Item, &query); err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalMap"}
+	}
+	return &query, nil
+}
+
+// ListQueries returns all the scheduled queries, page by page.
+func (table *QueriesTable) ListQueries() ([]*models.ScheduledQuery, error) {
+	queries := make([]*models.ScheduledQuery, 0)
+	var unmarshalErr error
+	err := table.Client.ScanPages(&dynamodb.ScanInput{
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(table.Name),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var pageQueries []*models.ScheduledQuery
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageQueries); unmarshalErr != nil {
+			return false // stop paginating
+		}
+		queries = append(queries, pageQueries...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.ScanPages"}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.AWSError{Err: unmarshalErr, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	return queries, nil
+}
+
+// PutQuery writes a scheduled query, replacing the one with the same ID.
+func (table *QueriesTable) PutQuery(query *models.ScheduledQuery) error {
+	item, err := dynamodbattribute.MarshalMap(query)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	if _, err = table.Client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: aws.String(table.Name)}); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// DeleteQuery deletes a scheduled query, a DoesNotExistError is returned if there is none with that ID.
+func (table *QueriesTable) DeleteQuery(queryID *string) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(hashKey))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build DeleteQuery ddb expression: " + err.Error()}
+	}
+
+	_, err = table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Key:                      map[string]*dynamodb.AttributeValue{hashKey: {S: queryID}},
+		TableName:                aws.String(table.Name),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return &genericapi.DoesNotExistError{Message: "scheduled query " + aws.StringValue(queryID) + " does not exist"}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
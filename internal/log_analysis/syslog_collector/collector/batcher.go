@@ -0,0 +1,160 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// The key of the batches in the http-ingest bucket: integration ID, hour, start time and a random ID
+const objectKeyFormat = "syslog/%s/%s/%s-%s.log.gz"
+
+var newline = []byte{'\n'}
+
+// The messages of an integration, compressed as they are added
+type batch struct {
+	integrationID string
+	started       time.Time
+	object        bytes.Buffer
+	writer        *gzip.Writer
+	// The size of the messages before compression
+	size     int
+	messages int
+}
+
+// batcher batches the messages by integration and writes each batch to an object of the http-ingest bucket.
+type batcher struct {
+	lock     sync.Mutex
+	batches  map[string]*batch
+	maxBytes int
+	maxAge   time.Duration
+	now      func() time.Time
+	// Writes a complete batch, tests replace it
+	write func(*batch) error
+}
+
+func newBatcher(maxBytes int, maxAge time.Duration) *batcher {
+	return &batcher{
+		batches:  make(map[string]*batch),
+		maxBytes: maxBytes,
+		maxAge:   maxAge,
+		now:      time.Now,
+		write:    writeBatch,
+	}
+}
+
+// add adds a message to the batch of its integration, and writes the batch once it's full.
+//
+// The log processor reads a message per line, the line breaks within a message are replaced by spaces.
+func (b *batcher) add(integrationID string, message []byte) {
+	message = bytes.ReplaceAll(bytes.ReplaceAll(message, []byte{'\r'}, nil), newline, []byte{' '})
+
+	b.lock.Lock()
+	current := b.batches[integrationID]
+	if current == nil {
+		current = &batch{integrationID: integrationID, started: b.now().UTC()}
+		current.writer = gzip.NewWriter(&current.object)
+		b.batches[integrationID] = current
+	}
+	// Writing to the buffer of the batch can't fail
+	_, _ = current.writer.Write(message)
+	_, _ = current.writer.Write(newline)
+	current.size += len(message) + 1
+	current.messages++
+	full := current.size >= b.maxBytes
+	if full {
+		delete(b.batches, integrationID)
+	}
+	b.lock.Unlock()
+
+	if full {
+		b.complete(current)
+	}
+}
+
+// flushExpired writes the batches which are as old as the max age.
+func (b *batcher) flushExpired() {
+	b.flush(func(current *batch) bool { return b.now().Sub(current.started) >= b.maxAge })
+}
+
+// flushAll writes every batch, when the collector stops.
+func (b *batcher) flushAll() {
+	b.flush(func(*batch) bool { return true })
+}
+
+func (b *batcher) flush(due func(*batch) bool) {
+	var flushed []*batch
+	b.lock.Lock()
+	for integrationID, current := range b.batches {
+		if due(current) {
+			flushed = append(flushed, current)
+			delete(b.batches, integrationID)
+		}
+	}
+	b.lock.Unlock()
+
+	for _, current := range flushed {
+		b.complete(current)
+	}
+}
+
+// complete writes a batch which takes no more messages.
+//
+// The senders aren't acknowledged, the messages of a batch which can't be written are lost.
+func (b *batcher) complete(current *batch) {
+	// Closing the writer of the buffer can't fail
+	_ = current.writer.Close()
+	if err := b.write(current); err != nil {
+		zap.L().Error("failed to write the messages of a syslog integration",
+			zap.String("integrationId", current.integrationID),
+			zap.Int("messages", current.messages),
+			zap.Error(err))
+		return
+	}
+	zap.L().Debug("wrote the messages of a syslog integration",
+		zap.String("integrationId", current.integrationID),
+		zap.Int("messages", current.messages),
+		zap.Int("bytes", current.size))
+}
+
+// writeBatch writes a batch to an object of the http-ingest bucket, S3 notifies the log processor of it.
+func writeBatch(current *batch) error {
+	key := fmt.Sprintf(objectKeyFormat, current.integrationID, current.started.Format("2006/01/02/15"),
+		current.started.Format("20060102T150405Z"), uuid.New().String())
+	_, err := s3Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(env.HTTPIngestBucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(current.object.Bytes()),
+		ContentType: aws.String("application/x-gzip"),
+	})
+	if err != nil {
+		return errors.Wrap(err, "s3.PutObject failed")
+	}
+	return nil
+}
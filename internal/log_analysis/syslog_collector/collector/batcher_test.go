@@ -0,0 +1,108 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A batch as it was written
+type writtenBatch struct {
+	integrationID string
+	started       time.Time
+	lines         string
+}
+
+// Replaces the writes of a batcher, the batches are returned in the order of their integrations
+func captureBatches(t *testing.T, b *batcher) func() []writtenBatch {
+	var written []writtenBatch
+	b.write = func(current *batch) error {
+		reader, err := gzip.NewReader(&current.object)
+		require.NoError(t, err)
+		lines, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		written = append(written, writtenBatch{integrationID: current.integrationID, started: current.started, lines: string(lines)})
+		return nil
+	}
+	return func() []writtenBatch {
+		sort.SliceStable(written, func(i, j int) bool { return written[i].integrationID < written[j].integrationID })
+		return written
+	}
+}
+
+func TestBatcherWritesFullBatches(t *testing.T) {
+	b := newBatcher(30, time.Minute)
+	b.now = func() time.Time { return testCreatedAt }
+	written := captureBatches(t, b)
+
+	b.add("firewalls", []byte("<13>1 - fw1 - - - first"))
+	assert.Empty(t, written())
+	// Line breaks within a message are replaced
+	b.add("firewalls", []byte("<13>1 - fw1 - - - second\r\nline"))
+	b.add("firewalls", []byte("<13>1 - fw1 - - - third"))
+	assert.Equal(t, []writtenBatch{
+		{integrationID: "firewalls", started: testCreatedAt, lines: "<13>1 - fw1 - - - first\n<13>1 - fw1 - - - second line\n"},
+	}, written())
+
+	b.flushAll()
+	assert.Len(t, written(), 2)
+	assert.Equal(t, "<13>1 - fw1 - - - third\n", written()[1].lines)
+}
+
+func TestBatcherFlushesExpiredBatches(t *testing.T) {
+	now := testCreatedAt
+	b := newBatcher(1024, time.Minute)
+	b.now = func() time.Time { return now }
+	written := captureBatches(t, b)
+
+	b.add("datacenter", []byte("old"))
+	now = now.Add(30 * time.Second)
+	b.add("firewalls", []byte("new"))
+	b.flushExpired()
+	assert.Empty(t, written())
+
+	now = now.Add(30 * time.Second)
+	b.flushExpired()
+	assert.Equal(t, []writtenBatch{{integrationID: "datacenter", started: testCreatedAt, lines: "old\n"}}, written())
+
+	b.flushAll()
+	assert.Equal(t, "firewalls", written()[1].integrationID)
+}
+
+func TestBatcherWriteFails(t *testing.T) {
+	b := newBatcher(1, time.Minute)
+	attempts := 0
+	b.write = func(*batch) error {
+		attempts++
+		return errors.New("access denied")
+	}
+	b.add("firewalls", []byte("lost"))
+	b.flushAll()
+	// The failed batch is dropped
+	assert.Equal(t, 1, attempts)
+	assert.Empty(t, b.batches)
+}
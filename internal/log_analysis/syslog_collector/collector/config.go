@@ -0,0 +1,60 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/kelseyhightower/envconfig"
+)
+
+var (
+	env          envConfig
+	awsSession   *session.Session
+	s3Client     s3iface.S3API
+	lambdaClient lambdaiface.LambdaAPI
+)
+
+type envConfig struct {
+	// The bucket the batches are written to, S3 notifies the log processor of its objects
+	HTTPIngestBucket string `required:"true" split_words:"true"`
+	// The address the connections of the load balancer are accepted on, the load balancer terminates TLS
+	ListenAddress string `default:":6514" split_words:"true"`
+	// Whether the connections start with the PROXY protocol v2 header of the load balancer, which has the sender
+	ProxyProtocol bool `default:"true" split_words:"true"`
+	// A batch is written once its messages reach the size, or once it's as old as the age
+	BatchMaxBytes int           `default:"10485760" split_words:"true"`
+	BatchMaxAge   time.Duration `default:"1m" split_words:"true"`
+	// Connections which send nothing for this long are closed
+	IdleTimeout time.Duration `default:"10m" split_words:"true"`
+}
+
+// Setup parses the environment and builds the AWS clients.
+func Setup() {
+	envconfig.MustProcess("", &env)
+
+	awsSession = session.Must(session.NewSession())
+	s3Client = s3.New(awsSession)
+	lambdaClient = lambda.New(awsSession)
+}
@@ -0,0 +1,83 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// The largest message accepted, the connections which send larger ones are closed
+const maxMessageBytes = 64 * 1024
+
+// readMessage reads the next message of a connection, framed either by octet counting or by a trailing LF.
+// See https://tools.ietf.org/html/rfc6587#section-3.4
+//
+// The reader must buffer at least maxMessageBytes. The message is only valid until the next read.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	// The length of an octet counted frame starts with a non-zero digit, a message starts with its priority
+	if first[0] >= '1' && first[0] <= '9' {
+		return readOctetCountedMessage(r)
+	}
+
+	line, err := r.ReadSlice('\n')
+	switch err {
+	case nil:
+	case io.EOF:
+		// The last message of a connection may omit the trailer
+		if len(line) == 0 {
+			return nil, io.EOF
+		}
+	case bufio.ErrBufferFull:
+		return nil, errors.Errorf("the message is longer than %d bytes", maxMessageBytes)
+	default:
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+func readOctetCountedMessage(r *bufio.Reader) ([]byte, error) {
+	length, err := r.ReadSlice(' ')
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the length of the message")
+	}
+	size, err := strconv.Atoi(string(length[:len(length)-1]))
+	if err != nil {
+		return nil, errors.Errorf("invalid message length %q", length[:len(length)-1])
+	}
+	if size > maxMessageBytes {
+		return nil, errors.Errorf("the message is longer than %d bytes", maxMessageBytes)
+	}
+	message, err := r.Peek(size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the message")
+	}
+	if _, err = r.Discard(size); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
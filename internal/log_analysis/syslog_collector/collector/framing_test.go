@@ -0,0 +1,80 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readMessages(input string) (messages []string, err error) {
+	reader := bufio.NewReaderSize(strings.NewReader(input), maxMessageBytes+16)
+	for {
+		message, err := readMessage(reader)
+		if err == io.EOF {
+			return messages, nil
+		}
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, string(message))
+	}
+}
+
+func TestReadMessageNonTransparentFraming(t *testing.T) {
+	messages, err := readMessages("<13>Dec  2 16:31:03 host app: one\r\n<13>Dec  2 16:31:04 host app: two\n\n<13>Dec  2 16:31:05 host app: three")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"<13>Dec  2 16:31:03 host app: one",
+		"<13>Dec  2 16:31:04 host app: two",
+		"",
+		"<13>Dec  2 16:31:05 host app: three",
+	}, messages)
+}
+
+func octetCounted(message string) string {
+	return fmt.Sprintf("%d %s", len(message), message)
+}
+
+func TestReadMessageOctetCounting(t *testing.T) {
+	first, second := "<13>1 - host app - - one", "<13>1 - host app - - two\nlines"
+	messages, err := readMessages(octetCounted(first) + octetCounted(second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{first, second}, messages)
+
+	// The framing of each message is detected
+	messages, err = readMessages(octetCounted(first) + "<13>Dec  2 16:31:03 host app: line\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{first, "<13>Dec  2 16:31:03 host app: line"}, messages)
+}
+
+func TestReadMessageTooLong(t *testing.T) {
+	_, err := readMessages("<13>" + strings.Repeat("x", maxMessageBytes+16))
+	assert.Error(t, err)
+	_, err = readMessages("70000 <13>1 - host app - - Test")
+	assert.Error(t, err)
+	_, err = readMessages("30 <13>1 - host")
+	assert.Error(t, err)
+}
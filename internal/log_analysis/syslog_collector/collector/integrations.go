@@ -0,0 +1,118 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	sourceAPIFunctionName = "panther-source-api"
+
+	// How long the networks of the integrations are used before they are listed again
+	integrationsTTL = time.Minute
+)
+
+var listIntegrationsFunc = func() (integrations []*models.SourceIntegration, err error) {
+	err = genericapi.Invoke(lambdaClient, sourceAPIFunctionName, &models.LambdaInput{
+		ListIntegrations: &models.ListIntegrationsInput{IntegrationType: aws.String(models.IntegrationTypeSyslog)},
+	}, &integrations)
+	return
+}
+
+// A network the devices of an integration send their messages from
+type integrationNetwork struct {
+	network       *net.IPNet
+	prefixLength  int
+	integrationID string
+}
+
+// integrationResolver attributes the senders of messages to the syslog integrations by their networks.
+type integrationResolver struct {
+	lock      sync.Mutex
+	networks  []integrationNetwork // the most specific first
+	listed    bool
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+func newIntegrationResolver() *integrationResolver {
+	return &integrationResolver{now: time.Now}
+}
+
+// resolve returns the ID of the integration with the most specific network of the sender, "" if there is none.
+//
+// Among the integrations with the same network, the one added first gets the messages.
+func (r *integrationResolver) resolve(sender net.IP) string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if now := r.now(); !now.Before(r.expiresAt) {
+		// The networks last listed are kept when the integrations can't be listed, until the next attempt
+		r.expiresAt = now.Add(integrationsTTL)
+		if integrations, err := listIntegrationsFunc(); err != nil {
+			zap.L().Error("failed to list the syslog integrations", zap.Bool("listedBefore", r.listed), zap.Error(err))
+		} else {
+			r.networks, r.listed = integrationNetworks(integrations), true
+		}
+	}
+
+	for _, network := range r.networks {
+		if network.network.Contains(sender) {
+			return network.integrationID
+		}
+	}
+	return ""
+}
+
+func integrationNetworks(integrations []*models.SourceIntegration) []integrationNetwork {
+	sort.SliceStable(integrations, func(i, j int) bool {
+		return aws.TimeValue(integrations[i].CreatedAtTime).Before(aws.TimeValue(integrations[j].CreatedAtTime))
+	})
+	var networks []integrationNetwork
+	for _, integration := range integrations {
+		for _, cidr := range integration.SyslogAllowedCIDRs {
+			_, network, err := net.ParseCIDR(aws.StringValue(cidr))
+			if err != nil {
+				// The networks are validated when they are set
+				zap.L().Warn("invalid network of a syslog integration",
+					zap.String("integrationId", aws.StringValue(integration.IntegrationID)),
+					zap.String("cidr", aws.StringValue(cidr)))
+				continue
+			}
+			prefixLength, _ := network.Mask.Size()
+			networks = append(networks, integrationNetwork{
+				network:       network,
+				prefixLength:  prefixLength,
+				integrationID: aws.StringValue(integration.IntegrationID),
+			})
+		}
+	}
+	sort.SliceStable(networks, func(i, j int) bool { return networks[i].prefixLength > networks[j].prefixLength })
+	return networks
+}
@@ -0,0 +1,91 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+var testCreatedAt = time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+
+func syslogIntegration(id string, createdAt time.Time, cidrs ...string) *models.SourceIntegration {
+	return &models.SourceIntegration{SourceIntegrationMetadata: &models.SourceIntegrationMetadata{
+		IntegrationID:      aws.String(id),
+		IntegrationType:    aws.String(models.IntegrationTypeSyslog),
+		CreatedAtTime:      aws.Time(createdAt),
+		SyslogAllowedCIDRs: aws.StringSlice(cidrs),
+	}}
+}
+
+// Replaces the integrations listed from the source API, counting the listings
+func mockIntegrations(t *testing.T, integrations []*models.SourceIntegration, err error) *int {
+	listed := 0
+	original := listIntegrationsFunc
+	t.Cleanup(func() { listIntegrationsFunc = original })
+	listIntegrationsFunc = func() ([]*models.SourceIntegration, error) {
+		listed++
+		return integrations, err
+	}
+	return &listed
+}
+
+func TestResolveMostSpecificNetwork(t *testing.T) {
+	mockIntegrations(t, []*models.SourceIntegration{
+		syslogIntegration("datacenter", testCreatedAt, "10.0.0.0/8", "2001:db8::/32"),
+		syslogIntegration("firewalls", testCreatedAt, "10.1.2.0/24"),
+		// The network of the earlier integration wins
+		syslogIntegration("duplicate", testCreatedAt.Add(time.Hour), "10.1.2.0/24"),
+		syslogIntegration("invalid", testCreatedAt, "10.1.2.3"),
+	}, nil)
+	resolver := newIntegrationResolver()
+
+	assert.Equal(t, "firewalls", resolver.resolve(net.ParseIP("10.1.2.3")))
+	assert.Equal(t, "datacenter", resolver.resolve(net.ParseIP("10.1.3.3")))
+	assert.Equal(t, "datacenter", resolver.resolve(net.ParseIP("2001:db8::5")))
+	assert.Equal(t, "", resolver.resolve(net.ParseIP("192.0.2.10")))
+}
+
+func TestResolveListsAgainOnceExpired(t *testing.T) {
+	listed := mockIntegrations(t, []*models.SourceIntegration{syslogIntegration("datacenter", testCreatedAt, "10.0.0.0/8")}, nil)
+	now := testCreatedAt
+	resolver := newIntegrationResolver()
+	resolver.now = func() time.Time { return now }
+
+	assert.Equal(t, "datacenter", resolver.resolve(net.ParseIP("10.0.0.1")))
+	now = now.Add(integrationsTTL - time.Second)
+	assert.Equal(t, "datacenter", resolver.resolve(net.ParseIP("10.0.0.1")))
+	assert.Equal(t, 1, *listed)
+
+	// The networks listed before are kept when the integrations can't be listed
+	listIntegrationsFunc = func() ([]*models.SourceIntegration, error) {
+		*listed++
+		return nil, errors.New("throttled")
+	}
+	now = now.Add(time.Second)
+	assert.Equal(t, "datacenter", resolver.resolve(net.ParseIP("10.0.0.1")))
+	assert.Equal(t, 2, *listed)
+}
@@ -0,0 +1,84 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// The signature version 2 of the PROXY protocol starts with, see
+// https://www.haproxy.org/download/2.2/doc/proxy-protocol.txt
+var proxySignature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	proxyVersion2     = 0x20
+	proxyCommandLocal = 0x00
+	proxyCommandProxy = 0x01
+	proxyFamilyTCP4   = 0x11
+	proxyFamilyTCP6   = 0x21
+)
+
+// readProxyHeader reads the PROXY protocol v2 header a connection starts with and returns the address of the sender.
+//
+// The address is nil for the connections of the load balancer itself, such as its health checks.
+func readProxyHeader(r *bufio.Reader) (net.IP, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "failed to read the proxy protocol header")
+	}
+	if !bytes.Equal(header[:12], proxySignature) {
+		return nil, errors.New("the connection doesn't start with a proxy protocol v2 header")
+	}
+	if header[12]&0xf0 != proxyVersion2 {
+		return nil, errors.Errorf("unsupported proxy protocol version %d", header[12]>>4)
+	}
+	addresses := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, addresses); err != nil {
+		return nil, errors.Wrap(err, "failed to read the proxy protocol addresses")
+	}
+
+	switch header[12] & 0x0f {
+	case proxyCommandLocal:
+		return nil, nil
+	case proxyCommandProxy:
+	default:
+		return nil, errors.Errorf("unsupported proxy protocol command %d", header[12]&0x0f)
+	}
+	// The source address comes first, the type-length-values which follow the addresses are ignored
+	switch header[13] {
+	case proxyFamilyTCP4:
+		if len(addresses) < 12 {
+			return nil, errors.New("the proxy protocol header is too short for TCP over IPv4")
+		}
+		return net.IP(addresses[:4]), nil
+	case proxyFamilyTCP6:
+		if len(addresses) < 36 {
+			return nil, errors.New("the proxy protocol header is too short for TCP over IPv6")
+		}
+		return net.IP(addresses[:16]), nil
+	default:
+		return nil, errors.Errorf("unsupported proxy protocol address family %#x", header[13])
+	}
+}
@@ -0,0 +1,79 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proxyHeader builds the PROXY protocol v2 header of a TCP connection from the source
+func proxyHeader(command byte, source net.IP) []byte {
+	var addresses []byte
+	family := byte(proxyFamilyTCP4)
+	if ipv4 := source.To4(); ipv4 != nil {
+		addresses = append(append(addresses, ipv4...), 10, 0, 0, 1, 0x19, 0x6a, 0x19, 0x6a)
+	} else {
+		family = proxyFamilyTCP6
+		addresses = append(append(addresses, source.To16()...), net.ParseIP("fd00::1").To16()...)
+		addresses = append(addresses, 0x19, 0x6a, 0x19, 0x6a)
+	}
+	header := append([]byte{}, proxySignature...)
+	header = append(header, proxyVersion2|command, family, 0, 0)
+	binary.BigEndian.PutUint16(header[14:], uint16(len(addresses)))
+	return append(header, addresses...)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	reader := bufio.NewReader(bytes.NewReader(append(proxyHeader(proxyCommandProxy, net.ParseIP("192.0.2.10")), "<13>1 -"...)))
+	sender, err := readProxyHeader(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.10", sender.String())
+	// The messages follow the header
+	rest, err := reader.ReadString('-')
+	require.NoError(t, err)
+	assert.Equal(t, "<13>1 -", rest)
+}
+
+func TestReadProxyHeaderIPv6(t *testing.T) {
+	sender, err := readProxyHeader(bufio.NewReader(bytes.NewReader(proxyHeader(proxyCommandProxy, net.ParseIP("2001:db8::5")))))
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::5", sender.String())
+}
+
+func TestReadProxyHeaderLocal(t *testing.T) {
+	sender, err := readProxyHeader(bufio.NewReader(bytes.NewReader(proxyHeader(proxyCommandLocal, net.ParseIP("10.0.0.1")))))
+	require.NoError(t, err)
+	assert.Nil(t, sender)
+}
+
+func TestReadProxyHeaderInvalid(t *testing.T) {
+	_, err := readProxyHeader(bufio.NewReader(bytes.NewReader([]byte("<13>1 2020-04-02T16:31:03Z host app - - Test\n"))))
+	assert.Error(t, err)
+
+	header := proxyHeader(proxyCommandProxy, net.ParseIP("10.0.0.1"))
+	_, err = readProxyHeader(bufio.NewReader(bytes.NewReader(header[:20])))
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Server accepts the syslog connections forwarded by the load balancer and batches their messages by integration.
+type Server struct {
+	proxyProtocol bool
+	idleTimeout   time.Duration
+	resolver      *integrationResolver
+	batcher       *batcher
+
+	lock        sync.Mutex
+	connections map[net.Conn]struct{}
+	handlers    sync.WaitGroup
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewServer returns a server configured by the environment, see Setup.
+func NewServer() *Server {
+	return &Server{
+		proxyProtocol: env.ProxyProtocol,
+		idleTimeout:   env.IdleTimeout,
+		resolver:      newIntegrationResolver(),
+		batcher:       newBatcher(env.BatchMaxBytes, env.BatchMaxAge),
+		connections:   make(map[net.Conn]struct{}),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Listen listens on the address of the environment, see Setup.
+func Listen() (net.Listener, error) {
+	return net.Listen("tcp", env.ListenAddress)
+}
+
+// Serve accepts connections until the listener is closed, and writes the batches as they expire.
+func (s *Server) Serve(listener net.Listener) error {
+	go s.flushExpired()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				zap.L().Warn("failed to accept a connection", zap.Error(err))
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			select {
+			case <-s.stop:
+				return nil
+			default:
+				return err
+			}
+		}
+		s.lock.Lock()
+		select {
+		case <-s.stop: // accepted while the server shuts down
+			_ = conn.Close()
+			s.lock.Unlock()
+			return nil
+		default:
+		}
+		s.connections[conn] = struct{}{}
+		s.handlers.Add(1)
+		s.lock.Unlock()
+		go s.handle(conn)
+	}
+}
+
+// Shutdown closes the listener and the connections, then writes the batches.
+func (s *Server) Shutdown(listener net.Listener) {
+	s.lock.Lock()
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.lock.Unlock()
+	if err := listener.Close(); err != nil {
+		zap.L().Warn("failed to close the listener", zap.Error(err))
+	}
+	s.lock.Lock()
+	for conn := range s.connections {
+		_ = conn.Close()
+	}
+	s.lock.Unlock()
+	s.handlers.Wait()
+	s.batcher.flushAll()
+}
+
+func (s *Server) flushExpired() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.batcher.flushExpired()
+		}
+	}
+}
+
+// handle reads the messages of a connection, which are attributed to the integration of its sender.
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+		s.lock.Lock()
+		delete(s.connections, conn)
+		s.lock.Unlock()
+		s.handlers.Done()
+	}()
+
+	reader := bufio.NewReaderSize(conn, maxMessageBytes+16)
+	var sender net.IP
+	if s.proxyProtocol {
+		_ = conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		var err error
+		if sender, err = readProxyHeader(reader); err != nil {
+			zap.L().Warn("failed to read the sender of a connection", zap.String("remoteAddr", conn.RemoteAddr().String()),
+				zap.Error(err))
+			return
+		}
+		if sender == nil { // the load balancer checks the collector is up
+			return
+		}
+	} else if address, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		sender = address.IP
+	}
+
+	integrationID := s.resolver.resolve(sender)
+	if integrationID == "" {
+		zap.L().Warn("no syslog integration allows the sender", zap.String("sender", sender.String()))
+		return
+	}
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		message, err := readMessage(reader)
+		if err != nil {
+			if err != io.EOF && !isClosedError(err) {
+				zap.L().Warn("failed to read the messages of a connection", zap.String("sender", sender.String()),
+					zap.String("integrationId", integrationID), zap.Error(err))
+			}
+			return
+		}
+		if len(message) > 0 {
+			s.batcher.add(integrationID, message)
+		}
+	}
+}
+
+// The connections are closed when the server shuts down
+func isClosedError(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
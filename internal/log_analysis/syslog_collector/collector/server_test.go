@@ -0,0 +1,116 @@
+package collector
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+type mockS3Client struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (client *mockS3Client) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := client.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+// Starts a server on a local port, the connections start with the PROXY protocol header
+func startServer(t *testing.T) (*Server, net.Listener, *mockS3Client) {
+	env = envConfig{
+		HTTPIngestBucket: "panther-http-ingest",
+		ProxyProtocol:    true,
+		BatchMaxBytes:    1024,
+		BatchMaxAge:      time.Minute,
+		IdleTimeout:      time.Minute,
+	}
+	mockS3 := &mockS3Client{}
+	s3Client = mockS3
+	mockS3.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil)
+	mockIntegrations(t, []*models.SourceIntegration{syslogIntegration("firewalls", testCreatedAt, "192.0.2.0/24")}, nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewServer()
+	go func() { assert.NoError(t, server.Serve(listener)) }()
+	return server, listener, mockS3
+}
+
+func send(t *testing.T, listener net.Listener, data ...[]byte) {
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	for _, chunk := range data {
+		_, err = conn.Write(chunk)
+		require.NoError(t, err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	server, listener, mockS3 := startServer(t)
+
+	send(t, listener, proxyHeader(proxyCommandProxy, net.ParseIP("192.0.2.10")),
+		[]byte(octetCounted("<13>1 2020-04-02T16:31:03Z fw1 app - - - one")+"<13>Dec  2 16:31:03 fw1 app: two\n"))
+	// The messages of unknown senders and of the health checks of the load balancer are dropped
+	send(t, listener, proxyHeader(proxyCommandProxy, net.ParseIP("198.51.100.1")), []byte("<13>Dec  2 16:31:03 other app: dropped\n"))
+	send(t, listener, proxyHeader(proxyCommandLocal, net.ParseIP("10.0.0.1")))
+	// The connections are handled concurrently, they must be read before the server stops
+	time.Sleep(100 * time.Millisecond)
+	server.Shutdown(listener)
+
+	mockS3.AssertNumberOfCalls(t, "PutObject", 1)
+	input := mockS3.Calls[0].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Equal(t, "panther-http-ingest", aws.StringValue(input.Bucket))
+	assert.Regexp(t, regexp.MustCompile(`^syslog/firewalls/\d{4}/\d{2}/\d{2}/\d{2}/\d{8}T\d{6}Z-[0-9a-f-]{36}\.log\.gz$`), *input.Key)
+	reader, err := gzip.NewReader(input.Body)
+	require.NoError(t, err)
+	lines, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "<13>1 2020-04-02T16:31:03Z fw1 app - - - one\n<13>Dec  2 16:31:03 fw1 app: two\n", string(lines))
+}
+
+func TestServerShutdownClosesConnections(t *testing.T) {
+	server, listener, mockS3 := startServer(t)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(append(proxyHeader(proxyCommandProxy, net.ParseIP("192.0.2.10")), "<13>Dec  2 16:31:03 fw1 app: open\n"...))
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	// The connection is still open, its messages are written when the server stops
+	server.Shutdown(listener)
+	mockS3.AssertNumberOfCalls(t, "PutObject", 1)
+}
@@ -0,0 +1,69 @@
+package main
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/syslog_collector/collector"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+// The collector runs as an ECS service behind a network load balancer, it's not a Lambda function
+func main() {
+	config := zap.NewProductionConfig()
+	if lambdalogger.DebugEnabled {
+		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	}
+	config.InitialFields = map[string]interface{}{"application": lambdalogger.Application}
+	logger, err := config.Build()
+	if err != nil {
+		log.Fatalf("failed to build the logger: %v", err)
+	}
+	zap.ReplaceGlobals(logger)
+
+	collector.Setup()
+	listener, err := collector.Listen()
+	if err != nil {
+		zap.L().Fatal("failed to listen", zap.Error(err))
+	}
+
+	server := collector.NewServer()
+	// ECS stops the task with SIGTERM, the batches are written before it exits
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	stopped := make(chan struct{})
+	go func() {
+		<-signals
+		zap.L().Info("stopping the syslog collector")
+		server.Shutdown(listener)
+		close(stopped)
+	}()
+
+	zap.L().Info("accepting syslog connections", zap.String("address", listener.Addr().String()))
+	if err = server.Serve(listener); err != nil {
+		zap.L().Fatal("failed to accept connections", zap.Error(err))
+	}
+	<-stopped
+}
@@ -0,0 +1,105 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The largest feed which is read, in bytes
+const maxFeedSize = 64 * 1024 * 1024
+
+// ImportThreatIntelFeeds imports the indicators of the enabled feed sets, or of the requested one.
+//
+// It runs on a schedule: a failed import is stored with the set and doesn't stop the other imports.
+func (API) ImportThreatIntelFeeds(input *models.ImportThreatIntelFeedsInput) (models.ImportThreatIntelFeedsOutput, error) {
+	var sets []*models.ThreatIntelSet
+	if input.SetID != nil {
+		set, err := setsTable.GetSet(input.SetID)
+		if err != nil {
+			return nil, err
+		}
+		if set == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "threat intel set " + *input.SetID + " does not exist"}
+		}
+		if aws.StringValue(set.Source) != models.SourceFeed {
+			return nil, &genericapi.InvalidInputError{Message: "threat intel set " + *input.SetID + " has no feed"}
+		}
+		sets = append(sets, set)
+	} else {
+		all, err := setsTable.ListSets()
+		if err != nil {
+			return nil, err
+		}
+		for _, set := range all {
+			if aws.StringValue(set.Source) == models.SourceFeed && aws.BoolValue(set.Enabled) {
+				sets = append(sets, set)
+			}
+		}
+	}
+
+	result := make(models.ImportThreatIntelFeedsOutput, 0, len(sets))
+	for _, set := range sets {
+		if err := importFeed(set); err != nil {
+			zap.L().Warn("failed to import threat intel feed", zap.String("setId", *set.SetID), zap.Error(err))
+			set.LastImportError = aws.String(err.Error())
+		}
+		if err := setsTable.PutSet(set); err != nil {
+			return nil, err
+		}
+		result = append(result, set)
+	}
+	return result, nil
+}
+
+// importFeed downloads the indicators of a feed set and stores them with its indicator count.
+//
+// A failed import keeps the last indicators of the set.
+func importFeed(set *models.ThreatIntelSet) error {
+	now := nowFunc().UTC()
+	set.LastImportTime = &now
+
+	response, err := httpClient.Get(*set.FeedURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to download the feed")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download the feed: status %d", response.StatusCode)
+	}
+
+	indicators, err := parseFeed(io.LimitReader(response.Body, maxFeedSize))
+	if err != nil {
+		return err
+	}
+	if err = putIndicators(*set.SetID, indicators); err != nil {
+		return err
+	}
+	set.IndicatorCount, set.IndicatorsUpdatedAt, set.LastImportError = aws.Int(len(indicators)), &now, nil
+	return nil
+}
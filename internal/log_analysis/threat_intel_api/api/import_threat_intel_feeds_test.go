@@ -0,0 +1,107 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+func TestParseFeed(t *testing.T) {
+	feed := "# Known C2 servers\n198.51.100.7\n\n203.0.113.9,botnet # since 2020\nEVIL.example.com\tc2\n198.51.100.7\n"
+	indicators, err := parseFeed(strings.NewReader(feed))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"198.51.100.7", "203.0.113.9", "evil.example.com"}, indicators)
+}
+
+func TestImportThreatIntelFeeds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/c2.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("198.51.100.7\n203.0.113.9\n"))
+	}))
+	defer server.Close()
+
+	mockSets, mockS3Client := setupMocks()
+	lastImport := testTime.Add(-time.Hour)
+	imported := &models.ThreatIntelSet{
+		SetID:   aws.String(testSetID),
+		Source:  aws.String(models.SourceFeed),
+		FeedURL: aws.String(server.URL + "/c2.txt"),
+		Enabled: aws.Bool(true),
+	}
+	failed := &models.ThreatIntelSet{
+		SetID:               aws.String("0f1bd3a2-3b8f-4a4e-b5a4-8a0c3c1f2e11"),
+		Source:              aws.String(models.SourceFeed),
+		FeedURL:             aws.String(server.URL + "/moved.txt"),
+		Enabled:             aws.Bool(true),
+		IndicatorCount:      aws.Int(5),
+		IndicatorsUpdatedAt: &lastImport,
+	}
+	disabled := &models.ThreatIntelSet{
+		SetID:   aws.String("5e0c7a1d-0d4b-4c36-a0fb-2b0c8f0b3e77"),
+		Source:  aws.String(models.SourceFeed),
+		FeedURL: aws.String(server.URL + "/c2.txt"),
+		Enabled: aws.Bool(false),
+	}
+	user := &models.ThreatIntelSet{SetID: aws.String(testSetID), Source: aws.String(models.SourceUser), Enabled: aws.Bool(true)}
+	mockSets.On("ListSets").Return([]*models.ThreatIntelSet{imported, failed, disabled, user}, nil)
+	mockS3Client.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+	mockSets.On("PutSet", mock.Anything).Return(nil).Twice()
+
+	result, err := (API{}).ImportThreatIntelFeeds(&models.ImportThreatIntelFeedsInput{})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	mockSets.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+	assert.Equal(t, "198.51.100.7\n203.0.113.9", putBody(t, mockS3Client.Calls[0]))
+
+	assert.Equal(t, 2, *result[0].IndicatorCount)
+	assert.Equal(t, testTime, *result[0].IndicatorsUpdatedAt)
+	assert.Nil(t, result[0].LastImportError)
+
+	// The failed import keeps the last indicators
+	assert.Equal(t, 5, *result[1].IndicatorCount)
+	assert.Equal(t, lastImport, *result[1].IndicatorsUpdatedAt)
+	assert.Equal(t, testTime, *result[1].LastImportTime)
+	assert.Equal(t, "failed to download the feed: status 404", *result[1].LastImportError)
+}
+
+func TestImportThreatIntelFeedsUserSet(t *testing.T) {
+	mockSets, _ := setupMocks()
+	mockSets.On("GetSet", aws.String(testSetID)).Return(
+		&models.ThreatIntelSet{SetID: aws.String(testSetID), Source: aws.String(models.SourceUser)}, nil)
+
+	_, err := (API{}).ImportThreatIntelFeeds(&models.ImportThreatIntelFeedsInput{SetID: aws.String(testSetID)})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+}
@@ -0,0 +1,102 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// The most indicators of a feed, so the log processor can hold all the sets in memory
+const maxFeedIndicators = 1000000
+
+// normalizeIndicators returns the unique normalized indicators, in their first order
+func normalizeIndicators(indicators []string) []string {
+	seen := make(map[string]struct{}, len(indicators))
+	result := make([]string, 0, len(indicators))
+	for _, indicator := range indicators {
+		indicator = models.NormalizeIndicator(indicator)
+		if indicator == "" {
+			continue
+		}
+		if _, ok := seen[indicator]; ok {
+			continue
+		}
+		seen[indicator] = struct{}{}
+		result = append(result, indicator)
+	}
+	return result
+}
+
+// parseFeed reads the indicators of a feed: the first value of each line, # starts a comment
+func parseFeed(r io.Reader) ([]string, error) {
+	var indicators []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(fields) == 0 {
+			continue
+		}
+		indicators = append(indicators, fields[0])
+		if len(indicators) > maxFeedIndicators {
+			return nil, errors.Errorf("the feed has more than %d indicators", maxFeedIndicators)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the feed")
+	}
+	return normalizeIndicators(indicators), nil
+}
+
+// putIndicators writes the indicators of a set, one per line
+func putIndicators(setID string, indicators []string) error {
+	_, err := s3Client.PutObject(&s3.PutObjectInput{
+		Body:   strings.NewReader(strings.Join(indicators, "\n")),
+		Bucket: aws.String(env.ProcessedDataBucket),
+		Key:    aws.String(models.IndicatorsKey(setID)),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "s3.PutObject"}
+	}
+	return nil
+}
+
+// deleteIndicators deletes the indicators of a set, there may be none
+func deleteIndicators(setID string) error {
+	_, err := s3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(env.ProcessedDataBucket),
+		Key:    aws.String(models.IndicatorsKey(setID)),
+	})
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "s3.DeleteObject"}
+	}
+	return nil
+}
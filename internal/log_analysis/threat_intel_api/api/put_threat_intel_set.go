@@ -0,0 +1,94 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const defaultSeverity = "MEDIUM"
+
+// PutThreatIntelSet creates or updates a threat intel set and its indicators.
+//
+// The indicators are written before the set, so the log processor never loads a set without them.
+// The feed of a feed set is imported right away, a feed which can't be imported is not accepted.
+func (API) PutThreatIntelSet(input *models.PutThreatIntelSetInput) (*models.PutThreatIntelSetOutput, error) {
+	if len(input.Indicators) > 0 && input.FeedURL != nil {
+		return nil, &genericapi.InvalidInputError{Message: "a threat intel set has either indicators or a feed URL, not both"}
+	}
+	if len(input.Indicators) == 0 && input.FeedURL == nil {
+		return nil, &genericapi.InvalidInputError{Message: "a threat intel set needs indicators or a feed URL"}
+	}
+
+	now := nowFunc().UTC()
+	set := &models.ThreatIntelSet{
+		SetID:          input.SetID,
+		Name:           input.Name,
+		Description:    input.Description,
+		Source:         aws.String(models.SourceUser),
+		FeedURL:        input.FeedURL,
+		Enabled:        aws.Bool(aws.BoolValue(input.Enabled)),
+		AlertOnMatch:   aws.Bool(aws.BoolValue(input.AlertOnMatch)),
+		Severity:       input.Severity,
+		CreatedAtTime:  &now,
+		CreatedBy:      input.UserID,
+		LastModified:   &now,
+		LastModifiedBy: input.UserID,
+	}
+	if set.Severity == nil {
+		set.Severity = aws.String(defaultSeverity)
+	}
+
+	if input.SetID != nil {
+		existing, err := setsTable.GetSet(input.SetID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, &genericapi.DoesNotExistError{Message: "threat intel set " + *input.SetID + " does not exist"}
+		}
+		set.CreatedAtTime, set.CreatedBy = existing.CreatedAtTime, existing.CreatedBy
+	} else {
+		set.SetID = aws.String(uuid.New().String())
+	}
+
+	if input.FeedURL != nil {
+		set.Source = aws.String(models.SourceFeed)
+		if err := importFeed(set); err != nil {
+			return nil, &genericapi.InvalidInputError{Message: "failed to import the feed: " + err.Error()}
+		}
+	} else {
+		indicators := normalizeIndicators(input.Indicators)
+		if err := putIndicators(*set.SetID, indicators); err != nil {
+			return nil, err
+		}
+		set.IndicatorCount, set.IndicatorsUpdatedAt = aws.Int(len(indicators)), &now
+	}
+
+	if err := setsTable.PutSet(set); err != nil {
+		return nil, err
+	}
+	zap.L().Info("put threat intel set", zap.String("setId", *set.SetID), zap.Int("indicatorCount", *set.IndicatorCount))
+	return set, nil
+}
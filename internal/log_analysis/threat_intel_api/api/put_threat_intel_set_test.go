@@ -0,0 +1,187 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const (
+	testUserID = "97c4db4e-61d5-40a7-82de-6dd63b199bd2"
+	testSetID  = "8c2e7e84-6b4c-4f86-9b36-1c3d0f8e8a61"
+)
+
+var testTime = time.Date(2020, 3, 14, 21, 37, 42, 0, time.UTC)
+
+type mockTable struct {
+	mock.Mock
+}
+
+func (m *mockTable) GetSet(setID *string) (*models.ThreatIntelSet, error) {
+	args := m.Called(setID)
+	set, _ := args.Get(0).(*models.ThreatIntelSet)
+	return set, args.Error(1)
+}
+
+func (m *mockTable) ListSets() ([]*models.ThreatIntelSet, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.ThreatIntelSet), args.Error(1)
+}
+
+func (m *mockTable) PutSet(set *models.ThreatIntelSet) error {
+	return m.Called(set).Error(0)
+}
+
+func (m *mockTable) DeleteSet(setID *string) error {
+	return m.Called(setID).Error(0)
+}
+
+type mockS3 struct {
+	s3iface.S3API
+	mock.Mock
+}
+
+func (m *mockS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+func (m *mockS3) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*s3.DeleteObjectOutput), args.Error(1)
+}
+
+func setupMocks() (*mockTable, *mockS3) {
+	mockSets, mockS3Client := &mockTable{}, &mockS3{}
+	setsTable, s3Client = mockSets, mockS3Client
+	env.ProcessedDataBucket = "processed-data"
+	nowFunc = func() time.Time { return testTime }
+	return mockSets, mockS3Client
+}
+
+// The indicators written to S3 by a PutObject call
+func putBody(t *testing.T, call mock.Call) string {
+	body, err := ioutil.ReadAll(call.Arguments.Get(0).(*s3.PutObjectInput).Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestPutThreatIntelSet(t *testing.T) {
+	mockSets, mockS3Client := setupMocks()
+	mockS3Client.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+	mockSets.On("PutSet", mock.Anything).Return(nil).Once()
+
+	result, err := (API{}).PutThreatIntelSet(&models.PutThreatIntelSetInput{
+		Name:         aws.String("Known C2 servers"),
+		Indicators:   []string{"198.51.100.7", " Evil.Example.com ", "evil.example.com", ""},
+		Enabled:      aws.Bool(true),
+		AlertOnMatch: aws.Bool(true),
+		UserID:       aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, *result.SetID)
+	assert.Equal(t, models.SourceUser, *result.Source)
+	assert.Equal(t, "MEDIUM", *result.Severity)
+	assert.Equal(t, 2, *result.IndicatorCount)
+	assert.Equal(t, testTime, *result.IndicatorsUpdatedAt)
+	mockSets.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+
+	put := mockS3Client.Calls[0].Arguments.Get(0).(*s3.PutObjectInput)
+	assert.Equal(t, "processed-data", *put.Bucket)
+	assert.Equal(t, "threat_intel/"+*result.SetID+".txt", *put.Key)
+	assert.Equal(t, "198.51.100.7\nevil.example.com", putBody(t, mockS3Client.Calls[0]))
+}
+
+func TestPutThreatIntelSetUpdate(t *testing.T) {
+	mockSets, mockS3Client := setupMocks()
+	created := testTime.Add(-time.Hour)
+	mockSets.On("GetSet", aws.String(testSetID)).Return(&models.ThreatIntelSet{
+		SetID:         aws.String(testSetID),
+		CreatedAtTime: &created,
+		CreatedBy:     aws.String("creator"),
+	}, nil)
+	mockS3Client.On("PutObject", mock.Anything).Return(&s3.PutObjectOutput{}, nil).Once()
+	mockSets.On("PutSet", mock.Anything).Return(nil).Once()
+
+	result, err := (API{}).PutThreatIntelSet(&models.PutThreatIntelSetInput{
+		SetID:      aws.String(testSetID),
+		Name:       aws.String("Known C2 servers"),
+		Indicators: []string{"198.51.100.7"},
+		UserID:     aws.String(testUserID),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testSetID, *result.SetID)
+	assert.Equal(t, created, *result.CreatedAtTime)
+	assert.Equal(t, "creator", *result.CreatedBy)
+	assert.Equal(t, testTime, *result.LastModified)
+	assert.False(t, *result.Enabled)
+}
+
+func TestPutThreatIntelSetInvalid(t *testing.T) {
+	mockSets, mockS3Client := setupMocks()
+	mockSets.On("GetSet", aws.String(testSetID)).Return(nil, nil)
+
+	// Either indicators or a feed URL
+	_, err := (API{}).PutThreatIntelSet(&models.PutThreatIntelSetInput{Name: aws.String("empty"), UserID: aws.String(testUserID)})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+	_, err = (API{}).PutThreatIntelSet(&models.PutThreatIntelSetInput{
+		Name:       aws.String("both"),
+		Indicators: []string{"198.51.100.7"},
+		FeedURL:    aws.String("https://feeds.example.com/c2.txt"),
+		UserID:     aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.InvalidInputError{}, err)
+
+	// Only existing sets are updated
+	_, err = (API{}).PutThreatIntelSet(&models.PutThreatIntelSetInput{
+		SetID:      aws.String(testSetID),
+		Name:       aws.String("missing"),
+		Indicators: []string{"198.51.100.7"},
+		UserID:     aws.String(testUserID),
+	})
+	assert.IsType(t, &genericapi.DoesNotExistError{}, err)
+	mockS3Client.AssertNotCalled(t, "PutObject", mock.Anything)
+	mockSets.AssertNotCalled(t, "PutSet", mock.Anything)
+}
+
+func TestDeleteThreatIntelSet(t *testing.T) {
+	mockSets, mockS3Client := setupMocks()
+	mockSets.On("DeleteSet", aws.String(testSetID)).Return(nil)
+	mockS3Client.On("DeleteObject", &s3.DeleteObjectInput{
+		Bucket: aws.String("processed-data"),
+		Key:    aws.String("threat_intel/" + testSetID + ".txt"),
+	}).Return(&s3.DeleteObjectOutput{}, nil)
+
+	require.NoError(t, (API{}).DeleteThreatIntelSet(&models.DeleteThreatIntelSetInput{SetID: aws.String(testSetID)}))
+	mockSets.AssertExpectations(t)
+	mockS3Client.AssertExpectations(t)
+}
@@ -0,0 +1,49 @@
+package api
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+// GetThreatIntelSet returns a threat intel set by its ID.
+func (API) GetThreatIntelSet(input *models.GetThreatIntelSetInput) (*models.GetThreatIntelSetOutput, error) {
+	set, err := setsTable.GetSet(input.SetID)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, &genericapi.DoesNotExistError{Message: "threat intel set " + *input.SetID + " does not exist"}
+	}
+	return set, nil
+}
+
+// ListThreatIntelSets returns all the threat intel sets.
+func (API) ListThreatIntelSets(_ *models.ListThreatIntelSetsInput) (models.ListThreatIntelSetsOutput, error) {
+	return setsTable.ListSets()
+}
+
+// DeleteThreatIntelSet deletes a threat intel set, then its indicators.
+func (API) DeleteThreatIntelSet(input *models.DeleteThreatIntelSetInput) error {
+	if err := setsTable.DeleteSet(input.SetID); err != nil {
+		return err
+	}
+	return deleteIndicators(*input.SetID)
+}
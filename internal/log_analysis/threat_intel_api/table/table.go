@@ -0,0 +1,131 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const hashKey = "setId"
+
+// API defines the interface for the threat intel sets table which can be used for mocking.
+type API interface {
+	GetSet(setID *string) (*models.ThreatIntelSet, error)
+	ListSets() ([]*models.ThreatIntelSet, error)
+	PutSet(set *models.ThreatIntelSet) error
+	DeleteSet(setID *string) error
+}
+
+// SetsTable encapsulates a connection to the Dynamo threat intel sets table.
+type SetsTable struct {
+	Name   string
+	Client dynamodbiface.DynamoDBAPI
+}
+
+// The SetsTable must satisfy the API interface.
+var _ API = (*SetsTable)(nil)
+
+// GetSet returns a threat intel set by its ID, nil if it doesn't exist.
+func (table *SetsTable) GetSet(setID *string) (*models.ThreatIntelSet, error) {
+	output, err := table.Client.GetItem(&dynamodb.GetItemInput{
+		ConsistentRead: aws.Bool(true),
+		Key:            map[string]*dynamodb.AttributeValue{hashKey: {S: setID}},
+		TableName:      aws.String(table.Name),
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.GetItem"}
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+
+	var set models.ThreatIntelSet
+	if err = dynamodbattribute.UnmarshalMap(output.Item, &set); err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.UnmarshalMap"}
+	}
+	return &set, nil
+}
+
+// ListSets returns all the threat intel sets, page by page.
+func (table *SetsTable) ListSets() ([]*models.ThreatIntelSet, error) {
+	sets := make([]*models.ThreatIntelSet, 0)
+	var unmarshalErr error
+	err := table.Client.ScanPages(&dynamodb.ScanInput{
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(table.Name),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		var pageSets []*models.ThreatIntelSet
+		if unmarshalErr = dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageSets); unmarshalErr != nil {
+			return false // stop paginating
+		}
+		sets = append(sets, pageSets...)
+		return true
+	})
+	if err != nil {
+		return nil, &genericapi.AWSError{Err: err, Method: "Dynamodb.ScanPages"}
+	}
+	if unmarshalErr != nil {
+		return nil, &genericapi.AWSError{Err: unmarshalErr, Method: "Dynamodb.UnmarshalListOfMaps"}
+	}
+	return sets, nil
+}
+
+// PutSet writes a threat intel set, replacing the one with the same ID.
+func (table *SetsTable) PutSet(set *models.ThreatIntelSet) error {
+	item, err := dynamodbattribute.MarshalMap(set)
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.MarshalMap"}
+	}
+	if _, err = table.Client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: aws.String(table.Name)}); err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.PutItem"}
+	}
+	return nil
+}
+
+// DeleteSet deletes a threat intel set, a DoesNotExistError is returned if there is none with that ID.
+func (table *SetsTable) DeleteSet(setID *string) error {
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.AttributeExists(expression.Name(hashKey))).
+		Build()
+	if err != nil {
+		return &genericapi.InternalError{Message: "failed to build DeleteSet ddb expression: " + err.Error()}
+	}
+
+	_, err = table.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		ConditionExpression:      expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		Key:                      map[string]*dynamodb.AttributeValue{hashKey: {S: setID}},
+		TableName:                aws.String(table.Name),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return &genericapi.DoesNotExistError{Message: "threat intel set " + aws.StringValue(setID) + " does not exist"}
+	}
+	if err != nil {
+		return &genericapi.AWSError{Err: err, Method: "Dynamodb.DeleteItem"}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package table
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/threatintel/models"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+type mockDynamoClient struct {
+	dynamodbiface.DynamoDBAPI
+	mock.Mock
+}
+
+func (m *mockDynamoClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
+}
+
+func (m *mockDynamoClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func TestGetSet(t *testing.T) {
+	set := &models.ThreatIntelSet{
+		SetID:          aws.String("8c2e7e84-6b4c-4f86-9b36-1c3d0f8e8a61"),
+		Name:           aws.String("Known C2 servers"),
+		Source:         aws.String(models.SourceUser),
+		IndicatorCount: aws.Int(2),
+	}
+	item, err := dynamodbattribute.MarshalMap(set)
+	require.NoError(t, err)
+	mockClient := &mockDynamoClient{}
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: item}, nil).Once()
+	mockClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	table := &SetsTable{Name: "test", Client: mockClient}
+
+	result, err := table.GetSet(set.SetID)
+	require.NoError(t, err)
+	assert.Equal(t, set, result)
+
+	result, err = table.GetSet(aws.String("0f1bd3a2-3b8f-4a4e-b5a4-8a0c3c1f2e11"))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestDeleteSetDoesNotExist(t *testing.T) {
+	mockClient := &mockDynamoClient{}
+	mockClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{},
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+	table := &SetsTable{Name: "test", Client: mockClient}
+
+	assert.IsType(t, &genericapi.DoesNotExistError{}, table.DeleteSet(aws.String("0f1bd3a2-3b8f-4a4e-b5a4-8a0c3c1f2e11")))
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	"github.com/panther-labs/panther/pkg/parquet"
 )
 
 const (
@@ -234,3 +235,55 @@ func (gm *GlueTableMetadata) partitionValues(t time.Time) (values []*string) {
 	}
 	return
 }
+
+// GetParquetSchema returns the schema of the Parquet objects of a table, from its columns in Glue.
+//
+// The Parquet columns are read by name, the objects written before a column was added read as null in that column.
+func GetParquetSchema(client glueiface.GlueAPI, databaseName, tableName string) (*parquet.Schema, error) {
+	tableOutput, err := client.GetTable(&glue.GetTableInput{
+		DatabaseName: aws.String(databaseName),
+		Name:         aws.String(tableName),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get table %s.%s", databaseName, tableName)
+	}
+	columns := make([]parquet.Column, len(tableOutput.Table.StorageDescriptor.Columns))
+	for i, column := range tableOutput.Table.StorageDescriptor.Columns {
+		columns[i] = parquet.Column{Name: aws.StringValue(column.Name), Type: aws.StringValue(column.Type)}
+	}
+	schema, err := parquet.NewSchema(columns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unsupported columns in table %s.%s", databaseName, tableName)
+	}
+	return schema, nil
+}
+
+// CreateOrUpdateJSONTable creates the table of the gzipped JSON lines of the processed data in the bucket,
+// or replaces the columns of the table if it already exists.
+func (gm *GlueTableMetadata) CreateOrUpdateJSONTable(client glueiface.GlueAPI, bucket string, columns []*glue.Column) error {
+	descriptor := getJSONPartitionDescriptor("s3://" + bucket + "/" + gm.Prefix())
+	descriptor.Columns = columns
+	tableInput := &glue.TableInput{
+		Name:              aws.String(gm.tableName),
+		TableType:         aws.String("EXTERNAL_TABLE"),
+		StorageDescriptor: descriptor,
+	}
+	if gm.description != "" {
+		tableInput.Description = aws.String(gm.description)
+	}
+	for _, key := range gm.PartitionKeys() {
+		tableInput.PartitionKeys = append(tableInput.PartitionKeys, &glue.Column{
+			Name: aws.String(key.Name),
+			Type: aws.String(key.Type),
+		})
+	}
+
+	_, err := client.CreateTable(&glue.CreateTableInput{DatabaseName: aws.String(gm.databaseName), TableInput: tableInput})
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == glue.ErrCodeAlreadyExistsException {
+		_, err = client.UpdateTable(&glue.UpdateTableInput{DatabaseName: aws.String(gm.databaseName), TableInput: tableInput})
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create or update table %s.%s", gm.databaseName, gm.tableName)
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
 )
@@ -167,6 +168,28 @@ func TestSyncPartitionDeletePartitionNonAWSError(t *testing.T) {
 	glueClient.AssertExpectations(t)
 }
 
+func TestGetParquetSchema(t *testing.T) {
+	glueClient := &mockGlue{}
+	glueClient.On("GetTable", &glue.GetTableInput{DatabaseName: aws.String("db"), Name: aws.String("table")}).
+		Return(&glue.GetTableOutput{Table: &glue.TableData{StorageDescriptor: &glue.StorageDescriptor{
+			Columns: []*glue.Column{
+				{Name: aws.String("eventName"), Type: aws.String("string")},
+				{Name: aws.String("tags"), Type: aws.String("array<string>")},
+			},
+		}}}, nil).Once()
+	schema, err := GetParquetSchema(glueClient, "db", "table")
+	require.NoError(t, err)
+	assert.NotNil(t, schema)
+	glueClient.AssertExpectations(t)
+
+	glueClient.On("GetTable", mock.Anything).
+		Return(&glue.GetTableOutput{Table: &glue.TableData{StorageDescriptor: &glue.StorageDescriptor{
+			Columns: []*glue.Column{{Name: aws.String("amount"), Type: aws.String("decimal(10,2)")}},
+		}}}, nil).Once()
+	_, err = GetParquetSchema(glueClient, "db", "table")
+	assert.Error(t, err)
+}
+
 type mockGlue struct {
 	glueiface.GlueAPI
 	mock.Mock
@@ -187,7 +210,50 @@ func (m *mockGlue) CreatePartition(input *glue.CreatePartitionInput) (*glue.Crea
 	return args.Get(0).(*glue.CreatePartitionOutput), args.Error(1)
 }
 
+func (m *mockGlue) UpdatePartition(input *glue.UpdatePartitionInput) (*glue.UpdatePartitionOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.UpdatePartitionOutput), args.Error(1)
+}
+
 func (m *mockGlue) DeletePartition(input *glue.DeletePartitionInput) (*glue.DeletePartitionOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*glue.DeletePartitionOutput), args.Error(1)
 }
+
+func (m *mockGlue) CreateTable(input *glue.CreateTableInput) (*glue.CreateTableOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.CreateTableOutput), args.Error(1)
+}
+
+func (m *mockGlue) UpdateTable(input *glue.UpdateTableInput) (*glue.UpdateTableOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*glue.UpdateTableOutput), args.Error(1)
+}
+
+func TestCreateOrUpdateJSONTable(t *testing.T) {
+	gm := NewGlueTableMetadata(models.RuleData, "Custom.Firewall", "firewall logs", GlueTableHourly, nil)
+	columns := []*glue.Column{{Name: aws.String("src_ip"), Type: aws.String("string")}}
+
+	// The table is created
+	mockClient := &mockGlue{}
+	mockClient.On("CreateTable", mock.Anything).Return(&glue.CreateTableOutput{}, nil).Once()
+	require.NoError(t, gm.CreateOrUpdateJSONTable(mockClient, "bucket", columns))
+	mockClient.AssertExpectations(t)
+	input := mockClient.Calls[0].Arguments.Get(0).(*glue.CreateTableInput)
+	assert.Equal(t, RuleMatchDatabaseName, *input.DatabaseName)
+	assert.Equal(t, "custom_firewall", *input.TableInput.Name)
+	assert.Equal(t, "s3://bucket/rules/custom_firewall/", *input.TableInput.StorageDescriptor.Location)
+	assert.Equal(t, columns, input.TableInput.StorageDescriptor.Columns)
+	assert.Len(t, input.TableInput.PartitionKeys, 4)
+
+	// The existing table is updated
+	mockClient = &mockGlue{}
+	mockClient.On("CreateTable", mock.Anything).Return(&glue.CreateTableOutput{}, entityExistsError).Once()
+	mockClient.On("UpdateTable", mock.Anything).Return(&glue.UpdateTableOutput{}, nil).Once()
+	require.NoError(t, gm.CreateOrUpdateJSONTable(mockClient, "bucket", columns))
+	mockClient.AssertExpectations(t)
+
+	mockClient = &mockGlue{}
+	mockClient.On("CreateTable", mock.Anything).Return(&glue.CreateTableOutput{}, otherAWSError).Once()
+	assert.Error(t, gm.CreateOrUpdateJSONTable(mockClient, "bucket", columns))
+}
@@ -35,14 +35,24 @@ import (
 // NOTE: this struct has all accessor behind functions to allow a lazy evaluation
 //       so the cost of creating the schema is only when actually needing this information.
 
+const (
+	// JSONObjectSuffix is the suffix of the keys of the gzip JSON lines objects
+	JSONObjectSuffix = ".json.gz"
+	// ParquetObjectSuffix is the suffix of the keys of the snappy Parquet objects
+	ParquetObjectSuffix = ".parquet"
+
+	parquetDataFormat           = "parquet"
+	parquetSerializationLibrary = "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"
+)
+
 // A partition in Glue containing Panther data
 type GluePartition struct {
 	datatype         models.DataType
 	databaseName     string
 	tableName        string
 	s3Bucket         string
-	dataFormat       string // Can currently be only "json"
-	compression      string // Can only be "gzip" currently
+	dataFormat       string // Can be "json" or "parquet"
+	compression      string // Can be "gzip" for "json" or "snappy" for "parquet"
 	partitionColumns []PartitionColumnInfo
 }
 
@@ -97,6 +107,38 @@ func (gp *GluePartition) GetPartitionLocation() string {
 	return prefix
 }
 
+// GetTime returns the start of the time range of the partition.
+func (gp *GluePartition) GetTime() time.Time {
+	fields := []int{0, 1, 1, 0} // year, month, day, hour
+	for i, column := range gp.partitionColumns {
+		fields[i], _ = strconv.Atoi(column.Value) // the values are integers, see inferPartitionColumnInfo()
+	}
+	return time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], 0, 0, 0, time.UTC)
+}
+
+// GetTimebin returns the time resolution of the partition, from its partition columns.
+func (gp *GluePartition) GetTimebin() GlueTableTimebin {
+	// One column for each partition key after the year: monthly, daily then hourly
+	return GlueTableTimebin(len(gp.partitionColumns) - 1)
+}
+
+// NextPartition returns the partition of the same table and format for the next time range, e.g. the next hour.
+func (gp *GluePartition) NextPartition() *GluePartition {
+	next := gp.GetTimebin().Next(gp.GetTime())
+	values := []string{
+		strconv.Itoa(next.Year()),
+		fmt.Sprintf("%02d", next.Month()),
+		fmt.Sprintf("%02d", next.Day()),
+		fmt.Sprintf("%02d", next.Hour()),
+	}
+	partition := *gp
+	partition.partitionColumns = make([]PartitionColumnInfo, len(gp.partitionColumns))
+	for i, column := range gp.partitionColumns {
+		partition.partitionColumns[i] = PartitionColumnInfo{Key: column.Key, Value: values[i]}
+	}
+	return &partition
+}
+
 // Contains information about partition columns
 type PartitionColumnInfo struct {
 	Key   string
@@ -104,15 +146,13 @@ type PartitionColumnInfo struct {
 }
 
 // Creates a new partition in Glue using the client provided.
+//
+// Parquet objects can't be read from a partition which already exists with the JSON format:
+// an error is returned until the partition is migrated to Parquet.
 func (gp *GluePartition) CreatePartition(client glueiface.GlueAPI) error {
-	partitionValues := make([]*string, len(gp.partitionColumns))
-	for i, field := range gp.partitionColumns {
-		partitionValues[i] = aws.String(field.Value)
-	}
-	partitionPrefix := gp.GetPartitionLocation()
 	partitionInput := &glue.PartitionInput{
-		Values:            partitionValues,
-		StorageDescriptor: getJSONPartitionDescriptor(partitionPrefix), // We only support JSON currently
+		Values:            gp.partitionValues(),
+		StorageDescriptor: gp.getPartitionDescriptor(),
 	}
 	input := &glue.CreatePartitionInput{
 		DatabaseName:   aws.String(gp.databaseName),
@@ -123,6 +163,9 @@ func (gp *GluePartition) CreatePartition(client glueiface.GlueAPI) error {
 	if err != nil {
 		if awsErr, ok := err.(awserr.Error); ok {
 			if awsErr.Code() == glue.ErrCodeAlreadyExistsException {
+				if gp.dataFormat == parquetDataFormat {
+					return gp.checkPartitionFormat(client)
+				}
 				return nil
 			}
 		}
@@ -131,6 +174,52 @@ func (gp *GluePartition) CreatePartition(client glueiface.GlueAPI) error {
 	return nil
 }
 
+// UpdatePartition sets the format of an existing partition to the one of the partition.
+func (gp *GluePartition) UpdatePartition(client glueiface.GlueAPI) error {
+	_, err := client.UpdatePartition(&glue.UpdatePartitionInput{
+		DatabaseName:       aws.String(gp.databaseName),
+		TableName:          aws.String(gp.tableName),
+		PartitionValueList: gp.partitionValues(),
+		PartitionInput: &glue.PartitionInput{
+			Values:            gp.partitionValues(),
+			StorageDescriptor: gp.getPartitionDescriptor(),
+		},
+	})
+	return errors.Wrap(err, "failed to update partition")
+}
+
+func (gp *GluePartition) checkPartitionFormat(client glueiface.GlueAPI) error {
+	output, err := client.GetPartition(&glue.GetPartitionInput{
+		DatabaseName:    aws.String(gp.databaseName),
+		TableName:       aws.String(gp.tableName),
+		PartitionValues: gp.partitionValues(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get existing partition")
+	}
+	serde := output.Partition.StorageDescriptor.SerdeInfo.SerializationLibrary
+	if aws.StringValue(serde) != parquetSerializationLibrary {
+		return errors.Errorf("partition %s already exists with the serde %s: migrate it to Parquet with the parquetmigrate tool",
+			gp.GetPartitionLocation(), aws.StringValue(serde))
+	}
+	return nil
+}
+
+func (gp *GluePartition) partitionValues() []*string {
+	partitionValues := make([]*string, len(gp.partitionColumns))
+	for i, field := range gp.partitionColumns {
+		partitionValues[i] = aws.String(field.Value)
+	}
+	return partitionValues
+}
+
+func (gp *GluePartition) getPartitionDescriptor() *glue.StorageDescriptor {
+	if gp.dataFormat == parquetDataFormat {
+		return getParquetPartitionDescriptor(gp.GetPartitionLocation())
+	}
+	return getJSONPartitionDescriptor(gp.GetPartitionLocation())
+}
+
 func getJSONPartitionDescriptor(s3Path string) *glue.StorageDescriptor {
 	return &glue.StorageDescriptor{
 		InputFormat:  aws.String("org.apache.hadoop.mapred.TextInputFormat"),
@@ -146,17 +235,36 @@ func getJSONPartitionDescriptor(s3Path string) *glue.StorageDescriptor {
 	}
 }
 
+func getParquetPartitionDescriptor(s3Path string) *glue.StorageDescriptor {
+	return &glue.StorageDescriptor{
+		InputFormat:  aws.String("org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat"),
+		OutputFormat: aws.String("org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat"),
+		SerdeInfo: &glue.SerDeInfo{
+			SerializationLibrary: aws.String(parquetSerializationLibrary),
+			Parameters: map[string]*string{
+				"serialization.format": aws.String("1"),
+			},
+		},
+		Location: aws.String(s3Path),
+	}
+}
+
 // Gets the partition from S3bucket and S3 object key info.
 // The s3Object key is expected to be in the the format
-// `{logs,rules}/{table_name}/year=d{4}/month=d{2}/[day=d{2}/][hour=d{2}/]/{S+}.json.gz` otherwise an error is returned.
+// `{logs,rules}/{table_name}/year=d{4}/month=d{2}/[day=d{2}/][hour=d{2}/]/{S+}.{json.gz,parquet}` otherwise an error is returned.
 func GetPartitionFromS3(s3Bucket, s3ObjectKey string) (*GluePartition, error) {
 	partition := &GluePartition{s3Bucket: s3Bucket}
 
-	if !strings.HasSuffix(s3ObjectKey, ".json.gz") {
-		return nil, errors.New("currently only GZIP json is supported")
+	switch {
+	case strings.HasSuffix(s3ObjectKey, JSONObjectSuffix):
+		partition.compression = "gzip"
+		partition.dataFormat = "json"
+	case strings.HasSuffix(s3ObjectKey, ParquetObjectSuffix):
+		partition.compression = "snappy"
+		partition.dataFormat = parquetDataFormat
+	default:
+		return nil, errors.New("currently only GZIP json and Parquet are supported")
 	}
-	partition.compression = "gzip"
-	partition.dataFormat = "json"
 
 	s3Keys := strings.Split(s3ObjectKey, "/")
 	if len(s3Keys) < 4 {
@@ -19,6 +19,7 @@ package awsglue
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -61,6 +62,24 @@ func TestCreatePartitionFromS3Rule(t *testing.T) {
 	assert.Equal(t, expectedPartitionValues, partition.GetPartitionColumnsInfo())
 }
 
+func TestNextPartition(t *testing.T) {
+	partition, err := GetPartitionFromS3("bucket", "logs/table/year=2020/month=12/day=31/hour=23/item.parquet")
+	require.NoError(t, err)
+	assert.Equal(t, GlueTableHourly, partition.GetTimebin())
+	assert.Equal(t, time.Date(2020, 12, 31, 23, 0, 0, 0, time.UTC), partition.GetTime())
+
+	next := partition.NextPartition()
+	assert.Equal(t, "s3://bucket/logs/table/year=2021/month=01/day=01/hour=00/", next.GetPartitionLocation())
+	assert.Equal(t, "parquet", next.GetDataFormat())
+	assert.Equal(t, LogProcessingDatabaseName, next.GetDatabase())
+	// The partition itself is unchanged
+	assert.Equal(t, "s3://bucket/logs/table/year=2020/month=12/day=31/hour=23/", partition.GetPartitionLocation())
+
+	partition, err = GetPartitionFromS3("bucket", "logs/table/year=2020/month=02/item.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "s3://bucket/logs/table/year=2020/month=03/", partition.NextPartition().GetPartitionLocation())
+}
+
 func TestCreatePartitionFromS3Log(t *testing.T) {
 	s3ObjectKey := "logs/table/year=2020/month=02/day=26/hour=15/item.json.gz"
 	partition, err := GetPartitionFromS3("bucket", s3ObjectKey)
@@ -145,7 +164,7 @@ func TestCreatePartitionMisingYearPartition(t *testing.T) {
 }
 
 func TestCreatePartitionUknownFormat(t *testing.T) {
-	s3ObjectKey := "rules/table/year=2020/month=02/day=26/hour=15/rule_id=Rule.Id/item.parquet"
+	s3ObjectKey := "rules/table/year=2020/month=02/day=26/hour=15/rule_id=Rule.Id/item.csv"
 	_, err := GetPartitionFromS3("bucket", s3ObjectKey)
 	require.Error(t, err)
 }
@@ -187,3 +206,89 @@ func TestCreatePartitionGeneralError(t *testing.T) {
 	assert.Error(t, partition.CreatePartition(mockClient))
 	mockClient.AssertExpectations(t)
 }
+
+func TestCreatePartitionParquet(t *testing.T) {
+	s3ObjectKey := "logs/table/year=2020/month=02/day=26/hour=15/item.parquet"
+	partition, err := GetPartitionFromS3("bucket", s3ObjectKey)
+	require.NoError(t, err)
+	assert.Equal(t, "parquet", partition.GetDataFormat())
+	assert.Equal(t, "snappy", partition.GetCompression())
+
+	expectedCreatePartitionInput := &glue.CreatePartitionInput{
+		DatabaseName: aws.String(LogProcessingDatabaseName),
+		TableName:    aws.String("table"),
+		PartitionInput: &glue.PartitionInput{
+			StorageDescriptor: &glue.StorageDescriptor{
+				InputFormat:  aws.String("org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat"),
+				OutputFormat: aws.String("org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat"),
+				SerdeInfo: &glue.SerDeInfo{
+					SerializationLibrary: aws.String("org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"),
+					Parameters:           map[string]*string{"serialization.format": aws.String("1")},
+				},
+				Location: aws.String("s3://bucket/logs/table/year=2020/month=02/day=26/hour=15/"),
+			},
+			Values: aws.StringSlice([]string{"2020", "02", "26", "15"}),
+		},
+	}
+
+	mockClient := &mockGlue{}
+	mockClient.On("CreatePartition", expectedCreatePartitionInput).Return(&glue.CreatePartitionOutput{}, nil)
+
+	assert.NoError(t, partition.CreatePartition(mockClient))
+	mockClient.AssertExpectations(t)
+}
+
+func TestCreatePartitionParquetAlreadyExists(t *testing.T) {
+	partition, err := GetPartitionFromS3("bucket", "logs/table/year=2020/month=02/day=26/hour=15/item.parquet")
+	require.NoError(t, err)
+
+	mockClient := &mockGlue{}
+	mockClient.On("CreatePartition", mock.Anything).
+		Return(&glue.CreatePartitionOutput{}, awserr.New(glue.ErrCodeAlreadyExistsException, "error", nil))
+	mockClient.On("GetPartition", &glue.GetPartitionInput{
+		DatabaseName:    aws.String(LogProcessingDatabaseName),
+		TableName:       aws.String("table"),
+		PartitionValues: aws.StringSlice([]string{"2020", "02", "26", "15"}),
+	}).Return(&glue.GetPartitionOutput{Partition: &glue.Partition{
+		StorageDescriptor: getParquetPartitionDescriptor(partition.GetPartitionLocation()),
+	}}, nil)
+
+	assert.NoError(t, partition.CreatePartition(mockClient))
+	mockClient.AssertExpectations(t)
+}
+
+func TestCreatePartitionParquetExistsAsJSON(t *testing.T) {
+	partition, err := GetPartitionFromS3("bucket", "logs/table/year=2020/month=02/day=26/hour=15/item.parquet")
+	require.NoError(t, err)
+
+	mockClient := &mockGlue{}
+	mockClient.On("CreatePartition", mock.Anything).
+		Return(&glue.CreatePartitionOutput{}, awserr.New(glue.ErrCodeAlreadyExistsException, "error", nil))
+	mockClient.On("GetPartition", mock.Anything).Return(&glue.GetPartitionOutput{Partition: &glue.Partition{
+		StorageDescriptor: getJSONPartitionDescriptor(partition.GetPartitionLocation()),
+	}}, nil)
+
+	err = partition.CreatePartition(mockClient)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "migrate it to Parquet")
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdatePartition(t *testing.T) {
+	partition, err := GetPartitionFromS3("bucket", "logs/table/year=2020/month=02/item.parquet")
+	require.NoError(t, err)
+
+	mockClient := &mockGlue{}
+	mockClient.On("UpdatePartition", &glue.UpdatePartitionInput{
+		DatabaseName:       aws.String(LogProcessingDatabaseName),
+		TableName:          aws.String("table"),
+		PartitionValueList: aws.StringSlice([]string{"2020", "02"}),
+		PartitionInput: &glue.PartitionInput{
+			Values:            aws.StringSlice([]string{"2020", "02"}),
+			StorageDescriptor: getParquetPartitionDescriptor("s3://bucket/logs/table/year=2020/month=02/"),
+		},
+	}).Return(&glue.UpdatePartitionOutput{}, nil)
+
+	assert.NoError(t, partition.UpdatePartition(mockClient))
+	mockClient.AssertExpectations(t)
+}
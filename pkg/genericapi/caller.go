@@ -0,0 +1,91 @@
+package genericapi
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// Caller identifies the user of the Panther web application invoking an API through AppSync.
+//
+// The Lambda input struct has an optional *Caller field next to its routes, which is not a route.
+// AppSync sets it from the claims of the ID token of the user.
+// Without a Caller, the API is invoked by another Panther service, whose IAM role already authorized it.
+type Caller struct {
+	UserID *string `json:"userId"`
+	Role   *string `json:"role"`
+
+	// Space-separated, as the "permissions" claim of the ID token
+	Permissions *string `json:"permissions"`
+}
+
+var callerType = reflect.TypeOf((*Caller)(nil))
+
+// HasPermission returns true if the role of the caller has the given permission.
+func (c *Caller) HasPermission(permission string) bool {
+	for _, p := range strings.Fields(aws.StringValue(c.Permissions)) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission declares the permission the callers need to invoke the given routes.
+//
+// A route without a permission can't be invoked by a caller at all, only by other Panther services.
+func (r *Router) RequirePermission(permission string, routes ...string) *Router {
+	for _, route := range routes {
+		r.permissions[route] = permission
+	}
+	return r
+}
+
+// Returns a PermissionDeniedError unless the caller of the request, if any, can invoke its route.
+func (r *Router) authorize(lambdaInput interface{}, route string) error {
+	caller := findCaller(lambdaInput)
+	if caller == nil {
+		return nil
+	}
+
+	permission, ok := r.permissions[route]
+	if !ok {
+		return &PermissionDeniedError{Route: route, Message: "the route is only available to Panther services"}
+	}
+	if !caller.HasPermission(permission) {
+		return &PermissionDeniedError{Route: route, Message: fmt.Sprintf(
+			"the %s permission is required, role %s does not have it", permission, aws.StringValue(caller.Role))}
+	}
+	return nil
+}
+
+// findCaller returns the Caller field of the Lambda input struct, or nil if it has none.
+func findCaller(lambdaInput interface{}) *Caller {
+	structValue := reflect.Indirect(reflect.ValueOf(lambdaInput))
+	for i := 0; i < structValue.NumField(); i++ {
+		if structValue.Type().Field(i).Type == callerType {
+			return structValue.Field(i).Interface().(*Caller)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package genericapi
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type callerLambdaInput struct {
+	Caller     *Caller `json:"caller"`
+	AddRule    *addRuleInput
+	DeleteRule *deleteRuleInput
+	UpdateRule *updateRuleInput
+}
+
+func callerRouter() *Router {
+	return NewRouter("testNamespace", "testComponent", nil, &routes{}).
+		RequirePermission("RuleModify", "AddRule", "UpdateRule")
+}
+
+func TestCallerHasPermission(t *testing.T) {
+	caller := &Caller{Permissions: aws.String("RuleRead RuleModify")}
+	assert.True(t, caller.HasPermission("RuleModify"))
+	assert.False(t, caller.HasPermission("Rule"))
+	assert.False(t, (&Caller{}).HasPermission("RuleRead"))
+}
+
+func TestHandleCallerAllowed(t *testing.T) {
+	result, err := callerRouter().Handle(&callerLambdaInput{
+		Caller:  &Caller{Role: aws.String("Analyst"), Permissions: aws.String("RuleRead RuleModify")},
+		AddRule: &addRuleInput{Name: aws.String("AddRule")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &addRuleOutput{RuleID: aws.String(mockID)}, result)
+}
+
+func TestHandleCallerDenied(t *testing.T) {
+	result, err := callerRouter().Handle(&callerLambdaInput{
+		Caller:  &Caller{Role: aws.String("ReadOnly"), Permissions: aws.String("RuleRead")},
+		AddRule: &addRuleInput{Name: aws.String("AddRule")},
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, &PermissionDeniedError{
+		Route:   "AddRule",
+		Message: "the RuleModify permission is required, role ReadOnly does not have it",
+	}, err)
+}
+
+func TestHandleCallerRouteWithoutPermission(t *testing.T) {
+	result, err := callerRouter().Handle(&callerLambdaInput{
+		Caller:     &Caller{Role: aws.String("Admin"), Permissions: aws.String("RuleRead RuleModify")},
+		DeleteRule: &deleteRuleInput{RuleID: aws.String(mockID)},
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, &PermissionDeniedError{
+		Route: "DeleteRule", Message: "the route is only available to Panther services"}, err)
+}
+
+func TestHandleWithoutCaller(t *testing.T) {
+	// Other Panther services don't send a caller, they are authorized by IAM
+	result, err := callerRouter().Handle(&callerLambdaInput{DeleteRule: &deleteRuleInput{RuleID: aws.String(mockID)}})
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+}
+
+func TestVerifyHandlersCaller(t *testing.T) {
+	assert.NoError(t, callerRouter().VerifyHandlers(&callerLambdaInput{}))
+}
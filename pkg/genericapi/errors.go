@@ -41,6 +41,18 @@ func (e *AWSError) Error() string {
 	return e.Route + " failed: AWS " + e.Method + " error: " + e.Err.Error()
 }
 
+// ConflictError is raised if the request conflicts with the current state of another item.
+//
+// For example, a unique attribute which is already used by a different item.
+type ConflictError struct {
+	Route   string
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Route + " failed: conflict: " + e.Message
+}
+
 // DoesNotExistError is raised if the item being retrieved or modified does not exist.
 type DoesNotExistError struct {
 	Route   string
@@ -129,3 +141,37 @@ func (e *LambdaError) Error() string {
 
 	return result + *e.ErrorMessage
 }
+
+// PermissionDeniedError is raised if the role of the caller doesn't have the permission the route requires.
+type PermissionDeniedError struct {
+	Route   string
+	Message string
+}
+
+func (e *PermissionDeniedError) Error() string {
+	return e.Route + " failed: permission denied: " + e.Message
+}
+
+// PreconditionFailedError is raised if the request was conditional and its precondition doesn't hold.
+//
+// For example, an update expecting the item to be in a state it is no longer in.
+type PreconditionFailedError struct {
+	Route   string
+	Message string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Route + " failed: precondition failed: " + e.Message
+}
+
+// UnavailableError is raised if the operation is not allowed right now, but may be later.
+//
+// For example, starting a scan during a blackout window of the integration.
+type UnavailableError struct {
+	Route   string
+	Message string
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Route + " failed: unavailable: " + e.Message
+}
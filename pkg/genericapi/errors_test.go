@@ -34,6 +34,11 @@ func TestAWSError(t *testing.T) {
 	assert.Equal(t, "Do failed: AWS dynamodb.PutItem error: not authorized", err.Error())
 }
 
+func TestConflictError(t *testing.T) {
+	err := &ConflictError{Route: "Do", Message: "label=panther"}
+	assert.Equal(t, "Do failed: conflict: label=panther", err.Error())
+}
+
 func TestDoesNotExistError(t *testing.T) {
 	err := &DoesNotExistError{Route: "Do", Message: "name=panther"}
 	assert.Equal(t, "Do failed: does not exist: name=panther", err.Error())
@@ -64,3 +69,13 @@ func TestLambdaError(t *testing.T) {
 		Route: "Do", FunctionName: "rules-api", ErrorMessage: aws.String("task timed out")}
 	assert.Equal(t, "Do failed: lambda error returned: rules-api: task timed out", err.Error())
 }
+
+func TestPreconditionFailedError(t *testing.T) {
+	err := &PreconditionFailedError{Route: "Do", Message: "status is scanning"}
+	assert.Equal(t, "Do failed: precondition failed: status is scanning", err.Error())
+}
+
+func TestUnavailableError(t *testing.T) {
+	err := &UnavailableError{Route: "Do", Message: "blackout until noon"}
+	assert.Equal(t, "Do failed: unavailable: blackout until noon", err.Error())
+}
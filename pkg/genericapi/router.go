@@ -35,6 +35,7 @@ type Router struct {
 	validate     *validator.Validate      // input validation
 	routes       reflect.Value            // handler functions
 	routesByName map[string]reflect.Value // cache routeName => handler function
+	permissions  map[string]string        // routeName => permission required from the callers
 }
 
 // NewRouter initializes a Router with the handler functions and validator.
@@ -52,6 +53,7 @@ func NewRouter(namespace, component string, validate *validator.Validate, routes
 		validate:     validate,
 		routes:       reflected,
 		routesByName: make(map[string]reflect.Value, reflected.NumMethod()),
+		permissions:  make(map[string]string),
 	}
 }
 
@@ -79,6 +81,10 @@ func (r *Router) Handle(input interface{}) (output interface{}, err error) {
 		return nil, &InvalidInputError{Route: req.route, Message: err.Error()}
 	}
 
+	if err = r.authorize(input, req.route); err != nil {
+		return nil, err
+	}
+
 	// Find the handler function, either cached or reflected.
 	var handler reflect.Value
 	var ok bool
@@ -118,7 +124,7 @@ func findRequest(lambdaInput interface{}) (*request, error) {
 	var result *request
 	for i := 0; i < structValue.NumField(); i++ {
 		fieldValue := structValue.Field(i)
-		if fieldValue.IsNil() {
+		if fieldValue.IsNil() || fieldValue.Type() == callerType {
 			continue
 		}
 
@@ -26,16 +26,27 @@ import (
 // This should be part of the unit tests for your Lambda function.
 func (r *Router) VerifyHandlers(lambdaInput interface{}) error {
 	inputValue := reflect.Indirect(reflect.ValueOf(lambdaInput))
+	inputType := inputValue.Type()
+
+	// The Caller field is not a route
 	numFields := inputValue.NumField()
+	numRoutes := numFields
+	for i := 0; i < numFields; i++ {
+		if inputType.Field(i).Type == callerType {
+			numRoutes--
+		}
+	}
 
-	if numFields != r.routes.NumMethod() {
+	if numRoutes != r.routes.NumMethod() {
 		return &InternalError{Message: fmt.Sprintf(
-			"input has %d fields but there are %d handlers", numFields, r.routes.NumMethod())}
+			"input has %d fields but there are %d handlers", numRoutes, r.routes.NumMethod())}
 	}
 
 	// Loop over the fields in the lambda input struct
-	inputType := inputValue.Type()
 	for i := 0; i < numFields; i++ {
+		if inputType.Field(i).Type == callerType {
+			continue
+		}
 		handlerName := inputType.Field(i).Name
 		handler := r.routes.MethodByName(handlerName)
 		if !handler.IsValid() {
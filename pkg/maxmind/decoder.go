@@ -0,0 +1,234 @@
+package maxmind
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// The types of the data section values
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEnd       = 13
+	typeBoolean   = 14
+	typeFloat     = 15
+)
+
+var errTruncated = errors.New("invalid MaxMind DB file: truncated data section")
+
+// decoder decodes the values of a data section, pointers are offsets in the section
+type decoder struct {
+	buf []byte
+}
+
+// decode returns the value at an offset and the offset of the next value.
+//
+// Unsigned integers are uint64 (*big.Int for uint128), int32 values are int64 and floats are float64.
+func (d decoder) decode(offset uint) (interface{}, uint, error) {
+	valueType, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if valueType == typePointer {
+		pointer, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		// The value of the pointer can't be a pointer itself
+		value, _, err := d.decodeValue(pointer)
+		return value, next, err
+	}
+	return d.decodeType(valueType, size, offset)
+}
+
+func (d decoder) decodeValue(offset uint) (interface{}, uint, error) {
+	valueType, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if valueType == typePointer {
+		return nil, 0, errors.New("invalid MaxMind DB file: pointer to a pointer")
+	}
+	return d.decodeType(valueType, size, offset)
+}
+
+// control reads the type and the size of the value at an offset, and the offset of its payload
+func (d decoder) control(offset uint) (valueType, size, next uint, err error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, errTruncated
+	}
+	ctrl := d.buf[offset]
+	offset++
+	valueType = uint(ctrl >> 5)
+	if valueType == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, errTruncated
+		}
+		valueType = 7 + uint(d.buf[offset])
+		offset++
+	}
+	size = uint(ctrl & 0x1f)
+	if valueType == typePointer || size < 29 {
+		return valueType, size, offset, nil
+	}
+
+	extra := size - 28 // 1 to 3 bytes of size follow
+	if offset+extra > uint(len(d.buf)) {
+		return 0, 0, 0, errTruncated
+	}
+	n := d.readUint(offset, extra)
+	switch extra {
+	case 1:
+		size = 29 + n
+	case 2:
+		size = 285 + n
+	default:
+		size = 65821 + n
+	}
+	return valueType, size, offset + extra, nil
+}
+
+// pointer returns the offset a pointer points to, from the size bits of its control byte
+func (d decoder) pointer(size, offset uint) (pointer, next uint, err error) {
+	count := ((size >> 3) & 3) + 1
+	if offset+count > uint(len(d.buf)) {
+		return 0, 0, errTruncated
+	}
+	n := d.readUint(offset, count)
+	switch count {
+	case 1:
+		pointer = (size&7)<<8 | n
+	case 2:
+		pointer = ((size&7)<<16 | n) + 2048
+	case 3:
+		pointer = ((size&7)<<24 | n) + 526336
+	default:
+		pointer = n
+	}
+	return pointer, offset + count, nil
+}
+
+func (d decoder) readUint(offset, count uint) uint {
+	var n uint
+	for _, b := range d.buf[offset : offset+count] {
+		n = n<<8 | uint(b)
+	}
+	return n
+}
+
+func (d decoder) decodeType(valueType, size, offset uint) (interface{}, uint, error) {
+	switch valueType {
+	case typeMap:
+		return d.decodeMap(size, offset)
+	case typeArray:
+		return d.decodeArray(size, offset)
+	case typeBoolean:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errTruncated
+	}
+	payload, next := d.buf[offset:offset+size], offset+size
+	switch valueType {
+	case typeString:
+		return string(payload), next, nil
+	case typeBytes:
+		return append([]byte(nil), payload...), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.Errorf("invalid MaxMind DB double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.Errorf("invalid MaxMind DB float size %d", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(payload))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, errors.Errorf("invalid MaxMind DB integer size %d", size)
+		}
+		var n uint64
+		for _, b := range payload {
+			n = n<<8 | uint64(b)
+		}
+		return n, next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, errors.Errorf("invalid MaxMind DB int32 size %d", size)
+		}
+		var n uint32
+		for _, b := range payload {
+			n = n<<8 | uint32(b)
+		}
+		if size == 4 {
+			return int64(int32(n)), next, nil
+		}
+		return int64(n), next, nil
+	case typeUint128:
+		return new(big.Int).SetBytes(payload), next, nil
+	default:
+		return nil, 0, errors.Errorf("invalid MaxMind DB data type %d", valueType)
+	}
+}
+
+func (d decoder) decodeMap(size, offset uint) (interface{}, uint, error) {
+	fields := make(map[string]interface{}, size)
+	for i := uint(0); i < size; i++ {
+		key, next, err := d.decode(offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, 0, errors.New("invalid MaxMind DB file: map key is not a string")
+		}
+		fields[name], offset, err = d.decode(next)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return fields, offset, nil
+}
+
+func (d decoder) decodeArray(size, offset uint) (interface{}, uint, error) {
+	values := make([]interface{}, size)
+	for i := range values {
+		var err error
+		if values[i], offset, err = d.decode(offset); err != nil {
+			return nil, 0, err
+		}
+	}
+	return values, offset, nil
+}
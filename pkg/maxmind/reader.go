@@ -0,0 +1,180 @@
+// Package maxmind looks up IP addresses in MaxMind DB files, such as the GeoLite2 City and ASN databases.
+//
+// The files are read from memory: a binary search tree of the networks, whose leaves point to the records of the
+// data section, decoded as Go maps, slices, strings and numbers.
+//
+// See https://maxmind.github.io/MaxMind-DB/
+package maxmind
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// The metadata is at the end of the file, after the last occurrence of this marker
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// The data section starts after 16 zero bytes following the search tree
+const dataSectionSeparator = 16
+
+// Metadata describes a database.
+type Metadata struct {
+	DatabaseType string
+	Description  map[string]string
+	IPVersion    uint64
+	NodeCount    uint64
+	RecordSize   uint64
+	BuildEpoch   uint64
+}
+
+// Reader looks up the records of IP addresses in a database, it is safe for concurrent use.
+type Reader struct {
+	Metadata Metadata
+
+	tree      []byte
+	data      decoder
+	nodeBytes uint64
+	ipv4Start uint64 // the node of the IPv4 addresses in an IPv6 tree
+}
+
+// Open reads the metadata and the search tree of a database file.
+func Open(file []byte) (*Reader, error) {
+	start := bytes.LastIndex(file, metadataMarker)
+	if start < 0 {
+		return nil, errors.New("invalid MaxMind DB file: no metadata")
+	}
+	metadata, _, err := decoder{buf: file[start+len(metadataMarker):]}.decode(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid MaxMind DB metadata")
+	}
+	fields, ok := metadata.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid MaxMind DB metadata: not a map")
+	}
+
+	r := &Reader{}
+	r.Metadata.DatabaseType, _ = fields["database_type"].(string)
+	r.Metadata.IPVersion, _ = fields["ip_version"].(uint64)
+	r.Metadata.NodeCount, _ = fields["node_count"].(uint64)
+	r.Metadata.RecordSize, _ = fields["record_size"].(uint64)
+	r.Metadata.BuildEpoch, _ = fields["build_epoch"].(uint64)
+	if description, ok := fields["description"].(map[string]interface{}); ok {
+		r.Metadata.Description = make(map[string]string, len(description))
+		for language, text := range description {
+			r.Metadata.Description[language], _ = text.(string)
+		}
+	}
+
+	switch r.Metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, errors.Errorf("invalid MaxMind DB record size %d", r.Metadata.RecordSize)
+	}
+	if r.Metadata.IPVersion != 4 && r.Metadata.IPVersion != 6 {
+		return nil, errors.Errorf("invalid MaxMind DB ip version %d", r.Metadata.IPVersion)
+	}
+
+	r.nodeBytes = r.Metadata.RecordSize / 4
+	treeSize := r.Metadata.NodeCount * r.nodeBytes
+	if treeSize+dataSectionSeparator > uint64(start) {
+		return nil, errors.New("invalid MaxMind DB file: the search tree is larger than the file")
+	}
+	r.tree = file[:treeSize]
+	r.data = decoder{buf: file[treeSize+dataSectionSeparator : start]}
+
+	if r.Metadata.IPVersion == 6 {
+		// IPv4 addresses are looked up as ::a.b.c.d
+		for i := 0; i < 96 && r.ipv4Start < r.Metadata.NodeCount; i++ {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// Lookup returns the record of the network of an IP address, nil if it is not in the database.
+func (r *Reader) Lookup(ip net.IP) (interface{}, error) {
+	node, bitCount := uint64(0), 128
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip, bitCount = ipv4, 32
+		if r.Metadata.IPVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if r.Metadata.IPVersion == 4 {
+		return nil, nil // IPv6 addresses are not in IPv4 databases
+	}
+	if ip == nil || len(ip)*8 != bitCount {
+		return nil, errors.Errorf("invalid IP address %v", ip)
+	}
+
+	for i := 0; i < bitCount && node < r.Metadata.NodeCount; i++ {
+		bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+		node = r.record(node, bit)
+	}
+	if node == r.Metadata.NodeCount { // empty network
+		return nil, nil
+	}
+	if node < r.Metadata.NodeCount {
+		return nil, errors.New("invalid MaxMind DB file: the search tree is deeper than the addresses")
+	}
+
+	offset := node - r.Metadata.NodeCount - dataSectionSeparator
+	if offset >= uint64(len(r.data.buf)) {
+		return nil, errors.New("invalid MaxMind DB file: record outside of the data section")
+	}
+	record, _, err := r.data.decode(uint(offset))
+	return record, err
+}
+
+// record returns the left (bit 0) or right (bit 1) record of a node of the search tree
+func (r *Reader) record(node uint64, bit byte) uint64 {
+	b := r.tree[node*r.nodeBytes : (node+1)*r.nodeBytes]
+	switch r.Metadata.RecordSize {
+	case 24:
+		if bit == 0 {
+			return uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+		}
+		return uint64(b[3])<<16 | uint64(b[4])<<8 | uint64(b[5])
+	case 28:
+		if bit == 0 {
+			return uint64(b[3]&0xf0)<<20 | uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+		}
+		return uint64(b[3]&0x0f)<<24 | uint64(b[4])<<16 | uint64(b[5])<<8 | uint64(b[6])
+	default:
+		if bit == 0 {
+			return uint64(b[0])<<24 | uint64(b[1])<<16 | uint64(b[2])<<8 | uint64(b[3])
+		}
+		return uint64(b[4])<<24 | uint64(b[5])<<16 | uint64(b[6])<<8 | uint64(b[7])
+	}
+}
+
+// Value returns the value of the nested maps of a record at a path of keys, nil if there is none.
+//
+// For example Value(record, "country", "iso_code") is the country code of a GeoLite2 City record.
+func Value(record interface{}, path ...string) interface{} {
+	for _, key := range path {
+		fields, ok := record.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		record = fields[key]
+	}
+	return record
+}
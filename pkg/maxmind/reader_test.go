@@ -0,0 +1,158 @@
+package maxmind
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildDatabase writes a database file with the records of some networks
+func buildDatabase(t *testing.T, ipVersion, recordSize uint64, networks []Network) []byte {
+	file, err := Write(&Metadata{
+		DatabaseType: "Test-City",
+		Description:  map[string]string{"en": "Test database"},
+		IPVersion:    ipVersion,
+		RecordSize:   recordSize,
+		BuildEpoch:   1584221862,
+	}, networks)
+	require.NoError(t, err)
+	return file
+}
+
+var testRecord = map[string]interface{}{
+	"country": map[string]interface{}{
+		"iso_code": "US",
+		"names":    map[string]interface{}{"en": "United States"},
+	},
+	"location":                 map[string]interface{}{"latitude": 37.751, "longitude": -97.822},
+	"is_in_eu":                 false,
+	"subdivisions":             []interface{}{map[string]interface{}{"iso_code": "CA"}},
+	"autonomous_system_number": uint64(15169),
+}
+
+func TestLookup(t *testing.T) {
+	for _, test := range []struct {
+		ipVersion, recordSize uint64
+	}{{4, 24}, {6, 24}, {6, 28}, {6, 32}} {
+		networks := []Network{
+			{CIDR: "1.2.3.0/24", Record: testRecord},
+			{CIDR: "1.2.128.0/17", Record: map[string]interface{}{"autonomous_system_number": uint64(7)}},
+		}
+		if test.ipVersion == 6 {
+			networks = append(networks, Network{CIDR: "2001:db8::/32", Record: map[string]interface{}{"v6": true}})
+		}
+		reader, err := Open(buildDatabase(t, test.ipVersion, test.recordSize, networks))
+		require.NoError(t, err)
+		assert.Equal(t, "Test-City", reader.Metadata.DatabaseType)
+		assert.Equal(t, map[string]string{"en": "Test database"}, reader.Metadata.Description)
+		assert.Equal(t, test.recordSize, reader.Metadata.RecordSize)
+
+		record, err := reader.Lookup(net.ParseIP("1.2.3.4"))
+		require.NoError(t, err)
+		assert.Equal(t, testRecord, record, test)
+		assert.Equal(t, "US", Value(record, "country", "iso_code"))
+		assert.Equal(t, -97.822, Value(record, "location", "longitude"))
+		assert.Nil(t, Value(record, "city", "names", "en"))
+
+		record, err = reader.Lookup(net.ParseIP("1.2.200.1"))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), Value(record, "autonomous_system_number"))
+
+		record, err = reader.Lookup(net.ParseIP("1.2.4.1"))
+		require.NoError(t, err)
+		assert.Nil(t, record)
+
+		record, err = reader.Lookup(net.ParseIP("2001:db8::1"))
+		require.NoError(t, err)
+		if test.ipVersion == 6 {
+			assert.Equal(t, map[string]interface{}{"v6": true}, record)
+		} else {
+			assert.Nil(t, record)
+		}
+	}
+}
+
+func TestWriteLargeValues(t *testing.T) {
+	// The sizes of the long strings and maps take extra bytes
+	record := map[string]interface{}{"short": strings.Repeat("a", 300), "long": strings.Repeat("b", 70000)}
+	for i := 0; i < 40; i++ {
+		record[fmt.Sprintf("key%d", i)] = uint64(i)
+	}
+	reader, err := Open(buildDatabase(t, 4, 24, []Network{{CIDR: "10.0.0.0/8", Record: record}}))
+	require.NoError(t, err)
+	result, err := reader.Lookup(net.ParseIP("10.1.2.3"))
+	require.NoError(t, err)
+	assert.Equal(t, record, result)
+
+	_, err = Write(&Metadata{IPVersion: 4, RecordSize: 24}, []Network{{CIDR: "2001:db8::/32", Record: record}})
+	assert.Error(t, err)
+	_, err = Write(&Metadata{IPVersion: 4, RecordSize: 24}, []Network{{CIDR: "10.0.0.0/8", Record: 1}})
+	assert.Error(t, err)
+}
+
+func TestOpenInvalid(t *testing.T) {
+	_, err := Open([]byte("not a database"))
+	assert.Error(t, err)
+
+	file := buildDatabase(t, 4, 24, []Network{{CIDR: "1.2.3.0/24", Record: testRecord}})
+	_, err = Open(file[bytes.LastIndex(file, metadataMarker):]) // only the metadata
+	assert.Error(t, err)
+}
+
+func TestDecodePointer(t *testing.T) {
+	// "US" then a pointer to it, then a pointer to the pointer
+	d := decoder{buf: []byte{0x42, 'U', 'S', 0x20, 0x00, 0x20, 0x03}}
+	value, next, err := d.decode(3)
+	require.NoError(t, err)
+	assert.Equal(t, "US", value)
+	assert.Equal(t, uint(5), next)
+
+	_, _, err = d.decode(5)
+	assert.Error(t, err)
+}
+
+func TestDecodeTypes(t *testing.T) {
+	// int32 -1, uint128 1, float 1.5, a string of 30 bytes
+	buf := []byte{0x04, 0x01, 0xff, 0xff, 0xff, 0xff, 0x01, 0x03, 0x01, 0x04, 0x08}
+	buf = append(buf, 0x00, 0x00, 0x00, 0x00)
+	binary.BigEndian.PutUint32(buf[len(buf)-4:], math.Float32bits(1.5))
+	buf = append(buf, 0x5d, 0x01)
+	buf = append(buf, "abcdefghijklmnopqrstuvwxyz0123"...)
+	d := decoder{buf: buf}
+
+	value, next, err := d.decode(0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), value)
+	value, next, err = d.decode(next)
+	require.NoError(t, err)
+	assert.Equal(t, "1", value.(fmt.Stringer).String())
+	value, next, err = d.decode(next)
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+	value, _, err = d.decode(next)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz0123", value)
+}
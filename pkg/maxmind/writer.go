@@ -0,0 +1,212 @@
+package maxmind
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// Network is a network of a database and its record.
+type Network struct {
+	CIDR   string
+	Record interface{}
+}
+
+type writerNode struct {
+	children [2]*writerNode
+	record   interface{}
+}
+
+// Write returns a database file with the records of some networks, mostly to test the readers of the databases.
+//
+// The records are maps, slices, strings, bools, uint64 and float64 values. The IPv4 networks of an IPv6 database
+// are written as ::a.b.c.d networks.
+func Write(metadata *Metadata, networks []Network) ([]byte, error) {
+	switch metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, errors.Errorf("invalid record size %d", metadata.RecordSize)
+	}
+
+	root := &writerNode{}
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.CIDR)
+		if err != nil {
+			return nil, err
+		}
+		ip, ones := ipNet.IP, 0
+		if ones, _ = ipNet.Mask.Size(); metadata.IPVersion == 6 && ip.To4() != nil {
+			ip, ones = append(make(net.IP, 12), ip.To4()...), ones+96
+		} else if metadata.IPVersion == 4 && ip.To4() == nil {
+			return nil, errors.Errorf("IPv6 network %s in an IPv4 database", network.CIDR)
+		}
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if node.children[bit] == nil {
+				node.children[bit] = &writerNode{}
+			}
+			node = node.children[bit]
+		}
+		node.record = network.Record
+	}
+
+	// Number the nodes breadth first, the nodes with a record are the leaves
+	var nodes []*writerNode
+	index := make(map[*writerNode]uint64)
+	for queue := []*writerNode{root}; len(queue) > 0; queue = queue[1:] {
+		node := queue[0]
+		if node.record != nil {
+			continue
+		}
+		index[node] = uint64(len(nodes))
+		nodes = append(nodes, node)
+		for _, child := range node.children {
+			if child != nil {
+				queue = append(queue, child)
+			}
+		}
+	}
+	nodeCount := uint64(len(nodes))
+
+	var tree, data []byte
+	for _, node := range nodes {
+		var records [2]uint64
+		for bit, child := range node.children {
+			switch {
+			case child == nil:
+				records[bit] = nodeCount
+			case child.record != nil:
+				records[bit] = nodeCount + dataSectionSeparator + uint64(len(data))
+				var err error
+				if data, err = encode(data, child.record); err != nil {
+					return nil, err
+				}
+			default:
+				records[bit] = index[child]
+			}
+		}
+		tree = append(tree, encodeNode(metadata.RecordSize, records)...)
+	}
+
+	file := append(tree, make([]byte, dataSectionSeparator)...)
+	file = append(file, data...)
+	file = append(file, metadataMarker...)
+	description := make(map[string]interface{}, len(metadata.Description))
+	for language, text := range metadata.Description {
+		description[language] = text
+	}
+	return encode(file, map[string]interface{}{
+		"binary_format_major_version": uint64(2),
+		"binary_format_minor_version": uint64(0),
+		"build_epoch":                 metadata.BuildEpoch,
+		"database_type":               metadata.DatabaseType,
+		"description":                 description,
+		"ip_version":                  metadata.IPVersion,
+		"node_count":                  nodeCount,
+		"record_size":                 metadata.RecordSize,
+	})
+}
+
+func encodeNode(recordSize uint64, records [2]uint64) []byte {
+	left, right := records[0], records[1]
+	switch recordSize {
+	case 24:
+		return []byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)}
+	case 28:
+		return []byte{byte(left >> 16), byte(left >> 8), byte(left),
+			byte(left>>24)<<4 | byte(right>>24)&0x0f, byte(right >> 16), byte(right >> 8), byte(right)}
+	default:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint32(b, uint32(left))
+		binary.BigEndian.PutUint32(b[4:], uint32(right))
+		return b
+	}
+}
+
+func encodeControl(buf []byte, valueType, size int) []byte {
+	var extra []byte
+	switch {
+	case size < 29:
+	case size < 285:
+		size, extra = 29, []byte{byte(size - 29)}
+	case size < 65821:
+		n := size - 285
+		size, extra = 30, []byte{byte(n >> 8), byte(n)}
+	default:
+		n := size - 65821
+		size, extra = 31, []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+	if valueType > 7 {
+		buf = append(buf, byte(size), byte(valueType-7))
+	} else {
+		buf = append(buf, byte(valueType<<5|size))
+	}
+	return append(buf, extra...)
+}
+
+// encode appends a value to a data section
+func encode(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return append(encodeControl(buf, typeString, len(v)), v...), nil
+	case float64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(v))
+		return append(encodeControl(buf, typeDouble, 8), b...), nil
+	case uint64:
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		for len(b) > 0 && b[0] == 0 {
+			b = b[1:]
+		}
+		return append(encodeControl(buf, typeUint64, len(b)), b...), nil
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		return encodeControl(buf, typeBoolean, size), nil
+	case []interface{}:
+		buf = encodeControl(buf, typeArray, len(v))
+		for _, element := range v {
+			var err error
+			if buf, err = encode(buf, element); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case map[string]interface{}:
+		buf = encodeControl(buf, typeMap, len(v))
+		for key, element := range v {
+			var err error
+			if buf, err = encode(buf, key); err != nil {
+				return nil, err
+			}
+			if buf, err = encode(buf, element); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, errors.Errorf("unsupported record value of type %T", value)
+	}
+}
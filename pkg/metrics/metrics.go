@@ -0,0 +1,127 @@
+package metrics
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// Namespace is the CloudWatch namespace of the Panther metrics.
+const Namespace = "Panther"
+
+// The units of the metrics, see cloudwatch.StandardUnit*
+const (
+	UnitBytes        = "Bytes"
+	UnitCount        = "Count"
+	UnitMilliseconds = "Milliseconds"
+)
+
+// Metric is a value of a metric.
+type Metric struct {
+	Name  string
+	Unit  string
+	Value float64
+}
+
+// Logger publishes metrics to CloudWatch with the embedded metric format:
+// they are written as JSON to the standard output of the Lambda function, CloudWatch Logs extracts them.
+//
+// See https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+type Logger struct {
+	// The log lines are written to the standard output, except in tests
+	Out io.Writer
+
+	dimensionSets [][]string
+	now           func() time.Time
+}
+
+// NewLogger returns a Logger publishing the metrics once for each set of dimensions.
+//
+// For example the sets {"LogType"} and {"SourceId"} publish the metrics per log type and per source.
+func NewLogger(dimensionSets ...[]string) *Logger {
+	return &Logger{Out: os.Stdout, dimensionSets: dimensionSets, now: time.Now}
+}
+
+// The metadata of the metrics of a log line
+type metadata struct {
+	Timestamp         int64             `json:"Timestamp"`
+	CloudWatchMetrics []metricDirective `json:"CloudWatchMetrics"`
+}
+
+type metricDirective struct {
+	Namespace  string             `json:"Namespace"`
+	Dimensions [][]string         `json:"Dimensions"`
+	Metrics    []metricDefinition `json:"Metrics"`
+}
+
+type metricDefinition struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// Log publishes the metrics with the values of the dimensions.
+//
+// The dimension sets with a dimension without a value are skipped, nothing is logged if none is left.
+func (l *Logger) Log(dimensions map[string]string, metrics ...Metric) error {
+	var dimensionSets [][]string
+	for _, set := range l.dimensionSets {
+		if hasDimensions(dimensions, set) {
+			dimensionSets = append(dimensionSets, set)
+		}
+	}
+	if len(dimensionSets) == 0 || len(metrics) == 0 {
+		return nil
+	}
+
+	directive := metricDirective{Namespace: Namespace, Dimensions: dimensionSets}
+	line := make(map[string]interface{}, len(dimensions)+len(metrics)+1)
+	for name, value := range dimensions {
+		if value != "" {
+			line[name] = value
+		}
+	}
+	for _, metric := range metrics {
+		directive.Metrics = append(directive.Metrics, metricDefinition{Name: metric.Name, Unit: metric.Unit})
+		line[metric.Name] = metric.Value
+	}
+	line["_aws"] = metadata{
+		Timestamp:         l.now().UnixNano() / int64(time.Millisecond),
+		CloudWatchMetrics: []metricDirective{directive},
+	}
+
+	// One write per log line, CloudWatch Logs parses each line on its own
+	body, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	_, err = l.Out.Write(append(body, '\n'))
+	return err
+}
+
+func hasDimensions(dimensions map[string]string, set []string) bool {
+	for _, name := range set {
+		if dimensions[name] == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package metrics
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger(out *bytes.Buffer, dimensionSets ...[]string) *Logger {
+	logger := NewLogger(dimensionSets...)
+	logger.Out = out
+	logger.now = func() time.Time { return time.Unix(1577836800, 0) }
+	return logger
+}
+
+func TestLog(t *testing.T) {
+	var out bytes.Buffer
+	logger := testLogger(&out, []string{"LogType"}, []string{"SourceId"})
+
+	require.NoError(t, logger.Log(map[string]string{"LogType": "AWS.CloudTrail", "SourceId": "source-1"},
+		Metric{Name: "EventsProcessed", Unit: UnitCount, Value: 10},
+		Metric{Name: "BytesIngested", Unit: UnitBytes, Value: 2048},
+	))
+
+	expected := `{"BytesIngested":2048,"EventsProcessed":10,"LogType":"AWS.CloudTrail","SourceId":"source-1",` +
+		`"_aws":{"Timestamp":1577836800000,"CloudWatchMetrics":[{"Namespace":"Panther","Dimensions":[["LogType"],["SourceId"]],` +
+		`"Metrics":[{"Name":"EventsProcessed","Unit":"Count"},{"Name":"BytesIngested","Unit":"Bytes"}]}]}}` + "\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestLogMissingDimension(t *testing.T) {
+	var out bytes.Buffer
+	logger := testLogger(&out, []string{"LogType"}, []string{"SourceId"})
+
+	require.NoError(t, logger.Log(map[string]string{"LogType": "", "SourceId": "source-1"},
+		Metric{Name: "ClassificationErrors", Unit: UnitCount, Value: 3}))
+
+	expected := `{"ClassificationErrors":3,"SourceId":"source-1",` +
+		`"_aws":{"Timestamp":1577836800000,"CloudWatchMetrics":[{"Namespace":"Panther","Dimensions":[["SourceId"]],` +
+		`"Metrics":[{"Name":"ClassificationErrors","Unit":"Count"}]}]}}` + "\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestLogNoDimensions(t *testing.T) {
+	var out bytes.Buffer
+	logger := testLogger(&out, []string{"LogType"})
+
+	require.NoError(t, logger.Log(nil, Metric{Name: "EventsProcessed", Unit: UnitCount, Value: 1}))
+	assert.Empty(t, out.String())
+}
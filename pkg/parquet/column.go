@@ -0,0 +1,279 @@
+package parquet
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The layouts of the timestamps of the events, the first is the one Panther writes them with
+var timestampLayouts = []string{"2006-01-02 15:04:05.000000000", time.RFC3339Nano}
+
+// The Julian day of the Unix epoch, INT96 timestamps are the day and the nanoseconds within it
+const julianDayOfEpoch = 2440588
+
+// column holds the values of a leaf of the schema, along with their levels.
+//
+// The values are kept encoded as PLAIN, except for booleans which are bit-packed once the page is written.
+type column struct {
+	leaf   *node
+	values bytes.Buffer
+	bools  []bool
+	defs   []int
+	reps   []int
+	// The number of values which are not null
+	count int
+}
+
+// size is the state of a column, restored when a row fails to be written.
+type size struct{ values, bools, levels, count int }
+
+func (c *column) size() size {
+	return size{c.values.Len(), len(c.bools), len(c.defs), c.count}
+}
+
+func (c *column) truncate(s size) {
+	c.values.Truncate(s.values)
+	c.bools, c.defs, c.count = c.bools[:s.bools], c.defs[:s.levels], s.count
+	if c.reps != nil {
+		c.reps = c.reps[:s.levels]
+	}
+}
+
+func (c *column) null(rep, def int) {
+	c.defs = append(c.defs, def)
+	if c.leaf.maxRep > 0 {
+		c.reps = append(c.reps, rep)
+	}
+}
+
+func (c *column) add(value interface{}, rep int) error {
+	if err := c.encode(value); err != nil {
+		return err
+	}
+	c.count++
+	c.null(rep, c.leaf.maxDef)
+	return nil
+}
+
+// encode appends a value decoded from JSON, converting it to the type of the column.
+func (c *column) encode(value interface{}) error {
+	var scratch [12]byte
+	switch c.leaf.physical {
+	case typeBoolean:
+		v, ok := value.(bool)
+		if !ok {
+			return errors.Errorf("%v is not a boolean", value)
+		}
+		c.bools = append(c.bools, v)
+	case typeInt32:
+		v, err := integer(value, 32)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(scratch[:], uint32(int32(v)))
+		c.values.Write(scratch[:4])
+	case typeInt64:
+		v, err := integer(value, 64)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint64(scratch[:], uint64(v))
+		c.values.Write(scratch[:8])
+	case typeFloat, typeDouble:
+		v, err := float(value)
+		if err != nil {
+			return err
+		}
+		if c.leaf.physical == typeFloat {
+			binary.LittleEndian.PutUint32(scratch[:], math.Float32bits(float32(v)))
+			c.values.Write(scratch[:4])
+		} else {
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(v))
+			c.values.Write(scratch[:8])
+		}
+	case typeInt96:
+		t, err := timestamp(value)
+		if err != nil {
+			return err
+		}
+		nanos := t.UnixNano()
+		day := nanos/int64(24*time.Hour) + julianDayOfEpoch
+		if within := nanos % int64(24*time.Hour); within < 0 { // before the epoch
+			day--
+			nanos = within + int64(24*time.Hour)
+		} else {
+			nanos = within
+		}
+		binary.LittleEndian.PutUint64(scratch[:], uint64(nanos))
+		binary.LittleEndian.PutUint32(scratch[8:], uint32(day))
+		c.values.Write(scratch[:12])
+	case typeByteArray:
+		text, err := toText(value)
+		if err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint32(scratch[:], uint32(len(text)))
+		c.values.Write(scratch[:4])
+		c.values.WriteString(text)
+	}
+	return nil
+}
+
+func integer(value interface{}, bits int) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return strconv.ParseInt(string(v), 10, bits)
+	case string:
+		return strconv.ParseInt(v, 10, bits)
+	}
+	return 0, errors.Errorf("%v is not an integer", value)
+}
+
+func float(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, errors.Errorf("%v is not a number", value)
+}
+
+func timestamp(value interface{}) (time.Time, error) {
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, errors.Errorf("%v is not a timestamp", value)
+	}
+	var err error
+	for _, layout := range timestampLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, text); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.Wrapf(err, "%s is not a timestamp", text)
+}
+
+// toText returns the text of a string column, values of other types are kept as their JSON like the JSON SerDe does.
+func toText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return string(v), nil
+	}
+	text, err := json.Marshal(value)
+	return string(text), err
+}
+
+// shred adds a value of a field to the columns of its leaves, see the Dremel paper for the levels.
+//
+// The value is absent at the definition level def, the levels of its own fields start above it. The repetition
+// level is the one of the first value added, the next entries of a list or a map are repeated at the level of
+// their repeated group.
+func shred(columns []*column, n *node, value interface{}, rep, def int) error {
+	if value == nil {
+		nullLeaves(columns, n, rep, def)
+		return nil
+	}
+	if !n.required {
+		def++
+	}
+	switch n.kind {
+	case primitiveNode:
+		return errors.Wrap(columns[n.leaf].add(value, rep), n.name)
+	case structNode:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("%s: %v is not an object", n.name, value)
+		}
+		for _, field := range n.fields {
+			if err := shred(columns, field, fields[field.name], rep, def); err != nil {
+				return errors.WithMessage(err, n.name)
+			}
+		}
+	case listNode:
+		elements, ok := value.([]interface{})
+		if !ok {
+			return errors.Errorf("%s: %v is not an array", n.name, value)
+		}
+		if len(elements) == 0 {
+			nullLeaves(columns, n, rep, def)
+			return nil
+		}
+		for i, element := range elements {
+			if i > 0 {
+				rep = n.entryRep
+			}
+			if err := shred(columns, n.element, element, rep, def+1); err != nil {
+				return errors.WithMessage(err, n.name)
+			}
+		}
+	case mapNode:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("%s: %v is not an object", n.name, value)
+		}
+		if len(entries) == 0 {
+			nullLeaves(columns, n, rep, def)
+			return nil
+		}
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if i > 0 {
+				rep = n.entryRep
+			}
+			if err := shred(columns, n.key, key, rep, def+1); err != nil {
+				return errors.WithMessage(err, n.name)
+			}
+			if err := shred(columns, n.value, entries[key], rep, def+1); err != nil {
+				return errors.WithMessage(err, n.name)
+			}
+		}
+	}
+	return nil
+}
+
+// nullLeaves adds a null to each leaf under a field which is absent at the definition level def.
+func nullLeaves(columns []*column, n *node, rep, def int) {
+	switch n.kind {
+	case primitiveNode:
+		columns[n.leaf].null(rep, def)
+	case structNode:
+		for _, field := range n.fields {
+			nullLeaves(columns, field, rep, def)
+		}
+	case listNode:
+		nullLeaves(columns, n.element, rep, def)
+	case mapNode:
+		nullLeaves(columns, n.key, rep, def)
+		nullLeaves(columns, n.value, rep, def)
+	}
+}
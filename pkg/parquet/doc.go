@@ -0,0 +1,23 @@
+// Package parquet writes Parquet files of JSON events, whose columns are the ones of a Glue table.
+//
+// The files have a single row group, with a single page compressed with Snappy for each column. The values are
+// encoded as PLAIN and the levels with runs of the RLE hybrid encoding: this is all that Athena needs to read them.
+//
+// See https://github.com/apache/parquet-format
+package parquet
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
@@ -0,0 +1,284 @@
+package parquet
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	magic     = "PAR1"
+	createdBy = "panther"
+
+	codecSnappy   = 1
+	encodingRLE   = 3
+	encodingPlain = 0
+	pageTypeData  = 0
+)
+
+// Writer buffers the rows of a file in memory.
+type Writer struct {
+	schema  *Schema
+	columns []*column
+	rows    int
+}
+
+// NewWriter returns a writer of files with the given schema.
+func NewWriter(schema *Schema) *Writer {
+	columns := make([]*column, len(schema.leaves))
+	for i, leaf := range schema.leaves {
+		columns[i] = &column{leaf: leaf}
+	}
+	return &Writer{schema: schema, columns: columns}
+}
+
+// Rows returns the number of rows written since the writer was created or reset.
+func (w *Writer) Rows() int {
+	return w.rows
+}
+
+// WriteJSON adds a row from a JSON object, its fields which are not columns are ignored.
+//
+// A row whose fields don't match the types of their columns is rejected, the rows already written are kept.
+func (w *Writer) WriteJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil {
+		return errors.Wrap(err, "failed to decode row")
+	}
+	return w.WriteRow(row)
+}
+
+// WriteRow adds a row of values decoded from JSON with UseNumber.
+func (w *Writer) WriteRow(row map[string]interface{}) error {
+	sizes := make([]size, len(w.columns))
+	for i, c := range w.columns {
+		sizes[i] = c.size()
+	}
+	for _, field := range w.schema.root.fields {
+		if err := shred(w.columns, field, row[field.name], 0, 0); err != nil {
+			for i, c := range w.columns {
+				c.truncate(sizes[i])
+			}
+			return errors.WithMessage(err, "failed to write row")
+		}
+	}
+	w.rows++
+	return nil
+}
+
+// Reset drops the rows written, to start a new file.
+func (w *Writer) Reset() {
+	for i, c := range w.columns {
+		w.columns[i] = &column{leaf: c.leaf}
+	}
+	w.rows = 0
+}
+
+// Bytes returns the file of the rows written.
+func (w *Writer) Bytes() []byte {
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	chunks := make([]chunk, len(w.columns))
+	for i, c := range w.columns {
+		chunks[i] = c.writePage(&file)
+	}
+
+	footer := &thriftWriter{}
+	footer.structBegin()
+	footer.i32Field(1, 1) // version
+	w.writeSchema(footer)
+	footer.i64Field(3, int64(w.rows))
+	footer.listField(4, thriftStruct, 1)
+	footer.structBegin() // the row group
+	footer.listField(1, thriftStruct, len(chunks))
+	var totalSize int64
+	for i, c := range chunks {
+		c.write(footer, w.columns[i].leaf)
+		totalSize += c.uncompressedSize
+	}
+	footer.i64Field(2, totalSize)
+	footer.i64Field(3, int64(w.rows))
+	footer.structEnd()
+	footer.stringField(6, createdBy)
+	footer.structEnd()
+
+	file.Write(footer.buf.Bytes())
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(footer.buf.Len()))
+	file.Write(length[:])
+	file.WriteString(magic)
+	return file.Bytes()
+}
+
+// writeSchema writes the fields of the schema depth first, each group is followed by its children.
+func (w *Writer) writeSchema(footer *thriftWriter) {
+	var elements []func()
+	var add func(n *node, repetition int32)
+	element := func(n *node, name string, repetition int32, children int, converted int32, primitive bool) {
+		elements = append(elements, func() {
+			footer.structBegin()
+			if primitive {
+				footer.i32Field(1, n.physical)
+			}
+			if repetition >= 0 {
+				footer.i32Field(3, repetition)
+			}
+			footer.stringField(4, name)
+			if !primitive {
+				footer.i32Field(5, int32(children))
+			}
+			if converted != noConvertedType {
+				footer.i32Field(6, converted)
+			}
+			footer.structEnd()
+		})
+	}
+	add = func(n *node, repetition int32) {
+		switch n.kind {
+		case primitiveNode:
+			element(n, n.name, repetition, 0, n.converted, true)
+		case structNode:
+			element(n, n.name, repetition, len(n.fields), n.converted, false)
+			for _, field := range n.fields {
+				add(field, repetitionOptional)
+			}
+		case listNode:
+			element(n, n.name, repetition, 1, convertedList, false)
+			element(n, "list", repetitionRepeated, 1, noConvertedType, false)
+			add(n.element, repetitionOptional)
+		case mapNode:
+			element(n, n.name, repetition, 1, convertedMap, false)
+			element(n, "key_value", repetitionRepeated, 2, convertedMapKeyValue, false)
+			add(n.key, repetitionRequired)
+			add(n.value, repetitionOptional)
+		}
+	}
+	// The root has no repetition
+	add(w.schema.root, -1)
+
+	footer.listField(2, thriftStruct, len(elements))
+	for _, write := range elements {
+		write()
+	}
+}
+
+// chunk is where the page of a column was written in the file.
+type chunk struct {
+	offset           int64
+	values           int
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+func (c chunk) write(footer *thriftWriter, leaf *node) {
+	footer.structBegin()
+	footer.i64Field(2, c.offset) // file_offset
+	footer.structField(3)        // meta_data
+	footer.i32Field(1, leaf.physical)
+	encodings := []int32{encodingPlain, encodingRLE}
+	footer.listField(2, thriftI32, len(encodings))
+	for _, encoding := range encodings {
+		footer.zigzag(int64(encoding))
+	}
+	footer.listField(3, thriftBinary, len(leaf.path))
+	for _, name := range leaf.path {
+		footer.str(name)
+	}
+	footer.i32Field(4, codecSnappy)
+	footer.i64Field(5, int64(c.values))
+	footer.i64Field(6, c.uncompressedSize)
+	footer.i64Field(7, c.compressedSize)
+	footer.i64Field(9, c.offset) // data_page_offset
+	footer.structEnd()
+	footer.structEnd()
+}
+
+// writePage writes the levels and the values of the column as a data page.
+func (c *column) writePage(file *bytes.Buffer) chunk {
+	var page bytes.Buffer
+	if c.leaf.maxRep > 0 {
+		writeLevels(&page, c.reps, c.leaf.maxRep)
+	}
+	if c.leaf.maxDef > 0 {
+		writeLevels(&page, c.defs, c.leaf.maxDef)
+	}
+	if c.leaf.physical == typeBoolean {
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, v := range c.bools {
+			if v {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		page.Write(packed)
+	} else {
+		page.Write(c.values.Bytes())
+	}
+	compressed := snappyEncode(page.Bytes())
+
+	header := &thriftWriter{}
+	header.structBegin()
+	header.i32Field(1, pageTypeData)
+	header.i32Field(2, int32(page.Len()))
+	header.i32Field(3, int32(len(compressed)))
+	header.structField(5) // data_page_header
+	header.i32Field(1, int32(len(c.defs)))
+	header.i32Field(2, encodingPlain)
+	header.i32Field(3, encodingRLE)
+	header.i32Field(4, encodingRLE)
+	header.structEnd()
+	header.structEnd()
+
+	result := chunk{
+		offset:           int64(file.Len()),
+		values:           len(c.defs),
+		uncompressedSize: int64(header.buf.Len() + page.Len()),
+		compressedSize:   int64(header.buf.Len() + len(compressed)),
+	}
+	file.Write(header.buf.Bytes())
+	file.Write(compressed)
+	return result
+}
+
+// writeLevels writes levels as runs of the RLE hybrid encoding, preceded by their length.
+func writeLevels(page *bytes.Buffer, levels []int, maxLevel int) {
+	width := (bits.Len(uint(maxLevel)) + 7) / 8
+	var runs bytes.Buffer
+	var scratch [binary.MaxVarintLen64]byte
+	for start := 0; start < len(levels); {
+		end := start + 1
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		runs.Write(scratch[:binary.PutUvarint(scratch[:], uint64(end-start)<<1)])
+		for i := 0; i < width; i++ {
+			runs.WriteByte(byte(levels[start] >> (8 * i)))
+		}
+		start = end
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(runs.Len()))
+	page.Write(length[:])
+	page.Write(runs.Bytes())
+}
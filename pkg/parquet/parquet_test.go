@@ -0,0 +1,388 @@
+package parquet
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test reader decodes the files independently of the writer, following the specification.
+
+func snappyDecode(t *testing.T, src []byte) []byte {
+	length, n := binary.Uvarint(src)
+	require.Greater(t, n, 0)
+	src = src[n:]
+	var dst []byte
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 0x03 {
+		case snappyTagLiteral:
+			size := int(tag >> 2)
+			src = src[1:]
+			if size >= 60 {
+				extra := size - 59
+				size = 0
+				for i := 0; i < extra; i++ {
+					size |= int(src[i]) << (8 * i)
+				}
+				src = src[extra:]
+			}
+			size++
+			dst = append(dst, src[:size]...)
+			src = src[size:]
+		case snappyTagCopy2:
+			size := int(tag>>2) + 1
+			offset := int(binary.LittleEndian.Uint16(src[1:]))
+			require.True(t, offset > 0 && offset <= len(dst), "copy offset %d out of range", offset)
+			for i := 0; i < size; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+			src = src[3:]
+		default:
+			t.Fatalf("unexpected snappy tag %x", tag)
+		}
+	}
+	require.Equal(t, int(length), len(dst))
+	return dst
+}
+
+type thriftReader struct {
+	t    *testing.T
+	data []byte
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data)
+	require.Greater(r.t, n, 0)
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(fieldType byte) interface{} {
+	switch fieldType {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		size := int(r.varint())
+		v := string(r.data[:size])
+		r.data = r.data[size:]
+		return v
+	case thriftList:
+		header := r.byte()
+		size, elemType := int(header>>4), header&0x0f
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(elemType)
+		}
+		return list
+	case thriftStruct:
+		fields := make(map[int16]interface{})
+		var id int16
+		for {
+			header := r.byte()
+			if header == 0 {
+				return fields
+			}
+			if delta := int16(header >> 4); delta != 0 {
+				id += delta
+			} else {
+				id = int16(r.zigzag())
+			}
+			fields[id] = r.value(header & 0x0f)
+		}
+	}
+	r.t.Fatalf("unexpected thrift type %d", fieldType)
+	return nil
+}
+
+type readColumn struct {
+	path   []interface{}
+	values []interface{}
+	defs   []int
+	reps   []int
+}
+
+// readFile returns the footer of a file, along with the content of each column.
+func readFile(t *testing.T, file []byte) (map[int16]interface{}, []*readColumn) {
+	require.Equal(t, magic, string(file[:4]))
+	require.Equal(t, magic, string(file[len(file)-4:]))
+	footerLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footerStart := len(file) - 8 - footerLength
+	footer := (&thriftReader{t: t, data: file[footerStart : len(file)-8]}).value(thriftStruct).(map[int16]interface{})
+
+	// The leaves of the schema, with their maximum levels
+	type leaf struct{ maxDef, maxRep int }
+	var leaves []leaf
+	schema := footer[2].([]interface{})
+	var walk func(i, def, rep int) int
+	walk = func(i, def, rep int) int {
+		element := schema[i].(map[int16]interface{})
+		switch element[3] {
+		case int64(repetitionOptional):
+			def++
+		case int64(repetitionRepeated):
+			def++
+			rep++
+		}
+		children, ok := element[5]
+		if !ok {
+			leaves = append(leaves, leaf{def, rep})
+			return i + 1
+		}
+		next := i + 1
+		for c := 0; c < int(children.(int64)); c++ {
+			next = walk(next, def, rep)
+		}
+		return next
+	}
+	require.Equal(t, len(schema), walk(0, 0, 0))
+
+	rowGroup := footer[4].([]interface{})[0].(map[int16]interface{})
+	chunks := rowGroup[1].([]interface{})
+	require.Len(t, chunks, len(leaves))
+	columns := make([]*readColumn, len(chunks))
+	for i, chunk := range chunks {
+		metadata := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		assert.Equal(t, int64(codecSnappy), metadata[4])
+		reader := &thriftReader{t: t, data: file[metadata[9].(int64):footerStart]}
+		header := reader.value(thriftStruct).(map[int16]interface{})
+		page := snappyDecode(t, reader.data[:header[3].(int64)])
+		require.Len(t, page, int(header[2].(int64)))
+		count := int(header[5].(map[int16]interface{})[1].(int64))
+		assert.Equal(t, int64(count), metadata[5])
+
+		c := &readColumn{path: metadata[3].([]interface{})}
+		if leaves[i].maxRep > 0 {
+			c.reps, page = readLevels(t, page, leaves[i].maxRep, count)
+		}
+		if leaves[i].maxDef > 0 {
+			c.defs, page = readLevels(t, page, leaves[i].maxDef, count)
+		}
+		nonNull := 0
+		for j := 0; j < count; j++ {
+			if leaves[i].maxDef == 0 || c.defs[j] == leaves[i].maxDef {
+				nonNull++
+			}
+		}
+		c.values = readValues(t, metadata[1].(int64), page, nonNull)
+		columns[i] = c
+	}
+	return footer, columns
+}
+
+func readLevels(t *testing.T, page []byte, maxLevel, count int) ([]int, []byte) {
+	length := int(binary.LittleEndian.Uint32(page))
+	runs := page[4 : 4+length]
+	width := 1
+	if maxLevel > 255 {
+		width = 2
+	}
+	var levels []int
+	for len(runs) > 0 {
+		header, n := binary.Uvarint(runs)
+		require.Zero(t, header&1, "only RLE runs are written")
+		level := 0
+		for i := 0; i < width; i++ {
+			level |= int(runs[n+i]) << (8 * i)
+		}
+		for j := 0; j < int(header>>1); j++ {
+			levels = append(levels, level)
+		}
+		runs = runs[n+width:]
+	}
+	require.Len(t, levels, count)
+	return levels, page[4+length:]
+}
+
+func readValues(t *testing.T, physical int64, page []byte, count int) []interface{} {
+	values := make([]interface{}, count)
+	for i := range values {
+		switch physical {
+		case typeBoolean:
+			values[i] = page[i/8]&(1<<(i%8)) != 0
+		case typeInt32:
+			values[i], page = int32(binary.LittleEndian.Uint32(page)), page[4:]
+		case typeInt64:
+			values[i], page = int64(binary.LittleEndian.Uint64(page)), page[8:]
+		case typeFloat:
+			values[i], page = math.Float32frombits(binary.LittleEndian.Uint32(page)), page[4:]
+		case typeDouble:
+			values[i], page = math.Float64frombits(binary.LittleEndian.Uint64(page)), page[8:]
+		case typeInt96:
+			nanos := int64(binary.LittleEndian.Uint64(page))
+			day := int64(binary.LittleEndian.Uint32(page[8:]))
+			values[i] = time.Unix(0, (day-julianDayOfEpoch)*int64(24*time.Hour)+nanos).UTC()
+			page = page[12:]
+		case typeByteArray:
+			size := int(binary.LittleEndian.Uint32(page))
+			values[i], page = string(page[4:4+size]), page[4+size:]
+		}
+	}
+	return values
+}
+
+func TestWriterPrimitives(t *testing.T) {
+	schema, err := NewSchema([]Column{
+		{Name: "name", Type: "string"},
+		{Name: "bytes", Type: "bigint"},
+		{Name: "port", Type: "int"},
+		{Name: "allowed", Type: "boolean"},
+		{Name: "ratio", Type: "double"},
+		{Name: "p_event_time", Type: "timestamp"},
+	})
+	require.NoError(t, err)
+	writer := NewWriter(schema)
+	require.NoError(t, writer.WriteJSON([]byte(`{"name":"a","bytes":12345678901,"port":443,"allowed":true,"ratio":0.5,`+
+		`"p_event_time":"2020-01-02 03:04:05.600000000","ignored":1}`)))
+	require.NoError(t, writer.WriteJSON([]byte(`{"allowed":false,"p_event_time":"1969-12-31 23:00:00.000000000"}`)))
+	// Values of other types are kept as their JSON in string columns
+	require.NoError(t, writer.WriteJSON([]byte(`{"name":{"nested":[1]}}`)))
+	assert.Equal(t, 3, writer.Rows())
+
+	footer, columns := readFile(t, writer.Bytes())
+	assert.Equal(t, int64(3), footer[3])
+	assert.Equal(t, createdBy, footer[6])
+	assert.Equal(t, []interface{}{"a", `{"nested":[1]}`}, columns[0].values)
+	assert.Equal(t, []int{1, 0, 1}, columns[0].defs)
+	assert.Equal(t, []interface{}{int64(12345678901)}, columns[1].values)
+	assert.Equal(t, []interface{}{int32(443)}, columns[2].values)
+	assert.Equal(t, []interface{}{true, false}, columns[3].values)
+	assert.Equal(t, []interface{}{0.5}, columns[4].values)
+	assert.Equal(t, []interface{}{
+		time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC),
+		time.Date(1969, 12, 31, 23, 0, 0, 0, time.UTC),
+	}, columns[5].values)
+	for _, c := range columns {
+		assert.Nil(t, c.reps)
+	}
+}
+
+func TestWriterNested(t *testing.T) {
+	schema, err := NewSchema([]Column{
+		{Name: "tags", Type: "array<string>"},
+		{Name: "counts", Type: "map<string,bigint>"},
+		{Name: "files", Type: "array<struct<name:string,size:bigint>>"},
+		{Name: "user", Type: "struct<id:string,groups:array<string>>"},
+	})
+	require.NoError(t, err)
+	writer := NewWriter(schema)
+	for _, row := range []string{
+		`{"tags":["a","b"],"counts":{"b":2,"a":1},"files":[{"name":"x"},{"size":3}],"user":{"id":"u","groups":["g"]}}`,
+		`{"tags":[],"counts":{},"files":[null],"user":{}}`,
+		`{}`,
+		`{"tags":[null]}`,
+	} {
+		require.NoError(t, writer.WriteJSON([]byte(row)))
+	}
+
+	_, columns := readFile(t, writer.Bytes())
+	require.Len(t, columns, 7)
+	tags := columns[0]
+	assert.Equal(t, []interface{}{"tags", "list", "element"}, tags.path)
+	assert.Equal(t, []interface{}{"a", "b"}, tags.values)
+	assert.Equal(t, []int{3, 3, 1, 0, 2}, tags.defs)
+	assert.Equal(t, []int{0, 1, 0, 0, 0}, tags.reps)
+
+	keys, values := columns[1], columns[2]
+	assert.Equal(t, []interface{}{"counts", "key_value", "key"}, keys.path)
+	// The entries of maps are sorted by key
+	assert.Equal(t, []interface{}{"a", "b"}, keys.values)
+	assert.Equal(t, []int{2, 2, 1, 0, 0}, keys.defs)
+	assert.Equal(t, []int{0, 1, 0, 0, 0}, keys.reps)
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, values.values)
+	assert.Equal(t, []int{3, 3, 1, 0, 0}, values.defs)
+
+	names, sizes := columns[3], columns[4]
+	assert.Equal(t, []interface{}{"files", "list", "element", "name"}, names.path)
+	assert.Equal(t, []interface{}{"x"}, names.values)
+	assert.Equal(t, []int{4, 3, 2, 0, 0}, names.defs)
+	assert.Equal(t, []int{0, 1, 0, 0, 0}, names.reps)
+	assert.Equal(t, []interface{}{int64(3)}, sizes.values)
+	assert.Equal(t, []int{3, 4, 2, 0, 0}, sizes.defs)
+
+	userID, groups := columns[5], columns[6]
+	assert.Equal(t, []interface{}{"user", "id"}, userID.path)
+	assert.Equal(t, []int{2, 1, 0, 0}, userID.defs)
+	assert.Equal(t, []interface{}{"user", "groups", "list", "element"}, groups.path)
+	assert.Equal(t, []int{4, 1, 0, 0}, groups.defs)
+}
+
+func TestWriterRejectedRow(t *testing.T) {
+	schema, err := NewSchema([]Column{
+		{Name: "name", Type: "string"},
+		{Name: "tags", Type: "array<string>"},
+		{Name: "port", Type: "int"},
+	})
+	require.NoError(t, err)
+	writer := NewWriter(schema)
+	require.NoError(t, writer.WriteJSON([]byte(`{"name":"a","port":1}`)))
+	// The name and the tags are written before the port fails
+	err = writer.WriteJSON([]byte(`{"name":"b","tags":["x"],"port":"not a port"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port")
+	assert.Error(t, writer.WriteJSON([]byte(`{"port":4294967296}`)))
+	assert.Error(t, writer.WriteJSON([]byte(`not json`)))
+	assert.Equal(t, 1, writer.Rows())
+
+	_, columns := readFile(t, writer.Bytes())
+	assert.Equal(t, []interface{}{"a"}, columns[0].values)
+	assert.Equal(t, []int{0}, columns[1].defs)
+	assert.Equal(t, []interface{}{int32(1)}, columns[2].values)
+
+	writer.Reset()
+	assert.Zero(t, writer.Rows())
+	footer, columns := readFile(t, writer.Bytes())
+	assert.Equal(t, int64(0), footer[3])
+	assert.Empty(t, columns[0].values)
+}
+
+func TestSnappy(t *testing.T) {
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+	repetitive := bytes.Repeat([]byte(`{"eventName":"GetObject","eventSource":"s3.amazonaws.com"}`), 5000)
+	// Matches which overlap their own copy, and a literal longer than 60 bytes
+	overlapping := append(bytes.Repeat([]byte("a"), 1000), random[:300]...)
+
+	for _, input := range [][]byte{nil, []byte("short"), random, repetitive, overlapping} {
+		compressed := snappyEncode(input)
+		assert.Equal(t, len(input), len(snappyDecode(t, compressed)))
+		assert.True(t, bytes.Equal(input, snappyDecode(t, compressed)))
+	}
+	assert.Less(t, len(snappyEncode(repetitive)), len(repetitive)/20)
+}
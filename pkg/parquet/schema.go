@@ -0,0 +1,269 @@
+package parquet
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The physical types of the values
+const (
+	typeBoolean   = 0
+	typeInt32     = 1
+	typeInt64     = 2
+	typeInt96     = 3
+	typeFloat     = 4
+	typeDouble    = 5
+	typeByteArray = 6
+)
+
+// The repetitions of the fields
+const (
+	repetitionRequired = 0
+	repetitionOptional = 1
+	repetitionRepeated = 2
+)
+
+// The logical types of the fields, noConvertedType is not one of them
+const (
+	convertedUTF8        = 0
+	convertedMap         = 1
+	convertedMapKeyValue = 2
+	convertedList        = 3
+	convertedInt8        = 15
+	convertedInt16       = 16
+	noConvertedType      = -1
+)
+
+type nodeKind int
+
+const (
+	primitiveNode nodeKind = iota
+	structNode
+	listNode
+	mapNode
+)
+
+// Column is a column of a Glue table, its type is a Hive type such as "array<struct<name:string,size:bigint>>".
+type Column struct {
+	Name string
+	Type string
+}
+
+// Schema is the schema of the files, one field for each column.
+//
+// The columns are matched by name with the ones of the table, so the schema of a file can differ from the
+// current one of the table: the columns it lacks are read as null.
+type Schema struct {
+	root   *node
+	leaves []*node
+}
+
+// node is a field of the schema.
+//
+// Lists and maps follow the 3-level layout of the specification, which Hive and Athena read: the repeated
+// group of their entries, e.g. "list", is implied by the node rather than being one of its children.
+type node struct {
+	name      string
+	kind      nodeKind
+	physical  int32
+	converted int32
+	fields    []*node
+
+	// For lists and maps: the element, or the key and the value, and the repetition level of their entries
+	element, key, value *node
+	entryRep            int
+
+	// For primitives: their index among the leaves, the path from the root and the maximum levels
+	leaf     int
+	path     []string
+	maxDef   int
+	maxRep   int
+	required bool
+}
+
+// NewSchema returns the schema of the files of a table with the given columns.
+func NewSchema(columns []Column) (*Schema, error) {
+	schema := &Schema{root: &node{name: "schema", kind: structNode}}
+	for _, column := range columns {
+		field, err := parseType(column.Name, column.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "column %s", column.Name)
+		}
+		schema.root.fields = append(schema.root.fields, field)
+	}
+	for _, field := range schema.root.fields {
+		schema.index(field, nil, 0, 0)
+	}
+	return schema, nil
+}
+
+// index sets the paths and levels of the leaves under a field, which is optional unless stated otherwise.
+func (s *Schema) index(n *node, path []string, def, rep int) {
+	path = append(path[:len(path):len(path)], n.name)
+	if !n.required {
+		def++
+	}
+	switch n.kind {
+	case primitiveNode:
+		n.leaf, n.path, n.maxDef, n.maxRep = len(s.leaves), path, def, rep
+		s.leaves = append(s.leaves, n)
+	case structNode:
+		for _, field := range n.fields {
+			s.index(field, path, def, rep)
+		}
+	case listNode:
+		n.entryRep = rep + 1
+		s.index(n.element, append(path, "list"), def+1, rep+1)
+	case mapNode:
+		n.entryRep = rep + 1
+		s.index(n.key, append(path, "key_value"), def+1, rep+1)
+		s.index(n.value, append(path, "key_value"), def+1, rep+1)
+	}
+}
+
+// parseType parses a Hive type, as found in the columns of Glue tables.
+func parseType(name, hiveType string) (*node, error) {
+	n, rest, err := parseTypePrefix(name, strings.TrimSpace(hiveType))
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, errors.Errorf("unexpected %q after type", rest)
+	}
+	return n, nil
+}
+
+// parseTypePrefix parses the type which starts t, and returns what's left after it.
+func parseTypePrefix(name, t string) (*node, string, error) {
+	keyword := t
+	if i := strings.IndexAny(t, "<,>"); i >= 0 {
+		keyword = t[:i]
+	}
+	rest := t[len(keyword):]
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+
+	switch keyword {
+	case "array":
+		element, rest, err := parseTypeArguments(rest, 1)
+		if err != nil {
+			return nil, "", err
+		}
+		element[0].name = "element"
+		return &node{name: name, kind: listNode, converted: convertedList, element: element[0]}, rest, nil
+	case "map":
+		kv, rest, err := parseTypeArguments(rest, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		if kv[0].kind != primitiveNode {
+			return nil, "", errors.New("map keys must be primitive")
+		}
+		kv[0].name, kv[0].required, kv[1].name = "key", true, "value"
+		return &node{name: name, kind: mapNode, converted: convertedMap, key: kv[0], value: kv[1]}, rest, nil
+	case "struct":
+		fields, rest, err := parseStructFields(rest)
+		if err != nil {
+			return nil, "", err
+		}
+		return &node{name: name, kind: structNode, converted: noConvertedType, fields: fields}, rest, nil
+	}
+
+	primitive := &node{name: name, kind: primitiveNode, converted: noConvertedType}
+	switch keyword {
+	case "boolean":
+		primitive.physical = typeBoolean
+	case "tinyint":
+		primitive.physical, primitive.converted = typeInt32, convertedInt8
+	case "smallint":
+		primitive.physical, primitive.converted = typeInt32, convertedInt16
+	case "int":
+		primitive.physical = typeInt32
+	case "bigint":
+		primitive.physical = typeInt64
+	case "float":
+		primitive.physical = typeFloat
+	case "double":
+		primitive.physical = typeDouble
+	case "string":
+		primitive.physical, primitive.converted = typeByteArray, convertedUTF8
+	case "timestamp":
+		// Hive and Athena read the timestamps of Parquet files as INT96
+		primitive.physical = typeInt96
+	default:
+		return nil, "", errors.Errorf("unsupported type %q", keyword)
+	}
+	return primitive, rest, nil
+}
+
+// parseTypeArguments parses the types between the angle brackets of an array or a map.
+func parseTypeArguments(t string, count int) ([]*node, string, error) {
+	if !strings.HasPrefix(t, "<") {
+		return nil, "", errors.New("missing type arguments")
+	}
+	t = t[1:]
+	args := make([]*node, count)
+	for i := range args {
+		arg, rest, err := parseTypePrefix("", t)
+		if err != nil {
+			return nil, "", err
+		}
+		separator := ">"
+		if i < count-1 {
+			separator = ","
+		}
+		if !strings.HasPrefix(rest, separator) {
+			return nil, "", errors.Errorf("expected %q in type arguments", separator)
+		}
+		args[i], t = arg, rest[1:]
+	}
+	return args, t, nil
+}
+
+// parseStructFields parses the name:type pairs between the angle brackets of a struct.
+func parseStructFields(t string) ([]*node, string, error) {
+	if !strings.HasPrefix(t, "<") {
+		return nil, "", errors.New("missing struct fields")
+	}
+	t = t[1:]
+	var fields []*node
+	for !strings.HasPrefix(t, ">") {
+		colon := strings.Index(t, ":")
+		if colon <= 0 {
+			return nil, "", errors.New("expected name:type in struct fields")
+		}
+		field, rest, err := parseTypePrefix(strings.TrimSpace(t[:colon]), t[colon+1:])
+		if err != nil {
+			return nil, "", err
+		}
+		fields = append(fields, field)
+		switch {
+		case strings.HasPrefix(rest, ","):
+			t = rest[1:]
+		case strings.HasPrefix(rest, ">"):
+			t = rest
+		default:
+			return nil, "", errors.New("expected , or > in struct fields")
+		}
+	}
+	if len(fields) == 0 {
+		return nil, "", errors.New("struct without fields")
+	}
+	return fields, t[1:], nil
+}
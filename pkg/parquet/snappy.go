@@ -0,0 +1,113 @@
+package parquet
+
+/**
+ * Copyright 2020 Panther Labs Inc
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+import (
+	"encoding/binary"
+)
+
+// Snappy compresses the pages of the columns, see https://github.com/google/snappy/blob/master/format_description.txt
+const (
+	snappyTagLiteral = 0x00
+	snappyTagCopy2   = 0x02
+
+	// The input is compressed in blocks, so that the offset of a copy always fits in 2 bytes
+	snappyBlockSize = 1 << 16
+	// Shorter inputs are not worth looking for matches
+	snappyMinInput = 16
+	snappyMaxCopy  = 64
+	snappyHashBits = 14
+)
+
+// snappyEncode compresses src in the block format of Snappy.
+//
+// The matches are found with a hash table of the previous 4-byte sequences of the block, like the reference
+// implementation but without its heuristics to skip incompressible input faster.
+func snappyEncode(src []byte) []byte {
+	dst := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(src)+len(src)/6+1)
+	dst = dst[:binary.PutUvarint(dst, uint64(len(src)))]
+	for start := 0; start < len(src); start += snappyBlockSize {
+		end := start + snappyBlockSize
+		if end > len(src) {
+			end = len(src)
+		}
+		dst = snappyEncodeBlock(dst, src[start:end])
+	}
+	return dst
+}
+
+func snappyEncodeBlock(dst, block []byte) []byte {
+	if len(block) < snappyMinInput {
+		return snappyLiteral(dst, block)
+	}
+	var table [1 << snappyHashBits]int32 // positions + 1, zero is empty
+	literalStart := 0
+	for i := 0; i+4 <= len(block); {
+		sequence := binary.LittleEndian.Uint32(block[i:])
+		hash := (sequence * 0x1e35a7bd) >> (32 - snappyHashBits)
+		candidate := int(table[hash]) - 1
+		table[hash] = int32(i + 1)
+		if candidate < 0 || binary.LittleEndian.Uint32(block[candidate:]) != sequence {
+			i++
+			continue
+		}
+
+		dst = snappyLiteral(dst, block[literalStart:i])
+		length := 4
+		for i+length < len(block) && block[candidate+length] == block[i+length] {
+			length++
+		}
+		dst = snappyCopy(dst, i-candidate, length)
+		i += length
+		literalStart = i
+	}
+	return snappyLiteral(dst, block[literalStart:])
+}
+
+func snappyLiteral(dst, literal []byte) []byte {
+	n := len(literal) - 1
+	switch {
+	case n < 0:
+		return dst
+	case n < 60:
+		dst = append(dst, byte(n)<<2|snappyTagLiteral)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|snappyTagLiteral, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2|snappyTagLiteral, byte(n), byte(n>>8))
+	default:
+		dst = append(dst, 62<<2|snappyTagLiteral, byte(n), byte(n>>8), byte(n>>16))
+	}
+	return append(dst, literal...)
+}
+
+// snappyCopy emits copies of at most 64 bytes, the last one of a long match covers at least 4 bytes
+// to stay worth its 3 bytes.
+func snappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := length
+		if n > snappyMaxCopy {
+			n = snappyMaxCopy
+			if length-n < 4 {
+				n = length - 4
+			}
+		}
+		dst = append(dst, byte(n-1)<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
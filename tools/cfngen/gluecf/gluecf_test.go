@@ -21,10 +21,14 @@ package gluecf
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
+	schemamodels "github.com/panther-labs/panther/api/lambda/customschema/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/customlogs"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
 	"github.com/panther-labs/panther/pkg/awsglue"
 )
@@ -58,3 +62,18 @@ func TestTablesCloudFormation(t *testing.T) {
 
 	assert.Equal(t, expectedOutput, cf)
 }
+
+// The custom schemas create their tables at runtime, their columns must match the generated ones
+func TestCustomSchemaColumns(t *testing.T) {
+	schema := &schemamodels.CustomSchema{
+		Fields: []*schemamodels.CustomSchemaField{{Name: aws.String("src_ip"), Type: aws.String(schemamodels.FieldTypeString)}},
+	}
+	expected := append([]Column{{Name: "src_ip", Type: "string"}}, InferJSONColumns(&parsers.PantherLog{}, GlueMappings...)...)
+	expected = append(expected, RuleMatchColumns...)
+
+	var columns []Column
+	for _, c := range customlogs.RuleMatchColumns(schema) {
+		columns = append(columns, Column{Name: aws.StringValue(c.Name), Type: aws.StringValue(c.Type), Comment: aws.StringValue(c.Comment)})
+	}
+	assert.Equal(t, expected, columns)
+}
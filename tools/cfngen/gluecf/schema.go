@@ -187,6 +187,10 @@ func inferMap(t reflect.Type, customMappingsTable map[string]string) (jsonType s
 		jsonType = fmt.Sprintf("map<%s,struct<%s>>", t.Key(), inferStruct(mapOfType, customMappingsTable))
 		return
 	}
+	if mapOfType.Kind() == reflect.Map {
+		jsonType = fmt.Sprintf("map<%s,%s>", t.Key(), inferMap(mapOfType, customMappingsTable))
+		return
+	}
 	jsonType = fmt.Sprintf("map<%s,%s>", t.Key(), toJSONType(mapOfType))
 	return
 }
@@ -216,6 +220,13 @@ func toJSONType(t reflect.Type) (jsonType string) {
 		jsonType = "int"
 	case "int64":
 		jsonType = "bigint"
+	// the unsigned ints take the next larger signed type to fit their range
+	case "uint8":
+		jsonType = "smallint"
+	case "uint16":
+		jsonType = "int"
+	case "uint32":
+		jsonType = "bigint"
 	case "float32":
 		jsonType = "float"
 	case "float64":
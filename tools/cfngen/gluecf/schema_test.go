@@ -85,6 +85,8 @@ func TestInferJsonColumns(t *testing.T) {
 		Int16Field  int16  `description:"test field"`
 		Int32Field  int32  `description:"test field"`
 		Int64Field  int64  `description:"test field"`
+		Uint8Field  uint8  `description:"test field"`
+		Uint16Field uint16 `description:"test field"`
 		IntPtrField *int32 `description:"test field"`
 
 		Float32Field    float32  `description:"test field"`
@@ -104,9 +106,10 @@ func TestInferJsonColumns(t *testing.T) {
 
 		MapSlice []map[string]string `description:"test field"`
 
-		MapStringToInterface map[string]interface{} `description:"test field"`
-		MapStringToString    map[string]string      `description:"test field"`
-		MapStringToStruct    map[string]TestStruct  `description:"test field"`
+		MapStringToInterface map[string]interface{}       `description:"test field"`
+		MapStringToString    map[string]string            `description:"test field"`
+		MapStringToStruct    map[string]TestStruct        `description:"test field"`
+		MapStringToMap       map[string]map[string]string `description:"test field"`
 
 		StructField       TestStruct   `description:"test field"`
 		NestedStructField NestedStruct `description:"test field"`
@@ -125,6 +128,8 @@ func TestInferJsonColumns(t *testing.T) {
 		Int16Field:  1,
 		Int32Field:  1,
 		Int64Field:  1,
+		Uint8Field:  1,
+		Uint16Field: 1,
 		IntPtrField: &i,
 
 		Float32Field:    1,
@@ -149,6 +154,7 @@ func TestInferJsonColumns(t *testing.T) {
 		MapStringToInterface: make(map[string]interface{}),
 		MapStringToString:    make(map[string]string),
 		MapStringToStruct:    make(map[string]TestStruct),
+		MapStringToMap:       make(map[string]map[string]string),
 
 		StructField: TestStruct{},
 		NestedStructField: NestedStruct{
@@ -190,6 +196,8 @@ func TestInferJsonColumns(t *testing.T) {
 		{Name: "Int16Field", Type: "smallint", Comment: "test field"},
 		{Name: "Int32Field", Type: "int", Comment: "test field"},
 		{Name: "Int64Field", Type: "bigint", Comment: "test field"},
+		{Name: "Uint8Field", Type: "smallint", Comment: "test field"},
+		{Name: "Uint16Field", Type: "int", Comment: "test field"},
 		{Name: "IntPtrField", Type: "int", Comment: "test field"},
 		{Name: "Float32Field", Type: "float", Comment: "test field"},
 		{Name: "Float64Field", Type: "double", Comment: "test field"},
@@ -205,6 +213,7 @@ func TestInferJsonColumns(t *testing.T) {
 		{Name: "MapStringToInterface", Type: "map<string,string>", Comment: "test field"}, // special case
 		{Name: "MapStringToString", Type: "map<string,string>", Comment: "test field"},
 		{Name: "MapStringToStruct", Type: "map<string,struct<Field1:string,Field2:int>>", Comment: "test field"},
+		{Name: "MapStringToMap", Type: "map<string,map<string,string>>", Comment: "test field"},
 		{Name: "StructField", Type: "struct<Field1:string,Field2:int>", Comment: "test field"},
 		{Name: "NestedStructField", Type: "struct<InheritedField:string,A:struct<Field1:string,Field2:int>,B:struct<Field1:string,Field2:int>,C:struct<Field1:string,Field2:int>>", Comment: "test field"}, // nolint
 		{Name: "CustomTypeField", Type: "foo", Comment: "test field"},
@@ -43,5 +43,6 @@ func Dashboards(awsRegion string) (dashboards []*cloudwatchcf.Dashboard) {
 	dashboards = append(dashboards, cloudwatchcf.NewDashboard(awsRegion, "PantherAlertProcessing", alertsJSON))
 	dashboards = append(dashboards, cloudwatchcf.NewDashboard(awsRegion, "PantherRemediation", remediationJSON))
 	dashboards = append(dashboards, cloudwatchcf.NewDashboard(awsRegion, "PantherLogAnalysis", logProcessingJSON))
+	dashboards = append(dashboards, cloudwatchcf.NewDashboard(awsRegion, "PantherIngestionHealth", ingestionJSON))
 	return dashboards
 }
@@ -31,6 +31,12 @@ type backendParameters struct {
 	PythonLayerVersionArn        string `yaml:"PythonLayerVersionArn"`
 	WebApplicationCertificateArn string `yaml:"WebApplicationCertificateArn"`
 	TracingMode                  string `yaml:"TracingMode"`
+	ParquetLogTypes              string `yaml:"ParquetLogTypes"`
+	GeoIPDatabasePath            string `yaml:"GeoIPDatabasePath"`
+	LogProcessorConcurrency      int    `yaml:"LogProcessorConcurrency"`
+	PackSigningKeys              string `yaml:"PackSigningKeys"`
+	QueryConcurrencyQuota        int    `yaml:"QueryConcurrencyQuota"`
+	QueryScannedBytesQuota       int64  `yaml:"QueryScannedBytesQuota"`
 }
 
 type frontendParameters struct {
@@ -38,16 +44,23 @@ type frontendParameters struct {
 	WebApplicationFargateTaskMemory int `yaml:"WebApplicationFargateTaskMemory"`
 }
 
+type syslogCollectorParameters struct {
+	CertificateArn    string `yaml:"CertificateArn"` // the collector is only deployed with a certificate
+	FargateTaskCPU    int    `yaml:"FargateTaskCPU"`
+	FargateTaskMemory int    `yaml:"FargateTaskMemory"`
+}
+
 type monitoringParameters struct {
 	AlarmSNSTopicARN string `yaml:"AlarmSNSTopicARN"` // where to send alarms (optional)
 }
 
 // PantherConfig describes the panther_config.yml file.
 type PantherConfig struct {
-	BucketsParameterValues    bucketsParameters    `yaml:"BucketsParameterValues"`
-	BackendParameterValues    backendParameters    `yaml:"BackendParameterValues"`
-	FrontendParameterValues   frontendParameters   `yaml:"FrontendParameterValues"`
-	MonitoringParameterValues monitoringParameters `yaml:"MonitoringParameterValues"`
-	PipLayer                  []string             `yaml:"PipLayer"`
-	InitialAnalysisSets       []string             `yaml:"InitialAnalysisSets"`
+	BucketsParameterValues         bucketsParameters         `yaml:"BucketsParameterValues"`
+	BackendParameterValues         backendParameters         `yaml:"BackendParameterValues"`
+	FrontendParameterValues        frontendParameters        `yaml:"FrontendParameterValues"`
+	MonitoringParameterValues      monitoringParameters      `yaml:"MonitoringParameterValues"`
+	SyslogCollectorParameterValues syslogCollectorParameters `yaml:"SyslogCollectorParameterValues"`
+	PipLayer                       []string                  `yaml:"PipLayer"`
+	InitialAnalysisSets            []string                  `yaml:"InitialAnalysisSets"`
 }
@@ -132,6 +132,9 @@ func Deploy() {
 	// Deploy frontend stack
 	runDeploy(func() { deployFrontend(awsSession, bucket, backendOutputs, &config) })
 
+	// Deploy the syslog collector, if it's configured
+	runDeploy(func() { deploySyslogCollector(awsSession, bucket, backendOutputs, &config) })
+
 	// Deploy monitoring
 	runDeploy(func() { deployMonitoring(awsSession, bucket, backendOutputs, &config) })
 
@@ -174,8 +177,14 @@ func getBackendDeployParams(
 	result := map[string]string{
 		"CloudWatchLogRetentionDays":   strconv.Itoa(v.CloudWatchLogRetentionDays),
 		"Debug":                        strconv.FormatBool(v.Debug),
+		"GeoIPDatabasePath":            v.GeoIPDatabasePath,
 		"LayerVersionArns":             v.LayerVersionArns,
+		"LogProcessorConcurrency":      strconv.Itoa(v.LogProcessorConcurrency),
+		"PackSigningKeys":              v.PackSigningKeys,
+		"ParquetLogTypes":              v.ParquetLogTypes,
 		"PythonLayerVersionArn":        v.PythonLayerVersionArn,
+		"QueryConcurrencyQuota":        strconv.Itoa(v.QueryConcurrencyQuota),
+		"QueryScannedBytesQuota":       strconv.FormatInt(v.QueryScannedBytesQuota, 10),
 		"S3BucketAccessLogs":           logBucket,
 		"S3BucketSource":               sourceBucket,
 		"TracingMode":                  v.TracingMode,
@@ -261,6 +270,9 @@ func postDeploySetup(awsSession *session.Session, backendOutputs map[string]stri
 		return fmt.Errorf("failed to enable TOTP for user pool %s: %v", userPoolID, err)
 	}
 
+	if err := migrateUserRoles(awsSession); err != nil {
+		return err
+	}
 	if err := inviteFirstUser(awsSession); err != nil {
 		return err
 	}
@@ -268,6 +280,21 @@ func postDeploySetup(awsSession *session.Session, backendOutputs map[string]stri
 	return initializeAnalysisSets(awsSession, backendOutputs["AnalysisApiEndpoint"], config)
 }
 
+// The users created before the roles existed are given the Admin role, the first time roles are deployed.
+func migrateUserRoles(awsSession *session.Session) error {
+	input := &usermodels.LambdaInput{
+		MigrateUserRoles: &usermodels.MigrateUserRolesInput{},
+	}
+	var output usermodels.MigrateUserRolesOutput
+	if err := invokeLambda(awsSession, "panther-users-api", input, &output); err != nil {
+		return fmt.Errorf("failed to migrate the roles of the users: %v", err)
+	}
+	if len(output.MigratedUsers) > 0 {
+		logger.Infof("deploy: %d existing users were given the Admin role", len(output.MigratedUsers))
+	}
+	return nil
+}
+
 // If the users list is empty (e.g. on the initial deploy), create the first user.
 func inviteFirstUser(awsSession *session.Session) error {
 	input := &usermodels.LambdaInput{
@@ -299,6 +326,7 @@ func inviteFirstUser(awsSession *session.Session) error {
 			GivenName:  &firstName,
 			FamilyName: &lastName,
 			Email:      &email,
+			Role:       aws.String(usermodels.RoleAdmin),
 		},
 	}
 	if err := invokeLambda(awsSession, "panther-users-api", input, nil); err != nil {
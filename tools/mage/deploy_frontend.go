@@ -35,6 +35,7 @@ const (
 	awsEnvFile       = "out/.env.aws"
 	frontendStack    = "panther-app-frontend"
 	frontendTemplate = "deployments/frontend.yml"
+	webDockerfile    = "deployments/web/Dockerfile"
 )
 
 func deployFrontend(awsSession *session.Session, bucket string, backendOutputs map[string]string, config *PantherConfig) {
@@ -42,7 +43,7 @@ func deployFrontend(awsSession *session.Session, bucket string, backendOutputs m
 		logger.Fatalf("failed to write ENV variables to file %s: %v", awsEnvFile, err)
 	}
 
-	dockerImage, err := buildAndPushImageFromSource(awsSession, backendOutputs["WebApplicationImageRegistry"])
+	dockerImage, err := buildAndPushImageFromSource(awsSession, backendOutputs["WebApplicationImageRegistry"], webDockerfile)
 	if err != nil {
 		logger.Fatal(err)
 	}
@@ -74,7 +75,7 @@ func generateDotEnvFromCfnOutputs(awsSession *session.Session, outputs map[strin
 }
 
 // Build a personalized docker image from source and push it to the private image repo of the user
-func buildAndPushImageFromSource(awsSession *session.Session, imageRegistry string) (string, error) {
+func buildAndPushImageFromSource(awsSession *session.Session, imageRegistry, dockerfile string) (string, error) {
 	logger.Debug("deploy: requesting access to remote image repo")
 	response, err := ecr.New(awsSession).GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{})
 	if err != nil {
@@ -94,8 +95,8 @@ func buildAndPushImageFromSource(awsSession *session.Session, imageRegistry stri
 		return "", err
 	}
 
-	logger.Info("deploy: docker build web server (deployments/web/Dockerfile)")
-	dockerBuildOutput, err := sh.Output("docker", "build", "--file", "deployments/web/Dockerfile", "--quiet", ".")
+	logger.Infof("deploy: docker build %s", dockerfile)
+	dockerBuildOutput, err := sh.Output("docker", "build", "--file", dockerfile, "--quiet", ".")
 	if err != nil {
 		return "", fmt.Errorf("docker build failed: %v", err)
 	}
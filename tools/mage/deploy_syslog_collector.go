@@ -0,0 +1,58 @@
+package mage
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+const (
+	syslogCollectorStack      = "panther-app-syslog-collector"
+	syslogCollectorTemplate   = "deployments/syslog_collector.yml"
+	syslogCollectorDockerfile = "deployments/syslog/Dockerfile"
+)
+
+func deploySyslogCollector(awsSession *session.Session, bucket string, backendOutputs map[string]string, config *PantherConfig) {
+	settings := config.SyslogCollectorParameterValues
+	if settings.CertificateArn == "" {
+		logger.Debug("deploy: no syslog collector certificate, skipping the syslog collector")
+		return
+	}
+
+	dockerImage, err := buildAndPushImageFromSource(awsSession, backendOutputs["SyslogCollectorImageRegistry"], syslogCollectorDockerfile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	params := map[string]string{
+		"SyslogCollectorFargateTaskCPU":    strconv.Itoa(settings.FargateTaskCPU),
+		"SyslogCollectorFargateTaskMemory": strconv.Itoa(settings.FargateTaskMemory),
+		"SyslogCollectorImage":             dockerImage,
+		"SyslogCollectorCertificateArn":    settings.CertificateArn,
+		"SyslogCollectorClusterName":       backendOutputs["WebApplicationClusterName"],
+		"SyslogCollectorVpcId":             backendOutputs["WebApplicationVpcId"],
+		"SyslogCollectorSubnetOneId":       backendOutputs["WebApplicationSubnetOneId"],
+		"SyslogCollectorSubnetTwoId":       backendOutputs["WebApplicationSubnetTwoId"],
+		"HttpIngestBucket":                 backendOutputs["HttpIngestBucketName"],
+		"Debug":                            strconv.FormatBool(config.BackendParameterValues.Debug),
+	}
+	outputs := deployTemplate(awsSession, syslogCollectorTemplate, bucket, syslogCollectorStack, params)
+	logger.Infof("deploy: syslog collector endpoint = %s", outputs["SyslogCollectorEndpoint"])
+}
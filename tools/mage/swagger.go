@@ -30,8 +30,13 @@ import (
 )
 
 const (
-	pantherLambdaKey = "x-panther-lambda-cfn-resource" // top-level key in Swagger file
-	space8           = "        "
+	pantherLambdaKey     = "x-panther-lambda-cfn-resource" // top-level key in Swagger file
+	pantherAPIKeyKey     = "x-panther-api-key-auth"        // top-level key in Swagger file
+	pantherLambdaAuthKey = "x-panther-lambda-auth"         // top-level key in Swagger file
+	space8               = "        "
+
+	// Trailing comment on a DefinitionBody to embed the API for callers with Panther API tokens
+	tokenAuthComment = "# token-auth"
 )
 
 // Match "DefinitionBody: api/myspec.yml  # possible comment"
@@ -76,10 +81,11 @@ func embedAPIs(cfn []byte) ([]byte, error) {
 
 	cfn = swaggerPattern.ReplaceAllFunc(cfn, func(match []byte) []byte {
 		strMatch := strings.TrimSpace(string(match))
-		apiFilename := strings.Split(strMatch, " ")[1]
+		apiFilename := strings.Fields(strMatch)[1]
+		tokenAuth := strings.HasSuffix(strMatch, tokenAuthComment)
 
 		var body *string
-		body, err = loadSwagger(apiFilename)
+		body, err = loadSwagger(apiFilename, tokenAuth)
 		if err != nil {
 			return nil // stop here and the top-level err will be returned
 		}
@@ -102,7 +108,9 @@ func embedAPIs(cfn []byte) ([]byte, error) {
 // This is required so we can interpolate the Region and AccountID - API gateway needs to know
 // the ARN of the Lambda function being invoked for each endpoint. The interpolation does not work
 // if we just reference a swagger file in S3 - the api spec must be embedded into the CloudFormation itself.
-func loadSwagger(filename string) (*string, error) {
+//
+// With tokenAuth, the callers are authorized by the Panther token authorizer instead of the API's own scheme.
+func loadSwagger(filename string, tokenAuth bool) (*string, error) {
 	var apiBody map[string]interface{}
 	if err := yaml.Unmarshal(readFile(filename), &apiBody); err != nil {
 		return nil, fmt.Errorf("failed to parse file %s: %v", filename, err)
@@ -118,6 +126,60 @@ func loadSwagger(filename string) (*string, error) {
 		},
 	}
 
+	// APIs called from outside of AWS authorize their callers with API keys in the x-api-key header instead.
+	security := "sigv4"
+	if apiKeyAuth, _ := apiBody[pantherAPIKeyKey].(bool); apiKeyAuth {
+		apiBody["securityDefinitions"] = map[string]interface{}{
+			"api_key": map[string]string{
+				"type": "apiKey",
+				"name": "x-api-key",
+				"in":   "header",
+			},
+		}
+		apiBody["x-amazon-apigateway-api-key-source"] = "HEADER"
+		security = "api_key"
+	}
+	delete(apiBody, pantherAPIKeyKey)
+
+	// APIs called by services which can't sign requests nor send API keys (e.g. webhooks) are authorized
+	// by their Lambda handler instead, e.g. with a token of their own.
+	lambdaAuth, _ := apiBody[pantherLambdaAuthKey].(bool)
+	if lambdaAuth {
+		delete(apiBody, "securityDefinitions")
+		delete(apiBody, "x-amazon-apigateway-api-key-source")
+	}
+	delete(apiBody, pantherLambdaAuthKey)
+
+	// APIs called by CI pipelines and scripts authorize their callers with the Panther API tokens,
+	// verified by a Lambda REQUEST authorizer which also checks the scopes of the token.
+	if tokenAuth {
+		apiBody["securityDefinitions"] = map[string]interface{}{
+			"panther_token": map[string]interface{}{
+				"type":                         "apiKey",
+				"name":                         "Authorization",
+				"in":                           "header",
+				"x-amazon-apigateway-authtype": "custom",
+				"x-amazon-apigateway-authorizer": map[string]interface{}{
+					"type":                         "request",
+					"identitySource":               "method.request.header.Authorization",
+					"authorizerResultTtlInSeconds": 300,
+					"authorizerUri": map[string]interface{}{
+						"Fn::Sub": strings.Join([]string{
+							"arn:aws:apigateway:${AWS::Region}:lambda:path",
+							"2015-03-31",
+							"functions",
+							"arn:aws:lambda:${AWS::Region}:${AWS::AccountId}:function:panther-token-authorizer",
+							"invocations",
+						}, "/"),
+					},
+				},
+			},
+		}
+		delete(apiBody, "x-amazon-apigateway-api-key-source")
+		security = "panther_token"
+		lambdaAuth = false
+	}
+
 	// API Gateway will validate all requests to the maximum possible extent.
 	apiBody["x-amazon-apigateway-request-validators"] = map[string]interface{}{
 		"validate-all": map[string]bool{
@@ -132,7 +194,7 @@ func loadSwagger(filename string) (*string, error) {
 	}
 	delete(apiBody, pantherLambdaKey)
 
-	// Every method requires the same boilerplate settings: validation, sigv4, lambda integration
+	// Every method requires the same boilerplate settings: validation, authorization, lambda integration
 	for _, endpoints := range apiBody["paths"].(map[interface{}]interface{}) {
 		for _, definition := range endpoints.(map[interface{}]interface{}) {
 			def := definition.(map[interface{}]interface{})
@@ -151,8 +213,10 @@ func loadSwagger(filename string) (*string, error) {
 				},
 			}
 			def["x-amazon-apigateway-request-validator"] = "validate-all"
-			def["security"] = []map[string]interface{}{
-				{"sigv4": []string{}},
+			if !lambdaAuth {
+				def["security"] = []map[string]interface{}{
+					{security: []string{}},
+				}
 			}
 
 			// Replace integer response codes with strings (cfn doesn't support non-string keys).
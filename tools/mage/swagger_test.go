@@ -61,3 +61,39 @@ func TestEmbedAPIs(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(expected), string(transformed))
 }
+
+func TestLoadSwaggerAPIKeyAuth(t *testing.T) {
+	body, err := loadSwagger("testdata/api/api-key.yml", false)
+	require.NoError(t, err)
+	assert.Contains(t, *body, "- api_key: []")
+	assert.Contains(t, *body, "name: x-api-key")
+	assert.Contains(t, *body, "x-amazon-apigateway-api-key-source: HEADER")
+	assert.NotContains(t, *body, "sigv4")
+	assert.NotContains(t, *body, "x-panther-api-key-auth")
+}
+
+func TestLoadSwaggerLambdaAuth(t *testing.T) {
+	body, err := loadSwagger("testdata/api/lambda-auth.yml", false)
+	require.NoError(t, err)
+	assert.NotContains(t, *body, "security")
+	assert.NotContains(t, *body, "sigv4")
+	assert.NotContains(t, *body, "x-panther-lambda-auth")
+	assert.Contains(t, *body, "x-amazon-apigateway-integration")
+}
+
+func TestLoadSwaggerTokenAuth(t *testing.T) {
+	body, err := loadSwagger("testdata/api/first.yml", true)
+	require.NoError(t, err)
+	assert.Contains(t, *body, "- panther_token: []")
+	assert.Contains(t, *body, "x-amazon-apigateway-authtype: custom")
+	assert.Contains(t, *body, "identitySource: method.request.header.Authorization")
+	assert.Contains(t, *body, "function:panther-token-authorizer/invocations")
+	assert.NotContains(t, *body, "sigv4")
+}
+
+func TestEmbedAPIsTokenAuth(t *testing.T) {
+	transformed, err := embedAPIs([]byte("Resources:\n  Api:\n    Properties:\n      DefinitionBody: testdata/api/first.yml # token-auth"))
+	require.NoError(t, err)
+	assert.Contains(t, string(transformed), "- panther_token: []")
+	assert.NotContains(t, string(transformed), tokenAuthComment)
+}
@@ -173,11 +173,16 @@ func destroyCfnStacks(awsSession *session.Session) error {
 		errCount++
 	}
 
-	// Delete frontend stack first because the ECS service needs to completely stop before the
-	// ECS cluster in the backendStack can be deleted.
-	logger.Infof("deleting CloudFormation stack: %s", frontendStack)
-	go deleteStack(client, aws.String(frontendStack), results)
-	handleResult(<-results)
+	// Delete the frontend and syslog collector stacks first because their ECS services need to completely stop
+	// before the ECS cluster in the backendStack can be deleted.
+	serviceStacks := []string{frontendStack, syslogCollectorStack}
+	logger.Infof("deleting CloudFormation stacks: %s", strings.Join(serviceStacks, ", "))
+	for _, stack := range serviceStacks {
+		go deleteStack(client, aws.String(stack), results)
+	}
+	for range serviceStacks {
+		handleResult(<-results)
+	}
 
 	// Trigger the deletion of the remaining stacks in parallel
 	parallelStacks := []string{backendStack, monitoringStack, databasesStack, bucketStack, onboardStack}
@@ -100,6 +100,7 @@ export enum ComplianceStatusEnum {
 export type CreateOrModifyPolicyInput = {
   actionDelaySeconds?: Maybe<Scalars['Int']>;
   alertSuppressSeconds?: Maybe<Scalars['Int']>;
+  autoRemediationApproval?: Maybe<Scalars['Boolean']>;
   autoRemediationId?: Maybe<Scalars['ID']>;
   autoRemediationParameters?: Maybe<Scalars['AWSJSON']>;
   body: Scalars['String'];
@@ -130,6 +131,21 @@ export type CreateOrModifyRuleInput = {
   tests?: Maybe<Array<Maybe<PolicyUnitTestInput>>>;
 };
 
+export type CustomWebhookConfig = {
+  __typename?: 'CustomWebhookConfig';
+  webhookURL: Scalars['String'];
+  method?: Maybe<Scalars['String']>;
+  headers?: Maybe<Scalars['AWSJSON']>;
+  bodyTemplate: Scalars['String'];
+};
+
+export type CustomWebhookConfigInput = {
+  webhookURL: Scalars['String'];
+  method?: Maybe<Scalars['String']>;
+  headers?: Maybe<Scalars['AWSJSON']>;
+  bodyTemplate: Scalars['String'];
+};
+
 export type DeletePolicyInput = {
   policies?: Maybe<Array<Maybe<DeletePolicyInputItem>>>;
 };
@@ -163,6 +179,8 @@ export type DestinationConfig = {
   opsgenie?: Maybe<OpsgenieConfig>;
   msTeams?: Maybe<MsTeamsConfig>;
   asana?: Maybe<AsanaConfig>;
+  serviceNow?: Maybe<ServiceNowConfig>;
+  customWebhook?: Maybe<CustomWebhookConfig>;
 };
 
 export type DestinationConfigInput = {
@@ -175,6 +193,8 @@ export type DestinationConfigInput = {
   opsgenie?: Maybe<OpsgenieConfigInput>;
   msTeams?: Maybe<MsTeamsConfigInput>;
   asana?: Maybe<AsanaConfigInput>;
+  serviceNow?: Maybe<ServiceNowConfigInput>;
+  customWebhook?: Maybe<CustomWebhookConfigInput>;
 };
 
 export type DestinationInput = {
@@ -195,6 +215,8 @@ export enum DestinationTypeEnum {
   Sns = 'sns',
   Sqs = 'sqs',
   Asana = 'asana',
+  Servicenow = 'servicenow',
+  Customwebhook = 'customwebhook',
 }
 
 export type GeneralSettings = {
@@ -589,6 +611,7 @@ export type PolicyDetails = {
   __typename?: 'PolicyDetails';
   actionDelaySeconds?: Maybe<Scalars['Int']>;
   alertSuppressSeconds?: Maybe<Scalars['Int']>;
+  autoRemediationApproval?: Maybe<Scalars['Boolean']>;
   autoRemediationId?: Maybe<Scalars['ID']>;
   autoRemediationParameters?: Maybe<Scalars['AWSJSON']>;
   complianceStatus?: Maybe<ComplianceStatusEnum>;
@@ -813,6 +836,23 @@ export enum SeverityEnum {
   Critical = 'CRITICAL',
 }
 
+export type ServiceNowConfig = {
+  __typename?: 'ServiceNowConfig';
+  instanceURL: Scalars['String'];
+  userName: Scalars['String'];
+  password: Scalars['String'];
+  table?: Maybe<Scalars['String']>;
+  callbackToken: Scalars['String'];
+};
+
+export type ServiceNowConfigInput = {
+  instanceURL: Scalars['String'];
+  userName: Scalars['String'];
+  password: Scalars['String'];
+  table?: Maybe<Scalars['String']>;
+  callbackToken: Scalars['String'];
+};
+
 export type SlackConfig = {
   __typename?: 'SlackConfig';
   webhookURL: Scalars['String'];
@@ -0,0 +1,129 @@
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import React from 'react';
+import { Field } from 'formik';
+import * as Yup from 'yup';
+import FormikTextInput from 'Components/fields/text-input';
+import FormikTextArea from 'Components/fields/textarea';
+import FormikCombobox from 'Components/fields/combobox';
+import { DestinationConfigInput } from 'Generated/schema';
+import BaseDestinationForm, {
+  BaseDestinationFormValues,
+  defaultValidationSchema,
+} from 'Components/forms/common/base-destination-form';
+import { Text } from 'pouncejs';
+
+type CustomWebhookFieldValues = Pick<DestinationConfigInput, 'customWebhook'>;
+
+interface CustomWebhookDestinationFormProps {
+  initialValues: BaseDestinationFormValues<CustomWebhookFieldValues>;
+  onSubmit: (values: BaseDestinationFormValues<CustomWebhookFieldValues>) => void;
+}
+
+const webhookMethods = ['POST', 'PUT', 'PATCH'];
+
+// The headers are a JSON object of header names to (templated) values
+const isHeadersObject = (value?: string) => {
+  try {
+    const headers = JSON.parse(value);
+    return (
+      headers !== null &&
+      typeof headers === 'object' &&
+      !Array.isArray(headers) &&
+      Object.values(headers).every(header => typeof header === 'string')
+    );
+  } catch (err) {
+    return false;
+  }
+};
+
+const customWebhookFieldsValidationSchema = Yup.object().shape({
+  outputConfig: Yup.object().shape({
+    customWebhook: Yup.object().shape({
+      webhookURL: Yup.string()
+        .url('Must be a valid webhook URL')
+        .required(),
+      method: Yup.string().oneOf(webhookMethods),
+      headers: Yup.string().test(
+        'headers',
+        'Must be a JSON object of header names and values',
+        isHeadersObject
+      ),
+      bodyTemplate: Yup.string().required(),
+    }),
+  }),
+});
+
+// @ts-ignore
+// We merge the two schemas together: the one deriving from the common fields, plus the custom
+// ones that change for each destination.
+// https://github.com/jquense/yup/issues/522
+const mergedValidationSchema = defaultValidationSchema.concat(customWebhookFieldsValidationSchema);
+
+const CustomWebhookDestinationForm: React.FC<CustomWebhookDestinationFormProps> = ({
+  onSubmit,
+  initialValues,
+}) => {
+  return (
+    <BaseDestinationForm<CustomWebhookFieldValues>
+      initialValues={initialValues}
+      validationSchema={mergedValidationSchema}
+      onSubmit={onSubmit}
+    >
+      <Field
+        as={FormikTextInput}
+        name="outputConfig.customWebhook.webhookURL"
+        label="Webhook URL"
+        placeholder="Where should we send the alerts?"
+        mb={6}
+        aria-required
+      />
+      <Field
+        as={FormikCombobox}
+        name="outputConfig.customWebhook.method"
+        label="HTTP Method"
+        mb={6}
+        items={webhookMethods}
+        inputProps={{ placeholder: 'Select an HTTP method' }}
+      />
+      <Field
+        as={FormikTextArea}
+        name="outputConfig.customWebhook.headers"
+        label="Headers"
+        placeholder={'{"Authorization": "Bearer {{secret \\"panther-webhooks/token\\"}}"}'}
+      />
+      <Text size="small" color="grey200" mt={2} mb={6}>
+        A JSON object of header names and values. Secrets under panther-webhooks/ can be read with
+        the secret function.
+      </Text>
+      <Field
+        as={FormikTextArea}
+        name="outputConfig.customWebhook.bodyTemplate"
+        label="Body Template"
+        placeholder={'{"title": {{json .Title}}, "severity": "{{.Severity}}"}'}
+        aria-required
+      />
+      <Text size="small" color="grey200" mt={2}>
+        A Go template rendered with the alert, see the documentation for its fields
+      </Text>
+    </BaseDestinationForm>
+  );
+};
+
+export default CustomWebhookDestinationForm;
@@ -25,3 +25,5 @@ export { default as OpsgenieDestinationForm } from './opsgenie-destination.form'
 export { default as GithubDestinationForm } from './github-destination-form';
 export { default as PagerDutyDestinationForm } from './pagerduty-destination-form';
 export { default as AsanaDestinationForm } from './asana-destination-form';
+export { default as ServiceNowDestinationForm } from './servicenow-destination-form';
+export { default as CustomWebhookDestinationForm } from './custom-webhook-destination-form';
@@ -38,6 +38,10 @@ const msTeamsFieldsValidationSchema = Yup.object().shape({
     msTeams: Yup.object().shape({
       webhookURL: Yup.string()
         .url('Must be a valid webhook URL')
+        .matches(
+          /^https:\/\/(outlook\.office(365)?\.com|[^/]+\.webhook\.office\.com)\//i,
+          'Must be a Microsoft Teams incoming webhook URL'
+        )
         .required(),
     }),
   }),
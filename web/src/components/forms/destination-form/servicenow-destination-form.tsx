@@ -0,0 +1,114 @@
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import React from 'react';
+import { Field } from 'formik';
+import * as Yup from 'yup';
+import FormikTextInput from 'Components/fields/text-input';
+import { DestinationConfigInput } from 'Generated/schema';
+import BaseDestinationForm, {
+  BaseDestinationFormValues,
+  defaultValidationSchema,
+} from 'Components/forms/common/base-destination-form';
+
+type ServiceNowFieldValues = Pick<DestinationConfigInput, 'serviceNow'>;
+
+interface ServiceNowDestinationFormProps {
+  initialValues: BaseDestinationFormValues<ServiceNowFieldValues>;
+  onSubmit: (values: BaseDestinationFormValues<ServiceNowFieldValues>) => void;
+}
+
+const serviceNowFieldsValidationSchema = Yup.object().shape({
+  outputConfig: Yup.object().shape({
+    serviceNow: Yup.object().shape({
+      instanceURL: Yup.string()
+        .url('Must be a valid ServiceNow instance URL')
+        .matches(/^https:\/\//, 'Must be an https:// URL')
+        .required(),
+      userName: Yup.string().required(),
+      password: Yup.string().required(),
+      table: Yup.string().max(80),
+      callbackToken: Yup.string()
+        .min(16, 'Must be at least 16 characters')
+        .required(),
+    }),
+  }),
+});
+
+// @ts-ignore
+// We merge the two schemas together: the one deriving from the common fields, plus the custom
+// ones that change for each destination.
+// https://github.com/jquense/yup/issues/522
+const mergedValidationSchema = defaultValidationSchema.concat(serviceNowFieldsValidationSchema);
+
+const ServiceNowDestinationForm: React.FC<ServiceNowDestinationFormProps> = ({
+  onSubmit,
+  initialValues,
+}) => {
+  return (
+    <BaseDestinationForm<ServiceNowFieldValues>
+      initialValues={initialValues}
+      validationSchema={mergedValidationSchema}
+      onSubmit={onSubmit}
+    >
+      <Field
+        as={FormikTextInput}
+        name="outputConfig.serviceNow.instanceURL"
+        label="Instance URL"
+        placeholder="What's the URL of your ServiceNow instance?"
+        mb={6}
+        aria-required
+      />
+      <Field
+        as={FormikTextInput}
+        name="outputConfig.serviceNow.userName"
+        label="User Name"
+        placeholder="Which user should open the incidents?"
+        mb={6}
+        aria-required
+      />
+      <Field
+        as={FormikTextInput}
+        name="outputConfig.serviceNow.password"
+        label="Password"
+        placeholder="What's the password of the user?"
+        mb={6}
+        aria-required
+        autoComplete="new-password"
+      />
+      <Field
+        as={FormikTextInput}
+        name="outputConfig.serviceNow.table"
+        label="Table"
+        placeholder="Which table should the incidents go to? (defaults to incident)"
+        mb={6}
+      />
+      <Field
+        as={FormikTextInput}
+        name="outputConfig.serviceNow.callbackToken"
+        label="Callback Token"
+        placeholder="The secret ServiceNow sends when an incident is closed"
+        mb={6}
+        aria-required
+        autoComplete="new-password"
+      />
+    </BaseDestinationForm>
+  );
+};
+
+export default ServiceNowDestinationForm;
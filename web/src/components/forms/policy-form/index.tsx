@@ -28,6 +28,7 @@ import PolicyFormTestFields from '../common/rule-form-test-fields';
 
 export const policyEditableFields = [
   ...ruleCoreEditableFields,
+  'autoRemediationApproval',
   'autoRemediationId',
   'autoRemediationParameters',
   'suppressions',
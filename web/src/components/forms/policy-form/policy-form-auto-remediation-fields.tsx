@@ -17,12 +17,13 @@
  */
 
 import React from 'react';
-import { Alert, Box, Combobox, Grid, InputElementLabel, Spinner } from 'pouncejs';
+import { Alert, Box, Combobox, Flex, Grid, InputElementLabel, Spinner } from 'pouncejs';
 import { Field, useFormikContext } from 'formik';
 import FormikTextInput from 'Components/fields/text-input';
 import { formatJSON, extractErrorMessage } from 'Helpers/utils';
 import { useQuery, gql } from '@apollo/client';
 import FormikEditor from 'Components/fields/editor';
+import FormikSwitch from 'Components/fields/switch';
 import { PANTHER_SCHEMA_DOCS_LINK } from 'Source/constants';
 import { PolicyFormValues } from './index';
 
@@ -101,6 +102,12 @@ const PolicyFormAutoRemediationFields: React.FC = () => {
             setAutoRemediationSelection(remediationTuple);
           }}
         />
+        <Flex alignItems="center" hidden={!values.autoRemediationId}>
+          <InputElementLabel htmlFor="autoRemediationApproval" mr={6}>
+            Require Approval
+          </InputElementLabel>
+          <Field as={FormikSwitch} name="autoRemediationApproval" />
+        </Flex>
       </Grid>
       <Box hidden>
         <Field as={FormikTextInput} name="autoRemediationId" />
@@ -31,11 +31,13 @@ import {
 import { BaseDestinationFormValues } from 'Components/forms/common/base-destination-form';
 import {
   AsanaDestinationForm,
+  CustomWebhookDestinationForm,
   GithubDestinationForm,
   JiraDestinationForm,
   MicrosoftTeamsDestinationForm,
   OpsgenieDestinationForm,
   PagerDutyDestinationForm,
+  ServiceNowDestinationForm,
   SlackDestinationForm,
   SNSDestinationForm,
   SQSDestinationForm,
@@ -88,6 +90,19 @@ const ADD_DESTINATION = gql`
           personalAccessToken
           projectGids
         }
+        serviceNow {
+          instanceURL
+          userName
+          password
+          table
+          callbackToken
+        }
+        customWebhook {
+          webhookURL
+          method
+          headers
+          bodyTemplate
+        }
       }
       verificationStatus
       defaultForSeverity
@@ -264,6 +279,36 @@ const AddDestinationSidesheet: React.FC<AddDestinationSidesheetProps> = ({ desti
             onSubmit={handleSubmit}
           />
         );
+      case DestinationTypeEnum.Servicenow:
+        return (
+          <ServiceNowDestinationForm
+            initialValues={{
+              ...commonInitialValues,
+              outputConfig: {
+                serviceNow: {
+                  instanceURL: '',
+                  userName: '',
+                  password: '',
+                  table: '',
+                  callbackToken: '',
+                },
+              },
+            }}
+            onSubmit={handleSubmit}
+          />
+        );
+      case DestinationTypeEnum.Customwebhook:
+        return (
+          <CustomWebhookDestinationForm
+            initialValues={{
+              ...commonInitialValues,
+              outputConfig: {
+                customWebhook: { webhookURL: '', method: 'POST', headers: '{}', bodyTemplate: '' },
+              },
+            }}
+            onSubmit={handleSubmit}
+          />
+        );
       default:
         return null;
     }
@@ -29,6 +29,8 @@ import jiraLogo from 'Assets/jira-minimal-logo.svg';
 import snsLogo from 'Assets/aws-sns-minimal-logo.svg';
 import sqsLogo from 'Assets/aws-sqs-minimal-logo.svg';
 import asanaLogo from 'Assets/asana-minimal-logo.svg';
+import serviceNowLogo from 'Assets/servicenow-minimal-logo.svg';
+import webhookLogo from 'Assets/webhook-minimal-logo.svg';
 
 import { SIDESHEETS } from 'Components/utils/sidesheet-context';
 import { DestinationTypeEnum } from 'Generated/schema';
@@ -79,6 +81,16 @@ const destinationConfigs = [
     title: 'Asana',
     destinationType: DestinationTypeEnum.Asana,
   },
+  {
+    logo: serviceNowLogo,
+    title: 'ServiceNow',
+    destinationType: DestinationTypeEnum.Servicenow,
+  },
+  {
+    logo: webhookLogo,
+    title: 'Custom Webhook',
+    destinationType: DestinationTypeEnum.Customwebhook,
+  },
 ];
 
 export const SelectDestinationSidesheet: React.FC = () => {
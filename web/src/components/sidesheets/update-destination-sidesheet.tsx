@@ -32,11 +32,13 @@ import {
 import { BaseDestinationFormValues } from 'Components/forms/common/base-destination-form';
 import {
   AsanaDestinationForm,
+  CustomWebhookDestinationForm,
   GithubDestinationForm,
   JiraDestinationForm,
   MicrosoftTeamsDestinationForm,
   OpsgenieDestinationForm,
   PagerDutyDestinationForm,
+  ServiceNowDestinationForm,
   SlackDestinationForm,
   SNSDestinationForm,
   SQSDestinationForm,
@@ -88,6 +90,19 @@ const UPDATE_DESTINATION = gql`
           personalAccessToken
           projectGids
         }
+        serviceNow {
+          instanceURL
+          userName
+          password
+          table
+          callbackToken
+        }
+        customWebhook {
+          webhookURL
+          method
+          headers
+          bodyTemplate
+        }
       }
       verificationStatus
       defaultForSeverity
@@ -266,6 +281,37 @@ export const UpdateDestinationSidesheet: React.FC<UpdateDestinationSidesheetProp
             onSubmit={handleSubmit}
           />
         );
+      case DestinationTypeEnum.Servicenow:
+        return (
+          <ServiceNowDestinationForm
+            initialValues={{
+              ...commonInitialValues,
+              outputConfig: pick(destination.outputConfig, [
+                'serviceNow.instanceURL',
+                'serviceNow.userName',
+                'serviceNow.password',
+                'serviceNow.table',
+                'serviceNow.callbackToken',
+              ]),
+            }}
+            onSubmit={handleSubmit}
+          />
+        );
+      case DestinationTypeEnum.Customwebhook:
+        return (
+          <CustomWebhookDestinationForm
+            initialValues={{
+              ...commonInitialValues,
+              outputConfig: pick(destination.outputConfig, [
+                'customWebhook.webhookURL',
+                'customWebhook.method',
+                'customWebhook.headers',
+                'customWebhook.bodyTemplate',
+              ]),
+            }}
+            onSubmit={handleSubmit}
+          />
+        );
       default:
         return null;
     }
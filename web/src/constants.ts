@@ -49,7 +49,9 @@ export const RESOURCE_TYPES = [
   'AWS.EC2.SecurityGroup',
   'AWS.EC2.Volume',
   'AWS.EC2.VPC',
+  'AWS.ECR.Repository',
   'AWS.ECS.Cluster',
+  'AWS.EKS.Cluster',
   'AWS.ELBV2.ApplicationLoadBalancer',
   'AWS.GuardDuty.Detector',
   'AWS.IAM.Group',
@@ -62,7 +64,9 @@ export const RESOURCE_TYPES = [
   'AWS.PasswordPolicy',
   'AWS.RDS.Instance',
   'AWS.Redshift.Cluster',
+  'AWS.Route53.HostedZone',
   'AWS.S3.Bucket',
+  'AWS.SNS.Topic',
   'AWS.WAF.Regional.WebACL',
   'AWS.WAF.WebACL',
 ] as const;
@@ -74,13 +78,16 @@ export const LOG_TYPES = [
   'AWS.GuardDuty',
   'AWS.S3ServerAccess',
   'AWS.VPCFlow',
+  'GCP.AuditLog',
   'Nginx.Access',
+  'Okta.SystemLog',
   'Osquery.Batch',
   'Osquery.Differential',
   'Osquery.Snapshot',
   'Osquery.Status',
   'OSSEC.EventInfo',
   'Syslog.RFC3164',
+  'Syslog.RFC5424',
 ] as const;
 
 export const SEVERITY_COLOR_MAP: { [key in SeverityEnum]: BadgeProps['color'] } = {
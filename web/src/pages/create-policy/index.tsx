@@ -30,6 +30,7 @@ import { getOperationName } from '@apollo/client/utilities/graphql/getFromAST';
 import { extractErrorMessage } from 'Helpers/utils';
 
 const initialValues: PolicyDetails = {
+  autoRemediationApproval: false,
   autoRemediationId: '',
   autoRemediationParameters: '{}',
   description: '',
@@ -49,6 +50,7 @@ const initialValues: PolicyDetails = {
 const CREATE_POLICY = gql`
   mutation CreatePolicy($input: CreateOrModifyPolicyInput!) {
     addPolicy(input: $input) {
+      autoRemediationApproval
       autoRemediationId
       autoRemediationParameters
       description
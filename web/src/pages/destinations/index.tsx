@@ -72,6 +72,19 @@ export const LIST_DESTINATIONS = gql`
           personalAccessToken
           projectGids
         }
+        serviceNow {
+          instanceURL
+          userName
+          password
+          table
+          callbackToken
+        }
+        customWebhook {
+          webhookURL
+          method
+          headers
+          bodyTemplate
+        }
       }
       verificationStatus
       defaultForSeverity
@@ -32,6 +32,7 @@ import { extractErrorMessage } from 'Helpers/utils';
 const POLICY_DETAILS = gql`
   query PolicyDetails($input: GetPolicyInput!) {
     policy(input: $input) {
+      autoRemediationApproval
       autoRemediationId
       autoRemediationParameters
       description
@@ -58,6 +59,7 @@ const POLICY_DETAILS = gql`
 const UPDATE_POLICY = gql`
   mutation UpdatePolicy($input: CreateOrModifyPolicyInput!) {
     updatePolicy(input: $input) {
+      autoRemediationApproval
       autoRemediationId
       autoRemediationParameters
       description
@@ -52,6 +52,7 @@ export const POLICY_DETAILS = gql`
     $resourcesForPolicyInput: ResourcesForPolicyInput!
   ) {
     policy(input: $policyDetailsInput) {
+      autoRemediationApproval
       autoRemediationId
       autoRemediationParameters
       complianceStatus